@@ -2,13 +2,13 @@ package agent
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"context"
+	"github.com/golang/protobuf/proto"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
-	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/util/logger"
 	"google.golang.org/grpc"
 )
 
@@ -16,6 +16,12 @@ func (as *AgentServer) heartbeat() {
 
 	for {
 		err := as.doHeartbeat(10 * time.Second)
+		select {
+		case <-as.deregister:
+			close(as.deregistered)
+			return
+		default:
+		}
 		if err != nil {
 			time.Sleep(30 * time.Second)
 		}
@@ -35,16 +41,21 @@ func (as *AgentServer) doHeartbeat(sleepInterval time.Duration) error {
 
 	stream, err := client.SendHeartbeat(context.Background())
 	if err != nil {
-		log.Printf("SendHeartbeat error: %v", err)
+		logger.Errorf("SendHeartbeat error: %v", err)
 		return err
 	}
 	as.sendOneHeartbeat(stream)
 
-	log.Printf("Heartbeat to %s", as.Master)
+	logger.Infof("Heartbeat to %s", as.Master)
 
 	ticker := time.NewTicker(sleepInterval)
 	for {
 		select {
+		case <-as.deregister:
+			// the master drops the agent when the stream is closed
+			stream.CloseAndRecv()
+			logger.Infof("Deregistered from %s", as.Master)
+			return nil
 		case <-as.allocatedHasChanges:
 			if err := as.sendOneHeartbeat(stream); err != nil {
 				return err
@@ -59,6 +70,8 @@ func (as *AgentServer) doHeartbeat(sleepInterval time.Duration) error {
 }
 
 func (as *AgentServer) sendOneHeartbeat(stream pb.GleamMaster_SendHeartbeatClient) error {
+	load := as.collectLoad()
+
 	as.allocatedResourceLock.Lock()
 	beat := &pb.Heartbeat{
 		Location: &pb.Location{
@@ -67,15 +80,20 @@ func (as *AgentServer) sendOneHeartbeat(stream pb.GleamMaster_SendHeartbeatClien
 			Server:     *as.Option.Host,
 			Port:       int32(*as.Option.Port),
 		},
-		Resource:  as.computeResource,
-		Allocated: proto.Clone(as.allocatedResource).(*pb.ComputeResource),
+		Resource:        as.offeredResource(),
+		Allocated:       proto.Clone(as.allocatedResource).(*pb.ComputeResource),
+		Load:            load,
+		ProtocolVersion: pb.ProtocolVersion,
+		Labels:          as.labels,
+		Taints:          as.taints,
+		CachedShards:    as.storageBackend.CachedShardNames(),
 	}
 	as.allocatedResourceLock.Unlock()
 
-	// log.Printf("Reporting allocated %v", as.allocatedResource)
+	logger.Debugf("Reporting allocated %v", beat.Allocated)
 
 	if err := stream.Send(beat); err != nil {
-		log.Printf("%v.Send(%v) = %v", stream, beat, err)
+		logger.Errorf("%v.Send(%v) = %v", stream, beat, err)
 		return err
 	}
 	return nil
@@ -3,15 +3,16 @@ package agent
 import (
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"os"
 	"path"
 	"path/filepath"
+	"sync/atomic"
 
 	"context"
 	"github.com/lovelly/gleam/distributed/resource"
 	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 	"google.golang.org/grpc"
 )
 
@@ -59,12 +60,12 @@ func (as *AgentServer) SendFileResource(stream pb.GleamAgent_SendFileResourceSer
 			break
 		}
 		if err != nil {
-			log.Printf("Receiving file %s error: %v", toFile, err)
+			logger.Errorf("Receiving file %s error: %v", toFile, err)
 			return err
 		}
 		_, err = f.Write(request.GetContent())
 		if err != nil {
-			log.Printf("Write file error: %v", err)
+			logger.Errorf("Write file error: %v", err)
 			return err
 		}
 	}
@@ -81,9 +82,13 @@ func (as *AgentServer) SendFileResource(stream pb.GleamAgent_SendFileResourceSer
 // Cleanup remove all files related to a particular flow
 func (as *AgentServer) Cleanup(ctx context.Context, cleanupRequest *pb.CleanupRequest) (*pb.CleanupResponse, error) {
 
-	log.Println("cleaning up", cleanupRequest.GetFlowHashCode())
+	logger.ForFlow(cleanupRequest.GetFlowHashCode()).Infof("cleaning up")
 	dir := path.Join(*as.Option.Dir, fmt.Sprintf("%d", cleanupRequest.GetFlowHashCode()))
 	os.RemoveAll(dir)
+	if as.executorPool != nil {
+		as.executorPool.closeFlow(cleanupRequest.GetFlowHashCode())
+	}
+	as.flowThrottles.removeFlow(cleanupRequest.GetFlowHashCode())
 
 	return &pb.CleanupResponse{}, nil
 }
@@ -91,6 +96,10 @@ func (as *AgentServer) Cleanup(ctx context.Context, cleanupRequest *pb.CleanupRe
 // Execute executes a request and stream stdout and stderr back
 func (as *AgentServer) Execute(request *pb.ExecutionRequest, stream pb.GleamAgent_ExecuteServer) error {
 
+	if err := pb.CheckProtocolVersion(request.GetProtocolVersion()); err != nil {
+		return fmt.Errorf("Failed to execute on agent %s:%d: %v", *as.Option.Host, *as.Option.Port, err)
+	}
+
 	dir := path.Join(*as.Option.Dir, fmt.Sprintf("%d", request.GetInstructionSet().GetFlowHashCode()), request.GetDir())
 	os.MkdirAll(dir, 0755)
 
@@ -99,12 +108,21 @@ func (as *AgentServer) Execute(request *pb.ExecutionRequest, stream pb.GleamAgen
 	as.plusAllocated(allocated)
 	defer as.minusAllocated(allocated)
 
+	atomic.AddInt32(&as.loadTracker.runningTasks, 1)
+	defer atomic.AddInt32(&as.loadTracker.runningTasks, -1)
+
 	request.InstructionSet.AgentAddress = fmt.Sprintf("%s:%d", *as.Option.Host, *as.Option.Port)
+	if as.Option.MmapLocalShards != nil && *as.Option.MmapLocalShards {
+		request.InstructionSet.AgentDataDir = *as.Option.Dir
+	}
 
 	statsChan := createStatsChanByInstructionSet(request.InstructionSet)
 
 	defer deleteStatsChanByInstructionSet(request.InstructionSet)
 
+	if as.executorPool != nil && isPoolable(request.InstructionSet) {
+		return as.executePooled(stream, request, dir, statsChan)
+	}
 	return as.executeCommand(stream, request, dir, statsChan)
 
 }
@@ -134,13 +152,37 @@ func (as *AgentServer) CollectExecutionStatistics(stream pb.GleamAgent_CollectEx
 // Delete deletes a particular dataset shard
 func (as *AgentServer) Delete(ctx context.Context, deleteRequest *pb.DeleteDatasetShardRequest) (*pb.DeleteDatasetShardResponse, error) {
 
-	log.Println("deleting", deleteRequest.Name)
+	logger.Infof("deleting %s", deleteRequest.Name)
 	as.storageBackend.DeleteNamedDatasetShard(deleteRequest.Name)
 	as.inMemoryChannels.Cleanup(deleteRequest.Name)
+	as.authorizer.Forget(deleteRequest.Name)
+	as.flowThrottles.forget(deleteRequest.Name)
 
 	return &pb.DeleteDatasetShardResponse{}, nil
 }
 
+// Authorize binds the dataset shards allocated to this agent to the access
+// token of the flow, before the flow's executors read or write them.
+func (as *AgentServer) Authorize(ctx context.Context, authorizeRequest *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+
+	if authorizeRequest.GetIsExisting() {
+		// the shards of the cached datasets written by earlier flows
+		for _, name := range authorizeRequest.GetNames() {
+			if !as.storageBackend.HasCompleteShard(name) {
+				return &pb.AuthorizeResponse{Error: fmt.Sprintf("%s is not kept by the agent", name)}, nil
+			}
+		}
+	}
+	for _, name := range authorizeRequest.GetNames() {
+		if err := as.authorizer.Bind(name, authorizeRequest.GetAccessToken()); err != nil {
+			return &pb.AuthorizeResponse{Error: err.Error()}, nil
+		}
+	}
+	as.flowThrottles.add(authorizeRequest.GetFlowHashCode(), authorizeRequest.GetNetworkBytesPerSecond(), authorizeRequest.GetNames())
+
+	return &pb.AuthorizeResponse{}, nil
+}
+
 func (as *AgentServer) plusAllocated(allocated pb.ComputeResource) {
 	as.allocatedResourceLock.Lock()
 	*as.allocatedResource = as.allocatedResource.Plus(allocated)
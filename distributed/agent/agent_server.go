@@ -4,8 +4,8 @@ package agent
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"net"
 	"os"
 	"path/filepath"
@@ -13,9 +13,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/distributed/netchan"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
-	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 type AgentServerOption struct {
@@ -29,6 +31,30 @@ type AgentServerOption struct {
 	MemoryMB     *int64
 	CPULevel     *int32
 	CleanRestart *bool
+	// NetworkMBPerSecond limits data sent by the agent, 0 means no limit
+	NetworkMBPerSecond *int64
+	// IndexShards builds block indexes for on disk shards, for partial reads
+	IndexShards *bool
+	// MmapLocalShards lets executors on this agent map on disk shards
+	// written to this agent, instead of reading them through the socket
+	MmapLocalShards *bool
+	// SecretsProvider is passed to executors to look up the secrets of steps
+	SecretsProvider *string
+	Authorizer      Authorizer
+	// DatasetTTL keeps on disk shards for this long after all their readers
+	// finish, for debugging. 0 deletes them at once.
+	DatasetTTL *time.Duration
+	// ExecutorIdleTime keeps executors for this long after their tasks, to
+	// run later tasks of the same flow. 0 starts an executor for each task.
+	ExecutorIdleTime *time.Duration
+	// Labels are comma separated key=value pairs, for steps to select agents
+	Labels *string
+	// Taints are comma separated key or key=value, keeping away steps
+	// not tolerating them
+	Taints *string
+	// DrainTimeout is how long to wait for the running tasks on SIGTERM,
+	// before deregistering from the master. 0 exits at once.
+	DrainTimeout *time.Duration
 }
 
 type AgentServer struct {
@@ -41,6 +67,16 @@ type AgentServer struct {
 	storageBackend          *LocalDatasetShardsManager
 	inMemoryChannels        *LocalDatasetShardsManagerInMemory
 	receiveFileResourceLock sync.Mutex
+	authorizer              Authorizer
+	loadTracker             *agentLoadTracker
+	throttle                *netchan.Throttle
+	flowThrottles           *flowThrottles
+	executorPool            *executorPool
+	labels                  []string
+	taints                  []string
+	draining                int32
+	deregister              chan struct{}
+	deregistered            chan struct{}
 }
 
 func RunAgentServer(option *AgentServerOption) {
@@ -48,13 +84,13 @@ func RunAgentServer(option *AgentServerOption) {
 	if err != nil {
 		panic(err)
 	}
-	println("starting in", absoluteDir)
+	logger.Infof("starting in %s", absoluteDir)
 	option.Dir = &absoluteDir
 
 	as := &AgentServer{
 		Option:           option,
 		Master:           *option.Master,
-		storageBackend:   NewLocalDatasetShardsManager(*option.Dir, int(*option.Port)),
+		storageBackend:   NewLocalDatasetShardsManager(*option.Dir, int(*option.Port), option.IndexShards != nil && *option.IndexShards),
 		inMemoryChannels: NewLocalDatasetShardsManagerInMemory(),
 		computeResource: &pb.ComputeResource{
 			CpuCount: int32(*option.MaxExecutor),
@@ -63,6 +99,30 @@ func RunAgentServer(option *AgentServerOption) {
 		},
 		allocatedResource:   &pb.ComputeResource{},
 		allocatedHasChanges: make(chan struct{}, 5),
+		authorizer:          option.Authorizer,
+		flowThrottles:       newFlowThrottles(),
+		loadTracker:         &agentLoadTracker{},
+		deregister:          make(chan struct{}),
+		deregistered:        make(chan struct{}),
+	}
+	if option.NetworkMBPerSecond != nil {
+		as.throttle = netchan.NewThrottle(*option.NetworkMBPerSecond * 1024 * 1024)
+	}
+	if as.authorizer == nil {
+		as.authorizer = newTokenAuthorizer()
+	}
+	if option.ExecutorIdleTime != nil && *option.ExecutorIdleTime > 0 {
+		as.executorPool = newExecutorPool(*option.ExecutorIdleTime)
+	}
+	if option.Labels != nil {
+		as.labels = splitList(*option.Labels)
+	}
+	if option.Taints != nil {
+		as.taints = splitList(*option.Taints)
+	}
+
+	if option.DrainTimeout != nil && *option.DrainTimeout > 0 {
+		go as.drainOnTerminate(*option.DrainTimeout)
 	}
 
 	go as.storageBackend.purgeExpiredEntries()
@@ -71,13 +131,13 @@ func RunAgentServer(option *AgentServerOption) {
 
 	tcpListener, err := net.Listen("tcp", fmt.Sprintf("%v:%d", *option.Host, *option.Port))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
 	fmt.Println("AgentServer tcp starts on", fmt.Sprintf("%v:%d", *option.Host, *option.Port))
 
 	grpcListener, err := net.Listen("tcp", fmt.Sprintf("%v:%d", *option.Host, *option.Port+10000))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
 	fmt.Println("AgentServer grpc starts on", fmt.Sprintf("%v:%d", *option.Host, *option.Port+10000))
 
@@ -119,7 +179,7 @@ func (as *AgentServer) serveTcp(listener net.Listener) {
 			if c, ok := conn.(*net.TCPConn); ok {
 				c.SetKeepAlive(true)
 			}
-			as.handleRequest(conn)
+			as.handleRequest(&meteredConn{conn, &as.loadTracker.transferredBytes, as.throttle})
 		}()
 	}
 }
@@ -129,31 +189,57 @@ func (r *AgentServer) handleRequest(conn net.Conn) {
 	data, err := util.ReadMessage(conn)
 
 	if err != nil {
-		log.Printf("Failed to read command:%v", err)
+		logger.Errorf("Failed to read command:%v", err)
 		return
 	}
 
 	newCmd := &pb.ControlMessage{}
 	if err := proto.Unmarshal(data, newCmd); err != nil {
-		log.Fatal("unmarshaling error: ", err)
+		logger.Fatalf("unmarshaling error: %v", err)
 	}
 	r.handleCommandConnection(conn, newCmd)
 }
 
 func (as *AgentServer) handleCommandConnection(conn net.Conn,
 	command *pb.ControlMessage) {
-	if command.GetReadRequest() != nil {
+	if err := pb.CheckProtocolVersion(command.GetProtocolVersion()); err != nil {
+		logger.Warnf("Reject connection from %v: %v", conn.RemoteAddr(), err)
+		return
+	}
+	if readRequest := command.GetReadRequest(); readRequest != nil {
+		if err := pb.CheckReaderProtocolVersion(command.GetProtocolVersion()); err != nil {
+			logger.Warnf("Reject reader %s from %v: %v", readRequest.ReaderName, conn.RemoteAddr(), err)
+			return
+		}
 		if !command.GetIsOnDiskIO() {
-			as.handleInMemoryReadConnection(conn, command.ReadRequest.ReaderName, command.ReadRequest.ChannelName)
+			as.handleInMemoryReadConnection(conn, readRequest.ReaderName, readRequest.ChannelName, readRequest.AccessToken, readRequest.ShardRange)
 		} else {
-			as.handleReadConnection(conn, command.ReadRequest.ReaderName, command.ReadRequest.ChannelName)
+			as.handleReadConnection(conn, readRequest.ReaderName, readRequest.ChannelName, readRequest.AccessToken, readRequest.ShardRange, readRequest.MapLocally)
 		}
 	}
-	if command.GetWriteRequest() != nil {
+	if writeRequest := command.GetWriteRequest(); writeRequest != nil {
+		if err := as.authorizer.AuthorizeWrite(writeRequest.ChannelName, writeRequest.AccessToken); err != nil {
+			logger.Errorf("%s rejected: %v", writeRequest.WriterName, err)
+			return
+		}
+		var reader io.Reader = conn
+		if throttle := as.flowThrottles.forChannel(writeRequest.ChannelName); throttle != nil {
+			reader = netchan.NewThrottledReader(conn, throttle)
+		}
 		if !command.GetIsOnDiskIO() {
-			as.handleLocalInMemoryWriteConnection(conn, command.WriteRequest.WriterName, command.WriteRequest.ChannelName, int(command.GetWriteRequest().GetReaderCount()))
+			as.handleLocalInMemoryWriteConnection(messageReader(reader, command.GetProtocolVersion()), writeRequest.WriterName, writeRequest.ChannelName, int(writeRequest.GetReaderCount()))
 		} else {
-			as.handleLocalWriteConnection(conn, command.WriteRequest.WriterName, command.WriteRequest.ChannelName, int(command.GetWriteRequest().GetReaderCount()))
+			as.handleLocalWriteConnection(messageReader(reader, command.GetProtocolVersion()), writeRequest.WriterName, writeRequest.ChannelName, int(writeRequest.GetReaderCount()), writeRequest.GetCompression())
+		}
+	}
+}
+
+// splitList splits the comma separated list, dropping empty entries.
+func splitList(list string) (ret []string) {
+	for _, x := range strings.Split(list, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			ret = append(ret, x)
 		}
 	}
+	return
 }
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Authorizer decides whether a connecting reader or writer can access
+// a named dataset shard. The token comes from the DatasetShardLocation
+// the driver handed to the executor.
+type Authorizer interface {
+	// Bind is called when the driver allocates the shard to this agent.
+	Bind(channelName, accessToken string) error
+	AuthorizeWrite(channelName, accessToken string) error
+	AuthorizeRead(channelName, accessToken string) error
+	Forget(channelName string)
+}
+
+// tokenAuthorizer binds each dataset shard to the access token of the flow
+// the driver allocated it to. Readers and writers must present the same
+// token. Shards not allocated by a driver can not be read or written.
+type tokenAuthorizer struct {
+	sync.Mutex
+	channelTokens map[string]string
+}
+
+func newTokenAuthorizer() *tokenAuthorizer {
+	return &tokenAuthorizer{
+		channelTokens: make(map[string]string),
+	}
+}
+
+// Bind binds the shard to the token. The driver binds a shard again when it
+// allocates the shard of the same name to a later flow, e.g. a cached dataset.
+func (a *tokenAuthorizer) Bind(channelName, accessToken string) error {
+	a.Lock()
+	defer a.Unlock()
+
+	a.channelTokens[channelName] = accessToken
+	return nil
+}
+
+func (a *tokenAuthorizer) AuthorizeWrite(channelName, accessToken string) error {
+	return a.authorize("writing", channelName, accessToken)
+}
+
+func (a *tokenAuthorizer) AuthorizeRead(channelName, accessToken string) error {
+	return a.authorize("reading", channelName, accessToken)
+}
+
+func (a *tokenAuthorizer) authorize(action, channelName, accessToken string) error {
+	a.Lock()
+	defer a.Unlock()
+
+	token, ok := a.channelTokens[channelName]
+	if !ok {
+		return fmt.Errorf("%s is not allocated when %s it", channelName, action)
+	}
+	if token != accessToken {
+		return fmt.Errorf("access token mismatch when %s %s", action, channelName)
+	}
+	return nil
+}
+
+func (a *tokenAuthorizer) Forget(channelName string) {
+	a.Lock()
+	defer a.Unlock()
+
+	delete(a.channelTokens, channelName)
+}
@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestTokenAuthorizer(t *testing.T) {
+	a := newTokenAuthorizer()
+
+	if err := a.AuthorizeWrite("f-d1-s0", ""); err == nil {
+		t.Errorf("wrote a shard not allocated by the driver")
+	}
+	if err := a.AuthorizeRead("f-d1-s0", ""); err == nil {
+		t.Errorf("read a shard not allocated by the driver")
+	}
+
+	a.Bind("f-d1-s0", "token")
+	if err := a.AuthorizeWrite("f-d1-s0", "other"); err == nil {
+		t.Errorf("wrote with another token")
+	}
+	if err := a.AuthorizeWrite("f-d1-s0", "token"); err != nil {
+		t.Errorf("write with the bound token: %v", err)
+	}
+	if err := a.AuthorizeRead("f-d1-s0", "other"); err == nil {
+		t.Errorf("read with another token")
+	}
+	if err := a.AuthorizeRead("f-d1-s0", "token"); err != nil {
+		t.Errorf("read with the bound token: %v", err)
+	}
+
+	a.Forget("f-d1-s0")
+	if err := a.AuthorizeRead("f-d1-s0", "token"); err == nil {
+		t.Errorf("read a forgotten shard")
+	}
+}
+
+func TestRejectReadBeforeWaiting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	as := &AgentServer{
+		storageBackend:   NewLocalDatasetShardsManager(dir, 45327, false),
+		inMemoryChannels: NewLocalDatasetShardsManagerInMemory(),
+		authorizer:       newTokenAuthorizer(),
+	}
+	as.authorizer.Bind("f-d1-s0", "token")
+
+	for _, onDisk := range []bool{true, false} {
+		server, client := net.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer server.Close()
+			if onDisk {
+				as.handleReadConnection(server, "reader", "f-d1-s0", "other", nil, false)
+			} else {
+				as.handleInMemoryReadConnection(server, "reader", "f-d1-s0", "other", nil)
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("onDisk=%v: waited for the shard before rejecting the reader", onDisk)
+		}
+		client.Close()
+	}
+}
+
+func TestAuthorizeMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	as := &AgentServer{
+		storageBackend:   NewLocalDatasetShardsManager(dir, 45327, false),
+		inMemoryChannels: NewLocalDatasetShardsManagerInMemory(),
+		authorizer:       newTokenAuthorizer(),
+		loadTracker:      &agentLoadTracker{},
+		flowThrottles:    newFlowThrottles(),
+		Option:           &AgentServerOption{},
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go as.serveTcp(listener)
+	address := listener.Addr().String()
+
+	as.authorizer.Bind("f-d1-s0", "token")
+	ds := as.storageBackend.CreateNamedDatasetShard("f-d1-s0", 2)
+
+	if _, err := netchan.DialMapChannel(context.Background(), "reader", address, "f-d1-s0", "other"); err == nil {
+		t.Fatalf("mapped the shard with another token")
+	}
+
+	for reader := 1; reader <= 2; reader++ {
+		channel, err := netchan.DialMapChannel(context.Background(), "reader", address, "f-d1-s0", "token")
+		if err != nil {
+			t.Fatalf("reader %d: map the shard: %v", reader, err)
+		}
+		if readers := activeReaders(as.storageBackend, ds); readers != 1 {
+			t.Errorf("reader %d: %d active readers while mapping the shard", reader, readers)
+		}
+		if err := channel.Close(true); err != nil {
+			t.Fatalf("reader %d: close the mapped shard: %v", reader, err)
+		}
+		waitFor(t, func() bool { return activeReaders(as.storageBackend, ds) == 0 })
+		if reader == 1 && !hasShard(as.storageBackend, "f-d1-s0") {
+			t.Fatalf("deleted the shard before its last reader")
+		}
+	}
+	// the last reader deletes the shard
+	waitFor(t, func() bool { return !hasShard(as.storageBackend, "f-d1-s0") })
+	if err := as.authorizer.AuthorizeRead("f-d1-s0", "token"); err == nil {
+		t.Errorf("read the deleted shard")
+	}
+}
+
+func hasShard(m *LocalDatasetShardsManager, name string) bool {
+	m.Lock()
+	defer m.Unlock()
+	_, found := m.name2Store[name]
+	return found
+}
+
+func activeReaders(m *LocalDatasetShardsManager, ds store.DataStore) int {
+	m.Lock()
+	defer m.Unlock()
+	return m.activeReaders[ds]
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for deadline := time.Now().Add(time.Second); !condition(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out")
+		}
+	}
+}
+
+func TestRejectOldReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	as := &AgentServer{
+		storageBackend:   NewLocalDatasetShardsManager(dir, 45327, false),
+		inMemoryChannels: NewLocalDatasetShardsManagerInMemory(),
+		authorizer:       newTokenAuthorizer(),
+	}
+	as.authorizer.Bind("f-d1-s0", "token")
+
+	// the readers before the abort message would read it as a message length
+	for _, version := range []int32{0, pb.StreamEndProtocolVersion - 1} {
+		server, client := net.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer server.Close()
+			as.handleCommandConnection(server, &pb.ControlMessage{
+				ProtocolVersion: version,
+				IsOnDiskIO:      true,
+				ReadRequest:     &pb.ReadRequest{ReaderName: "reader", ChannelName: "f-d1-s0", AccessToken: "token"},
+			})
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("version %d: waited for the shard instead of rejecting the reader", version)
+		}
+		client.Close()
+	}
+}
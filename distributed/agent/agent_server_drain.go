@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// drainOnTerminate stops taking new tasks on SIGTERM, waits for the running
+// tasks up to the timeout, deregisters from the master, and exits. Cluster
+// managers, e.g. Kubernetes when scaling down, send SIGTERM before killing
+// the agent.
+func (as *AgentServer) drainOnTerminate(timeout time.Duration) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGTERM)
+	<-signalChan
+
+	logger.Infof("Draining, waiting up to %v for running tasks", timeout)
+	atomic.StoreInt32(&as.draining, 1)
+	select {
+	case as.allocatedHasChanges <- struct{}{}:
+	default:
+	}
+
+	for deadline := time.Now().Add(timeout); as.isBusy() && time.Now().Before(deadline); {
+		time.Sleep(time.Second)
+	}
+
+	close(as.deregister)
+	select {
+	case <-as.deregistered:
+	case <-time.After(10 * time.Second):
+	}
+	os.Exit(0)
+}
+
+func (as *AgentServer) isBusy() bool {
+	as.allocatedResourceLock.Lock()
+	defer as.allocatedResourceLock.Unlock()
+	return atomic.LoadInt32(&as.loadTracker.runningTasks) > 0 || !as.allocatedResource.IsZero()
+}
+
+// offeredResource is the resource reported to the master, none when draining.
+func (as *AgentServer) offeredResource() *pb.ComputeResource {
+	if atomic.LoadInt32(&as.draining) == 1 {
+		return &pb.ComputeResource{CpuLevel: as.computeResource.CpuLevel}
+	}
+	return as.computeResource
+}
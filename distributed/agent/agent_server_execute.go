@@ -3,15 +3,15 @@ package agent
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"sync"
 	"syscall"
 
-	"github.com/lovelly/gleam/pb"
 	"github.com/golang/protobuf/proto"
 	"github.com/kardianos/osext"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func (as *AgentServer) executeCommand(
@@ -21,6 +21,7 @@ func (as *AgentServer) executeCommand(
 	statChan chan *pb.ExecutionStat,
 ) (err error) {
 
+	log := taskLogger(startRequest.GetInstructionSet())
 	stopChan := make(chan bool)
 
 	// start the command
@@ -39,26 +40,30 @@ func (as *AgentServer) executeCommand(
 		"--note",
 		startRequest.GetInstructionSet().GetName(),
 	)
+	if as.Option.SecretsProvider != nil && *as.Option.SecretsProvider != "" {
+		command.Args = append(command.Args, "--secrets", *as.Option.SecretsProvider)
+	}
+	command.Args = append(command.Args, logger.Args()...)
 	stdin, err := command.StdinPipe()
 	if err != nil {
-		log.Printf("Failed to create stdin pipe: %v", err)
+		log.Errorf("Failed to create stdin pipe: %v", err)
 		return
 	}
 	stdout, err := command.StdoutPipe()
 	if err != nil {
-		log.Printf("Failed to create stdout pipe: %v", err)
+		log.Errorf("Failed to create stdout pipe: %v", err)
 		return
 	}
 	stderr, err := command.StderrPipe()
 	if err != nil {
-		log.Printf("Failed to create stderr pipe: %v", err)
+		log.Errorf("Failed to create stderr pipe: %v", err)
 		return
 	}
 	// msg.Env = startRequest.Envs
 	command.Dir = dir
 
 	if err = command.Start(); err != nil {
-		log.Printf("Failed to start command %s under %s: %v",
+		log.Errorf("Failed to start command %s under %s: %v",
 			command.Path, command.Dir, err)
 		return err
 	}
@@ -87,23 +92,23 @@ func (as *AgentServer) executeCommand(
 	// send instruction set to executor
 	msgMessageBytes, err := proto.Marshal(startRequest.GetInstructionSet())
 	if err != nil {
-		log.Printf("Failed to marshal command %s: %v",
+		log.Errorf("Failed to marshal command %s: %v",
 			startRequest.GetInstructionSet().String(), err)
 		return err
 	}
 	if _, err = stdin.Write(msgMessageBytes); err != nil {
-		log.Printf("Failed to write command: %v", err)
+		log.Errorf("Failed to write command: %v", err)
 		return err
 	}
 	if err = stdin.Close(); err != nil {
-		log.Printf("Failed to close command: %v", err)
+		log.Errorf("Failed to close command: %v", err)
 		return err
 	}
 
 	// wait for finish
 	waitErr := command.Wait()
 	if waitErr != nil {
-		log.Printf("Failed to run command %s: %v", startRequest.GetInstructionSet().GetName(), waitErr)
+		log.Errorf("Failed to run command %s: %v", startRequest.GetInstructionSet().GetName(), waitErr)
 	}
 
 	close(stopChan)
@@ -209,3 +214,13 @@ func sendExitStats(stream pb.GleamAgent_ExecuteServer, cmd *exec.Cmd) error {
 	}
 	return nil
 }
+
+// taskLogger logs with the flow id, and the step and task ids of the first
+// instruction, which identify the task group.
+func taskLogger(instructionSet *pb.InstructionSet) *logger.Logger {
+	var stepId, taskId int32
+	if instructions := instructionSet.GetInstructions(); len(instructions) > 0 {
+		stepId, taskId = instructions[0].GetStepId(), instructions[0].GetTaskId()
+	}
+	return logger.ForTask(instructionSet.GetFlowHashCode(), stepId, taskId)
+}
@@ -0,0 +1,293 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kardianos/osext"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// executorPool keeps the executors started by "gleam execute --pooled" after
+// their tasks, to run the later tasks of the same flow without starting new
+// executor processes.
+type executorPool struct {
+	sync.Mutex
+	idleTime time.Duration
+	idle     map[string][]*pooledExecutor // by the executor directory
+}
+
+type pooledExecutor struct {
+	dir          string
+	flowHashCode uint32
+	command      *exec.Cmd
+	stdin        io.WriteCloser
+	stdout       io.Reader
+	idleTimer    *time.Timer
+
+	streamLock sync.Mutex
+	stream     pb.GleamAgent_ExecuteServer // of the current task
+}
+
+func newExecutorPool(idleTime time.Duration) *executorPool {
+	return &executorPool{
+		idleTime: idleTime,
+		idle:     make(map[string][]*pooledExecutor),
+	}
+}
+
+// isPoolable checks whether the executor can be reused after the instruction
+// set. The secrets are set in the executor environment, and the profiles are
+// written when the executor exits.
+func isPoolable(instructionSet *pb.InstructionSet) bool {
+	if instructionSet.GetIsProfiling() {
+		return false
+	}
+	for _, instruction := range instructionSet.GetInstructions() {
+		if len(instruction.GetSecretEnvs()) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// take returns an idle executor started in the directory, or nil.
+func (p *executorPool) take(dir string) *pooledExecutor {
+	p.Lock()
+	defer p.Unlock()
+	executors := p.idle[dir]
+	if len(executors) == 0 {
+		return nil
+	}
+	e := executors[len(executors)-1]
+	if len(executors) == 1 {
+		delete(p.idle, dir)
+	} else {
+		p.idle[dir] = executors[:len(executors)-1]
+	}
+	e.idleTimer.Stop()
+	return e
+}
+
+// put keeps the executor for the idle time.
+func (p *executorPool) put(e *pooledExecutor) {
+	p.Lock()
+	defer p.Unlock()
+	p.idle[e.dir] = append(p.idle[e.dir], e)
+	e.idleTimer = time.AfterFunc(p.idleTime, func() {
+		if p.remove(e) {
+			e.stop()
+		}
+	})
+}
+
+func (p *executorPool) remove(e *pooledExecutor) bool {
+	p.Lock()
+	defer p.Unlock()
+	executors := p.idle[e.dir]
+	for i, x := range executors {
+		if x == e {
+			p.idle[e.dir] = append(executors[:i], executors[i+1:]...)
+			if len(p.idle[e.dir]) == 0 {
+				delete(p.idle, e.dir)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// closeFlow stops the idle executors of the flow.
+func (p *executorPool) closeFlow(flowHashCode uint32) {
+	p.Lock()
+	var stopped []*pooledExecutor
+	for dir, executors := range p.idle {
+		if executors[0].flowHashCode != flowHashCode {
+			continue
+		}
+		for _, e := range executors {
+			e.idleTimer.Stop()
+			stopped = append(stopped, e)
+		}
+		delete(p.idle, dir)
+	}
+	p.Unlock()
+
+	for _, e := range stopped {
+		e.stop()
+	}
+}
+
+func (as *AgentServer) startPooledExecutor(dir string, flowHashCode uint32) (*pooledExecutor, error) {
+	executableFullFilename, _ := osext.Executable()
+
+	command := exec.Command(
+		executableFullFilename,
+		"execute",
+		"--pooled",
+		"--dir",
+		dir,
+		"--note",
+		fmt.Sprintf("flow %d", flowHashCode),
+	)
+	if as.Option.SecretsProvider != nil && *as.Option.SecretsProvider != "" {
+		command.Args = append(command.Args, "--secrets", *as.Option.SecretsProvider)
+	}
+	command.Args = append(command.Args, logger.Args()...)
+	command.Dir = dir
+
+	e := &pooledExecutor{
+		dir:          dir,
+		flowHashCode: flowHashCode,
+		command:      command,
+	}
+	var err error
+	if e.stdin, err = command.StdinPipe(); err != nil {
+		return nil, fmt.Errorf("Failed to create stdin pipe: %v", err)
+	}
+	if e.stdout, err = command.StdoutPipe(); err != nil {
+		return nil, fmt.Errorf("Failed to create stdout pipe: %v", err)
+	}
+	stderr, err := command.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create stderr pipe: %v", err)
+	}
+	if err = command.Start(); err != nil {
+		return nil, fmt.Errorf("Failed to start command %s under %s: %v", command.Path, command.Dir, err)
+	}
+	go e.forwardError(stderr)
+
+	return e, nil
+}
+
+// executePooled runs the instruction set on an idle executor of the flow, or
+// a new one, and keeps the executor for the later tasks if it succeeds.
+func (as *AgentServer) executePooled(
+	stream pb.GleamAgent_ExecuteServer,
+	startRequest *pb.ExecutionRequest,
+	dir string,
+	statChan chan *pb.ExecutionStat,
+) error {
+
+	instructionSet := startRequest.GetInstructionSet()
+	log := taskLogger(instructionSet)
+	e := as.executorPool.take(dir)
+	if e == nil {
+		var err error
+		if e, err = as.startPooledExecutor(dir, instructionSet.GetFlowHashCode()); err != nil {
+			log.Errorf("%v", err)
+			return err
+		}
+	}
+	e.setStream(stream)
+
+	stopChan := make(chan bool)
+	go func() {
+		select {
+		case <-stream.Context().Done():
+			e.command.Process.Signal(syscall.SIGTERM)
+		case <-stopChan:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go streamPulse(&wg, stopChan, statChan, stream)
+
+	response, err := e.execute(instructionSet)
+
+	close(stopChan)
+	wg.Wait()
+	e.setStream(nil)
+
+	if err != nil {
+		log.Errorf("Failed to run %s: %v", instructionSet.GetName(), err)
+		e.stop()
+		return err
+	}
+
+	if sendErr := stream.Send(&pb.ExecutionResponse{
+		SystemTime: response.GetSystemTime(),
+		UserTime:   response.GetUserTime(),
+	}); sendErr != nil {
+		log.Errorf("Failed to send exit stats response: %v", sendErr)
+	}
+
+	if response.GetError() != nil {
+		// the executor may have left the failed instructions running
+		e.stop()
+		stream.Send(&pb.ExecutionResponse{
+			Error: response.GetError(),
+		})
+		return fmt.Errorf("%s", response.GetError())
+	}
+
+	as.executorPool.put(e)
+	return nil
+}
+
+func (e *pooledExecutor) execute(instructionSet *pb.InstructionSet) (*pb.ExecutionResponse, error) {
+	data, err := proto.Marshal(instructionSet)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal command %s: %v", instructionSet.String(), err)
+	}
+	if err = util.WriteMessage(e.stdin, data); err != nil {
+		return nil, fmt.Errorf("Failed to write command: %v", err)
+	}
+	data, err = util.ReadMessage(e.stdout)
+	if err == io.EOF {
+		return nil, fmt.Errorf("executor exited")
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ExecutionResponse{}
+	if err = proto.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal execution response: %v", err)
+	}
+	return response, nil
+}
+
+func (e *pooledExecutor) setStream(stream pb.GleamAgent_ExecuteServer) {
+	e.streamLock.Lock()
+	e.stream = stream
+	e.streamLock.Unlock()
+}
+
+// forwardError sends the stderr of the executor to the current task, and logs
+// it as the executors started for one task do.
+func (e *pooledExecutor) forwardError(reader io.Reader) {
+	tee := io.TeeReader(reader, os.Stderr)
+
+	buffer := make([]byte, 1024)
+	for {
+		n, err := tee.Read(buffer)
+		if err != nil {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		e.streamLock.Lock()
+		if e.stream != nil {
+			e.stream.Send(&pb.ExecutionResponse{
+				Error: append([]byte{}, buffer[0:n]...),
+			})
+		}
+		e.streamLock.Unlock()
+	}
+}
+
+// stop lets the executor exit after reading the end of its input.
+func (e *pooledExecutor) stop() {
+	e.stdin.Close()
+	go e.command.Wait()
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func newTestExecutor(dir string, flowHashCode uint32) (*pooledExecutor, *io.PipeReader) {
+	stdinReader, stdinWriter := io.Pipe()
+	return &pooledExecutor{
+		dir:          dir,
+		flowHashCode: flowHashCode,
+		command:      exec.Command("true"),
+		stdin:        stdinWriter,
+	}, stdinReader
+}
+
+func isStopped(stdin *io.PipeReader) bool {
+	done := make(chan bool)
+	go func() {
+		_, err := stdin.Read(make([]byte, 1))
+		done <- err == io.EOF
+	}()
+	select {
+	case stopped := <-done:
+		return stopped
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestExecutorPoolReuse(t *testing.T) {
+	p := newExecutorPool(time.Hour)
+
+	e, _ := newTestExecutor("/data/1/a", 1)
+	p.put(e)
+
+	if p.take("/data/1/b") != nil {
+		t.Errorf("took an executor of another directory")
+	}
+	if p.take("/data/1/a") != e {
+		t.Errorf("the idle executor is not reused")
+	}
+	if p.take("/data/1/a") != nil {
+		t.Errorf("the executor is reused twice")
+	}
+}
+
+func TestExecutorPoolIdleTime(t *testing.T) {
+	p := newExecutorPool(10 * time.Millisecond)
+
+	e, stdin := newTestExecutor("/data/1/a", 1)
+	p.put(e)
+
+	if !isStopped(stdin) {
+		t.Fatalf("the idle executor is not stopped")
+	}
+	if p.take("/data/1/a") != nil {
+		t.Errorf("took a stopped executor")
+	}
+}
+
+func TestExecutorPoolCloseFlow(t *testing.T) {
+	p := newExecutorPool(time.Hour)
+
+	e1, stdin1 := newTestExecutor("/data/1/a", 1)
+	e2, _ := newTestExecutor("/data/2/a", 2)
+	p.put(e1)
+	p.put(e2)
+
+	p.closeFlow(1)
+
+	if !isStopped(stdin1) {
+		t.Errorf("the executor of the cleaned up flow is not stopped")
+	}
+	if p.take("/data/2/a") != e2 {
+		t.Errorf("the executor of another flow is stopped")
+	}
+}
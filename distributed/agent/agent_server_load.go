@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/pb"
+)
+
+// meteredConn counts the bytes going through the agent's data connections,
+// so the agent can report its network saturation to the master.
+// Writes are also limited by the agent's bandwidth throttle, if any.
+type meteredConn struct {
+	net.Conn
+	counter  *int64
+	throttle *netchan.Throttle
+}
+
+func (c *meteredConn) Read(p []byte) (n int, err error) {
+	n, err = c.Conn.Read(p)
+	atomic.AddInt64(c.counter, int64(n))
+	return
+}
+
+func (c *meteredConn) Write(p []byte) (n int, err error) {
+	c.throttle.Wait(len(p))
+	n, err = c.Conn.Write(p)
+	atomic.AddInt64(c.counter, int64(n))
+	return
+}
+
+// agentLoadTracker keeps the counters reported in each heartbeat.
+type agentLoadTracker struct {
+	transferredBytes int64
+	runningTasks     int32
+
+	lastTransferredBytes int64
+	lastReportTime       time.Time
+}
+
+func (as *AgentServer) collectLoad() *pb.AgentLoad {
+	now := time.Now()
+	transferred := atomic.LoadInt64(&as.loadTracker.transferredBytes)
+
+	var bytesPerSecond int64
+	if !as.loadTracker.lastReportTime.IsZero() {
+		if elapsed := now.Sub(as.loadTracker.lastReportTime).Seconds(); elapsed > 0 {
+			bytesPerSecond = int64(float64(transferred-as.loadTracker.lastTransferredBytes) / elapsed)
+		}
+	}
+	as.loadTracker.lastTransferredBytes = transferred
+	as.loadTracker.lastReportTime = now
+
+	return &pb.AgentLoad{
+		RunningTasks:          atomic.LoadInt32(&as.loadTracker.runningTasks),
+		DiskUsedMb:            as.storageBackend.DiskUsage() / 1024 / 1024,
+		NetworkBytesPerSecond: bytesPerSecond,
+	}
+}
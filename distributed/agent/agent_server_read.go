@@ -4,22 +4,51 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
-	"log"
 	"net"
+	"time"
 
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
-func (as *AgentServer) handleReadConnection(conn net.Conn, readerName, channelName string) {
+func (as *AgentServer) handleReadConnection(conn net.Conn, readerName, channelName, accessToken string, shardRange *pb.ShardRange, mapLocally bool) {
 
-	log.Printf("on disk %s waits for %s", readerName, channelName)
+	if err := as.authorizer.AuthorizeRead(channelName, accessToken); err != nil {
+		logger.Errorf("on disk %s rejected: %v", readerName, err)
+		return
+	}
+
+	logger.Debugf("on disk %s waits for %s", readerName, channelName)
 
 	dsStore := as.storageBackend.WaitForNamedDatasetShard(channelName)
+	defer as.storageBackend.StopReading(dsStore)
+
+	if mapLocally {
+		as.handleMapConnection(conn, readerName, channelName)
+		return
+	}
+
+	filter, err := newShardRangeFilter(shardRange)
+	if err != nil {
+		logger.Errorf("on disk %s failed to read %s: %v", readerName, channelName, err)
+		return
+	}
 
-	log.Printf("on disk %s starts reading %s", readerName, channelName)
+	logger.Debugf("on disk %s starts reading %s", readerName, channelName)
 
-	var offset int64
-	var err error
+	var offset, rowIndex int64
+
+	var index *store.BlockIndex
+	if s, ok := dsStore.(store.IndexedDataStore); ok && filter != nil {
+		index = s.Index()
+	}
+	if index != nil {
+		if block, found := index.SeekRow(filter.startRow); found {
+			offset, rowIndex = block.Offset, block.StartRow
+		}
+	}
 
 	var size int32
 	sizeBuf := make([]byte, 4)
@@ -29,14 +58,41 @@ func (as *AgentServer) handleReadConnection(conn net.Conn, readerName, channelNa
 	messageBytesCache := make([]byte, util.BUFFER_SIZE)
 	var messageBytes []byte
 
+	// the decompressed messages of a compressed block
+	var blockBytes []byte
+
 	messageWriter := util.NewBufferedMessageWriter(conn, util.BUFFER_SIZE)
+	sendMessage := func(message []byte) error {
+		rowIndex++
+		if filter != nil && !filter.accepts(rowIndex-1, message) {
+			return nil
+		}
+		if err := messageWriter.WriteMessage(message); err != nil {
+			logger.Errorf("%s failed to receive %s at %d: %v", readerName, channelName, offset, err)
+			return err
+		}
+		count += int64(len(message))
+		return nil
+	}
+
 	// loop for every read
 	for {
+		if filter != nil && filter.isDone(rowIndex) {
+			break
+		}
+		if index != nil {
+			if block, found := index.BlockAt(offset); found && filter.skipsBlock(block) {
+				offset += block.Size
+				rowIndex += block.RowCount
+				continue
+			}
+		}
+
 		_, err = dsStore.ReadAt(sizeBuf, offset)
 		if err != nil {
 			// connection is closed
 			if err != io.EOF {
-				log.Printf("Read size from %s offset %d: %v", channelName, offset, err)
+				logger.Errorf("Read size from %s offset %d: %v", channelName, offset, err)
 			}
 			// println("got problem reading", channelName, offset, err.Error())
 			break
@@ -47,9 +103,22 @@ func (as *AgentServer) handleReadConnection(conn net.Conn, readerName, channelNa
 		if size == int32(util.MessageControlEOF) {
 			break
 		}
+		if size == int32(util.MessageControlAbort) {
+			// pass on to the reader, instead of ending the shard early
+			if err = messageWriter.Flush(); err == nil {
+				util.WriteAbortMessage(conn)
+				err = util.ErrAborted
+			}
+			break
+		}
 
 		// println("reading", channelName, offset, "size:", size)
 
+		isCompressed := size < 0
+		if isCompressed {
+			size = -size
+		}
+
 		offset += 4
 		if size > util.BUFFER_SIZE {
 			messageBytes = make([]byte, size)
@@ -60,26 +129,91 @@ func (as *AgentServer) handleReadConnection(conn net.Conn, readerName, channelNa
 		if err != nil {
 			// connection is closed
 			if err != io.EOF {
-				log.Printf("Read data from %s offset %d: %v", channelName, offset, err)
+				logger.Errorf("Read data from %s offset %d: %v", channelName, offset, err)
 			}
 			break
 		}
 		offset += int64(size)
 
-		err = messageWriter.WriteMessage(messageBytes)
+		if !isCompressed {
+			if err = sendMessage(messageBytes); err != nil {
+				break
+			}
+			continue
+		}
+
+		if blockBytes, err = store.DecompressBlock(blockBytes[:0], messageBytes); err != nil {
+			logger.Errorf("Read block from %s offset %d: %v", channelName, offset, err)
+			break
+		}
+		for block := blockBytes; len(block) >= 4 && err == nil; {
+			if filter != nil && filter.isDone(rowIndex) {
+				break
+			}
+			length := int(binary.LittleEndian.Uint32(block[0:4]))
+			err = sendMessage(block[4 : 4+length])
+			block = block[4+length:]
+		}
 		if err != nil {
-			log.Printf("%s failed to receive %s at %d: %v", readerName, channelName, offset, err)
 			break
 		}
 
-		count += int64(size)
-
 	}
-	messageWriter.Flush()
+	if flushErr := messageWriter.Flush(); err == nil {
+		err = flushErr
+	}
 
 	if err != nil {
-		log.Printf("on disk %s finished reading %s %d bytes error: %v", readerName, channelName, count, err)
+		logger.Errorf("on disk %s finished reading %s %d bytes error: %v", readerName, channelName, count, err)
 	} else {
-		log.Printf("on disk %s finished reading %s %d bytes", readerName, channelName, count)
+		logger.Infof("on disk %s finished reading %s %d bytes", readerName, channelName, count)
+		as.finishReading(channelName)
+	}
+}
+
+// handleMapConnection lets the reader on this machine map the shard file,
+// and keeps the shard until the reader reports the end of reading.
+func (as *AgentServer) handleMapConnection(conn net.Conn, readerName, channelName string) {
+
+	if err := util.WriteEOFMessage(conn); err != nil {
+		logger.Errorf("mmap %s failed to start reading %s: %v", readerName, channelName, err)
+		return
+	}
+
+	logger.Debugf("mmap %s starts reading %s", readerName, channelName)
+
+	if _, err := util.ReadStreamMessage(conn); err != io.EOF {
+		logger.Errorf("mmap %s failed reading %s: %v", readerName, channelName, err)
+		return
+	}
+
+	logger.Infof("mmap %s finished reading %s", readerName, channelName)
+	as.finishReading(channelName)
+}
+
+// finishReading deletes the on disk shard after its last pending reader
+// finishes, or DatasetTTL later.
+func (as *AgentServer) finishReading(channelName string) {
+	ds, isLast := as.storageBackend.FinishReading(channelName)
+	if !isLast {
+		return
+	}
+	deleteShard := func() {
+		logger.Infof("deleting read %s", channelName)
+		as.storageBackend.DeleteFinishedDatasetShard(channelName, ds)
+		as.authorizer.Forget(channelName)
+		as.flowThrottles.forget(channelName)
+	}
+	if ttl := as.datasetTTL(); ttl > 0 {
+		time.AfterFunc(ttl, deleteShard)
+		return
+	}
+	deleteShard()
+}
+
+func (as *AgentServer) datasetTTL() time.Duration {
+	if as.Option.DatasetTTL == nil {
+		return 0
 	}
+	return *as.Option.DatasetTTL
 }
@@ -3,38 +3,86 @@ package agent
 import (
 	"bufio"
 	"io"
-	"log"
 	"net"
 
+	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
-func (as *AgentServer) handleInMemoryReadConnection(conn net.Conn, readerName, channelName string) {
+func (as *AgentServer) handleInMemoryReadConnection(conn net.Conn, readerName, channelName, accessToken string, shardRange *pb.ShardRange) {
 
-	log.Printf("in memory %s waits for %s", readerName, channelName)
+	if err := as.authorizer.AuthorizeRead(channelName, accessToken); err != nil {
+		logger.Errorf("in memory %s rejected: %v", readerName, err)
+		return
+	}
+
+	logger.Debugf("in memory %s waits for %s", readerName, channelName)
 
 	ch := as.inMemoryChannels.WaitForNamedDatasetShard(channelName)
 
 	if ch == nil {
-		log.Printf("in memory %s read an empty %s", readerName, channelName)
+		logger.Infof("in memory %s read an empty %s", readerName, channelName)
+		return
+	}
+
+	filter, err := newShardRangeFilter(shardRange)
+	if err != nil {
+		logger.Errorf("in memory %s failed to read %s: %v", readerName, channelName, err)
+		return
+	}
+	if filter != nil {
+		as.handleInMemoryRangeRead(conn, readerName, channelName, ch, filter)
 		return
 	}
 
 	writer := bufio.NewWriter(conn)
 	defer writer.Flush()
 
-	log.Printf("in memory %s start reading %s", readerName, channelName)
+	logger.Debugf("in memory %s start reading %s", readerName, channelName)
 	buf := make([]byte, util.BUFFER_SIZE)
 	count, err := io.CopyBuffer(writer, ch.Reader, buf)
 
 	if err == nil {
 		if ch.Error != nil {
-			log.Printf("in memory %s failed because writing to %s failed: %d %v", readerName, channelName, count, ch.Error)
+			logger.Errorf("in memory %s failed because writing to %s failed: %d %v", readerName, channelName, count, ch.Error)
 		} else {
-			log.Printf("in memory %s finished reading %s %d bytes", readerName, channelName, count)
+			logger.Infof("in memory %s finished reading %s %d bytes", readerName, channelName, count)
 		}
 	} else {
-		log.Printf("in memory %s failed reading %s %d bytes %v", readerName, channelName, count, err)
+		logger.Errorf("in memory %s failed reading %s %d bytes %v", readerName, channelName, count, err)
+	}
+
+}
+
+// handleInMemoryRangeRead sends only the rows in the range. In memory shards
+// have no index, so the whole shard is still consumed.
+func (as *AgentServer) handleInMemoryRangeRead(conn net.Conn, readerName, channelName string, ch *util.Piper, filter *shardRangeFilter) {
+
+	logger.Debugf("in memory %s start reading part of %s", readerName, channelName)
+
+	messageWriter := util.NewBufferedMessageWriter(conn, util.BUFFER_SIZE)
+	defer messageWriter.Flush()
+
+	var rowIndex, count int64
+	var writeErr error
+	err := util.ProcessMessage(ch.Reader, func(message []byte) error {
+		rowIndex++
+		if writeErr != nil || !filter.accepts(rowIndex-1, message) {
+			return nil
+		}
+		if writeErr = messageWriter.WriteMessage(message); writeErr == nil {
+			count += int64(len(message))
+		}
+		return nil
+	})
+	if err == nil {
+		err = writeErr
 	}
 
+	if err != nil {
+		logger.Errorf("in memory %s failed reading %s %d bytes %v", readerName, channelName, count, err)
+	} else {
+		logger.Infof("in memory %s finished reading %s %d bytes", readerName, channelName, count)
+	}
 }
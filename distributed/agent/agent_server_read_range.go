@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+const shardIndexBlockSize = 1024 * 1024
+
+// shardRangeFilter selects the rows of a partial read.
+type shardRangeFilter struct {
+	startRow int64
+	stopRow  int64
+	startKey []interface{}
+	stopKey  []interface{}
+}
+
+// newShardRangeFilter returns nil if the whole shard should be read.
+func newShardRangeFilter(r *pb.ShardRange) (f *shardRangeFilter, err error) {
+	if r == nil {
+		return nil, nil
+	}
+	f = &shardRangeFilter{
+		startRow: r.GetStartRow(),
+		stopRow:  r.GetStopRow(),
+	}
+	if len(r.GetStartKey()) > 0 {
+		if f.startKey, err = util.DecodeKeys(r.GetStartKey()); err != nil {
+			return nil, fmt.Errorf("Failed to decode start key: %v", err)
+		}
+	}
+	if len(r.GetStopKey()) > 0 {
+		if f.stopKey, err = util.DecodeKeys(r.GetStopKey()); err != nil {
+			return nil, fmt.Errorf("Failed to decode stop key: %v", err)
+		}
+	}
+	if f.startRow == 0 && f.stopRow == 0 && f.startKey == nil && f.stopKey == nil {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func (f *shardRangeFilter) hasKeyRange() bool {
+	return f.startKey != nil || f.stopKey != nil
+}
+
+// isDone tells whether no more rows are needed.
+func (f *shardRangeFilter) isDone(rowIndex int64) bool {
+	return f.stopRow > 0 && rowIndex >= f.stopRow
+}
+
+// skipsBlock tells whether none of the rows in the block are needed.
+func (f *shardRangeFilter) skipsBlock(b store.Block) bool {
+	if b.StartRow+b.RowCount <= f.startRow {
+		return true
+	}
+	if !b.HasKeys || b.MinKey == nil {
+		return false
+	}
+	if f.startKey != nil && store.CompareKeys(b.MaxKey, f.startKey) < 0 {
+		return true
+	}
+	if f.stopKey != nil && store.CompareKeys(b.MinKey, f.stopKey) >= 0 {
+		return true
+	}
+	return false
+}
+
+// accepts tells whether the row is in the range.
+func (f *shardRangeFilter) accepts(rowIndex int64, message []byte) bool {
+	if rowIndex < f.startRow || f.isDone(rowIndex) {
+		return false
+	}
+	if !f.hasKeyRange() {
+		return true
+	}
+	row, err := util.DecodeRow(message)
+	if err != nil {
+		return false
+	}
+	if f.startKey != nil && store.CompareKeys(row.K, f.startKey) < 0 {
+		return false
+	}
+	if f.stopKey != nil && store.CompareKeys(row.K, f.stopKey) >= 0 {
+		return false
+	}
+	return true
+}
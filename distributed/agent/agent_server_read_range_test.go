@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestReadShardRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	as := &AgentServer{
+		storageBackend: NewLocalDatasetShardsManager(dir, 45327, true),
+		authorizer:     newTokenAuthorizer(),
+	}
+	as.authorizer.Bind("f-d1-s0", "token")
+
+	// rows of 1KB, so the shard has several index blocks
+	const count = 3000
+	padding := strings.Repeat("x", 1024)
+	var rows [][]byte
+	for i := 0; i < count; i++ {
+		var buf bytes.Buffer
+		if err := util.NewRow(util.Now(), int64(i), padding).WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		message, err := util.ReadMessage(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, message)
+	}
+	as.handleLocalWriteConnection(messages(rows...), "writer", "f-d1-s0", 3, "")
+
+	dsStore := as.storageBackend.WaitForNamedDatasetShard("f-d1-s0")
+	index := dsStore.(store.IndexedDataStore).Index()
+	as.storageBackend.StopReading(dsStore)
+	second, found := index.SeekRow(count - 1)
+	if !found || second.StartRow == 0 {
+		t.Fatalf("the shard of %d rows has one index block", count)
+	}
+
+	for _, test := range []struct {
+		shardRange *pb.ShardRange
+		start      int64
+		stop       int64
+	}{
+		// as read by the LocalLimit() of a LIMIT
+		{&pb.ShardRange{StopRow: 3}, 0, 3},
+		// from a later block, skipping the leading blocks
+		{&pb.ShardRange{StartRow: second.StartRow + 1, StopRow: second.StartRow + 3}, second.StartRow + 1, second.StartRow + 3},
+	} {
+		server, client := net.Pipe()
+		go func() {
+			defer server.Close()
+			as.handleReadConnection(server, "reader", "f-d1-s0", "token", test.shardRange, false)
+		}()
+		var keys []int64
+		for {
+			message, err := util.ReadMessage(client)
+			if err != nil {
+				break
+			}
+			row, err := util.DecodeRow(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, util.ToInt64(row.K[0]))
+		}
+		client.Close()
+		var expected []int64
+		for i := test.start; i < test.stop; i++ {
+			expected = append(expected, i)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("read %v of range %v, expected %v", keys, test.shardRange, expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"sync"
+
+	"github.com/lovelly/gleam/distributed/netchan"
+)
+
+// flowThrottles limit how fast the flows write their shards to this agent.
+// All the tasks of a flow writing to the agent share the flow's throttle.
+type flowThrottles struct {
+	sync.Mutex
+	throttles      map[uint32]*netchan.Throttle
+	channelToFlows map[string]uint32
+}
+
+func newFlowThrottles() *flowThrottles {
+	return &flowThrottles{
+		throttles:      make(map[uint32]*netchan.Throttle),
+		channelToFlows: make(map[string]uint32),
+	}
+}
+
+// add limits the writes of the shards of the flow, keeping the flow's
+// existing throttle.
+func (f *flowThrottles) add(flowHashCode uint32, bytesPerSecond int64, channelNames []string) {
+	if bytesPerSecond <= 0 {
+		return
+	}
+	f.Lock()
+	defer f.Unlock()
+
+	if _, found := f.throttles[flowHashCode]; !found {
+		f.throttles[flowHashCode] = netchan.NewThrottle(bytesPerSecond)
+	}
+	for _, name := range channelNames {
+		f.channelToFlows[name] = flowHashCode
+	}
+}
+
+// forChannel returns the throttle of the flow writing the shard, or nil.
+func (f *flowThrottles) forChannel(channelName string) *netchan.Throttle {
+	f.Lock()
+	defer f.Unlock()
+
+	flowHashCode, found := f.channelToFlows[channelName]
+	if !found {
+		return nil
+	}
+	return f.throttles[flowHashCode]
+}
+
+func (f *flowThrottles) forget(channelName string) {
+	f.Lock()
+	defer f.Unlock()
+
+	delete(f.channelToFlows, channelName)
+}
+
+// removeFlow drops the flow's throttle after the flow is cleaned up.
+func (f *flowThrottles) removeFlow(flowHashCode uint32) {
+	f.Lock()
+	defer f.Unlock()
+
+	delete(f.throttles, flowHashCode)
+	for name, flow := range f.channelToFlows {
+		if flow == flowHashCode {
+			delete(f.channelToFlows, name)
+		}
+	}
+}
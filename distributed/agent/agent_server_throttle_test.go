@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"testing"
+)
+
+func TestFlowThrottles(t *testing.T) {
+	f := newFlowThrottles()
+	f.add(1, 1024, []string{"f1-d1-s0"})
+	f.add(1, 1024, []string{"f1-d2-s0"})
+	f.add(2, 0, []string{"f2-d1-s0"})
+
+	throttle := f.forChannel("f1-d1-s0")
+	if throttle == nil || f.forChannel("f1-d2-s0") != throttle {
+		t.Fatalf("the shards of a flow do not share the flow's throttle")
+	}
+	if f.forChannel("f2-d1-s0") != nil {
+		t.Errorf("throttled a flow without bandwidth limit")
+	}
+
+	f.forget("f1-d1-s0")
+	if f.forChannel("f1-d1-s0") != nil {
+		t.Errorf("throttled a deleted shard")
+	}
+	f.removeFlow(1)
+	if f.forChannel("f1-d2-s0") != nil {
+		t.Errorf("throttled a shard of a cleaned up flow")
+	}
+}
@@ -2,40 +2,104 @@ package agent
 
 import (
 	"io"
-	"log"
 
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
-func (as *AgentServer) handleLocalWriteConnection(reader io.Reader, writerName, channelName string, readerCount int) {
+// messageReader reads the messages written to the agent. The writers since
+// pb.StreamEndProtocolVersion end the stream with the EOF message, so a
+// stream closed without it is incomplete.
+func messageReader(reader io.Reader, protocolVersion int32) func() ([]byte, error) {
+	if protocolVersion < pb.StreamEndProtocolVersion {
+		return func() ([]byte, error) { return util.ReadMessage(reader) }
+	}
+	return func() ([]byte, error) { return util.ReadStreamMessage(reader) }
+}
 
-	dsStore := as.storageBackend.CreateNamedDatasetShard(channelName)
+func (as *AgentServer) handleLocalWriteConnection(readMessage func() ([]byte, error), writerName, channelName string, readerCount int, compression string) {
 
-	log.Printf("on disk %s starts writing %s expected reader:%d", writerName, channelName, readerCount)
+	dsStore := as.storageBackend.CreateNamedDatasetShard(channelName, readerCount)
+
+	logger.Debugf("on disk %s starts writing %s expected reader:%d", writerName, channelName, readerCount)
 
 	var count int64
 
-	messageWriter := util.NewBufferedMessageWriter(dsStore, util.BUFFER_SIZE)
+	var indexer *store.BlockIndexer
+	if s, ok := dsStore.(store.IndexedDataStore); ok && s.Index() != nil {
+		indexer = store.NewBlockIndexer(s.Index(), shardIndexBlockSize)
+	}
+
+	codec, isAuto, err := store.ParseCompression(compression)
+	if err != nil {
+		logger.Warnf("on disk %s writes %s uncompressed: %v", writerName, channelName, err)
+	}
+
+	// the compressed shards are written in blocks, cut the same as the index
+	var writeMessage func([]byte) error
+	var flush func() error
+	var blockWriter *store.BlockWriter
+	if codec != nil {
+		blockWriter = store.NewBlockWriter(dsStore, codec, isAuto, indexer, shardIndexBlockSize)
+		writeMessage, flush = blockWriter.WriteMessage, blockWriter.Flush
+	} else {
+		messageWriter := util.NewBufferedMessageWriter(dsStore, util.BUFFER_SIZE)
+		writeMessage = func(message []byte) error {
+			if err := messageWriter.WriteMessage(message); err != nil {
+				return err
+			}
+			if indexer != nil && indexer.Add(message) {
+				if err := messageWriter.Flush(); err != nil {
+					return err
+				}
+				indexer.Flush()
+			}
+			return nil
+		}
+		flush = func() error {
+			err := messageWriter.Flush()
+			if indexer != nil {
+				indexer.Flush()
+			}
+			return err
+		}
+	}
 
 	for {
 
-		message, err := util.ReadMessage(reader)
-		if err == io.EOF {
-			// println("agent recv eof:", string(message.Bytes()))
+		var message []byte
+		message, err = readMessage()
+		if err != nil {
 			break
 		}
-		if err == nil {
-			count += int64(len(message))
-			messageWriter.WriteMessage(message)
-			// println("agent recv:", string(message.Bytes()))
-		} else {
-			log.Printf("on disk %s Failed to write to %s: %v", writerName, channelName, err)
+		count += int64(len(message))
+		if err = writeMessage(message); err != nil {
+			break
 		}
 	}
 
-	messageWriter.Flush()
-	util.WriteEOFMessage(dsStore)
+	// a shard not stored completely, e.g. on a full disk, is aborted
+	if flushErr := flush(); flushErr != nil && err == io.EOF {
+		err = flushErr
+	}
+	if err == io.EOF {
+		util.WriteEOFMessage(dsStore)
+	} else {
+		// the readers fail instead of reading part of the shard
+		util.WriteAbortMessage(dsStore)
+	}
+	as.storageBackend.FinishWriting(channelName, dsStore, err == io.EOF)
 
-	log.Printf("on disk %s finished writing %s %d bytes", writerName, channelName, count)
+	if err != io.EOF {
+		logger.Errorf("on disk %s aborted writing %s %d bytes: %v", writerName, channelName, count, err)
+		return
+	}
+	if blockWriter != nil && blockWriter.Codec() == nil {
+		logger.Infof("on disk %s finished writing %s %d bytes, uncompressed as the rows did not shrink", writerName, channelName, count)
+		return
+	}
+	logger.Infof("on disk %s finished writing %s %d bytes", writerName, channelName, count)
 
 }
@@ -1,14 +1,13 @@
 package agent
 
 import (
-	"bufio"
 	"io"
-	"log"
 
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
-func (as *AgentServer) handleLocalInMemoryWriteConnection(r io.Reader, writerName, channelName string, readerCount int) {
+func (as *AgentServer) handleLocalInMemoryWriteConnection(readMessage func() ([]byte, error), writerName, channelName string, readerCount int) {
 
 	ch := as.inMemoryChannels.CreateNamedDatasetShard(channelName, readerCount)
 	defer func() {
@@ -16,20 +15,36 @@ func (as *AgentServer) handleLocalInMemoryWriteConnection(r io.Reader, writerNam
 		ch.wg.Wait()
 	}()
 
-	log.Printf("in memory %s starts writing %s expected reader:%d", writerName, channelName, readerCount)
+	logger.Debugf("in memory %s starts writing %s expected reader:%d", writerName, channelName, readerCount)
 
-	writer := bufio.NewWriter(ch.incomingChannel.Writer)
+	writer := util.NewBufferedMessageWriter(ch.incomingChannel.Writer, util.BUFFER_SIZE)
 	defer writer.Flush()
 
-	buf := make([]byte, util.BUFFER_SIZE)
-	count, err := io.CopyBuffer(writer, r, buf)
+	var count int64
+	var err error
+	for {
+		var message []byte
+		if message, err = readMessage(); err != nil {
+			break
+		}
+		count += int64(len(message))
+		if err = writer.WriteMessage(message); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	} else if flushErr := writer.Flush(); flushErr == nil {
+		// the readers fail instead of reading part of the shard
+		util.WriteAbortMessage(ch.incomingChannel.Writer)
+	}
 
 	ch.incomingChannel.Error = err
 	ch.incomingChannel.Counter = count
 
 	if err != nil {
-		log.Printf("in memory %s finished writing %s %d bytes: %v", writerName, channelName, count, err)
+		logger.Errorf("in memory %s finished writing %s %d bytes: %v", writerName, channelName, count, err)
 	} else {
-		log.Printf("in memory %s finished writing %s %d bytes", writerName, channelName, count)
+		logger.Infof("in memory %s finished writing %s %d bytes", writerName, channelName, count)
 	}
 }
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/util"
+)
+
+// messages reads the messages, and then the end of the stream.
+func messages(messages ...[]byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if len(messages) == 0 {
+			return nil, io.EOF
+		}
+		message := messages[0]
+		messages = messages[1:]
+		return message, nil
+	}
+}
+
+func TestWriteAbortsOnFailedWrite(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full to fill the disk")
+	}
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	as := &AgentServer{storageBackend: NewLocalDatasetShardsManager(dir, 45327, false)}
+
+	tests := []struct {
+		name     string
+		messages [][]byte
+		isFull   bool
+	}{
+		{"written", [][]byte{[]byte("row")}, false},
+		{"full on flush", [][]byte{[]byte("row")}, true},
+		{"full on write", [][]byte{make([]byte, util.BUFFER_SIZE)}, true},
+	}
+	for i, test := range tests {
+		name := fmt.Sprintf("f-d1-s%d", i)
+		if test.isFull {
+			// writing the shard file fails as on a full disk
+			file := store.DataFileName(dir, store.ShardStoreName(name, 45327))
+			if err := os.Symlink("/dev/full", file); err != nil {
+				t.Fatal(err)
+			}
+		}
+		as.handleLocalWriteConnection(messages(test.messages...), "test", name, 0, "")
+		if isComplete := as.storageBackend.HasCompleteShard(name); isComplete == test.isFull {
+			t.Errorf("%s: the shard is complete: %v", test.name, isComplete)
+		}
+	}
+}
@@ -2,25 +2,52 @@ package agent
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 type LocalDatasetShardsManager struct {
+	// diskUsage counts the bytes of the shard files, see DiskUsage()
+	diskUsage int64
 	sync.Mutex
 	dir            string
 	port           int
 	name2Store     map[string]store.DataStore
 	name2StoreCond *sync.Cond
+	indexShards    bool
+	// pendingReaders counts the readers of each shard that have not finished yet
+	pendingReaders map[string]int
+	// isComplete tells whether the shard of the name was written completely
+	isComplete map[string]bool
+	// nextGenerations are the kept shards being written again, by name. The
+	// readers read the complete shards until the next generations are
+	// complete, see CreateNamedDatasetShard().
+	nextGenerations map[string]store.DataStore
+	generationCount int
+	// activeReaders counts the readers of each shard, so the shards replaced
+	// by their next generations are closed after their last reader
+	activeReaders map[store.DataStore]int
+	replaced      map[store.DataStore]bool
 }
 
-func NewLocalDatasetShardsManager(dir string, port int) *LocalDatasetShardsManager {
+func NewLocalDatasetShardsManager(dir string, port int, indexShards bool) *LocalDatasetShardsManager {
 	m := &LocalDatasetShardsManager{
-		dir:        dir,
-		port:       port,
-		name2Store: make(map[string]store.DataStore),
+		dir:            dir,
+		port:           port,
+		name2Store:     make(map[string]store.DataStore),
+		indexShards:    indexShards,
+		pendingReaders: make(map[string]int),
+
+		isComplete:      make(map[string]bool),
+		nextGenerations: make(map[string]store.DataStore),
+		activeReaders:   make(map[store.DataStore]int),
+		replaced:        make(map[store.DataStore]bool),
 	}
 	m.name2StoreCond = sync.NewCond(m)
 	return m
@@ -36,6 +63,12 @@ func (m *LocalDatasetShardsManager) doDelete(name string) {
 	}
 
 	delete(m.name2Store, name)
+	delete(m.pendingReaders, name)
+	delete(m.isComplete, name)
+	if next, found := m.nextGenerations[name]; found {
+		delete(m.nextGenerations, name)
+		next.Destroy()
+	}
 
 	ds.Destroy()
 }
@@ -53,19 +86,40 @@ func (m *LocalDatasetShardsManager) DeleteNamedDatasetShard(name string) {
 
 }
 
-func (m *LocalDatasetShardsManager) CreateNamedDatasetShard(name string) store.DataStore {
+// CreateNamedDatasetShard creates the shard, replacing an existing one.
+// With readerCount readers, the shard can be deleted after they all finish reading.
+// Zero keeps it until it is deleted or purged.
+// A kept shard written completely is replaced only when the shard written
+// again without readerCount is complete, as reported by FinishWriting(),
+// e.g. a cached dataset written again by another flow. Until then the
+// readers read the complete shard instead of the partial one.
+func (m *LocalDatasetShardsManager) CreateNamedDatasetShard(name string, readerCount int) store.DataStore {
 
 	m.Lock()
 	defer m.Unlock()
 
+	_, isCounted := m.pendingReaders[name]
+	if m.isComplete[name] && !isCounted && readerCount == 0 {
+		if next, found := m.nextGenerations[name]; found {
+			next.Destroy()
+		}
+		m.generationCount++
+		next := m.newStore(fmt.Sprintf("%s.g%d", name, m.generationCount))
+		m.nextGenerations[name] = next
+		return next
+	}
+
 	_, ok := m.name2Store[name]
 	if ok {
 		m.doDelete(name)
 	}
 
-	s := store.NewLocalFileDataStore(m.dir, fmt.Sprintf("%s-%d", name, m.port))
+	s := m.newStore(name)
 
 	m.name2Store[name] = s
+	if readerCount > 0 {
+		m.pendingReaders[name] = readerCount
+	}
 	// println(name, "is broadcasting...")
 	m.name2StoreCond.Broadcast()
 
@@ -73,6 +127,64 @@ func (m *LocalDatasetShardsManager) CreateNamedDatasetShard(name string) store.D
 
 }
 
+func (m *LocalDatasetShardsManager) newStore(name string) store.DataStore {
+	var ds *store.LocalFileDataStore
+	if m.indexShards {
+		ds = store.NewIndexedLocalFileDataStore(m.dir, store.ShardStoreName(name, m.port))
+	} else {
+		ds = store.NewLocalFileDataStore(m.dir, store.ShardStoreName(name, m.port))
+	}
+	ds.CountUsage(&m.diskUsage)
+	return ds
+}
+
+// DiskUsage returns the bytes of the shard files kept by the agent, counted
+// when they are written and deleted.
+func (m *LocalDatasetShardsManager) DiskUsage() int64 {
+	return atomic.LoadInt64(&m.diskUsage)
+}
+
+// FinishWriting marks the shard as written completely, or not. A complete
+// next generation of the shard replaces the current one, which is closed
+// after its readers finish. An incomplete next generation is dropped.
+func (m *LocalDatasetShardsManager) FinishWriting(name string, ds store.DataStore, isComplete bool) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.nextGenerations[name] != ds {
+		if m.name2Store[name] == ds {
+			m.isComplete[name] = isComplete
+		}
+		return
+	}
+
+	delete(m.nextGenerations, name)
+	if !isComplete {
+		ds.Destroy()
+		return
+	}
+	// the readers of the replaced file keep reading it until it is closed
+	if err := ds.Rename(store.ShardStoreName(name, m.port)); err != nil {
+		logger.Errorf("Failed to replace %s by its next generation: %v", name, err)
+		ds.Destroy()
+		return
+	}
+	if current, found := m.name2Store[name]; found {
+		current.ReleaseUsage()
+		if m.activeReaders[current] > 0 {
+			m.replaced[current] = true
+		} else {
+			current.Close()
+		}
+	}
+	m.name2Store[name] = ds
+	m.name2StoreCond.Broadcast()
+
+}
+
+// WaitForNamedDatasetShard waits until the shard is created, and returns it.
+// The reader should call StopReading() after reading it.
 func (m *LocalDatasetShardsManager) WaitForNamedDatasetShard(name string) store.DataStore {
 
 	m.Lock()
@@ -80,6 +192,7 @@ func (m *LocalDatasetShardsManager) WaitForNamedDatasetShard(name string) store.
 
 	for {
 		if ds, ok := m.name2Store[name]; ok {
+			m.activeReaders[ds]++
 			return ds
 		}
 		// println(name, "is waiting to read...")
@@ -88,6 +201,90 @@ func (m *LocalDatasetShardsManager) WaitForNamedDatasetShard(name string) store.
 
 }
 
+// StopReading counts a reader of the shard as gone, and closes the shard if
+// it was replaced and this was its last reader.
+func (m *LocalDatasetShardsManager) StopReading(ds store.DataStore) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.activeReaders[ds] > 1 {
+		m.activeReaders[ds]--
+		return
+	}
+	delete(m.activeReaders, ds)
+	if m.replaced[ds] {
+		delete(m.replaced, ds)
+		ds.Close()
+	}
+
+}
+
+// FinishReading counts a reader of the shard as finished. It returns the shard
+// when it was the last pending reader, so the shard is not needed any more.
+func (m *LocalDatasetShardsManager) FinishReading(name string) (store.DataStore, bool) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	count, ok := m.pendingReaders[name]
+	if !ok {
+		return nil, false
+	}
+	if count > 1 {
+		m.pendingReaders[name] = count - 1
+		return nil, false
+	}
+	delete(m.pendingReaders, name)
+	return m.name2Store[name], true
+
+}
+
+// DeleteFinishedDatasetShard deletes the shard returned by FinishReading,
+// unless it has been written again since.
+func (m *LocalDatasetShardsManager) DeleteFinishedDatasetShard(name string, ds store.DataStore) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.name2Store[name] == ds {
+		m.doDelete(name)
+	}
+
+}
+
+// cachedShardPrefix starts the names of the shards of the cached datasets,
+// see flow.DatasetShard.Name().
+const cachedShardPrefix = "c-"
+
+// CachedShardNames returns the names of the shards of the cached datasets
+// written completely, reported to the master by the heartbeats.
+func (m *LocalDatasetShardsManager) CachedShardNames() (names []string) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	for name := range m.name2Store {
+		if strings.HasPrefix(name, cachedShardPrefix) && m.isComplete[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+
+}
+
+// HasCompleteShard tells whether the shard is kept, written completely.
+func (m *LocalDatasetShardsManager) HasCompleteShard(name string) bool {
+
+	m.Lock()
+	defer m.Unlock()
+
+	_, found := m.name2Store[name]
+	return found && m.isComplete[name]
+
+}
+
 // purge executor status older than 24 hours to save memory
 func (m *LocalDatasetShardsManager) purgeExpiredEntries() {
 	for {
@@ -97,7 +294,7 @@ func (m *LocalDatasetShardsManager) purgeExpiredEntries() {
 			var oldShardNames []string
 			for name, ds := range m.name2Store {
 				if ds.LastWriteAt().Before(cutoverLimit) && ds.LastReadAt().Before(cutoverLimit) {
-					println("purging dataset", name, "last write:", ds.LastWriteAt().String(), "last read:", ds.LastReadAt().String())
+					logger.Infof("purging dataset %s last write: %v last read: %v", name, ds.LastWriteAt(), ds.LastReadAt())
 					oldShardNames = append(oldShardNames, name)
 				}
 			}
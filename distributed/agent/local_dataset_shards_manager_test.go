@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFinishReading(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewLocalDatasetShardsManager(dir, 45327, false)
+	ds := m.CreateNamedDatasetShard("f-d1-s0", 2)
+
+	if _, isLast := m.FinishReading("f-d1-s0"); isLast {
+		t.Fatalf("finished with one pending reader")
+	}
+	finished, isLast := m.FinishReading("f-d1-s0")
+	if !isLast || finished != ds {
+		t.Fatalf("not finished after the last reader")
+	}
+
+	// the shard is written again before the delayed delete
+	rewritten := m.CreateNamedDatasetShard("f-d1-s0", 1)
+	m.DeleteFinishedDatasetShard("f-d1-s0", finished)
+	if m.name2Store["f-d1-s0"] != rewritten {
+		t.Errorf("deleted the rewritten shard")
+	}
+	if _, isLast := m.FinishReading("f-d1-s0"); !isLast {
+		t.Fatalf("not finished after the only reader")
+	}
+	m.DeleteFinishedDatasetShard("f-d1-s0", rewritten)
+	if _, found := m.name2Store["f-d1-s0"]; found {
+		t.Errorf("kept the finished shard")
+	}
+
+	// shards without reader counts are kept
+	m.CreateNamedDatasetShard("f-d2-s0", 0)
+	if _, isLast := m.FinishReading("f-d2-s0"); isLast {
+		t.Errorf("finished a shard without reader count")
+	}
+}
+
+func TestShardGenerations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewLocalDatasetShardsManager(dir, 45327, false)
+	first := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	first.Write([]byte("first"))
+	m.FinishWriting("c-cached-s0", first, true)
+
+	// the kept shard is written again while it is read
+	reading := m.WaitForNamedDatasetShard("c-cached-s0")
+	next := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	next.Write([]byte("second"))
+	if ds := m.WaitForNamedDatasetShard("c-cached-s0"); ds != first {
+		t.Fatalf("read the shard being written")
+	}
+	m.StopReading(first)
+
+	m.FinishWriting("c-cached-s0", next, true)
+	if ds := m.WaitForNamedDatasetShard("c-cached-s0"); ds != next {
+		t.Fatalf("read the replaced shard")
+	}
+	m.StopReading(next)
+
+	// the reader of the replaced shard reads it to the end
+	data := make([]byte, 5)
+	if _, err := reading.ReadAt(data, 0); err != nil || string(data) != "first" {
+		t.Errorf("read %q from the replaced shard: %v", data, err)
+	}
+	m.StopReading(reading)
+
+	// an incomplete generation is dropped
+	failed := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	m.FinishWriting("c-cached-s0", failed, false)
+	if ds := m.WaitForNamedDatasetShard("c-cached-s0"); ds != next {
+		t.Errorf("replaced by the incomplete shard")
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewLocalDatasetShardsManager(dir, 45327, false)
+	first := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	first.Write([]byte("first"))
+	m.FinishWriting("c-cached-s0", first, true)
+	m.CreateNamedDatasetShard("f-d1-s0", 1).Write([]byte("other"))
+	if usage := m.DiskUsage(); usage != 10 {
+		t.Errorf("disk usage %d after writing 10 bytes", usage)
+	}
+
+	// the next generation replaces the kept shard
+	next := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	next.Write([]byte("second"))
+	if usage := m.DiskUsage(); usage != 16 {
+		t.Errorf("disk usage %d while writing the next generation", usage)
+	}
+	m.FinishWriting("c-cached-s0", next, true)
+	if usage := m.DiskUsage(); usage != 11 {
+		t.Errorf("disk usage %d after replacing the kept shard", usage)
+	}
+
+	m.DeleteNamedDatasetShard("c-cached-s0")
+	m.DeleteNamedDatasetShard("f-d1-s0")
+	if usage := m.DiskUsage(); usage != 0 {
+		t.Errorf("disk usage %d after deleting all shards", usage)
+	}
+}
+
+func TestCachedShardNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewLocalDatasetShardsManager(dir, 45327, false)
+	complete := m.CreateNamedDatasetShard("c-cached-s1", 0)
+	m.FinishWriting("c-cached-s1", complete, true)
+	partial := m.CreateNamedDatasetShard("c-cached-s0", 0)
+	m.FinishWriting("c-cached-s0", partial, false)
+	flowShard := m.CreateNamedDatasetShard("f-d1-s0", 0)
+	m.FinishWriting("f-d1-s0", flowShard, true)
+
+	if names := m.CachedShardNames(); len(names) != 1 || names[0] != "c-cached-s1" {
+		t.Errorf("cached shards %v, expected the complete c-cached-s1", names)
+	}
+	if !m.HasCompleteShard("c-cached-s1") || m.HasCompleteShard("c-cached-s0") || m.HasCompleteShard("c-cached-s2") {
+		t.Errorf("only c-cached-s1 is kept complete")
+	}
+
+	m.DeleteNamedDatasetShard("c-cached-s1")
+	if names := m.CachedShardNames(); len(names) != 0 {
+		t.Errorf("cached shards %v after the delete", names)
+	}
+}
@@ -0,0 +1,313 @@
+// Package definition builds flows defined by data, a pb.FlowDefinition in
+// JSON, YAML or protobuf, and serves them over gRPC, so systems not linking
+// the Go library can run gleam jobs.
+//
+// The steps use the file plugins, and the mappers, reducers and predicates
+// registered in the binary serving the definitions. The shell commands and
+// the files they can use are limited by the Permissions of the server.
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/plugins/file"
+	"github.com/lovelly/gleam/util"
+)
+
+// Parse decodes a flow definition in JSON or YAML, e.g.
+//
+//	{"name": "wordcount", "steps": [
+//	  {"id": "lines", "op": "read", "format": "txt", "path": "/data/*.txt", "shards": 4},
+//	  {"id": "words", "op": "pipe", "inputs": ["lines"], "command": "tr -s ' ' '\n'"},
+//	  {"id": "ones", "op": "map", "inputs": ["words"], "function": "main.addOne"},
+//	  {"id": "counts", "op": "reduceBy", "inputs": ["ones"], "function": "main.sum", "fields": [1]},
+//	  {"id": "result", "op": "collect", "inputs": ["counts"]}
+//	]}
+//
+// or the same in YAML:
+//
+//	name: wordcount
+//	steps:
+//	- {id: lines, op: read, format: txt, path: /data/*.txt, shards: 4}
+//	- {id: words, op: pipe, inputs: [lines], command: "tr -s ' ' '\n'"}
+func Parse(data []byte) (*pb.FlowDefinition, error) {
+	// JSON is YAML too
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse flow definition: %v", err)
+	}
+	def := &pb.FlowDefinition{}
+	if err := json.Unmarshal(data, def); err != nil {
+		return nil, fmt.Errorf("Failed to parse flow definition: %v", err)
+	}
+	return def, nil
+}
+
+// Permissions allow the steps acting on the hosts of the agents and the
+// driver, which the definitions can not use by default, as their clients
+// are not trusted as much as the binary serving them.
+type Permissions struct {
+	// AllowCommands allows the pipe steps, running any shell command.
+	AllowCommands bool
+	// AllowedPaths are the folders, or URL prefixes, of the files the read
+	// and write steps can use.
+	AllowedPaths []string
+}
+
+// checkPath fails unless the path is in one of the allowed paths.
+func (p Permissions) checkPath(path string) error {
+	if path == "" {
+		return nil
+	}
+	for _, element := range strings.Split(path, "/") {
+		if element == ".." {
+			return fmt.Errorf("path %s is not allowed with ..", path)
+		}
+	}
+	for _, allowed := range p.AllowedPaths {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if path == allowed || strings.HasPrefix(path, allowed+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %s is not allowed by the server", path)
+}
+
+// Build adds the steps of the definition to a new flow, in order, so each
+// step can only read the datasets of the steps before it. The rows of the
+// collect steps are passed to collect, on the driver. The steps not allowed
+// by the permissions fail the build.
+//
+// The ops and the fields they use, besides id, inputs and name:
+//
+//	read                  format (csv, tsv, txt, jsonl, or orc and parquet if
+//	                      their formats are imported), path, shards
+//	lines                 lines
+//	map                   function, arg
+//	filter                function
+//	reduceBy              function, fields
+//	pipe                  command
+//	partition             shards, fields
+//	sort, distinct        fields
+//	top                   n, fields
+//	join, leftOuterJoin,
+//	rightOuterJoin,
+//	fullOuterJoin         fields, with 2 inputs
+//	write                 format (csv, tsv), path
+//	collect
+//
+// The fields are 1-based, negative for descending order, and default to the
+// first field, the key.
+func Build(def *pb.FlowDefinition, permissions Permissions, collect func(stepId string, row []interface{}) error) (*flow.Flow, error) {
+	f := flow.New(def.GetName())
+	datasets := make(map[string]*flow.Dataset)
+	for i, step := range def.GetSteps() {
+		if step.GetId() == "" {
+			return nil, fmt.Errorf("step %d has no id", i+1)
+		}
+		if _, found := datasets[step.GetId()]; found {
+			return nil, fmt.Errorf("step %s is defined twice", step.GetId())
+		}
+		var inputs []*flow.Dataset
+		for _, input := range step.GetInputs() {
+			d, found := datasets[input]
+			if !found {
+				return nil, fmt.Errorf("step %s reads %s, not defined by an earlier step", step.GetId(), input)
+			}
+			inputs = append(inputs, d)
+		}
+		d, err := buildStep(f, step, inputs, permissions, collect)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %v", step.GetId(), err)
+		}
+		datasets[step.GetId()] = d
+	}
+	return f, nil
+}
+
+func buildStep(f *flow.Flow, step *pb.StepDefinition, inputs []*flow.Dataset, permissions Permissions,
+	collect func(stepId string, row []interface{}) error) (*flow.Dataset, error) {
+	name := step.GetName()
+	if name == "" {
+		name = step.GetId()
+	}
+	inputCount := 1
+	switch step.GetOp() {
+	case "read", "lines":
+		inputCount = 0
+	case "join", "leftOuterJoin", "rightOuterJoin", "fullOuterJoin":
+		inputCount = 2
+	}
+	if len(inputs) != inputCount {
+		return nil, fmt.Errorf("%s needs %d inputs, not %d", step.GetOp(), inputCount, len(inputs))
+	}
+
+	switch step.GetOp() {
+	case "read":
+		if err := permissions.checkPath(step.GetPath()); err != nil {
+			return nil, err
+		}
+		source, err := fileSource(step.GetFormat(), step.GetPath(), shardCount(step))
+		if err != nil {
+			return nil, err
+		}
+		return f.Read(source), nil
+	case "lines":
+		return f.Strings(step.GetLines()), nil
+	case "map":
+		mapperId, err := lookupMapper(step.GetFunction())
+		if err != nil {
+			return nil, err
+		}
+		return inputs[0].MapWithArg(name, mapperId, step.GetArg()), nil
+	case "filter":
+		predicateId, err := lookupMapper(step.GetFunction())
+		if err != nil {
+			return nil, err
+		}
+		if mapper, _ := gio.GetMapper(predicateId); mapper.Predicate == nil {
+			return nil, fmt.Errorf("%s is not registered by gio.RegisterPredicate()", step.GetFunction())
+		}
+		return inputs[0].Filter(name, predicateId), nil
+	case "reduceBy":
+		reducerId, err := lookupReducer(step.GetFunction())
+		if err != nil {
+			return nil, err
+		}
+		return inputs[0].ReduceBy(name, reducerId, sortOption(step)), nil
+	case "pipe":
+		if !permissions.AllowCommands {
+			return nil, fmt.Errorf("pipe is not allowed by the server")
+		}
+		if step.GetCommand() == "" {
+			return nil, fmt.Errorf("pipe needs a command")
+		}
+		return inputs[0].Pipe(name, step.GetCommand()), nil
+	case "partition":
+		return inputs[0].Partition(name, shardCount(step), sortOption(step)), nil
+	case "sort":
+		return inputs[0].Sort(name, sortOption(step)), nil
+	case "distinct":
+		return inputs[0].Distinct(name, sortOption(step)), nil
+	case "top":
+		if step.GetN() <= 0 {
+			return nil, fmt.Errorf("top needs a positive n")
+		}
+		return inputs[0].Top(name, int(step.GetN()), sortOption(step)), nil
+	case "join":
+		return inputs[0].Join(name, inputs[1], sortOption(step)), nil
+	case "leftOuterJoin":
+		return inputs[0].LeftOuterJoin(name, inputs[1], sortOption(step)), nil
+	case "rightOuterJoin":
+		return inputs[0].RightOuterJoin(name, inputs[1], sortOption(step)), nil
+	case "fullOuterJoin":
+		return inputs[0].FullOuterJoin(name, inputs[1], sortOption(step)), nil
+	case "write":
+		if err := permissions.checkPath(step.GetPath()); err != nil {
+			return nil, err
+		}
+		sink, err := fileSink(step.GetFormat(), step.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		return inputs[0].Write(sink), nil
+	case "collect":
+		stepId := step.GetId()
+		return inputs[0].OutputRow(func(row *util.Row) error {
+			return collect(stepId, append(row.K, row.V...))
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown op %q", step.GetOp())
+}
+
+func fileSource(format, path string, shards int) (*file.FileSource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("read needs a path")
+	}
+	if !file.IsSupported(format) {
+		return nil, fmt.Errorf("unknown file format %q", format)
+	}
+	switch format {
+	case "csv":
+		return file.Csv(path, shards), nil
+	case "tsv":
+		return file.Tsv(path, shards), nil
+	case "txt":
+		return file.Txt(path, shards), nil
+	case "jsonl":
+		return file.Jsonl(path, shards), nil
+	case "orc":
+		return file.Orc(path, shards), nil
+	case "parquet":
+		return file.Parquet(path, shards), nil
+	}
+	return nil, fmt.Errorf("unknown file format %q", format)
+}
+
+func fileSink(format, path string) (*file.FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("write needs a path")
+	}
+	switch format {
+	case "csv":
+		return file.CsvSink(path), nil
+	case "tsv":
+		return file.TsvSink(path), nil
+	}
+	return nil, fmt.Errorf("unknown file format %q", format)
+}
+
+// lookupMapper finds the mapper by its id, or by the name of its function.
+func lookupMapper(function string) (gio.MapperId, error) {
+	if _, found := gio.GetMapper(gio.MapperId(function)); found {
+		return gio.MapperId(function), nil
+	}
+	if mapperId, found := gio.LookupMapper(function); found {
+		return mapperId, nil
+	}
+	return "", fmt.Errorf("mapper %q is not registered", function)
+}
+
+// lookupReducer finds the reducer by its id, or by the name of its function.
+func lookupReducer(function string) (gio.ReducerId, error) {
+	if _, found := gio.GetReducer(gio.ReducerId(function)); found {
+		return gio.ReducerId(function), nil
+	}
+	if reducerId, found := gio.LookupReducer(function); found {
+		return reducerId, nil
+	}
+	return "", fmt.Errorf("reducer %q is not registered", function)
+}
+
+// sortOption orders by the 1-based fields, descending for negative fields.
+func sortOption(step *pb.StepDefinition) *flow.SortOption {
+	if len(step.GetFields()) == 0 {
+		return flow.Field(1)
+	}
+	var option *flow.SortOption
+	for _, field := range step.GetFields() {
+		index, ascending := int(field), true
+		if field < 0 {
+			index, ascending = int(-field), false
+		}
+		if option == nil {
+			option = flow.OrderBy(index, ascending)
+		} else {
+			option.By(index, ascending)
+		}
+	}
+	return option
+}
+
+func shardCount(step *pb.StepDefinition) int {
+	if step.GetShards() <= 0 {
+		return 1
+	}
+	return int(step.GetShards())
+}
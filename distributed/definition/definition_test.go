@@ -0,0 +1,53 @@
+package definition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	for definition, expected := range map[string]string{
+		`{"steps": [{"id": "a", "op": "lines", "lines": ["x"]}, {"id": "b", "op": "pipe", "inputs": ["a"], "command": "cat"}, {"id": "c", "op": "collect", "inputs": ["b"]}]}`: "",
+		`{"steps": [{"op": "lines"}]}`:                                                           "step 1 has no id",
+		`{"steps": [{"id": "a", "op": "lines"}, {"id": "a", "op": "lines"}]}`:                    "step a is defined twice",
+		`{"steps": [{"id": "b", "op": "sort", "inputs": ["a"]}]}`:                                "step b reads a",
+		`{"steps": [{"id": "a", "op": "lines"}, {"id": "b", "op": "join", "inputs": ["a"]}]}`:    "join needs 2 inputs, not 1",
+		`{"steps": [{"id": "a", "op": "lines"}, {"id": "b", "op": "map", "inputs": ["a"]}]}`:     "mapper \"\" is not registered",
+		`{"steps": [{"id": "a", "op": "lines"}, {"id": "b", "op": "shuffle", "inputs": ["a"]}]}`: "unknown op \"shuffle\"",
+		`{"steps": [{"id": "a", "op": "read", "format": "xml", "path": "/tmp/a.xml"}]}`:          "unknown file format \"xml\"",
+		`{"steps": [{"id": "a", "op": "read", "format": "txt", "path": "/etc/passwd"}]}`:         "path /etc/passwd is not allowed",
+		`{"steps": [{"id": "a", "op": "read", "format": "txt", "path": "/tmp/../etc/passwd"}]}`:  "is not allowed with ..",
+		`{"steps": [{"id": "a", "op": "read", "format": "txt", "path": "/tmp/a.txt"}]}`:          "",
+		`{"steps": [{"id": "a", "op": "read", "format": "txt", "path": "/tmpfs/a.txt"}]}`:        "path /tmpfs/a.txt is not allowed",
+		"steps:\n- {id: a, op: lines, lines: [x]}\n- {id: b, op: collect, inputs: [a]}\n":        "",
+	} {
+		def, err := Parse([]byte(definition))
+		if err != nil {
+			t.Fatalf("%s: %v", definition, err)
+		}
+		_, err = Build(def, Permissions{AllowCommands: true, AllowedPaths: []string{"/tmp/"}}, func(stepId string, row []interface{}) error { return nil })
+		if expected == "" && err != nil {
+			t.Errorf("%s: %v", definition, err)
+		}
+		if expected != "" && (err == nil || !strings.Contains(err.Error(), expected)) {
+			t.Errorf("%s: %v, expected %s", definition, err, expected)
+		}
+	}
+}
+
+func TestBuildWithoutPermissions(t *testing.T) {
+	for definition, expected := range map[string]string{
+		`{"steps": [{"id": "a", "op": "lines", "lines": ["x"]}, {"id": "b", "op": "pipe", "inputs": ["a"], "command": "cat"}]}`:                       "pipe is not allowed",
+		`{"steps": [{"id": "a", "op": "read", "format": "txt", "path": "/tmp/a.txt"}]}`:                                                               "path /tmp/a.txt is not allowed",
+		`{"steps": [{"id": "a", "op": "lines", "lines": ["x"]}, {"id": "b", "op": "write", "inputs": ["a"], "format": "txt", "path": "/tmp/b.txt"}]}`: "path /tmp/b.txt is not allowed",
+	} {
+		def, err := Parse([]byte(definition))
+		if err != nil {
+			t.Fatalf("%s: %v", definition, err)
+		}
+		_, err = Build(def, Permissions{}, func(stepId string, row []interface{}) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: %v, expected %s", definition, err, expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package definition
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
+	"google.golang.org/grpc"
+)
+
+// rowsPerResponse is the most collected rows sent in one response.
+const rowsPerResponse = 1024
+
+// ServerOption sets who can run flows on the server, and what they can do.
+type ServerOption struct {
+	// AccessToken is required in each flow definition, as its accessToken.
+	AccessToken string
+	// Permissions allow the steps acting on the hosts, none by default.
+	Permissions Permissions
+}
+
+// Server runs the flows submitted to the pb.GleamFlowDefinition service with
+// the options, e.g. distributed.Option(), one flow per call.
+type Server struct {
+	option  ServerOption
+	options []flow.FlowOption
+}
+
+func NewServer(option ServerOption, options ...flow.FlowOption) *Server {
+	return &Server{option: option, options: options}
+}
+
+// Serve listens on the address for flow definitions, until it fails. The
+// binary calling it should call gio.Init() first, as the executors run it
+// for the Go mappers and reducers of the flows. The definitions are only
+// run with the access token of the option.
+func Serve(address string, option ServerOption, options ...flow.FlowOption) error {
+	if option.AccessToken == "" {
+		return fmt.Errorf("The flow definition server needs an access token")
+	}
+	if !gio.HasInitalized {
+		return fmt.Errorf("gio.Init() is required before serving flow definitions")
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("Failed to listen on %s: %v", address, err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterGleamFlowDefinitionServer(grpcServer, NewServer(option, options...))
+	return grpcServer.Serve(listener)
+}
+
+// Run builds the flow, validates it, and runs it, sending the rows of the
+// collect steps in batches. The problems of the definition, and the errors
+// of the flow, are sent as an error response instead of failing the call.
+// Only the definitions without the access token fail the call.
+func (s *Server) Run(def *pb.FlowDefinition, stream pb.GleamFlowDefinition_RunServer) error {
+	if s.option.AccessToken == "" || subtle.ConstantTimeCompare([]byte(def.GetAccessToken()), []byte(s.option.AccessToken)) != 1 {
+		return fmt.Errorf("access token of flow definition %s is not valid", def.GetName())
+	}
+
+	var lock sync.Mutex
+	var sendErr error
+	batches := make(map[string][]string)
+	send := func(stepId string) {
+		if sendErr == nil && len(batches[stepId]) > 0 {
+			sendErr = stream.Send(&pb.FlowDefinitionResponse{StepId: stepId, Rows: batches[stepId]})
+		}
+		batches[stepId] = nil
+	}
+
+	err := s.run(def, stream, func(stepId string, row []interface{}) error {
+		encoded, err := util.JsonRow(row)
+		if err != nil {
+			return fmt.Errorf("Failed to encode row of %s: %v", stepId, err)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		batches[stepId] = append(batches[stepId], string(encoded))
+		if len(batches[stepId]) >= rowsPerResponse {
+			send(stepId)
+		}
+		return sendErr
+	})
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, step := range def.GetSteps() {
+		send(step.GetId())
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		logger.Errorf("flow definition %s failed: %v", def.GetName(), err)
+		return stream.Send(&pb.FlowDefinitionResponse{Error: err.Error()})
+	}
+	return nil
+}
+
+// run builds and runs the flow, returning its errors, also the panics of the
+// flow runners, so one failed flow does not stop the server.
+func (s *Server) run(def *pb.FlowDefinition, stream pb.GleamFlowDefinition_RunServer,
+	collect func(stepId string, row []interface{}) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("flow %s panicked: %v", def.GetName(), r)
+		}
+	}()
+
+	fc, err := Build(def, s.option.Permissions, collect)
+	if err != nil {
+		return err
+	}
+	if err = fc.Validate(s.options...); err != nil {
+		return err
+	}
+
+	logger.Infof("running flow definition %s with %d steps", def.GetName(), len(def.GetSteps()))
+	return fc.RunErr(stream.Context(), s.options...)
+}
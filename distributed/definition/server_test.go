@@ -0,0 +1,118 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"google.golang.org/grpc"
+)
+
+var failOnBadRow = gio.RegisterMapper(func(row []interface{}) error {
+	if value := gio.ToString(row[0]); value == "bad" {
+		return fmt.Errorf("row %s is bad", value)
+	}
+	gio.Emit(row...)
+	return nil
+})
+
+// TestMain runs the mappers when the test binary is started again to run
+// them, instead of the tests.
+func TestMain(m *testing.M) {
+	gio.Init()
+	os.Exit(m.Run())
+}
+
+// runDefinition submits the definition to a server with the token, and
+// returns the collected rows, and the error response.
+func runDefinition(t *testing.T, token string, definition string) (rows []string, flowErr string, err error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterGleamFlowDefinitionServer(server, NewServer(ServerOption{AccessToken: "secret"}))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	def, err := Parse([]byte(definition))
+	if err != nil {
+		t.Fatalf("%s: %v", definition, err)
+	}
+	def.AccessToken = token
+	stream, err := pb.NewGleamFlowDefinitionClient(conn).Run(context.Background(), def)
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			sort.Strings(rows)
+			return rows, flowErr, nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		for _, row := range response.GetRows() {
+			rows = append(rows, response.GetStepId()+" "+row)
+		}
+		if response.GetError() != "" {
+			flowErr = response.GetError()
+		}
+	}
+}
+
+func TestServerRun(t *testing.T) {
+	definition := fmt.Sprintf(`{"name": "check", "steps": [
+		{"id": "a", "op": "lines", "lines": ["x", "%s"]},
+		{"id": "b", "op": "map", "inputs": ["a"], "function": %q},
+		{"id": "c", "op": "collect", "inputs": ["b"]}
+	]}`, "y", failOnBadRow)
+
+	rows, flowErr, err := runDefinition(t, "secret", definition)
+	if err != nil || flowErr != "" {
+		t.Fatalf("run: %v %s", err, flowErr)
+	}
+	if expected := []string{`c ["x"]`, `c ["y"]`}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("collected %v, expected %v", rows, expected)
+	}
+
+	// the flow errors are sent, after the rows collected before them
+	_, flowErr, err = runDefinition(t, "secret", strings.Replace(definition, `"y"`, `"bad"`, 1))
+	if err != nil {
+		t.Fatalf("run the failing flow: %v", err)
+	}
+	if !strings.Contains(flowErr, "b.Map") {
+		t.Errorf("failing flow ended with error %q", flowErr)
+	}
+
+	// the problems of the definition are sent as errors too
+	_, flowErr, err = runDefinition(t, "secret", `{"steps": [{"id": "a", "op": "lines"}, {"id": "b", "op": "pipe", "inputs": ["a"], "command": "cat"}]}`)
+	if err != nil || !strings.Contains(flowErr, "pipe is not allowed") {
+		t.Errorf("pipe ended with %v, error %q", err, flowErr)
+	}
+}
+
+func TestServerRunWithoutToken(t *testing.T) {
+	definition := `{"steps": [{"id": "a", "op": "lines", "lines": ["x"]}, {"id": "b", "op": "collect", "inputs": ["a"]}]}`
+	for _, token := range []string{"", "guess"} {
+		rows, _, err := runDefinition(t, token, definition)
+		if err == nil || !strings.Contains(err.Error(), "access token") {
+			t.Errorf("token %q collected %v, error %v", token, rows, err)
+		}
+	}
+}
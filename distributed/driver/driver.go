@@ -3,7 +3,8 @@ package driver
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 	"sync"
 	"time"
@@ -14,19 +15,28 @@ import (
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 	"github.com/lovelly/gleam/util/on_interrupt"
 	"google.golang.org/grpc"
 )
 
 type Option struct {
-	RequiredFiles []resource.FileResource
-	Master        string
-	DataCenter    string
-	Rack          string
-	TaskMemoryMB  int
-	FlowBid       float64
-	Module        string
-	IsProfiling   bool
+	RequiredFiles      []resource.FileResource
+	Master             string
+	DataCenter         string
+	Rack               string
+	TaskMemoryMB       int
+	MinTaskMemoryMB    int
+	FlowBid            float64
+	Module             string
+	IsProfiling        bool
+	NetworkMBPerSecond int
+	LocalThresholdMB   int64
+	StepMemoryMB       map[string]int
+	MaxConcurrentTasks int
+	RetryWaitTimes     []time.Duration
+	StopOnFailure      bool
+	Compression        string
 }
 
 type FlowDriver struct {
@@ -36,6 +46,9 @@ type FlowDriver struct {
 	taskGroups []*plan.TaskGroup
 
 	status *pb.FlowExecutionStatus
+
+	errLock sync.Mutex
+	err     error
 }
 
 func NewFlowDriver(option *Option) *FlowDriver {
@@ -45,8 +58,27 @@ func NewFlowDriver(option *Option) *FlowDriver {
 	}
 }
 
+// GetFlowRunner lets the flows be validated as run by the driver.
+func (fcd *FlowDriver) GetFlowRunner() flow.FlowRunner {
+	return fcd
+}
+
 // driver runs on local, controlling all tasks
 func (fcd *FlowDriver) RunFlowContext(parentCtx context.Context, fc *flow.Flow) {
+	fcd.errLock.Lock()
+	fcd.err = nil
+	fcd.errLock.Unlock()
+
+	if err := fc.Validate(fcd); err != nil {
+		logger.Errorf("Failed to validate flow %s: %v", fc.Name, err)
+		fcd.setErr(err)
+		return
+	}
+
+	if fcd.isSmallFlow(fc) {
+		flow.Local.RunFlowContext(parentCtx, fc)
+		return
+	}
 
 	// task fusion to minimize disk IO
 	fcd.stepGroups, fcd.taskGroups = plan.GroupTasks(fc)
@@ -56,12 +88,19 @@ func (fcd *FlowDriver) RunFlowContext(parentCtx context.Context, fc *flow.Flow)
 	sched := scheduler.New(
 		fcd.Option.Master,
 		&scheduler.Option{
-			DataCenter:   fcd.Option.DataCenter,
-			Rack:         fcd.Option.Rack,
-			TaskMemoryMB: fcd.Option.TaskMemoryMB,
-			Module:       fcd.Option.Module,
-			FlowHashcode: fc.HashCode,
-			IsProfiling:  fcd.Option.IsProfiling,
+			DataCenter:            fcd.Option.DataCenter,
+			Rack:                  fcd.Option.Rack,
+			TaskMemoryMB:          fcd.Option.TaskMemoryMB,
+			MinTaskMemoryMB:       fcd.Option.MinTaskMemoryMB,
+			Module:                fcd.Option.Module,
+			FlowHashcode:          fc.HashCode,
+			IsProfiling:           fcd.Option.IsProfiling,
+			AccessToken:           newAccessToken(),
+			NetworkBytesPerSecond: int64(fcd.Option.NetworkMBPerSecond) * 1024 * 1024,
+			StepMemoryMB:          fcd.Option.StepMemoryMB,
+			MaxConcurrentTasks:    fcd.Option.MaxConcurrentTasks,
+			RetryWaitTimes:        fcd.Option.RetryWaitTimes,
+			Compression:           fcd.Option.Compression,
 		},
 	)
 
@@ -70,9 +109,10 @@ func (fcd *FlowDriver) RunFlowContext(parentCtx context.Context, fc *flow.Flow)
 	defer fcd.cleanup(sched, fc)
 
 	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
 
 	on_interrupt.OnInterrupt(func() {
-		println("interrupted ...")
+		logger.Warnf("interrupted ...")
 		fcd.printDistributedStatus(os.Stderr)
 		cancel()
 		fcd.cleanup(sched, fc)
@@ -80,11 +120,19 @@ func (fcd *FlowDriver) RunFlowContext(parentCtx context.Context, fc *flow.Flow)
 
 	// schedule to run the steps
 	var wg, reportWg sync.WaitGroup
-	for _, taskGroup := range fcd.taskGroups {
+	taskGroups := fcd.taskGroupsToRun(sched)
+	for _, taskGroup := range taskGroups {
 		wg.Add(1)
 		go func(taskGroup *plan.TaskGroup) {
-			sched.ExecuteTaskGroup(ctx, fc, fcd.GetTaskGroupStatus(taskGroup), &wg, taskGroup,
-				fcd.Option.FlowBid/float64(len(fcd.taskGroups)), fcd.Option.RequiredFiles)
+			err := sched.ExecuteTaskGroup(ctx, fc, fcd.GetTaskGroupStatus(taskGroup), &wg, taskGroup,
+				fcd.Option.FlowBid/float64(len(taskGroups)), fcd.Option.RequiredFiles)
+			if err != nil {
+				logger.Errorf("Failed to execute task group %s: %v", taskGroup.String(), err)
+				fcd.setErr(err)
+				if fcd.Option.StopOnFailure {
+					cancel()
+				}
+			}
 		}(taskGroup)
 	}
 	go sched.Market.FetcherLoop()
@@ -93,15 +141,147 @@ func (fcd *FlowDriver) RunFlowContext(parentCtx context.Context, fc *flow.Flow)
 	reportWg.Add(1)
 	go fcd.reportStatus(ctx, &reportWg, fcd.Option.Master, stopChan)
 
-	log.Printf("Start Job Status URL http://%s/job/%d", fcd.Option.Master, fcd.status.GetId())
+	logger.Infof("Start Job Status URL http://%s/job/%d", fcd.Option.Master, fcd.status.GetId())
 
 	wg.Wait()
+	fcd.collectPeekedRows()
+	if fcd.Err() == nil {
+		fcd.saveBucketings(fc)
+	}
 
 	stopChan <- true
 	reportWg.Wait()
 
 }
 
+// saveBucketings tells the master how the buckets kept by BucketBy() are
+// bucketed, so the later flows of other drivers read them by Flow.Buckets().
+func (fcd *FlowDriver) saveBucketings(fc *flow.Flow) {
+	for _, d := range fc.Datasets {
+		if d.Meta.Bucketing == nil {
+			continue
+		}
+		bucketing := &pb.Bucketing{
+			Name:        d.Meta.CacheName,
+			BucketCount: int32(d.Meta.Bucketing.BucketCount),
+		}
+		for _, field := range d.Meta.Bucketing.KeyFields {
+			bucketing.KeyFields = append(bucketing.KeyFields, int32(field))
+		}
+		if err := scheduler.SaveBucketing(fcd.Option.Master, bucketing); err != nil {
+			logger.Errorf("Failed to save the buckets %s to the master: %v", bucketing.Name, err)
+		}
+	}
+}
+
+// Err returns the first failure of the last flow run by the driver.
+func (fcd *FlowDriver) Err() error {
+	fcd.errLock.Lock()
+	defer fcd.errLock.Unlock()
+	return fcd.err
+}
+
+func (fcd *FlowDriver) setErr(err error) {
+	fcd.errLock.Lock()
+	defer fcd.errLock.Unlock()
+	if fcd.err == nil {
+		fcd.err = err
+	}
+}
+
+// taskGroupsToRun skips the task groups whose cached output shards were
+// written by an earlier flow, and the step groups only needed by them.
+func (fcd *FlowDriver) taskGroupsToRun(sched *scheduler.Scheduler) (ret []*plan.TaskGroup) {
+	skipped := make(map[*plan.TaskGroup]bool)
+	cached := make(map[*plan.StepGroup]bool)
+	hasReaders := make(map[*plan.StepGroup]bool)
+	for _, stepGroup := range fcd.stepGroups {
+		cached[stepGroup] = len(stepGroup.TaskGroups) > 0
+		for _, taskGroup := range stepGroup.TaskGroups {
+			skipped[taskGroup] = sched.UseCachedOutput(taskGroup)
+			cached[stepGroup] = cached[stepGroup] && skipped[taskGroup]
+		}
+		for _, parent := range stepGroup.Parents {
+			hasReaders[parent] = true
+		}
+	}
+
+	needed := make(map[*plan.StepGroup]bool)
+	var visit func(stepGroup *plan.StepGroup)
+	visit = func(stepGroup *plan.StepGroup) {
+		if needed[stepGroup] || cached[stepGroup] {
+			return
+		}
+		needed[stepGroup] = true
+		for _, parent := range stepGroup.Parents {
+			visit(parent)
+		}
+	}
+	for _, stepGroup := range fcd.stepGroups {
+		if !hasReaders[stepGroup] {
+			visit(stepGroup)
+		}
+	}
+
+	for _, taskGroup := range fcd.taskGroups {
+		if needed[taskGroup.ParentStepGroup] && !skipped[taskGroup] {
+			ret = append(ret, taskGroup)
+		}
+	}
+	if skippedCount := len(fcd.taskGroups) - len(ret); skippedCount > 0 {
+		logger.Infof("Skipping %d task groups, reading the cached datasets", skippedCount)
+	}
+	return
+}
+
+// isSmallFlow checks whether the input of the flow is known to be less than
+// the threshold, so it runs faster locally than on the cluster, and whether
+// none of its steps needs the agents.
+func (fcd *FlowDriver) isSmallFlow(fc *flow.Flow) bool {
+	size, isKnown := fc.GetInputSize()
+	if !isKnown || size >= fcd.Option.LocalThresholdMB {
+		return false
+	}
+	for _, step := range fc.Steps {
+		if needsAgents(step) {
+			return false
+		}
+	}
+	logger.Infof("Running flow %s locally, with %d MB input", fc.Name, size)
+	return true
+}
+
+// needsAgents tells whether the step only runs right on the agents: the
+// secrets are only provided by the agents, the local runner ignores the node
+// selectors and tolerations, e.g. of the ipc sources and sinks, and the
+// cached and bucketed shards are kept on the agents.
+func needsAgents(step *flow.Step) bool {
+	if len(step.Secrets) > 0 || len(step.NodeSelector) > 0 || len(step.Tolerations) > 0 {
+		return true
+	}
+	datasets := step.InputDatasets
+	if step.OutputDataset != nil {
+		datasets = append(datasets[:len(datasets):len(datasets)], step.OutputDataset)
+	}
+	for _, d := range datasets {
+		if d.Meta.CacheName != "" || d.Meta.Bucketing != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// newAccessToken creates a random per-flow token. Agents only serve a dataset
+// shard to readers presenting the same token its writer used.
+func newAccessToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Errorf("Failed to generate flow access token: %v", err)
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
 func (fcd *FlowDriver) cleanup(sched *scheduler.Scheduler, fc *flow.Flow) {
 	var wg sync.WaitGroup
 
@@ -139,7 +319,7 @@ func (fcd *FlowDriver) cleanup(sched *scheduler.Scheduler, fc *flow.Flow) {
 			if err := scheduler.SendCleanupRequest(url, &pb.CleanupRequest{
 				FlowHashCode: fc.HashCode,
 			}); err != nil {
-				println("Purging dataset error:", err.Error())
+				logger.Warnf("Purging dataset error: %v", err)
 			}
 		}(url)
 	}
@@ -150,14 +330,14 @@ func (fcd *FlowDriver) cleanup(sched *scheduler.Scheduler, fc *flow.Flow) {
 func (fcd *FlowDriver) reportStatus(ctx context.Context, wg *sync.WaitGroup, master string, stopChan chan bool) {
 	grpcConection, err := util.GleamGrpcDial(master, grpc.WithInsecure())
 	if err != nil {
-		log.Printf("Failed to dial: %v", err)
+		logger.Errorf("Failed to dial: %v", err)
 		return
 	}
 	defer func() {
 		// println("grpc closing....")
 		time.Sleep(50 * time.Millisecond)
 		if err := grpcConection.Close(); err != nil {
-			log.Printf("grpcConection.close error: %v", err)
+			logger.Warnf("grpcConection.close error: %v", err)
 		}
 
 		wg.Done()
@@ -166,7 +346,7 @@ func (fcd *FlowDriver) reportStatus(ctx context.Context, wg *sync.WaitGroup, mas
 
 	stream, err := client.SendFlowExecutionStatus(ctx)
 	if err != nil {
-		log.Printf("Failed to create stream on SendFlowExecutionStatus: %v", err)
+		logger.Errorf("Failed to create stream on SendFlowExecutionStatus: %v", err)
 		return
 	}
 
@@ -176,9 +356,9 @@ func (fcd *FlowDriver) reportStatus(ctx context.Context, wg *sync.WaitGroup, mas
 		case <-stopChan:
 			fcd.status.Driver.StopTime = time.Now().UnixNano()
 			if err = stream.Send(fcd.status); err == nil {
-				log.Printf("Saved Job Status URL http://%s/job/%d", fcd.Option.Master, fcd.status.GetId())
+				logger.Infof("Saved Job Status URL http://%s/job/%d", fcd.Option.Master, fcd.status.GetId())
 			} else {
-				log.Printf("Failed to update Job Status http://%s/job/%d : %v", fcd.Option.Master, fcd.status.GetId(), err)
+				logger.Errorf("Failed to update Job Status http://%s/job/%d : %v", fcd.Option.Master, fcd.status.GetId(), err)
 			}
 			stream.CloseSend()
 			return
@@ -23,6 +23,26 @@ func (fcd *FlowDriver) GetTaskGroupStatus(taskGroup *plan.TaskGroup) *pb.FlowExe
 	return nil
 }
 
+// collectPeekedRows sets the stats reported by the executors to the tasks of
+// the peeked steps, for Dataset.PeekedRows().
+func (fcd *FlowDriver) collectPeekedRows() {
+	for _, taskGroup := range fcd.taskGroups {
+		status := fcd.GetTaskGroupStatus(taskGroup)
+		if status == nil {
+			continue
+		}
+		for _, execution := range status.Executions {
+			for _, stat := range execution.GetExecutionStat().GetStats() {
+				for _, task := range taskGroup.Tasks {
+					if task.Step.PeekCount > 0 && int32(task.Step.Id) == stat.StepId && int32(task.Id) == stat.TaskId {
+						task.Stat = stat
+					}
+				}
+			}
+		}
+	}
+}
+
 func (fcd *FlowDriver) logExecutionPlan(fc *flow.Flow) {
 
 	for _, step := range fc.Steps {
@@ -0,0 +1,127 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"google.golang.org/grpc"
+)
+
+func TestFirstError(t *testing.T) {
+	fcd := NewFlowDriver(&Option{})
+	if err := fcd.Err(); err != nil {
+		t.Fatalf("unexpected error before running: %v", err)
+	}
+
+	first := errors.New("first")
+	fcd.setErr(first)
+	fcd.setErr(errors.New("second"))
+	if err := fcd.Err(); err != first {
+		t.Errorf("error %v, expected %v", err, first)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	f := flow.New("testValidationError")
+	f.Slices([][]interface{}{{"a"}}).Hint(flow.FieldCount(1)).Sort("sort", flow.Field(2)).
+		Fprintf(ioutil.Discard, "%v\n")
+
+	fcd := NewFlowDriver(&Option{})
+	fcd.RunFlowContext(context.Background(), f)
+	if _, ok := fcd.Err().(flow.ValidationErrors); !ok {
+		t.Errorf("error %v, expected the validation errors", fcd.Err())
+	}
+}
+
+// bucketingMaster records the saved buckets.
+type bucketingMaster struct {
+	pb.GleamMasterServer
+	saved []*pb.Bucketing
+}
+
+func (m *bucketingMaster) SaveBucketing(ctx context.Context, in *pb.Bucketing) (*pb.Empty, error) {
+	m.saved = append(m.saved, in)
+	return &pb.Empty{}, nil
+}
+
+func TestSaveBucketings(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	master := &bucketingMaster{}
+	server := grpc.NewServer()
+	pb.RegisterGleamMasterServer(server, master)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	f := flow.New("testSaveBucketings")
+	f.Slices([][]interface{}{{"a", 1}}).BucketBy("testSaveBucketings", []int{2, 1}, 4)
+
+	// the gRPC port of the master is 10000 more than its port
+	port := listener.Addr().(*net.TCPAddr).Port - 10000
+	fcd := NewFlowDriver(&Option{Master: fmt.Sprintf("127.0.0.1:%d", port)})
+	fcd.saveBucketings(f)
+	if len(master.saved) != 1 {
+		t.Fatalf("saved the buckets %v", master.saved)
+	}
+	saved := master.saved[0]
+	if saved.GetName() != "testSaveBucketings" || saved.GetBucketCount() != 4 || !reflect.DeepEqual(saved.GetKeyFields(), []int32{2, 1}) {
+		t.Errorf("saved the buckets as %v", saved)
+	}
+}
+
+func TestIsSmallFlow(t *testing.T) {
+	sized := func(f *flow.Flow, size int64) *flow.Dataset {
+		return f.Source("sized", func(io.Writer, *pb.InstructionStat) error { return nil }).Hint(flow.TotalSize(size))
+	}
+	tests := []struct {
+		name    string
+		build   func(f *flow.Flow)
+		isSmall bool
+	}{
+		{"below the threshold", func(f *flow.Flow) {
+			sized(f, 9).Fprintf(ioutil.Discard, "%s\n")
+		}, true},
+		{"at the threshold", func(f *flow.Flow) {
+			sized(f, 10).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"unknown size", func(f *flow.Flow) {
+			f.Source("unknown", func(io.Writer, *pb.InstructionStat) error { return nil }).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"secrets", func(f *flow.Flow) {
+			sized(f, 1).Map("map", "x").Hint(flow.Secret("TOKEN", "token")).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"node selector", func(f *flow.Flow) {
+			sized(f, 1).Map("map", "x").Hint(flow.NodeSelector("ssd=true")).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"tolerations", func(f *flow.Flow) {
+			sized(f, 1).Map("map", "x").Hint(flow.Tolerate("gpu")).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"cached output", func(f *flow.Flow) {
+			sized(f, 1).Map("map", "x").Cache("testIsSmallFlow").Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"cached input", func(f *flow.Flow) {
+			f.Cached("testIsSmallFlow", 1).Hint(flow.TotalSize(1)).Fprintf(ioutil.Discard, "%s\n")
+		}, false},
+		{"bucketed output", func(f *flow.Flow) {
+			sized(f, 1).BucketBy("testIsSmallFlow", []int{1}, 2)
+		}, false},
+	}
+	fcd := NewFlowDriver(&Option{LocalThresholdMB: 10})
+	for _, test := range tests {
+		f := flow.New("testIsSmallFlow")
+		test.build(f)
+		if isSmall := fcd.isSmallFlow(f); isSmall != test.isSmall {
+			t.Errorf("%s: small %v, expected %v", test.name, isSmall, test.isSmall)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package scheduler
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"time"
@@ -11,14 +10,14 @@ import (
 	"context"
 	"github.com/lovelly/gleam/distributed/resource"
 	"github.com/lovelly/gleam/pb"
-	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 	"google.golang.org/grpc"
 )
 
 func sendRelatedFile(ctx context.Context, client pb.GleamAgentClient, flowHashCode uint32, relatedFile resource.FileResource) error {
 	fh, err := resource.GenerateFileHash(relatedFile.FullPath)
 	if err != nil {
-		log.Printf("Failed2 to read %s: %v", relatedFile.FullPath, err)
+		logger.Errorf("Failed2 to read %s: %v", relatedFile.FullPath, err)
 		return err
 	}
 
@@ -31,19 +30,19 @@ func sendRelatedFile(ctx context.Context, client pb.GleamAgentClient, flowHashCo
 
 	stream, err := client.SendFileResource(ctx, grpc.WaitForReady(true))
 	if err != nil {
-		log.Printf("%v.SendFileResource(_) = _, %v", client, err)
+		logger.Errorf("%v.SendFileResource(_) = _, %v", client, err)
 		return err
 	}
 
 	err = stream.Send(fileResourceRequest)
 	if err != nil {
-		log.Printf("%v.SendFirstFileResource(_) = _, %v", client, err)
+		logger.Errorf("%v.SendFirstFileResource(_) = _, %v", client, err)
 		return err
 	}
 
 	fileResourceResponse, err := stream.Recv()
 	if err != nil {
-		log.Printf("%v.CheckFileResourceExists(_) = _, %v", client, err)
+		logger.Errorf("%v.CheckFileResourceExists(_) = _, %v", client, err)
 		return err
 	}
 
@@ -54,7 +53,7 @@ func sendRelatedFile(ctx context.Context, client pb.GleamAgentClient, flowHashCo
 
 	f, err := os.Open(relatedFile.FullPath)
 	if err != nil {
-		log.Printf("OpenFile %s error: %v", relatedFile.FullPath, err)
+		logger.Errorf("OpenFile %s error: %v", relatedFile.FullPath, err)
 		stream.CloseSend()
 		return err
 	}
@@ -67,7 +66,7 @@ func sendRelatedFile(ctx context.Context, client pb.GleamAgentClient, flowHashCo
 			break
 		}
 		if err != nil {
-			log.Printf("File Read %s error: %v", relatedFile.FullPath, err)
+			logger.Errorf("File Read %s error: %v", relatedFile.FullPath, err)
 			return err
 		}
 		fileResource := &pb.FileResourceRequest{
@@ -78,7 +77,7 @@ func sendRelatedFile(ctx context.Context, client pb.GleamAgentClient, flowHashCo
 		}
 		err = stream.Send(fileResource)
 		if err != nil {
-			log.Printf("%v.Send file %s: %v", client, fileResource.Name, err)
+			logger.Errorf("%v.Send file %s: %v", client, fileResource.Name, err)
 			return err
 		}
 	}
@@ -98,10 +97,10 @@ func sendExecutionRequest(ctx context.Context,
 	server string, request *pb.ExecutionRequest) error {
 
 	return withClient(server, func(client pb.GleamAgentClient) error {
-		log.Printf("%s %v> starting with %v MB memory...\n", server, request.InstructionSet.Name, request.GetResource().GetMemoryMb())
+		logger.Infof("%s %v> starting with %v MB memory...", server, request.InstructionSet.Name, request.GetResource().GetMemoryMb())
 		stream, err := client.Execute(ctx, request, grpc.FailFast(false))
 		if err != nil {
-			log.Printf("sendExecutionRequest.Execute: %v", err)
+			logger.Errorf("sendExecutionRequest.Execute: %v", err)
 			return err
 		}
 
@@ -113,11 +112,11 @@ func sendExecutionRequest(ctx context.Context,
 				break
 			}
 			if err != nil {
-				log.Printf("sendExecutionRequest %v stream from %s: %v", request.GetInstructionSet().GetName(), server, err)
+				logger.Errorf("sendExecutionRequest %v stream from %s: %v", request.GetInstructionSet().GetName(), server, err)
 				break
 			}
 			if response.GetError() != nil {
-				log.Printf("%s %v>%s", server, request.InstructionSet.Name, string(response.GetError()))
+				logger.Errorf("%s %v>%s", server, request.InstructionSet.Name, string(response.GetError()))
 				executionStatus.Error = response.GetError()
 			}
 			if response.GetOutput() != nil {
@@ -182,35 +181,72 @@ func sendDeleteRequest(server string, request *pb.DeleteDatasetShardRequest) err
 	return withClient(server, func(client pb.GleamAgentClient) error {
 		_, err := client.Delete(context.Background(), request, grpc.FailFast(false))
 		if err != nil {
-			log.Printf("%v.Delete(_) = _, %v", client, err)
+			logger.Errorf("%v.Delete(_) = _, %v", client, err)
 		}
 		return err
 	})
 }
 
+func sendAuthorizeRequest(server string, request *pb.AuthorizeRequest) error {
+	return withClient(server, func(client pb.GleamAgentClient) error {
+		response, err := client.Authorize(context.Background(), request, grpc.FailFast(false))
+		if err != nil {
+			logger.Errorf("%v.Authorize(_) = _, %v", client, err)
+			return err
+		}
+		if response.GetError() != "" {
+			return fmt.Errorf("%s", response.GetError())
+		}
+		return nil
+	})
+}
+
+// cachedShardsTimeout limits looking up the cached shards, as the agents
+// keeping them may be gone.
+const cachedShardsTimeout = 10 * time.Second
+
+// checkCachedShards binds the shards the agent keeps to the flow, failing
+// if it does not keep all of them, or is not reachable.
+func checkCachedShards(server string, request *pb.AuthorizeRequest) error {
+	grpcConnection, err := connections.get(server)
+	if err != nil {
+		return fmt.Errorf("driver dial agent: %v", err)
+	}
+	client := pb.NewGleamAgentClient(grpcConnection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cachedShardsTimeout)
+	defer cancel()
+	response, err := client.Authorize(ctx, request)
+	if err != nil {
+		connections.evict(server, grpcConnection)
+		return err
+	}
+	if response.GetError() != "" {
+		return fmt.Errorf("%s", response.GetError())
+	}
+	return nil
+}
+
 func SendCleanupRequest(server string, request *pb.CleanupRequest) error {
 	return withClient(server, func(client pb.GleamAgentClient) error {
 		_, err := client.Cleanup(context.Background(), request, grpc.FailFast(false))
 		if err != nil {
-			log.Printf("%v.Delete(_) = _, %v", client, err)
+			logger.Errorf("%v.Delete(_) = _, %v", client, err)
 		}
 		return err
 	})
 }
 
 func withClient(server string, fn func(client pb.GleamAgentClient) error) error {
-	grpcConnection, err := util.GleamGrpcDial(server,
-		grpc.WithInsecure(),
-		grpc.WithBlock(),
-	)
+	grpcConnection, err := connections.get(server, grpc.WithBlock())
 	if err != nil {
 		return fmt.Errorf("driver dial agent: %v", err)
 	}
-	defer func() {
-		time.Sleep(50 * time.Millisecond)
-		grpcConnection.Close()
-	}()
 	client := pb.NewGleamAgentClient(grpcConnection)
 
-	return fn(client)
+	err = fn(client)
+	if err != nil {
+		connections.evict(server, grpcConnection)
+	}
+	return err
 }
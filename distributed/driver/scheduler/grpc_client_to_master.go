@@ -1,27 +1,72 @@
 package scheduler
 
 import (
-	"log"
+	"context"
 	"time"
 
-	"context"
 	"github.com/lovelly/gleam/pb"
-	"github.com/lovelly/gleam/util"
-	"google.golang.org/grpc"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func getResources(master string, request *pb.ComputeRequest) (*pb.AllocationResult, error) {
 
-	grpcConection, err := util.GleamGrpcDial(master, grpc.WithInsecure())
+	grpcConection, err := connections.get(master)
 	if err != nil {
-		log.Printf("fail to dial %s: %v", master, err)
+		logger.Errorf("fail to dial %s: %v", master, err)
+		return nil, err
 	}
-	defer func() {
-		time.Sleep(50 * time.Millisecond)
-		grpcConection.Close()
-	}()
 
 	client := pb.NewGleamMasterClient(grpcConection)
 
 	return client.GetResources(context.Background(), request)
 }
+
+func getCachedShards(master string, request *pb.CachedShardsRequest) (*pb.CachedShardsResponse, error) {
+
+	grpcConection, err := connections.get(master)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewGleamMasterClient(grpcConection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cachedShardsTimeout)
+	defer cancel()
+	return client.GetCachedShards(ctx, request)
+}
+
+// bucketingTimeout limits saving and looking up the buckets on the master.
+const bucketingTimeout = 10 * time.Second
+
+// SaveBucketing keeps how the buckets written by a flow are bucketed on the
+// master.
+func SaveBucketing(master string, bucketing *pb.Bucketing) error {
+
+	grpcConection, err := connections.get(master)
+	if err != nil {
+		return err
+	}
+
+	client := pb.NewGleamMasterClient(grpcConection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketingTimeout)
+	defer cancel()
+	_, err = client.SaveBucketing(ctx, bucketing)
+	return err
+}
+
+// GetBucketing looks up how the buckets of the name are bucketed on the
+// master, without the bucket count if they are not known.
+func GetBucketing(master string, name string) (*pb.Bucketing, error) {
+
+	grpcConection, err := connections.get(master)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewGleamMasterClient(grpcConection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketingTimeout)
+	defer cancel()
+	return client.GetBucketing(ctx, &pb.BucketingRequest{Name: name})
+}
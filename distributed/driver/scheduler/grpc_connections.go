@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/lovelly/gleam/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connections keeps one grpc connection per agent or master, shared by the
+// flows running in the same driver process, e.g. concurrent sql queries.
+// grpc multiplexes the calls and reconnects broken connections. Connections
+// failing to reconnect, e.g. to the agents removed from the cluster, are
+// closed and dialed again if needed.
+var connections = &connectionPool{conns: make(map[string]*grpc.ClientConn)}
+
+type connectionPool struct {
+	sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func (p *connectionPool) get(server string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	p.Lock()
+	p.evictBroken()
+	conn, found := p.conns[server]
+	p.Unlock()
+	if found {
+		return conn, nil
+	}
+
+	// not blocking other servers while dialing
+	conn, err := util.GleamGrpcDial(server, append(opts, grpc.WithInsecure())...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	if existing, found := p.conns[server]; found {
+		conn.Close()
+		return existing, nil
+	}
+	p.conns[server] = conn
+	return conn, nil
+}
+
+// evict closes the connection to the server if it is broken, e.g. after a
+// failed call, unless it was replaced already.
+func (p *connectionPool) evict(server string, conn *grpc.ClientConn) {
+	p.Lock()
+	defer p.Unlock()
+	if p.conns[server] == conn && isBroken(conn) {
+		delete(p.conns, server)
+		conn.Close()
+	}
+}
+
+func (p *connectionPool) evictBroken() {
+	for server, conn := range p.conns {
+		if isBroken(conn) {
+			delete(p.conns, server)
+			conn.Close()
+		}
+	}
+}
+
+func isBroken(conn *grpc.ClientConn) bool {
+	state := conn.GetState()
+	return state == connectivity.TransientFailure || state == connectivity.Shutdown
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestConnectionPoolEvictsBroken(t *testing.T) {
+	p := &connectionPool{conns: make(map[string]*grpc.ClientConn)}
+	server := "127.0.0.1:1"
+
+	conn, err := p.get(server)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() {
+		for _, c := range p.conns {
+			c.Close()
+		}
+	}()
+	if again, _ := p.get(server); again != conn {
+		t.Fatalf("dialed again for a cached connection")
+	}
+
+	// kept while not broken
+	p.evict(server, conn)
+	if p.conns[server] != conn {
+		t.Fatalf("evicted a connection not broken")
+	}
+
+	conn.Close()
+	redialed, err := p.get(server)
+	if err != nil {
+		t.Fatalf("dial again: %v", err)
+	}
+	if redialed == conn {
+		t.Errorf("reused the closed connection")
+	}
+
+	p.conns["127.0.0.1:2"] = conn
+	p.get(server)
+	if _, found := p.conns["127.0.0.1:2"]; found {
+		t.Errorf("kept the broken connection of another server")
+	}
+}
@@ -19,6 +19,7 @@ type Scheduler struct {
 	Market       *market.Market
 	Option       *Option
 	shardLocator *DatasetShardLocator
+	taskSlots    chan bool // limits the concurrent task groups, if set
 }
 
 type RemoteExecutorStatus struct {
@@ -41,6 +42,18 @@ type Option struct {
 	TaskMemoryMB int
 	Module       string
 	IsProfiling  bool
+	// NetworkBytesPerSecond limits how fast the flow writes to each agent, 0 means no limit
+	NetworkBytesPerSecond int64
+	AccessToken           string
+	// StepMemoryMB overrides the memory estimates of the tasks, by step name
+	StepMemoryMB       map[string]int
+	MaxConcurrentTasks int
+	// RetryWaitTimes are the delays before retrying failed restartable tasks
+	RetryWaitTimes []time.Duration
+	// MinTaskMemoryMB is the least memory requested for each task group, if set
+	MinTaskMemoryMB int
+	// Compression is the codec of the on disk shards without their own
+	Compression string
 }
 
 func New(leader string, option *Option) *Scheduler {
@@ -56,6 +69,9 @@ func New(leader string, option *Option) *Scheduler {
 		shardLocator: NewDatasetShardLocator(),
 		Option:       option,
 	}
+	if option.MaxConcurrentTasks > 0 {
+		s.taskSlots = make(chan bool, option.MaxConcurrentTasks)
+	}
 	s.Market.SetScoreFunction(s.Score).SetFetchFunction(s.Fetch)
 	return s
 }
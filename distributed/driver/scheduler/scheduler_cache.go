@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/lovelly/gleam/distributed/plan"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// cachedShards are the locations of the shards of the cached datasets
+// written by the flows of this driver, until the heartbeats of their agents
+// report them to the master. See Dataset.Cache().
+var cachedShards = struct {
+	sync.Mutex
+	locations map[string]pb.DataLocation
+}{
+	locations: make(map[string]pb.DataLocation),
+}
+
+// ForgetCachedShard stops reusing a shard of a cached dataset, e.g. after
+// a task failed to read it.
+func ForgetCachedShard(name string) {
+	cachedShards.Lock()
+	defer cachedShards.Unlock()
+	delete(cachedShards.locations, name)
+}
+
+// lookupCachedShards finds the agents keeping the shards of cached datasets
+// by their names, as reported to the master, or else as written by the
+// flows of this driver.
+func lookupCachedShards(master string, names []string) map[string]*pb.Location {
+	found := make(map[string]*pb.Location)
+	if response, err := getCachedShards(master, &pb.CachedShardsRequest{Names: names}); err != nil {
+		logger.Warnf("Failed to look up the cached shards on %s: %v", master, err)
+	} else {
+		for _, location := range response.GetLocations() {
+			found[location.GetName()] = location.GetLocation()
+		}
+	}
+
+	cachedShards.Lock()
+	defer cachedShards.Unlock()
+	for _, name := range names {
+		if location, ok := cachedShards.locations[name]; ok && found[name] == nil {
+			found[name] = location.Location
+		}
+	}
+	return found
+}
+
+// hasCachedOutput tells whether the task group writes the shard of a
+// cached dataset on an agent.
+func hasCachedOutput(taskGroup *plan.TaskGroup) bool {
+	lastTask := taskGroup.Tasks[len(taskGroup.Tasks)-1]
+	ds := lastTask.Step.OutputDataset
+	return ds != nil && ds.Meta.CacheName != "" && !lastTask.Step.IsOnDriverSide
+}
+
+// UseCachedOutput registers the cached shards written by the task group in
+// an earlier flow, and tells whether all of them are found, so the task
+// group does not need to run. The agents are asked to bind the shards to
+// this flow, and the shards they no longer keep, e.g. after they were purged
+// or the agent restarted, are forgotten.
+func (s *Scheduler) UseCachedOutput(taskGroup *plan.TaskGroup) bool {
+	if !hasCachedOutput(taskGroup) {
+		return false
+	}
+	outputShards := taskGroup.Tasks[len(taskGroup.Tasks)-1].OutputShards
+	var names []string
+	for _, shard := range outputShards {
+		names = append(names, shard.Name())
+	}
+	locations := lookupCachedShards(s.Master, names)
+	if len(locations) < len(names) {
+		return false
+	}
+
+	agentShards := make(map[string][]string)
+	for _, name := range names {
+		server := locations[name].URL()
+		agentShards[server] = append(agentShards[server], name)
+	}
+	for server, shardNames := range agentShards {
+		if err := checkCachedShards(server, &pb.AuthorizeRequest{
+			Names:                 shardNames,
+			AccessToken:           s.Option.AccessToken,
+			FlowHashCode:          s.Option.FlowHashcode,
+			NetworkBytesPerSecond: s.Option.NetworkBytesPerSecond,
+			IsExisting:            true,
+		}); err != nil {
+			logger.Warnf("Failed to reuse the cached shards on %s: %v", server, err)
+			for _, name := range shardNames {
+				ForgetCachedShard(name)
+			}
+			return false
+		}
+	}
+
+	for _, shard := range outputShards {
+		s.setShardLocation(shard, pb.DataLocation{
+			Name:        shard.Name(),
+			Location:    locations[shard.Name()],
+			OnDisk:      shard.Dataset.GetIsOnDiskIO(),
+			Compression: s.compressionOf(shard.Dataset),
+			AccessToken: s.Option.AccessToken,
+		})
+	}
+	taskGroup.MarkStop(nil)
+	return true
+}
+
+// DeleteCachedShards deletes the shards of a cached dataset from the agents
+// keeping them, found by the master, e.g. after the next iteration of a loop
+// read them. The shards not found are already gone.
+func DeleteCachedShards(master string, names []string) {
+	var wg sync.WaitGroup
+	for name, location := range lookupCachedShards(master, names) {
+		ForgetCachedShard(name)
+		wg.Add(1)
+		go func(name string, location *pb.Location) {
+			defer wg.Done()
+			if err := sendDeleteRequest(location.URL(), &pb.DeleteDatasetShardRequest{Name: name}); err != nil {
+				logger.Warnf("Failed to delete the cached shard %s: %v", name, err)
+			}
+		}(name, location)
+	}
+	wg.Wait()
+}
+
+// forgetCachedInputs stops reusing the cached shards read by the task, after
+// it failed, e.g. as their agent is gone.
+func forgetCachedInputs(task *flow.Task) {
+	for _, shard := range task.InputShards {
+		if shard.Dataset.Meta.CacheName != "" {
+			ForgetCachedShard(shard.Name())
+		}
+	}
+}
+
+// cacheOutput remembers where the task group wrote the shards of a cached
+// dataset, for the later flows.
+func (s *Scheduler) cacheOutput(taskGroup *plan.TaskGroup) {
+	cachedShards.Lock()
+	defer cachedShards.Unlock()
+	for _, shard := range taskGroup.Tasks[len(taskGroup.Tasks)-1].OutputShards {
+		if location, found := s.GetShardLocation(shard); found {
+			cachedShards.locations[shard.Name()] = location
+		}
+	}
+}
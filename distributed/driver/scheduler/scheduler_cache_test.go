@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/distributed/plan"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"google.golang.org/grpc"
+)
+
+// testMaster reports the cached shards kept by its agents.
+type testMaster struct {
+	pb.GleamMasterServer
+	locations []*pb.DataLocation
+}
+
+func (m *testMaster) GetCachedShards(ctx context.Context, in *pb.CachedShardsRequest) (*pb.CachedShardsResponse, error) {
+	return &pb.CachedShardsResponse{Locations: m.locations}, nil
+}
+
+// testAgent keeps the shards of the names, and records their tokens.
+type testAgent struct {
+	pb.GleamAgentServer
+	sync.Mutex
+	kept   map[string]bool
+	tokens map[string]string
+}
+
+func (a *testAgent) Authorize(ctx context.Context, in *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+	a.Lock()
+	defer a.Unlock()
+	for _, name := range in.GetNames() {
+		if in.GetIsExisting() && !a.kept[name] {
+			return &pb.AuthorizeResponse{Error: fmt.Sprintf("%s is not kept by the agent", name)}, nil
+		}
+	}
+	for _, name := range in.GetNames() {
+		a.tokens[name] = in.GetAccessToken()
+	}
+	return &pb.AuthorizeResponse{}, nil
+}
+
+func (a *testAgent) Delete(ctx context.Context, in *pb.DeleteDatasetShardRequest) (*pb.DeleteDatasetShardResponse, error) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.kept, in.GetName())
+	return &pb.DeleteDatasetShardResponse{}, nil
+}
+
+// serveGrpc serves on an ephemeral port, and returns the location dialed on
+// it, whose port is 10000 less than the gRPC port.
+func serveGrpc(t *testing.T, register func(server *grpc.Server)) (*pb.Location, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	register(server)
+	go server.Serve(listener)
+	port := listener.Addr().(*net.TCPAddr).Port - 10000
+	return &pb.Location{Server: "127.0.0.1", Port: int32(port)}, server.Stop
+}
+
+// cachedTaskGroup plans a flow caching a dataset of 2 shards by the name,
+// and returns the task group writing it.
+func cachedTaskGroup(t *testing.T, name string) *plan.TaskGroup {
+	f := flow.New(name)
+	f.Slices([][]interface{}{{1}, {2}}).RoundRobin("shards", 2).Cache(name).
+		Fprintf(nil, "%v\n")
+	_, taskGroups := plan.GroupTasks(f)
+	for _, taskGroup := range taskGroups {
+		if hasCachedOutput(taskGroup) {
+			return taskGroup
+		}
+	}
+	t.Fatalf("no task group writes the cached dataset %s", name)
+	return nil
+}
+
+func TestUseCachedOutput(t *testing.T) {
+	agent := &testAgent{kept: make(map[string]bool), tokens: make(map[string]string)}
+	agentLocation, stopAgent := serveGrpc(t, func(server *grpc.Server) {
+		pb.RegisterGleamAgentServer(server, agent)
+	})
+	defer stopAgent()
+	master := &testMaster{}
+	masterLocation, stopMaster := serveGrpc(t, func(server *grpc.Server) {
+		pb.RegisterGleamMasterServer(server, master)
+	})
+	defer stopMaster()
+
+	names := []string{"c-testUseCachedOutput-s0", "c-testUseCachedOutput-s1"}
+	s := New(masterLocation.URL(), &Option{AccessToken: "token"})
+
+	// not cached yet
+	if s.UseCachedOutput(cachedTaskGroup(t, "testUseCachedOutput")) {
+		t.Errorf("reused the shards not cached")
+	}
+
+	// reported by the heartbeats of the agent
+	for _, name := range names {
+		agent.kept[name] = true
+		master.locations = append(master.locations, &pb.DataLocation{Name: name, Location: agentLocation, OnDisk: true})
+	}
+	taskGroup := cachedTaskGroup(t, "testUseCachedOutput")
+	if !s.UseCachedOutput(taskGroup) {
+		t.Fatalf("failed to reuse the shards reported to the master")
+	}
+	for _, shard := range taskGroup.Tasks[len(taskGroup.Tasks)-1].OutputShards {
+		location, found := s.GetShardLocation(shard)
+		if !found || location.Location.URL() != agentLocation.URL() || location.AccessToken != "token" {
+			t.Errorf("shard %s at %+v", shard.Name(), location)
+		}
+		if agent.tokens[shard.Name()] != "token" {
+			t.Errorf("shard %s is not bound to the flow", shard.Name())
+		}
+	}
+
+	// written by a flow of this driver, not reported yet
+	master.locations = nil
+	cachedShards.Lock()
+	for _, name := range names {
+		cachedShards.locations[name] = pb.DataLocation{Name: name, Location: agentLocation}
+	}
+	cachedShards.Unlock()
+	if !s.UseCachedOutput(cachedTaskGroup(t, "testUseCachedOutput")) {
+		t.Errorf("failed to reuse the shards written by this driver")
+	}
+
+	// purged by the agent
+	delete(agent.kept, names[1])
+	if s.UseCachedOutput(cachedTaskGroup(t, "testUseCachedOutput")) {
+		t.Errorf("reused the shards purged by the agent")
+	}
+	if locations := lookupCachedShards(s.Master, names); len(locations) != 0 {
+		t.Errorf("kept the purged shards at %v", locations)
+	}
+}
+
+func TestForgetCachedInputs(t *testing.T) {
+	f := flow.New("testForgetCachedInputs")
+	cached := f.Slices([][]interface{}{{1}}).RoundRobin("shards", 1).Cache("testForgetCachedInputs")
+	cached.RoundRobin("again", 1).Fprintf(nil, "%v\n")
+
+	name := cached.Shards[0].Name()
+	cachedShards.Lock()
+	cachedShards.locations[name] = pb.DataLocation{Name: name}
+	cachedShards.Unlock()
+
+	forgetCachedInputs(cached.Shards[0].ReadingTasks[0])
+	cachedShards.Lock()
+	defer cachedShards.Unlock()
+	if _, found := cachedShards.locations[name]; found {
+		t.Errorf("kept the cached shard %s after its reader failed", name)
+	}
+}
+
+func TestDeleteCachedShards(t *testing.T) {
+	agent := &testAgent{kept: make(map[string]bool), tokens: make(map[string]string)}
+	agentLocation, stopAgent := serveGrpc(t, func(server *grpc.Server) {
+		pb.RegisterGleamAgentServer(server, agent)
+	})
+	defer stopAgent()
+	master := &testMaster{}
+	masterLocation, stopMaster := serveGrpc(t, func(server *grpc.Server) {
+		pb.RegisterGleamMasterServer(server, master)
+	})
+	defer stopMaster()
+
+	// one shard reported to the master, the other written by this driver
+	names := []string{"c-testDeleteCachedShards-s0", "c-testDeleteCachedShards-s1"}
+	agent.kept[names[0]], agent.kept[names[1]] = true, true
+	master.locations = []*pb.DataLocation{{Name: names[0], Location: agentLocation, OnDisk: true}}
+	cachedShards.Lock()
+	cachedShards.locations[names[1]] = pb.DataLocation{Name: names[1], Location: agentLocation}
+	cachedShards.Unlock()
+
+	DeleteCachedShards(masterLocation.URL(), append(names, "c-testDeleteCachedShards-s2"))
+	agent.Lock()
+	if len(agent.kept) != 0 {
+		t.Errorf("kept %v on the agent", agent.kept)
+	}
+	agent.Unlock()
+	master.locations = nil
+	if locations := lookupCachedShards(masterLocation.URL(), names); len(locations) != 0 {
+		t.Errorf("remembered the deleted shards at %v", locations)
+	}
+}
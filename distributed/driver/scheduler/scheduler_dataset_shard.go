@@ -6,9 +6,15 @@ import (
 	"github.com/lovelly/gleam/distributed/plan"
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 )
 
+// DeleteOutput deletes the output shards of the task group from the agents,
+// unless they are cached for the later flows.
 func (s *Scheduler) DeleteOutput(taskGroup *plan.TaskGroup) {
+	if hasCachedOutput(taskGroup) {
+		return
+	}
 	var wg sync.WaitGroup
 	tasks := taskGroup.Tasks
 	for _, shard := range tasks[len(tasks)-1].OutputShards {
@@ -23,7 +29,7 @@ func (s *Scheduler) DeleteOutput(taskGroup *plan.TaskGroup) {
 			if err := sendDeleteRequest(location.Location.URL(), &pb.DeleteDatasetShardRequest{
 				Name: shard.Name(),
 			}); err != nil {
-				println("Purging dataset error:", err.Error())
+				logger.Warnf("Purging dataset error: %v", err)
 			}
 		}(location, shard)
 	}
@@ -48,6 +54,17 @@ func isInputOnDisk(task *flow.Task) bool {
 	return true
 }
 
+// compressionOf returns the codec of the shards of the dataset kept on disk.
+func (s *Scheduler) compressionOf(d *flow.Dataset) string {
+	if !d.GetIsOnDiskIO() {
+		return ""
+	}
+	if compression := d.GetCompression(); compression != "" {
+		return compression
+	}
+	return s.Option.Compression
+}
+
 func isRestartableTasks(tasks []*flow.Task) bool {
 	for _, task := range tasks {
 		if !task.Step.Meta.IsRestartable {
@@ -3,7 +3,6 @@ package scheduler
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/lovelly/gleam/distributed/netchan"
@@ -11,6 +10,7 @@ import (
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func (s *Scheduler) remoteExecuteOnLocation(ctx context.Context,
@@ -33,7 +33,7 @@ func (s *Scheduler) remoteExecuteOnLocation(ctx context.Context,
 	for _, shard := range firstTask.InputShards {
 		loc, hasLocation := s.GetShardLocation(shard)
 		if !hasLocation {
-			log.Printf("The shard is missing?: %s", shard.Name())
+			logger.Warnf("The shard is missing?: %s", shard.Name())
 			continue
 		}
 		inputLocations = append(inputLocations, loc)
@@ -41,23 +41,31 @@ func (s *Scheduler) remoteExecuteOnLocation(ctx context.Context,
 
 	for _, shard := range lastTask.OutputShards {
 		outputLocations = append(outputLocations, pb.DataLocation{
-			Name:     shard.Name(),
-			Location: allocation.Location,
-			OnDisk:   shard.Dataset.GetIsOnDiskIO(),
+			Name:        shard.Name(),
+			Location:    allocation.Location,
+			OnDisk:      shard.Dataset.GetIsOnDiskIO(),
+			Compression: s.compressionOf(shard.Dataset),
+			AccessToken: s.Option.AccessToken,
 		})
 	}
 
 	firstInstruction.SetInputLocations(inputLocations)
 	lastInstruction.SetOutputLocations(outputLocations)
+	if shardRange := taskGroup.InputShardRange(); shardRange != nil {
+		for _, location := range firstInstruction.InputShardLocations {
+			location.ShardRange = shardRange
+		}
+	}
 
 	instructionSet.FlowHashCode = flowContext.HashCode
 	instructionSet.IsProfiling = s.Option.IsProfiling
 	instructionSet.Name = taskGroup.String()
 
 	request := &pb.ExecutionRequest{
-		InstructionSet: instructionSet,
-		Dir:            s.Option.Module,
-		Resource:       allocation.Allocated,
+		InstructionSet:  instructionSet,
+		Dir:             s.Option.Module,
+		Resource:        allocation.Allocated,
+		ProtocolVersion: pb.ProtocolVersion,
 	}
 	taskGroupStatus.Request = request
 	taskGroupStatus.Allocation = allocation
@@ -65,7 +73,7 @@ func (s *Scheduler) remoteExecuteOnLocation(ctx context.Context,
 	// println("RequestId:", taskGroup.RequestId, instructions.FlowHashCode)
 
 	if err := sendExecutionRequest(ctx, taskGroupStatus, executionStatus, allocation.Location.URL(), request); err != nil {
-		log.Printf("remote execution error: %v", err)
+		logger.Errorf("remote execution error: %v", err)
 		return err
 	}
 
@@ -106,8 +114,8 @@ func (s *Scheduler) localExecuteSource(ctx context.Context,
 		wg.Add(1)
 		go func(shard *flow.DatasetShard) {
 			// println(task.Step.Name, "writing to", shard.Name(), "at", location.Location.URL())
-			if err := netchan.DialWriteChannel(ctx, wg, "driver_input", location.Location.URL(), shard.Name(), shard.Dataset.GetIsOnDiskIO(), shard.IncomingChan.Reader, len(shard.ReadingTasks)); err != nil {
-				println("starting:", task.Step.Name, "output location:", location.Location.URL(), shard.Name(), "error:", err.Error())
+			if err := netchan.DialWriteChannelCompressed(ctx, wg, "driver_input", location.Location.URL(), shard.Name(), location.AccessToken, shard.Dataset.GetIsOnDiskIO(), s.compressionOf(shard.Dataset), shard.IncomingChan.Reader, len(shard.ReadingTasks)); err != nil {
+				logger.Errorf("starting: %s output location: %s %s error: %v", task.Step.Name, location.Location.URL(), shard.Name(), err)
 			}
 		}(shard)
 	}
@@ -127,8 +135,8 @@ func (s *Scheduler) localExecuteOutput(ctx context.Context, flowContext *flow.Fl
 		wg.Add(1)
 		go func(shard *flow.DatasetShard) {
 			// println(task.Step.Name, "reading from", shard.Name(), "at", location.Location.URL(), "to", inChan, "onDisk", shard.Dataset.GetIsOnDiskIO())
-			if err := netchan.DialReadChannel(ctx, wg, "driver_output", location.Location.URL(), shard.Name(), shard.Dataset.GetIsOnDiskIO(), inChan.Writer); err != nil {
-				println("starting:", task.Step.Name, "input location:", location.Location.URL(), shard.Name(), "error:", err.Error())
+			if err := netchan.DialReadChannel(ctx, wg, "driver_output", location.Location.URL(), shard.Name(), location.AccessToken, shard.Dataset.GetIsOnDiskIO(), inChan.Writer); err != nil {
+				logger.Errorf("starting: %s input location: %s %s error: %v", task.Step.Name, location.Location.URL(), shard.Name(), err)
 			}
 		}(shard)
 	}
@@ -2,10 +2,9 @@ package scheduler
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"os"
 	"sync"
-	"time"
 
 	"github.com/lovelly/gleam/distributed/driver/scheduler/market"
 	"github.com/lovelly/gleam/distributed/plan"
@@ -13,16 +12,18 @@ import (
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 // ExecuteTaskGroup wait for inputs and execute the task group remotely.
-// If cancelled, the output will be cleaned up.
+// If cancelled, the output will be cleaned up. It returns the failure of the
+// task group after all its retries.
 func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 	fc *flow.Flow,
 	taskGroupStatus *pb.FlowExecutionStatus_TaskGroup,
 	wg *sync.WaitGroup,
 	taskGroup *plan.TaskGroup,
-	bid float64, relatedFiles []resource.FileResource) {
+	bid float64, relatedFiles []resource.FileResource) error {
 
 	defer wg.Done()
 
@@ -33,9 +34,9 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 		if err := taskGroupStatus.Track(func(exeStatus *pb.FlowExecutionStatus_TaskGroup_Execution) error {
 			return s.localExecute(ctx, fc, exeStatus, lastTask, wg)
 		}); err != nil {
-			log.Fatalf("Failed to execute on driver side: %v", err)
+			return fmt.Errorf("Failed to execute on driver side: %v", err)
 		}
-		return
+		return nil
 	}
 	if !needsInputFromDriver(tasks[0]) {
 		// wait until inputs are registed
@@ -50,6 +51,11 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 
 	// fmt.Printf("inputs of %s is %s\n", tasks[0].Name(), s.allInputLocations(tasks[0]))
 
+	if s.taskSlots != nil {
+		s.taskSlots <- true
+		defer func() { <-s.taskSlots }()
+	}
+
 	pickedServerChan := make(chan market.Supply, 1)
 	s.Market.AddDemand(market.Requirement(taskGroup), bid, pickedServerChan)
 
@@ -58,14 +64,24 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 	allocation := supply.Object.(*pb.Allocation)
 	defer s.Market.ReturnSupply(supply)
 
+	// only this flow can read and write the shards allocated to the agent
+	if err := util.TimeDelayedRetry(func() error {
+		return s.authorizeShards(allocation, tasks[0], lastTask)
+	}, s.Option.RetryWaitTimes...); err != nil {
+		taskGroup.MarkStop(err)
+		return fmt.Errorf("Failed to authorize %s on %s: %v", taskGroup.String(), allocation.Location.URL(), err)
+	}
+
 	if needsInputFromDriver(tasks[0]) {
 		// tell the driver to write to me
 		for _, shard := range tasks[0].InputShards {
 			// println("registering", shard.Name(), "at", allocation.Location.URL())
 			s.setShardLocation(shard, pb.DataLocation{
-				Name:     shard.Name(),
-				Location: allocation.Location,
-				OnDisk:   shard.Dataset.GetIsOnDiskIO(),
+				Name:        shard.Name(),
+				Location:    allocation.Location,
+				OnDisk:      shard.Dataset.GetIsOnDiskIO(),
+				Compression: s.compressionOf(shard.Dataset),
+				AccessToken: s.Option.AccessToken,
 			})
 		}
 	}
@@ -73,9 +89,11 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 	for _, shard := range lastTask.OutputShards {
 		// println("registering", shard.Name(), "at", allocation.Location.URL(), "onDisk", shard.Dataset.GetIsOnDiskIO())
 		s.setShardLocation(shard, pb.DataLocation{
-			Name:     shard.Name(),
-			Location: allocation.Location,
-			OnDisk:   shard.Dataset.GetIsOnDiskIO(),
+			Name:        shard.Name(),
+			Location:    allocation.Location,
+			OnDisk:      shard.Dataset.GetIsOnDiskIO(),
+			Compression: s.compressionOf(shard.Dataset),
+			AccessToken: s.Option.AccessToken,
 		})
 	}
 
@@ -100,7 +118,7 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 			return nil
 		})
 		if err != nil {
-			log.Fatalf("Failed to send related files: %v", err)
+			return fmt.Errorf("Failed to send related files: %v", err)
 		}
 	}
 
@@ -109,17 +127,17 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 			return s.remoteExecuteOnLocation(ctx, fc, taskGroupStatus, exeStatus, taskGroup, allocation, wg)
 		})
 		if err != nil {
-			log.Printf("Failed to remoteExecuteOnLocation %v: %v", allocation, err)
+			logger.Errorf("Failed to remoteExecuteOnLocation %v: %v", allocation, err)
 		}
 		taskGroup.MarkStop(err)
 		return err
 	}
 
-	util.ExecuteWithCleanup(
+	err := util.ExecuteWithCleanup(
 		ctx,
 		func() error {
 			if isRestartableTasks(tasks) {
-				return util.TimeDelayedRetry(fn, time.Minute, 3*time.Minute)
+				return util.TimeDelayedRetry(fn, s.Option.RetryWaitTimes...)
 			} else {
 				return fn()
 			}
@@ -134,12 +152,40 @@ func (s *Scheduler) ExecuteTaskGroup(ctx context.Context,
 					if err := sendDeleteRequest(allocation.Location.URL(), &pb.DeleteDatasetShardRequest{
 						Name: shard.Name(),
 					}); err != nil {
-						println("Purging dataset error:", err.Error())
+						logger.Warnf("Purging dataset error: %v", err)
 					}
 				}(shard)
 			}
 			w.Wait()
 		},
 	)
+	if err != nil {
+		forgetCachedInputs(tasks[0])
+		return err
+	}
+	if hasCachedOutput(taskGroup) {
+		s.cacheOutput(taskGroup)
+	}
+	return nil
+}
 
+// authorizeShards binds the shards the task group writes on the allocated
+// agent, including the inputs written by the driver, to the flow's token,
+// and to the flow's bandwidth limit on the agent.
+func (s *Scheduler) authorizeShards(allocation *pb.Allocation, firstTask, lastTask *flow.Task) error {
+	var names []string
+	if needsInputFromDriver(firstTask) {
+		for _, shard := range firstTask.InputShards {
+			names = append(names, shard.Name())
+		}
+	}
+	for _, shard := range lastTask.OutputShards {
+		names = append(names, shard.Name())
+	}
+	return sendAuthorizeRequest(allocation.Location.URL(), &pb.AuthorizeRequest{
+		Names:                 names,
+		AccessToken:           s.Option.AccessToken,
+		FlowHashCode:          s.Option.FlowHashcode,
+		NetworkBytesPerSecond: s.Option.NetworkBytesPerSecond,
+	})
 }
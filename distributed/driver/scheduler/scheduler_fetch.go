@@ -1,13 +1,13 @@
 package scheduler
 
 import (
-	"log"
 	"math/rand"
 	"time"
 
 	"github.com/lovelly/gleam/distributed/driver/scheduler/market"
 	"github.com/lovelly/gleam/distributed/plan"
 	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 // Requirement is TaskGroup
@@ -18,15 +18,17 @@ func (s *Scheduler) Fetch(demands []market.Demand) {
 	request.Hostname = s.Option.Hostname
 	request.FlowHashCode = s.Option.FlowHashcode
 	request.DataCenter = s.Option.DataCenter
+	request.ProtocolVersion = pb.ProtocolVersion
 	for _, d := range demands {
 		taskGroup := d.Requirement.(*plan.TaskGroup)
 		requiredResource := taskGroup.RequiredResources()
+		requiredResource.MemoryMb = s.memoryCost(taskGroup)
 		request.ComputeResources = append(request.ComputeResources, requiredResource)
 	}
 
 	result, err := getResources(s.Master, &request)
 	if err != nil {
-		log.Printf("%s Failed to allocate: %v", s.Master, err)
+		logger.Errorf("%s Failed to allocate: %v", s.Master, err)
 		time.Sleep(time.Millisecond * time.Duration(15000+rand.Int63n(5000)))
 	} else {
 		if len(result.Allocations) == 0 {
@@ -10,7 +10,12 @@ func (s *Scheduler) Score(r market.Requirement, bid float64, obj market.Object)
 	alloc := obj.(*pb.Allocation)
 	tg, loc := r.(*plan.TaskGroup), alloc.Location
 
-	memCost := memoryCost(tg)
+	// allocations are reused by other task groups
+	if !alloc.Allocated.SameConstraints(tg.RequiredResources()) {
+		return -1
+	}
+
+	memCost := s.memoryCost(tg)
 	if memCost > alloc.Allocated.MemoryMb {
 		return -1
 	}
@@ -28,11 +33,24 @@ func (s *Scheduler) Score(r market.Requirement, bid float64, obj market.Object)
 	return float64(bid) / cost
 }
 
-func memoryCost(tg *plan.TaskGroup) (cost int64) {
+// memoryCost estimates the memory of the task group, unless configured by
+// the step names, capped by the steps' MaxMemoryMB, and requests at least
+// MinTaskMemoryMB if set.
+func (s *Scheduler) memoryCost(tg *plan.TaskGroup) (cost int64) {
 	for _, t := range tg.Tasks {
-		if t.Step.Instruction != nil && t.Step.OutputDataset != nil {
-			cost += int64(t.Step.Instruction.GetMemoryCostInMB(t.Step.OutputDataset.GetPartitionSize()))
+		var taskCost int64
+		if memoryMB, found := s.Option.StepMemoryMB[t.Step.Name]; found {
+			taskCost = int64(memoryMB)
+		} else if t.Step.Instruction != nil && t.Step.OutputDataset != nil {
+			taskCost = int64(t.Step.Instruction.GetMemoryCostInMB(t.Step.OutputDataset.GetPartitionSize()))
 		}
+		if t.Step.MaxMemoryMB > 0 && taskCost > int64(t.Step.MaxMemoryMB) {
+			taskCost = int64(t.Step.MaxMemoryMB)
+		}
+		cost += taskCost
+	}
+	if cost < int64(s.Option.MinTaskMemoryMB) {
+		cost = int64(s.Option.MinTaskMemoryMB)
 	}
 	return
 }
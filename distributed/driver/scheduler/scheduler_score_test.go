@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/distributed/plan"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/instruction"
+)
+
+func TestMemoryCost(t *testing.T) {
+	ds := flow.New("memory cost").Strings([]string{"a", "b"})
+
+	taskGroup := func(maxMemoryMB int) *plan.TaskGroup {
+		return &plan.TaskGroup{Tasks: []*flow.Task{
+			{Step: &flow.Step{Name: "count", Instruction: instruction.NewCountRows(), OutputDataset: ds}},
+			{Step: &flow.Step{Name: "sort", Instruction: instruction.NewLocalSort(nil, 10), OutputDataset: ds, MaxMemoryMB: maxMemoryMB}},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		option      Option
+		maxMemoryMB int
+		expected    int64
+	}{
+		{"estimates", Option{}, 0, 11},
+		{"legacy TaskMemoryMB is not a floor", Option{TaskMemoryMB: 64}, 0, 11},
+		{"floor", Option{MinTaskMemoryMB: 64}, 0, 64},
+		{"floor below the estimates", Option{MinTaskMemoryMB: 5}, 0, 11},
+		{"by step name", Option{StepMemoryMB: map[string]int{"sort": 100}}, 0, 101},
+		{"capped by step", Option{StepMemoryMB: map[string]int{"sort": 100}}, 20, 21},
+	}
+	for _, tt := range tests {
+		option := tt.option
+		s := &Scheduler{Option: &option}
+		if cost := s.memoryCost(taskGroup(tt.maxMemoryMB)); cost != tt.expected {
+			t.Errorf("%s: memory cost %d, expected %d", tt.name, cost, tt.expected)
+		}
+	}
+}
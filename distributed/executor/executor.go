@@ -1,10 +1,10 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"os"
 	"os/exec"
@@ -13,9 +13,12 @@ import (
 	"time"
 
 	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/distributed/secrets"
 	"github.com/lovelly/gleam/instruction"
 	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/script"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 	"github.com/lovelly/gleam/util/on_interrupt"
 )
 
@@ -23,20 +26,38 @@ type ExecutorOption struct {
 	Dir          string
 	AgentAddress string
 	HashCode     uint32
+	Secrets      secrets.SecretsProvider
+	// ReplayDir replays a task locally: the input shards are read from the
+	// files downloaded there by DownloadInputShards(), and the output shards
+	// are written there, instead of to the agents.
+	ReplayDir string
+	// Debugger runs the executables of the Go mappers and reducers, e.g.
+	// "dlv exec --headless --listen=:2345 --", when replaying.
+	Debugger []string
 }
 
 type Executor struct {
 	Option       *ExecutorOption
 	instructions *pb.InstructionSet
+	log          *logger.Logger
 	stats        []*pb.InstructionStat
-	grpcAddress  string
+	// statsLock guards the peeked rows of the stats while they are reported
+	statsLock   sync.Mutex
+	grpcAddress string
+	// writers are the output shards still being written to the agents
+	writers sync.WaitGroup
 }
 
+// writerDrainTimeout limits how long a failed or interrupted executor waits
+// for its output writers to end their shards as aborted.
+const writerDrainTimeout = 10 * time.Second
+
 func NewExecutor(option *ExecutorOption, instructions *pb.InstructionSet) *Executor {
 
 	return &Executor{
 		Option:       option,
 		instructions: instructions,
+		log:          logger.ForFlow(instructions.GetFlowHashCode()),
 	}
 }
 
@@ -45,19 +66,26 @@ func (exe *Executor) ExecuteInstructionSet() error {
 	// start a listener for stats
 	listener, err := net.Listen("tcp", ":0")
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		exe.log.Fatalf("failed to listen: %v", err)
 	}
 	exe.grpcAddress = listener.Addr().String()
 	go exe.serveGrpc(listener)
+	// stop serving when the executor is reused by the agent for another task
+	defer listener.Close()
 
 	//TODO pass in the context
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	var wg sync.WaitGroup
 	on_interrupt.OnInterrupt(func() {
 		// Calling cancel() will stop all the mappers and reducers.
 		cancel()
 
+		// The writers first end the output shards as aborted, so the readers
+		// do not take the partial shards as complete.
+		exe.drainWriters()
+
 		// Wait for all the mappers and reducers to stop.
 		// If we don't wait here, the executor process may exit before the signal is
 		// passed to all of its children processes.
@@ -98,9 +126,12 @@ func (exe *Executor) ExecuteInstructionSet() error {
 		}
 	}
 
+	// a replayed task has no agent to report to
 	var heartbeatWg sync.WaitGroup
-	heartbeatWg.Add(1)
-	go exe.statusHeartbeat(&heartbeatWg, finishedChan)
+	if exe.Option.ReplayDir == "" {
+		heartbeatWg.Add(1)
+		go exe.statusHeartbeat(&heartbeatWg, finishedChan)
+	}
 	defer heartbeatWg.Wait()
 
 	go func() {
@@ -110,15 +141,19 @@ func (exe *Executor) ExecuteInstructionSet() error {
 
 	select {
 	case <-finishedChan:
-		exe.reportStatus()
+		if exe.Option.ReplayDir == "" {
+			exe.reportStatus()
+		}
 	case err := <-ioErrChan:
 		if err != nil {
 			cancel()
+			exe.drainWriters()
 			return err
 		}
 	case err := <-exeErrChan:
 		if err != nil {
 			cancel()
+			exe.drainWriters()
 			return err
 		}
 	}
@@ -126,8 +161,23 @@ func (exe *Executor) ExecuteInstructionSet() error {
 	return nil
 }
 
+// drainWriters waits for the output writers to end their shards, up to
+// writerDrainTimeout, before the executor exits.
+func (exe *Executor) drainWriters() {
+	drained := make(chan struct{})
+	go func() {
+		exe.writers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(writerDrainTimeout):
+		exe.log.Errorf("Failed to drain the output writers in %v", writerDrainTimeout)
+	}
+}
+
 func setupReaders(ctx context.Context, wg *sync.WaitGroup, ioErrChan chan error,
-	i *pb.Instruction, inPiper *util.Piper, isFirst bool) (readers []io.Reader) {
+	instructions *pb.InstructionSet, i *pb.Instruction, inPiper *util.Piper, isFirst bool, replayDir string) (readers []io.Reader) {
 
 	if !isFirst {
 		readers = append(readers, inPiper.Reader)
@@ -136,18 +186,36 @@ func setupReaders(ctx context.Context, wg *sync.WaitGroup, ioErrChan chan error,
 			wg.Add(1)
 			inChan := util.NewPiper()
 			// println(i.GetName(), "connecting to", inputLocation.Address(), "to read", inputLocation.GetName())
+			if replayDir != "" {
+				go func(inputLocation *pb.DatasetShardLocation) {
+					if err := readReplayShard(wg, replayInputFile(replayDir, inputLocation), inChan.Writer); err != nil {
+						ioErrChan <- fmt.Errorf("Failed %s reading downloaded %s: %v", i.GetName(), inputLocation.GetName(), err)
+					}
+				}(inputLocation)
+				readers = append(readers, &util.StoppableReader{PipeReader: inChan.Reader})
+				continue
+			}
+			if shard := openLocalShard(ctx, instructions, i.GetName(), inputLocation); shard != nil {
+				go func(inputLocation *pb.DatasetShardLocation) {
+					if err := readLocalShard(wg, shard, inChan.Writer); err != nil {
+						ioErrChan <- fmt.Errorf("Failed %s reading local %s: %v", i.GetName(), inputLocation.GetName(), err)
+					}
+				}(inputLocation)
+				readers = append(readers, &util.StoppableReader{PipeReader: inChan.Reader})
+				continue
+			}
 			go func(inputLocation *pb.DatasetShardLocation) {
-				err := netchan.DialReadChannel(ctx, wg, i.GetName(), inputLocation.Address(), inputLocation.GetName(), inputLocation.GetOnDisk(), inChan.Writer)
+				err := netchan.DialReadChannelRange(ctx, wg, i.GetName(), inputLocation.Address(), inputLocation.GetName(), inputLocation.GetAccessToken(), inputLocation.GetOnDisk(), inputLocation.GetShardRange(), inChan.Writer)
 				if err != nil {
 					ioErrChan <- fmt.Errorf("Failed %s reading %s from %s: %v", i.GetName(), inputLocation.GetName(), inputLocation.Address(), err)
 				}
 			}(inputLocation)
-			readers = append(readers, inChan.Reader)
+			readers = append(readers, &util.StoppableReader{PipeReader: inChan.Reader})
 		}
 	}
 	return
 }
-func setupWriters(ctx context.Context, wg *sync.WaitGroup, ioErrChan chan error,
+func (exe *Executor) setupWriters(ctx context.Context, wg *sync.WaitGroup, ioErrChan chan error,
 	i *pb.Instruction, outPiper *util.Piper, isLast bool, readerCount int) (writers []io.Writer) {
 
 	if !isLast {
@@ -157,8 +225,24 @@ func setupWriters(ctx context.Context, wg *sync.WaitGroup, ioErrChan chan error,
 			wg.Add(1)
 			outChan := util.NewPiper()
 			// println(i.GetName(), "connecting to", outputLocation.Address(), "to write", outputLocation.GetName(), "readerCount", readerCount)
+			if replayDir := exe.Option.ReplayDir; replayDir != "" {
+				go func(outputLocation *pb.DatasetShardLocation) {
+					if err := writeReplayShard(wg, replayOutputFile(replayDir, outputLocation), outChan.Reader); err != nil {
+						ioErrChan <- fmt.Errorf("Failed %s writing %s locally: %v", i.GetName(), outputLocation.GetName(), err)
+					}
+				}(outputLocation)
+				writers = append(writers, outChan.Writer)
+				continue
+			}
+			exe.writers.Add(1)
+			go func() {
+				// stop writing at once when the task fails
+				<-ctx.Done()
+				outChan.Reader.CloseWithError(ctx.Err())
+			}()
 			go func(outputLocation *pb.DatasetShardLocation) {
-				err := netchan.DialWriteChannel(ctx, wg, i.GetName(), outputLocation.Address(), outputLocation.GetName(), outputLocation.GetOnDisk(), outChan.Reader, readerCount)
+				defer exe.writers.Done()
+				err := netchan.DialWriteChannelCompressed(ctx, wg, i.GetName(), outputLocation.Address(), outputLocation.GetName(), outputLocation.GetAccessToken(), outputLocation.GetOnDisk(), outputLocation.GetCompression(), outChan.Reader, readerCount)
 				if err != nil {
 					ioErrChan <- fmt.Errorf("Failed %s writing %s to %s: %v", i.GetName(), outputLocation.GetName(), outputLocation.Address(), err)
 				}
@@ -177,18 +261,44 @@ func (exe *Executor) executeInstruction(ctx context.Context, wg *sync.WaitGroup,
 
 	defer wg.Done()
 
-	readers := setupReaders(ctx, wg, ioErrChan, i, inChan, isFirst)
-	writers := setupWriters(ctx, wg, ioErrChan, i, outChan, isLast, readerCount)
+	secretEnv, err := exe.lookupSecrets(i)
+	if err != nil {
+		exeErrChan <- err
+		return
+	}
 
+	readers := setupReaders(ctx, wg, ioErrChan, is, i, inChan, isFirst, exe.Option.ReplayDir)
+	writers := exe.setupWriters(ctx, wg, ioErrChan, i, outChan, isLast, readerCount)
+
+	// a failed instruction aborts its outputs, instead of ending them early
+	var instructionErr error
+	fail := func(err error) {
+		instructionErr = err
+		exeErrChan <- err
+	}
 	defer func() {
 		for _, writer := range writers {
-			if c, ok := writer.(io.Closer); ok {
+			if c, ok := writer.(*io.PipeWriter); ok {
+				c.CloseWithError(instructionErr)
+			} else if c, ok := writer.(io.Closer); ok {
 				c.Close()
 			}
 		}
 	}()
 
-	util.BufWrites(writers, func(writers []io.Writer) {
+	if filter := i.GetFilter(); filter != nil {
+		// the predicates run in the binary of the flow, as the mappers
+		filter.Path = filepath.Join(exe.Option.Dir, filepath.Base(filter.Path))
+	}
+
+	outWriters := writers
+	if i.GetPeekCount() > 0 {
+		outWriters = util.PeekWrites(writers, int(i.GetPeekCount()), i.GetScript().GetIsPipe(), stat, &exe.statsLock)
+	}
+
+	setCommandEnv(i, secretEnv)
+
+	util.BufWrites(outWriters, func(writers []io.Writer) {
 		if f := instruction.InstructionRunner.GetInstructionFunction(i); f != nil {
 			if prevIsPipe {
 				var tmpReaders []io.Reader
@@ -200,7 +310,7 @@ func (exe *Executor) executeInstruction(ctx context.Context, wg *sync.WaitGroup,
 			err := f(readers, writers, stat)
 			if err != nil {
 				// println(i.GetName(), "running error", err.Error())
-				exeErrChan <- fmt.Errorf("Failed executing function %s: %v", i.GetName(), err)
+				fail(fmt.Errorf("Failed executing function %s: %v", i.GetName(), err))
 			}
 			return
 		}
@@ -210,7 +320,7 @@ func (exe *Executor) executeInstruction(ctx context.Context, wg *sync.WaitGroup,
 		var err error
 		script := i.GetScript()
 		if script == nil {
-			exeErrChan <- fmt.Errorf("no script provided in instruction")
+			fail(fmt.Errorf("no script provided in instruction"))
 			return
 		}
 
@@ -227,12 +337,33 @@ func (exe *Executor) executeInstruction(ctx context.Context, wg *sync.WaitGroup,
 			executablePath := filepath.Base(script.Path)
 			script.Path = filepath.Join(exe.Option.Dir, executablePath)
 		}
+		if script.Arg != "" {
+			// the argument can be longer than the command line allows
+			argFile, argErr := writeArgFile(exe.Option.Dir, script.Arg)
+			if argErr != nil {
+				fail(fmt.Errorf("Failed to write the argument of %s: %v", i.GetName(), argErr))
+				return
+			}
+			defer os.Remove(argFile)
+			script.Args = append(script.Args, "-gleam.mapperArgFile", argFile)
+		}
 
 		// println("args:", i.GetScript().Args[len(i.GetScript().Args)-1])
 
+		if len(readers) == 0 {
+			// a script without input datasets reads nothing
+			readers = append(readers, bytes.NewReader(nil))
+		}
+
 		for x := 0; x < 3; x++ {
 			command := exec.CommandContext(ctx, script.Path, script.Args...)
+			if len(exe.Option.Debugger) > 0 && !script.IsPipe {
+				args := append([]string{}, exe.Option.Debugger[1:]...)
+				args = append(append(args, script.Path), script.Args...)
+				command = exec.CommandContext(ctx, exe.Option.Debugger[0], args...)
+			}
 			command.Dir = exe.Option.Dir
+			command.Env = commandEnv(script.GetEnv(), secretEnv)
 			// fmt.Fprintf(os.Stderr, "starting %d %d: %v\n", i.StepId, i.TaskId, command.Args)
 			wg.Add(1)
 			err = util.Execute(ctx, wg, stat, i.GetName(), command, readers[0], writers[0], prevIsPipe, script.GetIsPipe(), false, os.Stderr)
@@ -240,13 +371,18 @@ func (exe *Executor) executeInstruction(ctx context.Context, wg *sync.WaitGroup,
 				break
 			}
 			if err != nil {
-				log.Printf("Failed %d time to start %v %v %v:%v", (x + 1), command.Path, command.Args, command.Env, err)
+				logger.ForTask(is.GetFlowHashCode(), i.GetStepId(), i.GetTaskId()).Warnf("Failed %d time to start %v %v %v:%v", (x + 1), command.Path, command.Args, script.GetEnv(), err)
 				time.Sleep(time.Duration(1) * time.Second)
 			}
 		}
 		if err != nil {
-			exeErrChan <- fmt.Errorf("Failed executing command %s: %v", i.GetName(), err)
+			fail(fmt.Errorf("Failed executing command %s: %v", i.GetName(), err))
 		}
 	})
 
 }
+
+// writeArgFile writes the argument of a mapper to a file in the dir.
+func writeArgFile(dir string, arg string) (string, error) {
+	return (&script.Command{Arg: arg}).WriteArgFile(dir)
+}
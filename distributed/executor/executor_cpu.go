@@ -0,0 +1,22 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package executor
+
+import (
+	"syscall"
+)
+
+// cpuTimes returns the system and user time in seconds used by the executor
+// and the commands it has waited for.
+func cpuTimes() (systemTime, userTime float64) {
+	for _, who := range []int{syscall.RUSAGE_SELF, syscall.RUSAGE_CHILDREN} {
+		var usage syscall.Rusage
+		if err := syscall.Getrusage(who, &usage); err != nil {
+			continue
+		}
+		systemTime += float64(usage.Stime.Nano()) / 1e9
+		userTime += float64(usage.Utime.Nano()) / 1e9
+	}
+	return
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package executor
+
+// cpuTimes is not tracked on this platform.
+func cpuTimes() (systemTime, userTime float64) {
+	return 0, 0
+}
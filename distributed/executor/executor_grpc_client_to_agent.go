@@ -2,7 +2,6 @@ package executor
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -23,14 +22,10 @@ func (exe *Executor) statusHeartbeat(wg *sync.WaitGroup, finishedChan chan bool)
 		}
 
 		tickChan := time.Tick(1 * time.Second)
-		stat := &pb.ExecutionStat{
-			FlowHashCode: exe.instructions.FlowHashCode,
-			Stats:        exe.stats,
-		}
 		for {
 			select {
 			case <-tickChan:
-				if err := stream.Send(stat); err != nil {
+				if err := stream.Send(exe.executionStat()); err != nil {
 					return fmt.Errorf("executor Send(%v): %v", exe.stats, err)
 				}
 			case <-finishedChan:
@@ -42,7 +37,7 @@ func (exe *Executor) statusHeartbeat(wg *sync.WaitGroup, finishedChan chan bool)
 	})
 
 	if err != nil {
-		log.Printf("executor heartbeat to agent %v: %v", exe.Option.AgentAddress, err)
+		exe.log.Errorf("executor heartbeat to agent %v: %v", exe.Option.AgentAddress, err)
 	}
 
 }
@@ -56,12 +51,7 @@ func (exe *Executor) reportStatus() {
 		}
 		// defer stream.CloseSend()
 
-		stat := &pb.ExecutionStat{
-			FlowHashCode: exe.instructions.FlowHashCode,
-			Stats:        exe.stats,
-		}
-
-		if err := stream.Send(stat); err != nil {
+		if err := stream.Send(exe.executionStat()); err != nil {
 			return fmt.Errorf("%v.Send(%v) = %v", stream, exe.stats, err)
 		}
 
@@ -69,11 +59,24 @@ func (exe *Executor) reportStatus() {
 	})
 
 	if err != nil {
-		log.Printf("executor reportStatus to %v: %v", exe.Option.AgentAddress, err)
+		exe.log.Errorf("executor reportStatus to %v: %v", exe.Option.AgentAddress, err)
 	}
 
 }
 
+// executionStat copies the stats, whose peeked rows are still being written.
+func (exe *Executor) executionStat() *pb.ExecutionStat {
+	exe.statsLock.Lock()
+	defer exe.statsLock.Unlock()
+	stat := &pb.ExecutionStat{FlowHashCode: exe.instructions.FlowHashCode}
+	for _, s := range exe.stats {
+		copied := *s
+		copied.PeekedRows = append([][]byte(nil), s.PeekedRows...)
+		stat.Stats = append(stat.Stats, &copied)
+	}
+	return stat
+}
+
 func withClient(server string, fn func(client pb.GleamAgentClient) error) error {
 	grpcConnection, err := util.GleamGrpcDial(server,
 		grpc.WithInsecure(),
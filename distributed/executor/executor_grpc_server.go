@@ -28,9 +28,11 @@ func (exe *Executor) CollectExecutionStatistics(stream pb.GleamExecutor_CollectE
 		}
 
 		for _, stat := range stats.Stats {
-			for i, current := range exe.stats {
+			for _, current := range exe.stats {
 				if current.StepId == stat.StepId && current.TaskId == stat.TaskId {
-					exe.stats[i] = stat
+					// keep the rows peeked by the executor
+					current.InputCounter = stat.InputCounter
+					current.OutputCounter = stat.OutputCounter
 					// fmt.Printf("executor received stat: %+v\n", stat)
 					break
 				}
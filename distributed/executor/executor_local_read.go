@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/distributed/store"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// localShard is an input shard mapped from the agent running this executor.
+type localShard struct {
+	reader  *store.MmapShardReader
+	channel *netchan.MapChannel
+}
+
+// openLocalShard maps the input shard if it is a finished on disk shard
+// written to the agent running this executor, after the agent checks the
+// access token. It returns nil if the shard should be read from the agent
+// instead.
+func openLocalShard(ctx context.Context, instructions *pb.InstructionSet, readerName string, inputLocation *pb.DatasetShardLocation) *localShard {
+	if instructions.GetAgentDataDir() == "" || !inputLocation.GetOnDisk() {
+		return nil
+	}
+	if inputLocation.Address() != instructions.GetAgentAddress() {
+		return nil
+	}
+	// partial reads are served by the agent's block index
+	if r := inputLocation.GetShardRange(); r != nil && (r.GetStartRow() != 0 || r.GetStopRow() != 0 ||
+		len(r.GetStartKey()) > 0 || len(r.GetStopKey()) > 0) {
+		return nil
+	}
+
+	log := logger.ForFlow(instructions.GetFlowHashCode())
+	channel, err := netchan.DialMapChannel(ctx, readerName, inputLocation.Address(), inputLocation.GetName(), inputLocation.GetAccessToken())
+	if err != nil {
+		log.Warnf("read %s from agent instead of mmap: %v", inputLocation.GetName(), err)
+		return nil
+	}
+
+	filename := store.DataFileName(instructions.GetAgentDataDir(),
+		store.ShardStoreName(inputLocation.GetName(), int(inputLocation.GetPort())))
+	reader, err := store.OpenMmapShardReader(filename)
+	if err != nil {
+		if err != store.ErrShardNotFinished && err != store.ErrShardCompressed {
+			log.Warnf("read %s from agent instead of mmap: %v", inputLocation.GetName(), err)
+		}
+		channel.Close(false)
+		return nil
+	}
+	return &localShard{reader: reader, channel: channel}
+}
+
+// readLocalShard copies the mapped shard to the channel, closes both, and
+// tells the agent whether the shard is read completely.
+func readLocalShard(wg *sync.WaitGroup, shard *localShard, outChan io.WriteCloser) error {
+	defer wg.Done()
+	defer outChan.Close()
+	defer shard.reader.Close()
+
+	_, err := shard.reader.WriteTo(outChan)
+	shard.channel.Close(err == nil)
+	if err == util.ErrReaderStopped {
+		return nil
+	}
+	return err
+}
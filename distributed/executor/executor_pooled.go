@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// ExecuteInstructionSets runs the instruction sets read from the reader one
+// by one, so that an agent can reuse the executor for the tasks of a flow.
+// After each of them, an ExecutionResponse with its error and cpu times is
+// written to the writer. It returns when the reader is closed.
+func ExecuteInstructionSets(option *ExecutorOption, reader io.Reader, writer io.Writer) error {
+	for {
+		data, err := util.ReadMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read instruction set: %v", err)
+		}
+		instructionSet := &pb.InstructionSet{}
+		if err := proto.Unmarshal(data, instructionSet); err != nil {
+			return fmt.Errorf("Failed to unmarshal instruction set: %v", err)
+		}
+
+		executorOption := *option
+		executorOption.AgentAddress = instructionSet.AgentAddress
+
+		systemTime, userTime := cpuTimes()
+		response := &pb.ExecutionResponse{}
+		if err := NewExecutor(&executorOption, instructionSet).ExecuteInstructionSet(); err != nil {
+			response.Error = []byte(fmt.Sprintf("Failed task %s: %v", instructionSet.GetName(), err))
+		}
+		stopSystemTime, stopUserTime := cpuTimes()
+		response.SystemTime = stopSystemTime - systemTime
+		response.UserTime = stopUserTime - userTime
+
+		if data, err = proto.Marshal(response); err != nil {
+			return fmt.Errorf("Failed to marshal execution response: %v", err)
+		}
+		if err = util.WriteMessage(writer, data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	"google.golang.org/grpc"
+)
+
+// FetchTaskGroup finds the task group running the task of the step in the
+// flow history kept by the master, with the instruction set it executed.
+func FetchTaskGroup(master string, flowId uint32, stepId, taskId int32) (*pb.FlowExecutionStatus_TaskGroup, error) {
+	grpcConnection, err := util.GleamGrpcDial(master, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dial master %s: %v", master, err)
+	}
+	defer grpcConnection.Close()
+
+	client := pb.NewGleamMasterClient(grpcConnection)
+	history, err := client.GetFlowHistory(context.Background(), &pb.FlowHistoryRequest{Id: flowId})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get flow %d from master %s: %v", flowId, master, err)
+	}
+	for _, status := range history.GetStatuses() {
+		for _, taskGroup := range status.GetTaskGroups() {
+			for i, id := range taskGroup.GetStepIds() {
+				if id != stepId || i >= len(taskGroup.GetTaskIds()) || taskGroup.GetTaskIds()[i] != taskId {
+					continue
+				}
+				if taskGroup.GetRequest().GetInstructionSet() == nil {
+					return nil, fmt.Errorf("Flow %d has no instructions recorded for task %d of step %d", flowId, taskId, stepId)
+				}
+				return taskGroup, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("Failed to find task %d of step %d in flow %d", taskId, stepId, flowId)
+}
+
+// DownloadInputShards saves the input shards of the instruction set to the
+// replay directory. The shards downloaded by earlier replays are kept, so a
+// task can be replayed again after the agents remove its input shards.
+// The agents only keep the on disk shards after they are read, for their
+// -dataset.ttl. The shards the agents no longer keep fail the download,
+// instead of waiting for them to be written again.
+func DownloadInputShards(instructions *pb.InstructionSet, dir string) error {
+	if len(instructions.GetInstructions()) == 0 {
+		return nil
+	}
+	for _, inputLocation := range instructions.GetInstructions()[0].GetInputShardLocations() {
+		filename := replayInputFile(dir, inputLocation)
+		if _, err := os.Stat(filename); err == nil {
+			continue
+		}
+		if err := checkShardKept(inputLocation); err != nil {
+			return fmt.Errorf("Failed to find %s on %s: %v", inputLocation.GetName(), inputLocation.Address(), err)
+		}
+		if err := downloadShard(inputLocation, filename); err != nil {
+			return fmt.Errorf("Failed to download %s from %s: %v", inputLocation.GetName(), inputLocation.Address(), err)
+		}
+	}
+	return nil
+}
+
+// shardCheckTimeout limits asking the agent for a shard, as it may be gone.
+const shardCheckTimeout = 10 * time.Second
+
+// checkShardKept asks the agent whether it keeps the shard written
+// completely, since the readers of a missing shard wait until it is written.
+func checkShardKept(location *pb.DatasetShardLocation) error {
+	grpcConnection, err := util.GleamGrpcDial(location.Address(), grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer grpcConnection.Close()
+
+	client := pb.NewGleamAgentClient(grpcConnection)
+	ctx, cancel := context.WithTimeout(context.Background(), shardCheckTimeout)
+	defer cancel()
+	response, err := client.Authorize(ctx, &pb.AuthorizeRequest{
+		Names:       []string{location.GetName()},
+		AccessToken: location.GetAccessToken(),
+		IsExisting:  true,
+	})
+	if err != nil {
+		return err
+	}
+	if response.GetError() != "" {
+		return fmt.Errorf("%s", response.GetError())
+	}
+	return nil
+}
+
+func downloadShard(inputLocation *pb.DatasetShardLocation, filename string) error {
+	tmpFile := filename + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+
+	inChan := util.NewPiper()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var readErr error
+	go func() {
+		readErr = netchan.DialReadChannelRange(context.Background(), &wg, "replay", inputLocation.Address(),
+			inputLocation.GetName(), inputLocation.GetAccessToken(), inputLocation.GetOnDisk(),
+			inputLocation.GetShardRange(), inChan.Writer)
+		// the channel is not closed if the agent can not be reached
+		inChan.Writer.CloseWithError(readErr)
+	}()
+	_, copyErr := io.Copy(f, inChan.Reader)
+	wg.Wait()
+	if closeErr := f.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if readErr != nil {
+		return readErr
+	}
+	if copyErr != nil {
+		return copyErr
+	}
+	return os.Rename(tmpFile, filename)
+}
+
+func replayInputFile(dir string, location *pb.DatasetShardLocation) string {
+	return filepath.Join(dir, location.GetName()+".in")
+}
+
+func replayOutputFile(dir string, location *pb.DatasetShardLocation) string {
+	return filepath.Join(dir, location.GetName()+".out")
+}
+
+// readReplayShard copies a downloaded input shard to the channel, and closes it.
+func readReplayShard(wg *sync.WaitGroup, filename string, outChan io.WriteCloser) error {
+	defer wg.Done()
+	defer outChan.Close()
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(outChan, f)
+	if err == util.ErrReaderStopped {
+		return nil
+	}
+	return err
+}
+
+// writeReplayShard copies the output shard from the channel to a file.
+func writeReplayShard(wg *sync.WaitGroup, filename string, inChan io.Reader) error {
+	defer wg.Done()
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, inChan); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
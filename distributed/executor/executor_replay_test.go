@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+	"google.golang.org/grpc"
+)
+
+// testAgent keeps no shards.
+type testAgent struct {
+	pb.GleamAgentServer
+}
+
+func (a *testAgent) Authorize(ctx context.Context, in *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+	if in.GetIsExisting() {
+		return &pb.AuthorizeResponse{Error: fmt.Sprintf("%s is not kept by the agent", in.GetNames()[0])}, nil
+	}
+	return &pb.AuthorizeResponse{}, nil
+}
+
+func TestDownloadInputShards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the gRPC port of the agent is 10000 more than its data port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterGleamAgentServer(server, &testAgent{})
+	go server.Serve(listener)
+	defer server.Stop()
+	agentPort := int32(listener.Addr().(*net.TCPAddr).Port - 10000)
+
+	instructions := func(location *pb.DatasetShardLocation) *pb.InstructionSet {
+		return &pb.InstructionSet{Instructions: []*pb.Instruction{
+			{InputShardLocations: []*pb.DatasetShardLocation{location}},
+		}}
+	}
+
+	// purged by the agent
+	purged := &pb.DatasetShardLocation{Name: "f1-d1-s0", Host: "127.0.0.1", Port: agentPort, OnDisk: true}
+	err = DownloadInputShards(instructions(purged), dir)
+	if err == nil || !strings.Contains(err.Error(), "f1-d1-s0 is not kept by the agent") {
+		t.Errorf("downloaded the purged shard: %v", err)
+	}
+
+	// the agent is gone
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	closed.Close()
+	gone := &pb.DatasetShardLocation{Name: "f1-d1-s1", Host: "127.0.0.1",
+		Port: int32(closed.Addr().(*net.TCPAddr).Port - 10000), OnDisk: true}
+	if err := DownloadInputShards(instructions(gone), dir); err == nil {
+		t.Errorf("downloaded the shard of a gone agent")
+	}
+
+	// downloaded by an earlier replay
+	if err := ioutil.WriteFile(replayInputFile(dir, gone), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := DownloadInputShards(instructions(gone), dir); err != nil {
+		t.Errorf("failed to reuse the downloaded shard: %v", err)
+	}
+}
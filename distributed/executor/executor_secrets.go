@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// lookupSecrets returns the "NAME=value" environment variables
+// for the secrets of the instruction.
+func (exe *Executor) lookupSecrets(i *pb.Instruction) (env []string, err error) {
+	if len(i.GetSecretEnvs()) == 0 {
+		return nil, nil
+	}
+	if exe.Option.Secrets == nil {
+		return nil, fmt.Errorf("no secrets provider for %s", i.GetName())
+	}
+	for _, s := range i.GetSecretEnvs() {
+		value, err := exe.Option.Secrets.GetSecret(s.GetSecretName())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get secret %s for %s: %v", s.GetSecretName(), i.GetName(), err)
+		}
+		env = append(env, s.GetEnvName()+"="+value)
+	}
+	return env, nil
+}
+
+// commandEnv keeps the executor environment, so the commands still find
+// their PATH, HOME, etc. A nil result also means inheriting it.
+func commandEnv(scriptEnv, secretEnv []string) []string {
+	if len(scriptEnv) == 0 && len(secretEnv) == 0 {
+		return nil
+	}
+	env := os.Environ()
+	env = append(env, scriptEnv...)
+	return append(env, secretEnv...)
+}
+
+// setCommandEnv passes the secrets to the commands started by the
+// instructions run inside the executor, e.g. Filter and PipeAsArgs,
+// without changing the environment of the executor.
+func setCommandEnv(i *pb.Instruction, secretEnv []string) {
+	if len(secretEnv) == 0 {
+		return
+	}
+	if filter := i.GetFilter(); filter != nil {
+		filter.Env = append(filter.Env, secretEnv...)
+	}
+	if pipeAsArgs := i.GetPipeAsArgs(); pipeAsArgs != nil {
+		pipeAsArgs.Env = append(pipeAsArgs.Env, secretEnv...)
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -11,42 +13,81 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
 	a "github.com/lovelly/gleam/distributed/agent"
 	exe "github.com/lovelly/gleam/distributed/executor"
 	m "github.com/lovelly/gleam/distributed/master"
 	"github.com/lovelly/gleam/distributed/netchan"
+	"github.com/lovelly/gleam/distributed/secrets"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 	"github.com/lovelly/gleam/util/on_interrupt"
-	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
 )
 
 var (
 	app = kingpin.New("gleam", "distributed gleam, acts as master, agent, or executor")
 
-	master        = app.Command("master", "Start a master process")
-	masterAddress = master.Flag("address", "listening address host:port").Default(":45326").String()
-	masterLogDir  = master.Flag("logDirectory", "a directory to store execution logs").Default(os.TempDir()).String()
+	logLevel  = app.Flag("log.level", "write the log lines of this level or above: debug, info, warn, or error").Default("info").Enum("debug", "info", "warn", "error")
+	logFormat = app.Flag("log.format", "log line format: text, or json for log collectors").Default(logger.FormatText).Enum(logger.FormatText, logger.FormatJson)
+
+	master             = app.Command("master", "Start a master process")
+	masterAddress      = master.Flag("address", "listening address host:port").Default(":45326").String()
+	masterLogDir       = master.Flag("logDirectory", "a directory to store execution logs").Default(os.TempDir()).String()
+	masterK8sAgents    = master.Flag("k8s.agents", "scale the agents in Kubernetes, deployment/<name> or statefulset/<name>, empty disables scaling").Default("").String()
+	masterK8sNamespace = master.Flag("k8s.namespace", "namespace of the agents, defaults to the namespace of the master pod").Default("").String()
+	masterMinAgents    = master.Flag("agents.min", "the least agents when scaling").Default("1").Int()
+	masterMaxAgents    = master.Flag("agents.max", "the most agents when scaling").Default("10").Int()
+	masterAgentIdle    = master.Flag("agents.idle", "remove agents without tasks for this long when scaling").Default("10m").Duration()
 
-	executor     = app.Command("execute", "Execute an instruction set")
-	executorNote = executor.Flag("note", "description").String()
-	executorDir  = executor.Flag("dir", "working directory of the executor").String()
+	executor        = app.Command("execute", "Execute an instruction set")
+	executorNote    = executor.Flag("note", "description").String()
+	executorDir     = executor.Flag("dir", "working directory of the executor").String()
+	executorSecrets = executor.Flag("secrets", "secrets provider: env for $GLEAM_SECRET_<name>, env:PREFIX_, file:/dir, or vault:URL").Default("env").String()
+	executorPooled  = executor.Flag("pooled", "execute the instruction sets from stdin one by one, until stdin is closed").Bool()
+
+	replay          = app.Command("replay", "Re-run a task of a finished flow locally, e.g. under a debugger or profiler")
+	replayMaster    = replay.Flag("master", "master address").Default("localhost:45326").String()
+	replayFlow      = replay.Flag("flow", "flow id, as shown by the master").Required().Uint32()
+	replayStep      = replay.Flag("step", "step id").Required().Int32()
+	replayTask      = replay.Flag("task", "task id").Required().Int32()
+	replayDir       = replay.Flag("dir", "working directory with the driver executable, to keep the input and output shards").Default(".").String()
+	replaySecrets   = replay.Flag("secrets", "secrets provider: env for $GLEAM_SECRET_<name>, env:PREFIX_, file:/dir, or vault:URL").Default("env").String()
+	replayProfiling = replay.Flag("profiling", "write cpu and memory profiles of the task").Bool()
+	replayDebugger  = replay.Flag("debugger", "run the executables of the Go mappers and reducers by this command, e.g. \"dlv exec --headless --listen=:2345 --\"").Default("").String()
+
+	submit           = app.Command("submit", "Run a flow defined in JSON or YAML by a flow definition server, printing the collected rows as jsonl")
+	submitServer     = submit.Flag("server", "flow definition server address").Default("localhost:45330").String()
+	submitDefinition = submit.Flag("definition", "the JSON or YAML file of the flow definition, - for stdin").Required().String()
+	submitToken      = submit.Flag("token", "access token of the flow definition server").Default("").String()
 
 	agent       = app.Command("agent", "Agent that can accept read, write requests, manage executors")
 	agentOption = &a.AgentServerOption{
-		Dir:          agent.Flag("dir", "agent folder to store computed data").Default(os.TempDir()).String(),
-		Host:         agent.Flag("host", "agent listening host address. Required in 2-way SSL mode.").Default("localhost").String(),
-		Port:         agent.Flag("port", "agent listening port").Default("45327").Int32(),
-		Master:       agent.Flag("master", "master address").Default("localhost:45326").String(),
-		DataCenter:   agent.Flag("dataCenter", "data center name").Default("defaultDataCenter").String(),
-		Rack:         agent.Flag("rack", "rack name").Default("defaultRack").String(),
-		MaxExecutor:  agent.Flag("executor.max", "upper limit of executors").Default(strconv.Itoa(runtime.NumCPU())).Int32(),
-		CPULevel:     agent.Flag("executor.cpu.level", "relative computing power of single cpu core").Default("1").Int32(),
-		MemoryMB:     agent.Flag("memory", "memory limit in MB").Default("1024").Int64(),
-		CleanRestart: agent.Flag("clean.restart", "clean up previous dataset files").Default("true").Bool(),
+		Dir:                agent.Flag("dir", "agent folder to store computed data").Default(os.TempDir()).String(),
+		Host:               agent.Flag("host", "agent listening host address. Required in 2-way SSL mode.").Default("localhost").String(),
+		Port:               agent.Flag("port", "agent listening port").Default("45327").Int32(),
+		Master:             agent.Flag("master", "master address").Default("localhost:45326").String(),
+		DataCenter:         agent.Flag("dataCenter", "data center name").Default("defaultDataCenter").String(),
+		Rack:               agent.Flag("rack", "rack name").Default("defaultRack").String(),
+		MaxExecutor:        agent.Flag("executor.max", "upper limit of executors").Default(strconv.Itoa(runtime.NumCPU())).Int32(),
+		CPULevel:           agent.Flag("executor.cpu.level", "relative computing power of single cpu core").Default("1").Int32(),
+		MemoryMB:           agent.Flag("memory", "memory limit in MB").Default("1024").Int64(),
+		CleanRestart:       agent.Flag("clean.restart", "clean up previous dataset files").Default("true").Bool(),
+		NetworkMBPerSecond: agent.Flag("network.bandwidth", "limit of data sent out in MB per second, 0 means no limit").Default("0").Int64(),
+		IndexShards:        agent.Flag("shard.index", "index on disk shards so row or key ranges can be read without a full scan").Default("false").Bool(),
+		SecretsProvider:    agent.Flag("secrets", "secrets provider for steps: env for $GLEAM_SECRET_<name>, env:PREFIX_, file:/dir, or vault:URL with $VAULT_TOKEN").Default("env").String(),
+		MmapLocalShards:    agent.Flag("shard.mmap", "executors read finished on disk shards of this agent by mmap instead of the socket").Default("true").Bool(),
+		DatasetTTL:         agent.Flag("dataset.ttl", "keep on disk dataset shards for this long after all their readers finish, for debugging").Default("0s").Duration(),
+		ExecutorIdleTime:   agent.Flag("executor.idle", "keep executors for this long to run later tasks of the same flow, 0 starts one executor per task").Default("0s").Duration(),
+		Labels:             agent.Flag("labels", "comma separated key=value labels, e.g. ssd=true,zone=a, for steps to select this agent").Default("").String(),
+		Taints:             agent.Flag("taints", "comma separated key or key=value taints, only steps tolerating them run on this agent").Default("").String(),
+		DrainTimeout:       agent.Flag("drain.timeout", "on SIGTERM, wait this long for running tasks before leaving the master, 0 exits at once. Keep it below the termination grace period, 30s by default in Kubernetes").Default("25s").Duration(),
 	}
 	profiling = agent.Flag("profiling", "enable cpu and memory profiling").Default("false").Bool()
 
@@ -54,92 +95,178 @@ var (
 	writeTopic         = writer.Flag("topic", "Name of a topic").Required().String()
 	writerAgentAddress = writer.Flag("agent", "agent host:port").Default("localhost:45327").String()
 	writeToDisk        = writer.Flag("onDisk", "write to memory").Default("false").Bool()
+	writeToken         = writer.Flag("token", "access token of the topic").Default("").String()
+	writeFormat        = writer.Flag("format", "input format: tsv, csv, jsonl of JSON arrays, or msgpack rows").Default(util.FormatTsv).Enum(util.FormatTsv, util.FormatCsv, util.FormatJsonl, util.FormatMsgpack)
+	writeKeyFields     = writer.Flag("keyFields", "the 1-based key fields, e.g. 2,3, defaults to the first field").Default("").String()
 
 	reader             = app.Command("read", "Read data from a topic, output to console")
 	readTopic          = reader.Flag("topic", "Name of a source topic").Required().String()
 	readerAgentAddress = reader.Flag("agent", "agent host:port").Default("localhost:45327").String()
 	readFromDisk       = reader.Flag("onDisk", "read from memory").Default("false").Bool()
+	readToken          = reader.Flag("token", "access token of the topic").Default("").String()
+	readStartRow       = reader.Flag("startRow", "first row to read, counting from 0").Default("0").Int64()
+	readStopRow        = reader.Flag("stopRow", "stop before this row, 0 means reading to the end").Default("0").Int64()
+	readFormat         = reader.Flag("format", "output format: tsv, csv, jsonl of JSON arrays, or msgpack rows").Default(util.FormatTsv).Enum(util.FormatTsv, util.FormatCsv, util.FormatJsonl, util.FormatMsgpack)
+	readFields         = reader.Flag("fields", "the 1-based fields to output, e.g. 2,3, defaults to all fields").Default("").String()
 )
 
 func main() {
 
-	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	command := kingpin.MustParse(app.Parse(os.Args[1:]))
+	if err := logger.Configure(*logLevel, *logFormat); err != nil {
+		log.Fatalf("Failed to configure the log: %v", err)
+	}
+	logger.RedirectStdLog()
+
+	switch command {
 
 	case master.FullCommand():
-		println("master listening on", *masterAddress)
-		m.RunMaster(*masterAddress, *masterLogDir)
+		var autoscale *m.AutoscaleOption
+		if *masterK8sAgents != "" {
+			scaler, err := m.NewKubernetesScaler(*masterK8sNamespace, *masterK8sAgents)
+			if err != nil {
+				logger.Fatalf("Failed to scale agents %s: %v", *masterK8sAgents, err)
+			}
+			autoscale = &m.AutoscaleOption{
+				Scaler:    scaler,
+				MinAgents: *masterMinAgents,
+				MaxAgents: *masterMaxAgents,
+				IdleTime:  *masterAgentIdle,
+				Interval:  30 * time.Second,
+			}
+		}
+		logger.Infof("master listening on %s", *masterAddress)
+		m.RunMaster(*masterAddress, *masterLogDir, autoscale)
 
 	case executor.FullCommand():
 
+		if *executorPooled {
+			secretsProvider, err := secrets.NewSecretsProvider(*executorSecrets)
+			if err != nil {
+				logger.Fatalf("Failed executor %s: %v", *executorNote, err)
+			}
+			// stdout is only for the execution results
+			results := os.Stdout
+			os.Stdout = os.Stderr
+			if err := exe.ExecuteInstructionSets(&exe.ExecutorOption{
+				Dir:     *executorDir,
+				Secrets: secretsProvider,
+			}, os.Stdin, results); err != nil {
+				logger.Fatalf("Failed executor %s: %v", *executorNote, err)
+			}
+			return
+		}
+
 		rawData, err := ioutil.ReadAll(os.Stdin)
 		if err != nil {
-			log.Fatalf("failed to read stdin: %v", err)
+			logger.Fatalf("failed to read stdin: %v", err)
 		}
 		instructionSet := pb.InstructionSet{}
 		if err := proto.Unmarshal(rawData, &instructionSet); err != nil {
-			log.Fatal("unmarshaling instructions error: ", err)
+			logger.Fatalf("unmarshaling instructions error: %v", err)
 		}
 
 		if instructionSet.IsProfiling {
-			// profiling the gleam executor
-			profilingFile := fmt.Sprintf("exe%d-cpu-%s.pprof", instructionSet.GetFlowHashCode(), strings.Join(instructionSet.InstructionNames(), "-"))
-			pwd, _ := os.Getwd()
-			// println("saving exe pprof to", pwd+"/"+profilingFile)
-			f, err := os.Create(profilingFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-			pprof.StartCPUProfile(f)
-			defer pprof.StopCPUProfile()
-
-			memProfFile := fmt.Sprintf("exe%d-mem-%s.pprof", instructionSet.GetFlowHashCode(), strings.Join(instructionSet.InstructionNames(), "-"))
-			mf, err := os.Create(memProfFile)
-			// println("saving pprof to", pwd+"/"+memProfFile)
-			if err != nil {
-				log.Fatalf("failed to create memory profile file %s: %v", pwd+"/"+memProfFile, err)
-			}
-
-			defer func() {
-				runtime.GC()
-				pprof.Lookup("heap").WriteTo(mf, 0)
+			defer startProfiling(&instructionSet)()
+		}
 
-			}()
+		secretsProvider, err := secrets.NewSecretsProvider(*executorSecrets)
+		if err != nil {
+			logger.Fatalf("Failed task %s: %v", *executorNote, err)
 		}
 
 		if err := exe.NewExecutor(&exe.ExecutorOption{
 			AgentAddress: instructionSet.AgentAddress,
 			Dir:          *executorDir,
+			Secrets:      secretsProvider,
 		}, &instructionSet).ExecuteInstructionSet(); err != nil {
-			log.Fatalf("Failed task %s: %v", *executorNote, err)
+			logger.Fatalf("Failed task %s: %v", *executorNote, err)
+		}
+
+	case replay.FullCommand():
+
+		taskGroup, err := exe.FetchTaskGroup(*replayMaster, *replayFlow, *replayStep, *replayTask)
+		if err != nil {
+			logger.Fatalf("Failed to replay: %v", err)
+		}
+		executions := taskGroup.GetExecutions()
+		if len(executions) > 0 && len(executions[len(executions)-1].GetError()) > 0 {
+			logger.Warnf("task failed in flow %d: %s", *replayFlow, executions[len(executions)-1].GetError())
+		}
+
+		instructionSet := taskGroup.GetRequest().GetInstructionSet()
+		if err := exe.DownloadInputShards(instructionSet, *replayDir); err != nil {
+			logger.Fatalf("Failed to replay: %v", err)
+		}
+		instructionSet.IsProfiling = *replayProfiling
+		if instructionSet.IsProfiling {
+			defer startProfiling(instructionSet)()
+		}
+
+		secretsProvider, err := secrets.NewSecretsProvider(*replaySecrets)
+		if err != nil {
+			logger.Fatalf("Failed to replay: %v", err)
+		}
+
+		logger.Infof("replaying %s in %s", strings.Join(instructionSet.InstructionNames(), ","), *replayDir)
+		if err := exe.NewExecutor(&exe.ExecutorOption{
+			Dir:       *replayDir,
+			Secrets:   secretsProvider,
+			ReplayDir: *replayDir,
+			Debugger:  strings.Fields(*replayDebugger),
+		}, instructionSet).ExecuteInstructionSet(); err != nil {
+			logger.Fatalf("Failed to replay task %d of step %d: %v", *replayTask, *replayStep, err)
 		}
 
 	case writer.FullCommand():
 
+		keyFields, err := parseFields(*writeKeyFields)
+		if err != nil {
+			logger.Fatalf("invalid key fields %s: %v", *writeKeyFields, err)
+		}
+		if err := authorizeTopic(*writerAgentAddress, *writeTopic, *writeToken); err != nil {
+			logger.Fatalf("Failed to authorize %s on %s: %v", *writeTopic, *writerAgentAddress, err)
+		}
 		inChan := util.NewPiper()
 		var wg sync.WaitGroup
 		wg.Add(1)
-		go netchan.DialWriteChannel(context.Background(), &wg, "stdin", *writerAgentAddress, *writeTopic, *writeToDisk, inChan.Reader, 1)
+		go netchan.DialWriteChannel(context.Background(), &wg, "stdin", *writerAgentAddress, *writeTopic, *writeToken, *writeToDisk, inChan.Reader, 1)
 		wg.Add(1)
-		go util.LineReaderToChannel(&wg, &pb.InstructionStat{}, "stdin", os.Stdin, inChan.Writer, true, os.Stderr)
+		go util.FormatReaderToChannel(&wg, &pb.InstructionStat{}, "stdin", *writeFormat, keyFields, os.Stdin, inChan.Writer, os.Stderr)
 		wg.Wait()
 
 	case reader.FullCommand():
 
+		fields, err := parseFields(*readFields)
+		if err != nil {
+			logger.Fatalf("invalid fields %s: %v", *readFields, err)
+		}
 		outChan := util.NewPiper()
 		var wg sync.WaitGroup
 		wg.Add(1)
-		go netchan.DialReadChannel(context.Background(), &wg, "stdout", *readerAgentAddress, *readTopic, *readFromDisk, outChan.Writer)
+		shardRange := &pb.ShardRange{StartRow: *readStartRow, StopRow: *readStopRow}
+		go netchan.DialReadChannelRange(context.Background(), &wg, "stdout", *readerAgentAddress, *readTopic, *readToken, *readFromDisk, shardRange, outChan.Writer)
 		wg.Add(1)
-		util.ChannelToLineWriter(&wg, &pb.InstructionStat{}, "stdout", outChan.Reader, os.Stdout, os.Stderr)
+		util.ChannelToFormatWriter(&wg, &pb.InstructionStat{}, "stdout", *readFormat, fields, outChan.Reader, os.Stdout, os.Stderr)
 		wg.Wait()
 
+	case submit.FullCommand():
+
+		if err := submitFlowDefinition(*submitServer, *submitDefinition, *submitToken); err != nil {
+			logger.Fatalf("Failed to run %s: %v", *submitDefinition, err)
+		}
+
 	case agent.FullCommand():
 
+		if _, err := secrets.NewSecretsProvider(*agentOption.SecretsProvider); err != nil {
+			logger.Fatalf("invalid secrets provider: %v", err)
+		}
+
 		if *profiling {
 			cpuProfile := fmt.Sprintf("agent-%d-cpu.pprof", *agentOption.Port)
 			f, err := os.Create(cpuProfile)
 			if err != nil {
-				log.Fatalf("failed to create agent cpu profile file %s: %v", cpuProfile, err)
+				logger.Fatalf("failed to create agent cpu profile file %s: %v", cpuProfile, err)
 			}
 			pprof.StartCPUProfile(f)
 			defer pprof.StopCPUProfile()
@@ -148,7 +275,7 @@ func main() {
 			memProfFile := fmt.Sprintf("agent-%d-mem.mprof", *agentOption.Port)
 			mf, err := os.Create(memProfFile)
 			if err != nil {
-				log.Fatalf("failed to create agent memory profile file %s: %v", memProfFile, err)
+				logger.Fatalf("failed to create agent memory profile file %s: %v", memProfFile, err)
 			}
 
 			on_interrupt.OnInterrupt(func() {
@@ -164,3 +291,116 @@ func main() {
 		a.RunAgentServer(agentOption)
 	}
 }
+
+// parseFields parses the comma separated 1-based field indexes.
+func parseFields(fields string) ([]int, error) {
+	if fields == "" {
+		return nil, nil
+	}
+	var indexes []int
+	for _, field := range strings.Split(fields, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if index < 1 {
+			return nil, fmt.Errorf("field %d is not 1-based", index)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// authorizeTopic binds the topic on the agent to the token, as the driver
+// does for the shards it allocates, so readers need the same token.
+func authorizeTopic(agentAddress, topic, token string) error {
+	conn, err := util.GleamGrpcDial(agentAddress, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	response, err := pb.NewGleamAgentClient(conn).Authorize(context.Background(), &pb.AuthorizeRequest{
+		Names:       []string{topic},
+		AccessToken: token,
+	})
+	if err != nil {
+		return err
+	}
+	if response.GetError() != "" {
+		return fmt.Errorf("%s", response.GetError())
+	}
+	return nil
+}
+
+// submitFlowDefinition runs the flow definition in the file by the server,
+// with the access token, and prints the rows sent back.
+func submitFlowDefinition(server, fileName, token string) error {
+	var data []byte
+	var err error
+	if fileName == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(fileName)
+	}
+	if err != nil {
+		return err
+	}
+	// as definition.Parse(), without linking the plugins of the flows
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return fmt.Errorf("Failed to parse flow definition: %v", err)
+	}
+	def := &pb.FlowDefinition{}
+	if err := json.Unmarshal(data, def); err != nil {
+		return fmt.Errorf("Failed to parse flow definition: %v", err)
+	}
+	def.AccessToken = token
+
+	conn, err := grpc.Dial(server, grpc.WithInsecure())
+	if err != nil {
+		return fmt.Errorf("Failed to dial %s: %v", server, err)
+	}
+	defer conn.Close()
+	stream, err := pb.NewGleamFlowDefinitionClient(conn).Run(context.Background(), def)
+	if err != nil {
+		return err
+	}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if response.GetError() != "" {
+			return fmt.Errorf("%s", response.GetError())
+		}
+		for _, row := range response.GetRows() {
+			fmt.Println(row)
+		}
+	}
+}
+
+// startProfiling writes the cpu and memory profiles of the executor,
+// until the returned function is called.
+func startProfiling(instructionSet *pb.InstructionSet) (stop func()) {
+	profilingFile := fmt.Sprintf("exe%d-cpu-%s.pprof", instructionSet.GetFlowHashCode(), strings.Join(instructionSet.InstructionNames(), "-"))
+	f, err := os.Create(profilingFile)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	pprof.StartCPUProfile(f)
+
+	memProfFile := fmt.Sprintf("exe%d-mem-%s.pprof", instructionSet.GetFlowHashCode(), strings.Join(instructionSet.InstructionNames(), "-"))
+	mf, err := os.Create(memProfFile)
+	if err != nil {
+		pwd, _ := os.Getwd()
+		logger.Fatalf("failed to create memory profile file %s: %v", pwd+"/"+memProfFile, err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		runtime.GC()
+		pprof.Lookup("heap").WriteTo(mf, 0)
+	}
+}
@@ -0,0 +1,179 @@
+package master
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// AgentScaler changes the number of agents, e.g. the replicas of a
+// Kubernetes deployment. New agents register themselves with the master.
+type AgentScaler interface {
+	Replicas() (int, error)
+	SetReplicas(n int) error
+	// RemoveAgents lowers the replicas by removing some of the agents, by
+	// their servers, and returns the removed ones. It never removes other
+	// agents, which may be busy.
+	RemoveAgents(replicas int, servers []string) (removed []string, err error)
+}
+
+type AutoscaleOption struct {
+	Scaler    AgentScaler
+	MinAgents int
+	MaxAgents int
+	// IdleTime is how long agents stay without tasks before scaling down
+	IdleTime time.Duration
+	Interval time.Duration
+}
+
+// demandTracker remembers the largest request the agents could not serve,
+// since the last check. Drivers repeat unserved requests every few seconds.
+type demandTracker struct {
+	sync.Mutex
+	unmet     pb.ComputeResource
+	unmetTime time.Time
+}
+
+func (d *demandTracker) record(requests []*pb.ComputeResource, allocations []*pb.Allocation) {
+	var unmet pb.ComputeResource
+	for _, r := range requests {
+		unmet = unmet.Plus(*r)
+	}
+	for _, a := range allocations {
+		unmet = unmet.Minus(*a.Allocated)
+	}
+	if unmet.CpuCount <= 0 {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	if unmet.CpuCount > d.unmet.CpuCount {
+		d.unmet = unmet
+	}
+	d.unmetTime = time.Now()
+}
+
+func (d *demandTracker) take() (unmet pb.ComputeResource, unmetTime time.Time) {
+	d.Lock()
+	defer d.Unlock()
+	unmet, d.unmet = d.unmet, pb.ComputeResource{}
+	return unmet, d.unmetTime
+}
+
+func (s *MasterServer) autoscale(option *AutoscaleOption) {
+	logger.Infof("Scaling agents between %d and %d", option.MinAgents, option.MaxAgents)
+	for range time.Tick(option.Interval) {
+		if err := s.autoscaleOnce(option); err != nil {
+			logger.Errorf("Failed to scale agents: %v", err)
+		}
+	}
+}
+
+// autoscaleOnce adds agents for the unserved requests, or removes the agents
+// idle for IdleTime if all requests were served since then.
+func (s *MasterServer) autoscaleOnce(option *AutoscaleOption) error {
+	replicas, err := option.Scaler.Replicas()
+	if err != nil {
+		return err
+	}
+	agents := s.Topology.allAgents()
+	unmet, unmetTime := s.demand.take()
+
+	if unmet.CpuCount <= 0 && replicas > option.MinAgents && time.Since(unmetTime) > option.IdleTime {
+		return s.removeIdleAgents(option, replicas)
+	}
+
+	target := replicas
+	if unmet.CpuCount > 0 {
+		if replicas > len(agents) {
+			// wait for the started agents to register
+			return nil
+		}
+		var cpuCount int32
+		for _, agent := range agents {
+			cpuCount += agent.Resource.CpuCount
+		}
+		perAgent := int32(1)
+		if len(agents) > 0 && cpuCount >= int32(len(agents)) {
+			perAgent = cpuCount / int32(len(agents))
+		}
+		target = replicas + int((unmet.CpuCount+perAgent-1)/perAgent)
+	}
+
+	if target > option.MaxAgents {
+		target = option.MaxAgents
+	}
+	if target < option.MinAgents {
+		target = option.MinAgents
+	}
+	if target == replicas {
+		return nil
+	}
+	logger.Infof("Scaling agents from %d to %d, unserved %v", replicas, target, unmet)
+	return option.Scaler.SetReplicas(target)
+}
+
+// removeIdleAgents drains the agents idle for IdleTime, so they get no new
+// tasks, and removes them, keeping at least MinAgents. The drained agents
+// not removed take tasks again.
+func (s *MasterServer) removeIdleAgents(option *AutoscaleOption, replicas int) error {
+	drained := s.Topology.drainIdleAgents(option.IdleTime, replicas-option.MinAgents)
+	if len(drained) == 0 {
+		return nil
+	}
+	var servers []string
+	for _, agent := range drained {
+		servers = append(servers, agent.Location.Server)
+	}
+
+	removed, err := option.Scaler.RemoveAgents(replicas, servers)
+	isRemoved := make(map[string]bool)
+	for _, server := range removed {
+		isRemoved[server] = true
+	}
+	var kept []*AgentInformation
+	for _, agent := range drained {
+		if !isRemoved[agent.Location.Server] {
+			kept = append(kept, agent)
+		}
+	}
+	s.Topology.undrainAgents(kept)
+	if err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		logger.Infof("Scaling agents from %d to %d, removing idle %v", replicas, replicas-len(removed), removed)
+	}
+	return nil
+}
+
+// drainIdleAgents marks at most max agents, idle for idleTime with nothing
+// allocated, as draining.
+func (tp *Topology) drainIdleAgents(idleTime time.Duration, max int) (drained []*AgentInformation) {
+	tp.allocationLock.Lock()
+	defer tp.allocationLock.Unlock()
+
+	for _, agent := range tp.allAgents() {
+		if len(drained) >= max {
+			break
+		}
+		if agent.Draining || agent.IdleSince.IsZero() || time.Since(agent.IdleSince) <= idleTime || !agent.Allocated.IsZero() {
+			continue
+		}
+		agent.Draining = true
+		drained = append(drained, agent)
+	}
+	return
+}
+
+func (tp *Topology) undrainAgents(agents []*AgentInformation) {
+	tp.allocationLock.Lock()
+	defer tp.allocationLock.Unlock()
+
+	for _, agent := range agents {
+		agent.Draining = false
+	}
+}
@@ -0,0 +1,189 @@
+package master
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// podDeletionCost is set on the idle agent pods, so the deployment removes
+// them first when lowering the replicas.
+const podDeletionCost = "-1000"
+
+// KubernetesScaler scales a deployment or a stateful set of agents with the
+// Kubernetes API, from a master pod with a service account allowed to get
+// and patch its scale, and to list and patch the agent pods. The agents
+// should register with their pod IP as the host.
+type KubernetesScaler struct {
+	kind     string // deployment or statefulset
+	name     string
+	podsUrl  string
+	scaleUrl string
+	client   *http.Client
+}
+
+type kubernetesScale struct {
+	Spec struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+}
+
+type kubernetesPodList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	} `json:"items"`
+}
+
+// NewKubernetesScaler scales the target, "deployment/<name>" or
+// "statefulset/<name>", in the namespace, or the namespace of the master
+// pod if empty.
+func NewKubernetesScaler(namespace, target string) (*KubernetesScaler, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("Not running in a Kubernetes pod")
+	}
+
+	parts := strings.SplitN(target, "/", 2)
+	if len(parts) != 2 || (parts[0] != "deployment" && parts[0] != "statefulset") {
+		return nil, fmt.Errorf("Unknown agents %s, expecting deployment/<name> or statefulset/<name>", target)
+	}
+
+	if namespace == "" {
+		data, err := ioutil.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the pod namespace: %v", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	caCert, err := ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the cluster certificate: %v", err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("Failed to parse the cluster certificate")
+	}
+
+	return &KubernetesScaler{
+		kind:     parts[0],
+		name:     parts[1],
+		podsUrl:  fmt.Sprintf("https://%s:%s/api/v1/namespaces/%s/pods", host, port, namespace),
+		scaleUrl: fmt.Sprintf("https://%s:%s/apis/apps/v1/namespaces/%s/%ss/%s/scale", host, port, namespace, parts[0], parts[1]),
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}},
+		},
+	}, nil
+}
+
+func (k *KubernetesScaler) Replicas() (int, error) {
+	var scale kubernetesScale
+	if err := k.do("GET", k.scaleUrl, "", nil, &scale); err != nil {
+		return 0, err
+	}
+	return scale.Spec.Replicas, nil
+}
+
+func (k *KubernetesScaler) SetReplicas(n int) error {
+	body := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, n))
+	return k.do("PATCH", k.scaleUrl, "application/merge-patch+json", body, nil)
+}
+
+// RemoveAgents sets a low pod deletion cost on the pods of a deployment
+// before lowering its replicas. A stateful set always removes the pods of
+// the highest ordinals, so only these are removed if they are in servers.
+func (k *KubernetesScaler) RemoveAgents(replicas int, servers []string) (removed []string, err error) {
+	podServers := make(map[string]string)
+	for _, server := range servers {
+		pod, err := k.podOf(server)
+		if err != nil {
+			return nil, err
+		}
+		podServers[pod] = server
+	}
+
+	if k.kind == "statefulset" {
+		for ordinal := replicas - 1; ordinal >= 0; ordinal-- {
+			server, found := podServers[fmt.Sprintf("%s-%d", k.name, ordinal)]
+			if !found {
+				break
+			}
+			removed = append(removed, server)
+		}
+	} else {
+		body := []byte(fmt.Sprintf(`{"metadata":{"annotations":{"controller.kubernetes.io/pod-deletion-cost":"%s"}}}`, podDeletionCost))
+		for pod, server := range podServers {
+			if err := k.do("PATCH", k.podsUrl+"/"+pod, "application/merge-patch+json", body, nil); err != nil {
+				return nil, err
+			}
+			removed = append(removed, server)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if err := k.SetReplicas(replicas - len(removed)); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// podOf finds the name of the pod with the IP.
+func (k *KubernetesScaler) podOf(ip string) (string, error) {
+	var pods kubernetesPodList
+	if err := k.do("GET", k.podsUrl+"?fieldSelector="+url.QueryEscape("status.podIP="+ip), "", nil, &pods); err != nil {
+		return "", err
+	}
+	if len(pods.Items) != 1 {
+		return "", fmt.Errorf("Found %d pods with IP %s", len(pods.Items), ip)
+	}
+	return pods.Items[0].Metadata.Name, nil
+}
+
+func (k *KubernetesScaler) do(method, resourceUrl, contentType string, body []byte, result interface{}) error {
+	req, err := http.NewRequest(method, resourceUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	// the token is rotated, so read it for each request
+	token, err := ioutil.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return fmt.Errorf("Failed to read the service account token: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to %s %s: %v", method, resourceUrl, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %v", resourceUrl, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to %s %s: %s %s", method, resourceUrl, resp.Status, data)
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("Failed to parse %s: %v", resourceUrl, err)
+		}
+	}
+	return nil
+}
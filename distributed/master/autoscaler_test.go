@@ -0,0 +1,112 @@
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+type fakeScaler struct {
+	replicas int
+	removed  []string
+	// removable limits the removed agents, like the ordinals of a stateful set
+	removable map[string]bool
+}
+
+func (f *fakeScaler) Replicas() (int, error) { return f.replicas, nil }
+func (f *fakeScaler) SetReplicas(n int) error {
+	f.replicas = n
+	return nil
+}
+func (f *fakeScaler) RemoveAgents(replicas int, servers []string) (removed []string, err error) {
+	for _, server := range servers {
+		if f.removable == nil || f.removable[server] {
+			removed = append(removed, server)
+		}
+	}
+	f.removed = append(f.removed, removed...)
+	f.replicas = replicas - len(removed)
+	return removed, nil
+}
+
+func TestAutoscale(t *testing.T) {
+	s := &MasterServer{Topology: NewTopology()}
+	scaler := &fakeScaler{replicas: 1}
+	option := &AutoscaleOption{Scaler: scaler, MinAgents: 1, MaxAgents: 5, IdleTime: time.Minute}
+
+	s.Topology.UpdateAgentInformation(&pb.Heartbeat{
+		Location:  &pb.Location{DataCenter: "dc", Rack: "r", Server: "a", Port: 1},
+		Resource:  &pb.ComputeResource{CpuCount: 2, MemoryMb: 1024},
+		Allocated: &pb.ComputeResource{},
+	})
+
+	// 3 unserved tasks need 2 more agents of 2 executors
+	s.demand.record([]*pb.ComputeResource{{CpuCount: 3}}, nil)
+	if err := s.autoscaleOnce(option); err != nil || scaler.replicas != 3 {
+		t.Fatalf("scaled up to %d: %v", scaler.replicas, err)
+	}
+
+	// the new agents have not registered yet
+	s.demand.record([]*pb.ComputeResource{{CpuCount: 3}}, nil)
+	if err := s.autoscaleOnce(option); err != nil || scaler.replicas != 3 {
+		t.Fatalf("scaled again to %d before agents registered: %v", scaler.replicas, err)
+	}
+
+	// no scaling down while requests were recently unserved
+	if err := s.autoscaleOnce(option); err != nil || scaler.replicas != 3 {
+		t.Fatalf("scaled down to %d: %v", scaler.replicas, err)
+	}
+
+	s.demand.unmetTime = time.Now().Add(-time.Hour)
+	for _, agent := range s.Topology.allAgents() {
+		agent.IdleSince = time.Now().Add(-time.Hour)
+	}
+	if err := s.autoscaleOnce(option); err != nil || scaler.replicas != 2 {
+		t.Fatalf("scaled down to %d: %v", scaler.replicas, err)
+	}
+	if len(scaler.removed) != 1 || scaler.removed[0] != "a" {
+		t.Errorf("removed agents %v, expected the idle one", scaler.removed)
+	}
+}
+
+func TestRemoveIdleAgents(t *testing.T) {
+	s := &MasterServer{Topology: NewTopology()}
+	for _, server := range []string{"busy", "idle", "kept"} {
+		s.Topology.UpdateAgentInformation(&pb.Heartbeat{
+			Location:  &pb.Location{DataCenter: "dc", Rack: "r", Server: server, Port: 1},
+			Resource:  &pb.ComputeResource{CpuCount: 2, MemoryMb: 1024},
+			Allocated: &pb.ComputeResource{},
+		})
+	}
+	for _, agent := range s.Topology.allAgents() {
+		agent.IdleSince = time.Now().Add(-time.Hour)
+		if agent.Location.Server == "busy" {
+			agent.Allocated = pb.ComputeResource{CpuCount: 1}
+		}
+	}
+	s.demand.unmetTime = time.Now().Add(-time.Hour)
+
+	scaler := &fakeScaler{replicas: 3, removable: map[string]bool{"idle": true}}
+	option := &AutoscaleOption{Scaler: scaler, MinAgents: 1, MaxAgents: 5, IdleTime: time.Minute}
+	if err := s.autoscaleOnce(option); err != nil {
+		t.Fatalf("autoscale: %v", err)
+	}
+	if scaler.replicas != 2 || len(scaler.removed) != 1 || scaler.removed[0] != "idle" {
+		t.Fatalf("scaled down to %d, removing %v", scaler.replicas, scaler.removed)
+	}
+
+	// only the removed agent stops taking tasks
+	for _, agent := range s.Topology.allAgents() {
+		if agent.Draining != (agent.Location.Server == "idle") {
+			t.Errorf("agent %s draining: %v", agent.Location.Server, agent.Draining)
+		}
+	}
+	dc, _ := s.Topology.GetDataCenter("dc")
+	allocations := s.Topology.findServers(dc, []*pb.ComputeResource{{CpuCount: 1}, {CpuCount: 1}, {CpuCount: 1}})
+	for _, allocation := range allocations {
+		if allocation.Location.Server == "idle" {
+			t.Errorf("allocated on the drained agent")
+		}
+	}
+}
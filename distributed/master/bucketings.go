@@ -0,0 +1,52 @@
+package master
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// bucketings are the buckets kept by BucketBy() on the agents, saved by the
+// drivers of the flows writing them. They are also kept in the history
+// store, if any, so they outlive the master.
+type bucketings struct {
+	sync.Mutex
+	byName map[string]*pb.Bucketing
+}
+
+// SaveBucketing keeps how the buckets of the name are bucketed, so the later
+// flows read them with Flow.Buckets().
+func (s *MasterServer) SaveBucketing(ctx context.Context, in *pb.Bucketing) (*pb.Empty, error) {
+	s.bucketings.Lock()
+	s.bucketings.byName[in.GetName()] = in
+	s.bucketings.Unlock()
+	if s.history != nil {
+		if err := s.history.SaveBucketing(in); err != nil {
+			logger.Errorf("Failed to save the buckets %s to history: %v", in.GetName(), err)
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// GetBucketing returns how the buckets of the name are bucketed, without the
+// bucket count if they are not known.
+func (s *MasterServer) GetBucketing(ctx context.Context, in *pb.BucketingRequest) (*pb.Bucketing, error) {
+	s.bucketings.Lock()
+	bucketing, found := s.bucketings.byName[in.GetName()]
+	s.bucketings.Unlock()
+	if found {
+		return bucketing, nil
+	}
+	if s.history != nil {
+		bucketing, err := s.history.GetBucketing(in.GetName())
+		if err != nil {
+			return nil, err
+		}
+		if bucketing != nil {
+			return bucketing, nil
+		}
+	}
+	return &pb.Bucketing{Name: in.GetName()}, nil
+}
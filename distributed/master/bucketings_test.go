@@ -0,0 +1,47 @@
+package master
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestBucketings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	get := func(s *MasterServer, name string) *pb.Bucketing {
+		bucketing, err := s.GetBucketing(context.Background(), &pb.BucketingRequest{Name: name})
+		if err != nil {
+			t.Fatalf("get the buckets %s: %v", name, err)
+		}
+		return bucketing
+	}
+
+	s := newMasterServer(dir)
+	if bucketing := get(s, "users"); bucketing.GetBucketCount() != 0 {
+		t.Errorf("found the unknown buckets as %v", bucketing)
+	}
+	saved := &pb.Bucketing{Name: "users", KeyFields: []int32{1, 2}, BucketCount: 4}
+	if _, err := s.SaveBucketing(context.Background(), saved); err != nil {
+		t.Fatal(err)
+	}
+	if bucketing := get(s, "users"); !reflect.DeepEqual(bucketing.GetKeyFields(), saved.KeyFields) || bucketing.GetBucketCount() != 4 {
+		t.Errorf("found the buckets as %v", bucketing)
+	}
+
+	// kept by the history store after the master restarts
+	s.history.Close()
+	restarted := newMasterServer(dir)
+	defer restarted.history.Close()
+	if bucketing := get(restarted, "users"); !reflect.DeepEqual(bucketing.GetKeyFields(), saved.KeyFields) || bucketing.GetBucketCount() != 4 {
+		t.Errorf("found the buckets as %v after restarting", bucketing)
+	}
+}
@@ -0,0 +1,43 @@
+package master
+
+import (
+	"context"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// GetCachedShards finds the agents keeping the shards of the cached datasets,
+// as reported by their heartbeats, so the drivers of later flows reuse them.
+// The shards kept by several agents are found on the agent heard from last.
+func (s *MasterServer) GetCachedShards(ctx context.Context, in *pb.CachedShardsRequest) (*pb.CachedShardsResponse, error) {
+	return &pb.CachedShardsResponse{
+		Locations: s.Topology.findCachedShards(in.GetNames()),
+	}, nil
+}
+
+func (tp *Topology) findCachedShards(names []string) (locations []*pb.DataLocation) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	found := make(map[string]*AgentInformation)
+	for _, agent := range tp.allAgents() {
+		for _, name := range agent.CachedShards {
+			if last, ok := found[name]; wanted[name] && (!ok || last.LastHeartBeat.Before(agent.LastHeartBeat)) {
+				found[name] = agent
+			}
+		}
+	}
+	for _, name := range names {
+		if agent, ok := found[name]; ok {
+			location := agent.Location
+			locations = append(locations, &pb.DataLocation{
+				Name:     name,
+				Location: &location,
+				OnDisk:   true,
+			})
+			delete(found, name)
+		}
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package master
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestFindCachedShards(t *testing.T) {
+	tp := NewTopology()
+	a := &pb.Location{DataCenter: "dc", Rack: "r", Server: "a", Port: 1}
+	b := &pb.Location{DataCenter: "dc", Rack: "r", Server: "b", Port: 1}
+	heartbeat := func(location *pb.Location, cachedShards ...string) {
+		tp.UpdateAgentInformation(&pb.Heartbeat{
+			Location:     location,
+			Resource:     &pb.ComputeResource{CpuCount: 1},
+			Allocated:    &pb.ComputeResource{},
+			CachedShards: cachedShards,
+		})
+	}
+	find := func(names ...string) map[string]string {
+		found := make(map[string]string)
+		for _, location := range tp.findCachedShards(names) {
+			found[location.GetName()] = location.GetLocation().URL()
+		}
+		return found
+	}
+
+	heartbeat(a, "c-x-s0", "c-x-s1")
+	heartbeat(b, "c-y-s0")
+	found := find("c-x-s0", "c-x-s1", "c-y-s0", "c-z-s0")
+	if len(found) != 3 || found["c-x-s0"] != a.URL() || found["c-x-s1"] != a.URL() || found["c-y-s0"] != b.URL() {
+		t.Errorf("found %v", found)
+	}
+
+	// purged by the agent
+	heartbeat(a, "c-x-s0")
+	if found := find("c-x-s1"); len(found) != 0 {
+		t.Errorf("found the purged shard at %v", found)
+	}
+
+	// the agent is gone
+	tp.deleteAgentInformation(b)
+	if found := find("c-y-s0"); len(found) != 0 {
+		t.Errorf("found the shard of the lost agent at %v", found)
+	}
+}
@@ -0,0 +1,66 @@
+package master
+
+import (
+	"context"
+	"sync"
+)
+
+// flowWatch counts the status updates of the flows, so the clients of the
+// REST API can wait for a status newer than the one they have.
+type flowWatch struct {
+	sync.Mutex
+	version  int64            // of all the flows
+	versions map[uint32]int64 // of each flow
+	changed  chan struct{}    // closed and replaced on each update
+}
+
+func newFlowWatch() *flowWatch {
+	return &flowWatch{
+		versions: make(map[uint32]int64),
+		changed:  make(chan struct{}),
+	}
+}
+
+// update records a new status of the flow, and wakes the waiting clients.
+func (w *flowWatch) update(id uint32) {
+	w.Lock()
+	defer w.Unlock()
+	w.version++
+	w.versions[id] = w.version
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// forget drops the flow evicted from the status cache.
+func (w *flowWatch) forget(id uint32) {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.versions, id)
+}
+
+// current returns the version of the flow, or of all the flows if the id
+// is 0, and the channel closed on the next update.
+func (w *flowWatch) current(id uint32) (version int64, changed <-chan struct{}) {
+	w.Lock()
+	defer w.Unlock()
+	if id == 0 {
+		return w.version, w.changed
+	}
+	return w.versions[id], w.changed
+}
+
+// wait returns the version of the flow, or of all the flows if the id is
+// 0, once it is newer than the version, or when the context is done.
+func (w *flowWatch) wait(ctx context.Context, id uint32, version int64) int64 {
+	for {
+		current, changed := w.current(id)
+		if current > version {
+			return current
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return current
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFlowWatch(t *testing.T) {
+	w := newFlowWatch()
+	w.update(1)
+	w.update(2)
+	if v, _ := w.current(0); v != 2 {
+		t.Errorf("version of all flows is %d, expected 2", v)
+	}
+	if v, _ := w.current(1); v != 1 {
+		t.Errorf("version of flow 1 is %d, expected 1", v)
+	}
+
+	// an update of another flow does not wake the watch of flow 1
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if v := w.wait(ctx, 1, 1); v != 1 {
+		t.Errorf("timed out wait returned %d, expected 1", v)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		w.update(2)
+		w.update(1)
+	}()
+	if v := w.wait(context.Background(), 1, 1); v != 4 {
+		t.Errorf("wait returned %d, expected 4", v)
+	}
+
+	w.forget(1)
+	if v, _ := w.current(1); v != 0 {
+		t.Errorf("version of forgotten flow is %d, expected 0", v)
+	}
+}
@@ -0,0 +1,137 @@
+package master
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/pb"
+)
+
+var (
+	flowsBucket      = []byte("flows")
+	startTimeBucket  = []byte("flowsByStartTime")
+	bucketingsBucket = []byte("bucketings")
+)
+
+// HistoryStore keeps the execution status of finished flows,
+// including the DAG, task stats, durations and errors.
+// Unlike the status cache, it is not evicted.
+type HistoryStore struct {
+	db *bolt.DB
+}
+
+func NewHistoryStore(dir string) (*HistoryStore, error) {
+	db, err := bolt.Open(filepath.Join(dir, "gleam_history.db"), 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open history store in %s: %v", dir, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(flowsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(bucketingsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(startTimeBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to create history buckets: %v", err)
+	}
+	return &HistoryStore{db: db}, nil
+}
+
+// Save stores or replaces the status of a flow.
+func (h *HistoryStore) Save(status *pb.FlowExecutionStatus) error {
+	data, err := proto.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal flow %d status: %v", status.GetId(), err)
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(flowsBucket).Put(flowKey(status.GetId()), data); err != nil {
+			return err
+		}
+		return tx.Bucket(startTimeBucket).Put(startTimeKey(status), flowKey(status.GetId()))
+	})
+}
+
+// Get returns the status of a flow, or nil if it is not found.
+func (h *HistoryStore) Get(id uint32) (status *pb.FlowExecutionStatus, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(flowsBucket).Get(flowKey(id))
+		if data == nil {
+			return nil
+		}
+		status = &pb.FlowExecutionStatus{}
+		return proto.Unmarshal(data, status)
+	})
+	return
+}
+
+// List returns the most recently started flows, newest first.
+func (h *HistoryStore) List(limit int) (statuses []*pb.FlowExecutionStatus, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		flows := tx.Bucket(flowsBucket)
+		c := tx.Bucket(startTimeBucket).Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(statuses) < limit); k, v = c.Prev() {
+			data := flows.Get(v)
+			if data == nil {
+				continue
+			}
+			status := &pb.FlowExecutionStatus{}
+			if err := proto.Unmarshal(data, status); err != nil {
+				return err
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return
+}
+
+// SaveBucketing stores or replaces the buckets kept by BucketBy() by the name.
+func (h *HistoryStore) SaveBucketing(bucketing *pb.Bucketing) error {
+	data, err := proto.Marshal(bucketing)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal the buckets %s: %v", bucketing.GetName(), err)
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketingsBucket).Put([]byte(bucketing.GetName()), data)
+	})
+}
+
+// GetBucketing returns the buckets kept by the name, or nil if not found.
+func (h *HistoryStore) GetBucketing(name string) (bucketing *pb.Bucketing, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketingsBucket).Get([]byte(name))
+		if data == nil {
+			return nil
+		}
+		bucketing = &pb.Bucketing{}
+		return proto.Unmarshal(data, bucketing)
+	})
+	return
+}
+
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+func flowKey(id uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, id)
+	return b
+}
+
+// startTimeKey sorts by start time, and stays unique for flows started at the same time.
+func startTimeKey(status *pb.FlowExecutionStatus) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b, uint64(status.GetDriver().GetStartTime()))
+	binary.BigEndian.PutUint32(b[8:], status.GetId())
+	return b
+}
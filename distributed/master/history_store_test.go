@@ -0,0 +1,152 @@
+package master
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func newFlowStatus(id uint32, name string, startTime int64) *pb.FlowExecutionStatus {
+	return &pb.FlowExecutionStatus{
+		Id:     id,
+		Driver: &pb.FlowExecutionStatus_DriverInfo{Name: name, StartTime: startTime, StopTime: startTime + 1},
+	}
+}
+
+func listedIds(t *testing.T, h *HistoryStore, limit int) (ids []uint32) {
+	statuses, err := h.List(limit)
+	if err != nil {
+		t.Fatalf("list %d: %v", limit, err)
+	}
+	for _, status := range statuses {
+		ids = append(ids, status.GetId())
+	}
+	return
+}
+
+func TestHistoryStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h, err := NewHistoryStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// saved out of order, and two flows started at the same time
+	for _, status := range []*pb.FlowExecutionStatus{
+		newFlowStatus(2, "second", 20),
+		newFlowStatus(1, "first", 10),
+		newFlowStatus(4, "last", 30),
+		newFlowStatus(3, "third", 20),
+	} {
+		if err := h.Save(status); err != nil {
+			t.Fatalf("save %d: %v", status.GetId(), err)
+		}
+	}
+
+	status, err := h.Get(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.GetDriver().GetName() != "second" || status.GetDriver().GetStopTime() != 21 {
+		t.Errorf("got flow 2 as %v", status)
+	}
+	if status, err := h.Get(9); status != nil || err != nil {
+		t.Errorf("got the missing flow 9 as %v, %v", status, err)
+	}
+
+	for _, test := range []struct {
+		limit    int
+		expected []uint32
+	}{
+		{0, []uint32{4, 3, 2, 1}},
+		{2, []uint32{4, 3}},
+		{10, []uint32{4, 3, 2, 1}},
+	} {
+		if ids := listedIds(t, h, test.limit); !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("listed %v with limit %d, expected %v", ids, test.limit, test.expected)
+		}
+	}
+
+	// kept after reopening the store
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	h, err = NewHistoryStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer h.Close()
+	if ids := listedIds(t, h, 0); !reflect.DeepEqual(ids, []uint32{4, 3, 2, 1}) {
+		t.Errorf("listed %v after reopening", ids)
+	}
+	if status, _ := h.Get(1); status.GetDriver().GetName() != "first" {
+		t.Errorf("got flow 1 as %v after reopening", status)
+	}
+}
+
+func TestHistoryStoreBucketing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h, err := NewHistoryStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if bucketing, err := h.GetBucketing("users"); bucketing != nil || err != nil {
+		t.Errorf("got the missing buckets as %v, %v", bucketing, err)
+	}
+	for _, count := range []int32{4, 8} {
+		if err := h.SaveBucketing(&pb.Bucketing{Name: "users", KeyFields: []int32{1}, BucketCount: count}); err != nil {
+			t.Fatal(err)
+		}
+		if bucketing, _ := h.GetBucketing("users"); bucketing.GetBucketCount() != count {
+			t.Errorf("got the buckets as %v, expected %d buckets", bucketing, count)
+		}
+	}
+}
+
+func TestGetFlowHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newMasterServer(dir)
+	defer s.history.Close()
+	for _, status := range []*pb.FlowExecutionStatus{newFlowStatus(1, "first", 10), newFlowStatus(2, "second", 20)} {
+		if err := s.history.Save(status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	resp, err := s.GetFlowHistory(ctx, &pb.FlowHistoryRequest{Id: 1})
+	if err != nil || len(resp.GetStatuses()) != 1 || resp.GetStatuses()[0].GetDriver().GetName() != "first" {
+		t.Errorf("got flow 1 as %v, %v", resp, err)
+	}
+	if _, err := s.GetFlowHistory(ctx, &pb.FlowHistoryRequest{Id: 9}); err == nil {
+		t.Errorf("got the missing flow 9")
+	}
+	resp, err = s.GetFlowHistory(ctx, &pb.FlowHistoryRequest{Limit: 1})
+	if err != nil || len(resp.GetStatuses()) != 1 || resp.GetStatuses()[0].GetId() != 2 {
+		t.Errorf("listed %v, %v, expected the newest flow", resp, err)
+	}
+
+	s.history = nil
+	if _, err := s.GetFlowHistory(ctx, &pb.FlowHistoryRequest{}); err == nil {
+		t.Errorf("got the history without a history store")
+	}
+}
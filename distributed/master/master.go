@@ -3,36 +3,38 @@
 package master
 
 import (
-	"log"
 	"net"
 	"net/http"
 
+	"github.com/gorilla/mux"
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
-	"github.com/gorilla/mux"
+	"github.com/lovelly/gleam/util/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
 var masterServer *MasterServer
 
-func RunMaster(listenOn string, logDirectory string) {
+// RunMaster starts the master. If autoscale is not nil, the master adds
+// agents when their resources are not enough, and removes idle agents.
+func RunMaster(listenOn string, logDirectory string, autoscale *AutoscaleOption) {
 
 	masterServer = newMasterServer(logDirectory)
 
 	httpL, err := net.Listen("tcp", listenOn)
 	if err != nil {
-		log.Fatalf("master server fails to listen on %s: %v", listenOn, err)
+		logger.Fatalf("master server fails to listen on %s: %v", listenOn, err)
 	}
 	defer httpL.Close()
 
 	grpcAddress, err := util.ParseServerToGrpcAddress(listenOn)
 	if err != nil {
-		log.Fatalf("master server fails to parse listen on %s: %v", listenOn, err)
+		logger.Fatalf("master server fails to parse listen on %s: %v", listenOn, err)
 	}
 	grpcL, err := net.Listen("tcp", grpcAddress)
 	if err != nil {
-		log.Fatalf("master server fails to listen on %s: %v", listenOn, err)
+		logger.Fatalf("master server fails to listen on %s: %v", listenOn, err)
 	}
 	defer grpcL.Close()
 
@@ -41,13 +43,24 @@ func RunMaster(listenOn string, logDirectory string) {
 	pb.RegisterGleamMasterServer(grpcS, masterServer)
 	reflection.Register(grpcS)
 
-	r := mux.NewRouter()
-	r.HandleFunc("/job/{id:[0-9]+}", masterServer.jobStatusHandler)
-	r.HandleFunc("/", masterServer.uiStatusHandler)
-
 	go grpcS.Serve(grpcL)
-	go http.Serve(httpL, r)
+	go http.Serve(httpL, masterServer.router())
+	if autoscale != nil {
+		go masterServer.autoscale(autoscale)
+	}
 
 	select {}
 
 }
+
+// router serves the status pages and the REST API of the master.
+func (ms *MasterServer) router() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/job/{id:[0-9]+}", ms.jobStatusHandler)
+	r.HandleFunc("/history", ms.historyHandler)
+	r.HandleFunc("/flows", ms.listFlowsHandler).Methods("GET")
+	r.HandleFunc("/flows/{id:[0-9]+}", ms.getFlowHandler).Methods("GET")
+	r.HandleFunc("/flows/{id:[0-9]+}/tasks", ms.getFlowTasksHandler).Methods("GET")
+	r.HandleFunc("/", ms.uiStatusHandler)
+	return r
+}
@@ -4,16 +4,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"context"
-	"github.com/lovelly/gleam/pb"
 	"github.com/golang/protobuf/proto"
 	"github.com/hashicorp/golang-lru"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 type MasterServer struct {
@@ -21,6 +21,10 @@ type MasterServer struct {
 	statusCache  *lru.Cache
 	logDirectory string
 	startTime    time.Time
+	history      *HistoryStore
+	bucketings   bucketings
+	demand       demandTracker
+	watch        *flowWatch
 }
 
 func newMasterServer(logDirectory string) *MasterServer {
@@ -28,16 +32,28 @@ func newMasterServer(logDirectory string) *MasterServer {
 		Topology:     NewTopology(),
 		logDirectory: logDirectory,
 		startTime:    time.Now(),
+		watch:        newFlowWatch(),
+		bucketings:   bucketings{byName: make(map[string]*pb.Bucketing)},
 	}
 	m.statusCache, _ = lru.NewWithEvict(512, m.onCacheEvict)
 	if strings.HasSuffix(m.logDirectory, "/") {
 		m.logDirectory = strings.TrimSuffix(m.logDirectory, "/")
 	}
 	m.onStartup()
+	history, err := NewHistoryStore(m.logDirectory)
+	if err != nil {
+		logger.Warnf("Job history is disabled: %v", err)
+	} else {
+		m.history = history
+	}
 	return m
 }
 
 func (s *MasterServer) GetResources(ctx context.Context, in *pb.ComputeRequest) (*pb.AllocationResult, error) {
+	if err := pb.CheckProtocolVersion(in.GetProtocolVersion()); err != nil {
+		return nil, fmt.Errorf("Driver %s@%s rejected: %v", in.GetUsername(), in.GetHostname(), err)
+	}
+
 	var err error
 	dcName := in.GetDataCenter()
 	if dcName == "" {
@@ -52,8 +68,9 @@ func (s *MasterServer) GetResources(ctx context.Context, in *pb.ComputeRequest)
 	}
 
 	allocations := s.Topology.findServers(dc, in.GetComputeResources())
+	s.demand.record(in.GetComputeResources(), allocations)
 
-	log.Printf("%v requests %+v, allocated %+v", in.FlowHashCode, in.GetComputeResources(), allocations)
+	logger.ForFlow(in.FlowHashCode).Infof("requests %+v, allocated %+v", in.GetComputeResources(), allocations)
 
 	return &pb.AllocationResult{
 		Allocations: allocations,
@@ -67,14 +84,18 @@ func (s *MasterServer) SendHeartbeat(stream pb.GleamMaster_SendHeartbeatServer)
 		heartbeat, err := stream.Recv()
 		if err == nil {
 			if location == nil {
+				if err := pb.CheckProtocolVersion(heartbeat.GetProtocolVersion()); err != nil {
+					logger.Warnf("rejected agent %v: %v", heartbeat.Location, err)
+					return err
+				}
 				location = heartbeat.Location
-				log.Printf("added agent: %v", location)
+				logger.Infof("added agent: %v", location)
 			}
 		} else {
 			if location != nil {
 				s.Topology.deleteAgentInformation(location)
 			}
-			log.Printf("lost agent: %v", location)
+			logger.Warnf("lost agent: %v", location)
 
 			if err == io.EOF {
 				return nil
@@ -105,9 +126,16 @@ func (s *MasterServer) SendFlowExecutionStatus(stream pb.GleamMaster_SendFlowExe
 			fes.Driver.StopTime = time.Now().UnixNano()
 		}
 		s.statusCache.Add(id, fes)
+		s.watch.update(id)
 
 		data, _ := proto.Marshal(fes)
 		ioutil.WriteFile(fmt.Sprintf("%s/f%d.log", s.logDirectory, id), data, 0644)
+
+		if s.history != nil {
+			if err := s.history.Save(fes); err != nil {
+				logger.ForFlow(id).Errorf("Failed to save flow to history: %v", err)
+			}
+		}
 	}()
 
 	for {
@@ -123,7 +151,30 @@ func (s *MasterServer) SendFlowExecutionStatus(stream pb.GleamMaster_SendFlowExe
 
 		id = status.GetId()
 		s.statusCache.Add(id, status)
+		s.watch.update(id)
+	}
+}
+
+// GetFlowHistory returns one finished flow by id, or the most recent flows.
+func (s *MasterServer) GetFlowHistory(ctx context.Context, in *pb.FlowHistoryRequest) (*pb.FlowHistoryResponse, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("Job history is not available.")
+	}
+	if in.GetId() != 0 {
+		status, err := s.history.Get(in.GetId())
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			return nil, fmt.Errorf("Failed to find flow %d in history", in.GetId())
+		}
+		return &pb.FlowHistoryResponse{Statuses: []*pb.FlowExecutionStatus{status}}, nil
+	}
+	statuses, err := s.history.List(int(in.GetLimit()))
+	if err != nil {
+		return nil, err
 	}
+	return &pb.FlowHistoryResponse{Statuses: statuses}, nil
 }
 
 func (s *MasterServer) onStartup() {
@@ -142,5 +193,6 @@ func (s *MasterServer) onStartup() {
 
 func (s *MasterServer) onCacheEvict(key interface{}, value interface{}) {
 	id := key.(uint32)
+	s.watch.forget(id)
 	os.Remove(fmt.Sprintf("%s/f%d.log", s.logDirectory, id))
 }
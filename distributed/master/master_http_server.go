@@ -1,16 +1,16 @@
 package master
 
 import (
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
 	"time"
 
-	"github.com/lovelly/gleam/distributed/master/ui"
-	"github.com/lovelly/gleam/pb"
 	"github.com/gorilla/mux"
 	"github.com/hashicorp/golang-lru"
+	"github.com/lovelly/gleam/distributed/master/ui"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func (ms *MasterServer) uiStatusHandler(w http.ResponseWriter, r *http.Request) {
@@ -27,13 +27,15 @@ func (ms *MasterServer) uiStatusHandler(w http.ResponseWriter, r *http.Request)
 	})
 
 	args := struct {
-		Version   string
-		Topology  interface{}
-		StartTime time.Time
-		Logs      *lru.Cache
-		Stats     []*pb.FlowExecutionStatus
+		Version         string
+		ProtocolVersion int32
+		Topology        interface{}
+		StartTime       time.Time
+		Logs            *lru.Cache
+		Stats           []*pb.FlowExecutionStatus
 	}{
 		"0.01",
+		pb.ProtocolVersion,
 		ms.Topology,
 		ms.startTime,
 		ms.statusCache,
@@ -46,12 +48,12 @@ func (ms *MasterServer) jobStatusHandler(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	jobId, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		log.Printf("Failed to parse job id %s", vars["id"])
+		logger.Warnf("Failed to parse job id %s", vars["id"])
 		return
 	}
-	status, ok := ms.statusCache.Get(uint32(jobId))
+	status, ok := ms.flowStatus(uint32(jobId))
 	if !ok {
-		log.Printf("Failed to find job status for %d", jobId)
+		logger.Warnf("Failed to find job status for %d", jobId)
 		return
 	}
 
@@ -65,10 +67,39 @@ func (ms *MasterServer) jobStatusHandler(w http.ResponseWriter, r *http.Request)
 	}{
 		"0.01",
 		ms.Topology,
-		status.(*pb.FlowExecutionStatus),
-		ui.GenSvg(status.(*pb.FlowExecutionStatus)),
+		status,
+		ui.GenSvg(status),
 		ms.startTime,
 		ms.statusCache,
 	}
 	ui.JobStatusTpl.Execute(w, args)
 }
+
+func (ms *MasterServer) historyHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	var stats []*pb.FlowExecutionStatus
+	if ms.history != nil {
+		var err error
+		stats, err = ms.history.List(limit)
+		if err != nil {
+			logger.Errorf("Failed to list job history: %v", err)
+		}
+	}
+
+	args := struct {
+		Version   string
+		StartTime time.Time
+		Limit     int
+		Stats     []*pb.FlowExecutionStatus
+	}{
+		"0.01",
+		ms.startTime,
+		limit,
+		stats,
+	}
+	ui.HistoryTpl.Execute(w, args)
+}
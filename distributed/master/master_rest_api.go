@@ -0,0 +1,326 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// The REST API returns the flows known to the master as JSON, for the
+// dashboards and tools not using gRPC:
+//
+//   GET /flows               the recent flows, ?limit=100 by default
+//   GET /flows/{id}          a flow and its steps
+//   GET /flows/{id}/tasks    the task groups of a flow and their executions
+//
+// With ?version=N, the request waits up to ?wait=30s for a status newer than
+// the version returned before, to watch the flows without polling.
+
+const maxWatchWait = 5 * time.Minute
+
+type flowJson struct {
+	Id         uint32     `json:"id"`
+	Version    int64      `json:"version"`
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	Username   string     `json:"username"`
+	Hostname   string     `json:"hostname"`
+	Executable string     `json:"executable"`
+	StartTime  *time.Time `json:"startTime,omitempty"`
+	StopTime   *time.Time `json:"stopTime,omitempty"`
+	StepCount  int        `json:"stepCount"`
+	TaskGroups int        `json:"taskGroups"`
+	Finished   int        `json:"finishedTaskGroups"`
+	Failed     int        `json:"failedTaskGroups"`
+	Steps      []stepJson `json:"steps,omitempty"`
+}
+
+type stepJson struct {
+	Id              int32   `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description,omitempty"`
+	ParentIds       []int32 `json:"parentIds"`
+	TaskCount       int     `json:"taskCount"`
+	InputDatasetIds []int32 `json:"inputDatasetIds"`
+	OutputDatasetId int32   `json:"outputDatasetId"`
+	InputCounter    int64   `json:"inputCounter"`
+	OutputCounter   int64   `json:"outputCounter"`
+	FilteredCounter int64   `json:"filteredCounter,omitempty"`
+}
+
+type tasksJson struct {
+	Id         uint32          `json:"id"`
+	Version    int64           `json:"version"`
+	TaskGroups []taskGroupJson `json:"taskGroups"`
+}
+
+type taskGroupJson struct {
+	StepIds    []int32         `json:"stepIds"`
+	TaskIds    []int32         `json:"taskIds"`
+	Location   string          `json:"location,omitempty"`
+	State      string          `json:"state"`
+	Executions []executionJson `json:"executions"`
+}
+
+type executionJson struct {
+	StartTime  *time.Time            `json:"startTime,omitempty"`
+	StopTime   *time.Time            `json:"stopTime,omitempty"`
+	SystemTime float64               `json:"systemTime"`
+	UserTime   float64               `json:"userTime"`
+	Error      string                `json:"error,omitempty"`
+	Stats      []*pb.InstructionStat `json:"stats"`
+}
+
+func (ms *MasterServer) listFlowsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	version := ms.waitForFlows(r, 0)
+
+	var statuses []*pb.FlowExecutionStatus
+	listed := make(map[uint32]bool)
+	for _, key := range ms.statusCache.Keys() {
+		if status, ok := ms.statusCache.Get(key.(uint32)); ok {
+			statuses = append(statuses, status.(*pb.FlowExecutionStatus))
+			listed[key.(uint32)] = true
+		}
+	}
+	if ms.history != nil {
+		history, err := ms.history.List(limit)
+		if err != nil {
+			logger.Errorf("Failed to list job history: %v", err)
+		}
+		for _, status := range history {
+			if !listed[status.GetId()] {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].GetDriver().GetStartTime() > statuses[j].GetDriver().GetStartTime()
+	})
+	if len(statuses) > limit {
+		statuses = statuses[:limit]
+	}
+
+	flows := []flowJson{}
+	for _, status := range statuses {
+		flows = append(flows, ms.toFlowJson(status))
+	}
+	writeJson(w, struct {
+		Version int64      `json:"version"`
+		Flows   []flowJson `json:"flows"`
+	}{version, flows})
+}
+
+func (ms *MasterServer) getFlowHandler(w http.ResponseWriter, r *http.Request) {
+	status, ok := ms.flowStatusOf(w, r)
+	if !ok {
+		return
+	}
+	flow := ms.toFlowJson(status)
+	counters := stepCounters(status)
+	for _, step := range status.GetSteps() {
+		c := counters[step.GetId()]
+		flow.Steps = append(flow.Steps, stepJson{
+			Id:              step.GetId(),
+			Name:            step.GetName(),
+			Description:     step.GetDescription(),
+			ParentIds:       step.GetParentIds(),
+			TaskCount:       len(step.GetTaskIds()),
+			InputDatasetIds: step.GetInputDatasetId(),
+			OutputDatasetId: step.GetOutputDatasetId(),
+			InputCounter:    c.InputCounter,
+			OutputCounter:   c.OutputCounter,
+			FilteredCounter: c.FilteredCounter,
+		})
+	}
+	writeJson(w, flow)
+}
+
+func (ms *MasterServer) getFlowTasksHandler(w http.ResponseWriter, r *http.Request) {
+	status, ok := ms.flowStatusOf(w, r)
+	if !ok {
+		return
+	}
+	tasks := tasksJson{
+		Id:         status.GetId(),
+		TaskGroups: []taskGroupJson{},
+	}
+	tasks.Version, _ = ms.watch.current(status.GetId())
+	for _, taskGroup := range status.GetTaskGroups() {
+		group := taskGroupJson{
+			StepIds:    taskGroup.GetStepIds(),
+			TaskIds:    taskGroup.GetTaskIds(),
+			State:      taskGroupState(taskGroup),
+			Executions: []executionJson{},
+		}
+		if location := taskGroup.GetAllocation().GetLocation(); location != nil {
+			group.Location = location.URL()
+		}
+		for _, execution := range taskGroup.GetExecutions() {
+			stats := execution.GetExecutionStat().GetStats()
+			if stats == nil {
+				stats = []*pb.InstructionStat{}
+			}
+			group.Executions = append(group.Executions, executionJson{
+				StartTime:  nanoTime(execution.GetStartTime()),
+				StopTime:   nanoTime(execution.GetStopTime()),
+				SystemTime: execution.GetSystemTime(),
+				UserTime:   execution.GetUserTime(),
+				Error:      string(execution.GetError()),
+				Stats:      stats,
+			})
+		}
+		tasks.TaskGroups = append(tasks.TaskGroups, group)
+	}
+	writeJson(w, tasks)
+}
+
+// flowStatusOf waits for the flow of the request if asked, and returns its
+// status from the cache or the history, or writes a 404 error.
+func (ms *MasterServer) flowStatusOf(w http.ResponseWriter, r *http.Request) (*pb.FlowExecutionStatus, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "invalid flow id", http.StatusBadRequest)
+		return nil, false
+	}
+	ms.waitForFlows(r, uint32(id))
+	status, ok := ms.flowStatus(uint32(id))
+	if !ok {
+		http.Error(w, "flow not found", http.StatusNotFound)
+		return nil, false
+	}
+	return status, true
+}
+
+// flowStatus returns the status of the flow from the cache or the history.
+func (ms *MasterServer) flowStatus(id uint32) (*pb.FlowExecutionStatus, bool) {
+	if status, ok := ms.statusCache.Get(id); ok {
+		return status.(*pb.FlowExecutionStatus), true
+	}
+	if ms.history != nil {
+		if status, err := ms.history.Get(id); err == nil && status != nil {
+			return status, true
+		}
+	}
+	return nil, false
+}
+
+// waitForFlows waits for a status of the flow, or of any flow if the id is
+// 0, newer than the version of the request, if any, and returns the version.
+func (ms *MasterServer) waitForFlows(r *http.Request, id uint32) int64 {
+	version, err := strconv.ParseInt(r.FormValue("version"), 10, 64)
+	if err != nil {
+		current, _ := ms.watch.current(id)
+		return current
+	}
+	wait := 30 * time.Second
+	if d, err := time.ParseDuration(r.FormValue("wait")); err == nil && d >= 0 {
+		wait = d
+	}
+	if wait > maxWatchWait {
+		wait = maxWatchWait
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+	return ms.watch.wait(ctx, id, version)
+}
+
+func (ms *MasterServer) toFlowJson(status *pb.FlowExecutionStatus) flowJson {
+	driver := status.GetDriver()
+	flow := flowJson{
+		Id:         status.GetId(),
+		Name:       driver.GetName(),
+		State:      "running",
+		Error:      status.GetError(),
+		Username:   driver.GetUsername(),
+		Hostname:   driver.GetHostname(),
+		Executable: driver.GetExecutable(),
+		StartTime:  nanoTime(driver.GetStartTime()),
+		StopTime:   nanoTime(driver.GetStopTime()),
+		StepCount:  len(status.GetSteps()),
+		TaskGroups: len(status.GetTaskGroups()),
+	}
+	flow.Version, _ = ms.watch.current(status.GetId())
+	for _, taskGroup := range status.GetTaskGroups() {
+		switch taskGroupState(taskGroup) {
+		case "finished":
+			flow.Finished++
+		case "failed":
+			flow.Failed++
+		}
+	}
+	if driver.GetStopTime() != 0 {
+		flow.State = "finished"
+		if flow.Error != "" || flow.Failed > 0 {
+			flow.State = "failed"
+		}
+	}
+	return flow
+}
+
+// taskGroupState is the state of the last execution of the task group.
+func taskGroupState(taskGroup *pb.FlowExecutionStatus_TaskGroup) string {
+	executions := taskGroup.GetExecutions()
+	if len(executions) == 0 {
+		return "pending"
+	}
+	last := executions[len(executions)-1]
+	switch {
+	case len(last.GetError()) > 0:
+		return "failed"
+	case last.GetStopTime() != 0:
+		return "finished"
+	}
+	return "running"
+}
+
+// stepCounters sums the instruction stats of the last execution of each
+// task group by step.
+func stepCounters(status *pb.FlowExecutionStatus) map[int32]*pb.InstructionStat {
+	counters := make(map[int32]*pb.InstructionStat)
+	for _, step := range status.GetSteps() {
+		counters[step.GetId()] = &pb.InstructionStat{StepId: step.GetId()}
+	}
+	for _, taskGroup := range status.GetTaskGroups() {
+		executions := taskGroup.GetExecutions()
+		if len(executions) == 0 {
+			continue
+		}
+		for _, stat := range executions[len(executions)-1].GetExecutionStat().GetStats() {
+			c, ok := counters[stat.GetStepId()]
+			if !ok {
+				continue
+			}
+			c.InputCounter += stat.GetInputCounter()
+			c.OutputCounter += stat.GetOutputCounter()
+			c.FilteredCounter += stat.GetFilteredCounter()
+		}
+	}
+	return counters
+}
+
+func nanoTime(t int64) *time.Time {
+	if t == 0 {
+		return nil
+	}
+	x := time.Unix(0, t)
+	return &x
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorf("Failed to write json: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// newRestApi serves the REST API of a master with flow 1 finished, flow 2
+// running, and flow 3 only in the history.
+func newRestApi(t *testing.T) (*MasterServer, *httptest.Server, func()) {
+	dir, err := ioutil.TempDir("", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newMasterServer(dir)
+	if s.history == nil {
+		t.Fatalf("no history in %s", dir)
+	}
+	if err := s.history.Save(&pb.FlowExecutionStatus{
+		Id:     3,
+		Driver: &pb.FlowExecutionStatus_DriverInfo{Name: "old", StartTime: 1, StopTime: 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	updateStatus(s, &pb.FlowExecutionStatus{
+		Id:     1,
+		Driver: &pb.FlowExecutionStatus_DriverInfo{Name: "done", StartTime: 10, StopTime: 20},
+		Steps: []*pb.FlowExecutionStatus_Step{
+			{Id: 1, Name: "read", TaskIds: []int32{0, 1}, OutputDatasetId: 1},
+			{Id: 2, Name: "map", ParentIds: []int32{1}, TaskIds: []int32{0, 1}, InputDatasetId: []int32{1}, OutputDatasetId: 2},
+		},
+		TaskGroups: []*pb.FlowExecutionStatus_TaskGroup{
+			{
+				StepIds:    []int32{1, 2},
+				TaskIds:    []int32{0, 0},
+				Allocation: &pb.Allocation{Location: &pb.Location{Server: "agent1", Port: 45327}},
+				Executions: []*pb.FlowExecutionStatus_TaskGroup_Execution{
+					{StartTime: 11, StopTime: 12, Error: []byte("lost agent")},
+					{StartTime: 13, StopTime: 14, ExecutionStat: &pb.ExecutionStat{Stats: []*pb.InstructionStat{
+						{StepId: 1, InputCounter: 5, OutputCounter: 5},
+						{StepId: 2, InputCounter: 5, OutputCounter: 3},
+					}}},
+				},
+			},
+			{
+				StepIds: []int32{1, 2},
+				TaskIds: []int32{1, 1},
+				Executions: []*pb.FlowExecutionStatus_TaskGroup_Execution{
+					{StartTime: 11, StopTime: 15, ExecutionStat: &pb.ExecutionStat{Stats: []*pb.InstructionStat{
+						{StepId: 1, InputCounter: 2, OutputCounter: 2},
+						{StepId: 2, InputCounter: 2, OutputCounter: 1},
+					}}},
+				},
+			},
+		},
+	})
+	updateStatus(s, &pb.FlowExecutionStatus{
+		Id:         2,
+		Driver:     &pb.FlowExecutionStatus_DriverInfo{Name: "running", StartTime: 30},
+		TaskGroups: []*pb.FlowExecutionStatus_TaskGroup{{StepIds: []int32{1}, TaskIds: []int32{0}}},
+	})
+	server := httptest.NewServer(s.router())
+	return s, server, func() {
+		server.Close()
+		s.history.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// updateStatus records the status as SendFlowExecutionStatus() does.
+func updateStatus(s *MasterServer, status *pb.FlowExecutionStatus) {
+	s.statusCache.Add(status.GetId(), status)
+	s.watch.update(status.GetId())
+}
+
+func getJson(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+type flowList struct {
+	Version int64      `json:"version"`
+	Flows   []flowJson `json:"flows"`
+}
+
+func TestListFlows(t *testing.T) {
+	_, server, stop := newRestApi(t)
+	defer stop()
+
+	var list flowList
+	getJson(t, server.URL+"/flows", &list)
+	if list.Version != 2 {
+		t.Errorf("version of the flows is %d, expected 2", list.Version)
+	}
+	var got []string
+	for _, flow := range list.Flows {
+		got = append(got, fmt.Sprintf("%d %s %s %d/%d/%d", flow.Id, flow.Name, flow.State, flow.Finished, flow.Failed, flow.TaskGroups))
+	}
+	// the most recent first, with the flows only in the history
+	expected := []string{"2 running running 0/0/1", "1 done finished 2/0/2", "3 old finished 0/0/0"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("listed %v, expected %v", got, expected)
+	}
+
+	getJson(t, server.URL+"/flows?limit=1", &list)
+	if len(list.Flows) != 1 || list.Flows[0].Id != 2 {
+		t.Errorf("listed %+v with limit 1", list.Flows)
+	}
+}
+
+func TestGetFlow(t *testing.T) {
+	_, server, stop := newRestApi(t)
+	defer stop()
+
+	var flow flowJson
+	if code := getJson(t, server.URL+"/flows/1", &flow); code != http.StatusOK {
+		t.Fatalf("get flow 1: %d", code)
+	}
+	if flow.Name != "done" || flow.Version != 1 || flow.StepCount != 2 {
+		t.Errorf("got flow %+v", flow)
+	}
+	// the counters of the last execution of each task group
+	var got []string
+	for _, step := range flow.Steps {
+		got = append(got, fmt.Sprintf("%d %s %v %d/%d", step.Id, step.Name, step.ParentIds, step.InputCounter, step.OutputCounter))
+	}
+	if expected := []string{"1 read [] 7/7", "2 map [1] 7/4"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got steps %v, expected %v", got, expected)
+	}
+
+	if code := getJson(t, server.URL+"/flows/3", &flow); code != http.StatusOK || flow.Name != "old" {
+		t.Errorf("got flow %+v of the history with %d", flow, code)
+	}
+	if code := getJson(t, server.URL+"/flows/9", &flow); code != http.StatusNotFound {
+		t.Errorf("got unknown flow with %d", code)
+	}
+}
+
+func TestGetFlowTasks(t *testing.T) {
+	_, server, stop := newRestApi(t)
+	defer stop()
+
+	var tasks tasksJson
+	if code := getJson(t, server.URL+"/flows/1/tasks", &tasks); code != http.StatusOK {
+		t.Fatalf("get tasks of flow 1: %d", code)
+	}
+	if tasks.Id != 1 || tasks.Version != 1 || len(tasks.TaskGroups) != 2 {
+		t.Fatalf("got tasks %+v", tasks)
+	}
+	group := tasks.TaskGroups[0]
+	if group.State != "finished" || group.Location != "agent1:45327" || len(group.Executions) != 2 {
+		t.Errorf("got task group %+v", group)
+	}
+	if group.Executions[0].Error != "lost agent" || len(group.Executions[1].Stats) != 2 {
+		t.Errorf("got executions %+v", group.Executions)
+	}
+
+	getJson(t, server.URL+"/flows/2/tasks", &tasks)
+	if len(tasks.TaskGroups) != 1 || tasks.TaskGroups[0].State != "pending" {
+		t.Errorf("got tasks %+v of the running flow", tasks)
+	}
+}
+
+func TestWatchFlows(t *testing.T) {
+	s, server, stop := newRestApi(t)
+	defer stop()
+
+	// without updates, the wait ends with the same version
+	var flow flowJson
+	start := time.Now()
+	getJson(t, server.URL+"/flows/2?version=2&wait=20ms", &flow)
+	if flow.Version != 2 || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("waited %v for version %d", time.Since(start), flow.Version)
+	}
+
+	// an older version returns at once
+	var list flowList
+	getJson(t, server.URL+"/flows?version=1&wait=1m", &list)
+	if list.Version != 2 {
+		t.Errorf("listed version %d, expected 2", list.Version)
+	}
+
+	// the updates of other flows do not end the wait for a flow
+	done := make(chan flowJson)
+	go func() {
+		var flow flowJson
+		if resp, err := http.Get(server.URL + "/flows/2?version=2&wait=10s"); err == nil {
+			json.NewDecoder(resp.Body).Decode(&flow)
+			resp.Body.Close()
+		}
+		done <- flow
+	}()
+	time.Sleep(20 * time.Millisecond)
+	updateStatus(s, &pb.FlowExecutionStatus{Id: 1, Driver: &pb.FlowExecutionStatus_DriverInfo{Name: "done", StartTime: 10, StopTime: 20}})
+	updateStatus(s, &pb.FlowExecutionStatus{Id: 2, Driver: &pb.FlowExecutionStatus_DriverInfo{Name: "running", StartTime: 30, StopTime: 40}})
+	select {
+	case flow := <-done:
+		if flow.Version != 4 || flow.State != "finished" {
+			t.Errorf("watched flow %+v, expected version 4", flow)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the update did not end the wait")
+	}
+}
@@ -35,18 +35,23 @@ func (tp *Topology) allocateServersOnRack(dc *DataCenter, rack *Rack, requests [
 	if len(agents) == 0 {
 		return
 	}
-	start := rand.Intn(len(agents)) - 1
+	// shuffle so equally loaded agents share the work
+	for i := range agents {
+		j := rand.Intn(i + 1)
+		agents[i], agents[j] = agents[j], agents[i]
+	}
+
 	for _, req := range requests {
 		request := req
 
+		// try the least busy agents first, counting the tasks just assigned
+		sort.Stable(byAgentLoad(agents))
+
 		hasAllocation := false
-		for x := 0; x < len(agents); x++ {
-			start++
-			if start == len(agents) {
-				start = 0
+		for _, agent := range agents {
+			if agent.Draining || !request.Schedulable(agent.Labels, agent.Taints) {
+				continue
 			}
-			agent := agents[start]
-
 			available := agent.Resource.Minus(agent.Allocated)
 
 			// fmt.Printf("available %v, requested %v\n", available, request.GetMemoryMb())
@@ -60,6 +65,7 @@ func (tp *Topology) allocateServersOnRack(dc *DataCenter, rack *Rack, requests [
 				dc.Allocated = dc.Allocated.Plus(*request)
 				tp.Allocated = tp.Allocated.Plus(*request)
 				available = available.Minus(*request)
+				agent.Load.RunningTasks++
 				hasAllocation = true
 				break
 			}
@@ -75,6 +81,9 @@ func (tp *Topology) allocateServersOnRack(dc *DataCenter, rack *Rack, requests [
 }
 
 func (tp *Topology) findServers(dc *DataCenter, requests []*pb.ComputeResource) (ret []*pb.Allocation) {
+	// not allocating to the agents being drained
+	tp.allocationLock.Lock()
+	defer tp.allocationLock.Unlock()
 
 	// sort racks by unallocated resources
 	var racks []*Rack
@@ -103,6 +112,14 @@ func (s byAvailableResources) Less(i, j int) bool {
 	return s[i].Resource.Minus(s[i].Allocated).Covers(s[j].Resource.Minus(s[j].Allocated))
 }
 
+type byAgentLoad []*AgentInformation
+
+func (s byAgentLoad) Len() int      { return len(s) }
+func (s byAgentLoad) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byAgentLoad) Less(i, j int) bool {
+	return s[i].Load.Busyness() < s[j].Load.Busyness()
+}
+
 type byRequestedResources []*pb.ComputeResource
 
 func (s byRequestedResources) Len() int      { return len(s) }
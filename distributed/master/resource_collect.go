@@ -28,12 +28,28 @@ func (tp *Topology) UpdateAgentInformation(ai *pb.Heartbeat) {
 			oldInfo.Resource = *ai.Resource
 		}
 		oldInfo.LastHeartBeat = time.Now()
+		oldInfo.ProtocolVersion = ai.ProtocolVersion
+		oldInfo.Labels, oldInfo.Taints = ai.Labels, ai.Taints
+		oldInfo.CachedShards = ai.CachedShards
+		if ai.Load != nil {
+			oldInfo.Load = *ai.Load
+		}
 	} else {
+		var load pb.AgentLoad
+		if ai.Load != nil {
+			load = *ai.Load
+		}
 		rack.AddAgent(&AgentInformation{
-			Location:      *ai.Location,
-			LastHeartBeat: time.Now(),
-			Resource:      *ai.Resource,
-			Allocated:     *ai.Allocated,
+			Location:        *ai.Location,
+			LastHeartBeat:   time.Now(),
+			IdleSince:       time.Now(),
+			Resource:        *ai.Resource,
+			Allocated:       *ai.Allocated,
+			Load:            load,
+			ProtocolVersion: ai.ProtocolVersion,
+			Labels:          ai.Labels,
+			Taints:          ai.Taints,
+			CachedShards:    ai.CachedShards,
 		})
 	}
 
@@ -47,6 +63,11 @@ func (tp *Topology) UpdateAgentInformation(ai *pb.Heartbeat) {
 	}
 
 	if hasOldInfo {
+		if oldInfo.Load.RunningTasks > 0 || !ai.Allocated.IsZero() {
+			oldInfo.IdleSince = time.Time{}
+		} else if oldInfo.IdleSince.IsZero() {
+			oldInfo.IdleSince = time.Now()
+		}
 		deltaAllocated := ai.Allocated.Minus(oldInfo.Allocated)
 		oldInfo.Allocated = *ai.Allocated
 		// fmt.Printf("deltaAllocated %+v\n", deltaAllocated)
@@ -82,10 +103,11 @@ func (tp *Topology) deleteAgentInformation(location *pb.Location) {
 	deltaResource := oldInfo.Resource
 	deltaAllocated := oldInfo.Allocated
 
+	// draining agents have no resources left
+	rack.DropAgent(location)
+
 	if !deltaResource.IsZero() {
 		// fmt.Printf("deleting %+v\n", oldInfo)
-		rack.DropAgent(location)
-
 		rack.Resource = rack.Resource.Minus(deltaResource)
 		rack.Allocated = rack.Allocated.Minus(deltaAllocated)
 		dc.Resource = dc.Resource.Minus(deltaResource)
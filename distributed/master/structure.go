@@ -12,6 +12,17 @@ type AgentInformation struct {
 	LastHeartBeat time.Time
 	Resource      pb.ComputeResource
 	Allocated     pb.ComputeResource
+	Load          pb.AgentLoad
+	// ProtocolVersion is 0 for agents older than protocol versioning
+	ProtocolVersion int32
+	Labels          []string // key=value
+	Taints          []string // key or key=value
+	// IdleSince is when the agent finished its last task, zero if busy
+	IdleSince time.Time
+	// Draining agents get no new tasks, being removed by the autoscaler
+	Draining bool
+	// CachedShards are the shards of the cached datasets the agent keeps
+	CachedShards []string
 }
 
 type Rack struct {
@@ -35,6 +46,8 @@ type Topology struct {
 	Allocated pb.ComputeResource
 	sync.RWMutex
 	DataCenters map[string]*DataCenter
+	// allocationLock serializes the allocations and the draining of agents
+	allocationLock sync.Mutex
 }
 
 func NewTopology() *Topology {
@@ -131,6 +144,15 @@ func (rack *Rack) DropAgent(location *pb.Location) {
 	delete(rack.Agents, location.URL())
 }
 
+func (tp *Topology) allAgents() (ret []*AgentInformation) {
+	for _, dc := range tp.GetDataCenters() {
+		for _, rack := range dc.GetRacks() {
+			ret = append(ret, rack.GetAgents()...)
+		}
+	}
+	return
+}
+
 func (rack *Rack) GetAgents() (ret []*AgentInformation) {
 	rack.RLock()
 	defer rack.RUnlock()
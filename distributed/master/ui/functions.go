@@ -1,14 +1,18 @@
 package ui
 
 import (
+	"fmt"
 	"text/template"
 	"time"
+
+	"github.com/lovelly/gleam/util"
 )
 
 var (
 	funcMap = template.FuncMap{
 		"duration": Duration,
 		"unix":     Unix,
+		"row":      Row,
 	}
 )
 
@@ -24,3 +28,24 @@ func Unix(t int64) time.Time {
 	nano := t / 1e9
 	return time.Unix(t/1e9, nano-nano%1e6)
 }
+
+// Row formats the encoded row peeked by the executors.
+func Row(data []byte) string {
+	row, err := util.DecodeRow(data)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v %v", formatFields(row.K), formatFields(row.V))
+}
+
+// formatFields shows the strings encoded as bytes as text.
+func formatFields(fields []interface{}) []interface{} {
+	var ret []interface{}
+	for _, field := range fields {
+		if b, ok := field.([]byte); ok {
+			field = string(b)
+		}
+		ret = append(ret, field)
+	}
+	return ret
+}
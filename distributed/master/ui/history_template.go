@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"text/template"
+)
+
+var HistoryTpl = template.Must(template.New("history").Funcs(funcMap).Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <title>Gleam {{ .Version }} History</title>
+    <link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/3.3.1/css/bootstrap.min.css">
+  </head>
+  <body>
+    <div class="container">
+      <div class="page-header">
+	    <h1>
+          <a href="https://github.com/lovelly/gleam">Gleam</a> <small>{{ .Version }}</small>
+	    </h1>
+      </div>
+
+      <div class="row">
+        <h2>Job History <small>latest {{ .Limit }}, <a href="/">back to status</a></small></h2>
+        <table class="table table-striped">
+          <thead>
+            <tr>
+              <th>Id</th>
+              <th>Name</th>
+              <th>Driver</th>
+              <th>User</th>
+              <th>Host</th>
+              <th>Started</th>
+              <th>Duration</th>
+              <th>Error</th>
+            </tr>
+          </thead>
+          <tbody>
+            {{ range $idx, $stat := $.Stats }}
+            <tr>
+              <td><a href="/job/{{$stat.Id}}">{{ $stat.Id }}</a></td>
+              <td>{{ $stat.Driver.Name }}</td>
+              <td>{{ $stat.Driver.Executable }}</td>
+              <td>{{ $stat.Driver.Username }}</td>
+              <td>{{ $stat.Driver.Hostname }}</td>
+              <td>{{ unix $stat.Driver.StartTime }}</td>
+              <td>{{ duration $stat.Driver.StopTime $stat.Driver.StartTime }}</td>
+              <td>{{ $stat.Error }}</td>
+            </tr>
+            {{ end }}
+          </tbody>
+        </table>
+      </div>
+
+    </div>
+  </body>
+</html>
+`))
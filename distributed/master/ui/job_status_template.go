@@ -136,7 +136,9 @@ var JobStatusTpl = template.Must(template.New("job").Funcs(funcMap).Parse(`<!DOC
                      {{with .ExecutionStat}}
                      <ul>
                        {{range .Stats}}
-                          <li>{{.StepId}}:{{.TaskId}} {{.InputCounter}}=>{{.OutputCounter}}</li>
+                          <li>{{.StepId}}:{{.TaskId}} {{.InputCounter}}=>{{.OutputCounter}}{{with .FilteredCounter}} filtered {{.}}{{end}}{{with .Lag}} lag {{.}}{{end}}
+                          {{with .PeekedRows}}<ul>{{range .}}<li><code>{{row .}}</code></li>{{end}}</ul>{{end}}
+                          </li>
                        {{end}}
                      </ul>
                      {{end}}
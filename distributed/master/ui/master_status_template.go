@@ -31,6 +31,10 @@ var MasterStatusTpl = template.Must(template.New("master").Funcs(funcMap).Parse(
                 <th>Allocated</th>
                 <td>{{ .Topology.Allocated }}</td>
               </tr>
+              <tr>
+                <th>Protocol Version</th>
+                <td>{{ .ProtocolVersion }}</td>
+              </tr>
             </tbody>
           </table>
         </div>
@@ -62,6 +66,11 @@ var MasterStatusTpl = template.Must(template.New("master").Funcs(funcMap).Parse(
               <th>Last Heartbeat</th>
               <th>Resource</th>
               <th>Allocated</th>
+              <th>Running Tasks</th>
+              <th>Disk Used</th>
+              <th>Network</th>
+              <th>Protocol</th>
+              <th>Labels</th>
             </tr>
           </thead>
           <tbody>
@@ -76,6 +85,11 @@ var MasterStatusTpl = template.Must(template.New("master").Funcs(funcMap).Parse(
               <td>{{ $agent.LastHeartBeat }}</td>
               <td>{{ $agent.Resource }}</td>
               <td>{{ $agent.Allocated }}</td>
+              <td>{{ $agent.Load.RunningTasks }}</td>
+              <td>{{ $agent.Load.DiskUsedMb }}MB</td>
+              <td>{{ $agent.Load.NetworkBytesPerSecond }}B/s</td>
+              <td>{{ if $agent.ProtocolVersion }}{{ $agent.ProtocolVersion }}{{ else }}unknown{{ end }}</td>
+              <td>{{ range $agent.Labels }}<code>{{ . }}</code> {{ end }}{{ range $agent.Taints }}<code>taint {{ . }}</code> {{ end }}</td>
             </tr>
               {{ end }}
             {{ end }}
@@ -85,7 +99,7 @@ var MasterStatusTpl = template.Must(template.New("master").Funcs(funcMap).Parse(
       </div>
 
       <div class="row">
-        <h2>Jobs</h2>
+        <h2>Jobs <small><a href="/history">history</a></small></h2>
         <table class="table table-striped">
           <thead>
             <tr>
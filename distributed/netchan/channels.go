@@ -3,9 +3,11 @@
 package netchan
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"sync"
@@ -16,7 +18,12 @@ import (
 	"github.com/golang/protobuf/proto"
 )
 
-func DialReadChannel(ctx context.Context, wg *sync.WaitGroup, readerName string, address string, channelName string, onDisk bool, outChan io.WriteCloser) error {
+func DialReadChannel(ctx context.Context, wg *sync.WaitGroup, readerName string, address string, channelName string, accessToken string, onDisk bool, outChan io.WriteCloser) error {
+	return DialReadChannelRange(ctx, wg, readerName, address, channelName, accessToken, onDisk, nil, outChan)
+}
+
+// DialReadChannelRange reads only the rows in the shard range. A nil range reads the whole shard.
+func DialReadChannelRange(ctx context.Context, wg *sync.WaitGroup, readerName string, address string, channelName string, accessToken string, onDisk bool, shardRange *pb.ShardRange, outChan io.WriteCloser) error {
 
 	var dialer net.Dialer
 	conn, err := dialer.DialContext(ctx, "tcp", address)
@@ -31,10 +38,13 @@ func DialReadChannel(ctx context.Context, wg *sync.WaitGroup, readerName string,
 	}
 
 	data, err := proto.Marshal(&pb.ControlMessage{
-		IsOnDiskIO: onDisk,
+		IsOnDiskIO:      onDisk,
+		ProtocolVersion: pb.ProtocolVersion,
 		ReadRequest: &pb.ReadRequest{
 			ChannelName: channelName,
 			ReaderName:  readerName,
+			AccessToken: accessToken,
+			ShardRange:  shardRange,
 		},
 	})
 
@@ -51,7 +61,68 @@ func DialReadChannel(ctx context.Context, wg *sync.WaitGroup, readerName string,
 	return util.ReaderToChannel(wg, channelName, conn, outChan, true, os.Stderr)
 }
 
-func DialWriteChannel(ctx context.Context, wg *sync.WaitGroup, writerName string, address string, channelName string, onDisk bool, inChan io.Reader, readerCount int) error {
+// MapChannel lets a reader map an on disk shard file of the agent on the same
+// machine, instead of reading the shard through the agent. The agent checks
+// the access token, and counts the reader as reading the shard until the
+// channel is closed.
+type MapChannel struct {
+	conn net.Conn
+}
+
+// DialMapChannel waits until the agent has the shard, and allows mapping it.
+func DialMapChannel(ctx context.Context, readerName string, address string, channelName string, accessToken string) (*MapChannel, error) {
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to dial map %s: %v", address, err)
+	}
+
+	data, err := proto.Marshal(&pb.ControlMessage{
+		IsOnDiskIO:      true,
+		ProtocolVersion: pb.ProtocolVersion,
+		ReadRequest: &pb.ReadRequest{
+			ChannelName: channelName,
+			ReaderName:  readerName,
+			AccessToken: accessToken,
+			MapLocally:  true,
+		},
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Fail to marshal ReadRequest: %v", err)
+	}
+
+	if err = util.WriteMessage(conn, data); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Fail to write ReadRequest: %v", err)
+	}
+
+	// the agent closes the connection if the reader is rejected
+	if _, err = util.ReadStreamMessage(conn); err != io.EOF {
+		conn.Close()
+		return nil, fmt.Errorf("Agent %s did not allow mapping %s: %v", address, channelName, err)
+	}
+
+	return &MapChannel{conn: conn}, nil
+}
+
+// Close tells the agent whether the reader has read the whole shard.
+func (c *MapChannel) Close(isFinished bool) error {
+	defer c.conn.Close()
+	if isFinished {
+		return util.WriteEOFMessage(c.conn)
+	}
+	return util.WriteAbortMessage(c.conn)
+}
+
+func DialWriteChannel(ctx context.Context, wg *sync.WaitGroup, writerName string, address string, channelName string, accessToken string, onDisk bool, inChan io.Reader, readerCount int) error {
+	return DialWriteChannelCompressed(ctx, wg, writerName, address, channelName, accessToken, onDisk, "", inChan, readerCount)
+}
+
+// DialWriteChannelCompressed asks the agent to compress the on disk shard,
+// as parsed by store.ParseCompression. The agent decompresses it for the readers.
+func DialWriteChannelCompressed(ctx context.Context, wg *sync.WaitGroup, writerName string, address string, channelName string, accessToken string, onDisk bool, compression string, inChan io.Reader, readerCount int) error {
 
 	var dialer net.Dialer
 	conn, err := dialer.DialContext(ctx, "tcp", address)
@@ -66,11 +137,14 @@ func DialWriteChannel(ctx context.Context, wg *sync.WaitGroup, writerName string
 	}
 
 	data, err := proto.Marshal(&pb.ControlMessage{
-		IsOnDiskIO: onDisk,
+		IsOnDiskIO:      onDisk,
+		ProtocolVersion: pb.ProtocolVersion,
 		WriteRequest: &pb.WriteRequest{
 			ChannelName: channelName,
 			ReaderCount: int32(readerCount),
 			WriterName:  writerName,
+			AccessToken: accessToken,
+			Compression: compression,
 		},
 	})
 
@@ -84,6 +158,46 @@ func DialWriteChannel(ctx context.Context, wg *sync.WaitGroup, writerName string
 		return fmt.Errorf("Fail to write WriteRequest: %v", err)
 	}
 
-	return util.ChannelToWriter(wg, channelName, inChan, conn, os.Stderr)
+	return writeStream(ctx, wg, channelName, inChan, conn)
+
+}
+
+// writeStream copies the messages to the agent, and ends the stream with the
+// EOF message, or with the abort message if the input fails or the context
+// is cancelled, e.g. the executor is interrupted. The agent then keeps the
+// shard as incomplete, and its readers fail instead of reading part of it.
+func writeStream(ctx context.Context, wg *sync.WaitGroup, channelName string, inChan io.Reader, conn net.Conn) (err error) {
+	defer wg.Done()
+
+	reader := bufio.NewReaderSize(inChan, util.BUFFER_SIZE)
+	writer := util.NewBufferedMessageWriter(conn, util.BUFFER_SIZE)
 
+	var counter int64
+	for err == nil && ctx.Err() == nil {
+		var message []byte
+		if message, err = util.ReadMessage(reader); err == nil {
+			counter += int64(len(message))
+			err = writer.WriteMessage(message)
+		}
+	}
+	if err == io.EOF && ctx.Err() == nil {
+		err = nil
+		if err = writer.Flush(); err == nil {
+			err = util.WriteEOFMessage(conn)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s>Moved %d bytes: %v\n", channelName, counter, err)
+		}
+		return err
+	}
+	if err == nil || err == io.EOF {
+		err = ctx.Err()
+	}
+	fmt.Fprintf(os.Stderr, "%s>Aborted after %d bytes: %v\n", channelName, counter, err)
+	if flushErr := writer.Flush(); flushErr == nil {
+		util.WriteAbortMessage(conn)
+	}
+	// do not block the instruction still writing the rest
+	go io.Copy(ioutil.Discard, reader)
+	return err
 }
@@ -0,0 +1,87 @@
+package netchan
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle is a token bucket limiting how many bytes per second
+// can go through the data channels sharing it.
+// A nil Throttle does not limit anything.
+type Throttle struct {
+	sync.Mutex
+	bytesPerSecond float64
+	tokens         float64
+	lastRefill     time.Time
+}
+
+// NewThrottle creates a token bucket allowing bytesPerSecond, with bursts up to
+// one second worth of data. It returns nil if bytesPerSecond is not positive.
+func NewThrottle(bytesPerSecond int64) *Throttle {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &Throttle{
+		bytesPerSecond: float64(bytesPerSecond),
+		tokens:         float64(bytesPerSecond),
+		lastRefill:     time.Now(),
+	}
+}
+
+// Wait blocks until n bytes can be sent.
+func (t *Throttle) Wait(n int) {
+	if t == nil {
+		return
+	}
+	remaining := float64(n)
+	for remaining > 0 {
+		t.Lock()
+		t.refill()
+		take := remaining
+		if take > t.tokens {
+			take = t.tokens
+		}
+		t.tokens -= take
+		remaining -= take
+		var delay time.Duration
+		if remaining > 0 {
+			need := remaining
+			if need > t.bytesPerSecond {
+				need = t.bytesPerSecond
+			}
+			delay = time.Duration(need / t.bytesPerSecond * float64(time.Second))
+		}
+		t.Unlock()
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+}
+
+func (t *Throttle) refill() {
+	now := time.Now()
+	t.tokens += now.Sub(t.lastRefill).Seconds() * t.bytesPerSecond
+	t.lastRefill = now
+	if t.tokens > t.bytesPerSecond {
+		t.tokens = t.bytesPerSecond
+	}
+}
+
+type throttledReader struct {
+	io.Reader
+	throttles []*Throttle
+}
+
+// NewThrottledReader limits the reading speed by all the throttles.
+func NewThrottledReader(r io.Reader, throttles ...*Throttle) io.Reader {
+	return &throttledReader{r, throttles}
+}
+
+func (r *throttledReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	for _, t := range r.throttles {
+		t.Wait(n)
+	}
+	return
+}
@@ -2,21 +2,43 @@ package distributed
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/lovelly/gleam/distributed/driver"
+	"github.com/lovelly/gleam/distributed/driver/scheduler"
 	"github.com/lovelly/gleam/distributed/resource"
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 type DistributedOption struct {
-	RequiredFiles []resource.FileResource
-	Master        string
-	DataCenter    string
-	Rack          string
-	TaskMemoryMB  int
-	FlowBid       float64
-	Module        string
-	IsProfiling   bool
+	RequiredFiles      []resource.FileResource
+	Master             string
+	DataCenter         string
+	Rack               string
+	TaskMemoryMB       int
+	MinTaskMemoryMB    int // the least memory requested for each task, if set
+	FlowBid            float64
+	Module             string
+	IsProfiling        bool
+	NetworkMBPerSecond int
+	LocalThresholdMB   int64
+	StepMemoryMB       map[string]int // by step name, overriding the estimates
+	MaxConcurrentTasks int
+	FailurePolicy      FailurePolicy
+	Compression        string // the codec of on disk shards without a Compression() hint
+
+	driver *driver.FlowDriver // the runner of the last flow
+}
+
+// FailurePolicy decides how the driver handles failed tasks.
+type FailurePolicy struct {
+	// RetryWaitTimes are the delays before each retry of a failed task,
+	// if its input can be read again. Empty means no retries.
+	RetryWaitTimes []time.Duration
+	// StopOnFailure cancels the rest of the flow when a task failed all its
+	// retries. The failure is reported by Err().
+	StopOnFailure bool
 }
 
 func Option() *DistributedOption {
@@ -25,20 +47,123 @@ func Option() *DistributedOption {
 		DataCenter:   "",
 		TaskMemoryMB: 64,
 		FlowBid:      100.0,
+		FailurePolicy: FailurePolicy{
+			RetryWaitTimes: []time.Duration{time.Minute, 3 * time.Minute},
+		},
 	}
 }
 
 func (o *DistributedOption) GetFlowRunner() flow.FlowRunner {
-	return driver.NewFlowDriver(&driver.Option{
-		RequiredFiles: o.RequiredFiles,
-		Master:        o.Master,
-		DataCenter:    o.DataCenter,
-		Rack:          o.Rack,
-		TaskMemoryMB:  o.TaskMemoryMB,
-		FlowBid:       o.FlowBid,
-		Module:        o.Module,
-		IsProfiling:   o.IsProfiling,
+	o.driver = driver.NewFlowDriver(&driver.Option{
+		RequiredFiles:      o.RequiredFiles,
+		Master:             o.Master,
+		DataCenter:         o.DataCenter,
+		Rack:               o.Rack,
+		TaskMemoryMB:       o.TaskMemoryMB,
+		MinTaskMemoryMB:    o.MinTaskMemoryMB,
+		FlowBid:            o.FlowBid,
+		Module:             o.Module,
+		IsProfiling:        o.IsProfiling,
+		NetworkMBPerSecond: o.NetworkMBPerSecond,
+		LocalThresholdMB:   o.LocalThresholdMB,
+		StepMemoryMB:       o.StepMemoryMB,
+		MaxConcurrentTasks: o.MaxConcurrentTasks,
+		RetryWaitTimes:     o.FailurePolicy.RetryWaitTimes,
+		StopOnFailure:      o.FailurePolicy.StopOnFailure,
+		Compression:        o.Compression,
 	})
+	return o.driver
+}
+
+// Err returns the first failure of the last flow run with the option.
+func (o *DistributedOption) Err() error {
+	if o.driver == nil {
+		return nil
+	}
+	return o.driver.Err()
+}
+
+// Uncache deletes the shards cached by the name from the agents, e.g. the
+// output of an iteration of Flow.Loop() read by the next one.
+func (o *DistributedOption) Uncache(name string, shardCount int) {
+	var names []string
+	for i := 0; i < shardCount; i++ {
+		names = append(names, flow.CachedShardName(name, i))
+	}
+	scheduler.DeleteCachedShards(o.Master, names)
+}
+
+// GetBucketing looks up how the buckets kept by BucketBy() in the flows of
+// other drivers are bucketed, as saved on the master.
+func (o *DistributedOption) GetBucketing(name string) (flow.Bucketing, bool) {
+	bucketing, err := scheduler.GetBucketing(o.Master, name)
+	if err != nil {
+		logger.Errorf("Failed to find the buckets %s on the master %s: %v", name, o.Master, err)
+		return flow.Bucketing{}, false
+	}
+	if bucketing.GetBucketCount() == 0 {
+		return flow.Bucketing{}, false
+	}
+	ret := flow.Bucketing{BucketCount: int(bucketing.GetBucketCount())}
+	for _, field := range bucketing.GetKeyFields() {
+		ret.KeyFields = append(ret.KeyFields, int(field))
+	}
+	return ret, true
+}
+
+// WithMaster sets the master address, host:port.
+func (o *DistributedOption) WithMaster(master string) *DistributedOption {
+	o.Master = master
+	return o
+}
+
+// WithDataCenter runs the tasks in the data center, and optionally the rack.
+func (o *DistributedOption) WithDataCenter(dataCenter string, rack ...string) *DistributedOption {
+	o.DataCenter = dataCenter
+	if len(rack) > 0 {
+		o.Rack = rack[0]
+	}
+	return o
+}
+
+// WithTaskMemoryMB sets the memory requested for the tasks of the named
+// steps, instead of the estimates of their instructions. Without step names,
+// it sets the least memory requested for each task.
+func (o *DistributedOption) WithTaskMemoryMB(memoryMB int, stepNames ...string) *DistributedOption {
+	if len(stepNames) == 0 {
+		o.MinTaskMemoryMB = memoryMB
+		return o
+	}
+	if o.StepMemoryMB == nil {
+		o.StepMemoryMB = make(map[string]int)
+	}
+	for _, name := range stepNames {
+		o.StepMemoryMB[name] = memoryMB
+	}
+	return o
+}
+
+// WithMaxConcurrentTasks limits how many tasks of the flow run at the same
+// time, to share the cluster with other flows. Tasks streaming data to each
+// other need to run at the same time, so keep it at least as large as the
+// partition count of the flow. 0 means no limit.
+func (o *DistributedOption) WithMaxConcurrentTasks(n int) *DistributedOption {
+	o.MaxConcurrentTasks = n
+	return o
+}
+
+// WithFailurePolicy sets how failed tasks are retried.
+func (o *DistributedOption) WithFailurePolicy(policy FailurePolicy) *DistributedOption {
+	o.FailurePolicy = policy
+	return o
+}
+
+// WithCompression sets how the agents compress the on disk shards of the
+// flow, unless the datasets have a flow.Compression() hint. See
+// flow.Compression() for the codecs.
+func (o *DistributedOption) WithCompression(compression string) *DistributedOption {
+	o.Compression = compression
+	return o
 }
 
 func (o *DistributedOption) SetDataCenter(dataCenter string) *DistributedOption {
@@ -57,6 +182,23 @@ func (o *DistributedOption) SetProfiling(isProfiling bool) *DistributedOption {
 	return o
 }
 
+// SetNetworkBandwidth limits how fast the tasks of the flow write data to each agent,
+// in MB per second. The tasks writing to the same agent share the limit.
+// This keeps background flows from saturating the network. 0 means no limit.
+func (o *DistributedOption) SetNetworkBandwidth(mbPerSecond int) *DistributedOption {
+	o.NetworkMBPerSecond = mbPerSecond
+	return o
+}
+
+// SetLocalThreshold runs the flow in the driver process, as in local mode, if
+// its input size is known to be less than sizeInMB, e.g. from Strings() or a
+// TotalSize() hint, to avoid scheduling tiny flows on the cluster.
+// 0, the default, always runs the flow on the cluster.
+func (o *DistributedOption) SetLocalThreshold(sizeInMB int64) *DistributedOption {
+	o.LocalThresholdMB = sizeInMB
+	return o
+}
+
 // WithFile sends any related file over to gleam agents
 // so the task can still access these files on gleam agents.
 // The files are placed on the executed task's current working directory.
@@ -1,6 +1,8 @@
 package plan
 
 import (
+	"sort"
+
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
 )
@@ -8,7 +10,8 @@ import (
 func TranslateToInstructionSet(taskGroups *TaskGroup) (ret *pb.InstructionSet) {
 	ret = &pb.InstructionSet{}
 	lastShards := taskGroups.Tasks[len(taskGroups.Tasks)-1].OutputShards
-	if len(lastShards) > 0 {
+	if len(lastShards) > 0 && lastShards[0].Dataset.Meta.CacheName == "" {
+		// cached shards are kept after they are read
 		ret.ReaderCount = int32(len(lastShards[0].ReadingTasks))
 	}
 	for _, task := range taskGroups.Tasks {
@@ -42,12 +45,28 @@ func translateToInstruction(task *flow.Task) (ret *pb.Instruction) {
 				Path:   command.Path,
 				Args:   command.Args,
 				Env:    command.Env,
+				Arg:    command.Arg,
 			},
 		}
 	}
 
 	ret.StepId = int32(task.Step.Id)
 	ret.TaskId = int32(task.Id)
+	ret.SecretEnvs = translateSecrets(task.Step.Secrets)
+	ret.PeekCount = int32(task.Step.PeekCount)
 
 	return
 }
+
+func translateSecrets(secrets map[string]string) (ret []*pb.SecretEnv) {
+	for envName, secretName := range secrets {
+		ret = append(ret, &pb.SecretEnv{
+			EnvName:    envName,
+			SecretName: secretName,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].EnvName < ret[j].EnvName
+	})
+	return
+}
@@ -73,6 +73,16 @@ func (t *TaskGroup) String() string {
 	return strings.Join(steps, "-")
 }
 
+// InputShardRange is the range read from each input shard of the task group,
+// or nil to read the shards whole. When the first step needs only the leading
+// rows, e.g. LocalLimit() of a LIMIT, the agents skip the blocks after them.
+func (t *TaskGroup) InputShardRange() *pb.ShardRange {
+	if step := t.Tasks[0].Step; step.InputRowLimit > 0 {
+		return &pb.ShardRange{StopRow: int64(step.InputRowLimit)}
+	}
+	return nil
+}
+
 func (t *TaskGroup) RequiredResources() *pb.ComputeResource {
 
 	resource := &pb.ComputeResource{
@@ -81,6 +91,8 @@ func (t *TaskGroup) RequiredResources() *pb.ComputeResource {
 	}
 
 	for _, task := range t.Tasks {
+		resource.NodeSelector = appendMissing(resource.NodeSelector, task.Step.NodeSelector)
+		resource.Tolerations = appendMissing(resource.Tolerations, task.Step.Tolerations)
 		inst := task.Step.Instruction
 		if inst != nil && task.Step.OutputDataset != nil {
 			taskMemSize := inst.GetMemoryCostInMB(task.Step.OutputDataset.GetPartitionSize())
@@ -92,6 +104,19 @@ func (t *TaskGroup) RequiredResources() *pb.ComputeResource {
 	return resource
 }
 
+func appendMissing(list []string, items []string) []string {
+	for _, item := range items {
+		found := false
+		for _, x := range list {
+			found = found || x == item
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func (t *TaskGroup) MarkStop(err error) {
 	t.StopAt = time.Now()
 	t.Error = err
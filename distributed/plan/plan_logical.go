@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func isMergeableDataset(ds *flow.Dataset, taskCount int) bool {
@@ -16,6 +17,10 @@ func isMergeableDataset(ds *flow.Dataset, taskCount int) bool {
 	if len(ds.ReadingSteps) > 1 {
 		return false
 	}
+	if ds.Meta.CacheName != "" {
+		// cached shards are kept by the agents
+		return false
+	}
 	for _, shard := range ds.Shards {
 		if len(shard.ReadingTasks) > 1 {
 			return false
@@ -63,7 +68,7 @@ func translateToStepGroups(fc *flow.Flow) []*StepGroup {
 		// println("step:", step.Name, step.Id, "starting...")
 		ancestorStepId, foundStepId := findAncestorStepId(step)
 		if !foundStepId {
-			println("step:", step.Id, "Not found ancestorStepId.")
+			logger.Warnf("step: %d Not found ancestorStepId.", step.Id)
 			continue
 		}
 		// println("step:", step.Name, step.Id, "ancestorStepId", ancestorStepId)
@@ -1,9 +1,8 @@
 package plan
 
 import (
-	"log"
-
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 // group local tasks into one task group
@@ -35,7 +34,7 @@ func assertSameNumberOfTasks(steps []*flow.Step) {
 	count := len(steps[0].Tasks)
 	for _, step := range steps {
 		if count != len(step.Tasks) {
-			log.Fatalf("This should not happen: step %d have %d tasks, but step %d have %d tasks.", steps[0].Id, count, step.Id, len(step.Tasks))
+			logger.Fatalf("This should not happen: step %d have %d tasks, but step %d have %d tasks.", steps[0].Id, count, step.Id, len(step.Tasks))
 		}
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/lovelly/gleam/distributed/plan"
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 type DistributedPlanner struct {
@@ -24,38 +25,38 @@ func (fcd *DistributedPlanner) RunFlowContext(ctx context.Context, fc *flow.Flow
 
 	stepGroups, taskGroups := plan.GroupTasks(fc)
 
-	fmt.Println("=== task execution groups ===")
+	logger.Infof("=== task execution groups ===")
 	for _, taskGroup := range taskGroups {
-		fmt.Printf("%s\n", taskGroup.String())
+		logger.Infof("%s", taskGroup.String())
 		firstTask := taskGroup.Tasks[0]
 		lastTask := taskGroup.Tasks[len(taskGroup.Tasks)-1]
 		if len(firstTask.Step.InputDatasets) > 0 {
-			fmt.Printf("  input:\n")
+			logger.Infof("  input:")
 			for _, shard := range firstTask.InputShards {
-				fmt.Printf("    shard: %v\n", shard.Name())
+				logger.Infof("    shard: %v", shard.Name())
 			}
 		}
 		if lastTask.Step.OutputDataset != nil {
-			fmt.Printf("  output:\n")
+			logger.Infof("  output:")
 			for _, shard := range lastTask.OutputShards {
-				fmt.Printf("    shard: %v\n", shard.Name())
+				logger.Infof("    shard: %v", shard.Name())
 			}
 		}
 	}
 
-	fmt.Println("=== step groups ===")
+	logger.Infof("=== step groups ===")
 	for i, stepGroup := range stepGroups {
-		fmt.Printf("  step group: %d", i)
+		line := fmt.Sprintf("  step group: %d", i)
 		if len(stepGroup.Steps) > 0 && stepGroup.Steps[0].OutputDataset != nil {
-			fmt.Printf(" partition: %d", len(stepGroup.Steps[0].OutputDataset.Shards))
+			line += fmt.Sprintf(" partition: %d", len(stepGroup.Steps[0].OutputDataset.Shards))
 		}
-		fmt.Println()
+		logger.Infof("%s", line)
 		for _, step := range stepGroup.Steps {
-			fmt.Printf("    step: %s", step.Name)
+			line := fmt.Sprintf("    step: %s", step.Name)
 			if step.OutputDataset != nil {
-				fmt.Printf(" size: %d MB", step.OutputDataset.GetTotalSize())
+				line += fmt.Sprintf(" size: %d MB", step.OutputDataset.GetTotalSize())
 			}
-			fmt.Println()
+			logger.Infof("%s", line)
 		}
 	}
 
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvSecretsProvider reads secrets from environment variables.
+type EnvSecretsProvider struct {
+	prefix string
+}
+
+func NewEnvSecretsProvider(prefix string) *EnvSecretsProvider {
+	return &EnvSecretsProvider{prefix: prefix}
+}
+
+func (p *EnvSecretsProvider) GetSecret(name string) (string, error) {
+	value, found := os.LookupEnv(p.prefix + name)
+	if !found {
+		return "", fmt.Errorf("secret %s is not found in environment variable %s", name, p.prefix+name)
+	}
+	return value, nil
+}
+
+// FileSecretsProvider reads each secret from a file named after it,
+// e.g. mounted Kubernetes or Docker secrets.
+type FileSecretsProvider struct {
+	dir string
+}
+
+func NewFileSecretsProvider(dir string) *FileSecretsProvider {
+	return &FileSecretsProvider{dir: dir}
+}
+
+func (p *FileSecretsProvider) GetSecret(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		return "", fmt.Errorf("Failed to read secret %s: %v", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
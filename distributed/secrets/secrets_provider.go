@@ -0,0 +1,50 @@
+// Package secrets looks up credentials for the steps run by executors,
+// so they do not need to be shipped in the binary or the instruction set.
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultEnvPrefix keeps the env provider from handing out the other
+// environment variables of the agent, e.g. its own credentials.
+const DefaultEnvPrefix = "GLEAM_SECRET_"
+
+// SecretsProvider returns the value of a named secret.
+type SecretsProvider interface {
+	GetSecret(name string) (string, error)
+}
+
+// NewSecretsProvider creates a provider from its spec:
+//
+//	env              environment variables GLEAM_SECRET_<name> of the agent
+//	env:PREFIX_      environment variables PREFIX_<name>
+//	file:/some/dir   files /some/dir/<name>
+//	vault:URL        fields of the Vault secret at URL,
+//	                 e.g. https://vault:8200/v1/secret/data/gleam,
+//	                 read with the token in $VAULT_TOKEN
+func NewSecretsProvider(spec string) (SecretsProvider, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+	switch kind {
+	case "", "env":
+		if arg == "" {
+			arg = DefaultEnvPrefix
+		}
+		return NewEnvSecretsProvider(arg), nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("missing directory in secrets provider %s", spec)
+		}
+		return NewFileSecretsProvider(arg), nil
+	case "vault":
+		if arg == "" {
+			return nil, fmt.Errorf("missing url in secrets provider %s", spec)
+		}
+		return NewVaultSecretsProvider(arg, ""), nil
+	}
+	return nil, fmt.Errorf("unknown secrets provider %s", spec)
+}
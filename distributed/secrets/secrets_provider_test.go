@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecretsProviders(t *testing.T) {
+	os.Setenv("GLEAM_TEST_DB_PASSWORD", "env-secret")
+	defer os.Unsetenv("GLEAM_TEST_DB_PASSWORD")
+	os.Setenv("GLEAM_SECRET_DB_PASSWORD", "default-env-secret")
+	defer os.Unsetenv("GLEAM_SECRET_DB_PASSWORD")
+
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("file-secret\n"), 0600)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"DB_PASSWORD":"vault-secret"},"metadata":{"version":1}}}`))
+	}))
+	defer vault.Close()
+	os.Setenv("VAULT_TOKEN", "test-token")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	for spec, expected := range map[string]string{
+		"env":                "default-env-secret",
+		"env:GLEAM_TEST_":    "env-secret",
+		"file:" + dir:        "file-secret",
+		"vault:" + vault.URL: "vault-secret",
+	} {
+		p, err := NewSecretsProvider(spec)
+		if err != nil {
+			t.Fatalf("create provider %s: %v", spec, err)
+		}
+		value, err := p.GetSecret("DB_PASSWORD")
+		if err != nil {
+			t.Errorf("provider %s: %v", spec, err)
+		} else if value != expected {
+			t.Errorf("provider %s returned %q, expected %q", spec, value, expected)
+		}
+		if _, err := p.GetSecret("MISSING"); err == nil {
+			t.Errorf("provider %s found a missing secret", spec)
+		}
+	}
+
+	if p, _ := NewSecretsProvider("env"); p != nil {
+		if _, err := p.GetSecret("VAULT_TOKEN"); err == nil {
+			t.Errorf("env provider handed out an agent environment variable")
+		}
+	}
+
+	if _, err := NewFileSecretsProvider(dir).GetSecret("../etc/passwd"); err == nil {
+		t.Errorf("file provider allowed a path outside its directory")
+	}
+}
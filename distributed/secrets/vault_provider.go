@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// VaultSecretsProvider reads secrets from the fields of one Vault secret.
+// Both KV version 1 and version 2 responses are understood.
+// The secret is fetched once and then cached.
+type VaultSecretsProvider struct {
+	sync.Mutex
+	url    string
+	token  string
+	client *http.Client
+	fields map[string]interface{}
+}
+
+// NewVaultSecretsProvider reads the secret at the url, e.g.
+// https://vault:8200/v1/secret/data/gleam. An empty token means $VAULT_TOKEN.
+func NewVaultSecretsProvider(url, token string) *VaultSecretsProvider {
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	return &VaultSecretsProvider{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *VaultSecretsProvider) GetSecret(name string) (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.fields == nil {
+		fields, err := p.fetch()
+		if err != nil {
+			return "", err
+		}
+		p.fields = fields
+	}
+
+	value, found := p.fields[name]
+	if !found {
+		return "", fmt.Errorf("secret %s is not found in vault %s", name, p.url)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func (p *VaultSecretsProvider) fetch() (map[string]interface{}, error) {
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create vault request %s: %v", p.url, err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read vault %s: %v", p.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to read vault %s: %s", p.url, resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("Failed to decode vault %s: %v", p.url, err)
+	}
+
+	// KV version 2 nests the fields under data.data
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := secret.Data["metadata"]; hasMetadata {
+			return nested, nil
+		}
+	}
+	if secret.Data == nil {
+		return map[string]interface{}{}, nil
+	}
+	return secret.Data, nil
+}
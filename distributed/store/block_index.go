@@ -0,0 +1,141 @@
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// Block is a run of consecutive rows in a shard file.
+type Block struct {
+	StartRow int64 // index of the first row in the shard
+	RowCount int64
+	Offset   int64 // file offset of the first row
+	Size     int64 // bytes of all rows in the block
+	MinKey   []interface{}
+	MaxKey   []interface{}
+	HasKeys  bool // false if some rows could not be decoded
+}
+
+// BlockIndex locates blocks of rows in a shard file, so readers only
+// interested in a row range or key range can skip the other blocks.
+type BlockIndex struct {
+	sync.RWMutex
+	blocks []Block
+}
+
+func NewBlockIndex() *BlockIndex {
+	return &BlockIndex{}
+}
+
+func (idx *BlockIndex) add(b Block) {
+	idx.Lock()
+	idx.blocks = append(idx.blocks, b)
+	idx.Unlock()
+}
+
+// SeekRow returns the last indexed block starting at or before the row.
+func (idx *BlockIndex) SeekRow(row int64) (Block, bool) {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	i := sort.Search(len(idx.blocks), func(i int) bool {
+		return idx.blocks[i].StartRow > row
+	})
+	if i == 0 {
+		return Block{}, false
+	}
+	return idx.blocks[i-1], true
+}
+
+// BlockAt returns the indexed block starting exactly at the offset.
+func (idx *BlockIndex) BlockAt(offset int64) (Block, bool) {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	i := sort.Search(len(idx.blocks), func(i int) bool {
+		return idx.blocks[i].Offset >= offset
+	})
+	if i < len(idx.blocks) && idx.blocks[i].Offset == offset {
+		return idx.blocks[i], true
+	}
+	return Block{}, false
+}
+
+// BlockIndexer cuts the rows written to a shard into blocks of about blockSize bytes.
+type BlockIndexer struct {
+	index     *BlockIndex
+	blockSize int64
+	current   Block
+	offset    int64
+	row       int64
+}
+
+func NewBlockIndexer(index *BlockIndex, blockSize int64) *BlockIndexer {
+	return &BlockIndexer{
+		index:     index,
+		blockSize: blockSize,
+	}
+}
+
+// Add indexes one message, which is written as a 4-byte length and the encoded row.
+// It returns true when the current block is full. The caller should make sure
+// the block is written to the file before calling Flush to publish it.
+func (b *BlockIndexer) Add(message []byte) (isFull bool) {
+	if b.current.RowCount == 0 {
+		b.current = Block{
+			StartRow: b.row,
+			Offset:   b.offset,
+			HasKeys:  true,
+		}
+	}
+
+	if row, err := util.DecodeRow(message); err != nil {
+		b.current.HasKeys = false
+	} else if b.current.HasKeys {
+		if b.current.MinKey == nil || CompareKeys(row.K, b.current.MinKey) < 0 {
+			b.current.MinKey = row.K
+		}
+		if b.current.MaxKey == nil || CompareKeys(row.K, b.current.MaxKey) > 0 {
+			b.current.MaxKey = row.K
+		}
+	}
+
+	size := int64(4 + len(message))
+	b.current.RowCount++
+	b.current.Size += size
+	b.row++
+	b.offset += size
+
+	return b.current.Size >= b.blockSize
+}
+
+// SetStoredSize changes the bytes the current block takes in the file,
+// e.g. after the block is compressed.
+func (b *BlockIndexer) SetStoredSize(size int64) {
+	b.offset += size - b.current.Size
+	b.current.Size = size
+}
+
+// Flush adds the current partial block to the index.
+func (b *BlockIndexer) Flush() {
+	if b.current.RowCount > 0 {
+		b.index.add(b.current)
+		b.current = Block{}
+	}
+}
+
+// CompareKeys compares keys on the fields both have.
+func CompareKeys(a, b []interface{}) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if ret := util.Compare(a[i], b[i]); ret != 0 {
+			return ret
+		}
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+func TestBlockIndex(t *testing.T) {
+	index := NewBlockIndex()
+	indexer := NewBlockIndexer(index, 100)
+
+	for i := 0; i < 50; i++ {
+		message, err := util.NewRow(util.Now(), i, "some value").MarshalMsg(nil)
+		if err != nil {
+			t.Fatalf("marshal row %d: %v", i, err)
+		}
+		if indexer.Add(message) {
+			indexer.Flush()
+		}
+	}
+	indexer.Flush()
+
+	block, found := index.SeekRow(25)
+	if !found {
+		t.Fatalf("no block found for row 25")
+	}
+	if block.StartRow > 25 || block.StartRow+block.RowCount <= 25 {
+		t.Errorf("block %+v does not contain row 25", block)
+	}
+	if !block.HasKeys || util.Compare(block.MinKey[0], block.StartRow) != 0 {
+		t.Errorf("block %+v has unexpected min key", block)
+	}
+
+	next, found := index.BlockAt(block.Offset + block.Size)
+	if !found || next.StartRow != block.StartRow+block.RowCount {
+		t.Errorf("next block %+v does not follow %+v", next, block)
+	}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// autoGiveUpBlocks is how many blocks in a row may not shrink before the
+// auto compression stores the rest of the shard as is.
+const autoGiveUpBlocks = 4
+
+// BlockWriter writes the messages of an on disk shard in blocks compressed
+// by the codec. Each compressed block is stored as one meta data message,
+// with the negative length of the compressed bytes, and read back by
+// DecompressBlock. The blocks that do not shrink by at least 1/8 are stored
+// as the plain messages, the same as the shards without compression, so
+// the shard files mix both.
+type BlockWriter struct {
+	w          io.Writer
+	codec      Codec
+	isAuto     bool
+	indexer    *BlockIndexer
+	blockSize  int
+	block      []byte
+	frame      []byte
+	poorBlocks int
+}
+
+// NewBlockWriter cuts the blocks as the indexer does, or by blockSize if
+// the indexer is nil.
+func NewBlockWriter(w io.Writer, codec Codec, isAuto bool, indexer *BlockIndexer, blockSize int64) *BlockWriter {
+	return &BlockWriter{
+		w:         w,
+		codec:     codec,
+		isAuto:    isAuto,
+		indexer:   indexer,
+		blockSize: int(blockSize),
+		block:     make([]byte, 0, blockSize+util.BUFFER_SIZE),
+	}
+}
+
+func (bw *BlockWriter) WriteMessage(m []byte) error {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(m)))
+	bw.block = append(append(bw.block, length[:]...), m...)
+
+	isFull := len(bw.block) >= bw.blockSize
+	if bw.indexer != nil {
+		isFull = bw.indexer.Add(m)
+	}
+	if isFull {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current block, and publishes it in the index.
+func (bw *BlockWriter) Flush() error {
+	if len(bw.block) == 0 {
+		return nil
+	}
+	stored := bw.block
+	if bw.codec != nil {
+		bw.frame = compressBlock(bw.codec, bw.frame, bw.block)
+		if bw.frame != nil && len(bw.frame)+4 <= len(bw.block)-len(bw.block)/8 {
+			bw.poorBlocks = 0
+			var length [4]byte
+			binary.LittleEndian.PutUint32(length[:], uint32(-int32(len(bw.frame))))
+			stored = append(length[:], bw.frame...)
+		} else {
+			bw.poorBlocks++
+			if bw.isAuto && bw.poorBlocks >= autoGiveUpBlocks {
+				bw.codec = nil
+			}
+		}
+	}
+
+	_, err := bw.w.Write(stored)
+	if bw.indexer != nil {
+		bw.indexer.SetStoredSize(int64(len(stored)))
+		bw.indexer.Flush()
+	}
+	bw.block = bw.block[:0]
+	return err
+}
+
+// Codec returns the codec still compressing the blocks, or nil.
+func (bw *BlockWriter) Codec() Codec {
+	return bw.codec
+}
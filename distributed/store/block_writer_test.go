@@ -0,0 +1,79 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// readBlocks reads back the messages of the shard, decompressing the blocks.
+func readBlocks(t *testing.T, data []byte) (messages [][]byte, compressedBlocks int) {
+	for len(data) >= 4 {
+		length := int32(binary.LittleEndian.Uint32(data))
+		if length >= 0 {
+			messages = append(messages, data[4:4+length])
+			data = data[4+length:]
+			continue
+		}
+		block, err := DecompressBlock(nil, data[4:4-length])
+		if err != nil {
+			t.Fatalf("decompress block: %v", err)
+		}
+		inner, _ := readBlocks(t, block)
+		messages = append(messages, inner...)
+		compressedBlocks++
+		data = data[4-length:]
+	}
+	return
+}
+
+func TestBlockWriter(t *testing.T) {
+	for _, compression := range []string{"lz4", "zstd:9", "auto"} {
+		codec, isAuto, err := ParseCompression(compression)
+		if err != nil {
+			t.Fatalf("parse %s: %v", compression, err)
+		}
+
+		var compressible, random bytes.Buffer
+		index := NewBlockIndex()
+		w := NewBlockWriter(&compressible, codec, isAuto, NewBlockIndexer(index, 1000), 1000)
+		for i := 0; i < 100; i++ {
+			message, _ := util.NewRow(util.Now(), i, "some repeated value").MarshalMsg(nil)
+			w.WriteMessage(message)
+		}
+		w.Flush()
+
+		messages, compressedBlocks := readBlocks(t, compressible.Bytes())
+		if len(messages) != 100 || compressedBlocks == 0 {
+			t.Fatalf("%s: read %d messages in %d compressed blocks", compression, len(messages), compressedBlocks)
+		}
+		if row, err := util.DecodeRow(messages[42]); err != nil || util.Compare(row.K[0], 42) != 0 {
+			t.Errorf("%s: message 42 is %v: %v", compression, row, err)
+		}
+		block, _ := index.SeekRow(42)
+		if next, found := index.BlockAt(block.Offset + block.Size); !found || next.StartRow != block.StartRow+block.RowCount {
+			t.Errorf("%s: block %+v is not followed by %+v", compression, block, next)
+		}
+
+		w = NewBlockWriter(&random, codec, isAuto, nil, 1000)
+		for i := 0; i < 100; i++ {
+			message := make([]byte, 100)
+			rand.Read(message)
+			w.WriteMessage(message)
+		}
+		w.Flush()
+		if _, compressedBlocks := readBlocks(t, random.Bytes()); compressedBlocks != 0 {
+			t.Errorf("%s: stored %d blocks of random bytes compressed", compression, compressedBlocks)
+		}
+		if isAuto != (w.Codec() == nil) {
+			t.Errorf("%s: still compressing random bytes with %v", compression, w.Codec())
+		}
+	}
+
+	if _, _, err := ParseCompression("lz4:3"); err == nil {
+		t.Errorf("lz4 accepts levels")
+	}
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+)
+
+// Codec compresses the blocks of rows of on disk shards. The codecs are
+// safe for concurrent use. Compress may return nil if the block does not
+// shrink.
+type Codec interface {
+	Name() string
+	Compress(dst, src []byte) []byte
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// CompressionAuto selects the codec by the compressibility of the rows. The
+// blocks are compressed with zstd until a few blocks in a row do not shrink,
+// e.g. already compressed or random bytes, and stored as is from then on.
+const CompressionAuto = "auto"
+
+const (
+	codecIdLz4  = 1
+	codecIdZstd = 2
+)
+
+// ParseCompression parses the compression of on disk shards: "" or "none",
+// "lz4", "zstd" or "zstd:<level>" with the zstd levels 1 to 22, or "auto".
+// The codec is nil if the shards are not compressed.
+func ParseCompression(compression string) (codec Codec, isAuto bool, err error) {
+	name, level := compression, ""
+	if i := strings.Index(compression, ":"); i >= 0 {
+		name, level = compression[:i], compression[i+1:]
+	}
+	if level != "" && name != "zstd" {
+		return nil, false, fmt.Errorf("Compression %q has no levels", name)
+	}
+	switch name {
+	case "", "none":
+		codec = nil
+	case "lz4":
+		codec = lz4Codec{}
+	case "zstd", CompressionAuto:
+		zstdLevel := 3
+		if level != "" {
+			if zstdLevel, err = strconv.Atoi(level); err != nil || zstdLevel < 1 || zstdLevel > 22 {
+				return nil, false, fmt.Errorf("Invalid zstd level in compression %q", compression)
+			}
+		}
+		codec, err = newZstdCodec(zstdLevel)
+		isAuto = name == CompressionAuto
+	default:
+		return nil, false, fmt.Errorf("Unknown compression %q", compression)
+	}
+	return
+}
+
+// compressBlock encodes the block as the codec id, the block size, and the
+// compressed bytes. It returns nil if the codec can not compress the block.
+func compressBlock(codec Codec, dst, block []byte) []byte {
+	var header [1 + binary.MaxVarintLen64]byte
+	switch codec.(type) {
+	case lz4Codec:
+		header[0] = codecIdLz4
+	case *zstdCodec:
+		header[0] = codecIdZstd
+	}
+	n := 1 + binary.PutUvarint(header[1:], uint64(len(block)))
+	return codec.Compress(append(dst[:0], header[:n]...), block)
+}
+
+// DecompressBlock decodes a block written by a BlockWriter, appending the
+// messages to dst.
+func DecompressBlock(dst, frame []byte) ([]byte, error) {
+	if len(frame) < 2 {
+		return nil, fmt.Errorf("Invalid compressed block of %d bytes", len(frame))
+	}
+	size, n := binary.Uvarint(frame[1:])
+	if n <= 0 {
+		return nil, fmt.Errorf("Invalid compressed block size")
+	}
+	var codec Codec
+	switch frame[0] {
+	case codecIdLz4:
+		codec = lz4Codec{}
+	case codecIdZstd:
+		codec = zstdDecoder
+	default:
+		return nil, fmt.Errorf("Unknown codec %d of compressed block", frame[0])
+	}
+	if cap(dst)-len(dst) < int(size) {
+		grown := make([]byte, len(dst), len(dst)+int(size))
+		copy(grown, dst)
+		dst = grown
+	}
+	ret, err := codec.Decompress(dst, frame[1+n:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decompress %s block: %v", codec.Name(), err)
+	}
+	if len(ret)-len(dst) != int(size) {
+		return nil, fmt.Errorf("Decompressed %d bytes instead of %d", len(ret)-len(dst), size)
+	}
+	return ret, nil
+}
+
+type lz4Codec struct{}
+
+func (lz4Codec) Name() string { return "lz4" }
+
+func (lz4Codec) Compress(dst, src []byte) []byte {
+	start := len(dst)
+	bound := lz4.CompressBlockBound(len(src))
+	if cap(dst)-start < bound {
+		grown := make([]byte, start, start+bound)
+		copy(grown, dst)
+		dst = grown
+	}
+	n, err := lz4.CompressBlock(src, dst[start:start+bound], nil)
+	if err != nil || n == 0 {
+		// incompressible
+		return nil
+	}
+	return dst[:start+n]
+}
+
+func (lz4Codec) Decompress(dst, src []byte) ([]byte, error) {
+	start := len(dst)
+	n, err := lz4.UncompressBlock(src, dst[start:cap(dst)])
+	if err != nil {
+		return nil, err
+	}
+	return dst[:start+n], nil
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+var (
+	zstdCodecs     = make(map[int]*zstdCodec)
+	zstdCodecsLock sync.Mutex
+
+	// zstdDecoder decompresses the blocks of all levels, concurrently.
+	zstdDecoder = &zstdCodec{}
+)
+
+func init() {
+	zstdDecoder.decoder, _ = zstd.NewReader(nil)
+}
+
+// newZstdCodec shares the encoder of the level by all shards.
+func newZstdCodec(level int) (*zstdCodec, error) {
+	zstdCodecsLock.Lock()
+	defer zstdCodecsLock.Unlock()
+
+	if codec, found := zstdCodecs[level]; found {
+		return codec, nil
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create zstd encoder level %d: %v", level, err)
+	}
+	codec := &zstdCodec{encoder: encoder, decoder: zstdDecoder.decoder}
+	zstdCodecs[level] = codec
+	return codec, nil
+}
+
+func (c *zstdCodec) Name() string { return "zstd" }
+
+func (c *zstdCodec) Compress(dst, src []byte) []byte {
+	return c.encoder.EncodeAll(src, dst)
+}
+
+func (c *zstdCodec) Decompress(dst, src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, dst)
+}
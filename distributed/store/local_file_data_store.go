@@ -2,25 +2,43 @@
 package store
 
 import (
+	"fmt"
 	"io"
 	"path"
+	"sync/atomic"
 	"time"
 )
 
 type DataStore interface {
 	io.Writer
 	io.ReaderAt
+	io.Closer
 	Destroy()
+	// Rename moves the data to the named store, replacing its data.
+	Rename(name string) error
+	// ReleaseUsage stops counting the written bytes in the disk usage, e.g.
+	// after the data is replaced by Rename() of another store.
+	ReleaseUsage()
 	LastWriteAt() time.Time
 	LastReadAt() time.Time
 }
 
+// IndexedDataStore is a DataStore that may keep a block index of its rows.
+type IndexedDataStore interface {
+	DataStore
+	Index() *BlockIndex
+}
+
 type LocalFileDataStore struct {
 	dir         string
 	name        string
 	store       *SingleFileStore
 	lastWriteAt time.Time
 	lastReadAt  time.Time
+	index       *BlockIndex
+	// usage is the disk usage counter the written bytes are added to
+	usage   *int64
+	written int64
 }
 
 func NewLocalFileDataStore(dir, name string) (ds *LocalFileDataStore) {
@@ -28,7 +46,7 @@ func NewLocalFileDataStore(dir, name string) (ds *LocalFileDataStore) {
 		dir:  dir,
 		name: name,
 		store: &SingleFileStore{
-			Filename: path.Join(dir, name+".dat"),
+			Filename: DataFileName(dir, name),
 		},
 		lastWriteAt: time.Now(),
 	}
@@ -36,9 +54,37 @@ func NewLocalFileDataStore(dir, name string) (ds *LocalFileDataStore) {
 	return
 }
 
+// ShardStoreName is the store name of a dataset shard kept by the agent on the port.
+func ShardStoreName(shardName string, port int) string {
+	return fmt.Sprintf("%s-%d", shardName, port)
+}
+
+// DataFileName is the file of the named local file data store.
+func DataFileName(dir, name string) string {
+	return path.Join(dir, name+".dat")
+}
+
+// NewIndexedLocalFileDataStore creates a data store whose writers
+// also build a block index for partial reads.
+func NewIndexedLocalFileDataStore(dir, name string) (ds *LocalFileDataStore) {
+	ds = NewLocalFileDataStore(dir, name)
+	ds.index = NewBlockIndex()
+	return
+}
+
+// CountUsage adds the bytes written to the store to the usage counter,
+// until the store is destroyed or releases them.
+func (ds *LocalFileDataStore) CountUsage(usage *int64) {
+	ds.usage = usage
+}
+
 func (ds *LocalFileDataStore) Write(data []byte) (int, error) {
 	count, err := ds.store.Write(data)
 	ds.lastWriteAt = time.Now()
+	if ds.usage != nil {
+		atomic.AddInt64(&ds.written, int64(count))
+		atomic.AddInt64(ds.usage, int64(count))
+	}
 	return count, err
 }
 
@@ -49,6 +95,29 @@ func (ds *LocalFileDataStore) ReadAt(data []byte, offset int64) (int, error) {
 
 func (ds *LocalFileDataStore) Destroy() {
 	ds.store.Destroy()
+	ds.ReleaseUsage()
+}
+
+func (ds *LocalFileDataStore) ReleaseUsage() {
+	if ds.usage != nil {
+		atomic.AddInt64(ds.usage, -atomic.SwapInt64(&ds.written, 0))
+	}
+}
+
+// Close closes the file, but keeps it, e.g. after it is replaced by Rename()
+// of another store, which would be destroyed instead.
+func (ds *LocalFileDataStore) Close() error {
+	return ds.store.Close()
+}
+
+// Rename moves the file to the file of the named store. The readers of the
+// replaced file keep reading its data until they close it.
+func (ds *LocalFileDataStore) Rename(name string) error {
+	if err := ds.store.Rename(DataFileName(ds.dir, name)); err != nil {
+		return err
+	}
+	ds.name = name
+	return nil
 }
 
 func (ds *LocalFileDataStore) LastWriteAt() time.Time {
@@ -58,3 +127,8 @@ func (ds *LocalFileDataStore) LastWriteAt() time.Time {
 func (ds *LocalFileDataStore) LastReadAt() time.Time {
 	return ds.lastReadAt
 }
+
+// Index returns the block index, or nil if the store is not indexed.
+func (ds *LocalFileDataStore) Index() *BlockIndex {
+	return ds.index
+}
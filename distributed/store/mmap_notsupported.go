@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package store
+
+import (
+	"fmt"
+	"os"
+)
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, fmt.Errorf("mmap is not supported")
+}
+
+func munmap(data []byte) error {
+	return nil
+}
+
+func adviseSequentialRead(data []byte) {
+}
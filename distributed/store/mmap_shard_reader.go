@@ -0,0 +1,130 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// ErrShardNotFinished means the shard file is still being written.
+var ErrShardNotFinished = errors.New("shard is not finished")
+
+// ErrShardCompressed means the shard has compressed blocks, which are
+// decompressed by the agent serving the shard.
+var ErrShardCompressed = errors.New("shard is compressed")
+
+// MmapShardReader reads the messages of a finished on-disk shard through a
+// read-only memory map, so executors on the same machine as the agent can
+// skip the socket. The trailing EOF message is not returned, the same as
+// reading the shard from the agent.
+type MmapShardReader struct {
+	data []byte
+	size int
+	pos  int
+}
+
+// OpenMmapShardReader maps the shard file. It returns ErrShardNotFinished
+// if the file does not end with a complete EOF message yet, and
+// ErrShardCompressed if it has compressed blocks.
+func OpenMmapShardReader(filename string) (*MmapShardReader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < 4 {
+		return nil, ErrShardNotFinished
+	}
+	if int64(int(info.Size())) != info.Size() {
+		return nil, fmt.Errorf("shard file %s is too large to map", filename)
+	}
+
+	data, err := mmapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to mmap %s: %v", filename, err)
+	}
+	adviseSequentialRead(data)
+
+	size, finished, isCompressed := finishedShardSize(data)
+	if !finished || isCompressed {
+		munmap(data)
+		if isCompressed {
+			return nil, ErrShardCompressed
+		}
+		return nil, ErrShardNotFinished
+	}
+
+	return &MmapShardReader{data: data, size: size}, nil
+}
+
+// finishedShardSize walks the message headers, and returns the size
+// of the messages before the EOF message.
+func finishedShardSize(data []byte) (size int, finished, isCompressed bool) {
+	for size+4 <= len(data) {
+		length := int32(binary.LittleEndian.Uint32(data[size : size+4]))
+		if length == int32(util.MessageControlEOF) {
+			return size, size+4 == len(data), isCompressed
+		}
+		if length == int32(util.MessageControlAbort) {
+			// read from the agent, which fails the reader
+			return size, false, isCompressed
+		}
+		if length < 0 {
+			// meta data, the compressed blocks
+			length = -length
+			isCompressed = true
+		}
+		size += 4 + int(length)
+	}
+	return size, false, isCompressed
+}
+
+func (r *MmapShardReader) Read(p []byte) (n int, err error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	n = copy(p, r.data[r.pos:r.size])
+	r.pos += n
+	return n, nil
+}
+
+// WriteTo writes the mapped bytes directly, without copying into a read buffer.
+func (r *MmapShardReader) WriteTo(w io.Writer) (n int64, err error) {
+	for r.pos < r.size {
+		end := r.pos + util.BUFFER_SIZE
+		if end > r.size {
+			end = r.size
+		}
+		m, err := w.Write(r.data[r.pos:end])
+		r.pos += m
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Size returns the bytes of the messages in the shard.
+func (r *MmapShardReader) Size() int {
+	return r.size
+}
+
+func (r *MmapShardReader) Close() error {
+	if r.data == nil {
+		return nil
+	}
+	err := munmap(r.data)
+	r.data = nil
+	r.size, r.pos = 0, 0
+	return err
+}
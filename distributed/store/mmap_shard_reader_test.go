@@ -0,0 +1,120 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+func writeTestShard(t testing.TB, dir string, rows int, finished bool) string {
+	filename := filepath.Join(dir, "shard.dat")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("create %s: %v", filename, err)
+	}
+	defer f.Close()
+	w := util.NewBufferedMessageWriter(f, util.BUFFER_SIZE)
+	message := bytes.Repeat([]byte("x"), 100)
+	for i := 0; i < rows; i++ {
+		w.WriteMessage(message)
+	}
+	w.Flush()
+	if finished {
+		util.WriteEOFMessage(f)
+	}
+	return filename
+}
+
+func TestMmapShardReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmap_shard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := writeTestShard(t, dir, 1000, false)
+	if _, err := OpenMmapShardReader(filename); err != ErrShardNotFinished {
+		t.Fatalf("open unfinished shard: %v", err)
+	}
+
+	filename = writeTestShard(t, dir, 1000, true)
+	r, err := OpenMmapShardReader(filename)
+	if err != nil {
+		t.Fatalf("open finished shard: %v", err)
+	}
+	defer r.Close()
+
+	count := 0
+	err = util.ProcessMessage(r, func(m []byte) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read shard: %v", err)
+	}
+	if count != 1000 {
+		t.Errorf("read %d messages, expected 1000", count)
+	}
+}
+
+const benchmarkShardRows = 100000
+
+func BenchmarkMmapShardRead(b *testing.B) {
+	dir, _ := ioutil.TempDir("", "mmap_shard")
+	defer os.RemoveAll(dir)
+	filename := writeTestShard(b, dir, benchmarkShardRows, true)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := OpenMmapShardReader(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+		n, _ := io.Copy(ioutil.Discard, r)
+		r.Close()
+		b.SetBytes(n)
+	}
+}
+
+// BenchmarkSocketShardRead reads the shard the way the agent serves it,
+// by ReadAt calls sent through a loopback connection.
+func BenchmarkSocketShardRead(b *testing.B) {
+	dir, _ := ioutil.TempDir("", "mmap_shard")
+	defer os.RemoveAll(dir)
+	filename := writeTestShard(b, dir, benchmarkShardRows, true)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			f, _ := os.Open(filename)
+			io.Copy(conn, io.NewSectionReader(f, 0, 1<<62))
+			f.Close()
+			conn.Close()
+		}
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			b.Fatal(err)
+		}
+		n, _ := io.Copy(ioutil.Discard, conn)
+		conn.Close()
+		b.SetBytes(n)
+	}
+}
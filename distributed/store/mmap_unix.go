@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package store
+
+import (
+	"os"
+	"syscall"
+)
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
+
+// adviseSequentialRead asks the kernel to read ahead aggressively,
+// and to drop the pages soon after they are read.
+func adviseSequentialRead(data []byte) {
+	syscall.Madvise(data, syscall.MADV_SEQUENTIAL)
+	syscall.Madvise(data, syscall.MADV_WILLNEED)
+}
@@ -123,6 +123,17 @@ func (l *SingleFileStore) filename() string {
 	return l.Filename
 }
 
+// Rename moves the file, replacing the existing file.
+func (l *SingleFileStore) Rename(filename string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.Rename(l.Filename, filename); err != nil {
+		return fmt.Errorf("Failed to rename %s to %s: %v", l.Filename, filename, err)
+	}
+	l.Filename = filename
+	return nil
+}
+
 func (l *SingleFileStore) Destroy() {
 	// println("removing file", l.filename())
 	l.Close()
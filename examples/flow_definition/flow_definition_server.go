@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/lovelly/gleam/distributed"
+	"github.com/lovelly/gleam/distributed/definition"
+	"github.com/lovelly/gleam/gio"
+	_ "github.com/lovelly/gleam/gio/mapper"
+	_ "github.com/lovelly/gleam/gio/reducer"
+	_ "github.com/lovelly/gleam/plugins/file/orc"
+	_ "github.com/lovelly/gleam/plugins/file/parquet"
+)
+
+var (
+	address       = flag.String("address", ":45330", "listening address of the flow definitions")
+	master        = flag.String("master", "localhost:45326", "master address")
+	token         = flag.String("token", "", "access token required in the flow definitions")
+	allowCommands = flag.Bool("allow.commands", false, "allow the pipe steps to run shell commands")
+	allowPaths    = flag.String("allow.paths", "", "comma separated folders of the files the steps can read and write")
+)
+
+// Serves flows defined in JSON, YAML or protobuf, using the mappers and
+// reducers registered in this binary, e.g.
+//
+//	flow_definition_server --token secret --allow.paths /etc/passwd
+//	gleam submit --server localhost:45330 --token secret --definition word_count.yaml
+func main() {
+
+	gio.Init() // If the command line invokes the mapper or reducer, execute it and exit.
+	flag.Parse()
+
+	option := definition.ServerOption{
+		AccessToken: *token,
+		Permissions: definition.Permissions{AllowCommands: *allowCommands},
+	}
+	if *allowPaths != "" {
+		option.Permissions.AllowedPaths = strings.Split(*allowPaths, ",")
+	}
+	log.Fatal(definition.Serve(*address, option, distributed.Option().WithMaster(*master)))
+}
@@ -4,6 +4,7 @@ import (
 	. "github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/gio"
 	"github.com/lovelly/gleam/plugins/file"
+	_ "github.com/lovelly/gleam/plugins/file/parquet"
 )
 
 func main() {
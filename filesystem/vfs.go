@@ -27,6 +27,7 @@ type VirtualFile interface {
 type VirtualFileSystem interface {
 	Accept(*FileLocation) bool
 	Open(*FileLocation) (VirtualFile, error)
+	Create(*FileLocation) (io.WriteCloser, error)
 	List(*FileLocation) ([]*FileLocation, error)
 	IsDir(*FileLocation) bool
 }
@@ -36,6 +37,7 @@ var (
 		&LocalFileSystem{},
 		&HdfsFileSystem{},
 		&S3FileSystem{},
+		&GcsFileSystem{},
 	}
 )
 
@@ -53,6 +55,19 @@ func Open(filepath string) (VirtualFile, error) {
 	return nil, fmt.Errorf("Unknown file %s", filepath)
 }
 
+// Create creates or truncates the file for writing, and creates its
+// parent directories if needed.
+// The file is complete when the returned writer is closed.
+func Create(filepath string) (io.WriteCloser, error) {
+	fileLocation := &FileLocation{filepath}
+	for _, fs := range fileSystems {
+		if fs.Accept(fileLocation) {
+			return fs.Create(fileLocation)
+		}
+	}
+	return nil, fmt.Errorf("Unknown file %s", filepath)
+}
+
 func List(filepath string) ([]*FileLocation, error) {
 	fileLocation := &FileLocation{filepath}
 	for _, fs := range fileSystems {
@@ -0,0 +1,179 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GcsFileSystem accesses the gs://bucket/object files of Google Cloud
+// Storage, with the application default credentials.
+type GcsFileSystem struct {
+}
+
+func (fs *GcsFileSystem) Accept(fl *FileLocation) bool {
+	return strings.HasPrefix(fl.Location, "gs://")
+}
+
+func (fs *GcsFileSystem) Open(fl *FileLocation) (VirtualFile, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create gcs client: %v", err)
+	}
+	bucketName, objectName, err := splitGcsLocationToParts(fl.Location)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	object := client.Bucket(bucketName).Object(objectName)
+	attrs, err := object.Attrs(context.Background())
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Failed to stat %s: %v", fl.Location, err)
+	}
+	return &VirtualFileGcs{client: client, object: object, size: attrs.Size}, nil
+}
+
+// Create uploads the object while it is written, and completes it when the
+// writer is closed.
+func (fs *GcsFileSystem) Create(fl *FileLocation) (io.WriteCloser, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create gcs client: %v", err)
+	}
+	bucketName, objectName, err := splitGcsLocationToParts(fl.Location)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	w := client.Bucket(bucketName).Object(objectName).NewWriter(context.Background())
+	return &gcsFileWriter{w, client}, nil
+}
+
+// List lists the objects directly under the folder.
+func (fs *GcsFileSystem) List(fl *FileLocation) (fileLocations []*FileLocation, err error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create gcs client: %v", err)
+	}
+	defer client.Close()
+
+	bucketName, prefix, err := splitGcsLocationToParts(strings.TrimSuffix(fl.Location, "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	it := client.Bucket(bucketName).Objects(context.Background(), &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list %s: %v", fl.Location, err)
+		}
+		if attrs.Name == "" {
+			// a sub folder
+			continue
+		}
+		fileLocations = append(fileLocations, &FileLocation{"gs://" + bucketName + "/" + attrs.Name})
+	}
+	return fileLocations, nil
+}
+
+func (fs *GcsFileSystem) IsDir(fl *FileLocation) bool {
+	return false
+}
+
+func splitGcsLocationToParts(location string) (bucketName, objectName string, err error) {
+	gcsPrefix := "gs://"
+	if !strings.HasPrefix(location, gcsPrefix) {
+		return "", "", fmt.Errorf("parameter %s should start with gs://", location)
+	}
+	parts := strings.SplitN(location[len(gcsPrefix):], "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("parameter %s has no object name", location)
+	}
+	return parts[0], parts[1], nil
+}
+
+// VirtualFileGcs reads the object by ranges.
+type VirtualFileGcs struct {
+	client *storage.Client
+	object *storage.ObjectHandle
+	size   int64
+	offset int64
+	reader io.ReadCloser
+}
+
+func (vf *VirtualFileGcs) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= vf.size {
+		return 0, io.EOF
+	}
+	r, err := vf.object.NewRangeReader(context.Background(), off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	n, err = io.ReadFull(r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (vf *VirtualFileGcs) Read(p []byte) (n int, err error) {
+	if vf.reader == nil {
+		if vf.reader, err = vf.object.NewRangeReader(context.Background(), vf.offset, -1); err != nil {
+			return 0, err
+		}
+	}
+	n, err = vf.reader.Read(p)
+	vf.offset += int64(n)
+	return n, err
+}
+
+func (vf *VirtualFileGcs) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += vf.offset
+	case io.SeekEnd:
+		offset += vf.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("Seek to negative offset %d", offset)
+	}
+	if offset != vf.offset && vf.reader != nil {
+		vf.reader.Close()
+		vf.reader = nil
+	}
+	vf.offset = offset
+	return offset, nil
+}
+
+func (vf *VirtualFileGcs) Size() int64 {
+	return vf.size
+}
+
+func (vf *VirtualFileGcs) Close() error {
+	if vf.reader != nil {
+		vf.reader.Close()
+	}
+	return vf.client.Close()
+}
+
+type gcsFileWriter struct {
+	*storage.Writer
+	client *storage.Client
+}
+
+func (w *gcsFileWriter) Close() error {
+	defer w.client.Close()
+	if err := w.Writer.Close(); err != nil {
+		return fmt.Errorf("Failed to upload gs://%s/%s: %v", w.Writer.Bucket, w.Writer.Name, err)
+	}
+	return nil
+}
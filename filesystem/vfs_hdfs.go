@@ -2,8 +2,10 @@ package filesystem
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/colinmarc/hdfs"
@@ -42,6 +44,26 @@ func (fs *HdfsFileSystem) Open(fl *FileLocation) (VirtualFile, error) {
 	return &VirtualFileHdfs{file}, err
 }
 
+func (fs *HdfsFileSystem) Create(fl *FileLocation) (io.WriteCloser, error) {
+	namenode, path, err := splitLocationToParts(fl.Location)
+	if err != nil {
+		return nil, err
+	}
+	if namenode == "" {
+		namenode = os.Getenv("HADOOP_NAMENODE")
+	}
+
+	client, err := hdfs.New(namenode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client to %s:%v\n", namenode, err)
+	}
+	if err = client.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	return client.Create(path)
+}
+
 // List generates a full list of file locations under the given
 // location, which should have a prefix of hdfs://
 func (fs *HdfsFileSystem) List(fl *FileLocation) (fileLocations []*FileLocation, err error) {
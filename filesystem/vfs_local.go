@@ -1,9 +1,11 @@
 package filesystem
 
 import (
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -19,6 +21,13 @@ func (fs *LocalFileSystem) Open(fl *FileLocation) (VirtualFile, error) {
 	return &VirtualFileLocal{osFile}, err
 }
 
+func (fs *LocalFileSystem) Create(fl *FileLocation) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(fl.Location), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(fl.Location)
+}
+
 func (fs *LocalFileSystem) List(fl *FileLocation) (fileLocations []*FileLocation, err error) {
 	files, err := ioutil.ReadDir(fl.Location)
 	if err != nil {
@@ -26,16 +26,11 @@ func (fs *S3FileSystem) Accept(fl *FileLocation) bool {
 }
 
 func (fs *S3FileSystem) Open(fl *FileLocation) (VirtualFile, error) {
-	sess, err := session.NewSession(aws.NewConfig().WithCredentials(
-		credentials.NewStaticCredentials(Option[AWS_ACCESS_KEY], Option[AWS_SECRET_KEY], ""),
-	))
+	svc, err := newS3Service()
 	if err != nil {
-		fmt.Println("failed to create session,", err)
 		return nil, err
 	}
 
-	svc := s3.New(sess)
-
 	bucketName, objectKey, err := splitS3LocationToParts(fl.Location)
 
 	if err != nil {
@@ -55,14 +50,69 @@ func (fs *S3FileSystem) Open(fl *FileLocation) (VirtualFile, error) {
 	return newVirtualFileS3(resp.Body)
 }
 
+// Create writes to a temporary file, which is uploaded when the writer is closed.
+func (fs *S3FileSystem) Create(fl *FileLocation) (io.WriteCloser, error) {
+	svc, err := newS3Service()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName, objectKey, err := splitS3LocationToParts(fl.Location)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to split S3 location to parts %s: %v", fl.Location, err)
+	}
+
+	filename := fmt.Sprintf("%s/s3_%d", os.TempDir(), rand.Uint32())
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &s3FileWriter{outFile, svc, bucketName, objectKey}, nil
+}
+
+// List lists the objects directly under the folder.
 func (fs *S3FileSystem) List(fl *FileLocation) (fileLocations []*FileLocation, err error) {
-	return nil, fmt.Errorf("S3 Listing is not supported yet.")
+	svc, err := newS3Service()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName, prefix, err := splitS3LocationToParts(strings.TrimSuffix(fl.Location, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to split S3 location to parts %s: %v", fl.Location, err)
+	}
+
+	err = svc.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucketName),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			fileLocations = append(fileLocations, &FileLocation{"s3://" + bucketName + "/" + aws.StringValue(object.Key)})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list %s: %v", fl.Location, err)
+	}
+	return fileLocations, nil
 }
 
 func (fs *S3FileSystem) IsDir(fl *FileLocation) bool {
 	return false
 }
 
+func newS3Service() (*s3.S3, error) {
+	sess, err := session.NewSession(aws.NewConfig().WithCredentials(
+		credentials.NewStaticCredentials(Option[AWS_ACCESS_KEY], Option[AWS_SECRET_KEY], ""),
+	))
+	if err != nil {
+		fmt.Println("failed to create session,", err)
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
 func splitS3LocationToParts(location string) (bucketName, objectKey string, err error) {
 	s3Prefix := "s3://"
 	if !strings.HasPrefix(location, s3Prefix) {
@@ -102,3 +152,29 @@ func (vf *VirtualFileS3) Close() error {
 	vf.File.Close()
 	return os.Remove(vf.filename)
 }
+
+type s3FileWriter struct {
+	*os.File
+	svc        *s3.S3
+	bucketName string
+	objectKey  string
+}
+
+// Close uploads the written file, and removes the temporary file.
+func (w *s3FileWriter) Close() error {
+	defer os.Remove(w.File.Name())
+	defer w.File.Close()
+
+	if _, err := w.File.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err := w.svc.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(w.bucketName),
+		Key:    aws.String(w.objectKey),
+		Body:   w.File,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to upload s3://%s/%s: %v", w.bucketName, w.objectKey, err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/lovelly/gleam/gio"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 )
 
 func New(name string) (fc *Flow) {
@@ -29,7 +30,7 @@ func (fc *Flow) Run(options ...FlowOption) {
 func (fc *Flow) RunContext(ctx context.Context, options ...FlowOption) {
 
 	if !gio.HasInitalized {
-		println("gio.Init() is required right after main() if pure go mapper or reducer is used.")
+		logger.Errorf("gio.Init() is required right after main() if pure go mapper or reducer is used.")
 		os.Exit(1)
 	}
 
@@ -225,3 +226,68 @@ func fromTaskToDatasetShard(task *Task, shard *DatasetShard) {
 		task.OutputShards = append(task.OutputShards, shard)
 	}
 }
+
+// removeStep disconnects a step without output dataset from its inputs, and
+// removes it from the flow, e.g. the step added by Collect() for one run.
+func (f *Flow) removeStep(step *Step) {
+	f.Steps = withoutStep(f.Steps, step)
+	for _, input := range step.InputDatasets {
+		input.ReadingSteps = withoutStep(input.ReadingSteps, step)
+	}
+	for _, task := range step.Tasks {
+		for _, shard := range task.InputShards {
+			for i, t := range shard.ReadingTasks {
+				if t == task {
+					shard.ReadingTasks = append(shard.ReadingTasks[:i], shard.ReadingTasks[i+1:]...)
+					shard.OutgoingChans = append(shard.OutgoingChans[:i], shard.OutgoingChans[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+// replaceInput makes the step read the shards of the replacement instead of
+// the input, which has as many shards, e.g. the cached shards of a dataset of
+// another flow.
+func replaceInput(step *Step, input, replacement *Dataset) {
+	isReading := false
+	for i, d := range step.InputDatasets {
+		if d == input {
+			step.InputDatasets[i] = replacement
+			isReading = true
+		}
+	}
+	if !isReading {
+		return
+	}
+	input.ReadingSteps = withoutStep(input.ReadingSteps, step)
+	replacement.ReadingSteps = append(replacement.ReadingSteps, step)
+	for _, task := range step.Tasks {
+		for j, shard := range task.InputShards {
+			if shard.Dataset != input {
+				continue
+			}
+			for i, t := range shard.ReadingTasks {
+				if t == task {
+					shard.ReadingTasks = append(shard.ReadingTasks[:i], shard.ReadingTasks[i+1:]...)
+					shard.OutgoingChans = append(shard.OutgoingChans[:i], shard.OutgoingChans[i+1:]...)
+					break
+				}
+			}
+			replaced := replacement.Shards[shard.Id]
+			replaced.ReadingTasks = append(replaced.ReadingTasks, task)
+			replaced.OutgoingChans = append(replaced.OutgoingChans, task.InputChans[j])
+			task.InputShards[j] = replaced
+		}
+	}
+}
+
+func withoutStep(steps []*Step, step *Step) []*Step {
+	for i, s := range steps {
+		if s == step {
+			return append(steps[:i], steps[i+1:]...)
+		}
+	}
+	return steps
+}
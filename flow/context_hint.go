@@ -28,6 +28,50 @@ func (d *Dataset) GetTotalSize() int64 {
 	return currentDatasetTotalSize
 }
 
+// GetInputSize returns the total size in MB of the inputs of the flow, and
+// whether it is known, i.e. every source is in memory, e.g. from Strings(), or
+// is read into a dataset hinted with TotalSize() or PartitionSize().
+func (fc *Flow) GetInputSize() (totalSize int64, isKnown bool) {
+	visited := make(map[*Dataset]bool)
+	var collect func(d *Dataset) bool
+	collect = func(d *Dataset) bool {
+		if visited[d] {
+			return true
+		}
+		visited[d] = true
+		if d.Meta.IsSizeKnown {
+			totalSize += d.Meta.TotalSize
+			return true
+		}
+		if len(d.Step.InputDatasets) == 0 {
+			return false
+		}
+		for _, ds := range d.Step.InputDatasets {
+			if !collect(ds) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, step := range fc.Steps {
+		if step.OutputDataset != nil {
+			continue
+		}
+		// the inputs of the steps writing out of the flow
+		for _, d := range step.InputDatasets {
+			if !collect(d) {
+				return 0, false
+			}
+		}
+	}
+	for _, d := range fc.Datasets {
+		if len(d.ReadingSteps) == 0 && !collect(d) {
+			return 0, false
+		}
+	}
+	return totalSize, true
+}
+
 // GetPartitionSize returns the size in MB for each partition of
 // the dataset. This is based on the hinted total size divided by
 // the number of partitions.
@@ -40,3 +84,12 @@ func (d *Dataset) GetPartitionSize() int64 {
 func (d *Dataset) GetIsOnDiskIO() bool {
 	return d.Meta.OnDisk == ModeOnDisk
 }
+
+// GetCompression returns the hinted codec of the on disk shards, or ""
+// for the default of the flow. The shards in memory are not compressed.
+func (d *Dataset) GetCompression() string {
+	if !d.GetIsOnDiskIO() {
+		return ""
+	}
+	return d.Meta.Compression
+}
@@ -0,0 +1,53 @@
+package flow
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestGetInputSize(t *testing.T) {
+	unknown := func(f *Flow) *Dataset {
+		return f.Source("unknown", func(io.Writer, *pb.InstructionStat) error { return nil })
+	}
+	tests := []struct {
+		name    string
+		build   func(f *Flow)
+		size    int64
+		isKnown bool
+	}{
+		{"in memory", func(f *Flow) {
+			f.Strings([]string{"a", "b"}).Fprintf(ioutil.Discard, "%s\n")
+		}, 0, true},
+		{"hinted", func(f *Flow) {
+			unknown(f).Hint(TotalSize(5)).Fprintf(ioutil.Discard, "%s\n")
+		}, 5, true},
+		{"hinted after a step", func(f *Flow) {
+			unknown(f).Map("map", "x").Hint(TotalSize(3)).Fprintf(ioutil.Discard, "%s\n")
+		}, 3, true},
+		{"joined", func(f *Flow) {
+			unknown(f).Hint(TotalSize(3)).JoinByKey("join", unknown(f).Hint(PartitionSize(4))).
+				Fprintf(ioutil.Discard, "%s\n")
+		}, 7, true},
+		{"not hinted", func(f *Flow) {
+			unknown(f).Fprintf(ioutil.Discard, "%s\n")
+		}, 0, false},
+		{"one input not hinted", func(f *Flow) {
+			unknown(f).Hint(TotalSize(3)).JoinByKey("join", unknown(f)).Fprintf(ioutil.Discard, "%s\n")
+		}, 0, false},
+		{"dataset not read", func(f *Flow) {
+			f.Strings([]string{"a"}).Fprintf(ioutil.Discard, "%s\n")
+			unknown(f)
+		}, 0, false},
+	}
+	for _, test := range tests {
+		f := New("testGetInputSize")
+		test.build(f)
+		size, isKnown := f.GetInputSize()
+		if size != test.size || isKnown != test.isKnown {
+			t.Errorf("%s: input size %d, known %v, expected %d, known %v", test.name, size, isKnown, test.size, test.isKnown)
+		}
+	}
+}
@@ -41,6 +41,17 @@ func (s *DatasetShard) TimeTaken() time.Duration {
 	return time.Now().Sub(s.ReadyTime)
 }
 
+// CachedShardName is the name of a shard of the datasets cached by the name,
+// kept by the agents between the flows.
+func CachedShardName(cacheName string, shardId int) string {
+	return fmt.Sprintf("c-%s-s%d", cacheName, shardId)
+}
+
+// Name identifies the shard on the agents. The shards of cached datasets
+// are named by the cache name, to be found by the later flows.
 func (s *DatasetShard) Name() string {
+	if s.Dataset.Meta.CacheName != "" {
+		return CachedShardName(s.Dataset.Meta.CacheName, s.Id)
+	}
 	return fmt.Sprintf("f%d-d%d-s%d", s.Dataset.Flow.HashCode, s.Dataset.Id, s.Id)
 }
@@ -0,0 +1,80 @@
+package flow
+
+import (
+	"log"
+	"sync"
+)
+
+// Bucketing describes the buckets kept by BucketBy().
+type Bucketing struct {
+	KeyFields   []int // the rows are hashed and sorted by these fields
+	BucketCount int
+}
+
+// bucketings are the buckets kept by BucketBy() in this process, by name.
+var bucketings = struct {
+	sync.Mutex
+	byName map[string]Bucketing
+}{
+	byName: make(map[string]Bucketing),
+}
+
+// BucketBy hashes the rows by the key fields into n buckets, sorts each
+// bucket by the key fields, and keeps the buckets by the name after the
+// flow, like Persist() on disk, and so does the master for the flows run by
+// agents, with how they are bucketed. The buckets are stable: a key is always in
+// the same bucket of n, as in all datasets partitioned by the key into n
+// shards. Later flows read the buckets with Flow.Buckets(), and can join
+// them with other buckets of the same key fields and count, e.g. by
+// JoinByKey() if the key is the first field, without shuffling or sorting
+// either side, similar to Hive bucketed tables.
+func (d *Dataset) BucketBy(name string, keyFields []int, n int) *Dataset {
+	if len(keyFields) == 0 || n <= 0 {
+		log.Panicf("BucketBy %s needs key fields and a positive bucket count, not %v and %d", name, keyFields, n)
+	}
+	sortOption := Field(keyFields...)
+	ret := d.Partition(name, n, sortOption).LocalSort(name, sortOption)
+	ret.Persist(name, ModeOnDisk)
+
+	bucketing := Bucketing{
+		KeyFields:   append([]int(nil), keyFields...),
+		BucketCount: n,
+	}
+	// the drivers save it to the master, after the agents keep the buckets
+	ret.Meta.Bucketing = &bucketing
+	bucketings.Lock()
+	bucketings.byName[name] = bucketing
+	bucketings.Unlock()
+	return ret
+}
+
+// GetBucketing returns how the buckets kept by the name are bucketed.
+func GetBucketing(name string) (Bucketing, bool) {
+	bucketings.Lock()
+	defer bucketings.Unlock()
+	bucketing, found := bucketings.byName[name]
+	return bucketing, found
+}
+
+// Buckets reads the buckets kept by BucketBy() in an earlier flow, as a
+// dataset partitioned and sorted by the key fields, with a shard for each
+// bucket. The buckets written by another process are found by the options
+// of the flows run by agents, which ask the master. The flow fails if the
+// buckets are no longer kept.
+func (fc *Flow) Buckets(name string, options ...FlowOption) *Dataset {
+	bucketing, found := GetBucketing(name)
+	for _, option := range options {
+		if g, ok := option.(interface {
+			GetBucketing(name string) (Bucketing, bool)
+		}); ok && !found {
+			bucketing, found = g.GetBucketing(name)
+		}
+	}
+	if !found {
+		log.Panicf("No buckets named %s are kept by BucketBy()", name)
+	}
+	ret := fc.cachedInput("Buckets", name, bucketing.BucketCount)
+	ret.IsPartitionedBy = bucketing.KeyFields
+	ret.IsLocalSorted = Field(bucketing.KeyFields...).orderByList
+	return ret
+}
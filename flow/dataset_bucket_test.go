@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/instruction"
+)
+
+func TestBucketedJoin(t *testing.T) {
+	f := New("testBucketedJoinWrite")
+	f.Slices([][]interface{}{{"a", 1}, {"b", 2}, {"c", 3}}).BucketBy("testBucketedJoinLeft", []int{1}, 3)
+	f.Slices([][]interface{}{{"c", "z"}, {"a", "x"}, {"d", "w"}}).BucketBy("testBucketedJoinRight", []int{1}, 3)
+	f.Run()
+	defer Uncache("testBucketedJoinLeft")
+	defer Uncache("testBucketedJoinRight")
+
+	g := New("testBucketedJoinRead")
+	joined := g.Buckets("testBucketedJoinLeft").JoinByKey("join", g.Buckets("testBucketedJoinRight"))
+	for _, step := range g.Steps {
+		switch step.Instruction.(type) {
+		case *instruction.ScatterPartitions, *instruction.CollectPartitions, *instruction.LocalSort:
+			t.Errorf("joining the buckets added step %s", step.Name)
+		}
+	}
+
+	rows, err := joined.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v %v %v", row...))
+	}
+	sort.Strings(got)
+	if expected := []string{"a 1 x", "c 3 z"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("joined %v, expected %v", got, expected)
+	}
+}
+
+// bucketingOption finds the buckets saved by other processes.
+type bucketingOption map[string]Bucketing
+
+func (o bucketingOption) GetFlowRunner() FlowRunner { return Local }
+func (o bucketingOption) GetBucketing(name string) (Bucketing, bool) {
+	bucketing, found := o[name]
+	return bucketing, found
+}
+
+func TestBucketsOfOtherProcesses(t *testing.T) {
+	option := bucketingOption{"testBucketsOfOtherProcesses": {KeyFields: []int{2, 1}, BucketCount: 4}}
+	d := New("testBucketsOfOtherProcesses").Buckets("testBucketsOfOtherProcesses", option)
+	if len(d.Shards) != 4 || !reflect.DeepEqual(d.IsPartitionedBy, []int{2, 1}) {
+		t.Errorf("read %d buckets partitioned by %v", len(d.Shards), d.IsPartitionedBy)
+	}
+}
@@ -0,0 +1,277 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/script"
+	"github.com/lovelly/gleam/util"
+)
+
+var cacheNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Cache keeps the shards of the dataset by the name after the flow runs, so
+// the datasets cached by the same name in later flows, e.g. the iterations
+// of a loop, read them instead of running their steps again. Local flows
+// keep the shards in memory, see Persist(). The agents keep them on disk,
+// until unused for 24 hours, and report them to the master, where the
+// drivers of later flows find them by the names "c-<name>-s<shard id>".
+// The shards are only reused by datasets with as many shards, and whose
+// steps do not share their inputs with other steps.
+func (d *Dataset) Cache(name string) *Dataset {
+	return d.Persist(name, ModeInMemory)
+}
+
+// Persist is like Cache(), keeping the shards of local flows in memory or on
+// disk by the mode.
+func (d *Dataset) Persist(name string, mode ModeIO) *Dataset {
+	if !cacheNamePattern.MatchString(name) {
+		log.Panicf("Cache name %q should only have letters, digits, '_' or '-'", name)
+	}
+	d.Meta.CacheName = name
+	d.Meta.CacheMode = mode
+	// the agents only keep the shards on disk
+	d.Meta.OnDisk = ModeOnDisk
+	return d
+}
+
+// CacheTo keeps the shards of the dataset by the name on disk, see Persist(),
+// runs the flow, and returns the count of the rows kept. Unlike Run(), it
+// returns the errors of the flow, and then the shards may not be kept.
+// Later flows read the shards with Cached().
+func (d *Dataset) CacheTo(ctx context.Context, name string, options ...FlowOption) (int64, error) {
+	d.Persist(name, ModeOnDisk)
+	counts, step := add1ShardTo1Step(d)
+	step.SetInstruction(name+".count", instruction.NewCountRows())
+	// each shard counts its rows, summed up by the driver
+	rows, err := counts.MergeTo(name, 1).Collect(ctx, options...)
+	if err != nil {
+		return 0, err
+	}
+	if isLocal(options) && !IsCached(name) {
+		return 0, fmt.Errorf("Failed to keep the shards of %s", name)
+	}
+	var count int64
+	for _, row := range rows {
+		count += util.ToInt64(row[0])
+	}
+	return count, nil
+}
+
+// Cached reads the shards cached by the name in an earlier flow, e.g. by
+// CacheTo(). The step only runs, and fails, if the shards are no longer
+// cached.
+func (fc *Flow) Cached(name string, shardCount int) *Dataset {
+	return fc.cachedInput("Cached", name, shardCount)
+}
+
+// isLocal tells whether the flow runs locally with the options.
+func isLocal(options []FlowOption) bool {
+	for _, option := range options {
+		if option != FlowOption(Local) {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedInput reads the shards cached by the name in an earlier flow. The
+// step only runs, and fails, if the shards are no longer cached.
+func (fc *Flow) cachedInput(stepName, cacheName string, shardCount int) *Dataset {
+	ret := fc.NewNextDataset(shardCount)
+	step := fc.NewStep()
+	step.NetworkType = OneShardToOneShard
+	fromStepToDataset(step, ret)
+	for _, shard := range ret.Shards {
+		fromTaskToDatasetShard(step.NewTask(), shard)
+	}
+
+	problem := fmt.Sprintf("the shards cached as %s are not found", cacheName)
+	step.Name = stepName
+	step.Function = func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return fmt.Errorf("Failed to read %s", problem)
+	}
+	// the driver registers the cached shards before scheduling the step,
+	// so the agents only run it if the shards are dropped
+	step.Command = script.NewShellScript().Pipe(fmt.Sprintf("echo 'Failed to read %s' >&2; exit 1", problem)).GetCommand()
+
+	return ret.Cache(cacheName)
+}
+
+// IsCached tells whether the shards cached by the name are kept locally.
+func IsCached(name string) bool {
+	localCache.Lock()
+	defer localCache.Unlock()
+	_, found := localCache.shards[name]
+	return found
+}
+
+// Uncache drops the shards cached locally by the name.
+func Uncache(name string) {
+	localCache.Lock()
+	defer localCache.Unlock()
+	for _, shard := range localCache.shards[name] {
+		shard.remove()
+	}
+	delete(localCache.shards, name)
+}
+
+// localCache keeps the shards of the datasets cached by local flows, by the
+// cache names. The shards are pending until all of them are written.
+var localCache = struct {
+	sync.Mutex
+	shards  map[string][]*cachedShard
+	pending map[*Dataset]*pendingShards
+}{
+	shards:  make(map[string][]*cachedShard),
+	pending: make(map[*Dataset]*pendingShards),
+}
+
+type pendingShards struct {
+	shards []*cachedShard
+	count  int
+	failed bool
+}
+
+// cachedShard is a shard kept in memory, or in a file if path is set.
+type cachedShard struct {
+	data []byte
+	path string
+}
+
+func (c *cachedShard) open() (io.ReadCloser, error) {
+	if c.path == "" {
+		return ioutil.NopCloser(bytes.NewReader(c.data)), nil
+	}
+	return os.Open(c.path)
+}
+
+func (c *cachedShard) remove() {
+	if c.path != "" {
+		os.Remove(c.path)
+	}
+}
+
+// cachedShards returns the shards cached by the dataset's cache name, if
+// they can be read instead of running the steps of the dataset.
+func (d *Dataset) cachedShards() []*cachedShard {
+	if d.Meta.CacheName == "" {
+		return nil
+	}
+	localCache.Lock()
+	shards := localCache.shards[d.Meta.CacheName]
+	localCache.Unlock()
+	if len(shards) != len(d.Shards) || !d.hasOwnInputs() {
+		return nil
+	}
+	return shards
+}
+
+// hasOwnInputs tells whether the datasets before this dataset are only read
+// by its steps, so they need not run if this dataset is read from its cache.
+func (d *Dataset) hasOwnInputs() bool {
+	steps := make(map[*Step]bool)
+	var visit func(step *Step)
+	visit = func(step *Step) {
+		if steps[step] {
+			return
+		}
+		steps[step] = true
+		for _, input := range step.InputDatasets {
+			visit(input.Step)
+		}
+	}
+	visit(d.Step)
+	for step := range steps {
+		if step == d.Step || step.OutputDataset == nil {
+			continue
+		}
+		for _, reader := range step.OutputDataset.ReadingSteps {
+			if !steps[reader] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// shardCapture copies a shard of a cached dataset as it is written.
+type shardCapture struct {
+	shard *DatasetShard
+	buf   bytes.Buffer
+	file  *os.File
+	err   error
+}
+
+func newShardCapture(shard *DatasetShard) *shardCapture {
+	c := &shardCapture{shard: shard}
+	if shard.Dataset.Meta.CacheMode == ModeOnDisk {
+		c.file, c.err = ioutil.TempFile("", "gleam-cache-"+shard.Dataset.Meta.CacheName+"-")
+	}
+	return c
+}
+
+func (c *shardCapture) Write(p []byte) (int, error) {
+	if c.err != nil {
+		// the shard is not cached, but it is still written to the readers
+		return len(p), nil
+	}
+	if c.file != nil {
+		_, c.err = c.file.Write(p)
+		return len(p), nil
+	}
+	return c.buf.Write(p)
+}
+
+// commit keeps the shard, if it was written completely, and caches the
+// dataset when all its shards are kept.
+func (c *shardCapture) commit(err error) {
+	if err == nil {
+		err = c.err
+	}
+	shard := &cachedShard{data: c.buf.Bytes()}
+	if c.file != nil {
+		shard.path = c.file.Name()
+		if closeErr := c.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	d := c.shard.Dataset
+	if err != nil {
+		shardLogger(c.shard).Errorf("Failed to cache shard %s as %s: %v", c.shard.Name(), d.Meta.CacheName, err)
+	}
+
+	localCache.Lock()
+	defer localCache.Unlock()
+	pending := localCache.pending[d]
+	if pending == nil {
+		pending = &pendingShards{shards: make([]*cachedShard, len(d.Shards))}
+		localCache.pending[d] = pending
+	}
+	pending.shards[c.shard.Id] = shard
+	pending.count++
+	pending.failed = pending.failed || err != nil
+	if pending.count < len(d.Shards) {
+		return
+	}
+	delete(localCache.pending, d)
+	if pending.failed {
+		for _, s := range pending.shards {
+			s.remove()
+		}
+		return
+	}
+	for _, old := range localCache.shards[d.Meta.CacheName] {
+		old.remove()
+	}
+	localCache.shards[d.Meta.CacheName] = pending.shards
+}
@@ -0,0 +1,42 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestCacheTo(t *testing.T) {
+	ctx := context.Background()
+	rows := [][]interface{}{{"a", int64(1)}, {"b", nil}, {"c", 2.5}}
+
+	count, err := New("testCacheTo").Slices(rows).CacheTo(ctx, "test-cache-to")
+	defer Uncache("test-cache-to")
+	if err != nil {
+		t.Fatalf("cache to: %v", err)
+	}
+	if count != int64(len(rows)) {
+		t.Errorf("cached %d rows, expecting %d", count, len(rows))
+	}
+	cached, err := New("testCached").Cached("test-cache-to", 1).Collect(ctx)
+	if err != nil {
+		t.Fatalf("read cached: %v", err)
+	}
+	if !reflect.DeepEqual(cached, rows) {
+		t.Errorf("read %v, expecting %v", cached, rows)
+	}
+
+	failing := New("testCacheToFailing").Source("failing", func(w io.Writer, stats *pb.InstructionStat) error {
+		return fmt.Errorf("failing source")
+	})
+	if _, err := failing.CacheTo(ctx, "test-cache-to-failing"); err == nil {
+		t.Errorf("expecting the error of the flow")
+	}
+	if IsCached("test-cache-to-failing") {
+		t.Errorf("expecting no shards kept for the failed flow")
+	}
+}
@@ -6,7 +6,8 @@ import (
 
 // CoGroup joins two datasets by the key,
 // Each result row becomes this format:
-//   (key, []left_rows, []right_rows)
+//
+//	(key, []left_rows, []right_rows)
 func (d *Dataset) CoGroup(name string, other *Dataset, sortOption *SortOption) *Dataset {
 	sorted_d := d.Partition(name, len(d.Shards), sortOption).LocalSort(name, sortOption)
 	if d == other {
@@ -19,13 +20,40 @@ func (d *Dataset) CoGroup(name string, other *Dataset, sortOption *SortOption) *
 	return t
 }
 
+// CoGroupByKey groups this dataset and the others by the key, the first field.
+// Each result row becomes this format, with an empty list for the datasets
+// without the key:
+//
+//	(key, []this_rows, []other_rows_1, ..., []other_rows_n)
+func (d *Dataset) CoGroupByKey(name string, others ...*Dataset) *Dataset {
+	sortOption := Field(1)
+	sorted_d := d.Partition(name, len(d.Shards), sortOption).LocalSort(name, sortOption)
+	inputs := []*Dataset{sorted_d}
+	for _, other := range others {
+		if other == d {
+			inputs = append(inputs, sorted_d)
+			continue
+		}
+		inputs = append(inputs, other.Partition(name, len(d.Shards), sortOption).LocalSort(name, sortOption))
+	}
+	t := coGroupPartitionedSorted(name, inputs, sortOption.Indexes())
+	t.IsLocalSorted = sortOption.orderByList
+	return t
+}
+
 // CoGroupPartitionedSorted joins 2 datasets that are sharded
 // by the same key and already locally sorted within each shard.
 func (this *Dataset) CoGroupPartitionedSorted(name string, that *Dataset, indexes []int) (ret *Dataset) {
+	return coGroupPartitionedSorted(name, []*Dataset{this, that}, indexes)
+}
+
+// coGroupPartitionedSorted groups the datasets that are sharded by the same
+// key and already locally sorted within each shard.
+func coGroupPartitionedSorted(name string, inputs []*Dataset, indexes []int) (ret *Dataset) {
+	this := inputs[0]
 	ret = this.Flow.NewNextDataset(len(this.Shards))
 	ret.IsPartitionedBy = indexes
 
-	inputs := []*Dataset{this, that}
 	step := this.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
 	step.SetInstruction(name, instruction.NewCoGroupPartitionedSorted(indexes))
 	return ret
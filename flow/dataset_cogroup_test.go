@@ -0,0 +1,45 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCoGroupByKey(t *testing.T) {
+	f := New("testCoGroupByKey")
+	orders := f.Slices([][]interface{}{{"a", 1}, {"b", 2}, {"a", 3}, {"d", 4}}).RoundRobin("orders", 2)
+	payments := f.Slices([][]interface{}{{"a", 10}, {"c", 20}})
+	refunds := f.Slices([][]interface{}{{"b", 100}, {"d", 200}, {"d", 300}})
+
+	rows, err := orders.CoGroupByKey("cogroup", payments, refunds, orders).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("cogroup: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		// the values of each key are sorted, since the shards are merged in any order
+		var groups []string
+		for _, group := range row[1:] {
+			var values []string
+			for _, value := range group.([]interface{}) {
+				values = append(values, fmt.Sprint(value))
+			}
+			sort.Strings(values)
+			groups = append(groups, fmt.Sprint(values))
+		}
+		got = append(got, fmt.Sprintf("%v %v", row[0], groups))
+	}
+	sort.Strings(got)
+	expected := []string{
+		"a [[[1] [3]] [[10]] [] [[1] [3]]]",
+		"b [[[2]] [] [[100]] [[2]]]",
+		"c [[] [[20]] [] []]",
+		"d [[[4]] [] [[200] [300]] [[4]]]",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("cogrouped %v, expected %v", got, expected)
+	}
+}
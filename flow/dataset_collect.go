@@ -0,0 +1,221 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// MaxCollectBytes limits the encoded size of the rows collected by
+// Collect() and CollectTo() into the driver memory.
+var MaxCollectBytes int64 = 256 * 1024 * 1024
+
+// Collect runs the flow, and returns the rows of the dataset, each row as its
+// keys followed by its values. The partitions are returned one after another.
+// The flow is left as it was, without the step collecting the rows.
+// It fails if the rows are more than MaxCollectBytes, so it fits small
+// results, e.g. after aggregations. If the flow runs without errors, the
+// functions added by OnSuccess() are called.
+func (d *Dataset) Collect(ctx context.Context, options ...FlowOption) ([][]interface{}, error) {
+	var partitions [][][]interface{}
+	var collectErr error
+	var size int64
+	var lock sync.Mutex
+
+	step := d.Flow.AddAllToOneStep(d, nil)
+	defer d.Flow.removeStep(step)
+	step.IsOnDriverSide = true
+	step.Name = "Collect"
+	partitions = make([][][]interface{}, len(step.Tasks[0].InputShards))
+	step.Function = func(readers []io.Reader, writers []io.Writer, stat *pb.InstructionStat) error {
+		var wg sync.WaitGroup
+		for i, reader := range readers {
+			wg.Add(1)
+			go func(i int, reader io.Reader) {
+				defer wg.Done()
+				// keep reading after failures, not to block the writers
+				err := util.TakeMessage(reader, -1, func(encodedBytes []byte) error {
+					lock.Lock()
+					defer lock.Unlock()
+					stat.InputCounter++
+					if size += int64(len(encodedBytes)); size > MaxCollectBytes {
+						if collectErr == nil {
+							collectErr = fmt.Errorf("Collected rows are more than %d bytes", MaxCollectBytes)
+						}
+						return nil
+					}
+					row, err := util.DecodeRow(encodedBytes)
+					if err != nil {
+						if collectErr == nil {
+							collectErr = fmt.Errorf("Failed to decode row: %v", err)
+						}
+						return nil
+					}
+					partitions[i] = append(partitions[i], append(row.K, row.V...))
+					return nil
+				})
+				if err != nil {
+					lock.Lock()
+					if collectErr == nil {
+						collectErr = err
+					}
+					lock.Unlock()
+				}
+			}(i, reader)
+		}
+		wg.Wait()
+		return nil
+	}
+
+	d.Flow.RunContext(ctx, options...)
+
+	if collectErr != nil {
+		return nil, collectErr
+	}
+	if err := d.Flow.runErr(ctx, options); err != nil {
+		return nil, err
+	}
+	var rows [][]interface{}
+	for _, partition := range partitions {
+		rows = append(rows, partition...)
+	}
+	return rows, nil
+}
+
+// RunErr runs the flow as RunContext() does, and returns its first failure,
+// e.g. of the Output() steps, or of the flow run by the options. If the flow
+// runs without errors, the functions added by OnSuccess() are called.
+func (fc *Flow) RunErr(ctx context.Context, options ...FlowOption) error {
+	fc.RunContext(ctx, options...)
+	return fc.runErr(ctx, options)
+}
+
+// runErr returns the failure of the flow run with the options.
+func (fc *Flow) runErr(ctx context.Context, options []FlowOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, option := range options {
+		if failed, ok := option.(interface{ Err() error }); ok && failed.Err() != nil {
+			return failed.Err()
+		}
+	}
+	return fc.succeeded()
+}
+
+// OnSuccess adds a function to call after Collect(), CacheTo(), or RunErr() runs the
+// flow without errors, e.g. to commit the offsets of the messages read by
+// the sources once the rows are written. Collect() returns its error.
+func (fc *Flow) OnSuccess(f func() error) {
+	fc.onSuccess = append(fc.onSuccess, f)
+}
+
+// succeeded returns the failure of the Output() steps, or calls the
+// functions added by OnSuccess(), in order, until one fails.
+func (fc *Flow) succeeded() error {
+	fc.failureLock.Lock()
+	failure := fc.failure
+	fc.failureLock.Unlock()
+	if failure != nil {
+		return failure
+	}
+	for _, f := range fc.onSuccess {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CollectTo runs the flow, and collects the rows of the dataset into the
+// slice pointed by slicePtr, as Collect() does. If the slice elements are
+// structs, or pointers to structs, the fields of each row are set to the
+// struct fields in order. Otherwise the first field of each row is set to
+// the element.
+func (d *Dataset) CollectTo(ctx context.Context, slicePtr interface{}, options ...FlowOption) error {
+	slice := reflect.ValueOf(slicePtr)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("CollectTo needs a pointer to a slice, not %T", slicePtr)
+	}
+	slice = slice.Elem()
+
+	rows, err := d.Collect(ctx, options...)
+	if err != nil {
+		return err
+	}
+
+	elemType := slice.Type().Elem()
+	result := reflect.MakeSlice(slice.Type(), 0, len(rows))
+	for _, row := range rows {
+		elem := reflect.New(elemType).Elem()
+		if err := setRowTo(row, elem); err != nil {
+			return err
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+	return nil
+}
+
+func setRowTo(row []interface{}, dst reflect.Value) error {
+	if dst.Kind() == reflect.Ptr && dst.Type().Elem().Kind() == reflect.Struct {
+		dst.Set(reflect.New(dst.Type().Elem()))
+		dst = dst.Elem()
+	}
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField() && i < len(row); i++ {
+			if err := setReflectValue(row[i], dst.Field(i)); err != nil {
+				return fmt.Errorf("Failed to set field %s: %v", dst.Type().Field(i).Name, err)
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Interface {
+			dst.Set(reflect.ValueOf(row))
+			return nil
+		}
+	}
+	if len(row) == 0 {
+		return nil
+	}
+	return setReflectValue(row[0], dst)
+}
+
+func setReflectValue(src interface{}, dst reflect.Value) error {
+	if !dst.CanSet() {
+		return fmt.Errorf("unexported %v", dst.Type())
+	}
+	if src == nil {
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(gio.ToString(src))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(gio.ToInt64(src))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dst.SetUint(uint64(gio.ToInt64(src)))
+	case reflect.Float32, reflect.Float64:
+		dst.SetFloat(gio.ToFloat64(src))
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return fmt.Errorf("%v is not bool", src)
+		}
+		dst.SetBool(b)
+	default:
+		v := reflect.ValueOf(src)
+		if !v.Type().ConvertibleTo(dst.Type()) {
+			return fmt.Errorf("%T is not %v", src, dst.Type())
+		}
+		dst.Set(v.Convert(dst.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestCollectLeavesFlowUnchanged(t *testing.T) {
+	f := New("testCollect")
+	ds := f.Slices([][]interface{}{{"a", 1}, {"b", 2}})
+	steps, readingSteps := len(f.Steps), len(ds.ReadingSteps)
+	outgoingChans := len(ds.Shards[0].OutgoingChans)
+
+	rows, err := ds.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(string) < rows[j][0].(string) })
+	expected := [][]interface{}{{"a", int64(1)}, {"b", int64(2)}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("collected %v, expected %v", rows, expected)
+	}
+
+	if len(f.Steps) != steps || len(ds.ReadingSteps) != readingSteps || len(ds.Shards[0].OutgoingChans) != outgoingChans {
+		t.Errorf("collect left %d steps, %d reading steps, %d outgoing chans, expected %d, %d, %d",
+			len(f.Steps), len(ds.ReadingSteps), len(ds.Shards[0].OutgoingChans), steps, readingSteps, outgoingChans)
+	}
+}
+
+func TestCollectTo(t *testing.T) {
+	type count struct {
+		Word  string
+		Count int
+	}
+	var counts []*count
+	if err := New("testCollectTo").Slices([][]interface{}{{"a", 3}}).CollectTo(context.Background(), &counts); err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || *counts[0] != (count{"a", 3}) {
+		t.Errorf("collected %+v", counts)
+	}
+
+	var words []string
+	if err := New("testCollectTo").Strings([]string{"x"}).CollectTo(context.Background(), &words); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(words, []string{"x"}) {
+		t.Errorf("collected %v", words)
+	}
+
+	if err := New("testCollectTo").Strings([]string{"x"}).CollectTo(context.Background(), words); err == nil {
+		t.Errorf("collected into a slice, not a pointer")
+	}
+}
+
+func TestCollectTooManyBytes(t *testing.T) {
+	defer func(max int64) { MaxCollectBytes = max }(MaxCollectBytes)
+	MaxCollectBytes = 10
+
+	if _, err := New("testCollectTooManyBytes").Ints([]int{1, 2, 3, 4, 5}).Collect(context.Background()); err == nil {
+		t.Errorf("collected more than %d bytes", MaxCollectBytes)
+	}
+}
+
+func TestCollectOnSuccess(t *testing.T) {
+	f := New("testOnSuccess")
+	var called int
+	f.OnSuccess(func() error {
+		called++
+		return nil
+	})
+	if _, err := f.Slices([][]interface{}{{"a", 1}}).Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if called != 1 {
+		t.Errorf("called %d times after the flow succeeded", called)
+	}
+
+	failing := New("testOnSuccessFailing")
+	failing.OnSuccess(func() error {
+		called++
+		return nil
+	})
+	ds := failing.Source("failing", func(w io.Writer, stats *pb.InstructionStat) error {
+		return fmt.Errorf("failing source")
+	})
+	if _, err := ds.Collect(context.Background()); err == nil {
+		t.Errorf("expecting the error of the flow")
+	}
+	if called != 1 {
+		t.Errorf("called after the flow failed")
+	}
+
+	f.OnSuccess(func() error { return fmt.Errorf("failing commit") })
+	if _, err := f.Slices([][]interface{}{{"b", 2}}).Collect(context.Background()); err == nil {
+		t.Errorf("expecting the error of the function")
+	}
+}
+
+func TestCollectOutputFailure(t *testing.T) {
+	f := New("testCollectOutputFailure")
+	var called int
+	f.OnSuccess(func() error {
+		called++
+		return nil
+	})
+	ds := f.Slices([][]interface{}{{"a", 1}, {"b", 2}}).OutputRow(func(row *util.Row) error {
+		if row.K[0] == "b" {
+			return fmt.Errorf("failing output")
+		}
+		return nil
+	})
+	if _, err := ds.Collect(context.Background()); err == nil || err.Error() != "failing output" {
+		t.Errorf("expecting the error of the output, got %v", err)
+	}
+	if called != 0 {
+		t.Errorf("called after the output failed")
+	}
+}
@@ -0,0 +1,174 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
+)
+
+// Source is a data source read in shards by the executors, e.g. the
+// splits of files or the partitions of a message queue.
+//
+// The source is gob encoded from the driver to the executors, so
+// register its type with gob.Register() in an init function.
+type Source interface {
+	// ShardCount returns the number of shards, on the driver.
+	ShardCount() (int, error)
+	// OpenShard starts reading a shard, on an executor.
+	OpenShard(shardIndex int) (RowIterator, error)
+}
+
+// RowIterator reads the rows of a source shard.
+type RowIterator interface {
+	// Next returns the next row, or io.EOF after the last row.
+	Next() (*util.Row, error)
+	Close() error
+}
+
+// Sink is a data sink written by the tasks of a dataset on the executors.
+//
+// The sink is gob encoded from the driver to the executors, so
+// register its type with gob.Register() in an init function.
+type Sink interface {
+	// OpenTask starts writing the rows of a task, on an executor.
+	OpenTask(taskId int) (TaskWriter, error)
+}
+
+// TaskWriter writes the rows of a task to a sink.
+type TaskWriter interface {
+	Write(row *util.Row) error
+	// CommitTask completes the output of the task after its last row.
+	CommitTask() error
+	// AbortTask discards the output of the task if it failed.
+	AbortTask() error
+}
+
+// TaskOutput is implemented by the task writers with rows to emit after
+// committing, e.g. the files written by the task, which the next steps of the
+// dataset returned by WriteSink() read.
+type TaskOutput interface {
+	CommittedRows() []*util.Row
+}
+
+// sinkEnv passes the encoded sink to the writing mapper.
+const sinkEnv = "GLEAM_SINK"
+
+var (
+	registeredMapperReadSourceShard = gio.RegisterMapper(readSourceShard)
+	registeredMapperWriteSinkRow    = gio.RegisterMapperWithCloser(writeSinkRow, closeSinkTask)
+)
+
+// ReadSource reads the shards of the source, in partitionCount partitions.
+func (fc *Flow) ReadSource(name string, s Source, partitionCount int) *Dataset {
+	encodedSource := encodeConnector(&s)
+	return fc.Source(name, func(writer io.Writer, stats *pb.InstructionStat) error {
+		stats.InputCounter++
+		shardCount, err := s.ShardCount()
+		if err != nil {
+			return fmt.Errorf("Failed to list shards of %s: %v", name, err)
+		}
+		for i := 0; i < shardCount; i++ {
+			stats.OutputCounter++
+			if err := util.NewRow(util.Now(), encodedSource, i).WriteTo(writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).RoundRobin(name, partitionCount).Map(name+".Read", registeredMapperReadSourceShard)
+}
+
+// WriteSink writes the rows of the dataset to the sink. Each task commits
+// its output after its last row, or aborts it if the task fails. The returned
+// dataset has the rows of the committed tasks implementing TaskOutput.
+func (d *Dataset) WriteSink(name string, s Sink) *Dataset {
+	ret := d.Map(name+".Write", registeredMapperWriteSinkRow)
+	ret.Step.Command.Env = append(ret.Step.Command.Env, sinkEnv+"="+base64.StdEncoding.EncodeToString(encodeConnector(&s)))
+	return ret
+}
+
+func encodeConnector(connector interface{}) []byte {
+	var network bytes.Buffer
+	enc := gob.NewEncoder(&network)
+	if err := enc.Encode(connector); err != nil {
+		logger.Fatalf("encode %T: %v", connector, err)
+	}
+	return network.Bytes()
+}
+
+func readSourceShard(row []interface{}) error {
+	var s Source
+	if err := gob.NewDecoder(bytes.NewBuffer(row[0].([]byte))).Decode(&s); err != nil {
+		return fmt.Errorf("Failed to decode source: %v", err)
+	}
+	shardIndex := int(gio.ToInt64(row[1]))
+
+	iterator, err := s.OpenShard(shardIndex)
+	if err != nil {
+		return fmt.Errorf("Failed to open shard %d: %v", shardIndex, err)
+	}
+	defer iterator.Close()
+
+	for {
+		r, err := iterator.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read shard %d: %v", shardIndex, err)
+		}
+		if err := gio.TsEmitKV(r.T, r.K, r.V); err != nil {
+			return err
+		}
+	}
+}
+
+// sinkTask is the task writer of the writing mapper.
+var sinkTask TaskWriter
+
+func writeSinkRow(row []interface{}) error {
+	if sinkTask == nil {
+		data, err := base64.StdEncoding.DecodeString(os.Getenv(sinkEnv))
+		if err != nil {
+			return fmt.Errorf("Failed to decode sink: %v", err)
+		}
+		var s Sink
+		if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&s); err != nil {
+			return fmt.Errorf("Failed to decode sink: %v", err)
+		}
+		if sinkTask, err = s.OpenTask(gio.TaskId()); err != nil {
+			return fmt.Errorf("Failed to open sink task %d: %v", gio.TaskId(), err)
+		}
+	}
+	return sinkTask.Write(util.NewRow(util.Now(), row...))
+}
+
+func closeSinkTask(processErr error) error {
+	if sinkTask == nil {
+		return processErr
+	}
+	if processErr != nil {
+		if err := sinkTask.AbortTask(); err != nil {
+			return fmt.Errorf("%v, and failed to abort sink task %d: %v", processErr, gio.TaskId(), err)
+		}
+		return processErr
+	}
+	if err := sinkTask.CommitTask(); err != nil {
+		return err
+	}
+	if output, ok := sinkTask.(TaskOutput); ok {
+		for _, r := range output.CommittedRows() {
+			if err := gio.TsEmitKV(r.T, r.K, r.V); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+type testTaskWriter struct {
+	abortErr             error
+	committed, isAborted bool
+}
+
+func (w *testTaskWriter) Write(row *util.Row) error { return nil }
+func (w *testTaskWriter) CommitTask() error {
+	w.committed = true
+	return nil
+}
+func (w *testTaskWriter) AbortTask() error {
+	w.isAborted = true
+	return w.abortErr
+}
+
+func TestCloseSinkTask(t *testing.T) {
+	defer func() { sinkTask = nil }()
+	processErr := errors.New("failed row")
+
+	w := &testTaskWriter{}
+	sinkTask = w
+	if err := closeSinkTask(processErr); err != processErr || !w.isAborted || w.committed {
+		t.Errorf("closing a failed task: %v, aborted %v", err, w.isAborted)
+	}
+
+	w = &testTaskWriter{abortErr: errors.New("abort failed")}
+	sinkTask = w
+	if err := closeSinkTask(processErr); err == nil || err == processErr {
+		t.Errorf("closing a failed task without aborting it: %v", err)
+	}
+
+	w = &testTaskWriter{}
+	sinkTask = w
+	if err := closeSinkTask(nil); err != nil || !w.committed || w.isAborted {
+		t.Errorf("closing a task: %v, committed %v", err, w.committed)
+	}
+
+	sinkTask = nil
+	if err := closeSinkTask(processErr); err != processErr {
+		t.Errorf("closing a failed task before its first row: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package flow
+
+import (
+	"github.com/lovelly/gleam/instruction"
+)
+
+// LocalExists keeps the rows of each shard of this dataset that have rows with
+// the same keys in the same shard of that dataset, or with isAnti, the rows
+// that have none. With withMark, all rows are kept, with 1 or 0 appended for
+// whether they are kept. Each shard of that dataset is only read until all
+// keys of the shard of this dataset are found.
+func (this *Dataset) LocalExists(name string, that *Dataset, thisKeys, thatKeys *SortOption, isAnti, withMark bool) *Dataset {
+	ret := this.Flow.NewNextDataset(len(this.Shards))
+	ret.IsPartitionedBy = this.IsPartitionedBy
+	ret.IsLocalSorted = this.IsLocalSorted
+	inputs := []*Dataset{this, that}
+	step := this.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
+	step.SetInstruction(name, instruction.NewLocalExists(thisKeys.Indexes(), thatKeys.Indexes(), isAnti, withMark, false))
+	return ret
+}
+
+// LocalNotIn is LocalExists with isAnti, by the NULL semantics of NOT IN. If
+// that dataset has rows, the rows of this dataset with NULL keys are dropped,
+// and if any row of that dataset has NULL keys, so are the rows without
+// matching rows. With withMark, these rows are kept with NULL appended. The
+// rows of that dataset are counted by every shard, and the counts are sent to
+// all shards of this dataset.
+func (this *Dataset) LocalNotIn(name string, that *Dataset, thisKeys, thatKeys *SortOption, withMark bool) *Dataset {
+	counts, step := add1ShardTo1Step(that)
+	step.SetInstruction(name+".counts", instruction.NewCountNullKeys(thatKeys.Indexes()))
+	counts = counts.Broadcast(name+".counts", len(this.Shards))
+
+	ret := this.Flow.NewNextDataset(len(this.Shards))
+	ret.IsPartitionedBy = this.IsPartitionedBy
+	ret.IsLocalSorted = this.IsLocalSorted
+	inputs := []*Dataset{this, that, counts}
+	step = this.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
+	step.SetInstruction(name, instruction.NewLocalExists(thisKeys.Indexes(), thatKeys.Indexes(), true, withMark, true))
+	return ret
+}
@@ -0,0 +1,92 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+var (
+	isEven = gio.RegisterPredicate(func(row []interface{}) (bool, error) {
+		return gio.ToInt64(row[0])%2 == 0, nil
+	})
+	isSmall = gio.RegisterPredicate(func(row []interface{}) (bool, error) {
+		if gio.ToInt64(row[0]) > 3 {
+			return false, fmt.Errorf("row %v is too large", row)
+		}
+		return true, nil
+	})
+)
+
+func TestFilter(t *testing.T) {
+	rows, err := New("testFilter").Ints([]int{1, 2, 3, 4}).Filter("even", isEven).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if got := fmt.Sprint(rows); got != "[[2] [4]]" {
+		t.Errorf("filtered %s, expected [[2] [4]]", got)
+	}
+
+	_, err = New("testFilterFailing").Ints([]int{1, 2, 3, 4}).Filter("small", isSmall).Collect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "row [4] is too large") {
+		t.Errorf("filtered with %v, expected the error of the predicate", err)
+	}
+}
+
+// filterByCommand filters the ints by the predicate run as a command, as by
+// the agents, and returns the ints kept.
+func filterByCommand(t *testing.T, predicateId gio.MapperId, n int) ([]int64, *pb.InstructionStat, error) {
+	var input bytes.Buffer
+	for i := 1; i <= n; i++ {
+		if err := util.NewRow(util.Now(), i).WriteTo(&input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	stats := &pb.InstructionStat{}
+	err := instruction.DoFilterByCommand(&input, &out, mapperCommand(predicateId, ""), stats)
+	var kept []int64
+	for {
+		row, readErr := util.ReadRow(&out)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			t.Fatalf("read the rows kept: %v", readErr)
+		}
+		kept = append(kept, gio.ToInt64(row.K[0]))
+	}
+	return kept, stats, err
+}
+
+func TestFilterByCommand(t *testing.T) {
+	// many more rows than waiting for their verdicts, or buffered
+	kept, stats, err := filterByCommand(t, isEven, 100000)
+	if err != nil {
+		t.Fatalf("filter: %v", err)
+	}
+	if len(kept) != 50000 || kept[0] != 2 || kept[len(kept)-1] != 100000 {
+		t.Errorf("kept %d rows, from %v", len(kept), kept[:3])
+	}
+	if stats.InputCounter != 100000 || stats.OutputCounter != 50000 || stats.FilteredCounter != 50000 {
+		t.Errorf("counted %d in, %d out and %d filtered", stats.InputCounter, stats.OutputCounter, stats.FilteredCounter)
+	}
+
+	// the predicate fails at the 4th row
+	kept, _, err = filterByCommand(t, isSmall, 10)
+	if err == nil {
+		t.Errorf("filtered without the error of the predicate")
+	}
+	if !reflect.DeepEqual(kept, []int64{1, 2, 3}) {
+		t.Errorf("kept %v before the predicate failed", kept)
+	}
+}
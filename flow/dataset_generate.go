@@ -0,0 +1,103 @@
+package flow
+
+import (
+	"io"
+	"math/rand"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// Generator generates one field of the row at the index. The random source
+// is seeded by the partition, so the same flow generates the same rows.
+type Generator func(index int64, r *rand.Rand) interface{}
+
+// Generate begins a flow with n rows generated on the driver, in
+// partitionCount partitions. Each generator produces one field of the rows,
+// in the order of the generators. This is handy for benchmarks and tests.
+// For large data sets, read a source generated on the executors instead,
+// e.g. the plugins/tpch tables.
+func (fc *Flow) Generate(name string, n int64, partitionCount int, generators ...Generator) (ret *Dataset) {
+	if partitionCount < 1 {
+		partitionCount = 1
+	}
+	ret = fc.NewNextDataset(partitionCount)
+	step := fc.AddOneToAllStep(nil, ret)
+	step.IsOnDriverSide = true
+	step.Name = name
+	step.Function = func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		errChan := make(chan error, len(writers))
+		for i, writer := range writers {
+			start, stop := n*int64(i)/int64(len(writers)), n*int64(i+1)/int64(len(writers))
+			go func(partition int, writer io.Writer) {
+				errChan <- generateRows(start, stop, rand.New(rand.NewSource(int64(partition))), generators, writer)
+			}(i, writer)
+		}
+		for range writers {
+			if err := <-errChan; err != nil {
+				return err
+			}
+		}
+		stats.OutputCounter += n
+		return nil
+	}
+	return
+}
+
+func generateRows(start, stop int64, r *rand.Rand, generators []Generator, writer io.Writer) error {
+	values := make([]interface{}, len(generators))
+	for index := start; index < stop; index++ {
+		for i, g := range generators {
+			values[i] = g(index, r)
+		}
+		if err := util.NewRow(util.Now(), values...).WriteTo(writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sequence generates the row index.
+func Sequence() Generator {
+	return func(index int64, r *rand.Rand) interface{} {
+		return index
+	}
+}
+
+// RandomInt generates an integer in [min, max), or min if max is not
+// larger than min.
+func RandomInt(min, max int64) Generator {
+	if max <= min {
+		return func(index int64, r *rand.Rand) interface{} {
+			return min
+		}
+	}
+	return func(index int64, r *rand.Rand) interface{} {
+		return min + r.Int63n(max-min)
+	}
+}
+
+// RandomFloat generates a float in [min, max).
+func RandomFloat(min, max float64) Generator {
+	return func(index int64, r *rand.Rand) interface{} {
+		return min + r.Float64()*(max-min)
+	}
+}
+
+// RandomString generates a string of lower case letters.
+func RandomString(length int) Generator {
+	return func(index int64, r *rand.Rand) interface{} {
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = byte('a' + r.Intn(26))
+		}
+		return string(b)
+	}
+}
+
+// OneOf generates one of the values.
+func OneOf(values ...interface{}) Generator {
+	return func(index int64, r *rand.Rand) interface{} {
+		return values[r.Intn(len(values))]
+	}
+}
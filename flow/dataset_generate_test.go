@@ -0,0 +1,38 @@
+package flow
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomInt(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, test := range []struct{ min, max int64 }{{3, 3}, {5, 2}, {-2, 3}} {
+		g := RandomInt(test.min, test.max)
+		for i := int64(0); i < 100; i++ {
+			v := g(i, r).(int64)
+			if test.max <= test.min && v != test.min || test.max > test.min && (v < test.min || v >= test.max) {
+				t.Fatalf("RandomInt(%d, %d) generated %d", test.min, test.max, v)
+			}
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rows, err := New("testGenerate").Generate("numbers", 10, 3, Sequence(), RandomInt(7, 7)).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int64]bool)
+	for _, row := range rows {
+		index, value := row[0].(int64), row[1].(int64)
+		if seen[index] || value != 7 {
+			t.Errorf("generated row %v", row)
+		}
+		seen[index] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("generated %d rows, expected 10", len(seen))
+	}
+}
@@ -15,6 +15,7 @@ func (d *Dataset) Hint(options ...DasetsetHint) *Dataset {
 func TotalSize(n int64) DasetsetHint {
 	return func(d *Dataset) {
 		d.Meta.TotalSize = n
+		d.Meta.IsSizeKnown = true
 	}
 }
 
@@ -23,6 +24,66 @@ func TotalSize(n int64) DasetsetHint {
 func PartitionSize(n int64) DasetsetHint {
 	return func(d *Dataset) {
 		d.Meta.TotalSize = n * int64(len(d.GetShards()))
+		d.Meta.IsSizeKnown = true
+	}
+}
+
+// FieldCount hints the number of fields of the rows, keys included.
+// Outer joins need it to add the nils of a side without rows in a partition.
+func FieldCount(n int) DasetsetHint {
+	return func(d *Dataset) {
+		d.Meta.FieldCount = n
+	}
+}
+
+// Secret sets the environment variable envName to the named secret, when the
+// step producing this dataset runs on an executor. The secret is looked up by
+// the agent's secrets provider, so it is not shipped with the flow.
+// Pipe() commands and gio mappers and reducers can read it via os.Getenv().
+func Secret(envName, secretName string) DasetsetHint {
+	return func(d *Dataset) {
+		if d.Step.Secrets == nil {
+			d.Step.Secrets = make(map[string]string)
+		}
+		d.Step.Secrets[envName] = secretName
+	}
+}
+
+// NodeSelector runs the step producing this dataset only on agents with all
+// the labels, e.g. "ssd=true", as set by the agent's --labels option.
+func NodeSelector(labels ...string) DasetsetHint {
+	return func(d *Dataset) {
+		d.Step.NodeSelector = append(d.Step.NodeSelector, labels...)
+	}
+}
+
+// Tolerate allows the step producing this dataset to run on agents with the
+// taints, as set by the agent's --taints option. Tolerating "key" accepts all
+// taints "key=value". Steps do not run on agents with taints they do not
+// tolerate.
+func Tolerate(taints ...string) DasetsetHint {
+	return func(d *Dataset) {
+		d.Step.Tolerations = append(d.Step.Tolerations, taints...)
+	}
+}
+
+// MaxMemoryMB caps the memory requested for each task of the step producing
+// this dataset, whether estimated by the instruction or configured by the
+// step name. 0 removes the cap.
+func MaxMemoryMB(n int) DasetsetHint {
+	return func(d *Dataset) {
+		d.Step.MaxMemoryMB = n
+	}
+}
+
+// Compression sets how the agents compress the shards of this dataset if
+// they are kept on disk, e.g. by OnDisk() or Persist(): "none", "lz4",
+// "zstd", "zstd:<level>" with levels 1 to 22, or "auto" to compress with
+// zstd only while the rows shrink. It trades the CPU of the agents for
+// their disk space, and is ignored for datasets kept in memory.
+func Compression(compression string) DasetsetHint {
+	return func(d *Dataset) {
+		d.Meta.Compression = compression
 	}
 }
 
@@ -1,6 +1,8 @@
 package flow
 
 import (
+	"fmt"
+
 	"github.com/lovelly/gleam/instruction"
 )
 
@@ -31,18 +33,37 @@ func (d *Dataset) RightOuterJoinByKey(name string, other *Dataset) *Dataset {
 	return d.DoJoin(name, other, false, true, Field(1))
 }
 
+// FullOuterJoin joins two datasets by the key, also keeping the rows of
+// both datasets without matching rows, with nils for the other side.
+// Both datasets need the FieldCount() hint, so the rows have the nils of a
+// side without rows in their partition, and the flow fails without it.
+func (d *Dataset) FullOuterJoin(name string, other *Dataset, sortOption *SortOption) *Dataset {
+	if d.Meta.FieldCount == 0 || other.Meta.FieldCount == 0 {
+		return d.Flow.FailedSource(name, fmt.Errorf("%s: full outer join needs the FieldCount() hints of both datasets", name))
+	}
+	return d.DoJoin(name, other, true, true, sortOption)
+}
+
+func (d *Dataset) FullOuterJoinByKey(name string, other *Dataset) *Dataset {
+	return d.FullOuterJoin(name, other, Field(1))
+}
+
 func (d *Dataset) DoJoin(name string, other *Dataset, leftOuter, rightOuter bool, sortOption *SortOption) *Dataset {
 	sorted_d := d.Partition(name+".left", len(d.Shards), sortOption).LocalSort(name+".left", sortOption)
+	sorted_d.Meta.FieldCount = d.Meta.FieldCount
 	var sorted_other *Dataset
 	if d == other {
 		sorted_other = sorted_d
 	} else {
 		sorted_other = other.Partition(name+".right", len(d.Shards), sortOption).LocalSort(name+".right", sortOption)
+		sorted_other.Meta.FieldCount = other.Meta.FieldCount
 	}
 	return sorted_d.JoinPartitionedSorted(name, sorted_other, sortOption, leftOuter, rightOuter)
 }
 
-// JoinPartitionedSorted Join multiple datasets that are sharded by the same key, and locally sorted within the shard
+// JoinPartitionedSorted Join multiple datasets that are sharded by the same key, and locally sorted within the shard.
+// The outer joins add nils for the values of the other side, as many as hinted by FieldCount(), or as the values
+// of its rows. Without the hint, a partition without rows on the other side gets no nils.
 func (this *Dataset) JoinPartitionedSorted(name string, that *Dataset, sortOption *SortOption,
 	isLeftOuterJoin, isRightOuterJoin bool) *Dataset {
 	ret := this.Flow.NewNextDataset(len(this.Shards))
@@ -51,6 +72,16 @@ func (this *Dataset) JoinPartitionedSorted(name string, that *Dataset, sortOptio
 
 	inputs := []*Dataset{this, that}
 	step := this.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
-	step.SetInstruction(name, instruction.NewJoinPartitionedSorted(isLeftOuterJoin, isRightOuterJoin, sortOption.Indexes()))
+	step.SetInstruction(name, instruction.NewJoinPartitionedSorted(isLeftOuterJoin, isRightOuterJoin, sortOption.Indexes(),
+		valueCount(this, sortOption), valueCount(that, sortOption)))
 	return ret
 }
+
+// valueCount is the number of fields of the rows besides the key fields,
+// or 0 if not hinted.
+func valueCount(d *Dataset, sortOption *SortOption) int {
+	if d.Meta.FieldCount <= len(sortOption.Indexes()) {
+		return 0
+	}
+	return d.Meta.FieldCount - len(sortOption.Indexes())
+}
@@ -10,6 +10,14 @@ func (bigger *Dataset) HashJoin(name string, smaller *Dataset, sortOption *SortO
 	return smaller.Broadcast(name, len(bigger.Shards)).LocalHashAndJoinWith(name, bigger, sortOption)
 }
 
+// BroadcastJoin joins the small dataset by the key, the first field, without
+// shuffling this dataset. The small dataset is read once, and sent to all
+// shards of this dataset, which are joined with it in memory.
+// Each result row is (key, this_values, small_values), like JoinByKey.
+func (d *Dataset) BroadcastJoin(name string, small *Dataset) *Dataset {
+	return d.HashJoin(name, small, Field(1))
+}
+
 func (this *Dataset) LocalHashAndJoinWith(name string, that *Dataset, sortOption *SortOption) *Dataset {
 	ret := this.Flow.NewNextDataset(len(that.Shards))
 	ret.IsPartitionedBy = that.IsPartitionedBy
@@ -20,11 +28,15 @@ func (this *Dataset) LocalHashAndJoinWith(name string, that *Dataset, sortOption
 	return ret
 }
 
-// Broadcast replicates itself to all shards.
+// Broadcast replicates itself to all shards. The shards are merged first.
 func (d *Dataset) Broadcast(name string, shardCount int) *Dataset {
 	if shardCount == 1 && len(d.Shards) == shardCount {
 		return d
 	}
+	d = d.MergeTo(name, 1)
+	if shardCount == 1 {
+		return d
+	}
 	ret := d.Flow.NewNextDataset(shardCount)
 	step := d.Flow.AddOneToAllStep(d, ret)
 	step.SetInstruction(name, instruction.NewBroadcast())
@@ -0,0 +1,45 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/instruction"
+)
+
+func TestBroadcastJoin(t *testing.T) {
+	f := New("testBroadcastJoin")
+	orders := f.Slices([][]interface{}{
+		{"a", 1}, {"b", 2}, {"a", 3}, {"c", 4}, {"d", 5}, {"b", 6},
+	}).RoundRobin("orders", 3)
+	customers := f.Slices([][]interface{}{
+		{"a", "alice"}, {"b", "bob"}, {"e", "eve"},
+	}).RoundRobin("customers", 2)
+
+	joined := orders.BroadcastJoin("join", customers)
+	if len(joined.Shards) != len(orders.Shards) {
+		t.Errorf("joined into %d shards, expected the %d shards of the large dataset", len(joined.Shards), len(orders.Shards))
+	}
+	for _, step := range f.Steps {
+		switch step.Instruction.(type) {
+		case *instruction.ScatterPartitions, *instruction.LocalSort:
+			t.Errorf("step %s partitions or sorts the datasets", step.Name)
+		}
+	}
+	rows, err := joined.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("broadcast join: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v", row))
+	}
+	sort.Strings(got)
+	expected := []string{"[a 1 alice]", "[a 3 alice]", "[b 2 bob]", "[b 6 bob]"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("joined %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,115 @@
+package flow
+
+import (
+	"github.com/lovelly/gleam/instruction"
+)
+
+// JoinOption configures how JoinWithOption() and LeftOuterJoinWithOption()
+// join the datasets.
+type JoinOption struct {
+	saltCount  int
+	sampleSize int
+	hotRatio   float64
+}
+
+// SkewJoin spreads the rows of each hot key of the left dataset across
+// saltCount partitions, instead of one, and replicates the rows of the
+// right dataset with the hot keys to all of them. The hot keys are the keys
+// of at least 100 of the first 10000 rows of any shard of the left dataset,
+// see SampleSize() and HotKeyRatio(). A key of less than 10 rows is not hot.
+func SkewJoin(saltCount int) *JoinOption {
+	return &JoinOption{
+		saltCount:  saltCount,
+		sampleSize: 10000,
+		hotRatio:   0.01,
+	}
+}
+
+// SampleSize sets the number of the first rows of each shard sampled for
+// the hot keys. The sampled rows are kept in memory.
+func (o *JoinOption) SampleSize(n int) *JoinOption {
+	o.sampleSize = n
+	return o
+}
+
+// HotKeyRatio sets the share of the sample size making a key hot.
+func (o *JoinOption) HotKeyRatio(ratio float64) *JoinOption {
+	o.hotRatio = ratio
+	return o
+}
+
+// JoinWithOption joins two datasets by the key like Join(), as configured
+// by the option, e.g. SkewJoin(8) when a few keys have most of the rows.
+func (d *Dataset) JoinWithOption(name string, other *Dataset, sortOption *SortOption, option *JoinOption) *Dataset {
+	return d.doJoinWithOption(name, other, false, sortOption, option)
+}
+
+// LeftOuterJoinWithOption joins two datasets by the key like LeftOuterJoin(),
+// as configured by the option.
+func (d *Dataset) LeftOuterJoinWithOption(name string, other *Dataset, sortOption *SortOption, option *JoinOption) *Dataset {
+	return d.doJoinWithOption(name, other, true, sortOption, option)
+}
+
+// doJoinWithOption salts the keys of both datasets, which are joined by the
+// keys and the salt, and removes the salt. The salts are only decided on
+// the left dataset, so a right dataset whose rows would be replicated to
+// salts without matching rows can not be outer joined. A dataset is not
+// salted to join itself.
+func (d *Dataset) doJoinWithOption(name string, other *Dataset, leftOuter bool, sortOption *SortOption, option *JoinOption) *Dataset {
+	if option == nil || option.saltCount <= 1 || d == other {
+		return d.DoJoin(name, other, leftOuter, false, sortOption)
+	}
+	indexes := sortOption.Indexes()
+	keyCount := len(indexes)
+
+	salted := d.saltHotKeys(name+".salt", indexes, option)
+	hotKeys := salted.filterSalted(name+".hotKeys", keyCount, true).Broadcast(name+".hotKeys", len(other.Shards))
+	left := salted.filterSalted(name+".left", keyCount, false)
+	right := hotKeys.replicateHotKeys(name+".replicate", other, indexes, option.saltCount)
+	if d.Meta.FieldCount > 0 {
+		left.Meta.FieldCount = d.Meta.FieldCount + 1
+	}
+	if other.Meta.FieldCount > 0 {
+		right.Meta.FieldCount = other.Meta.FieldCount + 1
+	}
+
+	saltedKeys := Field(sequence(1, keyCount+1)...)
+	ret := left.DoJoin(name, right, leftOuter, false, saltedKeys).unsalt(name+".unsalt", keyCount)
+	ret.IsLocalSorted = Field(sequence(1, keyCount)...).orderByList
+	return ret
+}
+
+func (d *Dataset) saltHotKeys(name string, indexes []int, option *JoinOption) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(name, instruction.NewSaltHotKeys(indexes, option.saltCount, option.sampleSize, option.hotRatio))
+	return ret
+}
+
+func (d *Dataset) filterSalted(name string, keyCount int, hotKeys bool) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(name, instruction.NewFilterSalted(keyCount, hotKeys))
+	return ret
+}
+
+// replicateHotKeys salts the other dataset, with the hot keys broadcast to
+// all its shards.
+func (hotKeys *Dataset) replicateHotKeys(name string, other *Dataset, indexes []int, saltCount int) *Dataset {
+	ret := other.Flow.NewNextDataset(len(other.Shards))
+	step := other.Flow.MergeDatasets1ShardTo1Step([]*Dataset{hotKeys, other}, ret)
+	step.SetInstruction(name, instruction.NewReplicateHotKeys(indexes, saltCount))
+	return ret
+}
+
+func (d *Dataset) unsalt(name string, keyCount int) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(name, instruction.NewUnsalt(keyCount))
+	return ret
+}
+
+// sequence returns count consecutive numbers starting from start.
+func sequence(start, count int) (ret []int) {
+	for i := 0; i < count; i++ {
+		ret = append(ret, start+i)
+	}
+	return
+}
@@ -0,0 +1,49 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSkewJoin(t *testing.T) {
+	var leftRows [][]interface{}
+	var expected []string
+	for i := 0; i < 30; i++ {
+		leftRows = append(leftRows, []interface{}{"hot", i})
+		expected = append(expected, fmt.Sprintf("hot %d h", i))
+	}
+	leftRows = append(leftRows, []interface{}{"cold", 30}, []interface{}{"lonely", 31})
+	expected = append(expected, "cold 30 c")
+
+	tests := []struct {
+		name      string
+		leftOuter bool
+		expected  []string
+	}{
+		{"inner", false, expected},
+		{"left outer", true, append([]string{"lonely 31 <nil>"}, expected...)},
+	}
+	for _, test := range tests {
+		f := New("testSkewJoin")
+		left := f.Slices(leftRows).RoundRobin("left", 2)
+		right := f.Slices([][]interface{}{{"hot", "h"}, {"cold", "c"}, {"unmatched", "u"}})
+		// each shard of the left dataset samples 15 rows of the hot key
+		option := SkewJoin(4).SampleSize(20).HotKeyRatio(0.1)
+		rows, err := left.doJoinWithOption("join", right, test.leftOuter, Field(1), option).Collect(context.Background())
+		if err != nil {
+			t.Fatalf("%s skew join: %v", test.name, err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprintf("%v %v %v", row...))
+		}
+		sort.Strings(got)
+		sort.Strings(test.expected)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s skew join: %v, expected %v", test.name, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOuterJoins(t *testing.T) {
+	tests := []struct {
+		name     string
+		join     func(l, r *Dataset) *Dataset
+		expected []string
+	}{
+		{"inner", func(l, r *Dataset) *Dataset { return l.JoinByKey("join", r) },
+			[]string{"a 1 x"}},
+		{"left outer", func(l, r *Dataset) *Dataset { return l.LeftOuterJoinByKey("join", r) },
+			[]string{"a 1 x", "b 2 <nil>", "c 3 <nil>"}},
+		{"right outer", func(l, r *Dataset) *Dataset { return l.RightOuterJoinByKey("join", r) },
+			[]string{"a 1 x", "d <nil> y"}},
+		{"full outer", func(l, r *Dataset) *Dataset { return l.FullOuterJoinByKey("join", r) },
+			[]string{"a 1 x", "b 2 <nil>", "c 3 <nil>", "d <nil> y"}},
+	}
+	for _, test := range tests {
+		// most partitions have the rows of one side only
+		f := New("testOuterJoins")
+		left := f.Slices([][]interface{}{{"a", 1}, {"b", 2}, {"c", 3}}).RoundRobin("left", 5).Hint(FieldCount(2))
+		right := f.Slices([][]interface{}{{"a", "x"}, {"d", "y"}}).Hint(FieldCount(2))
+		rows, err := test.join(left, right).Collect(context.Background())
+		if err != nil {
+			t.Fatalf("%s join: %v", test.name, err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprintf("%v %v %v", row...))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s join: %v, expected %v", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestFullOuterJoinNeedsFieldCount(t *testing.T) {
+	f := New("testFullOuterJoinNeedsFieldCount")
+	left := f.Slices([][]interface{}{{"a", 1}}).Hint(FieldCount(2))
+	right := f.Slices([][]interface{}{{"b", 2}})
+	if _, err := left.FullOuterJoinByKey("join", right).Collect(context.Background()); err == nil {
+		t.Errorf("expected the full outer join without the field count of a side to fail")
+	}
+}
@@ -2,6 +2,7 @@ package flow
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/lovelly/gleam/gio"
@@ -12,23 +13,63 @@ import (
 // Mapper runs the mapper registered to the mapperId.
 // This is used to execute pure Go code.
 func (d *Dataset) Map(name string, mapperId gio.MapperId) *Dataset {
+	return d.MapWithArg(name, mapperId, "")
+}
+
+// MapWithArg runs the mapper registered to the mapperId, which gets the arg
+// by gio.MapperArg(), e.g. the parameters of a mapper shared by many steps.
+func (d *Dataset) MapWithArg(name string, mapperId gio.MapperId, arg string) *Dataset {
 	ret, step := add1ShardTo1Step(d)
 	step.Name = name + ".Map"
 	step.IsPipe = false
 	step.IsGoCode = true
 
-	ex, _ := os.Executable()
-
 	mapper, _ := gio.GetMapper(mapperId)
 	step.Description = mapper.Name
+	step.Command = mapperCommand(mapperId, arg)
+	return ret
+}
+
+// mapperCommand runs this binary as the mapper registered to the mapperId.
+// The arg is given in a file by -gleam.mapperArgFile, which the runners add.
+func mapperCommand(mapperId gio.MapperId, arg string) *script.Command {
+	ex, _ := os.Executable()
 
 	var args []string
 	args = append(args, os.Args[1:]...)
 	args = append(args, "-gleam.mapper", string(mapperId))
-	step.Command = &script.Command{
+	return &script.Command{
 		Path: ex,
 		Args: args,
+		Arg:  arg,
+	}
+}
+
+// MapPartitions runs the partition mapper registered to the mapperId by
+// gio.RegisterPartitionMapper(), once for each partition, with all its rows.
+func (d *Dataset) MapPartitions(name string, mapperId gio.MapperId) *Dataset {
+	if mapper, found := gio.GetMapper(mapperId); found && mapper.PartitionMapper == nil {
+		log.Panicf("MapPartitions %s needs a mapper registered by gio.RegisterPartitionMapper(), not %s", name, mapper.Name)
+	}
+	ret := d.Map(name, mapperId)
+	ret.Step.Name = name + ".MapPartitions"
+	return ret
+}
+
+// Filter keeps the rows accepted by the predicate registered to the
+// predicateId by gio.RegisterPredicate(). The dropped rows are counted as
+// filtered in the statistics of the step.
+func (d *Dataset) Filter(name string, predicateId gio.MapperId) *Dataset {
+	mapper, found := gio.GetMapper(predicateId)
+	if found && mapper.Predicate == nil {
+		log.Panicf("Filter %s needs a predicate registered by gio.RegisterPredicate(), not %s", name, mapper.Name)
 	}
+	ret, step := add1ShardTo1Step(d)
+	ret.IsLocalSorted = d.IsLocalSorted
+	ret.IsPartitionedBy = d.IsPartitionedBy
+	step.SetInstruction(name, instruction.NewFilter(string(predicateId), mapper.Predicate, mapperCommand(predicateId, "")))
+	step.IsGoCode = true
+	step.Description = mapper.Name
 	return ret
 }
 
@@ -55,6 +96,19 @@ func (d *Dataset) SelectKV(name string, keys, values *SortOption) *Dataset {
 	return ret
 }
 
+// Convert converts the fields, in order, to the types instruction.ConvertInt,
+// ConvertFloat, ConvertString, ConvertDecimal, ConvertDate or ConvertDatetime,
+// e.g. the strings read from csv files to numbers. The fields of empty types
+// are not changed, and the step fails on the values not convertible.
+func (d *Dataset) Convert(name string, types ...string) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	ret.IsLocalSorted = d.IsLocalSorted
+	ret.IsPartitionedBy = d.IsPartitionedBy
+	step.SetInstruction(name, instruction.NewConvert(types))
+	step.Description = fmt.Sprintf("convert %v", types)
+	return ret
+}
+
 // LocalLimit take the local first n rows and skip all other rows.
 func (d *Dataset) LocalLimit(name string, n int, offset int) *Dataset {
 	ret, step := add1ShardTo1Step(d)
@@ -62,5 +116,7 @@ func (d *Dataset) LocalLimit(name string, n int, offset int) *Dataset {
 	ret.IsPartitionedBy = d.IsPartitionedBy
 	step.SetInstruction(name, instruction.NewLocalLimit(n, offset))
 	step.Description = fmt.Sprintf("local limit %d", n)
+	// the agents send only the leading rows of the input shards
+	step.InputRowLimit = n + offset
 	return ret
 }
@@ -0,0 +1,35 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+var appendArgLength = gio.RegisterMapper(func(row []interface{}) error {
+	gio.Emit(row[0], len(gio.MapperArg()))
+	return nil
+})
+
+func TestMapWithLongArg(t *testing.T) {
+	// longer than a single argument of the command line can be on Linux
+	arg := strings.Repeat("x", 512*1024)
+
+	rows, err := New("testMapWithLongArg").Slices([][]interface{}{{"a"}, {"b"}}).
+		MapWithArg("length", appendArgLength, arg).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("map with arg: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v %v", row...))
+	}
+	expected := []string{"a 524288", "b 524288"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("mapped %v, expected %v", got, expected)
+	}
+}
@@ -4,7 +4,36 @@ import (
 	"github.com/lovelly/gleam/instruction"
 )
 
+// MergeSortedTo merges the locally sorted shards into partitionCount sorted
+// shards. With more than one partition, the rows are routed by ranges of the
+// sorted fields, split by the keys sampled from each shard, so the
+// partitions are merged in parallel and are sorted one after another.
+// Unsorted shards are merged as they are, the adjacent shards together.
 func (d *Dataset) MergeSortedTo(name string, partitionCount int) (ret *Dataset) {
+	if partitionCount == 1 || len(d.IsLocalSorted) == 0 {
+		return d.mergeAdjacentSortedTo(name, partitionCount)
+	}
+
+	// about 100 keys per partition from each shard
+	sampleSize := 100 * partitionCount
+	samples, step := add1ShardTo1Step(d)
+	step.SetInstruction(name+".sample", instruction.NewSampleRangeKeys(d.IsLocalSorted, sampleSize))
+
+	boundaries := d.Flow.NewNextDataset(1)
+	step = d.Flow.AddLinkedNToOneStep(samples, len(samples.Shards), boundaries)
+	step.SetInstruction(name, instruction.NewRangeBoundaries(d.IsLocalSorted, partitionCount))
+
+	scattered := d.scatterRanges(name, boundaries.Broadcast(name, len(d.Shards)), partitionCount)
+
+	ret = d.Flow.NewNextDataset(partitionCount)
+	ret.IsLocalSorted = d.IsLocalSorted
+	step = d.Flow.AddLinkedNToOneStep(scattered, len(d.Shards), ret)
+	step.SetInstruction(name, instruction.NewMergeSortedTo(d.IsLocalSorted))
+	return ret
+}
+
+// mergeAdjacentSortedTo merges every few adjacent shards into one.
+func (d *Dataset) mergeAdjacentSortedTo(name string, partitionCount int) (ret *Dataset) {
 	if len(d.Shards) == partitionCount {
 		return d
 	}
@@ -21,9 +50,53 @@ func (d *Dataset) MergeSortedTo(name string, partitionCount int) (ret *Dataset)
 	return ret
 }
 
+// scatterRanges routes the rows of each shard to the partitionCount ranges
+// split by the boundaries, which have a shard for each shard. The rows of
+// range k from shard i are in shard k*len(d.Shards)+i.
+func (d *Dataset) scatterRanges(name string, boundaries *Dataset, partitionCount int) (ret *Dataset) {
+	ret = d.Flow.NewNextDataset(len(d.Shards) * partitionCount)
+	step := d.Flow.NewStep()
+	step.NetworkType = OneShardToEveryNShard
+	fromStepToDataset(step, ret)
+	fromDatasetToStep(boundaries, step)
+	fromDatasetToStep(d, step)
+
+	m := len(d.Shards)
+	for i, shard := range d.Shards {
+		task := step.NewTask()
+		for k := 0; k < partitionCount; k++ {
+			fromTaskToDatasetShard(task, ret.Shards[k*m+i])
+		}
+		fromDatasetShardToTask(boundaries.Shards[i], task)
+		fromDatasetShardToTask(shard, task)
+	}
+	step.SetInstruction(name, instruction.NewScatterRanges(d.IsLocalSorted))
+	return ret
+}
+
+// MergeSortedWith merges the rows of the others into the locally sorted
+// shards of d, which stay sorted. The rows of the others are spread over the
+// shards of d, and sorted like them, first.
+func (d *Dataset) MergeSortedWith(name string, others ...*Dataset) (ret *Dataset) {
+	sortOption := &SortOption{orderByList: d.IsLocalSorted}
+	inputs := []*Dataset{d}
+	for _, other := range others {
+		if len(other.Shards) != len(d.Shards) {
+			other = other.MergeTo(name, 1).RoundRobin(name, len(d.Shards))
+		}
+		inputs = append(inputs, other.LocalSort(name, sortOption))
+	}
+
+	ret = d.Flow.NewNextDataset(len(d.Shards))
+	ret.IsLocalSorted = d.IsLocalSorted
+	step := d.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
+	step.SetInstruction(name, instruction.NewMergeSortedTo(d.IsLocalSorted))
+	return ret
+}
+
 func (d *Dataset) TreeMergeSortedTo(name string, partitionCount int, factor int) (ret *Dataset) {
 	if len(d.Shards) > factor && len(d.Shards) > partitionCount {
-		t := d.MergeSortedTo(name, len(d.Shards)/factor)
+		t := d.mergeAdjacentSortedTo(name, len(d.Shards)/factor)
 		return t.TreeMergeSortedTo(name, partitionCount, factor)
 	}
 	if len(d.Shards) > partitionCount {
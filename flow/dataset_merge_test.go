@@ -0,0 +1,66 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMergeSortedWith(t *testing.T) {
+	f := New("testMergeSortedWith")
+	sorted := f.Slices([][]interface{}{
+		{1, "a"}, {4, "d"}, {6, "f"},
+	}).LocalSort("sort", Field(1))
+	others := []*Dataset{
+		f.Slices([][]interface{}{{5, "e"}, {2, "b"}}),
+		f.Slices([][]interface{}{{7, "g"}, {3, "c"}, {0, "z"}}).RoundRobin("others", 2),
+	}
+
+	rows, err := sorted.MergeSortedWith("merge", others...).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("merge sorted: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v %v", row...))
+	}
+	expected := []string{"0 z", "1 a", "2 b", "3 c", "4 d", "5 e", "6 f", "7 g"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("merged %v, expected %v", got, expected)
+	}
+}
+
+func TestMergeSortedTo(t *testing.T) {
+	// more rows than the pipes between the steps buffer
+	const count = 20000
+	var slices [][]interface{}
+	for i := count - 1; i >= 0; i-- {
+		slices = append(slices, []interface{}{i, fmt.Sprintf("row %d", i)})
+	}
+
+	f := New("testMergeSortedTo")
+	merged := f.Slices(slices).RoundRobin("spread", 4).LocalSort("sort", Field(1)).MergeSortedTo("merge", 2)
+	if len(merged.Shards) != 2 {
+		t.Fatalf("merged to %d shards, expected 2", len(merged.Shards))
+	}
+
+	rows, err := merged.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("merge sorted: %v", err)
+	}
+	if len(rows) != count {
+		t.Fatalf("merged %d rows, expected %d", len(rows), count)
+	}
+	var keys []int
+	for _, row := range rows {
+		keys = append(keys, int(row[0].(int64)))
+	}
+	sort.Ints(keys)
+	for i, key := range keys {
+		if key != i {
+			t.Fatalf("merged row %d is %d", i, key)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 )
 
 // Output concurrently collects outputs from previous step to the driver.
+// The first error of f fails the Collect() of the flow.
 func (d *Dataset) Output(f func(io.Reader) error) *Dataset {
 	step := d.Flow.AddAllToOneStep(d, nil)
 	step.IsOnDriverSide = true
@@ -29,6 +30,11 @@ func (d *Dataset) Output(f func(io.Reader) error) *Dataset {
 			err := <-errChan
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to process output: %v\n", err)
+				d.Flow.failureLock.Lock()
+				if d.Flow.failure == nil {
+					d.Flow.failure = err
+				}
+				d.Flow.failureLock.Unlock()
 				return err
 			}
 		}
@@ -0,0 +1,27 @@
+package flow
+
+import (
+	"github.com/lovelly/gleam/util"
+)
+
+// Peek copies the first n rows written by each task of this dataset, while the
+// flow runs, to the driver and the master UI, to inspect the intermediate data
+// of a distributed run. Read them with PeekedRows() after the flow is run.
+func (d *Dataset) Peek(n int) *Dataset {
+	d.Step.PeekCount = n
+	return d
+}
+
+// PeekedRows returns the rows copied by Peek(), in the order of the tasks.
+func (d *Dataset) PeekedRows() (rows []*util.Row) {
+	for _, task := range d.Step.Tasks {
+		for _, data := range task.Stat.GetPeekedRows() {
+			row, err := util.DecodeRow(data)
+			if err != nil {
+				continue
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
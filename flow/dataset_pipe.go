@@ -8,7 +8,9 @@ import (
 // Pipe runs the code as an external program, which processes the
 // tab-separated input from the program's stdin, and outout to
 // stdout also in tab-separated lines.
+// The "{{name}}" placeholders of flow parameters are substituted.
 func (d *Dataset) Pipe(name, code string) *Dataset {
+	code = d.Flow.ExpandShell(code)
 	ret, step := add1ShardTo1Step(d)
 	step.Name = name
 	step.Description = code
@@ -26,6 +28,16 @@ func (d *Dataset) Pipe(name, code string) *Dataset {
 func (d *Dataset) PipeAsArgs(name, code string) *Dataset {
 	ret, step := add1ShardTo1Step(d)
 	step.IsPipe = true
-	step.SetInstruction(name, instruction.NewPipeAsArgs(code))
+	step.SetInstruction(name, instruction.NewPipeAsArgs(d.Flow.ExpandShell(code)))
+	return ret
+}
+
+// PipeColumn runs the code as an external program, which answers each
+// tab-separated line of the last argCount fields of the rows with one
+// line, in order. The answer replaces those fields, as the last field of
+// the row. The "{{name}}" placeholders of flow parameters are substituted.
+func (d *Dataset) PipeColumn(name, code string, argCount int) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(name, instruction.NewPipeColumn(d.Flow.Expand(code), argCount))
 	return ret
 }
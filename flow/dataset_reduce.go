@@ -1,11 +1,13 @@
 package flow
 
 import (
+	"log"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/instruction"
 	"github.com/lovelly/gleam/script"
 )
 
@@ -28,6 +30,55 @@ func (d *Dataset) ReduceBy(name string, reducerId gio.ReducerId, keyFields *Sort
 	return ret
 }
 
+// Aggregate runs the combiner registered to the combinerId on each shard,
+// folding the values of the rows with the same key, the first field, into an
+// accumulator starting from the seed, combiner(accumulator, values). Only one
+// row per key and shard is moved, where the reducer registered to the
+// reducerId merges the accumulators. The output rows are the key followed by
+// the accumulator, spread if it is a []interface{}.
+func (d *Dataset) Aggregate(name string, seed interface{}, combinerId, reducerId gio.ReducerId) *Dataset {
+	return d.CombineBy(name, seed, combinerId, reducerId, Field(1))
+}
+
+// CombineBy is Aggregate with the rows grouped by the key fields.
+func (d *Dataset) CombineBy(name string, seed interface{}, combinerId, reducerId gio.ReducerId, keyFields *SortOption) (ret *Dataset) {
+
+	name = name + ".CombineBy"
+
+	ret = d.LocalSort(name, keyFields).LocalCombineBy(name+".LocalCombineBy", seed, combinerId, keyFields)
+	if len(d.Shards) > 1 {
+		// the keys are moved to the front, in the same order
+		keysFirst := &SortOption{}
+		for i, orderBy := range keyFields.orderByList {
+			keysFirst.orderByList = append(keysFirst.orderByList, instruction.OrderBy{Index: i + 1, Order: orderBy.Order})
+		}
+		ret.IsLocalSorted = keysFirst.orderByList
+		ret = ret.MergeSortedTo(name, 1).LocalReduceBy(name+".LocalReduceBy", reducerId, keysFirst)
+	}
+	return ret
+}
+
+// LocalCombineBy folds the rows of each shard with the same key fields into
+// one row, by the combiner starting from the seed. The rows need to be sorted
+// by the key fields.
+func (d *Dataset) LocalCombineBy(name string, seed interface{}, combinerId gio.ReducerId, sortOption *SortOption) *Dataset {
+	encodedSeed, err := gio.EncodeSeed(seed)
+	if err != nil {
+		log.Panicf("LocalCombineBy %s: %v", name, err)
+	}
+
+	ret, step := add1ShardTo1Step(d)
+	step.Name = name
+	step.IsPipe = false
+	step.IsGoCode = true
+
+	combiner, _ := gio.GetReducer(combinerId)
+	step.Description = combiner.Name
+
+	step.Command = reducerCommand(combinerId, sortOption, "-gleam.seed", encodedSeed)
+	return ret
+}
+
 // Reduce runs the reducer registered to the reducerId,
 // combining all rows into one row
 func (d *Dataset) Reduce(name string, reducerId gio.ReducerId) (ret *Dataset) {
@@ -48,6 +99,14 @@ func (d *Dataset) LocalReduceBy(name string, reducerId gio.ReducerId, sortOption
 	step.IsPipe = false
 	step.IsGoCode = true
 
+	reducer, _ := gio.GetReducer(reducerId)
+	step.Description = reducer.Name
+
+	step.Command = reducerCommand(reducerId, sortOption)
+	return ret
+}
+
+func reducerCommand(reducerId gio.ReducerId, sortOption *SortOption, extraArgs ...string) *script.Command {
 	// add key indexes for reducer command line option
 	keyPositions := []string{}
 	if sortOption != nil {
@@ -62,17 +121,14 @@ func (d *Dataset) LocalReduceBy(name string, reducerId gio.ReducerId, sortOption
 
 	ex, _ := os.Executable()
 
-	reducer, _ := gio.GetReducer(reducerId)
-	step.Description = reducer.Name
-
 	var args []string
 	args = append(args, os.Args[1:]...)
 	args = append(args, "-gleam.reducer", string(reducerId))
 	args = append(args, "-gleam.keyFields", keyFields)
+	args = append(args, extraArgs...)
 
-	step.Command = &script.Command{
+	return &script.Command{
 		Path: ex,
 		Args: args,
 	}
-	return ret
 }
@@ -0,0 +1,66 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+// countAndSum folds the values into the seed [count, sum].
+var countAndSum = gio.RegisterReducer(func(accumulator, value interface{}) (interface{}, error) {
+	x := accumulator.([]interface{})
+	return []interface{}{gio.ToInt64(x[0]) + 1, gio.ToInt64(x[1]) + gio.ToInt64(value)}, nil
+})
+
+// addCountAndSum merges the [count, sum] of the shards.
+var addCountAndSum = gio.RegisterReducer(func(x, y interface{}) (interface{}, error) {
+	a, b := x.([]interface{}), y.([]interface{})
+	return []interface{}{gio.ToInt64(a[0]) + gio.ToInt64(b[0]), gio.ToInt64(a[1]) + gio.ToInt64(b[1])}, nil
+})
+
+var sumValues = gio.RegisterReducer(func(x, y interface{}) (interface{}, error) {
+	return gio.ToInt64(x) + gio.ToInt64(y), nil
+})
+
+func collectStrings(t *testing.T, d *Dataset) (got []string) {
+	rows, err := d.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	for _, row := range rows {
+		got = append(got, strings.TrimSpace(fmt.Sprintln(row...)))
+	}
+	sort.Strings(got)
+	return
+}
+
+func TestAggregate(t *testing.T) {
+	rows := [][]interface{}{{"a", 1}, {"b", 10}, {"a", 2}, {"a", 3}}
+	expected := []string{"a 3 6", "b 1 10"}
+	for _, shards := range []int{1, 2} {
+		f := New("testAggregate")
+		d := f.Slices(rows)
+		if shards > 1 {
+			d = d.RoundRobin("spread", shards)
+		}
+		aggregated := d.Aggregate("average", []interface{}{0, 0}, countAndSum, addCountAndSum)
+		if got := collectStrings(t, aggregated); !reflect.DeepEqual(got, expected) {
+			t.Errorf("aggregated %d shards to %v, expected %v", shards, got, expected)
+		}
+	}
+}
+
+func TestCombineBy(t *testing.T) {
+	f := New("testCombineBy")
+	rows := [][]interface{}{{"a", "x", 1}, {"a", "y", 2}, {"b", "x", 3}, {"a", "x", 4}, {"a", "y", 5}}
+	combined := f.Slices(rows).RoundRobin("spread", 3).CombineBy("sum", 0, sumValues, sumValues, Field(1, 2))
+	expected := []string{"a x 5", "a y 7", "b x 3"}
+	if got := collectStrings(t, combined); !reflect.DeepEqual(got, expected) {
+		t.Errorf("combined to %v, expected %v", got, expected)
+	}
+}
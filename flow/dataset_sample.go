@@ -0,0 +1,40 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lovelly/gleam/instruction"
+)
+
+// Sample keeps each row with the probability fraction, e.g. 0.1 for about
+// 10% of the rows. The rows are sampled in each shard, before any shuffle.
+// The same seed samples the same rows of the same input.
+func (d *Dataset) Sample(fraction float64, seed int64) *Dataset {
+	return d.sample("sample", instruction.NewSample(fraction, seed, false, nil, nil), fmt.Sprintf("sample %v", fraction))
+}
+
+// SampleByKey keeps each row with the probability of its key, the first
+// field, in fractions, e.g. to sample the rare keys more than the frequent
+// ones. The rows of the keys without fractions are dropped.
+func (d *Dataset) SampleByKey(fractions map[string]float64, seed int64) *Dataset {
+	var keys []string
+	for key := range fractions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var keyFractions []float64
+	for _, key := range keys {
+		keyFractions = append(keyFractions, fractions[key])
+	}
+	return d.sample("sampleByKey", instruction.NewSample(0, seed, true, keys, keyFractions), fmt.Sprintf("sample by key %v", fractions))
+}
+
+func (d *Dataset) sample(name string, sample *instruction.Sample, description string) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	ret.IsPartitionedBy = d.IsPartitionedBy
+	ret.IsLocalSorted = d.IsLocalSorted
+	step.SetInstruction(name, sample)
+	step.Description = description
+	return ret
+}
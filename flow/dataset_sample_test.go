@@ -0,0 +1,68 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	var input [][]interface{}
+	for i := 0; i < 1000; i++ {
+		input = append(input, []interface{}{i})
+	}
+	sample := func(fraction float64, seed int64) []string {
+		rows, err := New("testSample").Slices(input).RoundRobin("shards", 2).
+			Sample(fraction, seed).Collect(context.Background())
+		if err != nil {
+			t.Fatalf("sample %v: %v", fraction, err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprint(row...))
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	if got := sample(0, 1); len(got) != 0 {
+		t.Errorf("sampled %d rows of fraction 0", len(got))
+	}
+	if got := sample(1, 1); len(got) != len(input) {
+		t.Errorf("sampled %d rows of fraction 1, expected %d", len(got), len(input))
+	}
+	first := sample(0.3, 7)
+	if len(first) < 200 || len(first) > 400 {
+		t.Errorf("sampled %d rows of fraction 0.3 of %d", len(first), len(input))
+	}
+	if again := sample(0.3, 7); !reflect.DeepEqual(first, again) {
+		t.Errorf("the same seed sampled %d rows, then %d other rows", len(first), len(again))
+	}
+	if other := sample(0.3, 8); reflect.DeepEqual(first, other) {
+		t.Errorf("another seed sampled the same rows")
+	}
+}
+
+func TestSampleByKey(t *testing.T) {
+	var input [][]interface{}
+	for i := 0; i < 300; i++ {
+		input = append(input, []interface{}{[]string{"rare", "frequent", "dropped"}[i%3], i})
+	}
+	fractions := map[string]float64{"rare": 1, "frequent": 0.5}
+	rows, err := New("testSampleByKey").Slices(input).SampleByKey(fractions, 3).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("sample by key: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, row := range rows {
+		counts[fmt.Sprint(row[0])]++
+	}
+	if counts["rare"] != 100 || counts["dropped"] != 0 {
+		t.Errorf("sampled %v, expected all the rare rows and none of the dropped ones", counts)
+	}
+	if counts["frequent"] < 30 || counts["frequent"] > 70 {
+		t.Errorf("sampled %d of the 100 frequent rows with fraction 0.5", counts["frequent"])
+	}
+}
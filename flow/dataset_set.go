@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"github.com/lovelly/gleam/instruction"
+)
+
+// UnionRows unions this dataset with the others, partitioned by the rows.
+// With keepDuplicates, each row is kept as many times as it is in all the
+// datasets, like UNION ALL. Otherwise each row is kept once, like UNION.
+// Rows are equal if all their fields are equal.
+func (d *Dataset) UnionRows(name string, others []*Dataset, keepDuplicates bool) *Dataset {
+	inputs := []*Dataset{d.partitionByRow(name, len(d.Shards))}
+	for _, other := range others {
+		inputs = append(inputs, other.partitionByRow(name, len(d.Shards)))
+	}
+	return d.setOperation(name, inputs, instruction.SetUnion, keepDuplicates)
+}
+
+// Intersect keeps the rows of this dataset that are also in that dataset.
+// With keepDuplicates, a row is kept as many times as it is in both datasets,
+// like INTERSECT ALL. Otherwise each row is kept once.
+func (d *Dataset) Intersect(name string, that *Dataset, keepDuplicates bool) *Dataset {
+	return d.setOperation(name, []*Dataset{
+		d.partitionByRow(name+".left", len(d.Shards)),
+		that.partitionByRow(name+".right", len(d.Shards)),
+	}, instruction.SetIntersect, keepDuplicates)
+}
+
+// Subtract keeps the rows of this dataset that are not in that dataset.
+// With keepDuplicates, a row is kept as many more times as it is in this
+// dataset than in that dataset, like EXCEPT ALL. Otherwise each row is kept once.
+func (d *Dataset) Subtract(name string, that *Dataset, keepDuplicates bool) *Dataset {
+	return d.setOperation(name, []*Dataset{
+		d.partitionByRow(name+".left", len(d.Shards)),
+		that.partitionByRow(name+".right", len(d.Shards)),
+	}, instruction.SetSubtract, keepDuplicates)
+}
+
+func (d *Dataset) setOperation(name string, inputs []*Dataset, operation string, keepDuplicates bool) *Dataset {
+	ret := d.Flow.NewNextDataset(len(d.Shards))
+	step := d.Flow.MergeDatasets1ShardTo1Step(inputs, ret)
+	step.SetInstruction(name, instruction.NewSetOperation(operation, keepDuplicates))
+	return ret
+}
+
+// partitionByRow partitions the rows by the hash of all their fields, so that
+// equal rows of datasets with the same shard count are in the same shards.
+func (d *Dataset) partitionByRow(name string, shard int) *Dataset {
+	if 1 == len(d.Shards) && shard == 1 {
+		return d
+	}
+	ret := d.Flow.NewNextDataset(len(d.Shards) * shard)
+	step := d.Flow.AddOneToEveryNStep(d, shard, ret)
+	step.SetInstruction(name, instruction.NewScatterRows())
+	if len(ret.Shards) != shard {
+		ret = ret.partition_collect(name, shard, nil)
+	}
+	return ret
+}
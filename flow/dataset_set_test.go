@@ -0,0 +1,54 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSetOperations(t *testing.T) {
+	left := [][]interface{}{{"a", 1}, {"a", 1}, {"a", 1}, {"b", 2}, {"c", nil}, {"d", 4}}
+	right := [][]interface{}{{"a", 1}, {"a", 1}, {"b", 3}, {"c", nil}, {"e", 5}}
+
+	tests := []struct {
+		name     string
+		op       func(l, r *Dataset) *Dataset
+		expected []string
+	}{
+		{"union", func(l, r *Dataset) *Dataset { return l.UnionRows("union", []*Dataset{r}, false) },
+			[]string{"a 1", "b 2", "b 3", "c <nil>", "d 4", "e 5"}},
+		{"union all", func(l, r *Dataset) *Dataset { return l.UnionRows("union", []*Dataset{r}, true) },
+			[]string{"a 1", "a 1", "a 1", "a 1", "a 1", "b 2", "b 3", "c <nil>", "c <nil>", "d 4", "e 5"}},
+		{"intersect", func(l, r *Dataset) *Dataset { return l.Intersect("intersect", r, false) },
+			[]string{"a 1", "c <nil>"}},
+		{"intersect all", func(l, r *Dataset) *Dataset { return l.Intersect("intersect", r, true) },
+			[]string{"a 1", "a 1", "c <nil>"}},
+		{"subtract", func(l, r *Dataset) *Dataset { return l.Subtract("subtract", r, false) },
+			[]string{"b 2", "d 4"}},
+		{"subtract all", func(l, r *Dataset) *Dataset { return l.Subtract("subtract", r, true) },
+			[]string{"a 1", "b 2", "d 4"}},
+	}
+	for _, test := range tests {
+		for _, shards := range []int{1, 3} {
+			f := New("testSetOperations")
+			l, r := f.Slices(left), f.Slices(right)
+			if shards > 1 {
+				l, r = l.RoundRobin("left", shards), r.RoundRobin("right", shards)
+			}
+			rows, err := test.op(l, r).Collect(context.Background())
+			if err != nil {
+				t.Fatalf("%s of %d shards: %v", test.name, shards, err)
+			}
+			var got []string
+			for _, row := range rows {
+				got = append(got, fmt.Sprintf("%v %v", row...))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("%s of %d shards: %v, expected %v", test.name, shards, got, test.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package flow
+
+type Sinker interface {
+	Save(*Dataset) *Dataset
+}
+
+// Write writes the dataset to a data sink, e.g. files, and returns the
+// dataset of the writing step.
+func (d *Dataset) Write(s Sinker) *Dataset {
+	return s.Save(d)
+}
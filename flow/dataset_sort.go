@@ -38,12 +38,31 @@ func (d *Dataset) SortByKey(name string) *Dataset {
 	return d.Sort(name, Field(1))
 }
 
-// Top streams through total n items, picking reverse ordered k items with O(n*log(k)) complexity.
-// Required Memory: about same size as n items in memory
+// Top picks the first k rows ordered by the sortOption, like Sort() and
+// Limit(), streaming through total n items with O(n*log(k)) complexity.
+// Required Memory: about same size as k items in memory
 func (d *Dataset) Top(name string, k int, sortOption *SortOption) *Dataset {
 	ret := d.LocalTop(name, k, sortOption)
 	if len(d.Shards) > 1 {
-		ret = ret.MergeSortedTo(name, 1).LocalLimit(name, k, 0)
+		ret = ret.MergeTo(name, 1).LocalTop(name+".merge", k, sortOption)
+	}
+	return ret
+}
+
+// TopN picks the first n rows ordered by the sortOption, see Top().
+func (d *Dataset) TopN(name string, n int, sortOption *SortOption) *Dataset {
+	return d.Top(name, n, sortOption)
+}
+
+// TopNByKey picks the first n rows of each key, the first field, ordered by
+// the sortOption. The rows of each shard are partitioned by the key after
+// keeping their top rows, which are merged by another heap per key.
+// Required Memory: about same size as n rows of each key in memory
+func (d *Dataset) TopNByKey(name string, n int, sortOption *SortOption) *Dataset {
+	keys := []int{1}
+	ret := d.localTop(name, n, sortOption, keys)
+	if len(d.Shards) > 1 && !intArrayEquals(d.IsPartitionedBy, keys) {
+		ret = ret.PartitionByKey(name, len(d.Shards)).localTop(name+".merge", n, sortOption, keys)
 	}
 	return ret
 }
@@ -69,11 +88,19 @@ func (d *Dataset) LocalSort(name string, sortOption *SortOption) *Dataset {
 	return ret
 }
 
+// LocalTop picks the first n rows of each shard ordered by the sortOption,
+// keeping n rows in a heap.
 func (d *Dataset) LocalTop(name string, n int, sortOption *SortOption) *Dataset {
+	return d.localTop(name, n, sortOption, nil)
+}
+
+// localTop picks the first n rows of each key of the fields at indexes, or of
+// all the rows of each shard.
+func (d *Dataset) localTop(name string, n int, sortOption *SortOption, indexes []int) *Dataset {
 	ret, step := add1ShardTo1Step(d)
-	ret.IsLocalSorted = getReverseOrderBy(sortOption.orderByList)
+	ret.IsLocalSorted = append(Field(indexes...).orderByList, sortOption.orderByList...)
 	ret.IsPartitionedBy = d.IsPartitionedBy
-	step.SetInstruction(name, instruction.NewLocalTop(n, ret.IsLocalSorted))
+	step.SetInstruction(name, instruction.NewLocalTop(n, sortOption.orderByList, indexes))
 	step.Description = fmt.Sprintf("local top %v", n)
 
 	return ret
@@ -90,14 +117,3 @@ func isOrderByEquals(a []instruction.OrderBy, b []instruction.OrderBy) bool {
 	}
 	return true
 }
-
-func getReverseOrderBy(a []instruction.OrderBy) (reversed []instruction.OrderBy) {
-	for _, v := range a {
-		if v.Order == instruction.Ascending {
-			reversed = append(reversed, instruction.OrderBy{v.Index, instruction.Descending})
-		} else {
-			reversed = append(reversed, instruction.OrderBy{v.Index, instruction.Ascending})
-		}
-	}
-	return reversed
-}
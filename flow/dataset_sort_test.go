@@ -0,0 +1,82 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTop(t *testing.T) {
+	input := [][]interface{}{{3, "c"}, {1, "a"}, {5, "e"}, {2, "b"}, {4, "d"}, {6, "f"}}
+
+	tests := []struct {
+		name       string
+		shards     int
+		sortOption *SortOption
+		expected   []string
+	}{
+		{"ascending", 1, Field(1), []string{"1 a", "2 b", "3 c"}},
+		{"descending", 1, OrderBy(1, false), []string{"6 f", "5 e", "4 d"}},
+		{"ascending of shards", 2, Field(1), []string{"1 a", "2 b", "3 c"}},
+		{"descending of shards", 3, OrderBy(1, false), []string{"6 f", "5 e", "4 d"}},
+	}
+	for _, test := range tests {
+		for _, top := range []func(d *Dataset) *Dataset{
+			func(d *Dataset) *Dataset { return d.Top("top", 3, test.sortOption) },
+			func(d *Dataset) *Dataset { return d.TopN("topN", 3, test.sortOption) },
+		} {
+			d := New("testTop").Slices(input)
+			if test.shards > 1 {
+				d = d.RoundRobin("shards", test.shards)
+			}
+			rows, err := top(d).Collect(context.Background())
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			var got []string
+			for _, row := range rows {
+				got = append(got, fmt.Sprintf("%v %v", row...))
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("%s: top %v, expected %v", test.name, got, test.expected)
+			}
+		}
+	}
+}
+
+func TestTopNByKey(t *testing.T) {
+	input := [][]interface{}{
+		{"b", 2}, {"a", 5}, {"b", 7}, {"a", 1}, {"c", 3},
+		{"a", 4}, {"b", 1}, {"a", 3}, {"b", 9},
+	}
+
+	tests := []struct {
+		name       string
+		shards     int
+		sortOption *SortOption
+		expected   []string
+	}{
+		{"ascending", 1, Field(2), []string{"a 1", "a 3", "b 1", "b 2", "c 3"}},
+		{"descending", 1, OrderBy(2, false), []string{"a 5", "a 4", "b 9", "b 7", "c 3"}},
+		{"descending of shards", 3, OrderBy(2, false), []string{"a 5", "a 4", "b 9", "b 7", "c 3"}},
+	}
+	for _, test := range tests {
+		d := New("testTopNByKey").Slices(input)
+		if test.shards > 1 {
+			d = d.RoundRobin("shards", test.shards)
+		}
+		rows, err := d.TopNByKey("topNByKey", 2, test.sortOption).
+			MergeSortedTo("merged", 1).Collect(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprintf("%v %v", row...))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: top by key %v, expected %v", test.name, got, test.expected)
+		}
+	}
+}
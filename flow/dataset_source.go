@@ -74,6 +74,15 @@ func (fc *Flow) Source(name string, f func(io.Writer, *pb.InstructionStat) error
 	return
 }
 
+// FailedSource is a source failing with the error, for the sources which
+// fail to generate their datasets, so the flow fails with the error when it
+// runs instead of on a nil dataset.
+func (fc *Flow) FailedSource(name string, err error) (ret *Dataset) {
+	return fc.Source(name, func(io.Writer, *pb.InstructionStat) error {
+		return err
+	})
+}
+
 // Channel accepts a channel to feed into the flow.
 func (fc *Flow) Channel(ch chan interface{}) (ret *Dataset) {
 	ret = fc.NewNextDataset(1)
@@ -106,7 +115,11 @@ func (fc *Flow) Bytes(slice [][]byte) (ret *Dataset) {
 		close(inputChannel)
 	}()
 
-	return fc.Channel(inputChannel)
+	var size int64
+	for _, data := range slice {
+		size += int64(len(data))
+	}
+	return fc.Channel(inputChannel).inMemory(size)
 }
 
 // Strings begins a flow with an []string
@@ -120,7 +133,11 @@ func (fc *Flow) Strings(lines []string) (ret *Dataset) {
 		close(inputChannel)
 	}()
 
-	return fc.Channel(inputChannel)
+	var size int64
+	for _, data := range lines {
+		size += int64(len(data))
+	}
+	return fc.Channel(inputChannel).inMemory(size)
 }
 
 // Ints begins a flow with an []int
@@ -134,7 +151,7 @@ func (fc *Flow) Ints(numbers []int) (ret *Dataset) {
 		close(inputChannel)
 	}()
 
-	return fc.Channel(inputChannel)
+	return fc.Channel(inputChannel).inMemory(int64(8 * len(numbers)))
 }
 
 // Slices begins a flow with an [][]interface{}
@@ -147,7 +164,7 @@ func (fc *Flow) Slices(slices [][]interface{}) (ret *Dataset) {
 	step.Function = func(readers []io.Reader, writers []io.Writer, stat *pb.InstructionStat) error {
 		for _, slice := range slices {
 			stat.InputCounter++
-			err := util.NewRow(util.Now(), slice).WriteTo(writers[0])
+			err := util.NewRow(util.Now(), slice...).WriteTo(writers[0])
 			if err != nil {
 				return err
 			}
@@ -155,6 +172,18 @@ func (fc *Flow) Slices(slices [][]interface{}) (ret *Dataset) {
 		}
 		return nil
 	}
-	return
 
+	var size int64
+	for _, slice := range slices {
+		size += int64(util.NewRow(0, slice...).Msgsize())
+	}
+	return ret.inMemory(size)
+
+}
+
+// inMemory marks the size of the dataset read from memory, in bytes.
+func (d *Dataset) inMemory(size int64) *Dataset {
+	d.Meta.TotalSize = size / (1024 * 1024)
+	d.Meta.IsSizeKnown = true
+	return d
 }
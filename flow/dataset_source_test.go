@@ -0,0 +1,35 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlicesWriteFieldsOfRows(t *testing.T) {
+	rows, err := New("testSlices").Slices([][]interface{}{
+		{"a", 1},
+		{"b", 2},
+	}).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{{"a", 1}, {"b", 2}}
+	if len(rows) != len(expected) {
+		t.Fatalf("collected %v, expected %v", rows, expected)
+	}
+	for i, row := range rows {
+		if len(row) != 2 || row[0] != expected[i][0] || reflect.ValueOf(row[1]).Int() != int64(expected[i][1].(int)) {
+			t.Errorf("row %d is %v, expected %v", i, row, expected[i])
+		}
+	}
+}
+
+func TestFailedSource(t *testing.T) {
+	_, err := New("testFailedSource").FailedSource("export", errors.New("no credentials")).Collect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("collected with error %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/instruction"
+)
+
+// Split splits the rows tagged by their first field, e.g. by gio.EmitTo()
+// in a mapper, into the datasets of the tags 0 to tagCount-1, without the
+// tag. The rows of other tags are dropped, and counted as filtered. The
+// dataset is computed once, and read by each of the returned datasets, e.g.
+//
+//	outputs := d.Map("validate", validateId).Split("validate", 2)
+//	valid, invalid := outputs[0], outputs[1]
+func (d *Dataset) Split(name string, tagCount int) []*Dataset {
+	var outputs []*Dataset
+	for tag := 0; tag < tagCount; tag++ {
+		outputs = append(outputs, d.SelectTag(name, tag))
+	}
+	return outputs
+}
+
+// SelectTag keeps the rows tagged by the tag, their first field, without the
+// tag. See Split().
+func (d *Dataset) SelectTag(name string, tag int) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(fmt.Sprintf("%s.%d", name, tag), instruction.NewSelectTag(tag))
+	step.Description = fmt.Sprintf("tag %d", tag)
+
+	// the rows keep their order, with the fields after the tag moved forward
+	if !hasIndex(d.IsPartitionedBy, 1) {
+		for _, index := range d.IsPartitionedBy {
+			ret.IsPartitionedBy = append(ret.IsPartitionedBy, index-1)
+		}
+	}
+	var sortIndexes []int
+	for _, orderBy := range d.IsLocalSorted {
+		sortIndexes = append(sortIndexes, orderBy.Index)
+	}
+	if !hasIndex(sortIndexes, 1) {
+		for _, orderBy := range d.IsLocalSorted {
+			ret.IsLocalSorted = append(ret.IsLocalSorted, instruction.OrderBy{Index: orderBy.Index - 1, Order: orderBy.Order})
+		}
+	}
+	if d.Meta.FieldCount > 1 {
+		ret.Meta.FieldCount = d.Meta.FieldCount - 1
+	}
+	return ret
+}
+
+func hasIndex(indexes []int, index int) bool {
+	for _, x := range indexes {
+		if x == index {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/util"
+)
+
+var tagByParity = gio.RegisterMapper(func(row []interface{}) error {
+	x := gio.ToInt64(row[0])
+	return gio.EmitTo(int(x%2), x, row[1])
+})
+
+func TestSplit(t *testing.T) {
+	f := New("testSplit")
+	outputs := f.Slices([][]interface{}{
+		{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"},
+	}).Map("tag", tagByParity).Split("parity", 2)
+	if len(outputs) != 2 {
+		t.Fatalf("split to %d datasets, expected 2", len(outputs))
+	}
+
+	// both outputs read the tagged rows, so they are collected together
+	var even, odd []string
+	outputs[0].OutputRow(func(row *util.Row) error {
+		even = append(even, fmt.Sprintf("%v %v", row.K[0], row.V[0]))
+		return nil
+	})
+	outputs[1].OutputRow(func(row *util.Row) error {
+		odd = append(odd, fmt.Sprintf("%v %v", row.K[0], row.V[0]))
+		return nil
+	})
+	if err := f.RunErr(context.Background()); err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	sort.Strings(even)
+	sort.Strings(odd)
+	if !reflect.DeepEqual(even, []string{"2 b", "4 d"}) || !reflect.DeepEqual(odd, []string{"1 a", "3 c"}) {
+		t.Errorf("split to %v and %v", even, odd)
+	}
+}
+
+func TestSelectTagPartitioning(t *testing.T) {
+	f := New("testSelectTagPartitioning")
+	d := f.Slices([][]interface{}{{0, 1, "a"}})
+	d.IsPartitionedBy = []int{2}
+	d.IsLocalSorted = []instruction.OrderBy{{Index: 3, Order: instruction.Descending}}
+	d.Meta.FieldCount = 3
+
+	ret := d.SelectTag("select", 0)
+	if !reflect.DeepEqual(ret.IsPartitionedBy, []int{1}) {
+		t.Errorf("partitioned by %v, expected [1]", ret.IsPartitionedBy)
+	}
+	if !reflect.DeepEqual(ret.IsLocalSorted, []instruction.OrderBy{{Index: 2, Order: instruction.Descending}}) {
+		t.Errorf("sorted by %v", ret.IsLocalSorted)
+	}
+	if ret.Meta.FieldCount != 2 {
+		t.Errorf("field count %d, expected 2", ret.Meta.FieldCount)
+	}
+
+	// the tag is not kept, so neither is the order or partitioning by it
+	d.IsPartitionedBy = []int{1}
+	d.IsLocalSorted = []instruction.OrderBy{{Index: 1, Order: instruction.Ascending}}
+	ret = d.SelectTag("select", 0)
+	if len(ret.IsPartitionedBy) != 0 || len(ret.IsLocalSorted) != 0 {
+		t.Errorf("partitioned by %v and sorted by %v by the tag", ret.IsPartitionedBy, ret.IsLocalSorted)
+	}
+}
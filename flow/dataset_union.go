@@ -4,7 +4,8 @@ import (
 	"github.com/lovelly/gleam/instruction"
 )
 
-// Union union multiple Datasets as one Dataset
+// Union union multiple Datasets as one Dataset, keeping the duplicated rows.
+// See UnionRows to keep each row once.
 func (this *Dataset) Union(name string, others []*Dataset, isParallel bool) *Dataset {
 	ret := this.Flow.NewNextDataset(len(this.Shards))
 	inputs := []*Dataset{this}
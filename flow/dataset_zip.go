@@ -0,0 +1,42 @@
+package flow
+
+import (
+	"github.com/lovelly/gleam/instruction"
+)
+
+// ZipWithIndex appends to each row its index in the dataset, counting from
+// 0 in the order of the shards, and of the rows in each shard. The rows of
+// each shard are counted first, and then read again with the number of rows
+// in the shards before it.
+func (d *Dataset) ZipWithIndex(name string) *Dataset {
+	counts, step := add1ShardTo1Step(d)
+	step.SetInstruction(name+".count", instruction.NewCountRows())
+
+	offsets := d.Flow.NewNextDataset(len(d.Shards))
+	step = d.Flow.AddAllToAllStep(counts, offsets)
+	step.SetInstruction(name+".offsets", instruction.NewShardOffsets())
+
+	ret := d.Flow.NewNextDataset(len(d.Shards))
+	step = d.Flow.MergeDatasets1ShardTo1Step([]*Dataset{offsets, d}, ret)
+	step.SetInstruction(name, instruction.NewZipWithIndex(false))
+	return d.zipped(ret)
+}
+
+// ZipWithUniqueId appends to each row a unique id, the shard id in the
+// high bits above the index of the row in its shard. Unlike ZipWithIndex(),
+// the ids have gaps, but the rows are read only once.
+func (d *Dataset) ZipWithUniqueId(name string) *Dataset {
+	ret, step := add1ShardTo1Step(d)
+	step.SetInstruction(name, instruction.NewZipWithIndex(true))
+	return d.zipped(ret)
+}
+
+// zipped keeps the keys and the order of the rows, with one more field.
+func (d *Dataset) zipped(ret *Dataset) *Dataset {
+	ret.IsPartitionedBy = d.IsPartitionedBy
+	ret.IsLocalSorted = d.IsLocalSorted
+	if d.Meta.FieldCount > 0 {
+		ret.Meta.FieldCount = d.Meta.FieldCount + 1
+	}
+	return ret
+}
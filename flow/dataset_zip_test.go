@@ -0,0 +1,60 @@
+package flow
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestZipWithIndex(t *testing.T) {
+	const count = 1000
+	var slices [][]interface{}
+	for i := 0; i < count; i++ {
+		slices = append(slices, []interface{}{i})
+	}
+
+	f := New("testZipWithIndex")
+	// the unique ids tell the shard of each row, and its index in the shard
+	zipped := f.Slices(slices).RoundRobin("spread", 3).ZipWithUniqueId("id").ZipWithIndex("index")
+	rows, err := zipped.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	if len(rows) != count {
+		t.Fatalf("zipped %d rows, expected %d", len(rows), count)
+	}
+
+	type zippedRow struct{ shard, indexInShard, index int64 }
+	var zippedRows []zippedRow
+	values := make(map[int64]bool)
+	for _, row := range rows {
+		id := row[1].(int64)
+		zippedRows = append(zippedRows, zippedRow{id >> 40, id & (1<<40 - 1), row[2].(int64)})
+		values[row[0].(int64)] = true
+	}
+	if len(values) != count {
+		t.Errorf("zipped %d distinct rows, expected %d", len(values), count)
+	}
+	sort.Slice(zippedRows, func(i, j int) bool {
+		x, y := zippedRows[i], zippedRows[j]
+		return x.shard < y.shard || x.shard == y.shard && x.indexInShard < y.indexInShard
+	})
+
+	// the indexes count the rows of the shards one after another
+	shards := make(map[int64]bool)
+	for i, row := range zippedRows {
+		shards[row.shard] = true
+		if row.index != int64(i) {
+			t.Fatalf("row %d of shard %d has index %d, expected %d", row.indexInShard, row.shard, row.index, i)
+		}
+		if i > 0 && row.shard == zippedRows[i-1].shard && row.indexInShard != zippedRows[i-1].indexInShard+1 {
+			t.Fatalf("unique ids of shard %d skip from %d to %d", row.shard, zippedRows[i-1].indexInShard, row.indexInShard)
+		}
+		if (i == 0 || row.shard != zippedRows[i-1].shard) && row.indexInShard != 0 {
+			t.Fatalf("unique ids of shard %d start from %d", row.shard, row.indexInShard)
+		}
+	}
+	if len(shards) != 3 {
+		t.Errorf("zipped rows of %d shards, expected 3", len(shards))
+	}
+}
@@ -0,0 +1,216 @@
+package flow
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/util"
+)
+
+// IterationStats describes an iteration run by Flow.Loop().
+type IterationStats struct {
+	Iteration int   // counting from 1
+	Rows      int64 // the rows of the dataset returned by the body
+	Duration  time.Duration
+}
+
+// Loop applies the body to the dataset d of the flow repeatedly, e.g. for
+// PageRank or k-means, until the until function returns true after an
+// iteration. Each iteration runs as a flow by the options, reading the output
+// of the previous iteration from the shards cached by Cache(), so the agents
+// keep the shards between the iterations, and delete them after the next
+// iteration. The other datasets of the flow read by the body, e.g. the links
+// of PageRank, are cached by the first iteration, and read from their caches
+// by the later ones. The outputs added by the body, e.g. OutputRow(), run in
+// every iteration, and can compute the measures of the convergence checked by
+// until. It returns the output of the last iteration, as the first dataset of
+// a new flow.
+func (fc *Flow) Loop(d *Dataset, body func(*Dataset) *Dataset, until func(IterationStats) bool, options ...FlowOption) *Dataset {
+	if d.Flow != fc {
+		log.Panicf("Loop on dataset d%d of flow %s, not of flow %s", d.Id, d.Flow.Name, fc.Name)
+	}
+
+	// each iteration has its own cache name, so it does not read the shards
+	// cached by an earlier iteration instead of running
+	cacheName := func(iteration int) string {
+		return fmt.Sprintf("loop-%d-%d", fc.HashCode, iteration)
+	}
+
+	var statics []*loopStatic
+	current, input, shardCount := fc, d, 0
+	for iteration := 1; ; iteration++ {
+		var output *Dataset
+		if iteration == 1 {
+			bodyStart := len(fc.Steps)
+			output = body(input).Cache(cacheName(iteration))
+			for _, static := range fc.staticInputs(fc.Steps[bodyStart:], input) {
+				statics = append(statics, cacheStatic(static))
+			}
+		} else {
+			// the cached statics are read before the steps of the body
+			readers := make(map[*Dataset]*Dataset)
+			for _, static := range statics {
+				reader := current.cachedInput("LoopStatic", static.cacheName, len(static.d.Shards))
+				reader.IsPartitionedBy = static.d.IsPartitionedBy
+				reader.IsLocalSorted = static.d.IsLocalSorted
+				reader.Meta.FieldCount = static.d.Meta.FieldCount
+				readers[static.d] = reader
+			}
+			bodyStart := len(current.Steps)
+			stepCount, datasetCount := len(fc.Steps), len(fc.Datasets)
+			output = body(input).Cache(cacheName(iteration))
+			// the body may transform the statics too, e.g. partition them for a join
+			current.adopt(fc, stepCount, datasetCount)
+			for _, step := range current.Steps[bodyStart:] {
+				for static, reader := range readers {
+					replaceInput(step, static, reader)
+				}
+			}
+			current.sortSteps()
+		}
+
+		stats := IterationStats{Iteration: iteration}
+		counts, step := add1ShardTo1Step(output)
+		step.SetInstruction("loop.count", instruction.NewCountRows())
+		// each shard counts its rows, summed up by the driver
+		counts.MergeTo("loop", 1).OutputRow(func(row *util.Row) error {
+			stats.Rows += util.ToInt64(row.K[0])
+			return nil
+		})
+
+		startTime := time.Now()
+		current.Run(options...)
+		stats.Duration = time.Since(startTime)
+		if iteration > 1 {
+			uncache(cacheName(iteration-1), shardCount, options)
+		}
+		shardCount = len(output.Shards)
+
+		current = fc.nextIteration(iteration)
+		input = current.cachedInput("LoopInput", cacheName(iteration), shardCount)
+		if until(stats) {
+			for _, static := range statics {
+				if static.isOwned {
+					uncache(static.cacheName, len(static.d.Shards), options)
+				}
+			}
+			return input
+		}
+	}
+}
+
+// loopStatic is a dataset of the flow read by the body of Loop(), kept by
+// the cache name for the later iterations.
+type loopStatic struct {
+	d         *Dataset
+	cacheName string
+	isOwned   bool // cached by Loop(), and so dropped after the last iteration
+}
+
+// cacheStatic keeps the shards of the dataset read by the body of Loop(),
+// unless it is cached already. The agents only keep the shards written by
+// them, so the datasets written by the driver, e.g. Slices(), are copied to
+// a cached dataset by the agents.
+func cacheStatic(d *Dataset) *loopStatic {
+	if d.Meta.CacheName != "" && !d.Step.IsOnDriverSide {
+		return &loopStatic{d: d, cacheName: d.Meta.CacheName}
+	}
+	name := fmt.Sprintf("loop-%d-d%d", d.Flow.HashCode, d.Id)
+	if !d.Step.IsOnDriverSide {
+		d.Cache(name)
+		return &loopStatic{d: d, cacheName: name, isOwned: true}
+	}
+	copied, step := add1ShardTo1Step(d)
+	step.SetInstruction("loop.static", instruction.NewMergeTo())
+	copied.Cache(name)
+	return &loopStatic{d: d, cacheName: name, isOwned: true}
+}
+
+// staticInputs returns the datasets of the flow read by the steps, besides
+// the input, which are not written by the steps.
+func (fc *Flow) staticInputs(steps []*Step, input *Dataset) (statics []*Dataset) {
+	written := make(map[*Dataset]bool)
+	for _, step := range steps {
+		written[step.OutputDataset] = true
+	}
+	found := make(map[*Dataset]bool)
+	for _, step := range steps {
+		for _, d := range step.InputDatasets {
+			if d != input && !written[d] && !found[d] {
+				found[d] = true
+				statics = append(statics, d)
+			}
+		}
+	}
+	return
+}
+
+// uncache drops the shards cached by the name, kept locally, or by the
+// agents of the flows run with the options which can delete them.
+func uncache(name string, shardCount int, options []FlowOption) {
+	Uncache(name)
+	for _, option := range options {
+		if u, ok := option.(interface {
+			Uncache(name string, shardCount int)
+		}); ok {
+			u.Uncache(name, shardCount)
+		}
+	}
+}
+
+// adopt moves the steps and datasets added to the other flow since it had the
+// given counts, e.g. by transforming its datasets in the body of Loop(), to
+// this flow.
+func (fc *Flow) adopt(other *Flow, stepCount, datasetCount int) {
+	for _, d := range other.Datasets[datasetCount:] {
+		d.Flow, d.Id = fc, len(fc.Datasets)
+		fc.Datasets = append(fc.Datasets, d)
+	}
+	other.Datasets = other.Datasets[:datasetCount]
+	for _, step := range other.Steps[stepCount:] {
+		step.Flow, step.Id = fc, len(fc.Steps)
+		fc.Steps = append(fc.Steps, step)
+	}
+	other.Steps = other.Steps[:stepCount]
+}
+
+// sortSteps orders the steps after the steps writing their inputs, keeping
+// the order of the steps otherwise, and renumbers them by the order.
+func (fc *Flow) sortSteps() {
+	visited := make(map[*Step]bool)
+	var sorted []*Step
+	var visit func(step *Step)
+	visit = func(step *Step) {
+		if visited[step] || step.Flow != fc {
+			return
+		}
+		visited[step] = true
+		for _, input := range step.InputDatasets {
+			if input != nil {
+				visit(input.Step)
+			}
+		}
+		sorted = append(sorted, step)
+	}
+	for _, step := range fc.Steps {
+		visit(step)
+	}
+	for i, step := range sorted {
+		step.Id = i
+	}
+	fc.Steps = sorted
+}
+
+// nextIteration creates the flow of the iteration after the given one.
+func (fc *Flow) nextIteration(iteration int) *Flow {
+	next := New(fmt.Sprintf("%s-%d", fc.Name, iteration+1))
+	if fc.Params != nil {
+		next.Params = make(map[string]string)
+		for name, value := range fc.Params {
+			next.Params[name] = value
+		}
+	}
+	return next
+}
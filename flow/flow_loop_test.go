@@ -0,0 +1,78 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+var multiplyByWeight = gio.RegisterMapper(func(row []interface{}) error {
+	gio.Emit(row[0], gio.ToInt64(row[1])*gio.ToInt64(row[2]))
+	return nil
+})
+
+// uncacheRecorder runs the flows locally, and records the dropped caches.
+type uncacheRecorder struct {
+	names []string
+}
+
+func (r *uncacheRecorder) GetFlowRunner() FlowRunner { return Local }
+func (r *uncacheRecorder) Uncache(name string, shardCount int) {
+	r.names = append(r.names, fmt.Sprintf("%s/%d", name, shardCount))
+}
+
+func TestLoop(t *testing.T) {
+	f := New("testLoop")
+	weights := f.Slices([][]interface{}{{"a", 2}, {"b", 3}})
+	values := f.Slices([][]interface{}{{"a", 1}, {"b", 1}})
+
+	var iterations []int64
+	recorder := &uncacheRecorder{}
+	result := f.Loop(values, func(d *Dataset) *Dataset {
+		// the weights of the first flow are read by every iteration
+		return d.Join("join", weights, Field(1)).Map("multiply", multiplyByWeight).PartitionByKey("shards", 2)
+	}, func(stats IterationStats) bool {
+		iterations = append(iterations, stats.Rows)
+		return stats.Iteration == 3
+	}, recorder)
+
+	rows, err := result.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v %v", row...))
+	}
+	sort.Strings(got)
+	if expected := []string{"a 8", "b 27"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("loop ended with %v, expected %v", got, expected)
+	}
+	if expected := []int64{2, 2, 2}; !reflect.DeepEqual(iterations, expected) {
+		t.Errorf("iterations counted %v rows, expected %v", iterations, expected)
+	}
+
+	// the outputs of the first 2 iterations, and the cached weights
+	last := fmt.Sprintf("loop-%d-3", f.HashCode)
+	expected := []string{
+		fmt.Sprintf("loop-%d-1/2", f.HashCode),
+		fmt.Sprintf("loop-%d-2/2", f.HashCode),
+		fmt.Sprintf("loop-%d-d%d/1", f.HashCode, weights.Id),
+	}
+	if !reflect.DeepEqual(recorder.names, expected) {
+		t.Errorf("dropped the caches %v, expected %v", recorder.names, expected)
+	}
+	for _, name := range []string{fmt.Sprintf("loop-%d-1", f.HashCode), fmt.Sprintf("loop-%d-d%d", f.HashCode, weights.Id)} {
+		if IsCached(name) {
+			t.Errorf("kept the cache %s after the loop", name)
+		}
+	}
+	if !IsCached(last) {
+		t.Errorf("dropped the output %s of the last iteration", last)
+	}
+	Uncache(last)
+}
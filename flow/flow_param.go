@@ -0,0 +1,104 @@
+package flow
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// paramFlag collects the repeated "-flow.param name=value" command line flags.
+type paramFlag map[string]string
+
+var paramFlagValues = make(paramFlag)
+
+func init() {
+	flag.Var(paramFlagValues, "flow.param", "flow parameter as name=value, can be repeated")
+}
+
+func (p paramFlag) String() string {
+	var kvs []string
+	for k, v := range p {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
+func (p paramFlag) Set(kv string) error {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expecting name=value, but got %s", kv)
+	}
+	p[parts[0]] = parts[1]
+	return nil
+}
+
+var paramPlaceholder = regexp.MustCompile(`\{\{\s*[A-Za-z_][A-Za-z0-9_.-]*\s*\}\}`)
+
+// Param declares a flow parameter, and returns its value for this submission.
+// The value comes from the "-flow.param name=value" flag, which gio.Init() parses,
+// or else the environment variable GLEAM_PARAM_<NAME> set by a scheduler,
+// or else the default value.
+//
+// Once declared, "{{name}}" placeholders are substituted in file patterns,
+// Pipe() commands and SQL text, so the same flow binary can run for
+// different dates or tenants. The values are quoted as one shell word in the
+// commands, and as a string literal in SQL, so the placeholders should not be
+// quoted again.
+func (fc *Flow) Param(name, defaultValue string) string {
+	value, found := paramFlagValues[name]
+	if !found {
+		value, found = os.LookupEnv("GLEAM_PARAM_" + strings.ToUpper(strings.Replace(name, ".", "_", -1)))
+	}
+	if !found {
+		value = defaultValue
+	}
+	if fc.Params == nil {
+		fc.Params = make(map[string]string)
+	}
+	fc.Params[name] = value
+	return value
+}
+
+// Expand substitutes the "{{name}}" placeholders of the declared parameters
+// by their values as is, e.g. in file patterns.
+// Placeholders of undeclared names are kept as is.
+func (fc *Flow) Expand(text string) string {
+	return fc.expand(text, nil)
+}
+
+// ExpandShell substitutes the placeholders by their values quoted as one
+// shell word, so the values can not run other commands.
+func (fc *Flow) ExpandShell(text string) string {
+	return fc.expand(text, func(value string) string {
+		return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+	})
+}
+
+// ExpandSQL substitutes the placeholders by their values as SQL string literals.
+func (fc *Flow) ExpandSQL(text string) string {
+	return fc.expand(text, func(value string) string {
+		value = strings.Replace(value, `\`, `\\`, -1)
+		return "'" + strings.Replace(value, "'", "''", -1) + "'"
+	})
+}
+
+func (fc *Flow) expand(text string, quote func(string) string) string {
+	if len(fc.Params) == 0 || !strings.Contains(text, "{{") {
+		return text
+	}
+	return paramPlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := strings.TrimSpace(placeholder[2 : len(placeholder)-2])
+		value, found := fc.Params[name]
+		if !found {
+			return placeholder
+		}
+		if quote != nil {
+			return quote(value)
+		}
+		return value
+	})
+}
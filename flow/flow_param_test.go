@@ -0,0 +1,21 @@
+package flow
+
+import (
+	"testing"
+)
+
+func TestExpandParams(t *testing.T) {
+	fc := New("testExpandParams")
+	fc.Param("date", "2017-01-01")
+	fc.Param("tenant", "it's; rm -rf /")
+
+	if got := fc.Expand("/logs/{{date}}/*.csv"); got != "/logs/2017-01-01/*.csv" {
+		t.Errorf("expanded file pattern: %s", got)
+	}
+	if got := fc.ExpandShell("grep {{ tenant }}"); got != `grep 'it'\''s; rm -rf /'` {
+		t.Errorf("expanded command: %s", got)
+	}
+	if got := fc.ExpandSQL(`select * from t where tenant = {{tenant}} and note = {{missing}}`); got != `select * from t where tenant = 'it''s; rm -rf /' and note = {{missing}}` {
+		t.Errorf("expanded sql: %s", got)
+	}
+}
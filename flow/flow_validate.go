@@ -0,0 +1,153 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/instruction"
+)
+
+// ValidationError is a problem of a step, or of the whole flow if Step is
+// nil, found by Flow.Validate().
+type ValidationError struct {
+	Step    *Step
+	Problem string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Step == nil {
+		return e.Problem
+	}
+	return fmt.Sprintf("step %d %s: %s", e.Step.Id, e.Step.Name, e.Problem)
+}
+
+// ValidationErrors are all the problems found by Flow.Validate().
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	var problems []string
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+	}
+	return fmt.Sprintf("%d problems: %s", len(errs), strings.Join(problems, "; "))
+}
+
+// Validate checks the flow before running it with the options, as Run()
+// would, so the mistakes are reported by the driver instead of failing the
+// executors. It returns ValidationErrors, or nil if no problem is found:
+//
+//   - the Go mappers and reducers not registered, or gio.Init() not called
+//   - the steps joining datasets of other shard counts
+//   - the fields read by index beyond the rows hinted by FieldCount()
+//   - the steps without inputs producing no rows, and the outputs without inputs
+//   - the datasets of other flows, not run by the agents
+//   - the flows without outputs, which run nothing locally
+//   - the secrets, only provided by the agents to the steps they run
+func (fc *Flow) Validate(options ...FlowOption) error {
+	distributed := false
+	for _, option := range options {
+		if option.GetFlowRunner() != FlowRunner(Local) {
+			distributed = true
+		}
+	}
+
+	var errs ValidationErrors
+	problem := func(step *Step, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Step: step, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	hasGoCode, hasOutput := false, false
+	for _, step := range fc.Steps {
+		if step.IsGoCode {
+			hasGoCode = true
+			if mapperId := commandFlag(step, "-gleam.mapper"); mapperId != "" {
+				if _, found := gio.GetMapper(gio.MapperId(mapperId)); !found {
+					problem(step, "mapper %s is not registered by gio.RegisterMapper()", mapperId)
+				}
+			}
+			if reducerId := commandFlag(step, "-gleam.reducer"); reducerId != "" {
+				if _, found := gio.GetReducer(gio.ReducerId(reducerId)); !found {
+					problem(step, "reducer %s is not registered by gio.RegisterReducer()", reducerId)
+				}
+			}
+		}
+
+		if step.NetworkType == MergeTwoShardToOneShard && step.OutputDataset != nil {
+			for _, input := range step.InputDatasets {
+				if len(input.Shards) != len(step.OutputDataset.Shards) {
+					problem(step, "dataset d%d has %d shards, not %d like the other inputs", input.Id, len(input.Shards), len(step.OutputDataset.Shards))
+				}
+			}
+		}
+
+		if step.Instruction != nil {
+			for i, indexes := range instruction.InputFieldIndexes(step.Instruction, len(step.InputDatasets)) {
+				if i >= len(step.InputDatasets) || step.InputDatasets[i] == nil {
+					break
+				}
+				input := step.InputDatasets[i]
+				for _, index := range indexes {
+					if fieldCount := input.Meta.FieldCount; fieldCount > 0 && index > fieldCount {
+						problem(step, "field %d is read from dataset d%d, whose rows have %d fields", index, input.Id, fieldCount)
+					}
+				}
+			}
+		}
+
+		if len(step.InputDatasets) == 0 {
+			if step.OutputDataset == nil {
+				problem(step, "the output has no input dataset")
+			} else if step.Function == nil && step.Command == nil && step.Script == nil {
+				problem(step, "the source produces no rows")
+			}
+		}
+		for _, input := range step.InputDatasets {
+			if input == nil || input.Step == nil {
+				problem(step, "an input dataset is not produced by any step")
+			} else if input.Flow != fc && distributed {
+				problem(step, "dataset d%d of flow %s is not run by the agents of this flow", input.Id, input.Flow.Name)
+			}
+		}
+		if step.OutputDataset == nil {
+			hasOutput = true
+		}
+
+		if len(step.Secrets) > 0 {
+			if !distributed {
+				problem(step, "secrets are only provided to the steps run by agents, not locally")
+			} else if step.IsOnDriverSide {
+				problem(step, "secrets are only provided to the steps run by agents, not on the driver")
+			}
+		}
+	}
+
+	if hasGoCode && !gio.HasInitalized {
+		problem(nil, "gio.Init() is required right after main() if pure go mapper or reducer is used")
+	}
+	if !hasOutput && !distributed && len(fc.Steps) > 0 {
+		problem(nil, "the flow has no output, e.g. Fprintf() or Output(), so no step runs locally")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// commandFlag returns the value of the last flag of the step's command.
+func commandFlag(step *Step, flag string) (value string) {
+	if step.Command == nil {
+		return ""
+	}
+	args := step.Command.Args
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			value = args[i+1]
+		}
+	}
+	return
+}
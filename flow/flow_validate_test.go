@@ -0,0 +1,101 @@
+package flow
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+// remoteRunner validates the flows as run by agents, without running them.
+type remoteRunner struct{}
+
+func (r remoteRunner) GetFlowRunner() FlowRunner                    { return r }
+func (r remoteRunner) RunFlowContext(ctx context.Context, fc *Flow) {}
+
+func TestValidate(t *testing.T) {
+	rows := [][]interface{}{{"a", 1}, {"b", 2}}
+
+	tests := []struct {
+		name     string
+		build    func(f *Flow)
+		options  []FlowOption
+		problems []string
+	}{
+		{
+			name: "valid",
+			build: func(f *Flow) {
+				f.Slices(rows).Hint(FieldCount(2)).Sort("sort", Field(2)).Fprintf(ioutil.Discard, "%v %v\n")
+			},
+		},
+		{
+			name: "unregistered mapper",
+			build: func(f *Flow) {
+				f.Slices(rows).Map("map", gio.MapperId("unregistered")).Fprintf(ioutil.Discard, "%v\n")
+			},
+			problems: []string{"mapper unregistered is not registered"},
+		},
+		{
+			name: "field beyond the hinted fields",
+			build: func(f *Flow) {
+				f.Slices(rows).Hint(FieldCount(2)).Sort("sort", Field(3)).Fprintf(ioutil.Discard, "%v %v\n")
+			},
+			problems: []string{"field 3 is read from dataset d0, whose rows have 2 fields"},
+		},
+		{
+			name: "join keys beyond the hinted fields",
+			build: func(f *Flow) {
+				left := f.Slices(rows).Hint(FieldCount(1))
+				right := f.Slices(rows).Hint(FieldCount(2))
+				left.Join("join", right, Field(2)).Fprintf(ioutil.Discard, "%v %v\n")
+			},
+			problems: []string{"whose rows have 1 fields"},
+		},
+		{
+			name: "no output",
+			build: func(f *Flow) {
+				f.Slices(rows).Sort("sort", Field(1))
+			},
+			problems: []string{"the flow has no output"},
+		},
+		{
+			name: "secrets run locally",
+			build: func(f *Flow) {
+				f.Slices(rows).Hint(Secret("TOKEN", "token")).Fprintf(ioutil.Discard, "%v %v\n")
+			},
+			problems: []string{"secrets are only provided to the steps run by agents, not locally"},
+		},
+		{
+			name: "dataset of another flow run by agents",
+			build: func(f *Flow) {
+				others := []*Dataset{New("other").Slices(rows)}
+				f.Slices(rows).Union("union", others, false).Fprintf(ioutil.Discard, "%v %v\n")
+			},
+			options:  []FlowOption{remoteRunner{}},
+			problems: []string{"of flow other is not run by the agents of this flow"},
+		},
+	}
+	for _, test := range tests {
+		f := New("testValidate")
+		test.build(f)
+		err := f.Validate(test.options...)
+		if len(test.problems) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Errorf("%s: validated with %v, expected ValidationErrors", test.name, err)
+			continue
+		}
+		for _, problem := range test.problems {
+			if !strings.Contains(errs.Error(), problem) {
+				t.Errorf("%s: %v, expected %q", test.name, errs, problem)
+			}
+		}
+	}
+}
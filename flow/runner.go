@@ -2,14 +2,15 @@ package flow
 
 import (
 	"context"
+	"fmt"
 	"io"
-	"log"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
+	"github.com/lovelly/gleam/util/logger"
 	"github.com/lovelly/gleam/util/on_interrupt"
 )
 
@@ -38,10 +39,11 @@ func (r *localDriver) GetFlowRunner() FlowRunner {
 }
 
 func (r *localDriver) RunFlowContext(ctx context.Context, fc *Flow) {
-	r.ctx = ctx
+	// flows may run concurrently, each with its own context
+	run := &localDriver{ctx: ctx}
 	var wg sync.WaitGroup
 	wg.Add(1)
-	r.RunFlowAsync(&wg, fc)
+	run.RunFlowAsync(&wg, fc)
 	wg.Wait()
 }
 
@@ -58,6 +60,15 @@ func (r *localDriver) RunFlowAsync(wg *sync.WaitGroup, fc *Flow) {
 			}(step)
 		}
 	}
+	// the cached datasets without readers run for their caches
+	for _, ds := range fc.Datasets {
+		if ds.Meta.CacheName != "" && len(ds.ReadingSteps) == 0 {
+			wg.Add(1)
+			go func(ds *Dataset) {
+				r.runDataset(wg, ds)
+			}(ds)
+		}
+	}
 }
 
 func (r *localDriver) runDataset(wg *sync.WaitGroup, d *Dataset) {
@@ -70,6 +81,16 @@ func (r *localDriver) runDataset(wg *sync.WaitGroup, d *Dataset) {
 	}
 	d.StartTime = time.Now()
 
+	if cached := d.cachedShards(); cached != nil {
+		for i, shard := range d.Shards {
+			wg.Add(1)
+			go func(shard *DatasetShard, cached *cachedShard) {
+				r.runCachedDatasetShard(wg, shard, cached)
+			}(shard, cached[i])
+		}
+		return
+	}
+
 	for _, shard := range d.Shards {
 		wg.Add(1)
 		go func(shard *DatasetShard) {
@@ -89,15 +110,53 @@ func (r *localDriver) runDatasetShard(wg *sync.WaitGroup, shard *DatasetShard) {
 	for _, outgoingChan := range shard.OutgoingChans {
 		writers = append(writers, outgoingChan.Writer)
 	}
+	var capture *shardCapture
+	if shard.Dataset.Meta.CacheName != "" {
+		capture = newShardCapture(shard)
+		writers = append(writers, capture)
+	}
 
+	var err error
 	util.BufWrites(writers, func(writers []io.Writer) {
 		w := io.MultiWriter(writers...)
-		n, _ := io.Copy(w, shard.IncomingChan.Reader)
+		var n int64
+		n, err = io.Copy(w, shard.IncomingChan.Reader)
 		// println("shard", shard.Name(), "moved", n, "bytes.")
 		shard.Counter = n
 		shard.CloseTime = time.Now()
 	})
 
+	for _, outgoingChan := range shard.OutgoingChans {
+		// pass the failure of the step on to the readers
+		outgoingChan.Writer.CloseWithError(err)
+	}
+	if capture != nil {
+		capture.commit(err)
+	}
+}
+
+// runCachedDatasetShard writes the cached shard to its readers, instead of
+// running the step of the dataset.
+func (r *localDriver) runCachedDatasetShard(wg *sync.WaitGroup, shard *DatasetShard, cached *cachedShard) {
+	defer wg.Done()
+	shard.ReadyTime = time.Now()
+
+	var writers []io.Writer
+	for _, outgoingChan := range shard.OutgoingChans {
+		writers = append(writers, outgoingChan.Writer)
+	}
+
+	reader, err := cached.open()
+	if err != nil {
+		shardLogger(shard).Errorf("Failed to read cached shard %s: %v", shard.Name(), err)
+	} else {
+		util.BufWrites(writers, func(writers []io.Writer) {
+			shard.Counter, _ = io.Copy(io.MultiWriter(writers...), reader)
+		})
+		reader.Close()
+	}
+	shard.CloseTime = time.Now()
+
 	for _, outgoingChan := range shard.OutgoingChans {
 		outgoingChan.Writer.Close()
 	}
@@ -143,19 +202,61 @@ func (r *localDriver) runTask(wg *sync.WaitGroup, task *Task) {
 	// get an exec.Command
 	scriptCommand := task.Step.GetScriptCommand()
 	execCommand := scriptCommand.ToOsExecCommand()
+	if task.Step.IsGoCode {
+		execCommand.Args = append(execCommand.Args,
+			"-flow.hashcode", fmt.Sprint(task.Step.Flow.HashCode),
+			"-flow.stepId", fmt.Sprint(task.Step.Id),
+			"-flow.taskId", fmt.Sprint(task.Id))
+	}
+	if scriptCommand.Arg != "" {
+		argFile, err := scriptCommand.WriteArgFile("")
+		if err != nil {
+			taskLogger(task).Errorf("Failed to write the arguments of task %s-%d: %v", task.Step.Name, task.Id, err)
+			task.OutputShards[0].IncomingChan.Writer.CloseWithError(err)
+			return
+		}
+		defer os.Remove(argFile)
+		execCommand.Args = append(execCommand.Args, "-gleam.mapperArgFile", argFile)
+	}
 
 	if task.Step.NetworkType == OneShardToOneShard {
 		// fmt.Printf("execCommand: %+v\n", execCommand)
 		reader := task.InputChans[0].Reader
-		writer := task.OutputShards[0].IncomingChan.Writer
+		var writer io.Writer = task.OutputShards[0].IncomingChan.Writer
 		wg.Add(1)
 		prevIsPipe := task.InputShards[0].Dataset.Step.IsPipe
 		task.Stat = &pb.InstructionStat{}
-		err := util.Execute(r.ctx, wg, task.Stat, task.Step.Name, execCommand, reader, writer, prevIsPipe, task.Step.IsPipe, true, os.Stderr)
+		if task.Step.PeekCount > 0 {
+			writer = util.PeekWrites([]io.Writer{writer}, task.Step.PeekCount, task.Step.IsPipe, task.Stat, nil)[0]
+		}
+		err := util.Execute(r.ctx, wg, task.Stat, task.Step.Name, execCommand, reader, writer, prevIsPipe, task.Step.IsPipe, false, os.Stderr)
 		if err != nil {
-			log.Println(err.Error())
+			taskLogger(task).Errorf("Failed to run task %s-%d: %v", task.Step.Name, task.Id, err)
+			// the readers fail instead of taking the partial outputs as complete
+			task.OutputShards[0].IncomingChan.Writer.CloseWithError(err)
+		}
+		if c, ok := writer.(io.Closer); ok {
+			c.Close()
 		}
 	} else {
-		println("network type:", task.Step.NetworkType)
+		taskLogger(task).Errorf("Task %s-%d has unsupported network type %v", task.Step.Name, task.Id, task.Step.NetworkType)
+	}
+}
+
+// taskLogger returns a logger adding the flow, step and task ids of the task.
+func taskLogger(task *Task) *logger.Logger {
+	return logger.ForTask(task.Step.Flow.HashCode, int32(task.Step.Id), int32(task.Id))
+}
+
+// shardLogger returns a logger adding the ids of the task writing the shard.
+func shardLogger(shard *DatasetShard) *logger.Logger {
+	step := shard.Dataset.Step
+	for _, task := range step.Tasks {
+		for _, s := range task.OutputShards {
+			if s == shard {
+				return taskLogger(task)
+			}
+		}
 	}
+	return logger.ForFlow(step.Flow.HashCode).With("step", int32(step.Id))
 }
@@ -2,7 +2,6 @@ package flow
 
 import (
 	"io"
-	"log"
 	"os"
 
 	"github.com/lovelly/gleam/instruction"
@@ -14,6 +13,7 @@ import (
 func (fc *Flow) NewStep() (step *Step) {
 	step = &Step{
 		Id:     len(fc.Steps),
+		Flow:   fc,
 		Params: make(map[string]interface{}),
 		Meta:   &StepMetadata{IsIdempotent: true},
 	}
@@ -51,11 +51,18 @@ func (step *Step) RunFunction(task *Task) error {
 	}
 
 	if task.Stat == nil {
-		task.Stat = &pb.InstructionStat{}
+		task.Stat = &pb.InstructionStat{StepId: int32(step.Id), TaskId: int32(task.Id)}
+	}
+	if step.PeekCount > 0 {
+		writers = util.PeekWrites(writers, step.PeekCount, false, task.Stat, nil)
 	}
 	err := task.Step.Function(readers, writers, task.Stat)
 	if err != nil {
-		log.Printf("Failed to run task %s-%d: %v\n", task.Step.Name, task.Id, err)
+		taskLogger(task).Errorf("Failed to run task %s-%d: %v", task.Step.Name, task.Id, err)
+		// the readers fail instead of taking the partial outputs as complete
+		for _, shard := range task.OutputShards {
+			shard.IncomingChan.Writer.CloseWithError(err)
+		}
 	}
 
 	for _, writer := range writers {
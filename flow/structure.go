@@ -42,8 +42,14 @@ const (
 )
 
 type DasetsetMetadata struct {
-	TotalSize int64
-	OnDisk    ModeIO
+	TotalSize   int64
+	OnDisk      ModeIO
+	IsSizeKnown bool       // TotalSize is hinted or measured, not summed up from the inputs
+	FieldCount  int        // the number of fields of the rows, if hinted
+	CacheName   string     // the shards are kept by this name after the flow, set by Cache()
+	CacheMode   ModeIO     // where local flows keep the cached shards
+	Compression string     // the codec of the on disk shards, set by the Compression() hint
+	Bucketing   *Bucketing // how the shards are bucketed, set by BucketBy()
 }
 
 type DasetsetShardMetadata struct {
@@ -64,6 +70,12 @@ type Flow struct {
 	Steps    []*Step
 	Datasets []*Dataset
 	HashCode uint32
+	Params   map[string]string // declared by Param()
+
+	onSuccess []func() error // added by OnSuccess()
+
+	failureLock sync.Mutex
+	failure     error // the first failure of the Output() steps
 }
 
 type Dataset struct {
@@ -108,6 +120,12 @@ type Step struct {
 	Command        *script.Command // used in Pipe()
 	Meta           *StepMetadata
 	Params         map[string]interface{}
+	Secrets        map[string]string // env name => secret name, set by executors
+	PeekCount      int               // rows of each task output copied to Task.Stat, set by Peek()
+	NodeSelector   []string          // agent labels required by the tasks, as key=value
+	Tolerations    []string          // agent taints accepted by the tasks, as key or key=value
+	MaxMemoryMB    int               // most memory requested for each task, 0 for no limit, set by MaxMemoryMB()
+	InputRowLimit  int               // leading rows read from each input shard, 0 for all, set by LocalLimit()
 	RunLocked
 }
 
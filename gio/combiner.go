@@ -0,0 +1,118 @@
+package gio
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// EncodeSeed encodes the seed of a combiner for the -gleam.seed option.
+func EncodeSeed(seed interface{}) (string, error) {
+	data, err := util.NewRow(0, seed).MarshalMsg(nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode seed %v: %v", seed, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// seedDecoder returns a function decoding the seed again for each call, so
+// each group of rows folds into its own copy of the seed, also of the maps
+// and slices in it.
+func seedDecoder(encoded string) (func() (interface{}, error), error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode seed %s: %v", encoded, err)
+	}
+	newSeed := func() (interface{}, error) {
+		row := &util.Row{}
+		if _, err := row.UnmarshalMsg(data); err != nil {
+			return nil, fmt.Errorf("Failed to decode seed %s: %v", encoded, err)
+		}
+		if len(row.K) != 1 {
+			return nil, fmt.Errorf("Failed to decode seed %s: %d values", encoded, len(row.K))
+		}
+		return row.K[0], nil
+	}
+	if _, err := newSeed(); err != nil {
+		return nil, err
+	}
+	return newSeed, nil
+}
+
+// processCombiner folds the values of each group of rows with the same keys
+// into an accumulator starting from the seed, f(accumulator, values). The
+// values are the only value of the row, or all values as []interface{}. An
+// accumulator of []interface{} is emitted as several values.
+func (runner *gleamRunner) processCombiner(ctx context.Context, f Reducer, keyPositions []int, encodedSeed string) (err error) {
+	newSeed, err := seedDecoder(encodedSeed)
+	if err != nil {
+		return err
+	}
+	return runner.report(ctx, func() error {
+		return runCombiner(f, keyPositions, newSeed, os.Stdin, os.Stdout)
+	})
+}
+
+func runCombiner(f Reducer, keyPositions []int, newSeed func() (interface{}, error), reader io.Reader, writer io.Writer) (err error) {
+
+	var lastTs int64
+	var lastKeys []interface{}
+	var accumulator interface{}
+	hasRows := false
+
+	for {
+		row, err := util.ReadRow(reader)
+		if err != nil {
+			if err != io.EOF {
+				return fmt.Errorf("combiner input row error: %v", err)
+			}
+			break
+		}
+		stat.Stats[0].InputCounter++
+
+		row.UseKeys(keyPositions)
+		if hasRows && util.Compare(lastKeys, row.K) != 0 {
+			if err := emitCombined(writer, lastTs, lastKeys, accumulator); err != nil {
+				return fmt.Errorf("combiner output row error: %v", err)
+			}
+			hasRows = false
+		}
+		if !hasRows {
+			if accumulator, err = newSeed(); err != nil {
+				return err
+			}
+			lastTs, lastKeys, hasRows = row.T, row.K, true
+		}
+
+		var values interface{} = row.V
+		if len(row.V) == 1 {
+			values = row.V[0]
+		}
+		if accumulator, err = f(accumulator, values); err != nil {
+			return fmt.Errorf("combiner error: %v", err)
+		}
+		if row.T > lastTs {
+			lastTs = row.T
+		}
+	}
+	if hasRows {
+		if err := emitCombined(writer, lastTs, lastKeys, accumulator); err != nil {
+			return fmt.Errorf("combiner output row error: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func emitCombined(writer io.Writer, ts int64, keys []interface{}, accumulator interface{}) error {
+	values, ok := accumulator.([]interface{})
+	if !ok {
+		values = []interface{}{accumulator}
+	}
+	stat.Stats[0].OutputCounter++
+	return util.NewRow(ts).AppendKey(keys...).AppendValue(values...).WriteTo(writer)
+}
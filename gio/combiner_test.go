@@ -0,0 +1,106 @@
+package gio
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// failingWriter fails every write, as a closed output.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("output closed") }
+
+func TestRunCombiner(t *testing.T) {
+	var input bytes.Buffer
+	for i, row := range [][]interface{}{{"a", "x"}, {"a", "y"}, {"a", "x"}, {"b", "z"}} {
+		util.NewRow(int64(i+1), row...).WriteTo(&input)
+	}
+	truncated := append(append([]byte(nil), input.Bytes()...), 0, 0, 0, 9, 'x')
+
+	// counts the values of each key in a map, which is shared if the seed is
+	countValues := func(accumulator, value interface{}) (interface{}, error) {
+		counts := accumulator.(map[string]interface{})
+		counts[ToString(value)] = ToInt64(counts[ToString(value)]) + 1
+		return accumulator, nil
+	}
+	// counts and sums the lengths of the values in a seed of several values
+	sumLengths := func(accumulator, value interface{}) (interface{}, error) {
+		x := accumulator.([]interface{})
+		x[0], x[1] = ToInt64(x[0])+1, ToInt64(x[1])+int64(len(ToString(value)))
+		return x, nil
+	}
+	failing := func(accumulator, value interface{}) (interface{}, error) {
+		return nil, errors.New("bad value")
+	}
+
+	tests := []struct {
+		name     string
+		fn       Reducer
+		seed     interface{}
+		input    []byte
+		expected []string
+		problem  string
+	}{
+		{"map seed", countValues, map[string]interface{}{}, input.Bytes(), []string{"3 [a map[x:2 y:1]]", "4 [b map[z:1]]"}, ""},
+		{"several values", sumLengths, []interface{}{0, 0}, input.Bytes(), []string{"3 [a 3 3]", "4 [b 1 1]"}, ""},
+		{"empty", sumLengths, []interface{}{0, 0}, nil, nil, ""},
+		{"truncated input", countValues, map[string]interface{}{}, truncated, nil, "combiner input row error"},
+		{"combiner error", failing, 0, input.Bytes(), nil, "bad value"},
+	}
+	for _, test := range tests {
+		encoded, err := EncodeSeed(test.seed)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		newSeed, err := seedDecoder(encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var out bytes.Buffer
+		var rows []string
+		withStats(func() {
+			if err = runCombiner(test.fn, []int{1}, newSeed, bytes.NewReader(test.input), &out); err == nil {
+				rows = readRows(t, out.Bytes())
+			}
+		})
+		if test.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("%s: failed with %v, expected %q", test.name, err, test.problem)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		sort.Strings(rows)
+		if !reflect.DeepEqual(rows, test.expected) {
+			t.Errorf("%s: combined to %v, expected %v", test.name, rows, test.expected)
+		}
+	}
+
+	newSeed, _ := seedDecoder(mustEncodeSeed(t, []interface{}{0, 0}))
+	withStats(func() {
+		err := runCombiner(sumLengths, []int{1}, newSeed, bytes.NewReader(input.Bytes()), failingWriter{})
+		if err == nil || !strings.Contains(err.Error(), "output closed") {
+			t.Errorf("combined to a closed output with %v", err)
+		}
+	})
+	if _, err := seedDecoder("not base64!"); err == nil {
+		t.Errorf("decoded an invalid seed")
+	}
+}
+
+func mustEncodeSeed(t *testing.T, seed interface{}) string {
+	encoded, err := EncodeSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
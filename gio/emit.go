@@ -11,6 +11,13 @@ func Emit(anyObject ...interface{}) error {
 	return TsEmit(util.Now(), anyObject...)
 }
 
+// EmitTo writes a row tagged for the side output of the tag, counting from
+// 0, for Dataset.Split(), which splits the rows of the mapper into datasets
+// by their tags.
+func EmitTo(tag int, anyObject ...interface{}) error {
+	return Emit(append([]interface{}{tag}, anyObject...)...)
+}
+
 // TsEmit encode and write a row of data to os.Stdout
 // with ts in milliseconds epoch time
 func TsEmit(ts int64, anyObject ...interface{}) error {
@@ -18,6 +25,12 @@ func TsEmit(ts int64, anyObject ...interface{}) error {
 	return util.NewRow(ts, anyObject...).WriteTo(os.Stdout)
 }
 
+// SetLag reports how far the task is behind the end of its input, e.g. the
+// unread messages of a streaming source, in the stats of the task.
+func SetLag(lag int64) {
+	stat.Stats[0].Lag = lag
+}
+
 func TsEmitKV(ts int64, keys, values []interface{}) error {
 	stat.Stats[0].OutputCounter++
 	return util.NewRow(ts).AppendKey(keys...).AppendValue(values...).WriteTo(os.Stdout)
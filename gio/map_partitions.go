@@ -0,0 +1,103 @@
+package gio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// PartitionMapper processes all rows of a partition in one call, e.g. to
+// batch the calls to other services, or to keep state across the rows,
+// such as a bloom filter. It reads the rows from the iterator, and writes
+// its rows to the writer.
+type PartitionMapper func(rows *RowIterator, out *RowWriter) error
+
+// RowIterator reads the rows of the partition given to a PartitionMapper.
+type RowIterator struct {
+	reader io.Reader
+	row    []interface{}
+	ts     int64
+	err    error
+}
+
+// Next reads the next row. It returns false after the last row, or if
+// reading failed, as reported by Err().
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	row, err := util.ReadRow(it.reader)
+	if err != nil {
+		if err != io.EOF {
+			it.err = fmt.Errorf("partition mapper input row error: %v", err)
+		}
+		it.row = nil
+		return false
+	}
+	stat.Stats[0].InputCounter++
+	it.row = append(append(it.row[:0], row.K...), row.V...)
+	it.ts = row.T
+	return true
+}
+
+// Row returns the fields of the current row, keys first. The slice is
+// reused by Next().
+func (it *RowIterator) Row() []interface{} {
+	return it.row
+}
+
+// Timestamp returns the timestamp of the current row.
+func (it *RowIterator) Timestamp() int64 {
+	return it.ts
+}
+
+// Err returns the error which stopped Next(), if any.
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// RowWriter writes the rows of a PartitionMapper.
+type RowWriter struct {
+	writer io.Writer
+}
+
+// Emit writes a row of the fields.
+func (w *RowWriter) Emit(fields ...interface{}) error {
+	return w.TsEmit(util.Now(), fields...)
+}
+
+// TsEmit writes a row of the fields, with the timestamp in milliseconds.
+func (w *RowWriter) TsEmit(ts int64, fields ...interface{}) error {
+	stat.Stats[0].OutputCounter++
+	return util.NewRow(ts, fields...).WriteTo(w.writer)
+}
+
+// RegisterPartitionMapper registers a function processing whole partitions,
+// for Dataset.MapPartitions().
+func RegisterPartitionMapper(fn PartitionMapper) MapperId {
+	return registerMapper(MapperObject{PartitionMapper: fn, Name: funcName(fn)})
+}
+
+func (runner *gleamRunner) processPartitionMapper(ctx context.Context, m MapperObject) (err error) {
+	return runner.report(ctx, func() error {
+		if err := runPartitionMapper(m.PartitionMapper, os.Stdin, os.Stdout); err != nil {
+			return fmt.Errorf("processing error: %v", err)
+		}
+		return nil
+	})
+}
+
+// runPartitionMapper maps the rows read from the reader to the writer. It
+// fails with the error of the mapper, or else of reading the rows, which the
+// mapper may not check.
+func runPartitionMapper(fn PartitionMapper, reader io.Reader, writer io.Writer) error {
+	rows := &RowIterator{reader: reader}
+	err := fn(rows, &RowWriter{writer: writer})
+	if err == nil {
+		err = rows.Err()
+	}
+	return err
+}
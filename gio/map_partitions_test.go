@@ -0,0 +1,123 @@
+package gio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// withStats counts the rows of a task, as set up by the runner.
+func withStats(f func()) *pb.InstructionStat {
+	defer func(old []*pb.InstructionStat) { stat.Stats = old }(stat.Stats)
+	stat.Stats = []*pb.InstructionStat{{}}
+	f()
+	return stat.Stats[0]
+}
+
+// readRows reads the rows written by a RowWriter, as "ts fields".
+func readRows(t *testing.T, data []byte) (rows []string) {
+	it := &RowIterator{reader: bytes.NewReader(data)}
+	for it.Next() {
+		rows = append(rows, fmt.Sprintf("%d %v", it.Timestamp(), it.Row()))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read rows: %v", err)
+	}
+	return
+}
+
+func TestRowIteratorAndWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var rows []string
+	stats := withStats(func() {
+		w := &RowWriter{writer: &buf}
+		if err := w.TsEmit(1, "a", 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.TsEmit(2, "b", []interface{}{"c", 3}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.TsEmit(3); err != nil {
+			t.Fatal(err)
+		}
+		rows = readRows(t, buf.Bytes())
+	})
+	if expected := []string{"1 [a 1]", "2 [b [c 3]]", "3 []"}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("read %v, expected %v", rows, expected)
+	}
+	if stats.InputCounter != 3 || stats.OutputCounter != 3 {
+		t.Errorf("counted %d in and %d out", stats.InputCounter, stats.OutputCounter)
+	}
+}
+
+func TestRunPartitionMapper(t *testing.T) {
+	var input bytes.Buffer
+	for i := 1; i <= 3; i++ {
+		util.NewRow(int64(i), "k", i).WriteTo(&input)
+	}
+	truncated := append([]byte(nil), input.Bytes()...)
+	truncated = append(truncated, 0, 0, 0, 9, 'x')
+
+	// sums up the values of the partition, in one row
+	sum := RegisterPartitionMapper(func(rows *RowIterator, out *RowWriter) error {
+		var total int64
+		for rows.Next() {
+			total += ToInt64(rows.Row()[1])
+		}
+		return out.TsEmit(0, total)
+	})
+	failing := RegisterPartitionMapper(func(rows *RowIterator, out *RowWriter) error {
+		rows.Next()
+		return errors.New("service unavailable")
+	})
+	mapper, found := GetMapper(sum)
+	if !found || mapper.PartitionMapper == nil || mapper.Mapper != nil {
+		t.Fatalf("registered the partition mapper as %+v", mapper)
+	}
+	if other := RegisterMapper(func(row []interface{}) error { return nil }); other == sum || other == failing {
+		t.Errorf("registered the mapper as %s again", other)
+	}
+	failingMapper, _ := GetMapper(failing)
+
+	tests := []struct {
+		name     string
+		fn       PartitionMapper
+		input    []byte
+		expected []string
+		problem  string
+	}{
+		{"mapped", mapper.PartitionMapper, input.Bytes(), []string{"0 [6]"}, ""},
+		{"empty", mapper.PartitionMapper, nil, []string{"0 [0]"}, ""},
+		{"truncated input", mapper.PartitionMapper, truncated, nil, "partition mapper input row error"},
+		{"mapper error", failingMapper.PartitionMapper, input.Bytes(), nil, "service unavailable"},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		var rows []string
+		var err error
+		withStats(func() {
+			if err = runPartitionMapper(test.fn, bytes.NewReader(test.input), &out); err == nil {
+				rows = readRows(t, out.Bytes())
+			}
+		})
+		if test.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("%s: failed with %v, expected %q", test.name, err, test.problem)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(rows, test.expected) {
+			t.Errorf("%s: mapped to %v, expected %v", test.name, rows, test.expected)
+		}
+	}
+}
@@ -18,8 +18,11 @@ type Reducer func(x, y interface{}) (interface{}, error)
 
 type gleamTaskOption struct {
 	Mapper          string
+	MapperArg       string
+	MapperArgFile   string
 	Reducer         string
 	KeyFields       string
+	Seed            string
 	ExecutorAddress string
 	HashCode        uint
 	StepId          int
@@ -40,8 +43,11 @@ var (
 
 func init() {
 	flag.StringVar(&taskOption.Mapper, "gleam.mapper", "", "the generated mapper name")
+	flag.StringVar(&taskOption.MapperArg, "gleam.mapperArg", "", "the argument of the mapper")
+	flag.StringVar(&taskOption.MapperArgFile, "gleam.mapperArgFile", "", "the file with the argument of the mapper")
 	flag.StringVar(&taskOption.Reducer, "gleam.reducer", "", "the generated reducer name")
 	flag.StringVar(&taskOption.KeyFields, "gleam.keyFields", "", "the 1-based key fields")
+	flag.StringVar(&taskOption.Seed, "gleam.seed", "", "the encoded seed, to run the reducer as a combiner")
 	flag.StringVar(&taskOption.ExecutorAddress, "gleam.executor", "", "executor address")
 	flag.UintVar(&taskOption.HashCode, "flow.hashcode", 0, "flow hashcode")
 	flag.IntVar(&taskOption.StepId, "flow.stepId", -1, "flow step id")
@@ -57,8 +63,11 @@ var (
 )
 
 type MapperObject struct {
-	Mapper Mapper
-	Name   string
+	Mapper          Mapper
+	Name            string
+	Closer          func(err error) error // called after the last row or an error, if set
+	PartitionMapper PartitionMapper       // set instead of Mapper by RegisterPartitionMapper()
+	Predicate       Predicate             // set instead of Mapper by RegisterPredicate()
 }
 
 type ReducerObject struct {
@@ -73,15 +82,42 @@ func init() {
 
 // RegisterMapper register a mapper function to process a command
 func RegisterMapper(fn Mapper) MapperId {
+	return registerMapper(MapperObject{Mapper: fn, Name: funcName(fn)})
+}
+
+// registerMapper registers the mapper by the next mapper id.
+func registerMapper(mapper MapperObject) MapperId {
 	mappersLock.Lock()
 	defer mappersLock.Unlock()
 
 	mapperId := MapperId(fmt.Sprintf("m%d", len(mappers)+1))
-	mappers[mapperId] = MapperObject{fn, runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()}
+	mappers[mapperId] = mapper
 
 	return mapperId
 }
 
+func funcName(fn interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// RegisterMapperWithCloser registers a mapper function, and a function called
+// after the mapper processed all rows of a task, e.g. to close the files
+// written by the mapper. The closer gets the error if the processing failed,
+// and returns the error of the task, which may add to it.
+func RegisterMapperWithCloser(fn Mapper, closer func(err error) error) MapperId {
+	return registerMapper(MapperObject{Mapper: fn, Name: funcName(fn), Closer: closer})
+}
+
+// MapperArg returns the argument given to the mapper by Dataset.MapWithArg().
+func MapperArg() string {
+	return taskOption.MapperArg
+}
+
+// TaskId returns the id of the task run by the mapper or reducer.
+func TaskId() int {
+	return taskOption.TaskId
+}
+
 func GetMapper(mapperId MapperId) (mapper MapperObject, found bool) {
 	mappersLock.Lock()
 	defer mappersLock.Unlock()
@@ -91,12 +127,27 @@ func GetMapper(mapperId MapperId) (mapper MapperObject, found bool) {
 	return
 }
 
+// LookupMapper finds the mapper, partition mapper or predicate registered
+// with the function of the name, e.g. main.tokenize, for callers not
+// knowing the order of the registrations.
+func LookupMapper(name string) (mapperId MapperId, found bool) {
+	mappersLock.Lock()
+	defer mappersLock.Unlock()
+
+	for id, mapper := range mappers {
+		if mapper.Name == name {
+			return id, true
+		}
+	}
+	return
+}
+
 func RegisterReducer(fn Reducer) ReducerId {
 	reducersLock.Lock()
 	defer reducersLock.Unlock()
 
 	reducerId := ReducerId(fmt.Sprintf("r%d", len(reducers)+1))
-	reducers[reducerId] = ReducerObject{fn, runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()}
+	reducers[reducerId] = ReducerObject{fn, funcName(fn)}
 
 	return reducerId
 }
@@ -110,6 +161,19 @@ func GetReducer(reducerId ReducerId) (reducer ReducerObject, found bool) {
 	return
 }
 
+// LookupReducer finds the reducer registered with the function of the name.
+func LookupReducer(name string) (reducerId ReducerId, found bool) {
+	reducersLock.Lock()
+	defer reducersLock.Unlock()
+
+	for id, reducer := range reducers {
+		if reducer.Name == name {
+			return id, true
+		}
+	}
+	return
+}
+
 // Init determines whether the driver program will execute the mapper/reducer or not.
 // If the command line invokes the mapper or reducer, execute it and exit.
 // This function will invoke flag.Parse() first.
@@ -9,9 +9,17 @@ import (
 	"github.com/lovelly/gleam/util"
 )
 
-func (runner *gleamRunner) processMapper(ctx context.Context, f Mapper) (err error) {
+func (runner *gleamRunner) processMapper(ctx context.Context, m MapperObject) (err error) {
 	return runner.report(ctx, func() error {
-		return runner.doProcessMapper(ctx, f)
+		err := runner.doProcessMapper(ctx, m.Mapper)
+		if m.Closer != nil {
+			if closeErr := m.Closer(err); closeErr != nil && err == nil {
+				err = fmt.Errorf("closing error: %v", closeErr)
+			} else if closeErr != nil {
+				err = closeErr
+			}
+		}
+		return err
 	})
 }
 
@@ -0,0 +1,59 @@
+package gio
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// Predicate tells whether to keep a row, for Dataset.Filter().
+type Predicate func([]interface{}) (bool, error)
+
+// RegisterPredicate registers a function filtering the rows, for
+// Dataset.Filter().
+func RegisterPredicate(fn Predicate) MapperId {
+	return registerMapper(MapperObject{Predicate: fn, Name: funcName(fn)})
+}
+
+// processPredicate writes a byte for each row read, 1 to keep the row and 0
+// to drop it. The rows themselves stay with the Filter instruction.
+func (runner *gleamRunner) processPredicate(ctx context.Context, m MapperObject) (err error) {
+	return runner.report(ctx, func() error {
+		in := bufio.NewReader(os.Stdin)
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for {
+			// the Filter instruction waits for the verdicts of the rows sent
+			if !util.HasBufferedMessage(in) {
+				if err := out.Flush(); err != nil {
+					return err
+				}
+			}
+			row, err := util.ReadRow(in)
+			if err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("predicate input row error: %v", err)
+			}
+			stat.Stats[0].InputCounter++
+
+			keep, err := m.Predicate(append(row.K, row.V...))
+			if err != nil {
+				return fmt.Errorf("processing error: %v", err)
+			}
+			verdict := byte(0)
+			if keep {
+				stat.Stats[0].OutputCounter++
+				verdict = 1
+			}
+			if err := out.WriteByte(verdict); err != nil {
+				return err
+			}
+		}
+	})
+}
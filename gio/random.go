@@ -0,0 +1,48 @@
+package gio
+
+import (
+	"encoding/binary"
+	"math/rand"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/lovelly/gleam/util"
+)
+
+// The random functions here are seeded by the flow hashcode and the row
+// keys, instead of the time or the task. A retried or speculative task
+// makes the same choices for the same rows, so sampling or splitting rows
+// does not break exactly-once outputs. Different flows make different choices.
+
+// RandSeed returns a seed for the keys, stable within the flow.
+func RandSeed(keys ...interface{}) int64 {
+	h := xxhash.New64()
+	var hashCode [8]byte
+	binary.BigEndian.PutUint64(hashCode[:], uint64(taskOption.HashCode))
+	h.Write(hashCode[:])
+	if encodedKeys, err := util.EncodeKeys(keys...); err == nil {
+		h.Write(encodedKeys)
+	}
+	return int64(h.Sum64() & (1<<63 - 1))
+}
+
+// Rand returns a random source for the keys, to draw several values
+// for one row.
+func Rand(keys ...interface{}) *rand.Rand {
+	return rand.New(rand.NewSource(RandSeed(keys...)))
+}
+
+// RandFloat64 returns a number in [0.0, 1.0) for the keys.
+func RandFloat64(keys ...interface{}) float64 {
+	return float64(RandSeed(keys...)>>10) / (1 << 53)
+}
+
+// Sample tells whether to keep the row of the keys, with the probability of
+// the fraction.
+func Sample(fraction float64, keys ...interface{}) bool {
+	return RandFloat64(keys...) < fraction
+}
+
+// Bucket assigns the keys to one of n buckets, e.g. to A/B split rows.
+func Bucket(n int, keys ...interface{}) int {
+	return int(RandSeed(keys...) % int64(n))
+}
@@ -0,0 +1,70 @@
+package gio
+
+import (
+	"testing"
+)
+
+func withHashCode(hashCode uint, f func()) {
+	defer func(old uint) { taskOption.HashCode = old }(taskOption.HashCode)
+	taskOption.HashCode = hashCode
+	f()
+}
+
+func TestRandSeed(t *testing.T) {
+	var first, again, otherFlow int64
+	withHashCode(1, func() {
+		first = RandSeed("a", 1)
+		again = RandSeed("a", 1)
+	})
+	withHashCode(2, func() {
+		otherFlow = RandSeed("a", 1)
+	})
+	if first != again {
+		t.Errorf("the same keys have seeds %d and %d", first, again)
+	}
+	if first == otherFlow {
+		t.Errorf("two flows have the same seed %d", first)
+	}
+	if first < 0 || otherFlow < 0 {
+		t.Errorf("negative seeds %d %d", first, otherFlow)
+	}
+
+	withHashCode(1, func() {
+		if RandSeed("a", 1) == RandSeed("a", 2) {
+			t.Errorf("different keys have the same seed")
+		}
+		if x, y := Rand("a").Int63(), Rand("a").Int63(); x != y {
+			t.Errorf("the random sources of the same keys draw %d and %d", x, y)
+		}
+	})
+}
+
+func TestSampleAndBucket(t *testing.T) {
+	withHashCode(7, func() {
+		n := 10000
+		sampled, buckets := 0, make([]int, 4)
+		for i := 0; i < n; i++ {
+			if f := RandFloat64(i); f < 0 || f >= 1 {
+				t.Fatalf("RandFloat64(%d) = %v", i, f)
+			}
+			if Sample(0.3, i) {
+				sampled++
+			}
+			if Sample(0, i) || !Sample(1, i) {
+				t.Fatalf("sampled %d with fraction 0 or dropped it with fraction 1", i)
+			}
+			buckets[Bucket(len(buckets), i)]++
+		}
+		if sampled < n*27/100 || sampled > n*33/100 {
+			t.Errorf("sampled %d of %d rows with fraction 0.3", sampled, n)
+		}
+		for b, count := range buckets {
+			if count < n/4*9/10 || count > n/4*11/10 {
+				t.Errorf("bucket %d has %d of %d rows", b, count, n)
+			}
+		}
+		if Sample(0.3, "x") != Sample(0.3, "x") || Bucket(4, "x") != Bucket(4, "x") {
+			t.Errorf("the choices for the same keys differ")
+		}
+	})
+}
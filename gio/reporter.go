@@ -17,10 +17,11 @@ func (runner *gleamRunner) report(ctx context.Context, f func() error) error {
 	go runner.statusHeartbeat(&heartbeatWg, finishedChan)
 	defer heartbeatWg.Wait()
 
+	var err error
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		f()
+		err = f()
 		wg.Done()
 	}()
 
@@ -36,5 +37,5 @@ func (runner *gleamRunner) report(ctx context.Context, f func() error) error {
 		return ctx.Err()
 	}
 
-	return nil
+	return err
 }
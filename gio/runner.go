@@ -3,6 +3,7 @@ package gio
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"runtime"
@@ -55,9 +56,24 @@ func (runner *gleamRunner) runMapperReducer() {
 		},
 	}
 
+	if runner.Option.MapperArgFile != "" {
+		arg, err := ioutil.ReadFile(runner.Option.MapperArgFile)
+		if err != nil {
+			log.Fatalf("Failed to read the mapper argument: %v", err)
+		}
+		runner.Option.MapperArg = string(arg)
+	}
+
 	if runner.Option.Mapper != "" {
 		if fn, ok := mappers[MapperId(runner.Option.Mapper)]; ok {
-			if err := runner.processMapper(ctx, fn.Mapper); err != nil {
+			process := runner.processMapper
+			if fn.PartitionMapper != nil {
+				process = runner.processPartitionMapper
+			}
+			if fn.Predicate != nil {
+				process = runner.processPredicate
+			}
+			if err := process(ctx, fn); err != nil {
 				log.Fatalf("Failed to execute mapper %v: %v", os.Args, err)
 			}
 			return
@@ -80,6 +96,12 @@ func (runner *gleamRunner) runMapperReducer() {
 				keyIndexes = append(keyIndexes, keyIndex)
 			}
 
+			if runner.Option.Seed != "" {
+				if err := runner.processCombiner(ctx, fn.Reducer, keyIndexes, runner.Option.Seed); err != nil {
+					log.Fatalf("Failed to execute combiner %v: %v", os.Args, err)
+				}
+				return
+			}
 			if err := runner.processReducer(ctx, fn.Reducer, keyIndexes); err != nil {
 				log.Fatalf("Failed to execute reducer %v: %v", os.Args, err)
 			}
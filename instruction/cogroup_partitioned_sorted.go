@@ -1,6 +1,7 @@
 package instruction
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/lovelly/gleam/pb"
@@ -32,7 +33,7 @@ func (b *CoGroupPartitionedSorted) Name(prefix string) string {
 
 func (b *CoGroupPartitionedSorted) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
 	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
-		return DoCoGroupPartitionedSorted(readers[0], readers[1], writers[0], b.indexes, stats)
+		return DoCoGroupPartitionedSorted(readers, writers[0], b.indexes, stats)
 	}
 }
 
@@ -48,62 +49,53 @@ func (b *CoGroupPartitionedSorted) GetMemoryCostInMB(partitionSize int64) int64
 	return 5
 }
 
-func DoCoGroupPartitionedSorted(leftRawChan, rightRawChan io.Reader, writer io.Writer, indexes []int, stats *pb.InstructionStat) error {
-	leftChan := newChannelOfValuesWithSameKey("left", leftRawChan, indexes)
-	rightChan := newChannelOfValuesWithSameKey("right", rightRawChan, indexes)
-
-	// get first value from both channels
-	leftValuesWithSameKey, leftHasValue := <-leftChan
-	rightValuesWithSameKey, rightHasValue := <-rightChan
-
-	for leftHasValue && rightHasValue {
-		x := util.Compare(leftValuesWithSameKey.K, rightValuesWithSameKey.K)
-		ts := max(leftValuesWithSameKey.T, rightValuesWithSameKey.T)
-		switch {
-		case x == 0:
-			util.NewRow(ts).AppendKey(
-				leftValuesWithSameKey.K...).AppendValue(
-				leftValuesWithSameKey.V).AppendValue(
-				rightValuesWithSameKey.V).WriteTo(writer)
-			stats.OutputCounter++
-			leftValuesWithSameKey, leftHasValue = <-leftChan
-			rightValuesWithSameKey, rightHasValue = <-rightChan
-			stats.InputCounter += 2
-		case x < 0:
-			util.NewRow(ts).AppendKey(
-				leftValuesWithSameKey.K...).AppendValue(
-				leftValuesWithSameKey.V).AppendValue(
-				[]interface{}{}).WriteTo(writer)
-			stats.OutputCounter++
-			leftValuesWithSameKey, leftHasValue = <-leftChan
-			stats.InputCounter++
-		case x > 0:
-			util.NewRow(ts).AppendKey(
-				leftValuesWithSameKey.K...).AppendValue(
-				[]interface{}{}).AppendValue(
-				rightValuesWithSameKey.V).WriteTo(writer)
-			stats.OutputCounter++
-			rightValuesWithSameKey, rightHasValue = <-rightChan
+// DoCoGroupPartitionedSorted groups the rows of all the inputs, sorted by the
+// keys, into one row per key with the list of values of each input, empty
+// for the inputs without the key.
+func DoCoGroupPartitionedSorted(readers []io.Reader, writer io.Writer, indexes []int, stats *pb.InstructionStat) error {
+	chans := make([]chan util.Row, len(readers))
+	heads := make([]util.Row, len(readers))
+	hasValues := make([]bool, len(readers))
+	next := func(i int) {
+		heads[i], hasValues[i] = <-chans[i]
+		if hasValues[i] {
 			stats.InputCounter++
 		}
 	}
-	for leftHasValue {
-		util.NewRow(leftValuesWithSameKey.T).AppendKey(
-			leftValuesWithSameKey.K...).AppendValue(
-			leftValuesWithSameKey.V).AppendValue(
-			[]interface{}{}).WriteTo(writer)
-		stats.OutputCounter++
-		leftValuesWithSameKey, leftHasValue = <-leftChan
-		stats.InputCounter++
+	// read all inputs at once, since the same dataset can be several inputs
+	for i, reader := range readers {
+		chans[i] = newChannelOfValuesWithSameKey(fmt.Sprintf("cogroup input %d", i), reader, indexes)
+	}
+	for i := range readers {
+		next(i)
 	}
-	for rightHasValue {
-		util.NewRow(rightValuesWithSameKey.T).AppendKey(
-			rightValuesWithSameKey.K...).AppendValue(
-			[]interface{}{}).AppendValue(
-			rightValuesWithSameKey.V).WriteTo(writer)
+
+	for {
+		// the smallest key of all inputs
+		smallest := -1
+		for i := range heads {
+			if hasValues[i] && (smallest < 0 || util.Compare(heads[i].K, heads[smallest].K) < 0) {
+				smallest = i
+			}
+		}
+		if smallest < 0 {
+			return nil
+		}
+
+		key := heads[smallest].K
+		row := util.NewRow(heads[smallest].T).AppendKey(key...)
+		for i := range heads {
+			if !hasValues[i] || util.Compare(heads[i].K, key) != 0 {
+				row.AppendValue([]interface{}{})
+				continue
+			}
+			row.T = max(row.T, heads[i].T)
+			row.AppendValue(heads[i].V)
+			next(i)
+		}
+		if err := row.WriteTo(writer); err != nil {
+			return fmt.Errorf("CoGroup>Failed to write: %v", err)
+		}
 		stats.OutputCounter++
-		rightValuesWithSameKey, rightHasValue = <-rightChan
-		stats.InputCounter++
 	}
-	return nil
 }
@@ -0,0 +1,42 @@
+package instruction
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoCoGroupPartitionedSorted(t *testing.T) {
+	inputs := [][][]interface{}{
+		{{"a", int64(1)}, {"a", int64(2)}, {"c", int64(3)}},
+		{{"b", int64(4)}, {"c", int64(5)}, {"c", int64(6)}},
+		{},
+	}
+	var readers []io.Reader
+	for _, rows := range inputs {
+		readers = append(readers, encodeRows(t, rows))
+	}
+	var out bytes.Buffer
+	stats := &pb.InstructionStat{}
+	if err := DoCoGroupPartitionedSorted(readers, &out, []int{1}, stats); err != nil {
+		t.Fatalf("cogroup: %v", err)
+	}
+
+	none := []interface{}{}
+	expected := [][]interface{}{
+		{"a", []interface{}{[]interface{}{int64(1)}, []interface{}{int64(2)}}, none, none},
+		{"b", none, []interface{}{[]interface{}{int64(4)}}, none},
+		{"c", []interface{}{[]interface{}{int64(3)}}, []interface{}{[]interface{}{int64(5)}, []interface{}{int64(6)}}, none},
+	}
+	rows := decodeRows(t, &out)
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("cogrouped %v, expected %v", rows, expected)
+	}
+	// the inputs are counted by the groups of the same key
+	if stats.InputCounter != 4 || stats.OutputCounter != 3 {
+		t.Errorf("counted %d inputs and %d outputs", stats.InputCounter, stats.OutputCounter)
+	}
+}
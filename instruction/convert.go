@@ -0,0 +1,216 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// The types of Convert. The decimals are kept exactly as strings, and the
+// dates and datetimes as strings like "2006-01-02" and "2006-01-02 15:04:05",
+// the formats of the sql values.
+const (
+	ConvertInt      = "int"
+	ConvertFloat    = "float"
+	ConvertString   = "string"
+	ConvertDecimal  = "decimal"
+	ConvertDate     = "date"
+	ConvertDatetime = "datetime"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetConvert() != nil {
+			return NewConvert(m.GetConvert().GetTypes())
+		}
+		return nil
+	})
+}
+
+type Convert struct {
+	types []string
+}
+
+func NewConvert(types []string) *Convert {
+	return &Convert{types}
+}
+
+func (b *Convert) Name(prefix string) string {
+	return prefix + ".Convert"
+}
+
+func (b *Convert) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoConvert(readers[0], writers[0], b.types, stats)
+	}
+}
+
+func (b *Convert) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		Convert: &pb.Instruction_Convert{
+			Types: b.types,
+		},
+	}
+}
+
+func (b *Convert) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 3
+}
+
+// DoConvert converts the fields of each row, in order, to the types. The
+// fields without a type, and the nil values, are not changed. It fails on
+// the values not convertible, e.g. "abc" to int.
+func DoConvert(reader io.Reader, writer io.Writer, types []string, stats *pb.InstructionStat) error {
+
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+
+		for i, t := range types {
+			if t == "" {
+				continue
+			}
+			var field *interface{}
+			if i < len(row.K) {
+				field = &row.K[i]
+			} else if i-len(row.K) < len(row.V) {
+				field = &row.V[i-len(row.K)]
+			} else {
+				continue
+			}
+			converted, err := convertValue(*field, t)
+			if err != nil {
+				return fmt.Errorf("Failed to convert field %d: %v", i+1, err)
+			}
+			*field = converted
+		}
+
+		if err := row.WriteTo(writer); err != nil {
+			return err
+		}
+		stats.OutputCounter++
+
+		return nil
+	})
+
+}
+
+// dateLayouts are the layouts of the date and datetime strings read.
+var dateLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999Z07:00", "2006-01-02", "20060102"}
+
+func convertValue(v interface{}, t string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	s, isString := v.(string)
+	if isString {
+		s = strings.TrimSpace(s)
+	}
+	switch t {
+	case ConvertString:
+		if isString {
+			return v, nil
+		}
+		return fmt.Sprint(v), nil
+	case ConvertInt:
+		switch x := v.(type) {
+		case string:
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return int64(f), nil
+			}
+		case float64:
+			return int64(x), nil
+		case float32:
+			return int64(x), nil
+		case bool:
+			if x {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		default:
+			if isInteger(v) {
+				return util.ToInt64(v), nil
+			}
+		}
+	case ConvertFloat:
+		switch x := v.(type) {
+		case string:
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, nil
+			}
+		case float64, float32:
+			return util.ToFloat64(x), nil
+		case bool:
+			if x {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		default:
+			if isInteger(v) {
+				return float64(util.ToInt64(v)), nil
+			}
+		}
+	case ConvertDecimal:
+		switch x := v.(type) {
+		case string:
+			if _, ok := new(big.Rat).SetString(s); ok && !strings.ContainsAny(s, "/eE") {
+				return s, nil
+			}
+		case float64:
+			return strconv.FormatFloat(x, 'f', -1, 64), nil
+		case float32:
+			return strconv.FormatFloat(float64(x), 'f', -1, 32), nil
+		default:
+			if isInteger(v) {
+				return strconv.FormatInt(util.ToInt64(v), 10), nil
+			}
+		}
+	case ConvertDate, ConvertDatetime:
+		var tm time.Time
+		switch x := v.(type) {
+		case string:
+			for _, layout := range dateLayouts {
+				if parsed, err := time.Parse(layout, s); err == nil {
+					tm = parsed
+					break
+				}
+			}
+		case time.Time:
+			tm = x
+		default:
+			if isInteger(v) {
+				// unix milliseconds, e.g. of the kafka timestamps
+				tm = time.Unix(0, util.ToInt64(v)*int64(time.Millisecond)).UTC()
+			}
+		}
+		if tm.IsZero() {
+			break
+		}
+		if t == ConvertDate {
+			return tm.Format("2006-01-02"), nil
+		}
+		return tm.Format("2006-01-02 15:04:05"), nil
+	default:
+		return nil, fmt.Errorf("unknown type %s", t)
+	}
+	return nil, fmt.Errorf("%v of %T is not a %s", v, v, t)
+}
+
+func isInteger(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
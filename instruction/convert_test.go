@@ -0,0 +1,57 @@
+package instruction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		t        string
+		expected interface{}
+	}{
+		{" 42 ", ConvertInt, int64(42)},
+		{"4.7", ConvertInt, int64(4)},
+		{int32(7), ConvertInt, int64(7)},
+		{true, ConvertInt, int64(1)},
+		{"2.5", ConvertFloat, 2.5},
+		{int64(3), ConvertFloat, 3.0},
+		{int64(3), ConvertString, "3"},
+		{[]byte("abc"), ConvertString, "abc"},
+		{"12345678901234567890.123456789", ConvertDecimal, "12345678901234567890.123456789"},
+		{0.1, ConvertDecimal, "0.1"},
+		{int64(-5), ConvertDecimal, "-5"},
+		{"2024-03-01", ConvertDate, "2024-03-01"},
+		{"2024-03-01 10:20:30", ConvertDate, "2024-03-01"},
+		{"2024-03-01T10:20:30Z", ConvertDatetime, "2024-03-01 10:20:30"},
+		{int64(1709288430000), ConvertDatetime, "2024-03-01 10:20:30"},
+		{time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC), ConvertDate, "2024-03-01"},
+		{nil, ConvertInt, nil},
+	}
+	for _, test := range tests {
+		converted, err := convertValue(test.value, test.t)
+		if err != nil {
+			t.Errorf("convert %v to %s: %v", test.value, test.t, err)
+		} else if converted != test.expected {
+			t.Errorf("converted %v to %s as %v of %T, expected %v", test.value, test.t, converted, converted, test.expected)
+		}
+	}
+
+	for _, test := range []struct {
+		value interface{}
+		t     string
+	}{
+		{"abc", ConvertInt},
+		{"abc", ConvertFloat},
+		{"1e3", ConvertDecimal},
+		{"1/3", ConvertDecimal},
+		{"yesterday", ConvertDate},
+		{map[string]interface{}{}, ConvertInt},
+		{1, "unknown"},
+	} {
+		if converted, err := convertValue(test.value, test.t); err == nil {
+			t.Errorf("converted %v to %s as %v, expected an error", test.value, test.t, converted)
+		}
+	}
+}
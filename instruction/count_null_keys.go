@@ -0,0 +1,70 @@
+package instruction
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetCountNullKeys() != nil {
+			return NewCountNullKeys(toInts(m.GetCountNullKeys().GetIndexes()))
+		}
+		return nil
+	})
+}
+
+// CountNullKeys counts the rows, and the rows with NULL in any of the key fields.
+type CountNullKeys struct {
+	indexes []int
+}
+
+func NewCountNullKeys(indexes []int) *CountNullKeys {
+	return &CountNullKeys{indexes}
+}
+
+func (b *CountNullKeys) Name(prefix string) string {
+	return prefix + ".CountNullKeys"
+}
+
+func (b *CountNullKeys) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoCountNullKeys(readers[0], writers[0], b.indexes, stats)
+	}
+}
+
+func (b *CountNullKeys) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		CountNullKeys: &pb.Instruction_CountNullKeys{
+			Indexes: getIndexes(b.indexes),
+		},
+	}
+}
+
+func (b *CountNullKeys) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoCountNullKeys writes one row, the number of rows read, and the number of
+// them with NULL keys.
+func DoCountNullKeys(reader io.Reader, writer io.Writer, indexes []int, stats *pb.InstructionStat) error {
+	var nullKeys int64
+	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		_, hasKey, err := existsKey(row, indexes)
+		if err != nil {
+			return err
+		}
+		if !hasKey {
+			nullKeys++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	stats.OutputCounter++
+	return util.NewRow(util.Now(), stats.InputCounter, nullKeys).WriteTo(writer)
+}
@@ -0,0 +1,57 @@
+package instruction
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetCountRows() != nil {
+			return NewCountRows()
+		}
+		return nil
+	})
+}
+
+type CountRows struct {
+}
+
+func NewCountRows() *CountRows {
+	return &CountRows{}
+}
+
+func (b *CountRows) Name(prefix string) string {
+	return prefix + ".CountRows"
+}
+
+func (b *CountRows) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoCountRows(readers[0], writers[0], stats)
+	}
+}
+
+func (b *CountRows) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		CountRows: &pb.Instruction_CountRows{},
+	}
+}
+
+func (b *CountRows) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoCountRows writes one row, the number of rows read.
+func DoCountRows(reader io.Reader, writer io.Writer, stats *pb.InstructionStat) error {
+	err := util.ProcessMessage(reader, func(data []byte) error {
+		stats.InputCounter++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	stats.OutputCounter++
+	return util.NewRow(util.Now(), stats.InputCounter).WriteTo(writer)
+}
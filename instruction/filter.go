@@ -0,0 +1,182 @@
+package instruction
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/script"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetFilter() != nil {
+			return NewFilter(
+				m.GetFilter().GetPredicateId(),
+				nil,
+				&script.Command{
+					Path: m.GetFilter().GetPath(),
+					Args: m.GetFilter().GetArgs(),
+					Env:  m.GetFilter().GetEnv(),
+				},
+			)
+		}
+		return nil
+	})
+}
+
+// Filter keeps the rows accepted by a predicate registered in Go. In the
+// process defining the flow, it calls the predicate directly. Elsewhere it
+// runs the command, the binary of the flow, which sends back a byte for
+// each row, 1 to keep it, so the rows are not decoded and encoded again.
+type Filter struct {
+	predicateId string
+	predicate   func([]interface{}) (bool, error)
+	command     *script.Command
+}
+
+func NewFilter(predicateId string, predicate func([]interface{}) (bool, error), command *script.Command) *Filter {
+	return &Filter{predicateId, predicate, command}
+}
+
+func (b *Filter) Name(prefix string) string {
+	return prefix + ".Filter"
+}
+
+func (b *Filter) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		if b.predicate != nil {
+			return DoFilter(readers[0], writers[0], b.predicate, stats)
+		}
+		return DoFilterByCommand(readers[0], writers[0], b.command, stats)
+	}
+}
+
+func (b *Filter) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		Filter: &pb.Instruction_Filter{
+			PredicateId: b.predicateId,
+			Path:        b.command.Path,
+			Args:        b.command.Args,
+			Env:         b.command.Env,
+		},
+	}
+}
+
+func (b *Filter) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoFilter writes the rows accepted by the predicate, and counts the
+// dropped rows as filtered.
+func DoFilter(reader io.Reader, writer io.Writer, predicate func([]interface{}) (bool, error), stats *pb.InstructionStat) error {
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		keep, err := predicate(append(row.K, row.V...))
+		if err != nil {
+			return fmt.Errorf("Failed to filter row: %v", err)
+		}
+		if !keep {
+			stats.FilteredCounter++
+			return nil
+		}
+		stats.OutputCounter++
+		return row.WriteTo(writer)
+	})
+}
+
+// DoFilterByCommand sends the rows to the command, and writes the rows
+// whose verdicts it sends back are 1.
+func DoFilterByCommand(reader io.Reader, writer io.Writer, command *script.Command, stats *pb.InstructionStat) error {
+	cmd := exec.Command(command.Path, command.Args...)
+	cmd.Env = append(os.Environ(), command.Env...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("Failed to open predicate input: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("Failed to open predicate output: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Failed to start predicate %s: %v", command.Path, err)
+	}
+
+	// the rows sent and waiting for their verdicts
+	pending := make(chan []byte, 1024)
+	sendErrChan := make(chan error, 1)
+	go func() {
+		defer close(pending)
+		in := bufio.NewReader(reader)
+		out := bufio.NewWriter(stdin)
+		err := util.ProcessMessage(in, func(m []byte) error {
+			select {
+			case pending <- m:
+			default:
+				// the verdicts of the pending rows may wait for the rows
+				// buffered here
+				if err := out.Flush(); err != nil {
+					return err
+				}
+				pending <- m
+			}
+			if err := util.WriteMessage(out, m); err != nil {
+				return err
+			}
+			// the predicate waits for the rows buffered here, before
+			// waiting for the next row
+			if !util.HasBufferedMessage(in) {
+				return out.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = out.Flush()
+		}
+		stdin.Close()
+		sendErrChan <- err
+	}()
+
+	var filterErr error
+	verdicts := bufio.NewReader(stdout)
+	for m := range pending {
+		if filterErr != nil {
+			continue
+		}
+		stats.InputCounter++
+		verdict, err := verdicts.ReadByte()
+		if err != nil {
+			filterErr = fmt.Errorf("Failed to read predicate verdict: %v", err)
+			// stop the predicate, so the rows are no longer sent to it
+			cmd.Process.Kill()
+			continue
+		}
+		if verdict == 0 {
+			stats.FilteredCounter++
+			continue
+		}
+		stats.OutputCounter++
+		if err := util.WriteMessage(writer, m); err != nil {
+			filterErr = err
+			cmd.Process.Kill()
+		}
+	}
+
+	sendErr := <-sendErrChan
+	waitErr := cmd.Wait()
+	if filterErr != nil {
+		return filterErr
+	}
+	if sendErr != nil {
+		return fmt.Errorf("Failed to send rows to predicate: %v", sendErr)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("Failed to run predicate %s: %v", command.Path, waitErr)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package instruction
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetFilterSalted() != nil {
+			return NewFilterSalted(
+				int(m.GetFilterSalted().GetKeyCount()),
+				m.GetFilterSalted().GetHotKeys(),
+			)
+		}
+		return nil
+	})
+}
+
+type FilterSalted struct {
+	keyCount int
+	hotKeys  bool
+}
+
+func NewFilterSalted(keyCount int, hotKeys bool) *FilterSalted {
+	return &FilterSalted{keyCount, hotKeys}
+}
+
+func (b *FilterSalted) Name(prefix string) string {
+	return prefix + ".FilterSalted"
+}
+
+func (b *FilterSalted) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoFilterSalted(readers[0], writers[0], b.keyCount, b.hotKeys, stats)
+	}
+}
+
+func (b *FilterSalted) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		FilterSalted: &pb.Instruction_FilterSalted{
+			KeyCount: int32(b.keyCount),
+			HotKeys:  b.hotKeys,
+		},
+	}
+}
+
+func (b *FilterSalted) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoFilterSalted separates the rows of DoSaltHotKeys(). With hotKeys, it
+// emits the hot keys announced, or else the salted rows.
+func DoFilterSalted(reader io.Reader, writer io.Writer, keyCount int, hotKeys bool, stats *pb.InstructionStat) error {
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		fields := append(row.K, row.V...)
+		isHotKey := len(fields) > keyCount && util.ToInt64(fields[keyCount]) == hotKeySalt
+		if isHotKey != hotKeys {
+			return nil
+		}
+		stats.OutputCounter++
+		if hotKeys {
+			return util.NewRow(row.T, fields[:keyCount]...).WriteTo(writer)
+		}
+		return row.WriteTo(writer)
+	})
+}
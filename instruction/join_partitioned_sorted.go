@@ -14,6 +14,8 @@ func init() {
 				m.GetJoinPartitionedSorted().GetIsLeftOuterJoin(),
 				m.GetJoinPartitionedSorted().GetIsRightOuterJoin(),
 				toInts(m.GetJoinPartitionedSorted().GetIndexes()),
+				int(m.GetJoinPartitionedSorted().GetLeftValueCount()),
+				int(m.GetJoinPartitionedSorted().GetRightValueCount()),
 			)
 		}
 		return nil
@@ -24,10 +26,15 @@ type JoinPartitionedSorted struct {
 	isLeftOuterJoin  bool
 	isRightOuterJoin bool
 	indexes          []int
+	leftValueCount   int
+	rightValueCount  int
 }
 
-func NewJoinPartitionedSorted(isLeftOuterJoin bool, isRightOuterJoin bool, indexes []int) *JoinPartitionedSorted {
-	return &JoinPartitionedSorted{isLeftOuterJoin, isRightOuterJoin, indexes}
+// NewJoinPartitionedSorted joins the rows by the key fields. The outer joins
+// add nils for the values of the other side, leftValueCount or
+// rightValueCount of them, or as many as the values of its rows if 0.
+func NewJoinPartitionedSorted(isLeftOuterJoin bool, isRightOuterJoin bool, indexes []int, leftValueCount, rightValueCount int) *JoinPartitionedSorted {
+	return &JoinPartitionedSorted{isLeftOuterJoin, isRightOuterJoin, indexes, leftValueCount, rightValueCount}
 }
 
 func (b *JoinPartitionedSorted) Name(prefix string) string {
@@ -36,7 +43,8 @@ func (b *JoinPartitionedSorted) Name(prefix string) string {
 
 func (b *JoinPartitionedSorted) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
 	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
-		return DoJoinPartitionedSorted(readers[0], readers[1], writers[0], b.indexes, b.isLeftOuterJoin, b.isRightOuterJoin, stats)
+		return DoJoinPartitionedSorted(readers[0], readers[1], writers[0], b.indexes, b.isLeftOuterJoin, b.isRightOuterJoin,
+			b.leftValueCount, b.rightValueCount, stats)
 	}
 }
 
@@ -46,6 +54,8 @@ func (b *JoinPartitionedSorted) SerializeToCommand() *pb.Instruction {
 			IsLeftOuterJoin:  (b.isLeftOuterJoin),
 			IsRightOuterJoin: (b.isRightOuterJoin),
 			Indexes:          getIndexes(b.indexes),
+			LeftValueCount:   int32(b.leftValueCount),
+			RightValueCount:  int32(b.rightValueCount),
 		},
 	}
 }
@@ -55,7 +65,7 @@ func (b *JoinPartitionedSorted) GetMemoryCostInMB(partitionSize int64) int64 {
 }
 
 func DoJoinPartitionedSorted(leftRawChan, rightRawChan io.Reader, writer io.Writer, indexes []int,
-	isLeftOuterJoin, isRightOuterJoin bool, stats *pb.InstructionStat) error {
+	isLeftOuterJoin, isRightOuterJoin bool, leftValueCount, rightValueCount int, stats *pb.InstructionStat) error {
 	leftChan := newChannelOfValuesWithSameKey("left", leftRawChan, indexes)
 	rightChan := newChannelOfValuesWithSameKey("right", rightRawChan, indexes)
 
@@ -63,11 +73,11 @@ func DoJoinPartitionedSorted(leftRawChan, rightRawChan io.Reader, writer io.Writ
 	leftValuesWithSameKey, leftHasValue := <-leftChan
 	rightValuesWithSameKey, rightHasValue := <-rightChan
 
-	var leftValueLength, rightValueLength int
-	if leftHasValue {
+	leftValueLength, rightValueLength := leftValueCount, rightValueCount
+	if leftHasValue && leftValueLength == 0 {
 		leftValueLength = len(leftValuesWithSameKey.V[0].([]interface{}))
 	}
-	if rightHasValue {
+	if rightHasValue && rightValueLength == 0 {
 		rightValueLength = len(rightValuesWithSameKey.V[0].([]interface{}))
 	}
 
@@ -0,0 +1,42 @@
+package instruction
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoJoinPartitionedSorted(t *testing.T) {
+	left := [][]interface{}{{"a", int64(1)}, {"b", int64(2)}}
+	right := [][]interface{}{{"b", "x", "y"}, {"c", "z", "w"}}
+	tests := []struct {
+		name                            string
+		left, right                     [][]interface{}
+		leftOuter, rightOuter           bool
+		leftValueCount, rightValueCount int
+		expected                        [][]interface{}
+	}{
+		{"inner", left, right, false, false, 0, 0,
+			[][]interface{}{{"b", int64(2), "x", "y"}}},
+		{"full outer", left, right, true, true, 0, 0,
+			[][]interface{}{{"a", int64(1), nil, nil}, {"b", int64(2), "x", "y"}, {"c", nil, "z", "w"}}},
+		// the value counts give the nils of a side without rows
+		{"left outer of no rows", left, nil, true, false, 1, 2,
+			[][]interface{}{{"a", int64(1), nil, nil}, {"b", int64(2), nil, nil}}},
+		{"right outer of no rows", nil, right, false, true, 1, 2,
+			[][]interface{}{{"b", nil, "x", "y"}, {"c", nil, "z", "w"}}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		err := DoJoinPartitionedSorted(encodeRows(t, test.left), encodeRows(t, test.right), &out, []int{1},
+			test.leftOuter, test.rightOuter, test.leftValueCount, test.rightValueCount, &pb.InstructionStat{})
+		if err != nil {
+			t.Fatalf("%s join: %v", test.name, err)
+		}
+		if rows := decodeRows(t, &out); !reflect.DeepEqual(rows, test.expected) {
+			t.Errorf("%s join: %v, expected %v", test.name, rows, test.expected)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package instruction
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetLocalExists() != nil {
+			return NewLocalExists(
+				toInts(m.GetLocalExists().GetIndexes()),
+				toInts(m.GetLocalExists().GetThatIndexes()),
+				m.GetLocalExists().GetIsAnti(),
+				m.GetLocalExists().GetWithMark(),
+				m.GetLocalExists().GetIsNullAware(),
+			)
+		}
+		return nil
+	})
+}
+
+// LocalExists checks whether the rows of the first input have rows with the
+// same keys in the second input. The first input is kept in memory, and the
+// second input is read only until all the keys are found.
+type LocalExists struct {
+	indexes     []int
+	thatIndexes []int
+	isAnti      bool
+	withMark    bool
+	isNullAware bool
+}
+
+// NewLocalExists keeps the rows with matching rows, or without them if isAnti.
+// If withMark, all rows are kept, and 1 or 0 is appended for whether they have matching rows.
+// If isNullAware, the third input has the DoCountNullKeys() rows of all of the
+// second inputs, and the rows whose NOT IN is NULL are dropped, or marked with NULL.
+func NewLocalExists(indexes, thatIndexes []int, isAnti, withMark, isNullAware bool) *LocalExists {
+	return &LocalExists{indexes, thatIndexes, isAnti, withMark, isNullAware}
+}
+
+func (b *LocalExists) Name(prefix string) string {
+	return prefix + ".LocalExists"
+}
+
+func (b *LocalExists) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		var countsReader io.Reader
+		if b.isNullAware {
+			countsReader = readers[2]
+		}
+		return DoLocalExists(readers[0], readers[1], countsReader, writers[0], b.indexes, b.thatIndexes, b.isAnti, b.withMark, stats)
+	}
+}
+
+func (b *LocalExists) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		LocalExists: &pb.Instruction_LocalExists{
+			Indexes:     getIndexes(b.indexes),
+			ThatIndexes: getIndexes(b.thatIndexes),
+			IsAnti:      b.isAnti,
+			WithMark:    b.withMark,
+			IsNullAware: b.isNullAware,
+		},
+	}
+}
+
+func (b *LocalExists) GetMemoryCostInMB(partitionSize int64) int64 {
+	return int64(float32(partitionSize) * 1.1)
+}
+
+// errAllKeysFound stops reading the second input.
+var errAllKeysFound = errors.New("all keys are found")
+
+// DoLocalExists writes the rows of the reader with, or if isAnti without,
+// matching rows in thatReader. With a countsReader, the NOT IN of the rows is
+// NULL by the counts of all rows of that side: if it has rows, for the rows
+// with NULL keys, and if it also has rows with NULL keys, for the rows without
+// matching rows. A key with NULL in any field is taken as NULL.
+func DoLocalExists(reader, thatReader, countsReader io.Reader, writer io.Writer, indexes, thatIndexes []int,
+	isAnti, withMark bool, stats *pb.InstructionStat) error {
+
+	var rows []*util.Row
+	var rowKeys []string
+	var rowHasKeys []bool
+	found := make(map[string]bool)
+	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		key, hasKey, err := existsKey(row, indexes)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		rowKeys = append(rowKeys, key)
+		rowHasKeys = append(rowHasKeys, hasKey)
+		if hasKey {
+			found[key] = false
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("LocalExists>Failed to read input data:%v\n", err)
+		return err
+	}
+
+	// only the first matching row of each key is needed
+	notFound := len(found)
+	if notFound == 0 {
+		util.StopReading(thatReader)
+	} else {
+		err = util.ProcessRow(thatReader, nil, func(row *util.Row) error {
+			stats.InputCounter++
+			key, hasKey, err := existsKey(row, thatIndexes)
+			if err != nil {
+				return err
+			}
+			if isFound, isKey := found[key]; hasKey && isKey && !isFound {
+				found[key] = true
+				notFound--
+				if notFound == 0 {
+					return errAllKeysFound
+				}
+			}
+			return nil
+		})
+		if err == errAllKeysFound {
+			util.StopReading(thatReader)
+			err = nil
+		}
+		if err != nil {
+			fmt.Printf("LocalExists>Failed to read the other input data:%v\n", err)
+			return err
+		}
+	}
+
+	// the counts are read after the second input, which they are counted from
+	var thatRows, thatNullKeys int64
+	if countsReader != nil {
+		err = util.ProcessRow(countsReader, nil, func(row *util.Row) error {
+			stats.InputCounter++
+			fields := append(append([]interface{}{}, row.K...), row.V...)
+			thatRows += util.ToInt64(fields[0])
+			thatNullKeys += util.ToInt64(fields[1])
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("LocalExists>Failed to read the counts:%v\n", err)
+			return err
+		}
+	}
+
+	for i, row := range rows {
+		exists := found[rowKeys[i]]
+		isNull := countsReader != nil && thatRows > 0 && !exists && (!rowHasKeys[i] || thatNullKeys > 0)
+		if withMark {
+			var mark interface{} = int64(0)
+			if isNull {
+				mark = nil
+			} else if exists != isAnti {
+				mark = int64(1)
+			}
+			row.AppendValue(mark)
+		} else if exists == isAnti || isNull {
+			continue
+		}
+		if err := row.WriteTo(writer); err != nil {
+			return fmt.Errorf("LocalExists>Failed to write: %v", err)
+		}
+		stats.OutputCounter++
+	}
+	return nil
+}
+
+// existsKey encodes the key fields of the row. Keys with NULL never match.
+func existsKey(row *util.Row, indexes []int) (key string, hasKey bool, err error) {
+	fields := append(append([]interface{}{}, row.K...), row.V...)
+	keys := make([]interface{}, len(indexes))
+	hasKey = true
+	for i, index := range indexes {
+		keys[i] = fields[index-1]
+		if keys[i] == nil {
+			hasKey = false
+		}
+	}
+	keyBytes, err := util.EncodeKeys(keys...)
+	if err != nil {
+		return "", false, fmt.Errorf("Failed to encoded keys %+v: %v", keys, err)
+	}
+	return string(keyBytes), hasKey, nil
+}
@@ -51,12 +51,12 @@ func (b *LocalHashAndJoinWith) GetMemoryCostInMB(partitionSize int64) int64 {
 }
 
 func DoLocalHashAndJoinWith(leftReader, rightReader io.Reader, writer io.Writer, indexes []int, stats *pb.InstructionStat) error {
-	hashmap := make(map[string]*util.Row)
+	hashmap := make(map[string][]*util.Row)
 	err := util.ProcessRow(leftReader, indexes, func(row *util.Row) error {
-		// write the row if key is different
+		// keep all rows with the same key
 		stats.InputCounter++
 		keyBytes, _ := util.EncodeKeys(row.K...)
-		hashmap[string(keyBytes)] = row
+		hashmap[string(keyBytes)] = append(hashmap[string(keyBytes)], row)
 		return nil
 	})
 	if err != nil {
@@ -75,8 +75,9 @@ func DoLocalHashAndJoinWith(leftReader, rightReader io.Reader, writer io.Writer,
 		if err != nil {
 			return fmt.Errorf("Failed to encoded keys %+v: %v", row.K, err)
 		}
-		if mappedRow, ok := hashmap[string(keyBytes)]; ok {
-			row.AppendValue(mappedRow.V...).WriteTo(writer)
+		for _, mappedRow := range hashmap[string(keyBytes)] {
+			util.NewRow(row.T).AppendKey(row.K...).AppendValue(
+				row.V...).AppendValue(mappedRow.V...).WriteTo(writer)
 			stats.OutputCounter++
 		}
 		return nil
@@ -3,6 +3,7 @@ package instruction
 import (
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/lovelly/gleam/pb"
 	"github.com/lovelly/gleam/util"
@@ -14,6 +15,7 @@ func init() {
 			return NewLocalTop(
 				int(m.GetLocalTop().GetN()),
 				toOrderBys(m.GetLocalTop().GetOrderBys()),
+				toInts(m.GetLocalTop().GetIndexes()),
 			)
 		}
 		return nil
@@ -23,10 +25,11 @@ func init() {
 type LocalTop struct {
 	n        int
 	orderBys []OrderBy
+	indexes  []int
 }
 
-func NewLocalTop(n int, orderBys []OrderBy) *LocalTop {
-	return &LocalTop{n, orderBys}
+func NewLocalTop(n int, orderBys []OrderBy, indexes []int) *LocalTop {
+	return &LocalTop{n, orderBys, indexes}
 }
 
 func (b *LocalTop) Name(prefix string) string {
@@ -35,7 +38,7 @@ func (b *LocalTop) Name(prefix string) string {
 
 func (b *LocalTop) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
 	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
-		return DoLocalTop(readers[0], writers[0], b.n, b.orderBys, stats)
+		return DoLocalTop(readers[0], writers[0], b.n, b.orderBys, b.indexes, stats)
 	}
 }
 
@@ -44,50 +47,89 @@ func (b *LocalTop) SerializeToCommand() *pb.Instruction {
 		LocalTop: &pb.Instruction_LocalTop{
 			N:        int32(b.n),
 			OrderBys: getOrderBys(b.orderBys),
+			Indexes:  getIndexes(b.indexes),
 		},
 	}
 }
 
+// topRowSize is the size of the rows kept, estimated for the memory cost.
+const topRowSize = 1024
+
+// GetMemoryCostInMB estimates the n rows kept, unless kept for each key, or
+// else the whole partition.
 func (b *LocalTop) GetMemoryCostInMB(partitionSize int64) int64 {
-	return 5
+	cost := partitionSize
+	if kept := int64(b.n)*topRowSize>>20 + 1; len(b.indexes) == 0 && kept < cost {
+		cost = kept
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	return cost
 }
 
-// DoLocalTop streamingly compare and get the top n items
-func DoLocalTop(reader io.Reader, writer io.Writer, n int, orderBys []OrderBy, stats *pb.InstructionStat) error {
+// topGroup keeps the first n rows of a key in a heap, with the last of them
+// on top.
+type topGroup struct {
+	keys []interface{}
+	pq   *util.PriorityQueue
+}
 
-	pq := newMinQueueOfPairs(orderBys)
+// DoLocalTop emits the first n rows by the orderBys, of all rows, or of each
+// key of the fields at indexes, streaming through the rows with O(log(n))
+// for each row. The rows of each key are emitted in order, and the keys in
+// ascending order. The rows are kept in a heap of n rows per key, so the top
+// rows of several shards merged are merged again by DoLocalTop.
+func DoLocalTop(reader io.Reader, writer io.Writer, n int, orderBys []OrderBy, indexes []int, stats *pb.InstructionStat) error {
+	groups := make(map[string]*topGroup)
+	var keys []string
 
 	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
 		stats.InputCounter++
-
-		if pq.Len() >= n {
-			if lessThan(orderBys, pq.Top().(*util.Row), row) {
-				pq.Dequeue()
-				pq.Enqueue(row, 0)
-			}
-		} else {
-			pq.Enqueue(row, 0)
-
+		if n <= 0 {
+			return nil
+		}
+		rowKeys := fieldsAt(row, indexes)
+		keyBytes, err := util.EncodeKeys(rowKeys...)
+		if err != nil {
+			return fmt.Errorf("Failed to encoded keys %+v: %v", rowKeys, err)
+		}
+		key := string(keyBytes)
+		group, found := groups[key]
+		if !found {
+			group = &topGroup{keys: rowKeys, pq: newMaxQueueOfRows(orderBys)}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		if group.pq.Len() < n {
+			group.pq.Enqueue(row, 0)
+		} else if lessThan(orderBys, row, group.pq.Top().(*util.Row)) {
+			group.pq.Dequeue()
+			group.pq.Enqueue(row, 0)
 		}
 		return nil
 	})
 	if err != nil {
-		fmt.Printf("Top>Failed to process input data:%v\n", err)
 		return err
 	}
 
-	// read data out of the priority queue
-	length := pq.Len()
-	itemsToReverse := make([]*util.Row, length)
-	for i := 0; i < length; i++ {
-		entry, _ := pq.Dequeue()
-		itemsToReverse[i] = entry.(*util.Row)
-	}
-	for i := length - 1; i >= 0; i-- {
-		itemsToReverse[i].WriteTo(writer)
-		stats.OutputCounter++
+	sort.Slice(keys, func(i, j int) bool {
+		return compareFields(groups[keys[i]].keys, groups[keys[j]].keys) < 0
+	})
+	for _, key := range keys {
+		pq := groups[key].pq
+		rows := make([]*util.Row, pq.Len())
+		for i := len(rows) - 1; i >= 0; i-- {
+			entry, _ := pq.Dequeue()
+			rows[i] = entry.(*util.Row)
+		}
+		for _, row := range rows {
+			if err := row.WriteTo(writer); err != nil {
+				return err
+			}
+			stats.OutputCounter++
+		}
 	}
-
 	return nil
 }
 
@@ -97,3 +139,31 @@ func newMinQueueOfPairs(orderBys []OrderBy) *util.PriorityQueue {
 		return lessThan(orderBys, x, y)
 	})
 }
+
+// newMaxQueueOfRows has the last row by the orderBys on top.
+func newMaxQueueOfRows(orderBys []OrderBy) *util.PriorityQueue {
+	return util.NewPriorityQueue(func(a, b interface{}) bool {
+		return lessThan(orderBys, b.(*util.Row), a.(*util.Row))
+	})
+}
+
+// fieldsAt returns the 1-based fields of the row.
+func fieldsAt(row *util.Row, indexes []int) (fields []interface{}) {
+	for _, index := range indexes {
+		if index <= len(row.K) {
+			fields = append(fields, row.K[index-1])
+		} else {
+			fields = append(fields, row.V[index-1-len(row.K)])
+		}
+	}
+	return
+}
+
+func compareFields(a, b []interface{}) int {
+	for i := range a {
+		if compared := util.Compare(a[i], b[i]); compared != 0 {
+			return compared
+		}
+	}
+	return 0
+}
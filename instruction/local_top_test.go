@@ -0,0 +1,70 @@
+package instruction
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoLocalTop(t *testing.T) {
+	input := [][]interface{}{
+		{"b", 2}, {"a", 5}, {"b", 7}, {"a", 1}, {"c", 3}, {"a", 4},
+	}
+	ascending := []OrderBy{{Index: 2, Order: Ascending}}
+	descending := []OrderBy{{Index: 2, Order: Descending}}
+
+	tests := []struct {
+		name     string
+		n        int
+		orderBys []OrderBy
+		indexes  []int
+		expected []string
+	}{
+		{"ascending", 3, ascending, nil, []string{"a 1", "b 2", "c 3"}},
+		{"descending", 2, descending, nil, []string{"b 7", "a 5"}},
+		{"more than the rows", 10, ascending, nil, []string{"a 1", "b 2", "c 3", "a 4", "a 5", "b 7"}},
+		{"none", 0, ascending, nil, nil},
+		{"by key", 2, descending, []int{1}, []string{"a 5", "a 4", "b 7", "b 2", "c 3"}},
+		{"by key ascending", 1, ascending, []int{1}, []string{"a 1", "b 2", "c 3"}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		stats := &pb.InstructionStat{}
+		if err := DoLocalTop(encodeRows(t, input), &out, test.n, test.orderBys, test.indexes, stats); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var got []string
+		for _, row := range decodeRows(t, &out) {
+			got = append(got, fmt.Sprintf("%v %v", row...))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: top %v, expected %v", test.name, got, test.expected)
+		}
+		if stats.InputCounter != int64(len(input)) || stats.OutputCounter != int64(len(test.expected)) {
+			t.Errorf("%s: counted %d in and %d out", test.name, stats.InputCounter, stats.OutputCounter)
+		}
+	}
+}
+
+func TestLocalTopMemoryCost(t *testing.T) {
+	tests := []struct {
+		top           *LocalTop
+		partitionSize int64
+		expected      int64
+	}{
+		{NewLocalTop(10, nil, nil), 100, 1},
+		{NewLocalTop(10*1024, nil, nil), 100, 11},
+		{NewLocalTop(1000*1024, nil, nil), 100, 100},
+		{NewLocalTop(10, nil, []int{1}), 100, 100},
+		{NewLocalTop(10, nil, []int{1}), 0, 1},
+	}
+	for _, test := range tests {
+		if got := test.top.GetMemoryCostInMB(test.partitionSize); got != test.expected {
+			t.Errorf("top %d by %v of %dMB costs %dMB, expected %dMB",
+				test.top.n, test.top.indexes, test.partitionSize, got, test.expected)
+		}
+	}
+}
@@ -52,7 +52,12 @@ func (b *MergeSortedTo) GetMemoryCostInMB(partitionSize int64) int64 {
 func DoMergeSortedTo(readers []io.Reader, writer io.Writer, orderBys []OrderBy, stats *pb.InstructionStat) error {
 	indexes := getIndexesFromOrderBys(orderBys)
 
-	pq := newMinQueueOfPairs(orderBys)
+	// the rows are compared after their sorted fields are moved first
+	keyOrderBys := make([]OrderBy, len(orderBys))
+	for i, orderBy := range orderBys {
+		keyOrderBys[i] = OrderBy{Index: i + 1, Order: orderBy.Order}
+	}
+	pq := newMinQueueOfPairs(keyOrderBys)
 
 	// enqueue one item to the pq from each channel
 	for shardId, reader := range readers {
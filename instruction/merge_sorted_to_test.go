@@ -0,0 +1,39 @@
+package instruction
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoMergeSortedTo(t *testing.T) {
+	// sorted by the second field descending, which is moved first
+	inputs := [][][]interface{}{
+		{{"a", int64(9)}, {"b", int64(4)}, {"c", int64(1)}},
+		{{"d", int64(7)}, {"e", int64(3)}},
+		{},
+	}
+	var readers []io.Reader
+	for _, rows := range inputs {
+		readers = append(readers, encodeRows(t, rows))
+	}
+	var out bytes.Buffer
+	stats := &pb.InstructionStat{}
+	orderBys := []OrderBy{{Index: 2, Order: Descending}}
+	if err := DoMergeSortedTo(readers, &out, orderBys, stats); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{int64(9), "a"}, {int64(7), "d"}, {int64(4), "b"}, {int64(3), "e"}, {int64(1), "c"},
+	}
+	if rows := decodeRows(t, &out); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("merged %v, expected %v", rows, expected)
+	}
+	if stats.InputCounter != 5 || stats.OutputCounter != 5 {
+		t.Errorf("counted %d inputs and %d outputs", stats.InputCounter, stats.OutputCounter)
+	}
+}
@@ -10,6 +10,9 @@ import (
 func init() {
 	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
 		if m.GetScatterPartitions() != nil {
+			if m.GetScatterPartitions().GetByRow() {
+				return NewScatterRows()
+			}
 			return NewScatterPartitions(
 				toInts(m.GetScatterPartitions().GetIndexes()),
 			)
@@ -20,10 +23,17 @@ func init() {
 
 type ScatterPartitions struct {
 	indexes []int
+	byRow   bool
 }
 
 func NewScatterPartitions(indexes []int) *ScatterPartitions {
-	return &ScatterPartitions{indexes}
+	return &ScatterPartitions{indexes: indexes}
+}
+
+// NewScatterRows scatters the rows by the hash of all their fields, so that
+// equal rows are in the same partition.
+func NewScatterRows() *ScatterPartitions {
+	return &ScatterPartitions{byRow: true}
 }
 
 func (b *ScatterPartitions) Name(prefix string) string {
@@ -32,6 +42,9 @@ func (b *ScatterPartitions) Name(prefix string) string {
 
 func (b *ScatterPartitions) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
 	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		if b.byRow {
+			return DoScatterRows(readers[0], writers, stats)
+		}
 		return DoScatterPartitions(readers[0], writers, b.indexes, stats)
 	}
 }
@@ -40,6 +53,7 @@ func (b *ScatterPartitions) SerializeToCommand() *pb.Instruction {
 	return &pb.Instruction{
 		ScatterPartitions: &pb.Instruction_ScatterPartitions{
 			Indexes: getIndexes(b.indexes),
+			ByRow:   b.byRow,
 		},
 	}
 }
@@ -61,3 +75,17 @@ func DoScatterPartitions(reader io.Reader, writers []io.Writer, indexes []int, s
 	})
 
 }
+
+func DoScatterRows(reader io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	shardCount := len(writers)
+
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		x := util.PartitionByKeys(shardCount, append(append([]interface{}{}, row.K...), row.V...))
+		if err := row.WriteTo(writers[x]); err == nil {
+			stats.OutputCounter++
+		}
+		return nil
+	})
+
+}
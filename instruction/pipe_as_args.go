@@ -16,7 +16,10 @@ import (
 func init() {
 	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
 		if m.GetPipeAsArgs() != nil {
-			return NewPipeAsArgs(m.GetPipeAsArgs().GetCode())
+			return &PipeAsArgs{
+				code: m.GetPipeAsArgs().GetCode(),
+				env:  m.GetPipeAsArgs().GetEnv(),
+			}
 		}
 		return nil
 	})
@@ -24,10 +27,11 @@ func init() {
 
 type PipeAsArgs struct {
 	code string
+	env  []string // added to the environment of the commands, e.g. secrets
 }
 
 func NewPipeAsArgs(code string) *PipeAsArgs {
-	return &PipeAsArgs{code}
+	return &PipeAsArgs{code: code}
 }
 
 func (b *PipeAsArgs) Name(prefix string) string {
@@ -36,7 +40,7 @@ func (b *PipeAsArgs) Name(prefix string) string {
 
 func (b *PipeAsArgs) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
 	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
-		return DoPipeAsArgs(readers[0], writers[0], b.code, stats)
+		return DoPipeAsArgs(readers[0], writers[0], b.code, b.env, stats)
 	}
 }
 
@@ -44,6 +48,7 @@ func (b *PipeAsArgs) SerializeToCommand() *pb.Instruction {
 	return &pb.Instruction{
 		PipeAsArgs: &pb.Instruction_PipeAsArgs{
 			Code: b.code,
+			Env:  b.env,
 		},
 	}
 }
@@ -52,7 +57,7 @@ func (b *PipeAsArgs) GetMemoryCostInMB(partitionSize int64) int64 {
 	return 3
 }
 
-func DoPipeAsArgs(reader io.Reader, writer io.Writer, code string, stats *pb.InstructionStat) error {
+func DoPipeAsArgs(reader io.Reader, writer io.Writer, code string, env []string, stats *pb.InstructionStat) error {
 	var wg sync.WaitGroup
 
 	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
@@ -81,6 +86,7 @@ func DoPipeAsArgs(reader io.Reader, writer io.Writer, code string, stats *pb.Ins
 		command := &script.Command{
 			Path: "sh",
 			Args: []string{"-c", actualCode},
+			Env:  env,
 		}
 		// write output to writer
 		wg.Add(1)
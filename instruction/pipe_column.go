@@ -0,0 +1,197 @@
+package instruction
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/script"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetPipeColumn() != nil {
+			return NewPipeColumn(
+				m.GetPipeColumn().GetCode(),
+				int(m.GetPipeColumn().GetArgCount()),
+			)
+		}
+		return nil
+	})
+}
+
+type PipeColumn struct {
+	code     string
+	argCount int
+}
+
+func NewPipeColumn(code string, argCount int) *PipeColumn {
+	return &PipeColumn{code, argCount}
+}
+
+func (b *PipeColumn) Name(prefix string) string {
+	return prefix + ".PipeColumn"
+}
+
+func (b *PipeColumn) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoPipeColumn(readers[0], writers[0], b.code, b.argCount, stats)
+	}
+}
+
+func (b *PipeColumn) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		PipeColumn: &pb.Instruction_PipeColumn{
+			Code:     b.code,
+			ArgCount: int32(b.argCount),
+		},
+	}
+}
+
+func (b *PipeColumn) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 3
+}
+
+// DoPipeColumn runs the code as one external program for all the rows. The
+// last argCount fields of each row are written to the program's stdin as a
+// tab-separated line, and the program answers each line with one line, in
+// order, escaped as pipeLine() escapes the fields. The rows are emitted
+// without those fields, followed by the answer.
+func DoPipeColumn(reader io.Reader, writer io.Writer, code string, argCount int, stats *pb.InstructionStat) error {
+	command := &script.Command{
+		Path: "sh",
+		Args: []string{"-c", code},
+	}
+	cmd := command.ToOsExecCommand()
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("Failed to open stdin of %s: %v", code, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("Failed to open stdout of %s: %v", code, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Failed to start %s: %v", code, err)
+	}
+
+	// the rows waiting for their answers, queued before their lines are
+	// written, so the program may buffer any number of lines
+	var mu sync.Mutex
+	var pending [][]interface{}
+	var writeErr error
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		w := bufio.NewWriterSize(stdin, util.BUFFER_SIZE)
+		writeErr = util.ProcessRow(reader, nil, func(row *util.Row) error {
+			stats.InputCounter++
+			fields := append(row.K, row.V...)
+			if len(fields) < argCount {
+				return fmt.Errorf("row %v has less than %d fields", fields, argCount)
+			}
+			mu.Lock()
+			kept := len(fields) - argCount
+			pending = append(pending, fields[:kept:kept])
+			mu.Unlock()
+			_, err := w.WriteString(pipeLine(fields[kept:]))
+			return err
+		})
+		if err := w.Flush(); writeErr == nil {
+			writeErr = err
+		}
+		stdin.Close()
+	}()
+
+	var answered int
+	lines := bufio.NewReaderSize(stdout, util.BUFFER_SIZE)
+	for {
+		// the lines can be longer than the buffer
+		line, readErr := lines.ReadString('\n')
+		if readErr != nil && (readErr != io.EOF || line == "") {
+			if readErr != io.EOF {
+				err = readErr
+			}
+			break
+		}
+		mu.Lock()
+		if len(pending) == 0 {
+			mu.Unlock()
+			err = fmt.Errorf("%s answered more lines than the rows", code)
+			break
+		}
+		fields := pending[0]
+		pending = pending[1:]
+		mu.Unlock()
+		row := util.NewRow(util.Now(), append(fields, pipeAnswer(line))...)
+		if err = row.WriteTo(writer); err != nil {
+			break
+		}
+		answered++
+		stats.OutputCounter++
+	}
+	if err != nil {
+		// stop the program, and its input
+		cmd.Process.Kill()
+		io.Copy(ioutil.Discard, stdout)
+	}
+	<-done
+	waitErr := cmd.Wait()
+	switch {
+	case err != nil:
+		return err
+	case writeErr != nil:
+		return fmt.Errorf("Failed to write to %s: %v", code, writeErr)
+	case waitErr != nil:
+		return fmt.Errorf("Failed to run %s: %v", code, waitErr)
+	case len(pending) > 0:
+		return fmt.Errorf("%s answered %d of %d rows", code, answered, answered+len(pending))
+	}
+	return nil
+}
+
+// pipeLine formats the fields as a tab-separated line, escaped like the
+// lines of SELECT ... INTO OUTFILE: NULL is \N, and the backslashes, tabs
+// and new lines in the fields are \\, \t and \n.
+func pipeLine(fields []interface{}) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		var s string
+		switch x := f.(type) {
+		case nil:
+			parts[i] = `\N`
+			continue
+		case string:
+			s = x
+		case []byte:
+			s = string(x)
+		default:
+			s = fmt.Sprint(x)
+		}
+		parts[i] = pipeEscaper.Replace(s)
+	}
+	return strings.Join(parts, "\t") + "\n"
+}
+
+var (
+	pipeEscaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	pipeUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+)
+
+// pipeAnswer reads the line answered, escaped as pipeLine() escapes the
+// fields: \N is NULL.
+func pipeAnswer(line string) interface{} {
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if line == `\N` {
+		return nil
+	}
+	return pipeUnescaper.Replace(line)
+}
@@ -0,0 +1,38 @@
+package instruction
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoPipeColumn(t *testing.T) {
+	// longer than a line bufio.Scanner reads
+	long := strings.Repeat("x", 100*1024)
+	input := [][]interface{}{
+		{"a", nil},
+		{"b", "tab\tnew line\nback\\slash"},
+		{"c", long},
+		{"d", `\N`},
+	}
+	var out bytes.Buffer
+	stats := &pb.InstructionStat{}
+	if err := DoPipeColumn(encodeRows(t, input), &out, "cat", 1, stats); err != nil {
+		t.Fatalf("pipe column: %v", err)
+	}
+	if rows := decodeRows(t, &out); !reflect.DeepEqual(rows, input) {
+		t.Errorf("piped %.200v, expected %.200v", rows, input)
+	}
+	if stats.InputCounter != 4 || stats.OutputCounter != 4 {
+		t.Errorf("counted %d inputs and %d outputs", stats.InputCounter, stats.OutputCounter)
+	}
+
+	out.Reset()
+	err := DoPipeColumn(encodeRows(t, input), &out, "head -n 3", 1, &pb.InstructionStat{})
+	if err == nil {
+		t.Errorf("expected an error for the rows not answered")
+	}
+}
@@ -0,0 +1,85 @@
+package instruction
+
+import (
+	"io"
+	"sort"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetRangeBoundaries() != nil {
+			return NewRangeBoundaries(
+				toOrderBys(m.GetRangeBoundaries().GetOrderBys()),
+				int(m.GetRangeBoundaries().GetPartitionCount()),
+			)
+		}
+		return nil
+	})
+}
+
+type RangeBoundaries struct {
+	orderBys       []OrderBy
+	partitionCount int
+}
+
+func NewRangeBoundaries(orderBys []OrderBy, partitionCount int) *RangeBoundaries {
+	return &RangeBoundaries{orderBys, partitionCount}
+}
+
+func (b *RangeBoundaries) Name(prefix string) string {
+	return prefix + ".RangeBoundaries"
+}
+
+func (b *RangeBoundaries) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoRangeBoundaries(readers, writers[0], b.orderBys, b.partitionCount, stats)
+	}
+}
+
+func (b *RangeBoundaries) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		RangeBoundaries: &pb.Instruction_RangeBoundaries{
+			OrderBys:       getOrderBys(b.orderBys),
+			PartitionCount: int32(b.partitionCount),
+		},
+	}
+}
+
+func (b *RangeBoundaries) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 5
+}
+
+// DoRangeBoundaries sorts the keys sampled by DoSampleRangeKeys() from all
+// the readers, and writes the partitionCount-1 keys splitting them into
+// partitions of about the same size, in order.
+func DoRangeBoundaries(readers []io.Reader, writer io.Writer, orderBys []OrderBy, partitionCount int, stats *pb.InstructionStat) error {
+	var samples []*util.Row
+	for _, reader := range readers {
+		err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+			stats.InputCounter++
+			samples = append(samples, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	keyOrderBys := rangeKeyOrderBys(orderBys)
+	sort.Slice(samples, func(a, b int) bool {
+		return lessThan(keyOrderBys, samples[a], samples[b])
+	})
+	for i := 1; i < partitionCount; i++ {
+		if err := samples[i*len(samples)/partitionCount].WriteTo(writer); err != nil {
+			return err
+		}
+		stats.OutputCounter++
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetReplicateHotKeys() != nil {
+			return NewReplicateHotKeys(
+				toInts(m.GetReplicateHotKeys().GetIndexes()),
+				int(m.GetReplicateHotKeys().GetSaltCount()),
+			)
+		}
+		return nil
+	})
+}
+
+type ReplicateHotKeys struct {
+	indexes   []int
+	saltCount int
+}
+
+func NewReplicateHotKeys(indexes []int, saltCount int) *ReplicateHotKeys {
+	return &ReplicateHotKeys{indexes, saltCount}
+}
+
+func (b *ReplicateHotKeys) Name(prefix string) string {
+	return prefix + ".ReplicateHotKeys"
+}
+
+func (b *ReplicateHotKeys) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoReplicateHotKeys(readers[0], readers[1], writers[0], b.indexes, b.saltCount, stats)
+	}
+}
+
+func (b *ReplicateHotKeys) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		ReplicateHotKeys: &pb.Instruction_ReplicateHotKeys{
+			Indexes:   getIndexes(b.indexes),
+			SaltCount: int32(b.saltCount),
+		},
+	}
+}
+
+func (b *ReplicateHotKeys) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 5
+}
+
+// DoReplicateHotKeys reads the hot keys of DoFilterSalted() first, then
+// emits the rows as (keys, salt, values), once for each salt from 0 to
+// saltCount-1 if their key is hot, or else with salt 0.
+func DoReplicateHotKeys(hotKeysReader, reader io.Reader, writer io.Writer, indexes []int, saltCount int,
+	stats *pb.InstructionStat) error {
+
+	hotKeys := make(map[string]bool)
+	err := util.ProcessRow(hotKeysReader, nil, func(row *util.Row) error {
+		keyBytes, err := util.EncodeKeys(append(row.K, row.V...)...)
+		if err != nil {
+			return fmt.Errorf("Failed to encoded keys %+v: %v", row.K, err)
+		}
+		hotKeys[string(keyBytes)] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return util.ProcessRow(reader, indexes, func(row *util.Row) error {
+		stats.InputCounter++
+		keyBytes, err := util.EncodeKeys(row.K...)
+		if err != nil {
+			return fmt.Errorf("Failed to encoded keys %+v: %v", row.K, err)
+		}
+		salts := 1
+		if hotKeys[string(keyBytes)] {
+			salts = saltCount
+		}
+		for salt := 0; salt < salts; salt++ {
+			err := util.NewRow(row.T).AppendKey(row.K...).AppendKey(int64(salt)).AppendValue(row.V...).WriteTo(writer)
+			if err != nil {
+				return err
+			}
+			stats.OutputCounter++
+		}
+		return nil
+	})
+}
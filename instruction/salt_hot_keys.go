@@ -0,0 +1,144 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+const (
+	// hotKeySalt is the salt of the rows announcing the hot keys.
+	hotKeySalt = int64(-1)
+	// minHotKeyCount is the least rows of a hot key in the sample, so the
+	// keys of a small shard are not hot.
+	minHotKeyCount = 10
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetSaltHotKeys() != nil {
+			return NewSaltHotKeys(
+				toInts(m.GetSaltHotKeys().GetIndexes()),
+				int(m.GetSaltHotKeys().GetSaltCount()),
+				int(m.GetSaltHotKeys().GetSampleSize()),
+				m.GetSaltHotKeys().GetHotRatio(),
+			)
+		}
+		return nil
+	})
+}
+
+type SaltHotKeys struct {
+	indexes    []int
+	saltCount  int
+	sampleSize int
+	hotRatio   float64
+}
+
+func NewSaltHotKeys(indexes []int, saltCount, sampleSize int, hotRatio float64) *SaltHotKeys {
+	return &SaltHotKeys{indexes, saltCount, sampleSize, hotRatio}
+}
+
+func (b *SaltHotKeys) Name(prefix string) string {
+	return prefix + ".SaltHotKeys"
+}
+
+func (b *SaltHotKeys) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoSaltHotKeys(readers[0], writers[0], b.indexes, b.saltCount, b.sampleSize, b.hotRatio, stats)
+	}
+}
+
+func (b *SaltHotKeys) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		SaltHotKeys: &pb.Instruction_SaltHotKeys{
+			Indexes:    getIndexes(b.indexes),
+			SaltCount:  int32(b.saltCount),
+			SampleSize: int32(b.sampleSize),
+			HotRatio:   b.hotRatio,
+		},
+	}
+}
+
+func (b *SaltHotKeys) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 5
+}
+
+// DoSaltHotKeys emits the rows as (keys, salt, values). The hot keys are the
+// keys of at least hotRatio*sampleSize, and minHotKeyCount, of the first
+// sampleSize rows. Their rows are salted in turn from 0 to saltCount-1, and
+// the other rows by 0. The hot keys are announced first, by rows (keys, -1).
+func DoSaltHotKeys(reader io.Reader, writer io.Writer, indexes []int, saltCount, sampleSize int, hotRatio float64,
+	stats *pb.InstructionStat) error {
+
+	var sample []*util.Row
+	var sampleKeys []string
+	counts := make(map[string]int)
+	var salts map[string]int
+
+	salt := func(row *util.Row, key string) error {
+		s := int64(0)
+		if next, isHot := salts[key]; isHot {
+			s = int64(next)
+			salts[key] = (next + 1) % saltCount
+		}
+		stats.OutputCounter++
+		return util.NewRow(row.T).AppendKey(row.K...).AppendKey(s).AppendValue(row.V...).WriteTo(writer)
+	}
+
+	// the threshold does not shrink with the sample of a small shard
+	threshold := hotRatio * float64(sampleSize)
+	if threshold < minHotKeyCount {
+		threshold = minHotKeyCount
+	}
+
+	// announce the hot keys of the sample, and salt its rows
+	flushSample := func() error {
+		salts = make(map[string]int)
+		for i, row := range sample {
+			key := sampleKeys[i]
+			if _, found := salts[key]; found || float64(counts[key]) < threshold {
+				continue
+			}
+			salts[key] = 0
+			if err := util.NewRow(row.T).AppendKey(row.K...).AppendKey(hotKeySalt).WriteTo(writer); err != nil {
+				return err
+			}
+		}
+		for i, row := range sample {
+			if err := salt(row, sampleKeys[i]); err != nil {
+				return err
+			}
+		}
+		sample, sampleKeys = nil, nil
+		return nil
+	}
+
+	err := util.ProcessRow(reader, indexes, func(row *util.Row) error {
+		stats.InputCounter++
+		keyBytes, err := util.EncodeKeys(row.K...)
+		if err != nil {
+			return fmt.Errorf("Failed to encoded keys %+v: %v", row.K, err)
+		}
+		key := string(keyBytes)
+		if salts != nil {
+			return salt(row, key)
+		}
+		sample = append(sample, row)
+		sampleKeys = append(sampleKeys, key)
+		counts[key]++
+		if len(sample) >= sampleSize {
+			return flushSample()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if salts == nil {
+		return flushSample()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package instruction
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoSaltHotKeys(t *testing.T) {
+	repeat := func(key string, count int) (rows [][]interface{}) {
+		for i := 0; i < count; i++ {
+			rows = append(rows, []interface{}{key, int64(i)})
+		}
+		return
+	}
+	tests := []struct {
+		name       string
+		input      [][]interface{}
+		sampleSize int
+		hotRatio   float64
+		expected   []string
+	}{
+		{
+			// every key of a small shard has more than 1% of its rows
+			name:       "small shard",
+			input:      [][]interface{}{{"a", int64(0)}, {"a", int64(1)}, {"b", int64(2)}},
+			sampleSize: 100,
+			hotRatio:   0.01,
+			expected:   []string{"a 0 0", "a 0 1", "b 0 2"},
+		},
+		{
+			name:       "hot key in the sample",
+			input:      append(repeat("a", 11), []interface{}{"b", int64(0)}),
+			sampleSize: 20,
+			hotRatio:   0.1,
+			expected: []string{"a -1", "a 0 0", "a 1 1", "a 2 2", "a 0 3", "a 1 4", "a 2 5",
+				"a 0 6", "a 1 7", "a 2 8", "a 0 9", "a 1 10", "b 0 0"},
+		},
+		{
+			name:       "hot key after the sample",
+			input:      append(repeat("a", 12), []interface{}{"a", int64(12)}, []interface{}{"b", int64(0)}),
+			sampleSize: 12,
+			hotRatio:   0.5,
+			expected: []string{"a -1", "a 0 0", "a 1 1", "a 2 2", "a 0 3", "a 1 4", "a 2 5",
+				"a 0 6", "a 1 7", "a 2 8", "a 0 9", "a 1 10", "a 2 11", "a 0 12", "b 0 0"},
+		},
+		{
+			// the share of the sample, but less than minHotKeyCount rows
+			name:       "few rows",
+			input:      append(repeat("a", 9), []interface{}{"b", int64(0)}),
+			sampleSize: 10,
+			hotRatio:   0.5,
+			expected:   []string{"a 0 0", "a 0 1", "a 0 2", "a 0 3", "a 0 4", "a 0 5", "a 0 6", "a 0 7", "a 0 8", "b 0 0"},
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		stats := &pb.InstructionStat{}
+		err := DoSaltHotKeys(encodeRows(t, test.input), &out, []int{1}, 3, test.sampleSize, test.hotRatio, stats)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var got []string
+		for _, row := range decodeRows(t, &out) {
+			got = append(got, strings.TrimSpace(fmt.Sprintln(row...)))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: salted %v, expected %v", test.name, got, test.expected)
+		}
+		if stats.InputCounter != int64(len(test.input)) || stats.OutputCounter != int64(len(test.input)) {
+			t.Errorf("%s: counted %d inputs and %d outputs", test.name, stats.InputCounter, stats.OutputCounter)
+		}
+	}
+}
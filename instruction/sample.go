@@ -0,0 +1,116 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetSample() != nil {
+			return NewSample(
+				m.GetSample().GetFraction(),
+				m.GetSample().GetSeed(),
+				m.GetSample().GetByKey(),
+				m.GetSample().GetKeys(),
+				m.GetSample().GetFractions(),
+			)
+		}
+		return nil
+	})
+}
+
+type Sample struct {
+	fraction  float64
+	seed      int64
+	byKey     bool
+	keys      []string
+	fractions []float64
+}
+
+// NewSample samples the rows with the fraction, or with byKey, with the
+// fractions of the keys, the first fields.
+func NewSample(fraction float64, seed int64, byKey bool, keys []string, fractions []float64) *Sample {
+	return &Sample{fraction, seed, byKey, keys, fractions}
+}
+
+func (b *Sample) Name(prefix string) string {
+	return prefix + ".Sample"
+}
+
+func (b *Sample) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoSample(readers[0], writers[0], b.fraction, b.seed, b.byKey, b.keys, b.fractions, stats)
+	}
+}
+
+func (b *Sample) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		Sample: &pb.Instruction_Sample{
+			Fraction:  b.fraction,
+			Seed:      b.seed,
+			ByKey:     b.byKey,
+			Keys:      b.keys,
+			Fractions: b.fractions,
+		},
+	}
+}
+
+func (b *Sample) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoSample keeps each row with the probability fraction, or with byKey,
+// the fraction of its key, or 0 for the keys without fractions. The random
+// numbers are seeded by the seed and the first row, so the shards are not
+// sampled alike, and the same input is sampled the same.
+func DoSample(reader io.Reader, writer io.Writer, fraction float64, seed int64, byKey bool, keys []string, fractions []float64,
+	stats *pb.InstructionStat) error {
+
+	keyFractions := make(map[string]float64)
+	for i, key := range keys {
+		if i < len(fractions) {
+			keyFractions[key] = fractions[i]
+		}
+	}
+
+	var random *rand.Rand
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		fields := append(row.K, row.V...)
+		if random == nil {
+			encoded, err := util.EncodeKeys(fields...)
+			if err != nil {
+				return fmt.Errorf("Failed to encode row %+v: %v", fields, err)
+			}
+			random = rand.New(rand.NewSource(seed ^ int64(util.Hash(encoded))))
+		}
+		f := fraction
+		if byKey {
+			f = 0
+			if len(fields) > 0 {
+				f = keyFractions[sampleKey(fields[0])]
+			}
+		}
+		if random.Float64() >= f {
+			return nil
+		}
+		stats.OutputCounter++
+		return row.WriteTo(writer)
+	})
+}
+
+// sampleKey is the key of the fractions of the rows with the field.
+func sampleKey(field interface{}) string {
+	switch x := field.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	}
+	return fmt.Sprint(field)
+}
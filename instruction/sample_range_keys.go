@@ -0,0 +1,79 @@
+package instruction
+
+import (
+	"io"
+	"math/rand"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetSampleRangeKeys() != nil {
+			return NewSampleRangeKeys(
+				toOrderBys(m.GetSampleRangeKeys().GetOrderBys()),
+				int(m.GetSampleRangeKeys().GetSize()),
+			)
+		}
+		return nil
+	})
+}
+
+type SampleRangeKeys struct {
+	orderBys []OrderBy
+	size     int
+}
+
+func NewSampleRangeKeys(orderBys []OrderBy, size int) *SampleRangeKeys {
+	return &SampleRangeKeys{orderBys, size}
+}
+
+func (b *SampleRangeKeys) Name(prefix string) string {
+	return prefix + ".SampleRangeKeys"
+}
+
+func (b *SampleRangeKeys) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoSampleRangeKeys(readers[0], writers[0], b.orderBys, b.size, stats)
+	}
+}
+
+func (b *SampleRangeKeys) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		SampleRangeKeys: &pb.Instruction_SampleRangeKeys{
+			OrderBys: getOrderBys(b.orderBys),
+			Size:     int32(b.size),
+		},
+	}
+}
+
+func (b *SampleRangeKeys) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 5
+}
+
+// DoSampleRangeKeys keeps a uniform sample of at most size keys of the rows,
+// the fields ordered by, and writes them as rows of only keys.
+func DoSampleRangeKeys(reader io.Reader, writer io.Writer, orderBys []OrderBy, size int, stats *pb.InstructionStat) error {
+	var samples [][]interface{}
+	random := rand.New(rand.NewSource(int64(size)))
+	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		if len(samples) < size {
+			samples = append(samples, rangeKeys(orderBys, row))
+		} else if i := random.Int63n(stats.InputCounter); i < int64(size) {
+			samples[i] = rangeKeys(orderBys, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, keys := range samples {
+		if err := (&util.Row{K: keys}).WriteTo(writer); err != nil {
+			return err
+		}
+		stats.OutputCounter++
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetScatterRanges() != nil {
+			return NewScatterRanges(
+				toOrderBys(m.GetScatterRanges().GetOrderBys()),
+			)
+		}
+		return nil
+	})
+}
+
+type ScatterRanges struct {
+	orderBys []OrderBy
+}
+
+func NewScatterRanges(orderBys []OrderBy) *ScatterRanges {
+	return &ScatterRanges{orderBys}
+}
+
+func (b *ScatterRanges) Name(prefix string) string {
+	return prefix + ".ScatterRanges"
+}
+
+func (b *ScatterRanges) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoScatterRanges(readers[0], readers[1], writers, b.orderBys, stats)
+	}
+}
+
+func (b *ScatterRanges) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		ScatterRanges: &pb.Instruction_ScatterRanges{
+			OrderBys: getOrderBys(b.orderBys),
+		},
+	}
+}
+
+func (b *ScatterRanges) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 5
+}
+
+// DoScatterRanges reads the boundaries of DoRangeBoundaries() first, then
+// writes each row to the writer of its range: the first writer for the rows
+// before the first boundary, the next writer from each boundary on. The
+// order of the rows is kept, so sorted rows stay sorted in each range.
+// The rows are kept in a temporary file while waiting for the boundaries,
+// which are sampled from the same rows. As the rows are sorted, the writer of
+// each range is closed once the rows pass it, so the readers merging the
+// range from all the shards do not wait for the rows of the later ranges.
+func DoScatterRanges(boundariesReader, reader io.Reader, writers []io.Writer, orderBys []OrderBy, stats *pb.InstructionStat) error {
+	file, err := spillRows(reader, "gleam-scatter-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var boundaries []*util.Row
+	err = util.ProcessRow(boundariesReader, nil, func(row *util.Row) error {
+		boundaries = append(boundaries, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keyOrderBys := rangeKeyOrderBys(orderBys)
+	current := 0
+	return util.ProcessRow(file, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		keys := &util.Row{K: rangeKeys(orderBys, row)}
+		x := sort.Search(len(boundaries), func(i int) bool {
+			return lessThan(keyOrderBys, keys, boundaries[i])
+		})
+		if x >= len(writers) {
+			x = len(writers) - 1
+		}
+		if x < current {
+			return fmt.Errorf("row %v is not sorted, but in range %d after range %d", row.K, x, current)
+		}
+		for ; current < x; current++ {
+			if c, ok := writers[current].(io.Closer); ok {
+				c.Close()
+			}
+		}
+		if err := row.WriteTo(writers[x]); err != nil {
+			return err
+		}
+		stats.OutputCounter++
+		return nil
+	})
+}
+
+// rangeKeys are the fields of the row ordered by, in order.
+func rangeKeys(orderBys []OrderBy, row *util.Row) (keys []interface{}) {
+	klen := len(row.K)
+	for _, order := range orderBys {
+		if order.Index <= klen {
+			keys = append(keys, row.K[order.Index-1])
+		} else {
+			keys = append(keys, row.V[order.Index-1-klen])
+		}
+	}
+	return
+}
+
+// rangeKeyOrderBys order the rows of rangeKeys() as the orderBys order
+// the rows they are from.
+func rangeKeyOrderBys(orderBys []OrderBy) (keyOrderBys []OrderBy) {
+	for i, order := range orderBys {
+		keyOrderBys = append(keyOrderBys, OrderBy{Index: i + 1, Order: order.Order})
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package instruction
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// rangeWriter records the rows written to a range, and whether it is closed.
+type rangeWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *rangeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestDoScatterRanges(t *testing.T) {
+	orderBys := []OrderBy{{Index: 1, Order: Ascending}}
+	boundaries := encodeRows(t, [][]interface{}{{int64(3)}, {int64(6)}})
+	rows := encodeRows(t, [][]interface{}{
+		{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}, {int64(7), "d"}, {int64(8), "e"},
+	})
+	writers := []*rangeWriter{{}, {}, {}}
+	stats := &pb.InstructionStat{}
+	if err := DoScatterRanges(boundaries, rows, []io.Writer{writers[0], writers[1], writers[2]}, orderBys, stats); err != nil {
+		t.Fatalf("scatter: %v", err)
+	}
+
+	expected := [][][]interface{}{
+		{{int64(1), "a"}, {int64(2), "b"}},
+		{{int64(3), "c"}},
+		{{int64(7), "d"}, {int64(8), "e"}},
+	}
+	for i, writer := range writers {
+		if got := decodeRows(t, &writer.Buffer); !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("range %d has %v, expected %v", i, got, expected[i])
+		}
+	}
+	// the passed ranges are ended, the last one by the caller
+	if !writers[0].closed || !writers[1].closed || writers[2].closed {
+		t.Errorf("closed the ranges %v %v %v", writers[0].closed, writers[1].closed, writers[2].closed)
+	}
+	if stats.InputCounter != 5 || stats.OutputCounter != 5 {
+		t.Errorf("counted %d inputs and %d outputs", stats.InputCounter, stats.OutputCounter)
+	}
+
+	// the rows can not go back to a closed range
+	unsorted := encodeRows(t, [][]interface{}{{int64(7), "d"}, {int64(1), "a"}})
+	boundaries = encodeRows(t, [][]interface{}{{int64(3)}, {int64(6)}})
+	err := DoScatterRanges(boundaries, unsorted, []io.Writer{&rangeWriter{}, &rangeWriter{}, &rangeWriter{}}, orderBys, &pb.InstructionStat{})
+	if err == nil || !strings.Contains(err.Error(), "not sorted") {
+		t.Errorf("scattered unsorted rows with %v", err)
+	}
+}
@@ -60,20 +60,11 @@ func DoSelect(reader io.Reader, writer io.Writer, keyIndexes, valueIndexes []int
 		stats.InputCounter++
 
 		var keys, values []interface{}
-		kLen := len(row.K)
 		for _, x := range keyIndexes {
-			if x <= kLen {
-				keys = append(keys, row.K[x-1])
-			} else {
-				keys = append(keys, row.V[x-1-kLen])
-			}
+			keys = append(keys, field(row, x))
 		}
 		for _, x := range valueIndexes {
-			if x <= kLen {
-				values = append(values, row.K[x-1])
-			} else {
-				values = append(values, row.V[x-1-kLen])
-			}
+			values = append(values, field(row, x))
 		}
 		row.K, row.V = keys, values
 
@@ -93,3 +84,16 @@ func joinInts(a []int, sep string) string {
 
 	return strings.Join(b, sep)
 }
+
+// field returns the 1-based field of the row. Missing fields are nil,
+// e.g. the values of the missing side of outer joins on an empty shard.
+func field(row *util.Row, x int) interface{} {
+	kLen := len(row.K)
+	if x <= kLen {
+		return row.K[x-1]
+	}
+	if x-1-kLen < len(row.V) {
+		return row.V[x-1-kLen]
+	}
+	return nil
+}
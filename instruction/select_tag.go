@@ -0,0 +1,77 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetSelectTag() != nil {
+			return NewSelectTag(int(m.GetSelectTag().GetTag()))
+		}
+		return nil
+	})
+}
+
+type SelectTag struct {
+	tag int
+}
+
+func NewSelectTag(tag int) *SelectTag {
+	return &SelectTag{tag}
+}
+
+func (b *SelectTag) Name(prefix string) string {
+	return fmt.Sprintf("%s.SelectTag%d", prefix, b.tag)
+}
+
+func (b *SelectTag) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoSelectTag(readers[0], writers[0], b.tag, stats)
+	}
+}
+
+func (b *SelectTag) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		SelectTag: &pb.Instruction_SelectTag{
+			Tag: int32(b.tag),
+		},
+	}
+}
+
+func (b *SelectTag) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoSelectTag keeps the rows tagged by the tag, the first field, and
+// removes the tag. The other rows, and the rows with only a tag, are counted
+// as filtered.
+func DoSelectTag(reader io.Reader, writer io.Writer, tag int, stats *pb.InstructionStat) error {
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		fields := append(row.K, row.V...)
+		if len(fields) == 0 || !isTag(fields[0], tag) {
+			stats.FilteredCounter++
+			return nil
+		}
+		fields = fields[1:]
+		if len(fields) == 0 {
+			stats.FilteredCounter++
+			return nil
+		}
+		stats.OutputCounter++
+		return util.NewRow(row.T).AppendKey(fields[0]).AppendValue(fields[1:]...).WriteTo(writer)
+	})
+}
+
+func isTag(value interface{}, tag int) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return util.ToInt64(value) == int64(tag)
+	}
+	return false
+}
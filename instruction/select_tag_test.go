@@ -0,0 +1,42 @@
+package instruction
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestDoSelectTag(t *testing.T) {
+	rows := [][]interface{}{
+		{int64(0), "a", int64(1)},
+		{int64(1), "b"},
+		{int64(0), "c"},
+		{"0", "not a tag"},
+		{int64(0)}, // only a tag
+		{int64(2), "d"},
+	}
+	for _, test := range []struct {
+		tag      int
+		expected [][]interface{}
+		filtered int64
+	}{
+		{0, [][]interface{}{{"a", int64(1)}, {"c"}}, 4},
+		{1, [][]interface{}{{"b"}}, 5},
+		{3, nil, 6},
+	} {
+		var out bytes.Buffer
+		stats := &pb.InstructionStat{}
+		if err := DoSelectTag(encodeRows(t, rows), &out, test.tag, stats); err != nil {
+			t.Fatalf("select tag %d: %v", test.tag, err)
+		}
+		if got := decodeRows(t, &out); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("tag %d selected %v, expected %v", test.tag, got, test.expected)
+		}
+		if stats.InputCounter != int64(len(rows)) || stats.FilteredCounter != test.filtered ||
+			stats.OutputCounter != int64(len(test.expected)) {
+			t.Errorf("tag %d counted %+v", test.tag, stats)
+		}
+	}
+}
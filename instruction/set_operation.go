@@ -0,0 +1,164 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// The operations of SetOperation.
+const (
+	SetUnion     = "union"
+	SetIntersect = "intersect"
+	SetSubtract  = "subtract"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetSetOperation() != nil {
+			return NewSetOperation(
+				m.GetSetOperation().GetOperation(),
+				m.GetSetOperation().GetKeepDuplicates(),
+			)
+		}
+		return nil
+	})
+}
+
+// SetOperation merges the inputs, which are partitioned by the whole rows, as
+// sets of rows. Rows are equal if all their fields are equal, NULL included.
+type SetOperation struct {
+	operation      string
+	keepDuplicates bool
+}
+
+// NewSetOperation unions all the inputs, or keeps the rows of the first input
+// that are also in the second input for SetIntersect, or that are not for
+// SetSubtract. If keepDuplicates, a row is kept as many times as it is in the
+// result of the operation counting duplicates, like UNION ALL, INTERSECT ALL
+// and EXCEPT ALL. Otherwise each row is kept once.
+func NewSetOperation(operation string, keepDuplicates bool) *SetOperation {
+	return &SetOperation{operation, keepDuplicates}
+}
+
+func (b *SetOperation) Name(prefix string) string {
+	return prefix + ".SetOperation"
+}
+
+func (b *SetOperation) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoSetOperation(readers, writers[0], b.operation, b.keepDuplicates, stats)
+	}
+}
+
+func (b *SetOperation) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		SetOperation: &pb.Instruction_SetOperation{
+			Operation:      b.operation,
+			KeepDuplicates: b.keepDuplicates,
+		},
+	}
+}
+
+func (b *SetOperation) GetMemoryCostInMB(partitionSize int64) int64 {
+	return int64(float32(partitionSize) * 1.1)
+}
+
+func DoSetOperation(readers []io.Reader, writer io.Writer, operation string, keepDuplicates bool,
+	stats *pb.InstructionStat) error {
+
+	write := func(row *util.Row) error {
+		if err := row.WriteTo(writer); err != nil {
+			return fmt.Errorf("SetOperation>Failed to write: %v", err)
+		}
+		stats.OutputCounter++
+		return nil
+	}
+
+	if operation == SetUnion {
+		seen := make(map[string]bool)
+		for _, reader := range readers {
+			err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+				stats.InputCounter++
+				if !keepDuplicates {
+					key, err := rowKey(row)
+					if err != nil {
+						return err
+					}
+					if seen[key] {
+						return nil
+					}
+					seen[key] = true
+				}
+				return write(row)
+			})
+			if err != nil {
+				fmt.Printf("SetOperation>Failed to read input data:%v\n", err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	if operation != SetIntersect && operation != SetSubtract {
+		return fmt.Errorf("SetOperation>Unknown operation %s", operation)
+	}
+	if len(readers) != 2 {
+		return fmt.Errorf("SetOperation>%s needs 2 inputs, not %d", operation, len(readers))
+	}
+
+	// count the rows of the second input
+	counts := make(map[string]int)
+	err := util.ProcessRow(readers[1], nil, func(row *util.Row) error {
+		stats.InputCounter++
+		key, err := rowKey(row)
+		if err != nil {
+			return err
+		}
+		counts[key]++
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("SetOperation>Failed to read the other input data:%v\n", err)
+		return err
+	}
+
+	written := make(map[string]bool)
+	err = util.ProcessRow(readers[0], nil, func(row *util.Row) error {
+		stats.InputCounter++
+		key, err := rowKey(row)
+		if err != nil {
+			return err
+		}
+		found := counts[key] > 0
+		if keepDuplicates && found {
+			counts[key]--
+		}
+		if found != (operation == SetIntersect) {
+			return nil
+		}
+		if !keepDuplicates {
+			if written[key] {
+				return nil
+			}
+			written[key] = true
+		}
+		return write(row)
+	})
+	if err != nil {
+		fmt.Printf("SetOperation>Failed to read input data:%v\n", err)
+	}
+	return err
+}
+
+// rowKey encodes all the fields of the row.
+func rowKey(row *util.Row) (string, error) {
+	fields := append(append([]interface{}{}, row.K...), row.V...)
+	keyBytes, err := util.EncodeKeys(fields...)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encoded row %+v: %v", fields, err)
+	}
+	return string(keyBytes), nil
+}
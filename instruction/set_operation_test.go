@@ -0,0 +1,77 @@
+package instruction
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestDoSetOperation(t *testing.T) {
+	left := [][]interface{}{{"a", int64(1)}, {"a", int64(1)}, {"b", nil}, {"c", int64(3)}}
+	right := [][]interface{}{{"a", int64(1)}, {"b", nil}, {"d", int64(4)}}
+
+	tests := []struct {
+		operation      string
+		keepDuplicates bool
+		expected       [][]interface{}
+	}{
+		{SetUnion, false, [][]interface{}{{"a", int64(1)}, {"b", nil}, {"c", int64(3)}, {"d", int64(4)}}},
+		{SetUnion, true, append(append([][]interface{}{}, left...), right...)},
+		{SetIntersect, false, [][]interface{}{{"a", int64(1)}, {"b", nil}}},
+		{SetIntersect, true, [][]interface{}{{"a", int64(1)}, {"b", nil}}},
+		{SetSubtract, false, [][]interface{}{{"c", int64(3)}}},
+		{SetSubtract, true, [][]interface{}{{"a", int64(1)}, {"c", int64(3)}}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		stats := &pb.InstructionStat{}
+		err := DoSetOperation([]io.Reader{encodeRows(t, left), encodeRows(t, right)}, &out,
+			test.operation, test.keepDuplicates, stats)
+		if err != nil {
+			t.Errorf("%s keepDuplicates=%v: %v", test.operation, test.keepDuplicates, err)
+			continue
+		}
+		rows := decodeRows(t, &out)
+		if !reflect.DeepEqual(rows, test.expected) {
+			t.Errorf("%s keepDuplicates=%v: %v, expected %v", test.operation, test.keepDuplicates, rows, test.expected)
+		}
+		if stats.InputCounter != int64(len(left)+len(right)) || stats.OutputCounter != int64(len(rows)) {
+			t.Errorf("%s keepDuplicates=%v: counted %d inputs and %d outputs", test.operation, test.keepDuplicates,
+				stats.InputCounter, stats.OutputCounter)
+		}
+	}
+
+	if err := DoSetOperation([]io.Reader{encodeRows(t, left)}, &bytes.Buffer{}, SetIntersect, false,
+		&pb.InstructionStat{}); err == nil {
+		t.Errorf("expecting an error intersecting one input")
+	}
+	if err := DoSetOperation([]io.Reader{encodeRows(t, left), encodeRows(t, right)}, &bytes.Buffer{}, "xor", false,
+		&pb.InstructionStat{}); err == nil {
+		t.Errorf("expecting an error for an unknown operation")
+	}
+}
+
+func encodeRows(t *testing.T, rows [][]interface{}) io.Reader {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		if err := util.NewRow(util.Now(), row...).WriteTo(&buf); err != nil {
+			t.Fatalf("encode %v: %v", row, err)
+		}
+	}
+	return &buf
+}
+
+func decodeRows(t *testing.T, reader io.Reader) (rows [][]interface{}) {
+	err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+		rows = append(rows, append(append([]interface{}{}, row.K...), row.V...))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return rows
+}
@@ -0,0 +1,70 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetShardOffsets() != nil {
+			return NewShardOffsets()
+		}
+		return nil
+	})
+}
+
+type ShardOffsets struct {
+}
+
+func NewShardOffsets() *ShardOffsets {
+	return &ShardOffsets{}
+}
+
+func (b *ShardOffsets) Name(prefix string) string {
+	return prefix + ".ShardOffsets"
+}
+
+func (b *ShardOffsets) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoShardOffsets(readers, writers, stats)
+	}
+}
+
+func (b *ShardOffsets) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		ShardOffsets: &pb.Instruction_ShardOffsets{},
+	}
+}
+
+func (b *ShardOffsets) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoShardOffsets reads the row counts of DoCountRows() from each reader, and
+// writes to each writer the number of rows of the readers before it.
+func DoShardOffsets(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	var offset int64
+	for i, reader := range readers {
+		var count int64
+		err := util.ProcessRow(reader, nil, func(row *util.Row) error {
+			stats.InputCounter++
+			count += util.ToInt64(row.K[0])
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to read row count of shard %d: %v", i, err)
+		}
+		if i < len(writers) {
+			if err := util.NewRow(util.Now(), offset).WriteTo(writers[i]); err != nil {
+				return err
+			}
+			stats.OutputCounter++
+		}
+		offset += count
+	}
+	return nil
+}
@@ -50,24 +50,35 @@ func (b *Union) GetMemoryCostInMB(partitionSize int64) int64 {
 	return 3
 }
 
+// DoUnion copies the rows of all readers to the writer, and returns the
+// first error of reading or writing them.
 func DoUnion(readers []io.Reader, writer io.Writer, isParallel bool,
 	stats *pb.InstructionStat) error {
 
 	var mutex sync.Mutex
 	var wg sync.WaitGroup
+	var firstErr error
 	wg.Add(len(readers))
-	defer wg.Wait()
 
 	procReader := func(reader io.Reader) {
-		util.ProcessRow(reader, nil, func(row *util.Row) error {
+		defer wg.Done()
+		err := util.ProcessRow(reader, nil, func(row *util.Row) error {
 			mutex.Lock()
-			row.WriteTo(writer)
+			defer mutex.Unlock()
 			stats.InputCounter++
+			if err := row.WriteTo(writer); err != nil {
+				return err
+			}
 			stats.OutputCounter++
-			mutex.Unlock()
 			return nil
 		})
-		wg.Done()
+		if err != nil {
+			mutex.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			mutex.Unlock()
+		}
 	}
 
 	if isParallel {
@@ -82,5 +93,6 @@ func DoUnion(readers []io.Reader, writer io.Writer, isParallel bool,
 		}()
 	}
 
-	return nil
+	wg.Wait()
+	return firstErr
 }
@@ -0,0 +1,60 @@
+package instruction
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetUnsalt() != nil {
+			return NewUnsalt(int(m.GetUnsalt().GetKeyCount()))
+		}
+		return nil
+	})
+}
+
+type Unsalt struct {
+	keyCount int
+}
+
+func NewUnsalt(keyCount int) *Unsalt {
+	return &Unsalt{keyCount}
+}
+
+func (b *Unsalt) Name(prefix string) string {
+	return prefix + ".Unsalt"
+}
+
+func (b *Unsalt) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		return DoUnsalt(readers[0], writers[0], b.keyCount, stats)
+	}
+}
+
+func (b *Unsalt) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		Unsalt: &pb.Instruction_Unsalt{
+			KeyCount: int32(b.keyCount),
+		},
+	}
+}
+
+func (b *Unsalt) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoUnsalt removes the salt following the keyCount keys of the rows.
+func DoUnsalt(reader io.Reader, writer io.Writer, keyCount int, stats *pb.InstructionStat) error {
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		stats.InputCounter++
+		fields := append(row.K, row.V...)
+		if len(fields) > keyCount {
+			fields = append(fields[:keyCount], fields[keyCount+1:]...)
+		}
+		stats.OutputCounter++
+		return util.NewRow(row.T).AppendKey(fields[:keyCount]...).AppendValue(fields[keyCount:]...).WriteTo(writer)
+	})
+}
@@ -83,3 +83,55 @@ func max(x, y int64) int64 {
 	}
 	return y
 }
+
+// InputFieldIndexes returns the 1-based fields the instruction reads by index
+// from the rows of each of its inputs, or nil for the inputs it does not read
+// by index, so they can be checked before the instruction runs.
+func InputFieldIndexes(ins Instruction, inputCount int) [][]int {
+	m := ins.SerializeToCommand()
+	var indexes []int
+	switch {
+	case m.GetSelect() != nil:
+		indexes = append(toInts(m.GetSelect().GetKeyIndexes()), toInts(m.GetSelect().GetValueIndexes())...)
+	case m.GetScatterPartitions() != nil:
+		indexes = toInts(m.GetScatterPartitions().GetIndexes())
+	case m.GetLocalTop() != nil:
+		indexes = append(toInts(m.GetLocalTop().GetIndexes()), getIndexesFromOrderBys(toOrderBys(m.GetLocalTop().GetOrderBys()))...)
+	case m.GetLocalSort() != nil:
+		indexes = getIndexesFromOrderBys(toOrderBys(m.GetLocalSort().GetOrderBys()))
+	case m.GetMergeSortedTo() != nil:
+		indexes = getIndexesFromOrderBys(toOrderBys(m.GetMergeSortedTo().GetOrderBys()))
+	case m.GetLocalDistinct() != nil:
+		indexes = getIndexesFromOrderBys(toOrderBys(m.GetLocalDistinct().GetOrderBys()))
+	case m.GetLocalGroupBySorted() != nil:
+		indexes = toInts(m.GetLocalGroupBySorted().GetIndexes())
+	case m.GetSaltHotKeys() != nil:
+		indexes = toInts(m.GetSaltHotKeys().GetIndexes())
+	case m.GetCountNullKeys() != nil:
+		indexes = toInts(m.GetCountNullKeys().GetIndexes())
+	case m.GetSampleRangeKeys() != nil:
+		indexes = getIndexesFromOrderBys(toOrderBys(m.GetSampleRangeKeys().GetOrderBys()))
+	case m.GetScatterRanges() != nil:
+		return [][]int{nil, getIndexesFromOrderBys(toOrderBys(m.GetScatterRanges().GetOrderBys()))}
+	case m.GetReplicateHotKeys() != nil:
+		return [][]int{nil, toInts(m.GetReplicateHotKeys().GetIndexes())}
+	case m.GetLocalExists() != nil:
+		return [][]int{toInts(m.GetLocalExists().GetIndexes()), toInts(m.GetLocalExists().GetThatIndexes())}
+	case m.GetJoinPartitionedSorted() != nil:
+		indexes := toInts(m.GetJoinPartitionedSorted().GetIndexes())
+		return [][]int{indexes, indexes}
+	case m.GetLocalHashAndJoinWith() != nil:
+		indexes := toInts(m.GetLocalHashAndJoinWith().GetIndexes())
+		return [][]int{indexes, indexes}
+	case m.GetCoGroupPartitionedSorted() != nil:
+		ret := make([][]int, inputCount)
+		for i := range ret {
+			ret[i] = toInts(m.GetCoGroupPartitionedSorted().GetIndexes())
+		}
+		return ret
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	return [][]int{indexes}
+}
@@ -0,0 +1,129 @@
+package instruction
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+func init() {
+	InstructionRunner.Register(func(m *pb.Instruction) Instruction {
+		if m.GetZipWithIndex() != nil {
+			return NewZipWithIndex(m.GetZipWithIndex().GetUniqueId())
+		}
+		return nil
+	})
+}
+
+// uniqueIdShardShift puts the shard id above the row index in unique ids,
+// leaving room for 2^40 rows in each shard.
+const uniqueIdShardShift = 40
+
+type ZipWithIndex struct {
+	uniqueId bool
+}
+
+func NewZipWithIndex(uniqueId bool) *ZipWithIndex {
+	return &ZipWithIndex{uniqueId}
+}
+
+func (b *ZipWithIndex) Name(prefix string) string {
+	if b.uniqueId {
+		return prefix + ".ZipWithUniqueId"
+	}
+	return prefix + ".ZipWithIndex"
+}
+
+func (b *ZipWithIndex) Function() func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+	return func(readers []io.Reader, writers []io.Writer, stats *pb.InstructionStat) error {
+		if b.uniqueId {
+			return DoZipWithUniqueId(readers[0], writers[0], int64(stats.TaskId), stats)
+		}
+		return DoZipWithIndex(readers[0], readers[1], writers[0], stats)
+	}
+}
+
+func (b *ZipWithIndex) SerializeToCommand() *pb.Instruction {
+	return &pb.Instruction{
+		ZipWithIndex: &pb.Instruction_ZipWithIndex{
+			UniqueId: b.uniqueId,
+		},
+	}
+}
+
+func (b *ZipWithIndex) GetMemoryCostInMB(partitionSize int64) int64 {
+	return 1
+}
+
+// DoZipWithIndex appends the global index to each row, its offset read
+// from DoShardOffsets() plus its index in the shard. The offset depends on
+// all the shards, including this one, so the rows are kept in a temporary
+// file until the offset is read.
+func DoZipWithIndex(offsetReader, reader io.Reader, writer io.Writer, stats *pb.InstructionStat) error {
+	file, err := spillRows(reader, "gleam-zip-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var offset int64
+	err = util.ProcessRow(offsetReader, nil, func(row *util.Row) error {
+		offset = util.ToInt64(row.K[0])
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to read offset: %v", err)
+	}
+
+	return zipRows(file, writer, offset, stats)
+}
+
+// spillRows keeps all the rows of the reader in a temporary file, opened to
+// read them again, so the shard is read completely before the other inputs
+// of a step, which may need its other readers to read it first. The caller
+// closes and removes the file.
+func spillRows(reader io.Reader, prefix string) (*os.File, error) {
+	file, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temporary file: %v", err)
+	}
+
+	buffered := util.NewBufferedMessageWriter(file, 64*1024)
+	err = util.ProcessMessage(reader, func(data []byte) error {
+		return buffered.WriteMessage(data)
+	})
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		err = fmt.Errorf("Failed to keep rows in %s: %v", file.Name(), err)
+	} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+		err = fmt.Errorf("Failed to read rows in %s: %v", file.Name(), err)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
+
+// DoZipWithUniqueId appends a unique id to each row, the shard id above its
+// index in the shard, without reading the other shards.
+func DoZipWithUniqueId(reader io.Reader, writer io.Writer, shardId int64, stats *pb.InstructionStat) error {
+	return zipRows(reader, writer, shardId<<uniqueIdShardShift, stats)
+}
+
+func zipRows(reader io.Reader, writer io.Writer, start int64, stats *pb.InstructionStat) error {
+	return util.ProcessRow(reader, nil, func(row *util.Row) error {
+		row.AppendValue(start + stats.InputCounter)
+		stats.InputCounter++
+		stats.OutputCounter++
+		return row.WriteTo(writer)
+	})
+}
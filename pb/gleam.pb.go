@@ -16,9 +16,16 @@ It has these top-level messages:
 	Allocation
 	AllocationResult
 	Heartbeat
+	AgentLoad
 	Empty
 	DataLocation
 	FlowExecutionStatus
+	FlowHistoryRequest
+	FlowHistoryResponse
+	CachedShardsRequest
+	CachedShardsResponse
+	Bucketing
+	BucketingRequest
 	FileResourceRequest
 	FileResourceResponse
 	ExecutionRequest
@@ -28,15 +35,29 @@ It has these top-level messages:
 	ControlMessage
 	DeleteDatasetShardRequest
 	DeleteDatasetShardResponse
+	AuthorizeRequest
+	AuthorizeResponse
 	CleanupRequest
 	CleanupResponse
 	WriteRequest
 	ReadRequest
+	ShardRange
 	InstructionSet
 	Instruction
+	SecretEnv
 	OrderBy
+	SqlPlan
+	SqlExpr
+	SqlColumn
+	SqlFieldType
+	SqlAggFunc
+	SqlByItem
 	DatasetShard
 	DatasetShardLocation
+	RowBatch
+	FlowDefinition
+	StepDefinition
+	FlowDefinitionResponse
 */
 package pb
 
@@ -67,6 +88,7 @@ type ComputeRequest struct {
 	Username         string             `protobuf:"bytes,3,opt,name=username" json:"username,omitempty"`
 	Hostname         string             `protobuf:"bytes,4,opt,name=hostname" json:"hostname,omitempty"`
 	FlowHashCode     uint32             `protobuf:"varint,5,opt,name=flowHashCode" json:"flowHashCode,omitempty"`
+	ProtocolVersion  int32              `protobuf:"varint,6,opt,name=protocolVersion" json:"protocolVersion,omitempty"`
 }
 
 func (m *ComputeRequest) Reset()                    { *m = ComputeRequest{} }
@@ -109,13 +131,22 @@ func (m *ComputeRequest) GetFlowHashCode() uint32 {
 	return 0
 }
 
+func (m *ComputeRequest) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 type ComputeResource struct {
-	CpuCount int32 `protobuf:"varint,1,opt,name=cpu_count,json=cpuCount" json:"cpu_count,omitempty"`
-	CpuLevel int32 `protobuf:"varint,2,opt,name=cpu_level,json=cpuLevel" json:"cpu_level,omitempty"`
-	GpuCount int32 `protobuf:"varint,3,opt,name=gpu_count,json=gpuCount" json:"gpu_count,omitempty"`
-	GpuLevel int32 `protobuf:"varint,4,opt,name=gpu_level,json=gpuLevel" json:"gpu_level,omitempty"`
-	MemoryMb int64 `protobuf:"varint,5,opt,name=memory_mb,json=memoryMb" json:"memory_mb,omitempty"`
-	DiskMb   int64 `protobuf:"varint,6,opt,name=disk_mb,json=diskMb" json:"disk_mb,omitempty"`
+	CpuCount     int32    `protobuf:"varint,1,opt,name=cpu_count,json=cpuCount" json:"cpu_count,omitempty"`
+	CpuLevel     int32    `protobuf:"varint,2,opt,name=cpu_level,json=cpuLevel" json:"cpu_level,omitempty"`
+	GpuCount     int32    `protobuf:"varint,3,opt,name=gpu_count,json=gpuCount" json:"gpu_count,omitempty"`
+	GpuLevel     int32    `protobuf:"varint,4,opt,name=gpu_level,json=gpuLevel" json:"gpu_level,omitempty"`
+	MemoryMb     int64    `protobuf:"varint,5,opt,name=memory_mb,json=memoryMb" json:"memory_mb,omitempty"`
+	DiskMb       int64    `protobuf:"varint,6,opt,name=disk_mb,json=diskMb" json:"disk_mb,omitempty"`
+	NodeSelector []string `protobuf:"bytes,7,rep,name=node_selector,json=nodeSelector" json:"node_selector,omitempty"`
+	Tolerations  []string `protobuf:"bytes,8,rep,name=tolerations" json:"tolerations,omitempty"`
 }
 
 func (m *ComputeResource) Reset()                    { *m = ComputeResource{} }
@@ -165,6 +196,20 @@ func (m *ComputeResource) GetDiskMb() int64 {
 	return 0
 }
 
+func (m *ComputeResource) GetNodeSelector() []string {
+	if m != nil {
+		return m.NodeSelector
+	}
+	return nil
+}
+
+func (m *ComputeResource) GetTolerations() []string {
+	if m != nil {
+		return m.Tolerations
+	}
+	return nil
+}
+
 type DataResource struct {
 	Location *Location `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
 	Size     int64     `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
@@ -271,9 +316,14 @@ func (m *AllocationResult) GetAllocations() []*Allocation {
 
 // ////////////////////////////////////////////////
 type Heartbeat struct {
-	Location  *Location        `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
-	Resource  *ComputeResource `protobuf:"bytes,2,opt,name=resource" json:"resource,omitempty"`
-	Allocated *ComputeResource `protobuf:"bytes,3,opt,name=allocated" json:"allocated,omitempty"`
+	Location        *Location        `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
+	Resource        *ComputeResource `protobuf:"bytes,2,opt,name=resource" json:"resource,omitempty"`
+	Allocated       *ComputeResource `protobuf:"bytes,3,opt,name=allocated" json:"allocated,omitempty"`
+	Load            *AgentLoad       `protobuf:"bytes,4,opt,name=load" json:"load,omitempty"`
+	ProtocolVersion int32            `protobuf:"varint,5,opt,name=protocolVersion" json:"protocolVersion,omitempty"`
+	Labels          []string         `protobuf:"bytes,6,rep,name=labels" json:"labels,omitempty"`
+	Taints          []string         `protobuf:"bytes,7,rep,name=taints" json:"taints,omitempty"`
+	CachedShards    []string         `protobuf:"bytes,8,rep,name=cachedShards" json:"cachedShards,omitempty"`
 }
 
 func (m *Heartbeat) Reset()                    { *m = Heartbeat{} }
@@ -302,25 +352,94 @@ func (m *Heartbeat) GetAllocated() *ComputeResource {
 	return nil
 }
 
+func (m *Heartbeat) GetLoad() *AgentLoad {
+	if m != nil {
+		return m.Load
+	}
+	return nil
+}
+
+func (m *Heartbeat) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *Heartbeat) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Heartbeat) GetTaints() []string {
+	if m != nil {
+		return m.Taints
+	}
+	return nil
+}
+
+func (m *Heartbeat) GetCachedShards() []string {
+	if m != nil {
+		return m.CachedShards
+	}
+	return nil
+}
+
+type AgentLoad struct {
+	RunningTasks          int32 `protobuf:"varint,1,opt,name=running_tasks,json=runningTasks" json:"running_tasks,omitempty"`
+	DiskUsedMb            int64 `protobuf:"varint,2,opt,name=disk_used_mb,json=diskUsedMb" json:"disk_used_mb,omitempty"`
+	NetworkBytesPerSecond int64 `protobuf:"varint,3,opt,name=network_bytes_per_second,json=networkBytesPerSecond" json:"network_bytes_per_second,omitempty"`
+}
+
+func (m *AgentLoad) Reset()                    { *m = AgentLoad{} }
+func (m *AgentLoad) String() string            { return proto.CompactTextString(m) }
+func (*AgentLoad) ProtoMessage()               {}
+func (*AgentLoad) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *AgentLoad) GetRunningTasks() int32 {
+	if m != nil {
+		return m.RunningTasks
+	}
+	return 0
+}
+
+func (m *AgentLoad) GetDiskUsedMb() int64 {
+	if m != nil {
+		return m.DiskUsedMb
+	}
+	return 0
+}
+
+func (m *AgentLoad) GetNetworkBytesPerSecond() int64 {
+	if m != nil {
+		return m.NetworkBytesPerSecond
+	}
+	return 0
+}
+
 type Empty struct {
 }
 
 func (m *Empty) Reset()                    { *m = Empty{} }
 func (m *Empty) String() string            { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 // ////////////////////////////////////////////////
 type DataLocation struct {
-	Name     string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Location *Location `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
-	OnDisk   bool      `protobuf:"varint,3,opt,name=onDisk" json:"onDisk,omitempty"`
+	Name        string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Location    *Location `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
+	OnDisk      bool      `protobuf:"varint,3,opt,name=onDisk" json:"onDisk,omitempty"`
+	Compression string    `protobuf:"bytes,4,opt,name=compression" json:"compression,omitempty"`
+	AccessToken string    `protobuf:"bytes,5,opt,name=accessToken" json:"accessToken,omitempty"`
 }
 
 func (m *DataLocation) Reset()                    { *m = DataLocation{} }
 func (m *DataLocation) String() string            { return proto.CompactTextString(m) }
 func (*DataLocation) ProtoMessage()               {}
-func (*DataLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*DataLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *DataLocation) GetName() string {
 	if m != nil {
@@ -343,6 +462,20 @@ func (m *DataLocation) GetOnDisk() bool {
 	return false
 }
 
+func (m *DataLocation) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
+func (m *DataLocation) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
 // ////////////////////////////////////////////////
 type FlowExecutionStatus struct {
 	StepGroups    []*FlowExecutionStatus_StepGroup    `protobuf:"bytes,1,rep,name=stepGroups" json:"stepGroups,omitempty"`
@@ -359,7 +492,7 @@ type FlowExecutionStatus struct {
 func (m *FlowExecutionStatus) Reset()                    { *m = FlowExecutionStatus{} }
 func (m *FlowExecutionStatus) String() string            { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus) ProtoMessage()               {}
-func (*FlowExecutionStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*FlowExecutionStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *FlowExecutionStatus) GetStepGroups() []*FlowExecutionStatus_StepGroup {
 	if m != nil {
@@ -432,7 +565,7 @@ type FlowExecutionStatus_Task struct {
 func (m *FlowExecutionStatus_Task) Reset()                    { *m = FlowExecutionStatus_Task{} }
 func (m *FlowExecutionStatus_Task) String() string            { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_Task) ProtoMessage()               {}
-func (*FlowExecutionStatus_Task) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9, 0} }
+func (*FlowExecutionStatus_Task) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10, 0} }
 
 func (m *FlowExecutionStatus_Task) GetStepId() int32 {
 	if m != nil {
@@ -461,7 +594,7 @@ type FlowExecutionStatus_Step struct {
 func (m *FlowExecutionStatus_Step) Reset()                    { *m = FlowExecutionStatus_Step{} }
 func (m *FlowExecutionStatus_Step) String() string            { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_Step) ProtoMessage()               {}
-func (*FlowExecutionStatus_Step) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9, 1} }
+func (*FlowExecutionStatus_Step) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10, 1} }
 
 func (m *FlowExecutionStatus_Step) GetId() int32 {
 	if m != nil {
@@ -518,10 +651,12 @@ type FlowExecutionStatus_Dataset struct {
 	ReadingStepIds []int32 `protobuf:"varint,3,rep,packed,name=readingStepIds" json:"readingStepIds,omitempty"`
 }
 
-func (m *FlowExecutionStatus_Dataset) Reset()                    { *m = FlowExecutionStatus_Dataset{} }
-func (m *FlowExecutionStatus_Dataset) String() string            { return proto.CompactTextString(m) }
-func (*FlowExecutionStatus_Dataset) ProtoMessage()               {}
-func (*FlowExecutionStatus_Dataset) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9, 2} }
+func (m *FlowExecutionStatus_Dataset) Reset()         { *m = FlowExecutionStatus_Dataset{} }
+func (m *FlowExecutionStatus_Dataset) String() string { return proto.CompactTextString(m) }
+func (*FlowExecutionStatus_Dataset) ProtoMessage()    {}
+func (*FlowExecutionStatus_Dataset) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{10, 2}
+}
 
 func (m *FlowExecutionStatus_Dataset) GetId() int32 {
 	if m != nil {
@@ -553,7 +688,7 @@ func (m *FlowExecutionStatus_DatasetShard) Reset()         { *m = FlowExecutionS
 func (m *FlowExecutionStatus_DatasetShard) String() string { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_DatasetShard) ProtoMessage()    {}
 func (*FlowExecutionStatus_DatasetShard) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{9, 3}
+	return fileDescriptor0, []int{10, 3}
 }
 
 func (m *FlowExecutionStatus_DatasetShard) GetDatasetId() int32 {
@@ -579,7 +714,7 @@ func (m *FlowExecutionStatus_StepGroup) Reset()         { *m = FlowExecutionStat
 func (m *FlowExecutionStatus_StepGroup) String() string { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_StepGroup) ProtoMessage()    {}
 func (*FlowExecutionStatus_StepGroup) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{9, 4}
+	return fileDescriptor0, []int{10, 4}
 }
 
 func (m *FlowExecutionStatus_StepGroup) GetStepIds() []int32 {
@@ -608,7 +743,7 @@ func (m *FlowExecutionStatus_TaskGroup) Reset()         { *m = FlowExecutionStat
 func (m *FlowExecutionStatus_TaskGroup) String() string { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_TaskGroup) ProtoMessage()    {}
 func (*FlowExecutionStatus_TaskGroup) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{9, 5}
+	return fileDescriptor0, []int{10, 5}
 }
 
 func (m *FlowExecutionStatus_TaskGroup) GetStepIds() []int32 {
@@ -662,7 +797,7 @@ func (m *FlowExecutionStatus_TaskGroup_Execution) Reset() {
 func (m *FlowExecutionStatus_TaskGroup_Execution) String() string { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_TaskGroup_Execution) ProtoMessage()    {}
 func (*FlowExecutionStatus_TaskGroup_Execution) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{9, 5, 0}
+	return fileDescriptor0, []int{10, 5, 0}
 }
 
 func (m *FlowExecutionStatus_TaskGroup_Execution) GetStartTime() int64 {
@@ -728,7 +863,7 @@ func (m *FlowExecutionStatus_DriverInfo) Reset()         { *m = FlowExecutionSta
 func (m *FlowExecutionStatus_DriverInfo) String() string { return proto.CompactTextString(m) }
 func (*FlowExecutionStatus_DriverInfo) ProtoMessage()    {}
 func (*FlowExecutionStatus_DriverInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{9, 6}
+	return fileDescriptor0, []int{10, 6}
 }
 
 func (m *FlowExecutionStatus_DriverInfo) GetUsername() string {
@@ -780,6 +915,127 @@ func (m *FlowExecutionStatus_DriverInfo) GetName() string {
 	return ""
 }
 
+type FlowHistoryRequest struct {
+	Id    uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Limit int32  `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *FlowHistoryRequest) Reset()                    { *m = FlowHistoryRequest{} }
+func (m *FlowHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*FlowHistoryRequest) ProtoMessage()               {}
+func (*FlowHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *FlowHistoryRequest) GetId() uint32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *FlowHistoryRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type FlowHistoryResponse struct {
+	Statuses []*FlowExecutionStatus `protobuf:"bytes,1,rep,name=statuses" json:"statuses,omitempty"`
+}
+
+func (m *FlowHistoryResponse) Reset()                    { *m = FlowHistoryResponse{} }
+func (m *FlowHistoryResponse) String() string            { return proto.CompactTextString(m) }
+func (*FlowHistoryResponse) ProtoMessage()               {}
+func (*FlowHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *FlowHistoryResponse) GetStatuses() []*FlowExecutionStatus {
+	if m != nil {
+		return m.Statuses
+	}
+	return nil
+}
+
+type CachedShardsRequest struct {
+	Names []string `protobuf:"bytes,1,rep,name=names" json:"names,omitempty"`
+}
+
+func (m *CachedShardsRequest) Reset()                    { *m = CachedShardsRequest{} }
+func (m *CachedShardsRequest) String() string            { return proto.CompactTextString(m) }
+func (*CachedShardsRequest) ProtoMessage()               {}
+func (*CachedShardsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *CachedShardsRequest) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+type CachedShardsResponse struct {
+	Locations []*DataLocation `protobuf:"bytes,1,rep,name=locations" json:"locations,omitempty"`
+}
+
+func (m *CachedShardsResponse) Reset()                    { *m = CachedShardsResponse{} }
+func (m *CachedShardsResponse) String() string            { return proto.CompactTextString(m) }
+func (*CachedShardsResponse) ProtoMessage()               {}
+func (*CachedShardsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *CachedShardsResponse) GetLocations() []*DataLocation {
+	if m != nil {
+		return m.Locations
+	}
+	return nil
+}
+
+// Bucketing describes the buckets kept by BucketBy() on the agents.
+type Bucketing struct {
+	Name        string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	KeyFields   []int32 `protobuf:"varint,2,rep,packed,name=keyFields" json:"keyFields,omitempty"`
+	BucketCount int32   `protobuf:"varint,3,opt,name=bucketCount" json:"bucketCount,omitempty"`
+}
+
+func (m *Bucketing) Reset()                    { *m = Bucketing{} }
+func (m *Bucketing) String() string            { return proto.CompactTextString(m) }
+func (*Bucketing) ProtoMessage()               {}
+func (*Bucketing) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *Bucketing) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Bucketing) GetKeyFields() []int32 {
+	if m != nil {
+		return m.KeyFields
+	}
+	return nil
+}
+
+func (m *Bucketing) GetBucketCount() int32 {
+	if m != nil {
+		return m.BucketCount
+	}
+	return 0
+}
+
+type BucketingRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *BucketingRequest) Reset()                    { *m = BucketingRequest{} }
+func (m *BucketingRequest) String() string            { return proto.CompactTextString(m) }
+func (*BucketingRequest) ProtoMessage()               {}
+func (*BucketingRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *BucketingRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 type FileResourceRequest struct {
 	Name         string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Dir          string `protobuf:"bytes,2,opt,name=dir" json:"dir,omitempty"`
@@ -791,7 +1047,7 @@ type FileResourceRequest struct {
 func (m *FileResourceRequest) Reset()                    { *m = FileResourceRequest{} }
 func (m *FileResourceRequest) String() string            { return proto.CompactTextString(m) }
 func (*FileResourceRequest) ProtoMessage()               {}
-func (*FileResourceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*FileResourceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *FileResourceRequest) GetName() string {
 	if m != nil {
@@ -836,7 +1092,7 @@ type FileResourceResponse struct {
 func (m *FileResourceResponse) Reset()                    { *m = FileResourceResponse{} }
 func (m *FileResourceResponse) String() string            { return proto.CompactTextString(m) }
 func (*FileResourceResponse) ProtoMessage()               {}
-func (*FileResourceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*FileResourceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *FileResourceResponse) GetAlreadyExists() bool {
 	if m != nil {
@@ -853,15 +1109,16 @@ func (m *FileResourceResponse) GetOk() bool {
 }
 
 type ExecutionRequest struct {
-	InstructionSet *InstructionSet  `protobuf:"bytes,1,opt,name=instructionSet" json:"instructionSet,omitempty"`
-	Dir            string           `protobuf:"bytes,2,opt,name=dir" json:"dir,omitempty"`
-	Resource       *ComputeResource `protobuf:"bytes,3,opt,name=resource" json:"resource,omitempty"`
+	InstructionSet  *InstructionSet  `protobuf:"bytes,1,opt,name=instructionSet" json:"instructionSet,omitempty"`
+	Dir             string           `protobuf:"bytes,2,opt,name=dir" json:"dir,omitempty"`
+	Resource        *ComputeResource `protobuf:"bytes,3,opt,name=resource" json:"resource,omitempty"`
+	ProtocolVersion int32            `protobuf:"varint,4,opt,name=protocolVersion" json:"protocolVersion,omitempty"`
 }
 
 func (m *ExecutionRequest) Reset()                    { *m = ExecutionRequest{} }
 func (m *ExecutionRequest) String() string            { return proto.CompactTextString(m) }
 func (*ExecutionRequest) ProtoMessage()               {}
-func (*ExecutionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*ExecutionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *ExecutionRequest) GetInstructionSet() *InstructionSet {
 	if m != nil {
@@ -884,6 +1141,13 @@ func (m *ExecutionRequest) GetResource() *ComputeResource {
 	return nil
 }
 
+func (m *ExecutionRequest) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 type ExecutionResponse struct {
 	Output        []byte         `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
 	Error         []byte         `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
@@ -895,7 +1159,7 @@ type ExecutionResponse struct {
 func (m *ExecutionResponse) Reset()                    { *m = ExecutionResponse{} }
 func (m *ExecutionResponse) String() string            { return proto.CompactTextString(m) }
 func (*ExecutionResponse) ProtoMessage()               {}
-func (*ExecutionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*ExecutionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *ExecutionResponse) GetOutput() []byte {
 	if m != nil {
@@ -940,7 +1204,7 @@ type ExecutionStat struct {
 func (m *ExecutionStat) Reset()                    { *m = ExecutionStat{} }
 func (m *ExecutionStat) String() string            { return proto.CompactTextString(m) }
 func (*ExecutionStat) ProtoMessage()               {}
-func (*ExecutionStat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*ExecutionStat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *ExecutionStat) GetFlowHashCode() uint32 {
 	if m != nil {
@@ -957,16 +1221,19 @@ func (m *ExecutionStat) GetStats() []*InstructionStat {
 }
 
 type InstructionStat struct {
-	StepId        int32 `protobuf:"varint,1,opt,name=stepId" json:"stepId,omitempty"`
-	TaskId        int32 `protobuf:"varint,2,opt,name=taskId" json:"taskId,omitempty"`
-	InputCounter  int64 `protobuf:"varint,3,opt,name=inputCounter" json:"inputCounter,omitempty"`
-	OutputCounter int64 `protobuf:"varint,4,opt,name=outputCounter" json:"outputCounter,omitempty"`
+	StepId          int32    `protobuf:"varint,1,opt,name=stepId" json:"stepId,omitempty"`
+	TaskId          int32    `protobuf:"varint,2,opt,name=taskId" json:"taskId,omitempty"`
+	InputCounter    int64    `protobuf:"varint,3,opt,name=inputCounter" json:"inputCounter,omitempty"`
+	OutputCounter   int64    `protobuf:"varint,4,opt,name=outputCounter" json:"outputCounter,omitempty"`
+	PeekedRows      [][]byte `protobuf:"bytes,5,rep,name=peekedRows,proto3" json:"peekedRows,omitempty"`
+	Lag             int64    `protobuf:"varint,6,opt,name=lag" json:"lag,omitempty"`
+	FilteredCounter int64    `protobuf:"varint,7,opt,name=filteredCounter" json:"filteredCounter,omitempty"`
 }
 
 func (m *InstructionStat) Reset()                    { *m = InstructionStat{} }
 func (m *InstructionStat) String() string            { return proto.CompactTextString(m) }
 func (*InstructionStat) ProtoMessage()               {}
-func (*InstructionStat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*InstructionStat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *InstructionStat) GetStepId() int32 {
 	if m != nil {
@@ -996,16 +1263,38 @@ func (m *InstructionStat) GetOutputCounter() int64 {
 	return 0
 }
 
+func (m *InstructionStat) GetPeekedRows() [][]byte {
+	if m != nil {
+		return m.PeekedRows
+	}
+	return nil
+}
+
+func (m *InstructionStat) GetLag() int64 {
+	if m != nil {
+		return m.Lag
+	}
+	return 0
+}
+
+func (m *InstructionStat) GetFilteredCounter() int64 {
+	if m != nil {
+		return m.FilteredCounter
+	}
+	return 0
+}
+
 type ControlMessage struct {
-	IsOnDiskIO   bool          `protobuf:"varint,1,opt,name=isOnDiskIO" json:"isOnDiskIO,omitempty"`
-	ReadRequest  *ReadRequest  `protobuf:"bytes,2,opt,name=readRequest" json:"readRequest,omitempty"`
-	WriteRequest *WriteRequest `protobuf:"bytes,3,opt,name=writeRequest" json:"writeRequest,omitempty"`
+	IsOnDiskIO      bool          `protobuf:"varint,1,opt,name=isOnDiskIO" json:"isOnDiskIO,omitempty"`
+	ReadRequest     *ReadRequest  `protobuf:"bytes,2,opt,name=readRequest" json:"readRequest,omitempty"`
+	WriteRequest    *WriteRequest `protobuf:"bytes,3,opt,name=writeRequest" json:"writeRequest,omitempty"`
+	ProtocolVersion int32         `protobuf:"varint,4,opt,name=protocolVersion" json:"protocolVersion,omitempty"`
 }
 
 func (m *ControlMessage) Reset()                    { *m = ControlMessage{} }
 func (m *ControlMessage) String() string            { return proto.CompactTextString(m) }
 func (*ControlMessage) ProtoMessage()               {}
-func (*ControlMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*ControlMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *ControlMessage) GetIsOnDiskIO() bool {
 	if m != nil {
@@ -1028,6 +1317,13 @@ func (m *ControlMessage) GetWriteRequest() *WriteRequest {
 	return nil
 }
 
+func (m *ControlMessage) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 type DeleteDatasetShardRequest struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 }
@@ -1035,7 +1331,7 @@ type DeleteDatasetShardRequest struct {
 func (m *DeleteDatasetShardRequest) Reset()                    { *m = DeleteDatasetShardRequest{} }
 func (m *DeleteDatasetShardRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteDatasetShardRequest) ProtoMessage()               {}
-func (*DeleteDatasetShardRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*DeleteDatasetShardRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *DeleteDatasetShardRequest) GetName() string {
 	if m != nil {
@@ -1051,7 +1347,7 @@ type DeleteDatasetShardResponse struct {
 func (m *DeleteDatasetShardResponse) Reset()                    { *m = DeleteDatasetShardResponse{} }
 func (m *DeleteDatasetShardResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeleteDatasetShardResponse) ProtoMessage()               {}
-func (*DeleteDatasetShardResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*DeleteDatasetShardResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *DeleteDatasetShardResponse) GetError() string {
 	if m != nil {
@@ -1060,6 +1356,72 @@ func (m *DeleteDatasetShardResponse) GetError() string {
 	return ""
 }
 
+type AuthorizeRequest struct {
+	Names        []string `protobuf:"bytes,1,rep,name=names" json:"names,omitempty"`
+	AccessToken  string   `protobuf:"bytes,2,opt,name=accessToken" json:"accessToken,omitempty"`
+	FlowHashCode uint32   `protobuf:"varint,3,opt,name=flowHashCode" json:"flowHashCode,omitempty"`
+	// limits how fast the flow writes the shards to the agent, 0 means no limit
+	NetworkBytesPerSecond int64 `protobuf:"varint,4,opt,name=networkBytesPerSecond" json:"networkBytesPerSecond,omitempty"`
+	// only binds the shards if the agent keeps all of them written completely
+	IsExisting bool `protobuf:"varint,5,opt,name=isExisting" json:"isExisting,omitempty"`
+}
+
+func (m *AuthorizeRequest) Reset()                    { *m = AuthorizeRequest{} }
+func (m *AuthorizeRequest) String() string            { return proto.CompactTextString(m) }
+func (*AuthorizeRequest) ProtoMessage()               {}
+func (*AuthorizeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *AuthorizeRequest) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+func (m *AuthorizeRequest) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *AuthorizeRequest) GetFlowHashCode() uint32 {
+	if m != nil {
+		return m.FlowHashCode
+	}
+	return 0
+}
+
+func (m *AuthorizeRequest) GetNetworkBytesPerSecond() int64 {
+	if m != nil {
+		return m.NetworkBytesPerSecond
+	}
+	return 0
+}
+
+func (m *AuthorizeRequest) GetIsExisting() bool {
+	if m != nil {
+		return m.IsExisting
+	}
+	return false
+}
+
+type AuthorizeResponse struct {
+	Error string `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *AuthorizeResponse) Reset()                    { *m = AuthorizeResponse{} }
+func (m *AuthorizeResponse) String() string            { return proto.CompactTextString(m) }
+func (*AuthorizeResponse) ProtoMessage()               {}
+func (*AuthorizeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *AuthorizeResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 type CleanupRequest struct {
 	FlowHashCode uint32 `protobuf:"varint,1,opt,name=flowHashCode" json:"flowHashCode,omitempty"`
 }
@@ -1067,7 +1429,7 @@ type CleanupRequest struct {
 func (m *CleanupRequest) Reset()                    { *m = CleanupRequest{} }
 func (m *CleanupRequest) String() string            { return proto.CompactTextString(m) }
 func (*CleanupRequest) ProtoMessage()               {}
-func (*CleanupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*CleanupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *CleanupRequest) GetFlowHashCode() uint32 {
 	if m != nil {
@@ -1083,7 +1445,7 @@ type CleanupResponse struct {
 func (m *CleanupResponse) Reset()                    { *m = CleanupResponse{} }
 func (m *CleanupResponse) String() string            { return proto.CompactTextString(m) }
 func (*CleanupResponse) ProtoMessage()               {}
-func (*CleanupResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*CleanupResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *CleanupResponse) GetError() string {
 	if m != nil {
@@ -1096,12 +1458,14 @@ type WriteRequest struct {
 	ChannelName string `protobuf:"bytes,1,opt,name=channelName" json:"channelName,omitempty"`
 	WriterName  string `protobuf:"bytes,2,opt,name=writerName" json:"writerName,omitempty"`
 	ReaderCount int32  `protobuf:"varint,3,opt,name=readerCount" json:"readerCount,omitempty"`
+	AccessToken string `protobuf:"bytes,4,opt,name=accessToken" json:"accessToken,omitempty"`
+	Compression string `protobuf:"bytes,5,opt,name=compression" json:"compression,omitempty"`
 }
 
 func (m *WriteRequest) Reset()                    { *m = WriteRequest{} }
 func (m *WriteRequest) String() string            { return proto.CompactTextString(m) }
 func (*WriteRequest) ProtoMessage()               {}
-func (*WriteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*WriteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 func (m *WriteRequest) GetChannelName() string {
 	if m != nil {
@@ -1124,15 +1488,33 @@ func (m *WriteRequest) GetReaderCount() int32 {
 	return 0
 }
 
+func (m *WriteRequest) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *WriteRequest) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 type ReadRequest struct {
-	ChannelName string `protobuf:"bytes,1,opt,name=channelName" json:"channelName,omitempty"`
-	ReaderName  string `protobuf:"bytes,2,opt,name=readerName" json:"readerName,omitempty"`
+	ChannelName string      `protobuf:"bytes,1,opt,name=channelName" json:"channelName,omitempty"`
+	ReaderName  string      `protobuf:"bytes,2,opt,name=readerName" json:"readerName,omitempty"`
+	AccessToken string      `protobuf:"bytes,3,opt,name=accessToken" json:"accessToken,omitempty"`
+	ShardRange  *ShardRange `protobuf:"bytes,4,opt,name=shardRange" json:"shardRange,omitempty"`
+	// the reader maps the on disk shard file, see netchan.DialMapChannel()
+	MapLocally bool `protobuf:"varint,5,opt,name=mapLocally" json:"mapLocally,omitempty"`
 }
 
 func (m *ReadRequest) Reset()                    { *m = ReadRequest{} }
 func (m *ReadRequest) String() string            { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()               {}
-func (*ReadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*ReadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *ReadRequest) GetChannelName() string {
 	if m != nil {
@@ -1148,6 +1530,70 @@ func (m *ReadRequest) GetReaderName() string {
 	return ""
 }
 
+func (m *ReadRequest) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *ReadRequest) GetShardRange() *ShardRange {
+	if m != nil {
+		return m.ShardRange
+	}
+	return nil
+}
+
+func (m *ReadRequest) GetMapLocally() bool {
+	if m != nil {
+		return m.MapLocally
+	}
+	return false
+}
+
+// ShardRange selects part of a dataset shard.
+// Rows are counted from 0, and stopRow is exclusive, 0 means no limit.
+// Keys are encoded by util.EncodeKeys, stopKey is exclusive, empty means no limit.
+type ShardRange struct {
+	StartRow int64  `protobuf:"varint,1,opt,name=startRow" json:"startRow,omitempty"`
+	StopRow  int64  `protobuf:"varint,2,opt,name=stopRow" json:"stopRow,omitempty"`
+	StartKey []byte `protobuf:"bytes,3,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	StopKey  []byte `protobuf:"bytes,4,opt,name=stopKey,proto3" json:"stopKey,omitempty"`
+}
+
+func (m *ShardRange) Reset()                    { *m = ShardRange{} }
+func (m *ShardRange) String() string            { return proto.CompactTextString(m) }
+func (*ShardRange) ProtoMessage()               {}
+func (*ShardRange) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *ShardRange) GetStartRow() int64 {
+	if m != nil {
+		return m.StartRow
+	}
+	return 0
+}
+
+func (m *ShardRange) GetStopRow() int64 {
+	if m != nil {
+		return m.StopRow
+	}
+	return 0
+}
+
+func (m *ShardRange) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *ShardRange) GetStopKey() []byte {
+	if m != nil {
+		return m.StopKey
+	}
+	return nil
+}
+
 type InstructionSet struct {
 	Instructions []*Instruction `protobuf:"bytes,1,rep,name=instructions" json:"instructions,omitempty"`
 	ReaderCount  int32          `protobuf:"varint,2,opt,name=readerCount" json:"readerCount,omitempty"`
@@ -1155,12 +1601,13 @@ type InstructionSet struct {
 	IsProfiling  bool           `protobuf:"varint,4,opt,name=isProfiling" json:"isProfiling,omitempty"`
 	AgentAddress string         `protobuf:"bytes,5,opt,name=agentAddress" json:"agentAddress,omitempty"`
 	Name         string         `protobuf:"bytes,6,opt,name=name" json:"name,omitempty"`
+	AgentDataDir string         `protobuf:"bytes,8,opt,name=agentDataDir" json:"agentDataDir,omitempty"`
 }
 
 func (m *InstructionSet) Reset()                    { *m = InstructionSet{} }
 func (m *InstructionSet) String() string            { return proto.CompactTextString(m) }
 func (*InstructionSet) ProtoMessage()               {}
-func (*InstructionSet) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*InstructionSet) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *InstructionSet) GetInstructions() []*Instruction {
 	if m != nil {
@@ -1204,6 +1651,13 @@ func (m *InstructionSet) GetName() string {
 	return ""
 }
 
+func (m *InstructionSet) GetAgentDataDir() string {
+	if m != nil {
+		return m.AgentDataDir
+	}
+	return ""
+}
+
 type Instruction struct {
 	StepId                   int32                                 `protobuf:"varint,1,opt,name=stepId" json:"stepId,omitempty"`
 	TaskId                   int32                                 `protobuf:"varint,2,opt,name=taskId" json:"taskId,omitempty"`
@@ -1229,12 +1683,32 @@ type Instruction struct {
 	LocalLimit               *Instruction_LocalLimit               `protobuf:"bytes,22,opt,name=localLimit" json:"localLimit,omitempty"`
 	LocalGroupBySorted       *Instruction_LocalGroupBySorted       `protobuf:"bytes,23,opt,name=localGroupBySorted" json:"localGroupBySorted,omitempty"`
 	Union                    *Instruction_Union                    `protobuf:"bytes,24,opt,name=union" json:"union,omitempty"`
+	SecretEnvs               []*SecretEnv                          `protobuf:"bytes,25,rep,name=secretEnvs" json:"secretEnvs,omitempty"`
+	LocalExists              *Instruction_LocalExists              `protobuf:"bytes,27,opt,name=localExists" json:"localExists,omitempty"`
+	PeekCount                int32                                 `protobuf:"varint,28,opt,name=peekCount" json:"peekCount,omitempty"`
+	Convert                  *Instruction_Convert                  `protobuf:"bytes,29,opt,name=convert" json:"convert,omitempty"`
+	SetOperation             *Instruction_SetOperation             `protobuf:"bytes,30,opt,name=setOperation" json:"setOperation,omitempty"`
+	PipeColumn               *Instruction_PipeColumn               `protobuf:"bytes,31,opt,name=pipeColumn" json:"pipeColumn,omitempty"`
+	SaltHotKeys              *Instruction_SaltHotKeys              `protobuf:"bytes,32,opt,name=saltHotKeys" json:"saltHotKeys,omitempty"`
+	FilterSalted             *Instruction_FilterSalted             `protobuf:"bytes,33,opt,name=filterSalted" json:"filterSalted,omitempty"`
+	ReplicateHotKeys         *Instruction_ReplicateHotKeys         `protobuf:"bytes,34,opt,name=replicateHotKeys" json:"replicateHotKeys,omitempty"`
+	Unsalt                   *Instruction_Unsalt                   `protobuf:"bytes,35,opt,name=unsalt" json:"unsalt,omitempty"`
+	Sample                   *Instruction_Sample                   `protobuf:"bytes,36,opt,name=sample" json:"sample,omitempty"`
+	Filter                   *Instruction_Filter                   `protobuf:"bytes,38,opt,name=filter" json:"filter,omitempty"`
+	SampleRangeKeys          *Instruction_SampleRangeKeys          `protobuf:"bytes,39,opt,name=sampleRangeKeys" json:"sampleRangeKeys,omitempty"`
+	RangeBoundaries          *Instruction_RangeBoundaries          `protobuf:"bytes,40,opt,name=rangeBoundaries" json:"rangeBoundaries,omitempty"`
+	ScatterRanges            *Instruction_ScatterRanges            `protobuf:"bytes,41,opt,name=scatterRanges" json:"scatterRanges,omitempty"`
+	CountRows                *Instruction_CountRows                `protobuf:"bytes,42,opt,name=countRows" json:"countRows,omitempty"`
+	ShardOffsets             *Instruction_ShardOffsets             `protobuf:"bytes,43,opt,name=shardOffsets" json:"shardOffsets,omitempty"`
+	ZipWithIndex             *Instruction_ZipWithIndex             `protobuf:"bytes,44,opt,name=zipWithIndex" json:"zipWithIndex,omitempty"`
+	SelectTag                *Instruction_SelectTag                `protobuf:"bytes,45,opt,name=selectTag" json:"selectTag,omitempty"`
+	CountNullKeys            *Instruction_CountNullKeys            `protobuf:"bytes,46,opt,name=countNullKeys" json:"countNullKeys,omitempty"`
 }
 
 func (m *Instruction) Reset()                    { *m = Instruction{} }
 func (m *Instruction) String() string            { return proto.CompactTextString(m) }
 func (*Instruction) ProtoMessage()               {}
-func (*Instruction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*Instruction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
 
 func (m *Instruction) GetStepId() int32 {
 	if m != nil {
@@ -1404,7 +1878,147 @@ func (m *Instruction) GetUnion() *Instruction_Union {
 	return nil
 }
 
-type Instruction_Select struct {
+func (m *Instruction) GetSecretEnvs() []*SecretEnv {
+	if m != nil {
+		return m.SecretEnvs
+	}
+	return nil
+}
+
+func (m *Instruction) GetLocalExists() *Instruction_LocalExists {
+	if m != nil {
+		return m.LocalExists
+	}
+	return nil
+}
+
+func (m *Instruction) GetPeekCount() int32 {
+	if m != nil {
+		return m.PeekCount
+	}
+	return 0
+}
+
+func (m *Instruction) GetConvert() *Instruction_Convert {
+	if m != nil {
+		return m.Convert
+	}
+	return nil
+}
+
+func (m *Instruction) GetSetOperation() *Instruction_SetOperation {
+	if m != nil {
+		return m.SetOperation
+	}
+	return nil
+}
+
+func (m *Instruction) GetPipeColumn() *Instruction_PipeColumn {
+	if m != nil {
+		return m.PipeColumn
+	}
+	return nil
+}
+
+func (m *Instruction) GetSaltHotKeys() *Instruction_SaltHotKeys {
+	if m != nil {
+		return m.SaltHotKeys
+	}
+	return nil
+}
+
+func (m *Instruction) GetFilterSalted() *Instruction_FilterSalted {
+	if m != nil {
+		return m.FilterSalted
+	}
+	return nil
+}
+
+func (m *Instruction) GetReplicateHotKeys() *Instruction_ReplicateHotKeys {
+	if m != nil {
+		return m.ReplicateHotKeys
+	}
+	return nil
+}
+
+func (m *Instruction) GetUnsalt() *Instruction_Unsalt {
+	if m != nil {
+		return m.Unsalt
+	}
+	return nil
+}
+
+func (m *Instruction) GetSample() *Instruction_Sample {
+	if m != nil {
+		return m.Sample
+	}
+	return nil
+}
+
+func (m *Instruction) GetFilter() *Instruction_Filter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *Instruction) GetSampleRangeKeys() *Instruction_SampleRangeKeys {
+	if m != nil {
+		return m.SampleRangeKeys
+	}
+	return nil
+}
+
+func (m *Instruction) GetRangeBoundaries() *Instruction_RangeBoundaries {
+	if m != nil {
+		return m.RangeBoundaries
+	}
+	return nil
+}
+
+func (m *Instruction) GetScatterRanges() *Instruction_ScatterRanges {
+	if m != nil {
+		return m.ScatterRanges
+	}
+	return nil
+}
+
+func (m *Instruction) GetCountRows() *Instruction_CountRows {
+	if m != nil {
+		return m.CountRows
+	}
+	return nil
+}
+
+func (m *Instruction) GetShardOffsets() *Instruction_ShardOffsets {
+	if m != nil {
+		return m.ShardOffsets
+	}
+	return nil
+}
+
+func (m *Instruction) GetZipWithIndex() *Instruction_ZipWithIndex {
+	if m != nil {
+		return m.ZipWithIndex
+	}
+	return nil
+}
+
+func (m *Instruction) GetSelectTag() *Instruction_SelectTag {
+	if m != nil {
+		return m.SelectTag
+	}
+	return nil
+}
+
+func (m *Instruction) GetCountNullKeys() *Instruction_CountNullKeys {
+	if m != nil {
+		return m.CountNullKeys
+	}
+	return nil
+}
+
+type Instruction_Select struct {
 	KeyIndexes   []int32 `protobuf:"varint,1,rep,packed,name=keyIndexes" json:"keyIndexes,omitempty"`
 	ValueIndexes []int32 `protobuf:"varint,2,rep,packed,name=valueIndexes" json:"valueIndexes,omitempty"`
 }
@@ -1412,7 +2026,7 @@ type Instruction_Select struct {
 func (m *Instruction_Select) Reset()                    { *m = Instruction_Select{} }
 func (m *Instruction_Select) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_Select) ProtoMessage()               {}
-func (*Instruction_Select) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 0} }
+func (*Instruction_Select) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 0} }
 
 func (m *Instruction_Select) GetKeyIndexes() []int32 {
 	if m != nil {
@@ -1432,13 +2046,15 @@ type Instruction_JoinPartitionedSorted struct {
 	Indexes          []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
 	IsLeftOuterJoin  bool    `protobuf:"varint,2,opt,name=isLeftOuterJoin" json:"isLeftOuterJoin,omitempty"`
 	IsRightOuterJoin bool    `protobuf:"varint,3,opt,name=isRightOuterJoin" json:"isRightOuterJoin,omitempty"`
+	LeftValueCount   int32   `protobuf:"varint,4,opt,name=leftValueCount" json:"leftValueCount,omitempty"`
+	RightValueCount  int32   `protobuf:"varint,5,opt,name=rightValueCount" json:"rightValueCount,omitempty"`
 }
 
 func (m *Instruction_JoinPartitionedSorted) Reset()         { *m = Instruction_JoinPartitionedSorted{} }
 func (m *Instruction_JoinPartitionedSorted) String() string { return proto.CompactTextString(m) }
 func (*Instruction_JoinPartitionedSorted) ProtoMessage()    {}
 func (*Instruction_JoinPartitionedSorted) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 1}
+	return fileDescriptor0, []int{34, 1}
 }
 
 func (m *Instruction_JoinPartitionedSorted) GetIndexes() []int32 {
@@ -1462,6 +2078,20 @@ func (m *Instruction_JoinPartitionedSorted) GetIsRightOuterJoin() bool {
 	return false
 }
 
+func (m *Instruction_JoinPartitionedSorted) GetLeftValueCount() int32 {
+	if m != nil {
+		return m.LeftValueCount
+	}
+	return 0
+}
+
+func (m *Instruction_JoinPartitionedSorted) GetRightValueCount() int32 {
+	if m != nil {
+		return m.RightValueCount
+	}
+	return 0
+}
+
 type Instruction_CoGroupPartitionedSorted struct {
 	Indexes []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
 }
@@ -1470,7 +2100,7 @@ func (m *Instruction_CoGroupPartitionedSorted) Reset()         { *m = Instructio
 func (m *Instruction_CoGroupPartitionedSorted) String() string { return proto.CompactTextString(m) }
 func (*Instruction_CoGroupPartitionedSorted) ProtoMessage()    {}
 func (*Instruction_CoGroupPartitionedSorted) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 2}
+	return fileDescriptor0, []int{34, 2}
 }
 
 func (m *Instruction_CoGroupPartitionedSorted) GetIndexes() []int32 {
@@ -1481,13 +2111,14 @@ func (m *Instruction_CoGroupPartitionedSorted) GetIndexes() []int32 {
 }
 
 type Instruction_PipeAsArgs struct {
-	Code string `protobuf:"bytes,1,opt,name=code" json:"code,omitempty"`
+	Code string   `protobuf:"bytes,1,opt,name=code" json:"code,omitempty"`
+	Env  []string `protobuf:"bytes,2,rep,name=env" json:"env,omitempty"`
 }
 
 func (m *Instruction_PipeAsArgs) Reset()                    { *m = Instruction_PipeAsArgs{} }
 func (m *Instruction_PipeAsArgs) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_PipeAsArgs) ProtoMessage()               {}
-func (*Instruction_PipeAsArgs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 3} }
+func (*Instruction_PipeAsArgs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 3} }
 
 func (m *Instruction_PipeAsArgs) GetCode() string {
 	if m != nil {
@@ -1496,15 +2127,23 @@ func (m *Instruction_PipeAsArgs) GetCode() string {
 	return ""
 }
 
+func (m *Instruction_PipeAsArgs) GetEnv() []string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
 type Instruction_ScatterPartitions struct {
 	Indexes []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
+	ByRow   bool    `protobuf:"varint,2,opt,name=byRow" json:"byRow,omitempty"`
 }
 
 func (m *Instruction_ScatterPartitions) Reset()         { *m = Instruction_ScatterPartitions{} }
 func (m *Instruction_ScatterPartitions) String() string { return proto.CompactTextString(m) }
 func (*Instruction_ScatterPartitions) ProtoMessage()    {}
 func (*Instruction_ScatterPartitions) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 4}
+	return fileDescriptor0, []int{34, 4}
 }
 
 func (m *Instruction_ScatterPartitions) GetIndexes() []int32 {
@@ -1514,6 +2153,13 @@ func (m *Instruction_ScatterPartitions) GetIndexes() []int32 {
 	return nil
 }
 
+func (m *Instruction_ScatterPartitions) GetByRow() bool {
+	if m != nil {
+		return m.ByRow
+	}
+	return false
+}
+
 type Instruction_CollectPartitions struct {
 }
 
@@ -1521,7 +2167,7 @@ func (m *Instruction_CollectPartitions) Reset()         { *m = Instruction_Colle
 func (m *Instruction_CollectPartitions) String() string { return proto.CompactTextString(m) }
 func (*Instruction_CollectPartitions) ProtoMessage()    {}
 func (*Instruction_CollectPartitions) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 5}
+	return fileDescriptor0, []int{34, 5}
 }
 
 type Instruction_InputSplitReader struct {
@@ -1532,7 +2178,7 @@ func (m *Instruction_InputSplitReader) Reset()         { *m = Instruction_InputS
 func (m *Instruction_InputSplitReader) String() string { return proto.CompactTextString(m) }
 func (*Instruction_InputSplitReader) ProtoMessage()    {}
 func (*Instruction_InputSplitReader) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 6}
+	return fileDescriptor0, []int{34, 6}
 }
 
 func (m *Instruction_InputSplitReader) GetInputType() string {
@@ -1548,17 +2194,18 @@ type Instruction_RoundRobin struct {
 func (m *Instruction_RoundRobin) Reset()                    { *m = Instruction_RoundRobin{} }
 func (m *Instruction_RoundRobin) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_RoundRobin) ProtoMessage()               {}
-func (*Instruction_RoundRobin) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 7} }
+func (*Instruction_RoundRobin) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 7} }
 
 type Instruction_LocalTop struct {
 	N        int32      `protobuf:"varint,1,opt,name=n" json:"n,omitempty"`
 	OrderBys []*OrderBy `protobuf:"bytes,2,rep,name=orderBys" json:"orderBys,omitempty"`
+	Indexes  []int32    `protobuf:"varint,3,rep,packed,name=indexes" json:"indexes,omitempty"`
 }
 
 func (m *Instruction_LocalTop) Reset()                    { *m = Instruction_LocalTop{} }
 func (m *Instruction_LocalTop) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_LocalTop) ProtoMessage()               {}
-func (*Instruction_LocalTop) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 8} }
+func (*Instruction_LocalTop) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 8} }
 
 func (m *Instruction_LocalTop) GetN() int32 {
 	if m != nil {
@@ -1574,13 +2221,20 @@ func (m *Instruction_LocalTop) GetOrderBys() []*OrderBy {
 	return nil
 }
 
+func (m *Instruction_LocalTop) GetIndexes() []int32 {
+	if m != nil {
+		return m.Indexes
+	}
+	return nil
+}
+
 type Instruction_Broadcast struct {
 }
 
 func (m *Instruction_Broadcast) Reset()                    { *m = Instruction_Broadcast{} }
 func (m *Instruction_Broadcast) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_Broadcast) ProtoMessage()               {}
-func (*Instruction_Broadcast) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 9} }
+func (*Instruction_Broadcast) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 9} }
 
 type Instruction_LocalHashAndJoinWith struct {
 	Indexes []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
@@ -1590,7 +2244,7 @@ func (m *Instruction_LocalHashAndJoinWith) Reset()         { *m = Instruction_Lo
 func (m *Instruction_LocalHashAndJoinWith) String() string { return proto.CompactTextString(m) }
 func (*Instruction_LocalHashAndJoinWith) ProtoMessage()    {}
 func (*Instruction_LocalHashAndJoinWith) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 10}
+	return fileDescriptor0, []int{34, 10}
 }
 
 func (m *Instruction_LocalHashAndJoinWith) GetIndexes() []int32 {
@@ -1605,12 +2259,13 @@ type Instruction_Script struct {
 	Path   string   `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
 	Args   []string `protobuf:"bytes,3,rep,name=args" json:"args,omitempty"`
 	Env    []string `protobuf:"bytes,4,rep,name=env" json:"env,omitempty"`
+	Arg    string   `protobuf:"bytes,5,opt,name=arg" json:"arg,omitempty"`
 }
 
 func (m *Instruction_Script) Reset()                    { *m = Instruction_Script{} }
 func (m *Instruction_Script) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_Script) ProtoMessage()               {}
-func (*Instruction_Script) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 11} }
+func (*Instruction_Script) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 11} }
 
 func (m *Instruction_Script) GetIsPipe() bool {
 	if m != nil {
@@ -1640,6 +2295,13 @@ func (m *Instruction_Script) GetEnv() []string {
 	return nil
 }
 
+func (m *Instruction_Script) GetArg() string {
+	if m != nil {
+		return m.Arg
+	}
+	return ""
+}
+
 type Instruction_LocalSort struct {
 	OrderBys []*OrderBy `protobuf:"bytes,1,rep,name=orderBys" json:"orderBys,omitempty"`
 }
@@ -1647,7 +2309,7 @@ type Instruction_LocalSort struct {
 func (m *Instruction_LocalSort) Reset()                    { *m = Instruction_LocalSort{} }
 func (m *Instruction_LocalSort) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_LocalSort) ProtoMessage()               {}
-func (*Instruction_LocalSort) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 12} }
+func (*Instruction_LocalSort) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 12} }
 
 func (m *Instruction_LocalSort) GetOrderBys() []*OrderBy {
 	if m != nil {
@@ -1663,7 +2325,7 @@ type Instruction_MergeSortedTo struct {
 func (m *Instruction_MergeSortedTo) Reset()                    { *m = Instruction_MergeSortedTo{} }
 func (m *Instruction_MergeSortedTo) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_MergeSortedTo) ProtoMessage()               {}
-func (*Instruction_MergeSortedTo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 13} }
+func (*Instruction_MergeSortedTo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 13} }
 
 func (m *Instruction_MergeSortedTo) GetOrderBys() []*OrderBy {
 	if m != nil {
@@ -1678,7 +2340,7 @@ type Instruction_MergeTo struct {
 func (m *Instruction_MergeTo) Reset()                    { *m = Instruction_MergeTo{} }
 func (m *Instruction_MergeTo) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_MergeTo) ProtoMessage()               {}
-func (*Instruction_MergeTo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 14} }
+func (*Instruction_MergeTo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 14} }
 
 type Instruction_LocalDistinct struct {
 	OrderBys []*OrderBy `protobuf:"bytes,1,rep,name=orderBys" json:"orderBys,omitempty"`
@@ -1687,7 +2349,7 @@ type Instruction_LocalDistinct struct {
 func (m *Instruction_LocalDistinct) Reset()                    { *m = Instruction_LocalDistinct{} }
 func (m *Instruction_LocalDistinct) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_LocalDistinct) ProtoMessage()               {}
-func (*Instruction_LocalDistinct) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 15} }
+func (*Instruction_LocalDistinct) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 15} }
 
 func (m *Instruction_LocalDistinct) GetOrderBys() []*OrderBy {
 	if m != nil {
@@ -1704,7 +2366,7 @@ type Instruction_LocalLimit struct {
 func (m *Instruction_LocalLimit) Reset()                    { *m = Instruction_LocalLimit{} }
 func (m *Instruction_LocalLimit) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_LocalLimit) ProtoMessage()               {}
-func (*Instruction_LocalLimit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 16} }
+func (*Instruction_LocalLimit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 16} }
 
 func (m *Instruction_LocalLimit) GetN() int32 {
 	if m != nil {
@@ -1728,7 +2390,7 @@ func (m *Instruction_LocalGroupBySorted) Reset()         { *m = Instruction_Loca
 func (m *Instruction_LocalGroupBySorted) String() string { return proto.CompactTextString(m) }
 func (*Instruction_LocalGroupBySorted) ProtoMessage()    {}
 func (*Instruction_LocalGroupBySorted) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{24, 17}
+	return fileDescriptor0, []int{34, 17}
 }
 
 func (m *Instruction_LocalGroupBySorted) GetIndexes() []int32 {
@@ -1745,7 +2407,7 @@ type Instruction_Union struct {
 func (m *Instruction_Union) Reset()                    { *m = Instruction_Union{} }
 func (m *Instruction_Union) String() string            { return proto.CompactTextString(m) }
 func (*Instruction_Union) ProtoMessage()               {}
-func (*Instruction_Union) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24, 18} }
+func (*Instruction_Union) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 18} }
 
 func (m *Instruction_Union) GetIsParallel() bool {
 	if m != nil {
@@ -1754,108 +2416,1316 @@ func (m *Instruction_Union) GetIsParallel() bool {
 	return false
 }
 
-type OrderBy struct {
-	Index int32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
-	Order int32 `protobuf:"varint,2,opt,name=order" json:"order,omitempty"`
+type Instruction_LocalExists struct {
+	Indexes     []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
+	ThatIndexes []int32 `protobuf:"varint,2,rep,packed,name=thatIndexes" json:"thatIndexes,omitempty"`
+	IsAnti      bool    `protobuf:"varint,3,opt,name=isAnti" json:"isAnti,omitempty"`
+	WithMark    bool    `protobuf:"varint,4,opt,name=withMark" json:"withMark,omitempty"`
+	IsNullAware bool    `protobuf:"varint,5,opt,name=isNullAware" json:"isNullAware,omitempty"`
 }
 
-func (m *OrderBy) Reset()                    { *m = OrderBy{} }
-func (m *OrderBy) String() string            { return proto.CompactTextString(m) }
-func (*OrderBy) ProtoMessage()               {}
-func (*OrderBy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (m *Instruction_LocalExists) Reset()                    { *m = Instruction_LocalExists{} }
+func (m *Instruction_LocalExists) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_LocalExists) ProtoMessage()               {}
+func (*Instruction_LocalExists) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 19} }
 
-func (m *OrderBy) GetIndex() int32 {
+func (m *Instruction_LocalExists) GetIndexes() []int32 {
 	if m != nil {
-		return m.Index
+		return m.Indexes
 	}
-	return 0
+	return nil
 }
 
-func (m *OrderBy) GetOrder() int32 {
+func (m *Instruction_LocalExists) GetThatIndexes() []int32 {
 	if m != nil {
-		return m.Order
+		return m.ThatIndexes
 	}
-	return 0
+	return nil
 }
 
-type DatasetShard struct {
-	FlowName       string `protobuf:"bytes,1,opt,name=FlowName" json:"FlowName,omitempty"`
-	DatasetId      int32  `protobuf:"varint,2,opt,name=DatasetId" json:"DatasetId,omitempty"`
-	DatasetShardId int32  `protobuf:"varint,3,opt,name=DatasetShardId" json:"DatasetShardId,omitempty"`
-	FlowHashCode   uint32 `protobuf:"varint,4,opt,name=FlowHashCode" json:"FlowHashCode,omitempty"`
+func (m *Instruction_LocalExists) GetIsAnti() bool {
+	if m != nil {
+		return m.IsAnti
+	}
+	return false
 }
 
-func (m *DatasetShard) Reset()                    { *m = DatasetShard{} }
-func (m *DatasetShard) String() string            { return proto.CompactTextString(m) }
-func (*DatasetShard) ProtoMessage()               {}
-func (*DatasetShard) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (m *Instruction_LocalExists) GetWithMark() bool {
+	if m != nil {
+		return m.WithMark
+	}
+	return false
+}
 
-func (m *DatasetShard) GetFlowName() string {
+func (m *Instruction_LocalExists) GetIsNullAware() bool {
 	if m != nil {
-		return m.FlowName
+		return m.IsNullAware
+	}
+	return false
+}
+
+type Instruction_Convert struct {
+	Types []string `protobuf:"bytes,1,rep,name=types" json:"types,omitempty"`
+}
+
+func (m *Instruction_Convert) Reset()                    { *m = Instruction_Convert{} }
+func (m *Instruction_Convert) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_Convert) ProtoMessage()               {}
+func (*Instruction_Convert) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 20} }
+
+func (m *Instruction_Convert) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+type Instruction_SetOperation struct {
+	Operation      string `protobuf:"bytes,1,opt,name=operation" json:"operation,omitempty"`
+	KeepDuplicates bool   `protobuf:"varint,2,opt,name=keepDuplicates" json:"keepDuplicates,omitempty"`
+}
+
+func (m *Instruction_SetOperation) Reset()                    { *m = Instruction_SetOperation{} }
+func (m *Instruction_SetOperation) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_SetOperation) ProtoMessage()               {}
+func (*Instruction_SetOperation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 21} }
+
+func (m *Instruction_SetOperation) GetOperation() string {
+	if m != nil {
+		return m.Operation
 	}
 	return ""
 }
 
-func (m *DatasetShard) GetDatasetId() int32 {
+func (m *Instruction_SetOperation) GetKeepDuplicates() bool {
 	if m != nil {
-		return m.DatasetId
+		return m.KeepDuplicates
+	}
+	return false
+}
+
+type Instruction_PipeColumn struct {
+	Code     string `protobuf:"bytes,1,opt,name=code" json:"code,omitempty"`
+	ArgCount int32  `protobuf:"varint,2,opt,name=argCount" json:"argCount,omitempty"`
+}
+
+func (m *Instruction_PipeColumn) Reset()                    { *m = Instruction_PipeColumn{} }
+func (m *Instruction_PipeColumn) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_PipeColumn) ProtoMessage()               {}
+func (*Instruction_PipeColumn) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 22} }
+
+func (m *Instruction_PipeColumn) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Instruction_PipeColumn) GetArgCount() int32 {
+	if m != nil {
+		return m.ArgCount
 	}
 	return 0
 }
 
-func (m *DatasetShard) GetDatasetShardId() int32 {
+type Instruction_SaltHotKeys struct {
+	Indexes    []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
+	SaltCount  int32   `protobuf:"varint,2,opt,name=saltCount" json:"saltCount,omitempty"`
+	SampleSize int32   `protobuf:"varint,3,opt,name=sampleSize" json:"sampleSize,omitempty"`
+	HotRatio   float64 `protobuf:"fixed64,4,opt,name=hotRatio" json:"hotRatio,omitempty"`
+}
+
+func (m *Instruction_SaltHotKeys) Reset()                    { *m = Instruction_SaltHotKeys{} }
+func (m *Instruction_SaltHotKeys) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_SaltHotKeys) ProtoMessage()               {}
+func (*Instruction_SaltHotKeys) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 23} }
+
+func (m *Instruction_SaltHotKeys) GetIndexes() []int32 {
 	if m != nil {
-		return m.DatasetShardId
+		return m.Indexes
+	}
+	return nil
+}
+
+func (m *Instruction_SaltHotKeys) GetSaltCount() int32 {
+	if m != nil {
+		return m.SaltCount
 	}
 	return 0
 }
 
-func (m *DatasetShard) GetFlowHashCode() uint32 {
+func (m *Instruction_SaltHotKeys) GetSampleSize() int32 {
 	if m != nil {
-		return m.FlowHashCode
+		return m.SampleSize
 	}
 	return 0
 }
 
-type DatasetShardLocation struct {
-	Name   string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
-	Host   string `protobuf:"bytes,2,opt,name=Host" json:"Host,omitempty"`
-	Port   int32  `protobuf:"varint,3,opt,name=Port" json:"Port,omitempty"`
-	OnDisk bool   `protobuf:"varint,4,opt,name=onDisk" json:"onDisk,omitempty"`
+func (m *Instruction_SaltHotKeys) GetHotRatio() float64 {
+	if m != nil {
+		return m.HotRatio
+	}
+	return 0
+}
+
+type Instruction_FilterSalted struct {
+	KeyCount int32 `protobuf:"varint,1,opt,name=keyCount" json:"keyCount,omitempty"`
+	HotKeys  bool  `protobuf:"varint,2,opt,name=hotKeys" json:"hotKeys,omitempty"`
+}
+
+func (m *Instruction_FilterSalted) Reset()                    { *m = Instruction_FilterSalted{} }
+func (m *Instruction_FilterSalted) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_FilterSalted) ProtoMessage()               {}
+func (*Instruction_FilterSalted) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 24} }
+
+func (m *Instruction_FilterSalted) GetKeyCount() int32 {
+	if m != nil {
+		return m.KeyCount
+	}
+	return 0
+}
+
+func (m *Instruction_FilterSalted) GetHotKeys() bool {
+	if m != nil {
+		return m.HotKeys
+	}
+	return false
+}
+
+type Instruction_ReplicateHotKeys struct {
+	Indexes   []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
+	SaltCount int32   `protobuf:"varint,2,opt,name=saltCount" json:"saltCount,omitempty"`
+}
+
+func (m *Instruction_ReplicateHotKeys) Reset()         { *m = Instruction_ReplicateHotKeys{} }
+func (m *Instruction_ReplicateHotKeys) String() string { return proto.CompactTextString(m) }
+func (*Instruction_ReplicateHotKeys) ProtoMessage()    {}
+func (*Instruction_ReplicateHotKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{34, 25}
+}
+
+func (m *Instruction_ReplicateHotKeys) GetIndexes() []int32 {
+	if m != nil {
+		return m.Indexes
+	}
+	return nil
+}
+
+func (m *Instruction_ReplicateHotKeys) GetSaltCount() int32 {
+	if m != nil {
+		return m.SaltCount
+	}
+	return 0
+}
+
+type Instruction_Unsalt struct {
+	KeyCount int32 `protobuf:"varint,1,opt,name=keyCount" json:"keyCount,omitempty"`
+}
+
+func (m *Instruction_Unsalt) Reset()                    { *m = Instruction_Unsalt{} }
+func (m *Instruction_Unsalt) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_Unsalt) ProtoMessage()               {}
+func (*Instruction_Unsalt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 26} }
+
+func (m *Instruction_Unsalt) GetKeyCount() int32 {
+	if m != nil {
+		return m.KeyCount
+	}
+	return 0
+}
+
+type Instruction_Sample struct {
+	Fraction  float64   `protobuf:"fixed64,1,opt,name=fraction" json:"fraction,omitempty"`
+	Seed      int64     `protobuf:"varint,2,opt,name=seed" json:"seed,omitempty"`
+	ByKey     bool      `protobuf:"varint,3,opt,name=byKey" json:"byKey,omitempty"`
+	Keys      []string  `protobuf:"bytes,4,rep,name=keys" json:"keys,omitempty"`
+	Fractions []float64 `protobuf:"fixed64,5,rep,packed,name=fractions" json:"fractions,omitempty"`
+}
+
+func (m *Instruction_Sample) Reset()                    { *m = Instruction_Sample{} }
+func (m *Instruction_Sample) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_Sample) ProtoMessage()               {}
+func (*Instruction_Sample) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 27} }
+
+func (m *Instruction_Sample) GetFraction() float64 {
+	if m != nil {
+		return m.Fraction
+	}
+	return 0
+}
+
+func (m *Instruction_Sample) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *Instruction_Sample) GetByKey() bool {
+	if m != nil {
+		return m.ByKey
+	}
+	return false
+}
+
+func (m *Instruction_Sample) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *Instruction_Sample) GetFractions() []float64 {
+	if m != nil {
+		return m.Fractions
+	}
+	return nil
+}
+
+type Instruction_Filter struct {
+	PredicateId string   `protobuf:"bytes,1,opt,name=predicateId" json:"predicateId,omitempty"`
+	Path        string   `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Args        []string `protobuf:"bytes,3,rep,name=args" json:"args,omitempty"`
+	Env         []string `protobuf:"bytes,4,rep,name=env" json:"env,omitempty"`
+}
+
+func (m *Instruction_Filter) Reset()                    { *m = Instruction_Filter{} }
+func (m *Instruction_Filter) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_Filter) ProtoMessage()               {}
+func (*Instruction_Filter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 28} }
+
+func (m *Instruction_Filter) GetPredicateId() string {
+	if m != nil {
+		return m.PredicateId
+	}
+	return ""
+}
+
+func (m *Instruction_Filter) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Instruction_Filter) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Instruction_Filter) GetEnv() []string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+type Instruction_SampleRangeKeys struct {
+	OrderBys []*OrderBy `protobuf:"bytes,1,rep,name=orderBys" json:"orderBys,omitempty"`
+	Size     int32      `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *Instruction_SampleRangeKeys) Reset()         { *m = Instruction_SampleRangeKeys{} }
+func (m *Instruction_SampleRangeKeys) String() string { return proto.CompactTextString(m) }
+func (*Instruction_SampleRangeKeys) ProtoMessage()    {}
+func (*Instruction_SampleRangeKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{34, 29}
+}
+
+func (m *Instruction_SampleRangeKeys) GetOrderBys() []*OrderBy {
+	if m != nil {
+		return m.OrderBys
+	}
+	return nil
+}
+
+func (m *Instruction_SampleRangeKeys) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type Instruction_RangeBoundaries struct {
+	OrderBys       []*OrderBy `protobuf:"bytes,1,rep,name=orderBys" json:"orderBys,omitempty"`
+	PartitionCount int32      `protobuf:"varint,2,opt,name=partitionCount" json:"partitionCount,omitempty"`
+}
+
+func (m *Instruction_RangeBoundaries) Reset()         { *m = Instruction_RangeBoundaries{} }
+func (m *Instruction_RangeBoundaries) String() string { return proto.CompactTextString(m) }
+func (*Instruction_RangeBoundaries) ProtoMessage()    {}
+func (*Instruction_RangeBoundaries) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{34, 30}
+}
+
+func (m *Instruction_RangeBoundaries) GetOrderBys() []*OrderBy {
+	if m != nil {
+		return m.OrderBys
+	}
+	return nil
+}
+
+func (m *Instruction_RangeBoundaries) GetPartitionCount() int32 {
+	if m != nil {
+		return m.PartitionCount
+	}
+	return 0
+}
+
+type Instruction_ScatterRanges struct {
+	OrderBys []*OrderBy `protobuf:"bytes,1,rep,name=orderBys" json:"orderBys,omitempty"`
+}
+
+func (m *Instruction_ScatterRanges) Reset()                    { *m = Instruction_ScatterRanges{} }
+func (m *Instruction_ScatterRanges) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_ScatterRanges) ProtoMessage()               {}
+func (*Instruction_ScatterRanges) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 31} }
+
+func (m *Instruction_ScatterRanges) GetOrderBys() []*OrderBy {
+	if m != nil {
+		return m.OrderBys
+	}
+	return nil
+}
+
+type Instruction_CountRows struct {
+}
+
+func (m *Instruction_CountRows) Reset()                    { *m = Instruction_CountRows{} }
+func (m *Instruction_CountRows) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_CountRows) ProtoMessage()               {}
+func (*Instruction_CountRows) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 32} }
+
+type Instruction_ShardOffsets struct {
+}
+
+func (m *Instruction_ShardOffsets) Reset()                    { *m = Instruction_ShardOffsets{} }
+func (m *Instruction_ShardOffsets) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_ShardOffsets) ProtoMessage()               {}
+func (*Instruction_ShardOffsets) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 33} }
+
+type Instruction_ZipWithIndex struct {
+	UniqueId bool `protobuf:"varint,1,opt,name=uniqueId" json:"uniqueId,omitempty"`
+}
+
+func (m *Instruction_ZipWithIndex) Reset()                    { *m = Instruction_ZipWithIndex{} }
+func (m *Instruction_ZipWithIndex) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_ZipWithIndex) ProtoMessage()               {}
+func (*Instruction_ZipWithIndex) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 34} }
+
+func (m *Instruction_ZipWithIndex) GetUniqueId() bool {
+	if m != nil {
+		return m.UniqueId
+	}
+	return false
+}
+
+type Instruction_SelectTag struct {
+	Tag int32 `protobuf:"varint,1,opt,name=tag" json:"tag,omitempty"`
+}
+
+func (m *Instruction_SelectTag) Reset()                    { *m = Instruction_SelectTag{} }
+func (m *Instruction_SelectTag) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_SelectTag) ProtoMessage()               {}
+func (*Instruction_SelectTag) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 35} }
+
+func (m *Instruction_SelectTag) GetTag() int32 {
+	if m != nil {
+		return m.Tag
+	}
+	return 0
+}
+
+type Instruction_CountNullKeys struct {
+	Indexes []int32 `protobuf:"varint,1,rep,packed,name=indexes" json:"indexes,omitempty"`
+}
+
+func (m *Instruction_CountNullKeys) Reset()                    { *m = Instruction_CountNullKeys{} }
+func (m *Instruction_CountNullKeys) String() string            { return proto.CompactTextString(m) }
+func (*Instruction_CountNullKeys) ProtoMessage()               {}
+func (*Instruction_CountNullKeys) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34, 36} }
+
+func (m *Instruction_CountNullKeys) GetIndexes() []int32 {
+	if m != nil {
+		return m.Indexes
+	}
+	return nil
+}
+
+// SecretEnv sets the environment variable to a secret looked up by the executor
+type SecretEnv struct {
+	EnvName    string `protobuf:"bytes,1,opt,name=envName" json:"envName,omitempty"`
+	SecretName string `protobuf:"bytes,2,opt,name=secretName" json:"secretName,omitempty"`
+}
+
+func (m *SecretEnv) Reset()                    { *m = SecretEnv{} }
+func (m *SecretEnv) String() string            { return proto.CompactTextString(m) }
+func (*SecretEnv) ProtoMessage()               {}
+func (*SecretEnv) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *SecretEnv) GetEnvName() string {
+	if m != nil {
+		return m.EnvName
+	}
+	return ""
+}
+
+func (m *SecretEnv) GetSecretName() string {
+	if m != nil {
+		return m.SecretName
+	}
+	return ""
+}
+
+type OrderBy struct {
+	Index int32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Order int32 `protobuf:"varint,2,opt,name=order" json:"order,omitempty"`
+}
+
+func (m *OrderBy) Reset()                    { *m = OrderBy{} }
+func (m *OrderBy) String() string            { return proto.CompactTextString(m) }
+func (*OrderBy) ProtoMessage()               {}
+func (*OrderBy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *OrderBy) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *OrderBy) GetOrder() int32 {
+	if m != nil {
+		return m.Order
+	}
+	return 0
+}
+
+// SqlPlan is a fragment of a physical plan of the SQL executor.
+// The fields used depend on the type of the operator.
+type SqlPlan struct {
+	Type     string       `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Id       string       `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	Schema   []*SqlColumn `protobuf:"bytes,3,rep,name=schema" json:"schema,omitempty"`
+	Children []*SqlPlan   `protobuf:"bytes,4,rep,name=children" json:"children,omitempty"`
+	// conditions of Selection and TableScan, or other conditions of joins
+	Conditions []*SqlExpr `protobuf:"bytes,5,rep,name=conditions" json:"conditions,omitempty"`
+	// expressions of Projection, or group by items of Aggregation
+	Exprs           []*SqlExpr    `protobuf:"bytes,6,rep,name=exprs" json:"exprs,omitempty"`
+	AggFuncs        []*SqlAggFunc `protobuf:"bytes,7,rep,name=aggFuncs" json:"aggFuncs,omitempty"`
+	ByItems         []*SqlByItem  `protobuf:"bytes,8,rep,name=byItems" json:"byItems,omitempty"`
+	DbName          string        `protobuf:"bytes,9,opt,name=dbName" json:"dbName,omitempty"`
+	TableName       string        `protobuf:"bytes,10,opt,name=tableName" json:"tableName,omitempty"`
+	JoinType        int32         `protobuf:"varint,11,opt,name=joinType" json:"joinType,omitempty"`
+	EqualConditions []*SqlExpr    `protobuf:"bytes,12,rep,name=equalConditions" json:"equalConditions,omitempty"`
+	LeftConditions  []*SqlExpr    `protobuf:"bytes,13,rep,name=leftConditions" json:"leftConditions,omitempty"`
+	RightConditions []*SqlExpr    `protobuf:"bytes,14,rep,name=rightConditions" json:"rightConditions,omitempty"`
+	Offset          uint64        `protobuf:"varint,15,opt,name=offset" json:"offset,omitempty"`
+	Count           uint64        `protobuf:"varint,16,opt,name=count" json:"count,omitempty"`
+	Anti            bool          `protobuf:"varint,17,opt,name=anti" json:"anti,omitempty"`
+	WithAux         bool          `protobuf:"varint,18,opt,name=withAux" json:"withAux,omitempty"`
+	// the join strategy, small table and concurrency of HashJoin
+	Strategy    int32 `protobuf:"varint,19,opt,name=strategy" json:"strategy,omitempty"`
+	SmallTable  int32 `protobuf:"varint,20,opt,name=smallTable" json:"smallTable,omitempty"`
+	Concurrency int32 `protobuf:"varint,21,opt,name=concurrency" json:"concurrency,omitempty"`
+	// the codec encoded default values of the outer rows of HashJoin
+	DefaultValues []byte `protobuf:"bytes,22,opt,name=defaultValues,proto3" json:"defaultValues,omitempty"`
+	// the aggregation type of Aggregation, and whether it has group by items
+	AggType int32 `protobuf:"varint,23,opt,name=aggType" json:"aggType,omitempty"`
+	HasGby  bool  `protobuf:"varint,24,opt,name=hasGby" json:"hasGby,omitempty"`
+	// the join of Apply, without its children, which are the children of Apply
+	ApplyJoin   *SqlPlan     `protobuf:"bytes,25,opt,name=applyJoin" json:"applyJoin,omitempty"`
+	OuterSchema []*SqlColumn `protobuf:"bytes,26,rep,name=outerSchema" json:"outerSchema,omitempty"`
+	MaxOneRow   bool         `protobuf:"varint,27,opt,name=maxOneRow" json:"maxOneRow,omitempty"`
+	// the options of TableScan
+	TableAsName string `protobuf:"bytes,28,opt,name=tableAsName" json:"tableAsName,omitempty"`
+	Desc        bool   `protobuf:"varint,29,opt,name=desc" json:"desc,omitempty"`
+	KeepOrder   bool   `protobuf:"varint,30,opt,name=keepOrder" json:"keepOrder,omitempty"`
+	// whether Sort has a limit, of offset and count
+	HasLimit bool `protobuf:"varint,31,opt,name=hasLimit" json:"hasLimit,omitempty"`
+}
+
+func (m *SqlPlan) Reset()                    { *m = SqlPlan{} }
+func (m *SqlPlan) String() string            { return proto.CompactTextString(m) }
+func (*SqlPlan) ProtoMessage()               {}
+func (*SqlPlan) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *SqlPlan) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *SqlPlan) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SqlPlan) GetSchema() []*SqlColumn {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetChildren() []*SqlPlan {
+	if m != nil {
+		return m.Children
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetConditions() []*SqlExpr {
+	if m != nil {
+		return m.Conditions
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetExprs() []*SqlExpr {
+	if m != nil {
+		return m.Exprs
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetAggFuncs() []*SqlAggFunc {
+	if m != nil {
+		return m.AggFuncs
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetByItems() []*SqlByItem {
+	if m != nil {
+		return m.ByItems
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetDbName() string {
+	if m != nil {
+		return m.DbName
+	}
+	return ""
+}
+
+func (m *SqlPlan) GetTableName() string {
+	if m != nil {
+		return m.TableName
+	}
+	return ""
+}
+
+func (m *SqlPlan) GetJoinType() int32 {
+	if m != nil {
+		return m.JoinType
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetEqualConditions() []*SqlExpr {
+	if m != nil {
+		return m.EqualConditions
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetLeftConditions() []*SqlExpr {
+	if m != nil {
+		return m.LeftConditions
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetRightConditions() []*SqlExpr {
+	if m != nil {
+		return m.RightConditions
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetAnti() bool {
+	if m != nil {
+		return m.Anti
+	}
+	return false
+}
+
+func (m *SqlPlan) GetWithAux() bool {
+	if m != nil {
+		return m.WithAux
+	}
+	return false
+}
+
+func (m *SqlPlan) GetStrategy() int32 {
+	if m != nil {
+		return m.Strategy
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetSmallTable() int32 {
+	if m != nil {
+		return m.SmallTable
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetConcurrency() int32 {
+	if m != nil {
+		return m.Concurrency
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetDefaultValues() []byte {
+	if m != nil {
+		return m.DefaultValues
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetAggType() int32 {
+	if m != nil {
+		return m.AggType
+	}
+	return 0
+}
+
+func (m *SqlPlan) GetHasGby() bool {
+	if m != nil {
+		return m.HasGby
+	}
+	return false
+}
+
+func (m *SqlPlan) GetApplyJoin() *SqlPlan {
+	if m != nil {
+		return m.ApplyJoin
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetOuterSchema() []*SqlColumn {
+	if m != nil {
+		return m.OuterSchema
+	}
+	return nil
+}
+
+func (m *SqlPlan) GetMaxOneRow() bool {
+	if m != nil {
+		return m.MaxOneRow
+	}
+	return false
+}
+
+func (m *SqlPlan) GetTableAsName() string {
+	if m != nil {
+		return m.TableAsName
+	}
+	return ""
+}
+
+func (m *SqlPlan) GetDesc() bool {
+	if m != nil {
+		return m.Desc
+	}
+	return false
+}
+
+func (m *SqlPlan) GetKeepOrder() bool {
+	if m != nil {
+		return m.KeepOrder
+	}
+	return false
+}
+
+func (m *SqlPlan) GetHasLimit() bool {
+	if m != nil {
+		return m.HasLimit
+	}
+	return false
+}
+
+type SqlExpr struct {
+	Kind      int32         `protobuf:"varint,1,opt,name=kind" json:"kind,omitempty"`
+	FieldType *SqlFieldType `protobuf:"bytes,2,opt,name=fieldType" json:"fieldType,omitempty"`
+	Value     []byte        `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Column    *SqlColumn    `protobuf:"bytes,4,opt,name=column" json:"column,omitempty"`
+	FuncName  string        `protobuf:"bytes,5,opt,name=funcName" json:"funcName,omitempty"`
+	Args      []*SqlExpr    `protobuf:"bytes,6,rep,name=args" json:"args,omitempty"`
+}
+
+func (m *SqlExpr) Reset()                    { *m = SqlExpr{} }
+func (m *SqlExpr) String() string            { return proto.CompactTextString(m) }
+func (*SqlExpr) ProtoMessage()               {}
+func (*SqlExpr) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *SqlExpr) GetKind() int32 {
+	if m != nil {
+		return m.Kind
+	}
+	return 0
+}
+
+func (m *SqlExpr) GetFieldType() *SqlFieldType {
+	if m != nil {
+		return m.FieldType
+	}
+	return nil
+}
+
+func (m *SqlExpr) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *SqlExpr) GetColumn() *SqlColumn {
+	if m != nil {
+		return m.Column
+	}
+	return nil
+}
+
+func (m *SqlExpr) GetFuncName() string {
+	if m != nil {
+		return m.FuncName
+	}
+	return ""
+}
+
+func (m *SqlExpr) GetArgs() []*SqlExpr {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+type SqlColumn struct {
+	FromID      string        `protobuf:"bytes,1,opt,name=fromID" json:"fromID,omitempty"`
+	Position    int32         `protobuf:"varint,2,opt,name=position" json:"position,omitempty"`
+	DbName      string        `protobuf:"bytes,3,opt,name=dbName" json:"dbName,omitempty"`
+	TblName     string        `protobuf:"bytes,4,opt,name=tblName" json:"tblName,omitempty"`
+	ColName     string        `protobuf:"bytes,5,opt,name=colName" json:"colName,omitempty"`
+	Index       int32         `protobuf:"varint,6,opt,name=index" json:"index,omitempty"`
+	FieldType   *SqlFieldType `protobuf:"bytes,7,opt,name=fieldType" json:"fieldType,omitempty"`
+	IsAggOrSubq bool          `protobuf:"varint,8,opt,name=isAggOrSubq" json:"isAggOrSubq,omitempty"`
+}
+
+func (m *SqlColumn) Reset()                    { *m = SqlColumn{} }
+func (m *SqlColumn) String() string            { return proto.CompactTextString(m) }
+func (*SqlColumn) ProtoMessage()               {}
+func (*SqlColumn) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *SqlColumn) GetFromID() string {
+	if m != nil {
+		return m.FromID
+	}
+	return ""
+}
+
+func (m *SqlColumn) GetPosition() int32 {
+	if m != nil {
+		return m.Position
+	}
+	return 0
+}
+
+func (m *SqlColumn) GetDbName() string {
+	if m != nil {
+		return m.DbName
+	}
+	return ""
+}
+
+func (m *SqlColumn) GetTblName() string {
+	if m != nil {
+		return m.TblName
+	}
+	return ""
+}
+
+func (m *SqlColumn) GetColName() string {
+	if m != nil {
+		return m.ColName
+	}
+	return ""
+}
+
+func (m *SqlColumn) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *SqlColumn) GetFieldType() *SqlFieldType {
+	if m != nil {
+		return m.FieldType
+	}
+	return nil
+}
+
+func (m *SqlColumn) GetIsAggOrSubq() bool {
+	if m != nil {
+		return m.IsAggOrSubq
+	}
+	return false
+}
+
+type SqlFieldType struct {
+	Tp      int32    `protobuf:"varint,1,opt,name=tp" json:"tp,omitempty"`
+	Flag    uint32   `protobuf:"varint,2,opt,name=flag" json:"flag,omitempty"`
+	Flen    int32    `protobuf:"varint,3,opt,name=flen" json:"flen,omitempty"`
+	Decimal int32    `protobuf:"varint,4,opt,name=decimal" json:"decimal,omitempty"`
+	Charset string   `protobuf:"bytes,5,opt,name=charset" json:"charset,omitempty"`
+	Collate string   `protobuf:"bytes,6,opt,name=collate" json:"collate,omitempty"`
+	Elems   []string `protobuf:"bytes,7,rep,name=elems" json:"elems,omitempty"`
+}
+
+func (m *SqlFieldType) Reset()                    { *m = SqlFieldType{} }
+func (m *SqlFieldType) String() string            { return proto.CompactTextString(m) }
+func (*SqlFieldType) ProtoMessage()               {}
+func (*SqlFieldType) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *SqlFieldType) GetTp() int32 {
+	if m != nil {
+		return m.Tp
+	}
+	return 0
+}
+
+func (m *SqlFieldType) GetFlag() uint32 {
+	if m != nil {
+		return m.Flag
+	}
+	return 0
+}
+
+func (m *SqlFieldType) GetFlen() int32 {
+	if m != nil {
+		return m.Flen
+	}
+	return 0
+}
+
+func (m *SqlFieldType) GetDecimal() int32 {
+	if m != nil {
+		return m.Decimal
+	}
+	return 0
+}
+
+func (m *SqlFieldType) GetCharset() string {
+	if m != nil {
+		return m.Charset
+	}
+	return ""
+}
+
+func (m *SqlFieldType) GetCollate() string {
+	if m != nil {
+		return m.Collate
+	}
+	return ""
+}
+
+func (m *SqlFieldType) GetElems() []string {
+	if m != nil {
+		return m.Elems
+	}
+	return nil
+}
+
+type SqlAggFunc struct {
+	Name     string     `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Args     []*SqlExpr `protobuf:"bytes,2,rep,name=args" json:"args,omitempty"`
+	Distinct bool       `protobuf:"varint,3,opt,name=distinct" json:"distinct,omitempty"`
+	Mode     int32      `protobuf:"varint,4,opt,name=mode" json:"mode,omitempty"`
+}
+
+func (m *SqlAggFunc) Reset()                    { *m = SqlAggFunc{} }
+func (m *SqlAggFunc) String() string            { return proto.CompactTextString(m) }
+func (*SqlAggFunc) ProtoMessage()               {}
+func (*SqlAggFunc) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *SqlAggFunc) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SqlAggFunc) GetArgs() []*SqlExpr {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *SqlAggFunc) GetDistinct() bool {
+	if m != nil {
+		return m.Distinct
+	}
+	return false
+}
+
+func (m *SqlAggFunc) GetMode() int32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+type SqlByItem struct {
+	Expr *SqlExpr `protobuf:"bytes,1,opt,name=expr" json:"expr,omitempty"`
+	Desc bool     `protobuf:"varint,2,opt,name=desc" json:"desc,omitempty"`
+}
+
+func (m *SqlByItem) Reset()                    { *m = SqlByItem{} }
+func (m *SqlByItem) String() string            { return proto.CompactTextString(m) }
+func (*SqlByItem) ProtoMessage()               {}
+func (*SqlByItem) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *SqlByItem) GetExpr() *SqlExpr {
+	if m != nil {
+		return m.Expr
+	}
+	return nil
+}
+
+func (m *SqlByItem) GetDesc() bool {
+	if m != nil {
+		return m.Desc
+	}
+	return false
+}
+
+type DatasetShard struct {
+	FlowName       string `protobuf:"bytes,1,opt,name=FlowName" json:"FlowName,omitempty"`
+	DatasetId      int32  `protobuf:"varint,2,opt,name=DatasetId" json:"DatasetId,omitempty"`
+	DatasetShardId int32  `protobuf:"varint,3,opt,name=DatasetShardId" json:"DatasetShardId,omitempty"`
+	FlowHashCode   uint32 `protobuf:"varint,4,opt,name=FlowHashCode" json:"FlowHashCode,omitempty"`
+}
+
+func (m *DatasetShard) Reset()                    { *m = DatasetShard{} }
+func (m *DatasetShard) String() string            { return proto.CompactTextString(m) }
+func (*DatasetShard) ProtoMessage()               {}
+func (*DatasetShard) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *DatasetShard) GetFlowName() string {
+	if m != nil {
+		return m.FlowName
+	}
+	return ""
+}
+
+func (m *DatasetShard) GetDatasetId() int32 {
+	if m != nil {
+		return m.DatasetId
+	}
+	return 0
+}
+
+func (m *DatasetShard) GetDatasetShardId() int32 {
+	if m != nil {
+		return m.DatasetShardId
+	}
+	return 0
+}
+
+func (m *DatasetShard) GetFlowHashCode() uint32 {
+	if m != nil {
+		return m.FlowHashCode
+	}
+	return 0
+}
+
+type DatasetShardLocation struct {
+	Name        string      `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Host        string      `protobuf:"bytes,2,opt,name=Host" json:"Host,omitempty"`
+	Port        int32       `protobuf:"varint,3,opt,name=Port" json:"Port,omitempty"`
+	OnDisk      bool        `protobuf:"varint,4,opt,name=onDisk" json:"onDisk,omitempty"`
+	AccessToken string      `protobuf:"bytes,5,opt,name=accessToken" json:"accessToken,omitempty"`
+	ShardRange  *ShardRange `protobuf:"bytes,6,opt,name=shardRange" json:"shardRange,omitempty"`
+	Compression string      `protobuf:"bytes,7,opt,name=compression" json:"compression,omitempty"`
+}
+
+func (m *DatasetShardLocation) Reset()                    { *m = DatasetShardLocation{} }
+func (m *DatasetShardLocation) String() string            { return proto.CompactTextString(m) }
+func (*DatasetShardLocation) ProtoMessage()               {}
+func (*DatasetShardLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *DatasetShardLocation) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DatasetShardLocation) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *DatasetShardLocation) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *DatasetShardLocation) GetOnDisk() bool {
+	if m != nil {
+		return m.OnDisk
+	}
+	return false
+}
+
+func (m *DatasetShardLocation) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *DatasetShardLocation) GetShardRange() *ShardRange {
+	if m != nil {
+		return m.ShardRange
+	}
+	return nil
+}
+
+func (m *DatasetShardLocation) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
+type RowBatch struct {
+	// the msgpack encoded rows
+	Rows [][]byte `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+}
+
+func (m *RowBatch) Reset()                    { *m = RowBatch{} }
+func (m *RowBatch) String() string            { return proto.CompactTextString(m) }
+func (*RowBatch) ProtoMessage()               {}
+func (*RowBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *RowBatch) GetRows() [][]byte {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type FlowDefinition struct {
+	Name        string            `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Steps       []*StepDefinition `protobuf:"bytes,2,rep,name=steps" json:"steps,omitempty"`
+	AccessToken string            `protobuf:"bytes,3,opt,name=accessToken" json:"accessToken,omitempty"`
+}
+
+func (m *FlowDefinition) Reset()                    { *m = FlowDefinition{} }
+func (m *FlowDefinition) String() string            { return proto.CompactTextString(m) }
+func (*FlowDefinition) ProtoMessage()               {}
+func (*FlowDefinition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *FlowDefinition) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FlowDefinition) GetSteps() []*StepDefinition {
+	if m != nil {
+		return m.Steps
+	}
+	return nil
+}
+
+func (m *FlowDefinition) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+// StepDefinition adds the dataset named by the id, computed by the op from
+// the datasets of the inputs. The other fields are the parameters of the op.
+type StepDefinition struct {
+	Id     string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Inputs []string `protobuf:"bytes,2,rep,name=inputs" json:"inputs,omitempty"`
+	Op     string   `protobuf:"bytes,3,opt,name=op" json:"op,omitempty"`
+	Name   string   `protobuf:"bytes,4,opt,name=name" json:"name,omitempty"`
+	// a registered mapper, reducer or predicate, by id or function name
+	Function string `protobuf:"bytes,5,opt,name=function" json:"function,omitempty"`
+	Arg      string `protobuf:"bytes,6,opt,name=arg" json:"arg,omitempty"`
+	Command  string `protobuf:"bytes,7,opt,name=command" json:"command,omitempty"`
+	// the 1-based fields to sort, partition, reduce or join by, negative
+	// for descending order
+	Fields []int32  `protobuf:"varint,8,rep,packed,name=fields" json:"fields,omitempty"`
+	Shards int32    `protobuf:"varint,9,opt,name=shards" json:"shards,omitempty"`
+	Path   string   `protobuf:"bytes,10,opt,name=path" json:"path,omitempty"`
+	Format string   `protobuf:"bytes,11,opt,name=format" json:"format,omitempty"`
+	N      int32    `protobuf:"varint,12,opt,name=n" json:"n,omitempty"`
+	Lines  []string `protobuf:"bytes,13,rep,name=lines" json:"lines,omitempty"`
+}
+
+func (m *StepDefinition) Reset()                    { *m = StepDefinition{} }
+func (m *StepDefinition) String() string            { return proto.CompactTextString(m) }
+func (*StepDefinition) ProtoMessage()               {}
+func (*StepDefinition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *StepDefinition) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetInputs() []string {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *StepDefinition) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetFunction() string {
+	if m != nil {
+		return m.Function
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetArg() string {
+	if m != nil {
+		return m.Arg
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetFields() []int32 {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *StepDefinition) GetShards() int32 {
+	if m != nil {
+		return m.Shards
+	}
+	return 0
+}
+
+func (m *StepDefinition) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *StepDefinition) GetN() int32 {
+	if m != nil {
+		return m.N
+	}
+	return 0
 }
 
-func (m *DatasetShardLocation) Reset()                    { *m = DatasetShardLocation{} }
-func (m *DatasetShardLocation) String() string            { return proto.CompactTextString(m) }
-func (*DatasetShardLocation) ProtoMessage()               {}
-func (*DatasetShardLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
-
-func (m *DatasetShardLocation) GetName() string {
+func (m *StepDefinition) GetLines() []string {
 	if m != nil {
-		return m.Name
+		return m.Lines
 	}
-	return ""
+	return nil
 }
 
-func (m *DatasetShardLocation) GetHost() string {
+type FlowDefinitionResponse struct {
+	StepId string `protobuf:"bytes,1,opt,name=stepId" json:"stepId,omitempty"`
+	// the rows of a collect step, each a JSON array
+	Rows  []string `protobuf:"bytes,2,rep,name=rows" json:"rows,omitempty"`
+	Error string   `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *FlowDefinitionResponse) Reset()                    { *m = FlowDefinitionResponse{} }
+func (m *FlowDefinitionResponse) String() string            { return proto.CompactTextString(m) }
+func (*FlowDefinitionResponse) ProtoMessage()               {}
+func (*FlowDefinitionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *FlowDefinitionResponse) GetStepId() string {
 	if m != nil {
-		return m.Host
+		return m.StepId
 	}
 	return ""
 }
 
-func (m *DatasetShardLocation) GetPort() int32 {
+func (m *FlowDefinitionResponse) GetRows() []string {
 	if m != nil {
-		return m.Port
+		return m.Rows
 	}
-	return 0
+	return nil
 }
 
-func (m *DatasetShardLocation) GetOnDisk() bool {
+func (m *FlowDefinitionResponse) GetError() string {
 	if m != nil {
-		return m.OnDisk
+		return m.Error
 	}
-	return false
+	return ""
 }
 
 func init() {
@@ -1866,6 +3736,7 @@ func init() {
 	proto.RegisterType((*Allocation)(nil), "pb.Allocation")
 	proto.RegisterType((*AllocationResult)(nil), "pb.AllocationResult")
 	proto.RegisterType((*Heartbeat)(nil), "pb.Heartbeat")
+	proto.RegisterType((*AgentLoad)(nil), "pb.AgentLoad")
 	proto.RegisterType((*Empty)(nil), "pb.Empty")
 	proto.RegisterType((*DataLocation)(nil), "pb.DataLocation")
 	proto.RegisterType((*FlowExecutionStatus)(nil), "pb.FlowExecutionStatus")
@@ -1877,6 +3748,12 @@ func init() {
 	proto.RegisterType((*FlowExecutionStatus_TaskGroup)(nil), "pb.FlowExecutionStatus.TaskGroup")
 	proto.RegisterType((*FlowExecutionStatus_TaskGroup_Execution)(nil), "pb.FlowExecutionStatus.TaskGroup.Execution")
 	proto.RegisterType((*FlowExecutionStatus_DriverInfo)(nil), "pb.FlowExecutionStatus.DriverInfo")
+	proto.RegisterType((*FlowHistoryRequest)(nil), "pb.FlowHistoryRequest")
+	proto.RegisterType((*FlowHistoryResponse)(nil), "pb.FlowHistoryResponse")
+	proto.RegisterType((*CachedShardsRequest)(nil), "pb.CachedShardsRequest")
+	proto.RegisterType((*CachedShardsResponse)(nil), "pb.CachedShardsResponse")
+	proto.RegisterType((*Bucketing)(nil), "pb.Bucketing")
+	proto.RegisterType((*BucketingRequest)(nil), "pb.BucketingRequest")
 	proto.RegisterType((*FileResourceRequest)(nil), "pb.FileResourceRequest")
 	proto.RegisterType((*FileResourceResponse)(nil), "pb.FileResourceResponse")
 	proto.RegisterType((*ExecutionRequest)(nil), "pb.ExecutionRequest")
@@ -1886,10 +3763,13 @@ func init() {
 	proto.RegisterType((*ControlMessage)(nil), "pb.ControlMessage")
 	proto.RegisterType((*DeleteDatasetShardRequest)(nil), "pb.DeleteDatasetShardRequest")
 	proto.RegisterType((*DeleteDatasetShardResponse)(nil), "pb.DeleteDatasetShardResponse")
+	proto.RegisterType((*AuthorizeRequest)(nil), "pb.AuthorizeRequest")
+	proto.RegisterType((*AuthorizeResponse)(nil), "pb.AuthorizeResponse")
 	proto.RegisterType((*CleanupRequest)(nil), "pb.CleanupRequest")
 	proto.RegisterType((*CleanupResponse)(nil), "pb.CleanupResponse")
 	proto.RegisterType((*WriteRequest)(nil), "pb.WriteRequest")
 	proto.RegisterType((*ReadRequest)(nil), "pb.ReadRequest")
+	proto.RegisterType((*ShardRange)(nil), "pb.ShardRange")
 	proto.RegisterType((*InstructionSet)(nil), "pb.InstructionSet")
 	proto.RegisterType((*Instruction)(nil), "pb.Instruction")
 	proto.RegisterType((*Instruction_Select)(nil), "pb.Instruction.Select")
@@ -1911,9 +3791,38 @@ func init() {
 	proto.RegisterType((*Instruction_LocalLimit)(nil), "pb.Instruction.LocalLimit")
 	proto.RegisterType((*Instruction_LocalGroupBySorted)(nil), "pb.Instruction.LocalGroupBySorted")
 	proto.RegisterType((*Instruction_Union)(nil), "pb.Instruction.Union")
+	proto.RegisterType((*Instruction_LocalExists)(nil), "pb.Instruction.LocalExists")
+	proto.RegisterType((*Instruction_Convert)(nil), "pb.Instruction.Convert")
+	proto.RegisterType((*Instruction_SetOperation)(nil), "pb.Instruction.SetOperation")
+	proto.RegisterType((*Instruction_PipeColumn)(nil), "pb.Instruction.PipeColumn")
+	proto.RegisterType((*Instruction_SaltHotKeys)(nil), "pb.Instruction.SaltHotKeys")
+	proto.RegisterType((*Instruction_FilterSalted)(nil), "pb.Instruction.FilterSalted")
+	proto.RegisterType((*Instruction_ReplicateHotKeys)(nil), "pb.Instruction.ReplicateHotKeys")
+	proto.RegisterType((*Instruction_Unsalt)(nil), "pb.Instruction.Unsalt")
+	proto.RegisterType((*Instruction_Sample)(nil), "pb.Instruction.Sample")
+	proto.RegisterType((*Instruction_Filter)(nil), "pb.Instruction.Filter")
+	proto.RegisterType((*Instruction_SampleRangeKeys)(nil), "pb.Instruction.SampleRangeKeys")
+	proto.RegisterType((*Instruction_RangeBoundaries)(nil), "pb.Instruction.RangeBoundaries")
+	proto.RegisterType((*Instruction_ScatterRanges)(nil), "pb.Instruction.ScatterRanges")
+	proto.RegisterType((*Instruction_CountRows)(nil), "pb.Instruction.CountRows")
+	proto.RegisterType((*Instruction_ShardOffsets)(nil), "pb.Instruction.ShardOffsets")
+	proto.RegisterType((*Instruction_ZipWithIndex)(nil), "pb.Instruction.ZipWithIndex")
+	proto.RegisterType((*Instruction_SelectTag)(nil), "pb.Instruction.SelectTag")
+	proto.RegisterType((*Instruction_CountNullKeys)(nil), "pb.Instruction.CountNullKeys")
+	proto.RegisterType((*SecretEnv)(nil), "pb.SecretEnv")
 	proto.RegisterType((*OrderBy)(nil), "pb.OrderBy")
+	proto.RegisterType((*SqlPlan)(nil), "pb.SqlPlan")
+	proto.RegisterType((*SqlExpr)(nil), "pb.SqlExpr")
+	proto.RegisterType((*SqlColumn)(nil), "pb.SqlColumn")
+	proto.RegisterType((*SqlFieldType)(nil), "pb.SqlFieldType")
+	proto.RegisterType((*SqlAggFunc)(nil), "pb.SqlAggFunc")
+	proto.RegisterType((*SqlByItem)(nil), "pb.SqlByItem")
 	proto.RegisterType((*DatasetShard)(nil), "pb.DatasetShard")
 	proto.RegisterType((*DatasetShardLocation)(nil), "pb.DatasetShardLocation")
+	proto.RegisterType((*RowBatch)(nil), "pb.RowBatch")
+	proto.RegisterType((*FlowDefinition)(nil), "pb.FlowDefinition")
+	proto.RegisterType((*StepDefinition)(nil), "pb.StepDefinition")
+	proto.RegisterType((*FlowDefinitionResponse)(nil), "pb.FlowDefinitionResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1930,6 +3839,10 @@ type GleamMasterClient interface {
 	GetResources(ctx context.Context, in *ComputeRequest, opts ...grpc.CallOption) (*AllocationResult, error)
 	SendHeartbeat(ctx context.Context, opts ...grpc.CallOption) (GleamMaster_SendHeartbeatClient, error)
 	SendFlowExecutionStatus(ctx context.Context, opts ...grpc.CallOption) (GleamMaster_SendFlowExecutionStatusClient, error)
+	GetFlowHistory(ctx context.Context, in *FlowHistoryRequest, opts ...grpc.CallOption) (*FlowHistoryResponse, error)
+	GetCachedShards(ctx context.Context, in *CachedShardsRequest, opts ...grpc.CallOption) (*CachedShardsResponse, error)
+	SaveBucketing(ctx context.Context, in *Bucketing, opts ...grpc.CallOption) (*Empty, error)
+	GetBucketing(ctx context.Context, in *BucketingRequest, opts ...grpc.CallOption) (*Bucketing, error)
 }
 
 type gleamMasterClient struct {
@@ -2017,12 +3930,52 @@ func (x *gleamMasterSendFlowExecutionStatusClient) CloseAndRecv() (*Empty, error
 	return m, nil
 }
 
+func (c *gleamMasterClient) GetFlowHistory(ctx context.Context, in *FlowHistoryRequest, opts ...grpc.CallOption) (*FlowHistoryResponse, error) {
+	out := new(FlowHistoryResponse)
+	err := grpc.Invoke(ctx, "/pb.GleamMaster/GetFlowHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gleamMasterClient) GetCachedShards(ctx context.Context, in *CachedShardsRequest, opts ...grpc.CallOption) (*CachedShardsResponse, error) {
+	out := new(CachedShardsResponse)
+	err := grpc.Invoke(ctx, "/pb.GleamMaster/GetCachedShards", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gleamMasterClient) SaveBucketing(ctx context.Context, in *Bucketing, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/pb.GleamMaster/SaveBucketing", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gleamMasterClient) GetBucketing(ctx context.Context, in *BucketingRequest, opts ...grpc.CallOption) (*Bucketing, error) {
+	out := new(Bucketing)
+	err := grpc.Invoke(ctx, "/pb.GleamMaster/GetBucketing", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for GleamMaster service
 
 type GleamMasterServer interface {
 	GetResources(context.Context, *ComputeRequest) (*AllocationResult, error)
 	SendHeartbeat(GleamMaster_SendHeartbeatServer) error
 	SendFlowExecutionStatus(GleamMaster_SendFlowExecutionStatusServer) error
+	GetFlowHistory(context.Context, *FlowHistoryRequest) (*FlowHistoryResponse, error)
+	GetCachedShards(context.Context, *CachedShardsRequest) (*CachedShardsResponse, error)
+	SaveBucketing(context.Context, *Bucketing) (*Empty, error)
+	GetBucketing(context.Context, *BucketingRequest) (*Bucketing, error)
 }
 
 func RegisterGleamMasterServer(s *grpc.Server, srv GleamMasterServer) {
@@ -2099,6 +4052,78 @@ func (x *gleamMasterSendFlowExecutionStatusServer) Recv() (*FlowExecutionStatus,
 	return m, nil
 }
 
+func _GleamMaster_GetFlowHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlowHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GleamMasterServer).GetFlowHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GleamMaster/GetFlowHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GleamMasterServer).GetFlowHistory(ctx, req.(*FlowHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GleamMaster_GetCachedShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CachedShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GleamMasterServer).GetCachedShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GleamMaster/GetCachedShards",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GleamMasterServer).GetCachedShards(ctx, req.(*CachedShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GleamMaster_SaveBucketing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Bucketing)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GleamMasterServer).SaveBucketing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GleamMaster/SaveBucketing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GleamMasterServer).SaveBucketing(ctx, req.(*Bucketing))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GleamMaster_GetBucketing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BucketingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GleamMasterServer).GetBucketing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GleamMaster/GetBucketing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GleamMasterServer).GetBucketing(ctx, req.(*BucketingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _GleamMaster_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.GleamMaster",
 	HandlerType: (*GleamMasterServer)(nil),
@@ -2107,6 +4132,22 @@ var _GleamMaster_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetResources",
 			Handler:    _GleamMaster_GetResources_Handler,
 		},
+		{
+			MethodName: "GetFlowHistory",
+			Handler:    _GleamMaster_GetFlowHistory_Handler,
+		},
+		{
+			MethodName: "GetCachedShards",
+			Handler:    _GleamMaster_GetCachedShards_Handler,
+		},
+		{
+			MethodName: "SaveBucketing",
+			Handler:    _GleamMaster_SaveBucketing_Handler,
+		},
+		{
+			MethodName: "GetBucketing",
+			Handler:    _GleamMaster_GetBucketing_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2229,6 +4270,8 @@ type GleamAgentClient interface {
 	// collect execution stats from "gleam execute" processes
 	CollectExecutionStatistics(ctx context.Context, opts ...grpc.CallOption) (GleamAgent_CollectExecutionStatisticsClient, error)
 	Delete(ctx context.Context, in *DeleteDatasetShardRequest, opts ...grpc.CallOption) (*DeleteDatasetShardResponse, error)
+	// only the holders of the access token can read or write the shards
+	Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error)
 	Cleanup(ctx context.Context, in *CleanupRequest, opts ...grpc.CallOption) (*CleanupResponse, error)
 }
 
@@ -2346,6 +4389,15 @@ func (c *gleamAgentClient) Delete(ctx context.Context, in *DeleteDatasetShardReq
 	return out, nil
 }
 
+func (c *gleamAgentClient) Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error) {
+	out := new(AuthorizeResponse)
+	err := grpc.Invoke(ctx, "/pb.GleamAgent/Authorize", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gleamAgentClient) Cleanup(ctx context.Context, in *CleanupRequest, opts ...grpc.CallOption) (*CleanupResponse, error) {
 	out := new(CleanupResponse)
 	err := grpc.Invoke(ctx, "/pb.GleamAgent/Cleanup", in, out, c.cc, opts...)
@@ -2363,6 +4415,8 @@ type GleamAgentServer interface {
 	// collect execution stats from "gleam execute" processes
 	CollectExecutionStatistics(GleamAgent_CollectExecutionStatisticsServer) error
 	Delete(context.Context, *DeleteDatasetShardRequest) (*DeleteDatasetShardResponse, error)
+	// only the holders of the access token can read or write the shards
+	Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error)
 	Cleanup(context.Context, *CleanupRequest) (*CleanupResponse, error)
 }
 
@@ -2461,6 +4515,24 @@ func _GleamAgent_Delete_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GleamAgent_Authorize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GleamAgentServer).Authorize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GleamAgent/Authorize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GleamAgentServer).Authorize(ctx, req.(*AuthorizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GleamAgent_Cleanup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CleanupRequest)
 	if err := dec(in); err != nil {
@@ -2487,6 +4559,10 @@ var _GleamAgent_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _GleamAgent_Delete_Handler,
 		},
+		{
+			MethodName: "Authorize",
+			Handler:    _GleamAgent_Authorize_Handler,
+		},
 		{
 			MethodName: "Cleanup",
 			Handler:    _GleamAgent_Cleanup_Handler,
@@ -2513,167 +4589,506 @@ var _GleamAgent_serviceDesc = grpc.ServiceDesc{
 	Metadata: "gleam.proto",
 }
 
+// Client API for GleamRowStream service
+
+type GleamRowStreamClient interface {
+	Push(ctx context.Context, opts ...grpc.CallOption) (GleamRowStream_PushClient, error)
+}
+
+type gleamRowStreamClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGleamRowStreamClient(cc *grpc.ClientConn) GleamRowStreamClient {
+	return &gleamRowStreamClient{cc}
+}
+
+func (c *gleamRowStreamClient) Push(ctx context.Context, opts ...grpc.CallOption) (GleamRowStream_PushClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GleamRowStream_serviceDesc.Streams[0], c.cc, "/pb.GleamRowStream/Push", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gleamRowStreamPushClient{stream}
+	return x, nil
+}
+
+type GleamRowStream_PushClient interface {
+	Send(*RowBatch) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type gleamRowStreamPushClient struct {
+	grpc.ClientStream
+}
+
+func (x *gleamRowStreamPushClient) Send(m *RowBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gleamRowStreamPushClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for GleamRowStream service
+
+type GleamRowStreamServer interface {
+	Push(GleamRowStream_PushServer) error
+}
+
+func RegisterGleamRowStreamServer(s *grpc.Server, srv GleamRowStreamServer) {
+	s.RegisterService(&_GleamRowStream_serviceDesc, srv)
+}
+
+func _GleamRowStream_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GleamRowStreamServer).Push(&gleamRowStreamPushServer{stream})
+}
+
+type GleamRowStream_PushServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*RowBatch, error)
+	grpc.ServerStream
+}
+
+type gleamRowStreamPushServer struct {
+	grpc.ServerStream
+}
+
+func (x *gleamRowStreamPushServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gleamRowStreamPushServer) Recv() (*RowBatch, error) {
+	m := new(RowBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _GleamRowStream_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.GleamRowStream",
+	HandlerType: (*GleamRowStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       _GleamRowStream_Push_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gleam.proto",
+}
+
+// Client API for GleamFlowDefinition service
+
+type GleamFlowDefinitionClient interface {
+	Run(ctx context.Context, in *FlowDefinition, opts ...grpc.CallOption) (GleamFlowDefinition_RunClient, error)
+}
+
+type gleamFlowDefinitionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGleamFlowDefinitionClient(cc *grpc.ClientConn) GleamFlowDefinitionClient {
+	return &gleamFlowDefinitionClient{cc}
+}
+
+func (c *gleamFlowDefinitionClient) Run(ctx context.Context, in *FlowDefinition, opts ...grpc.CallOption) (GleamFlowDefinition_RunClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GleamFlowDefinition_serviceDesc.Streams[0], c.cc, "/pb.GleamFlowDefinition/Run", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gleamFlowDefinitionRunClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GleamFlowDefinition_RunClient interface {
+	Recv() (*FlowDefinitionResponse, error)
+	grpc.ClientStream
+}
+
+type gleamFlowDefinitionRunClient struct {
+	grpc.ClientStream
+}
+
+func (x *gleamFlowDefinitionRunClient) Recv() (*FlowDefinitionResponse, error) {
+	m := new(FlowDefinitionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for GleamFlowDefinition service
+
+type GleamFlowDefinitionServer interface {
+	Run(*FlowDefinition, GleamFlowDefinition_RunServer) error
+}
+
+func RegisterGleamFlowDefinitionServer(s *grpc.Server, srv GleamFlowDefinitionServer) {
+	s.RegisterService(&_GleamFlowDefinition_serviceDesc, srv)
+}
+
+func _GleamFlowDefinition_Run_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FlowDefinition)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GleamFlowDefinitionServer).Run(m, &gleamFlowDefinitionRunServer{stream})
+}
+
+type GleamFlowDefinition_RunServer interface {
+	Send(*FlowDefinitionResponse) error
+	grpc.ServerStream
+}
+
+type gleamFlowDefinitionRunServer struct {
+	grpc.ServerStream
+}
+
+func (x *gleamFlowDefinitionRunServer) Send(m *FlowDefinitionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _GleamFlowDefinition_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.GleamFlowDefinition",
+	HandlerType: (*GleamFlowDefinitionServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Run",
+			Handler:       _GleamFlowDefinition_Run_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gleam.proto",
+}
+
 func init() { proto.RegisterFile("gleam.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2536 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x59, 0x5f, 0x6f, 0xdc, 0xc6,
-	0x11, 0x0f, 0xef, 0x74, 0xff, 0xe6, 0xf4, 0x77, 0x25, 0xdb, 0x0c, 0x9b, 0x38, 0x0a, 0x91, 0xc6,
-	0x6a, 0x83, 0x28, 0x8e, 0xe2, 0x22, 0x85, 0x5b, 0x14, 0x95, 0xe5, 0x38, 0x51, 0x72, 0x8e, 0x8c,
-	0x95, 0x8a, 0xf4, 0x0f, 0x50, 0x83, 0x3a, 0xae, 0x25, 0x46, 0x14, 0x79, 0xe5, 0xee, 0xd9, 0x56,
-	0xbf, 0x40, 0x1f, 0x8a, 0x3e, 0x14, 0xe8, 0x4b, 0x81, 0x7e, 0x8e, 0xbe, 0xe4, 0x43, 0x14, 0xe8,
-	0x43, 0xde, 0xfa, 0xd6, 0x7e, 0x84, 0xbe, 0x17, 0x33, 0xbb, 0x24, 0x97, 0x3c, 0xde, 0x59, 0x41,
-	0xdf, 0xb8, 0x33, 0xbf, 0x19, 0xce, 0xce, 0xce, 0x0c, 0x67, 0x96, 0x30, 0x3c, 0x8b, 0x45, 0x70,
-	0xb9, 0x3b, 0xc9, 0x52, 0x95, 0xb2, 0xd6, 0xe4, 0xd4, 0xff, 0x87, 0x03, 0xab, 0x07, 0xe9, 0xe5,
-	0x64, 0xaa, 0x04, 0x17, 0xbf, 0x9b, 0x0a, 0xa9, 0xd8, 0x5b, 0x30, 0x0c, 0x03, 0x15, 0x3c, 0x1d,
-	0x8b, 0x44, 0x89, 0xcc, 0x75, 0xb6, 0x9d, 0x9d, 0x01, 0x07, 0x24, 0x1d, 0x10, 0x85, 0xfd, 0x1c,
-	0x36, 0xc6, 0x5a, 0xe4, 0x69, 0x26, 0x64, 0x3a, 0xcd, 0xc6, 0x42, 0xba, 0xad, 0xed, 0xf6, 0xce,
-	0x70, 0x6f, 0x73, 0x77, 0x72, 0xba, 0x5b, 0xe8, 0xd3, 0x3c, 0xbe, 0x3e, 0xae, 0x12, 0x24, 0xf3,
-	0xa0, 0x3f, 0x95, 0x22, 0x4b, 0x82, 0x4b, 0xe1, 0xb6, 0x49, 0x7f, 0xb1, 0x46, 0xde, 0x79, 0x2a,
-	0x15, 0xf1, 0x96, 0x34, 0x2f, 0x5f, 0x33, 0x1f, 0x96, 0x9f, 0xc5, 0xe9, 0x8b, 0xcf, 0x02, 0x79,
-	0x7e, 0x90, 0x86, 0xc2, 0xed, 0x6c, 0x3b, 0x3b, 0x2b, 0xbc, 0x42, 0xf3, 0xbf, 0x71, 0x60, 0xad,
-	0x66, 0x01, 0xfb, 0x1e, 0x0c, 0xc6, 0x93, 0xe9, 0xd3, 0x71, 0x3a, 0x4d, 0x14, 0x6d, 0xa8, 0xc3,
-	0xfb, 0xe3, 0xc9, 0xf4, 0x00, 0xd7, 0x39, 0x33, 0x16, 0xcf, 0x45, 0xec, 0xb6, 0x0a, 0xe6, 0x08,
-	0xd7, 0xc8, 0x3c, 0x2b, 0x24, 0xdb, 0x9a, 0x79, 0x66, 0x49, 0x9e, 0x15, 0x92, 0x4b, 0x05, 0xb3,
-	0x90, 0xbc, 0x14, 0x97, 0x69, 0x76, 0xf5, 0xf4, 0xf2, 0x94, 0x0c, 0x6d, 0xf3, 0xbe, 0x26, 0x3c,
-	0x3e, 0x65, 0xb7, 0xa0, 0x17, 0x46, 0xf2, 0x02, 0x59, 0x5d, 0x62, 0x75, 0x71, 0xf9, 0xf8, 0xd4,
-	0x1f, 0xc1, 0xf2, 0xc3, 0x40, 0x05, 0x85, 0xe5, 0x3b, 0xd0, 0x8f, 0xd3, 0x71, 0xa0, 0xa2, 0x34,
-	0x21, 0xc3, 0x87, 0x7b, 0xcb, 0xe8, 0xe2, 0x91, 0xa1, 0xf1, 0x82, 0xcb, 0x18, 0x2c, 0xc9, 0xe8,
-	0xf7, 0x82, 0x76, 0xd0, 0xe6, 0xf4, 0xec, 0x5f, 0x40, 0x3f, 0x47, 0xbe, 0xfa, 0x58, 0x19, 0x2c,
-	0x65, 0xc1, 0xf8, 0x82, 0x14, 0x0c, 0x38, 0x3d, 0xb3, 0x9b, 0xd0, 0x95, 0x22, 0x7b, 0x2e, 0x32,
-	0x73, 0x4c, 0x66, 0x85, 0xd8, 0x49, 0x9a, 0x29, 0xb3, 0x69, 0x7a, 0xf6, 0x23, 0x80, 0xfd, 0xb8,
-	0x30, 0xe7, 0xfa, 0x86, 0x7f, 0x08, 0x83, 0x40, 0xcb, 0x89, 0x90, 0x5e, 0x3e, 0x27, 0x8c, 0x4a,
-	0x94, 0xff, 0x10, 0xd6, 0xcb, 0x57, 0x71, 0x21, 0xa7, 0xb1, 0x62, 0x77, 0x61, 0x18, 0x14, 0x34,
-	0xe9, 0x3a, 0x14, 0x8f, 0xab, 0xa8, 0xc8, 0x82, 0xda, 0x10, 0xff, 0xaf, 0x0e, 0x0c, 0x3e, 0x13,
-	0x41, 0xa6, 0x4e, 0x45, 0xa0, 0xbe, 0x83, 0xc1, 0x1f, 0x40, 0x3f, 0x8f, 0xfb, 0x45, 0xf6, 0x16,
-	0xa0, 0xea, 0x0e, 0xdb, 0xd7, 0xda, 0x61, 0x0f, 0x3a, 0x9f, 0x5c, 0x4e, 0xd4, 0x95, 0x1f, 0xea,
-	0x80, 0x18, 0x59, 0xc7, 0x4c, 0xa9, 0xa1, 0xcf, 0x8f, 0x9e, 0x2b, 0xa6, 0xb7, 0x16, 0x9a, 0x7e,
-	0x13, 0xba, 0x69, 0xf2, 0x30, 0x92, 0x17, 0x64, 0x46, 0x9f, 0x9b, 0x95, 0xff, 0xb7, 0x15, 0xd8,
-	0x7c, 0x14, 0xa7, 0x2f, 0x3e, 0x79, 0x29, 0xc6, 0x53, 0x44, 0x1e, 0xab, 0x40, 0x4d, 0x25, 0xdb,
-	0x07, 0x90, 0x4a, 0x4c, 0x3e, 0xcd, 0xd2, 0xe9, 0x24, 0xf7, 0xe9, 0xdb, 0xa8, 0xbb, 0x01, 0xbc,
-	0x7b, 0x9c, 0x23, 0xb9, 0x25, 0x84, 0x2a, 0x54, 0x20, 0x2f, 0x8c, 0x8a, 0xd6, 0x62, 0x15, 0x27,
-	0x39, 0x92, 0x5b, 0x42, 0xec, 0x27, 0xd0, 0xc7, 0x38, 0x95, 0x42, 0x49, 0xb7, 0x4d, 0x0a, 0xde,
-	0x9a, 0xa7, 0xe0, 0xa1, 0xc6, 0xf1, 0x42, 0x80, 0x7d, 0x0e, 0x2b, 0xe6, 0xf9, 0xf8, 0x3c, 0xc8,
-	0x42, 0xe9, 0x2e, 0x91, 0x86, 0x77, 0x5e, 0xa1, 0x81, 0xc0, 0xbc, 0x2a, 0xca, 0xf6, 0xa0, 0x83,
-	0x66, 0x49, 0xb7, 0x43, 0x3a, 0xde, 0x58, 0xb4, 0x0d, 0xae, 0xa1, 0x28, 0x83, 0xde, 0x90, 0x6e,
-	0x77, 0xb1, 0x0c, 0x7a, 0x8f, 0x6b, 0x28, 0x5b, 0x85, 0x56, 0x14, 0xba, 0x3d, 0xaa, 0x6e, 0xad,
-	0x28, 0x64, 0xf7, 0xa1, 0x1b, 0x66, 0x11, 0xa6, 0x61, 0x9f, 0x8e, 0xd7, 0x9f, 0x6b, 0x3c, 0xa1,
-	0x0e, 0x93, 0x67, 0x29, 0x37, 0x12, 0x6c, 0x0b, 0x3a, 0x22, 0xcb, 0xd2, 0xcc, 0x1d, 0x50, 0xc4,
-	0xe8, 0x85, 0xb7, 0x0b, 0x4b, 0x68, 0x24, 0x25, 0xb8, 0x12, 0x93, 0xc3, 0xd0, 0x94, 0x45, 0xb3,
-	0x32, 0x16, 0xe8, 0x6a, 0xd8, 0x8a, 0x42, 0xef, 0x5b, 0x07, 0x96, 0xd0, 0x42, 0xc3, 0x70, 0x72,
-	0x46, 0x11, 0x8f, 0x2d, 0x2b, 0x1e, 0xdf, 0x80, 0xc1, 0x24, 0xc8, 0x44, 0xa2, 0x0e, 0x43, 0x7d,
-	0x60, 0x1d, 0x5e, 0x12, 0x98, 0x0b, 0x3d, 0xf4, 0xcc, 0xa1, 0x39, 0x8a, 0x0e, 0xcf, 0x97, 0xec,
-	0x5d, 0x58, 0x8d, 0x92, 0xc9, 0x54, 0x99, 0x23, 0x38, 0x0c, 0xc9, 0xcf, 0x1d, 0x5e, 0xa3, 0xb2,
-	0x1d, 0x58, 0x4b, 0xa7, 0xaa, 0x02, 0xec, 0x92, 0x41, 0x75, 0x32, 0xdb, 0x86, 0x61, 0x28, 0xe4,
-	0x38, 0x8b, 0x26, 0x94, 0x1c, 0x3d, 0x32, 0xd2, 0x26, 0x79, 0xbf, 0x82, 0x9e, 0x81, 0xcf, 0x6c,
-	0xad, 0xf4, 0x4d, 0xab, 0xe2, 0x9b, 0x77, 0x61, 0x35, 0x13, 0x41, 0x18, 0x25, 0x67, 0xc7, 0x44,
-	0xc8, 0xf7, 0x58, 0xa3, 0x7a, 0x3f, 0xd5, 0xa9, 0x9b, 0x87, 0x0f, 0xba, 0x25, 0x2c, 0x0c, 0xd6,
-	0xaf, 0x29, 0x09, 0x33, 0x1e, 0x3f, 0x80, 0x41, 0x91, 0x50, 0xe8, 0x33, 0x69, 0xde, 0xe5, 0x68,
-	0x9f, 0x99, 0x65, 0xd5, 0xd7, 0xad, 0x9a, 0xaf, 0xbd, 0x7f, 0xb7, 0x61, 0x50, 0xe4, 0xd4, 0x02,
-	0x2d, 0xd6, 0x99, 0xb4, 0xaa, 0x67, 0xb2, 0x0b, 0xbd, 0x4c, 0x37, 0x06, 0xa6, 0x72, 0x6d, 0x61,
-	0xec, 0x15, 0x71, 0x67, 0x9a, 0x06, 0x9e, 0x83, 0xd8, 0x2e, 0x40, 0x59, 0x63, 0xe9, 0xfb, 0x30,
-	0x5b, 0x85, 0x2d, 0x04, 0xfb, 0x02, 0x40, 0xe4, 0xca, 0xf2, 0xbc, 0x7a, 0xef, 0x95, 0xe5, 0xc1,
-	0x32, 0xc0, 0x12, 0xf7, 0xfe, 0xeb, 0xc0, 0xa0, 0xe0, 0xb0, 0x37, 0xb1, 0x78, 0x05, 0x99, 0x7a,
-	0xaa, 0x22, 0x53, 0x30, 0xdb, 0x7c, 0x40, 0x94, 0x93, 0xe8, 0x92, 0x9a, 0x02, 0xa9, 0xd2, 0x89,
-	0xe6, 0xea, 0xaf, 0x66, 0x1f, 0x09, 0xc4, 0x7c, 0x0b, 0x86, 0xf2, 0x4a, 0x2a, 0x71, 0xa9, 0xd9,
-	0xb8, 0x75, 0x87, 0x83, 0x26, 0xe5, 0xd2, 0xd8, 0xb2, 0x68, 0xf6, 0x12, 0xb1, 0xa9, 0x87, 0x21,
-	0x66, 0x91, 0x73, 0xf8, 0xdd, 0x5f, 0x36, 0x39, 0x87, 0x3a, 0x75, 0x7c, 0x3e, 0x3d, 0x0f, 0xe4,
-	0x39, 0x85, 0xec, 0x32, 0x07, 0x4d, 0xc2, 0xf6, 0x85, 0x7d, 0x0c, 0x2b, 0xc2, 0xde, 0x31, 0xc5,
-	0xeb, 0x70, 0x6f, 0xa3, 0xe2, 0x71, 0x64, 0xf0, 0x2a, 0xce, 0xfb, 0x97, 0x03, 0x50, 0xa6, 0x7e,
-	0xa5, 0xbd, 0x72, 0x16, 0xb4, 0x57, 0xad, 0x5a, 0x7b, 0x75, 0x3b, 0x3f, 0x8b, 0xe0, 0x34, 0xce,
-	0x1b, 0x33, 0x8b, 0xc2, 0xee, 0xc0, 0x5a, 0xb9, 0xd2, 0x9b, 0xd0, 0x1d, 0xda, 0x6a, 0x49, 0xa6,
-	0x8d, 0x54, 0x3d, 0xdf, 0x59, 0xe8, 0xf9, 0x6e, 0xcd, 0xf3, 0x79, 0x41, 0xe9, 0x95, 0x05, 0xc5,
-	0xff, 0x93, 0x03, 0x9b, 0x8f, 0xa2, 0xb8, 0xfc, 0x52, 0x9a, 0x60, 0x6b, 0xfa, 0x18, 0xae, 0x43,
-	0x3b, 0x8c, 0x32, 0xb3, 0x37, 0x7c, 0x44, 0x14, 0xd9, 0xda, 0xa6, 0x7a, 0x4a, 0xcf, 0x33, 0x9d,
-	0xe4, 0xd2, 0x6c, 0x27, 0x89, 0x49, 0x31, 0x4e, 0x13, 0x25, 0x12, 0x65, 0xce, 0x31, 0x5f, 0xfa,
-	0x23, 0xd8, 0xaa, 0x9a, 0x23, 0x27, 0x69, 0x22, 0x05, 0x7b, 0x07, 0x56, 0x82, 0x18, 0xab, 0xc0,
-	0xd5, 0x27, 0x2f, 0x23, 0xa9, 0x24, 0x19, 0xd6, 0xe7, 0x55, 0x22, 0x66, 0x7a, 0xaa, 0xdb, 0xac,
-	0x3e, 0x6f, 0xa5, 0x17, 0xfe, 0x9f, 0x1d, 0x58, 0xaf, 0x27, 0x14, 0xbb, 0x8f, 0xb5, 0x50, 0xaa,
-	0x6c, 0x3a, 0xa6, 0x53, 0x16, 0xca, 0x34, 0x25, 0x0c, 0x83, 0xe1, 0xb0, 0xc2, 0xe1, 0x35, 0x64,
-	0x83, 0x0b, 0xec, 0x96, 0xa5, 0x7d, 0x8d, 0x96, 0xc5, 0xff, 0xbb, 0x03, 0x1b, 0x96, 0x4d, 0x66,
-	0x7f, 0xd8, 0x3e, 0x50, 0xb8, 0x92, 0x31, 0xcb, 0xdc, 0xac, 0xca, 0x78, 0x6f, 0xd9, 0xf1, 0x7e,
-	0x1b, 0xac, 0x84, 0x69, 0x48, 0x21, 0x13, 0xa6, 0x27, 0x4d, 0x19, 0x34, 0x93, 0x0a, 0x9d, 0xeb,
-	0xa5, 0x82, 0xff, 0x5b, 0x58, 0xa9, 0xf0, 0x67, 0x4e, 0xda, 0x69, 0x38, 0xe9, 0x1f, 0xe0, 0x37,
-	0x3a, 0x50, 0x95, 0x29, 0xc6, 0xf6, 0x31, 0xbe, 0x47, 0x23, 0xfc, 0x3f, 0x3a, 0xb0, 0x56, 0x63,
-	0xcd, 0xfd, 0x88, 0xde, 0x84, 0xae, 0x2e, 0xa3, 0xf9, 0x07, 0x44, 0xaf, 0xd0, 0x24, 0xfa, 0xa2,
-	0xd1, 0x14, 0x61, 0x7a, 0xeb, 0x36, 0xaf, 0xd0, 0x30, 0x94, 0xb4, 0x73, 0x73, 0xd0, 0x12, 0x81,
-	0xaa, 0x44, 0x6c, 0x61, 0x57, 0x0f, 0xd2, 0x44, 0x65, 0x69, 0xfc, 0x58, 0x48, 0x19, 0x9c, 0x51,
-	0x12, 0x47, 0xf2, 0x88, 0xda, 0xba, 0xc3, 0x23, 0x13, 0x80, 0x16, 0x85, 0x7d, 0x08, 0x43, 0x0c,
-	0x46, 0x13, 0x67, 0xa6, 0x5f, 0x5c, 0xc3, 0x1d, 0xf3, 0x92, 0xcc, 0x6d, 0x0c, 0xbb, 0x07, 0xcb,
-	0x2f, 0xb2, 0xa8, 0x98, 0x10, 0x4d, 0x04, 0xad, 0xa3, 0xcc, 0x57, 0x16, 0x9d, 0x57, 0x50, 0xfe,
-	0x07, 0xf0, 0xfa, 0x43, 0x11, 0x0b, 0x25, 0x2a, 0x1d, 0xd5, 0xfc, 0xcc, 0xf5, 0xf7, 0xc0, 0x6b,
-	0x12, 0x30, 0xb1, 0x57, 0xc4, 0x98, 0x63, 0xf5, 0x31, 0xfe, 0x3d, 0x58, 0x3d, 0x88, 0x45, 0x90,
-	0x4c, 0x27, 0xb9, 0xe6, 0x6b, 0x9c, 0xb7, 0x7f, 0x07, 0xd6, 0x0a, 0xa9, 0x85, 0xea, 0x33, 0x58,
-	0xb6, 0x77, 0x88, 0xfd, 0xc4, 0xf8, 0x3c, 0x48, 0x12, 0x11, 0x7f, 0x59, 0x5a, 0x6f, 0x93, 0xd0,
-	0xfd, 0xe4, 0x85, 0xec, 0xcb, 0xb2, 0xc2, 0x5a, 0x14, 0xd4, 0x80, 0xae, 0x15, 0xd9, 0x81, 0x35,
-	0x52, 0xda, 0x24, 0xff, 0x08, 0x86, 0xd6, 0x49, 0x5c, 0xef, 0x95, 0x5a, 0xde, 0x7e, 0x65, 0x49,
-	0xf1, 0xff, 0xe3, 0xc0, 0x6a, 0xb5, 0x62, 0xb0, 0x8f, 0x30, 0x02, 0x0b, 0x4a, 0xde, 0xd9, 0xaf,
-	0xd5, 0xe2, 0x9e, 0x57, 0x40, 0x75, 0xd3, 0x5b, 0x33, 0xa6, 0xcf, 0xf8, 0xbe, 0xdd, 0x90, 0x6b,
-	0xdb, 0x30, 0x8c, 0xe4, 0x93, 0x2c, 0x7d, 0x16, 0xc5, 0x51, 0x72, 0x46, 0x61, 0xdd, 0xe7, 0x36,
-	0x09, 0xb5, 0x04, 0x67, 0x22, 0x51, 0xfb, 0x61, 0x98, 0x09, 0x29, 0x29, 0xf5, 0x07, 0xbc, 0x42,
-	0x2b, 0xe2, 0xa7, 0x6b, 0xc5, 0xcf, 0x3f, 0x37, 0x61, 0x68, 0x59, 0xff, 0x9d, 0xd3, 0xf2, 0x36,
-	0x80, 0x1e, 0xd0, 0x0f, 0x93, 0xc7, 0x0f, 0xcc, 0xc9, 0x58, 0x14, 0xf6, 0x39, 0x6c, 0x52, 0x8a,
-	0x52, 0x5c, 0x8e, 0x8a, 0x49, 0x53, 0xcf, 0x13, 0x2e, 0xfa, 0xce, 0x0e, 0xdc, 0x62, 0xfa, 0x6a,
-	0x12, 0x62, 0x23, 0xd8, 0x3a, 0xa2, 0x4c, 0xae, 0x29, 0xeb, 0xbc, 0x42, 0x59, 0xa3, 0x14, 0xdb,
-	0xc5, 0x31, 0x3d, 0x16, 0x63, 0x45, 0xfe, 0x18, 0xee, 0xdd, 0xac, 0x1d, 0xe4, 0xee, 0x31, 0x71,
-	0xb9, 0x41, 0xb1, 0xdf, 0xc0, 0x8d, 0xaf, 0xd3, 0x28, 0x79, 0x12, 0x64, 0x2a, 0x42, 0xbe, 0x08,
-	0x8f, 0xd3, 0x0c, 0x87, 0x53, 0xdd, 0x70, 0x7c, 0xbf, 0x2e, 0xfe, 0x79, 0x13, 0x98, 0x37, 0xeb,
-	0x60, 0x21, 0xb8, 0xe3, 0x94, 0xba, 0xb4, 0x59, 0xfd, 0x7a, 0x7c, 0xd9, 0xa9, 0xeb, 0x3f, 0x98,
-	0x83, 0xe7, 0x73, 0x35, 0xb1, 0xfb, 0x00, 0x93, 0x68, 0x22, 0xf6, 0xe5, 0x7e, 0x76, 0x26, 0x69,
-	0xb6, 0x19, 0xee, 0x79, 0x75, 0xbd, 0x4f, 0x0a, 0x04, 0xb7, 0xd0, 0xec, 0x08, 0x36, 0xe4, 0x38,
-	0x50, 0x4a, 0x64, 0x85, 0x5e, 0xe9, 0x02, 0xa9, 0x78, 0x7b, 0xc6, 0x73, 0x75, 0x20, 0x9f, 0x95,
-	0x45, 0x85, 0xe3, 0x34, 0x46, 0xd7, 0x5a, 0x0a, 0x87, 0xcd, 0x0a, 0x0f, 0xea, 0x40, 0x3e, 0x2b,
-	0xcb, 0x46, 0xb0, 0xae, 0xa3, 0x66, 0x12, 0x47, 0x8a, 0x53, 0x86, 0xb9, 0xcb, 0xa4, 0x6f, 0xbb,
-	0xae, 0xef, 0xb0, 0x86, 0xe3, 0x33, 0x92, 0xe8, 0xab, 0x2c, 0x9d, 0x26, 0x21, 0x4f, 0x4f, 0xa3,
-	0xc4, 0x5d, 0x69, 0xf6, 0x15, 0x2f, 0x10, 0xdc, 0x42, 0xb3, 0x7b, 0xfa, 0x6e, 0x21, 0x3e, 0x49,
-	0x27, 0xee, 0x2a, 0x49, 0xba, 0x75, 0xc9, 0x91, 0xe1, 0xf3, 0x02, 0xc9, 0x3e, 0x86, 0xc1, 0x69,
-	0x96, 0x06, 0xe1, 0x38, 0x90, 0xca, 0x5d, 0x23, 0xb1, 0xd7, 0xeb, 0x62, 0x0f, 0x72, 0x00, 0x2f,
-	0xb1, 0xec, 0x97, 0xb0, 0x45, 0x4a, 0xb0, 0x5c, 0xec, 0x27, 0x21, 0x06, 0xde, 0x57, 0x91, 0x3a,
-	0x77, 0xd7, 0x49, 0xc7, 0x3b, 0x8d, 0xaf, 0xae, 0x61, 0x79, 0xa3, 0x06, 0xca, 0x11, 0x9a, 0xfa,
-	0xdc, 0x8d, 0x39, 0x39, 0x42, 0x5c, 0x6e, 0x50, 0xb8, 0x05, 0xd2, 0x83, 0xf1, 0xe6, 0xb2, 0xe6,
-	0x2d, 0x8c, 0x72, 0x00, 0x2f, 0xb1, 0xec, 0x00, 0x56, 0x2e, 0x45, 0x76, 0x26, 0x74, 0xa0, 0x9e,
-	0xa4, 0xee, 0x26, 0x09, 0xbf, 0x59, 0x17, 0x7e, 0x6c, 0x83, 0x78, 0x55, 0x86, 0x7d, 0x08, 0x3d,
-	0x22, 0x9c, 0xa4, 0xee, 0x16, 0x89, 0xdf, 0x6a, 0x14, 0x3f, 0x49, 0x79, 0x8e, 0xc3, 0xf7, 0x92,
-	0x11, 0x0f, 0x23, 0xa9, 0xa2, 0x64, 0xac, 0xdc, 0x1b, 0xcd, 0xef, 0x1d, 0xd9, 0x20, 0x5e, 0x95,
-	0xc1, 0x50, 0x21, 0xc2, 0x28, 0xba, 0x8c, 0x94, 0x7b, 0xb3, 0x39, 0x54, 0x46, 0x05, 0x82, 0x5b,
-	0x68, 0xc6, 0x81, 0xd1, 0x8a, 0x32, 0xf6, 0xc1, 0x95, 0x49, 0xf9, 0x5b, 0xe5, 0x8d, 0xc5, 0x8c,
-	0x8e, 0x0a, 0x92, 0x37, 0x48, 0xb3, 0xf7, 0xa0, 0x33, 0x4d, 0x70, 0x92, 0x74, 0x49, 0xcd, 0x8d,
-	0xba, 0x9a, 0x5f, 0x20, 0x93, 0x6b, 0x8c, 0x37, 0x82, 0xae, 0x2e, 0x74, 0x58, 0xca, 0x2f, 0xc4,
-	0xd5, 0x61, 0x12, 0x8a, 0x97, 0x22, 0x1f, 0x76, 0x2d, 0x0a, 0x7e, 0x62, 0x9e, 0x07, 0xf1, 0x54,
-	0xe4, 0x08, 0x3d, 0xf4, 0x56, 0x68, 0xde, 0x1f, 0x1c, 0xb8, 0xd1, 0x58, 0xf8, 0x70, 0x30, 0x88,
-	0x2a, 0xaa, 0xf3, 0x25, 0xdb, 0x81, 0xb5, 0x48, 0x8e, 0xc4, 0x33, 0x75, 0x34, 0x55, 0x22, 0x43,
-	0x69, 0xd3, 0xe7, 0xd7, 0xc9, 0xec, 0x87, 0xb0, 0x1e, 0x49, 0x1e, 0x9d, 0x9d, 0x5b, 0x50, 0x7d,
-	0x27, 0x37, 0x43, 0xf7, 0xee, 0x81, 0x3b, 0xaf, 0x42, 0xce, 0xb7, 0xc5, 0xdb, 0x06, 0x28, 0xeb,
-	0x1f, 0x7e, 0x30, 0xc7, 0x79, 0x3b, 0x34, 0xe0, 0xf4, 0xec, 0xbd, 0x0f, 0x1b, 0x33, 0xe5, 0x6d,
-	0x81, 0xc2, 0x4d, 0xd8, 0x98, 0x29, 0x5e, 0xde, 0x5d, 0x58, 0xaf, 0x57, 0x20, 0xf6, 0x06, 0x0c,
-	0xa8, 0x06, 0x9d, 0x5c, 0x4d, 0xf2, 0x17, 0x96, 0x04, 0x6f, 0x19, 0xa0, 0xac, 0x35, 0xde, 0xbe,
-	0xbe, 0xa2, 0xa6, 0xaa, 0xb1, 0x0c, 0x4e, 0x62, 0xbe, 0xd5, 0x4e, 0xc2, 0xee, 0x40, 0x3f, 0xcd,
-	0x42, 0x91, 0x3d, 0xb8, 0xca, 0xfb, 0xf2, 0x21, 0x9e, 0xfe, 0x91, 0xa6, 0xf1, 0x82, 0xe9, 0x0d,
-	0x61, 0x50, 0xd4, 0x12, 0xef, 0x2e, 0x6c, 0x35, 0x15, 0x85, 0x05, 0xdb, 0xfa, 0x35, 0x74, 0x75,
-	0xea, 0x63, 0x63, 0x10, 0x49, 0xf4, 0x99, 0x69, 0x9b, 0xcd, 0x8a, 0x6e, 0xbb, 0x03, 0x75, 0x9e,
-	0xdf, 0x71, 0xe1, 0x33, 0xd2, 0x02, 0xfc, 0xf2, 0xb4, 0xb7, 0xdb, 0x48, 0xc3, 0x67, 0x9c, 0xbb,
-	0x44, 0xf2, 0x9c, 0x1a, 0x82, 0x01, 0xc7, 0x47, 0xef, 0x1e, 0x0c, 0x8a, 0x1a, 0x51, 0xd9, 0x90,
-	0xb3, 0x68, 0x43, 0x3f, 0x86, 0x95, 0x4a, 0x71, 0xb8, 0xbe, 0xe4, 0x00, 0x7a, 0xa6, 0x2e, 0xa0,
-	0x92, 0x4a, 0xa6, 0x5f, 0x5f, 0xc9, 0x1e, 0x40, 0x99, 0xe1, 0xb5, 0x43, 0xc1, 0x09, 0xf0, 0xd9,
-	0x33, 0x29, 0xf2, 0xf6, 0xcf, 0xac, 0xbc, 0x5d, 0x60, 0xb3, 0x19, 0xbd, 0xc0, 0xe9, 0x77, 0xa0,
-	0x43, 0xa9, 0xab, 0xc7, 0x95, 0x27, 0x41, 0x16, 0xc4, 0xb1, 0x88, 0xcb, 0x71, 0x25, 0xa7, 0xf8,
-	0x3f, 0x82, 0x9e, 0xb1, 0x10, 0x5b, 0x74, 0x12, 0x37, 0xd6, 0xe8, 0x05, 0x52, 0xc9, 0x72, 0x63,
-	0x90, 0x5e, 0xf8, 0x7f, 0x71, 0x6a, 0x97, 0x6f, 0x1e, 0xf4, 0x1f, 0xc5, 0xe9, 0x0b, 0xab, 0x87,
-	0x2e, 0xd6, 0x18, 0xaf, 0xe5, 0x4d, 0xa2, 0x56, 0x53, 0x12, 0xd8, 0xbb, 0xb0, 0x6a, 0x6b, 0x3a,
-	0x0c, 0x4d, 0x6b, 0x58, 0xa3, 0x62, 0x4d, 0x79, 0xd4, 0x70, 0xa5, 0x60, 0xd3, 0xfc, 0xaf, 0x61,
-	0xab, 0xa9, 0xad, 0xc3, 0x68, 0xb2, 0x2c, 0xa3, 0x67, 0xa4, 0x7d, 0x96, 0x9a, 0x09, 0x6d, 0xc0,
-	0xe9, 0x19, 0x69, 0x4f, 0xf0, 0x7b, 0xa4, 0x2d, 0xa0, 0x67, 0xeb, 0x4e, 0x7f, 0xc9, 0xbe, 0xd3,
-	0xdf, 0xfb, 0xc6, 0x81, 0xe1, 0xa7, 0xb1, 0x08, 0x2e, 0x1f, 0x07, 0x52, 0x51, 0x17, 0xb0, 0xfc,
-	0xa9, 0x50, 0xe5, 0x4f, 0x38, 0x56, 0xb9, 0x01, 0xa0, 0x61, 0xc3, 0xdb, 0xaa, 0xdd, 0xd4, 0xd1,
-	0xaf, 0x15, 0xff, 0x35, 0xf6, 0x3e, 0xac, 0x1c, 0x8b, 0x24, 0x2c, 0xff, 0x96, 0xac, 0x20, 0xb0,
-	0x58, 0x7a, 0x03, 0x9a, 0xcb, 0xe9, 0x87, 0xc5, 0x6b, 0x3b, 0x0e, 0xdb, 0x87, 0x5b, 0x08, 0x6f,
-	0xfa, 0xa3, 0x70, 0x6b, 0xce, 0xdd, 0x5e, 0x4d, 0xc5, 0xde, 0x11, 0xac, 0x90, 0xf1, 0x1a, 0x96,
-	0x66, 0xec, 0x67, 0xe0, 0x99, 0xea, 0x53, 0x91, 0xc4, 0xe8, 0x1e, 0x4b, 0x36, 0x7b, 0x31, 0x50,
-	0x57, 0xf8, 0x6d, 0x0b, 0x80, 0x34, 0xee, 0xe3, 0x1c, 0xc1, 0xbe, 0x80, 0x75, 0x32, 0xd1, 0xba,
-	0xc6, 0x31, 0xb6, 0xcd, 0xde, 0x33, 0x79, 0xee, 0x2c, 0x43, 0x8f, 0x8d, 0xa8, 0xf9, 0xae, 0xc3,
-	0xee, 0x43, 0x4f, 0xbf, 0x5b, 0xb0, 0xc6, 0xeb, 0x51, 0xef, 0x46, 0x8d, 0x9a, 0x4b, 0xdf, 0x75,
-	0xfe, 0xdf, 0x7d, 0xb1, 0x43, 0xe8, 0xea, 0xa9, 0x99, 0xd1, 0x97, 0x7e, 0xee, 0xc8, 0xed, 0xdd,
-	0x9e, 0xc7, 0xce, 0x8d, 0x61, 0xf7, 0xa0, 0x67, 0xc6, 0x62, 0x13, 0x1c, 0x95, 0xc9, 0xda, 0xdb,
-	0xac, 0xd0, 0x72, 0xa9, 0xd3, 0x2e, 0xfd, 0x4d, 0xfe, 0xe8, 0x7f, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x05, 0xf3, 0x2f, 0xfd, 0x5c, 0x1e, 0x00, 0x00,
+	// 4931 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x7b, 0xcd, 0x6f, 0xe4, 0xc6,
+	0x72, 0xb8, 0x39, 0x1f, 0x9a, 0x99, 0x9a, 0xd1, 0xc7, 0xf6, 0x6a, 0x77, 0x69, 0x7a, 0xed, 0x95,
+	0xf9, 0xfc, 0xbc, 0xb2, 0xf7, 0x67, 0xd9, 0x96, 0xd7, 0xf0, 0x2f, 0x9b, 0x97, 0xc0, 0x5a, 0xed,
+	0x87, 0x65, 0x6b, 0xad, 0x45, 0x4b, 0x7e, 0x2f, 0x79, 0x0f, 0x88, 0x40, 0x0d, 0x5b, 0x23, 0x46,
+	0x1c, 0x92, 0x4b, 0x72, 0x56, 0x2b, 0x9f, 0x92, 0xdc, 0x82, 0x20, 0x97, 0x24, 0xc7, 0x00, 0xb9,
+	0x04, 0x48, 0x10, 0xe4, 0xfc, 0x0e, 0xc9, 0x29, 0x48, 0x80, 0xfc, 0x07, 0x01, 0x72, 0x48, 0x4e,
+	0x01, 0xf2, 0x07, 0x04, 0x39, 0xe4, 0x16, 0x54, 0x75, 0x37, 0xd9, 0xe4, 0x50, 0x5a, 0xf9, 0xe5,
+	0xc6, 0xaa, 0xae, 0xaa, 0xee, 0xae, 0xae, 0xae, 0xae, 0xae, 0x2e, 0xc2, 0x70, 0x12, 0x0a, 0x6f,
+	0xba, 0x91, 0xa4, 0x71, 0x1e, 0xb3, 0x56, 0x72, 0xe4, 0xfe, 0x8f, 0x05, 0x4b, 0xdb, 0xf1, 0x34,
+	0x99, 0xe5, 0x82, 0x8b, 0x17, 0x33, 0x91, 0xe5, 0xec, 0x0e, 0x0c, 0x7d, 0x2f, 0xf7, 0x0e, 0xc7,
+	0x22, 0xca, 0x45, 0x6a, 0x5b, 0x6b, 0xd6, 0xfa, 0x80, 0x03, 0xa2, 0xb6, 0x09, 0xc3, 0xbe, 0x84,
+	0x6b, 0x63, 0xc9, 0x72, 0x98, 0x8a, 0x2c, 0x9e, 0xa5, 0x63, 0x91, 0xd9, 0xad, 0xb5, 0xf6, 0xfa,
+	0x70, 0xf3, 0xfa, 0x46, 0x72, 0xb4, 0x51, 0xc8, 0x93, 0x6d, 0x7c, 0x65, 0x5c, 0x45, 0x64, 0xcc,
+	0x81, 0xfe, 0x2c, 0x13, 0x69, 0xe4, 0x4d, 0x85, 0xdd, 0x26, 0xf9, 0x05, 0x8c, 0x6d, 0x27, 0x71,
+	0x96, 0x53, 0x5b, 0x47, 0xb6, 0x69, 0x98, 0xb9, 0x30, 0x3a, 0x0e, 0xe3, 0xb3, 0xaf, 0xbc, 0xec,
+	0x64, 0x3b, 0xf6, 0x85, 0xdd, 0x5d, 0xb3, 0xd6, 0x17, 0x79, 0x05, 0xc7, 0xd6, 0x61, 0x99, 0xa6,
+	0x37, 0x8e, 0xc3, 0x9f, 0x8a, 0x34, 0x0b, 0xe2, 0xc8, 0x5e, 0x58, 0xb3, 0xd6, 0xbb, 0xbc, 0x8e,
+	0x76, 0xff, 0xa0, 0x05, 0xcb, 0xb5, 0xb1, 0xb2, 0xb7, 0x60, 0x30, 0x4e, 0x66, 0x87, 0xe3, 0x78,
+	0x16, 0xe5, 0x34, 0xf5, 0x2e, 0xef, 0x8f, 0x93, 0xd9, 0x36, 0xc2, 0xba, 0x31, 0x14, 0x2f, 0x45,
+	0x68, 0xb7, 0x8a, 0xc6, 0x5d, 0x84, 0xb1, 0x71, 0x52, 0x70, 0xb6, 0x65, 0xe3, 0xc4, 0xe0, 0x9c,
+	0x14, 0x9c, 0x9d, 0xa2, 0xb1, 0xe0, 0x9c, 0x8a, 0x69, 0x9c, 0x9e, 0x1f, 0x4e, 0x8f, 0x68, 0x4a,
+	0x6d, 0xde, 0x97, 0x88, 0x67, 0x47, 0xec, 0x16, 0xf4, 0xfc, 0x20, 0x3b, 0xc5, 0xa6, 0x05, 0x6a,
+	0x5a, 0x40, 0xf0, 0xd9, 0x11, 0xfb, 0x11, 0x2c, 0x46, 0xb1, 0x2f, 0x0e, 0x33, 0x11, 0x8a, 0x71,
+	0x1e, 0xa7, 0x76, 0x6f, 0xad, 0xbd, 0x3e, 0xe0, 0x23, 0x44, 0xee, 0x2b, 0x1c, 0x5b, 0x83, 0x61,
+	0x1e, 0x87, 0x22, 0xf5, 0xf2, 0x20, 0x8e, 0x32, 0xbb, 0x4f, 0x24, 0x26, 0xca, 0xdd, 0x85, 0xd1,
+	0x23, 0x2f, 0xf7, 0x0a, 0x05, 0xac, 0x43, 0x3f, 0x8c, 0xc7, 0xd4, 0x48, 0xf3, 0x1f, 0x6e, 0x8e,
+	0x70, 0x4d, 0x77, 0x15, 0x8e, 0x17, 0xad, 0x8c, 0x41, 0x27, 0x0b, 0xbe, 0x17, 0xa4, 0x88, 0x36,
+	0xa7, 0x6f, 0xf7, 0x14, 0xfa, 0x9a, 0xf2, 0xf5, 0x76, 0xc4, 0xa0, 0x93, 0x7a, 0xe3, 0x53, 0x12,
+	0x30, 0xe0, 0xf4, 0xcd, 0x6e, 0xc2, 0x42, 0x26, 0xd2, 0x97, 0x22, 0x55, 0x76, 0xa1, 0x20, 0xa4,
+	0x4d, 0xe2, 0x34, 0x57, 0xba, 0xa3, 0x6f, 0x37, 0x00, 0xd8, 0x0a, 0x8b, 0xe1, 0x5c, 0x7d, 0xe0,
+	0x9f, 0xc2, 0xc0, 0x93, 0x7c, 0xc2, 0xa7, 0xce, 0x2f, 0xb0, 0xdb, 0x92, 0xca, 0x7d, 0x04, 0x2b,
+	0x65, 0x57, 0x5c, 0x64, 0xb3, 0x30, 0x67, 0x9f, 0xc0, 0xd0, 0x2b, 0x70, 0x99, 0x6d, 0xd1, 0x06,
+	0x58, 0x42, 0x41, 0x06, 0xa9, 0x49, 0xe2, 0xfe, 0x5d, 0x0b, 0x06, 0x5f, 0x09, 0x2f, 0xcd, 0x8f,
+	0x84, 0x97, 0xff, 0x80, 0x01, 0x7f, 0x0c, 0x7d, 0xbd, 0xd1, 0x2e, 0x1b, 0x6f, 0x41, 0x54, 0x9d,
+	0x61, 0xfb, 0x2a, 0x33, 0x64, 0xef, 0x42, 0x27, 0x8c, 0x3d, 0x9f, 0x14, 0x3c, 0xdc, 0x5c, 0xa4,
+	0x69, 0x4c, 0x44, 0x94, 0xef, 0xc6, 0x9e, 0xcf, 0xa9, 0xa9, 0x69, 0x67, 0x75, 0x1b, 0x77, 0x16,
+	0xae, 0x62, 0xe8, 0x1d, 0x89, 0x30, 0xb3, 0x17, 0xc8, 0xe2, 0x14, 0x84, 0xf8, 0xdc, 0x0b, 0xa2,
+	0x3c, 0x53, 0xc6, 0xaa, 0x20, 0xdc, 0xd7, 0x63, 0x6f, 0x7c, 0x22, 0xfc, 0xfd, 0x13, 0x2f, 0xf5,
+	0xb5, 0x9d, 0x56, 0x70, 0xee, 0x1f, 0x59, 0x30, 0x28, 0x46, 0x84, 0xd6, 0x9f, 0xce, 0xa2, 0x28,
+	0x88, 0x26, 0x87, 0xb9, 0x97, 0x9d, 0x66, 0x6a, 0xaf, 0x8e, 0x14, 0xf2, 0x00, 0x71, 0x6c, 0x0d,
+	0x46, 0xb4, 0x77, 0x66, 0x99, 0xf0, 0x71, 0x03, 0x49, 0x4b, 0x05, 0xc4, 0x7d, 0x97, 0x09, 0xff,
+	0xd9, 0x11, 0xfb, 0x02, 0xec, 0x48, 0xe4, 0x67, 0x71, 0x7a, 0x7a, 0x78, 0x74, 0x9e, 0x8b, 0xec,
+	0x30, 0x11, 0xe9, 0x61, 0x26, 0xc6, 0x71, 0x24, 0xf5, 0xd6, 0xe6, 0x37, 0x54, 0xfb, 0x43, 0x6c,
+	0x7e, 0x2e, 0xd2, 0x7d, 0x6a, 0x74, 0x7b, 0xd0, 0x7d, 0x3c, 0x4d, 0xf2, 0x73, 0xf7, 0xaf, 0x2d,
+	0xb9, 0x81, 0x76, 0x8d, 0x6d, 0x41, 0xbe, 0x4b, 0xda, 0x3b, 0x7d, 0x57, 0x96, 0xba, 0x75, 0xe9,
+	0x52, 0xdf, 0x84, 0x85, 0x38, 0x7a, 0x14, 0x64, 0xa7, 0xd4, 0x7d, 0x9f, 0x2b, 0x08, 0x37, 0x32,
+	0x7a, 0xd1, 0x54, 0x64, 0xa4, 0x77, 0xe9, 0x18, 0x4d, 0x14, 0x52, 0x78, 0xe3, 0xb1, 0xc8, 0xb2,
+	0x83, 0xf8, 0x54, 0xc8, 0x95, 0x19, 0x70, 0x13, 0xe5, 0xfe, 0xf9, 0x22, 0x5c, 0x7f, 0x12, 0xc6,
+	0x67, 0x8f, 0x5f, 0x89, 0xf1, 0x0c, 0x7b, 0xdb, 0xcf, 0xbd, 0x7c, 0x96, 0xb1, 0x2d, 0x80, 0x2c,
+	0x17, 0xc9, 0xd3, 0x34, 0x9e, 0x25, 0xda, 0x8e, 0xdf, 0xc5, 0xf1, 0x35, 0x10, 0x6f, 0xec, 0x6b,
+	0x4a, 0x6e, 0x30, 0xa1, 0x08, 0x5c, 0x06, 0x25, 0xa2, 0x75, 0xb9, 0x88, 0x03, 0x4d, 0xc9, 0x0d,
+	0x26, 0xf6, 0xeb, 0xd0, 0x47, 0xdf, 0x90, 0x89, 0x3c, 0xb3, 0xdb, 0x24, 0xe0, 0xce, 0x45, 0x02,
+	0x1e, 0x49, 0x3a, 0x5e, 0x30, 0xb0, 0xaf, 0x61, 0x51, 0x7d, 0x2b, 0x0b, 0xea, 0x90, 0x84, 0xf7,
+	0x5e, 0x23, 0x81, 0x88, 0x79, 0x95, 0x95, 0x6d, 0x42, 0x57, 0x9a, 0x54, 0x97, 0x64, 0xdc, 0xbe,
+	0x6c, 0x1a, 0x5c, 0x92, 0x22, 0x0f, 0x6a, 0x43, 0xda, 0xfb, 0x25, 0x3c, 0xa8, 0x3d, 0x2e, 0x49,
+	0xd9, 0x12, 0xb4, 0x02, 0xdf, 0xee, 0xd1, 0x11, 0xd6, 0x0a, 0x7c, 0xf6, 0x00, 0x16, 0xfc, 0x34,
+	0x40, 0xd7, 0xd7, 0x27, 0x13, 0x71, 0x2f, 0x1c, 0x3c, 0x51, 0xed, 0x44, 0xc7, 0x31, 0x57, 0x1c,
+	0x6c, 0x15, 0xba, 0x22, 0x4d, 0xe3, 0xd4, 0x1e, 0xd0, 0xb2, 0x4b, 0xc0, 0xd9, 0x80, 0x0e, 0x0e,
+	0x92, 0x9c, 0x6a, 0x2e, 0x92, 0x1d, 0x5f, 0xed, 0x12, 0x05, 0xa9, 0x11, 0xc8, 0x83, 0xac, 0x15,
+	0xf8, 0xce, 0xbf, 0x58, 0xd0, 0xc1, 0x11, 0xaa, 0x06, 0x4b, 0x37, 0x14, 0x36, 0xdd, 0x32, 0x6c,
+	0xfa, 0x36, 0x0c, 0x12, 0x2f, 0x15, 0x51, 0xbe, 0xe3, 0xcb, 0x05, 0xeb, 0xf2, 0x12, 0xc1, 0x6c,
+	0xe8, 0xa1, 0x66, 0x76, 0xd4, 0x52, 0x74, 0xb9, 0x06, 0xd9, 0xfb, 0xb0, 0x14, 0x44, 0xc9, 0x2c,
+	0x57, 0x4b, 0xb0, 0xe3, 0x93, 0x9e, 0xbb, 0xbc, 0x86, 0x45, 0x6f, 0x13, 0xcf, 0xf2, 0x0a, 0xa1,
+	0x3a, 0xc7, 0x6b, 0x68, 0xb4, 0x7c, 0x5f, 0x64, 0xe3, 0x34, 0x48, 0x68, 0x83, 0xf5, 0xa4, 0xe5,
+	0x1b, 0x28, 0xe7, 0xb7, 0xa1, 0xa7, 0xc8, 0xe7, 0xa6, 0x56, 0xea, 0xa6, 0x55, 0xd1, 0xcd, 0xfb,
+	0xb0, 0x94, 0x0a, 0xcf, 0x0f, 0xa2, 0xc9, 0x3e, 0x21, 0xf4, 0x1c, 0x6b, 0x58, 0xe7, 0x27, 0x72,
+	0xfb, 0x6b, 0xf3, 0x41, 0xb5, 0xf8, 0xc5, 0x80, 0x65, 0x37, 0x25, 0x62, 0x4e, 0xe3, 0xdb, 0x30,
+	0x28, 0x36, 0x14, 0xea, 0x2c, 0x53, 0x7d, 0x59, 0x52, 0x67, 0x0a, 0xac, 0xea, 0xba, 0x55, 0xd3,
+	0xb5, 0xf3, 0x1f, 0x6d, 0x18, 0x14, 0x7b, 0xea, 0x12, 0x29, 0xc6, 0x9a, 0xb4, 0xaa, 0x6b, 0xb2,
+	0x01, 0xbd, 0x54, 0x46, 0x7f, 0xea, 0xb4, 0x58, 0x45, 0xdb, 0x2b, 0xec, 0x4e, 0x45, 0x86, 0x5c,
+	0x13, 0xb1, 0x0d, 0x80, 0xf2, 0x5c, 0x53, 0x47, 0x46, 0xfd, 0xe4, 0x33, 0x28, 0xd8, 0x37, 0x00,
+	0x42, 0x0b, 0xd3, 0xfb, 0xea, 0xde, 0x6b, 0xdd, 0x83, 0x31, 0x00, 0x83, 0xdd, 0xf9, 0x6f, 0x0b,
+	0x06, 0x45, 0x0b, 0x7b, 0x1b, 0x9d, 0x97, 0x97, 0xe6, 0x87, 0x79, 0xa0, 0x9c, 0x6e, 0x9b, 0x0f,
+	0x08, 0x73, 0x10, 0x4c, 0x29, 0x9e, 0xcb, 0xf2, 0x38, 0x91, 0xad, 0xd2, 0xff, 0xf7, 0x11, 0x41,
+	0x8d, 0x77, 0x60, 0x98, 0x9d, 0x67, 0xb9, 0x98, 0xca, 0x66, 0x9c, 0xba, 0xc5, 0x41, 0xa2, 0x34,
+	0x37, 0xc6, 0xa5, 0xb2, 0xb9, 0x43, 0xcd, 0x14, 0xa8, 0x52, 0x63, 0xb1, 0xe7, 0xd0, 0xd5, 0x8e,
+	0xd4, 0x9e, 0x43, 0x99, 0xd2, 0x3e, 0x0f, 0x4f, 0xbc, 0xec, 0x84, 0x4c, 0x76, 0xc4, 0x41, 0xa2,
+	0x30, 0x46, 0x65, 0x5f, 0xc0, 0xa2, 0x30, 0x67, 0x4c, 0xf6, 0x3a, 0xdc, 0xbc, 0x56, 0xd1, 0x38,
+	0x36, 0xf0, 0x2a, 0x9d, 0xf3, 0x6f, 0x16, 0x40, 0xb9, 0xf5, 0x2b, 0x31, 0xb4, 0x75, 0x49, 0x0c,
+	0xdd, 0xaa, 0xc5, 0xd0, 0xef, 0xe8, 0xb5, 0xf0, 0x8e, 0x42, 0x1d, 0x7d, 0x1b, 0x18, 0x76, 0x17,
+	0x96, 0x4b, 0x48, 0x4e, 0x42, 0x9e, 0x36, 0x4b, 0x25, 0x9a, 0x26, 0x52, 0xd5, 0x7c, 0xf7, 0x52,
+	0xcd, 0x2f, 0xd4, 0x34, 0xaf, 0x1d, 0x4a, 0xaf, 0x74, 0x28, 0xee, 0x03, 0x60, 0x68, 0x0e, 0x5f,
+	0x05, 0x59, 0x1e, 0xa7, 0xe7, 0xfa, 0x36, 0x52, 0xee, 0x57, 0xe9, 0x25, 0x57, 0xa1, 0x1b, 0x06,
+	0xd3, 0x20, 0x57, 0x9b, 0x48, 0x02, 0xee, 0xd7, 0x70, 0xbd, 0xc2, 0x9b, 0x25, 0x71, 0x94, 0x09,
+	0xf6, 0x19, 0xf4, 0x33, 0x32, 0x2a, 0xa1, 0xcf, 0xb5, 0x5b, 0x17, 0x58, 0x1d, 0x2f, 0x08, 0xdd,
+	0x7b, 0x70, 0x7d, 0xdb, 0x08, 0x3c, 0xf4, 0x40, 0x56, 0xa1, 0x8b, 0xc3, 0x94, 0x82, 0x06, 0x5c,
+	0x02, 0xee, 0x13, 0x58, 0xad, 0x12, 0xab, 0x9e, 0x37, 0x60, 0x50, 0x0f, 0x0d, 0x57, 0xb0, 0x6b,
+	0x33, 0x54, 0xe0, 0x25, 0x89, 0x7b, 0x08, 0x83, 0x87, 0xb3, 0xf1, 0xa9, 0xc8, 0x83, 0x68, 0xd2,
+	0x18, 0x42, 0xdc, 0x86, 0xc1, 0xa9, 0x38, 0x7f, 0x12, 0x88, 0xb0, 0x74, 0x01, 0x05, 0x02, 0x5d,
+	0xe0, 0x11, 0xb1, 0x6f, 0x1b, 0xd7, 0x0f, 0x13, 0xe5, 0xbe, 0x0f, 0x2b, 0x45, 0x07, 0x7a, 0x4a,
+	0x0d, 0xfd, 0xb8, 0x7f, 0x6c, 0xc1, 0xf5, 0x27, 0x41, 0x58, 0xc6, 0x88, 0x17, 0xd3, 0xb2, 0x15,
+	0x68, 0xfb, 0x41, 0xaa, 0x2c, 0x0c, 0x3f, 0x91, 0x8a, 0x2c, 0xa6, 0x4d, 0xeb, 0x45, 0xdf, 0x73,
+	0x97, 0xb6, 0x4e, 0xc3, 0xa5, 0xcd, 0x86, 0xde, 0x38, 0x8e, 0x72, 0x11, 0xe5, 0x6a, 0x37, 0x69,
+	0xd0, 0xdd, 0x85, 0xd5, 0xea, 0x70, 0x94, 0x82, 0xdf, 0x83, 0x45, 0x2f, 0x44, 0x5f, 0x7c, 0xfe,
+	0xf8, 0x55, 0x90, 0xe5, 0x32, 0x00, 0xec, 0xf3, 0x2a, 0x12, 0xad, 0x27, 0x96, 0x17, 0x8c, 0x3e,
+	0x6f, 0xc5, 0xa7, 0xee, 0xdf, 0x5b, 0xb0, 0x52, 0x77, 0x6b, 0xec, 0x01, 0x9e, 0x48, 0x59, 0x9e,
+	0xce, 0xc6, 0x64, 0x0f, 0x22, 0x57, 0xe1, 0x38, 0xc3, 0x05, 0xdb, 0xa9, 0xb4, 0xf0, 0x1a, 0x65,
+	0x83, 0x0a, 0xcc, 0x60, 0xbd, 0x7d, 0x95, 0x60, 0xbd, 0x21, 0xac, 0xee, 0x34, 0x5f, 0x58, 0x7f,
+	0x69, 0xc1, 0x35, 0x63, 0xf4, 0x4a, 0x13, 0x18, 0x32, 0x92, 0x7b, 0xa1, 0x61, 0x8f, 0xb8, 0x82,
+	0x4a, 0xff, 0xd4, 0x32, 0xfd, 0xd3, 0x3b, 0x60, 0x38, 0xb8, 0x06, 0x97, 0xa7, 0xdc, 0xca, 0x41,
+	0x93, 0xc7, 0x9b, 0x73, 0x5d, 0xdd, 0xab, 0xb9, 0x2e, 0xf7, 0x77, 0x60, 0xb1, 0xd2, 0x3e, 0x67,
+	0x13, 0x56, 0x83, 0x4d, 0x7c, 0x80, 0x31, 0x95, 0x97, 0x57, 0x52, 0x0b, 0xe6, 0x6a, 0x60, 0x3f,
+	0x92, 0xc2, 0xfd, 0x4f, 0x0b, 0x96, 0x6b, 0x4d, 0x17, 0x06, 0x3d, 0x74, 0x07, 0xc1, 0x63, 0x4f,
+	0x1f, 0xf8, 0x12, 0xc2, 0x21, 0x51, 0x04, 0x42, 0xdb, 0x45, 0xdd, 0x3f, 0xdb, 0xbc, 0x82, 0x43,
+	0xa3, 0x93, 0xca, 0xd5, 0x44, 0x1d, 0x22, 0xaa, 0x22, 0x51, 0xc5, 0x89, 0x10, 0xa7, 0xc2, 0xe7,
+	0xf1, 0x99, 0x3c, 0xed, 0x46, 0xdc, 0xc0, 0xa0, 0xcd, 0x84, 0xde, 0x44, 0xf9, 0x44, 0xfc, 0x44,
+	0x13, 0x38, 0x0e, 0xc2, 0x5c, 0xa4, 0xc2, 0xd7, 0x92, 0x7b, 0xd4, 0x5a, 0x47, 0xbb, 0xff, 0x48,
+	0xf9, 0x9a, 0x28, 0x4f, 0xe3, 0xf0, 0x99, 0xc8, 0x32, 0x6f, 0x42, 0x0e, 0x3d, 0xc8, 0xf6, 0xe8,
+	0x9a, 0xb0, 0xb3, 0xa7, 0xb6, 0x81, 0x81, 0x61, 0x9f, 0xc2, 0x10, 0xb7, 0x84, 0xb2, 0x76, 0x75,
+	0xff, 0x58, 0x46, 0x6d, 0xf2, 0x12, 0xcd, 0x4d, 0x1a, 0x76, 0x1f, 0x46, 0x67, 0x69, 0x50, 0xa4,
+	0x84, 0x94, 0x1d, 0x93, 0x03, 0xfb, 0x99, 0x81, 0xe7, 0x15, 0xaa, 0x1f, 0x60, 0xc8, 0x1f, 0xc3,
+	0x9b, 0x8f, 0x44, 0x28, 0x72, 0x51, 0x89, 0xc3, 0x2f, 0xf1, 0x4a, 0x9b, 0xe0, 0x34, 0x31, 0xa8,
+	0x1d, 0x50, 0x58, 0xba, 0x65, 0x44, 0xbf, 0xee, 0x3f, 0x59, 0xb0, 0xb2, 0x35, 0xcb, 0x4f, 0xe2,
+	0x34, 0xf8, 0x5e, 0x5c, 0xea, 0xc5, 0xeb, 0x77, 0xa7, 0xd6, 0xdc, 0xdd, 0x69, 0xce, 0x60, 0xdb,
+	0x0d, 0x06, 0x7b, 0x1f, 0x9a, 0x2f, 0x8b, 0xca, 0x4a, 0x9a, 0x1b, 0xe5, 0xf2, 0x91, 0xbb, 0x0a,
+	0xa2, 0x89, 0xdd, 0xd5, 0xcb, 0xa7, 0x31, 0xee, 0x07, 0x70, 0xcd, 0x98, 0xc5, 0xa5, 0x33, 0xbe,
+	0x0f, 0x4b, 0xdb, 0xa1, 0xf0, 0xa2, 0x59, 0xa2, 0xa7, 0x7b, 0x85, 0x7d, 0xe6, 0xde, 0x85, 0xe5,
+	0x82, 0xeb, 0x52, 0xf1, 0xbf, 0xb4, 0x60, 0x64, 0x2e, 0x3f, 0x5d, 0x4a, 0x4f, 0xbc, 0x28, 0x12,
+	0xe1, 0xb7, 0xe5, 0x82, 0x99, 0x28, 0x9c, 0x1c, 0x99, 0x48, 0xfa, 0x6d, 0x19, 0x8a, 0x18, 0x18,
+	0x94, 0x80, 0x76, 0x27, 0xd2, 0xca, 0xb9, 0x65, 0xa0, 0xea, 0x4b, 0xd3, 0x99, 0x5f, 0x9a, 0xda,
+	0xd5, 0xb8, 0x3b, 0x77, 0x35, 0x76, 0xff, 0xc1, 0x82, 0xa1, 0x61, 0xeb, 0x57, 0x1b, 0xb7, 0x1c,
+	0x84, 0x39, 0xee, 0x12, 0x53, 0x1f, 0x55, 0x7b, 0x7e, 0x54, 0x1b, 0x00, 0x19, 0x19, 0xa9, 0x17,
+	0x4d, 0x84, 0x19, 0x22, 0xef, 0x17, 0x58, 0x6e, 0x50, 0x60, 0x8f, 0x53, 0x2f, 0xc1, 0xd0, 0x20,
+	0x0c, 0xcf, 0xb5, 0x19, 0x94, 0x18, 0xf7, 0x15, 0x40, 0xc9, 0x89, 0x5e, 0x9a, 0x22, 0x2d, 0x1e,
+	0x9f, 0xa9, 0x98, 0xb7, 0x80, 0xe5, 0x05, 0x20, 0x4e, 0xb0, 0x49, 0x06, 0xbc, 0x1a, 0x2c, 0xb8,
+	0xbe, 0x11, 0xe7, 0x34, 0xe4, 0x11, 0x2f, 0x60, 0xcd, 0x85, 0x4d, 0x1d, 0x79, 0x02, 0x2b, 0xd0,
+	0xfd, 0xc3, 0x16, 0x2c, 0x55, 0x4f, 0x41, 0xf6, 0x19, 0xfa, 0xca, 0x02, 0xa3, 0x03, 0x9c, 0xe5,
+	0x9a, 0x87, 0xe6, 0x15, 0xa2, 0xfa, 0x5a, 0xb7, 0xe6, 0xd7, 0xfa, 0x2a, 0x9b, 0x6c, 0x0d, 0x86,
+	0x41, 0xf6, 0x3c, 0x8d, 0x8f, 0x83, 0x10, 0xf7, 0x4b, 0x87, 0x14, 0x65, 0xa2, 0x50, 0x8a, 0x87,
+	0x79, 0xa2, 0x2d, 0xdf, 0x47, 0x03, 0x50, 0x06, 0x51, 0xc1, 0x15, 0x3e, 0x66, 0xc1, 0x88, 0x66,
+	0x34, 0x1f, 0xba, 0x98, 0x47, 0x81, 0xbc, 0x85, 0x0f, 0x78, 0x05, 0xe7, 0xfe, 0xd5, 0x3a, 0x0c,
+	0x8d, 0x19, 0xfe, 0xe0, 0x43, 0x06, 0x57, 0x99, 0x32, 0xbb, 0x3b, 0xd1, 0xb3, 0x87, 0xca, 0xdc,
+	0x0d, 0x0c, 0xfb, 0x1a, 0xae, 0xd3, 0x81, 0x43, 0x4b, 0xbd, 0x5b, 0x04, 0x90, 0x32, 0x9b, 0x61,
+	0xeb, 0x00, 0x32, 0x13, 0x55, 0x02, 0xde, 0xc4, 0xc4, 0x76, 0x61, 0x75, 0x6f, 0x96, 0xcf, 0xe1,
+	0xed, 0xee, 0x6b, 0x84, 0x35, 0x72, 0xb1, 0x0d, 0x4c, 0xcc, 0x86, 0x62, 0x9c, 0x93, 0xce, 0x86,
+	0x9b, 0x37, 0x6b, 0x8b, 0xbd, 0x21, 0x73, 0xce, 0x5c, 0x51, 0xb1, 0x5f, 0xc0, 0x8d, 0xdf, 0x8d,
+	0x83, 0xe8, 0xb9, 0x97, 0xe6, 0x01, 0xb6, 0x0b, 0x7f, 0x3f, 0x4e, 0x31, 0x1d, 0x29, 0xaf, 0x3b,
+	0x3f, 0xae, 0xb3, 0x7f, 0xdd, 0x44, 0xcc, 0x9b, 0x65, 0x30, 0x1f, 0xec, 0x71, 0x4c, 0x77, 0xc4,
+	0x79, 0xf9, 0x32, 0x79, 0xb2, 0x5e, 0x97, 0xbf, 0x7d, 0x01, 0x3d, 0xbf, 0x50, 0x12, 0x7b, 0x00,
+	0x90, 0x04, 0x89, 0xd8, 0xca, 0xb6, 0xd2, 0x49, 0x46, 0x99, 0x95, 0xe1, 0xa6, 0x53, 0x97, 0xfb,
+	0xbc, 0xa0, 0xe0, 0x06, 0x35, 0xdb, 0x83, 0x6b, 0xd9, 0xd8, 0xcb, 0x73, 0x91, 0x16, 0x72, 0x33,
+	0x1b, 0xd6, 0x2c, 0x9d, 0x17, 0xab, 0x68, 0xae, 0x4e, 0xc8, 0xe7, 0x79, 0x51, 0xe0, 0x38, 0x0e,
+	0x51, 0xb5, 0x86, 0xc0, 0x61, 0xb3, 0xc0, 0xed, 0x3a, 0x21, 0x9f, 0xe7, 0x65, 0xbb, 0xb0, 0x22,
+	0xad, 0x26, 0x09, 0x83, 0x9c, 0xd3, 0x2e, 0xb4, 0x47, 0x24, 0x6f, 0xad, 0x2e, 0x6f, 0xa7, 0x46,
+	0xc7, 0xe7, 0x38, 0x51, 0x57, 0x69, 0x3c, 0x8b, 0x7c, 0x1e, 0x1f, 0x05, 0x91, 0xbd, 0xd8, 0xac,
+	0x2b, 0x5e, 0x50, 0x70, 0x83, 0x9a, 0xdd, 0x97, 0xd9, 0xd1, 0xf0, 0x20, 0x4e, 0xec, 0xa5, 0x35,
+	0x4b, 0x1b, 0xa7, 0xc9, 0xb9, 0xab, 0xda, 0x79, 0x41, 0xc9, 0xbe, 0x80, 0xc1, 0x51, 0x1a, 0x7b,
+	0xfe, 0xd8, 0xcb, 0x72, 0x7b, 0x99, 0xd8, 0xde, 0xac, 0xb3, 0x3d, 0xd4, 0x04, 0xbc, 0xa4, 0x65,
+	0xbf, 0x05, 0xab, 0x24, 0x04, 0x5d, 0xca, 0x56, 0xe4, 0xa3, 0xe1, 0xfd, 0x2c, 0xc8, 0x4f, 0xec,
+	0x95, 0x35, 0x4b, 0xa7, 0x0c, 0xe7, 0xba, 0xae, 0xd1, 0xf2, 0x46, 0x09, 0xb4, 0x47, 0x28, 0xe7,
+	0x64, 0x5f, 0xbb, 0x60, 0x8f, 0x50, 0x2b, 0x57, 0x54, 0x38, 0x05, 0x92, 0x83, 0xf6, 0x66, 0xb3,
+	0xe6, 0x29, 0xec, 0x6a, 0x02, 0x5e, 0xd2, 0xb2, 0x6d, 0x58, 0x9c, 0x8a, 0x74, 0x22, 0xa4, 0xa1,
+	0x1e, 0xc4, 0xf6, 0x75, 0x62, 0x7e, 0xbb, 0xce, 0xfc, 0xcc, 0x24, 0xe2, 0x55, 0x1e, 0xf6, 0x29,
+	0xf4, 0x08, 0x71, 0x10, 0xdb, 0xab, 0x6b, 0x96, 0xbe, 0x1b, 0xcf, 0xb1, 0x1f, 0xc4, 0x5c, 0xd3,
+	0x61, 0xbf, 0x34, 0x88, 0x47, 0x14, 0x9b, 0x8c, 0x73, 0xfb, 0x46, 0x73, 0xbf, 0xbb, 0x26, 0x11,
+	0xaf, 0xf2, 0xa0, 0xa9, 0x10, 0x62, 0x97, 0xae, 0xf1, 0x37, 0x9b, 0x4d, 0x65, 0xb7, 0xa0, 0xe0,
+	0x06, 0x35, 0xe3, 0xc0, 0x08, 0xa2, 0x1d, 0xfb, 0xf0, 0x5c, 0x6d, 0xf9, 0x5b, 0x65, 0xbe, 0x74,
+	0x4e, 0x46, 0x85, 0x92, 0x37, 0x70, 0xb3, 0x7b, 0xd0, 0x9d, 0x45, 0x18, 0x39, 0xd8, 0x24, 0xe6,
+	0x46, 0x5d, 0xcc, 0x77, 0xd8, 0xc8, 0x25, 0x0d, 0xfb, 0x08, 0x20, 0x13, 0xe3, 0x54, 0xe4, 0x8f,
+	0xa3, 0x97, 0x99, 0xfd, 0xe6, 0x5a, 0x5b, 0x3f, 0x96, 0xec, 0x6b, 0x2c, 0x37, 0x08, 0xd8, 0x6f,
+	0xc0, 0x90, 0x7a, 0x54, 0x77, 0xd4, 0xb7, 0xa8, 0x87, 0xb7, 0x1a, 0x07, 0x2a, 0x49, 0xb8, 0x49,
+	0x4f, 0x79, 0x3f, 0x21, 0x4e, 0xe5, 0x81, 0x79, 0x5b, 0x26, 0x13, 0x0b, 0x04, 0x2e, 0xe0, 0x38,
+	0x8e, 0x5e, 0x8a, 0x34, 0xb7, 0xdf, 0x6e, 0x5e, 0xc0, 0x6d, 0xd9, 0xcc, 0x35, 0x1d, 0xfb, 0x12,
+	0x46, 0x99, 0xc8, 0xf7, 0x12, 0xf5, 0xfc, 0x67, 0xbf, 0xb3, 0x66, 0xe9, 0x74, 0x75, 0xd5, 0x97,
+	0x97, 0x34, 0xbc, 0xc2, 0xa1, 0x9d, 0xe2, 0x76, 0x1c, 0xce, 0xa6, 0x91, 0x7d, 0xe7, 0x62, 0xa7,
+	0x28, 0x29, 0xb8, 0x41, 0x8d, 0xda, 0xc8, 0xbc, 0x30, 0xff, 0x2a, 0xc6, 0x88, 0x23, 0xb3, 0xd7,
+	0x9a, 0xb5, 0xb1, 0x5f, 0x92, 0x70, 0x93, 0x1e, 0x07, 0x2f, 0xaf, 0x43, 0x48, 0x21, 0x7c, 0xfb,
+	0xdd, 0xe6, 0xc1, 0x3f, 0x31, 0x68, 0x78, 0x85, 0x03, 0x7d, 0x5e, 0x2a, 0x92, 0x30, 0x18, 0x7b,
+	0xb9, 0xd0, 0xa3, 0x70, 0x9b, 0x7d, 0x1e, 0xaf, 0xd1, 0xf1, 0x39, 0x4e, 0xdc, 0xee, 0xb3, 0x08,
+	0x07, 0x68, 0xff, 0xa8, 0x79, 0xbb, 0x7f, 0x47, 0xad, 0x5c, 0x51, 0x21, 0x7d, 0xe6, 0x4d, 0x93,
+	0x50, 0xd8, 0xef, 0x5d, 0xe0, 0x1e, 0xa8, 0x95, 0x2b, 0x2a, 0xa4, 0x97, 0xa3, 0xb7, 0xdf, 0x6f,
+	0xa6, 0x97, 0x33, 0xe5, 0x8a, 0x8a, 0xed, 0xc0, 0xb2, 0xe4, 0xa4, 0x18, 0x91, 0x26, 0x77, 0x77,
+	0xcd, 0xd2, 0x0f, 0x29, 0x0d, 0x1d, 0x69, 0x32, 0x5e, 0xe7, 0x43, 0x51, 0x29, 0x02, 0x0f, 0xd1,
+	0x4b, 0x7b, 0x69, 0x20, 0x32, 0x7b, 0xbd, 0x59, 0x14, 0xaf, 0x92, 0xf1, 0x3a, 0x1f, 0xfa, 0x0c,
+	0x75, 0x9a, 0x11, 0x69, 0x66, 0x7f, 0xd0, 0xec, 0x33, 0xf6, 0x4d, 0x22, 0x5e, 0xe5, 0x41, 0x4f,
+	0x49, 0x0f, 0xeb, 0x74, 0xa3, 0xfe, 0xb0, 0xd9, 0x53, 0x6e, 0x6b, 0x02, 0x5e, 0xd2, 0x92, 0xc1,
+	0x63, 0x20, 0xb3, 0x77, 0x7c, 0x4c, 0x2f, 0x4b, 0xf7, 0x2e, 0x30, 0x78, 0x83, 0x86, 0x57, 0x38,
+	0x50, 0xc2, 0xf7, 0x41, 0x82, 0xfe, 0x7d, 0x27, 0xf2, 0xc5, 0x2b, 0xfb, 0xff, 0x35, 0x4b, 0xf8,
+	0xb9, 0x41, 0xc3, 0x2b, 0x1c, 0x38, 0x78, 0x19, 0x14, 0x1d, 0x78, 0x13, 0xfb, 0xa3, 0xe6, 0xc1,
+	0xef, 0x6b, 0x02, 0x5e, 0xd2, 0xa2, 0xea, 0x68, 0x26, 0xdf, 0xce, 0xc2, 0x90, 0x96, 0x73, 0xa3,
+	0x59, 0x75, 0xdb, 0x26, 0x11, 0xaf, 0xf2, 0x38, 0xbb, 0xb0, 0x20, 0x85, 0x63, 0xf0, 0x79, 0x2a,
+	0xce, 0x69, 0x4c, 0x42, 0x3f, 0x0e, 0x18, 0x18, 0x0c, 0x80, 0x5f, 0x7a, 0xe1, 0x4c, 0x68, 0x0a,
+	0x99, 0x65, 0xac, 0xe0, 0x9c, 0x7f, 0xb5, 0xe0, 0x46, 0x63, 0xa8, 0x86, 0x17, 0x88, 0xa0, 0x22,
+	0x5a, 0x83, 0x98, 0x17, 0x08, 0xb2, 0x5d, 0x71, 0x9c, 0xef, 0xcd, 0x72, 0x91, 0x22, 0xb7, 0xca,
+	0xc8, 0xd5, 0xd1, 0xec, 0x43, 0x58, 0x09, 0x32, 0x1e, 0x4c, 0x4e, 0x0c, 0x52, 0xf9, 0x0e, 0x3a,
+	0x87, 0xc7, 0x07, 0x9a, 0x50, 0x1c, 0xe7, 0x3f, 0xc5, 0xd1, 0x49, 0x07, 0x29, 0x93, 0x0d, 0x35,
+	0x2c, 0xf6, 0x9e, 0x22, 0xa7, 0x41, 0xa8, 0x5e, 0xad, 0x6b, 0x68, 0xe7, 0x3e, 0xd8, 0x17, 0x45,
+	0x89, 0x17, 0xcf, 0xce, 0xd9, 0x04, 0x28, 0x63, 0x40, 0xbc, 0x58, 0x8c, 0xf5, 0x45, 0x7b, 0xc0,
+	0xe9, 0x1b, 0xf3, 0x3d, 0x22, 0x7a, 0x49, 0xea, 0x1c, 0x70, 0xfc, 0x74, 0xb6, 0xe1, 0xda, 0x5c,
+	0xd0, 0x77, 0x89, 0x02, 0x57, 0xa1, 0x7b, 0x74, 0xae, 0xef, 0x73, 0x7d, 0x2e, 0x01, 0xe7, 0x3a,
+	0x5c, 0x9b, 0x0b, 0xf4, 0x9c, 0x4f, 0x60, 0xa5, 0x1e, 0xad, 0xe1, 0x29, 0x42, 0xf1, 0xda, 0xc1,
+	0x79, 0xa2, 0x07, 0x56, 0x22, 0x9c, 0x11, 0x40, 0x19, 0x97, 0x39, 0xbf, 0x90, 0x05, 0x1c, 0x14,
+	0x61, 0x8d, 0xc0, 0x8a, 0xd4, 0xbd, 0xc6, 0x8a, 0xd8, 0x5d, 0xe8, 0xc7, 0xa9, 0x2f, 0xd2, 0x87,
+	0xe7, 0x3a, 0x23, 0x37, 0x44, 0x3b, 0xdc, 0x93, 0x38, 0x5e, 0x34, 0x9a, 0xf3, 0x68, 0x57, 0x55,
+	0x35, 0x84, 0x41, 0x11, 0x91, 0x39, 0x9f, 0xc0, 0x6a, 0x53, 0x68, 0x75, 0x89, 0xa6, 0x43, 0x58,
+	0x90, 0x01, 0x14, 0x5e, 0xaf, 0x82, 0x0c, 0xb5, 0xae, 0xd2, 0x5d, 0x0a, 0x42, 0xed, 0x27, 0x5e,
+	0x7e, 0xa2, 0xdf, 0x29, 0xf1, 0x1b, 0x71, 0x5e, 0x3a, 0x91, 0x63, 0x19, 0x70, 0xfa, 0xd6, 0x2b,
+	0xd2, 0x29, 0x56, 0x04, 0x31, 0x5e, 0x3a, 0x51, 0x77, 0x45, 0xfc, 0x74, 0xee, 0xc3, 0xa0, 0x88,
+	0xbd, 0x2a, 0x93, 0xb7, 0x2e, 0x99, 0xbc, 0xf3, 0xff, 0x61, 0xb1, 0x12, 0x74, 0x5d, 0x9d, 0x73,
+	0x00, 0x3d, 0x15, 0x6f, 0xa1, 0x90, 0x4a, 0x04, 0x75, 0x75, 0x21, 0x9b, 0x00, 0x65, 0xe4, 0x54,
+	0x5b, 0x40, 0xcc, 0x13, 0x93, 0x4f, 0xd3, 0x77, 0x52, 0x09, 0x39, 0x1b, 0xc0, 0xe6, 0x23, 0xa5,
+	0x4b, 0x96, 0xe1, 0x2e, 0x74, 0x29, 0x24, 0x92, 0x99, 0xab, 0xe7, 0x5e, 0xea, 0x85, 0xa1, 0x08,
+	0xcb, 0xc4, 0xa3, 0xc6, 0x38, 0x7f, 0x61, 0xc1, 0xd0, 0x08, 0x6d, 0x2e, 0x31, 0x70, 0x2c, 0x53,
+	0x3a, 0xf1, 0xf2, 0xaa, 0xe3, 0x31, 0x51, 0x72, 0xc5, 0xb7, 0xa2, 0x3c, 0xd0, 0x75, 0x11, 0x12,
+	0xc2, 0x94, 0xc6, 0x59, 0x90, 0x9f, 0x3c, 0xf3, 0xd2, 0x53, 0x95, 0x0b, 0x28, 0x60, 0x99, 0x2a,
+	0x40, 0x3f, 0xb8, 0x75, 0xe6, 0xa5, 0x42, 0xe5, 0x54, 0x4c, 0x94, 0x73, 0x07, 0x7a, 0x2a, 0x44,
+	0xc2, 0x3d, 0x96, 0x9f, 0x27, 0x65, 0x62, 0x90, 0x00, 0xe7, 0x00, 0x46, 0x66, 0x2c, 0x84, 0x5b,
+	0x29, 0xd6, 0x80, 0xde, 0x4a, 0x05, 0x02, 0x5d, 0xd2, 0xa9, 0x10, 0xc9, 0xa3, 0x99, 0x0a, 0x14,
+	0x32, 0xb5, 0x61, 0x6b, 0x58, 0xe7, 0x27, 0xd2, 0x65, 0xa8, 0xa8, 0xa8, 0xc9, 0x65, 0x38, 0xd0,
+	0xf7, 0xd2, 0x89, 0x99, 0x28, 0x29, 0x60, 0xe7, 0xf7, 0x2d, 0x18, 0x1a, 0x31, 0xd2, 0x25, 0x6a,
+	0xbd, 0x0d, 0x03, 0x0c, 0x3c, 0x4c, 0x31, 0x25, 0x82, 0x5e, 0x02, 0xe8, 0xd8, 0xdf, 0xc7, 0x2a,
+	0x2e, 0x95, 0x8b, 0x28, 0x31, 0xf2, 0x11, 0x31, 0xe7, 0x38, 0x35, 0xfd, 0x12, 0xa0, 0x61, 0xe7,
+	0x11, 0x8c, 0xcc, 0x30, 0x0b, 0x69, 0x4f, 0xc5, 0xf9, 0xb6, 0x59, 0x35, 0xa7, 0x61, 0x1c, 0xdf,
+	0x89, 0x8a, 0xb5, 0xa4, 0x3a, 0x34, 0xe8, 0x7c, 0x0d, 0x2b, 0xf5, 0x30, 0xeb, 0x57, 0x9d, 0x8d,
+	0xf3, 0x1e, 0x2c, 0xc8, 0x70, 0xeb, 0xb2, 0xb1, 0x38, 0xbf, 0x67, 0xc1, 0x82, 0x0c, 0x7e, 0x90,
+	0xec, 0x38, 0xf5, 0xc6, 0xc5, 0x4a, 0x5a, 0xbc, 0x80, 0x71, 0x49, 0x32, 0x21, 0xfc, 0xa2, 0xb4,
+	0x4d, 0x08, 0x5f, 0x3a, 0x61, 0x9d, 0x39, 0x23, 0x27, 0x8c, 0x69, 0x33, 0x06, 0x9d, 0x53, 0x9c,
+	0x99, 0x74, 0x25, 0xf4, 0x8d, 0x03, 0xd5, 0x92, 0x64, 0xb6, 0xc5, 0xe2, 0x25, 0xc2, 0xf1, 0x61,
+	0x41, 0xaa, 0x0e, 0xed, 0x33, 0x49, 0x85, 0x4f, 0xd3, 0x57, 0x19, 0xa4, 0x01, 0x37, 0x51, 0xbf,
+	0xba, 0x3f, 0x73, 0xbe, 0x85, 0xe5, 0x5a, 0x90, 0x77, 0x65, 0x27, 0x52, 0x29, 0xec, 0xeb, 0xca,
+	0xc2, 0x3e, 0xe7, 0x08, 0x96, 0x6b, 0x91, 0xde, 0xd5, 0xe5, 0xbd, 0x0f, 0x4b, 0x89, 0x3e, 0xa1,
+	0xcc, 0xd5, 0xab, 0x61, 0xd1, 0xed, 0x55, 0x82, 0xc0, 0xab, 0xbb, 0xbd, 0x21, 0x0c, 0x8a, 0xe8,
+	0xcf, 0x59, 0x82, 0x91, 0x19, 0xce, 0x39, 0x1f, 0xc2, 0xc8, 0x0c, 0xce, 0xe8, 0x85, 0x2b, 0x0a,
+	0x5e, 0xcc, 0xb4, 0xce, 0xfb, 0xbc, 0x80, 0x9d, 0xb7, 0x61, 0x50, 0x44, 0x62, 0xa8, 0xd5, 0xdc,
+	0x9b, 0x28, 0x1b, 0xc2, 0x4f, 0xe7, 0x03, 0x58, 0xac, 0xc4, 0x5a, 0x17, 0x5b, 0xab, 0xfb, 0x18,
+	0x25, 0xa9, 0x7b, 0x20, 0x92, 0x89, 0xe8, 0xa5, 0x91, 0x6c, 0xd6, 0x20, 0x6d, 0x42, 0x22, 0x33,
+	0x13, 0xcd, 0x25, 0xc6, 0xfd, 0x1c, 0x7a, 0x6a, 0xba, 0x68, 0x80, 0x24, 0x5c, 0x0d, 0x48, 0x02,
+	0x88, 0x25, 0x35, 0xe8, 0xf7, 0x70, 0x02, 0xdc, 0x3f, 0xed, 0x43, 0x6f, 0xff, 0x45, 0xf8, 0x3c,
+	0xf4, 0xc8, 0x98, 0xf3, 0xf2, 0xe4, 0xa7, 0x6f, 0xa3, 0x0e, 0x65, 0x40, 0xaf, 0xea, 0x3f, 0xc6,
+	0xcc, 0xc5, 0x89, 0x98, 0x7a, 0x76, 0xdb, 0xb8, 0xd2, 0xbe, 0x08, 0xd5, 0x25, 0x4e, 0x35, 0xe2,
+	0x82, 0x8c, 0x4f, 0x82, 0xd0, 0x4f, 0x29, 0x13, 0x5f, 0x2c, 0x88, 0xea, 0x89, 0x17, 0x8d, 0xec,
+	0x1e, 0x00, 0x3e, 0x6e, 0x04, 0x66, 0xc6, 0x51, 0x93, 0x3e, 0x7e, 0x95, 0xa4, 0xdc, 0x68, 0x66,
+	0xef, 0x42, 0x57, 0xbc, 0x4a, 0x52, 0x5d, 0x3c, 0x55, 0xa1, 0x93, 0x2d, 0xec, 0x43, 0xe8, 0x7b,
+	0x93, 0xc9, 0x93, 0x59, 0x34, 0x96, 0xa5, 0x83, 0x3a, 0x97, 0xfe, 0x22, 0xdc, 0x92, 0x68, 0x5e,
+	0xb4, 0xb3, 0xbb, 0xd0, 0x3b, 0x3a, 0xdf, 0xc9, 0xc5, 0x54, 0xd6, 0x11, 0x96, 0x93, 0x79, 0x48,
+	0x58, 0xae, 0x5b, 0xf1, 0x4c, 0xf1, 0x8f, 0x48, 0xef, 0xb2, 0x6a, 0x4a, 0x41, 0xb8, 0x7f, 0xa9,
+	0xca, 0x81, 0x9a, 0x40, 0x3a, 0xf9, 0x02, 0x81, 0xe6, 0x83, 0x49, 0x49, 0x0a, 0xa6, 0x86, 0xd2,
+	0xbd, 0x68, 0x98, 0x7d, 0x0e, 0xcb, 0xe2, 0xc5, 0xcc, 0x0b, 0xb7, 0xcb, 0xb9, 0x8f, 0xe6, 0xe7,
+	0x54, 0xa7, 0x61, 0x9f, 0xc9, 0x50, 0xd6, 0xe0, 0x5a, 0x9c, 0xe7, 0xaa, 0x91, 0x60, 0x5f, 0x14,
+	0xc0, 0x1a, 0x5c, 0x4b, 0x0d, 0x7d, 0xd5, 0x68, 0x8c, 0x28, 0x00, 0x73, 0x66, 0x1d, 0x1d, 0x05,
+	0xa0, 0x1d, 0xc9, 0xd2, 0xe5, 0x15, 0x42, 0x4b, 0x80, 0x9c, 0x0d, 0x1e, 0xba, 0xd7, 0x68, 0x9f,
+	0xd0, 0x37, 0x1a, 0x33, 0x1e, 0xb1, 0x5b, 0xb3, 0x57, 0x94, 0xb3, 0xea, 0x73, 0x0d, 0xca, 0xf7,
+	0x85, 0xd4, 0xcb, 0xc5, 0xe4, 0x9c, 0x32, 0x52, 0x5d, 0x5e, 0xc0, 0x64, 0xe8, 0x53, 0x2f, 0x0c,
+	0x0f, 0x50, 0x91, 0xf6, 0xaa, 0x3a, 0x6d, 0x0a, 0x8c, 0x7c, 0xc5, 0x89, 0xc6, 0xb3, 0x34, 0x15,
+	0xd1, 0xf8, 0x9c, 0x12, 0x4b, 0x5d, 0x6e, 0xa2, 0xf0, 0xf1, 0xd5, 0x17, 0xc7, 0xde, 0x2c, 0x94,
+	0x31, 0x7b, 0x46, 0xa9, 0xa3, 0x11, 0xaf, 0x22, 0x71, 0x74, 0xde, 0x64, 0x42, 0xab, 0x73, 0x8b,
+	0x64, 0x68, 0x10, 0x67, 0x7e, 0xe2, 0x65, 0x4f, 0x8f, 0xce, 0x29, 0xd1, 0xd3, 0xe7, 0x0a, 0x62,
+	0x1f, 0xc0, 0xc0, 0x4b, 0x92, 0xf0, 0x9c, 0x6e, 0x1b, 0x6f, 0xae, 0x59, 0x86, 0x0a, 0xc9, 0xaa,
+	0xcb, 0x56, 0xf6, 0x31, 0x15, 0xf7, 0x88, 0x74, 0x5f, 0xee, 0x15, 0xa7, 0x69, 0xaf, 0x98, 0x14,
+	0x68, 0x4a, 0x53, 0xef, 0xd5, 0x5e, 0x24, 0x30, 0x7a, 0x7f, 0x8b, 0xba, 0x2d, 0x11, 0x38, 0x67,
+	0xb2, 0xab, 0xad, 0x8c, 0x4c, 0xed, 0xb6, 0x3c, 0x00, 0x0c, 0x14, 0xea, 0x1f, 0xeb, 0xd8, 0x28,
+	0xbf, 0xd3, 0xe7, 0xf4, 0x2d, 0x2b, 0x41, 0x44, 0x42, 0x6e, 0x81, 0x12, 0x38, 0x7d, 0x5e, 0x22,
+	0xe8, 0xd4, 0xf6, 0x32, 0x99, 0x5b, 0xbb, 0x23, 0xbd, 0x9b, 0x86, 0xdd, 0x7f, 0xb6, 0xa0, 0xa7,
+	0x0c, 0x83, 0x0e, 0xae, 0x20, 0xd2, 0xef, 0x16, 0xf4, 0x8d, 0x45, 0x2b, 0xc7, 0x58, 0x4f, 0x42,
+	0xda, 0x6b, 0x95, 0x6f, 0xbe, 0xfb, 0x2f, 0xc2, 0x27, 0x1a, 0xcf, 0x4b, 0x12, 0xb4, 0x19, 0xba,
+	0x1c, 0xaa, 0xc7, 0x24, 0x09, 0xa0, 0x2f, 0x19, 0xcb, 0xec, 0x90, 0x51, 0x4b, 0x6c, 0xf8, 0x12,
+	0xd9, 0x48, 0xe7, 0xef, 0x2c, 0x1a, 0xd3, 0xcc, 0x65, 0xd8, 0x5d, 0xc0, 0xec, 0x8e, 0x3a, 0xe3,
+	0x1a, 0x1c, 0x02, 0x35, 0xb8, 0xff, 0x65, 0xc1, 0xa0, 0x10, 0x89, 0x2b, 0x7b, 0x9c, 0xc6, 0xd3,
+	0x9d, 0x47, 0xca, 0xc7, 0x29, 0x08, 0xbb, 0x48, 0xe2, 0x2c, 0x28, 0xca, 0x6e, 0xbb, 0xbc, 0x80,
+	0x8d, 0xcd, 0xdf, 0xae, 0x6c, 0x7e, 0x2c, 0x92, 0x3b, 0x92, 0xef, 0x82, 0xf2, 0xad, 0x51, 0x83,
+	0x8c, 0x6a, 0x54, 0x42, 0x63, 0xbc, 0x1a, 0x2c, 0x3d, 0xf3, 0x82, 0xe9, 0x99, 0x2b, 0xda, 0xec,
+	0xbd, 0x5e, 0x9b, 0x14, 0xae, 0x6e, 0x4d, 0x26, 0x7b, 0xe9, 0xfe, 0xec, 0xe8, 0x85, 0xdd, 0xd7,
+	0xe1, 0x6a, 0x81, 0x72, 0xff, 0xc6, 0x82, 0x91, 0xc9, 0x8d, 0x6e, 0x3c, 0x4f, 0x74, 0x31, 0x63,
+	0x9e, 0xe0, 0xa2, 0x1e, 0x63, 0x69, 0x41, 0x4b, 0x96, 0xdf, 0xe0, 0xb7, 0xc4, 0xa9, 0x37, 0xca,
+	0x2e, 0xa7, 0x6f, 0x9c, 0x8a, 0x2f, 0xc6, 0xc1, 0xd4, 0xd3, 0x3f, 0x23, 0x68, 0x90, 0x26, 0x79,
+	0xe2, 0xa5, 0xe8, 0x1f, 0xf4, 0x24, 0x25, 0xa8, 0xa6, 0x1f, 0x7a, 0xb9, 0x7e, 0x35, 0xd3, 0x20,
+	0x4e, 0x5f, 0x84, 0x62, 0x2a, 0x3d, 0xf3, 0x80, 0x4b, 0xc0, 0x7d, 0x01, 0x50, 0xba, 0xe7, 0xc6,
+	0xf2, 0x21, 0xbd, 0xca, 0xad, 0x0b, 0x56, 0x19, 0xd7, 0xcf, 0xd7, 0x99, 0x66, 0x19, 0x75, 0x15,
+	0x30, 0x0a, 0x9c, 0xea, 0x6a, 0xa2, 0x2e, 0xa7, 0x6f, 0xf7, 0x4b, 0x18, 0x14, 0x6e, 0x1e, 0xa5,
+	0xe3, 0xd9, 0xa1, 0x6a, 0x79, 0xaa, 0xd2, 0x85, 0xda, 0x01, 0xb4, 0xb7, 0x5a, 0xe5, 0xde, 0x72,
+	0xff, 0xcc, 0xaa, 0x95, 0x73, 0x3a, 0xd0, 0xc7, 0x6a, 0x31, 0xe3, 0xe8, 0x2e, 0x60, 0xdc, 0x88,
+	0x65, 0x6d, 0xaa, 0x0a, 0x48, 0x0b, 0x04, 0x46, 0x3d, 0xa6, 0xa4, 0x1d, 0x5f, 0xad, 0x40, 0x0d,
+	0x8b, 0x59, 0x97, 0x27, 0x0d, 0xe5, 0x51, 0x26, 0xce, 0xfd, 0x77, 0x0b, 0x56, 0x9b, 0xde, 0xea,
+	0x70, 0x0e, 0xc6, 0xd0, 0x3a, 0xda, 0x67, 0x7c, 0x15, 0xab, 0x42, 0x8f, 0x01, 0xa7, 0x6f, 0xc4,
+	0x3d, 0x8f, 0x53, 0xfd, 0xc0, 0x4e, 0xdf, 0x46, 0xa9, 0x79, 0xa7, 0x5e, 0x6a, 0x7e, 0x79, 0x21,
+	0x79, 0xed, 0x6d, 0x7b, 0xe1, 0xb5, 0x6f, 0xdb, 0xb5, 0x17, 0xfa, 0xde, 0xfc, 0x0b, 0xfd, 0x3b,
+	0xd0, 0xe7, 0xf1, 0xd9, 0x43, 0x2f, 0x1f, 0x53, 0x80, 0x9b, 0xc6, 0x67, 0x32, 0xa0, 0x1a, 0x71,
+	0xfa, 0x76, 0x13, 0x58, 0x42, 0x85, 0x3c, 0x12, 0xc7, 0x41, 0x14, 0x5c, 0x52, 0x66, 0xaf, 0xaa,
+	0xb0, 0xa5, 0x45, 0x51, 0xfd, 0x16, 0x96, 0xd7, 0x96, 0x6c, 0xba, 0xf6, 0xfa, 0xb5, 0xef, 0xf7,
+	0xee, 0x5f, 0xb6, 0x60, 0xa9, 0xca, 0x6b, 0x94, 0x22, 0x0e, 0x74, 0xe9, 0x30, 0xa5, 0x51, 0x32,
+	0x95, 0xda, 0x51, 0x10, 0xd2, 0xc5, 0x89, 0x92, 0xd9, 0x8a, 0x93, 0x62, 0xa8, 0x1d, 0x63, 0xa8,
+	0xca, 0xfb, 0xe5, 0x65, 0xc5, 0x42, 0x01, 0xeb, 0x5c, 0xc4, 0x42, 0x91, 0x8b, 0x90, 0x7b, 0x6f,
+	0x3a, 0xf5, 0x22, 0x5f, 0x29, 0x4f, 0x83, 0xe4, 0xfa, 0x64, 0x4d, 0x60, 0x9f, 0xe2, 0x4f, 0x05,
+	0x21, 0x3e, 0x93, 0x95, 0xf0, 0x03, 0xf5, 0x30, 0x4d, 0x50, 0x71, 0xa3, 0x00, 0xe3, 0x46, 0x81,
+	0x32, 0xe2, 0x74, 0xea, 0xe5, 0xf6, 0x50, 0xb9, 0x4f, 0x82, 0x64, 0xfa, 0x60, 0xa4, 0xd3, 0x07,
+	0x54, 0x78, 0x19, 0x09, 0x19, 0x9b, 0x0c, 0xb8, 0x04, 0xdc, 0x9f, 0xc3, 0xcd, 0xea, 0xc2, 0x98,
+	0x65, 0x69, 0xc6, 0xd3, 0xf8, 0xa0, 0x78, 0x1a, 0xd7, 0xcb, 0x2b, 0x75, 0x46, 0xdf, 0x65, 0xbd,
+	0x49, 0xdb, 0xa8, 0x37, 0xd9, 0xfc, 0xdb, 0x36, 0x0c, 0x9f, 0xe2, 0xff, 0x6a, 0xcf, 0xbc, 0x2c,
+	0xa7, 0x37, 0xc6, 0xd1, 0x53, 0x91, 0x97, 0x7f, 0x91, 0xb1, 0x4a, 0x5d, 0x1d, 0x95, 0x76, 0x38,
+	0xab, 0xb5, 0x2a, 0x64, 0xfa, 0x55, 0xc7, 0x7d, 0x83, 0x7d, 0x04, 0x8b, 0xfb, 0x22, 0xf2, 0xcb,
+	0xbf, 0x6f, 0xe8, 0x54, 0x2a, 0x40, 0x67, 0x80, 0xa0, 0xfc, 0xa3, 0xe3, 0x8d, 0x75, 0x8b, 0x6d,
+	0xc1, 0x2d, 0x24, 0x6f, 0xfa, 0x5b, 0xe2, 0xa2, 0x0a, 0xd2, 0xba, 0x88, 0x6d, 0x58, 0x7a, 0x2a,
+	0x72, 0xa3, 0x2a, 0x95, 0xdd, 0xd4, 0x9c, 0xd5, 0x12, 0x57, 0xe7, 0xd6, 0x1c, 0x5e, 0xaa, 0xd0,
+	0x7d, 0x83, 0x3d, 0x81, 0xe5, 0xa7, 0x22, 0x37, 0x2b, 0x4c, 0x65, 0xff, 0x0d, 0x05, 0xaa, 0x8e,
+	0x3d, 0xdf, 0x50, 0xc8, 0xb9, 0x07, 0x8b, 0xfb, 0xde, 0x4b, 0x51, 0x96, 0x98, 0xd2, 0xf4, 0x0b,
+	0xb0, 0x32, 0x76, 0xf6, 0x39, 0xe9, 0xb9, 0xa4, 0x5d, 0xad, 0xd0, 0xea, 0xee, 0xaa, 0x12, 0xdc,
+	0x37, 0x36, 0xf7, 0x60, 0x91, 0x56, 0x4b, 0xea, 0x25, 0x4e, 0xd9, 0x6f, 0x82, 0xa3, 0x12, 0x94,
+	0x15, 0x55, 0xa1, 0x07, 0x1f, 0x67, 0x6c, 0xbe, 0x6a, 0xb0, 0xa6, 0xc1, 0xcd, 0x3f, 0x69, 0x03,
+	0x90, 0x44, 0xfa, 0xed, 0x87, 0x7d, 0x03, 0x2b, 0xb4, 0x26, 0x46, 0x35, 0xa8, 0x5a, 0x8c, 0xf9,
+	0x72, 0x55, 0xc7, 0x9e, 0x6f, 0xd0, 0xca, 0x58, 0xb7, 0x3e, 0xb1, 0xd8, 0x03, 0xe8, 0xc9, 0xbe,
+	0x05, 0x6b, 0xac, 0x75, 0x77, 0x6e, 0xd4, 0xb0, 0x9a, 0xfb, 0x13, 0xeb, 0xff, 0x3a, 0x2f, 0xb6,
+	0x03, 0x0b, 0xb2, 0x98, 0x8d, 0x51, 0x26, 0xff, 0xc2, 0x4a, 0x38, 0xe7, 0x9d, 0x8b, 0x9a, 0x8b,
+	0x75, 0x7d, 0x00, 0x83, 0xa2, 0x38, 0x4c, 0x4e, 0xa4, 0x5e, 0xf1, 0xe6, 0xdc, 0xa8, 0x61, 0x0b,
+	0xde, 0xfb, 0xd0, 0x53, 0x75, 0x5f, 0x6a, 0x27, 0x55, 0x4a, 0xc7, 0x9c, 0xeb, 0x15, 0x9c, 0xe6,
+	0xda, 0xfc, 0x1c, 0x96, 0x68, 0x4d, 0x78, 0x7c, 0xb6, 0x9f, 0xa7, 0xc2, 0x9b, 0xb2, 0x1f, 0x41,
+	0xe7, 0xf9, 0x2c, 0x3b, 0x61, 0xf4, 0x4b, 0x93, 0xf6, 0xe2, 0xf5, 0xb5, 0x7c, 0x0e, 0xd7, 0x89,
+	0xad, 0xe6, 0xc5, 0x7f, 0x0d, 0xda, 0x7c, 0x16, 0xc9, 0xfe, 0xab, 0x4d, 0x8e, 0x33, 0x8f, 0x33,
+	0x57, 0xe1, 0x68, 0x81, 0x8a, 0x0a, 0x3f, 0xfb, 0xdf, 0x01, 0x00, 0x6a, 0x00, 0xde, 0xdc, 0xd3,
+	0x3a, 0x00, 0x00,
 }
@@ -22,10 +22,12 @@ func (m *InstructionSet) InstructionNames() (stepNames []string) {
 func (i *Instruction) SetInputLocations(locations []DataLocation) {
 	for _, loc := range locations {
 		i.InputShardLocations = append(i.InputShardLocations, &DatasetShardLocation{
-			Name:   loc.Name,
-			Host:   loc.Location.Server,
-			Port:   int32(loc.Location.Port),
-			OnDisk: loc.OnDisk,
+			Name:        loc.Name,
+			Host:        loc.Location.Server,
+			Port:        int32(loc.Location.Port),
+			OnDisk:      loc.OnDisk,
+			Compression: loc.Compression,
+			AccessToken: loc.AccessToken,
 		})
 	}
 }
@@ -33,10 +35,12 @@ func (i *Instruction) SetInputLocations(locations []DataLocation) {
 func (i *Instruction) SetOutputLocations(locations []DataLocation) {
 	for _, loc := range locations {
 		i.OutputShardLocations = append(i.OutputShardLocations, &DatasetShardLocation{
-			Name:   loc.Name,
-			Host:   loc.Location.Server,
-			Port:   int32(loc.Location.Port),
-			OnDisk: loc.OnDisk,
+			Name:        loc.Name,
+			Host:        loc.Location.Server,
+			Port:        int32(loc.Location.Port),
+			OnDisk:      loc.OnDisk,
+			Compression: loc.Compression,
+			AccessToken: loc.AccessToken,
 		})
 	}
 }
@@ -13,23 +13,23 @@ func TestEncodingDecoding(t *testing.T) {
 		ReaderCount:  23,
 		Instructions: []*Instruction{
 			{
-				Name: "map1",
-				Script: &Script{
+				StepId: 1,
+				Script: &Instruction_Script{
 					IsPipe: true,
 					Path:   "cat",
 					Args:   []string{"/etc/passwd"},
 				},
 			},
 			{
-				Name: "map2",
-				Script: &Script{
+				StepId: 2,
+				Script: &Instruction_Script{
 					IsPipe: true,
 					Path:   "sort",
 				},
 			},
 			{
-				Name: "map4",
-				Script: &Script{
+				StepId: 4,
+				Script: &Instruction_Script{
 					IsPipe: true,
 					Path:   "cat",
 				},
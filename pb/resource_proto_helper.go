@@ -2,6 +2,7 @@ package pb
 
 import (
 	"fmt"
+	"strings"
 )
 
 func (l *Location) URL() string {
@@ -55,3 +56,56 @@ func (a ComputeResource) IsZero() bool {
 func (a ComputeResource) Covers(b ComputeResource) bool {
 	return a.CpuCount >= b.CpuCount && a.MemoryMb >= b.MemoryMb
 }
+
+// Schedulable tells whether the requested resource can be allocated on an
+// agent with the labels and taints. The agent needs all labels of the node
+// selector, and the request needs to tolerate all taints of the agent.
+func (a *ComputeResource) Schedulable(labels, taints []string) bool {
+	for _, selector := range a.GetNodeSelector() {
+		if !contains(labels, selector) {
+			return false
+		}
+	}
+	for _, taint := range taints {
+		key := strings.SplitN(taint, "=", 2)[0]
+		if !contains(a.GetTolerations(), taint) && !contains(a.GetTolerations(), key) {
+			return false
+		}
+	}
+	return true
+}
+
+// SameConstraints tells whether both resources select the same agents.
+func (a *ComputeResource) SameConstraints(b *ComputeResource) bool {
+	return sameStrings(a.GetNodeSelector(), b.GetNodeSelector()) &&
+		sameStrings(a.GetTolerations(), b.GetTolerations())
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sameStrings(a, b []string) bool {
+	for _, x := range a {
+		if !contains(b, x) {
+			return false
+		}
+	}
+	for _, x := range b {
+		if !contains(a, x) {
+			return false
+		}
+	}
+	return true
+}
+
+// Busyness is a relative value of how loaded an agent is. One running task
+// weighs about the same as 100MB/s of network traffic.
+func (l *AgentLoad) Busyness() float64 {
+	return float64(l.GetRunningTasks()) + float64(l.GetNetworkBytesPerSecond())/(100*1024*1024)
+}
@@ -0,0 +1,27 @@
+package pb
+
+import (
+	"testing"
+)
+
+func TestSchedulable(t *testing.T) {
+	labels, taints := []string{"ssd=true", "zone=a"}, []string{"gpu=true"}
+	for _, c := range []struct {
+		request *ComputeResource
+		ok      bool
+	}{
+		{&ComputeResource{}, false},
+		{&ComputeResource{Tolerations: []string{"gpu"}}, true},
+		{&ComputeResource{Tolerations: []string{"gpu=true"}}, true},
+		{&ComputeResource{Tolerations: []string{"gpu=false"}}, false},
+		{&ComputeResource{NodeSelector: []string{"ssd=true"}, Tolerations: []string{"gpu"}}, true},
+		{&ComputeResource{NodeSelector: []string{"zone=b"}, Tolerations: []string{"gpu"}}, false},
+	} {
+		if ok := c.request.Schedulable(labels, taints); ok != c.ok {
+			t.Errorf("%v on %v %v: %v, expecting %v", c.request, labels, taints, ok, c.ok)
+		}
+	}
+	if !(&ComputeResource{}).Schedulable(nil, nil) {
+		t.Errorf("requests without constraints should run on agents without labels")
+	}
+}
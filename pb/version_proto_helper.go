@@ -0,0 +1,45 @@
+package pb
+
+import (
+	"fmt"
+)
+
+// ProtocolVersion is sent by drivers, agents and executors when they talk to
+// each other. Bump it when the messages change in an incompatible way.
+const ProtocolVersion = 2
+
+// StreamEndProtocolVersion is the first protocol version whose writers end
+// the data streams to the agents with the EOF or the abort message.
+const StreamEndProtocolVersion = 2
+
+// MinCompatibleProtocolVersion is the oldest protocol version still understood.
+const MinCompatibleProtocolVersion = 1
+
+// MinCompatibleReaderProtocolVersion is the oldest protocol version of the
+// peers reading the shards of the agents. The agents end the incomplete shards
+// with the abort message, which older readers would take for the length of a
+// message.
+const MinCompatibleReaderProtocolVersion = StreamEndProtocolVersion
+
+// CheckProtocolVersion verifies a peer's protocol version.
+// Peers built before versioning send 0, and are accepted on a best effort basis.
+func CheckProtocolVersion(peerVersion int32) error {
+	if peerVersion == 0 {
+		return nil
+	}
+	if peerVersion < MinCompatibleProtocolVersion || peerVersion > ProtocolVersion {
+		return fmt.Errorf("protocol version %d is not compatible, expecting %d to %d",
+			peerVersion, MinCompatibleProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// CheckReaderProtocolVersion verifies the protocol version of a peer reading
+// shards. Unlike CheckProtocolVersion, it rejects the peers sending 0.
+func CheckReaderProtocolVersion(peerVersion int32) error {
+	if peerVersion < MinCompatibleReaderProtocolVersion {
+		return fmt.Errorf("protocol version %d can not read the shards, expecting %d to %d",
+			peerVersion, MinCompatibleReaderProtocolVersion, ProtocolVersion)
+	}
+	return CheckProtocolVersion(peerVersion)
+}
@@ -0,0 +1,28 @@
+package pb
+
+import (
+	"testing"
+)
+
+func TestCheckProtocolVersion(t *testing.T) {
+	for _, c := range []struct {
+		version  int32
+		ok       bool
+		readerOk bool
+	}{
+		// sent by the peers built before versioning
+		{0, true, false},
+		{MinCompatibleProtocolVersion, true, MinCompatibleProtocolVersion >= MinCompatibleReaderProtocolVersion},
+		{StreamEndProtocolVersion, true, true},
+		{ProtocolVersion, true, true},
+		{ProtocolVersion + 1, false, false},
+		{-1, false, false},
+	} {
+		if err := CheckProtocolVersion(c.version); (err == nil) != c.ok {
+			t.Errorf("version %d: %v, expecting ok %v", c.version, err, c.ok)
+		}
+		if err := CheckReaderProtocolVersion(c.version); (err == nil) != c.readerOk {
+			t.Errorf("reader version %d: %v, expecting ok %v", c.version, err, c.readerOk)
+		}
+	}
+}
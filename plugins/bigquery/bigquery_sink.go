@@ -0,0 +1,92 @@
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/file"
+	_ "github.com/lovelly/gleam/plugins/file/parquet" // the staging files
+)
+
+// BigQuerySink writes the rows as parquet files to the staging folder, and
+// loads them into the table in one load job after all tasks succeeded.
+type BigQuerySink struct {
+	ProjectId     string
+	DatasetId     string
+	TableId       string
+	StagingFolder string
+	FieldNames    []string
+	FieldTypes    []string
+	Truncate      bool
+}
+
+// Sink writes the fields of the rows to the columns of the same names. The
+// staging folder, e.g. "gs://bucket/staging/run1", should be empty.
+func Sink(projectId, datasetId, tableId, stagingFolder string, fieldNames ...string) *BigQuerySink {
+	return &BigQuerySink{
+		ProjectId:     projectId,
+		DatasetId:     datasetId,
+		TableId:       tableId,
+		StagingFolder: strings.TrimSuffix(stagingFolder, "/"),
+		FieldNames:    fieldNames,
+	}
+}
+
+// SetFieldTypes sets the parquet types of the staged columns, e.g. INT64 for
+// INTEGER, DOUBLE for FLOAT, BOOLEAN, TIMESTAMP_MILLIS for TIMESTAMP, or UTF8
+// for STRING, for the fields not always having values of one Go type. The
+// columns without a type have the types of their values.
+func (s *BigQuerySink) SetFieldTypes(fieldTypes ...string) *BigQuerySink {
+	s.FieldTypes = fieldTypes
+	return s
+}
+
+// SetTruncate replaces the rows of the table instead of appending to them.
+func (s *BigQuerySink) SetTruncate(truncate bool) *BigQuerySink {
+	s.Truncate = truncate
+	return s
+}
+
+func (s *BigQuerySink) Save(d *flow.Dataset) *flow.Dataset {
+	return s.fileSink().Save(d)
+}
+
+// fileSink writes the staging files, with the parquet types of the columns.
+func (s *BigQuerySink) fileSink() *file.FileSink {
+	return file.ParquetSink(s.StagingFolder, s.FieldNames...).SetFieldTypes(s.FieldTypes...).OnCommit(s.load)
+}
+
+func (s *BigQuerySink) load(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	client, err := bigquery.NewClient(ctx, s.ProjectId)
+	if err != nil {
+		return fmt.Errorf("Failed to create client of project %s: %v", s.ProjectId, err)
+	}
+	defer client.Close()
+
+	var uris []string
+	for _, f := range files {
+		uris = append(uris, s.StagingFolder+"/"+f)
+	}
+	gcsRef := bigquery.NewGCSReference(uris...)
+	gcsRef.SourceFormat = bigquery.Parquet
+	loader := client.Dataset(s.DatasetId).Table(s.TableId).LoaderFrom(gcsRef)
+	loader.WriteDisposition = bigquery.WriteAppend
+	if s.Truncate {
+		loader.WriteDisposition = bigquery.WriteTruncate
+	}
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to load %s: %v", s.TableId, err)
+	}
+	if err := wait(ctx, job); err != nil {
+		return fmt.Errorf("Failed to load %s: %v", s.TableId, err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package bigquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStagingFileTypes(t *testing.T) {
+	s := Sink("project", "dataset", "table", "gs://bucket/staging/", "id", "name").SetFieldTypes("INT64", "UTF8").fileSink()
+	if s.FileType != "parquet" || s.Folder != "gs://bucket/staging" {
+		t.Errorf("stages %s files in %s", s.FileType, s.Folder)
+	}
+	if !reflect.DeepEqual(s.FieldNames, []string{"id", "name"}) || !reflect.DeepEqual(s.FieldTypes, []string{"INT64", "UTF8"}) {
+		t.Errorf("stages columns %v of types %v", s.FieldNames, s.FieldTypes)
+	}
+}
@@ -0,0 +1,106 @@
+// Package bigquery reads BigQuery tables and queries by exporting them to
+// parquet files in Google Cloud Storage, which are read in parallel by the
+// file plugin, and writes rows by loading the parquet files of a file sink.
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/file"
+)
+
+type BigQuerySource struct {
+	ProjectId      string
+	Sql            string
+	DatasetId      string
+	TableId        string
+	StagingFolder  string
+	PartitionCount int
+}
+
+// Query reads the result of the query, exported to the staging folder,
+// e.g. "gs://bucket/staging/run1", which should be empty.
+func Query(projectId, sql, stagingFolder string) *BigQuerySource {
+	return &BigQuerySource{
+		ProjectId:      projectId,
+		Sql:            sql,
+		StagingFolder:  strings.TrimSuffix(stagingFolder, "/"),
+		PartitionCount: 8,
+	}
+}
+
+// Table reads the table, exported to the staging folder, which should be empty.
+func Table(projectId, datasetId, tableId, stagingFolder string) *BigQuerySource {
+	return &BigQuerySource{
+		ProjectId:      projectId,
+		DatasetId:      datasetId,
+		TableId:        tableId,
+		StagingFolder:  strings.TrimSuffix(stagingFolder, "/"),
+		PartitionCount: 8,
+	}
+}
+
+func (s *BigQuerySource) Partitions(count int) *BigQuerySource {
+	s.PartitionCount = count
+	return s
+}
+
+// Generate exports the table or the query result on the driver,
+// and reads the exported files on the executors. The flow fails if the
+// export fails.
+func (s *BigQuerySource) Generate(f *flow.Flow) *flow.Dataset {
+	if err := s.export(context.Background()); err != nil {
+		return f.FailedSource("bigquery", fmt.Errorf("BigQuerySource failed to export: %v", err))
+	}
+	return file.Parquet(s.StagingFolder+"/part-*.parquet", s.PartitionCount).Generate(f)
+}
+
+func (s *BigQuerySource) export(ctx context.Context) error {
+	client, err := bigquery.NewClient(ctx, s.ProjectId)
+	if err != nil {
+		return fmt.Errorf("Failed to create client of project %s: %v", s.ProjectId, err)
+	}
+	defer client.Close()
+
+	var table *bigquery.Table
+	if s.Sql == "" {
+		table = client.Dataset(s.DatasetId).Table(s.TableId)
+	} else {
+		// the query result is in a temporary table
+		job, err := client.Query(s.Sql).Run(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to query %s: %v", s.Sql, err)
+		}
+		if err := wait(ctx, job); err != nil {
+			return fmt.Errorf("Failed to query %s: %v", s.Sql, err)
+		}
+		config, err := job.Config()
+		if err != nil {
+			return fmt.Errorf("Failed to read the query config: %v", err)
+		}
+		table = config.(*bigquery.QueryConfig).Dst
+	}
+
+	gcsRef := bigquery.NewGCSReference(s.StagingFolder + "/part-*.parquet")
+	gcsRef.DestinationFormat = bigquery.Parquet
+	job, err := table.ExtractorTo(gcsRef).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to export %s: %v", table.FullyQualifiedName(), err)
+	}
+	if err := wait(ctx, job); err != nil {
+		return fmt.Errorf("Failed to export %s: %v", table.FullyQualifiedName(), err)
+	}
+	return nil
+}
+
+func wait(ctx context.Context, job *bigquery.Job) error {
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
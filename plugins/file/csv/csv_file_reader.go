@@ -16,6 +16,11 @@ func New(reader io.Reader) *CsvFileReader {
 	}
 }
 
+// SetDelimiter sets the field delimiter, which is ',' by default.
+func (r *CsvFileReader) SetDelimiter(delimiter rune) {
+	r.csvReader.Comma = delimiter
+}
+
 func (r *CsvFileReader) ReadHeader() (fieldNames []string, err error) {
 	return r.csvReader.Read()
 }
@@ -0,0 +1,47 @@
+package file
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/plugins/file/split"
+)
+
+// Format reads, splits and writes the files of a type not built in, like
+// the orc and parquet files. The formats register themselves in Formats when
+// their packages are imported, so the binaries only link the formats they
+// use, e.g.
+//
+//	import _ "github.com/lovelly/gleam/plugins/file/orc"
+//
+// The csv, tsv, txt and jsonl files are built in.
+type Format struct {
+	// NewReader reads the rows of the shard, a whole file or a split of it.
+	NewReader func(vf filesystem.VirtualFile, shard *FileShardInfo) (FileReader, error)
+	// Split plans the splits of a file larger than the split size, to read
+	// in parallel. Nil reads the files whole.
+	Split func(vf filesystem.VirtualFile, fileName string, splitSize int64) ([]split.Range, error)
+	// NewWriter writes the files of the sinks. Nil has no sink. The field
+	// types are the ones set by FileSink.SetFieldTypes(), "" if not set.
+	NewWriter func(w io.WriteCloser, fieldNames, fieldTypes []string) (RowWriter, error)
+	// IsColumnar files have the column names, which the sources merge, and
+	// the sinks need the field names.
+	IsColumnar bool
+}
+
+// RowWriter writes the rows of a sink to a file.
+type RowWriter interface {
+	Write(values []interface{}) error
+	Close() error
+}
+
+// Formats are the registered file formats, by file type.
+var Formats = make(map[string]Format)
+
+var builtinFileTypes = map[string]bool{"csv": true, "tsv": true, "txt": true, "jsonl": true}
+
+// IsSupported tells whether the file type is built in or registered.
+func IsSupported(fileType string) bool {
+	_, found := Formats[fileType]
+	return found || builtinFileTypes[fileType]
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+)
+
+func TestIsSupported(t *testing.T) {
+	for fileType, expected := range map[string]bool{
+		"csv":     true,
+		"jsonl":   true,
+		"orc":     false, // not imported by the tests
+		"parquet": false,
+		"xml":     false,
+	} {
+		if IsSupported(fileType) != expected {
+			t.Errorf("IsSupported(%s) is %v", fileType, !expected)
+		}
+	}
+
+	Formats["test"] = Format{}
+	defer delete(Formats, "test")
+	if !IsSupported("test") {
+		t.Errorf("registered format is not supported")
+	}
+}
+
+func TestNotRegistered(t *testing.T) {
+	for name, d := range map[string]*flow.Dataset{
+		"source": flow.New("testSourceNotRegistered").Read(Orc("/tmp/*.orc", 1)),
+		"sink":   ParquetSink("/tmp/out", "a").Save(flow.New("testSinkNotRegistered").Slices([][]interface{}{{1}})),
+	} {
+		_, err := d.Collect(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "import its format") {
+			t.Errorf("%s without the format: %v", name, err)
+		}
+	}
+}
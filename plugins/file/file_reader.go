@@ -1,16 +1,14 @@
 package file
 
 import (
-	"compress/bzip2"
-	"compress/gzip"
 	"fmt"
 	"io"
-	"path/filepath"
+	"io/ioutil"
 
 	"github.com/lovelly/gleam/filesystem"
 	"github.com/lovelly/gleam/plugins/file/csv"
-	"github.com/lovelly/gleam/plugins/file/orc"
-	"github.com/lovelly/gleam/plugins/file/parquet"
+	"github.com/lovelly/gleam/plugins/file/jsonl"
+	"github.com/lovelly/gleam/plugins/file/split"
 	"github.com/lovelly/gleam/plugins/file/tsv"
 	"github.com/lovelly/gleam/plugins/file/txt"
 	"github.com/lovelly/gleam/util"
@@ -30,45 +28,87 @@ func Txt(fileOrPattern string, partitionCount int) *FileSource {
 func Tsv(fileOrPattern string, partitionCount int) *FileSource {
 	return newFileSource("tsv", fileOrPattern, partitionCount)
 }
+
+// Jsonl reads files of json objects, one per line, as the fields selected by
+// Select(), or as the lines if no fields are selected.
+func Jsonl(fileOrPattern string, partitionCount int) *FileSource {
+	return newFileSource("jsonl", fileOrPattern, partitionCount)
+}
+
+// Orc reads orc files, with the format registered by importing
+// github.com/lovelly/gleam/plugins/file/orc. Without the import, the flow
+// reading them fails.
 func Orc(fileOrPattern string, partitionCount int) *FileSource {
 	return newFileSource("orc", fileOrPattern, partitionCount)
 }
+
+// Parquet reads parquet files, with the format registered by importing
+// github.com/lovelly/gleam/plugins/file/parquet. Without the import, the
+// flow reading them fails.
 func Parquet(fileOrPattern string, partitionCount int) *FileSource {
 	return newFileSource("parquet", fileOrPattern, partitionCount)
 }
 
 func (ds *FileShardInfo) NewReader(vf filesystem.VirtualFile) (FileReader, error) {
-	// These formats require seeking, so they cannot be
-	// sequentially read by a compress/* reader.
-	if ds.FileType == "orc" {
-		if reader, err := orc.New(vf); err == nil {
-			return reader.Select(ds.Fields), nil
-		} else {
-			return nil, err
+	reader, _, err := ds.newReader(vf)
+	return reader, err
+}
+
+// newReader also returns the closer to stop decompressing the file in the
+// background.
+func (ds *FileShardInfo) newReader(vf filesystem.VirtualFile) (FileReader, io.Closer, error) {
+	// The registered formats, like orc and parquet, require seeking, so
+	// they cannot be sequentially read by a compress/* reader.
+	if format, found := Formats[ds.FileType]; found {
+		reader, err := format.NewReader(vf, ds)
+		if err != nil {
+			return nil, nil, err
 		}
-	} else if ds.FileType == "parquet" {
-		return parquet.New(vf, ds.FileName), nil
+		return reader, ioutil.NopCloser(vf), nil
 	}
 
-	var r io.Reader = vf
+	var r io.ReadCloser
 	var err error
-	switch filepath.Ext(ds.FileName) {
-	case ".gz":
-		r, err = gzip.NewReader(r)
-	case ".bz2":
-		r = bzip2.NewReader(r)
+	if ds.Stop > 0 {
+		r, err = split.NewReader(vf, ds.FileName, ds.Start, ds.Stop)
+	} else {
+		r, err = split.NewDecompressingReader(vf, ds.FileName)
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	switch ds.FileType {
 	case "csv":
-		return csv.New(r), nil
+		reader := csv.New(r)
+		if delimiter := ds.Config["delimiter"]; delimiter != "" {
+			reader.SetDelimiter([]rune(delimiter)[0])
+		}
+		return reader, r, nil
 	case "txt":
-		return txt.New(r), nil
+		return txt.New(r), r, nil
 	case "tsv":
-		return tsv.New(r), nil
+		if options, found := ds.tsvOptions(); found {
+			return tsv.NewWithOptions(r, options), r, nil
+		}
+		return tsv.New(r), r, nil
+	case "jsonl":
+		return jsonl.New(r).Select(ds.Fields), r, nil
+	}
+	r.Close()
+	return nil, nil, fmt.Errorf("File type %s is not defined. Its format may need to be imported, e.g. _ \"github.com/lovelly/gleam/plugins/file/%s\".", ds.FileType, ds.FileType)
+}
+
+// tsvOptions are the options set by SetDelimiter(), SetEnclosure() and SetEscape().
+func (ds *FileShardInfo) tsvOptions() (options tsv.Options, found bool) {
+	if delimiter := ds.Config["delimiter"]; delimiter != "" {
+		options.Delimiter, found = delimiter[0], true
+	}
+	if enclosed := ds.Config["enclosed"]; enclosed != "" {
+		options.Enclosed, found = enclosed[0], true
+	}
+	if escaped := ds.Config["escaped"]; escaped != "" {
+		options.Escaped, found = escaped[0], true
 	}
-	return nil, fmt.Errorf("File type %s is not defined.", ds.FileType)
+	return
 }
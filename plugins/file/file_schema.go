@@ -0,0 +1,95 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/filesystem"
+)
+
+// mergeSchemas returns the fields read from the orc or parquet files, so the
+// rows of all files have the same columns. These are the selected fields, or
+// the columns of all files by name, in the order they first appear.
+// In strict mode, all files must have the same columns, or the selected ones.
+func (s *FileSource) mergeSchemas(fileNames []string) ([]string, error) {
+	if !Formats[s.FileType].IsColumnar {
+		return s.Fields, nil
+	}
+	if !s.StrictSchema && (len(s.Fields) > 0 || len(fileNames) < 2) {
+		return s.Fields, nil
+	}
+
+	var merged []string
+	seen := make(map[string]bool)
+	var firstColumns []string
+	for i, fileName := range fileNames {
+		columns, err := s.readSchema(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			firstColumns = columns
+		}
+		if s.StrictSchema {
+			expected := s.Fields
+			if len(expected) == 0 {
+				expected = firstColumns
+			}
+			if missing := missingFields(columns, expected); len(missing) > 0 {
+				return nil, fmt.Errorf("File %s has no columns %v", fileName, missing)
+			}
+			if len(s.Fields) == 0 && len(columns) != len(firstColumns) {
+				return nil, fmt.Errorf("File %s has columns %v, different from %v of %s", fileName, columns, firstColumns, fileNames[0])
+			}
+		}
+		for _, column := range columns {
+			if !seen[column] {
+				seen[column] = true
+				merged = append(merged, column)
+			}
+		}
+	}
+
+	if len(s.Fields) > 0 {
+		return s.Fields, nil
+	}
+	return merged, nil
+}
+
+func missingFields(columns, fields []string) (missing []string) {
+	has := make(map[string]bool)
+	for _, column := range columns {
+		has[column] = true
+	}
+	for _, field := range fields {
+		if !has[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// readSchema reads the column names of an orc or parquet file.
+func (s *FileSource) readSchema(fileName string) ([]string, error) {
+	vf, err := filesystem.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open file %s: %v", fileName, err)
+	}
+	defer vf.Close()
+
+	ds := &FileShardInfo{
+		Config:   s.Config,
+		FileName: fileName,
+		FileType: s.FileType,
+	}
+	reader, closer, err := ds.newReader(vf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file %s: %v", fileName, err)
+	}
+	defer closer.Close()
+
+	columns, err := reader.ReadHeader()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the schema of file %s: %v", fileName, err)
+	}
+	return columns, nil
+}
@@ -0,0 +1,92 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/util"
+)
+
+// headerReader reads the column names of the test columnar files from their
+// first line.
+type headerReader struct {
+	vf filesystem.VirtualFile
+}
+
+func (r headerReader) Read() (*util.Row, error) {
+	return nil, io.EOF
+}
+
+func (r headerReader) ReadHeader() ([]string, error) {
+	line, err := bufio.NewReader(io.NewSectionReader(r.vf, 0, r.vf.Size())).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(line), ","), nil
+}
+
+func TestMergeSchemas(t *testing.T) {
+	Formats["columnar"] = Format{
+		NewReader: func(vf filesystem.VirtualFile, shard *FileShardInfo) (FileReader, error) {
+			return headerReader{vf: vf}, nil
+		},
+		IsColumnar: true,
+	}
+	defer delete(Formats, "columnar")
+
+	dir, err := ioutil.TempDir("", "gleam_file_schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var fileNames []string
+	for i, columns := range []string{"a,b", "b,c", "a,b"} {
+		fileName := filepath.Join(dir, fmt.Sprintf("%d.columnar", i))
+		if err := ioutil.WriteFile(fileName, []byte(columns+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fileNames = append(fileNames, fileName)
+	}
+
+	for _, test := range []struct {
+		fileNames []string
+		fields    []string
+		strict    bool
+		expected  []string
+		isError   bool
+	}{
+		{fileNames: fileNames, expected: []string{"a", "b", "c"}},
+		{fileNames: fileNames, fields: []string{"c", "a"}, expected: []string{"c", "a"}},
+		{fileNames: fileNames[:1], expected: nil},
+		{fileNames: fileNames, strict: true, isError: true},
+		{fileNames: []string{fileNames[0], fileNames[2]}, strict: true, expected: []string{"a", "b"}},
+		{fileNames: fileNames, fields: []string{"b"}, strict: true, expected: []string{"b"}},
+		{fileNames: fileNames, fields: []string{"a"}, strict: true, isError: true},
+	} {
+		s := newFileSource("columnar", filepath.Join(dir, "*.columnar"), 1)
+		s.Select(test.fields...).SetStrictSchema(test.strict)
+		merged, err := s.mergeSchemas(test.fileNames)
+		if test.isError {
+			if err == nil {
+				t.Errorf("%+v: merged %v, expected an error", test, merged)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", test, err)
+			continue
+		}
+		if !reflect.DeepEqual(merged, test.expected) {
+			t.Errorf("%+v: merged %v", test, merged)
+		}
+	}
+}
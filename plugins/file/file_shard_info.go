@@ -18,6 +18,11 @@ type FileShardInfo struct {
 	FileType  string
 	HasHeader bool
 	Fields    []string
+	// Start and Stop are the byte range of a split of the file, or the
+	// range of the stripes or row groups of orc or parquet files.
+	// Stop is 0 to read the whole file.
+	Start int64
+	Stop  int64
 }
 
 var (
@@ -42,11 +47,12 @@ func (ds *FileShardInfo) ReadSplit() error {
 	}
 	defer fr.Close()
 
-	reader, err := ds.NewReader(fr)
+	reader, closer, err := ds.newReader(fr)
 	if err != nil {
 		return fmt.Errorf("Failed to read file %s: %v", ds.FileName, err)
 	}
-	if ds.HasHeader {
+	defer closer.Close()
+	if ds.HasHeader && ds.Start == 0 {
 		reader.ReadHeader()
 	}
 
@@ -0,0 +1,427 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/csv"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/util"
+)
+
+// hiveDefaultPartition is the directory name of nil partition values.
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// FileSink writes the rows to files in a folder, one file for each task.
+// With PartitionBy(), the rows are written to hive style partition
+// directories instead, e.g. dt=2024-01-01/country=US/part-00001.parquet,
+// without the partition fields.
+// After all files are written, the driver writes the _manifest.json file
+// listing them, and an empty _SUCCESS file to mark the output complete.
+type FileSink struct {
+	Folder          string
+	FileType        string
+	FieldNames      []string
+	FieldTypes      []string
+	PartitionFields []string
+	MaxOpenWriters  int
+	Config          map[string]string
+
+	onCommit []func(files []string) error
+}
+
+// The file sinks are written through flow.Sink.
+func init() {
+	gob.Register(&FileSink{})
+}
+
+func CsvSink(folder string, fieldNames ...string) *FileSink {
+	return newFileSink("csv", folder, fieldNames)
+}
+func TsvSink(folder string, fieldNames ...string) *FileSink {
+	return newFileSink("tsv", folder, fieldNames)
+}
+
+// ParquetSink writes parquet files, with the format registered by importing
+// github.com/lovelly/gleam/plugins/file/parquet. The columns have the parquet
+// types of the Go types of their values, e.g. INT64 for integers, DOUBLE,
+// BOOLEAN, TIMESTAMP_MILLIS for time.Time, and UTF8 for the others. Without
+// the import, the flow writing them fails.
+func ParquetSink(folder string, fieldNames ...string) *FileSink {
+	return newFileSink("parquet", folder, fieldNames)
+}
+
+func newFileSink(fileType, folder string, fieldNames []string) *FileSink {
+	return &FileSink{
+		Folder:         folder,
+		FileType:       fileType,
+		FieldNames:     fieldNames,
+		MaxOpenWriters: 100,
+	}
+}
+
+// PartitionBy sets the fields to partition the files by. The fields are
+// the directory names, and the rows are written to the files in them.
+func (s *FileSink) PartitionBy(fields ...string) *FileSink {
+	s.PartitionFields = fields
+	return s
+}
+
+// SetFieldTypes sets the types of the columns of the fields in the files of
+// typed formats, e.g. the parquet types INT64, DOUBLE, BOOLEAN,
+// TIMESTAMP_MILLIS or UTF8. The columns without a type, "", have the types
+// of their values, which can differ between the files of the tasks.
+func (s *FileSink) SetFieldTypes(fieldTypes ...string) *FileSink {
+	s.FieldTypes = fieldTypes
+	return s
+}
+
+// SetMaxOpenWriters sets the number of files a task keeps open, 100 by
+// default. If a task writes to more partitions, the least recently written
+// file is closed, and a new file is started for later rows of its partition.
+func (s *FileSink) SetMaxOpenWriters(n int) *FileSink {
+	s.MaxOpenWriters = n
+	return s
+}
+
+// OnCommit adds a function to run on the driver after the _SUCCESS file is
+// written, with the written files relative to the folder, e.g. to load them
+// into a database. The flow fails if the function fails.
+func (s *FileSink) OnCommit(f func(files []string) error) *FileSink {
+	s.onCommit = append(s.onCommit, f)
+	return s
+}
+
+// SetDelimiter sets the field delimiter of csv files, which is ',' by default
+func (s *FileSink) SetDelimiter(delimiter rune) *FileSink {
+	if s.Config == nil {
+		s.Config = make(map[string]string)
+	}
+	s.Config["delimiter"] = string(delimiter)
+	return s
+}
+
+// Save writes the rows of the dataset on the executors.
+// The flow fails if the file type has no sink, or the fields do not fit it.
+func (s *FileSink) Save(d *flow.Dataset) *flow.Dataset {
+	if err := s.check(); err != nil {
+		return d.Flow.FailedSource(s.FileType+".Write", err)
+	}
+	ret := d.WriteSink(s.FileType+".Write", s)
+	s.addManifestStep(ret)
+	return ret
+}
+
+func (s *FileSink) check() error {
+	format, found := Formats[s.FileType]
+	if (!found && s.FileType != "csv" && s.FileType != "tsv") || (found && format.NewWriter == nil) {
+		return fmt.Errorf("File type %s has no sink, import its format, e.g. _ \"github.com/lovelly/gleam/plugins/file/%s\"", s.FileType, s.FileType)
+	}
+	if format.IsColumnar && len(s.FieldNames) == 0 {
+		return fmt.Errorf("%s sink %s needs the field names", s.FileType, s.Folder)
+	}
+	for _, field := range s.PartitionFields {
+		if s.fieldIndex(field) < 0 {
+			return fmt.Errorf("Partition field %s is not in the fields %v", field, s.FieldNames)
+		}
+	}
+	return nil
+}
+
+func (s *FileSink) fieldIndex(field string) int {
+	for i, fieldName := range s.FieldNames {
+		if fieldName == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// OpenTask implements flow.Sink. The task writes its files, and after
+// committing emits their paths, row counts and sizes for the manifest.
+func (s *FileSink) OpenTask(taskId int) (flow.TaskWriter, error) {
+	return newPartitionWriters(s, taskId), nil
+}
+
+// partitionWriters keeps up to MaxOpenWriters files open, by partition directory.
+type partitionWriters struct {
+	sink             *FileSink
+	taskId           int
+	partitionIndexes []int
+	valueIndexes     []int
+	valueNames       []string
+	valueTypes       []string
+	open             map[string]*list.Element
+	recent           *list.List // of *partitionWriter, the most recent first
+	fileCounts       map[string]int
+	files            []*manifestFile // the closed files
+}
+
+type partitionWriter struct {
+	dir    string
+	writer RowWriter
+	file   *manifestFile
+	bytes  *countingWriter
+}
+
+func newPartitionWriters(s *FileSink, taskId int) *partitionWriters {
+	p := &partitionWriters{
+		sink:       s,
+		taskId:     taskId,
+		open:       make(map[string]*list.Element),
+		recent:     list.New(),
+		fileCounts: make(map[string]int),
+	}
+	isPartitionField := make(map[int]bool)
+	for _, field := range s.PartitionFields {
+		index := s.fieldIndex(field)
+		p.partitionIndexes = append(p.partitionIndexes, index)
+		isPartitionField[index] = true
+	}
+	for i, fieldName := range s.FieldNames {
+		if !isPartitionField[i] {
+			p.valueIndexes = append(p.valueIndexes, i)
+			p.valueNames = append(p.valueNames, fieldName)
+			fieldType := ""
+			if i < len(s.FieldTypes) {
+				fieldType = s.FieldTypes[i]
+			}
+			p.valueTypes = append(p.valueTypes, fieldType)
+		}
+	}
+	return p
+}
+
+// Write implements flow.TaskWriter.
+func (p *partitionWriters) Write(row *util.Row) error {
+	values := make([]interface{}, 0, len(row.K)+len(row.V))
+	values = append(values, row.K...)
+	return p.write(append(values, row.V...))
+}
+
+// CommitTask implements flow.TaskWriter, closing the files.
+func (p *partitionWriters) CommitTask() error {
+	return p.close()
+}
+
+// AbortTask implements flow.TaskWriter, closing the files, which are left
+// out of the manifest.
+func (p *partitionWriters) AbortTask() error {
+	err := p.close()
+	p.files = nil
+	return err
+}
+
+// CommittedRows implements flow.TaskOutput, with the closed files.
+func (p *partitionWriters) CommittedRows() (rows []*util.Row) {
+	for _, file := range p.files {
+		rows = append(rows, util.NewRow(util.Now(), file.Path, file.Rows, file.Bytes))
+	}
+	return rows
+}
+
+func (p *partitionWriters) write(row []interface{}) error {
+	var dirs []string
+	for i, index := range p.partitionIndexes {
+		var value interface{}
+		if index < len(row) {
+			value = row[index]
+		}
+		dirs = append(dirs, p.sink.PartitionFields[i]+"="+escapePartitionValue(value))
+	}
+	pw, err := p.writer(path.Join(dirs...))
+	if err != nil {
+		return err
+	}
+	pw.file.Rows++
+
+	values := row
+	if len(p.partitionIndexes) > 0 {
+		values = nil
+		for _, index := range p.valueIndexes {
+			if index < len(row) {
+				values = append(values, row[index])
+			}
+		}
+		// fields after the named ones
+		if len(row) > len(p.sink.FieldNames) {
+			values = append(values, row[len(p.sink.FieldNames):]...)
+		}
+	}
+	return pw.writer.Write(values)
+}
+
+// writer returns the open writer of the partition directory, or starts a new
+// file in it, closing the least recently written file if too many are open.
+func (p *partitionWriters) writer(dir string) (*partitionWriter, error) {
+	if e, found := p.open[dir]; found {
+		p.recent.MoveToFront(e)
+		return e.Value.(*partitionWriter), nil
+	}
+	if p.sink.MaxOpenWriters > 0 && len(p.open) >= p.sink.MaxOpenWriters {
+		e := p.recent.Back()
+		p.recent.Remove(e)
+		if err := p.closeWriter(e.Value.(*partitionWriter)); err != nil {
+			return nil, err
+		}
+	}
+
+	fileName := fmt.Sprintf("part-%05d", p.taskId)
+	if count := p.fileCounts[dir]; count > 0 {
+		fileName = fmt.Sprintf("%s-%d", fileName, count)
+	}
+	p.fileCounts[dir]++
+	relativePath := path.Join(dir, fileName+"."+p.sink.FileType)
+	fullPath := strings.TrimSuffix(p.sink.Folder, "/") + "/" + relativePath
+
+	w, err := filesystem.Create(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create file %s: %v", fullPath, err)
+	}
+	counter := &countingWriter{WriteCloser: w}
+	writer, err := p.newRowWriter(counter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write file %s: %v", fullPath, err)
+	}
+	pw := &partitionWriter{
+		dir:    dir,
+		writer: writer,
+		file:   &manifestFile{Path: relativePath},
+		bytes:  counter,
+	}
+	p.open[dir] = p.recent.PushFront(pw)
+	return pw, nil
+}
+
+func (p *partitionWriters) closeWriter(pw *partitionWriter) error {
+	delete(p.open, pw.dir)
+	if err := pw.writer.Close(); err != nil {
+		return fmt.Errorf("Failed to close file %s: %v", pw.file.Path, err)
+	}
+	pw.file.Bytes = pw.bytes.count
+	p.files = append(p.files, pw.file)
+	return nil
+}
+
+func (p *partitionWriters) newRowWriter(w io.WriteCloser) (RowWriter, error) {
+	switch p.sink.FileType {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if delimiter := p.sink.Config["delimiter"]; delimiter != "" {
+			writer.Comma = []rune(delimiter)[0]
+		}
+		return &csvRowWriter{file: w, writer: writer}, nil
+	case "tsv":
+		return &tsvRowWriter{file: w, writer: bufio.NewWriter(w)}, nil
+	}
+	if format, found := Formats[p.sink.FileType]; found && format.NewWriter != nil {
+		writer, err := format.NewWriter(w, p.valueNames, p.valueTypes)
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		return writer, nil
+	}
+	w.Close()
+	return nil, fmt.Errorf("File type %s is not defined.", p.sink.FileType)
+}
+
+func (p *partitionWriters) close() (err error) {
+	for e := p.recent.Front(); e != nil; e = e.Next() {
+		if closeErr := p.closeWriter(e.Value.(*partitionWriter)); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	p.recent.Init()
+	return err
+}
+
+type countingWriter struct {
+	io.WriteCloser
+	count int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	w.count += int64(n)
+	return n, err
+}
+
+// escapePartitionValue escapes the characters hive escapes in partition
+// directory names.
+func escapePartitionValue(value interface{}) string {
+	if value == nil {
+		return hiveDefaultPartition
+	}
+	s := gio.ToString(value)
+	if s == "" {
+		return hiveDefaultPartition
+	}
+	var escaped bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c == 0x7F || strings.IndexByte("\"#%'*/:=?\\{[]^", c) >= 0 {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		} else {
+			escaped.WriteByte(c)
+		}
+	}
+	return escaped.String()
+}
+
+type tsvRowWriter struct {
+	file   io.WriteCloser
+	writer *bufio.Writer
+}
+
+func (w *tsvRowWriter) Write(values []interface{}) error {
+	for i, value := range values {
+		if i > 0 {
+			w.writer.WriteByte('\t')
+		}
+		if value != nil {
+			w.writer.WriteString(gio.ToString(value))
+		}
+	}
+	return w.writer.WriteByte('\n')
+}
+
+func (w *tsvRowWriter) Close() error {
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+type csvRowWriter struct {
+	file   io.WriteCloser
+	writer *csv.Writer
+}
+
+func (w *csvRowWriter) Write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			record[i] = gio.ToString(value)
+		}
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvRowWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+// sinkManifest is the _manifest.json file of the sink folder.
+type sinkManifest struct {
+	FlowId    uint32          `json:"flowId"`
+	Timestamp string          `json:"timestamp"`
+	Files     []*manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Path  string `json:"path"` // relative to the folder
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"`
+}
+
+// addManifestStep collects the files written by the tasks on the driver, and
+// writes the manifest and the _SUCCESS marker after all tasks succeeded.
+func (s *FileSink) addManifestStep(d *flow.Dataset) {
+	step := d.Flow.AddAllToOneStep(d, nil)
+	step.IsOnDriverSide = true
+	step.Name = s.FileType + ".Manifest"
+	step.Function = func(readers []io.Reader, writers []io.Writer, stat *pb.InstructionStat) error {
+		var filesLock sync.Mutex
+		var files []*manifestFile
+		errChan := make(chan error, len(readers))
+		for _, reader := range readers {
+			go func(reader io.Reader) {
+				errChan <- util.TakeMessage(reader, -1, func(encodedBytes []byte) error {
+					row, err := util.DecodeRow(encodedBytes)
+					if err != nil {
+						return fmt.Errorf("Failed to decode row: %v", err)
+					}
+					values := append(row.K, row.V...)
+					if len(values) < 3 {
+						return fmt.Errorf("Unexpected written file row %v", values)
+					}
+					stat.InputCounter++
+					filesLock.Lock()
+					files = append(files, &manifestFile{
+						Path:  gio.ToString(values[0]),
+						Rows:  gio.ToInt64(values[1]),
+						Bytes: gio.ToInt64(values[2]),
+					})
+					filesLock.Unlock()
+					return nil
+				})
+			}(reader)
+		}
+		for range readers {
+			if err := <-errChan; err != nil {
+				return err
+			}
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Path < files[j].Path
+		})
+		if err := s.writeManifest(&sinkManifest{
+			FlowId:    d.Flow.HashCode,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Files:     files,
+		}); err != nil {
+			return err
+		}
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		for _, f := range s.onCommit {
+			if err := f(paths); err != nil {
+				return fmt.Errorf("Failed to commit %s: %v", s.Folder, err)
+			}
+		}
+		return nil
+	}
+}
+
+func (s *FileSink) writeManifest(manifest *sinkManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode manifest: %v", err)
+	}
+	folder := strings.TrimSuffix(s.Folder, "/")
+	if err := writeFile(folder+"/_manifest.json", data); err != nil {
+		return err
+	}
+	return writeFile(folder+"/_SUCCESS", nil)
+}
+
+func writeFile(fileName string, data []byte) error {
+	w, err := filesystem.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("Failed to create file %s: %v", fileName, err)
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("Failed to write file %s: %v", fileName, err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("Failed to close file %s: %v", fileName, err)
+	}
+	return nil
+}
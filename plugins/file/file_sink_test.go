@@ -0,0 +1,162 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestPartitionWriters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_file_sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := TsvSink(dir, "dt", "name", "country").PartitionBy("dt", "country").SetMaxOpenWriters(1)
+	p := newPartitionWriters(s, 3)
+	for _, row := range [][]interface{}{
+		{"2024-01-01", "a", "US"},
+		{"2024-01-01", "b", "US"},
+		{"2024-01-01", "c", "a/b"},
+		{"2024-01-01", "d", "US"},
+		{"2024-01-02", "e", nil},
+	} {
+		if err := p.write(row); err != nil {
+			t.Fatalf("write %v: %v", row, err)
+		}
+	}
+	if err := p.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	for fileName, expected := range map[string]string{
+		"dt=2024-01-01/country=US/part-00003.tsv":                         "a\nb\n",
+		"dt=2024-01-01/country=a%2Fb/part-00003.tsv":                      "c\n",
+		"dt=2024-01-01/country=US/part-00003-1.tsv":                       "d\n",
+		"dt=2024-01-02/country=__HIVE_DEFAULT_PARTITION__/part-00003.tsv": "e\n",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			t.Errorf("read %s: %v", fileName, err)
+			continue
+		}
+		if string(data) != expected {
+			t.Errorf("%s has %q, expected %q", fileName, data, expected)
+		}
+	}
+
+	var rows, bytes int64
+	for _, file := range p.files {
+		rows += file.Rows
+		bytes += file.Bytes
+	}
+	if len(p.files) != 4 || rows != 5 || bytes != 10 {
+		t.Errorf("counted %d files of %d rows and %d bytes", len(p.files), rows, bytes)
+	}
+
+	if err := s.writeManifest(&sinkManifest{Files: p.files}); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	for _, fileName := range []string{"_manifest.json", "_SUCCESS"} {
+		if _, err := os.Stat(filepath.Join(dir, fileName)); err != nil {
+			t.Errorf("missing %s: %v", fileName, err)
+		}
+	}
+}
+
+func TestFileSinkTask(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_file_sink_task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var s flow.Sink = TsvSink(dir, "name", "country").PartitionBy("country")
+	task, err := s.OpenTask(1)
+	if err != nil {
+		t.Fatalf("open task: %v", err)
+	}
+	for _, row := range [][]interface{}{{"a", "US"}, {"b", "US"}, {"c", "FR"}} {
+		if err := task.Write(util.NewRow(util.Now(), row...)); err != nil {
+			t.Fatalf("write %v: %v", row, err)
+		}
+	}
+	if err := task.CommitTask(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	var files []string
+	for _, row := range task.(flow.TaskOutput).CommittedRows() {
+		values := append(row.K, row.V...)
+		files = append(files, fmt.Sprintf("%v %v", values[0], values[1]))
+	}
+	sort.Strings(files)
+	if expected := []string{"country=FR/part-00001.tsv 1", "country=US/part-00001.tsv 2"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("committed %v, expected %v", files, expected)
+	}
+
+	// an aborted task has no files for the manifest
+	task, err = s.OpenTask(2)
+	if err != nil {
+		t.Fatalf("open task: %v", err)
+	}
+	if err := task.Write(util.NewRow(util.Now(), "d", "US")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := task.AbortTask(); err != nil {
+		t.Fatalf("abort: %v", err)
+	}
+	if rows := task.(flow.TaskOutput).CommittedRows(); len(rows) != 0 {
+		t.Errorf("aborted task has files %v", rows)
+	}
+}
+
+type typedRowWriter struct {
+	io.WriteCloser
+}
+
+func (w typedRowWriter) Write(values []interface{}) error { return nil }
+
+func TestFileSinkFieldTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_file_sink_types")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var names, types []string
+	Formats["typed"] = Format{
+		NewWriter: func(w io.WriteCloser, fieldNames, fieldTypes []string) (RowWriter, error) {
+			names, types = fieldNames, fieldTypes
+			return typedRowWriter{w}, nil
+		},
+		IsColumnar: true,
+	}
+	defer delete(Formats, "typed")
+
+	s := newFileSink("typed", dir, []string{"dt", "id", "name"}).
+		SetFieldTypes("UTF8", "INT64").
+		PartitionBy("dt")
+	task, err := s.OpenTask(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := task.Write(util.NewRow(util.Now(), "2024-01-01", 1, "a")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := task.CommitTask(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	// the partition field is not a column
+	if !reflect.DeepEqual(names, []string{"id", "name"}) || !reflect.DeepEqual(types, []string{"INT64", ""}) {
+		t.Errorf("columns %v of types %q", names, types)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/lovelly/gleam/filesystem"
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/plugins/file/split"
 	"github.com/lovelly/gleam/util"
 )
 
@@ -23,14 +24,22 @@ type FileSource struct {
 	PartitionCount int
 	FileType       string
 	Fields         []string
+	Config         map[string]string
+	SplitSize      int64
+	StrictSchema   bool
 
 	prefix string
 }
 
 // Generate generates data shard info,
 // partitions them via round robin,
-// and reads each shard on each executor
+// and reads each shard on each executor.
+// The flow fails if the file type is not built in or registered.
 func (s *FileSource) Generate(f *flow.Flow) *flow.Dataset {
+	if !IsSupported(s.FileType) {
+		return f.FailedSource(s.prefix, fmt.Errorf("File type %s is not registered, import its format, e.g. _ \"github.com/lovelly/gleam/plugins/file/%s\"", s.FileType, s.FileType))
+	}
+	s.expandPath(f)
 	return s.genShardInfos(f).RoundRobin(s.prefix, s.PartitionCount).Map(s.prefix+".Read", registeredMapperReadShard)
 }
 
@@ -40,6 +49,68 @@ func (q *FileSource) SetHasHeader(hasHeader bool) *FileSource {
 	return q
 }
 
+// SetDelimiter sets the field delimiter of csv and tsv files, which is ',' and '\t' by default
+func (q *FileSource) SetDelimiter(delimiter rune) *FileSource {
+	if q.Config == nil {
+		q.Config = make(map[string]string)
+	}
+	q.Config["delimiter"] = string(delimiter)
+	return q
+}
+
+// SetEnclosure sets the character optionally quoting the fields of tsv files,
+// read like the ENCLOSED BY option of MySQL's LOAD DATA INFILE.
+func (q *FileSource) SetEnclosure(enclosed byte) *FileSource {
+	if q.Config == nil {
+		q.Config = make(map[string]string)
+	}
+	q.Config["enclosed"] = string([]byte{enclosed})
+	return q
+}
+
+// SetEscape sets the character escaping the special characters of tsv files,
+// read like the ESCAPED BY option of MySQL's LOAD DATA INFILE.
+func (q *FileSource) SetEscape(escaped byte) *FileSource {
+	if q.Config == nil {
+		q.Config = make(map[string]string)
+	}
+	q.Config["escaped"] = string([]byte{escaped})
+	return q
+}
+
+// SetSplitSize sets the size of the splits of large files, which are read in
+// parallel. Txt, tsv and jsonl files are split by bytes, and orc and parquet files by
+// stripes or row groups. Files compressed by gzip or bzip2 are not splittable,
+// but bgzip compressed files and zstd files with a seek table are.
+// The default is 128MB, and 0 disables the splitting.
+func (q *FileSource) SetSplitSize(sizeInMB int) *FileSource {
+	q.SplitSize = int64(sizeInMB) * 1024 * 1024
+	return q
+}
+
+// SetNestedMode sets how orc and parquet files with nested fields are read:
+// nested.Flatten reads the first value of repeated fields, nested.Explode reads
+// a row for each of their values, and nested.Struct reads lists and structs
+// as []interface{} and map[string]interface{} values. Parquet files are read
+// as leaf columns in the flatten mode by default, and orc files as top level
+// columns in the struct mode. Nested fields can be selected by dotted paths,
+// e.g. "a.b.c".
+func (q *FileSource) SetNestedMode(mode string) *FileSource {
+	if q.Config == nil {
+		q.Config = make(map[string]string)
+	}
+	q.Config["nested"] = mode
+	return q
+}
+
+// SetStrictSchema sets whether the orc or parquet files must have the same
+// columns. By default, the columns of the files are merged by name, and the
+// rows of the files without some columns have nil values for them.
+func (q *FileSource) SetStrictSchema(strict bool) *FileSource {
+	q.StrictSchema = strict
+	return q
+}
+
 // TODO adjust FileSource api to denote which data source can support columnar reads
 // Select selects fields that can be pushed down to data sources supporting columnar reads
 func (q *FileSource) Select(fields ...string) *FileSource {
@@ -49,11 +120,13 @@ func (q *FileSource) Select(fields ...string) *FileSource {
 
 // New creates a FileSource based on a file name.
 // The base file name can have "*", "?" pattern denoting a list of file names.
+// The "{{name}}" placeholders of flow parameters are substituted when the source is read.
 func newFileSource(fileType, fileOrPattern string, partitionCount int) *FileSource {
 
 	s := &FileSource{
 		PartitionCount: partitionCount,
 		FileType:       fileType,
+		SplitSize:      128 * 1024 * 1024,
 		prefix:         fileType,
 	}
 
@@ -88,17 +161,23 @@ func newFileSource(fileType, fileOrPattern string, partitionCount int) *FileSour
 	return s
 }
 
+// expandPath substitutes the flow parameters in the file name or pattern.
+func (s *FileSource) expandPath(f *flow.Flow) {
+	expanded := f.Expand(s.Path)
+	if expanded == s.Path {
+		return
+	}
+	if t := newFileSource(s.FileType, expanded, s.PartitionCount); t != nil {
+		s.folder, s.fileBaseName, s.hasWildcard, s.Path = t.folder, t.fileBaseName, t.hasWildcard, t.Path
+	}
+}
+
 func (s *FileSource) genShardInfos(f *flow.Flow) *flow.Dataset {
 	return f.Source(s.prefix+"."+s.fileBaseName, func(writer io.Writer, stats *pb.InstructionStat) error {
 		stats.InputCounter++
+		var fileNames []string
 		if !s.hasWildcard && !filesystem.IsDir(s.Path) {
-			stats.OutputCounter++
-			util.NewRow(util.Now(), encodeShardInfo(&FileShardInfo{
-				FileName:  s.Path,
-				FileType:  s.FileType,
-				HasHeader: s.HasHeader,
-				Fields:    s.Fields,
-			})).WriteTo(writer)
+			fileNames = append(fileNames, s.Path)
 		} else {
 			virtualFiles, err := filesystem.List(s.folder)
 			if err != nil {
@@ -106,20 +185,78 @@ func (s *FileSource) genShardInfos(f *flow.Flow) *flow.Dataset {
 			}
 			for _, vf := range virtualFiles {
 				if !s.hasWildcard || s.match(vf.Location) {
-					stats.OutputCounter++
-					util.NewRow(util.Now(), encodeShardInfo(&FileShardInfo{
-						FileName:  vf.Location,
-						FileType:  s.FileType,
-						HasHeader: s.HasHeader,
-						Fields:    s.Fields,
-					})).WriteTo(writer)
+					fileNames = append(fileNames, vf.Location)
 				}
 			}
 		}
+		fields, err := s.mergeSchemas(fileNames)
+		if err != nil {
+			return err
+		}
+		for _, fileName := range fileNames {
+			if err := s.writeShardInfos(writer, stats, fileName, fields); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
 
+// writeShardInfos writes the shard infos of the splits of the file, or of the
+// whole file.
+func (s *FileSource) writeShardInfos(writer io.Writer, stats *pb.InstructionStat, fileName string, fields []string) error {
+	ranges, err := s.planSplits(fileName)
+	if err != nil {
+		return err
+	}
+	if len(ranges) == 0 {
+		ranges = []split.Range{{}}
+	}
+	for _, r := range ranges {
+		stats.OutputCounter++
+		util.NewRow(util.Now(), encodeShardInfo(&FileShardInfo{
+			Config:    s.Config,
+			FileName:  fileName,
+			FileType:  s.FileType,
+			HasHeader: s.HasHeader,
+			Fields:    fields,
+			Start:     r.Start,
+			Stop:      r.Stop,
+		})).WriteTo(writer)
+	}
+	return nil
+}
+
+// planSplits splits large txt, tsv and jsonl files by bytes, and orc and parquet files
+// by stripes or row groups. Csv files, and tsv files with enclosed or escaped
+// fields, are not split since their fields can have newlines.
+func (s *FileSource) planSplits(fileName string) ([]split.Range, error) {
+	if s.SplitSize <= 0 || s.FileType == "csv" || s.Config["enclosed"] != "" || s.Config["escaped"] != "" {
+		return nil, nil
+	}
+	vf, err := filesystem.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open file %s: %v", fileName, err)
+	}
+	defer vf.Close()
+
+	switch s.FileType {
+	case "txt", "tsv", "jsonl":
+		ranges, err := split.Plan(vf, fileName, s.SplitSize)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to split file %s: %v", fileName, err)
+		}
+		return ranges, nil
+	}
+	if format, found := Formats[s.FileType]; found && format.Split != nil {
+		if vf.Size() <= s.SplitSize {
+			return nil, nil
+		}
+		return format.Split(vf, fileName, s.SplitSize)
+	}
+	return nil, nil
+}
+
 func (s *FileSource) match(fullPath string) bool {
 	baseName := filepath.Base(fullPath)
 	match, _ := filepath.Match(s.fileBaseName, baseName)
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/filesystem"
+)
+
+func TestReadSplits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_file_source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d %s", i, strings.Repeat("x", i%17)))
+	}
+	fileName := filepath.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(fileName, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Txt(fileName, 1)
+	s.SplitSize = 1000
+	ranges, err := s.planSplits(fileName)
+	if err != nil {
+		t.Fatalf("plan splits: %v", err)
+	}
+	if len(ranges) < 2 {
+		t.Fatalf("planned %d splits", len(ranges))
+	}
+
+	var read []string
+	for _, r := range ranges {
+		shard := &FileShardInfo{FileName: fileName, FileType: "txt", Start: r.Start, Stop: r.Stop}
+		vf, err := filesystem.Open(fileName)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		reader, closer, err := shard.newReader(vf)
+		if err != nil {
+			t.Fatalf("split %+v: %v", r, err)
+		}
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("split %+v: read: %v", r, err)
+			}
+			read = append(read, row.K[0].(string))
+		}
+		closer.Close()
+		vf.Close()
+	}
+	if !reflect.DeepEqual(read, lines) {
+		t.Errorf("read %d lines from %d splits, expected %d", len(read), len(ranges), len(lines))
+	}
+}
@@ -0,0 +1,73 @@
+// Package jsonl reads files of json objects, one per line.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/plugins/file/nested"
+	"github.com/lovelly/gleam/util"
+)
+
+// JsonlFileReader reads the selected fields of each object. Numbers are read
+// as int64 or float64, and nested objects and arrays as their json text.
+type JsonlFileReader struct {
+	scanner *bufio.Scanner
+	fields  []string
+	paths   [][]string
+}
+
+func New(reader io.Reader) *JsonlFileReader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return &JsonlFileReader{
+		scanner: scanner,
+	}
+}
+
+// Select sets the fields to read, in order, which can be dotted paths,
+// e.g. "a.b.c". The missing fields are nil. By default the line is read
+// as one field.
+func (r *JsonlFileReader) Select(fields []string) *JsonlFileReader {
+	r.fields = fields
+	r.paths = nil
+	for _, field := range fields {
+		r.paths = append(r.paths, nested.Path(field))
+	}
+	return r
+}
+
+func (r *JsonlFileReader) ReadHeader() (fieldNames []string, err error) {
+	return r.fields, nil
+}
+
+func (r *JsonlFileReader) Read() (row *util.Row, err error) {
+	var line []byte
+	for len(line) == 0 {
+		if !r.scanner.Scan() {
+			if err = r.scanner.Err(); err == nil {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		line = bytes.TrimSpace(r.scanner.Bytes())
+	}
+	if len(r.fields) == 0 {
+		return util.NewRow(util.Now(), string(line)), nil
+	}
+
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("Failed to decode json line %s: %v", line, err)
+	}
+	values := make([]interface{}, len(r.paths))
+	for i, path := range r.paths {
+		values[i] = util.JsonValue(nested.Get(object, path))
+	}
+	return util.NewRow(util.Now(), values...), nil
+}
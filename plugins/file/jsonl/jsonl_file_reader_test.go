@@ -0,0 +1,30 @@
+package jsonl
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSelectedFields(t *testing.T) {
+	r := New(strings.NewReader("{\"a\": 1, \"b\": {\"c\": \"x\"}}\n\n{\"a\": 2.5, \"d\": [1, 2]}\n")).
+		Select([]string{"a", "b.c", "d"})
+
+	expected := [][]interface{}{
+		{int64(1), "x", nil},
+		{2.5, nil, "[1,2]"},
+	}
+	for _, values := range expected {
+		row, err := r.Read()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if actual := append(row.K, row.V...); !reflect.DeepEqual(actual, values) {
+			t.Errorf("row %v, expecting %v", actual, values)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expecting EOF, got %v", err)
+	}
+}
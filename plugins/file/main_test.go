@@ -0,0 +1,15 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+// TestMain runs the mappers when the test binary is started again to run
+// them, instead of the tests.
+func TestMain(m *testing.M) {
+	gio.Init()
+	os.Exit(m.Run())
+}
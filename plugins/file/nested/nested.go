@@ -0,0 +1,93 @@
+// Package nested has the helpers shared by the readers of nested data, i.e.
+// orc and parquet files with structs and repeated fields.
+package nested
+
+import (
+	"strings"
+)
+
+// The modes of reading nested fields.
+const (
+	// Flatten reads the first value of repeated fields.
+	Flatten = "flatten"
+	// Explode reads a row for each value of the repeated fields.
+	Explode = "explode"
+	// Struct reads repeated fields as []interface{} and structs as
+	// map[string]interface{}.
+	Struct = "struct"
+)
+
+// Path splits a dotted field path, e.g. "a.b.c".
+func Path(field string) []string {
+	return strings.Split(field, ".")
+}
+
+// Get returns the value at the path in a struct value. The path through a
+// list returns the list of the values in its elements.
+func Get(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Get(v[path[0]], path[1:])
+	case []interface{}:
+		var values []interface{}
+		for _, element := range v {
+			values = append(values, Get(element, path))
+		}
+		return values
+	}
+	return nil
+}
+
+// Apply reads the row of the values in the mode. The repeated values are
+// []interface{} lists.
+func Apply(mode string, values []interface{}, repeated []bool) [][]interface{} {
+	switch mode {
+	case Explode:
+		return explode(values, repeated)
+	case Struct:
+		return [][]interface{}{values}
+	}
+	for i, value := range values {
+		if repeated[i] {
+			values[i] = first(value)
+		}
+	}
+	return [][]interface{}{values}
+}
+
+// explode reads a row for each index of the repeated values, which is nil in
+// the shorter lists, and a row of nils if all lists are empty. The other values
+// are the same in all rows.
+func explode(values []interface{}, repeated []bool) (rows [][]interface{}) {
+	n := 1
+	for i, value := range values {
+		if list, ok := value.([]interface{}); ok && repeated[i] && len(list) > n {
+			n = len(list)
+		}
+	}
+	for x := 0; x < n; x++ {
+		row := make([]interface{}, len(values))
+		for i, value := range values {
+			if !repeated[i] {
+				row[i] = value
+			} else if list, ok := value.([]interface{}); ok && x < len(list) {
+				row[i] = list[x]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func first(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	}
+	return value
+}
@@ -0,0 +1,43 @@
+package nested
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	values := func() []interface{} {
+		return []interface{}{"id", []interface{}{1, 2, 3}, []interface{}{"a", "b"}}
+	}
+	repeated := []bool{false, true, true}
+
+	if rows := Apply(Flatten, values(), repeated); !reflect.DeepEqual(rows, [][]interface{}{{"id", 1, "a"}}) {
+		t.Errorf("flatten: %v", rows)
+	}
+	if rows := Apply(Struct, values(), repeated); !reflect.DeepEqual(rows, [][]interface{}{values()}) {
+		t.Errorf("struct: %v", rows)
+	}
+	expected := [][]interface{}{{"id", 1, "a"}, {"id", 2, "b"}, {"id", 3, nil}}
+	if rows := Apply(Explode, values(), repeated); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("explode: %v", rows)
+	}
+	empty := []interface{}{"id", []interface{}{}, []interface{}(nil)}
+	if rows := Apply(Explode, empty, repeated); !reflect.DeepEqual(rows, [][]interface{}{{"id", nil, nil}}) {
+		t.Errorf("explode empty lists: %v", rows)
+	}
+}
+
+func TestGet(t *testing.T) {
+	value := map[string]interface{}{
+		"b": []interface{}{
+			map[string]interface{}{"c": 1},
+			map[string]interface{}{"c": 2},
+		},
+	}
+	if v := Get(value, Path("b.c")); !reflect.DeepEqual(v, []interface{}{1, 2}) {
+		t.Errorf("get b.c: %v", v)
+	}
+	if v := Get(value, Path("x.c")); v != nil {
+		t.Errorf("get x.c: %v", v)
+	}
+}
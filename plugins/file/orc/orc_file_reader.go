@@ -2,15 +2,31 @@ package orc
 
 import (
 	"io"
+	"reflect"
 
+	"github.com/lovelly/gleam/plugins/file/nested"
+	"github.com/lovelly/gleam/plugins/file/split"
 	"github.com/lovelly/gleam/util"
 	"github.com/scritchley/orc"
 )
 
 type OrcFileReader struct {
 	reader     *orc.Reader
+	size       int64
 	cursor     *orc.Cursor
+	stripe     int // the current stripe, or -1 before the first one
+	start      int
+	stop       int // 0 to read all stripes
 	fieldNames []string
+	mode       string
+	fields     []orcField
+	rows       [][]interface{} // the exploded rows to read
+}
+
+// orcField is a selected column, or a nested field in it.
+type orcField struct {
+	column int // in the selected columns, or -1 if not in the file
+	path   []string
 }
 
 // TODO predicate pushdown
@@ -21,9 +37,16 @@ func New(reader orc.SizedReaderAt) (*OrcFileReader, error) {
 	}
 	return &OrcFileReader{
 		reader:     t,
+		size:       reader.Size(),
+		stripe:     -1,
 		fieldNames: t.Schema().Columns(),
+		mode:       nested.Struct,
 	}, nil
 }
+
+// Select sets the fields to read, which can be dotted paths of nested fields.
+// The fields not in the file are read as nil, e.g. the columns added to later
+// files of a data set.
 func (r *OrcFileReader) Select(fields []string) *OrcFileReader {
 	if fields != nil {
 		r.fieldNames = fields
@@ -31,19 +54,155 @@ func (r *OrcFileReader) Select(fields []string) *OrcFileReader {
 	return r
 }
 
+// SetNestedMode sets how to read list values, one of nested.Flatten,
+// nested.Explode or nested.Struct, which is the default.
+func (r *OrcFileReader) SetNestedMode(mode string) *OrcFileReader {
+	if mode != "" {
+		r.mode = mode
+	}
+	return r
+}
+
+// SetStripes sets the range of the stripes to read.
+func (r *OrcFileReader) SetStripes(start, stop int) *OrcFileReader {
+	r.start, r.stop = start, stop
+	return r
+}
+
+// SplitStripes groups the stripes into ranges of about splitSize bytes, to
+// read them in parallel. It returns nil if there is only one range.
+func (r *OrcFileReader) SplitStripes(splitSize int64) (ranges []split.Range) {
+	numStripes := r.reader.NumStripes()
+	if numStripes < 2 {
+		return nil
+	}
+	stripesPerSplit := int64(1)
+	if stripeSize := r.size / int64(numStripes); stripeSize > 0 && splitSize > stripeSize {
+		stripesPerSplit = splitSize / stripeSize
+	}
+	for start := int64(0); start < int64(numStripes); start += stripesPerSplit {
+		stop := start + stripesPerSplit
+		if stop > int64(numStripes) {
+			stop = int64(numStripes)
+		}
+		ranges = append(ranges, split.Range{Start: start, Stop: stop})
+	}
+	if len(ranges) < 2 {
+		return nil
+	}
+	return ranges
+}
+
 func (r *OrcFileReader) ReadHeader() (fieldNames []string, err error) {
 	return r.fieldNames, nil
 }
 func (r *OrcFileReader) Read() (row *util.Row, err error) {
+	if len(r.rows) > 0 {
+		values := r.rows[0]
+		r.rows = r.rows[1:]
+		return util.NewRow(util.Now(), values...), nil
+	}
 	if r.cursor == nil {
-		r.cursor = r.reader.Select(r.fieldNames...)
+		r.cursor = r.reader.Select(r.selectColumns()...)
 	}
-	// Iterate over each row in the stripe.
-	if r.cursor.Next() || r.cursor.Stripes() && r.cursor.Next() {
+	// Iterate over each row in the stripes of the range, selecting its first
+	// stripe without reading the ones before.
+	for r.stripe < r.start || !r.cursor.Next() {
 		if err := r.cursor.Err(); err != nil {
 			return nil, err
 		}
-		return util.NewRow(util.Now(), r.cursor.Row()...), nil
+		next := r.stripe + 1
+		if next < r.start {
+			next = r.start
+		}
+		if next >= r.stopStripe() {
+			return nil, io.EOF
+		}
+		if err := r.cursor.SelectStripe(next); err != nil {
+			return nil, err
+		}
+		r.stripe = next
+	}
+	if err := r.cursor.Err(); err != nil {
+		return nil, err
+	}
+	columns := r.cursor.Row()
+	values := make([]interface{}, len(r.fields))
+	repeated := make([]bool, len(r.fields))
+	for i, field := range r.fields {
+		if field.column < 0 {
+			continue
+		}
+		values[i] = nested.Get(plain(columns[field.column]), field.path)
+		_, repeated[i] = values[i].([]interface{})
+	}
+	rows := nested.Apply(r.mode, values, repeated)
+	r.rows = rows[1:]
+	return util.NewRow(util.Now(), rows[0]...), nil
+}
+
+// stopStripe returns the end of the range of the stripes to read.
+func (r *OrcFileReader) stopStripe() int {
+	if r.stop > 0 && r.stop < r.reader.NumStripes() {
+		return r.stop
+	}
+	return r.reader.NumStripes()
+}
+
+// selectColumns returns the top level columns of the fields in the file. If
+// there is none, it still selects a column to read the number of rows.
+func (r *OrcFileReader) selectColumns() (columns []string) {
+	fileColumns := r.reader.Schema().Columns()
+	has := make(map[string]bool)
+	for _, column := range fileColumns {
+		has[column] = true
+	}
+	selected := make(map[string]int)
+	r.fields = nil
+	for _, fieldName := range r.fieldNames {
+		path := nested.Path(fieldName)
+		field := orcField{column: -1, path: path[1:]}
+		if has[fieldName] {
+			path, field.path = []string{fieldName}, nil
+		}
+		if has[path[0]] {
+			if _, found := selected[path[0]]; !found {
+				selected[path[0]] = len(columns)
+				columns = append(columns, path[0])
+			}
+			field.column = selected[path[0]]
+		}
+		r.fields = append(r.fields, field)
+	}
+	if len(columns) == 0 && len(fileColumns) > 0 {
+		columns = append(columns, fileColumns[0])
+	}
+	return columns
+}
+
+// plain converts the orc structs to map[string]interface{}, and lists to
+// []interface{}, which can be read by nested.Get() and encoded in rows.
+func plain(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			m[key.String()] = plain(v.MapIndex(key).Interface())
+		}
+		return m
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = plain(v.Index(i).Interface())
+		}
+		return list
 	}
-	return nil, io.EOF
+	return value
 }
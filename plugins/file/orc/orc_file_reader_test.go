@@ -0,0 +1,117 @@
+package orc
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/plugins/file/nested"
+	"github.com/scritchley/orc"
+)
+
+func TestPlain(t *testing.T) {
+	value := map[string]interface{}{
+		"id":   int64(1),
+		"tags": []string{"a", "b"},
+		"items": []map[string]interface{}{
+			{"name": "x", "data": []byte("1")},
+			{"name": "y", "data": []byte("2")},
+		},
+		"counts": map[int64]int64{1: 2},
+	}
+	expected := map[string]interface{}{
+		"id":   int64(1),
+		"tags": []interface{}{"a", "b"},
+		"items": []interface{}{
+			map[string]interface{}{"name": "x", "data": []byte("1")},
+			map[string]interface{}{"name": "y", "data": []byte("2")},
+		},
+		"counts": map[int64]int64{1: 2},
+	}
+	p := plain(value)
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("plain: %v", p)
+	}
+
+	names := nested.Get(p, nested.Path("items.name"))
+	if !reflect.DeepEqual(names, []interface{}{"x", "y"}) {
+		t.Errorf("items.name: %v", names)
+	}
+	rows := nested.Apply(nested.Explode, []interface{}{int64(1), names}, []bool{false, true})
+	if !reflect.DeepEqual(rows, [][]interface{}{{int64(1), "x"}, {int64(1), "y"}}) {
+		t.Errorf("explode items.name: %v", rows)
+	}
+}
+
+func TestReadStripes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "a.orc")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := orc.ParseSchema("struct<id:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// small stripes, to split the file
+	w, err := orc.NewWriter(f, orc.SetSchema(schema), orc.SetStripeTargetSize(1024))
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	var expected []int64
+	for i := int64(0); i < 10000; i++ {
+		expected = append(expected, i)
+		if err := w.Write(i); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	f.Close()
+
+	vf, err := filesystem.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vf.Close()
+	reader, err := New(vf)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	ranges := reader.SplitStripes(1)
+	if len(ranges) < 2 {
+		t.Fatalf("split into %d ranges", len(ranges))
+	}
+	var ids []int64
+	for _, r := range ranges {
+		reader, err := New(vf)
+		if err != nil {
+			t.Fatalf("new reader: %v", err)
+		}
+		reader.SetStripes(int(r.Start), int(r.Stop))
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("range %+v: read: %v", r, err)
+			}
+			ids = append(ids, row.K[0].(int64))
+		}
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("read %d ids from %d ranges, expected %d", len(ids), len(ranges), len(expected))
+	}
+}
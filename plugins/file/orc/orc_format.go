@@ -0,0 +1,38 @@
+package orc
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/plugins/file"
+	"github.com/lovelly/gleam/plugins/file/split"
+)
+
+// Importing this package registers the orc files for file.Orc().
+func init() {
+	file.Formats["orc"] = file.Format{
+		NewReader:  newShardReader,
+		Split:      splitFile,
+		IsColumnar: true,
+	}
+}
+
+func newShardReader(vf filesystem.VirtualFile, shard *file.FileShardInfo) (file.FileReader, error) {
+	reader, err := New(vf)
+	if err != nil {
+		return nil, err
+	}
+	reader.Select(shard.Fields).SetNestedMode(shard.Config["nested"])
+	if shard.Stop > 0 {
+		reader.SetStripes(int(shard.Start), int(shard.Stop))
+	}
+	return reader, nil
+}
+
+func splitFile(vf filesystem.VirtualFile, fileName string, splitSize int64) ([]split.Range, error) {
+	reader, err := New(vf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file %s: %v", fileName, err)
+	}
+	return reader.SplitStripes(splitSize), nil
+}
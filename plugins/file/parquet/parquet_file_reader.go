@@ -2,11 +2,14 @@ package parquet
 
 import (
 	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/plugins/file/nested"
+	"github.com/lovelly/gleam/plugins/file/split"
 	"github.com/lovelly/gleam/util"
 	. "github.com/xitongsys/parquet-go/ParquetFile"
 	. "github.com/xitongsys/parquet-go/ParquetReader"
 	. "github.com/xitongsys/parquet-go/ParquetType"
 	"io"
+	"strings"
 )
 
 type PqFile struct {
@@ -44,9 +47,37 @@ func (self *PqFile) Write(b []byte) (n int, err error) {
 func (self *PqFile) Close() error { return nil }
 
 type ParquetFileReader struct {
-	pqReader *ParquetReader
-	NumRows  int
-	Cursor   int
+	pqReader   *ParquetReader
+	fieldNames []string
+	mode       string
+	columns    []*pqColumn
+	fields     []*pqField
+	rows       [][]interface{} // the exploded rows to read
+	NumRows    int
+	Cursor     int
+}
+
+// pqColumn reads the values of a leaf column by row.
+type pqColumn struct {
+	path        string
+	relPath     []string // without the schema root
+	schemaIndex int32
+	repeated    bool // if it or a parent is repeated
+	pending     interface{}
+	hasPending  bool
+}
+
+// pqField is a selected leaf column, or the struct of a group of columns.
+type pqField struct {
+	column *pqColumn
+	node   *pqNode
+}
+
+type pqNode struct {
+	name     string
+	repeated bool
+	column   *pqColumn
+	children []*pqNode
 }
 
 func New(reader filesystem.VirtualFile, fileName string) *ParquetFileReader {
@@ -55,26 +86,346 @@ func New(reader filesystem.VirtualFile, fileName string) *ParquetFileReader {
 	pqFile, _ = pqFile.Open(fileName)
 	parquetFileReader.pqReader, _ = NewParquetColumnReader(pqFile, 1)
 	parquetFileReader.NumRows = int(parquetFileReader.pqReader.GetNumRows())
+	parquetFileReader.mode = nested.Flatten
+	parquetFileReader.initColumns()
 	return parquetFileReader
 }
 
+// Select sets the fields to read, which can be dotted paths of nested fields.
+// The fields not in the file are read as nil, e.g. the columns added to later
+// files of a data set.
+func (self *ParquetFileReader) Select(fields []string) *ParquetFileReader {
+	if fields != nil {
+		self.fieldNames = fields
+		self.fields = nil
+	}
+	return self
+}
+
+// SetNestedMode sets how to read repeated fields, one of nested.Flatten,
+// nested.Explode or nested.Struct. By default the fields are the leaf columns,
+// and in the struct mode the top level fields.
+func (self *ParquetFileReader) SetNestedMode(mode string) *ParquetFileReader {
+	if mode != "" {
+		self.mode = mode
+		self.fields = nil
+	}
+	return self
+}
+
+// SetRowGroups sets the range of the row groups to read. The column buffers
+// are created at the first read from the row groups of the footer, so they
+// seek to the column chunks of the range without reading the ones before.
+func (self *ParquetFileReader) SetRowGroups(start, stop int) *ParquetFileReader {
+	rowGroups := self.pqReader.Footer.RowGroups
+	if stop > len(rowGroups) {
+		stop = len(rowGroups)
+	}
+	if start > stop {
+		start = stop
+	}
+	self.pqReader.Footer.RowGroups = rowGroups[start:stop]
+	self.NumRows = 0
+	for _, rowGroup := range self.pqReader.Footer.RowGroups {
+		self.NumRows += int(rowGroup.NumRows)
+	}
+	return self
+}
+
+// SplitRowGroups groups the row groups into ranges of about splitSize bytes,
+// to read them in parallel. It returns nil if there is only one range.
+func (self *ParquetFileReader) SplitRowGroups(splitSize int64) (ranges []split.Range) {
+	var current split.Range
+	var size int64
+	for i, rowGroup := range self.pqReader.Footer.RowGroups {
+		size += rowGroup.TotalByteSize
+		current.Stop = int64(i + 1)
+		if size >= splitSize {
+			ranges = append(ranges, current)
+			current, size = split.Range{Start: current.Stop}, 0
+		}
+	}
+	if current.Stop > current.Start {
+		ranges = append(ranges, current)
+	}
+	if len(ranges) < 2 {
+		return nil
+	}
+	return ranges
+}
+
 func (self *ParquetFileReader) ReadHeader() (fieldNames []string, err error) {
-	return self.pqReader.SchemaHandler.ValueColumns, nil
+	return self.getFieldNames(), nil
 }
 
 func (self *ParquetFileReader) Read() (row *util.Row, err error) {
+	if len(self.rows) > 0 {
+		values := self.rows[0]
+		self.rows = self.rows[1:]
+		return util.NewRow(util.Now(), values...), nil
+	}
 	if self.Cursor >= self.NumRows {
 		return nil, io.EOF
 	}
-	objects := make([]interface{}, 0)
-	for _, fieldName := range self.pqReader.SchemaHandler.ValueColumns {
-		schemaIndex := self.pqReader.SchemaHandler.MapIndex[fieldName]
-		values, _, _ := self.pqReader.ReadColumnByPath(fieldName, 1)
-		objects = append(objects, ParquetTypeToGoType(values[0],
-			self.pqReader.SchemaHandler.SchemaElements[schemaIndex].Type,
-			self.pqReader.SchemaHandler.SchemaElements[schemaIndex].ConvertedType,
-		))
+	if self.fields == nil {
+		self.initFields()
+	}
+	columnValues := make(map[*pqColumn][]interface{})
+	for _, column := range self.columns {
+		if self.isRead(column) {
+			columnValues[column] = self.readColumn(column)
+		}
+	}
+
+	objects := make([]interface{}, len(self.fields))
+	repeated := make([]bool, len(self.fields))
+	for i, field := range self.fields {
+		switch {
+		case field.column != nil:
+			objects[i] = leafValue(field.column, columnValues[field.column], -1)
+			repeated[i] = field.column.repeated
+		case field.node != nil:
+			objects[i] = field.node.value(columnValues, -1)
+		}
 	}
 	self.Cursor++
-	return util.NewRow(util.Now(), objects...), nil
+
+	rows := nested.Apply(self.mode, objects, repeated)
+	self.rows = rows[1:]
+	return util.NewRow(util.Now(), rows[0]...), nil
+}
+
+func (self *ParquetFileReader) initColumns() {
+	schemaHandler := self.pqReader.SchemaHandler
+	rootName := schemaHandler.SchemaElements[0].Name
+	for _, path := range schemaHandler.ValueColumns {
+		fullPath := nested.Path(path)
+		relPath := fullPath
+		if len(relPath) > 1 && relPath[0] == rootName {
+			relPath = relPath[1:]
+		}
+		column := &pqColumn{
+			path:        path,
+			relPath:     relPath,
+			schemaIndex: schemaHandler.MapIndex[path],
+		}
+		for i := 1; i <= len(fullPath); i++ {
+			if self.isRepeated(fullPath[:i]) {
+				column.repeated = true
+			}
+		}
+		self.columns = append(self.columns, column)
+	}
+}
+
+func (self *ParquetFileReader) isRepeated(fullPath []string) bool {
+	schemaHandler := self.pqReader.SchemaHandler
+	schemaIndex, found := schemaHandler.MapIndex[strings.Join(fullPath, ".")]
+	if !found {
+		return false
+	}
+	return schemaHandler.SchemaElements[schemaIndex].GetRepetitionType().String() == "REPEATED"
+}
+
+func (self *ParquetFileReader) getFieldNames() []string {
+	if self.fieldNames != nil {
+		return self.fieldNames
+	}
+	if self.mode != nested.Struct {
+		return self.pqReader.SchemaHandler.ValueColumns
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, column := range self.columns {
+		if name := column.relPath[0]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// initFields resolves the fields to the columns. A field not in the file has
+// neither a column nor a node, and is read as nil.
+func (self *ParquetFileReader) initFields() {
+	self.fields = nil
+	for _, fieldName := range self.getFieldNames() {
+		path := nested.Path(fieldName)
+		field := &pqField{}
+		for _, column := range self.columns {
+			if column.path == fieldName || equalPath(column.relPath, path) {
+				field.column = column
+				break
+			}
+			if len(column.relPath) > len(path) && equalPath(column.relPath[:len(path)], path) {
+				if field.node == nil {
+					field.node = &pqNode{name: fieldName, repeated: self.isRepeated(self.fullPath(column, len(path)))}
+				}
+				field.node.add(self, column, len(path))
+			}
+		}
+		if field.column != nil {
+			field.node = nil
+		}
+		self.fields = append(self.fields, field)
+	}
+}
+
+// fullPath returns the path of the column at the depth of its relative path.
+func (self *ParquetFileReader) fullPath(column *pqColumn, depth int) []string {
+	fullPath := nested.Path(column.path)
+	return fullPath[:len(fullPath)-len(column.relPath)+depth]
+}
+
+func (self *ParquetFileReader) isRead(column *pqColumn) bool {
+	for _, field := range self.fields {
+		if field.column == column || field.node != nil && field.node.has(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// readColumn reads the values of the column in the current row. The values
+// of a repeated field continue while their repetition level is not 0.
+func (self *ParquetFileReader) readColumn(column *pqColumn) (values []interface{}) {
+	read := func() (interface{}, int32, bool) {
+		values, rls, _ := self.pqReader.ReadColumnByPath(column.path, 1)
+		if len(values) == 0 {
+			return nil, 0, false
+		}
+		var rl int32
+		if len(rls) > 0 {
+			rl = rls[0]
+		}
+		element := self.pqReader.SchemaHandler.SchemaElements[column.schemaIndex]
+		return ParquetTypeToGoType(values[0], element.Type, element.ConvertedType), rl, true
+	}
+
+	if column.hasPending {
+		values = append(values, column.pending)
+		column.pending, column.hasPending = nil, false
+	} else if value, _, ok := read(); ok {
+		values = append(values, value)
+	}
+	if !column.repeated {
+		return values
+	}
+	for {
+		value, rl, ok := read()
+		if !ok {
+			break
+		}
+		if rl == 0 {
+			column.pending, column.hasPending = value, true
+			break
+		}
+		values = append(values, value)
+	}
+	// an empty list is read as a null value
+	if len(values) == 1 && values[0] == nil {
+		values = []interface{}{}
+	}
+	return values
+}
+
+func (n *pqNode) add(r *ParquetFileReader, column *pqColumn, depth int) {
+	if depth == len(column.relPath) {
+		n.column = column
+		return
+	}
+	name := column.relPath[depth]
+	for _, child := range n.children {
+		if child.name == name {
+			child.add(r, column, depth+1)
+			return
+		}
+	}
+	child := &pqNode{name: name, repeated: r.isRepeated(r.fullPath(column, depth+1))}
+	n.children = append(n.children, child)
+	child.add(r, column, depth+1)
+}
+
+func (n *pqNode) has(column *pqColumn) bool {
+	if n.column == column {
+		return true
+	}
+	for _, child := range n.children {
+		if child.has(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// value builds the struct of the node in the current row, with the values of
+// the index of the repeated group above it, or -1. The repeated groups are
+// lists, zipped from the values of their columns. Nested lists are flattened.
+// The list wrappers, e.g. the "list" and "element" groups of LIST fields, are
+// skipped.
+func (n *pqNode) value(columnValues map[*pqColumn][]interface{}, index int) interface{} {
+	if n.column != nil {
+		return leafValue(n.column, columnValues[n.column], index)
+	}
+	if n.repeated && index < 0 {
+		count := n.count(columnValues)
+		list := make([]interface{}, count)
+		for i := 0; i < count; i++ {
+			list[i] = n.groupValue(columnValues, i)
+		}
+		return list
+	}
+	return n.groupValue(columnValues, index)
+}
+
+func (n *pqNode) groupValue(columnValues map[*pqColumn][]interface{}, index int) interface{} {
+	if len(n.children) == 1 && (n.repeated || n.children[0].repeated) {
+		return n.children[0].value(columnValues, index)
+	}
+	values := make(map[string]interface{})
+	for _, child := range n.children {
+		values[child.name] = child.value(columnValues, index)
+	}
+	return values
+}
+
+// count returns the number of values of the columns of the repeated group.
+func (n *pqNode) count(columnValues map[*pqColumn][]interface{}) (count int) {
+	if n.column != nil {
+		return len(columnValues[n.column])
+	}
+	for _, child := range n.children {
+		if c := child.count(columnValues); c > count {
+			count = c
+		}
+	}
+	return count
+}
+
+func leafValue(column *pqColumn, values []interface{}, index int) interface{} {
+	if index >= 0 {
+		if index < len(values) {
+			return values[index]
+		}
+		return nil
+	}
+	if column.repeated {
+		return values
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+func equalPath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,64 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadRowGroups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "a.parquet")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewWriter(f, []string{"name"}, nil)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	// small row groups, to split the file
+	writer.pqWriter.RowGroupSize = 1024
+	var expected []string
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("name %d", i)
+		expected = append(expected, name)
+		if err := writer.Write([]*string{&name}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	ranges := New(nil, fileName).SplitRowGroups(1)
+	if len(ranges) < 2 {
+		t.Fatalf("split into %d ranges", len(ranges))
+	}
+	var names []string
+	for _, r := range ranges {
+		reader := New(nil, fileName).SetRowGroups(int(r.Start), int(r.Stop))
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("range %+v: read: %v", r, err)
+			}
+			names = append(names, fmt.Sprint(row.K[0]))
+		}
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("read %d names from %d ranges, expected %d", len(names), len(ranges), len(expected))
+	}
+}
@@ -0,0 +1,73 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+
+	. "github.com/xitongsys/parquet-go/ParquetFile"
+	. "github.com/xitongsys/parquet-go/ParquetWriter"
+)
+
+// PqWriteFile is the ParquetFile written to a writer, e.g. of filesystem.Create().
+type PqWriteFile struct {
+	W io.WriteCloser
+}
+
+func (self *PqWriteFile) Create(name string) (ParquetFile, error) {
+	return self, nil
+}
+
+func (self *PqWriteFile) Open(name string) (ParquetFile, error) {
+	return nil, fmt.Errorf("parquet file %s is write only", name)
+}
+func (self *PqWriteFile) Seek(offset int64, pos int) (int64, error) {
+	return 0, fmt.Errorf("parquet file is write only")
+}
+func (self *PqWriteFile) Read(b []byte) (n int, err error) {
+	return 0, fmt.Errorf("parquet file is write only")
+}
+func (self *PqWriteFile) Write(b []byte) (n int, err error) {
+	return self.W.Write(b)
+}
+func (self *PqWriteFile) Close() error { return self.W.Close() }
+
+// ParquetFileWriter writes rows of optional columns.
+type ParquetFileWriter struct {
+	pqFile   *PqWriteFile
+	pqWriter *CSVWriter
+}
+
+// NewWriter writes the columns of the parquet types, e.g. INT64, DOUBLE,
+// BOOLEAN, TIMESTAMP_MILLIS or UTF8, which is the type of the columns without
+// a type in fieldTypes.
+func NewWriter(writer io.WriteCloser, fieldNames, fieldTypes []string) (*ParquetFileWriter, error) {
+	md := make([]string, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		fieldType := "UTF8"
+		if i < len(fieldTypes) && fieldTypes[i] != "" {
+			fieldType = fieldTypes[i]
+		}
+		md[i] = fmt.Sprintf("name=%s, type=%s, repetitiontype=OPTIONAL", fieldName, fieldType)
+	}
+	pqFile := &PqWriteFile{W: writer}
+	pqWriter, err := NewCSVWriter(md, pqFile, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetFileWriter{pqFile: pqFile, pqWriter: pqWriter}, nil
+}
+
+// Write writes a row of the values formatted as strings, which are parsed as
+// the types of their columns. nil values are NULL.
+func (self *ParquetFileWriter) Write(values []*string) error {
+	return self.pqWriter.WriteString(values)
+}
+
+// Close writes the parquet footer and closes the writer.
+func (self *ParquetFileWriter) Close() error {
+	if err := self.pqWriter.WriteStop(); err != nil {
+		self.pqFile.Close()
+		return err
+	}
+	return self.pqFile.Close()
+}
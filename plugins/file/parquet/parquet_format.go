@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/plugins/file"
+	"github.com/lovelly/gleam/plugins/file/split"
+)
+
+// Importing this package registers the parquet files for file.Parquet() and
+// file.ParquetSink().
+func init() {
+	file.Formats["parquet"] = file.Format{
+		NewReader:  newShardReader,
+		Split:      splitFile,
+		NewWriter:  newRowWriter,
+		IsColumnar: true,
+	}
+}
+
+func newShardReader(vf filesystem.VirtualFile, shard *file.FileShardInfo) (file.FileReader, error) {
+	reader := New(vf, shard.FileName).Select(shard.Fields).SetNestedMode(shard.Config["nested"])
+	if shard.Stop > 0 {
+		reader.SetRowGroups(int(shard.Start), int(shard.Stop))
+	}
+	return reader, nil
+}
+
+func splitFile(vf filesystem.VirtualFile, fileName string, splitSize int64) ([]split.Range, error) {
+	return New(vf, fileName).SplitRowGroups(splitSize), nil
+}
+
+func newRowWriter(w io.WriteCloser, fieldNames, fieldTypes []string) (file.RowWriter, error) {
+	writer := &parquetRowWriter{w: w, fieldNames: fieldNames, types: make([]string, len(fieldNames))}
+	for i := range writer.types {
+		if i < len(fieldTypes) {
+			writer.types[i] = fieldTypes[i]
+		}
+		if writer.types[i] == "" {
+			writer.untyped++
+		}
+	}
+	return writer, nil
+}
+
+// inferRows is the most rows buffered to find the types of the columns
+// without a set type from their first non nil values. The columns with only
+// nil values are UTF8.
+const inferRows = 1000
+
+// parquetRowWriter writes the values as the parquet types of their Go types.
+type parquetRowWriter struct {
+	w          io.WriteCloser
+	fieldNames []string
+	types      []string
+	untyped    int
+	pending    [][]interface{}
+	writer     *ParquetFileWriter
+}
+
+func (w *parquetRowWriter) Write(values []interface{}) error {
+	if w.writer != nil {
+		return w.write(values)
+	}
+	for i, value := range values {
+		if i < len(w.types) && w.types[i] == "" && value != nil {
+			w.types[i] = parquetType(value)
+			w.untyped--
+		}
+	}
+	w.pending = append(w.pending, append([]interface{}(nil), values...))
+	if w.untyped > 0 && len(w.pending) < inferRows {
+		return nil
+	}
+	return w.flushPending()
+}
+
+// flushPending creates the writer with the inferred types, and writes the
+// buffered rows.
+func (w *parquetRowWriter) flushPending() error {
+	writer, err := NewWriter(w.w, w.fieldNames, w.types)
+	if err != nil {
+		return err
+	}
+	w.writer = writer
+	for _, values := range w.pending {
+		if err := w.write(values); err != nil {
+			return err
+		}
+	}
+	w.pending = nil
+	return nil
+}
+
+func (w *parquetRowWriter) write(values []interface{}) error {
+	columns := make([]*string, len(w.fieldNames))
+	for i, value := range values {
+		if i >= len(columns) || value == nil {
+			continue
+		}
+		s, err := formatValue(value, w.types[i])
+		if err != nil {
+			return fmt.Errorf("Failed to write field %s: %v", w.fieldNames[i], err)
+		}
+		columns[i] = &s
+	}
+	return w.writer.Write(columns)
+}
+
+func (w *parquetRowWriter) Close() error {
+	if w.writer == nil {
+		if err := w.flushPending(); err != nil {
+			w.w.Close()
+			return err
+		}
+	}
+	return w.writer.Close()
+}
+
+// parquetType returns the parquet type of the Go value.
+func parquetType(value interface{}) string {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return "INT64"
+	case float32, float64:
+		return "DOUBLE"
+	case bool:
+		return "BOOLEAN"
+	case time.Time:
+		return "TIMESTAMP_MILLIS"
+	}
+	return "UTF8"
+}
+
+// formatValue formats the value to be parsed as the parquet type.
+func formatValue(value interface{}, parquetType string) (string, error) {
+	switch parquetType {
+	case "INT64":
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+			return fmt.Sprint(v), nil
+		}
+	case "DOUBLE":
+		switch v := value.(type) {
+		case float32:
+			return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		case int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+			return fmt.Sprint(v), nil
+		}
+	case "BOOLEAN":
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v), nil
+		}
+	case "TIMESTAMP_MILLIS":
+		if v, ok := value.(time.Time); ok {
+			return strconv.FormatInt(v.UnixNano()/int64(time.Millisecond), 10), nil
+		}
+	default:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		}
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("%T value %v in a %s column", value, value, parquetType)
+}
@@ -0,0 +1,122 @@
+package parquet
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormatValue(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	for _, test := range []struct {
+		value       interface{}
+		parquetType string
+		expected    string
+	}{
+		{int32(-3), "INT64", "-3"},
+		{uint64(7), "INT64", "7"},
+		{1.5, "DOUBLE", "1.5"},
+		{float32(0.25), "DOUBLE", "0.25"},
+		{2, "DOUBLE", "2"},
+		{true, "BOOLEAN", "true"},
+		{at, "TIMESTAMP_MILLIS", "1704164645006"},
+		{"a", "UTF8", "a"},
+		{[]byte("b"), "UTF8", "b"},
+		{3, "UTF8", "3"},
+	} {
+		s, err := formatValue(test.value, test.parquetType)
+		if err != nil || s != test.expected {
+			t.Errorf("%T %v as %s: %q, %v", test.value, test.value, test.parquetType, s, err)
+		}
+	}
+	for value, expected := range map[interface{}]string{
+		int8(1): "INT64", float32(1): "DOUBLE", false: "BOOLEAN", at: "TIMESTAMP_MILLIS", "a": "UTF8",
+	} {
+		if parquetType(value) != expected {
+			t.Errorf("%T is %s, expected %s", value, parquetType(value), expected)
+		}
+	}
+	if _, err := formatValue("x", "INT64"); err == nil {
+		t.Errorf("formatted a string as INT64")
+	}
+}
+
+func TestWriteTypedColumns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_parquet_sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "a.parquet")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := newRowWriter(f, []string{"id", "score", "ok", "name"}, nil)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	// the types are found after the first row with a nil value
+	rows := [][]interface{}{
+		{int64(1), nil, true, "a"},
+		{int64(2), 0.5, false, nil},
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("write %v: %v", row, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if types := writer.(*parquetRowWriter).types; !reflect.DeepEqual(types, []string{"INT64", "DOUBLE", "BOOLEAN", "UTF8"}) {
+		t.Errorf("types %v", types)
+	}
+
+	reader := New(nil, fileName)
+	for i := range rows {
+		row, err := reader.Read()
+		if err != nil {
+			t.Fatalf("read row %d: %v", i, err)
+		}
+		if id, ok := row.K[0].(int64); !ok || id != int64(i+1) {
+			t.Errorf("row %d: id %T %v", i, row.K[0], row.K[0])
+		}
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("read after the rows: %v", err)
+	}
+}
+
+func TestSetColumnTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_parquet_sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "a.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the set types are kept, even for the columns of nil values
+	writer, err := newRowWriter(f, []string{"id", "score", "name"}, []string{"INT64", "DOUBLE", ""})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if err := writer.Write([]interface{}{nil, 1, "a"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := writer.Write([]interface{}{int64(2), "x", "b"}); err == nil {
+		t.Errorf("wrote a string to a DOUBLE column")
+	}
+	writer.Close()
+	if types := writer.(*parquetRowWriter).types; !reflect.DeepEqual(types, []string{"INT64", "DOUBLE", "UTF8"}) {
+		t.Errorf("types %v", types)
+	}
+}
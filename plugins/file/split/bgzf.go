@@ -0,0 +1,108 @@
+package split
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	bgzfHeaderSize   = 18
+	bgzfMaxBlockSize = 64 * 1024
+)
+
+// bgzfBlockSize returns the size of the bgzf block starting the header, or 0
+// if it is not a bgzf block header. The size is in the "BC" extra subfield.
+func bgzfBlockSize(header []byte) int {
+	if len(header) < bgzfHeaderSize || header[0] != 31 || header[1] != 139 || header[2] != 8 || header[3]&4 == 0 {
+		return 0
+	}
+	xlen := int(binary.LittleEndian.Uint16(header[10:12]))
+	if len(header) < 12+xlen {
+		return 0
+	}
+	extra := header[12 : 12+xlen]
+	for len(extra) >= 4 {
+		slen := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if extra[0] == 'B' && extra[1] == 'C' && slen == 2 && len(extra) >= 6 {
+			return int(binary.LittleEndian.Uint16(extra[4:6])) + 1
+		}
+		if len(extra) < 4+slen {
+			break
+		}
+		extra = extra[4+slen:]
+	}
+	return 0
+}
+
+func isBgzf(f File) bool {
+	header := make([]byte, bgzfHeaderSize)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return false
+	}
+	return bgzfBlockSize(header) > 0
+}
+
+// findBgzfBlock returns the offset of the first block starting at or after the
+// offset, or the file size if there is none. A candidate header is checked by
+// the header of the block after it.
+func findBgzfBlock(f File, offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	window := make([]byte, 2*bgzfMaxBlockSize+bgzfHeaderSize)
+	n, err := f.ReadAt(window, offset)
+	if err != nil && err != io.EOF {
+		return 0, fmt.Errorf("Failed to read block at %d: %v", offset, err)
+	}
+	window = window[:n]
+	for i := 0; i+bgzfHeaderSize <= len(window); i++ {
+		size := bgzfBlockSize(window[i:])
+		if size == 0 {
+			continue
+		}
+		next := i + size
+		if offset+int64(next) == f.Size() {
+			return offset + int64(i), nil
+		}
+		if next+bgzfHeaderSize <= len(window) && bgzfBlockSize(window[next:]) > 0 {
+			return offset + int64(i), nil
+		}
+	}
+	return f.Size(), nil
+}
+
+// newBgzfSource decompresses the blocks from the offset in parallel.
+func newBgzfSource(f File, offset int64) chunkSource {
+	r := io.NewSectionReader(f, offset, f.Size()-offset)
+	return newBlockSource(func() (int64, []byte, error) {
+		header := make([]byte, bgzfHeaderSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return 0, nil, fmt.Errorf("Truncated block at %d", offset)
+			}
+			return 0, nil, err
+		}
+		size := bgzfBlockSize(header)
+		if size < bgzfHeaderSize {
+			return 0, nil, fmt.Errorf("Invalid block at %d", offset)
+		}
+		block := make([]byte, size)
+		copy(block, header)
+		if _, err := io.ReadFull(r, block[bgzfHeaderSize:]); err != nil {
+			return 0, nil, fmt.Errorf("Failed to read block at %d: %v", offset, err)
+		}
+		blockOffset := offset
+		offset += int64(size)
+		return blockOffset, block, nil
+	}, func(block []byte) ([]byte, error) {
+		gz, err := gzip.NewReader(bytes.NewReader(block))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(gz)
+	})
+}
@@ -0,0 +1,123 @@
+// Package split reads line based text files in parallel splits, also when
+// they are compressed in seekable formats, i.e. bgzf, the blocked gzip of
+// bgzip, or zstd files with a seek table.
+//
+// A split is a range of the file. It reads the lines after the newlines in
+// its range, so every line is read by exactly one split, even if it crosses
+// the end of the range. For the compressed formats, the range covers the
+// blocks or frames starting in it.
+package split
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// File is the random access to a file, e.g. a filesystem.VirtualFile.
+type File interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// Range is a range of the file, in bytes of the compressed file.
+type Range struct {
+	Start int64
+	Stop  int64
+}
+
+// Plan splits the file into ranges of about splitSize bytes, at the block or
+// frame boundaries of seekable compressed files. It returns nil if the file
+// is not splittable, e.g. gzip or bzip2 files, or is not larger than one split.
+func Plan(f File, fileName string, splitSize int64) ([]Range, error) {
+	if splitSize <= 0 || f.Size() <= splitSize {
+		return nil, nil
+	}
+	switch filepath.Ext(fileName) {
+	case ".gz", ".bgz":
+		if !isBgzf(f) {
+			return nil, nil
+		}
+		// the splits skip to their first block
+		return evenRanges(f.Size(), splitSize), nil
+	case ".zst":
+		frames, err := readSeekTable(f)
+		if err != nil || frames == nil {
+			return nil, err
+		}
+		return frameRanges(frames, splitSize), nil
+	case ".bz2":
+		return nil, nil
+	}
+	return evenRanges(f.Size(), splitSize), nil
+}
+
+func evenRanges(size, splitSize int64) (ranges []Range) {
+	for start := int64(0); start < size; start += splitSize {
+		stop := start + splitSize
+		if stop > size {
+			stop = size
+		}
+		ranges = append(ranges, Range{start, stop})
+	}
+	return ranges
+}
+
+// NewReader reads the decompressed lines of the range of the file, which is
+// planned by Plan(). Close it to stop the decompression if the reading stops
+// before its end.
+func NewReader(f File, fileName string, start, stop int64) (io.ReadCloser, error) {
+	var source chunkSource
+	switch filepath.Ext(fileName) {
+	case ".gz", ".bgz":
+		blockStart, err := findBgzfBlock(f, start)
+		if err != nil {
+			return nil, err
+		}
+		source = newBgzfSource(f, blockStart)
+	case ".zst":
+		frames, err := readSeekTable(f)
+		if err != nil {
+			return nil, err
+		}
+		if frames == nil {
+			return nil, errNotSeekable
+		}
+		source = newZstdSource(f, frames, start)
+	default:
+		source = newPlainSource(f, start)
+	}
+	return &splitReader{source: source, stop: stop, skipping: start > 0}, nil
+}
+
+// NewDecompressingReader reads the whole file, decompressing it by its file
+// name extension. The blocks of seekable files are decompressed in parallel,
+// and other compressed files are decompressed ahead of the reads.
+func NewDecompressingReader(f File, fileName string) (io.ReadCloser, error) {
+	r := io.NewSectionReader(f, 0, f.Size())
+	switch filepath.Ext(fileName) {
+	case ".gz", ".bgz":
+		if isBgzf(f) {
+			return NewReader(f, fileName, 0, f.Size())
+		}
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return readAhead(gz), nil
+	case ".bz2":
+		return readAhead(bzip2.NewReader(r)), nil
+	case ".zst":
+		frames, err := readSeekTable(f)
+		if err != nil {
+			return nil, err
+		}
+		if frames != nil {
+			return NewReader(f, fileName, 0, f.Size())
+		}
+		return newZstdReader(r)
+	}
+	return ioutil.NopCloser(r), nil
+}
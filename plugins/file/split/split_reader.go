@@ -0,0 +1,219 @@
+package split
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+)
+
+const chunkSize = 1024 * 1024
+
+// chunk is a piece of the decompressed file. The lines ending in a chunk of a
+// compressed file are positioned at the offset of its block or frame, and the
+// lines of a plain file at the offset of their newlines.
+type chunk struct {
+	offset  int64
+	data    []byte
+	perByte bool
+}
+
+func (c *chunk) position(i int) int64 {
+	if c.perByte {
+		return c.offset + int64(i)
+	}
+	return c.offset
+}
+
+func (c *chunk) consume(n int) {
+	c.data = c.data[n:]
+	if c.perByte {
+		c.offset += int64(n)
+	}
+}
+
+type chunkSource interface {
+	next() (chunk, error)
+	close()
+}
+
+// splitReader reads the lines after the newlines positioned in the split, and
+// the first line for the split at the file start.
+type splitReader struct {
+	source   chunkSource
+	stop     int64
+	skipping bool
+	current  chunk
+	err      error
+}
+
+func (r *splitReader) Read(p []byte) (int, error) {
+	for {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if len(r.current.data) == 0 {
+			var err error
+			if r.current, err = r.source.next(); err != nil {
+				r.finish(err)
+			}
+			continue
+		}
+		if r.skipping {
+			i := bytes.IndexByte(r.current.data, '\n')
+			if i < 0 {
+				r.current.consume(len(r.current.data))
+				continue
+			}
+			position := r.current.position(i)
+			r.current.consume(i + 1)
+			r.skipping = false
+			if position >= r.stop {
+				r.finish(io.EOF)
+			}
+			continue
+		}
+		data := r.current.data
+		if len(data) > len(p) {
+			data = data[:len(p)]
+		}
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			n := copy(p, data)
+			r.current.consume(n)
+			return n, nil
+		}
+		n := copy(p, data[:i+1])
+		position := r.current.position(i)
+		r.current.consume(n)
+		if position >= r.stop {
+			r.finish(io.EOF)
+		}
+		return n, nil
+	}
+}
+
+func (r *splitReader) finish(err error) {
+	r.err = err
+	r.current = chunk{}
+	r.source.close()
+}
+
+func (r *splitReader) Close() error {
+	if r.err == nil {
+		r.finish(io.EOF)
+	}
+	return nil
+}
+
+// plainSource reads a plain file from the offset.
+type plainSource struct {
+	f      File
+	offset int64
+}
+
+func newPlainSource(f File, offset int64) chunkSource {
+	return &plainSource{f: f, offset: offset}
+}
+
+func (s *plainSource) next() (chunk, error) {
+	if s.offset >= s.f.Size() {
+		return chunk{}, io.EOF
+	}
+	data := make([]byte, chunkSize)
+	n, err := s.f.ReadAt(data, s.offset)
+	if n == 0 && err != nil {
+		return chunk{}, err
+	}
+	c := chunk{offset: s.offset, data: data[:n], perByte: true}
+	s.offset += int64(n)
+	return c, nil
+}
+
+func (s *plainSource) close() {
+}
+
+type blockResult struct {
+	chunk
+	err error
+}
+
+// blockSource decompresses the blocks on all cpus, and returns them in the
+// file order.
+type blockSource struct {
+	results   chan chan blockResult
+	done      chan bool
+	closeOnce sync.Once
+}
+
+func newBlockSource(
+	read func() (int64, []byte, error),
+	decompress func([]byte) ([]byte, error),
+) chunkSource {
+
+	s := &blockSource{
+		results: make(chan chan blockResult, runtime.NumCPU()),
+		done:    make(chan bool),
+	}
+
+	go func() {
+		defer close(s.results)
+		for {
+			result := make(chan blockResult, 1)
+			offset, block, err := read()
+			if err == io.EOF {
+				return
+			}
+			select {
+			case s.results <- result:
+			case <-s.done:
+				return
+			}
+			if err != nil {
+				result <- blockResult{err: err}
+				return
+			}
+			go func() {
+				data, err := decompress(block)
+				result <- blockResult{chunk{offset: offset, data: data}, err}
+			}()
+		}
+	}()
+
+	return s
+}
+
+func (s *blockSource) next() (chunk, error) {
+	result, ok := <-s.results
+	if !ok {
+		return chunk{}, io.EOF
+	}
+	r := <-result
+	return r.chunk, r.err
+}
+
+func (s *blockSource) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// readAhead reads from the reader in the background, e.g. to decompress a
+// stream on another cpu than the one parsing it. The reader is closed at its
+// end if it is a io.Closer.
+func readAhead(reader io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w := bufio.NewWriterSize(pw, chunkSize)
+		_, err := io.Copy(w, reader)
+		if err == nil {
+			err = w.Flush()
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			closer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
@@ -0,0 +1,149 @@
+package split
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func newBytesFile(data []byte) File {
+	return bytesFile{bytes.NewReader(data)}
+}
+
+func testLines(count int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&buf, "line %d %s\n", i, bytes.Repeat([]byte{'x'}, i%37))
+	}
+	return buf.Bytes()
+}
+
+// bgzip compresses the data into bgzf blocks of up to blockSize bytes of data.
+func bgzip(t *testing.T, data []byte, blockSize int) []byte {
+	var out bytes.Buffer
+	for len(data) > 0 {
+		n := blockSize
+		if n > len(data) {
+			n = len(data)
+		}
+		var block bytes.Buffer
+		w := gzip.NewWriter(&block)
+		w.Header.Extra = []byte{'B', 'C', 2, 0, 0, 0}
+		w.Write(data[:n])
+		if err := w.Close(); err != nil {
+			t.Fatalf("compress: %v", err)
+		}
+		b := block.Bytes()
+		binary.LittleEndian.PutUint16(b[16:18], uint16(len(b)-1))
+		out.Write(b)
+		data = data[n:]
+	}
+	return out.Bytes()
+}
+
+func readSplits(t *testing.T, f File, fileName string, splitSize int64) []byte {
+	ranges, err := Plan(f, fileName, splitSize)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(ranges) < 2 {
+		t.Fatalf("planned %d splits", len(ranges))
+	}
+	var all []byte
+	for _, r := range ranges {
+		reader, err := NewReader(f, fileName, r.Start, r.Stop)
+		if err != nil {
+			t.Fatalf("open split %+v: %v", r, err)
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("read split %+v: %v", r, err)
+		}
+		all = append(all, data...)
+	}
+	return all
+}
+
+func TestPlainSplits(t *testing.T) {
+	data := testLines(1000)
+	for _, splitSize := range []int64{3, 100, 4096} {
+		if got := readSplits(t, newBytesFile(data), "a.txt", splitSize); !bytes.Equal(got, data) {
+			t.Errorf("split size %d: read %d bytes, expected %d", splitSize, len(got), len(data))
+		}
+	}
+}
+
+func TestBgzfSplits(t *testing.T) {
+	data := testLines(5000)
+	compressed := bgzip(t, data, 1000)
+	for _, splitSize := range []int64{50, 300, 4096} {
+		if got := readSplits(t, newBytesFile(compressed), "a.gz", splitSize); !bytes.Equal(got, data) {
+			t.Errorf("split size %d: read %d bytes, expected %d", splitSize, len(got), len(data))
+		}
+	}
+
+	reader, err := NewDecompressingReader(newBytesFile(compressed), "a.gz")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(reader); !bytes.Equal(got, data) {
+		t.Errorf("read %d bytes, expected %d", len(got), len(data))
+	}
+}
+
+// zstdSeekable compresses the data into frames of up to frameSize bytes of
+// data, followed by their seek table.
+func zstdSeekable(t *testing.T, data []byte, frameSize int) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("encoder: %v", err)
+	}
+	var out, table bytes.Buffer
+	frameCount := 0
+	for len(data) > 0 {
+		n := frameSize
+		if n > len(data) {
+			n = len(data)
+		}
+		frame := encoder.EncodeAll(data[:n], nil)
+		out.Write(frame)
+		binary.Write(&table, binary.LittleEndian, uint32(len(frame)))
+		binary.Write(&table, binary.LittleEndian, uint32(n))
+		frameCount++
+		data = data[n:]
+	}
+	binary.Write(&out, binary.LittleEndian, uint32(zstdSkippableMagic))
+	binary.Write(&out, binary.LittleEndian, uint32(table.Len()+zstdFooterSize))
+	out.Write(table.Bytes())
+	binary.Write(&out, binary.LittleEndian, uint32(frameCount))
+	out.WriteByte(0)
+	binary.Write(&out, binary.LittleEndian, uint32(zstdSeekableMagic))
+	return out.Bytes()
+}
+
+func TestZstdSeekableSplits(t *testing.T) {
+	data := testLines(5000)
+	compressed := zstdSeekable(t, data, 1000)
+	for _, splitSize := range []int64{50, 300, 4096} {
+		if got := readSplits(t, newBytesFile(compressed), "a.zst", splitSize); !bytes.Equal(got, data) {
+			t.Errorf("split size %d: read %d bytes, expected %d", splitSize, len(got), len(data))
+		}
+	}
+
+	reader, err := NewDecompressingReader(newBytesFile(compressed), "a.zst")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(reader); !bytes.Equal(got, data) {
+		t.Errorf("read %d bytes, expected %d", len(got), len(data))
+	}
+}
@@ -0,0 +1,116 @@
+package split
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	zstdSkippableMagic = 0x184D2A5E
+	zstdSeekableMagic  = 0x8F92EAB1
+	zstdFooterSize     = 9
+)
+
+var errNotSeekable = errors.New("zstd file without a seek table")
+
+// zstdDecoder decompresses the frames of all seekable files, concurrently.
+var zstdDecoder, _ = zstd.NewReader(nil)
+
+// zstdFrame is an independently compressed frame of a seekable zstd file.
+type zstdFrame struct {
+	offset int64
+	size   int64
+}
+
+// readSeekTable returns the frames listed in the seek table at the end of a
+// seekable zstd file, or nil if the file has no seek table.
+func readSeekTable(f File) ([]zstdFrame, error) {
+	if f.Size() < zstdFooterSize+8 {
+		return nil, nil
+	}
+	footer := make([]byte, zstdFooterSize)
+	if _, err := f.ReadAt(footer, f.Size()-zstdFooterSize); err != nil {
+		return nil, fmt.Errorf("Failed to read seek table footer: %v", err)
+	}
+	if binary.LittleEndian.Uint32(footer[5:9]) != zstdSeekableMagic {
+		return nil, nil
+	}
+	frameCount := int64(binary.LittleEndian.Uint32(footer[0:4]))
+	entrySize := int64(8)
+	if footer[4]&0x80 != 0 {
+		// with checksums
+		entrySize = 12
+	}
+
+	tableSize := frameCount * entrySize
+	tableStart := f.Size() - zstdFooterSize - tableSize
+	if tableStart < 8 {
+		return nil, fmt.Errorf("Invalid seek table of %d frames", frameCount)
+	}
+	table := make([]byte, 8+tableSize)
+	if _, err := f.ReadAt(table, tableStart-8); err != nil {
+		return nil, fmt.Errorf("Failed to read seek table: %v", err)
+	}
+	if binary.LittleEndian.Uint32(table[0:4]) != zstdSkippableMagic {
+		return nil, fmt.Errorf("Invalid seek table frame")
+	}
+
+	var frames []zstdFrame
+	var offset int64
+	for entry := table[8:]; len(entry) >= int(entrySize); entry = entry[entrySize:] {
+		size := int64(binary.LittleEndian.Uint32(entry[0:4]))
+		frames = append(frames, zstdFrame{offset: offset, size: size})
+		offset += size
+	}
+	return frames, nil
+}
+
+// frameRanges groups the frames into ranges of at least splitSize bytes.
+func frameRanges(frames []zstdFrame, splitSize int64) (ranges []Range) {
+	var current Range
+	for _, frame := range frames {
+		current.Stop = frame.offset + frame.size
+		if current.Stop-current.Start >= splitSize {
+			ranges = append(ranges, current)
+			current = Range{Start: current.Stop}
+		}
+	}
+	if current.Stop > current.Start {
+		ranges = append(ranges, current)
+	}
+	return ranges
+}
+
+// newZstdSource decompresses the frames from the offset in parallel.
+func newZstdSource(f File, frames []zstdFrame, offset int64) chunkSource {
+	for len(frames) > 0 && frames[0].offset < offset {
+		frames = frames[1:]
+	}
+	return newBlockSource(func() (int64, []byte, error) {
+		if len(frames) == 0 {
+			return 0, nil, io.EOF
+		}
+		frame := frames[0]
+		frames = frames[1:]
+		data := make([]byte, frame.size)
+		if _, err := f.ReadAt(data, frame.offset); err != nil {
+			return 0, nil, fmt.Errorf("Failed to read frame at %d: %v", frame.offset, err)
+		}
+		return frame.offset, data, nil
+	}, func(frame []byte) ([]byte, error) {
+		return zstdDecoder.DecodeAll(frame, nil)
+	})
+}
+
+// newZstdReader decompresses a zstd file without a seek table ahead of the reads.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return readAhead(decoder.IOReadCloser()), nil
+}
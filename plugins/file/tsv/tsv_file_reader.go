@@ -2,6 +2,7 @@ package tsv
 
 import (
 	"bufio"
+	"bytes"
 	"io"
 	"strings"
 
@@ -10,6 +11,22 @@ import (
 
 type TsvFileReader struct {
 	scanner *bufio.Scanner
+	// reader and options read the fields in the text format of MySQL's
+	// LOAD DATA INFILE, if set by NewWithOptions().
+	reader  *bufio.Reader
+	options Options
+}
+
+// Options are the FIELDS options of MySQL's LOAD DATA INFILE.
+type Options struct {
+	// Delimiter separates the fields, '\t' by default.
+	Delimiter byte
+	// Enclosed optionally quotes the fields, and is doubled or escaped
+	// within them. Unquoted NULL fields are read as nil. 0 to not quote.
+	Enclosed byte
+	// Escaped escapes the special characters, e.g. \t, \n, the delimiter,
+	// and a line break within a field. \N is read as nil. 0 to not escape.
+	Escaped byte
 }
 
 func New(reader io.Reader) *TsvFileReader {
@@ -18,16 +35,45 @@ func New(reader io.Reader) *TsvFileReader {
 	}
 }
 
+// NewWithOptions reads the lines of the fields written like MySQL's
+// SELECT ... INTO OUTFILE, the format of LOAD DATA INFILE.
+func NewWithOptions(reader io.Reader, options Options) *TsvFileReader {
+	if options.Delimiter == 0 {
+		options.Delimiter = '\t'
+	}
+	return &TsvFileReader{
+		reader:  bufio.NewReader(reader),
+		options: options,
+	}
+}
+
 func (r *TsvFileReader) ReadHeader() (fieldNames []string, err error) {
+	if r.reader != nil {
+		values, err := r.readFields()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			s, _ := v.(string)
+			fieldNames = append(fieldNames, s)
+		}
+		return fieldNames, nil
+	}
 	return r.readOneLine()
 }
 func (r *TsvFileReader) Read() (row *util.Row, err error) {
+	var data []interface{}
+	if r.reader != nil {
+		if data, err = r.readFields(); err != nil {
+			return nil, err
+		}
+		return util.NewRow(util.Now(), data...), nil
+	}
 	var values []string
 	values, err = r.readOneLine()
 	if err != nil {
 		return nil, err
 	}
-	var data []interface{}
 	for _, v := range values {
 		data = append(data, v)
 	}
@@ -47,3 +93,94 @@ func (r *TsvFileReader) readOneLine() (values []string, err error) {
 	}
 	return strings.Split(string(data), "\t"), nil
 }
+
+// readFields reads the fields of one line, which can span several lines if
+// the line breaks are escaped or enclosed.
+func (r *TsvFileReader) readFields() (values []interface{}, err error) {
+	var field bytes.Buffer
+	isNull, isQuoted, inQuotes, hasData := false, false, false, false
+
+	endField := func() {
+		switch {
+		case isNull:
+			values = append(values, nil)
+		case r.options.Enclosed != 0 && !isQuoted && field.String() == "NULL":
+			values = append(values, nil)
+		default:
+			values = append(values, field.String())
+		}
+		field.Reset()
+		isNull, isQuoted = false, false
+	}
+
+	for {
+		c, err := r.reader.ReadByte()
+		if err == io.EOF {
+			if !hasData {
+				return nil, io.EOF
+			}
+			endField()
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		hasData = true
+
+		switch {
+		case r.options.Escaped != 0 && c == r.options.Escaped:
+			next, err := r.reader.ReadByte()
+			if err == io.EOF {
+				field.WriteByte(c)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if next == 'N' && !inQuotes && field.Len() == 0 {
+				isNull = true
+				continue
+			}
+			field.WriteByte(unescape(next))
+		case inQuotes:
+			if c != r.options.Enclosed {
+				field.WriteByte(c)
+				continue
+			}
+			// a doubled quote is a quote
+			if next, err := r.reader.Peek(1); err == nil && next[0] == r.options.Enclosed {
+				r.reader.ReadByte()
+				field.WriteByte(c)
+				continue
+			}
+			inQuotes = false
+		case r.options.Enclosed != 0 && c == r.options.Enclosed && field.Len() == 0 && !isQuoted:
+			inQuotes, isQuoted = true, true
+		case c == r.options.Delimiter:
+			endField()
+		case c == '\n':
+			endField()
+			return values, nil
+		default:
+			field.WriteByte(c)
+		}
+	}
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 0x1a
+	}
+	return c
+}
@@ -0,0 +1,58 @@
+package tsv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadWithOptions(t *testing.T) {
+	tests := []struct {
+		options Options
+		text    string
+		rows    [][]interface{}
+	}{
+		{
+			options: Options{Escaped: '\\'},
+			text:    "a\\tb\tc\\\\d\n\\N\tline\\\nbreak\n",
+			rows: [][]interface{}{
+				{"a\tb", "c\\d"},
+				{nil, "line\nbreak"},
+			},
+		},
+		{
+			options: Options{Delimiter: ',', Enclosed: '"', Escaped: '\\'},
+			text:    "\"a,b\",\"say \"\"hi\"\"\",NULL,\"NULL\"\n\"x\\\"y\",\"multi\nline\"\n",
+			rows: [][]interface{}{
+				{"a,b", "say \"hi\"", nil, "NULL"},
+				{"x\"y", "multi\nline"},
+			},
+		},
+		{
+			options: Options{Delimiter: ','},
+			text:    "a\\,b,\\N",
+			rows: [][]interface{}{
+				{"a\\", "b", "\\N"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		reader := NewWithOptions(strings.NewReader(test.text), test.options)
+		var rows [][]interface{}
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read %q: %v", test.text, err)
+			}
+			rows = append(rows, append(row.K, row.V...))
+		}
+		if !reflect.DeepEqual(rows, test.rows) {
+			t.Errorf("read %q: expected %q, got %q", test.text, test.rows, rows)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	gogrpc "google.golang.org/grpc"
+)
+
+// GrpcSink is a flow.Sink pushing the rows of each task in a stream to the
+// pb.GleamRowStream service at the address, e.g. a GrpcSource of another
+// flow. The rows are pushed in batches while the task runs, so the receiver
+// should discard the rows of a failed stream if it needs exactly once.
+// With a registry, the task i pushes to the shard i modulo the number of
+// shards registered by a GrpcSource.
+type GrpcSink struct {
+	Address   string
+	Registry  string
+	BatchSize int
+}
+
+func init() {
+	gob.Register(&GrpcSink{})
+}
+
+func NewSink(address string) *GrpcSink {
+	return &GrpcSink{
+		Address:   address,
+		BatchSize: 1024,
+	}
+}
+
+// NewRegisteredSink pushes to the shards of the GrpcSource with the registry.
+func NewRegisteredSink(registry string) *GrpcSink {
+	return &GrpcSink{
+		Registry:  registry,
+		BatchSize: 1024,
+	}
+}
+
+// SetBatchSize sets the number of rows sent in one message.
+func (s *GrpcSink) SetBatchSize(batchSize int) *GrpcSink {
+	s.BatchSize = batchSize
+	return s
+}
+
+func (s *GrpcSink) OpenTask(taskId int) (flow.TaskWriter, error) {
+	address, err := s.address(taskId)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := gogrpc.Dial(address, gogrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dial %s: %v", address, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewGleamRowStreamClient(conn).Push(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, fmt.Errorf("Failed to push to %s: %v", address, err)
+	}
+	return &rowPusher{
+		sink:    s,
+		address: address,
+		conn:    conn,
+		cancel:  cancel,
+		stream:  stream,
+	}, nil
+}
+
+// address returns the address of the shard the task pushes to.
+func (s *GrpcSink) address(taskId int) (string, error) {
+	if s.Registry == "" {
+		return s.Address, nil
+	}
+	files, err := filesystem.List(s.Registry)
+	if err != nil {
+		return "", fmt.Errorf("Failed to list registry %s: %v", s.Registry, err)
+	}
+	shardCount := 0
+	for _, file := range files {
+		if strings.HasPrefix(path.Base(file.Location), "shard-") {
+			shardCount++
+		}
+	}
+	if shardCount == 0 {
+		return "", fmt.Errorf("No shards registered in %s", s.Registry)
+	}
+	fileName := registryFile(s.Registry, taskId%shardCount)
+	vf, err := filesystem.Open(fileName)
+	if err != nil {
+		return "", fmt.Errorf("Failed to open %s: %v", fileName, err)
+	}
+	defer vf.Close()
+	data, err := ioutil.ReadAll(vf)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read %s: %v", fileName, err)
+	}
+	return string(data), nil
+}
+
+type rowPusher struct {
+	address string
+	sink    *GrpcSink
+	conn    *gogrpc.ClientConn
+	cancel  context.CancelFunc
+	stream  pb.GleamRowStream_PushClient
+	batch   pb.RowBatch
+}
+
+func (p *rowPusher) Write(row *util.Row) error {
+	data, err := row.MarshalMsg(nil)
+	if err != nil {
+		return fmt.Errorf("Failed to encode row: %v", err)
+	}
+	p.batch.Rows = append(p.batch.Rows, data)
+	if len(p.batch.Rows) >= p.sink.BatchSize {
+		return p.flush()
+	}
+	return nil
+}
+
+func (p *rowPusher) flush() error {
+	if len(p.batch.Rows) == 0 {
+		return nil
+	}
+	if err := p.stream.Send(&p.batch); err != nil {
+		return fmt.Errorf("Failed to push to %s: %v", p.address, err)
+	}
+	p.batch.Rows = nil
+	return nil
+}
+
+func (p *rowPusher) CommitTask() error {
+	defer p.conn.Close()
+	defer p.cancel()
+	if err := p.flush(); err != nil {
+		return err
+	}
+	if _, err := p.stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("Failed to close the push to %s: %v", p.address, err)
+	}
+	return nil
+}
+
+// AbortTask cancels the stream, so the receiver gets an error instead of
+// the end of the stream.
+func (p *rowPusher) AbortTask() error {
+	p.cancel()
+	return p.conn.Close()
+}
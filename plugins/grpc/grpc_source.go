@@ -0,0 +1,228 @@
+// Package grpc reads rows pushed to a gRPC endpoint by other flows or
+// services, and pushes rows to a downstream gRPC service, both with the
+// pb.GleamRowStream service, to compose flows with services without files
+// or message queues in between.
+package grpc
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+	gogrpc "google.golang.org/grpc"
+)
+
+// GrpcSource is a flow.Source of the rows pushed to the shards. The shard i
+// listens on the port of the address plus i, or on an ephemeral port if the
+// port is 0, on the executor reading it. With a registry, each shard writes
+// the address it can be reached at to the file "shard-<i>" in the registry
+// directory, where a GrpcSink finds it.
+type GrpcSource struct {
+	Address  string
+	Registry string
+	Shards   int
+	// Streams is the number of push streams a shard receives before it
+	// completes, or 0 to read until the flow is stopped.
+	Streams int
+}
+
+func init() {
+	gob.Register(&GrpcSource{})
+}
+
+// NewSource reads the rows pushed to the address, e.g. ":45326".
+func NewSource(address string) *GrpcSource {
+	return &GrpcSource{
+		Address: address,
+		Shards:  1,
+	}
+}
+
+// SetShards listens on the number of consecutive ports, one for each shard.
+func (s *GrpcSource) SetShards(count int) *GrpcSource {
+	s.Shards = count
+	return s
+}
+
+// SetStreams completes each shard after it received the number of push
+// streams, e.g. one from each task of an upstream flow.
+func (s *GrpcSource) SetStreams(count int) *GrpcSource {
+	s.Streams = count
+	return s
+}
+
+// SetRegistry writes the addresses of the shards to the directory, on any
+// file system, since the executors reading the shards are only known when
+// the flow runs. The files of a previous run are overwritten.
+func (s *GrpcSource) SetRegistry(dir string) *GrpcSource {
+	s.Registry = dir
+	return s
+}
+
+func (s *GrpcSource) ShardCount() (int, error) {
+	return s.Shards, nil
+}
+
+func (s *GrpcSource) OpenShard(shardIndex int) (flow.RowIterator, error) {
+	host, port, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse address %s: %v", s.Address, err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse port of %s: %v", s.Address, err)
+	}
+	if portNumber != 0 {
+		portNumber += shardIndex
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(portNumber))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on %s: %v", address, err)
+	}
+	log.Printf("gRPC source shard %d listens on %s", shardIndex, listener.Addr())
+
+	if s.Registry != "" {
+		if err := s.register(shardIndex, host, listener.Addr().(*net.TCPAddr).Port); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+
+	r := &rowReceiver{
+		streams: s.Streams,
+		rows:    make(chan *util.Row, 1024),
+		done:    make(chan struct{}),
+		server:  gogrpc.NewServer(),
+	}
+	pb.RegisterGleamRowStreamServer(r.server, r)
+	go r.server.Serve(listener)
+	return r, nil
+}
+
+// register writes the address of the shard, with the host name of the
+// executor if the source listens on all interfaces.
+func (s *GrpcSource) register(shardIndex int, host string, port int) error {
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("Failed to get the host name: %v", err)
+		}
+		host = hostname
+	}
+	fileName := registryFile(s.Registry, shardIndex)
+	w, err := filesystem.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("Failed to register shard %d in %s: %v", shardIndex, fileName, err)
+	}
+	if _, err := io.WriteString(w, net.JoinHostPort(host, strconv.Itoa(port))); err != nil {
+		w.Close()
+		return fmt.Errorf("Failed to register shard %d in %s: %v", shardIndex, fileName, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("Failed to register shard %d in %s: %v", shardIndex, fileName, err)
+	}
+	return nil
+}
+
+func registryFile(registry string, shardIndex int) string {
+	return fmt.Sprintf("%s/shard-%d", strings.TrimSuffix(registry, "/"), shardIndex)
+}
+
+// rowReceiver is the server of a shard, and iterates the pushed rows.
+type rowReceiver struct {
+	streams  int
+	rows     chan *util.Row
+	server   *gogrpc.Server
+	lock     sync.Mutex
+	finished int
+	err      error
+	// done is closed after the last stream, or the first failed one
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (r *rowReceiver) Push(stream pb.GleamRowStream_PushServer) error {
+	err := r.receive(stream)
+	r.finish(err)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.Empty{})
+}
+
+func (r *rowReceiver) receive(stream pb.GleamRowStream_PushServer) error {
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, data := range batch.Rows {
+			row, err := util.DecodeRow(data)
+			if err != nil {
+				return fmt.Errorf("Failed to decode row: %v", err)
+			}
+			select {
+			case r.rows <- row:
+			case <-r.done:
+				return fmt.Errorf("The shard is finished")
+			}
+		}
+	}
+}
+
+// finish counts the finished stream. The shard fails with the first failed
+// stream, since the rows it pushed are already read.
+func (r *rowReceiver) finish(err error) {
+	r.lock.Lock()
+	r.finished++
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("Failed to receive pushed rows: %v", err)
+	}
+	isLast := r.err != nil || r.streams > 0 && r.finished == r.streams
+	r.lock.Unlock()
+	if isLast {
+		r.doneOnce.Do(func() {
+			close(r.done)
+			// stop after the other pushes are finished
+			go r.server.GracefulStop()
+		})
+	}
+}
+
+func (r *rowReceiver) Next() (*util.Row, error) {
+	select {
+	case row := <-r.rows:
+		return row, nil
+	case <-r.done:
+	}
+	select {
+	case row := <-r.rows:
+		return row, nil
+	default:
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return nil, io.EOF
+}
+
+func (r *rowReceiver) Close() error {
+	r.server.Stop()
+	return nil
+}
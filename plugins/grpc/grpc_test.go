@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+func openTestShard(t *testing.T, streams int) (flow.RowIterator, string) {
+	registry, err := ioutil.TempDir("", "gleam_grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := NewSource("127.0.0.1:0").SetStreams(streams).SetRegistry(registry)
+	iterator, err := source.OpenShard(1)
+	if err != nil {
+		os.RemoveAll(registry)
+		t.Fatalf("open shard: %v", err)
+	}
+	return iterator, registry
+}
+
+func readAll(iterator flow.RowIterator) (count int, err error) {
+	for {
+		_, err := iterator.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+func TestPushRows(t *testing.T) {
+	iterator, registry := openTestShard(t, 2)
+	defer os.RemoveAll(registry)
+	defer iterator.Close()
+
+	// the shard 1 is the only one registered, so every task pushes to it
+	os.Rename(registryFile(registry, 1), registryFile(registry, 0))
+
+	sink := NewRegisteredSink(registry).SetBatchSize(3)
+	committed := make(chan error, 2)
+	for taskId := 0; taskId < 2; taskId++ {
+		task, err := sink.OpenTask(taskId)
+		if err != nil {
+			t.Fatalf("open task %d: %v", taskId, err)
+		}
+		for i := 0; i < 5; i++ {
+			if err := task.Write(util.NewRow(util.Now(), taskId, i)); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		go func() { committed <- task.CommitTask() }()
+	}
+
+	count, err := readAll(iterator)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if count != 10 {
+		t.Errorf("received %d rows, expected 10", count)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-committed; err != nil {
+			t.Errorf("commit: %v", err)
+		}
+	}
+}
+
+func TestAbortedPush(t *testing.T) {
+	iterator, registry := openTestShard(t, 2)
+	defer os.RemoveAll(registry)
+	defer iterator.Close()
+	os.Rename(registryFile(registry, 1), registryFile(registry, 0))
+
+	task, err := NewRegisteredSink(registry).SetBatchSize(1).OpenTask(0)
+	if err != nil {
+		t.Fatalf("open task: %v", err)
+	}
+	if err := task.Write(util.NewRow(util.Now(), 1)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := task.AbortTask(); err != nil {
+		t.Fatalf("abort: %v", err)
+	}
+
+	// the shard fails without waiting for the second stream
+	if _, err := readAll(iterator); err == nil {
+		t.Errorf("read the rows of an aborted stream without an error")
+	}
+}
+
+func TestNoRegisteredShard(t *testing.T) {
+	registry, err := ioutil.TempDir("", "gleam_grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(registry)
+	if _, err := NewRegisteredSink(registry).OpenTask(0); err == nil {
+		t.Errorf("opened a task without any registered shard")
+	}
+}
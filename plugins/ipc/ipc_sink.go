@@ -0,0 +1,140 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+// IpcSink is a flow.Sink writing each task to a connection of the unix
+// domain socket, or to the named pipe. The tasks writing to one pipe
+// interleave their output, so write a pipe from one partition. Run the
+// tasks on the machine with the socket or the pipe with OnAgents().
+type IpcSink struct {
+	Network string
+	Path    string
+	Format  string
+	// NodeSelector is the labels of the agents to run the tasks on.
+	NodeSelector []string
+}
+
+func init() {
+	gob.Register(&IpcSink{})
+}
+
+// UnixSink connects each task to the unix domain socket.
+func UnixSink(path string) *IpcSink {
+	return &IpcSink{
+		Network: unixNetwork,
+		Path:    path,
+		Format:  Tsv,
+	}
+}
+
+// PipeSink writes to the named pipe, after a reader opened it. It creates
+// the pipe if it does not exist.
+func PipeSink(path string) *IpcSink {
+	return &IpcSink{
+		Network: pipeNetwork,
+		Path:    path,
+		Format:  Tsv,
+	}
+}
+
+// SetFormat sets the format, Tsv or Rows.
+func (s *IpcSink) SetFormat(format string) *IpcSink {
+	s.Format = format
+	return s
+}
+
+// OnAgents runs the tasks only on the agents with all the labels, as set
+// by the agent's --labels option.
+func (s *IpcSink) OnAgents(labels ...string) *IpcSink {
+	s.NodeSelector = append(s.NodeSelector, labels...)
+	return s
+}
+
+// Save writes the dataset on the agents selected by OnAgents().
+func (s *IpcSink) Save(d *flow.Dataset) *flow.Dataset {
+	return d.WriteSink("ipc", s).Hint(flow.NodeSelector(s.NodeSelector...))
+}
+
+func (s *IpcSink) OpenTask(taskId int) (flow.TaskWriter, error) {
+	var w io.WriteCloser
+	if s.Network == pipeNetwork {
+		if err := mkfifo(s.Path); err != nil {
+			return nil, fmt.Errorf("Failed to create pipe %s: %v", s.Path, err)
+		}
+		// blocks until the pipe is opened for reading
+		f, err := os.OpenFile(s.Path, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open pipe %s: %v", s.Path, err)
+		}
+		w = f
+	} else {
+		conn, err := net.Dial(unixNetwork, s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to connect to %s: %v", s.Path, err)
+		}
+		w = conn
+	}
+	return &ipcWriter{
+		format: s.Format,
+		writer: bufio.NewWriter(w),
+		closer: w,
+	}, nil
+}
+
+type ipcWriter struct {
+	format string
+	writer *bufio.Writer
+	closer io.Closer
+}
+
+func (w *ipcWriter) Write(row *util.Row) error {
+	if w.format == Rows {
+		return row.WriteTo(w.writer)
+	}
+	values := append(append([]interface{}{}, row.K...), row.V...)
+	for i, value := range values {
+		if i > 0 {
+			if err := w.writer.WriteByte('\t'); err != nil {
+				return fmt.Errorf("Failed to write: %v", err)
+			}
+		}
+		var err error
+		switch v := value.(type) {
+		case []byte:
+			_, err = w.writer.Write(v)
+		case nil:
+		default:
+			_, err = fmt.Fprint(w.writer, v)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to write: %v", err)
+		}
+	}
+	if err := w.writer.WriteByte('\n'); err != nil {
+		return fmt.Errorf("Failed to write: %v", err)
+	}
+	return nil
+}
+
+func (w *ipcWriter) CommitTask() error {
+	if err := w.writer.Flush(); err != nil {
+		w.closer.Close()
+		return fmt.Errorf("Failed to flush: %v", err)
+	}
+	return w.closer.Close()
+}
+
+// AbortTask closes the connection without flushing the buffered rows.
+func (w *ipcWriter) AbortTask() error {
+	return w.closer.Close()
+}
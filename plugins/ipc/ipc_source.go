@@ -0,0 +1,259 @@
+// Package ipc reads and writes rows through unix domain sockets and named
+// pipes, to plug flows into the process pipelines of one machine without
+// temporary files.
+//
+// The data are either tab separated lines, or the msgpack encoded rows
+// of gleam, to connect flows with each other.
+package ipc
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+const (
+	// Tsv is the format of tab separated lines.
+	Tsv = "tsv"
+	// Rows is the format of msgpack encoded rows, as written by flows.
+	Rows = "rows"
+)
+
+const (
+	unixNetwork = "unix"
+	pipeNetwork = "pipe"
+)
+
+// IpcSource is a flow.Source of one shard, read on the executor of the
+// machine with the socket or the pipe. Run it on that machine with
+// OnAgents(), e.g. with the agent label "host=<name>".
+type IpcSource struct {
+	Network string
+	Path    string
+	Format  string
+	// Connections is the number of socket connections to read before the
+	// shard completes, or 0 to read until the flow is stopped.
+	Connections int
+	// NodeSelector is the labels of the agents to read the shard on.
+	NodeSelector []string
+}
+
+func init() {
+	gob.Register(&IpcSource{})
+}
+
+// UnixSource listens on the unix domain socket, and reads the connections
+// in parallel.
+func UnixSource(path string) *IpcSource {
+	return &IpcSource{
+		Network:     unixNetwork,
+		Path:        path,
+		Format:      Tsv,
+		Connections: 1,
+	}
+}
+
+// PipeSource reads the named pipe until all its writers close it. It
+// creates the pipe if it does not exist.
+func PipeSource(path string) *IpcSource {
+	return &IpcSource{
+		Network: pipeNetwork,
+		Path:    path,
+		Format:  Tsv,
+	}
+}
+
+// SetFormat sets the format, Tsv or Rows.
+func (s *IpcSource) SetFormat(format string) *IpcSource {
+	s.Format = format
+	return s
+}
+
+// SetConnections sets the number of socket connections to read.
+func (s *IpcSource) SetConnections(count int) *IpcSource {
+	s.Connections = count
+	return s
+}
+
+// OnAgents reads the shard only on the agents with all the labels, as set
+// by the agent's --labels option.
+func (s *IpcSource) OnAgents(labels ...string) *IpcSource {
+	s.NodeSelector = append(s.NodeSelector, labels...)
+	return s
+}
+
+// Generate reads the shard on the agents selected by OnAgents().
+func (s *IpcSource) Generate(f *flow.Flow) *flow.Dataset {
+	return f.ReadSource("ipc", s, 1).Hint(flow.NodeSelector(s.NodeSelector...))
+}
+
+func (s *IpcSource) ShardCount() (int, error) {
+	return 1, nil
+}
+
+func (s *IpcSource) OpenShard(shardIndex int) (flow.RowIterator, error) {
+	r := &ipcReader{
+		format: s.Format,
+		items:  make(chan rowOrError, 1024),
+		done:   make(chan struct{}),
+		conns:  make(map[io.Closer]bool),
+	}
+
+	if s.Network == pipeNetwork {
+		if err := mkfifo(s.Path); err != nil {
+			return nil, fmt.Errorf("Failed to create pipe %s: %v", s.Path, err)
+		}
+		// blocks until the pipe is opened for writing
+		f, err := os.Open(s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open pipe %s: %v", s.Path, err)
+		}
+		r.closer = f
+		go func() {
+			r.read(f)
+			close(r.items)
+		}()
+		return r, nil
+	}
+
+	// remove the socket left by a previous run
+	os.Remove(s.Path)
+	listener, err := net.Listen(unixNetwork, s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on %s: %v", s.Path, err)
+	}
+	r.closer = listener
+	go r.accept(listener, s.Connections)
+	return r, nil
+}
+
+type rowOrError struct {
+	row *util.Row
+	err error
+}
+
+// ipcReader reads the rows of the pipe or the socket connections. Closing
+// it stops the goroutines reading the connections, even if the rows are not
+// all read.
+type ipcReader struct {
+	format    string
+	items     chan rowOrError
+	closer    io.Closer
+	done      chan struct{}
+	closeOnce sync.Once
+	lock      sync.Mutex
+	conns     map[io.Closer]bool
+}
+
+func (r *ipcReader) accept(listener net.Listener, connections int) {
+	var wg sync.WaitGroup
+	for i := 0; connections <= 0 || i < connections; i++ {
+		conn, err := listener.Accept()
+		if err != nil {
+			r.send(rowOrError{err: fmt.Errorf("Failed to accept: %v", err)})
+			break
+		}
+		if !r.track(conn) {
+			conn.Close()
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.untrack(conn)
+			r.read(conn)
+		}()
+	}
+	wg.Wait()
+	close(r.items)
+}
+
+// track remembers the connection to close it with the reader, unless the
+// reader is already closed.
+func (r *ipcReader) track(conn io.Closer) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.conns == nil {
+		return false
+	}
+	r.conns[conn] = true
+	return true
+}
+
+func (r *ipcReader) untrack(conn io.Closer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.conns, conn)
+	conn.Close()
+}
+
+// send passes the row or the error to Next(), and returns false once the
+// reader is closed.
+func (r *ipcReader) send(item rowOrError) bool {
+	select {
+	case r.items <- item:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+func (r *ipcReader) read(reader io.Reader) {
+	var err error
+	if r.format == Rows {
+		err = util.ProcessMessage(reader, func(data []byte) error {
+			row, err := util.DecodeRow(data)
+			if err != nil {
+				return fmt.Errorf("Failed to decode row: %v", err)
+			}
+			if !r.send(rowOrError{row: row}) {
+				return errClosed
+			}
+			return nil
+		})
+	} else {
+		err = util.TakeTsv(reader, -1, func(message []string) error {
+			var values []interface{}
+			for _, m := range message {
+				values = append(values, m)
+			}
+			if !r.send(rowOrError{row: util.NewRow(util.Now(), values...)}) {
+				return errClosed
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		r.send(rowOrError{err: err})
+	}
+}
+
+var errClosed = fmt.Errorf("The reader is closed")
+
+func (r *ipcReader) Next() (*util.Row, error) {
+	item, ok := <-r.items
+	if !ok {
+		return nil, io.EOF
+	}
+	return item.row, item.err
+}
+
+func (r *ipcReader) Close() (err error) {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		err = r.closer.Close()
+		r.lock.Lock()
+		for conn := range r.conns {
+			conn.Close()
+		}
+		r.conns = nil
+		r.lock.Unlock()
+	})
+	return err
+}
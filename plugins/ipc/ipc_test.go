@@ -0,0 +1,135 @@
+package ipc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rows.sock")
+
+	for _, format := range []string{Tsv, Rows} {
+		iterator, err := UnixSource(path).SetFormat(format).SetConnections(2).OpenShard(0)
+		if err != nil {
+			t.Fatalf("open shard: %v", err)
+		}
+
+		for taskId := 0; taskId < 2; taskId++ {
+			task, err := UnixSink(path).SetFormat(format).OpenTask(taskId)
+			if err != nil {
+				t.Fatalf("open task: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if err := task.Write(util.NewRow(util.Now(), "a", i)); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+			}
+			if err := task.CommitTask(); err != nil {
+				t.Fatalf("commit: %v", err)
+			}
+		}
+
+		count := 0
+		for {
+			row, err := iterator.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s next: %v", format, err)
+			}
+			if len(row.K)+len(row.V) != 2 {
+				t.Errorf("%s row %v", format, row)
+			}
+			count++
+		}
+		if count != 6 {
+			t.Errorf("%s read %d rows, expected 6", format, count)
+		}
+		iterator.Close()
+	}
+}
+
+func TestCloseBeforeReading(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rows.sock")
+
+	goroutines := runtime.NumGoroutine()
+	iterator, err := UnixSource(path).SetConnections(2).OpenShard(0)
+	if err != nil {
+		t.Fatalf("open shard: %v", err)
+	}
+	task, err := UnixSink(path).OpenTask(0)
+	if err != nil {
+		t.Fatalf("open task: %v", err)
+	}
+	// more rows than the reader buffers, so its goroutine blocks
+	for i := 0; i < 5000; i++ {
+		if err := task.Write(util.NewRow(util.Now(), "a", i)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := task.CommitTask(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := iterator.Next(); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if err := iterator.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+
+	for start := time.Now(); runtime.NumGoroutine() > goroutines; {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("%d goroutines left after closing, %d before opening", runtime.NumGoroutine(), goroutines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestWriteError(t *testing.T) {
+	w := &ipcWriter{format: Tsv, writer: bufio.NewWriterSize(failingWriter{}, 16)}
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.Write(util.NewRow(util.Now(), "some long value", []byte("bytes"), i))
+	}
+	if err == nil {
+		t.Errorf("wrote to a broken writer")
+	}
+}
+
+func TestOnAgents(t *testing.T) {
+	f := flow.New("ipc")
+	ds := UnixSource("/tmp/in.sock").OnAgents("host=a").Generate(f)
+	if !reflect.DeepEqual(ds.Step.NodeSelector, []string{"host=a"}) {
+		t.Errorf("read on agents %v", ds.Step.NodeSelector)
+	}
+	out := PipeSink("/tmp/out.pipe").OnAgents("host=b").Save(ds)
+	if !reflect.DeepEqual(out.Step.NodeSelector, []string{"host=b"}) {
+		t.Errorf("write on agents %v", out.Step.NodeSelector)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package ipc
+
+import (
+	"fmt"
+)
+
+func mkfifo(path string) error {
+	return fmt.Errorf("named pipes are not supported")
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package ipc
+
+import (
+	"os"
+	"syscall"
+)
+
+// mkfifo creates the named pipe, unless the file exists.
+func mkfifo(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := syscall.Mkfifo(path, 0600); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
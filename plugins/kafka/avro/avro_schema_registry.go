@@ -0,0 +1,301 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/linkedin/goavro"
+	"github.com/lovelly/gleam/plugins/kafka"
+)
+
+// Importing this package decodes the Avro messages of kafka.Source(), with
+// the schemas of KafkaSource.SchemaRegistry().
+func init() {
+	kafka.NewSchemaRegistry = func(url string) kafka.SchemaRegistry {
+		return newSchemaRegistry(url)
+	}
+}
+
+// schemaRegistry decodes the Confluent Avro encoded messages, i.e. a zero
+// byte, the 4 byte schema id, and the Avro binary data, with the schemas
+// fetched from the schema registry.
+type schemaRegistry struct {
+	url    string
+	lock   sync.Mutex
+	codecs map[int32]*registryCodec
+}
+
+type registryCodec struct {
+	codec  *goavro.Codec
+	schema *avroSchema
+}
+
+type registrySchema struct {
+	Schema string `json:"schema"`
+}
+
+func newSchemaRegistry(url string) *schemaRegistry {
+	return &schemaRegistry{
+		url:    strings.TrimSuffix(url, "/"),
+		codecs: make(map[int32]*registryCodec),
+	}
+}
+
+// Decode returns the fields of the Avro record in the message, with the
+// values of the unions unwrapped.
+func (r *schemaRegistry) Decode(message []byte) (map[string]interface{}, error) {
+	if len(message) < 5 || message[0] != 0 {
+		return nil, fmt.Errorf("not a Confluent Avro message")
+	}
+	schemaId := int32(binary.BigEndian.Uint32(message[1:5]))
+	codec, err := r.codec(schemaId)
+	if err != nil {
+		return nil, err
+	}
+	native, _, err := codec.codec.NativeFromBinary(message[5:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode message of schema %d: %v", schemaId, err)
+	}
+	record, ok := codec.schema.value(native).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Schema %d is not a record", schemaId)
+	}
+	return record, nil
+}
+
+func (r *schemaRegistry) codec(schemaId int32) (*registryCodec, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if codec, found := r.codecs[schemaId]; found {
+		return codec, nil
+	}
+	schema, err := r.fetch(fmt.Sprintf("%s/schemas/ids/%d", r.url, schemaId))
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse schema %d: %v", schemaId, err)
+	}
+	parsed, err := parseAvroSchema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse schema %d: %v", schemaId, err)
+	}
+	r.codecs[schemaId] = &registryCodec{codec: codec, schema: parsed}
+	return r.codecs[schemaId], nil
+}
+
+// LatestFields returns the field names of the latest record schema of the subject.
+func (r *schemaRegistry) LatestFields(subject string) ([]string, error) {
+	schema, err := r.fetch(fmt.Sprintf("%s/subjects/%s/versions/latest", r.url, subject))
+	if err != nil {
+		return nil, err
+	}
+	var record struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil || record.Type != "record" {
+		return nil, fmt.Errorf("The latest schema of %s is not a record", subject)
+	}
+	var fields []string
+	for _, field := range record.Fields {
+		fields = append(fields, field.Name)
+	}
+	return fields, nil
+}
+
+func (r *schemaRegistry) fetch(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("Failed to fetch schema %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Failed to fetch schema %s: %s", url, resp.Status)
+	}
+	var s registrySchema
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return "", fmt.Errorf("Failed to decode schema %s: %v", url, err)
+	}
+	return s.Schema, nil
+}
+
+// avroSchema unwraps the values of unions, which goavro decodes as a map
+// from the name of the branch type to the value, by walking the values
+// along the schema.
+type avroSchema struct {
+	names map[string]interface{}
+}
+
+var avroPrimitives = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true,
+	"float": true, "double": true, "bytes": true, "string": true,
+}
+
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return nil, err
+	}
+	a := &avroSchema{names: make(map[string]interface{})}
+	a.collect(parsed, "")
+	a.names[""] = parsed
+	return a, nil
+}
+
+// collect indexes the named types by their full names.
+func (a *avroSchema) collect(schema interface{}, namespace string) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, branch := range s {
+			a.collect(branch, namespace)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error", "enum", "fixed":
+			name := fullName(s, namespace)
+			a.names[name] = s
+			for _, field := range fields(s) {
+				a.collect(field["type"], namespaceOf(name))
+			}
+		case "array":
+			a.collect(s["items"], namespace)
+		case "map":
+			a.collect(s["values"], namespace)
+		default:
+			a.collect(s["type"], namespace)
+		}
+	}
+}
+
+// value returns the value decoded with the top level schema.
+func (a *avroSchema) value(value interface{}) interface{} {
+	return a.valueOf(a.names[""], "", value)
+}
+
+func (a *avroSchema) valueOf(schema interface{}, namespace string, value interface{}) interface{} {
+	switch s := schema.(type) {
+	case string:
+		if avroPrimitives[s] {
+			return value
+		}
+		if named, found := a.names[qualify(s, namespace)]; found {
+			return a.valueOf(named, namespaceOf(qualify(s, namespace)), value)
+		}
+	case []interface{}:
+		union, ok := value.(map[string]interface{})
+		if !ok || len(union) != 1 {
+			return value
+		}
+		for typeName, x := range union {
+			for _, branch := range s {
+				if a.typeName(branch, namespace) == typeName {
+					return a.valueOf(branch, namespace, x)
+				}
+			}
+			return x
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error":
+			record, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			ns := namespaceOf(fullName(s, namespace))
+			m := make(map[string]interface{}, len(record))
+			for key, x := range record {
+				m[key] = x
+			}
+			for _, field := range fields(s) {
+				name, _ := field["name"].(string)
+				if x, found := record[name]; found {
+					m[name] = a.valueOf(field["type"], ns, x)
+				}
+			}
+			return m
+		case "array":
+			items, ok := value.([]interface{})
+			if !ok {
+				return value
+			}
+			list := make([]interface{}, len(items))
+			for i, x := range items {
+				list[i] = a.valueOf(s["items"], namespace, x)
+			}
+			return list
+		case "map":
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			m := make(map[string]interface{}, len(values))
+			for key, x := range values {
+				m[key] = a.valueOf(s["values"], namespace, x)
+			}
+			return m
+		case "enum", "fixed":
+			return value
+		default:
+			return a.valueOf(s["type"], namespace, value)
+		}
+	}
+	return value
+}
+
+// typeName returns the name goavro uses for the branch of a union.
+func (a *avroSchema) typeName(schema interface{}, namespace string) string {
+	switch s := schema.(type) {
+	case string:
+		if avroPrimitives[s] {
+			return s
+		}
+		return qualify(s, namespace)
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error", "enum", "fixed":
+			return fullName(s, namespace)
+		}
+		return a.typeName(s["type"], namespace)
+	}
+	return ""
+}
+
+func fields(record map[string]interface{}) (list []map[string]interface{}) {
+	fields, _ := record["fields"].([]interface{})
+	for _, f := range fields {
+		if field, ok := f.(map[string]interface{}); ok {
+			list = append(list, field)
+		}
+	}
+	return list
+}
+
+func fullName(named map[string]interface{}, namespace string) string {
+	name, _ := named["name"].(string)
+	if ns, ok := named["namespace"].(string); ok && !strings.Contains(name, ".") {
+		namespace = ns
+	}
+	return qualify(name, namespace)
+}
+
+func qualify(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func namespaceOf(fullName string) string {
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		return fullName[:i]
+	}
+	return ""
+}
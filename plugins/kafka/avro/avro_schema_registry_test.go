@@ -0,0 +1,98 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/linkedin/goavro"
+	"github.com/lovelly/gleam/plugins/kafka"
+)
+
+const testAvroSchema = `{
+	"type": "record", "name": "User", "namespace": "com.example",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": ["null", "int"]},
+		{"name": "address", "type": ["null", {
+			"type": "record", "name": "Address",
+			"fields": [
+				{"name": "city", "type": "string"},
+				{"name": "zip", "type": ["null", "string"]}
+			]
+		}]},
+		{"name": "previous", "type": {"type": "array", "items": ["null", "Address"]}},
+		{"name": "tags", "type": {"type": "map", "values": ["null", "long"]}},
+		{"name": "other", "type": ["null", {"type": "map", "values": "string"}]}
+	]
+}`
+
+func TestDecodeAvro(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/ids/7" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(registrySchema{Schema: testAvroSchema})
+	}))
+	defer server.Close()
+
+	codec, err := goavro.NewCodec(testAvroSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"name": "alice",
+		"age":  map[string]interface{}{"int": int32(30)},
+		"address": map[string]interface{}{"com.example.Address": map[string]interface{}{
+			"city": "Paris",
+			"zip":  map[string]interface{}{"string": "75001"},
+		}},
+		"previous": []interface{}{
+			nil,
+			map[string]interface{}{"com.example.Address": map[string]interface{}{
+				"city": "Lyon",
+				"zip":  nil,
+			}},
+		},
+		"tags":  map[string]interface{}{"a": map[string]interface{}{"long": int64(1)}},
+		"other": map[string]interface{}{"map": map[string]interface{}{"string": "x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], 7)
+
+	record, err := newSchemaRegistry(server.URL + "/").Decode(append(header, data...))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":     "alice",
+		"age":      int32(30),
+		"address":  map[string]interface{}{"city": "Paris", "zip": "75001"},
+		"previous": []interface{}{nil, map[string]interface{}{"city": "Lyon", "zip": nil}},
+		"tags":     map[string]interface{}{"a": int64(1)},
+		// the map keyed by "string" is a map, not a union
+		"other": map[string]interface{}{"string": "x"},
+	}
+	if !reflect.DeepEqual(record, expected) {
+		t.Errorf("decoded %v, expected %v", record, expected)
+	}
+
+	for _, message := range [][]byte{[]byte("plain"), {0, 0, 0, 0, 8, 1}} {
+		if _, err := newSchemaRegistry(server.URL).Decode(message); err == nil {
+			t.Errorf("decoded the bad message %q", message)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	if kafka.NewSchemaRegistry == nil {
+		t.Fatalf("importing the package does not register the schema registry")
+	}
+}
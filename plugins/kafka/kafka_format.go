@@ -0,0 +1,41 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lovelly/gleam/util"
+)
+
+// decodeMessage decodes the fields of the message in the format. The
+// missing fields are nil.
+func decodeMessage(format string, fields []string, message []byte) ([]interface{}, error) {
+	values := make([]interface{}, len(fields))
+	switch format {
+	case "json":
+		var record map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(message))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("Failed to decode json message: %v", err)
+		}
+		for i, field := range fields {
+			values[i] = util.JsonValue(record[field])
+		}
+	case "csv":
+		record, err := csv.NewReader(bytes.NewReader(message)).Read()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode csv message: %v", err)
+		}
+		for i := range fields {
+			if i < len(record) {
+				values[i] = record[i]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Unknown message format %s", format)
+	}
+	return values, nil
+}
@@ -3,6 +3,7 @@ package kafka
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"log"
 	"time"
 
@@ -11,17 +12,34 @@ import (
 )
 
 type KafkaPartitionInfo struct {
-	Brokers        []string
-	Topic          string
-	Group          string
-	TimeoutSeconds int
-	PartitionId    int32
+	Brokers           []string
+	Topic             string
+	Group             string
+	TimeoutSeconds    int
+	PartitionIds      []int32
+	ShardId           int
+	ShardCount        int
+	SchemaRegistryUrl string
+	AvroFieldNames    []string
+	MetadataFields    []string
+	MessageFormat     string
+	MessageFields     []string
+	WindowSeconds     int
+	BatchSeconds      int
+	EndOffsets        map[int32]int64 // the offsets after the batch, by partition
 }
 
 var (
 	MapperReadShard = gio.RegisterMapper(readShard)
 )
 
+// rebalanceInterval is how often the shards look for new partitions, and
+// lagInterval how often they report their lag.
+var (
+	rebalanceInterval = time.Minute
+	lagInterval       = 5 * time.Second
+)
+
 func init() {
 	gob.Register(KafkaPartitionInfo{})
 }
@@ -38,6 +56,21 @@ func (s *KafkaPartitionInfo) ReadSplit() error {
 	config.Net.DialTimeout = time.Duration(s.TimeoutSeconds) * time.Second
 	config.Net.ReadTimeout = time.Duration(s.TimeoutSeconds) * time.Second
 	config.Net.WriteTimeout = time.Duration(s.TimeoutSeconds) * time.Second
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	for _, field := range s.MetadataFields {
+		if field == "headers" {
+			// the record headers are added in kafka 0.11
+			config.Version = sarama.V0_11_0_0
+		}
+	}
+
+	var registry SchemaRegistry
+	if s.SchemaRegistryUrl != "" {
+		var err error
+		if registry, err = newSchemaRegistry(s.SchemaRegistryUrl); err != nil {
+			return err
+		}
+	}
 
 	c, err := sarama.NewClient(s.Brokers, config)
 	if err != nil {
@@ -53,13 +86,6 @@ func (s *KafkaPartitionInfo) ReadSplit() error {
 	}
 	defer offsetManager.Close()
 
-	partitionOffsetManager, err := offsetManager.ManagePartition(s.Topic, s.PartitionId)
-	if err != nil {
-		log.Printf("Kafka ManagePartition error: %v", err)
-		return err
-	}
-	defer partitionOffsetManager.Close()
-
 	consumer, err := sarama.NewConsumerFromClient(c)
 	if err != nil {
 		log.Printf("Kafka NewConsumerFromClient error: %v", err)
@@ -67,25 +93,111 @@ func (s *KafkaPartitionInfo) ReadSplit() error {
 	}
 	defer consumer.Close()
 
-	pc, err := consumer.ConsumePartition(s.Topic, s.PartitionId, sarama.OffsetOldest)
-	if err != nil {
-		log.Printf("Kafka Partition %d, error: %v", s.PartitionId, err)
-		return err
+	shard := newShardConsumer(s, c, offsetManager, consumer)
+	defer shard.close()
+	for _, partitionId := range s.PartitionIds {
+		if err := shard.assign(partitionId); err != nil {
+			log.Printf("Kafka shard %d: %v", s.ShardId, err)
+			return err
+		}
 	}
-	defer pc.Close()
 
-	for msg := range pc.Messages() {
-		if msg == nil {
-			continue
+	// a batch reads the partitions listed by the driver, up to the end
+	// offsets, and the driver commits them once the flow succeeds
+	batch := s.EndOffsets != nil
+	var rebalance, batchEnd <-chan time.Time
+	if !batch {
+		rebalanceTicker := time.NewTicker(rebalanceInterval)
+		defer rebalanceTicker.Stop()
+		rebalance = rebalanceTicker.C
+	} else if s.BatchSeconds > 0 {
+		batchTimer := time.NewTimer(time.Duration(s.BatchSeconds) * time.Second)
+		defer batchTimer.Stop()
+		batchEnd = batchTimer.C
+	}
+	lagTicker := time.NewTicker(lagInterval)
+	defer lagTicker.Stop()
+
+	for !shard.readAll() {
+		select {
+		case msg := <-shard.messages:
+			if msg == nil || !shard.owns(msg) {
+				continue
+			}
+			ts := msg.Timestamp.UnixNano() / int64(time.Millisecond)
+			values, err := s.messageValues(registry, msg)
+			if err != nil {
+				// the offset is not marked, so the message is read again
+				log.Printf("Kafka Partition %d offset %d: %v", msg.Partition, msg.Offset, err)
+				return err
+			}
+			gio.TsEmit(ts, values...)
+			shard.processed(msg)
+			gio.SetLag(shard.lag())
+		case <-rebalance:
+			if err := shard.rebalance(); err != nil {
+				log.Printf("Kafka shard %d: %v", s.ShardId, err)
+			}
+		case <-lagTicker.C:
+			gio.SetLag(shard.lag())
+		case <-batchEnd:
+			// the driver commits no offsets, so the next run reads the batch again
+			return fmt.Errorf("Kafka shard %d read the batch for more than %d seconds, %d messages left", s.ShardId, s.BatchSeconds, shard.lag())
 		}
-
-		partitionOffsetManager.MarkOffset(msg.Offset, "")
-		ts := msg.Timestamp.UnixNano() / int64(time.Millisecond)
-		gio.TsEmit(ts, msg.Value)
 	}
-
 	return nil
+}
 
+// messageValues returns the message value, or its Avro or formatted fields,
+// and the metadata fields.
+func (s *KafkaPartitionInfo) messageValues(registry SchemaRegistry, msg *sarama.ConsumerMessage) ([]interface{}, error) {
+	var values []interface{}
+	if registry != nil {
+		record, err := registry.Decode(msg.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range s.AvroFieldNames {
+			values = append(values, record[field])
+		}
+	} else if s.MessageFormat != "" {
+		fields, err := decodeMessage(s.MessageFormat, s.MessageFields, msg.Value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, fields...)
+	} else {
+		values = append(values, msg.Value)
+	}
+
+	for _, field := range s.MetadataFields {
+		switch field {
+		case "key":
+			values = append(values, msg.Key)
+		case "partition":
+			values = append(values, msg.Partition)
+		case "offset":
+			values = append(values, msg.Offset)
+		case "timestamp":
+			values = append(values, msg.Timestamp.UnixNano()/int64(time.Millisecond))
+		case "headers":
+			headers := make(map[string]interface{})
+			for _, header := range msg.Headers {
+				headers[string(header.Key)] = header.Value
+			}
+			values = append(values, headers)
+		case "window":
+			if s.WindowSeconds <= 0 {
+				return nil, fmt.Errorf("The window metadata field needs the window size")
+			}
+			ts := msg.Timestamp.UnixNano() / int64(time.Millisecond)
+			size := int64(s.WindowSeconds) * 1000
+			values = append(values, ts-ts%size)
+		default:
+			return nil, fmt.Errorf("Unknown metadata field %s", field)
+		}
+	}
+	return values, nil
 }
 
 func decodeShardInfo(encodedShardInfo []byte) *KafkaPartitionInfo {
@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"fmt"
+)
+
+// SchemaRegistry decodes the Confluent Avro encoded messages with the schemas
+// of a schema registry.
+type SchemaRegistry interface {
+	// Decode returns the fields of the Avro record in the message.
+	Decode(message []byte) (map[string]interface{}, error)
+	// LatestFields returns the field names of the latest record schema of
+	// the subject.
+	LatestFields(subject string) ([]string, error)
+}
+
+// NewSchemaRegistry is set by importing the Avro decoder, so only the
+// binaries reading Avro messages link its dependencies:
+//
+//	import _ "github.com/lovelly/gleam/plugins/kafka/avro"
+//
+// The sources with a SchemaRegistry() fail without it.
+var NewSchemaRegistry func(url string) SchemaRegistry
+
+func newSchemaRegistry(url string) (SchemaRegistry, error) {
+	if NewSchemaRegistry == nil {
+		return nil, fmt.Errorf("Avro messages are not registered, import _ \"github.com/lovelly/gleam/plugins/kafka/avro\"")
+	}
+	return NewSchemaRegistry(url), nil
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/lovelly/gleam/flow"
+)
+
+type testRegistry map[string]interface{}
+
+func (r testRegistry) Decode(message []byte) (map[string]interface{}, error) {
+	return r, nil
+}
+
+func (r testRegistry) LatestFields(subject string) ([]string, error) {
+	return nil, nil
+}
+
+func TestMessageValues(t *testing.T) {
+	info := &KafkaPartitionInfo{
+		AvroFieldNames: []string{"name", "missing"},
+		MetadataFields: []string{"offset"},
+	}
+	values, err := info.messageValues(testRegistry{"name": "alice"}, &sarama.ConsumerMessage{Offset: 12})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	expected := []interface{}{"alice", nil, int64(12)}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("decoded %v, expected %v", values, expected)
+	}
+}
+
+func TestSchemaRegistryNotRegistered(t *testing.T) {
+	source := New([]string{"localhost:1"}, "users", "test").SchemaRegistry("http://localhost:1")
+	_, err := flow.New("testSchemaRegistryNotRegistered").Read(source).Collect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "plugins/kafka/avro") {
+		t.Errorf("read Avro messages without the decoder: %v", err)
+	}
+}
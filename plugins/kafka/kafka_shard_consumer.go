@@ -0,0 +1,198 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Shopify/sarama"
+)
+
+// shardConsumer consumes the kafka partitions assigned to one gleam shard,
+// merging their messages into one channel.
+type shardConsumer struct {
+	info          *KafkaPartitionInfo
+	client        sarama.Client
+	offsetManager sarama.OffsetManager
+	consumer      sarama.Consumer
+	partitions    map[int32]*partitionConsumer
+	messages      chan *sarama.ConsumerMessage
+	done          chan struct{}
+}
+
+type partitionConsumer struct {
+	consumer sarama.PartitionConsumer
+	offsets  sarama.PartitionOffsetManager
+	next     int64
+	done     chan struct{}
+}
+
+func newShardConsumer(info *KafkaPartitionInfo, client sarama.Client, offsetManager sarama.OffsetManager, consumer sarama.Consumer) *shardConsumer {
+	return &shardConsumer{
+		info:          info,
+		client:        client,
+		offsetManager: offsetManager,
+		consumer:      consumer,
+		partitions:    make(map[int32]*partitionConsumer),
+		messages:      make(chan *sarama.ConsumerMessage),
+		done:          make(chan struct{}),
+	}
+}
+
+// assign starts consuming the partition, from the offset committed by the
+// consumer group, so a partition moved from another shard continues where
+// the other shard stopped.
+func (s *shardConsumer) assign(partitionId int32) error {
+	offsets, err := s.offsetManager.ManagePartition(s.info.Topic, partitionId)
+	if err != nil {
+		return fmt.Errorf("Failed to manage offsets of partition %d: %v", partitionId, err)
+	}
+	offset, _ := offsets.NextOffset()
+	pc, err := s.consumer.ConsumePartition(s.info.Topic, partitionId, offset)
+	if err == sarama.ErrOffsetOutOfRange {
+		// the committed offset has expired
+		offset = sarama.OffsetOldest
+		pc, err = s.consumer.ConsumePartition(s.info.Topic, partitionId, offset)
+	}
+	if err == nil && offset < 0 {
+		// resolve the oldest or newest offset, to count the lag from it
+		offset, err = s.client.GetOffset(s.info.Topic, partitionId, offset)
+		if err != nil {
+			pc.Close()
+		}
+	}
+	if err != nil {
+		offsets.Close()
+		return fmt.Errorf("Failed to consume partition %d: %v", partitionId, err)
+	}
+	p := &partitionConsumer{consumer: pc, offsets: offsets, next: offset, done: make(chan struct{})}
+	s.partitions[partitionId] = p
+
+	go func() {
+		for msg := range pc.Messages() {
+			select {
+			case s.messages <- msg:
+			case <-p.done:
+				return
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// release stops consuming the partition. Its offsets are committed, so the
+// shard it moves to continues from its last processed message.
+func (s *shardConsumer) release(partitionId int32) {
+	p := s.partitions[partitionId]
+	delete(s.partitions, partitionId)
+	close(p.done)
+	p.consumer.Close()
+	s.offsetManager.Commit()
+	p.offsets.Close()
+}
+
+// rebalance assigns the partitions added to the topic, by the partition id
+// modulo the shard count, and releases the partitions removed from the
+// topic or not assigned to this shard.
+func (s *shardConsumer) rebalance() error {
+	if err := s.client.RefreshMetadata(s.info.Topic); err != nil {
+		return fmt.Errorf("Failed to refresh metadata of %s: %v", s.info.Topic, err)
+	}
+	partitionIds, err := s.client.Partitions(s.info.Topic)
+	if err != nil {
+		return fmt.Errorf("Failed to list partitions of %s: %v", s.info.Topic, err)
+	}
+	assigned := make(map[int32]bool)
+	for _, partitionId := range partitionIds {
+		if shardOf(partitionId, s.info.ShardCount) == s.info.ShardId {
+			assigned[partitionId] = true
+		}
+	}
+	for partitionId := range s.partitions {
+		if !assigned[partitionId] {
+			log.Printf("Kafka shard %d released partition %d", s.info.ShardId, partitionId)
+			s.release(partitionId)
+		}
+	}
+	for _, partitionId := range partitionIds {
+		if _, found := s.partitions[partitionId]; found || !assigned[partitionId] {
+			continue
+		}
+		log.Printf("Kafka shard %d assigned new partition %d", s.info.ShardId, partitionId)
+		if err := s.assign(partitionId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// owns tells whether the message is from a partition still consumed by the
+// shard, and not from a released one, nor after the end of the batch.
+func (s *shardConsumer) owns(msg *sarama.ConsumerMessage) bool {
+	_, found := s.partitions[msg.Partition]
+	if end, isBatch := s.end(msg.Partition); isBatch && msg.Offset >= end {
+		return false
+	}
+	return found
+}
+
+// processed commits the offset of the message. The offsets of a batch are
+// committed by the driver, after the flow succeeds.
+func (s *shardConsumer) processed(msg *sarama.ConsumerMessage) {
+	p := s.partitions[msg.Partition]
+	if _, isBatch := s.end(msg.Partition); !isBatch {
+		p.offsets.MarkOffset(msg.Offset+1, "")
+	}
+	p.next = msg.Offset + 1
+}
+
+// end returns the offset after the batch in the partition, if the shard
+// reads a batch.
+func (s *shardConsumer) end(partitionId int32) (offset int64, isBatch bool) {
+	if s.info.EndOffsets == nil {
+		return 0, false
+	}
+	return s.info.EndOffsets[partitionId], true
+}
+
+// readAll tells whether the shard has read its batch. Without a batch, the
+// partitions are read forever.
+func (s *shardConsumer) readAll() bool {
+	if s.info.EndOffsets == nil {
+		return false
+	}
+	for partitionId, p := range s.partitions {
+		if end, _ := s.end(partitionId); p.next < end {
+			return false
+		}
+	}
+	return true
+}
+
+// lag returns the messages left in the partitions of the shard, including
+// the idle ones, or in the batch.
+func (s *shardConsumer) lag() (lag int64) {
+	for partitionId, p := range s.partitions {
+		end, isBatch := s.end(partitionId)
+		if !isBatch {
+			end = p.consumer.HighWaterMarkOffset()
+		}
+		if left := end - p.next; left > 0 {
+			lag += left
+		}
+	}
+	return lag
+}
+
+func (s *shardConsumer) close() {
+	close(s.done)
+	for _, p := range s.partitions {
+		p.consumer.Close()
+		p.offsets.Close()
+	}
+}
+
+func shardOf(partitionId int32, shardCount int) int {
+	return int(partitionId) % shardCount
+}
@@ -0,0 +1,198 @@
+package kafka
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+type testClient struct {
+	sarama.Client
+	partitionIds []int32
+	oldest       int64
+}
+
+func (c *testClient) RefreshMetadata(topics ...string) error { return nil }
+func (c *testClient) Partitions(topic string) ([]int32, error) {
+	return c.partitionIds, nil
+}
+func (c *testClient) GetOffset(topic string, partitionId int32, time int64) (int64, error) {
+	return c.oldest, nil
+}
+
+type testOffsetManager struct {
+	sarama.OffsetManager
+	committed map[int32]int64
+	marked    map[int32]int64
+	commits   int
+}
+
+func (m *testOffsetManager) ManagePartition(topic string, partitionId int32) (sarama.PartitionOffsetManager, error) {
+	return &testPartitionOffsets{m: m, partitionId: partitionId}, nil
+}
+func (m *testOffsetManager) Commit() { m.commits++ }
+
+type testPartitionOffsets struct {
+	sarama.PartitionOffsetManager
+	m           *testOffsetManager
+	partitionId int32
+}
+
+func (o *testPartitionOffsets) NextOffset() (int64, string) {
+	if offset, found := o.m.committed[o.partitionId]; found {
+		return offset, ""
+	}
+	return sarama.OffsetOldest, ""
+}
+func (o *testPartitionOffsets) MarkOffset(offset int64, metadata string) {
+	o.m.marked[o.partitionId] = offset
+}
+func (o *testPartitionOffsets) Close() error { return nil }
+
+type testConsumer struct {
+	sarama.Consumer
+	partitions map[int32]*testPartitionConsumer
+}
+
+func (c *testConsumer) ConsumePartition(topic string, partitionId int32, offset int64) (sarama.PartitionConsumer, error) {
+	pc := &testPartitionConsumer{messages: make(chan *sarama.ConsumerMessage, 10), offset: offset}
+	c.partitions[partitionId] = pc
+	return pc, nil
+}
+
+type testPartitionConsumer struct {
+	sarama.PartitionConsumer
+	messages      chan *sarama.ConsumerMessage
+	offset        int64
+	highWaterMark int64
+	closed        bool
+}
+
+func (pc *testPartitionConsumer) Messages() <-chan *sarama.ConsumerMessage { return pc.messages }
+func (pc *testPartitionConsumer) HighWaterMarkOffset() int64               { return pc.highWaterMark }
+func (pc *testPartitionConsumer) Close() error {
+	if !pc.closed {
+		pc.closed = true
+		close(pc.messages)
+	}
+	return nil
+}
+
+func newTestShardConsumer(shardId int, partitionIds ...int32) (*shardConsumer, *testClient, *testOffsetManager, *testConsumer) {
+	client := &testClient{partitionIds: partitionIds, oldest: 5}
+	offsets := &testOffsetManager{committed: map[int32]int64{1: 20}, marked: make(map[int32]int64)}
+	consumer := &testConsumer{partitions: make(map[int32]*testPartitionConsumer)}
+	info := &KafkaPartitionInfo{Topic: "t", ShardId: shardId, ShardCount: 2}
+	return newShardConsumer(info, client, offsets, consumer), client, offsets, consumer
+}
+
+func (s *shardConsumer) partitionIds() (ids []int32) {
+	for id := range s.partitions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestRebalance(t *testing.T) {
+	shard, client, offsets, consumer := newTestShardConsumer(1, 1, 3)
+	defer shard.close()
+	if err := shard.rebalance(); err != nil {
+		t.Fatal(err)
+	}
+	if ids := shard.partitionIds(); !reflect.DeepEqual(ids, []int32{1, 3}) {
+		t.Fatalf("assigned %v", ids)
+	}
+	if next := shard.partitions[1].next; next != 20 {
+		t.Errorf("partition 1 starts at %d, expected the committed offset 20", next)
+	}
+	if next := shard.partitions[3].next; next != 5 {
+		t.Errorf("partition 3 starts at %d, expected the oldest offset 5", next)
+	}
+
+	// partition 3 is gone, and partition 5 added
+	client.partitionIds = []int32{0, 1, 2, 4, 5}
+	if err := shard.rebalance(); err != nil {
+		t.Fatal(err)
+	}
+	if ids := shard.partitionIds(); !reflect.DeepEqual(ids, []int32{1, 5}) {
+		t.Errorf("rebalanced to %v", ids)
+	}
+	if !consumer.partitions[3].closed || offsets.commits != 1 {
+		t.Errorf("partition 3 is not released")
+	}
+
+	// the messages already read from a released partition are dropped
+	if shard.owns(&sarama.ConsumerMessage{Partition: 3}) || !shard.owns(&sarama.ConsumerMessage{Partition: 5}) {
+		t.Errorf("owns the wrong partitions")
+	}
+}
+
+func TestLag(t *testing.T) {
+	shard, _, offsets, consumer := newTestShardConsumer(1, 1, 3)
+	defer shard.close()
+	for _, id := range []int32{1, 3} {
+		if err := shard.assign(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	consumer.partitions[1].highWaterMark = 30
+	consumer.partitions[3].highWaterMark = 8
+	if lag := shard.lag(); lag != 10+3 {
+		t.Errorf("lag %d, expected 13", lag)
+	}
+
+	shard.processed(&sarama.ConsumerMessage{Partition: 1, Offset: 24})
+	if offsets.marked[1] != 25 {
+		t.Errorf("marked offset %d, expected 25", offsets.marked[1])
+	}
+	if lag := shard.lag(); lag != 5+3 {
+		t.Errorf("lag %d, expected 8", lag)
+	}
+
+	// the lag of the idle partition 3 grows without any message of its own
+	consumer.partitions[3].highWaterMark = 12
+	if lag := shard.lag(); lag != 5+7 {
+		t.Errorf("lag %d, expected 12", lag)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	shard, _, offsets, _ := newTestShardConsumer(1, 1, 3)
+	defer shard.close()
+	shard.info.EndOffsets = map[int32]int64{1: 22, 3: 5}
+	for _, id := range []int32{1, 3} {
+		if err := shard.assign(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if shard.readAll() || shard.lag() != 2 {
+		t.Errorf("read all %v with lag %d, expected 2 messages left", shard.readAll(), shard.lag())
+	}
+
+	for offset := int64(20); offset < 22; offset++ {
+		msg := &sarama.ConsumerMessage{Partition: 1, Offset: offset}
+		if !shard.owns(msg) {
+			t.Fatalf("message at %d is not in the batch", offset)
+		}
+		shard.processed(msg)
+	}
+	if shard.owns(&sarama.ConsumerMessage{Partition: 1, Offset: 22}) {
+		t.Errorf("the message after the batch is read")
+	}
+	if !shard.readAll() {
+		t.Errorf("expecting the batch to be read")
+	}
+	// the driver commits the offsets once the flow succeeds
+	if len(offsets.marked) != 0 {
+		t.Errorf("marked offsets %v by the shard", offsets.marked)
+	}
+	if err := commitEndOffsets(offsets, "t", shard.info.EndOffsets); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(offsets.marked, shard.info.EndOffsets) || offsets.commits != 1 {
+		t.Errorf("committed %v %d times, expected %v", offsets.marked, offsets.commits, shard.info.EndOffsets)
+	}
+}
@@ -3,7 +3,6 @@ package kafka
 import (
 	"fmt"
 	"io"
-	"log"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -13,29 +12,63 @@ import (
 )
 
 type KafkaSource struct {
-	Brokers        []string
-	Group          string
-	Topic          string
-	TimeoutSeconds int
+	Brokers           []string
+	Group             string
+	Topic             string
+	TimeoutSeconds    int
+	SchemaRegistryUrl string
+	AvroFieldNames    []string
+	MetadataFields    []string
+	ShardCount        int
+	MessageFormat     string
+	MessageFields     []string
+	WindowSeconds     int
+	BatchSeconds      int
 
 	prefix string
 }
 
 // Generate generates data shard info,
 // partitions them via round robin,
-// and reads each shard on each executor
+// and reads each shard on each executor.
+// The flow fails if the partitions or the schema can not be fetched.
 func (s *KafkaSource) Generate(f *flow.Flow) *flow.Dataset {
+	var registry SchemaRegistry
+	if s.SchemaRegistryUrl != "" {
+		var err error
+		if registry, err = newSchemaRegistry(s.SchemaRegistryUrl); err != nil {
+			return f.FailedSource(s.prefix, err)
+		}
+	}
 	partitionIds, err := s.fetchPartitionIds()
 	if err != nil {
-		log.Printf("KafkaSource failed to fetch kafka partitions: %v", err)
-		return nil
+		return f.FailedSource(s.prefix, fmt.Errorf("KafkaSource failed to fetch kafka partitions: %v", err))
+	}
+	if registry != nil && len(s.AvroFieldNames) == 0 {
+		s.AvroFieldNames, err = registry.LatestFields(s.Topic + "-value")
+		if err != nil {
+			return f.FailedSource(s.prefix, fmt.Errorf("KafkaSource failed to fetch the schema of %s: %v", s.Topic, err))
+		}
 	}
-	return s.genShardInfos(f, partitionIds).
-		RoundRobin(s.prefix, len(partitionIds)).
+	var endOffsets map[int32]int64
+	if s.BatchSeconds > 0 {
+		if endOffsets, err = s.fetchEndOffsets(partitionIds); err != nil {
+			return f.FailedSource(s.prefix, fmt.Errorf("KafkaSource failed to fetch the offsets of %s: %v", s.Topic, err))
+		}
+		f.OnSuccess(func() error {
+			return s.commitOffsets(endOffsets)
+		})
+	}
+	shardCount := s.ShardCount
+	if shardCount <= 0 {
+		shardCount = len(partitionIds)
+	}
+	return s.genShardInfos(f, partitionIds, endOffsets, shardCount).
+		RoundRobin(s.prefix, shardCount).
 		Map(s.prefix+".Read", MapperReadShard)
 }
 
-func (s *KafkaSource) fetchPartitionIds() ([]int32, error) {
+func (s *KafkaSource) newClient() (sarama.Client, error) {
 	config := sarama.NewConfig()
 	config.Net.DialTimeout = time.Duration(s.TimeoutSeconds) * time.Second
 	config.Net.ReadTimeout = time.Duration(s.TimeoutSeconds) * time.Second
@@ -45,6 +78,14 @@ func (s *KafkaSource) fetchPartitionIds() ([]int32, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Failed to connect to %v: %v", s.Brokers, err)
 	}
+	return c, nil
+}
+
+func (s *KafkaSource) fetchPartitionIds() ([]int32, error) {
+	c, err := s.newClient()
+	if err != nil {
+		return nil, err
+	}
 	defer c.Close()
 
 	// the partition ids for a topic
@@ -56,19 +97,96 @@ func (s *KafkaSource) fetchPartitionIds() ([]int32, error) {
 	return partitionIds, nil
 }
 
-func (s *KafkaSource) genShardInfos(f *flow.Flow, partitionIds []int32) *flow.Dataset {
+// fetchEndOffsets returns the offsets after the last messages of the
+// partitions, where the batch ends.
+func (s *KafkaSource) fetchEndOffsets(partitionIds []int32) (map[int32]int64, error) {
+	c, err := s.newClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	endOffsets := make(map[int32]int64)
+	for _, partitionId := range partitionIds {
+		offset, err := c.GetOffset(s.Topic, partitionId, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get the offset of partition %d: %v", partitionId, err)
+		}
+		endOffsets[partitionId] = offset
+	}
+	return endOffsets, nil
+}
+
+// commitOffsets commits the end offsets of the batch for the consumer group,
+// once the flow has processed the batch, so the next run reads the messages
+// after it.
+func (s *KafkaSource) commitOffsets(endOffsets map[int32]int64) error {
+	c, err := s.newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	offsetManager, err := sarama.NewOffsetManagerFromClient(s.Group, c)
+	if err != nil {
+		return fmt.Errorf("Failed to manage the offsets of group %s: %v", s.Group, err)
+	}
+	defer offsetManager.Close()
+	return commitEndOffsets(offsetManager, s.Topic, endOffsets)
+}
+
+func commitEndOffsets(offsetManager sarama.OffsetManager, topic string, endOffsets map[int32]int64) error {
+	var managed []sarama.PartitionOffsetManager
+	for partitionId, offset := range endOffsets {
+		offsets, err := offsetManager.ManagePartition(topic, partitionId)
+		if err != nil {
+			for _, offsets := range managed {
+				offsets.Close()
+			}
+			return fmt.Errorf("Failed to manage the offsets of partition %d: %v", partitionId, err)
+		}
+		offsets.MarkOffset(offset, "")
+		managed = append(managed, offsets)
+	}
+	offsetManager.Commit()
+	var commitErr error
+	for _, offsets := range managed {
+		if err := offsets.Close(); err != nil && commitErr == nil {
+			commitErr = fmt.Errorf("Failed to commit the offsets of %s: %v", topic, err)
+		}
+	}
+	return commitErr
+}
+
+func (s *KafkaSource) genShardInfos(f *flow.Flow, partitionIds []int32, endOffsets map[int32]int64, shardCount int) *flow.Dataset {
 	return f.Source(s.prefix+".list", func(writer io.Writer, stats *pb.InstructionStat) error {
 
 		stats.InputCounter++
 
+		assignments := make([][]int32, shardCount)
 		for _, pid := range partitionIds {
+			shardId := shardOf(pid, shardCount)
+			assignments[shardId] = append(assignments[shardId], pid)
+		}
+
+		for shardId, pids := range assignments {
 			stats.OutputCounter++
 			util.NewRow(util.Now(), encodeShardInfo(&KafkaPartitionInfo{
-				Brokers:        s.Brokers,
-				Topic:          s.Topic,
-				Group:          s.Group,
-				TimeoutSeconds: s.TimeoutSeconds,
-				PartitionId:    pid,
+				Brokers:           s.Brokers,
+				Topic:             s.Topic,
+				Group:             s.Group,
+				TimeoutSeconds:    s.TimeoutSeconds,
+				PartitionIds:      pids,
+				ShardId:           shardId,
+				ShardCount:        shardCount,
+				SchemaRegistryUrl: s.SchemaRegistryUrl,
+				AvroFieldNames:    s.AvroFieldNames,
+				MetadataFields:    s.MetadataFields,
+				MessageFormat:     s.MessageFormat,
+				MessageFields:     s.MessageFields,
+				WindowSeconds:     s.WindowSeconds,
+				BatchSeconds:      s.BatchSeconds,
+				EndOffsets:        endOffsets,
 			})).WriteTo(writer)
 		}
 
@@ -4,6 +4,10 @@ package kafka
 This file is only for the builder API.
 */
 
+import (
+	"time"
+)
+
 func New(brokers []string, topic, group string) *KafkaSource {
 	return &KafkaSource{
 		Brokers:        brokers,
@@ -19,3 +23,67 @@ func (s *KafkaSource) Timeout(seconds int) *KafkaSource {
 	s.TimeoutSeconds = seconds
 	return s
 }
+
+// Shards reads the partitions with the number of shards, assigning the
+// partition p to the shard p % count, so one shard can read several
+// partitions. By default each partition has its own shard. The shards also
+// read the partitions added to the topic while running, and continue from
+// the offsets committed by the group, also after the shard count changes.
+func (s *KafkaSource) Shards(count int) *KafkaSource {
+	s.ShardCount = count
+	return s
+}
+
+// SchemaRegistry decodes the Confluent Avro encoded messages with the schemas
+// of the registry, emitting the record fields instead of the raw messages.
+// The fields are those of the latest schema of the "<topic>-value" subject,
+// unless set by AvroFields(). The Avro decoder is linked by importing
+// _ "github.com/lovelly/gleam/plugins/kafka/avro", without which the flow
+// fails.
+func (s *KafkaSource) SchemaRegistry(url string) *KafkaSource {
+	s.SchemaRegistryUrl = url
+	return s
+}
+
+// AvroFields sets the record fields to emit, in order. The fields missing in
+// the schema of a message are nil.
+func (s *KafkaSource) AvroFields(fields ...string) *KafkaSource {
+	s.AvroFieldNames = fields
+	return s
+}
+
+// Metadata adds the message metadata after the value fields, in order,
+// from "key", "partition", "offset", "timestamp", "headers" and "window".
+// The timestamp is in unix milliseconds, and the window is the start of the
+// tumbling window of the timestamp, see Window().
+func (s *KafkaSource) Metadata(fields ...string) *KafkaSource {
+	s.MetadataFields = fields
+	return s
+}
+
+// Format decodes the messages, emitting the fields instead of the raw
+// messages. The format is "json", for the fields of a json object by name,
+// or "csv", for the comma separated fields in order.
+func (s *KafkaSource) Format(format string, fields ...string) *KafkaSource {
+	s.MessageFormat = format
+	s.MessageFields = fields
+	return s
+}
+
+// Window sets the size of the tumbling windows of the "window" metadata
+// field, e.g. to aggregate the messages by minute.
+func (s *KafkaSource) Window(size time.Duration) *KafkaSource {
+	s.WindowSeconds = int(size / time.Second)
+	return s
+}
+
+// Batch reads the messages up to the last ones when the flow is built,
+// instead of reading the partitions forever, failing if they are not read in
+// the duration. The offsets of the batch are committed once the flow runs
+// without errors, see flow.Flow.OnSuccess(), so each successful run reads
+// the next batch of messages, e.g. to aggregate them, and a failed run reads
+// the batch again.
+func (s *KafkaSource) Batch(d time.Duration) *KafkaSource {
+	s.BatchSeconds = int(d / time.Second)
+	return s
+}
@@ -0,0 +1,137 @@
+package mqtt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/lovelly/gleam/gio"
+)
+
+type MqttShardInfo struct {
+	Broker         string
+	Topic          string
+	ClientId       string
+	CleanSession   bool
+	Username       string
+	Password       string
+	Qos            byte
+	TimeoutSeconds int
+}
+
+var (
+	MapperReadShard = gio.RegisterMapper(readShard)
+)
+
+func init() {
+	gob.Register(MqttShardInfo{})
+}
+
+func readShard(row []interface{}) error {
+	encodedShardInfo := row[0].([]byte)
+	return decodeShardInfo(encodedShardInfo).ReadSplit()
+}
+
+func (s *MqttShardInfo) ReadSplit() error {
+
+	timeout := time.Duration(s.TimeoutSeconds) * time.Second
+	opts := paho.NewClientOptions().
+		AddBroker(s.Broker).
+		SetClientID(s.ClientId).
+		SetUsername(s.Username).
+		SetPassword(s.Password).
+		SetConnectTimeout(timeout).
+		SetAutoReconnect(true).
+		// keep the subscription and the queued messages across reconnects
+		SetCleanSession(s.CleanSession).
+		// acknowledge the messages once emitted, so the broker stops
+		// sending more than its in-flight limit when the shard lags
+		SetAutoAckDisabled(true)
+
+	messages := newMessageQueue()
+	client := paho.NewClient(opts)
+	if err := wait(client.Connect(), timeout); err != nil {
+		return fmt.Errorf("Failed to connect to %s: %v", s.Broker, err)
+	}
+	defer client.Disconnect(250)
+
+	token := client.Subscribe(s.Topic, s.Qos, func(_ paho.Client, msg paho.Message) {
+		messages.push(msg)
+	})
+	if err := wait(token, timeout); err != nil {
+		return fmt.Errorf("Failed to subscribe to %s: %v", s.Topic, err)
+	}
+
+	for {
+		msg := messages.pop()
+		if err := gio.Emit(msg.Topic(), msg.Payload()); err != nil {
+			return err
+		}
+		msg.Ack()
+	}
+}
+
+// messageQueue queues the messages from the subscription callback, which
+// must not block the client, or it stops reading from the connection and
+// misses its keep alives.
+type messageQueue struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	pending []paho.Message
+}
+
+func newMessageQueue() *messageQueue {
+	q := &messageQueue{}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+func (q *messageQueue) push(msg paho.Message) {
+	q.lock.Lock()
+	q.pending = append(q.pending, msg)
+	q.lock.Unlock()
+	q.cond.Signal()
+}
+
+// pop returns the oldest message, waiting for one.
+func (q *messageQueue) pop() paho.Message {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for len(q.pending) == 0 {
+		q.cond.Wait()
+	}
+	msg := q.pending[0]
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+	return msg
+}
+
+func wait(token paho.Token, timeout time.Duration) error {
+	if !token.WaitTimeout(timeout) {
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+	return token.Error()
+}
+
+func decodeShardInfo(encodedShardInfo []byte) *MqttShardInfo {
+	network := bytes.NewBuffer(encodedShardInfo)
+	dec := gob.NewDecoder(network)
+	var p MqttShardInfo
+	if err := dec.Decode(&p); err != nil {
+		log.Fatal("decode shard info", err)
+	}
+	return &p
+}
+
+func encodeShardInfo(shardInfo *MqttShardInfo) []byte {
+	var network bytes.Buffer
+	enc := gob.NewEncoder(&network)
+	if err := enc.Encode(shardInfo); err != nil {
+		log.Fatal("encode shard info:", err)
+	}
+	return network.Bytes()
+}
@@ -0,0 +1,81 @@
+package mqtt
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+type MqttSource struct {
+	Broker         string
+	Topic          string
+	ClientIdPrefix string
+	Username       string
+	Password       string
+	QosLevel       byte
+	Group          string
+	ShardCount     int
+	TimeoutSeconds int
+
+	prefix string
+}
+
+// Generate generates data shard info,
+// partitions them via round robin,
+// and reads each shard on each executor.
+// Each row has the topic and the payload of a message.
+func (s *MqttSource) Generate(f *flow.Flow) *flow.Dataset {
+	return s.genShardInfos(f).RoundRobin(s.prefix, s.ShardCount).Map(s.prefix+".Read", MapperReadShard)
+}
+
+func (s *MqttSource) genShardInfos(f *flow.Flow) *flow.Dataset {
+	return f.Source(s.prefix+".list", func(writer io.Writer, stats *pb.InstructionStat) error {
+
+		stats.InputCounter++
+
+		for _, shardInfo := range s.shardInfos() {
+			stats.OutputCounter++
+			util.NewRow(util.Now(), encodeShardInfo(shardInfo)).WriteTo(writer)
+		}
+
+		return nil
+	})
+}
+
+// shardInfos subscribes the shards to the topic, as a shared subscription
+// of the group if there are several shards. Without a client id prefix,
+// each run has its own client ids, and its sessions are cleaned up on
+// disconnect.
+func (s *MqttSource) shardInfos() (shardInfos []*MqttShardInfo) {
+	topic := s.Topic
+	if s.ShardCount > 1 {
+		group := s.Group
+		if group == "" {
+			group = "gleam"
+		}
+		topic = "$share/" + group + "/" + s.Topic
+	}
+
+	clientIdPrefix, cleanSession := s.ClientIdPrefix, false
+	if clientIdPrefix == "" {
+		clientIdPrefix, cleanSession = fmt.Sprintf("gleam-%08x", rand.Uint32()), true
+	}
+
+	for i := 0; i < s.ShardCount; i++ {
+		shardInfos = append(shardInfos, &MqttShardInfo{
+			Broker:         s.Broker,
+			Topic:          topic,
+			ClientId:       fmt.Sprintf("%s-%d", clientIdPrefix, i),
+			CleanSession:   cleanSession,
+			Username:       s.Username,
+			Password:       s.Password,
+			Qos:            s.QosLevel,
+			TimeoutSeconds: s.TimeoutSeconds,
+		})
+	}
+	return shardInfos
+}
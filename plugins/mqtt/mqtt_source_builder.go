@@ -0,0 +1,52 @@
+package mqtt
+
+/*
+This file is only for the builder API.
+*/
+
+// New reads the messages of the topic, which can have the "+" and "#"
+// wildcards, from the broker, e.g. "tcp://localhost:1883".
+func New(broker, topic string) *MqttSource {
+	return &MqttSource{
+		Broker:         broker,
+		Topic:          topic,
+		ShardCount:     1,
+		TimeoutSeconds: 16,
+
+		prefix: "mqtt",
+	}
+}
+
+// ClientId sets the prefix of the client ids, followed by the shard id.
+// The sessions of fixed client ids persist across runs, so a run continues
+// with the messages queued since the previous one. The prefix must not be
+// used by other flows, or their clients disconnect each other.
+func (s *MqttSource) ClientId(clientId string) *MqttSource {
+	s.ClientIdPrefix = clientId
+	return s
+}
+
+func (s *MqttSource) Auth(username, password string) *MqttSource {
+	s.Username, s.Password = username, password
+	return s
+}
+
+// Qos sets the quality of service of the subscription, 0, 1 or 2.
+func (s *MqttSource) Qos(qos byte) *MqttSource {
+	s.QosLevel = qos
+	return s
+}
+
+// Shards reads the topic with the number of shards, as a shared subscription
+// of the group, "$share/<group>/<topic>", so the broker, which needs to
+// support shared subscriptions, delivers each message to one of the shards.
+// The group defaults to "gleam".
+func (s *MqttSource) Shards(count int, group string) *MqttSource {
+	s.ShardCount, s.Group = count, group
+	return s
+}
+
+func (s *MqttSource) Timeout(seconds int) *MqttSource {
+	s.TimeoutSeconds = seconds
+	return s
+}
@@ -0,0 +1,91 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+func TestShardInfos(t *testing.T) {
+	single := New("tcp://localhost:1883", "sensors/#").shardInfos()
+	if len(single) != 1 || single[0].Topic != "sensors/#" {
+		t.Errorf("subscribed one shard to %+v", single)
+	}
+
+	for _, group := range []string{"", "g"} {
+		infos := New("tcp://localhost:1883", "sensors/#").Shards(3, group).shardInfos()
+		expected := "$share/g/sensors/#"
+		if group == "" {
+			expected = "$share/gleam/sensors/#"
+		}
+		for _, info := range infos {
+			if info.Topic != expected {
+				t.Errorf("group %q: subscribed to %s, expected %s", group, info.Topic, expected)
+			}
+		}
+	}
+
+	// the client ids of different runs do not collide
+	first := New("tcp://localhost:1883", "t").Shards(2, "").shardInfos()
+	second := New("tcp://localhost:1883", "t").Shards(2, "").shardInfos()
+	seen := make(map[string]bool)
+	for _, info := range append(first, second...) {
+		if seen[info.ClientId] {
+			t.Errorf("client id %s is used twice", info.ClientId)
+		}
+		seen[info.ClientId] = true
+		if !info.CleanSession {
+			t.Errorf("generated client id %s keeps its session", info.ClientId)
+		}
+	}
+
+	fixed := New("tcp://localhost:1883", "t").ClientId("reader").Shards(2, "").shardInfos()
+	if fixed[0].ClientId != "reader-0" || fixed[1].ClientId != "reader-1" || fixed[0].CleanSession {
+		t.Errorf("fixed client ids %+v %+v", fixed[0], fixed[1])
+	}
+}
+
+type testMessage struct {
+	paho.Message
+	payload string
+}
+
+func TestMessageQueue(t *testing.T) {
+	q := newMessageQueue()
+
+	// pushing never blocks the subscription callback
+	pushed := make(chan bool)
+	go func() {
+		for i := 0; i < 10000; i++ {
+			q.push(&testMessage{payload: fmt.Sprint(i)})
+		}
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("push blocked")
+	}
+
+	var popped []string
+	for i := 0; i < 10000; i++ {
+		popped = append(popped, q.pop().(*testMessage).payload)
+	}
+	for i, payload := range popped {
+		if payload != fmt.Sprint(i) {
+			t.Fatalf("popped %s at %d: %s", payload, i, strings.Join(popped[:i+1], ","))
+		}
+	}
+
+	// pop waits for the next message
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.push(&testMessage{payload: "late"})
+	}()
+	if msg := q.pop().(*testMessage); msg.payload != "late" {
+		t.Errorf("popped %s", msg.payload)
+	}
+}
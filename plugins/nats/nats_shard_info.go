@@ -0,0 +1,83 @@
+package nats
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lovelly/gleam/gio"
+	gonats "github.com/nats-io/go-nats"
+)
+
+type NatsShardInfo struct {
+	Servers        string
+	Subject        string
+	QueueGroup     string
+	Username       string
+	Password       string
+	TimeoutSeconds int
+}
+
+var (
+	MapperReadShard = gio.RegisterMapper(readShard)
+)
+
+func init() {
+	gob.Register(NatsShardInfo{})
+}
+
+func readShard(row []interface{}) error {
+	encodedShardInfo := row[0].([]byte)
+	return decodeShardInfo(encodedShardInfo).ReadSplit()
+}
+
+func (s *NatsShardInfo) ReadSplit() error {
+
+	options := []gonats.Option{
+		gonats.Timeout(time.Duration(s.TimeoutSeconds) * time.Second),
+		gonats.MaxReconnects(-1),
+	}
+	if s.Username != "" {
+		options = append(options, gonats.UserInfo(s.Username, s.Password))
+	}
+
+	nc, err := gonats.Connect(s.Servers, options...)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to %s: %v", s.Servers, err)
+	}
+	defer nc.Close()
+
+	messages := make(chan *gonats.Msg, 1024)
+	sub, err := nc.ChanQueueSubscribe(s.Subject, s.QueueGroup, messages)
+	if err != nil {
+		return fmt.Errorf("Failed to subscribe to %s: %v", s.Subject, err)
+	}
+	defer sub.Unsubscribe()
+
+	for msg := range messages {
+		gio.Emit(msg.Subject, msg.Data)
+	}
+
+	return nil
+}
+
+func decodeShardInfo(encodedShardInfo []byte) *NatsShardInfo {
+	network := bytes.NewBuffer(encodedShardInfo)
+	dec := gob.NewDecoder(network)
+	var p NatsShardInfo
+	if err := dec.Decode(&p); err != nil {
+		log.Fatal("decode shard info", err)
+	}
+	return &p
+}
+
+func encodeShardInfo(shardInfo *NatsShardInfo) []byte {
+	var network bytes.Buffer
+	enc := gob.NewEncoder(&network)
+	if err := enc.Encode(shardInfo); err != nil {
+		log.Fatal("encode shard info:", err)
+	}
+	return network.Bytes()
+}
@@ -0,0 +1,50 @@
+package nats
+
+import (
+	"io"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/util"
+)
+
+type NatsSource struct {
+	Servers        string
+	Subject        string
+	QueueGroup     string
+	Username       string
+	Password       string
+	ShardCount     int
+	TimeoutSeconds int
+
+	prefix string
+}
+
+// Generate generates data shard info,
+// partitions them via round robin,
+// and reads each shard on each executor.
+// Each row has the subject and the data of a message.
+func (s *NatsSource) Generate(f *flow.Flow) *flow.Dataset {
+	return s.genShardInfos(f).RoundRobin(s.prefix, s.ShardCount).Map(s.prefix+".Read", MapperReadShard)
+}
+
+func (s *NatsSource) genShardInfos(f *flow.Flow) *flow.Dataset {
+	return f.Source(s.prefix+".list", func(writer io.Writer, stats *pb.InstructionStat) error {
+
+		stats.InputCounter++
+
+		for i := 0; i < s.ShardCount; i++ {
+			stats.OutputCounter++
+			util.NewRow(util.Now(), encodeShardInfo(&NatsShardInfo{
+				Servers:        s.Servers,
+				Subject:        s.Subject,
+				QueueGroup:     s.QueueGroup,
+				Username:       s.Username,
+				Password:       s.Password,
+				TimeoutSeconds: s.TimeoutSeconds,
+			})).WriteTo(writer)
+		}
+
+		return nil
+	})
+}
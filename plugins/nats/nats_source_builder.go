@@ -0,0 +1,42 @@
+package nats
+
+/*
+This file is only for the builder API.
+*/
+
+// New reads the messages of the subject, which can have the "*" and ">"
+// wildcards, from the servers, e.g. "nats://localhost:4222".
+func New(servers, subject string) *NatsSource {
+	return &NatsSource{
+		Servers:        servers,
+		Subject:        subject,
+		QueueGroup:     "gleam",
+		ShardCount:     1,
+		TimeoutSeconds: 16,
+
+		prefix: "nats",
+	}
+}
+
+// Shards reads the subject with the number of shards, which subscribe in
+// the queue group, so the server delivers each message to one of the shards.
+// Other flows, or other consumers, in the same queue group share the messages.
+func (s *NatsSource) Shards(count int) *NatsSource {
+	s.ShardCount = count
+	return s
+}
+
+func (s *NatsSource) Queue(group string) *NatsSource {
+	s.QueueGroup = group
+	return s
+}
+
+func (s *NatsSource) Auth(username, password string) *NatsSource {
+	s.Username, s.Password = username, password
+	return s
+}
+
+func (s *NatsSource) Timeout(seconds int) *NatsSource {
+	s.TimeoutSeconds = seconds
+	return s
+}
@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/file"
+	_ "github.com/lovelly/gleam/plugins/file/parquet" // the staging files
+)
+
+// SnowflakeSink writes the rows as parquet files to the staging folder, and
+// copies them into the table after all tasks succeeded.
+type SnowflakeSink struct {
+	Dsn           string
+	Table         string
+	Stage         string
+	StagingFolder string
+	FieldNames    []string
+	FieldTypes    []string
+}
+
+// copyFileLimit is the number of files one COPY statement can list.
+const copyFileLimit = 1000
+
+// Sink writes the fields of the rows to the columns of the same names,
+// matched case insensitively. The stage and its staging folder should be empty.
+func Sink(dsn, table, stage, stagingFolder string, fieldNames ...string) *SnowflakeSink {
+	return &SnowflakeSink{
+		Dsn:           dsn,
+		Table:         table,
+		Stage:         strings.TrimSuffix(stage, "/"),
+		StagingFolder: strings.TrimSuffix(stagingFolder, "/"),
+		FieldNames:    fieldNames,
+	}
+}
+
+// SetFieldTypes sets the parquet types of the staged columns, e.g. INT64 for
+// NUMBER, DOUBLE for FLOAT, BOOLEAN, TIMESTAMP_MILLIS for TIMESTAMP_NTZ, or
+// UTF8 for VARCHAR, for the fields not always having values of one Go type.
+// The columns without a type have the types of their values.
+func (s *SnowflakeSink) SetFieldTypes(fieldTypes ...string) *SnowflakeSink {
+	s.FieldTypes = fieldTypes
+	return s
+}
+
+func (s *SnowflakeSink) Save(d *flow.Dataset) *flow.Dataset {
+	return s.fileSink().Save(d)
+}
+
+// fileSink writes the staging files, with the parquet types of the columns.
+func (s *SnowflakeSink) fileSink() *file.FileSink {
+	return file.ParquetSink(s.StagingFolder, s.FieldNames...).SetFieldTypes(s.FieldTypes...).OnCommit(s.copyInto)
+}
+
+func (s *SnowflakeSink) copyInto(files []string) error {
+	db, err := sql.Open("snowflake", s.Dsn)
+	if err != nil {
+		return fmt.Errorf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	// copy all files or none
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to begin a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for len(files) > 0 {
+		n := len(files)
+		if n > copyFileLimit {
+			n = copyFileLimit
+		}
+		statement := fmt.Sprintf("COPY INTO %s FROM %s/ FILES = ('%s') FILE_FORMAT = (TYPE = PARQUET) MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE",
+			s.Table, s.Stage, strings.Join(files[:n], "', '"))
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Failed to copy into %s: %v", s.Table, err)
+		}
+		files = files[n:]
+	}
+	return tx.Commit()
+}
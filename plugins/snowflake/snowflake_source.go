@@ -0,0 +1,68 @@
+// Package snowflake reads Snowflake queries by unloading them as parquet
+// files to an external stage, which are read in parallel by the file plugin,
+// and writes rows by copying the parquet files of a file sink into a table.
+//
+// The stage is an external stage, e.g. "@gleam_stage/run1", whose location
+// is the staging folder, e.g. "s3://bucket/staging/run1", which the file
+// plugin reads and writes.
+package snowflake
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/file"
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+type SnowflakeSource struct {
+	Dsn            string
+	Sql            string
+	Stage          string
+	StagingFolder  string
+	PartitionCount int
+}
+
+// Query reads the result of the query, with the dsn of the gosnowflake
+// driver, e.g. "user:password@account/database/schema?warehouse=wh".
+// The stage and its staging folder should be empty.
+func Query(dsn, sql, stage, stagingFolder string) *SnowflakeSource {
+	return &SnowflakeSource{
+		Dsn:            dsn,
+		Sql:            sql,
+		Stage:          strings.TrimSuffix(stage, "/"),
+		StagingFolder:  strings.TrimSuffix(stagingFolder, "/"),
+		PartitionCount: 8,
+	}
+}
+
+func (s *SnowflakeSource) Partitions(count int) *SnowflakeSource {
+	s.PartitionCount = count
+	return s
+}
+
+// Generate unloads the query result on the driver,
+// and reads the unloaded files on the executors. The flow fails if the
+// unload fails.
+func (s *SnowflakeSource) Generate(f *flow.Flow) *flow.Dataset {
+	if err := s.unload(); err != nil {
+		return f.FailedSource("snowflake", fmt.Errorf("SnowflakeSource failed to unload: %v", err))
+	}
+	return file.Parquet(s.StagingFolder+"/*.parquet", s.PartitionCount).Generate(f)
+}
+
+func (s *SnowflakeSource) unload() error {
+	db, err := sql.Open("snowflake", s.Dsn)
+	if err != nil {
+		return fmt.Errorf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	statement := fmt.Sprintf("COPY INTO %s/ FROM (%s) FILE_FORMAT = (TYPE = PARQUET) HEADER = TRUE OVERWRITE = TRUE", s.Stage, s.Sql)
+	if _, err := db.Exec(statement); err != nil {
+		return fmt.Errorf("Failed to %s: %v", statement, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package snowflake
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+)
+
+func TestStagingFileTypes(t *testing.T) {
+	s := Sink("dsn", "t", "@stage/run1/", "s3://bucket/run1", "id", "at").SetFieldTypes("INT64", "TIMESTAMP_MILLIS").fileSink()
+	if s.FileType != "parquet" || s.Folder != "s3://bucket/run1" {
+		t.Errorf("stages %s files in %s", s.FileType, s.Folder)
+	}
+	if !reflect.DeepEqual(s.FieldTypes, []string{"INT64", "TIMESTAMP_MILLIS"}) {
+		t.Errorf("stages columns of types %v", s.FieldTypes)
+	}
+}
+
+func TestFailedUnload(t *testing.T) {
+	ds := Query("not a dsn", "select 1", "@stage/run1", "s3://bucket/run1").Generate(flow.New("snowflake"))
+	if ds == nil {
+		t.Fatalf("generated a nil dataset")
+	}
+	if _, err := ds.Collect(context.Background()); err == nil {
+		t.Errorf("read a failed unload")
+	}
+}
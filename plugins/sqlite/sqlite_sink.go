@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+// SqliteSink is a flow.Sink inserting the rows into a table, each task in
+// one transaction, so the rows of a failed task are rolled back.
+type SqliteSink struct {
+	Path    string
+	Table   string
+	Columns []string
+}
+
+func init() {
+	gob.Register(&SqliteSink{})
+}
+
+// Sink inserts the rows into the columns of the table, or into all of its
+// columns if none is set. The table is created with the columns if it does
+// not exist.
+func Sink(path, table string, columns ...string) *SqliteSink {
+	return &SqliteSink{
+		Path:    path,
+		Table:   table,
+		Columns: columns,
+	}
+}
+
+func (s *SqliteSink) OpenTask(taskId int) (flow.TaskWriter, error) {
+	// the tasks wait for the write lock of each other
+	db, err := sql.Open("sqlite3", s.Path+"?_busy_timeout=60000")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %v", s.Path, err)
+	}
+	if len(s.Columns) > 0 {
+		if _, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", s.Table, strings.Join(s.Columns, ", "))); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("Failed to create table %s: %v", s.Table, err)
+		}
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to begin a transaction on %s: %v", s.Path, err)
+	}
+	return &rowInserter{sink: s, db: db, tx: tx}, nil
+}
+
+type rowInserter struct {
+	sink *SqliteSink
+	db   *sql.DB
+	tx   *sql.Tx
+	stmt *sql.Stmt
+}
+
+func (w *rowInserter) Write(row *util.Row) error {
+	values := append(append([]interface{}{}, row.K...), row.V...)
+	if w.stmt == nil {
+		insert := fmt.Sprintf("INSERT INTO %s VALUES (%s)", w.sink.Table, placeholders(len(values)))
+		if len(w.sink.Columns) > 0 {
+			insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", w.sink.Table, strings.Join(w.sink.Columns, ", "), placeholders(len(values)))
+		}
+		stmt, err := w.tx.Prepare(insert)
+		if err != nil {
+			return fmt.Errorf("Failed to prepare %s: %v", insert, err)
+		}
+		w.stmt = stmt
+	}
+	if _, err := w.stmt.Exec(values...); err != nil {
+		return fmt.Errorf("Failed to insert into %s: %v", w.sink.Table, err)
+	}
+	return nil
+}
+
+func (w *rowInserter) CommitTask() error {
+	defer w.db.Close()
+	if err := w.tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to commit to %s: %v", w.sink.Table, err)
+	}
+	return nil
+}
+
+func (w *rowInserter) AbortTask() error {
+	defer w.db.Close()
+	return w.tx.Rollback()
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", count), ",")
+}
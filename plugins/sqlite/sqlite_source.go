@@ -0,0 +1,131 @@
+// Package sqlite reads tables of sqlite databases in shards by rowid ranges,
+// and writes rows into tables, for small local pipelines and tests without
+// a database server.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SqliteSource is a flow.Source of the rows of a table, read in shards of
+// rowid ranges. The database file needs to be on the executors, e.g. in
+// the local mode.
+type SqliteSource struct {
+	Path         string
+	Table        string
+	SelectClause string
+	WhereClause  string
+	Shards       int
+}
+
+func init() {
+	gob.Register(&SqliteSource{})
+}
+
+// Source reads all columns of the table in the database file.
+func Source(path, table string) *SqliteSource {
+	return &SqliteSource{
+		Path:         path,
+		Table:        table,
+		SelectClause: "*",
+		Shards:       1,
+	}
+}
+
+func (s *SqliteSource) Select(selectClause string) *SqliteSource {
+	s.SelectClause = selectClause
+	return s
+}
+
+func (s *SqliteSource) Where(whereClause string) *SqliteSource {
+	s.WhereClause = whereClause
+	return s
+}
+
+// SetShards splits the rowids of the table evenly into the number of shards.
+func (s *SqliteSource) SetShards(count int) *SqliteSource {
+	s.Shards = count
+	return s
+}
+
+func (s *SqliteSource) ShardCount() (int, error) {
+	return s.Shards, nil
+}
+
+func (s *SqliteSource) OpenShard(shardIndex int) (flow.RowIterator, error) {
+	db, err := sql.Open("sqlite3", s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %v", s.Path, err)
+	}
+
+	var minRowId, maxRowId sql.NullInt64
+	if err := db.QueryRow(fmt.Sprintf("SELECT min(rowid), max(rowid) FROM %s", s.Table)).Scan(&minRowId, &maxRowId); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to read the rowids of %s: %v", s.Table, err)
+	}
+	start, stop := shardRange(minRowId.Int64, maxRowId.Int64+1, s.Shards, shardIndex)
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE rowid >= ? AND rowid < ?", s.SelectClause, s.Table)
+	if s.WhereClause != "" {
+		query += " AND (" + s.WhereClause + ")"
+	}
+	rows, err := db.Query(query, start, stop)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to query %s: %v", query, err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		db.Close()
+		return nil, fmt.Errorf("Failed to read the columns of %s: %v", query, err)
+	}
+	return &rowReader{db: db, rows: rows, columnCount: len(columns)}, nil
+}
+
+// shardRange returns the rowid range [start, stop) of the shard.
+func shardRange(minRowId, maxRowId int64, shardCount, shardIndex int) (start, stop int64) {
+	size := (maxRowId - minRowId + int64(shardCount) - 1) / int64(shardCount)
+	start = minRowId + int64(shardIndex)*size
+	stop = start + size
+	if shardIndex == shardCount-1 {
+		stop = maxRowId
+	}
+	return start, stop
+}
+
+type rowReader struct {
+	db          *sql.DB
+	rows        *sql.Rows
+	columnCount int
+}
+
+func (r *rowReader) Next() (*util.Row, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	values := make([]interface{}, r.columnCount)
+	pointers := make([]interface{}, r.columnCount)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+	return util.NewRow(util.Now(), values...), nil
+}
+
+func (r *rowReader) Close() error {
+	r.rows.Close()
+	return r.db.Close()
+}
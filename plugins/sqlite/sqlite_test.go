@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lovelly/gleam/util"
+)
+
+func TestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gleam_sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.db")
+
+	sink := Sink(path, "words", "word", "count")
+	for taskId, rowCount := range []int{10, 7, 5} {
+		task, err := sink.OpenTask(taskId)
+		if err != nil {
+			t.Fatalf("open task: %v", err)
+		}
+		for i := 0; i < rowCount; i++ {
+			if err := task.Write(util.NewRow(util.Now(), "w", int64(i))); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		if taskId == 2 {
+			err = task.AbortTask()
+		} else {
+			err = task.CommitTask()
+		}
+		if err != nil {
+			t.Fatalf("close task %d: %v", taskId, err)
+		}
+	}
+
+	source := Source(path, "words").Select("count").Where("count < 8").SetShards(4)
+	var count, sum int64
+	for shard := 0; shard < 4; shard++ {
+		iterator, err := source.OpenShard(shard)
+		if err != nil {
+			t.Fatalf("open shard: %v", err)
+		}
+		for {
+			row, err := iterator.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("next: %v", err)
+			}
+			count++
+			sum += row.K[0].(int64)
+		}
+		iterator.Close()
+	}
+	// the aborted task is rolled back
+	if count != 15 || sum != 28+21 {
+		t.Errorf("read %d rows of sum %d", count, sum)
+	}
+}
@@ -0,0 +1,79 @@
+package tpch
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// random is a splitmix64 generator seeded by the table and the key, so a
+// row is generated the same wherever its key falls into the shards.
+type random struct {
+	state uint64
+}
+
+func newRandom(tableSeed uint64, key int64) *random {
+	r := &random{state: tableSeed*0x9E3779B97F4A7C15 ^ uint64(key)}
+	r.next()
+	return r
+}
+
+func (r *random) next() uint64 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// int returns an integer in [min, max].
+func (r *random) int(min, max int64) int64 {
+	return min + int64(r.next()%uint64(max-min+1))
+}
+
+// money returns an amount in [min, max] with 2 decimals.
+func (r *random) money(min, max float64) float64 {
+	return float64(r.int(int64(min*100), int64(max*100))) / 100
+}
+
+func (r *random) pick(values []string) string {
+	return values[r.next()%uint64(len(values))]
+}
+
+// text returns a comment of the number of words.
+func (r *random) text(minWords, maxWords int64) string {
+	words := make([]string, r.int(minWords, maxWords))
+	for i := range words {
+		words[i] = r.pick(commentWords)
+	}
+	return strings.Join(words, " ")
+}
+
+func (r *random) phone(nationKey int64) string {
+	return fmt.Sprintf("%02d-%03d-%03d-%04d", nationKey+10, r.int(100, 999), r.int(100, 999), r.int(1000, 9999))
+}
+
+func (r *random) address() string {
+	b := make([]byte, r.int(10, 40))
+	for i := range b {
+		b[i] = addressChars[r.next()%uint64(len(addressChars))]
+	}
+	return string(b)
+}
+
+const addressChars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ,"
+
+var (
+	startDate   = time.Date(1992, 1, 1, 0, 0, 0, 0, time.UTC)
+	currentDate = time.Date(1995, 6, 17, 0, 0, 0, 0, time.UTC)
+	endDate     = time.Date(1998, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func round2(x float64) float64 {
+	return math.Floor(x*100+0.5) / 100
+}
@@ -0,0 +1,95 @@
+// Package tpch generates the tables of a TPC-H style data set on the
+// executors, to benchmark cluster setups and the SQL layer without hauling
+// external data sets around.
+//
+// The rows are generated from their keys, so every run, and every shard
+// layout, generates the same tables for the same scale factor.
+package tpch
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/util"
+)
+
+// TpchTable is a flow.Source of a generated table, in shards of key ranges.
+type TpchTable struct {
+	Name        string
+	ScaleFactor float64
+	Shards      int
+}
+
+func init() {
+	gob.Register(&TpchTable{})
+}
+
+// Table generates the table, one of Tables, with the scale factor.
+// Scale factor 1 generates about 1GB of data in total.
+func Table(name string, scaleFactor float64) *TpchTable {
+	return &TpchTable{
+		Name:        name,
+		ScaleFactor: scaleFactor,
+		Shards:      1,
+	}
+}
+
+// SetShards splits the keys of the table evenly into the number of shards.
+func (t *TpchTable) SetShards(count int) *TpchTable {
+	t.Shards = count
+	return t
+}
+
+// Fields returns the column names of the table.
+func (t *TpchTable) Fields() []string {
+	return Fields(t.Name)
+}
+
+func (t *TpchTable) ShardCount() (int, error) {
+	if _, ok := tables[t.Name]; !ok {
+		return 0, fmt.Errorf("Unknown table %s, expecting one of %v", t.Name, Tables)
+	}
+	return t.Shards, nil
+}
+
+func (t *TpchTable) OpenShard(shardIndex int) (flow.RowIterator, error) {
+	table, ok := tables[t.Name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown table %s, expecting one of %v", t.Name, Tables)
+	}
+	g := &generator{scaleFactor: t.ScaleFactor}
+	count := table.count(g)
+	return &tableIterator{
+		table:     table,
+		generator: g,
+		key:       1 + count*int64(shardIndex)/int64(t.Shards),
+		stopKey:   1 + count*int64(shardIndex+1)/int64(t.Shards),
+	}, nil
+}
+
+type tableIterator struct {
+	table     *table
+	generator *generator
+	key       int64
+	stopKey   int64
+	pending   [][]interface{}
+}
+
+func (it *tableIterator) Next() (*util.Row, error) {
+	for len(it.pending) == 0 {
+		if it.key >= it.stopKey {
+			return nil, io.EOF
+		}
+		it.pending = it.table.rows(it.generator, it.key)
+		it.key++
+	}
+	values := it.pending[0]
+	it.pending = it.pending[1:]
+	return util.NewRow(util.Now(), values...), nil
+}
+
+func (it *tableIterator) Close() error {
+	return nil
+}
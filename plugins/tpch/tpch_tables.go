@@ -0,0 +1,304 @@
+package tpch
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tables are the names of the generated tables.
+var Tables = []string{"region", "nation", "supplier", "customer", "part", "partsupp", "orders", "lineitem"}
+
+// table generates the rows of a key. Most tables have one row per key;
+// partsupp has 4 rows per part, and lineitem 1 to 7 rows per order.
+type table struct {
+	fields []string
+	count  func(g *generator) int64
+	rows   func(g *generator, key int64) [][]interface{}
+}
+
+// the seeds of the random values of the tables
+const (
+	regionSeed = iota + 1
+	nationSeed
+	supplierSeed
+	customerSeed
+	partSeed
+	partsuppSeed
+	ordersSeed
+	lineitemSeed
+	orderDateSeed
+)
+
+var tables = map[string]*table{
+	"region": {
+		fields: []string{"r_regionkey", "r_name", "r_comment"},
+		count:  func(g *generator) int64 { return int64(len(regions)) },
+		rows:   (*generator).region,
+	},
+	"nation": {
+		fields: []string{"n_nationkey", "n_name", "n_regionkey", "n_comment"},
+		count:  func(g *generator) int64 { return int64(len(nations)) },
+		rows:   (*generator).nation,
+	},
+	"supplier": {
+		fields: []string{"s_suppkey", "s_name", "s_address", "s_nationkey", "s_phone", "s_acctbal", "s_comment"},
+		count:  (*generator).supplierCount,
+		rows:   (*generator).supplier,
+	},
+	"customer": {
+		fields: []string{"c_custkey", "c_name", "c_address", "c_nationkey", "c_phone", "c_acctbal", "c_mktsegment", "c_comment"},
+		count:  (*generator).customerCount,
+		rows:   (*generator).customer,
+	},
+	"part": {
+		fields: []string{"p_partkey", "p_name", "p_mfgr", "p_brand", "p_type", "p_size", "p_container", "p_retailprice", "p_comment"},
+		count:  (*generator).partCount,
+		rows:   (*generator).part,
+	},
+	"partsupp": {
+		fields: []string{"ps_partkey", "ps_suppkey", "ps_availqty", "ps_supplycost", "ps_comment"},
+		count:  (*generator).partCount,
+		rows:   (*generator).partsupp,
+	},
+	"orders": {
+		fields: []string{"o_orderkey", "o_custkey", "o_orderstatus", "o_totalprice", "o_orderdate", "o_orderpriority", "o_clerk", "o_shippriority", "o_comment"},
+		count:  (*generator).orderCount,
+		rows:   (*generator).orders,
+	},
+	"lineitem": {
+		fields: []string{"l_orderkey", "l_partkey", "l_suppkey", "l_linenumber", "l_quantity", "l_extendedprice", "l_discount", "l_tax",
+			"l_returnflag", "l_linestatus", "l_shipdate", "l_commitdate", "l_receiptdate", "l_shipinstruct", "l_shipmode", "l_comment"},
+		count: (*generator).orderCount,
+		rows:  (*generator).lineitem,
+	},
+}
+
+// Fields returns the column names of the table, or nil for unknown tables.
+func Fields(name string) []string {
+	if t, ok := tables[name]; ok {
+		return t.fields
+	}
+	return nil
+}
+
+type generator struct {
+	scaleFactor float64
+}
+
+func (g *generator) scaled(n float64) int64 {
+	count := int64(n * g.scaleFactor)
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+func (g *generator) supplierCount() int64 { return g.scaled(10000) }
+func (g *generator) customerCount() int64 { return g.scaled(150000) }
+func (g *generator) partCount() int64     { return g.scaled(200000) }
+func (g *generator) orderCount() int64    { return g.scaled(1500000) }
+
+func (g *generator) region(key int64) [][]interface{} {
+	r := newRandom(regionSeed, key)
+	return [][]interface{}{{key - 1, regions[key-1], r.text(5, 15)}}
+}
+
+func (g *generator) nation(key int64) [][]interface{} {
+	r := newRandom(nationSeed, key)
+	n := nations[key-1]
+	return [][]interface{}{{key - 1, n.name, n.regionKey, r.text(5, 15)}}
+}
+
+func (g *generator) supplier(key int64) [][]interface{} {
+	r := newRandom(supplierSeed, key)
+	nationKey := r.int(0, int64(len(nations)-1))
+	return [][]interface{}{{
+		key,
+		fmt.Sprintf("Supplier#%09d", key),
+		r.address(),
+		nationKey,
+		r.phone(nationKey),
+		r.money(-999.99, 9999.99),
+		r.text(5, 15),
+	}}
+}
+
+func (g *generator) customer(key int64) [][]interface{} {
+	r := newRandom(customerSeed, key)
+	nationKey := r.int(0, int64(len(nations)-1))
+	return [][]interface{}{{
+		key,
+		fmt.Sprintf("Customer#%09d", key),
+		r.address(),
+		nationKey,
+		r.phone(nationKey),
+		r.money(-999.99, 9999.99),
+		r.pick(segments),
+		r.text(5, 20),
+	}}
+}
+
+func (g *generator) part(key int64) [][]interface{} {
+	r := newRandom(partSeed, key)
+	name := r.pick(colors)
+	for i := 0; i < 4; i++ {
+		name += " " + r.pick(colors)
+	}
+	m := r.int(1, 5)
+	return [][]interface{}{{
+		key,
+		name,
+		fmt.Sprintf("Manufacturer#%d", m),
+		fmt.Sprintf("Brand#%d%d", m, r.int(1, 5)),
+		r.pick(typeSizes) + " " + r.pick(typeFinishes) + " " + r.pick(typeMaterials),
+		r.int(1, 50),
+		r.pick(containerSizes) + " " + r.pick(containerTypes),
+		retailPrice(key),
+		r.text(2, 6),
+	}}
+}
+
+func (g *generator) partsupp(key int64) [][]interface{} {
+	r := newRandom(partsuppSeed, key)
+	var rows [][]interface{}
+	for i := int64(0); i < 4; i++ {
+		rows = append(rows, []interface{}{
+			key,
+			g.partSupplier(key, i),
+			r.int(1, 9999),
+			r.money(1, 1000),
+			r.text(10, 30),
+		})
+	}
+	return rows
+}
+
+// partSupplier is the i-th of the 4 suppliers of the part.
+func (g *generator) partSupplier(partKey, i int64) int64 {
+	s := g.supplierCount()
+	return (partKey+i*(s/4+(partKey-1)/s))%s + 1
+}
+
+func retailPrice(partKey int64) float64 {
+	return float64(90000+(partKey/10)%20001+100*(partKey%1000)) / 100
+}
+
+// orders totals the line items of the order, so both tables agree.
+func (g *generator) orders(key int64) [][]interface{} {
+	r := newRandom(ordersSeed, key)
+	lines := g.lineitem(key)
+	var totalPrice float64
+	shipped, open := 0, 0
+	for _, line := range lines {
+		price, discount, tax := line[5].(float64), line[6].(float64), line[7].(float64)
+		totalPrice += price * (1 + tax) * (1 - discount)
+		if line[9] == "F" {
+			shipped++
+		} else {
+			open++
+		}
+	}
+	status := "P"
+	if open == 0 {
+		status = "F"
+	} else if shipped == 0 {
+		status = "O"
+	}
+	return [][]interface{}{{
+		key,
+		r.int(1, g.customerCount()),
+		status,
+		round2(totalPrice),
+		formatDate(g.orderDate(key)),
+		r.pick(priorities),
+		fmt.Sprintf("Clerk#%09d", r.int(1, g.scaled(1000))),
+		int64(0),
+		r.text(5, 15),
+	}}
+}
+
+func (g *generator) orderDate(orderKey int64) time.Time {
+	r := newRandom(orderDateSeed, orderKey)
+	days := int64(endDate.Sub(startDate).Hours()/24) - 151
+	return startDate.AddDate(0, 0, int(r.int(0, days)))
+}
+
+func (g *generator) lineitem(key int64) [][]interface{} {
+	r := newRandom(lineitemSeed, key)
+	orderDate := g.orderDate(key)
+	var rows [][]interface{}
+	for lineNumber, count := int64(1), r.int(1, 7); lineNumber <= count; lineNumber++ {
+		partKey := r.int(1, g.partCount())
+		quantity := r.int(1, 50)
+		shipDate := orderDate.AddDate(0, 0, int(r.int(1, 121)))
+		commitDate := orderDate.AddDate(0, 0, int(r.int(30, 90)))
+		receiptDate := shipDate.AddDate(0, 0, int(r.int(1, 30)))
+		returnFlag := "N"
+		if !receiptDate.After(currentDate) {
+			returnFlag = r.pick([]string{"R", "A"})
+		}
+		lineStatus := "O"
+		if !shipDate.After(currentDate) {
+			lineStatus = "F"
+		}
+		rows = append(rows, []interface{}{
+			key,
+			partKey,
+			g.partSupplier(partKey, r.int(0, 3)),
+			lineNumber,
+			quantity,
+			round2(float64(quantity) * retailPrice(partKey)),
+			float64(r.int(0, 10)) / 100,
+			float64(r.int(0, 8)) / 100,
+			returnFlag,
+			lineStatus,
+			formatDate(shipDate),
+			formatDate(commitDate),
+			formatDate(receiptDate),
+			r.pick(shipInstructions),
+			r.pick(shipModes),
+			r.text(2, 8),
+		})
+	}
+	return rows
+}
+
+var regions = []string{"AFRICA", "AMERICA", "ASIA", "EUROPE", "MIDDLE EAST"}
+
+var nations = []struct {
+	name      string
+	regionKey int64
+}{
+	{"ALGERIA", 0}, {"ARGENTINA", 1}, {"BRAZIL", 1}, {"CANADA", 1}, {"EGYPT", 4},
+	{"ETHIOPIA", 0}, {"FRANCE", 3}, {"GERMANY", 3}, {"INDIA", 2}, {"INDONESIA", 2},
+	{"IRAN", 4}, {"IRAQ", 4}, {"JAPAN", 2}, {"JORDAN", 4}, {"KENYA", 0},
+	{"MOROCCO", 0}, {"MOZAMBIQUE", 0}, {"PERU", 1}, {"CHINA", 2}, {"ROMANIA", 3},
+	{"SAUDI ARABIA", 4}, {"VIETNAM", 2}, {"RUSSIA", 3}, {"UNITED KINGDOM", 3}, {"UNITED STATES", 1},
+}
+
+var (
+	segments         = []string{"AUTOMOBILE", "BUILDING", "FURNITURE", "MACHINERY", "HOUSEHOLD"}
+	priorities       = []string{"1-URGENT", "2-HIGH", "3-MEDIUM", "4-NOT SPECIFIED", "5-LOW"}
+	shipInstructions = []string{"DELIVER IN PERSON", "COLLECT COD", "NONE", "TAKE BACK RETURN"}
+	shipModes        = []string{"REG AIR", "AIR", "RAIL", "SHIP", "TRUCK", "MAIL", "FOB"}
+	typeSizes        = []string{"STANDARD", "SMALL", "MEDIUM", "LARGE", "ECONOMY", "PROMO"}
+	typeFinishes     = []string{"ANODIZED", "BURNISHED", "PLATED", "POLISHED", "BRUSHED"}
+	typeMaterials    = []string{"TIN", "NICKEL", "BRASS", "STEEL", "COPPER"}
+	containerSizes   = []string{"SM", "LG", "MED", "JUMBO", "WRAP"}
+	containerTypes   = []string{"CASE", "BOX", "BAG", "JAR", "PKG", "PACK", "CAN", "DRUM"}
+	colors           = []string{"almond", "antique", "aquamarine", "azure", "beige", "bisque", "black", "blanched", "blue",
+		"blush", "brown", "burlywood", "burnished", "chartreuse", "chiffon", "chocolate", "coral", "cornflower",
+		"cornsilk", "cream", "cyan", "dark", "deep", "dim", "dodger", "drab", "firebrick", "floral", "forest",
+		"frosted", "gainsboro", "ghost", "goldenrod", "green", "grey", "honeydew", "hot", "indian", "ivory",
+		"khaki", "lace", "lavender", "lawn", "lemon", "light", "lime", "linen", "magenta", "maroon", "medium",
+		"metallic", "midnight", "mint", "misty", "moccasin", "navajo", "navy", "olive", "orange", "orchid",
+		"pale", "papaya", "peach", "peru", "pink", "plum", "powder", "puff", "purple", "red", "rose", "rosy",
+		"royal", "saddle", "salmon", "sandy", "seashell", "sienna", "sky", "slate", "smoke", "snow", "spring",
+		"steel", "tan", "thistle", "tomato", "turquoise", "violet", "wheat", "white", "yellow"}
+	commentWords = []string{"furiously", "sly", "careful", "blithely", "quickly", "fluffily", "slyly", "carefully",
+		"final", "ironic", "even", "bold", "pending", "regular", "special", "express", "unusual", "silent",
+		"requests", "packages", "accounts", "deposits", "foxes", "ideas", "theodolites", "pinto", "beans",
+		"instructions", "dependencies", "excuses", "platelets", "asymptotes", "courts", "dolphins",
+		"sleep", "wake", "are", "cajole", "haggle", "nag", "use", "boost", "affix", "detect", "integrate",
+		"among", "across", "above", "against", "along", "after", "about", "according", "to", "the"}
+)
@@ -0,0 +1,74 @@
+package tpch
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func readTable(t *testing.T, table *TpchTable) (rows [][]interface{}) {
+	shardCount, err := table.ShardCount()
+	if err != nil {
+		t.Fatalf("shard count: %v", err)
+	}
+	for i := 0; i < shardCount; i++ {
+		it, err := table.OpenShard(i)
+		if err != nil {
+			t.Fatalf("open shard %d: %v", i, err)
+		}
+		for {
+			row, err := it.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read shard %d: %v", i, err)
+			}
+			rows = append(rows, append(row.K, row.V...))
+		}
+		it.Close()
+	}
+	return rows
+}
+
+func TestShardsGenerateTheSameRows(t *testing.T) {
+	for _, name := range Tables {
+		one := readTable(t, Table(name, 0.001))
+		many := readTable(t, Table(name, 0.001).SetShards(7))
+		if !reflect.DeepEqual(one, many) {
+			t.Errorf("%s: %d rows in 1 shard, %d rows in 7 shards differ", name, len(one), len(many))
+		}
+		for _, row := range one {
+			if len(row) != len(Fields(name)) {
+				t.Fatalf("%s: row %v has %d fields, expecting %v", name, row, len(row), Fields(name))
+			}
+		}
+	}
+}
+
+func TestRowCounts(t *testing.T) {
+	for name, count := range map[string]int{"region": 5, "nation": 25, "supplier": 10, "partsupp": 800, "orders": 1500} {
+		if rows := readTable(t, Table(name, 0.001)); len(rows) != count {
+			t.Errorf("%s: %d rows, expecting %d", name, len(rows), count)
+		}
+	}
+}
+
+func TestOrderTotalsMatchLineItems(t *testing.T) {
+	g := &generator{scaleFactor: 0.01}
+	for key := int64(1); key < 100; key++ {
+		var total float64
+		for _, line := range g.lineitem(key) {
+			total += line[5].(float64) * (1 + line[7].(float64)) * (1 - line[6].(float64))
+		}
+		if order := g.orders(key)[0]; order[3].(float64) != round2(total) {
+			t.Errorf("order %d: total price %v, line items %v", key, order[3], round2(total))
+		}
+	}
+}
+
+func TestUnknownTable(t *testing.T) {
+	if _, err := Table("nope", 1).ShardCount(); err == nil {
+		t.Errorf("expecting an error for an unknown table")
+	}
+}
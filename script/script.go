@@ -2,6 +2,8 @@
 package script
 
 import (
+	"io/ioutil"
+	"os"
 	"os/exec"
 )
 
@@ -9,6 +11,7 @@ type Command struct {
 	Path string
 	Args []string
 	Env  []string
+	Arg  string // passed in a file by WriteArgFile(), as it can be longer than the command line allows
 }
 
 type Script interface {
@@ -26,10 +29,34 @@ type Script interface {
 	Limit(n int, offset int)
 }
 
+// ToOsExecCommand creates the command with the environment of this process,
+// and the Env variables.
 func (c *Command) ToOsExecCommand() *exec.Cmd {
 	command := exec.Command(
 		c.Path, c.Args...,
 	)
-	command.Env = c.Env
+	if len(c.Env) > 0 {
+		command.Env = append(os.Environ(), c.Env...)
+	}
 	return command
 }
+
+// WriteArgFile writes the Arg to a new file in the dir, or in the default
+// directory for temporary files if dir is empty. The caller gives the file
+// to the command, and removes it after the command exits.
+func (c *Command) WriteArgFile(dir string) (string, error) {
+	f, err := ioutil.TempFile(dir, "gleam_arg_")
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.WriteString(c.Arg); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -111,6 +111,19 @@ type TableName struct {
 	TableInfo *model.TableInfo
 
 	IndexHints []*IndexHint
+
+	// Function is set if the table is read from a table function, e.g.
+	// FROM RANGE(10). The parser names such tables by TableFunctionPrefix.
+	Function *TableFunction
+}
+
+// TableFunctionPrefix starts the names of the tables of the table functions.
+const TableFunctionPrefix = "__table_function_"
+
+// TableFunction is a table function call in a FROM or JOIN clause.
+type TableFunction struct {
+	Name string   // in upper case
+	Args []string // the string literals, and the integers as text
 }
 
 // IndexHintType is the type for index hint use, ignore or force.
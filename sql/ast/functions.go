@@ -160,6 +160,14 @@ const (
 	CharFunc       = "char_func"
 	CharLength     = "char_length"
 	FindInSet      = "find_in_set"
+	Lpad           = "lpad"
+	Instr          = "instr"
+	Format         = "format"
+	Elt            = "elt"
+	ExportSet      = "export_set"
+	Quote          = "quote"
+	RegexpReplace  = "regexp_replace"
+	RegexpSubstr   = "regexp_substr"
 
 	// information functions
 	ConnectionID = "connection_id"
@@ -168,6 +176,7 @@ const (
 	Schema       = "schema"
 	FoundRows    = "found_rows"
 	LastInsertId = "last_insert_id"
+	RowCount     = "row_count"
 	User         = "user"
 	Version      = "version"
 
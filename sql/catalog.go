@@ -0,0 +1,157 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/executor"
+)
+
+// catalog is the content of the catalog file.
+type catalog struct {
+	Databases []string
+	Tables    []catalogTable
+}
+
+type catalogTable struct {
+	Database string
+	Table    string
+	Columns  []executor.TableColumn
+	Location executor.TableLocation
+	// ColumnElems are the members of the ENUM and SET columns
+	ColumnElems map[string][]string `json:",omitempty"`
+}
+
+// RegisterFileTable makes the files at the location queryable as a table, like
+// RegisterTable(). The files are read with the file plugin, so
+// "github.com/lovelly/gleam/sql/filesource" needs to be imported.
+// Unlike the tables of RegisterTable(), the table is saved by SaveCatalog().
+func RegisterFileTable(f *flow.Flow, tableName string, columns []executor.TableColumn, location executor.TableLocation, options ...TableOption) error {
+	if executor.OpenTableLocation == nil {
+		return fmt.Errorf("Failed to read %s: no file source, import github.com/lovelly/gleam/sql/filesource", location.Path)
+	}
+	dataset, err := executor.OpenTableLocation(f, &location)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %v", location.Path, err)
+	}
+	ts := newDatasetTable(dataset, tableName, columns, options)
+	ts.Location = &location
+	registerTableSource(ts)
+	return nil
+}
+
+// RegisterUnionTable makes the union of the tables queryable as one table,
+// e.g. the files of the past days and the kafka topic of CREATE STREAM for
+// the current day. The parts are read again by each statement, so they are
+// tables of RegisterFileTable(), of CREATE STREAM, or union tables. Their
+// columns are aligned to the columns by name, the missing ones are NULL, and
+// the values are converted to the column types, e.g. the strings of csv files
+// to numbers. The ENUM and SET columns have the members of the columns of the
+// same name in the parts. The union table is not saved by SaveCatalog().
+func RegisterUnionTable(f *flow.Flow, tableName string, columns []executor.TableColumn, partNames ...string) error {
+	if len(partNames) == 0 {
+		return fmt.Errorf("Union table %s needs parts", tableName)
+	}
+	var parts []*executor.TableSource
+	var columnElems map[string][]string
+	for _, partName := range partNames {
+		ts, found := executor.GetTable(executor.TableKey(splitTableName(partName)))
+		if !found {
+			return fmt.Errorf("Unknown table %s of union table %s", partName, tableName)
+		}
+		if ts.Location == nil && ts.Stream == nil && len(ts.Parts) == 0 {
+			return fmt.Errorf("Table %s can not be read again by union table %s, register it with RegisterFileTable()", partName, tableName)
+		}
+		parts = append(parts, ts)
+		for columnName, elems := range ts.ColumnElems {
+			if columnElems == nil {
+				columnElems = make(map[string][]string)
+			}
+			columnElems[columnName] = elems
+		}
+	}
+
+	dbName, name := splitTableName(tableName)
+	ts := &executor.TableSource{
+		DBName:      dbName,
+		TableInfo:   newTableInfo(name, columns, columnElems),
+		Parts:       parts,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	}
+	dataset, err := executor.OpenTable(f, ts)
+	if err != nil {
+		return fmt.Errorf("Failed to read union table %s: %v", tableName, err)
+	}
+	ts.Dataset = dataset
+	registerTableSource(ts)
+	return nil
+}
+
+// SaveCatalog writes the databases, and the tables registered by RegisterFileTable(),
+// to the catalog file. LoadCatalog() registers them again, e.g. when the program restarts.
+func SaveCatalog(fileName string) error {
+	c := catalog{Databases: executor.DatabaseNames()}
+	for _, ts := range executor.AllTables() {
+		if ts.Location == nil {
+			continue
+		}
+		c.Tables = append(c.Tables, catalogTable{
+			Database:    ts.DBName,
+			Table:       ts.TableInfo.Name.O,
+			Columns:     ts.Columns,
+			Location:    *ts.Location,
+			ColumnElems: ts.ColumnElems,
+		})
+	}
+	sort.Slice(c.Tables, func(i, j int) bool {
+		if c.Tables[i].Database != c.Tables[j].Database {
+			return c.Tables[i].Database < c.Tables[j].Database
+		}
+		return c.Tables[i].Table < c.Tables[j].Table
+	})
+
+	data, err := json.MarshalIndent(&c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode catalog: %v", err)
+	}
+	// write to a temporary file first, so a failed write keeps the previous catalog
+	tmpFileName := fileName + ".tmp"
+	if err = ioutil.WriteFile(tmpFileName, data, 0644); err != nil {
+		return fmt.Errorf("Failed to write catalog %s: %v", tmpFileName, err)
+	}
+	if err = os.Rename(tmpFileName, fileName); err != nil {
+		return fmt.Errorf("Failed to write catalog %s: %v", fileName, err)
+	}
+	return nil
+}
+
+// LoadCatalog registers the databases and tables of the catalog file written by
+// SaveCatalog(). The tables read their files on the flow.
+func LoadCatalog(f *flow.Flow, fileName string) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("Failed to read catalog %s: %v", fileName, err)
+	}
+	var c catalog
+	if err = json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("Failed to decode catalog %s: %v", fileName, err)
+	}
+	for _, dbName := range c.Databases {
+		executor.AddDatabase(dbName)
+	}
+	for _, t := range c.Tables {
+		var options []TableOption
+		for columnName, elems := range t.ColumnElems {
+			options = append(options, ColumnElems(columnName, elems...))
+		}
+		if err = RegisterFileTable(f, t.Database+"."+t.Table, t.Columns, t.Location, options...); err != nil {
+			return fmt.Errorf("Failed to load table %s.%s: %v", t.Database, t.Table, err)
+		}
+	}
+	return nil
+}
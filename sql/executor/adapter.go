@@ -2,6 +2,7 @@ package executor
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/lovelly/gleam/flow"
@@ -18,7 +19,19 @@ type Statement struct {
 	ctx        context.Context
 	Text       string
 	Plan       plan.Plan
-	startTime  time.Time
+	Outfile    *Outfile // the file of SELECT ... INTO OUTFILE
+	// Flow, if set, reads the tables of files and streams on this flow,
+	// instead of their registered datasets, so each statement can run its
+	// own flow.
+	Flow *flow.Flow
+	// TempTables are the temporary tables of the session, keyed by
+	// TableKey(). They hide the registered tables of the same name.
+	TempTables map[string]*TableSource
+	// Dirty, if set, keeps the changes of the statement in the transaction
+	// of the session, and merges the changes of the transaction into the
+	// tables it reads.
+	Dirty     *DirtyStatement
+	startTime time.Time
 }
 
 func (a *Statement) OriginText() string {
@@ -33,6 +46,9 @@ func (a *Statement) Exec(ctx context.Context) (*flow.Dataset, error) {
 	a.startTime = time.Now()
 
 	b := newExecutorBuilder(ctx, a.InfoSchema)
+	b.flow = a.Flow
+	b.tempTables = a.TempTables
+	b.dirty = a.Dirty
 
 	exe := b.build(a.Plan)
 	if b.err != nil {
@@ -43,5 +59,45 @@ func (a *Statement) Exec(ctx context.Context) (*flow.Dataset, error) {
 		return nil, fmt.Errorf("Failed to build execution plan %v", plan.ToString(a.Plan))
 	}
 
-	return exe.Exec(), nil
+	switch e := exe.(type) {
+	case *SetExec:
+		return nil, e.run()
+	case *SimpleExec:
+		return nil, e.run()
+	case *UpdateExec:
+		return nil, e.run()
+	}
+
+	// sql_select_limit applies to the SELECT statements without their own LIMIT
+	if selectLimit := ctx.GetSessionVars().SelectLimit; selectLimit != math.MaxUint64 {
+		if _, ok := exe.(*InsertExec); !ok && !hasLimit(a.Plan) {
+			exe = &LimitExec{Src: exe, Count: selectLimit, schema: exe.Schema()}
+		}
+	}
+
+	if a.Outfile != nil {
+		outfile, err := newOutfileExec(ctx, exe, a.Outfile)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exe = outfile
+	}
+
+	ds := exe.Exec()
+	limitMemory(ds, ctx.GetSessionVars().MaxMemoryMB, b.tableDatasets)
+	return ds, nil
+}
+
+// hasLimit checks whether the plan ends with a LIMIT, possibly under projections.
+func hasLimit(p plan.Plan) bool {
+	for p != nil {
+		if _, ok := p.(*plan.Limit); ok {
+			return true
+		}
+		if len(p.GetChildren()) != 1 {
+			return false
+		}
+		p = p.GetChildByIndex(0)
+	}
+	return false
 }
@@ -3,10 +3,15 @@ package executor
 import (
 	"fmt"
 
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
 	"github.com/lovelly/gleam/sql/infoschema"
 	"github.com/lovelly/gleam/sql/model"
 	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/table"
 )
 
 // executorBuilder builds an Executor from a Plan.
@@ -14,6 +19,13 @@ import (
 type executorBuilder struct {
 	ctx context.Context
 	is  infoschema.InfoSchema
+	// flow, if set, reads the tables of files and streams
+	flow       *flow.Flow
+	tempTables map[string]*TableSource
+	dirty      *DirtyStatement
+	// tableDatasets are the datasets of the tables read, which the next
+	// statements read too
+	tableDatasets map[*flow.Dataset]bool
 	// If there is any error during Executor building process, err is set.
 	err error
 }
@@ -49,11 +61,9 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return nil
 	case *plan.Insert:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil
+		return b.buildInsert(v)
 	case *plan.LoadData:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil
+		return b.buildLoadData(v)
 	case *plan.Limit:
 		return b.buildLimit(v)
 	case *plan.Prepare:
@@ -66,14 +76,15 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return nil
 	case *plan.Show:
+		if v.Tp == ast.ShowWarnings {
+			return &ShowWarningsExec{ctx: b.ctx, schema: v.GetSchema()}
+		}
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return nil
 	case *plan.Simple:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil
+		return &SimpleExec{ctx: b.ctx, Statement: v.Statement, dirty: b.dirty}
 	case *plan.Set:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil
+		return &SetExec{ctx: b.ctx, vars: v.VarAssigns}
 	case *plan.Sort:
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildSort(v)
@@ -81,21 +92,16 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildUnion(v)
 	case *plan.Update:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil
+		return b.buildUpdate(v)
 	case *plan.PhysicalUnionScan:
 		return b.buildUnionScanExec(v)
 	case *plan.PhysicalHashJoin:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildJoin(v)
 	case *plan.PhysicalHashSemiJoin:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
-		return nil // b.buildSemiJoin(v)
+		return b.buildSemiJoin(v)
 	case *plan.Selection:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildSelection(v)
 	case *plan.PhysicalAggregation:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildAggregation(v)
 	case *plan.Projection:
 		return b.buildProjection(v)
@@ -111,7 +117,6 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildTableDual(v)
 	case *plan.PhysicalApply:
-		b.err = fmt.Errorf("Unknown Plan %T", p)
 		return b.buildApply(v)
 	case *plan.Exists:
 		b.err = fmt.Errorf("Unknown Plan %T", p)
@@ -153,23 +158,27 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 	us := &UnionScanExec{ctx: b.ctx, Src: src, schema: v.GetSchema()}
 	switch x := src.(type) {
 	case *SelectTableExec:
-		us.desc = x.desc
-		us.condition = v.Condition
-		/*
-			case *XSelectIndexExec:
-				us.desc = x.indexPlan.Desc
-				for _, ic := range x.indexPlan.Index.Columns {
-					for i, col := range x.indexPlan.GetSchema().Columns {
-						if col.ColName.L == ic.Name.L {
-							us.usedIndex = append(us.usedIndex, i)
-							break
-						}
-					}
-				}
-				us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
-				us.condition = v.Condition
-				us.buildAndSortAddedRows(x.table, x.asName)
-		*/
+		us.scan = x
+		if v.Condition != nil {
+			if conditions := uncorrelated([]expression.Expression{v.Condition}); len(conditions) > 0 {
+				us.condition = expression.ComposeCNFCondition(b.ctx, conditions...)
+			}
+		}
+		rows := b.dirty.addedRows(TableKey(x.source.DBName, x.tableInfo.Name.L), b.table(x.source.DBName, x.tableInfo.Name.L))
+		if len(rows) == 0 && us.condition == nil {
+			return src
+		}
+		if us.condition != nil {
+			arg, err := pushDownArg(b.ctx, []expression.Expression{us.condition}, us.schema)
+			if err != nil {
+				b.err = fmt.Errorf("Failed to push down the condition %s: %v", us.condition, err)
+				return nil
+			}
+			us.arg = arg
+		}
+		for _, row := range rows {
+			us.addedRows = append(us.addedRows, EncodeRowValues(row))
+		}
 	default:
 		// The mem table will not be written by sql directly, so we can omit the union scan to avoid err reporting.
 		return src
@@ -177,34 +186,163 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 	return us
 }
 func (b *executorBuilder) buildJoin(v *plan.PhysicalHashJoin) Executor {
-	return nil
+	if len(v.LeftConditions) > 0 || len(v.RightConditions) > 0 || len(v.OtherConditions) > 0 {
+		b.err = fmt.Errorf("Join conditions other than column equality are not supported yet")
+		return nil
+	}
+	if len(v.EqualConditions) == 0 {
+		b.err = fmt.Errorf("Join without equal conditions is not supported yet")
+		return nil
+	}
+	left := b.build(v.GetChildByIndex(0))
+	right := b.build(v.GetChildByIndex(1))
+	if b.err != nil {
+		return nil
+	}
+	leftKeys, rightKeys, err := joinKeys(v.EqualConditions, left.Schema(), right.Schema())
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	leftRequired, rightRequired := v.RequiredProperties()
+	return &JoinExec{
+		left:         left,
+		right:        right,
+		schema:       v.GetSchema(),
+		joinType:     v.JoinType,
+		strategy:     v.Strategy,
+		rightIsSmall: v.SmallTable == 1,
+		leftKeys:     leftKeys,
+		rightKeys:    rightKeys,
+		keyTypes:     joinKeyTypes(left.Schema(), right.Schema(), leftKeys, rightKeys),
+		leftLayout:   layoutOf(v.GetChildByIndex(0), leftRequired),
+		rightLayout:  layoutOf(v.GetChildByIndex(1), rightRequired),
+	}
 }
 
 func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor {
-	return nil
+	src := b.build(v.GetChildByIndex(0))
+	if b.err != nil {
+		return nil
+	}
+	keys, aggregates, err := aggregationFields(v, src)
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	return &AggregationExec{
+		Src:        src,
+		schema:     v.GetSchema(),
+		keys:       keys,
+		aggregates: aggregates,
+	}
 }
 
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
-	return nil
+	src := b.build(v.GetChildByIndex(0))
+	if b.err != nil {
+		return nil
+	}
+	conditions := uncorrelated(v.Conditions)
+	if len(conditions) == 0 {
+		return src
+	}
+	arg, err := pushDownArg(b.ctx, conditions, src.Schema())
+	if err != nil {
+		b.err = fmt.Errorf("Failed to push down the conditions %v: %v", v.Conditions, err)
+		return nil
+	}
+	return &SelectionExec{Src: src, schema: v.GetSchema(), arg: arg}
 }
 
 func (b *executorBuilder) buildProjection(v *plan.Projection) Executor {
-	return &ProjectionExec{
-		Src:    b.build(v.GetChildByIndex(0)),
+	src := b.build(v.GetChildByIndex(0))
+	if b.err != nil {
+		return nil
+	}
+	e := &ProjectionExec{
+		Src:    src,
 		ctx:    b.ctx,
 		exprs:  v.Exprs,
 		schema: v.GetSchema(),
 	}
+	exprs, schema := v.Exprs, src.Schema()
+	if containsUDF(exprs) {
+		var err error
+		exprs, schema, e.udfCalls, err = b.buildUDFCalls(exprs, src)
+		if err != nil {
+			b.err = fmt.Errorf("Failed to run the functions of the projection %v: %v", v.Exprs, err)
+			return nil
+		}
+		if fields, ok := schemaFields(schema, exprs); ok {
+			e.fields = fields
+			return e
+		}
+	}
+	if _, ok := projectionFields(src, exprs); !ok {
+		arg, err := pushDownArg(b.ctx, exprs, schema)
+		if err != nil {
+			b.err = fmt.Errorf("Failed to push down the projection %v: %v", v.Exprs, err)
+			return nil
+		}
+		e.arg = arg
+	}
+	return e
 }
 
 func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 	return nil
 }
 
+// table returns the temporary table of the session, or the registered table.
+func (b *executorBuilder) table(dbName, tableName string) *TableSource {
+	key := TableKey(dbName, tableName)
+	if ts, found := b.tempTables[key]; found {
+		return ts
+	}
+	ts, _ := GetTable(key)
+	return ts
+}
+
 func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
-	table, _ := b.is.TableByName(model.NewCIStr(""), v.Table.Name)
+	src := b.table(v.DBName.L, v.Table.Name.L)
+	if b.flow != nil && src != nil {
+		dataset, err := OpenTable(b.flow, src)
+		if err != nil {
+			b.err = fmt.Errorf("Failed to read table %s.%s: %v", v.DBName, v.Table.Name, err)
+			return nil
+		}
+		if dataset != nil {
+			opened := *src
+			opened.Dataset = dataset
+			src = &opened
+		}
+	}
+	if src != nil && src.Dataset == nil && b.flow != nil && b.dirty.addedRows(TableKey(v.DBName.L, v.Table.Name.L), src) != nil {
+		// only the rows inserted by the session are read
+		empty := *src
+		empty.Dataset = b.flow.Slices(nil)
+		src = &empty
+	}
+	if src == nil || src.Dataset == nil {
+		b.err = fmt.Errorf("Table %s.%s has no dataset to read", v.DBName, v.Table.Name)
+		return nil
+	}
+	if table := b.table(v.DBName.L, v.Table.Name.L); table != nil && table.Dataset == src.Dataset {
+		if b.tableDatasets == nil {
+			b.tableDatasets = make(map[*flow.Dataset]bool)
+		}
+		b.tableDatasets[src.Dataset] = true
+	}
+	if limited := limitParallelism(src.Dataset, b.ctx.GetSessionVars().MaxParallelism); limited != src.Dataset {
+		merged := *src
+		merged.Dataset = limited
+		src = &merged
+	}
+	table, _ := b.is.TableByName(*v.DBName, v.Table.Name)
 	st := &SelectTableExec{
 		tableInfo:  v.Table,
+		source:     src,
 		ctx:        b.ctx,
 		asName:     v.TableAsName,
 		table:      table,
@@ -224,14 +362,291 @@ func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 	return st
 }
 
+func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
+	src := b.build(v.GetChildByIndex(0))
+	if b.err != nil {
+		return nil
+	}
+	insert := b.newInsertExec(v.DBName, v.Table.Meta(), v.Columns, src, v.Ignore)
+	if b.err != nil {
+		return nil
+	}
+	return insert
+}
+
+// newInsertExec creates the executor writing the rows of src to the sink of the table.
+// columns are the target columns of the values, or all columns if empty.
+func (b *executorBuilder) newInsertExec(dbName model.CIStr, tableInfo *model.TableInfo, columns []*ast.ColumnName, src Executor, ignore bool) *InsertExec {
+	dst := b.table(dbName.L, tableInfo.Name.L)
+	if dst == nil || dst.Sink == nil {
+		b.err = fmt.Errorf("Table %s.%s has no sink to insert into", dbName, tableInfo.Name)
+		return nil
+	}
+	cols := make([]*table.Column, len(tableInfo.Columns))
+	for i, col := range tableInfo.Columns {
+		cols[i] = table.ToColumn(col)
+	}
+	targets := cols
+	if len(columns) > 0 {
+		names := make([]string, len(columns))
+		for i, c := range columns {
+			names[i] = c.Name.O
+		}
+		var err error
+		if targets, err = table.FindCols(cols, names); err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		if err = table.CheckOnce(targets); err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+	}
+	return &InsertExec{
+		ctx:     b.ctx,
+		Src:     src,
+		sink:    dst.Sink,
+		columns: targets,
+		table:   cols,
+		ignore:  ignore,
+		dirty:   b.dirty,
+		key:     TableKey(dbName.L, tableInfo.Name.L),
+		source:  dst,
+	}
+}
+
+// buildUpdate changes the rows the transaction inserted into the table of a
+// sink, see UpdateExec. The conditions of the table scan are kept by its
+// union scan.
+func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
+	var conditions []expression.Expression
+	p := v.GetChildByIndex(0)
+	if sel, ok := p.(*plan.Selection); ok {
+		conditions = append(conditions, sel.Conditions...)
+		p = sel.GetChildByIndex(0)
+	}
+	us, ok := p.(*plan.PhysicalUnionScan)
+	if !ok {
+		b.err = fmt.Errorf("UPDATE supports the rows of one table, without ORDER BY and LIMIT")
+		return nil
+	}
+	ts, ok := us.GetChildByIndex(0).(*plan.PhysicalTableScan)
+	if !ok {
+		b.err = fmt.Errorf("UPDATE supports the rows of one table, without ORDER BY and LIMIT")
+		return nil
+	}
+	if us.Condition != nil {
+		conditions = append(conditions, us.Condition)
+	}
+	dst := b.table(ts.DBName.L, ts.Table.Name.L)
+	if dst == nil || dst.Sink == nil {
+		b.err = fmt.Errorf("Table %s.%s has no sink to update", ts.DBName, ts.Table.Name)
+		return nil
+	}
+	if dst.Dataset != nil || dst.Location != nil || dst.Stream != nil || dst.Cache != nil || dst.Source != nil || len(dst.Parts) > 0 {
+		b.err = fmt.Errorf("Table %s.%s can only update the rows inserted in the transaction, not the rows of its dataset", ts.DBName, ts.Table.Name)
+		return nil
+	}
+	e := &UpdateExec{
+		ctx:         b.ctx,
+		dirty:       b.dirty,
+		key:         TableKey(ts.DBName.L, ts.Table.Name.L),
+		source:      dst,
+		columns:     ts.Columns,
+		schema:      us.GetSchema(),
+		assignments: v.OrderedList,
+	}
+	if len(conditions) > 0 {
+		e.condition = expression.ComposeCNFCondition(b.ctx, conditions...)
+	}
+	return e
+}
+
+// buildLoadData inserts the rows of the file into the table's sink. Like MySQL,
+// LOAD DATA LOCAL turns conversion errors into warnings.
+func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
+	t, err := b.is.TableByName(v.Table.Schema, v.Table.Name)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	if err = checkLoadDataOptions(v.FieldsInfo, v.LinesInfo); err != nil {
+		b.err = err
+		return nil
+	}
+	dataset, err := loadDataSource(flow.New("load data"), v.Path, v.IsLocal, v.FieldsInfo)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	insert := b.newInsertExec(v.Table.Schema, t.Meta(), nil, &LoadDataExec{dataset: dataset}, v.IsLocal)
+	if b.err != nil {
+		return nil
+	}
+	return insert
+}
+
 func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 	return nil
 }
 
+// buildApply runs the inner side of an inner apply once per batch of outer rows,
+// instead of once per outer row. The correlated equalities become join keys,
+// so each batch only looks up the inner rows matching its keys, like an IN-list.
+// A scalar subquery keeps the outer rows without inner rows by a left outer join.
 func (b *executorBuilder) buildApply(v *plan.PhysicalApply) Executor {
-	return nil
+	if semiJoin, ok := v.PhysicalJoin.(*plan.PhysicalHashSemiJoin); ok {
+		return b.buildSemiApply(v, semiJoin)
+	}
+	join, ok := v.PhysicalJoin.(*plan.PhysicalHashJoin)
+	if !ok || join.JoinType != plan.InnerJoin {
+		b.err = fmt.Errorf("Apply other than inner join is not supported yet")
+		return nil
+	}
+	if len(join.LeftConditions) > 0 || len(join.RightConditions) > 0 || len(join.OtherConditions) > 0 {
+		b.err = fmt.Errorf("Join conditions other than column equality are not supported yet")
+		return nil
+	}
+	innerCols, outerCols, ok := v.CorrelatedEqualities()
+	if !ok {
+		b.err = fmt.Errorf("Correlated conditions other than column equality are not supported yet")
+		return nil
+	}
+	outer := b.build(v.GetChildByIndex(0))
+	inner := b.build(v.GetChildByIndex(1))
+	if b.err != nil {
+		return nil
+	}
+	leftKeys, rightKeys, err := joinKeys(join.EqualConditions, outer.Schema(), inner.Schema())
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	for i, innerCol := range innerCols {
+		outerIdx, innerIdx := columnIndex(outer, outerCols[i]), columnIndex(inner, innerCol)
+		if outerIdx < 0 || innerIdx < 0 {
+			b.err = fmt.Errorf("correlated condition %s = %s is not on both sides", innerCol, outerCols[i])
+			return nil
+		}
+		leftKeys = append(leftKeys, outerIdx+1)
+		rightKeys = append(rightKeys, innerIdx+1)
+	}
+	if len(leftKeys) == 0 {
+		b.err = fmt.Errorf("Apply without equal conditions is not supported yet")
+		return nil
+	}
+	e := &JoinExec{
+		left:         outer,
+		right:        inner,
+		schema:       v.GetSchema(),
+		joinType:     join.JoinType,
+		strategy:     join.Strategy,
+		rightIsSmall: true,
+		leftKeys:     leftKeys,
+		rightKeys:    rightKeys,
+		keyTypes:     joinKeyTypes(outer.Schema(), inner.Schema(), leftKeys, rightKeys),
+	}
+	if v.MaxOneRow {
+		// the hash joins are inner joins only
+		e.joinType = plan.LeftOuterJoin
+		e.strategy = plan.SortMergeJoin
+		e.batchCount = applyBatchCount(plan.EstimateRowCount(v.GetChildByIndex(0)))
+	} else if e.strategy != plan.BroadcastHashJoin {
+		// the broadcasted inner side is already run only once
+		e.strategy = plan.PartitionedHashJoin
+		e.batchCount = applyBatchCount(plan.EstimateRowCount(v.GetChildByIndex(0)))
+	}
+	return e
 }
 
 func (b *executorBuilder) buildUnion(v *plan.Union) Executor {
 	return nil
 }
+
+func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) Executor {
+	if len(v.LeftConditions) > 0 || len(v.RightConditions) > 0 || len(v.OtherConditions) > 0 {
+		b.err = fmt.Errorf("Semi join conditions other than column equality are not supported yet")
+		return nil
+	}
+	if len(v.EqualConditions) == 0 {
+		b.err = fmt.Errorf("Semi join without equal conditions is not supported yet")
+		return nil
+	}
+	outer := b.build(v.GetChildByIndex(0))
+	inner := b.build(v.GetChildByIndex(1))
+	if b.err != nil {
+		return nil
+	}
+	outerKeys, innerKeys, err := joinKeys(v.EqualConditions, outer.Schema(), inner.Schema())
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	outerRequired, innerRequired := v.RequiredProperties()
+	return &ExistsExec{
+		outer:       outer,
+		inner:       inner,
+		schema:      v.GetSchema(),
+		anti:        v.Anti,
+		withMark:    v.WithAux,
+		outerKeys:   outerKeys,
+		innerKeys:   innerKeys,
+		keyTypes:    joinKeyTypes(outer.Schema(), inner.Schema(), outerKeys, innerKeys),
+		outerLayout: layoutOf(v.GetChildByIndex(0), outerRequired),
+		innerLayout: layoutOf(v.GetChildByIndex(1), innerRequired),
+	}
+}
+
+// buildSemiApply checks a correlated EXISTS for batches of outer rows, like buildApply.
+// The correlated equalities become the keys, and the inner rows of each batch
+// are read only until every outer row has found one.
+func (b *executorBuilder) buildSemiApply(v *plan.PhysicalApply, join *plan.PhysicalHashSemiJoin) Executor {
+	if len(join.LeftConditions) > 0 || len(join.RightConditions) > 0 || len(join.OtherConditions) > 0 {
+		b.err = fmt.Errorf("Semi join conditions other than column equality are not supported yet")
+		return nil
+	}
+	innerCols, outerCols, ok := v.CorrelatedEqualities()
+	if !ok {
+		b.err = fmt.Errorf("Correlated conditions other than column equality are not supported yet")
+		return nil
+	}
+	innerPlan, err := existsSource(v.GetChildByIndex(1))
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	outer := b.build(v.GetChildByIndex(0))
+	inner := b.build(innerPlan)
+	if b.err != nil {
+		return nil
+	}
+	outerKeys, innerKeys, err := joinKeys(join.EqualConditions, outer.Schema(), inner.Schema())
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	for i, innerCol := range innerCols {
+		outerIdx, innerIdx := columnIndex(outer, outerCols[i]), columnIndex(inner, innerCol)
+		if outerIdx < 0 || innerIdx < 0 {
+			b.err = fmt.Errorf("correlated condition %s = %s is not on both sides", innerCol, outerCols[i])
+			return nil
+		}
+		outerKeys = append(outerKeys, outerIdx+1)
+		innerKeys = append(innerKeys, innerIdx+1)
+	}
+	if len(outerKeys) == 0 {
+		b.err = fmt.Errorf("Apply without equal conditions is not supported yet")
+		return nil
+	}
+	return &ExistsExec{
+		outer:      outer,
+		inner:      inner,
+		schema:     v.GetSchema(),
+		anti:       join.Anti,
+		withMark:   join.WithAux,
+		outerKeys:  outerKeys,
+		innerKeys:  innerKeys,
+		keyTypes:   joinKeyTypes(outer.Schema(), inner.Schema(), outerKeys, innerKeys),
+		batchCount: applyBatchCount(plan.EstimateRowCount(v.GetChildByIndex(0))),
+	}
+}
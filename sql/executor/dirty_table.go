@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// DirtyDB keeps the changes of the transaction of a session to the tables of
+// sinks: the rows inserted between BEGIN and COMMIT, as changed by UPDATE.
+// The statements of the transaction read them, COMMIT writes them to the
+// sinks, in the order of the inserts, and ROLLBACK drops them. Outside a
+// transaction, INSERT writes the rows to the sinks, and nothing is kept.
+type DirtyDB struct {
+	sync.Mutex
+	txn    int64 // counts the transactions, to drop the changes of the previous ones
+	inTxn  bool
+	tables []*dirtyTable // in the order of their first inserts
+}
+
+// dirtyTable holds the rows inserted into the table source, in the order of
+// the inserts, with all the columns of the table.
+type dirtyTable struct {
+	key    string
+	source *TableSource
+	rows   [][]types.Datum
+}
+
+func NewDirtyDB() *DirtyDB {
+	return &DirtyDB{}
+}
+
+// Statement returns the changes of the next statement of the session, which
+// are kept apart until the statement is done.
+func (db *DirtyDB) Statement() *DirtyStatement {
+	if db == nil {
+		return nil
+	}
+	db.Lock()
+	defer db.Unlock()
+	return &DirtyStatement{db: db, txn: db.txn, inTxn: db.inTxn}
+}
+
+// Rollback drops the changes of the transaction, and ends it.
+func (db *DirtyDB) Rollback() {
+	db.Lock()
+	defer db.Unlock()
+	db.tables = nil
+	db.inTxn = false
+}
+
+// begin starts a transaction, committing the current one, like MySQL.
+func (db *DirtyDB) begin() error {
+	db.Lock()
+	defer db.Unlock()
+	err := db.commit()
+	db.txn++
+	db.inTxn = true
+	return err
+}
+
+// commit writes the rows of the transaction to the sinks, and ends the
+// transaction. The rows are dropped even if a sink fails.
+func (db *DirtyDB) commit() error {
+	tables := db.tables
+	db.tables = nil
+	db.inTxn = false
+	for _, dt := range tables {
+		for _, row := range dt.rows {
+			if err := dt.source.Sink(EncodeRowValues(row)); err != nil {
+				return fmt.Errorf("Failed to commit the rows of table %s: %v", dt.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// table returns the rows of the table source, created if create is set.
+// The rows of a table registered again are not read by the next statements,
+// but are still committed to the sink of the previous table.
+func (db *DirtyDB) table(key string, source *TableSource, create bool) *dirtyTable {
+	for _, dt := range db.tables {
+		if dt.key == key && dt.source == source {
+			return dt
+		}
+	}
+	if !create {
+		return nil
+	}
+	dt := &dirtyTable{key: key, source: source}
+	db.tables = append(db.tables, dt)
+	return dt
+}
+
+// DirtyStatement keeps the changes of a statement in a transaction, which
+// Done() applies to the transaction only if the statement succeeds, so a
+// failed statement leaves none of its rows.
+type DirtyStatement struct {
+	db    *DirtyDB
+	txn   int64
+	inTxn bool
+
+	mu      sync.Mutex
+	changes []dirtyChange
+}
+
+// dirtyChange inserts the row into the table, or replaces its row of the
+// index if the index is not negative.
+type dirtyChange struct {
+	key    string
+	source *TableSource
+	index  int
+	row    []types.Datum
+}
+
+// Done applies the changes of the statement to its transaction, unless the
+// statement failed, or the transaction ended meanwhile.
+func (s *DirtyStatement) Done(err error) {
+	if s == nil || err != nil || len(s.changes) == 0 {
+		return
+	}
+	s.db.Lock()
+	defer s.db.Unlock()
+	if !s.db.inTxn || s.db.txn != s.txn {
+		return
+	}
+	for _, c := range s.changes {
+		dt := s.db.table(c.key, c.source, true)
+		if c.index < 0 {
+			dt.rows = append(dt.rows, c.row)
+		} else if c.index < len(dt.rows) {
+			dt.rows[c.index] = c.row
+		}
+	}
+}
+
+// IsEmpty tells whether the transaction has no rows to read.
+func (s *DirtyStatement) IsEmpty() bool {
+	if s == nil {
+		return true
+	}
+	s.db.Lock()
+	defer s.db.Unlock()
+	return len(s.db.tables) == 0
+}
+
+// inTransaction tells whether the statement runs in a transaction, and
+// keeps its changes until COMMIT.
+func (s *DirtyStatement) inTransaction() bool {
+	return s != nil && s.inTxn
+}
+
+// addRow records the row inserted into the table source.
+func (s *DirtyStatement) addRow(key string, source *TableSource, row []types.Datum) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, dirtyChange{key: key, source: source, index: -1, row: row})
+}
+
+// updateRow records the new values of the row of the index in addedRows().
+func (s *DirtyStatement) updateRow(key string, source *TableSource, index int, row []types.Datum) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, dirtyChange{key: key, source: source, index: index, row: row})
+}
+
+// addedRows returns the rows the transaction inserted into the table source,
+// without the changes of the statement.
+func (s *DirtyStatement) addedRows(key string, source *TableSource) [][]types.Datum {
+	if !s.inTransaction() {
+		return nil
+	}
+	s.db.Lock()
+	defer s.db.Unlock()
+	if s.db.txn != s.txn {
+		return nil
+	}
+	dt := s.db.table(key, source, false)
+	if dt == nil {
+		return nil
+	}
+	return append([][]types.Datum(nil), dt.rows...)
+}
+
+func (s *DirtyStatement) begin() error {
+	if s == nil {
+		return fmt.Errorf("Transactions need a session, see QueryManager")
+	}
+	return s.db.begin()
+}
+
+func (s *DirtyStatement) commit() error {
+	if s == nil {
+		return nil
+	}
+	s.db.Lock()
+	defer s.db.Unlock()
+	return s.db.commit()
+}
+
+func (s *DirtyStatement) rollback() {
+	if s != nil {
+		s.db.Rollback()
+	}
+}
@@ -0,0 +1,407 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/util/types"
+	"github.com/lovelly/gleam/util"
+)
+
+// The mappers and the reducer of the aggregations. Each row is mapped to the
+// keys and the states of the aggregates, which the reducer merges with
+// LocalReduceBy(). The states are tagged by their functions, so the reducer
+// needs no argument.
+var (
+	aggregateStatesMapper  = gio.RegisterMapper(mapAggregateStates)
+	aggregateStatesReducer = gio.RegisterReducer(mergeAggregateStates)
+	aggregateFinishMapper  = gio.RegisterMapper(finishAggregateStates)
+)
+
+// AggregationExec compiles a PhysicalAggregation into a gleam aggregation.
+// Each shard is aggregated into partial results before the shuffle, which
+// partitions them by the group by columns, and the partial results are
+// merged into the final values after it.
+type AggregationExec struct {
+	Src        Executor
+	schema     expression.Schema
+	keys       []int // 1-based
+	aggregates []aggregate
+}
+
+// aggregate is an aggregate function of a field. The field index starts
+// from 1, and 0 counts all rows. The sums, averages, minimums and maximums
+// of DECIMAL fields are computed as decimals.
+type aggregate struct {
+	Function string `json:"function"`
+	Field    int    `json:"field,omitempty"`
+	Decimal  bool   `json:"decimal,omitempty"`
+}
+
+// aggregateArg is the argument of aggregateStatesMapper.
+type aggregateArg struct {
+	Keys       []int       `json:"keys,omitempty"`
+	Aggregates []aggregate `json:"aggregates"`
+}
+
+// Schema implements the Executor Schema interface.
+func (e *AggregationExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Exec implements the Executor Exec interface.
+func (e *AggregationExec) Exec() *flow.Dataset {
+	arg, _ := json.Marshal(aggregateArg{Keys: e.keys, Aggregates: e.aggregates})
+	// the rows are the keys followed by the states of the aggregates
+	d := e.Src.Exec().MapWithArg("aggregate.states", aggregateStatesMapper, string(arg))
+
+	if len(e.keys) == 0 {
+		d = d.LocalReduceBy("aggregate.partial", aggregateStatesReducer, nil)
+		// the aggregates of no rows are written if there are no rows, e.g. a count of 0
+		empty := d.Flow.Slices([][]interface{}{{emptyAggregateStates(e.aggregates)}})
+		d = d.MergeTo("aggregate", 1).Union("aggregate.empty", []*flow.Dataset{empty}, false).
+			LocalReduceBy("aggregate.final", aggregateStatesReducer, nil)
+	} else {
+		keyFields := flow.Field(sequence(1, len(e.keys))...)
+		d = d.LocalSort("aggregate.partial", keyFields).
+			LocalReduceBy("aggregate.partial", aggregateStatesReducer, keyFields)
+		if len(d.Shards) > 1 {
+			d = d.Partition("aggregate", len(d.Shards), keyFields).
+				LocalSort("aggregate.final", keyFields).
+				LocalReduceBy("aggregate.final", aggregateStatesReducer, keyFields)
+		}
+	}
+
+	// the output rows are the aggregated values, in the schema order
+	return d.Map("aggregate.finish", aggregateFinishMapper)
+}
+
+// aggregationFields locates the group by columns and the arguments of the
+// aggregate functions in the output of the source executor.
+func aggregationFields(v *plan.PhysicalAggregation, src Executor) (keys []int, aggregates []aggregate, err error) {
+	for _, item := range v.GroupByItems {
+		col, ok := item.(*expression.Column)
+		if !ok {
+			return nil, nil, fmt.Errorf("GROUP BY %s is not supported yet", item)
+		}
+		idx := columnIndex(src, col)
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("GROUP BY column %s is not found", col)
+		}
+		keys = append(keys, idx+1)
+	}
+	for _, f := range v.AggFuncs {
+		if f.IsDistinct() {
+			return nil, nil, fmt.Errorf("%s with DISTINCT is not supported yet", f)
+		}
+		name, args := f.GetName(), f.GetArgs()
+		switch name {
+		case ast.AggFuncCount, ast.AggFuncSum, ast.AggFuncAvg,
+			ast.AggFuncMin, ast.AggFuncMax, ast.AggFuncFirstRow:
+		default:
+			return nil, nil, fmt.Errorf("Aggregate function %s is not supported yet", f)
+		}
+		if len(args) != 1 {
+			return nil, nil, fmt.Errorf("Aggregate function %s is not supported yet", f)
+		}
+		switch arg := args[0].(type) {
+		case *expression.Column:
+			idx := columnIndex(src, arg)
+			if idx < 0 {
+				return nil, nil, fmt.Errorf("Aggregate column %s is not found", arg)
+			}
+			aggregates = append(aggregates, aggregate{
+				Function: name,
+				Field:    idx + 1,
+				Decimal:  arg.GetType().Tp == mysql.TypeNewDecimal,
+			})
+		case *expression.Constant:
+			// e.g. count(*), which counts all rows
+			if name != ast.AggFuncCount || arg.Value.IsNull() {
+				return nil, nil, fmt.Errorf("Aggregate function %s is not supported yet", f)
+			}
+			aggregates = append(aggregates, aggregate{Function: name})
+		default:
+			return nil, nil, fmt.Errorf("Aggregate function %s is not supported yet", f)
+		}
+	}
+	return keys, aggregates, nil
+}
+
+// mapperAggregates is the argument of aggregateStatesMapper, parsed once by each task.
+var mapperAggregates struct {
+	sync.Once
+	arg aggregateArg
+	err error
+}
+
+// mapAggregateStates emits the keys of the row, and the states of the
+// aggregates of the row as one field.
+func mapAggregateStates(row []interface{}) error {
+	mapperAggregates.Do(func() {
+		if err := json.Unmarshal([]byte(gio.MapperArg()), &mapperAggregates.arg); err != nil {
+			mapperAggregates.err = fmt.Errorf("Failed to parse the mapper argument: %v", err)
+		}
+	})
+	if mapperAggregates.err != nil {
+		return mapperAggregates.err
+	}
+	arg := mapperAggregates.arg
+
+	fields := make([]interface{}, 0, len(arg.Keys)+1)
+	for _, key := range arg.Keys {
+		fields = append(fields, row[key-1])
+	}
+	states := make([]interface{}, len(arg.Aggregates))
+	for i, a := range arg.Aggregates {
+		var v interface{}
+		if a.Field > 0 {
+			v = row[a.Field-1]
+		}
+		state, err := newAggregateState(a, v)
+		if err != nil {
+			return err
+		}
+		states[i] = state
+	}
+	return gio.Emit(append(fields, states)...)
+}
+
+// newAggregateState returns the state of the aggregate of a value: the
+// function, whether it is computed as decimals, and the value, followed by
+// the count for avg.
+func newAggregateState(a aggregate, v interface{}) ([]interface{}, error) {
+	switch a.Function {
+	case ast.AggFuncCount:
+		if a.Field > 0 && v == nil {
+			return []interface{}{a.Function, false, int64(0)}, nil
+		}
+		return []interface{}{a.Function, false, int64(1)}, nil
+	case ast.AggFuncFirstRow:
+		return []interface{}{a.Function, false, v}, nil
+	}
+	if v != nil {
+		var err error
+		if v, err = aggregateValue(v, a.Decimal, a.Function != ast.AggFuncMin && a.Function != ast.AggFuncMax); err != nil {
+			return nil, err
+		}
+	}
+	if a.Function == ast.AggFuncAvg {
+		if v == nil {
+			return []interface{}{a.Function, a.Decimal, nil, int64(0)}, nil
+		}
+		return []interface{}{a.Function, a.Decimal, v, int64(1)}, nil
+	}
+	return []interface{}{a.Function, a.Decimal, v}, nil
+}
+
+// aggregateValue converts decimals to their canonical strings, and the
+// numbers to sum to int64 or float64, keeping other values to compare.
+func aggregateValue(v interface{}, isDecimal, isSum bool) (interface{}, error) {
+	if isDecimal {
+		d, err := toDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(d.ToString()), nil
+	}
+	if !isSum || isInteger(v) {
+		return v, nil
+	}
+	switch x := v.(type) {
+	case string:
+		f, _ := strconv.ParseFloat(x, 64)
+		return f, nil
+	case []byte:
+		f, _ := strconv.ParseFloat(string(x), 64)
+		return f, nil
+	}
+	return util.ToFloat64(v), nil
+}
+
+// emptyAggregateStates returns the states of the aggregates of no rows.
+func emptyAggregateStates(aggregates []aggregate) []interface{} {
+	states := make([]interface{}, len(aggregates))
+	for i, a := range aggregates {
+		switch a.Function {
+		case ast.AggFuncCount:
+			states[i] = []interface{}{a.Function, false, int64(0)}
+		case ast.AggFuncAvg:
+			states[i] = []interface{}{a.Function, a.Decimal, nil, int64(0)}
+		default:
+			states[i] = []interface{}{a.Function, a.Decimal, nil}
+		}
+	}
+	return states
+}
+
+// mergeAggregateStates merges the states y into the states x. NULL values are ignored.
+func mergeAggregateStates(x, y interface{}) (interface{}, error) {
+	xStates, yStates := x.([]interface{}), y.([]interface{})
+	if len(xStates) != len(yStates) {
+		return nil, fmt.Errorf("Failed to merge %d aggregates with %d aggregates", len(xStates), len(yStates))
+	}
+	for i := range xStates {
+		merged, err := mergeAggregateState(xStates[i].([]interface{}), yStates[i].([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		xStates[i] = merged
+	}
+	return xStates, nil
+}
+
+func mergeAggregateState(x, y []interface{}) ([]interface{}, error) {
+	function, isDecimal := toString(x[0]), x[1].(bool)
+	var err error
+	switch function {
+	case ast.AggFuncCount:
+		x[2] = util.ToInt64(x[2]) + util.ToInt64(y[2])
+	case ast.AggFuncSum:
+		x[2], err = addAggregateValues(x[2], y[2], isDecimal)
+	case ast.AggFuncAvg:
+		x[2], err = addAggregateValues(x[2], y[2], isDecimal)
+		x[3] = util.ToInt64(x[3]) + util.ToInt64(y[3])
+	case ast.AggFuncMin, ast.AggFuncMax:
+		if y[2] == nil {
+			break
+		}
+		if x[2] == nil {
+			x[2] = y[2]
+			break
+		}
+		var c int
+		if c, err = compareAggregateValues(y[2], x[2], isDecimal); err == nil &&
+			(function == ast.AggFuncMin && c < 0 || function == ast.AggFuncMax && c > 0) {
+			x[2] = y[2]
+		}
+	}
+	return x, err
+}
+
+// addAggregateValues adds decimals as decimals, integers as int64, and other
+// numbers as float64.
+func addAggregateValues(x, y interface{}, isDecimal bool) (interface{}, error) {
+	if x == nil {
+		return y, nil
+	}
+	if y == nil {
+		return x, nil
+	}
+	if isDecimal {
+		a, err := toDecimal(x)
+		if err != nil {
+			return nil, err
+		}
+		b, err := toDecimal(y)
+		if err != nil {
+			return nil, err
+		}
+		var sum types.MyDecimal
+		if err = types.DecimalAdd(a, b, &sum); err != nil {
+			return nil, err
+		}
+		return string(sum.ToString()), nil
+	}
+	if isInteger(x) && isInteger(y) {
+		return util.ToInt64(x) + util.ToInt64(y), nil
+	}
+	return util.ToFloat64(x) + util.ToFloat64(y), nil
+}
+
+func compareAggregateValues(x, y interface{}, isDecimal bool) (int, error) {
+	if !isDecimal {
+		return util.Compare([]interface{}{x}, []interface{}{y}), nil
+	}
+	a, err := toDecimal(x)
+	if err != nil {
+		return 0, err
+	}
+	b, err := toDecimal(y)
+	if err != nil {
+		return 0, err
+	}
+	return a.Compare(b), nil
+}
+
+// finishAggregateStates emits the aggregated values of the states, the last field.
+func finishAggregateStates(row []interface{}) error {
+	states := row[len(row)-1].([]interface{})
+	values := make([]interface{}, len(states))
+	for i, s := range states {
+		state := s.([]interface{})
+		if toString(state[0]) != ast.AggFuncAvg {
+			values[i] = state[2]
+			continue
+		}
+		sum, count := state[2], util.ToInt64(state[3])
+		if sum == nil || count == 0 {
+			continue
+		}
+		if !state[1].(bool) {
+			values[i] = util.ToFloat64(sum) / float64(count)
+			continue
+		}
+		d, err := toDecimal(sum)
+		if err != nil {
+			return err
+		}
+		// like MySQL, the average has 4 more decimal digits than the values
+		var quotient, avg types.MyDecimal
+		_, frac := d.PrecisionAndFrac()
+		if err = types.DecimalDiv(d, types.NewDecFromInt(count), &quotient, types.DivFracIncr); err != nil {
+			return err
+		}
+		if err = quotient.Round(&avg, frac+types.DivFracIncr); err != nil {
+			return err
+		}
+		values[i] = string(avg.ToString())
+	}
+	return gio.Emit(values...)
+}
+
+func toDecimal(v interface{}) (*types.MyDecimal, error) {
+	d := new(types.MyDecimal)
+	var err error
+	switch x := v.(type) {
+	case string:
+		err = d.FromString([]byte(x))
+	case []byte:
+		err = d.FromString(x)
+	case float32, float64:
+		err = d.FromFloat64(util.ToFloat64(x))
+	default:
+		if !isInteger(v) {
+			return nil, fmt.Errorf("Failed to convert %v of %T to decimal", v, v)
+		}
+		d = types.NewDecFromInt(util.ToInt64(v))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to convert %v to decimal: %v", v, err)
+	}
+	return d, nil
+}
+
+func toString(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	s, _ := v.(string)
+	return s
+}
+
+func isInteger(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/plan"
+)
+
+// ExistsExec compiles a PhysicalHashSemiJoin, also of an apply, e.g. of a
+// correlated EXISTS, into a gleam exists check. Both sides are partitioned by
+// the keys, and for each partition of outer rows, the inner rows are only read
+// until every outer key has its first matching row.
+type ExistsExec struct {
+	outer, inner Executor
+	schema       expression.Schema
+
+	anti      bool
+	withMark  bool  // output all outer rows, with whether they have matching rows
+	outerKeys []int // 1-based
+	innerKeys []int // 1-based
+	// keyTypes are the types the keys are converted to, see joinKeyTypes().
+	keyTypes []string
+	// outerLayout and innerLayout are the layouts the sides already have.
+	outerLayout, innerLayout keyLayout
+	// batchCount is the least number of partitions, set for applies to bound
+	// the outer rows of each inner lookup.
+	batchCount int
+}
+
+// Schema implements the Executor Schema interface.
+func (e *ExistsExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Exec implements the Executor Exec interface.
+func (e *ExistsExec) Exec() *flow.Dataset {
+	outerCount := e.outer.Schema().Len()
+	// both sides are keyed by the key columns, which partitioning moves to the front
+	outer := e.outer.Exec().SelectKV("exists.outer", flow.Field(e.outerKeys...), flow.Field(sequence(1, outerCount)...))
+	inner := e.inner.Exec().Select("exists.inner", flow.Field(e.innerKeys...))
+	if e.keyTypes != nil {
+		outer = convertKeys(outer, "exists.outer", e.keyTypes)
+		inner = convertKeys(inner, "exists.inner", e.keyTypes)
+	} else {
+		e.outerLayout.mark(outer, len(e.outerKeys))
+		e.innerLayout.mark(inner, len(e.innerKeys))
+	}
+	shardCount := partitionCount(outer, inner, e.outerLayout, e.innerLayout, e.batchCount)
+	keys := flow.Field(sequence(1, len(e.outerKeys))...)
+	outer = outer.Partition("exists.outer", shardCount, keys)
+	inner = inner.Partition("exists.inner", shardCount, keys)
+	var ret *flow.Dataset
+	if e.anti {
+		// the anti semi joins are of NOT IN and != ALL, which are NULL for NULL keys
+		ret = outer.LocalNotIn("exists", inner, keys, keys, e.withMark)
+	} else {
+		ret = outer.LocalExists("exists", inner, keys, keys, false, e.withMark)
+	}
+	return ret.Select("exists.select", flow.Field(sequence(len(e.outerKeys)+1, e.schema.Len())...))
+}
+
+// existsSource skips the operators above the inner plan of an EXISTS
+// that do not change whether inner rows exist. A LIMIT with rows is one of
+// them, since the exists check already stops at the first matching row.
+func existsSource(p plan.Plan) (plan.Plan, error) {
+	for {
+		switch x := p.(type) {
+		case *plan.Limit:
+			if x.Count == 0 || x.Offset > 0 {
+				return nil, fmt.Errorf("EXISTS with LIMIT %d, %d is not supported yet", x.Offset, x.Count)
+			}
+		case *plan.Projection, *plan.Trim, *plan.Sort:
+		default:
+			return p, nil
+		}
+		p = p.GetChildByIndex(0)
+	}
+}
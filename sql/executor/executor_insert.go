@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/table"
+	"github.com/lovelly/gleam/sql/util/types"
+	"github.com/lovelly/gleam/util"
+)
+
+// InsertExec writes the rows of INSERT INTO t SELECT ... to the sink of table t.
+// The selected values are cast to the types of the target columns. Conversion
+// errors become warnings in non-strict sql_mode or with INSERT IGNORE.
+type InsertExec struct {
+	ctx     context.Context
+	Src     Executor
+	sink    func(row []interface{}) error
+	columns []*table.Column // the target column of each selected value
+	table   []*table.Column // all columns of the table
+	ignore  bool
+
+	// dirty keeps the inserted rows of the table source, under key, in a
+	// transaction, until COMMIT writes them to the sink
+	dirty  *DirtyStatement
+	key    string
+	source *TableSource
+
+	mu sync.Mutex
+}
+
+// Schema implements the Executor Schema interface.
+func (e *InsertExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+// The rows are written when the flow of the returned dataset runs, and are
+// counted as the affected rows of the statement. In a transaction, they are
+// kept by the session until COMMIT.
+func (e *InsertExec) Exec() *flow.Dataset {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	return e.Src.Exec().OutputRow(func(row *util.Row) error {
+		values := append(append([]interface{}{}, row.K...), row.V...)
+		rec, err := e.toRecord(values)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.dirty.inTransaction() {
+			e.dirty.addRow(e.key, e.source, rec)
+		} else if err = e.sink(EncodeRowValues(rec)); err != nil {
+			return err
+		}
+		sc.AddAffectedRows(1)
+		return nil
+	})
+}
+
+// toRecord puts the values in their columns, fills the other columns with
+// their default values, and casts the values to the column types.
+func (e *InsertExec) toRecord(values []interface{}) ([]types.Datum, error) {
+	rec := make([]types.Datum, len(e.table))
+	assigned := make([]bool, len(e.table))
+	for i, col := range e.columns {
+		if i < len(values) {
+			rec[col.Offset] = types.NewDatum(values[i])
+		}
+		assigned[col.Offset] = true
+	}
+	for i, col := range e.table {
+		if assigned[i] {
+			continue
+		}
+		d, _, err := table.GetColDefaultValue(e.ctx, col.ToInfo())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		rec[i] = d
+	}
+	if err := table.CastValues(e.ctx, rec, e.columns, e.ignore); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rec, nil
+}
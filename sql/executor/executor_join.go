@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/util"
+)
+
+// joinKeysMapper converts the keys of mixed types to the type they are compared as.
+var joinKeysMapper = gio.RegisterMapper(convertJoinKeys)
+
+// JoinExec compiles a PhysicalHashJoin into gleam joins.
+// Both sides are keyed by the join columns, keeping all their columns as values,
+// so the output can be put back to the schema order of left columns then right columns.
+type JoinExec struct {
+	left, right Executor
+	schema      expression.Schema
+
+	joinType     plan.JoinType
+	strategy     plan.JoinStrategy
+	rightIsSmall bool
+	leftKeys     []int // 1-based
+	rightKeys    []int // 1-based
+	// keyTypes are the types the keys are converted to, see joinKeyTypes().
+	keyTypes []string
+	// leftLayout and rightLayout are the layouts the sides already have.
+	leftLayout, rightLayout keyLayout
+	// batchCount is the least number of partitions for the partitioned and
+	// sort merge strategies, set for applies to bound the outer rows of each inner lookup.
+	batchCount int
+}
+
+// ApplyBatchSize is the number of outer rows of an apply whose inner rows
+// are looked up together.
+var ApplyBatchSize = 10000
+
+// applyBatchCount splits the estimated outer rows into batches of ApplyBatchSize.
+func applyBatchCount(outerRows uint64) int {
+	if ApplyBatchSize <= 0 || outerRows == 0 {
+		return 0
+	}
+	return int((outerRows + uint64(ApplyBatchSize) - 1) / uint64(ApplyBatchSize))
+}
+
+// Schema implements the Executor Schema interface.
+func (e *JoinExec) Schema() expression.Schema {
+	return e.schema
+}
+
+func (e *JoinExec) Exec() *flow.Dataset {
+	leftCount, rightCount := e.left.Schema().Len(), e.right.Schema().Len()
+	left := e.left.Exec().SelectKV("join.left", flow.Field(e.leftKeys...), flow.Field(sequence(1, leftCount)...))
+	right := e.right.Exec().SelectKV("join.right", flow.Field(e.rightKeys...), flow.Field(sequence(1, rightCount)...))
+	keys := flow.Field(sequence(1, len(e.leftKeys))...)
+	if e.keyTypes != nil {
+		// the converted keys are partitioned and sorted again
+		left = convertKeys(left, "join.left", e.keyTypes)
+		right = convertKeys(right, "join.right", e.keyTypes)
+	} else {
+		e.leftLayout.mark(left, len(e.leftKeys))
+		e.rightLayout.mark(right, len(e.rightKeys))
+	}
+
+	// the joined rows are the keys, the values of the first side, then the second side
+	var joined *flow.Dataset
+	leftIsFirst := true
+	switch e.strategy {
+	case plan.BroadcastHashJoin:
+		if e.rightIsSmall {
+			joined = left.HashJoin("join", right, keys)
+		} else {
+			joined = right.HashJoin("join", left, keys)
+			leftIsFirst = false
+		}
+	case plan.PartitionedHashJoin:
+		shardCount := partitionCount(left, right, e.leftLayout, e.rightLayout, e.batchCount)
+		left = left.Partition("join.left", shardCount, keys)
+		right = right.Partition("join.right", shardCount, keys)
+		if e.rightIsSmall {
+			joined = right.LocalHashAndJoinWith("join", left, keys)
+		} else {
+			joined = left.LocalHashAndJoinWith("join", right, keys)
+			leftIsFirst = false
+		}
+	default:
+		if e.batchCount > 0 {
+			left = left.Partition("join.left", partitionCount(left, right, e.leftLayout, e.rightLayout, e.batchCount), keys)
+		}
+		// the field counts give the nils of the outer joins in the partitions without rows of a side
+		left.Hint(flow.FieldCount(len(e.leftKeys) + leftCount))
+		right.Hint(flow.FieldCount(len(e.rightKeys) + rightCount))
+		joined = left.DoJoin("join", right,
+			e.joinType == plan.LeftOuterJoin, e.joinType == plan.RightOuterJoin, keys)
+	}
+
+	keyCount := len(e.leftKeys)
+	var fields []int
+	if leftIsFirst {
+		fields = sequence(keyCount+1, leftCount+rightCount)
+	} else {
+		fields = append(sequence(keyCount+rightCount+1, leftCount), sequence(keyCount+1, rightCount)...)
+	}
+	return joined.Select("join.select", flow.Field(fields...))
+}
+
+// keyLayout is how a side keyed by the join keys is already laid out in its
+// shards, so gleam does not shuffle or sort it again.
+type keyLayout struct {
+	partitioned bool
+	sorted      bool
+}
+
+// layoutOf checks the layout the plan delivers against the required one.
+func layoutOf(p plan.Plan, required plan.PhysicalProperty) keyLayout {
+	delivered := plan.DeliveredProperty(p.(plan.PhysicalPlan))
+	return keyLayout{
+		partitioned: delivered.IsPartitionedBy(required.PartitionedBy),
+		sorted:      delivered.IsSortedBy(required.SortedBy),
+	}
+}
+
+// mark records the layout on the dataset, whose first keyCount fields are the keys.
+// The rows are only sorted within their shards if they are not shuffled again.
+func (l keyLayout) mark(d *flow.Dataset, keyCount int) {
+	if !l.partitioned {
+		return
+	}
+	d.IsPartitionedBy = sequence(1, keyCount)
+	if l.sorted {
+		d.IsLocalSorted = nil
+		for i := 1; i <= keyCount; i++ {
+			d.IsLocalSorted = append(d.IsLocalSorted, instruction.OrderBy{Index: i, Order: instruction.Ascending})
+		}
+	}
+}
+
+// partitionCount is the number of partitions of both sides. A side already
+// partitioned by the keys keeps its partitions, unless there are less than
+// batchCount of them.
+func partitionCount(left, right *flow.Dataset, leftLayout, rightLayout keyLayout, batchCount int) int {
+	if leftLayout.partitioned && len(left.Shards) >= batchCount {
+		return len(left.Shards)
+	}
+	if rightLayout.partitioned && len(right.Shards) >= batchCount {
+		return len(right.Shards)
+	}
+	shardCount := len(left.Shards)
+	if len(right.Shards) > shardCount {
+		shardCount = len(right.Shards)
+	}
+	if batchCount > shardCount {
+		shardCount = batchCount
+	}
+	return shardCount
+}
+
+// joinKeys locates the columns of the equal conditions on both sides.
+func joinKeys(equalConditions []*expression.ScalarFunction, leftSchema, rightSchema expression.Schema) (leftKeys, rightKeys []int, err error) {
+	for _, eq := range equalConditions {
+		args := eq.GetArgs()
+		if len(args) != 2 {
+			return nil, nil, fmt.Errorf("unexpected join condition %s", eq)
+		}
+		l, lOk := args[0].(*expression.Column)
+		r, rOk := args[1].(*expression.Column)
+		if !lOk || !rOk {
+			return nil, nil, fmt.Errorf("unexpected join condition %s", eq)
+		}
+		lIdx, rIdx := leftSchema.GetColumnIndex(l), rightSchema.GetColumnIndex(r)
+		if lIdx < 0 || rIdx < 0 {
+			// the condition may be written as right = left
+			lIdx, rIdx = leftSchema.GetColumnIndex(r), rightSchema.GetColumnIndex(l)
+		}
+		if lIdx < 0 || rIdx < 0 {
+			return nil, nil, fmt.Errorf("join condition %s is not on both sides", eq)
+		}
+		leftKeys = append(leftKeys, lIdx+1)
+		rightKeys = append(rightKeys, rIdx+1)
+	}
+	return leftKeys, rightKeys, nil
+}
+
+// The types the keys of mixed types are converted to.
+const (
+	keyAsDouble  = "double"
+	keyAsDecimal = "decimal"
+)
+
+// joinKeyTypes returns the types the keys are compared as, for the keys of
+// mixed numeric types, since the hashes and the order of the key values of
+// different types do not match even if they are equal. It returns nil if no
+// key needs to be converted.
+func joinKeyTypes(leftSchema, rightSchema expression.Schema, leftKeys, rightKeys []int) []string {
+	var ret []string
+	for i := range leftKeys {
+		lt, rt := leftSchema.Columns[leftKeys[i]-1].GetType(), rightSchema.Columns[rightKeys[i]-1].GetType()
+		l, r, tp := expression.CompareType(lt), expression.CompareType(rt), expression.CompareType(lt, rt)
+		if l == nil || r == nil || tp == nil || l.Tp == r.Tp {
+			continue
+		}
+		if ret == nil {
+			ret = make([]string, len(leftKeys))
+		}
+		switch tp.Tp {
+		case mysql.TypeDouble:
+			ret[i] = keyAsDouble
+		case mysql.TypeNewDecimal:
+			ret[i] = keyAsDecimal
+		}
+	}
+	return ret
+}
+
+// convertKeys converts the first fields of the rows, the keys, to the types.
+func convertKeys(d *flow.Dataset, name string, keyTypes []string) *flow.Dataset {
+	arg, _ := json.Marshal(keyTypes)
+	return d.MapWithArg(name+".keys", joinKeysMapper, string(arg))
+}
+
+// mapperKeyTypes are the key types of the argument of the mapper, parsed once by each task.
+var mapperKeyTypes struct {
+	sync.Once
+	keyTypes []string
+	err      error
+}
+
+func convertJoinKeys(row []interface{}) error {
+	mapperKeyTypes.Do(func() {
+		if err := json.Unmarshal([]byte(gio.MapperArg()), &mapperKeyTypes.keyTypes); err != nil {
+			mapperKeyTypes.err = fmt.Errorf("Failed to parse the mapper argument: %v", err)
+		}
+	})
+	if mapperKeyTypes.err != nil {
+		return mapperKeyTypes.err
+	}
+	for i, keyType := range mapperKeyTypes.keyTypes {
+		if row[i] == nil {
+			continue
+		}
+		var err error
+		switch keyType {
+		case keyAsDouble:
+			row[i], err = toDouble(row[i])
+		case keyAsDecimal:
+			row[i], err = toDecimalKey(row[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return gio.Emit(row...)
+}
+
+func toDouble(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case string, []byte:
+		f, err := strconv.ParseFloat(strings.TrimSpace(toString(x)), 64)
+		if err != nil {
+			return 0, fmt.Errorf("Failed to convert %v to double: %v", toString(x), err)
+		}
+		return f, nil
+	case float32, float64:
+		return util.ToFloat64(x), nil
+	}
+	if !isInteger(v) {
+		return 0, fmt.Errorf("Failed to convert %v of %T to double", v, v)
+	}
+	return float64(util.ToInt64(v)), nil
+}
+
+// toDecimalKey returns the decimal without trailing zeros of its fraction,
+// so that equal values of different scales are the same key.
+func toDecimalKey(v interface{}) (string, error) {
+	d, err := toDecimal(v)
+	if err != nil {
+		return "", err
+	}
+	s := string(d.ToString())
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s, nil
+}
+
+// columnIndex locates a column in the output of an executor,
+// also as a column passed through by a projection.
+func columnIndex(e Executor, col *expression.Column) int {
+	if idx := e.Schema().GetColumnIndex(col); idx >= 0 {
+		return idx
+	}
+	if p, ok := e.(*ProjectionExec); ok {
+		for i, expr := range p.exprs {
+			if c, ok := expr.(*expression.Column); ok && c.FromID == col.FromID && c.Position == col.Position {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// sequence returns count consecutive numbers starting from start.
+func sequence(start, count int) (ret []int) {
+	for i := 0; i < count; i++ {
+		ret = append(ret, start+i)
+	}
+	return
+}
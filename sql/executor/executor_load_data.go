@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/plugins/file"
+	"github.com/lovelly/gleam/plugins/file/tsv"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/expression"
+)
+
+// loadDataSource reads the file of LOAD DATA INFILE with the file plugin,
+// as tsv files with the FIELDS options. The file of LOAD DATA LOCAL INFILE is
+// read by the driver, and the other files by the executors.
+func loadDataSource(f *flow.Flow, path string, isLocal bool, fields *ast.FieldsClause) (*flow.Dataset, error) {
+	options := tsv.Options{Delimiter: '\t', Escaped: '\\'}
+	if fields != nil {
+		options = tsv.Options{Delimiter: fields.Terminated[0], Enclosed: fields.Enclosed, Escaped: fields.Escaped}
+	}
+	if isLocal {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return f.Source("load data local", func(writer io.Writer, stats *pb.InstructionStat) error {
+			return readLocalFile(path, options, writer, stats)
+		}), nil
+	}
+
+	src := file.Tsv(path, 1)
+	if src == nil {
+		return nil, fmt.Errorf("Invalid LOAD DATA file %s", path)
+	}
+	src.SetDelimiter(rune(options.Delimiter))
+	if options.Enclosed != 0 {
+		src.SetEnclosure(options.Enclosed)
+	}
+	if options.Escaped != 0 {
+		src.SetEscape(options.Escaped)
+	}
+	return f.Read(src), nil
+}
+
+func readLocalFile(path string, options tsv.Options, writer io.Writer, stats *pb.InstructionStat) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := tsv.NewWithOptions(f, options)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read %s: %v", path, err)
+		}
+		stats.InputCounter++
+		if err = row.WriteTo(writer); err != nil {
+			return err
+		}
+		stats.OutputCounter++
+	}
+}
+
+// LoadDataExec is the dataset of the file of LOAD DATA INFILE.
+// It is the source of the InsertExec writing them to the table's sink.
+type LoadDataExec struct {
+	dataset *flow.Dataset
+}
+
+// Schema implements the Executor Schema interface.
+func (e *LoadDataExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+func (e *LoadDataExec) Exec() *flow.Dataset {
+	return e.dataset
+}
+
+// checkLoadDataOptions checks the FIELDS and LINES options can be read by the file plugin:
+// one byte field delimiters, and lines ending with '\n'.
+func checkLoadDataOptions(fields *ast.FieldsClause, lines *ast.LinesClause) error {
+	if fields != nil && len(fields.Terminated) != 1 {
+		return fmt.Errorf("Unsupported LOAD DATA fields terminated by %q", fields.Terminated)
+	}
+	if lines != nil {
+		if lines.Starting != "" {
+			return fmt.Errorf("Unsupported LOAD DATA lines starting by %q", lines.Starting)
+		}
+		if lines.Terminated != "" && lines.Terminated != "\n" {
+			return fmt.Errorf("Unsupported LOAD DATA lines terminated by %q", lines.Terminated)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/util/types"
+	"github.com/lovelly/gleam/util"
+)
+
+// Outfile is the file of SELECT ... INTO OUTFILE 'file' [FORMAT format].
+type Outfile struct {
+	FileName string
+	Format   string // CSV, TSV or PARQUET. TSV if empty, like MySQL.
+}
+
+// RowWriter writes the rows of SELECT ... INTO OUTFILE.
+type RowWriter interface {
+	Write(row []types.Datum) error
+	Close() error
+}
+
+// OutfileFormats create the RowWriter of each SELECT ... INTO OUTFILE format,
+// writing the columns of the schema to w. CSV and TSV are built in, and
+// PARQUET is added by importing "github.com/lovelly/gleam/sql/filesource".
+var OutfileFormats = map[string]func(w io.WriteCloser, schema expression.Schema) (RowWriter, error){
+	"CSV": newCsvRowWriter(','),
+	"TSV": newCsvRowWriter('\t'),
+}
+
+// nullField is how MySQL writes NULL to an OUTFILE.
+const nullField = `\N`
+
+// OutfileExec writes the result rows of the query to the file.
+type OutfileExec struct {
+	ctx    context.Context
+	Src    Executor
+	file   *Outfile
+	create func(w io.WriteCloser, schema expression.Schema) (RowWriter, error)
+}
+
+func newOutfileExec(ctx context.Context, src Executor, file *Outfile) (*OutfileExec, error) {
+	format := strings.ToUpper(file.Format)
+	if format == "" {
+		format = "TSV"
+	}
+	create, found := OutfileFormats[format]
+	if !found {
+		return nil, fmt.Errorf("Unsupported OUTFILE format %s", file.Format)
+	}
+	return &OutfileExec{ctx: ctx, Src: src, file: file, create: create}, nil
+}
+
+// Schema implements the Executor Schema interface.
+func (e *OutfileExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+// The rows are merged into one shard, and written when the flow of the returned dataset runs.
+func (e *OutfileExec) Exec() *flow.Dataset {
+	return e.Src.Exec().MergeTo("outfile", 1).Output(func(reader io.Reader) error {
+		return e.write(reader)
+	})
+}
+
+func (e *OutfileExec) write(reader io.Reader) error {
+	file, err := filesystem.Create(e.file.FileName)
+	if err != nil {
+		return fmt.Errorf("Failed to create %s: %v", e.file.FileName, err)
+	}
+	schema := e.Src.Schema()
+	w, err := e.create(file, schema)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("Failed to write %s: %v", e.file.FileName, err)
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	err = util.TakeMessage(reader, -1, func(encodedBytes []byte) error {
+		row, err := util.DecodeRow(encodedBytes)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		datums, err := DecodeRowValues(sc, schema, append(append([]interface{}{}, row.K...), row.V...))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return w.Write(datums)
+	})
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to write %s: %v", e.file.FileName, err)
+	}
+	return nil
+}
+
+// csvRowWriter writes the rows as delimited text. NULL is written as \N.
+type csvRowWriter struct {
+	file   io.WriteCloser
+	writer *csv.Writer
+}
+
+func newCsvRowWriter(delimiter rune) func(io.WriteCloser, expression.Schema) (RowWriter, error) {
+	return func(w io.WriteCloser, schema expression.Schema) (RowWriter, error) {
+		writer := csv.NewWriter(w)
+		writer.Comma = delimiter
+		return &csvRowWriter{file: w, writer: writer}, nil
+	}
+}
+
+func (w *csvRowWriter) Write(row []types.Datum) error {
+	record := make([]string, len(row))
+	for i, d := range row {
+		if d.IsNull() {
+			record[i] = nullField
+			continue
+		}
+		s, err := d.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		record[i] = s
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvRowWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
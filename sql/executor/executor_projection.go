@@ -15,6 +15,9 @@ type ProjectionExec struct {
 	executed bool
 	ctx      context.Context
 	exprs    []expression.Expression
+	arg      string // the expressions, see pushDownArg(), unless they are columns
+	udfCalls []*udfCall
+	fields   []int // the 1-based fields of the expressions after the UDF calls, if they are columns
 }
 
 // Schema implements the Executor Schema interface.
@@ -54,23 +57,65 @@ func (e *ProjectionExec) Exec() *flow.Dataset {
 		return d
 	}
 
-	/*
-			sqlText := fmt.Sprintf(`
-		        function(%s)
-		          return %s
-		        end
-		    `, inputParams, outputParams)
-	*/
+	// the UDFs are run by pipes, before the other expressions
+	if len(e.udfCalls) > 0 {
+		d = e.execUDFCalls(d)
+		if e.fields != nil {
+			return d.Select("projection", flow.Field(e.fields...))
+		}
+		return d.MapWithArg("projection", projectMapper, e.arg)
+	}
 
-	// println(sqlText)
+	// columns are selected from the input, e.g. the group by columns after the aggregates
+	if fields, ok := projectionFields(e.Src, e.exprs); ok {
+		return d.Select("projection", flow.Field(fields...))
+	}
 
-	// ret := d.Map("map", sqlText)
-	ret := d
+	// the expressions are evaluated by the executors of the flow
+	return d.MapWithArg("projection", projectMapper, e.arg)
+}
 
-	return ret
+// projectionFields locates the 1-based fields of the expressions in the input,
+// if all the expressions are columns.
+func projectionFields(src Executor, exprs []expression.Expression) (fields []int, ok bool) {
+	for _, expr := range exprs {
+		col, isColumn := expr.(*expression.Column)
+		if !isColumn {
+			return nil, false
+		}
+		idx := columnIndex(src, col)
+		if idx < 0 {
+			return nil, false
+		}
+		fields = append(fields, idx+1)
+	}
+	return fields, true
+}
+
+// containsUDF tells whether any of the expressions calls a UDF.
+func containsUDF(exprs []expression.Expression) bool {
+	for _, expr := range exprs {
+		if expression.ContainsUDF(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaFields locates the 1-based fields of the expressions in the schema,
+// if all the expressions are columns.
+func schemaFields(schema expression.Schema, exprs []expression.Expression) (fields []int, ok bool) {
+	for _, expr := range exprs {
+		col, isColumn := expr.(*expression.Column)
+		if !isColumn || schema.GetColumnIndex(col) < 0 {
+			return nil, false
+		}
+		fields = append(fields, schema.GetColumnIndex(col)+1)
+	}
+	return fields, true
 }
 
-var re = regexp.MustCompile(`([a-z]+\w*\.)(\w+)`)
+var re = regexp.MustCompile(`([a-z]+\w*\.)+(\w+)`)
 
 func removeTableName(sqlText string) string {
 	return re.ReplaceAllString(sqlText, "$2")
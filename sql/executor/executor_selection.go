@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/expression"
+)
+
+// SelectionExec keeps the rows satisfying all the conditions, evaluated by
+// the executors of the flow.
+type SelectionExec struct {
+	Src    Executor
+	schema expression.Schema
+	arg    string // the conditions, see pushDownArg()
+}
+
+// Schema implements the Executor Schema interface.
+func (e *SelectionExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Exec implements the Executor Exec interface.
+func (e *SelectionExec) Exec() *flow.Dataset {
+	return e.Src.Exec().MapWithArg("selection", filterMapper, e.arg)
+}
@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/sessionctx/varsutil"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// SetExec executes the SET statement. It changes the session or global variables
+// when the statement is executed, and returns no dataset.
+type SetExec struct {
+	ctx  context.Context
+	vars []*expression.VarAssignment
+}
+
+// Schema implements the Executor Schema interface.
+func (e *SetExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+func (e *SetExec) Exec() *flow.Dataset {
+	return nil
+}
+
+func (e *SetExec) run() error {
+	sessionVars := e.ctx.GetSessionVars()
+	for _, v := range e.vars {
+		if v.Name == ast.SetNames {
+			if err := e.setCharset(v); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		// Variable is case insensitive, we use lower case.
+		name := strings.ToLower(v.Name)
+		if !v.IsSystem {
+			value, err := v.Expr.Eval(nil, e.ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if value.IsNull() {
+				delete(sessionVars.Users, name)
+				continue
+			}
+			sValue, err := value.ToString()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			sessionVars.Users[name] = sValue
+			continue
+		}
+
+		sysVar := variable.GetSysVar(name)
+		if sysVar == nil {
+			return variable.UnknownSystemVar.GenByArgs(name)
+		}
+		if sysVar.Scope == variable.ScopeNone {
+			return fmt.Errorf("Variable '%s' is a read only variable", name)
+		}
+		if v.IsGlobal {
+			if sysVar.Scope&variable.ScopeGlobal == 0 {
+				return fmt.Errorf("Variable '%s' is a SESSION variable and can't be used with SET GLOBAL", name)
+			}
+			value, err := e.getVarValue(v, sysVar)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			sValue := ""
+			if !value.IsNull() {
+				if sValue, err = value.ToString(); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			if err = varsutil.SetGlobalSystemVar(sessionVars, name, sValue); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		if sysVar.Scope&variable.ScopeSession == 0 {
+			return fmt.Errorf("Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL", name)
+		}
+		value, err := e.getVarValue(v, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = varsutil.SetSessionSystemVar(sessionVars, name, value); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// getVarValue evaluates the assigned value. DEFAULT sets a global variable
+// to the compiled-in default, and a session variable to the global value.
+func (e *SetExec) getVarValue(v *expression.VarAssignment, sysVar *variable.SysVar) (types.Datum, error) {
+	if !v.IsDefault {
+		value, err := v.Expr.Eval(nil, e.ctx)
+		return value, errors.Trace(err)
+	}
+	if sysVar != nil {
+		return types.NewStringDatum(sysVar.Value), nil
+	}
+	s, err := varsutil.GetGlobalSystemVar(e.ctx.GetSessionVars(), v.Name)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return types.NewStringDatum(s), nil
+}
+
+// setCharset executes SET NAMES charset [COLLATE collation].
+func (e *SetExec) setCharset(v *expression.VarAssignment) error {
+	value, err := v.Expr.Eval(nil, e.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cs, err := value.ToString()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sessionVars := e.ctx.GetSessionVars()
+	for _, name := range variable.SetNamesVariables {
+		if err = varsutil.SetSessionSystemVar(sessionVars, name, types.NewStringDatum(cs)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if v.ExtendValue != nil {
+		co, err := v.ExtendValue.Value.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(varsutil.SetSessionSystemVar(sessionVars, variable.CollationConnection, types.NewStringDatum(co)))
+	}
+	return nil
+}
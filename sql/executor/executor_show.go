@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/terror"
+)
+
+// ShowWarningsExec lists the warnings of the previous statement,
+// as rows of level, code and message.
+type ShowWarningsExec struct {
+	ctx    context.Context
+	schema expression.Schema
+}
+
+// Schema implements the Executor Schema interface.
+func (e *ShowWarningsExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Exec implements the Executor Exec interface.
+func (e *ShowWarningsExec) Exec() *flow.Dataset {
+	var rows [][]interface{}
+	for _, warn := range e.ctx.GetSessionVars().StmtCtx.GetWarnings() {
+		code, message := WarningCodeAndMessage(warn)
+		rows = append(rows, []interface{}{"Warning", int64(code), message})
+	}
+	return flow.New("show warnings").Slices(rows)
+}
+
+// WarningCodeAndMessage returns the MySQL error code and message of a warning.
+func WarningCodeAndMessage(warn error) (uint16, string) {
+	if te, ok := errors.Cause(warn).(*terror.Error); ok {
+		sqlErr := te.ToSQLError()
+		return sqlErr.Code, sqlErr.Message
+	}
+	return mysql.ErrUnknown, warn.Error()
+}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/infoschema"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/privilege"
+)
+
+// SimpleExec executes the USE, CREATE DATABASE, CREATE USER, DROP USER, GRANT,
+// BEGIN, COMMIT and ROLLBACK statements when the statement is executed, and
+// returns no dataset.
+type SimpleExec struct {
+	ctx       context.Context
+	Statement ast.StmtNode
+	dirty     *DirtyStatement // the transaction of the session
+}
+
+// Schema implements the Executor Schema interface.
+func (e *SimpleExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+func (e *SimpleExec) Exec() *flow.Dataset {
+	return nil
+}
+
+func (e *SimpleExec) run() error {
+	switch x := e.Statement.(type) {
+	case *ast.UseStmt:
+		return e.executeUse(x)
+	case *ast.CreateDatabaseStmt:
+		return e.executeCreateDatabase(x)
+	case *ast.CreateUserStmt:
+		return e.executeCreateUser(x)
+	case *ast.DropUserStmt:
+		return e.executeDropUser(x)
+	case *ast.GrantStmt:
+		return e.executeGrant(x)
+	case *ast.BeginStmt:
+		return e.dirty.begin()
+	case *ast.CommitStmt:
+		return e.dirty.commit()
+	case *ast.RollbackStmt:
+		e.dirty.rollback()
+		return nil
+	}
+	return fmt.Errorf("Unsupported statement %T", e.Statement)
+}
+
+func (e *SimpleExec) executeUse(s *ast.UseStmt) error {
+	if !HasDatabase(s.DBName) {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(s.DBName)
+	}
+	e.ctx.GetSessionVars().CurrentDB = strings.ToLower(s.DBName)
+	return nil
+}
+
+// executeCreateDatabase adds the database, which has no tables until
+// they are registered with the database name.
+func (e *SimpleExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
+	if !AddDatabase(s.Name) && !s.IfNotExists {
+		return infoschema.ErrDatabaseExists.GenByArgs(s.Name)
+	}
+	return nil
+}
+
+func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
+	for _, spec := range s.Specs {
+		var password string
+		if spec.AuthOpt != nil {
+			if !spec.AuthOpt.ByAuthString {
+				return fmt.Errorf("Failed to create user %s: only IDENTIFIED BY 'password' is supported", spec.User)
+			}
+			password = spec.AuthOpt.AuthString
+		}
+		if err := privilege.CreateUser(spec.User, password, s.IfNotExists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
+	for _, user := range s.UserList {
+		if err := privilege.DropUser(user, s.IfExists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeGrant grants the privileges to the users or roles, which must exist.
+// Column privileges are not supported.
+func (e *SimpleExec) executeGrant(s *ast.GrantStmt) error {
+	dbName, tableName := plan.GrantObject(e.ctx, s.Level)
+	for _, priv := range s.Privs {
+		if len(priv.Cols) > 0 {
+			return fmt.Errorf("Failed to grant: column privileges are not supported")
+		}
+	}
+	for _, spec := range s.Users {
+		for _, priv := range s.Privs {
+			if err := privilege.Grant(spec.User, priv.Priv, dbName, tableName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
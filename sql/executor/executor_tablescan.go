@@ -12,6 +12,7 @@ import (
 
 type SelectTableExec struct {
 	tableInfo *model.TableInfo
+	source    *TableSource
 	table     table.Table
 	asName    *model.CIStr
 	ctx       context.Context
@@ -48,11 +49,38 @@ func (e *SelectTableExec) Schema() expression.Schema {
 }
 
 // Next implements the Executor Next interface.
+// The columns not used by the query are pruned from the schema, so only the schema columns are selected.
 func (e *SelectTableExec) Exec() *flow.Dataset {
+	return e.selectFields(e.source.Dataset, nil)
+}
 
-	t := Tables[e.tableInfo.Name.String()]
-
-	d := t.Dataset
+// selectFields selects the columns of the schema from the rows of the table
+// in d, whose column of offset i is the field positions[i], or the field i+1
+// if positions is nil.
+func (e *SelectTableExec) selectFields(d *flow.Dataset, positions []int) *flow.Dataset {
+	var fields []int
+	for _, col := range e.Columns {
+		if positions != nil {
+			fields = append(fields, positions[col.Offset])
+		} else {
+			fields = append(fields, col.Offset+1)
+		}
+	}
+	if isSequence(fields, len(e.tableInfo.Columns)) {
+		return d
+	}
+	return d.Select("select", flow.Field(fields...))
+}
 
-	return d
+// isSequence checks whether the fields are all the count fields in order.
+func isSequence(fields []int, count int) bool {
+	if len(fields) != count {
+		return false
+	}
+	for i, field := range fields {
+		if field != i+1 {
+			return false
+		}
+	}
+	return true
 }
@@ -2,17 +2,24 @@ package executor
 
 import (
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/instruction"
 	"github.com/lovelly/gleam/sql/context"
 	"github.com/lovelly/gleam/sql/expression"
 )
 
+// UnionScanExec merges the rows the transaction of the session inserted into
+// the table, and not yet committed to its sink, into the rows read from the
+// table.
 type UnionScanExec struct {
 	ctx       context.Context
 	Src       Executor
-	desc      bool
+	scan      *SelectTableExec
 	condition expression.Expression
 
 	schema expression.Schema
+
+	addedRows [][]interface{} // with all the columns of the table
+	arg       string          // the condition, see pushDownArg()
 }
 
 // Schema implements the Executor Schema interface.
@@ -20,11 +27,46 @@ func (e *UnionScanExec) Schema() expression.Schema {
 	return e.schema
 }
 
-// Next implements the Executor Next interface.
+// Exec implements the Executor Exec interface.
+// The added rows are read in the order of the table scan: if the shards of
+// the table are sorted, the added rows are merged into them by the same
+// fields, and otherwise they follow the rows of the table, in the order of
+// the inserts. The rows are then filtered by the executors of the flow.
 func (e *UnionScanExec) Exec() *flow.Dataset {
-	d := e.Src.Exec()
-
-	ret := d
+	var d *flow.Dataset
+	switch table := e.scan.source.Dataset; {
+	case len(e.addedRows) == 0:
+		d = e.scan.Exec()
+	case len(table.IsLocalSorted) > 0:
+		merged := table.MergeSortedWith("union_scan", table.Flow.Slices(e.addedRows))
+		d = e.scan.selectFields(merged, mergedFields(table.IsLocalSorted, len(e.scan.tableInfo.Columns)))
+	default:
+		added := table.Flow.Slices(e.addedRows)
+		if len(table.Shards) > 1 {
+			added = added.RoundRobin("union_scan.shards", len(table.Shards))
+		}
+		d = e.scan.selectFields(table.Union("union_scan", []*flow.Dataset{added}, false), nil)
+	}
+	if e.arg != "" {
+		d = d.MapWithArg("union_scan.filter", filterMapper, e.arg)
+	}
+	return d
+}
 
-	return ret
+// mergedFields returns the field of each of the count columns of the rows
+// merged by the orderBys, which move the sorted fields first, see
+// util.Row.UseKeys().
+func mergedFields(orderBys []instruction.OrderBy, count int) []int {
+	fields := make([]int, count)
+	for i, orderBy := range orderBys {
+		fields[orderBy.Index-1] = i + 1
+	}
+	next := len(orderBys) + 1
+	for i := range fields {
+		if fields[i] == 0 {
+			fields[i] = next
+			next++
+		}
+	}
+	return fields
 }
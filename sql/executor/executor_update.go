@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/table"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// UpdateExec changes the rows inserted into the table of a sink in the
+// transaction of the session, which are written to the sink by COMMIT.
+// The sinks only append rows, so the rows committed before can not change.
+type UpdateExec struct {
+	ctx         context.Context
+	dirty       *DirtyStatement
+	key         string
+	source      *TableSource
+	columns     []*model.ColumnInfo // the columns of the schema
+	schema      expression.Schema
+	condition   expression.Expression
+	assignments []*expression.Assignment // by the columns of the schema, nil if not assigned
+}
+
+// Schema implements the Executor Schema interface.
+func (e *UpdateExec) Schema() expression.Schema {
+	return expression.NewSchema(nil)
+}
+
+// Exec implements the Executor Exec interface.
+// The rows are changed by run(), before the statement returns.
+func (e *UpdateExec) Exec() *flow.Dataset {
+	return nil
+}
+
+// run evaluates the condition and the assignments on the rows, and counts
+// the changed rows as the affected rows of the statement.
+func (e *UpdateExec) run() error {
+	var condition expression.Expression
+	if e.condition != nil {
+		condition = e.condition.Clone()
+		condition.ResolveIndices(e.schema)
+	}
+	exprs := make([]expression.Expression, len(e.assignments))
+	var assigned []*table.Column
+	for i, assign := range e.assignments {
+		if assign == nil {
+			continue
+		}
+		exprs[i] = assign.Expr.Clone()
+		exprs[i].ResolveIndices(e.schema)
+		assigned = append(assigned, table.ToColumn(e.columns[i]))
+	}
+
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for index, row := range e.dirty.addedRows(e.key, e.source) {
+		values := make([]types.Datum, len(e.columns))
+		for i, col := range e.columns {
+			values[i] = row[col.Offset]
+		}
+		if condition != nil {
+			matched, err := expression.EvalBool(condition, values, e.ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		updated := append([]types.Datum(nil), row...)
+		for i, expr := range exprs {
+			if expr == nil {
+				continue
+			}
+			value, err := expr.Eval(values, e.ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			updated[e.columns[i].Offset] = value
+		}
+		if err := table.CastValues(e.ctx, updated, assigned, false); err != nil {
+			return errors.Trace(err)
+		}
+		e.dirty.updateRow(e.key, e.source, index, updated)
+		sc.AddAffectedRows(1)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/model"
+)
+
+// udfColumnID is the FromID of the columns of the answers of the UDFs.
+const udfColumnID = "udf"
+
+// udfCall is a call of a UDF in the select fields. The values of its
+// arguments are appended to the rows, then replaced by the answers of the
+// UDF's program, see Dataset.PipeColumn().
+type udfCall struct {
+	udf      *expression.UDF
+	width    int    // the fields of the rows before the arguments
+	argCount int    // the arguments appended
+	fields   []int  // the 1-based fields of the rows and the arguments, if the arguments are columns
+	arg      string // else the rows and the arguments, see pushDownArg()
+}
+
+// buildUDFCalls replaces the calls of UDFs in the expressions by columns of
+// their answers, appended to the columns of the source in order. It returns
+// the expressions and the schema of the rows with the answers.
+func (b *executorBuilder) buildUDFCalls(exprs []expression.Expression, src Executor) ([]expression.Expression, expression.Schema, []*udfCall, error) {
+	schema := expression.NewSchema(append([]*expression.Column(nil), src.Schema().Columns...))
+	var calls []*udfCall
+	var replace func(expr expression.Expression) (expression.Expression, error)
+	replace = func(expr expression.Expression) (expression.Expression, error) {
+		sf, ok := expr.(*expression.ScalarFunction)
+		if !ok {
+			return expr, nil
+		}
+		udf := expression.UDFOf(sf)
+		if udf == nil {
+			args := sf.GetArgs()
+			for i, arg := range args {
+				replaced, err := replace(arg)
+				if err != nil {
+					return nil, err
+				}
+				args[i] = replaced
+			}
+			return sf, nil
+		}
+		for _, arg := range sf.GetArgs() {
+			if expression.ContainsUDF(arg) {
+				return nil, fmt.Errorf("the arguments of %s can not call functions run by pipes", sf)
+			}
+		}
+		call, err := b.buildUDFCall(udf, sf.GetArgs(), schema)
+		if err != nil {
+			return nil, err
+		}
+		col := &expression.Column{
+			FromID:   udfColumnID,
+			Position: len(calls),
+			ColName:  model.NewCIStr(sf.String()),
+			RetType:  sf.GetType(),
+		}
+		schema.Append(col)
+		calls = append(calls, call)
+		return col, nil
+	}
+
+	replaced := make([]expression.Expression, len(exprs))
+	for i, expr := range exprs {
+		var err error
+		if replaced[i], err = replace(expr.Clone()); err != nil {
+			return nil, schema, nil, err
+		}
+	}
+	return replaced, schema, calls, nil
+}
+
+// buildUDFCall appends the arguments to the rows of the schema, selected if
+// they are columns, or else evaluated by the executors.
+func (b *executorBuilder) buildUDFCall(udf *expression.UDF, args []expression.Expression, schema expression.Schema) (*udfCall, error) {
+	call := &udfCall{udf: udf, width: schema.Len(), argCount: len(args)}
+	if len(args) == 0 {
+		return call, nil
+	}
+	fields := sequence(1, call.width)
+	for _, arg := range args {
+		col, isColumn := arg.(*expression.Column)
+		if !isColumn || schema.GetColumnIndex(col) < 0 {
+			fields = nil
+			break
+		}
+		fields = append(fields, schema.GetColumnIndex(col)+1)
+	}
+	if fields != nil {
+		call.fields = fields
+		return call, nil
+	}
+	exprs := expression.Column2Exprs(schema.Columns)
+	arg, err := pushDownArg(b.ctx, append(exprs, args...), schema)
+	if err != nil {
+		return nil, err
+	}
+	call.arg = arg
+	return call, nil
+}
+
+// execUDFCalls runs the programs of the UDFs, and converts their answers to
+// the types returned by the UDFs.
+func (e *ProjectionExec) execUDFCalls(d *flow.Dataset) *flow.Dataset {
+	for _, call := range e.udfCalls {
+		name := "projection." + call.udf.Name
+		if call.fields != nil {
+			d = d.Select(name+".args", flow.Field(call.fields...))
+		} else if call.arg != "" {
+			d = d.MapWithArg(name+".args", projectMapper, call.arg)
+		}
+		d = d.PipeColumn(name, call.udf.Code, call.argCount)
+		if t := convertType(call.udf.RetType.Tp); t != "" && t != instruction.ConvertString {
+			types := make([]string, call.width+1)
+			types[call.width] = t
+			d = d.Convert(name+".convert", types...)
+		}
+	}
+	return d
+}
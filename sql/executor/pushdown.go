@@ -0,0 +1,168 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// The mappers evaluating the filters and projections of the plans on the
+// executors of the flows, instead of the driver. Their argument, see
+// pushDownArg(), has the expressions and the session variables they need.
+var (
+	filterMapper  = gio.RegisterMapper(filterRows)
+	projectMapper = gio.RegisterMapper(projectRows)
+)
+
+// pushedDown is the argument of the mappers.
+type pushedDown struct {
+	Exprs         json.RawMessage `json:"exprs"`
+	StrictSQLMode bool            `json:"strictSQLMode,omitempty"`
+	InSelectStmt  bool            `json:"inSelectStmt,omitempty"`
+	TimeZone      string          `json:"timeZone,omitempty"`
+}
+
+// pushDownArg serializes the expressions over the rows of the schema, and
+// the session variables to evaluate them, as the argument of the mappers.
+func pushDownArg(ctx context.Context, exprs []expression.Expression, schema expression.Schema) (string, error) {
+	resolved := make([]expression.Expression, len(exprs))
+	for i, expr := range exprs {
+		resolved[i] = expr.Clone()
+		resolved[i].ResolveIndices(schema)
+	}
+	serialized, err := expression.MarshalExprs(resolved, ctx)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	vars := ctx.GetSessionVars()
+	arg := pushedDown{
+		Exprs:         serialized,
+		StrictSQLMode: vars.StrictSQLMode,
+		InSelectStmt:  vars.StmtCtx.InSelectStmt,
+	}
+	if vars.TimeZone != nil {
+		arg.TimeZone = vars.TimeZone.String()
+	}
+	data, err := json.Marshal(arg)
+	return string(data), errors.Trace(err)
+}
+
+// uncorrelated splits the conditions, and drops the correlated ones, as the
+// applies turn them into join keys, see buildApply().
+func uncorrelated(conditions []expression.Expression) (ret []expression.Expression) {
+	for _, cond := range conditions {
+		for _, item := range expression.SplitCNFItems(cond) {
+			if !item.IsCorrelated() {
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
+}
+
+// mapperExprs are the expressions of the argument of the mapper, parsed once
+// by each task.
+var mapperExprs struct {
+	sync.Once
+	ctx   context.Context
+	exprs []expression.Expression
+	err   error
+}
+
+func parseMapperArg() (context.Context, []expression.Expression, error) {
+	mapperExprs.Do(func() {
+		var arg pushedDown
+		if err := json.Unmarshal([]byte(gio.MapperArg()), &arg); err != nil {
+			mapperExprs.err = fmt.Errorf("Failed to parse the mapper argument: %v", err)
+			return
+		}
+		vars := variable.NewSessionVars()
+		vars.StrictSQLMode = arg.StrictSQLMode
+		if arg.TimeZone != "" {
+			loc, err := time.LoadLocation(arg.TimeZone)
+			if err != nil {
+				mapperExprs.err = fmt.Errorf("Failed to load time zone %s: %v", arg.TimeZone, err)
+				return
+			}
+			vars.TimeZone = loc
+		}
+		vars.StmtCtx = &variable.StatementContext{TimeZone: vars.TimeZone, InSelectStmt: arg.InSelectStmt}
+		ctx := &mapperContext{vars: vars, values: make(map[fmt.Stringer]interface{})}
+		mapperExprs.ctx = ctx
+		mapperExprs.exprs, mapperExprs.err = expression.UnmarshalExprs(arg.Exprs, ctx)
+	})
+	return mapperExprs.ctx, mapperExprs.exprs, mapperExprs.err
+}
+
+// filterRows emits the rows satisfying all the expressions.
+func filterRows(row []interface{}) error {
+	ctx, exprs, err := parseMapperArg()
+	if err != nil {
+		return err
+	}
+	datums := toDatums(row)
+	for _, expr := range exprs {
+		matched, err := expression.EvalBool(expr, datums, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !matched {
+			return nil
+		}
+	}
+	return gio.Emit(row...)
+}
+
+// projectRows emits the values of the expressions.
+func projectRows(row []interface{}) error {
+	ctx, exprs, err := parseMapperArg()
+	if err != nil {
+		return err
+	}
+	datums := toDatums(row)
+	values := make([]types.Datum, len(exprs))
+	for i, expr := range exprs {
+		if values[i], err = expr.Eval(datums, ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return gio.Emit(EncodeRowValues(values)...)
+}
+
+func toDatums(row []interface{}) []types.Datum {
+	datums := make([]types.Datum, len(row))
+	for i, v := range row {
+		datums[i] = types.NewDatum(v)
+	}
+	return datums
+}
+
+// mapperContext is the context of the expressions evaluated by the mappers.
+type mapperContext struct {
+	vars   *variable.SessionVars
+	values map[fmt.Stringer]interface{}
+}
+
+func (c *mapperContext) SetValue(key fmt.Stringer, value interface{}) {
+	c.values[key] = value
+}
+
+func (c *mapperContext) Value(key fmt.Stringer) interface{} {
+	return c.values[key]
+}
+
+func (c *mapperContext) ClearValue(key fmt.Stringer) {
+	delete(c.values, key)
+}
+
+func (c *mapperContext) GetSessionVars() *variable.SessionVars {
+	return c.vars
+}
@@ -1,20 +1,211 @@
 package executor
 
 import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
 	"github.com/lovelly/gleam/flow"
 	"github.com/lovelly/gleam/sql/model"
 )
 
+// TableColumn describes a column of a registered table.
+// BIT, ENUM and SET columns hold the numeric values of EncodeRowValues(),
+// and TableSource.ColumnElems lists the members of the ENUM and SET columns.
 type TableColumn struct {
 	ColumnName string
 	ColumnType byte
 }
 
+// TableLocation is the file source of the dataset of a table,
+// so the table can be registered again from a saved catalog.
+type TableLocation struct {
+	FileType       string // csv, tsv, txt, jsonl, orc or parquet
+	Path           string // file name or pattern
+	PartitionCount int
+	HasHeader      bool
+	Fields         []string // the fields read from jsonl, orc or parquet files, all if empty
+}
+
+// TableSource is a registered table. Queries read its Dataset,
+// and INSERT INTO ... SELECT writes the rows to its Sink.
+// Location is set if the Dataset is read from files, Stream if it is
+// read from a message queue, and Cache if it is read from the shards kept by
+// flow.Dataset.CacheTo(). The tables of the table functions, e.g.
+// RANGE(10), have no Dataset, and each statement reads their Source.
+// A union table reads the union of its Parts, see OpenTable().
 type TableSource struct {
+	DBName    string
 	Dataset   *flow.Dataset
 	TableInfo *model.TableInfo
+	Sink      func(row []interface{}) error
+	Location  *TableLocation
+	Stream    *Stream
+	Cache     *TableCache
+	Source    flow.Sourcer
+	Parts     []*TableSource
+	Columns   []TableColumn
+	// ColumnElems are the members of the ENUM and SET columns, by column name.
+	ColumnElems map[string][]string
+}
+
+// TableCache names the shards of a table kept by flow.Dataset.CacheTo(),
+// e.g. of a temporary table.
+type TableCache struct {
+	Name       string
+	ShardCount int
 }
 
+// Stream is a table of the messages of a queue, e.g. a kafka topic, bound by
+// CREATE STREAM. Each run of the flow reading it reads the next messages.
+type Stream struct {
+	Type    string            // the source, e.g. kafka
+	Fields  []string          // the column names
+	Options map[string]string // the options of the WITH clause
+}
+
+// StreamSources read the messages of the streams, by the stream type.
+// Kafka is added by importing "github.com/lovelly/gleam/sql/streamsource".
+var StreamSources = make(map[string]func(f *flow.Flow, name string, stream *Stream) (*flow.Dataset, error))
+
+// OpenTableLocation reads the files of the location into a dataset.
+// It is set by importing "github.com/lovelly/gleam/sql/filesource".
+var OpenTableLocation func(f *flow.Flow, location *TableLocation) (*flow.Dataset, error)
+
+// DefaultDB is the database of the tables registered without a database name,
+// and the current database until a USE statement.
+const DefaultDB = "gleam"
+
 var (
-	Tables = make(map[string]*TableSource)
+	// databases are the names of the databases, in lower case. They are added
+	// by registering their tables and by CREATE DATABASE.
+	databases     = map[string]bool{DefaultDB: true}
+	databasesLock sync.RWMutex
+	// tables are the registered tables, keyed by TableKey().
+	tables     = make(map[string]*TableSource)
+	tablesLock sync.RWMutex
+	// schemaVersion counts the changes of the databases and tables.
+	schemaVersion int64
 )
+
+// SchemaVersion changes whenever databases or tables are added, replaced or
+// removed, e.g. to invalidate the plans cached for the previous tables.
+func SchemaVersion() int64 {
+	return atomic.LoadInt64(&schemaVersion)
+}
+
+// GetTable returns the registered table of the key.
+func GetTable(key string) (*TableSource, bool) {
+	tablesLock.RLock()
+	defer tablesLock.RUnlock()
+	ts, found := tables[key]
+	return ts, found
+}
+
+// PutTable registers the table, replacing the table of the same key.
+func PutTable(key string, ts *TableSource) {
+	tablesLock.Lock()
+	defer tablesLock.Unlock()
+	tables[key] = ts
+	atomic.AddInt64(&schemaVersion, 1)
+}
+
+// AddTable registers the table, and returns false if a table of the key is
+// registered already.
+func AddTable(key string, ts *TableSource) bool {
+	tablesLock.Lock()
+	defer tablesLock.Unlock()
+	if _, found := tables[key]; found {
+		return false
+	}
+	tables[key] = ts
+	atomic.AddInt64(&schemaVersion, 1)
+	return true
+}
+
+// DeleteTable removes the table of the key if canDelete accepts it, and
+// returns whether it is removed.
+func DeleteTable(key string, canDelete func(ts *TableSource) bool) bool {
+	tablesLock.Lock()
+	defer tablesLock.Unlock()
+	ts, found := tables[key]
+	if !found || !canDelete(ts) {
+		return false
+	}
+	delete(tables, key)
+	atomic.AddInt64(&schemaVersion, 1)
+	return true
+}
+
+// AllTables returns a copy of the registered tables, keyed by TableKey().
+func AllTables() map[string]*TableSource {
+	tablesLock.RLock()
+	defer tablesLock.RUnlock()
+	copied := make(map[string]*TableSource, len(tables))
+	for key, ts := range tables {
+		copied[key] = ts
+	}
+	return copied
+}
+
+// SetTables replaces the registered tables, and returns the replaced ones,
+// e.g. for tests to restore them.
+func SetTables(newTables map[string]*TableSource) map[string]*TableSource {
+	tablesLock.Lock()
+	defer tablesLock.Unlock()
+	old := tables
+	tables = newTables
+	atomic.AddInt64(&schemaVersion, 1)
+	return old
+}
+
+// HasDatabase checks whether the database is added.
+// Database names are case insensitive.
+func HasDatabase(dbName string) bool {
+	databasesLock.RLock()
+	defer databasesLock.RUnlock()
+	return databases[strings.ToLower(dbName)]
+}
+
+// AddDatabase adds the database, and returns false if it is already added.
+func AddDatabase(dbName string) bool {
+	databasesLock.Lock()
+	defer databasesLock.Unlock()
+	dbName = strings.ToLower(dbName)
+	if databases[dbName] {
+		return false
+	}
+	databases[dbName] = true
+	atomic.AddInt64(&schemaVersion, 1)
+	return true
+}
+
+// DatabaseNames returns the sorted names of the databases, in lower case.
+func DatabaseNames() (dbNames []string) {
+	databasesLock.RLock()
+	defer databasesLock.RUnlock()
+	for dbName := range databases {
+		dbNames = append(dbNames, dbName)
+	}
+	sort.Strings(dbNames)
+	return dbNames
+}
+
+// SetDatabases replaces the databases, e.g. to restore the ones returned by
+// DatabaseNames().
+func SetDatabases(dbNames []string) {
+	databasesLock.Lock()
+	defer databasesLock.Unlock()
+	databases = make(map[string]bool)
+	for _, dbName := range dbNames {
+		databases[strings.ToLower(dbName)] = true
+	}
+	atomic.AddInt64(&schemaVersion, 1)
+}
+
+// TableKey returns the key of a registered table.
+// Database and table names are case insensitive.
+func TableKey(dbName, tableName string) string {
+	return strings.ToLower(dbName) + "." + strings.ToLower(tableName)
+}
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"github.com/lovelly/gleam/flow"
+)
+
+// limitParallelism merges the shards of a table beyond the most shards read
+// by a query, set by gleam_max_parallelism, so the steps after it run fewer
+// tasks.
+func limitParallelism(d *flow.Dataset, maxParallelism uint64) *flow.Dataset {
+	if maxParallelism == 0 || uint64(len(d.Shards)) <= maxParallelism {
+		return d
+	}
+	ret := d.MergeTo("parallelism", int(maxParallelism))
+	// the merged shards mix the partitions
+	ret.IsPartitionedBy = nil
+	return ret
+}
+
+// limitMemory caps the memory requested for each task of the steps of the
+// query, set by gleam_max_memory_mb, keeping lower caps already set. The
+// steps of the datasets of the tables are shared by the queries, so they are
+// left as they are. The cap only lowers the memory the scheduler reserves for
+// the tasks, so an agent runs more of them at once; the tasks are not stopped
+// when they use more.
+func limitMemory(d *flow.Dataset, maxMemoryMB uint64, tables map[*flow.Dataset]bool) {
+	if d == nil || maxMemoryMB == 0 {
+		return
+	}
+	visited := make(map[*flow.Step]bool)
+	var visit func(d *flow.Dataset)
+	visit = func(d *flow.Dataset) {
+		step := d.Step
+		if step == nil || visited[step] || tables[d] {
+			return
+		}
+		visited[step] = true
+		if step.MaxMemoryMB == 0 || uint64(step.MaxMemoryMB) > maxMemoryMB {
+			d.Hint(flow.MaxMemoryMB(int(maxMemoryMB)))
+		}
+		for _, input := range step.InputDatasets {
+			visit(input)
+		}
+	}
+	visit(d)
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// EncodeRowValues converts datums to values the gleam row codec can carry.
+// BIT values become their number, ENUM values their index and SET values their
+// bitmask, so comparing, hashing and sorting rows follows MySQL.
+func EncodeRowValues(datums []types.Datum) []interface{} {
+	values := make([]interface{}, len(datums))
+	for i, d := range datums {
+		values[i] = encodeRowValue(d)
+	}
+	return values
+}
+
+func encodeRowValue(d types.Datum) interface{} {
+	switch d.Kind() {
+	case types.KindMysqlBit:
+		return d.GetMysqlBit().Value
+	case types.KindMysqlEnum:
+		return d.GetMysqlEnum().Value
+	case types.KindMysqlSet:
+		return d.GetMysqlSet().Value
+	case types.KindMysqlHex:
+		return d.GetMysqlHex().Value
+	case types.KindMysqlDecimal, types.KindMysqlTime, types.KindMysqlDuration:
+		s, _ := d.ToString()
+		return s
+	}
+	return d.GetValue()
+}
+
+// DecodeRowValues converts the values of a result row back to datums of the
+// schema's column types, e.g. to show ENUM and SET columns by their names.
+// ENUM and SET values may also be given by name.
+func DecodeRowValues(sc *variable.StatementContext, schema expression.Schema, values []interface{}) ([]types.Datum, error) {
+	datums := make([]types.Datum, len(values))
+	for i, v := range values {
+		d := types.NewDatum(v)
+		if i >= schema.Len() || d.IsNull() {
+			datums[i] = d
+			continue
+		}
+		converted, err := d.ConvertTo(sc, schema.Columns[i].RetType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		datums[i] = converted
+	}
+	return datums, nil
+}
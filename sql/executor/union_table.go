@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/instruction"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+// OpenTable reads the table again on the flow: the files of its Location, its
+// Stream, the shards of its Cache, the Source of a table function, or the Parts of a union table.
+// The tables of other datasets can only be read once, so it returns nil.
+func OpenTable(f *flow.Flow, ts *TableSource) (*flow.Dataset, error) {
+	switch {
+	case ts.Location != nil:
+		if OpenTableLocation == nil {
+			return nil, fmt.Errorf("no file source to read %s, import github.com/lovelly/gleam/sql/filesource", ts.Location.Path)
+		}
+		return OpenTableLocation(f, ts.Location)
+	case ts.Stream != nil:
+		open := StreamSources[ts.Stream.Type]
+		if open == nil {
+			return nil, fmt.Errorf("unknown stream type %s", ts.Stream.Type)
+		}
+		return open(f, ts.TableInfo.Name.L, ts.Stream)
+	case ts.Cache != nil:
+		return f.Cached(ts.Cache.Name, ts.Cache.ShardCount), nil
+	case ts.Source != nil:
+		return f.Read(ts.Source), nil
+	case len(ts.Parts) > 0:
+		return openUnionTable(f, ts)
+	}
+	return nil, nil
+}
+
+// openUnionTable reads the parts, with their columns aligned to the table's
+// columns, as one dataset with the shard count of the first part.
+func openUnionTable(f *flow.Flow, ts *TableSource) (*flow.Dataset, error) {
+	name := ts.TableInfo.Name.L
+	var first *flow.Dataset
+	var others []*flow.Dataset
+	for _, part := range ts.Parts {
+		d, err := OpenTable(f, part)
+		if err != nil {
+			return nil, fmt.Errorf("part %s: %v", part.TableInfo.Name, err)
+		}
+		if d == nil {
+			return nil, fmt.Errorf("part %s can not be read again, only the tables of files and streams can", part.TableInfo.Name)
+		}
+		d = alignColumns(name, d, part.Columns, ts.Columns)
+		if first == nil {
+			first = d
+			continue
+		}
+		if len(d.Shards) != len(first.Shards) {
+			d = d.RoundRobin(name+".shards", len(first.Shards))
+		}
+		others = append(others, d)
+	}
+	if len(others) == 0 {
+		return first, nil
+	}
+	return first.Union(name+".union", others, true), nil
+}
+
+// alignColumns selects the columns of the part by name, in the order of the
+// table's columns, with nil for the missing ones, and converts the values to
+// the column types, e.g. the strings of csv files to numbers. Reading the
+// table fails on the values not convertible.
+func alignColumns(name string, d *flow.Dataset, partColumns, columns []TableColumn) *flow.Dataset {
+	fields := make([]int, len(columns))
+	types := make([]string, len(columns))
+	converting := false
+	for i, c := range columns {
+		fields[i] = len(partColumns) + 1 // beyond the row, so nil
+		for j, p := range partColumns {
+			if strings.EqualFold(p.ColumnName, c.ColumnName) {
+				fields[i] = j + 1
+				break
+			}
+		}
+		if types[i] = convertType(c.ColumnType); types[i] != "" {
+			converting = true
+		}
+	}
+	if !isSequence(fields, len(partColumns)) {
+		d = d.Select(name+".align", flow.Field(fields...))
+	}
+	if converting {
+		d = d.Convert(name+".convert", types...)
+	}
+	return d
+}
+
+// convertType is the type to convert the values of the column type to,
+// or empty to keep the values.
+func convertType(columnType byte) string {
+	switch columnType {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		return instruction.ConvertInt
+	case mysql.TypeFloat, mysql.TypeDouble:
+		return instruction.ConvertFloat
+	case mysql.TypeDecimal, mysql.TypeNewDecimal:
+		return instruction.ConvertDecimal
+	case mysql.TypeDate:
+		return instruction.ConvertDate
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		return instruction.ConvertDatetime
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString, mysql.TypeBlob,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return instruction.ConvertString
+	}
+	return ""
+}
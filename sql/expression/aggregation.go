@@ -366,7 +366,7 @@ func (sf *sumFunction) GetType() *types.FieldType {
 	ft := types.NewFieldType(mysql.TypeNewDecimal)
 	ft.Charset = charset.CharsetBin
 	ft.Collate = charset.CollationBin
-	ft.Decimal = sf.Args[0].GetType().Decimal
+	ft.Flen, ft.Decimal = SumDecimalType(sf.Args[0].GetType())
 	return ft
 }
 
@@ -516,7 +516,7 @@ func (af *avgFunction) GetType() *types.FieldType {
 	ft := types.NewFieldType(mysql.TypeNewDecimal)
 	ft.Charset = charset.CharsetBin
 	ft.Collate = charset.CollationBin
-	ft.Decimal = af.Args[0].GetType().Decimal
+	ft.Flen, ft.Decimal = AvgDecimalType(af.Args[0].GetType())
 	return ft
 }
 
@@ -647,7 +647,7 @@ func (cf *concatFunction) Update(row []types.Datum, groupKey []byte, ectx contex
 	for _, val := range vals {
 		ctx.Buffer.WriteString(fmt.Sprintf("%v", val))
 	}
-	// TODO: if total length is greater than global var group_concat_max_len, truncate it.
+	cf.truncate(ctx, ectx)
 	return nil
 }
 
@@ -683,10 +683,18 @@ func (cf *concatFunction) StreamUpdate(row []types.Datum, ectx context.Context)
 	for _, val := range vals {
 		ctx.Buffer.WriteString(fmt.Sprintf("%v", val))
 	}
-	// TODO: if total length is greater than global var group_concat_max_len, truncate it.
+	cf.truncate(ctx, ectx)
 	return nil
 }
 
+// truncate cuts the result to group_concat_max_len bytes.
+func (cf *concatFunction) truncate(ctx *aggEvaluateContext, ectx context.Context) {
+	maxLen := ectx.GetSessionVars().GroupConcatMaxLen
+	if uint64(ctx.Buffer.Len()) > maxLen {
+		ctx.Buffer.Truncate(int(maxLen))
+	}
+}
+
 // GetGroupResult implements AggregationFunction interface.
 func (cf *concatFunction) GetGroupResult(groupKey []byte) (d types.Datum) {
 	ctx := cf.getContext(groupKey)
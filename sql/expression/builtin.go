@@ -52,6 +52,13 @@ func (b *baseBuiltinFunc) evalArgs(row []types.Datum) (_ []types.Datum, err erro
 	return b.argValues, nil
 }
 
+// evalArg evaluates the ith argument only, for the control functions that
+// skip the arguments they do not need, e.g. the other branch of IF().
+func (b *baseBuiltinFunc) evalArg(row []types.Datum, i int) (types.Datum, error) {
+	d, err := b.args[i].Eval(row, b.ctx)
+	return d, errors.Trace(err)
+}
+
 // isDeterministic will be true by default. Non-deterministic function will override this function.
 func (b *baseBuiltinFunc) isDeterministic() bool {
 	return b.deterministic
@@ -222,16 +229,27 @@ var funcs = map[string]functionClass{
 	ast.CharFunc:       &charFunctionClass{baseFunctionClass{ast.CharFunc, 2, -1}},
 	ast.CharLength:     &charLengthFunctionClass{baseFunctionClass{ast.CharLength, 1, 1}},
 	ast.FindInSet:      &findInSetFunctionClass{baseFunctionClass{ast.FindInSet, 2, 2}},
+	ast.Lpad:           &lpadFunctionClass{baseFunctionClass{ast.Lpad, 3, 3}},
+	ast.Instr:          &instrFunctionClass{baseFunctionClass{ast.Instr, 2, 2}},
+	ast.Format:         &formatFunctionClass{baseFunctionClass{ast.Format, 2, 3}},
+	ast.Elt:            &eltFunctionClass{baseFunctionClass{ast.Elt, 2, -1}},
+	ast.ExportSet:      &exportSetFunctionClass{baseFunctionClass{ast.ExportSet, 3, 5}},
+	ast.Quote:          &quoteFunctionClass{baseFunctionClass{ast.Quote, 1, 1}},
+	ast.RegexpReplace:  &regexpReplaceFunctionClass{baseFunctionClass{ast.RegexpReplace, 3, 6}},
+	ast.RegexpSubstr:   &regexpSubstrFunctionClass{baseFunctionClass{ast.RegexpSubstr, 2, 5}},
 
 	// information functions
-	ast.CurrentUser: &currentUserFunctionClass{baseFunctionClass{ast.CurrentUser, 0, 0}},
-	ast.Database:    &databaseFunctionClass{baseFunctionClass{ast.Database, 0, 0}},
+	ast.ConnectionID: &connectionIDFunctionClass{baseFunctionClass{ast.ConnectionID, 0, 0}},
+	ast.CurrentUser:  &currentUserFunctionClass{baseFunctionClass{ast.CurrentUser, 0, 0}},
+	ast.Database:     &databaseFunctionClass{baseFunctionClass{ast.Database, 0, 0}},
 	// This function is a synonym for DATABASE().
 	// See http://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_schema
-	ast.Schema:    &databaseFunctionClass{baseFunctionClass{ast.Schema, 0, 0}},
-	ast.FoundRows: &foundRowsFunctionClass{baseFunctionClass{ast.FoundRows, 0, 0}},
-	ast.User:      &userFunctionClass{baseFunctionClass{ast.User, 0, 0}},
-	ast.Version:   &versionFunctionClass{baseFunctionClass{ast.Version, 0, 0}},
+	ast.Schema:       &databaseFunctionClass{baseFunctionClass{ast.Schema, 0, 0}},
+	ast.FoundRows:    &foundRowsFunctionClass{baseFunctionClass{ast.FoundRows, 0, 0}},
+	ast.LastInsertId: &lastInsertIDFunctionClass{baseFunctionClass{ast.LastInsertId, 0, 1}},
+	ast.RowCount:     &rowCountFunctionClass{baseFunctionClass{ast.RowCount, 0, 0}},
+	ast.User:         &userFunctionClass{baseFunctionClass{ast.User, 0, 0}},
+	ast.Version:      &versionFunctionClass{baseFunctionClass{ast.Version, 0, 0}},
 
 	// control functions
 	ast.If:     &ifFunctionClass{baseFunctionClass{ast.If, 3, 3}},
@@ -294,6 +312,7 @@ var DynamicFuncs = map[string]int{
 	"database":       0,
 	"found_rows":     0,
 	"last_insert_id": 0,
+	"row_count":      0,
 	"user":           0,
 	"version":        0,
 	"sleep":          0,
@@ -18,7 +18,9 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/mysql"
 	"github.com/lovelly/gleam/sql/parser/opcode"
+	"github.com/lovelly/gleam/sql/util/charset"
 	"github.com/lovelly/gleam/sql/util/types"
 )
 
@@ -50,19 +52,13 @@ type builtinCoalesceSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinCoalesceSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
-	}
-	return builtinCoalesce(args, b.ctx)
-}
-
+// eval returns the first argument that is not NULL, without evaluating the
+// arguments after it.
 // See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
-func builtinCoalesce(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	for _, d = range args {
-		if !d.IsNull() {
-			return d, nil
+func (b *builtinCoalesceSig) eval(row []types.Datum) (d types.Datum, err error) {
+	for i := range b.args {
+		if d, err = b.evalArg(row, i); err != nil || !d.IsNull() {
+			return d, errors.Trace(err)
 		}
 	}
 	return d, nil
@@ -285,3 +281,166 @@ func compareFuncFactory(op opcode.Op) BuiltinFunc {
 		return
 	}
 }
+
+// The classes of types that comparison arguments are compared as.
+// See https://dev.mysql.com/doc/refman/5.7/en/type-conversion.html
+const (
+	cmpClassUnknown = iota
+	cmpClassNull
+	cmpClassInt
+	cmpClassDecimal
+	cmpClassReal
+	cmpClassString
+	cmpClassTime
+	cmpClassDuration
+)
+
+func cmpClass(tp *types.FieldType) int {
+	if tp == nil {
+		return cmpClassUnknown
+	}
+	switch tp.Tp {
+	case mysql.TypeNull:
+		return cmpClassNull
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeYear, mysql.TypeBit:
+		return cmpClassInt
+	case mysql.TypeDecimal, mysql.TypeNewDecimal:
+		return cmpClassDecimal
+	case mysql.TypeFloat, mysql.TypeDouble:
+		return cmpClassReal
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeTinyBlob,
+		mysql.TypeMediumBlob, mysql.TypeBlob, mysql.TypeLongBlob, mysql.TypeEnum, mysql.TypeSet:
+		return cmpClassString
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return cmpClassTime
+	case mysql.TypeDuration:
+		return cmpClassDuration
+	}
+	return cmpClassUnknown
+}
+
+// mergeCmpClass returns the class that arguments of classes a and b are
+// compared as: integers with decimals as decimals, strings with times as
+// times, and all other mixes as reals.
+func mergeCmpClass(a, b int) int {
+	switch {
+	case a == cmpClassUnknown || b == cmpClassUnknown:
+		return cmpClassUnknown
+	case a == cmpClassNull:
+		return b
+	case b == cmpClassNull, a == b:
+		return a
+	}
+	if a > b {
+		a, b = b, a
+	}
+	if a == cmpClassInt && b == cmpClassDecimal {
+		return cmpClassDecimal
+	}
+	if a == cmpClassString && (b == cmpClassTime || b == cmpClassDuration) {
+		return b
+	}
+	return cmpClassReal
+}
+
+func cmpClassType(class int) *types.FieldType {
+	var tp *types.FieldType
+	switch class {
+	case cmpClassInt:
+		tp = types.NewFieldType(mysql.TypeLonglong)
+	case cmpClassDecimal:
+		tp = types.NewFieldType(mysql.TypeNewDecimal)
+	case cmpClassReal:
+		tp = types.NewFieldType(mysql.TypeDouble)
+	case cmpClassString:
+		tp = types.NewFieldType(mysql.TypeVarString)
+		tp.Charset, tp.Collate = types.DefaultCharsetForType(mysql.TypeVarString)
+	case cmpClassTime:
+		tp = types.NewFieldType(mysql.TypeDatetime)
+		tp.Decimal = types.MaxFsp
+	case cmpClassDuration:
+		tp = types.NewFieldType(mysql.TypeDuration)
+		tp.Decimal = types.MaxFsp
+	default:
+		return nil
+	}
+	if class != cmpClassString {
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+	}
+	return tp
+}
+
+// CompareType returns the type that values of the types are compared as, e.g.
+// by GREATEST, or nil if they are not converted for comparisons.
+func CompareType(tps ...*types.FieldType) *types.FieldType {
+	if len(tps) == 0 {
+		return nil
+	}
+	class := cmpClass(tps[0])
+	for _, tp := range tps[1:] {
+		class = mergeCmpClass(class, cmpClass(tp))
+	}
+	return cmpClassType(class)
+}
+
+// CoerceCompareArgs casts the arguments of a comparison of mixed types to the
+// type that they are compared as, instead of leaving them to be compared by
+// the kinds of their values. Constants are converted in place. A constant
+// compared with an integer or a decimal is converted to its type instead if
+// no value is lost, so that int_col = '1' still compares integers.
+func CoerceCompareArgs(ctx context.Context, args ...Expression) []Expression {
+	tps := make([]*types.FieldType, len(args))
+	for i, arg := range args {
+		tps[i] = arg.GetType()
+	}
+	tp := CompareType(tps...)
+	if tp == nil {
+		return args
+	}
+	class := cmpClass(tp)
+	ret := make([]Expression, len(args))
+	copy(ret, args)
+	if len(args) == 2 {
+		for i := range args {
+			c, ok := args[i].(*Constant)
+			other := args[1-i].GetType()
+			otherClass := cmpClass(other)
+			if !ok || (otherClass != cmpClassInt && otherClass != cmpClassDecimal) || otherClass == class {
+				continue
+			}
+			if d, ok := convertLossless(ctx, c.Value, other); ok {
+				ret[i] = &Constant{Value: d, RetType: other}
+				return ret
+			}
+		}
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	for i, arg := range args {
+		if argClass := cmpClass(tps[i]); argClass == class || argClass == cmpClassNull {
+			continue
+		}
+		if c, ok := arg.(*Constant); ok {
+			if d, err := c.Value.ConvertTo(sc, tp); err == nil {
+				ret[i] = &Constant{Value: d, RetType: tp}
+				continue
+			}
+		}
+		ret[i] = NewCastFunc(tp, arg, ctx)
+	}
+	return ret
+}
+
+// convertLossless converts the value to the type if it stays equal.
+func convertLossless(ctx context.Context, value types.Datum, tp *types.FieldType) (types.Datum, bool) {
+	if value.IsNull() {
+		return value, true
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	d, err := value.ConvertTo(sc, tp)
+	if err != nil {
+		return d, false
+	}
+	cmp, err := d.CompareDatum(sc, value)
+	return d, err == nil && cmp == 0
+}
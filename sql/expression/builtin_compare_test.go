@@ -0,0 +1,69 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestCoerceCompareArgs(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	constant := func(value interface{}, tp byte) *Constant {
+		return &Constant{Value: types.NewDatum(value), RetType: types.NewFieldType(tp)}
+	}
+	column := func(tp byte) *Column {
+		return &Column{FromID: "t", Position: 0, RetType: types.NewFieldType(tp)}
+	}
+	for _, c := range []struct {
+		name     string
+		args     []Expression
+		expected []string // the types of the coerced arguments, "cast" for casts
+	}{
+		{"int = int", []Expression{column(mysql.TypeLong), constant(1, mysql.TypeLonglong)},
+			[]string{"int", "bigint"}},
+		{"int = '1'", []Expression{column(mysql.TypeLong), constant("1", mysql.TypeVarString)},
+			[]string{"int", "int"}},
+		{"'1' = int", []Expression{constant("1", mysql.TypeVarString), column(mysql.TypeLong)},
+			[]string{"int", "int"}},
+		{"int = '1.5'", []Expression{column(mysql.TypeLong), constant("1.5", mysql.TypeVarString)},
+			[]string{"cast", "double"}},
+		{"varchar = 1", []Expression{column(mysql.TypeVarchar), constant(1, mysql.TypeLonglong)},
+			[]string{"cast", "double"}},
+		{"int = decimal", []Expression{column(mysql.TypeLong), column(mysql.TypeNewDecimal)},
+			[]string{"cast", "decimal"}},
+		{"datetime = '2017-01-02'", []Expression{column(mysql.TypeDatetime), constant("2017-01-02", mysql.TypeVarString)},
+			[]string{"datetime", "datetime"}},
+		{"varchar = NULL", []Expression{column(mysql.TypeVarchar), constant(nil, mysql.TypeNull)},
+			[]string{"varchar", "null"}},
+		{"greatest(int, varchar, double)", []Expression{column(mysql.TypeLong), column(mysql.TypeVarchar), column(mysql.TypeDouble)},
+			[]string{"cast", "cast", "double"}},
+	} {
+		args := CoerceCompareArgs(ctx, c.args...)
+		for i, arg := range args {
+			actual := types.TypeStr(arg.GetType().Tp)
+			if f, ok := arg.(*ScalarFunction); ok && f.FuncName.L == ast.Cast {
+				actual = "cast"
+			}
+			if actual != c.expected[i] {
+				t.Errorf("%s: argument %d is %s, expected %s", c.name, i, actual, c.expected[i])
+			}
+		}
+	}
+
+	// without the casts, the string '10' is compared with 9 as a string
+	f, err := NewFunction(ctx, ast.Greatest, types.NewFieldType(mysql.TypeDouble),
+		CoerceCompareArgs(ctx, constant("10", mysql.TypeVarString), constant(9, mysql.TypeLonglong))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := f.Eval(nil, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.ToFloat64(ctx.GetSessionVars().StmtCtx); err != nil || v != 10 {
+		t.Errorf("greatest('10', 9): got %v, expected 10", d.GetValue())
+	}
+}
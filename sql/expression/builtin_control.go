@@ -45,36 +45,32 @@ type builtinCaseWhenSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinCaseWhenSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
-	}
-	return builtinCaseWhen(args, b.ctx)
-}
-
+// eval evaluates the conditions until one is true, and only the result of it.
 // See https://dev.mysql.com/doc/refman/5.7/en/case.html
-func builtinCaseWhen(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	sc := ctx.GetSessionVars().StmtCtx
-	l := len(args)
+func (b *builtinCaseWhenSig) eval(row []types.Datum) (d types.Datum, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	l := len(b.args)
 	for i := 0; i < l-1; i += 2 {
-		if args[i].IsNull() {
+		cond, err := b.evalArg(row, i)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if cond.IsNull() {
 			continue
 		}
-		b, err1 := args[i].ToBool(sc)
-		if err1 != nil {
-			return d, errors.Trace(err1)
+		isTrue, err := cond.ToBool(sc)
+		if err != nil {
+			return d, errors.Trace(err)
 		}
-		if b == 1 {
-			d = args[i+1]
-			return
+		if isTrue == 1 {
+			return b.evalArg(row, i+1)
 		}
 	}
 	// when clause(condition, result) -> args[i], args[i+1]; (i >= 0 && i+1 < l-1)
 	// else clause -> args[l-1]
 	// If case clause has else clause, l%2 == 1.
 	if l%2 == 1 {
-		d = args[l-1]
+		return b.evalArg(row, l-1)
 	}
 	return
 }
@@ -91,38 +87,30 @@ type builtinIfSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinIfSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
-	}
-	return builtinIf(args, b.ctx)
-}
-
+// eval only evaluates the branch chosen by the condition.
 // See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_if
-func builtinIf(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+func (b *builtinIfSig) eval(row []types.Datum) (d types.Datum, err error) {
 	// if(expr1, expr2, expr3)
 	// if expr1 is true, return expr2, otherwise, return expr3
-	v1 := args[0]
-	v2 := args[1]
-	v3 := args[2]
-
+	v1, err := b.evalArg(row, 0)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	if v1.IsNull() {
-		return v3, nil
+		return b.evalArg(row, 2)
 	}
 
-	b, err := v1.ToBool(ctx.GetSessionVars().StmtCtx)
+	isTrue, err := v1.ToBool(b.ctx.GetSessionVars().StmtCtx)
 	if err != nil {
-		d := types.Datum{}
 		return d, errors.Trace(err)
 	}
 
 	// TODO: check return type, must be numeric or string
-	if b == 1 {
-		return v2, nil
+	if isTrue == 1 {
+		return b.evalArg(row, 1)
 	}
 
-	return v3, nil
+	return b.evalArg(row, 2)
 }
 
 type ifNullFunctionClass struct {
@@ -137,26 +125,17 @@ type builtinIfNullSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinIfNullSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
-	}
-	return builtinIfNull(args, b.ctx)
-}
-
+// eval only evaluates expr2 if expr1 is NULL.
 // See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_ifnull
-func builtinIfNull(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinIfNullSig) eval(row []types.Datum) (d types.Datum, err error) {
 	// ifnull(expr1, expr2)
 	// if expr1 is not null, return expr1, otherwise, return expr2
-	v1 := args[0]
-	v2 := args[1]
-
-	if !v1.IsNull() {
-		return v1, nil
+	v1, err := b.evalArg(row, 0)
+	if err != nil || !v1.IsNull() {
+		return v1, errors.Trace(err)
 	}
 
-	return v2, nil
+	return b.evalArg(row, 1)
 }
 
 type nullIfFunctionClass struct {
@@ -0,0 +1,64 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestShortCircuitEvaluation(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	constant := func(value interface{}) Expression {
+		return &Constant{Value: types.NewDatum(value), RetType: types.NewFieldType(mysql.TypeUnspecified)}
+	}
+	// failing fails when it is evaluated, with an invalid regular expression
+	failing, err := NewFunction(ctx, ast.Regexp, types.NewFieldType(mysql.TypeLonglong), constant("a"), constant("("))
+	if err != nil {
+		t.Fatalf("regexp: %v", err)
+	}
+	for _, c := range []struct {
+		funcName string
+		args     []Expression
+		expected interface{} // nil for NULL
+	}{
+		{ast.AndAnd, []Expression{constant(0), failing}, int64(0)},
+		{ast.OrOr, []Expression{constant(1), failing}, int64(1)},
+		{ast.If, []Expression{constant(1), constant("yes"), failing}, "yes"},
+		{ast.If, []Expression{constant(nil), failing, constant("no")}, "no"},
+		{ast.Case, []Expression{constant(0), failing, constant(1), constant("second"), failing}, "second"},
+		{ast.Case, []Expression{constant(nil), failing, constant("else")}, "else"},
+		{ast.Ifnull, []Expression{constant("set"), failing}, "set"},
+		{ast.Coalesce, []Expression{constant(nil), constant(2), failing}, int64(2)},
+	} {
+		f, err := NewFunction(ctx, c.funcName, types.NewFieldType(mysql.TypeUnspecified), c.args...)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		d, err := f.Eval(nil, ctx)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		if actual := d.GetValue(); fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("%s%v: got %v, expected %v", c.funcName, c.args, actual, c.expected)
+		}
+	}
+
+	for _, args := range [][]Expression{
+		{constant(1), failing},
+		{constant(nil), failing},
+	} {
+		f, err := NewFunction(ctx, ast.AndAnd, types.NewFieldType(mysql.TypeLonglong), args...)
+		if err != nil {
+			t.Fatalf("and: %v", err)
+		}
+		if _, err = f.Eval(nil, ctx); err == nil {
+			t.Errorf("%s%v: expected the right argument to be evaluated", ast.AndAnd, args)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package expression
 import (
 	"github.com/lovelly/gleam/sql/context"
 	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/terror"
 	"github.com/lovelly/gleam/sql/util/types"
 	"github.com/juju/errors"
 )
@@ -30,6 +31,9 @@ var (
 	_ functionClass = &currentUserFunctionClass{}
 	_ functionClass = &userFunctionClass{}
 	_ functionClass = &versionFunctionClass{}
+	_ functionClass = &connectionIDFunctionClass{}
+	_ functionClass = &lastInsertIDFunctionClass{}
+	_ functionClass = &rowCountFunctionClass{}
 )
 
 var (
@@ -38,6 +42,9 @@ var (
 	_ builtinFunc = &builtinCurrentUserSig{}
 	_ builtinFunc = &builtinUserSig{}
 	_ builtinFunc = &builtinVersionSig{}
+	_ builtinFunc = &builtinConnectionIDSig{}
+	_ builtinFunc = &builtinLastInsertIDSig{}
+	_ builtinFunc = &builtinRowCountSig{}
 )
 
 type databaseFunctionClass struct {
@@ -211,3 +218,129 @@ func builtinVersion(args []types.Datum, ctx context.Context) (d types.Datum, err
 	d.SetString(mysql.ServerVersion)
 	return d, nil
 }
+
+type connectionIDFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *connectionIDFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := errors.Trace(c.verifyArgs(args)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bt := &builtinConnectionIDSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt, nil
+}
+
+type builtinConnectionIDSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinConnectionIDSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinConnectionID(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_connection-id
+func builtinConnectionID(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	data := ctx.GetSessionVars()
+	if data == nil {
+		return d, errors.Errorf("Missing session variable when evalue builtin")
+	}
+	if data.ConnectionID == 0 {
+		return d, errors.Trace(terror.MissConnectionID)
+	}
+
+	d.SetUint64(data.ConnectionID)
+	return d, nil
+}
+
+type lastInsertIDFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *lastInsertIDFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := errors.Trace(c.verifyArgs(args)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bt := &builtinLastInsertIDSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt, nil
+}
+
+type builtinLastInsertIDSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinLastInsertIDSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinLastInsertID(args, b.ctx)
+}
+
+// builtinLastInsertID returns the value kept by the session. With an argument,
+// like LAST_INSERT_ID(id + 1) of a sequence update, it keeps the argument
+// for the following calls, and returns it.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_last-insert-id
+func builtinLastInsertID(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	data := ctx.GetSessionVars()
+	if data == nil {
+		return d, errors.Errorf("Missing session variable when evalue builtin")
+	}
+	if len(args) == 1 {
+		if args[0].IsNull() {
+			return d, nil
+		}
+		id, err := args[0].ToInt64(data.StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		data.LastInsertID = uint64(id)
+	}
+
+	d.SetUint64(data.LastInsertID)
+	return d, nil
+}
+
+type rowCountFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *rowCountFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := errors.Trace(c.verifyArgs(args)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bt := &builtinRowCountSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt, nil
+}
+
+type builtinRowCountSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinRowCountSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinRowCount(args, b.ctx)
+}
+
+// builtinRowCount returns the rows affected by the previous statement, or -1
+// if it returned rows.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_row-count
+func builtinRowCount(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	data := ctx.GetSessionVars()
+	if data == nil {
+		return d, errors.Errorf("Missing session variable when evalue builtin")
+	}
+
+	d.SetInt64(data.PrevAffectedRows)
+	return d, nil
+}
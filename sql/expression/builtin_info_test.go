@@ -0,0 +1,47 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestInformationFunctions(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	ctx.vars.ConnectionID = 7
+	ctx.vars.PrevAffectedRows = -1
+	eval := func(funcName string, args ...interface{}) interface{} {
+		var argExprs []Expression
+		for _, arg := range args {
+			argExprs = append(argExprs, &Constant{Value: types.NewDatum(arg), RetType: types.NewFieldType(mysql.TypeUnspecified)})
+		}
+		f, err := NewFunction(ctx, funcName, types.NewFieldType(mysql.TypeUnspecified), argExprs...)
+		if err != nil {
+			t.Fatalf("%s%v: %v", funcName, args, err)
+		}
+		d, err := f.Eval(nil, ctx)
+		if err != nil {
+			t.Fatalf("%s%v: %v", funcName, args, err)
+		}
+		return d.GetValue()
+	}
+
+	if id := eval(ast.ConnectionID); id != uint64(7) {
+		t.Errorf("CONNECTION_ID(): got %v", id)
+	}
+	if id := eval(ast.LastInsertId); id != uint64(0) {
+		t.Errorf("LAST_INSERT_ID(): got %v", id)
+	}
+	if id := eval(ast.LastInsertId, 42); id != uint64(42) {
+		t.Errorf("LAST_INSERT_ID(42): got %v", id)
+	}
+	if id := eval(ast.LastInsertId); id != uint64(42) {
+		t.Errorf("LAST_INSERT_ID() after LAST_INSERT_ID(42): got %v", id)
+	}
+	if rows := eval(ast.RowCount); rows != int64(-1) {
+		t.Errorf("ROW_COUNT(): got %v", rows)
+	}
+}
@@ -15,8 +15,11 @@ package expression
 
 import (
 	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
+	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/context"
 	"github.com/lovelly/gleam/sql/util/types"
 )
@@ -30,11 +33,15 @@ const (
 var (
 	_ functionClass = &likeFunctionClass{}
 	_ functionClass = &regexpFunctionClass{}
+	_ functionClass = &regexpReplaceFunctionClass{}
+	_ functionClass = &regexpSubstrFunctionClass{}
 )
 
 var (
 	_ builtinFunc = &builtinLikeSig{}
 	_ builtinFunc = &builtinRegexpSig{}
+	_ builtinFunc = &builtinRegexpReplaceSig{}
+	_ builtinFunc = &builtinRegexpSubstrSig{}
 )
 
 // Handle escapes and wild cards convert pattern characters and pattern types.
@@ -188,11 +195,12 @@ type regexpFunctionClass struct {
 }
 
 func (c *regexpFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
-	return &builtinRegexpSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+	return &builtinRegexpSig{baseBuiltinFunc: newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
 }
 
 type builtinRegexpSig struct {
 	baseBuiltinFunc
+	cache regexpCache
 }
 
 func (b *builtinRegexpSig) eval(row []types.Datum) (types.Datum, error) {
@@ -200,12 +208,11 @@ func (b *builtinRegexpSig) eval(row []types.Datum) (types.Datum, error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	return builtinRegexp(args, b.ctx)
+	return builtinRegexp(args, &b.cache)
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/regexp.html#operator_regexp
-func builtinRegexp(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
+func builtinRegexp(args []types.Datum, cache *regexpCache) (d types.Datum, err error) {
 	if args[0].IsNull() || args[1].IsNull() {
 		return
 	}
@@ -218,10 +225,218 @@ func builtinRegexp(args []types.Datum, _ context.Context) (d types.Datum, err er
 	if err != nil {
 		return d, errors.Errorf("non-string Expression in LIKE: %v (Value of type %T)", args[1], args[1])
 	}
-	re, err := regexp.Compile(patternStr)
+	re, err := cache.compile(patternStr)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
 	d.SetInt64(boolToInt64(re.MatchString(targetStr)))
 	return
 }
+
+// regexpCache keeps the last compiled pattern of a function, so that the
+// pattern is compiled once per statement unless it changes from row to row.
+type regexpCache struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func (c *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	if c.re != nil && c.pattern == pattern {
+		return c.re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c.pattern, c.re = pattern, re
+	return re, nil
+}
+
+// regexpArgs are the evaluated arguments shared by REGEXP_REPLACE and
+// REGEXP_SUBSTR, with the pattern compiled with the match type.
+type regexpArgs struct {
+	str        string
+	re         *regexp.Regexp
+	offset     int // byte offset of the position
+	occurrence int64
+}
+
+// evalRegexpArgs evaluates expr, pat and then pos, occurrence and match_type
+// starting from args[optIndex]. isNull is set if any of them is NULL.
+func evalRegexpArgs(args []types.Datum, optIndex int, occurrence int64, cache *regexpCache,
+	ctx context.Context, funcName string) (r regexpArgs, isNull bool, err error) {
+	for _, arg := range args {
+		if arg.IsNull() {
+			return r, true, nil
+		}
+	}
+	if r.str, err = args[0].ToString(); err != nil {
+		return r, false, errors.Trace(err)
+	}
+	pattern, err := args[1].ToString()
+	if err != nil {
+		return r, false, errors.Trace(err)
+	}
+
+	sc := ctx.GetSessionVars().StmtCtx
+	pos := int64(1)
+	if len(args) > optIndex {
+		if pos, err = args[optIndex].ToInt64(sc); err != nil {
+			return r, false, errors.Trace(err)
+		}
+	}
+	r.occurrence = occurrence
+	if len(args) > optIndex+1 {
+		if r.occurrence, err = args[optIndex+1].ToInt64(sc); err != nil {
+			return r, false, errors.Trace(err)
+		}
+	}
+	flags := ""
+	if len(args) > optIndex+2 {
+		matchType, err := args[optIndex+2].ToString()
+		if err != nil {
+			return r, false, errors.Trace(err)
+		}
+		if flags, err = regexpFlags(matchType, funcName); err != nil {
+			return r, false, errors.Trace(err)
+		}
+	}
+
+	if pos < 1 || pos > int64(utf8.RuneCountInString(r.str))+1 {
+		return r, false, errors.Errorf("Index out of bounds in regular expression search: %d", pos)
+	}
+	for i := int64(1); i < pos; i++ {
+		_, size := utf8.DecodeRuneInString(r.str[r.offset:])
+		r.offset += size
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	r.re, err = cache.compile(pattern)
+	return r, false, errors.Trace(err)
+}
+
+// regexpFlags converts a MySQL match type into Go regexp flags. Patterns are
+// case sensitive as in REGEXP, unless the match type has i after the last c.
+func regexpFlags(matchType, funcName string) (string, error) {
+	caseInsensitive, multiLine, dotAll := false, false, false
+	for _, c := range matchType {
+		switch c {
+		case 'c':
+			caseInsensitive = false
+		case 'i':
+			caseInsensitive = true
+		case 'm':
+			multiLine = true
+		case 'n':
+			dotAll = true
+		case 'u':
+			// only \n ends lines in Go regexps
+		default:
+			return "", errors.Errorf("Incorrect arguments to %s: match type %q", funcName, matchType)
+		}
+	}
+	var flags []string
+	if caseInsensitive {
+		flags = append(flags, "i")
+	}
+	if multiLine {
+		flags = append(flags, "m")
+	}
+	if dotAll {
+		flags = append(flags, "s")
+	}
+	return strings.Join(flags, ""), nil
+}
+
+type regexpReplaceFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpReplaceFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinRegexpReplaceSig{baseBuiltinFunc: newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinRegexpReplaceSig struct {
+	baseBuiltinFunc
+	cache regexpCache
+}
+
+func (b *builtinRegexpReplaceSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinRegexpReplace(args, &b.cache, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-replace
+func builtinRegexpReplace(args []types.Datum, cache *regexpCache, ctx context.Context) (d types.Datum, err error) {
+	// REGEXP_REPLACE(expr, pat, repl[, pos[, occurrence[, match_type]]])
+	// occurrence 0 replaces all matches.
+	r, isNull, err := evalRegexpArgs(append(args[:2:2], args[3:]...), 2, 0, cache, ctx, ast.RegexpReplace)
+	if err != nil || isNull || args[2].IsNull() {
+		return d, errors.Trace(err)
+	}
+	repl, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+
+	str := r.str[r.offset:]
+	var buf []byte
+	last := 0
+	for i, match := range r.re.FindAllStringSubmatchIndex(str, -1) {
+		if r.occurrence > 0 && int64(i+1) != r.occurrence {
+			continue
+		}
+		buf = append(buf, str[last:match[0]]...)
+		buf = r.re.ExpandString(buf, repl, str, match)
+		last = match[1]
+	}
+	buf = append(buf, str[last:]...)
+	d.SetString(r.str[:r.offset] + string(buf))
+	return d, nil
+}
+
+type regexpSubstrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpSubstrFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinRegexpSubstrSig{baseBuiltinFunc: newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinRegexpSubstrSig struct {
+	baseBuiltinFunc
+	cache regexpCache
+}
+
+func (b *builtinRegexpSubstrSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinRegexpSubstr(args, &b.cache, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-substr
+func builtinRegexpSubstr(args []types.Datum, cache *regexpCache, ctx context.Context) (d types.Datum, err error) {
+	// REGEXP_SUBSTR(expr, pat[, pos[, occurrence[, match_type]]])
+	// It returns NULL if there is no such match.
+	r, isNull, err := evalRegexpArgs(args, 2, 1, cache, ctx, ast.RegexpSubstr)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	if r.occurrence < 1 {
+		r.occurrence = 1
+	}
+
+	matches := r.re.FindAllStringIndex(r.str[r.offset:], int(r.occurrence))
+	if int64(len(matches)) < r.occurrence {
+		return d, nil
+	}
+	match := matches[r.occurrence-1]
+	d.SetString(r.str[r.offset+match[0] : r.offset+match[1]])
+	return d, nil
+}
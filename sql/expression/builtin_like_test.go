@@ -0,0 +1,78 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestRegexpFunctions(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	for _, c := range []struct {
+		funcName string
+		args     []interface{}
+		expected interface{} // nil for NULL
+	}{
+		{ast.RegexpReplace, []interface{}{"a b c", "b", "X"}, "a X c"},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 1, 3}, "abc def X"},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 5}, "abc X X"},
+		{ast.RegexpReplace, []interface{}{"abc def", "([a-z])([a-z]+)", "$2$1"}, "bca efd"},
+		{ast.RegexpReplace, []interface{}{"héllo héllo", "l+", "L", 4, 0}, "hélLo héLo"},
+		{ast.RegexpReplace, []interface{}{"ABC", "b", "x", 1, 0, "i"}, "AxC"},
+		{ast.RegexpReplace, []interface{}{"ABC", "b", "x", 1, 0, "ic"}, "ABC"},
+		{ast.RegexpReplace, []interface{}{"abc", "d", "x"}, "abc"},
+		{ast.RegexpReplace, []interface{}{"abc", "b", nil}, nil},
+		{ast.RegexpReplace, []interface{}{nil, "b", "x"}, nil},
+
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+"}, "abc"},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 1, 3}, "ghi"},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 6}, "ef"},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 1, 4}, nil},
+		{ast.RegexpSubstr, []interface{}{"a\nb", "a.b", 1, 1, "n"}, "a\nb"},
+		{ast.RegexpSubstr, []interface{}{"a\nb", "a.b"}, nil},
+		{ast.RegexpSubstr, []interface{}{"x\nab", "^a.", 1, 1, "m"}, "ab"},
+		{ast.RegexpSubstr, []interface{}{"abc", "", 4}, ""},
+		{ast.RegexpSubstr, []interface{}{"abc", nil}, nil},
+	} {
+		var args []Expression
+		for _, arg := range c.args {
+			args = append(args, &Constant{Value: types.NewDatum(arg), RetType: types.NewFieldType(mysql.TypeUnspecified)})
+		}
+		f, err := NewFunction(ctx, c.funcName, types.NewFieldType(mysql.TypeVarString), args...)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		d, err := f.Eval(nil, ctx)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		if d.IsNull() != (c.expected == nil) || (c.expected != nil && fmt.Sprint(d.GetValue()) != c.expected) {
+			t.Errorf("%s%v: got %v, expected %v", c.funcName, c.args, d.GetValue(), c.expected)
+		}
+	}
+
+	for _, c := range [][]interface{}{
+		{"abc", "b", 0},
+		{"abc", "b", 5},
+		{"abc", "b", 1, 1, "x"},
+		{"abc", "("},
+	} {
+		var args []Expression
+		for _, arg := range c {
+			args = append(args, &Constant{Value: types.NewDatum(arg), RetType: types.NewFieldType(mysql.TypeUnspecified)})
+		}
+		f, err := NewFunction(ctx, ast.RegexpSubstr, types.NewFieldType(mysql.TypeVarString), args...)
+		if err == nil {
+			_, err = f.Eval(nil, ctx)
+		}
+		if err == nil {
+			t.Errorf("%s%v: expected an error", ast.RegexpSubstr, c)
+		}
+	}
+}
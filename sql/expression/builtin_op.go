@@ -52,12 +52,26 @@ type builtinAndAndSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinAndAndSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
+// eval only evaluates the right argument if the left one is not false.
+func (b *builtinAndAndSig) eval(row []types.Datum) (d types.Datum, err error) {
+	left, err := b.evalArg(row, 0)
 	if err != nil {
-		return types.Datum{}, errors.Trace(err)
+		return d, errors.Trace(err)
+	}
+	if !left.IsNull() {
+		x, err := left.ToBool(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		} else if x == 0 {
+			d.SetInt64(x)
+			return d, nil
+		}
+	}
+	right, err := b.evalArg(row, 1)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	return builtinAndAnd(args, b.ctx)
+	return builtinAndAnd([]types.Datum{left, right}, b.ctx)
 }
 
 func builtinAndAnd(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
@@ -104,12 +118,26 @@ type builtinOrOrSig struct {
 	baseBuiltinFunc
 }
 
-func (b *builtinOrOrSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
+// eval only evaluates the right argument if the left one is not true.
+func (b *builtinOrOrSig) eval(row []types.Datum) (d types.Datum, err error) {
+	left, err := b.evalArg(row, 0)
 	if err != nil {
-		return types.Datum{}, errors.Trace(err)
+		return d, errors.Trace(err)
+	}
+	if !left.IsNull() {
+		x, err := left.ToBool(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		} else if x == 1 {
+			d.SetInt64(x)
+			return d, nil
+		}
+	}
+	right, err := b.evalArg(row, 1)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	return builtinOrOr(args, b.ctx)
+	return builtinOrOr([]types.Datum{left, right}, b.ctx)
 }
 
 func builtinOrOr(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
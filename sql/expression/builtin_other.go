@@ -79,6 +79,7 @@ func builtinSleep(args []types.Datum, ctx context.Context) (d types.Datum, err e
 		if sessVars.StrictSQLMode {
 			return d, errors.New("incorrect arguments to sleep")
 		}
+		sessVars.StmtCtx.AppendWarning(errors.New("incorrect arguments to sleep"))
 		d.SetInt64(0)
 		return
 	}
@@ -93,6 +94,7 @@ func builtinSleep(args []types.Datum, ctx context.Context) (d types.Datum, err e
 		if sessVars.StrictSQLMode {
 			return d, errors.New("incorrect arguments to sleep")
 		}
+		sc.AppendWarning(errors.New("incorrect arguments to sleep"))
 		d.SetInt64(0)
 		return
 	}
@@ -218,9 +220,9 @@ func (b *builtinCastSig) eval(row []types.Datum) (types.Datum, error) {
 // See https://dev.mysql.com/doc/refman/5.7/en/cast-functions.html
 func CastFuncFactory(tp *types.FieldType) (BuiltinFunc, error) {
 	switch tp.Tp {
-	// Parser has restricted this.
+	// Parser has restricted this, except for the casts of comparison arguments to double.
 	case mysql.TypeString, mysql.TypeDuration, mysql.TypeDatetime,
-		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal:
+		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal, mysql.TypeDouble:
 		return func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 			d = args[0]
 			if d.IsNull() {
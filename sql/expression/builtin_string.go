@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"unicode/utf8"
 
 	"log"
 
@@ -65,6 +66,12 @@ var (
 	_ functionClass = &charLengthFunctionClass{}
 	_ functionClass = &findInSetFunctionClass{}
 	_ functionClass = &fieldFunctionClass{}
+	_ functionClass = &lpadFunctionClass{}
+	_ functionClass = &instrFunctionClass{}
+	_ functionClass = &formatFunctionClass{}
+	_ functionClass = &eltFunctionClass{}
+	_ functionClass = &exportSetFunctionClass{}
+	_ functionClass = &quoteFunctionClass{}
 )
 
 var (
@@ -94,6 +101,12 @@ var (
 	_ builtinFunc = &builtinCharSig{}
 	_ builtinFunc = &builtinCharLengthSig{}
 	_ builtinFunc = &builtinFindInSetSig{}
+	_ builtinFunc = &builtinLpadSig{}
+	_ builtinFunc = &builtinInstrSig{}
+	_ builtinFunc = &builtinFormatSig{}
+	_ builtinFunc = &builtinEltSig{}
+	_ builtinFunc = &builtinExportSetSig{}
+	_ builtinFunc = &builtinQuoteSig{}
 )
 
 type lengthFunctionClass struct {
@@ -1455,3 +1468,345 @@ func argsToSpecifiedType(args []types.Datum, allString bool, allNumber bool, ctx
 	}
 	return
 }
+
+type lpadFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *lpadFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinLpadSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinLpadSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinLpadSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinLpad(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_lpad
+func builtinLpad(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	// LPAD(str,len,padstr)
+	// args[0] string, args[1] int, args[2] string
+	if args[0].IsNull() || args[1].IsNull() || args[2].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	length, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	padStr, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+
+	// the lengths are in characters
+	runes, padRunes := []rune(str), []rune(padStr)
+	if length < 0 || (int64(len(runes)) < length && len(padRunes) == 0) {
+		return d, nil
+	}
+	l := int(length)
+	if len(runes) >= l {
+		d.SetString(string(runes[:l]))
+		return d, nil
+	}
+
+	headLen := l - len(runes)
+	head := []rune(strings.Repeat(padStr, headLen/len(padRunes)+1))[:headLen]
+	d.SetString(string(head) + str)
+	return d, nil
+}
+
+type instrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *instrFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinInstrSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinInstrSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinInstrSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinInstr(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_instr
+// The comparison is case insensitive, like the default collation, unless either string is binary.
+func builtinInstr(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+	// INSTR(str,substr)
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	subStr, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].Kind() != types.KindBytes && args[1].Kind() != types.KindBytes {
+		str, subStr = strings.ToLower(str), strings.ToLower(subStr)
+	}
+
+	i := strings.Index(str, subStr)
+	if i < 0 {
+		d.SetInt64(0)
+		return d, nil
+	}
+	// the position is in characters
+	d.SetInt64(int64(utf8.RuneCountInString(str[:i]) + 1))
+	return d, nil
+}
+
+type formatFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *formatFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinFormatSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinFormatSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinFormatSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinFormat(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_format
+// Only the en_US locale is supported, which is the default, so the locale argument is ignored.
+func builtinFormat(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	// FORMAT(X,D[,locale])
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	x, err := args[0].ToDecimal(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	dec, err := args[1].ToInt64(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if dec < 0 {
+		dec = 0
+	} else if dec > types.MaxFraction {
+		dec = types.MaxFraction
+	}
+
+	rounded := new(types.MyDecimal)
+	if err = x.Round(rounded, int(dec)); err != nil {
+		return d, errors.Trace(err)
+	}
+	s := rounded.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteRune(c)
+	}
+	if dec > 0 {
+		buf.WriteByte('.')
+		buf.WriteString(fracPart)
+		buf.WriteString(strings.Repeat("0", int(dec)-len(fracPart)))
+	}
+	d.SetString(buf.String())
+	return d, nil
+}
+
+type eltFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *eltFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinEltSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinEltSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinEltSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinElt(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_elt
+func builtinElt(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	// ELT(N,str1,str2,str3,...)
+	if args[0].IsNull() {
+		return d, nil
+	}
+	n, err := args[0].ToInt64(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if n < 1 || n >= int64(len(args)) || args[n].IsNull() {
+		return d, nil
+	}
+	str, err := args[n].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetString(str)
+	return d, nil
+}
+
+type exportSetFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *exportSetFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinExportSetSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinExportSetSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinExportSetSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinExportSet(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_export-set
+func builtinExportSet(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	// EXPORT_SET(bits,on,off[,separator[,number_of_bits]])
+	for _, arg := range args {
+		if arg.IsNull() {
+			return d, nil
+		}
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	bits, err := args[0].ToInt64(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	on, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	off, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	separator := ","
+	if len(args) > 3 {
+		if separator, err = args[3].ToString(); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	// number_of_bits is clipped to 64, also when it is negative, like an unsigned value
+	count := int64(64)
+	if len(args) > 4 {
+		if count, err = args[4].ToInt64(sc); err != nil {
+			return d, errors.Trace(err)
+		}
+		if count < 0 || count > 64 {
+			count = 64
+		}
+	}
+
+	parts := make([]string, count)
+	for i := range parts {
+		if uint64(bits)&(1<<uint(i)) != 0 {
+			parts[i] = on
+		} else {
+			parts[i] = off
+		}
+	}
+	d.SetString(strings.Join(parts, separator))
+	return d, nil
+}
+
+type quoteFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *quoteFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	return &builtinQuoteSig{newBaseBuiltinFunc(args, ctx)}, errors.Trace(c.verifyArgs(args))
+}
+
+type builtinQuoteSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinQuoteSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return builtinQuote(args, b.ctx)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_quote
+// NULL is returned as the word NULL without quotes.
+func builtinQuote(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+	if args[0].IsNull() {
+		d.SetString("NULL")
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('\'')
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '\\', '\'':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case 0:
+			buf.WriteString(`\0`)
+		case '\032':
+			buf.WriteString(`\Z`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('\'')
+	d.SetString(buf.String())
+	return d, nil
+}
@@ -0,0 +1,89 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+type testContext struct {
+	vars *variable.SessionVars
+}
+
+func (c *testContext) SetValue(key fmt.Stringer, value interface{}) {}
+func (c *testContext) Value(key fmt.Stringer) interface{}           { return nil }
+func (c *testContext) ClearValue(key fmt.Stringer)                  {}
+func (c *testContext) GetSessionVars() *variable.SessionVars        { return c.vars }
+
+func TestStringFunctions(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	for _, c := range []struct {
+		funcName string
+		args     []interface{}
+		expected interface{} // nil for NULL
+	}{
+		{ast.Lpad, []interface{}{"hi", 4, "??"}, "??hi"},
+		{ast.Lpad, []interface{}{"hi", 5, "ab"}, "abahi"},
+		{ast.Lpad, []interface{}{"hi", 1, "??"}, "h"},
+		{ast.Lpad, []interface{}{"héllo", 7, "ü"}, "üühéllo"},
+		{ast.Lpad, []interface{}{"hi", 3, ""}, nil},
+		{ast.Lpad, []interface{}{"hi", 2, ""}, "hi"},
+		{ast.Lpad, []interface{}{"hi", -1, "?"}, nil},
+		{ast.Lpad, []interface{}{nil, 3, "?"}, nil},
+
+		{ast.Instr, []interface{}{"foobarbar", "bar"}, int64(4)},
+		{ast.Instr, []interface{}{"xbar", "foobar"}, int64(0)},
+		{ast.Instr, []interface{}{"FooBar", "bar"}, int64(4)},
+		{ast.Instr, []interface{}{[]byte("FooBar"), "bar"}, int64(0)},
+		{ast.Instr, []interface{}{"héllo", "llo"}, int64(3)},
+		{ast.Instr, []interface{}{"abc", ""}, int64(1)},
+		{ast.Instr, []interface{}{"abc", nil}, nil},
+
+		{ast.Format, []interface{}{12332.123456, 4}, "12,332.1235"},
+		{ast.Format, []interface{}{12332.1, 4}, "12,332.1000"},
+		{ast.Format, []interface{}{12332.2, 0}, "12,332"},
+		{ast.Format, []interface{}{-1234567.891, 2}, "-1,234,567.89"},
+		{ast.Format, []interface{}{0.5, 0}, "1"},
+		{ast.Format, []interface{}{123, -2}, "123"},
+		{ast.Format, []interface{}{"1234.5", 1, "en_US"}, "1,234.5"},
+		{ast.Format, []interface{}{nil, 2}, nil},
+
+		{ast.Elt, []interface{}{1, "Aa", "Bb", "Cc"}, "Aa"},
+		{ast.Elt, []interface{}{4, "Aa", "Bb", "Cc"}, nil},
+		{ast.Elt, []interface{}{0, "Aa"}, nil},
+		{ast.Elt, []interface{}{2, "Aa", nil}, nil},
+		{ast.Elt, []interface{}{nil, "Aa"}, nil},
+
+		{ast.ExportSet, []interface{}{5, "Y", "N", ",", 4}, "Y,N,Y,N"},
+		{ast.ExportSet, []interface{}{6, "1", "0", ",", 10}, "0,1,1,0,0,0,0,0,0,0"},
+		{ast.ExportSet, []interface{}{1, "1", "0", "", 3}, "100"},
+		{ast.ExportSet, []interface{}{-1, "1", "0", "", 100}, "1111111111111111111111111111111111111111111111111111111111111111"},
+		{ast.ExportSet, []interface{}{5, "Y", "N", nil}, nil},
+
+		{ast.Quote, []interface{}{"Don't!"}, `'Don\'t!'`},
+		{ast.Quote, []interface{}{"a\\b\x00c\x1a"}, `'a\\b\0c\Z'`},
+		{ast.Quote, []interface{}{nil}, "NULL"},
+	} {
+		var args []Expression
+		for _, arg := range c.args {
+			args = append(args, &Constant{Value: types.NewDatum(arg), RetType: types.NewFieldType(mysql.TypeUnspecified)})
+		}
+		f, err := NewFunction(ctx, c.funcName, types.NewFieldType(mysql.TypeUnspecified), args...)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		d, err := f.Eval(nil, ctx)
+		if err != nil {
+			t.Errorf("%s%v: %v", c.funcName, c.args, err)
+			continue
+		}
+		if actual := d.GetValue(); fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("%s%v: got %v, expected %v", c.funcName, c.args, actual, c.expected)
+		}
+	}
+}
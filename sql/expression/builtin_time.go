@@ -590,7 +590,7 @@ func builtinNow(args []types.Datum, ctx context.Context) (d types.Datum, err err
 		}
 	}
 
-	tr, err := types.RoundFrac(time.Now(), int(fsp))
+	tr, err := types.RoundFrac(time.Now().In(getTimeZone(ctx)), int(fsp))
 	if err != nil {
 		d.SetNull()
 		return d, errors.Trace(err)
@@ -1041,7 +1041,7 @@ func builtinFromUnixTime(args []types.Datum, ctx context.Context) (d types.Datum
 	if fracDigitsNumber > types.MaxFsp {
 		fsp = types.MaxFsp
 	}
-	tr, err := types.RoundFrac(time.Unix(integralPart, fractionalPart), fsp)
+	tr, err := types.RoundFrac(time.Unix(integralPart, fractionalPart).In(getTimeZone(ctx)), fsp)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -1149,8 +1149,8 @@ func (b *builtinCurrentDateSig) eval(row []types.Datum) (types.Datum, error) {
 }
 
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_curdate
-func builtinCurrentDate(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	year, month, day := time.Now().Date()
+func builtinCurrentDate(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	year, month, day := time.Now().In(getTimeZone(ctx)).Date()
 	t := types.Time{
 		Time: types.FromDate(year, int(month), day, 0, 0, 0, 0),
 		Type: mysql.TypeDate, Fsp: 0}
@@ -1188,7 +1188,7 @@ func builtinCurrentTime(args []types.Datum, ctx context.Context) (d types.Datum,
 			return d, errors.Trace(err)
 		}
 	}
-	d.SetString(time.Now().Format("15:04:05.000000"))
+	d.SetString(time.Now().In(getTimeZone(ctx)).Format("15:04:05.000000"))
 	return convertToDuration(ctx.GetSessionVars().StmtCtx, d, fsp)
 }
 
@@ -1439,8 +1439,7 @@ func dateArithFuncFactory(op ast.DateArithType) BuiltinFunc {
 		if op == ast.DateArithSub {
 			year, month, day, duration = -year, -month, -day, -duration
 		}
-		// TODO: Consider time_zone variable.
-		t, err := result.Time.GoTime(time.Local)
+		t, err := result.Time.GoTime(timeZoneOf(result, ctx))
 		if err != nil {
 			return d, errors.Trace(err)
 		}
@@ -1572,7 +1571,7 @@ func builtinUnixTimestamp(args []types.Datum, ctx context.Context) (d types.Datu
 		return d, errors.Errorf("Unkonwn args type for unix_timestamp %d", args[0].Kind())
 	}
 
-	t1, err = t.Time.GoTime(getTimeZone(ctx))
+	t1, err = t.Time.GoTime(timeZoneOf(t, ctx))
 	if err != nil {
 		d.SetInt64(0)
 		return d, nil
@@ -1595,3 +1594,12 @@ func getTimeZone(ctx context.Context) *time.Location {
 	}
 	return ret
 }
+
+// timeZoneOf returns the time zone of a time value:
+// UTC for TIMESTAMP values, and the session time zone for the others.
+func timeZoneOf(t types.Time, ctx context.Context) *time.Location {
+	if t.Type == mysql.TypeTimestamp {
+		return time.UTC
+	}
+	return getTimeZone(ctx)
+}
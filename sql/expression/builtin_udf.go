@@ -0,0 +1,112 @@
+package expression
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// UDF is a function declared by CREATE FUNCTION name AS PIPE 'code'. The
+// code runs as an external program on the executors of the flows, which
+// answers each tab-separated line of the arguments with a line, in order.
+// The calls are run by the projections only, see executor.ProjectionExec.
+type UDF struct {
+	Name    string
+	Code    string
+	RetType *types.FieldType
+}
+
+// udfs are the registered UDFs, by their names in lower case.
+var udfs = struct {
+	sync.RWMutex
+	byName  map[string]*UDF
+	version int64
+}{byName: make(map[string]*UDF)}
+
+// RegisterUDF registers the UDF, replacing the UDF of the same name.
+// The builtin functions can not be replaced.
+func RegisterUDF(udf *UDF) error {
+	if _, isBuiltin := funcs[udf.Name]; isBuiltin {
+		return fmt.Errorf("function %s is a builtin function", udf.Name)
+	}
+	udfs.Lock()
+	defer udfs.Unlock()
+	udfs.byName[udf.Name] = udf
+	udfs.version++
+	return nil
+}
+
+// UnregisterUDF removes the UDF, and tells whether it was registered.
+func UnregisterUDF(name string) bool {
+	udfs.Lock()
+	defer udfs.Unlock()
+	if _, found := udfs.byName[name]; !found {
+		return false
+	}
+	delete(udfs.byName, name)
+	udfs.version++
+	return true
+}
+
+// GetUDF returns the registered UDF, or nil.
+func GetUDF(name string) *UDF {
+	udfs.RLock()
+	defer udfs.RUnlock()
+	return udfs.byName[name]
+}
+
+// UDFVersion changes whenever a UDF is registered or unregistered, e.g. to
+// invalidate the cached plans calling them.
+func UDFVersion() int64 {
+	udfs.RLock()
+	defer udfs.RUnlock()
+	return udfs.version
+}
+
+// UDFOf returns the UDF called by the expression, or nil.
+func UDFOf(expr Expression) *UDF {
+	if sf, ok := expr.(*ScalarFunction); ok {
+		if sig, ok := sf.Function.(*builtinUDFSig); ok {
+			return sig.udf
+		}
+	}
+	return nil
+}
+
+// ContainsUDF tells whether the expression calls any UDF.
+func ContainsUDF(expr Expression) bool {
+	if UDFOf(expr) != nil {
+		return true
+	}
+	if sf, ok := expr.(*ScalarFunction); ok {
+		for _, arg := range sf.GetArgs() {
+			if ContainsUDF(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newUDFFunction(ctx context.Context, udf *UDF, retType *types.FieldType, args []Expression) *ScalarFunction {
+	sig := &builtinUDFSig{newBaseBuiltinFunc(args, ctx), udf}
+	sig.deterministic = false
+	return &ScalarFunction{
+		FuncName: model.NewCIStr(udf.Name),
+		RetType:  retType,
+		Function: sig,
+	}
+}
+
+type builtinUDFSig struct {
+	baseBuiltinFunc
+	udf *UDF
+}
+
+// eval fails, as the UDFs are run by the external programs of the flows.
+func (b *builtinUDFSig) eval(row []types.Datum) (d types.Datum, err error) {
+	return d, fmt.Errorf("function %s is run by a pipe, only in the select fields", b.udf.Name)
+}
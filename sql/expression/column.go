@@ -160,8 +160,20 @@ func (col *Column) ResolveIndices(schema Schema) {
 // Column2Exprs will transfer column slice to expression slice.
 func Column2Exprs(cols []*Column) []Expression {
 	result := make([]Expression, 0, len(cols))
-	for _, col := range cols {
-		result = append(result, col.Clone())
+	for _, col := range cloneColumns(cols) {
+		result = append(result, col)
+	}
+	return result
+}
+
+// cloneColumns copies the columns into one pooled allocation instead of one
+// per column. The copies are still distinct, since ResolveIndices sets their Index.
+func cloneColumns(cols []*Column) []*Column {
+	pool := make([]Column, len(cols))
+	result := make([]*Column, len(cols))
+	for i, col := range cols {
+		pool[i] = *col
+		result[i] = &pool[i]
 	}
 	return result
 }
@@ -14,34 +14,72 @@
 package expression
 
 import (
-	"log"
+	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/context"
 )
 
+// nullPropagatingFuncs are the functions returning NULL when any argument is
+// NULL, so they are folded even if the other arguments are not constant.
+var nullPropagatingFuncs = map[string]bool{
+	ast.Plus:       true,
+	ast.Minus:      true,
+	ast.Mul:        true,
+	ast.Div:        true,
+	ast.IntDiv:     true,
+	ast.Mod:        true,
+	ast.EQ:         true,
+	ast.NE:         true,
+	ast.LT:         true,
+	ast.LE:         true,
+	ast.GT:         true,
+	ast.GE:         true,
+	ast.And:        true,
+	ast.Or:         true,
+	ast.Xor:        true,
+	ast.LeftShift:  true,
+	ast.RightShift: true,
+	ast.LogicXor:   true,
+	ast.Like:       true,
+	ast.Regexp:     true,
+	ast.Concat:     true,
+	ast.Replace:    true,
+	ast.Strcmp:     true,
+}
+
 // FoldConstant does constant folding optimization on an expression.
+// The deterministic functions of constants are evaluated, the functions
+// propagating NULL are folded to NULL with a NULL argument, and IF() and CASE
+// are folded to the branch chosen by constant conditions. The errors of the
+// evaluation are appended to the statement warnings, keeping the function.
 func FoldConstant(ctx context.Context, expr Expression) Expression {
 	scalarFunc, ok := expr.(*ScalarFunction)
 	if !ok {
 		return expr
 	}
-	if _, isDynamic := DynamicFuncs[scalarFunc.FuncName.L]; isDynamic {
+	if _, isDynamic := DynamicFuncs[scalarFunc.FuncName.L]; isDynamic || !scalarFunc.Function.isDeterministic() {
 		return expr
 	}
 	args := scalarFunc.GetArgs()
-	canFold := true
+	canFold, hasNull := true, false
 	for i := 0; i < len(args); i++ {
 		foldedArg := FoldConstant(ctx, args[i])
-		scalarFunc.GetArgs()[i] = foldedArg
-		if _, ok := foldedArg.(*Constant); !ok {
+		args[i] = foldedArg
+		if c, ok := foldedArg.(*Constant); !ok {
 			canFold = false
+		} else if c.Value.IsNull() {
+			hasNull = true
 		}
 	}
 	if !canFold {
-		return expr
+		if hasNull && nullPropagatingFuncs[scalarFunc.FuncName.L] {
+			return &Constant{RetType: scalarFunc.RetType}
+		}
+		return foldBranch(ctx, scalarFunc)
 	}
 	value, err := scalarFunc.Eval(nil, ctx)
 	if err != nil {
-		log.Printf("There may exist an error during constant folding. The function name is %s, args are %s", scalarFunc.FuncName, args)
+		// the function is evaluated again, if at all, when the statement runs
+		ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 		return expr
 	}
 	return &Constant{
@@ -49,3 +87,66 @@ func FoldConstant(ctx context.Context, expr Expression) Expression {
 		RetType: scalarFunc.RetType,
 	}
 }
+
+// foldBranch folds IF() and CASE to the branch chosen by their conditions,
+// if the conditions before it are constant. A branch not of the type of the
+// function is only folded if it is constant.
+func foldBranch(ctx context.Context, scalarFunc *ScalarFunction) Expression {
+	args := scalarFunc.GetArgs()
+	var branch Expression
+	switch scalarFunc.FuncName.L {
+	case ast.If:
+		isTrue, known := constantCondition(ctx, args[0])
+		if !known {
+			return scalarFunc
+		}
+		if isTrue {
+			branch = args[1]
+		} else {
+			branch = args[2]
+		}
+	case ast.Case:
+		l := len(args)
+		for i := 0; i < l-1 && branch == nil; i += 2 {
+			isTrue, known := constantCondition(ctx, args[i])
+			if !known {
+				return scalarFunc
+			}
+			if isTrue {
+				branch = args[i+1]
+			}
+		}
+		if branch == nil && l%2 == 1 {
+			branch = args[l-1]
+		} else if branch == nil {
+			return &Constant{RetType: scalarFunc.RetType}
+		}
+	default:
+		return scalarFunc
+	}
+	if c, ok := branch.(*Constant); ok {
+		return &Constant{Value: c.Value, RetType: scalarFunc.RetType}
+	}
+	if branch.GetType().Tp != scalarFunc.RetType.Tp {
+		return scalarFunc
+	}
+	return branch
+}
+
+// constantCondition tells whether a condition is true, if it is constant.
+// NULL is false.
+func constantCondition(ctx context.Context, cond Expression) (isTrue bool, known bool) {
+	c, ok := cond.(*Constant)
+	if !ok {
+		return false, false
+	}
+	if c.Value.IsNull() {
+		return false, true
+	}
+	v, err := c.Value.ToBool(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+		return false, false
+	}
+	return v == 1, true
+}
@@ -0,0 +1,60 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestFoldConstant(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	intType := types.NewFieldType(mysql.TypeLonglong)
+	constant := func(value interface{}) Expression {
+		return &Constant{Value: types.NewDatum(value), RetType: intType}
+	}
+	column := &Column{FromID: "t", ColName: model.NewCIStr("c"), RetType: intType}
+	function := func(name string, args ...Expression) Expression {
+		f, err := NewFunction(ctx, name, intType, args...)
+		if err != nil {
+			t.Fatalf("%s%v: %v", name, args, err)
+		}
+		return f
+	}
+
+	for _, c := range []struct {
+		expr     Expression
+		expected string
+	}{
+		{function(ast.Plus, constant(1), constant(2)), "3"},
+		{function(ast.Plus, column, function(ast.Mul, constant(2), constant(3))), "plus(c, 6)"},
+		{function(ast.Plus, column, constant(nil)), "<nil>"},
+		{function(ast.EQ, function(ast.Plus, column, constant(1)), constant(nil)), "<nil>"},
+		{function(ast.NullEQ, column, constant(nil)), "nulleq(c, <nil>)"},
+		{function(ast.If, constant(1), column, constant(0)), "c"},
+		{function(ast.If, constant(nil), column, constant(0)), "0"},
+		{function(ast.If, column, constant(1), constant(0)), "if(c, 1, 0)"},
+		{function(ast.Case, constant(0), constant(1), constant(1), column), "c"},
+		{function(ast.Case, constant(0), column, constant(2)), "2"},
+		{function(ast.Case, constant(0), column), "<nil>"},
+		{function(ast.Case, column, constant(1), constant(1), constant(2)), "case(c, 1, 1, 2)"},
+		{function("rand"), "rand()"},
+	} {
+		text := c.expr.String()
+		if actual := FoldConstant(ctx, c.expr).String(); actual != c.expected {
+			t.Errorf("%s: folded to %s, expected %s", text, actual, c.expected)
+		}
+	}
+
+	failing := function(ast.Regexp, constant("a"), &Constant{Value: types.NewDatum("("), RetType: intType})
+	if _, ok := FoldConstant(ctx, failing).(*ScalarFunction); !ok {
+		t.Errorf("%s: expected the failing function to be kept", failing)
+	}
+	if warnings := ctx.GetSessionVars().StmtCtx.GetWarnings(); len(warnings) != 1 {
+		t.Errorf("expected a warning folding %s, got %v", failing, fmt.Sprint(warnings))
+	}
+}
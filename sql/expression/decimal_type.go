@@ -0,0 +1,113 @@
+package expression
+
+import (
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/parser/opcode"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+const (
+	// MaxDecimalPrecision is the maximum number of digits of a DECIMAL.
+	MaxDecimalPrecision = 65
+	// MaxDecimalScale is the maximum number of digits after the decimal point of a DECIMAL.
+	MaxDecimalScale = 30
+
+	// sumPrecisionIncr is the number of digits SUM adds to the precision of its argument.
+	sumPrecisionIncr = 22
+)
+
+// decimalPrecisionAndScale returns the precision and scale of a numeric operand.
+// ok is false if they are unknown, e.g. for a DECIMAL without length.
+func decimalPrecisionAndScale(ft *types.FieldType) (precision, scale int, ok bool) {
+	switch ft.Tp {
+	case mysql.TypeDecimal, mysql.TypeNewDecimal:
+		if ft.Flen == types.UnspecifiedLength || ft.Decimal == types.UnspecifiedLength {
+			return 0, 0, false
+		}
+		return ft.Flen, ft.Decimal, true
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear, mysql.TypeBit:
+		precision = ft.Flen
+		if precision == types.UnspecifiedLength {
+			precision = mysql.GetDefaultFieldLength(ft.Tp)
+		}
+		if precision <= 0 {
+			precision = mysql.GetDefaultFieldLength(mysql.TypeLonglong)
+		}
+		return precision, 0, true
+	}
+	return 0, 0, false
+}
+
+// limitDecimalType caps precision and scale to the DECIMAL limits.
+func limitDecimalType(precision, scale int) (int, int) {
+	if scale > MaxDecimalScale {
+		scale = MaxDecimalScale
+	}
+	if precision > MaxDecimalPrecision {
+		precision = MaxDecimalPrecision
+	}
+	if precision < scale {
+		precision = scale
+	}
+	return precision, scale
+}
+
+// ArithmeticDecimalType returns the precision (Flen) and scale (Decimal) of the DECIMAL
+// result of "l op r" for +, -, *, / and %, following MySQL:
+//
+//	+, -: scale = max(s1, s2), precision = max(p1-s1, p2-s2) + scale + 1
+//	*:    scale = s1 + s2, precision = p1 + p2
+//	/:    scale = s1 + 4, precision = p1 + s2 + 4
+//	%:    scale = max(s1, s2), precision = max(p1-s1, p2-s2) + scale
+//
+// Both are types.UnspecifiedLength if an operand has no known precision.
+func ArithmeticDecimalType(op opcode.Op, l, r *types.FieldType) (flen, decimal int) {
+	p1, s1, ok1 := decimalPrecisionAndScale(l)
+	p2, s2, ok2 := decimalPrecisionAndScale(r)
+	if !ok1 || !ok2 {
+		return types.UnspecifiedLength, types.UnspecifiedLength
+	}
+	var precision, scale int
+	switch op {
+	case opcode.Plus, opcode.Minus:
+		scale = max(s1, s2)
+		precision = max(p1-s1, p2-s2) + scale + 1
+	case opcode.Mul:
+		scale = s1 + s2
+		precision = p1 + p2
+	case opcode.Div:
+		scale = s1 + types.DivFracIncr
+		precision = p1 + s2 + types.DivFracIncr
+	case opcode.Mod:
+		scale = max(s1, s2)
+		precision = max(p1-s1, p2-s2) + scale
+	default:
+		return types.UnspecifiedLength, types.UnspecifiedLength
+	}
+	return limitDecimalType(precision, scale)
+}
+
+// SumDecimalType returns the precision and scale of SUM(arg).
+func SumDecimalType(arg *types.FieldType) (flen, decimal int) {
+	precision, scale, ok := decimalPrecisionAndScale(arg)
+	if !ok {
+		return types.UnspecifiedLength, arg.Decimal
+	}
+	return limitDecimalType(precision+sumPrecisionIncr, scale)
+}
+
+// AvgDecimalType returns the precision and scale of AVG(arg).
+func AvgDecimalType(arg *types.FieldType) (flen, decimal int) {
+	precision, scale, ok := decimalPrecisionAndScale(arg)
+	if !ok {
+		return types.UnspecifiedLength, arg.Decimal
+	}
+	return limitDecimalType(precision+types.DivFracIncr, scale+types.DivFracIncr)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/parser/opcode"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func newDecimalType(flen, decimal int) *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeNewDecimal)
+	ft.Flen, ft.Decimal = flen, decimal
+	return ft
+}
+
+func TestArithmeticDecimalType(t *testing.T) {
+	a := newDecimalType(10, 2)
+	b := newDecimalType(6, 4)
+	i := types.NewFieldType(mysql.TypeLong)
+	for _, c := range []struct {
+		op      opcode.Op
+		l, r    *types.FieldType
+		flen    int
+		decimal int
+	}{
+		{opcode.Plus, a, b, 13, 4},
+		{opcode.Minus, a, i, 14, 2},
+		{opcode.Mul, a, b, 16, 6},
+		{opcode.Div, a, b, 18, 6},
+		{opcode.Div, i, i, 15, 4},
+		{opcode.Mod, a, b, 12, 4},
+		{opcode.Mul, newDecimalType(40, 20), newDecimalType(40, 20), 65, 30},
+		{opcode.Plus, a, types.NewFieldType(mysql.TypeNewDecimal), types.UnspecifiedLength, types.UnspecifiedLength},
+	} {
+		flen, decimal := ArithmeticDecimalType(c.op, c.l, c.r)
+		if flen != c.flen || decimal != c.decimal {
+			t.Errorf("%v: got decimal(%d,%d), expected decimal(%d,%d)", c.op, flen, decimal, c.flen, c.decimal)
+		}
+	}
+
+	if flen, decimal := SumDecimalType(a); flen != 32 || decimal != 2 {
+		t.Errorf("sum: got decimal(%d,%d), expected decimal(32,2)", flen, decimal)
+	}
+	if flen, decimal := AvgDecimalType(a); flen != 14 || decimal != 6 {
+		t.Errorf("avg: got decimal(%d,%d), expected decimal(14,6)", flen, decimal)
+	}
+}
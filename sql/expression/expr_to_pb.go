@@ -0,0 +1,196 @@
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/util/codec"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// The kinds of expressions in pb.SqlExpr.
+const (
+	ExprKindConstant int32 = iota
+	ExprKindColumn
+	ExprKindCorrelatedColumn
+	ExprKindScalarFunction
+)
+
+// ExprToPB converts the expression to protobuf, to be evaluated by the executors
+// of a distributed flow.
+func ExprToPB(expr Expression) (*pb.SqlExpr, error) {
+	switch x := expr.(type) {
+	case *Constant:
+		value, err := codec.EncodeValue(nil, x.Value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &pb.SqlExpr{Kind: ExprKindConstant, FieldType: FieldTypeToPB(x.RetType), Value: value}, nil
+	case *Column:
+		return &pb.SqlExpr{Kind: ExprKindColumn, Column: ColumnToPB(x)}, nil
+	case *CorrelatedColumn:
+		// the outer value is set when the inner plan runs
+		return &pb.SqlExpr{Kind: ExprKindCorrelatedColumn, Column: ColumnToPB(&x.Column)}, nil
+	case *ScalarFunction:
+		if x.FuncName.L == ast.Values {
+			return nil, errors.Errorf("function %s can not be serialized", x.FuncName.L)
+		}
+		args, err := ExprsToPB(x.GetArgs())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &pb.SqlExpr{Kind: ExprKindScalarFunction, FieldType: FieldTypeToPB(x.RetType), FuncName: x.FuncName.L, Args: args}, nil
+	}
+	return nil, errors.Errorf("expression %s can not be serialized", expr)
+}
+
+// ExprsToPB converts the expressions to protobuf.
+func ExprsToPB(exprs []Expression) ([]*pb.SqlExpr, error) {
+	ret := make([]*pb.SqlExpr, 0, len(exprs))
+	for _, expr := range exprs {
+		e, err := ExprToPB(expr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// PBToExpr converts the protobuf of ExprToPB back to the expression.
+func PBToExpr(e *pb.SqlExpr, ctx context.Context) (Expression, error) {
+	switch e.Kind {
+	case ExprKindConstant:
+		_, d, err := codec.DecodeOne(e.Value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ft := PBToFieldType(e.FieldType)
+		if ft != nil && isTimeType(ft.Tp) && d.Kind() == types.KindUint64 {
+			// times are encoded as packed integers
+			t := types.Time{Type: ft.Tp, Fsp: ft.Decimal}
+			if err = t.FromPackedUint(d.GetUint64()); err != nil {
+				return nil, errors.Trace(err)
+			}
+			d.SetMysqlTime(t)
+		}
+		return &Constant{Value: d, RetType: ft}, nil
+	case ExprKindColumn:
+		return PBToColumn(e.Column), nil
+	case ExprKindCorrelatedColumn:
+		return &CorrelatedColumn{Column: *PBToColumn(e.Column), Data: new(types.Datum)}, nil
+	case ExprKindScalarFunction:
+		args, err := PBToExprs(e.Args, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ft := PBToFieldType(e.FieldType)
+		if e.FuncName == ast.Cast {
+			if len(args) != 1 {
+				return nil, errors.Errorf("cast with %d arguments", len(args))
+			}
+			return NewCastFunc(ft, args[0], ctx), nil
+		}
+		f, err := NewFunction(ctx, e.FuncName, ft, args...)
+		return f, errors.Trace(err)
+	}
+	return nil, errors.Errorf("unknown expression kind %d", e.Kind)
+}
+
+func isTimeType(tp byte) bool {
+	return tp == mysql.TypeDate || tp == mysql.TypeDatetime || tp == mysql.TypeTimestamp
+}
+
+// PBToExprs converts the protobuf of ExprsToPB back to the expressions.
+func PBToExprs(es []*pb.SqlExpr, ctx context.Context) ([]Expression, error) {
+	ret := make([]Expression, 0, len(es))
+	for _, e := range es {
+		expr, err := PBToExpr(e, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ret = append(ret, expr)
+	}
+	return ret, nil
+}
+
+// ColumnToPB converts the column to protobuf, with its resolved index.
+func ColumnToPB(col *Column) *pb.SqlColumn {
+	return &pb.SqlColumn{
+		FromID:      col.FromID,
+		Position:    int32(col.Position),
+		DbName:      col.DBName.O,
+		TblName:     col.TblName.O,
+		ColName:     col.ColName.O,
+		Index:       int32(col.Index),
+		FieldType:   FieldTypeToPB(col.RetType),
+		IsAggOrSubq: col.IsAggOrSubq,
+	}
+}
+
+// PBToColumn converts the protobuf of ColumnToPB back to the column.
+func PBToColumn(c *pb.SqlColumn) *Column {
+	return &Column{
+		FromID:      c.FromID,
+		Position:    int(c.Position),
+		DBName:      model.NewCIStr(c.DbName),
+		TblName:     model.NewCIStr(c.TblName),
+		ColName:     model.NewCIStr(c.ColName),
+		Index:       int(c.Index),
+		RetType:     PBToFieldType(c.FieldType),
+		IsAggOrSubq: c.IsAggOrSubq,
+	}
+}
+
+// SchemaToPB converts the columns of the schema to protobuf.
+func SchemaToPB(schema Schema) []*pb.SqlColumn {
+	ret := make([]*pb.SqlColumn, 0, schema.Len())
+	for _, col := range schema.Columns {
+		ret = append(ret, ColumnToPB(col))
+	}
+	return ret
+}
+
+// PBToSchema converts the protobuf of SchemaToPB back to the schema.
+func PBToSchema(cols []*pb.SqlColumn) Schema {
+	columns := make([]*Column, 0, len(cols))
+	for _, c := range cols {
+		columns = append(columns, PBToColumn(c))
+	}
+	return NewSchema(columns)
+}
+
+// FieldTypeToPB converts the field type to protobuf. A nil type stays nil.
+func FieldTypeToPB(ft *types.FieldType) *pb.SqlFieldType {
+	if ft == nil {
+		return nil
+	}
+	return &pb.SqlFieldType{
+		Tp:      int32(ft.Tp),
+		Flag:    uint32(ft.Flag),
+		Flen:    int32(ft.Flen),
+		Decimal: int32(ft.Decimal),
+		Charset: ft.Charset,
+		Collate: ft.Collate,
+		Elems:   ft.Elems,
+	}
+}
+
+// PBToFieldType converts the protobuf of FieldTypeToPB back to the field type.
+func PBToFieldType(ft *pb.SqlFieldType) *types.FieldType {
+	if ft == nil {
+		return nil
+	}
+	return &types.FieldType{
+		Tp:      byte(ft.Tp),
+		Flag:    uint(ft.Flag),
+		Flen:    int(ft.Flen),
+		Decimal: int(ft.Decimal),
+		Charset: ft.Charset,
+		Collate: ft.Collate,
+		Elems:   ft.Elems,
+	}
+}
@@ -0,0 +1,67 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestExprToPB(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	col := &Column{
+		FromID:  "_1",
+		ColName: model.NewCIStr("line"),
+		RetType: types.NewFieldType(mysql.TypeLonglong),
+		Index:   1,
+	}
+	one := &Constant{Value: types.NewDatum(1), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	plus, err := NewFunction(ctx, ast.Plus, types.NewFieldType(mysql.TypeLonglong), col, one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, err := types.ParseTime("2017-01-02 03:04:05", mysql.TypeDatetime, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []Expression{
+		plus,
+		NewCastFunc(types.NewFieldType(mysql.TypeString), plus, ctx),
+		&Constant{Value: types.NewDatum(tm), RetType: types.NewFieldType(mysql.TypeDatetime)},
+		&Constant{Value: types.NewDatum(nil), RetType: types.NewFieldType(mysql.TypeNull)},
+	} {
+		e, err := ExprToPB(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		data, err := proto.Marshal(e)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		e = &pb.SqlExpr{}
+		if err = proto.Unmarshal(data, e); err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		decoded, err := PBToExpr(e, ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		row := []types.Datum{types.NewDatum("a"), types.NewDatum(41)}
+		expected, err := expr.Eval(row, ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		actual, err := decoded.Eval(row, ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", decoded, err)
+		}
+		if cmp, err := actual.CompareDatum(ctx.GetSessionVars().StmtCtx, expected); err != nil || cmp != 0 {
+			t.Errorf("%s: decoded %s evaluates to %v, expected %v", expr, decoded, actual.GetValue(), expected.GetValue())
+		}
+	}
+}
@@ -92,27 +92,42 @@ func EvalBool(expr Expression, row []types.Datum, ctx context.Context) (bool, er
 }
 
 // One stands for a number 1.
-var One = &Constant{
-	Value:   types.NewDatum(1),
-	RetType: types.NewFieldType(mysql.TypeTiny),
-}
+var One = NewSharedConstant(types.NewDatum(1), types.NewFieldType(mysql.TypeTiny))
 
 // Zero stands for a number 0.
-var Zero = &Constant{
-	Value:   types.NewDatum(0),
-	RetType: types.NewFieldType(mysql.TypeTiny),
-}
+var Zero = NewSharedConstant(types.NewDatum(0), types.NewFieldType(mysql.TypeTiny))
 
 // Null stands for null constant.
-var Null = &Constant{
-	Value:   types.NewDatum(nil),
-	RetType: types.NewFieldType(mysql.TypeTiny),
-}
+var Null = NewSharedConstant(types.NewDatum(nil), types.NewFieldType(mysql.TypeTiny))
 
 // Constant stands for a constant value.
 type Constant struct {
 	Value   types.Datum
 	RetType *types.FieldType
+
+	// shared means the constant is immutable, so it is not copied by Clone.
+	shared bool
+}
+
+// NewSharedConstant creates an immutable constant, which is shared instead of
+// copied by Clone. Use Mutable to get a copy that can be changed.
+func NewSharedConstant(value types.Datum, retType *types.FieldType) *Constant {
+	return &Constant{Value: value, RetType: retType, shared: true}
+}
+
+// IsShared returns if the constant is immutable and shared by its clones.
+func (c *Constant) IsShared() bool {
+	return c.shared
+}
+
+// Mutable returns the constant, or a copy of it if it is shared.
+func (c *Constant) Mutable() *Constant {
+	if !c.shared {
+		return c
+	}
+	con := *c
+	con.shared = false
+	return &con
 }
 
 // String implements fmt.Stringer interface.
@@ -128,6 +143,9 @@ func (c *Constant) MarshalJSON() ([]byte, error) {
 
 // Clone implements Expression interface.
 func (c *Constant) Clone() Expression {
+	if c.shared {
+		return c
+	}
 	con := *c
 	return &con
 }
@@ -0,0 +1,97 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestSharedConstant(t *testing.T) {
+	c := NewSharedConstant(types.NewIntDatum(1), types.NewFieldType(mysql.TypeLonglong))
+	if c.Clone() != c {
+		t.Errorf("the clone of a shared constant is a copy")
+	}
+	m := c.Mutable()
+	if m == c || m.IsShared() {
+		t.Errorf("the mutable constant is still shared")
+	}
+	if m.Clone() == Expression(m) {
+		t.Errorf("the clone of a mutable constant is not a copy")
+	}
+	m.Value = types.NewIntDatum(2)
+	if c.Value.GetInt64() != 1 {
+		t.Errorf("changing the mutable constant changed the shared one to %v", c.Value.GetValue())
+	}
+}
+
+func TestSchemaClone(t *testing.T) {
+	schema := newTestSchema(3)
+	clone := schema.Clone()
+	for i, col := range clone.Columns {
+		if col == schema.Columns[i] || !col.Equal(schema.Columns[i], nil) {
+			t.Errorf("column %d: %v is not a copy of %v", i, col, schema.Columns[i])
+		}
+	}
+	clone.Columns[0].Index = 2
+	if schema.Columns[0].Index != 0 || clone.Columns[1].Index != 1 {
+		t.Errorf("resolving a cloned column changed the other columns")
+	}
+}
+
+func newTestSchema(n int) Schema {
+	cols := make([]*Column, n)
+	for i := range cols {
+		cols[i] = &Column{
+			FromID:  "t",
+			ColName: model.NewCIStr(fmt.Sprintf("c%d", i)),
+			RetType: types.NewFieldType(mysql.TypeLonglong),
+			Index:   i,
+		}
+	}
+	return NewSchema(cols)
+}
+
+// cloned keeps the clones of the benchmarks from being optimized away.
+var cloned Expression
+
+func BenchmarkCloneConstant(b *testing.B) {
+	c := &Constant{Value: types.NewIntDatum(1), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cloned = c.Clone()
+	}
+}
+
+func BenchmarkCloneSharedConstant(b *testing.B) {
+	c := NewSharedConstant(types.NewIntDatum(1), types.NewFieldType(mysql.TypeLonglong))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cloned = c.Clone()
+	}
+}
+
+func BenchmarkCloneColumns(b *testing.B) {
+	schema := newTestSchema(16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result := make([]*Column, 0, schema.Len())
+		for _, col := range schema.Columns {
+			result = append(result, col.Clone().(*Column))
+		}
+	}
+}
+
+func BenchmarkCloneSchema(b *testing.B) {
+	schema := newTestSchema(16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		schema.Clone()
+	}
+}
@@ -69,6 +69,9 @@ func getTimeValue(ctx context.Context, v interface{}, tp byte, fsp int) (d types
 	case string:
 		upperX := strings.ToUpper(x)
 		if upperX == CurrentTimestamp {
+			if tp == mysql.TypeTimestamp {
+				defaultTime = defaultTime.UTC()
+			}
 			value.Time = types.FromGoTime(defaultTime)
 		} else if upperX == ZeroTimestamp {
 			value, _ = types.ParseTimeFromNum(0, tp, fsp)
@@ -152,10 +155,9 @@ func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 		if err != nil {
 			return time.Time{}, errors.Trace(err)
 		}
-		if timestamp <= 0 {
-			return value, nil
+		if timestamp > 0 {
+			value = time.Unix(timestamp, 0)
 		}
-		return time.Unix(timestamp, 0), nil
 	}
-	return value, nil
+	return value.In(getTimeZone(ctx)), nil
 }
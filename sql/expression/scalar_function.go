@@ -65,6 +65,11 @@ func (sf *ScalarFunction) MarshalJSON() ([]byte, error) {
 func NewFunction(ctx context.Context, funcName string, retType *types.FieldType, args ...Expression) (Expression, error) {
 	fc, ok := funcs[funcName]
 	if !ok {
+		if udf := GetUDF(funcName); udf != nil {
+			funcArgs := make([]Expression, len(args))
+			copy(funcArgs, args)
+			return newUDFFunction(ctx, udf, retType, funcArgs), nil
+		}
 		return nil, errFunctionNotExists.GenByArgs(funcName)
 	}
 	funcArgs := make([]Expression, len(args))
@@ -100,6 +105,8 @@ func (sf *ScalarFunction) Clone() Expression {
 		return NewCastFunc(v.tp, newArgs[0], sf.GetCtx())
 	case *builtinValuesSig:
 		return NewValuesFunc(v.offset, sf.GetType(), sf.GetCtx())
+	case *builtinUDFSig:
+		return newUDFFunction(sf.GetCtx(), v.udf, sf.RetType, newArgs)
 	}
 	newFunc, _ := NewFunction(sf.GetCtx(), sf.FuncName.L, sf.RetType, newArgs...)
 	return newFunc
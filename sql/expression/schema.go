@@ -58,12 +58,8 @@ func (s Schema) String() string {
 
 // Clone copies the total schema.
 func (s Schema) Clone() Schema {
-	result := NewSchema(make([]*Column, 0, s.Len()))
+	result := NewSchema(cloneColumns(s.Columns))
 	keys := make([]KeyInfo, 0, len(s.Keys))
-	for _, col := range s.Columns {
-		newCol := *col
-		result.Append(&newCol)
-	}
 	for _, key := range s.Keys {
 		keys = append(keys, key.Clone())
 	}
@@ -0,0 +1,166 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/util/codec"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// driverFuncs depend on the session or on the time of the statement, so
+// they are evaluated on the driver, if their arguments are constants.
+var driverFuncs = map[string]bool{
+	"connection_id":      true,
+	"current_user":       true,
+	"database":           true,
+	"found_rows":         true,
+	"last_insert_id":     true,
+	"row_count":          true,
+	"user":               true,
+	"version":            true,
+	ast.GetVar:           true,
+	ast.Curdate:          true,
+	ast.CurrentDate:      true,
+	ast.CurrentTime:      true,
+	ast.CurrentTimestamp: true,
+	ast.Curtime:          true,
+	ast.Now:              true,
+	ast.Sysdate:          true,
+	ast.UTCDate:          true,
+	ast.UnixTimestamp:    true,
+}
+
+// serializedExpr is an expression sent to the executors of the flows.
+// It is a column, a function, or else a constant.
+type serializedExpr struct {
+	Column   *int              `json:"column,omitempty"` // the index of the column in the row
+	Function string            `json:"function,omitempty"`
+	Type     *types.FieldType  `json:"type,omitempty"`
+	Args     []*serializedExpr `json:"args,omitempty"`
+	Value    []byte            `json:"value,omitempty"` // the constant encoded by codec.EncodeValue()
+	Kind     byte              `json:"kind,omitempty"`  // the kind of the constant, as strings are encoded as bytes
+}
+
+// MarshalExprs serializes the expressions, with their columns resolved to
+// the indexes of the row, to evaluate them on the executors of the flows.
+// The functions of the session, e.g. database() or now(), are evaluated
+// with the context first. The functions of the session over columns,
+// setting variables, and the correlated columns can not be serialized.
+func MarshalExprs(exprs []Expression, ctx context.Context) ([]byte, error) {
+	serialized := make([]*serializedExpr, len(exprs))
+	for i, expr := range exprs {
+		s, err := serializeExpr(expr, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		serialized[i] = s
+	}
+	return json.Marshal(serialized)
+}
+
+// UnmarshalExprs rebuilds the expressions serialized by MarshalExprs(),
+// evaluated with the context.
+func UnmarshalExprs(data []byte, ctx context.Context) ([]Expression, error) {
+	var serialized []*serializedExpr
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, errors.Trace(err)
+	}
+	exprs := make([]Expression, len(serialized))
+	for i, s := range serialized {
+		expr, err := deserializeExpr(s, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+func serializeExpr(expr Expression, ctx context.Context) (*serializedExpr, error) {
+	switch x := expr.(type) {
+	case *CorrelatedColumn:
+		return nil, fmt.Errorf("can not serialize the correlated column %s", x)
+	case *Column:
+		if x.Index < 0 {
+			return nil, fmt.Errorf("can not serialize the unresolved column %s", x)
+		}
+		index := x.Index
+		return &serializedExpr{Column: &index, Type: x.RetType}, nil
+	case *Constant:
+		return serializeConstant(x.Value, x.RetType)
+	case *ScalarFunction:
+		name := x.FuncName.L
+		if driverFuncs[name] && len(ExtractColumns(x)) == 0 {
+			value, err := x.Eval(nil, ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return serializeConstant(value, x.RetType)
+		}
+		if _, isDynamic := DynamicFuncs[name]; name == ast.SetVar || isDynamic && driverFuncs[name] {
+			return nil, fmt.Errorf("can not serialize %s, it depends on the session", x)
+		}
+		if _, isValues := x.Function.(*builtinValuesSig); isValues {
+			return nil, fmt.Errorf("can not serialize %s", x)
+		}
+		if _, isUDF := x.Function.(*builtinUDFSig); isUDF {
+			return nil, fmt.Errorf("can not serialize %s, it is run by a pipe", x)
+		}
+		s := &serializedExpr{Function: name, Type: x.RetType}
+		if cast, isCast := x.Function.(*builtinCastSig); isCast {
+			s.Type = cast.tp
+		}
+		for _, arg := range x.GetArgs() {
+			a, err := serializeExpr(arg, ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			s.Args = append(s.Args, a)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("can not serialize %s", expr)
+}
+
+func serializeConstant(value types.Datum, retType *types.FieldType) (*serializedExpr, error) {
+	encoded, err := codec.EncodeValue(nil, value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &serializedExpr{Type: retType, Value: encoded, Kind: value.Kind()}, nil
+}
+
+func deserializeExpr(s *serializedExpr, ctx context.Context) (Expression, error) {
+	switch {
+	case s.Column != nil:
+		return &Column{Index: *s.Column, RetType: s.Type}, nil
+	case s.Function != "":
+		args := make([]Expression, len(s.Args))
+		for i, a := range s.Args {
+			arg, err := deserializeExpr(a, ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			args[i] = arg
+		}
+		if s.Function == ast.Cast {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("cast needs 1 argument, not %d", len(args))
+			}
+			return NewCastFunc(s.Type, args[0], ctx), nil
+		}
+		return NewFunction(ctx, s.Function, s.Type, args...)
+	}
+	_, value, err := codec.DecodeOne(s.Value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if s.Kind == types.KindString && value.Kind() == types.KindBytes {
+		value.SetString(string(value.GetBytes()))
+	}
+	return &Constant{Value: value, RetType: s.Type}, nil
+}
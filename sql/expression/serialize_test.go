@@ -0,0 +1,53 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestMarshalExprs(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	ctx.vars.CurrentDB = "sales"
+	word := &Column{Index: 1, RetType: types.NewFieldType(mysql.TypeVarchar)}
+	suffix := &Constant{Value: types.NewStringDatum("!"), RetType: types.NewFieldType(mysql.TypeVarchar)}
+	concat, err := NewFunction(ctx, ast.Concat, types.NewFieldType(mysql.TypeVarchar), word, suffix)
+	if err != nil {
+		t.Fatalf("concat: %v", err)
+	}
+	database, err := NewFunction(ctx, "database", types.NewFieldType(mysql.TypeVarchar))
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	cast := NewCastFunc(types.NewFieldType(mysql.TypeLonglong), &Column{Index: 0}, ctx)
+
+	data, err := MarshalExprs([]Expression{concat, database, cast}, ctx)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	exprs, err := UnmarshalExprs(data, &testContext{vars: variable.NewSessionVars()})
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	row := types.MakeDatums("12", "word")
+	for i, expected := range []interface{}{"word!", "sales", int64(12)} {
+		d, err := exprs[i].Eval(row, ctx)
+		if err != nil {
+			t.Fatalf("eval %s: %v", exprs[i], err)
+		}
+		if d.GetValue() != expected {
+			t.Errorf("%s: %v, expecting %v", exprs[i], d.GetValue(), expected)
+		}
+	}
+
+	setVar, err := NewFunction(ctx, ast.SetVar, types.NewFieldType(mysql.TypeVarchar), suffix, word)
+	if err != nil {
+		t.Fatalf("setvar: %v", err)
+	}
+	if _, err = MarshalExprs([]Expression{setVar}, ctx); err == nil {
+		t.Errorf("expected an error serializing %s", setVar)
+	}
+}
@@ -24,15 +24,20 @@ import (
 
 // ExtractColumns extracts all columns from an expression.
 func ExtractColumns(expr Expression) (cols []*Column) {
+	return AppendColumns(nil, expr)
+}
+
+// AppendColumns appends all columns of an expression to cols.
+func AppendColumns(cols []*Column, expr Expression) []*Column {
 	switch v := expr.(type) {
 	case *Column:
-		return []*Column{v}
+		cols = append(cols, v)
 	case *ScalarFunction:
 		for _, arg := range v.GetArgs() {
-			cols = append(cols, ExtractColumns(arg)...)
+			cols = AppendColumns(cols, arg)
 		}
 	}
-	return
+	return cols
 }
 
 // ColumnSubstitute substitutes the columns in filter to expressions in select fields.
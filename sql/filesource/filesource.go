@@ -0,0 +1,90 @@
+// Package filesource reads the files of the tables registered with a file
+// location with the file plugin. It also adds the PARQUET format of
+// SELECT ... INTO OUTFILE.
+// Import it for its side effect:
+//
+//	import _ "github.com/lovelly/gleam/sql/filesource"
+package filesource
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/file"
+	_ "github.com/lovelly/gleam/plugins/file/orc"
+	"github.com/lovelly/gleam/plugins/file/parquet"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func init() {
+	executor.OpenTableLocation = open
+	executor.OutfileFormats["PARQUET"] = newParquetRowWriter
+}
+
+var fileSources = map[string]func(fileOrPattern string, partitionCount int) *file.FileSource{
+	"csv":     file.Csv,
+	"tsv":     file.Tsv,
+	"txt":     file.Txt,
+	"jsonl":   file.Jsonl,
+	"orc":     file.Orc,
+	"parquet": file.Parquet,
+}
+
+func open(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+	newFileSource, found := fileSources[location.FileType]
+	if !found {
+		return nil, fmt.Errorf("Unknown file type %s of %s", location.FileType, location.Path)
+	}
+	partitionCount := location.PartitionCount
+	if partitionCount <= 0 {
+		partitionCount = 1
+	}
+	src := newFileSource(location.Path, partitionCount)
+	if src == nil {
+		return nil, fmt.Errorf("Invalid file %s", location.Path)
+	}
+	if len(location.Fields) > 0 {
+		src.Select(location.Fields...)
+	}
+	return f.Read(src.SetHasHeader(location.HasHeader)), nil
+}
+
+// parquetRowWriter writes the rows as UTF8 columns named after the result columns.
+type parquetRowWriter struct {
+	writer *parquet.ParquetFileWriter
+}
+
+func newParquetRowWriter(w io.WriteCloser, schema expression.Schema) (executor.RowWriter, error) {
+	fieldNames := make([]string, schema.Len())
+	for i, col := range schema.Columns {
+		fieldNames[i] = col.ColName.O
+	}
+	writer, err := parquet.NewWriter(w, fieldNames)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &parquetRowWriter{writer: writer}, nil
+}
+
+func (w *parquetRowWriter) Write(row []types.Datum) error {
+	values := make([]*string, len(row))
+	for i, d := range row {
+		if d.IsNull() {
+			continue
+		}
+		s, err := d.ToString()
+		if err != nil {
+			return err
+		}
+		values[i] = &s
+	}
+	return w.writer.Write(values)
+}
+
+func (w *parquetRowWriter) Close() error {
+	return w.writer.Close()
+}
@@ -2,68 +2,359 @@ package sql
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/executor"
 	"github.com/lovelly/gleam/sql/infoschema"
 	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
 	"github.com/lovelly/gleam/sql/parser"
 	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/privilege"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
 	"github.com/lovelly/gleam/sql/util/types"
 )
 
-func RegisterTable(dataset *flow.Dataset, tableName string, columns []executor.TableColumn) {
+// RegisterTable makes the dataset queryable as a table. The dataset size hint,
+// e.g. dataset.Hint(flow.TotalSize(64)), lets joins broadcast small tables.
+// The table name can be qualified with its database, e.g. "sales.orders",
+// so tables of the same name coexist in different databases.
+// Otherwise the table is in executor.DefaultDB.
+// The ENUM and SET columns need their members, see ColumnElems().
+func RegisterTable(dataset *flow.Dataset, tableName string, columns []executor.TableColumn, options ...TableOption) {
+	registerTableSource(newDatasetTable(dataset, tableName, columns, options))
+}
+
+// TableOption sets more about the columns of a registered table.
+type TableOption func(columnElems map[string][]string)
+
+// ColumnElems lists the members of the ENUM or SET column, in the order
+// numbering the values of executor.EncodeRowValues(), e.g.
+//
+//	sql.RegisterTable(tasks, "tasks", []executor.TableColumn{
+//		{"name", mysql.TypeVarchar},
+//		{"state", mysql.TypeEnum},
+//	}, sql.ColumnElems("state", "open", "done"))
+func ColumnElems(columnName string, elems ...string) TableOption {
+	return func(columnElems map[string][]string) {
+		columnElems[columnName] = elems
+	}
+}
+
+func tableColumnElems(options []TableOption) map[string][]string {
+	if len(options) == 0 {
+		return nil
+	}
+	columnElems := make(map[string][]string)
+	for _, option := range options {
+		option(columnElems)
+	}
+	return columnElems
+}
+
+func newDatasetTable(dataset *flow.Dataset, tableName string, columns []executor.TableColumn, options []TableOption) *executor.TableSource {
+	dbName, tableName := splitTableName(tableName)
+	columnElems := tableColumnElems(options)
+	t := newTableInfo(tableName, columns, columnElems)
+	t.SizeInMB = dataset.Meta.TotalSize
+	return &executor.TableSource{
+		DBName:      dbName,
+		Dataset:     dataset,
+		TableInfo:   t,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	}
+}
+
+// RegisterSink makes the table a target of "INSERT INTO table SELECT ...".
+// The sink receives each inserted row, cast to the column types, when the flow
+// of the dataset returned by Query() runs. It is not called concurrently.
+// In a transaction of a QueryManager session, the rows are kept by the
+// session, and can be changed by UPDATE, until COMMIT writes them.
+// The table name can be qualified with its database, as in RegisterTable().
+func RegisterSink(tableName string, columns []executor.TableColumn, sink func(row []interface{}) error, options ...TableOption) {
+	dbName, tableName := splitTableName(tableName)
+	columnElems := tableColumnElems(options)
+	registerTableSource(&executor.TableSource{
+		DBName:      dbName,
+		TableInfo:   newTableInfo(tableName, columns, columnElems),
+		Sink:        sink,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	})
+}
+
+func splitTableName(name string) (dbName, tableName string) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return strings.ToLower(name[:i]), name[i+1:]
+	}
+	return executor.DefaultDB, name
+}
+
+func registerTableSource(ts *executor.TableSource) {
+	executor.AddDatabase(ts.DBName)
+	executor.PutTable(executor.TableKey(ts.DBName, ts.TableInfo.Name.O), ts)
+}
+
+func newTableInfo(tableName string, columns []executor.TableColumn, columnElems map[string][]string) *model.TableInfo {
 	var cols []*model.ColumnInfo
-	for _, c := range columns {
+	for i, c := range columns {
+		ft := types.NewFieldType(c.ColumnType)
+		ft.Elems = columnElems[c.ColumnName]
+		if c.ColumnType == mysql.TypeBit {
+			ft.Flen = types.MaxBitWidth
+		}
 		cols = append(cols, &model.ColumnInfo{
 			Name:      model.NewCIStr(c.ColumnName),
-			FieldType: *types.NewFieldType(c.ColumnType),
+			Offset:    i,
+			FieldType: *ft,
 		})
 	}
-	t := &model.TableInfo{
+	return &model.TableInfo{
 		Name:    model.NewCIStr(tableName),
 		Columns: cols,
 	}
-	executor.Tables[tableName] = &executor.TableSource{
-		Dataset:   dataset,
-		TableInfo: t,
+}
+
+// expandParams substitutes the parameters as string literals. The flows are
+// visited by their table names, so a parameter declared on several flows
+// has the same value on every run.
+func expandParams(sql string) string {
+	tables := executor.AllTables()
+	var keys []string
+	for key := range tables {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	expanded := make(map[*flow.Flow]bool)
+	for _, key := range keys {
+		ts := tables[key]
+		if ts.Dataset == nil {
+			continue
+		}
+		if fc := ts.Dataset.Flow; fc != nil && !expanded[fc] {
+			expanded[fc] = true
+			sql = fc.ExpandSQL(sql)
+		}
+	}
+	return sql
+}
+
+// InfoSchema returns the info schema of the registered tables, e.g. to
+// convert a plan back with plan.PBToPlan().
+func InfoSchema() infoschema.InfoSchema {
+	return infoschema.NewInfoSchemaFromDBs(dbInfoList(nil))
 }
 
-func tableInfoList() (infos []*model.TableInfo) {
-	for _, ts := range executor.Tables {
-		t := ts.TableInfo
-		infos = append(infos, t)
+// dbInfoList lists the databases and their registered tables, where the
+// temporary tables hide the registered tables of the same name.
+func dbInfoList(tempTables map[string]*executor.TableSource) (dbInfos []*model.DBInfo) {
+	dbs := make(map[string]*model.DBInfo)
+	for _, dbName := range executor.DatabaseNames() {
+		db := &model.DBInfo{Name: model.NewCIStr(dbName)}
+		dbs[dbName] = db
+		dbInfos = append(dbInfos, db)
+	}
+	for key, ts := range executor.AllTables() {
+		if _, found := tempTables[key]; found {
+			continue
+		}
+		if db := dbs[ts.DBName]; db != nil {
+			db.Tables = append(db.Tables, ts.TableInfo)
+		}
 	}
-	return infos
+	for _, ts := range tempTables {
+		if db := dbs[ts.DBName]; db != nil {
+			db.Tables = append(db.Tables, ts.TableInfo)
+		}
+	}
+	return dbInfos
 }
 
+// outfileClause matches the trailing "INTO OUTFILE 'file' [FORMAT format]" of a SELECT.
+var outfileClause = regexp.MustCompile(`(?is)\s+INTO\s+OUTFILE\s+'((?:[^']|'')*)'(?:\s+FORMAT\s+(\w+))?\s*;?\s*$`)
+
+// splitOutfile removes the INTO OUTFILE clause, which the parser does not support,
+// from the SQL.
+func splitOutfile(sql string) (string, *executor.Outfile) {
+	m := outfileClause.FindStringSubmatchIndex(sql)
+	if m == nil {
+		return sql, nil
+	}
+	outfile := &executor.Outfile{
+		FileName: strings.Replace(sql[m[2]:m[3]], "''", "'", -1),
+	}
+	if m[4] >= 0 {
+		outfile.Format = sql[m[4]:m[5]]
+	}
+	return sql[:m[0]], outfile
+}
+
+// Query runs the SQL on the registered tables. The "{{name}}" placeholders
+// of the parameters declared on the tables' flows are substituted first.
+// SET statements change the variables of the following queries, and return no dataset.
+// INSERT INTO ... SELECT returns the dataset whose rows are written to the table's sink.
+// USE changes the database of the unqualified table names of the following queries.
+// SELECT ... INTO OUTFILE 'file' [FORMAT CSV|TSV|PARQUET] returns the dataset whose
+// rows are written to the file, which can be on s3:// or hdfs://.
+// CREATE STREAM name (columns) WITH (options) binds a message queue, e.g. a
+// kafka topic, to a table, see package streamsource, and DROP STREAM removes it.
+// CREATE FUNCTION name AS PIPE 'code' declares a function run by an external
+// program in the select fields, see execFunctionStatement(), and DROP FUNCTION
+// removes it.
+// The table functions FILES('path', 'format') and RANGE(n) can be read like
+// tables, see ast.TableFunction. Reading and writing files needs the FILE privilege.
+// The filters and projections are evaluated by gio mappers on the executors,
+// so the program needs to call gio.Init() first.
+// See EnableResultCache() for caching the results of repeated queries, and
+// EnablePlanCache() for caching their plans.
+// The query has all privileges, see QueryAs() for the queries of users.
 func Query(sql string) (*flow.Dataset, plan.Plan, error) {
+	return query("", sql)
+}
+
+// QueryAs runs the SQL like Query(), as the user created by CREATE USER or by
+// privilege.CreateUser(). Statements fail without the privileges granted to the
+// user or its roles, e.g. SELECT on the tables read. Each user has its own
+// session variables. The user is not authenticated here, the caller checks
+// the password of the user first, e.g. with privilege.Authenticate().
+func QueryAs(user, sql string) (*flow.Dataset, plan.Plan, error) {
+	return query(user, sql)
+}
+
+func query(user, sql string) (*flow.Dataset, plan.Plan, error) {
+	if handled, err := execStreamStatement(user, sql); handled {
+		return nil, nil, err
+	}
+	if handled, err := execFunctionStatement(user, sql); handled {
+		return nil, nil, err
+	}
+	stmt, err := parseStatement(sql)
+	if err != nil {
+		return nil, nil, err
+	}
+	vars, err := getQuerySessionVars(user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create session %v", err)
+	}
+	return execStatement(vars, user, stmt, nil, nil, nil)
+}
+
+// parsedStatement is a statement parsed from the SQL, after expanding the
+// parameters and removing the INTO OUTFILE clause, with the tables of its
+// table functions.
+type parsedStatement struct {
+	sql            string
+	tree           ast.StmtNode
+	outfile        *executor.Outfile
+	tableFunctions map[string]*executor.TableSource // by table name
+}
+
+func parseStatement(sql string) (*parsedStatement, error) {
+	sql = expandParams(sql)
+	sql, outfile := splitOutfile(sql)
+
 	p := parser.New()
+	p.ResolveFunctions(isUDF)
 	tree, err := p.ParseOneStmt(sql, "", "")
 	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to parse SQL %s: %v", sql, err)
+		return nil, fmt.Errorf("Failed to parse SQL %s: %v", sql, err)
+	}
+	tableFunctions := &tableFunctionCollector{}
+	if tree.Accept(tableFunctions); tableFunctions.err != nil {
+		return nil, tableFunctions.err
 	}
+	if outfile != nil {
+		switch tree.(type) {
+		case *ast.SelectStmt, *ast.UnionStmt:
+		default:
+			return nil, fmt.Errorf("INTO OUTFILE is only supported by SELECT: %s", sql)
+		}
+	}
+	return &parsedStatement{sql: sql, tree: tree, outfile: outfile, tableFunctions: tableFunctions.tables}, nil
+}
 
-	infoSchema := infoschema.NewInfoSchema("", tableInfoList())
+// execStatement plans the statement with the session variables, and builds
+// its dataset on the flows of the tables read. If fc is set, the tables of
+// files, and the temporary tables, are read on fc instead. The tables of the
+// table functions are read on fc, or on a new flow. If dirty is set, it keeps
+// the changes of the statement in the transaction of the session, and the
+// statement reads the changes of the transaction.
+func execStatement(vars *variable.SessionVars, user string, stmt *parsedStatement, fc *flow.Flow, tempTables map[string]*executor.TableSource, dirty *executor.DirtyStatement) (*flow.Dataset, plan.Plan, error) {
+	sql, tree, outfile := stmt.sql, stmt.tree, stmt.outfile
+	if outfile != nil && user != "" && !privilege.Check(user, mysql.FilePriv, "", "") {
+		return nil, nil, privilege.ErrSpecificAccessDenied.GenByArgs("FILE")
+	}
 
-	session, err := CreateSession(infoSchema)
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to create session %v", err)
+	if len(stmt.tableFunctions) > 0 {
+		tables := make(map[string]*executor.TableSource)
+		for key, ts := range tempTables {
+			tables[key] = ts
+		}
+		for name, ts := range stmt.tableFunctions {
+			if ts.Location != nil && user != "" && !privilege.Check(user, mysql.FilePriv, "", "") {
+				return nil, nil, privilege.ErrSpecificAccessDenied.GenByArgs("FILE")
+			}
+			t := *ts
+			t.DBName = strings.ToLower(vars.CurrentDB)
+			tables[executor.TableKey(vars.CurrentDB, name)] = &t
+		}
+		tempTables = tables
+		if fc == nil {
+			fc = flow.New("query")
+		}
 	}
 
-	physicalPlan, err := Compile(session, tree)
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to get physical plan for %s: %v", sql, err)
+	infoSchema := infoschema.NewInfoSchemaFromDBs(dbInfoList(tempTables))
+
+	resetStmtCtx(vars, tree)
+	session := createSessionWithVars(infoSchema, vars)
+
+	var physicalPlan plan.Plan
+	planKey, cachePlan := "", false
+	if plans.enabled() {
+		planKey, cachePlan = planCacheKey(stmt, vars, user, tempTables)
+	}
+	if cachePlan {
+		physicalPlan, _ = plans.get(planKey)
+	}
+	if physicalPlan == nil {
+		var err error
+		if physicalPlan, err = Compile(session, tree); err != nil {
+			return nil, nil, fmt.Errorf("Failed to get physical plan for %s: %v", sql, err)
+		}
+		if cachePlan {
+			plans.put(planKey, physicalPlan)
+		}
+	}
+
+	var cached *cachedQuery
+	if outfile == nil && len(tempTables) == 0 && dirty.IsEmpty() && results.enabled() {
+		if cached = newCachedQuery(tree, physicalPlan, vars); cached != nil {
+			if rows, found := results.get(cached.key); found {
+				return cached.cachedDataset(rows), physicalPlan, nil
+			}
+		}
 	}
 
 	sa := &executor.Statement{
 		InfoSchema: infoSchema,
 		Plan:       physicalPlan,
 		Text:       tree.Text(),
+		Outfile:    outfile,
+		Flow:       fc,
+		TempTables: tempTables,
+		Dirty:      dirty,
 	}
 
 	ds, err := sa.Exec(session)
+	if cached != nil && err == nil && ds != nil {
+		cached.collect(ds)
+	}
 
 	return ds, physicalPlan, err
 
@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/parser"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// The parser does not support UDFs, so their statements are matched first:
+//
+//	CREATE FUNCTION [IF NOT EXISTS] name AS PIPE 'code' [RETURNS type]
+//	DROP FUNCTION [IF EXISTS] name
+//
+// The code runs as an external program, e.g. 'python udf.py', which answers
+// each tab-separated line of the arguments with a line. The values are
+// strings, unless converted to the type returned, e.g. RETURNS BIGINT, and
+// NULL is \N, see instruction.DoPipeColumn(). The parser resolves the calls
+// of the registered UDFs by isUDF().
+var (
+	createFunction = regexp.MustCompile(`(?is)^\s*CREATE\s+FUNCTION\s+(IF\s+NOT\s+EXISTS\s+)?(\w+)\s+AS\s+PIPE\s+'((?:[^']|'')*)'\s*(?:RETURNS\s+(.+?))?\s*;?\s*$`)
+	dropFunction   = regexp.MustCompile(`(?is)^\s*DROP\s+FUNCTION\s+(IF\s+EXISTS\s+)?(\w+)\s*;?\s*$`)
+)
+
+// execFunctionStatement runs CREATE FUNCTION or DROP FUNCTION, and tells
+// whether the SQL is one of them. Only the queries with all privileges can
+// run them.
+func execFunctionStatement(user, sql string) (bool, error) {
+	create := createFunction.FindStringSubmatch(sql)
+	drop := dropFunction.FindStringSubmatch(sql)
+	if create == nil && drop == nil {
+		return false, nil
+	}
+	if user != "" {
+		return true, fmt.Errorf("User %s can not create or drop functions", user)
+	}
+	if create != nil {
+		code := strings.Replace(create[3], "''", "'", -1)
+		return true, createUDF(create[2], code, create[4], create[1] != "")
+	}
+	return true, dropUDF(drop[2], drop[1] != "")
+}
+
+func createUDF(name, code, returns string, ifNotExists bool) error {
+	name = strings.ToLower(name)
+	if parser.IsKeyword(name) {
+		return fmt.Errorf("Function %s can not be named after a keyword", name)
+	}
+	if expression.GetUDF(name) != nil {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("Function %s already exists", name)
+	}
+	if strings.TrimSpace(code) == "" {
+		return fmt.Errorf("Function %s has no code", name)
+	}
+	retType := types.NewFieldType(mysql.TypeVarchar)
+	if returns != "" {
+		columns, columnElems, err := parseColumnDefs("(value " + returns + ")")
+		if err != nil || len(columns) != 1 {
+			return fmt.Errorf("Failed to parse function %s type %s: %v", name, returns, err)
+		}
+		retType = types.NewFieldType(columns[0].ColumnType)
+		retType.Elems = columnElems["value"]
+	}
+	return expression.RegisterUDF(&expression.UDF{
+		Name:    name,
+		Code:    code,
+		RetType: retType,
+	})
+}
+
+func dropUDF(name string, ifExists bool) error {
+	name = strings.ToLower(name)
+	if !expression.UnregisterUDF(name) && !ifExists {
+		return fmt.Errorf("Unknown function %s", name)
+	}
+	return nil
+}
+
+func isUDF(name string) bool {
+	return expression.GetUDF(strings.ToLower(name)) != nil
+}
@@ -84,17 +84,23 @@ type infoSchema struct {
 }
 
 func NewInfoSchema(schemaName string, tbList []*model.TableInfo) InfoSchema {
+	return NewInfoSchemaFromDBs([]*model.DBInfo{{Name: model.NewCIStr(schemaName), Tables: tbList}})
+}
+
+// NewInfoSchemaFromDBs creates the InfoSchema of the databases and their tables.
+func NewInfoSchemaFromDBs(dbInfos []*model.DBInfo) InfoSchema {
 	result := &infoSchema{}
 	result.schemaMap = make(map[string]*schemaTables)
-	dbInfo := &model.DBInfo{Name: model.NewCIStr(schemaName), Tables: tbList}
-	tableNames := &schemaTables{
-		dbInfo: dbInfo,
-		tables: make(map[string]table.Table),
-	}
-	result.schemaMap[schemaName] = tableNames
-	for _, tb := range tbList {
-		tbl := table.MockTableFromMeta(tb)
-		tableNames.tables[tb.Name.L] = tbl
+	for _, dbInfo := range dbInfos {
+		tableNames := &schemaTables{
+			dbInfo: dbInfo,
+			tables: make(map[string]table.Table),
+		}
+		result.schemaMap[dbInfo.Name.L] = tableNames
+		for _, tb := range dbInfo.Tables {
+			tbl := table.MockTableFromMeta(tb)
+			tableNames.tables[tb.Name.L] = tbl
+		}
 	}
 	return result
 }
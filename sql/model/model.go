@@ -40,6 +40,8 @@ type TableInfo struct {
 	Columns []*ColumnInfo `json:"cols"` // Columns are listed in the order in which they appear in the schema.
 	Indices []*IndexInfo  `json:"index_info"`
 	Comment string        `json:"comment"`
+	// SizeInMB is the estimated table size, 0 if unknown.
+	SizeInMB int64 `json:"size_in_mb"`
 }
 
 // Clone clones TableInfo.
@@ -174,6 +174,10 @@ const (
 	ExecutePriv
 	// IndexPriv is the privilege to create/drop index.
 	IndexPriv
+	// FilePriv is the privilege to read and write files, e.g. by LOAD DATA and INTO OUTFILE.
+	FilePriv
+	// SuperPriv is the privilege to change global variables.
+	SuperPriv
 	// AllPriv is the privilege for all actions.
 	AllPriv
 )
@@ -192,6 +196,8 @@ var Priv2UserCol = map[PrivilegeType]string{
 	AlterPriv:      "Alter_priv",
 	ExecutePriv:    "Execute_priv",
 	IndexPriv:      "Index_priv",
+	FilePriv:       "File_priv",
+	SuperPriv:      "Super_priv",
 }
 
 // Col2PrivType is the privilege tables column name to privilege type.
@@ -208,10 +214,12 @@ var Col2PrivType = map[string]PrivilegeType{
 	"Alter_priv":       AlterPriv,
 	"Execute_priv":     ExecutePriv,
 	"Index_priv":       IndexPriv,
+	"File_priv":        FilePriv,
+	"Super_priv":       SuperPriv,
 }
 
 // AllGlobalPrivs is all the privileges in global scope.
-var AllGlobalPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, ShowDBPriv, ExecutePriv, IndexPriv, CreateUserPriv}
+var AllGlobalPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, ShowDBPriv, ExecutePriv, IndexPriv, CreateUserPriv, FilePriv, SuperPriv}
 
 // Priv2Str is the map for privilege to string.
 var Priv2Str = map[PrivilegeType]string{
@@ -227,6 +235,8 @@ var Priv2Str = map[PrivilegeType]string{
 	AlterPriv:      "Alter",
 	ExecutePriv:    "Execute",
 	IndexPriv:      "Index",
+	FilePriv:       "File",
+	SuperPriv:      "Super",
 }
 
 // Priv2SetStr is the map for privilege to string.
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The grammar has no calls of functions by name, only of the builtin
+// functions it knows, so the scanner reads the calls of the functions
+// resolved by ResolveFunctions(), e.g. the UDFs, as the tokens of builtin
+// calls named after the functions:
+//
+//	name()             as CURDATE()
+//	name(expr, ...)    as COALESCE(expr, ...)
+//
+// The parser then creates the function calls with their names. A name is not
+// resolved where it names a table, e.g. INSERT INTO name (a), nor in the
+// statements defining the tables, views and indexes.
+
+// ResolveFunctions sets the functions called by name besides the builtin ones.
+// The keywords are never resolved, they are scanned as their tokens.
+func (parser *Parser) ResolveFunctions(isFunction func(name string) bool) {
+	parser.lexer.isFunction = isFunction
+}
+
+// IsKeyword tells whether the name is scanned as a keyword, and so can not
+// name a resolved function.
+func IsKeyword(name string) bool {
+	return tokenMap[strings.ToUpper(name)] != 0
+}
+
+// tableNameTokens are the tokens after which an identifier followed by '('
+// names a table, not a function.
+var tableNameTokens = map[int]bool{
+	int('.'):   true,
+	from:       true,
+	join:       true,
+	into:       true,
+	insert:     true,
+	replace:    true,
+	update:     true,
+	tableKwd:   true,
+	references: true,
+	index:      true,
+	key:        true,
+}
+
+// definitionTokens start the statements in which no function is resolved.
+var definitionTokens = map[int]bool{
+	create:   true,
+	alter:    true,
+	drop:     true,
+	rename:   true,
+	truncate: true,
+}
+
+// scanFunctionCall returns the token of the call of a resolved function, or
+// identifier if the identifier scanned is not one.
+func (s *Scanner) scanFunctionCall(v *yySymType) int {
+	if s.isFunction == nil || tableNameTokens[s.lastTok] || definitionTokens[s.firstTok] {
+		return identifier
+	}
+	if s.skipWhitespace() != '(' || !s.isFunction(v.ident) {
+		return identifier
+	}
+	args := strings.TrimLeftFunc(s.r.s[s.r.pos().Offset+1:], unicode.IsSpace)
+	if strings.HasPrefix(args, ")") {
+		v.item = v.ident
+		return curDate
+	}
+	return coalesce
+}
@@ -19,6 +19,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/lovelly/gleam/sql/ast"
 )
 
 var _ = yyLexer(&Scanner{})
@@ -40,6 +42,11 @@ type Scanner struct {
 
 	// for scanning such kind of comment: /*! MySQL-specific code */
 	specialComment *specialCommentScanner
+
+	lastTok        int                    // the token returned before
+	firstTok       int                    // the first token of the statement
+	tableFunctions []*ast.TableFunction   // scanned by scanTableFunction()
+	isFunction     func(name string) bool // set by Parser.ResolveFunctions()
 }
 
 type specialCommentScanner struct {
@@ -58,6 +65,9 @@ func (s *Scanner) reset(sql string) {
 	s.buf.Reset()
 	s.errs = s.errs[:0]
 	s.stmtStartPos = 0
+	s.lastTok = 0
+	s.firstTok = 0
+	s.tableFunctions = nil
 }
 
 func (s *Scanner) stmtText() string {
@@ -93,6 +103,22 @@ func (s *Scanner) Errorf(format string, a ...interface{}) {
 // return 0 tells parser that scanner meets EOF,
 // return invalid tells parser that scanner meets illegal character.
 func (s *Scanner) Lex(v *yySymType) int {
+	tok := s.lex(v)
+	if (s.lastTok == from || s.lastTok == join) && (tok == identifier || tok == rangeKwd) && isTableFunction(v.ident) {
+		if s.skipWhitespace() == '(' {
+			tok = s.scanTableFunction(v)
+		}
+	} else if tok == identifier {
+		tok = s.scanFunctionCall(v)
+	}
+	if s.lastTok == 0 || s.lastTok == int(';') {
+		s.firstTok = tok
+	}
+	s.lastTok = tok
+	return tok
+}
+
+func (s *Scanner) lex(v *yySymType) int {
 	tok, pos, lit := s.scan()
 	v.offset = pos.Offset
 	v.ident = lit
@@ -498,6 +498,15 @@ var tokenMap = map[string]int{
 	"CONV":                conv,
 	"BIT_XOR":             bitXor,
 	"CRC32":               crc32,
+	"ELT":                 elt,
+	"EXPORT_SET":          exportSet,
+	"FORMAT":              format,
+	"INSTR":               instr,
+	"LPAD":                lpad,
+	"QUOTE":               quote,
+	"REGEXP_REPLACE":      regexpReplace,
+	"REGEXP_SUBSTR":       regexpSubstr,
+	"ROW_COUNT":           rowCount,
 }
 
 func isTokenIdentifier(s string, buf *bytes.Buffer) int {
@@ -173,6 +173,7 @@ const (
 	duplicate                = 57635
 	dynamic                  = 57636
 	elseKwd                  = 57397
+	elt                      = 58031
 	enable                   = 57637
 	enclosed                 = 57398
 	end                      = 57638
@@ -187,6 +188,7 @@ const (
 	execute                  = 57642
 	exists                   = 57400
 	explain                  = 57401
+	exportSet                = 58032
 	extract                  = 57720
 	falseKwd                 = 57402
 	fieldKwd                 = 57532
@@ -201,6 +203,7 @@ const (
 	forKwd                   = 57404
 	force                    = 57405
 	foreign                  = 57406
+	format                   = 58033
 	foundRows                = 57535
 	from                     = 57407
 	fromDays                 = 57530
@@ -237,6 +240,7 @@ const (
 	inner                    = 57421
 	insert                   = 57426
 	insertValues             = 57739
+	instr                    = 58034
 	intLit                   = 57710
 	intType                  = 57427
 	integerType              = 57422
@@ -293,6 +297,7 @@ const (
 	lowerThanSetKeyword      = 57737
 	lowerThanWith            = 57748
 	lowestOpt                = 57732
+	lpad                     = 58035
 	lsh                      = 57723
 	ltrim                    = 57555
 	max                      = 57556
@@ -347,6 +352,7 @@ const (
 	processlist              = 57669
 	quarter                  = 57670
 	quick                    = 57671
+	quote                    = 58036
 	rand                     = 57566
 	rangeKwd                 = 57464
 	read                     = 57465
@@ -354,6 +360,8 @@ const (
 	redundant                = 57672
 	references               = 57467
 	regexpKwd                = 57468
+	regexpReplace            = 58037
+	regexpSubstr             = 58038
 	releaseLock              = 57592
 	rename                   = 57469
 	repeat                   = 57470
@@ -366,6 +374,7 @@ const (
 	rollback                 = 57675
 	round                    = 57589
 	row                      = 57676
+	rowCount                 = 58039
 	rowFormat                = 57677
 	rpad                     = 57593
 	rsh                      = 57728
@@ -654,6 +663,15 @@ var (
 		57542: 183, // unhex (1072x)
 		57584: 184, // upper (1072x)
 		57585: 185, // version (1072x)
+		58031: 185, // elt (1072x)
+		58032: 185, // exportSet (1072x)
+		58033: 185, // format (1072x)
+		58034: 185, // instr (1072x)
+		58035: 185, // lpad (1072x)
+		58036: 185, // quote (1072x)
+		58037: 185, // regexpReplace (1072x)
+		58038: 185, // regexpSubstr (1072x)
+		58039: 185, // rowCount (1072x)
 		57586: 186, // weekday (1072x)
 		57587: 187, // weekofyear (1072x)
 		57588: 188, // yearweek (1072x)
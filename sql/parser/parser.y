@@ -304,6 +304,15 @@ import (
 	conv		"CONV"
 	bitXor		"BIT_XOR"
 	crc32		"CRC32"
+	elt		"ELT"
+	exportSet	"EXPORT_SET"
+	format		"FORMAT"
+	instr		"INSTR"
+	lpad		"LPAD"
+	quote		"QUOTE"
+	regexpReplace	"REGEXP_REPLACE"
+	regexpSubstr	"REGEXP_SUBSTR"
+	rowCount	"ROW_COUNT"
 
 	/* the following tokens belong to UnReservedKeyword*/
 	action		"ACTION"
@@ -2164,6 +2173,7 @@ NotKeywordToken:
 |	"SECOND" | "SIGN" | "SLEEP" | "SQRT" | "SQL_CALC_FOUND_ROWS" | "STR_TO_DATE" | "SUBDATE" | "SUBSTRING" %prec lowerThanLeftParen |
 "SUBSTRING_INDEX" | "SUM" | "TRIM" | "RTRIM" | "UCASE" | "UPPER" | "VERSION" | "WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
 |	"STATS_PERSISTENT" | "GET_LOCK" | "RELEASE_LOCK" | "CEIL" | "CEILING" | "FLOOR" | "FROM_UNIXTIME" | "TIMEDIFF" | "LN" | "LOG" | "LOG2" | "LOG10" | "FIELD_KWD"
+|	"ELT" | "EXPORT_SET" | "FORMAT" | "INSTR" | "LPAD" | "QUOTE" | "REGEXP_REPLACE" | "REGEXP_SUBSTR" | "ROW_COUNT"
 
 /************************************************************************************
  *
@@ -2513,6 +2523,15 @@ FunctionNameConflict:
 |	"UTC_DATE"
 |	"CURRENT_DATE"
 |	"VERSION"
+|	"ELT"
+|	"EXPORT_SET"
+|	"FORMAT"
+|	"INSTR"
+|	"LPAD"
+|	"QUOTE"
+|	"REGEXP_REPLACE"
+|	"REGEXP_SUBSTR"
+|	"ROW_COUNT"
 |	"INTERVAL" %prec lowerThanIntervalKeyword
 
 FunctionCallConflict:
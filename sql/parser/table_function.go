@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lovelly/gleam/sql/ast"
+)
+
+// The grammar has no table functions, so the scanner reads the calls of
+// FILES() and RANGE() in the FROM and JOIN clauses as the names of their
+// tables, and the parser sets the calls to the table names:
+//
+//	FROM FILES('path or pattern' [, 'format' [, 'column definitions']])
+//	FROM RANGE([start,] stop [, step])
+var tableFunctions = map[string]bool{"FILES": true, "RANGE": true}
+
+func isTableFunction(name string) bool {
+	return tableFunctions[strings.ToUpper(name)]
+}
+
+// scanTableFunction scans the arguments of the table function up to the
+// closing parenthesis, and returns its table name as an identifier. The
+// arguments are string literals or integers.
+func (s *Scanner) scanTableFunction(v *yySymType) int {
+	function := &ast.TableFunction{Name: strings.ToUpper(v.ident)}
+	s.r.inc() // '('
+	for {
+		tok, _, lit := s.scan()
+		sign := ""
+		if tok == int('-') {
+			sign = "-"
+			tok, _, lit = s.scan()
+		}
+		switch {
+		case tok == stringLit && sign == "":
+			function.Args = append(function.Args, lit)
+		case tok == intLit:
+			function.Args = append(function.Args, sign+lit)
+		default:
+			s.Errorf("expecting a string or an integer argument of %s()", function.Name)
+			return invalid
+		}
+
+		switch tok, _, _ = s.scan(); tok {
+		case int(')'):
+			s.tableFunctions = append(s.tableFunctions, function)
+			v.ident = fmt.Sprintf("%s%d", ast.TableFunctionPrefix, len(s.tableFunctions))
+			return identifier
+		case int(','):
+		default:
+			s.Errorf("expecting ',' or ')' after the arguments of %s()", function.Name)
+			return invalid
+		}
+	}
+}
+
+// tableFunctionSetter sets the table functions scanned to their table names.
+type tableFunctionSetter struct {
+	functions []*ast.TableFunction
+}
+
+func (v tableFunctionSetter) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok && t.Schema.O == "" && strings.HasPrefix(t.Name.O, ast.TableFunctionPrefix) {
+		i, err := strconv.Atoi(strings.TrimPrefix(t.Name.O, ast.TableFunctionPrefix))
+		if err == nil && i >= 1 && i <= len(v.functions) {
+			t.Function = v.functions[i-1]
+		}
+	}
+	return n, false
+}
+
+func (v tableFunctionSetter) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
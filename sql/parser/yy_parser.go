@@ -97,6 +97,9 @@ func (parser *Parser) Parse(sql, charset, collation string) ([]ast.StmtNode, err
 	}
 	for _, stmt := range parser.result {
 		ast.SetFlag(stmt)
+		if len(parser.lexer.tableFunctions) > 0 {
+			stmt.Accept(tableFunctionSetter{functions: parser.lexer.tableFunctions})
+		}
 	}
 	return parser.result, nil
 }
@@ -51,7 +51,6 @@ func exprHasSetVar(expr expression.Expression) bool {
 // PruneColumns implements LogicalPlan interface.
 func (p *Projection) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.GetChildByIndex(0).(LogicalPlan)
-	var selfUsedCols []*expression.Column
 	used := getUsedList(parentUsedCols, p.schema)
 	for i := len(used) - 1; i >= 0; i-- {
 		if !used[i] && exprHasSetVar(p.Exprs[i]) {
@@ -59,17 +58,19 @@ func (p *Projection) PruneColumns(parentUsedCols []*expression.Column) {
 			p.Exprs = append(p.Exprs[:i], p.Exprs[i+1:]...)
 		}
 	}
+	selfUsedCols := newUsedColumns()
+	defer releaseUsedColumns(selfUsedCols)
 	for _, expr := range p.Exprs {
-		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(expr)...)
+		*selfUsedCols = expression.AppendColumns(*selfUsedCols, expr)
 	}
-	child.PruneColumns(selfUsedCols)
+	child.PruneColumns(*selfUsedCols)
 }
 
 // PruneColumns implements LogicalPlan interface.
 func (p *Selection) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.GetChildByIndex(0).(LogicalPlan)
 	for _, cond := range p.Conditions {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(cond)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, cond)
 	}
 	child.PruneColumns(parentUsedCols)
 	p.SetSchema(child.GetSchema())
@@ -85,23 +86,24 @@ func (p *Aggregation) PruneColumns(parentUsedCols []*expression.Column) {
 			p.AggFuncs = append(p.AggFuncs[:i], p.AggFuncs[i+1:]...)
 		}
 	}
-	var selfUsedCols []*expression.Column
+	selfUsedCols := newUsedColumns()
+	defer releaseUsedColumns(selfUsedCols)
 	for _, aggrFunc := range p.AggFuncs {
 		for _, arg := range aggrFunc.GetArgs() {
-			selfUsedCols = append(selfUsedCols, expression.ExtractColumns(arg)...)
+			*selfUsedCols = expression.AppendColumns(*selfUsedCols, arg)
 		}
 	}
 	for _, expr := range p.GroupByItems {
-		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(expr)...)
+		*selfUsedCols = expression.AppendColumns(*selfUsedCols, expr)
 	}
-	child.PruneColumns(selfUsedCols)
+	child.PruneColumns(*selfUsedCols)
 }
 
 // PruneColumns implements LogicalPlan interface.
 func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.GetChildByIndex(0).(LogicalPlan)
 	for _, item := range p.ByItems {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(item.Expr)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, item.Expr)
 	}
 	child.PruneColumns(parentUsedCols)
 	p.SetSchema(p.GetChildByIndex(0).GetSchema())
@@ -170,21 +172,29 @@ func (p *Insert) PruneColumns(_ []*expression.Column) {
 
 // PruneColumns implements LogicalPlan interface.
 func (p *Join) PruneColumns(parentUsedCols []*expression.Column) {
+	leftCols, rightCols := p.extractUsedCols(parentUsedCols)
+	p.GetChildByIndex(0).(LogicalPlan).PruneColumns(leftCols)
+	p.GetChildByIndex(1).(LogicalPlan).PruneColumns(rightCols)
+	p.mergeSchema()
+}
+
+// extractUsedCols splits the columns used by the parent and the join conditions
+// into the columns of the left and the right child.
+func (p *Join) extractUsedCols(parentUsedCols []*expression.Column) (leftCols, rightCols []*expression.Column) {
 	for _, eqCond := range p.EqualConditions {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(eqCond)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, eqCond)
 	}
 	for _, leftCond := range p.LeftConditions {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(leftCond)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, leftCond)
 	}
 	for _, rightCond := range p.RightConditions {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(rightCond)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, rightCond)
 	}
 	for _, otherCond := range p.OtherConditions {
-		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(otherCond)...)
+		parentUsedCols = expression.AppendColumns(parentUsedCols, otherCond)
 	}
 	lChild := p.GetChildByIndex(0).(LogicalPlan)
 	rChild := p.GetChildByIndex(1).(LogicalPlan)
-	var leftCols, rightCols []*expression.Column
 	for _, col := range parentUsedCols {
 		if lChild.GetSchema().GetColumnIndex(col) != -1 {
 			leftCols = append(leftCols, col)
@@ -192,8 +202,13 @@ func (p *Join) PruneColumns(parentUsedCols []*expression.Column) {
 			rightCols = append(rightCols, col)
 		}
 	}
-	lChild.PruneColumns(leftCols)
-	rChild.PruneColumns(rightCols)
+	return leftCols, rightCols
+}
+
+// mergeSchema rebuilds the schema of the join from the pruned children.
+func (p *Join) mergeSchema() {
+	lChild := p.GetChildByIndex(0).(LogicalPlan)
+	rChild := p.GetChildByIndex(1).(LogicalPlan)
 	composedSchema := expression.MergeSchema(lChild.GetSchema(), rChild.GetSchema())
 	if p.JoinType == SemiJoin {
 		p.schema = lChild.GetSchema().Clone()
@@ -207,11 +222,17 @@ func (p *Join) PruneColumns(parentUsedCols []*expression.Column) {
 }
 
 // PruneColumns implements LogicalPlan interface.
-func (p *Apply) PruneColumns(parentUseCols []*expression.Column) {
+// The inner plan is pruned first, so that the outer plan only keeps the
+// correlated columns that the pruned inner plan still uses.
+func (p *Apply) PruneColumns(parentUsedCols []*expression.Column) {
+	leftCols, rightCols := p.extractUsedCols(parentUsedCols)
+	p.GetChildByIndex(1).(LogicalPlan).PruneColumns(rightCols)
+	p.extractCorColumnsBySchema()
 	for _, col := range p.corCols {
-		parentUseCols = append(parentUseCols, &col.Column)
+		leftCols = append(leftCols, &col.Column)
 	}
-	p.Join.PruneColumns(parentUseCols)
+	p.GetChildByIndex(0).(LogicalPlan).PruneColumns(leftCols)
+	p.mergeSchema()
 }
 
 // PruneColumns implements LogicalPlan interface.
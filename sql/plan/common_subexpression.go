@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/model"
+)
+
+// shortCircuitFuncs only evaluate their first argument for every row. The
+// other arguments are not extracted as common subexpressions, which would
+// evaluate them for every row, e.g. 1/x of CASE WHEN x != 0 THEN 1/x END.
+var shortCircuitFuncs = map[string]bool{
+	ast.AndAnd:   true,
+	ast.OrOr:     true,
+	ast.Case:     true,
+	ast.If:       true,
+	ast.Ifnull:   true,
+	ast.Coalesce: true,
+}
+
+// eliminateCommonSubexpressions evaluates the subexpressions repeated in the
+// expressions of a Projection or a Selection once per row, as the columns of
+// a Projection added below it. e.g. for "select (a+b)*2, (a+b)/2 from t",
+// a+b is evaluated once, and the expressions use its column.
+func eliminateCommonSubexpressions(p LogicalPlan, ctx context.Context, alloc *idAllocator) error {
+	for _, child := range p.GetChildren() {
+		if err := eliminateCommonSubexpressions(child.(LogicalPlan), ctx, alloc); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch x := p.(type) {
+	case *Projection:
+		x.Exprs, err = extractCommonSubexpressions(x, x.Exprs, ctx, alloc)
+	case *Selection:
+		// the conditions on a table are evaluated by the table scan
+		if _, onTable := x.GetChildByIndex(0).(*DataSource); !onTable {
+			x.Conditions, err = extractCommonSubexpressions(x, x.Conditions, ctx, alloc)
+		}
+	}
+	return err
+}
+
+// extractCommonSubexpressions inserts a Projection computing the repeated
+// subexpressions of exprs below p, and returns exprs using their columns.
+func extractCommonSubexpressions(p LogicalPlan, exprs []expression.Expression, ctx context.Context, alloc *idAllocator) ([]expression.Expression, error) {
+	counts := make(map[string]int)
+	for _, expr := range exprs {
+		countSubexpressions(expr, counts)
+	}
+	repeated := false
+	for _, count := range counts {
+		if count > 1 {
+			repeated = true
+			break
+		}
+	}
+	if !repeated {
+		return exprs, nil
+	}
+
+	child := p.GetChildByIndex(0).(LogicalPlan)
+	childSchema := child.GetSchema().Clone()
+	proj := newProjection(ctx, alloc, len(childSchema.Columns))
+	proj.Exprs = append(proj.Exprs, expression.Column2Exprs(childSchema.Columns)...)
+	proj.SetSchema(childSchema)
+
+	e := &subexpressionExtractor{proj: proj, counts: counts, columns: make(map[string]*expression.Column)}
+	newExprs := make([]expression.Expression, len(exprs))
+	for i, expr := range exprs {
+		newExprs[i] = e.replace(expr)
+	}
+	if err := InsertPlan(p, child, proj); err != nil {
+		return nil, err
+	}
+	return newExprs, nil
+}
+
+// countSubexpressions counts the deterministic function calls evaluated for
+// every row, by their hash codes.
+func countSubexpressions(expr expression.Expression, counts map[string]int) {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok || !extractable(sf) {
+		return
+	}
+	counts[string(sf.HashCode())]++
+	args := sf.GetArgs()
+	if shortCircuitFuncs[sf.FuncName.L] && len(args) > 0 {
+		args = args[:1]
+	}
+	for _, arg := range args {
+		countSubexpressions(arg, counts)
+	}
+}
+
+func extractable(sf *expression.ScalarFunction) bool {
+	if _, isDynamic := expression.DynamicFuncs[sf.FuncName.L]; isDynamic {
+		return false
+	}
+	return !sf.IsCorrelated()
+}
+
+type subexpressionExtractor struct {
+	proj    *Projection
+	counts  map[string]int
+	columns map[string]*expression.Column // of the repeated subexpressions, by their hash codes
+}
+
+// replace replaces the outermost repeated subexpressions with their columns.
+func (e *subexpressionExtractor) replace(expr expression.Expression) expression.Expression {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok || !extractable(sf) {
+		return expr
+	}
+	hash := string(sf.HashCode())
+	if e.counts[hash] > 1 {
+		return e.column(hash, sf).Clone()
+	}
+	args := sf.GetArgs()
+	if shortCircuitFuncs[sf.FuncName.L] && len(args) > 0 {
+		args = args[:1]
+	}
+	changed := false
+	newArgs := append([]expression.Expression{}, sf.GetArgs()...)
+	for i, arg := range args {
+		if newArgs[i] = e.replace(arg); newArgs[i] != arg {
+			changed = true
+		}
+	}
+	if !changed {
+		return expr
+	}
+	if sf.FuncName.L == ast.Cast {
+		newFunc := sf.Clone().(*expression.ScalarFunction)
+		newFunc.GetArgs()[0] = newArgs[0]
+		return newFunc
+	}
+	newFunc, err := expression.NewFunction(sf.GetCtx(), sf.FuncName.L, sf.RetType, newArgs...)
+	if err != nil {
+		return expr
+	}
+	return newFunc
+}
+
+// column returns the column of the subexpression, computed by the projection.
+func (e *subexpressionExtractor) column(hash string, sf *expression.ScalarFunction) *expression.Column {
+	if col, ok := e.columns[hash]; ok {
+		return col
+	}
+	col := &expression.Column{
+		FromID:   e.proj.id,
+		ColName:  model.NewCIStr(fmt.Sprintf("cse_col_%d", len(e.columns))),
+		Position: e.proj.schema.Len(),
+		RetType:  sf.GetType(),
+	}
+	e.proj.Exprs = append(e.proj.Exprs, sf.Clone())
+	e.proj.schema.Append(col)
+	e.columns[hash] = col
+	return col
+}
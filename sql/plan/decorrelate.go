@@ -14,6 +14,7 @@
 package plan
 
 import (
+	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/expression"
 	"github.com/lovelly/gleam/sql/util/types"
 )
@@ -83,3 +84,55 @@ func decorrelate(p LogicalPlan) LogicalPlan {
 	p.SetChildren(newChildren...)
 	return p
 }
+
+// CorrelatedEqualities extracts the conditions of the inner plan in the form of
+// "inner column = outer column", so the inner plan can be run once for a batch of outer rows.
+// ok is false if the inner plan has other correlated conditions.
+func (p *PhysicalApply) CorrelatedEqualities() (innerCols, outerCols []*expression.Column, ok bool) {
+	ok = true
+	var walk func(p Plan)
+	walk = func(p Plan) {
+		var conditions []expression.Expression
+		switch x := p.(type) {
+		case *Selection:
+			conditions = x.Conditions
+		case *PhysicalTableScan:
+			conditions = append(x.AccessCondition, x.tableFilterConditions...)
+		case *PhysicalIndexScan:
+			conditions = append(append(x.AccessCondition, x.indexFilterConditions...), x.tableFilterConditions...)
+		}
+		for _, cond := range conditions {
+			if !cond.IsCorrelated() {
+				continue
+			}
+			inner, outer := correlatedEquality(cond)
+			if inner == nil {
+				ok = false
+				continue
+			}
+			innerCols = append(innerCols, inner)
+			outerCols = append(outerCols, outer)
+		}
+		for _, child := range p.GetChildren() {
+			walk(child)
+		}
+	}
+	walk(p.children[1])
+	return innerCols, outerCols, ok
+}
+
+func correlatedEquality(cond expression.Expression) (inner, outer *expression.Column) {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok || f.FuncName.L != ast.EQ || len(f.GetArgs()) != 2 {
+		return nil, nil
+	}
+	args := f.GetArgs()
+	for i := 0; i < 2; i++ {
+		col, isCol := args[i].(*expression.Column)
+		corCol, isCorCol := args[1-i].(*expression.CorrelatedColumn)
+		if isCol && isCorCol {
+			return col, &corCol.Column
+		}
+	}
+	return nil, nil
+}
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/lovelly/gleam/sql/ast"
@@ -36,11 +37,8 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 	if val, ok := expr.(*ast.ValueExpr); ok {
 		return val.Datum, nil
 	}
-	b := &planBuilder{
-		ctx:       ctx,
-		allocator: new(idAllocator),
-		colMapper: make(map[*ast.ColumnNameExpr]int),
-	}
+	b := newPlanBuilder(ctx, nil, new(idAllocator))
+	defer releasePlanBuilder(b)
 	if ctx.GetSessionVars().TxnCtx.InfoSchema != nil {
 		b.is = ctx.GetSessionVars().TxnCtx.InfoSchema.(infoschema.InfoSchema)
 	}
@@ -57,13 +55,9 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 // And this function returns a result expression, a new plan that may have apply or semi-join.
 func (b *planBuilder) rewrite(expr ast.ExprNode, p LogicalPlan, aggMapper map[*ast.AggregateFuncExpr]int, asScalar bool) (
 	expression.Expression, LogicalPlan, error) {
-	er := &expressionRewriter{
-		p:        p,
-		aggrMap:  aggMapper,
-		b:        b,
-		asScalar: asScalar,
-		ctx:      b.ctx,
-	}
+	er := rewriterPool.Get().(*expressionRewriter)
+	defer releaseRewriter(er)
+	er.p, er.aggrMap, er.b, er.asScalar, er.ctx = p, aggMapper, b, asScalar, b.ctx
 	if p != nil {
 		er.schema = p.GetSchema()
 	}
@@ -96,6 +90,29 @@ type expressionRewriter struct {
 	asScalar bool
 }
 
+// rewriterPool reuses the expressionRewriters and the memory of their stacks.
+var rewriterPool = sync.Pool{
+	New: func() interface{} {
+		return &expressionRewriter{}
+	},
+}
+
+// maxPooledStack is the largest stack kept by the pooled rewriters, so that a
+// huge expression does not keep its memory.
+const maxPooledStack = 64
+
+func releaseRewriter(er *expressionRewriter) {
+	stack := er.ctxStack[:cap(er.ctxStack)]
+	if len(stack) > maxPooledStack {
+		stack = nil
+	}
+	for i := range stack {
+		stack[i] = nil
+	}
+	*er = expressionRewriter{ctxStack: stack[:0]}
+	rewriterPool.Put(er)
+}
+
 func getRowLen(e expression.Expression) int {
 	if f, ok := e.(*expression.ScalarFunction); ok && f.FuncName.L == ast.RowFunc {
 		return len(f.GetArgs())
@@ -115,11 +132,72 @@ func getRowArg(e expression.Expression, idx int) expression.Expression {
 	return &expression.Constant{Value: d, RetType: c.GetType()}
 }
 
+// reversedOps are the operators of the comparisons with swapped arguments.
+var reversedOps = map[string]string{ast.LT: ast.GT, ast.LE: ast.GE, ast.GT: ast.LT, ast.GE: ast.LE}
+
+// columnBoundary compares the column with the constant converted to the type of
+// the column, instead of casting the column, when the constant is rounded or
+// truncated by the conversion, e.g. int_col > 1.9 is int_col >= 2, and
+// datetime_col < '2017-01-01 10:00:00.4' is datetime_col <= '2017-01-01 10:00:00'.
+// The boundary is adjusted like the ranges, see rangeBuilder.convertPoint().
+// It returns nil for the other comparisons.
+func (er *expressionRewriter) columnBoundary(l, r expression.Expression, op string) expression.Expression {
+	if _, ok := reversedOps[op]; !ok {
+		return nil
+	}
+	if _, ok := l.(*expression.Constant); ok {
+		l, r, op = r, l, reversedOps[op]
+	}
+	col, ok := l.(*expression.Column)
+	c, isConst := r.(*expression.Constant)
+	if !ok || !isConst || c.Value.IsNull() {
+		return nil
+	}
+	switch col.RetType.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeNewDecimal, mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+	default:
+		return nil
+	}
+	start := op == ast.GT || op == ast.GE
+	rb := &rangeBuilder{sc: er.ctx.GetSessionVars().StmtCtx}
+	point := rb.convertPoint(rangePoint{value: c.Value, start: start, excl: op == ast.GT || op == ast.LT}, col.RetType)
+	if rb.err != nil {
+		return nil
+	}
+	switch {
+	case start && point.excl:
+		op = ast.GT
+	case start:
+		op = ast.GE
+	case point.excl:
+		op = ast.LT
+	default:
+		op = ast.LE
+	}
+	f, err := expression.NewFunction(er.ctx, op, types.NewFieldType(mysql.TypeTiny), col, &expression.Constant{Value: point.value, RetType: col.RetType})
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+func isColumnEquality(l, r expression.Expression, op string) bool {
+	_, lOk := l.(*expression.Column)
+	_, rOk := r.(*expression.Column)
+	return lOk && rOk && op == ast.EQ
+}
+
 // constructBinaryOpFunctions converts (a0,a1,a2) op (b0,b1,b2) to (a0 op b0) and (a1 op b1) and (a2 op b2).
 func (er *expressionRewriter) constructBinaryOpFunction(l expression.Expression, r expression.Expression, op string) (expression.Expression, error) {
 	lLen, rLen := getRowLen(l), getRowLen(r)
 	if lLen == 1 && rLen == 1 {
-		return expression.NewFunction(er.ctx, op, types.NewFieldType(mysql.TypeTiny), l, r)
+		if isColumnEquality(l, r, op) {
+			// the equality stays on the columns, so it can be a join key,
+			// the join converts the keys of mixed types itself
+			return expression.NewFunction(er.ctx, op, types.NewFieldType(mysql.TypeTiny), l, r)
+		}
+		return expression.NewFunction(er.ctx, op, types.NewFieldType(mysql.TypeTiny), expression.CoerceCompareArgs(er.ctx, l, r)...)
 	} else if rLen != lLen {
 		return nil, ErrOperandColumns.GenByArgs(lLen)
 	}
@@ -352,12 +430,8 @@ func (er *expressionRewriter) buildQuantifierPlan(agg *Aggregation, cond, rexpr
 	outerSchemaLen := er.p.GetSchema().Len()
 	er.p = er.b.buildInnerApply(er.p, agg)
 	joinSchema := er.p.GetSchema()
-	proj := &Projection{
-		baseLogicalPlan: newBaseLogicalPlan(Proj, er.b.allocator),
-		Exprs:           expression.Column2Exprs(joinSchema.Clone().Columns[:outerSchemaLen]),
-	}
-	proj.self = proj
-	proj.initIDAndContext(er.ctx)
+	proj := newProjection(er.ctx, er.b.allocator, outerSchemaLen+1)
+	proj.Exprs = append(proj.Exprs, expression.Column2Exprs(joinSchema.Clone().Columns[:outerSchemaLen])...)
 	proj.SetSchema(expression.NewSchema(joinSchema.Clone().Columns[:outerSchemaLen]))
 	proj.Exprs = append(proj.Exprs, cond)
 	proj.schema.Append(&expression.Column{
@@ -584,7 +658,7 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
 		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
 	case *ast.ValueExpr:
-		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
+		value := expression.NewSharedConstant(v.Datum, &v.Type)
 		er.ctxStack = append(er.ctxStack, value)
 	case *ast.ParamMarkerExpr:
 		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
@@ -636,7 +710,7 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 }
 
 func datumToConstant(d types.Datum, tp byte) *expression.Constant {
-	return &expression.Constant{Value: d, RetType: types.NewFieldType(tp)}
+	return expression.NewSharedConstant(d, types.NewFieldType(tp))
 }
 
 func (er *expressionRewriter) rewriteVariable(v *ast.VariableExpr) {
@@ -731,7 +805,24 @@ func (er *expressionRewriter) binaryOpToExpression(v *ast.BinaryOperationExpr) {
 		if er.err != nil {
 			return
 		}
-		function, er.err = expression.NewFunction(er.ctx, v.Op.String(), &v.Type, er.ctxStack[stkLen-2:]...)
+		args := er.ctxStack[stkLen-2:]
+		switch v.Op {
+		case opcode.GT, opcode.GE, opcode.LT, opcode.LE:
+			if lLen == 1 {
+				function = er.columnBoundary(args[0], args[1], v.Op.String())
+				args = expression.CoerceCompareArgs(er.ctx, args...)
+			}
+		}
+		if function == nil {
+			op := v.Op.String()
+			switch v.Op {
+			case opcode.AndAnd:
+				op = ast.AndAnd
+			case opcode.OrOr:
+				op = ast.OrOr
+			}
+			function, er.err = expression.NewFunction(er.ctx, op, &v.Type, args...)
+		}
 	}
 	if er.err != nil {
 		er.err = errors.Trace(er.err)
@@ -910,9 +1001,11 @@ func (er *expressionRewriter) betweenToExpression(v *ast.BetweenExpr) {
 	}
 	var op string
 	var l, r expression.Expression
-	l, er.err = expression.NewFunction(er.ctx, ast.GE, &v.Type, er.ctxStack[stkLen-3], er.ctxStack[stkLen-2])
+	l, er.err = expression.NewFunction(er.ctx, ast.GE, &v.Type,
+		expression.CoerceCompareArgs(er.ctx, er.ctxStack[stkLen-3], er.ctxStack[stkLen-2])...)
 	if er.err == nil {
-		r, er.err = expression.NewFunction(er.ctx, ast.LE, &v.Type, er.ctxStack[stkLen-3].Clone(), er.ctxStack[stkLen-1])
+		r, er.err = expression.NewFunction(er.ctx, ast.LE, &v.Type,
+			expression.CoerceCompareArgs(er.ctx, er.ctxStack[stkLen-3].Clone(), er.ctxStack[stkLen-1])...)
 	}
 	op = ast.AndAnd
 	if er.err != nil {
@@ -951,6 +1044,10 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	if er.err != nil {
 		return
 	}
+	switch v.FnName.L {
+	case ast.Greatest, ast.Least:
+		args = expression.CoerceCompareArgs(er.ctx, args...)
+	}
 	var function expression.Expression
 	function, er.err = expression.NewFunction(er.ctx, v.FnName.L, &v.Type, args...)
 	er.ctxStack = er.ctxStack[:stackLen-len(v.Args)]
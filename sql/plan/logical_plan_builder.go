@@ -15,6 +15,7 @@ package plan
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/expression"
@@ -29,9 +30,21 @@ type idAllocator struct {
 	id int
 }
 
+// sharedIDs are the first ids of all statements, allocated once.
+var sharedIDs = func() []string {
+	ids := make([]string, 256)
+	for i := range ids {
+		ids[i] = "_" + strconv.Itoa(i)
+	}
+	return ids
+}()
+
 func (a *idAllocator) allocID() string {
 	a.id++
-	return fmt.Sprintf("_%d", a.id)
+	if a.id < len(sharedIDs) {
+		return sharedIDs[a.id]
+	}
+	return "_" + strconv.Itoa(a.id)
 }
 
 func (p *Aggregation) collectGroupByColumns() {
@@ -233,9 +246,7 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 func (b *planBuilder) buildSelection(p LogicalPlan, where ast.ExprNode, AggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
 	conditions := splitWhere(where)
 	expressions := make([]expression.Expression, 0, len(conditions))
-	selection := &Selection{baseLogicalPlan: newBaseLogicalPlan(Sel, b.allocator)}
-	selection.self = selection
-	selection.initIDAndContext(b.ctx)
+	selection := newSelection(b.ctx, b.allocator, nil)
 	for _, cond := range conditions {
 		expr, np, err := b.rewrite(cond, p, AggMapper, false)
 		if err != nil {
@@ -249,6 +260,7 @@ func (b *planBuilder) buildSelection(p LogicalPlan, where ast.ExprNode, AggMappe
 		expressions = append(expressions, expression.SplitCNFItems(expr)...)
 	}
 	if len(expressions) == 0 {
+		releaseSelection(selection)
 		return p
 	}
 	selection.Conditions = expressions
@@ -260,12 +272,7 @@ func (b *planBuilder) buildSelection(p LogicalPlan, where ast.ExprNode, AggMappe
 
 // buildProjection returns a Projection plan and non-aux columns length.
 func (b *planBuilder) buildProjection(p LogicalPlan, fields []*ast.SelectField, mapper map[*ast.AggregateFuncExpr]int) (LogicalPlan, int) {
-	proj := &Projection{
-		Exprs:           make([]expression.Expression, 0, len(fields)),
-		baseLogicalPlan: newBaseLogicalPlan(Proj, b.allocator),
-	}
-	proj.self = proj
-	proj.initIDAndContext(b.ctx)
+	proj := newProjection(b.ctx, b.allocator, len(fields))
 	schema := expression.NewSchema(make([]*expression.Column, 0, len(fields)))
 	oldLen := 0
 	for _, field := range fields {
@@ -918,6 +925,7 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 	if schemaName.L == "" {
 		schemaName = model.NewCIStr(b.ctx.GetSessionVars().CurrentDB)
 	}
+	b.visit(mysql.SelectPriv, schemaName.L, tn.Name.L)
 	tbl, err := b.is.TableByName(schemaName, tn.Name)
 	if err != nil {
 		b.err = errors.Trace(err)
@@ -1053,6 +1061,11 @@ func (b *planBuilder) buildSemiJoin(outerPlan, innerPlan LogicalPlan, onConditio
 
 func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	b.inUpdateStmt = true
+	if ts, ok := update.TableRefs.TableRefs.Left.(*ast.TableSource); ok {
+		if tn, ok := ts.Source.(*ast.TableName); ok {
+			b.visit(mysql.UpdatePriv, tn.Schema.L, tn.Name.L)
+		}
+	}
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: update.TableRefs, Where: update.Where, OrderBy: update.Order, Limit: update.Limit}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
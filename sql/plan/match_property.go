@@ -196,12 +196,25 @@ func (p *PhysicalHashJoin) matchProperty(prop *requiredProperty, childPlanInfo .
 	cost := lRes.cost + rRes.cost
 	if p.SmallTable == 1 {
 		cost += lCount + memoryFactor*rCount
+		np.Strategy = p.chooseStrategy(rRes.count)
 	} else {
 		cost += rCount + memoryFactor*lCount
+		np.Strategy = p.chooseStrategy(lRes.count)
 	}
 	return &physicalPlanInfo{p: &np, cost: cost, count: estimateJoinCount(lRes.count, rRes.count)}
 }
 
+// chooseStrategy picks how to run the join, by the estimated rows of the small table.
+func (p *PhysicalHashJoin) chooseStrategy(smallCount uint64) JoinStrategy {
+	if p.JoinType != InnerJoin || len(p.EqualConditions) == 0 {
+		return SortMergeJoin
+	}
+	if smallCount <= BroadcastJoinRowLimit {
+		return BroadcastHashJoin
+	}
+	return PartitionedHashJoin
+}
+
 // matchProperty implements PhysicalPlan matchProperty interface.
 func (p *Union) matchProperty(_ *requiredProperty, childPlanInfo ...*physicalPlanInfo) *physicalPlanInfo {
 	np := *p
@@ -33,15 +33,15 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 		return nil, errors.Trace(err)
 	}
 	allocator := new(idAllocator)
-	builder := &planBuilder{
-		ctx:       ctx,
-		is:        is,
-		colMapper: make(map[*ast.ColumnNameExpr]int),
-		allocator: allocator}
+	builder := newPlanBuilder(ctx, is, allocator)
+	defer releasePlanBuilder(builder)
 	p := builder.build(node)
 	if builder.err != nil {
 		return nil, errors.Trace(builder.err)
 	}
+	if err := checkPrivilege(ctx, builder.visitInfo); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if logic, ok := p.(LogicalPlan); ok {
 		return doOptimize(logic, ctx, allocator)
 	}
@@ -60,7 +60,13 @@ func doOptimize(logic LogicalPlan, ctx context.Context, allocator *idAllocator)
 		alloc: allocator,
 	}
 	solver.aggPushDown(logic)
+	if err = eliminateCommonSubexpressions(logic, ctx, allocator); err != nil {
+		return nil, errors.Trace(err)
+	}
 	logic.PruneColumns(logic.GetSchema().Columns)
+	if err = eliminateProjections(logic); err != nil {
+		return nil, errors.Trace(err)
+	}
 	logic.ResolveIndicesAndCorCols()
 	if !AllowCartesianProduct && existsCartesianProduct(logic) {
 		return nil, errors.Trace(ErrCartesianProductUnsupported)
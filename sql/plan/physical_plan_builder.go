@@ -38,6 +38,62 @@ const (
 // JoinConcurrency means the number of goroutines that participate in joining.
 var JoinConcurrency = 5
 
+// BroadcastJoinRowLimit is the most estimated rows of the small table of an inner
+// join to broadcast it to every shard of the other side. Joins with bigger tables
+// partition both sides by the join keys instead.
+var BroadcastJoinRowLimit uint64 = 1000000
+
+const (
+	// estimatedRowsPerMB converts the table size hints into row counts.
+	estimatedRowsPerMB = 10000
+	// unknownTableRowCount is used for tables registered without a size hint,
+	// large enough that they are not broadcasted.
+	unknownTableRowCount = 100000000
+)
+
+func estimateTableRowCount(table *model.TableInfo) uint64 {
+	if table.SizeInMB > 0 {
+		return uint64(table.SizeInMB) * estimatedRowsPerMB
+	}
+	return unknownTableRowCount
+}
+
+// EstimateRowCount estimates the rows read by the table scans of the plan,
+// from the size hints of their tables. It returns 0 if any table has no hint.
+func EstimateRowCount(p Plan) uint64 {
+	var table *model.TableInfo
+	switch x := p.(type) {
+	case *PhysicalTableScan:
+		table = x.Table
+	case *PhysicalIndexScan:
+		table = x.Table
+	}
+	if table != nil {
+		if table.SizeInMB <= 0 {
+			return 0
+		}
+		return estimateTableRowCount(table)
+	}
+	var count uint64
+	for _, child := range p.GetChildren() {
+		c := EstimateRowCount(child)
+		if c == 0 {
+			return 0
+		}
+		count += c
+	}
+	return count
+}
+
+// pushedConditions returns the conditions of the selection over the data source,
+// which is removed from the physical plan in favor of the scan.
+func (p *DataSource) pushedConditions() []expression.Expression {
+	if sel, ok := p.GetParentByIndex(0).(*Selection); ok {
+		return sel.Conditions
+	}
+	return nil
+}
+
 func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	ts := &PhysicalTableScan{
 		Table:               p.tableInfo,
@@ -50,10 +106,11 @@ func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInf
 	ts.allocator = p.allocator
 	ts.SetSchema(p.GetSchema())
 	ts.initIDAndContext(p.ctx)
+	ts.tableFilterConditions = p.pushedConditions()
 
 	var resultPlan PhysicalPlan
 	resultPlan = ts
-	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: 0}), nil
+	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: estimateTableRowCount(p.tableInfo)}), nil
 }
 
 func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.IndexInfo) (*physicalPlanInfo, error) {
@@ -70,10 +127,11 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 	is.allocator = p.allocator
 	is.initIDAndContext(p.ctx)
 	is.SetSchema(p.schema)
+	is.tableFilterConditions = p.pushedConditions()
 
 	var resultPlan PhysicalPlan
 	resultPlan = is
-	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: 0}), nil
+	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: estimateTableRowCount(p.tableInfo)}), nil
 }
 
 func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexColumn, pkIsHandle bool) bool {
@@ -241,6 +299,42 @@ func convertLimitOffsetToCount(prop *requiredProperty) *requiredProperty {
 	return ret
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *Insert) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	info, err = p.GetChildByIndex(0).(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	info = addPlanToResponse(p, info)
+	p.storePlanInfo(prop, info)
+	return info, nil
+}
+
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *Update) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	info, err = p.GetChildByIndex(0).(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	info = addPlanToResponse(p, info)
+	p.storePlanInfo(prop, info)
+	return info, nil
+}
+
 func limitProperty(limit *Limit) *requiredProperty {
 	return &requiredProperty{limit: limit}
 }
@@ -881,6 +975,7 @@ func (p *Apply) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 			PhysicalJoin: info.p,
 			OuterSchema:  p.corCols,
 		}
+		_, ap.MaxOneRow = p.children[1].(*MaxOneRow)
 		ap.tp = App
 		ap.allocator = p.allocator
 		ap.initIDAndContext(p.ctx)
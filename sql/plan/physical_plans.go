@@ -345,6 +345,35 @@ type PhysicalApply struct {
 
 	PhysicalJoin PhysicalPlan
 	OuterSchema  []*expression.CorrelatedColumn
+	// MaxOneRow is set for scalar subqueries, whose outer rows without
+	// inner rows are kept with a NULL value.
+	MaxOneRow bool
+}
+
+// JoinStrategy is how a PhysicalHashJoin is compiled to gleam.
+type JoinStrategy int
+
+const (
+	// SortMergeJoin partitions and sorts both sides by the join keys.
+	// It is used for outer joins.
+	SortMergeJoin JoinStrategy = iota
+	// BroadcastHashJoin copies the small table to every shard of the
+	// other side, and hashes it there.
+	BroadcastHashJoin
+	// PartitionedHashJoin partitions both sides by the join keys,
+	// and hashes the small table within each shard.
+	PartitionedHashJoin
+)
+
+// String implements fmt.Stringer interface.
+func (s JoinStrategy) String() string {
+	switch s {
+	case BroadcastHashJoin:
+		return "broadcast"
+	case PartitionedHashJoin:
+		return "partitioned"
+	}
+	return "sortMerge"
 }
 
 // PhysicalHashJoin represents hash join for inner/ outer join.
@@ -352,6 +381,7 @@ type PhysicalHashJoin struct {
 	basePlan
 
 	JoinType JoinType
+	Strategy JoinStrategy
 
 	EqualConditions []*expression.ScalarFunction
 	LeftConditions  []expression.Expression
@@ -634,10 +664,11 @@ func (p *PhysicalHashJoin) MarshalJSON() ([]byte, error) {
 			"\"leftCond\": %s,\n "+
 			"\"rightCond\": %s,\n "+
 			"\"otherCond\": %s,\n"+
+			"\"strategy\": \"%s\",\n "+
 			"\"leftPlan\": \"%s\",\n "+
 			"\"rightPlan\": \"%s\""+
 			"}",
-		eqConds, leftConds, rightConds, otherConds, leftChild.GetID(), rightChild.GetID()))
+		eqConds, leftConds, rightConds, otherConds, p.Strategy, leftChild.GetID(), rightChild.GetID()))
 	return buffer.Bytes(), nil
 }
 
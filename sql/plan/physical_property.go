@@ -0,0 +1,139 @@
+package plan
+
+import (
+	"github.com/lovelly/gleam/sql/expression"
+)
+
+// PhysicalProperty is how the rows of a physical plan are laid out in the
+// shards of its gleam dataset. An operator requires properties of its
+// children, e.g. a partitioned join requires both sides to be partitioned by
+// the join keys, and only needs to shuffle or sort the children that do not
+// deliver them already.
+type PhysicalProperty struct {
+	// PartitionedBy are the columns whose hash chooses the shard of a row.
+	PartitionedBy []*expression.Column
+	// SortedBy are the columns the rows of each shard are sorted by, ascending.
+	SortedBy []*expression.Column
+}
+
+// IsPartitionedBy checks whether the rows are partitioned by the columns, in
+// the same order, since the hash of the keys depends on their order.
+func (prop PhysicalProperty) IsPartitionedBy(cols []*expression.Column) bool {
+	return len(cols) > 0 && columnsEqual(prop.PartitionedBy, cols)
+}
+
+// IsSortedBy checks whether the rows of each shard are sorted by the columns.
+func (prop PhysicalProperty) IsSortedBy(cols []*expression.Column) bool {
+	return len(cols) > 0 && len(cols) <= len(prop.SortedBy) && columnsEqual(prop.SortedBy[:len(cols)], cols)
+}
+
+func columnsEqual(a, b []*expression.Column) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i], nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeliveredProperty returns the layout of the rows of the physical plan, as
+// compiled to gleam by the executors.
+func DeliveredProperty(p PhysicalPlan) PhysicalProperty {
+	switch x := p.(type) {
+	case *Selection, *PhysicalUnionScan:
+		return DeliveredProperty(x.GetChildByIndex(0).(PhysicalPlan))
+	case *Projection:
+		prop := DeliveredProperty(x.GetChildByIndex(0).(PhysicalPlan))
+		partitionedBy := x.projectColumns(prop.PartitionedBy)
+		if len(partitionedBy) < len(prop.PartitionedBy) {
+			// the rows are not partitioned by a part of the keys
+			partitionedBy = nil
+		}
+		return PhysicalProperty{PartitionedBy: partitionedBy, SortedBy: x.projectColumns(prop.SortedBy)}
+	case *PhysicalHashJoin:
+		leftKeys, rightKeys := joinKeyColumns(x.EqualConditions, x.GetChildByIndex(0).GetSchema())
+		keys := leftKeys
+		if x.JoinType == RightOuterJoin {
+			// the left keys of unmatched right rows are null
+			keys = rightKeys
+		}
+		switch x.Strategy {
+		case SortMergeJoin:
+			return PhysicalProperty{PartitionedBy: keys, SortedBy: keys}
+		case PartitionedHashJoin:
+			return PhysicalProperty{PartitionedBy: keys}
+		case BroadcastHashJoin:
+			// the rows of the bigger side stay in their shards and order
+			return DeliveredProperty(x.GetChildByIndex(1 - x.SmallTable).(PhysicalPlan))
+		}
+	case *PhysicalHashSemiJoin:
+		outerKeys, _ := joinKeyColumns(x.EqualConditions, x.GetChildByIndex(0).GetSchema())
+		return PhysicalProperty{PartitionedBy: outerKeys}
+	}
+	return PhysicalProperty{}
+}
+
+// RequiredProperties returns the layouts the join needs of its children,
+// which are empty if the join does not need any.
+func (p *PhysicalHashJoin) RequiredProperties() (left, right PhysicalProperty) {
+	leftKeys, rightKeys := joinKeyColumns(p.EqualConditions, p.GetChildByIndex(0).GetSchema())
+	switch p.Strategy {
+	case SortMergeJoin:
+		return PhysicalProperty{PartitionedBy: leftKeys, SortedBy: leftKeys},
+			PhysicalProperty{PartitionedBy: rightKeys, SortedBy: rightKeys}
+	case PartitionedHashJoin:
+		return PhysicalProperty{PartitionedBy: leftKeys}, PhysicalProperty{PartitionedBy: rightKeys}
+	}
+	return PhysicalProperty{}, PhysicalProperty{}
+}
+
+// RequiredProperties returns the layouts the semi join needs of its children.
+func (p *PhysicalHashSemiJoin) RequiredProperties() (outer, inner PhysicalProperty) {
+	outerKeys, innerKeys := joinKeyColumns(p.EqualConditions, p.GetChildByIndex(0).GetSchema())
+	return PhysicalProperty{PartitionedBy: outerKeys}, PhysicalProperty{PartitionedBy: innerKeys}
+}
+
+// joinKeyColumns returns the columns of the equal conditions on each side.
+// The columns of a condition can be in either order.
+func joinKeyColumns(eqConds []*expression.ScalarFunction, leftSchema expression.Schema) (leftKeys, rightKeys []*expression.Column) {
+	for _, eq := range eqConds {
+		args := eq.GetArgs()
+		if len(args) != 2 {
+			return nil, nil
+		}
+		l, lOk := args[0].(*expression.Column)
+		r, rOk := args[1].(*expression.Column)
+		if !lOk || !rOk {
+			return nil, nil
+		}
+		if leftSchema.GetColumnIndex(l) == -1 {
+			l, r = r, l
+		}
+		leftKeys = append(leftKeys, l)
+		rightKeys = append(rightKeys, r)
+	}
+	return leftKeys, rightKeys
+}
+
+// projectColumns maps the columns of the child to the output columns passing
+// them through, and returns the leading columns that are passed through.
+func (p *Projection) projectColumns(cols []*expression.Column) []*expression.Column {
+	var ret []*expression.Column
+	for _, col := range cols {
+		found := false
+		for i, expr := range p.Exprs {
+			if c, ok := expr.(*expression.Column); ok && c.Equal(col, nil) {
+				ret = append(ret, p.schema.Columns[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return ret
+}
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/lovelly/gleam/sql/context"
 	"github.com/lovelly/gleam/sql/expression"
@@ -30,6 +31,10 @@ const (
 	Sel = "Selection"
 	// St is the type of Set.
 	St = "Set"
+	// Sh is the type of Show.
+	Sh = "Show"
+	// Smp is the type of Simple.
+	Smp = "Simple"
 	// Proj is the type of Projection.
 	Proj = "Projection"
 	// Agg is the type of Aggregation.
@@ -311,6 +316,16 @@ func (p *basePlan) initIDAndContext(ctx context.Context) {
 	p.ctx = ctx
 }
 
+// initFromPB restores the id of a plan decoded by PBToPlan.
+func (p *basePlan) initFromPB(id string, ctx context.Context, allocator *idAllocator) {
+	p.id, p.tp = id, id
+	if i := strings.LastIndex(id, "_"); i >= 0 {
+		p.tp = id[:i]
+	}
+	p.allocator = allocator
+	p.ctx = ctx
+}
+
 // basePlan implements base Plan interface.
 // Should be used as embedded struct in Plan implementations.
 type basePlan struct {
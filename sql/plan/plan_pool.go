@@ -0,0 +1,125 @@
+package plan
+
+import (
+	"sync"
+
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+)
+
+// The Selections and Projections removed while building and optimizing the
+// plans, e.g. the Selections pushed down to the tables and the Projections
+// merged by eliminateProjections, go back to these pools for the next
+// statements, with the maps of their physical plans and the expression
+// slices of the Projections. The nodes left in the plans are not released,
+// since the plans are cached and run.
+var (
+	selectionPool = sync.Pool{
+		New: func() interface{} {
+			return &Selection{}
+		},
+	}
+	projectionPool = sync.Pool{
+		New: func() interface{} {
+			return &Projection{}
+		},
+	}
+)
+
+// maxPooledExprs is the largest expression slice kept by the pooled
+// Projections, so that a huge select list does not keep its memory.
+const maxPooledExprs = 64
+
+// newSelection gets a Selection of the conditions from the pool.
+func newSelection(ctx context.Context, allocator *idAllocator, conditions []expression.Expression) *Selection {
+	p := selectionPool.Get().(*Selection)
+	p.baseLogicalPlan = reuseBaseLogicalPlan(p.planMap, Sel, allocator)
+	p.Conditions = conditions
+	p.self = p
+	p.initIDAndContext(ctx)
+	return p
+}
+
+// releaseSelection puts the Selection removed from the plan back to the pool.
+// Its conditions are not reused, since they are pushed down to its children.
+func releaseSelection(p *Selection) {
+	planMap := clearPlanMap(p.planMap)
+	*p = Selection{}
+	p.planMap = planMap
+	selectionPool.Put(p)
+}
+
+// newProjection gets a Projection from the pool, with room for size expressions.
+func newProjection(ctx context.Context, allocator *idAllocator, size int) *Projection {
+	p := projectionPool.Get().(*Projection)
+	p.baseLogicalPlan = reuseBaseLogicalPlan(p.planMap, Proj, allocator)
+	if cap(p.Exprs) < size {
+		p.Exprs = make([]expression.Expression, 0, size)
+	}
+	p.self = p
+	p.initIDAndContext(ctx)
+	return p
+}
+
+// releaseProjection puts the Projection removed from the plan back to the
+// pool. Its expressions are substituted into its parent, which does not keep
+// the slice, so the slice is reused too.
+func releaseProjection(p *Projection) {
+	planMap := clearPlanMap(p.planMap)
+	exprs := p.Exprs[:cap(p.Exprs)]
+	if len(exprs) > maxPooledExprs {
+		exprs = nil
+	}
+	for i := range exprs {
+		exprs[i] = nil
+	}
+	*p = Projection{Exprs: exprs[:0]}
+	p.planMap = planMap
+	projectionPool.Put(p)
+}
+
+// reuseBaseLogicalPlan is newBaseLogicalPlan keeping the map of a pooled plan.
+func reuseBaseLogicalPlan(planMap map[string]*physicalPlanInfo, tp string, a *idAllocator) baseLogicalPlan {
+	if planMap == nil {
+		return newBaseLogicalPlan(tp, a)
+	}
+	return baseLogicalPlan{
+		planMap: planMap,
+		basePlan: basePlan{
+			tp:        tp,
+			allocator: a,
+		},
+	}
+}
+
+func clearPlanMap(planMap map[string]*physicalPlanInfo) map[string]*physicalPlanInfo {
+	for k := range planMap {
+		delete(planMap, k)
+	}
+	return planMap
+}
+
+// usedColumnsPool reuses the slices of the columns used by the plans, which
+// their children only read while pruning their columns.
+var usedColumnsPool = sync.Pool{
+	New: func() interface{} {
+		return new([]*expression.Column)
+	},
+}
+
+func newUsedColumns() *[]*expression.Column {
+	return usedColumnsPool.Get().(*[]*expression.Column)
+}
+
+func releaseUsedColumns(cols *[]*expression.Column) {
+	if cap(*cols) > maxPooledExprs {
+		return
+	}
+	// the children may have appended their columns beyond the length
+	all := (*cols)[:cap(*cols)]
+	for i := range all {
+		all[i] = nil
+	}
+	*cols = all[:0]
+	usedColumnsPool.Put(cols)
+}
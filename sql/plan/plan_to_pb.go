@@ -0,0 +1,356 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql/context"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/infoschema"
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/util/codec"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+// ToPB converts the physical plan to protobuf, so that fragments of it can be
+// shipped to the executors of a distributed flow, e.g. to evaluate the
+// conditions of a Selection or the expressions of a Projection there.
+// The columns keep their resolved indexes in the rows of the children.
+// PBToPlan converts it back.
+func ToPB(p PhysicalPlan) (*pb.SqlPlan, error) {
+	ret, err := nodeToPB(p)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, child := range p.GetChildren() {
+		c, err := ToPB(child.(PhysicalPlan))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ret.Children = append(ret.Children, c)
+	}
+	return ret, nil
+}
+
+// nodeToPB converts the plan without its children.
+func nodeToPB(p PhysicalPlan) (*pb.SqlPlan, error) {
+	ret := &pb.SqlPlan{
+		Id:     p.GetID(),
+		Schema: expression.SchemaToPB(p.GetSchema()),
+	}
+	var err error
+	switch x := p.(type) {
+	case *PhysicalTableScan:
+		ret.Type = "TableScan"
+		ret.DbName, ret.TableName = x.DBName.O, x.Table.Name.O
+		if x.TableAsName != nil {
+			ret.TableAsName = x.TableAsName.O
+		}
+		ret.Desc, ret.KeepOrder = x.Desc, x.KeepOrder
+		ret.Conditions, err = expression.ExprsToPB(append(append([]expression.Expression{}, x.AccessCondition...), x.tableFilterConditions...))
+	case *PhysicalDummyScan:
+		ret.Type = "DummyScan"
+	case *PhysicalUnionScan:
+		ret.Type = "UnionScan"
+		if x.Condition != nil {
+			ret.Conditions, err = expression.ExprsToPB([]expression.Expression{x.Condition})
+		}
+	case *Selection:
+		ret.Type = "Selection"
+		ret.Conditions, err = expression.ExprsToPB(x.Conditions)
+	case *Projection:
+		ret.Type = "Projection"
+		ret.Exprs, err = expression.ExprsToPB(x.Exprs)
+	case *PhysicalAggregation:
+		ret.Type = "Aggregation"
+		ret.AggType, ret.HasGby = int32(x.AggType), x.HasGby
+		if ret.Exprs, err = expression.ExprsToPB(x.GroupByItems); err == nil {
+			ret.AggFuncs, err = aggFuncsToPB(x.AggFuncs)
+		}
+	case *PhysicalHashJoin:
+		ret.Type = "HashJoin"
+		ret.JoinType = int32(x.JoinType)
+		ret.Strategy, ret.SmallTable, ret.Concurrency = int32(x.Strategy), int32(x.SmallTable), int32(x.Concurrency)
+		if len(x.DefaultValues) > 0 {
+			if ret.DefaultValues, err = codec.EncodeValue(nil, x.DefaultValues...); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		err = joinConditionsToPB(ret, x.EqualConditions, x.LeftConditions, x.RightConditions, x.OtherConditions)
+	case *PhysicalHashSemiJoin:
+		ret.Type = "HashSemiJoin"
+		ret.Anti, ret.WithAux = x.Anti, x.WithAux
+		err = joinConditionsToPB(ret, x.EqualConditions, x.LeftConditions, x.RightConditions, x.OtherConditions)
+	case *Limit:
+		ret.Type = "Limit"
+		ret.Offset, ret.Count = x.Offset, x.Count
+	case *Sort:
+		ret.Type = "Sort"
+		if x.ExecLimit != nil {
+			ret.HasLimit = true
+			ret.Offset, ret.Count = x.ExecLimit.Offset, x.ExecLimit.Count
+		}
+		for _, item := range x.ByItems {
+			expr, err := expression.ExprToPB(item.Expr)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ret.ByItems = append(ret.ByItems, &pb.SqlByItem{Expr: expr, Desc: item.Desc})
+		}
+	case *Trim:
+		ret.Type = "Trim"
+	case *Union:
+		ret.Type = "Union"
+	case *Exists:
+		ret.Type = "Exists"
+	case *MaxOneRow:
+		ret.Type = "MaxOneRow"
+	case *Cache:
+		ret.Type = "Cache"
+	case *PhysicalApply:
+		ret.Type = "Apply"
+		ret.MaxOneRow = x.MaxOneRow
+		for _, col := range x.OuterSchema {
+			ret.OuterSchema = append(ret.OuterSchema, expression.ColumnToPB(&col.Column))
+		}
+		// the children of the join are the ones of the apply
+		ret.ApplyJoin, err = nodeToPB(x.PhysicalJoin)
+	default:
+		return nil, errors.Errorf("plan %T can not be serialized", p)
+	}
+	return ret, errors.Trace(err)
+}
+
+func joinConditionsToPB(ret *pb.SqlPlan, eqConds []*expression.ScalarFunction, leftConds, rightConds, otherConds []expression.Expression) error {
+	var err error
+	for _, eq := range eqConds {
+		e, err := expression.ExprToPB(eq)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ret.EqualConditions = append(ret.EqualConditions, e)
+	}
+	if ret.LeftConditions, err = expression.ExprsToPB(leftConds); err != nil {
+		return errors.Trace(err)
+	}
+	if ret.RightConditions, err = expression.ExprsToPB(rightConds); err != nil {
+		return errors.Trace(err)
+	}
+	ret.Conditions, err = expression.ExprsToPB(otherConds)
+	return errors.Trace(err)
+}
+
+func aggFuncsToPB(aggFuncs []expression.AggregationFunction) ([]*pb.SqlAggFunc, error) {
+	var ret []*pb.SqlAggFunc
+	for _, f := range aggFuncs {
+		args, err := expression.ExprsToPB(f.GetArgs())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ret = append(ret, &pb.SqlAggFunc{Name: f.GetName(), Args: args, Distinct: f.IsDistinct(), Mode: int32(f.GetMode())})
+	}
+	return ret, nil
+}
+
+// PBToPlan converts the protobuf of ToPB back to the physical plan. The
+// tables of its scans are looked up in the info schema.
+func PBToPlan(sp *pb.SqlPlan, ctx context.Context, is infoschema.InfoSchema) (PhysicalPlan, error) {
+	d := &planDecoder{ctx: ctx, is: is, allocator: new(idAllocator)}
+	return d.toPlan(sp)
+}
+
+type planDecoder struct {
+	ctx       context.Context
+	is        infoschema.InfoSchema
+	allocator *idAllocator
+}
+
+func (d *planDecoder) toPlan(sp *pb.SqlPlan) (PhysicalPlan, error) {
+	p, err := d.node(sp)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, c := range sp.Children {
+		child, err := d.toPlan(c)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		addChild(p, child)
+	}
+	if ap, ok := p.(*PhysicalApply); ok {
+		ap.PhysicalJoin.SetChildren(ap.GetChildren()...)
+		// the correlated columns of the inner plan read the outer rows
+		// through the data of the outer schema
+		for _, corCol := range ap.PhysicalJoin.extractCorrelatedCols() {
+			for _, outer := range ap.OuterSchema {
+				if outer.Column.Equal(&corCol.Column, d.ctx) {
+					corCol.Data = outer.Data
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// node converts the plan without its children.
+func (d *planDecoder) node(sp *pb.SqlPlan) (PhysicalPlan, error) {
+	var p PhysicalPlan
+	var err error
+	switch sp.Type {
+	case "TableScan":
+		p, err = d.tableScan(sp)
+	case "DummyScan":
+		p = &PhysicalDummyScan{}
+	case "UnionScan":
+		us := &PhysicalUnionScan{}
+		var conds []expression.Expression
+		if conds, err = expression.PBToExprs(sp.Conditions, d.ctx); len(conds) > 0 {
+			us.Condition = conds[0]
+		}
+		p = us
+	case "Selection":
+		sel := &Selection{}
+		sel.Conditions, err = expression.PBToExprs(sp.Conditions, d.ctx)
+		p = sel
+	case "Projection":
+		proj := &Projection{}
+		proj.Exprs, err = expression.PBToExprs(sp.Exprs, d.ctx)
+		p = proj
+	case "Aggregation":
+		agg := &PhysicalAggregation{AggType: AggregationType(sp.AggType), HasGby: sp.HasGby}
+		if agg.GroupByItems, err = expression.PBToExprs(sp.Exprs, d.ctx); err == nil {
+			agg.AggFuncs, err = d.aggFuncs(sp.AggFuncs)
+		}
+		p = agg
+	case "HashJoin":
+		join := &PhysicalHashJoin{
+			JoinType:    JoinType(sp.JoinType),
+			Strategy:    JoinStrategy(sp.Strategy),
+			SmallTable:  int(sp.SmallTable),
+			Concurrency: int(sp.Concurrency),
+		}
+		if len(sp.DefaultValues) > 0 {
+			if join.DefaultValues, err = codec.Decode(sp.DefaultValues, 1); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		join.EqualConditions, join.LeftConditions, join.RightConditions, join.OtherConditions, err = d.joinConditions(sp)
+		p = join
+	case "HashSemiJoin":
+		join := &PhysicalHashSemiJoin{Anti: sp.Anti, WithAux: sp.WithAux}
+		join.EqualConditions, join.LeftConditions, join.RightConditions, join.OtherConditions, err = d.joinConditions(sp)
+		p = join
+	case "Limit":
+		p = &Limit{Offset: sp.Offset, Count: sp.Count}
+	case "Sort":
+		sort := &Sort{}
+		if sp.HasLimit {
+			sort.ExecLimit = &Limit{Offset: sp.Offset, Count: sp.Count}
+		}
+		for _, item := range sp.ByItems {
+			expr, err := expression.PBToExpr(item.Expr, d.ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			sort.ByItems = append(sort.ByItems, &ByItems{Expr: expr, Desc: item.Desc})
+		}
+		p = sort
+	case "Trim":
+		p = &Trim{}
+	case "Union":
+		p = &Union{}
+	case "Exists":
+		p = &Exists{}
+	case "MaxOneRow":
+		p = &MaxOneRow{}
+	case "Cache":
+		p = &Cache{}
+	case "Apply":
+		if sp.ApplyJoin == nil {
+			return nil, errors.Errorf("apply %s without a join", sp.Id)
+		}
+		ap := &PhysicalApply{MaxOneRow: sp.MaxOneRow}
+		for _, c := range sp.OuterSchema {
+			ap.OuterSchema = append(ap.OuterSchema, &expression.CorrelatedColumn{Column: *expression.PBToColumn(c), Data: new(types.Datum)})
+		}
+		ap.PhysicalJoin, err = d.node(sp.ApplyJoin)
+		p = ap
+	default:
+		return nil, errors.Errorf("unknown plan type %s", sp.Type)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	p.(interface {
+		initFromPB(string, context.Context, *idAllocator)
+	}).initFromPB(sp.Id, d.ctx, d.allocator)
+	p.SetSchema(expression.PBToSchema(sp.Schema))
+	return p, nil
+}
+
+func (d *planDecoder) tableScan(sp *pb.SqlPlan) (*PhysicalTableScan, error) {
+	dbName, tblName := model.NewCIStr(sp.DbName), model.NewCIStr(sp.TableName)
+	tbl, err := d.is.TableByName(dbName, tblName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ts := &PhysicalTableScan{
+		Table:     tbl.Meta(),
+		DBName:    &dbName,
+		Desc:      sp.Desc,
+		KeepOrder: sp.KeepOrder,
+	}
+	if sp.TableAsName != "" {
+		asName := model.NewCIStr(sp.TableAsName)
+		ts.TableAsName = &asName
+	}
+	for _, col := range sp.Schema {
+		for _, c := range ts.Table.Columns {
+			if c.Name.L == strings.ToLower(col.ColName) {
+				ts.Columns = append(ts.Columns, c)
+				break
+			}
+		}
+	}
+	// the access conditions were serialized with the filters, and are
+	// evaluated as filters
+	ts.tableFilterConditions, err = expression.PBToExprs(sp.Conditions, d.ctx)
+	return ts, errors.Trace(err)
+}
+
+func (d *planDecoder) joinConditions(sp *pb.SqlPlan) (eqConds []*expression.ScalarFunction, leftConds, rightConds, otherConds []expression.Expression, err error) {
+	for _, e := range sp.EqualConditions {
+		expr, err := expression.PBToExpr(e, d.ctx)
+		if err != nil {
+			return nil, nil, nil, nil, errors.Trace(err)
+		}
+		eq, ok := expr.(*expression.ScalarFunction)
+		if !ok {
+			return nil, nil, nil, nil, errors.Errorf("equal condition %s is not a function", expr)
+		}
+		eqConds = append(eqConds, eq)
+	}
+	if leftConds, err = expression.PBToExprs(sp.LeftConditions, d.ctx); err != nil {
+		return nil, nil, nil, nil, errors.Trace(err)
+	}
+	if rightConds, err = expression.PBToExprs(sp.RightConditions, d.ctx); err != nil {
+		return nil, nil, nil, nil, errors.Trace(err)
+	}
+	otherConds, err = expression.PBToExprs(sp.Conditions, d.ctx)
+	return eqConds, leftConds, rightConds, otherConds, errors.Trace(err)
+}
+
+func (d *planDecoder) aggFuncs(fs []*pb.SqlAggFunc) ([]expression.AggregationFunction, error) {
+	var ret []expression.AggregationFunction
+	for _, f := range fs {
+		args, err := expression.PBToExprs(f.Args, d.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		aggFunc := expression.NewAggFunction(f.Name, args, f.Distinct)
+		aggFunc.SetMode(expression.AggFunctionMode(f.Mode))
+		ret = append(ret, aggFunc)
+	}
+	return ret, nil
+}
@@ -15,6 +15,8 @@ package plan
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/context"
@@ -23,6 +25,7 @@ import (
 	"github.com/lovelly/gleam/sql/model"
 	"github.com/lovelly/gleam/sql/mysql"
 	"github.com/lovelly/gleam/sql/parser/opcode"
+	"github.com/lovelly/gleam/sql/privilege"
 	"github.com/lovelly/gleam/sql/table"
 	"github.com/lovelly/gleam/sql/terror"
 	"github.com/lovelly/gleam/sql/util/types"
@@ -36,6 +39,7 @@ var (
 	ErrUnknownColumn        = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
 	ErrWrongArguments       = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
 	ErrAmbiguous            = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
+	ErrWrongValueCount      = terror.ClassOptimizerPlan.New(CodeWrongValueCount, "Column count doesn't match value count at row %d")
 )
 
 // Error codes.
@@ -44,14 +48,16 @@ const (
 	SystemInternalError terror.ErrCode = 2
 	CodeAmbiguous       terror.ErrCode = 1052
 	CodeUnknownColumn   terror.ErrCode = 1054
+	CodeWrongValueCount terror.ErrCode = 1136
 	CodeWrongArguments  terror.ErrCode = 1210
 )
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeUnknownColumn:  mysql.ErrBadField,
-		CodeAmbiguous:      mysql.ErrNonUniq,
-		CodeWrongArguments: mysql.ErrWrongArguments,
+		CodeUnknownColumn:   mysql.ErrBadField,
+		CodeAmbiguous:       mysql.ErrNonUniq,
+		CodeWrongArguments:  mysql.ErrWrongArguments,
+		CodeWrongValueCount: mysql.ErrWrongValueCountOnRow,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -69,6 +75,81 @@ type planBuilder struct {
 	inUpdateStmt bool
 	// colMapper stores the column that must be pre-resolved.
 	colMapper map[*ast.ColumnNameExpr]int
+	// visitInfo lists the privileges that the statement needs.
+	visitInfo []visitInfo
+}
+
+// planBuilderPool reuses the planBuilders, with their map and slices, since
+// the MySQL protocol frontend plans thousands of small statements per second.
+var planBuilderPool = sync.Pool{
+	New: func() interface{} {
+		return &planBuilder{colMapper: make(map[*ast.ColumnNameExpr]int)}
+	},
+}
+
+// newPlanBuilder gets a planBuilder from the pool. Release it with
+// releasePlanBuilder once the statement is built.
+func newPlanBuilder(ctx context.Context, is infoschema.InfoSchema, allocator *idAllocator) *planBuilder {
+	b := planBuilderPool.Get().(*planBuilder)
+	b.ctx, b.is, b.allocator = ctx, is, allocator
+	return b
+}
+
+// releasePlanBuilder resets the builder and puts it back to the pool. The
+// allocator is not reused, since the plans keep it.
+func releasePlanBuilder(b *planBuilder) {
+	b.reset()
+	planBuilderPool.Put(b)
+}
+
+// reset clears the builder for the next statement, keeping the memory of
+// its map and slices.
+func (b *planBuilder) reset() {
+	for k := range b.colMapper {
+		delete(b.colMapper, k)
+	}
+	for i := range b.outerSchemas {
+		b.outerSchemas[i] = expression.Schema{}
+	}
+	*b = planBuilder{
+		colMapper:    b.colMapper,
+		outerSchemas: b.outerSchemas[:0],
+		visitInfo:    b.visitInfo[:0],
+	}
+}
+
+// visitInfo is a privilege on a table, or a global privilege without a table.
+type visitInfo struct {
+	privilege mysql.PrivilegeType
+	db        string
+	table     string
+}
+
+func (b *planBuilder) visit(priv mysql.PrivilegeType, dbName, tableName string) {
+	if dbName == "" && tableName != "" {
+		dbName = b.ctx.GetSessionVars().CurrentDB
+	}
+	b.visitInfo = append(b.visitInfo, visitInfo{privilege: priv, db: dbName, table: tableName})
+}
+
+// checkPrivilege checks that the user of the session has the privileges.
+// Sessions without a user have all privileges.
+func checkPrivilege(ctx context.Context, vs []visitInfo) error {
+	user := ctx.GetSessionVars().User
+	if user == "" {
+		return nil
+	}
+	for _, v := range vs {
+		if privilege.Check(user, v.privilege, v.db, v.table) {
+			continue
+		}
+		if v.table == "" {
+			return privilege.ErrSpecificAccessDenied.GenByArgs(strings.ToUpper(mysql.Priv2Str[v.privilege]))
+		}
+		return privilege.ErrTableAccessDenied.GenByArgs(strings.ToUpper(mysql.Priv2Str[v.privilege]),
+			privilege.AccountName(user), "%", v.table)
+	}
+	return nil
 }
 
 func (b *planBuilder) build(node ast.Node) Plan {
@@ -79,6 +160,27 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildUnion(x)
 	case *ast.SetStmt:
 		return b.buildSet(x)
+	case *ast.ShowStmt:
+		return b.buildShow(x)
+	case *ast.InsertStmt:
+		return b.buildInsert(x)
+	case *ast.LoadDataStmt:
+		return b.buildLoadData(x)
+	case *ast.UpdateStmt:
+		return b.buildUpdate(x)
+	case *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt:
+		return b.buildSimple(node.(ast.StmtNode))
+	case *ast.UseStmt:
+		return b.buildSimple(x)
+	case *ast.CreateDatabaseStmt:
+		b.visit(mysql.CreatePriv, x.Name, "")
+		return b.buildSimple(x)
+	case *ast.CreateUserStmt, *ast.DropUserStmt:
+		b.visit(mysql.CreateUserPriv, "", "")
+		return b.buildSimple(node.(ast.StmtNode))
+	case *ast.GrantStmt:
+		b.visitGrant(x)
+		return b.buildSimple(x)
 	}
 	b.err = ErrUnsupportedType.Gen("Unsupported type %T", node)
 	return nil
@@ -89,6 +191,9 @@ func (b *planBuilder) buildSet(v *ast.SetStmt) Plan {
 	p.tp = St
 	p.allocator = b.allocator
 	for _, vars := range v.Variables {
+		if vars.IsGlobal {
+			b.visit(mysql.SuperPriv, "", "")
+		}
 		assign := &expression.VarAssignment{
 			Name:     vars.Name,
 			IsGlobal: vars.IsGlobal,
@@ -285,6 +390,143 @@ func (b *planBuilder) findDefaultValue(cols []*table.Column, name *ast.ColumnNam
 	return nil, ErrUnknownColumn.GenByArgs(name.Name.O, "field_list")
 }
 
+// buildInsert builds INSERT INTO t [(cols)] SELECT ..., whose rows are written to
+// the sink of the registered table t.
+func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
+	if insert.Select == nil || insert.IsReplace || len(insert.OnDuplicate) > 0 {
+		b.err = ErrUnsupportedType.Gen("Unsupported INSERT, only INSERT ... SELECT is supported")
+		return nil
+	}
+	ts, ok := insert.Table.TableRefs.Left.(*ast.TableSource)
+	if !ok {
+		b.err = infoschema.ErrTableNotExists.GenByArgs()
+		return nil
+	}
+	tn, ok := ts.Source.(*ast.TableName)
+	if !ok {
+		b.err = infoschema.ErrTableNotExists.GenByArgs()
+		return nil
+	}
+	table, err := b.is.TableByName(tn.Schema, tn.Name)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	b.visit(mysql.InsertPriv, tn.Schema.L, tn.Name.L)
+	insertPlan := &Insert{
+		DBName:          tn.Schema,
+		Table:           table,
+		Columns:         insert.Columns,
+		Priority:        insert.Priority,
+		Ignore:          insert.Ignore,
+		baseLogicalPlan: newBaseLogicalPlan(Ins, b.allocator),
+	}
+	insertPlan.self = insertPlan
+	insertPlan.initIDAndContext(b.ctx)
+
+	selectPlan := b.build(insert.Select)
+	if b.err != nil {
+		return nil
+	}
+	columnCount := len(insert.Columns)
+	if columnCount == 0 {
+		columnCount = len(table.Meta().Columns)
+	}
+	if selectPlan.GetSchema().Len() != columnCount {
+		b.err = ErrWrongValueCount.GenByArgs(1)
+		return nil
+	}
+	addChild(insertPlan, selectPlan)
+	insertPlan.SetSchema(expression.NewSchema(nil))
+	return insertPlan
+}
+
+// visitGrant needs the granted privileges, and the grant option, on the
+// objects of the grant.
+func (b *planBuilder) visitGrant(grant *ast.GrantStmt) {
+	dbName, tableName := GrantObject(b.ctx, grant.Level)
+	if dbName == "*" {
+		dbName, tableName = "", ""
+	}
+	b.visit(mysql.GrantPriv, dbName, tableName)
+	for _, priv := range grant.Privs {
+		b.visit(priv.Priv, dbName, tableName)
+	}
+}
+
+// GrantObject returns the database and the table of the grant level, "*" for all.
+func GrantObject(ctx context.Context, level *ast.GrantLevel) (dbName, tableName string) {
+	dbName = level.DBName
+	if dbName == "" {
+		dbName = ctx.GetSessionVars().CurrentDB
+	}
+	switch level.Level {
+	case ast.GrantLevelGlobal:
+		return "*", "*"
+	case ast.GrantLevelDB:
+		return dbName, "*"
+	}
+	return dbName, level.TableName
+}
+
+func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
+	p := &Simple{Statement: node}
+	p.tp = Smp
+	p.allocator = b.allocator
+	p.initIDAndContext(b.ctx)
+	p.SetSchema(expression.NewSchema(nil))
+	return p
+}
+
+func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
+	b.visit(mysql.InsertPriv, ld.Table.Schema.L, ld.Table.Name.L)
+	b.visit(mysql.FilePriv, "", "")
+	p := &LoadData{
+		IsLocal:    ld.IsLocal,
+		Path:       ld.Path,
+		Table:      ld.Table,
+		FieldsInfo: ld.FieldsInfo,
+		LinesInfo:  ld.LinesInfo,
+	}
+	p.tp = Load
+	p.allocator = b.allocator
+	p.initIDAndContext(b.ctx)
+	p.SetSchema(expression.NewSchema(nil))
+	return p
+}
+
+func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
+	p := &Show{
+		Tp:              show.Tp,
+		DBName:          show.DBName,
+		Table:           show.Table,
+		Column:          show.Column,
+		Flag:            show.Flag,
+		Full:            show.Full,
+		User:            show.User,
+		GlobalScope:     show.GlobalScope,
+		baseLogicalPlan: newBaseLogicalPlan(Sh, b.allocator),
+	}
+	p.self = p
+	p.initIDAndContext(b.ctx)
+	p.SetSchema(buildShowSchema(show))
+	return p
+}
+
+// buildShowSchema builds the result columns of a show statement.
+func buildShowSchema(show *ast.ShowStmt) expression.Schema {
+	names, ftypes := getShowColNamesAndTypes(show)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(names)))
+	for i, name := range names {
+		tp := mysql.TypeVarchar
+		if len(ftypes) > i && ftypes[i] != mysql.TypeUnspecified {
+			tp = ftypes[i]
+		}
+		schema.Append(buildColumn("", name, tp, 0))
+	}
+	return schema
+}
+
 // getShowColNamesAndTypes gets column names and types. If the `ftypes` is empty, every column is set to varchar type.
 func getShowColNamesAndTypes(s *ast.ShowStmt) (names []string, ftypes []byte) {
 	switch s.Tp {
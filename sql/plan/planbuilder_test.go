@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestReleasePlanBuilder(t *testing.T) {
+	allocator := new(idAllocator)
+	b := newPlanBuilder(nil, nil, allocator)
+	b.colMapper[&ast.ColumnNameExpr{}] = 1
+	b.outerSchemas = append(b.outerSchemas, expression.NewSchema(nil))
+	b.visit(mysql.SelectPriv, "db", "t")
+	b.hasAgg = true
+	releasePlanBuilder(b)
+
+	if len(b.colMapper) != 0 || len(b.outerSchemas) != 0 || len(b.visitInfo) != 0 {
+		t.Errorf("released builder keeps %v %v %v", b.colMapper, b.outerSchemas, b.visitInfo)
+	}
+	if b.hasAgg || b.allocator != nil || b.ctx != nil || b.err != nil {
+		t.Errorf("released builder keeps its state: %+v", b)
+	}
+	if allocator.allocID() != "_1" {
+		t.Errorf("the allocator is reset with the builder")
+	}
+}
+
+func TestReleasePlanNodes(t *testing.T) {
+	allocator := new(idAllocator)
+	column := &expression.Column{}
+	child := newProjection(nil, allocator, 1)
+
+	sel := newSelection(nil, allocator, []expression.Expression{column})
+	addChild(sel, child)
+	sel.planMap["key"] = &physicalPlanInfo{}
+	releaseSelection(sel)
+	if sel.Conditions != nil || len(sel.children) != 0 || len(sel.planMap) != 0 || sel.self != nil || sel.id != "" {
+		t.Errorf("released selection keeps its state: %+v", sel)
+	}
+
+	proj := newProjection(nil, allocator, 2)
+	proj.Exprs = append(proj.Exprs, column, column)
+	exprs := proj.Exprs
+	releaseProjection(proj)
+	if len(proj.Exprs) != 0 || exprs[0] != nil || exprs[1] != nil {
+		t.Errorf("released projection keeps its expressions %v", exprs)
+	}
+	if reused := newProjection(nil, allocator, 3); cap(reused.Exprs) < 3 || len(reused.Exprs) != 0 {
+		t.Errorf("new projection has expressions %v of capacity %d", reused.Exprs, cap(reused.Exprs))
+	}
+
+	cols := newUsedColumns()
+	*cols = append(*cols, column, column)[:1]
+	appended := append(*cols, column) // as a child appends to the columns of its parent
+	releaseUsedColumns(cols)
+	if len(*cols) != 0 || appended[0] != nil || appended[1] != nil {
+		t.Errorf("released columns keep %v", appended)
+	}
+}
+
+func TestAllocID(t *testing.T) {
+	a := new(idAllocator)
+	for i := 1; i <= 300; i++ {
+		if id := a.allocID(); id != "_"+strconv.Itoa(i) {
+			t.Fatalf("id %d is %s", i, id)
+		}
+	}
+}
+
+func BenchmarkAllocID(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a := new(idAllocator)
+		for j := 0; j < 20; j++ {
+			a.allocID()
+		}
+	}
+}
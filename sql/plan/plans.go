@@ -19,6 +19,7 @@ import (
 
 	"github.com/lovelly/gleam/sql/ast"
 	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/model"
 	"github.com/lovelly/gleam/sql/sessionctx/variable"
 	"github.com/lovelly/gleam/sql/table"
 	"github.com/lovelly/gleam/sql/util/types"
@@ -172,6 +173,7 @@ type Simple struct {
 type Insert struct {
 	baseLogicalPlan
 
+	DBName      model.CIStr
 	Table       table.Table
 	tableSchema expression.Schema
 	Columns     []*ast.ColumnName
@@ -20,11 +20,7 @@ import (
 
 func addSelection(p Plan, child LogicalPlan, conditions []expression.Expression, allocator *idAllocator) error {
 	conditions = expression.PropagateConstant(p.context(), conditions)
-	selection := &Selection{
-		Conditions:      conditions,
-		baseLogicalPlan: newBaseLogicalPlan(Sel, allocator)}
-	selection.self = selection
-	selection.initIDAndContext(p.context())
+	selection := newSelection(p.context(), allocator, conditions)
 	selection.SetSchema(child.GetSchema().Clone())
 	return InsertPlan(p, child, selection)
 }
@@ -43,12 +39,13 @@ func (p *Selection) PredicatePushDown(predicates []expression.Expression) ([]exp
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
+	releaseSelection(p)
 	return nil, child, nil
 }
 
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *DataSource) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
-	return predicates, p, nil
+	return mergeColumnRanges(p.ctx, predicates), p, nil
 }
 
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"github.com/lovelly/gleam/sql/expression"
+)
+
+// eliminateProjections merges the Projections below other Projections and
+// Aggregations into them after the columns are pruned, so that the columns
+// of a row are selected once instead of by each of the stacked Projections,
+// e.g. of "select x.a from (select a, b from t) x".
+func eliminateProjections(p LogicalPlan) error {
+	for _, child := range p.GetChildren() {
+		if err := eliminateProjections(child.(LogicalPlan)); err != nil {
+			return err
+		}
+	}
+	if len(p.GetChildren()) != 1 {
+		return nil
+	}
+	proj, ok := p.GetChildByIndex(0).(*Projection)
+	if !ok || len(proj.GetParents()) != 1 {
+		return nil
+	}
+	switch x := p.(type) {
+	case *Projection:
+		if !canMergeProjection(proj, x.Exprs) {
+			return nil
+		}
+		for i, expr := range x.Exprs {
+			x.Exprs[i] = expression.ColumnSubstitute(expr, proj.schema, proj.Exprs)
+		}
+	case *Aggregation:
+		// the aggregates of the executor only read columns
+		for _, expr := range proj.Exprs {
+			if _, isColumn := expr.(*expression.Column); !isColumn {
+				return nil
+			}
+		}
+		for i, item := range x.GroupByItems {
+			x.GroupByItems[i] = expression.ColumnSubstitute(item, proj.schema, proj.Exprs)
+		}
+		for _, aggFunc := range x.AggFuncs {
+			args := make([]expression.Expression, 0, len(aggFunc.GetArgs()))
+			for _, arg := range aggFunc.GetArgs() {
+				args = append(args, expression.ColumnSubstitute(arg, proj.schema, proj.Exprs))
+			}
+			aggFunc.SetArgs(args)
+		}
+		x.collectGroupByColumns()
+	default:
+		return nil
+	}
+	if err := RemovePlan(proj); err != nil {
+		return err
+	}
+	releaseProjection(proj)
+	return nil
+}
+
+// canMergeProjection checks that the expressions of the projection can be
+// evaluated in the exprs using its columns instead. The SET @var expressions
+// must be evaluated even if they are not used, and the other expressions can
+// not be evaluated more often than before.
+func canMergeProjection(proj *Projection, exprs []expression.Expression) bool {
+	uses := make([]int, proj.schema.Len())
+	for _, expr := range exprs {
+		for _, col := range expression.ExtractColumns(expr) {
+			if idx := proj.schema.GetColumnIndex(col); idx != -1 {
+				uses[idx]++
+			}
+		}
+	}
+	for i, expr := range proj.Exprs {
+		if !exprHasSetVar(expr) {
+			return false
+		}
+		if _, isColumn := expr.(*expression.Column); !isColumn && uses[i] > 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -20,8 +20,11 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/context"
 	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/mysql"
 	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/terror"
 	"github.com/lovelly/gleam/sql/util/types"
 )
 
@@ -95,9 +98,17 @@ func (r *rangePointSorter) Swap(i, j int) {
 	r.points[i], r.points[j] = r.points[j], r.points[i]
 }
 
+// MaxRanges is the most ranges built from the conditions on a column, e.g. of
+// long IN lists or many ORs. Beyond it, the column has the full range, and
+// the conditions are only evaluated as filters. 0 means no limit.
+var MaxRanges = 1024
+
 type rangeBuilder struct {
 	err error
 	sc  *variable.StatementContext
+	// widened is set when ranges beyond MaxRanges are replaced by the full
+	// range, so the conditions must be kept as filters.
+	widened bool
 }
 
 func (r *rangeBuilder) build(expr expression.Expression) []rangePoint {
@@ -252,6 +263,9 @@ func (r *rangeBuilder) newBuildFromIn(expr *expression.ScalarFunction) []rangePo
 		endPoint := rangePoint{value: types.NewDatum(v.Value.GetValue())}
 		rangePoints = append(rangePoints, startPoint, endPoint)
 	}
+	if r.exceedsMaxRanges(rangePoints) {
+		return fullRange
+	}
 	sorter := rangePointSorter{points: rangePoints, sc: r.sc}
 	sort.Sort(&sorter)
 	if sorter.err != nil {
@@ -441,9 +455,44 @@ func (r *rangeBuilder) merge(a, b []rangePoint, union bool) []rangePoint {
 			inRangeCount--
 		}
 	}
+	merged = r.mergeAdjacent(merged)
+	if r.exceedsMaxRanges(merged) {
+		return fullRange
+	}
+	return merged
+}
+
+// mergeAdjacent merges the ranges ending where the next ones start, e.g.
+// [1, 2) and [2, 3] into [1, 3].
+func (r *rangeBuilder) mergeAdjacent(points []rangePoint) []rangePoint {
+	merged := points[:0]
+	for i := 0; i < len(points); i++ {
+		point := points[i]
+		if !point.start && i+1 < len(points) && !(point.excl && points[i+1].excl) {
+			cmp, err := point.value.CompareDatum(r.sc, points[i+1].value)
+			if err != nil {
+				r.err = errors.Trace(err)
+			} else if cmp == 0 {
+				// skip the end of this range and the start of the next one
+				i++
+				continue
+			}
+		}
+		merged = append(merged, point)
+	}
 	return merged
 }
 
+// exceedsMaxRanges checks whether the points are more than MaxRanges ranges,
+// which are widened to the full range.
+func (r *rangeBuilder) exceedsMaxRanges(points []rangePoint) bool {
+	if MaxRanges > 0 && len(points)/2 > MaxRanges {
+		r.widened = true
+		return true
+	}
+	return false
+}
+
 // buildIndexRanges build index ranges from range points.
 // Only the first column in the index is built, extra column ranges will be appended by
 // appendIndexRanges.
@@ -475,11 +524,29 @@ func (r *rangeBuilder) convertPoint(point rangePoint, tp *types.FieldType) range
 	case types.KindMaxValue, types.KindMinNotNull:
 		return point
 	}
-	casted, err := point.value.ConvertTo(r.sc, tp)
-	if err != nil {
+	// the values rounded or truncated to the type, or out of its range, are
+	// converted to the nearest values of the type, and the boundaries are
+	// adjusted below
+	sc := &variable.StatementContext{IgnoreTruncate: true, TimeZone: r.sc.TimeZone}
+	casted, err := point.value.ConvertTo(sc, tp)
+	if err != nil && !terror.ErrorEqual(err, types.ErrOverflow) {
 		r.err = errors.Trace(err)
+		return point
 	}
-	valCmpCasted, err := point.value.CompareDatum(r.sc, casted)
+	if tp.Tp == mysql.TypeDate && casted.Kind() == types.KindMysqlTime {
+		// DATE values have no time
+		t := casted.GetMysqlTime()
+		t.Time = types.FromDate(t.Time.Year(), t.Time.Month(), t.Time.Day(), 0, 0, 0, 0)
+		casted.SetMysqlTime(t)
+	}
+	exact := point.value
+	if exactTp := exactFieldType(tp); exactTp != nil {
+		if exact, err = point.value.ConvertTo(sc, exactTp); err != nil {
+			r.err = errors.Trace(err)
+			return point
+		}
+	}
+	valCmpCasted, err := exact.CompareDatum(r.sc, casted)
 	if err != nil {
 		r.err = errors.Trace(err)
 	}
@@ -515,6 +582,26 @@ func (r *rangeBuilder) convertPoint(point rangePoint, tp *types.FieldType) range
 	return point
 }
 
+// exactFieldType returns the type keeping the precision of the values
+// converted to the type, which are compared with the values rounded or
+// truncated to the type, e.g. the fractional seconds of a DATETIME, or the
+// time of a DATE. It returns nil for the types compared with the values
+// as they are.
+func exactFieldType(tp *types.FieldType) *types.FieldType {
+	switch tp.Tp {
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		exactTp := types.NewFieldType(tp.Tp)
+		if tp.Tp == mysql.TypeDate {
+			exactTp.Tp = mysql.TypeDatetime
+		}
+		exactTp.Decimal = types.MaxFsp
+		return exactTp
+	case mysql.TypeNewDecimal:
+		return types.NewFieldType(mysql.TypeNewDecimal)
+	}
+	return nil
+}
+
 // appendIndexRanges appends additional column ranges for multi-column index.
 // The additional column ranges can only be appended to point ranges.
 // for example we have an index (a, b), if the condition is (a > 1 and b = 2)
@@ -608,7 +695,165 @@ func (r *rangeBuilder) buildTableRanges(rangePoints []rangePoint) []TableRange {
 		if startInt > endInt {
 			continue
 		}
+		// e.g. [1, 2] and [3, 4] are merged into [1, 4]
+		if last := len(tableRanges) - 1; last >= 0 && tableRanges[last].HighVal != math.MaxInt64 && tableRanges[last].HighVal+1 >= startInt {
+			if endInt > tableRanges[last].HighVal {
+				tableRanges[last].HighVal = endInt
+			}
+			continue
+		}
 		tableRanges = append(tableRanges, TableRange{LowVal: startInt, HighVal: endInt})
 	}
 	return tableRanges
 }
+
+// mergeColumnRanges replaces the ORs and IN lists of comparisons of a column
+// with constants by the ranges they are merged into, e.g.
+// "a in (1, 2) or a between 2 and 5" is "a >= 1 and a <= 5", so fewer
+// comparisons are evaluated for each row. The conditions whose ranges are
+// widened beyond MaxRanges, or are not merged, are kept as they are, as the
+// residual filters of the full range.
+func mergeColumnRanges(ctx context.Context, conditions []expression.Expression) []expression.Expression {
+	ret := make([]expression.Expression, 0, len(conditions))
+	for _, cond := range conditions {
+		ret = append(ret, mergeRanges(ctx, cond))
+	}
+	return ret
+}
+
+func mergeRanges(ctx context.Context, cond expression.Expression) expression.Expression {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok || (f.FuncName.L != ast.OrOr && f.FuncName.L != ast.In) {
+		return cond
+	}
+	col, count := rangeColumn(f, nil)
+	if col == nil {
+		return cond
+	}
+	r := &rangeBuilder{sc: ctx.GetSessionVars().StmtCtx}
+	points := r.build(cond)
+	if r.err != nil || r.widened || len(points)/2 >= count {
+		return cond
+	}
+	merged, err := rangesToCondition(ctx, col, points)
+	if err != nil {
+		return cond
+	}
+	return merged
+}
+
+// rangeColumn returns the column compared by all the comparisons of the ORs,
+// ANDs and IN lists, and the number of comparisons, or nil for other conditions.
+func rangeColumn(expr expression.Expression, col *expression.Column) (*expression.Column, int) {
+	f, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return nil, 0
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.OrOr, ast.AndAnd:
+		col, l := rangeColumn(args[0], col)
+		if col == nil {
+			return nil, 0
+		}
+		col, r := rangeColumn(args[1], col)
+		return col, l + r
+	case ast.In:
+		for _, arg := range args[1:] {
+			if c, ok := arg.(*expression.Constant); !ok || c.Value.IsNull() {
+				return nil, 0
+			}
+		}
+		return sameColumn(args[0], col), len(args) - 1
+	case ast.EQ, ast.GE, ast.GT, ast.LE, ast.LT:
+		c, ok := args[1].(*expression.Constant)
+		if !ok || c.Value.IsNull() {
+			return nil, 0
+		}
+		return sameColumn(args[0], col), 1
+	}
+	return nil, 0
+}
+
+func sameColumn(expr expression.Expression, col *expression.Column) *expression.Column {
+	c, ok := expr.(*expression.Column)
+	if !ok || (col != nil && !c.Equal(col, nil)) {
+		return nil
+	}
+	return c
+}
+
+// rangesToCondition compares the column with the ranges of the points, with
+// an IN list for the single values.
+func rangesToCondition(ctx context.Context, col *expression.Column, points []rangePoint) (expression.Expression, error) {
+	boolType := types.NewFieldType(mysql.TypeTiny)
+	constant := func(p rangePoint) expression.Expression {
+		return &expression.Constant{Value: p.value, RetType: col.RetType}
+	}
+	var conds []expression.Expression
+	values := []expression.Expression{col}
+	for i := 0; i < len(points); i += 2 {
+		start, end := points[i], points[i+1]
+		if !start.excl && !end.excl && start.value.Kind() != types.KindMinNotNull && end.value.Kind() != types.KindMaxValue {
+			if cmp, err := start.value.CompareDatum(ctx.GetSessionVars().StmtCtx, end.value); err == nil && cmp == 0 {
+				values = append(values, constant(start))
+				continue
+			}
+		}
+		var bounds []expression.Expression
+		if start.value.Kind() != types.KindMinNotNull {
+			op := ast.GE
+			if start.excl {
+				op = ast.GT
+			}
+			bound, err := expression.NewFunction(ctx, op, boolType, col, constant(start))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			bounds = append(bounds, bound)
+		}
+		if end.value.Kind() != types.KindMaxValue {
+			op := ast.LE
+			if end.excl {
+				op = ast.LT
+			}
+			bound, err := expression.NewFunction(ctx, op, boolType, col, constant(end))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			bounds = append(bounds, bound)
+		}
+		if len(bounds) == 0 {
+			notNull, err := expression.NewFunction(ctx, ast.IsNull, boolType, col)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			bound, err := expression.NewFunction(ctx, ast.UnaryNot, boolType, notNull)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			bounds = append(bounds, bound)
+		}
+		conds = append(conds, expression.ComposeCNFCondition(ctx, bounds...))
+	}
+	switch len(values) {
+	case 1:
+	case 2:
+		eq, err := expression.NewFunction(ctx, ast.EQ, boolType, col, values[1])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		conds = append(conds, eq)
+	default:
+		in, err := expression.NewFunction(ctx, ast.In, boolType, values...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		conds = append(conds, in)
+	}
+	if len(conds) == 0 {
+		// no value is in the ranges
+		return &expression.Constant{Value: types.NewDatum(0), RetType: boolType}, nil
+	}
+	return expression.ComposeDNFCondition(ctx, conds...), nil
+}
@@ -0,0 +1,120 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+func TestConvertPoint(t *testing.T) {
+	newType := func(tp byte, flen, decimal int) *types.FieldType {
+		ft := types.NewFieldType(tp)
+		ft.Flen, ft.Decimal = flen, decimal
+		return ft
+	}
+	bigint := newType(mysql.TypeLonglong, 20, 0)
+	decimal := newType(mysql.TypeNewDecimal, 5, 1)
+	datetime := newType(mysql.TypeDatetime, 19, 0)
+	datetime3 := newType(mysql.TypeDatetime, 23, 3)
+	date := newType(mysql.TypeDate, 10, 0)
+
+	for _, c := range []struct {
+		tp       *types.FieldType
+		value    interface{}
+		start    bool
+		excl     bool
+		expected string
+	}{
+		{bigint, 1.9, true, true, "[2"},
+		{bigint, 1.1, true, false, "(1"},
+		{bigint, 1.1, false, true, "1]"},
+		{bigint, 1.9, false, false, "2)"},
+		{bigint, 2.0, true, true, "(2"},
+		{bigint, 1e30, false, true, "9223372036854775807]"},
+		{bigint, -1e30, true, false, "[-9223372036854775808"},
+
+		{decimal, "1.25", true, true, "[1.3"},
+		{decimal, "1.25", true, false, "[1.3"},
+		{decimal, "1.25", false, true, "1.3)"},
+		{decimal, "1.25", false, false, "1.3)"},
+		{decimal, "1.24", true, false, "(1.2"},
+		{decimal, "1.24", false, false, "1.2]"},
+		{decimal, 1.5, false, false, "1.5]"},
+		{decimal, "-1.25", true, false, "(-1.3"},
+		{decimal, "10000", false, true, "9999.9]"},
+
+		{datetime, "2017-01-01 10:00:00.5", true, true, "[2017-01-01 10:00:01"},
+		{datetime, "2017-01-01 10:00:00.5", true, false, "[2017-01-01 10:00:01"},
+		{datetime, "2017-01-01 10:00:00.5", false, true, "2017-01-01 10:00:01)"},
+		{datetime, "2017-01-01 10:00:00.5", false, false, "2017-01-01 10:00:01)"},
+		{datetime, "2017-01-01 10:00:00.4", true, false, "(2017-01-01 10:00:00"},
+		{datetime, "2017-01-01 10:00:00.4", false, false, "2017-01-01 10:00:00]"},
+		{datetime, "2017-01-01 10:00:00", false, true, "2017-01-01 10:00:00)"},
+		{datetime3, "2017-01-01 10:00:00.1234", true, false, "(2017-01-01 10:00:00.123"},
+		{datetime3, "2017-01-01 10:00:00.1235", false, false, "2017-01-01 10:00:00.124)"},
+		{date, "2017-01-01 10:00:00", true, false, "(2017-01-01"},
+		{date, "2017-01-01 10:00:00", false, true, "2017-01-01]"},
+		{date, "2017-01-01", false, true, "2017-01-01)"},
+	} {
+		r := &rangeBuilder{sc: new(variable.StatementContext)}
+		point := r.convertPoint(rangePoint{value: types.NewDatum(c.value), start: c.start, excl: c.excl}, c.tp)
+		if r.err != nil {
+			t.Errorf("%v of %v: %v", c.value, c.tp, r.err)
+			continue
+		}
+		if actual := point.String(); actual != c.expected {
+			t.Errorf("%v (start %v, excl %v) of %v: got %s, expected %s", c.value, c.start, c.excl, c.tp, actual, c.expected)
+		}
+	}
+}
+
+func TestRangeUnion(t *testing.T) {
+	point := func(value int64, start, excl bool) rangePoint {
+		return rangePoint{value: types.NewIntDatum(value), start: start, excl: excl}
+	}
+	format := func(points []rangePoint) string {
+		var s string
+		for i := 0; i < len(points); i += 2 {
+			s += points[i].String() + "," + points[i+1].String() + " "
+		}
+		return s
+	}
+
+	r := &rangeBuilder{sc: new(variable.StatementContext)}
+	for _, c := range []struct {
+		a, b     []rangePoint
+		expected string
+	}{
+		// overlapping
+		{[]rangePoint{point(1, true, false), point(3, false, false)}, []rangePoint{point(2, true, false), point(4, false, false)}, "[1,4] "},
+		// adjacent
+		{[]rangePoint{point(1, true, false), point(2, false, true)}, []rangePoint{point(2, true, false), point(3, false, false)}, "[1,3] "},
+		{[]rangePoint{point(1, true, false), point(2, false, false)}, []rangePoint{point(2, true, true), point(3, false, false)}, "[1,3] "},
+		// disjoint
+		{[]rangePoint{point(1, true, false), point(2, false, true)}, []rangePoint{point(2, true, true), point(3, false, false)}, "[1,2) (2,3] "},
+	} {
+		if actual := format(r.union(c.a, c.b)); actual != c.expected {
+			t.Errorf("%s union %s: got %s, expected %s", format(c.a), format(c.b), actual, c.expected)
+		}
+	}
+
+	tableRanges := r.buildTableRanges([]rangePoint{
+		point(1, true, false), point(2, false, false),
+		point(3, true, false), point(4, false, false),
+		point(6, true, false), point(7, false, false),
+	})
+	if len(tableRanges) != 2 || tableRanges[0] != (TableRange{1, 4}) || tableRanges[1] != (TableRange{6, 7}) {
+		t.Errorf("expected table ranges [1,4] [6,7], got %v", tableRanges)
+	}
+
+	defer func(maxRanges int) { MaxRanges = maxRanges }(MaxRanges)
+	MaxRanges = 2
+	r = &rangeBuilder{sc: new(variable.StatementContext)}
+	points := r.union([]rangePoint{point(1, true, false), point(1, false, false)}, []rangePoint{point(3, true, false), point(3, false, false)})
+	points = r.union(points, []rangePoint{point(5, true, false), point(5, false, false)})
+	if actual := format(points); actual != format(fullRange) || !r.widened {
+		t.Errorf("expected the full range beyond 2 ranges, got %s", actual)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"log"
 
 	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/expression"
 	"github.com/lovelly/gleam/sql/mysql"
 	"github.com/lovelly/gleam/sql/parser/opcode"
 	"github.com/lovelly/gleam/sql/sessionctx/variable"
@@ -146,7 +147,11 @@ func (v *typeInferrer) aggregateFunc(x *ast.AggregateFuncExpr) {
 		ft := types.NewFieldType(mysql.TypeNewDecimal)
 		ft.Charset = charset.CharsetBin
 		ft.Collate = charset.CollationBin
-		ft.Decimal = x.Args[0].GetType().Decimal
+		if name == ast.AggFuncSum {
+			ft.Flen, ft.Decimal = expression.SumDecimalType(x.Args[0].GetType())
+		} else {
+			ft.Flen, ft.Decimal = expression.AvgDecimalType(x.Args[0].GetType())
+		}
 		x.SetType(ft)
 	case ast.AggFuncGroupConcat:
 		ft := types.NewFieldType(mysql.TypeVarString)
@@ -175,6 +180,9 @@ func (v *typeInferrer) binaryOperation(x *ast.BinaryOperationExpr) {
 		if x.L.GetType() != nil && x.R.GetType() != nil {
 			xTp := mergeArithType(x.L.GetType().Tp, x.R.GetType().Tp)
 			x.Type.Init(xTp)
+			if xTp == mysql.TypeNewDecimal {
+				x.Type.Flen, x.Type.Decimal = expression.ArithmeticDecimalType(x.Op, x.L.GetType(), x.R.GetType())
+			}
 			leftUnsigned := x.L.GetType().Flag & mysql.UnsignedFlag
 			rightUnsigned := x.R.GetType().Flag & mysql.UnsignedFlag
 			// If both operands are unsigned, result is unsigned.
@@ -187,6 +195,9 @@ func (v *typeInferrer) binaryOperation(x *ast.BinaryOperationExpr) {
 				xTp = mysql.TypeNewDecimal
 			}
 			x.Type.Init(xTp)
+			if xTp == mysql.TypeNewDecimal {
+				x.Type.Flen, x.Type.Decimal = expression.ArithmeticDecimalType(x.Op, x.L.GetType(), x.R.GetType())
+			}
 		}
 	}
 	x.Type.Charset = charset.CharsetBin
@@ -225,6 +236,7 @@ func (v *typeInferrer) unaryOperation(x *ast.UnaryOperationExpr) {
 				x.Type.Tp = mysql.TypeDouble
 			case mysql.TypeNewDecimal:
 				x.Type.Tp = mysql.TypeNewDecimal
+				x.Type.Flen, x.Type.Decimal = x.V.GetType().Flen, x.V.GetType().Decimal
 			}
 		}
 	}
@@ -257,12 +269,19 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		chs = charset.CharsetBin
 	)
 	switch x.FnName.L {
-	case "abs", "ifnull", "nullif":
+	case "abs":
 		tp = x.Args[0].GetType()
 		// TODO: We should cover all types.
-		if x.FnName.L == "abs" && tp.Tp == mysql.TypeDatetime {
+		if tp.Tp == mysql.TypeDatetime {
 			tp = types.NewFieldType(mysql.TypeDouble)
 		}
+	case "ifnull", "coalesce":
+		tp = types.AggregateFieldType(true, argTypes(x.Args)...)
+	case "nullif":
+		// the first argument, or NULL
+		argTp := *x.Args[0].GetType()
+		argTp.Flag &^= mysql.NotNullFlag
+		tp = &argTp
 	case "round":
 		t := x.Args[0].GetType().Tp
 		switch t {
@@ -277,11 +296,13 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		for _, arg := range x.Args {
 			InferType(v.sc, arg)
 		}
-		if len(x.Args) > 0 {
+		argTps := make([]*types.FieldType, len(x.Args))
+		for i, arg := range x.Args {
+			argTps[i] = arg.GetType()
+		}
+		tp = expression.CompareType(argTps...)
+		if tp == nil && len(x.Args) > 0 {
 			tp = x.Args[0].GetType()
-			for i := 1; i < len(x.Args); i++ {
-				mergeArithType(tp.Tp, x.Args[i].GetType().Tp)
-			}
 		}
 	case "interval":
 		tp = types.NewFieldType(mysql.TypeLonglong)
@@ -307,7 +328,7 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		tp = types.NewFieldType(mysql.TypeDatetime)
 	case "microsecond", "second", "minute", "hour", "day", "week", "month", "year",
 		"dayofweek", "dayofmonth", "dayofyear", "weekday", "weekofyear", "yearweek", "datediff",
-		"found_rows", "length", "extract", "locate", "unix_timestamp":
+		"found_rows", "length", "extract", "locate", "instr", "unix_timestamp":
 		tp = types.NewFieldType(mysql.TypeLonglong)
 	case "now", "sysdate":
 		tp = types.NewFieldType(mysql.TypeDatetime)
@@ -324,29 +345,34 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 	case "dayname", "version", "database", "user", "current_user", "schema",
 		"concat", "concat_ws", "left", "lcase", "lower", "repeat",
 		"replace", "ucase", "upper", "convert", "substring",
-		"substring_index", "trim", "ltrim", "rtrim", "reverse", "hex", "unhex", "date_format", "rpad", "char_func", "conv":
+		"substring_index", "trim", "ltrim", "rtrim", "reverse", "hex", "unhex", "date_format", "rpad", "char_func", "conv",
+		"lpad", "format", "elt", "export_set", "quote", "regexp_replace", "regexp_substr":
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
 	case "strcmp", "isnull", "bit_length", "char_length", "character_length", "crc32", "timestampdiff", "sign":
 		tp = types.NewFieldType(mysql.TypeLonglong)
-	case "connection_id":
+	case "connection_id", "last_insert_id":
 		tp = types.NewFieldType(mysql.TypeLonglong)
 		tp.Flag |= mysql.UnsignedFlag
+	case "row_count":
+		tp = types.NewFieldType(mysql.TypeLonglong)
 	case "find_in_set", ast.Field:
 		tp = types.NewFieldType(mysql.TypeLonglong)
 	case "if":
-		// TODO: fix this
-		// See https://dev.mysql.com/doc/refman/5.5/en/control-flow-functions.html#function_if
-		// The default return type of IF() (which may matter when it is stored into a temporary table) is calculated as follows.
-		// Expression	Return Value
-		// expr2 or expr3 returns a string	string
-		// expr2 or expr3 returns a floating-point value	floating-point
-		// expr2 or expr3 returns an integer	integer
-		tp = x.Args[1].GetType()
+		// See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_if
+		tp = types.AggregateFieldType(false, argTypes(x.Args[1:])...)
 	case "get_lock", "release_lock":
 		tp = types.NewFieldType(mysql.TypeLonglong)
 	default:
-		tp = types.NewFieldType(mysql.TypeUnspecified)
+		if udf := expression.GetUDF(x.FnName.L); udf != nil {
+			retTp := *udf.RetType
+			tp = &retTp
+			if types.IsTypeChar(tp.Tp) || types.IsTypeBlob(tp.Tp) {
+				chs = v.defaultCharset
+			}
+		} else {
+			tp = types.NewFieldType(mysql.TypeUnspecified)
+		}
 	}
 	// If charset is unspecified.
 	if len(tp.Charset) == 0 {
@@ -365,41 +391,24 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 }
 
 // The return type of a CASE expression is the compatible aggregated type of all return values,
-// but also depends on the context in which it is used.
-// If used in a string context, the result is returned as a string.
-// If used in a numeric context, the result is returned as a decimal, real, or integer value.
+// see types.AggregateFieldType().
 func (v *typeInferrer) handleCaseExpr(x *ast.CaseExpr) {
-	var currType types.FieldType
+	var tps []*types.FieldType
 	for _, w := range x.WhenClauses {
-		t := w.Result.GetType()
-		if currType.Tp == mysql.TypeUnspecified {
-			currType = *t
-			continue
-		}
-		mtp := types.MergeFieldType(currType.Tp, t.Tp)
-		if mtp == t.Tp && mtp != currType.Tp {
-			currType.Charset = t.Charset
-			currType.Collate = t.Collate
-		}
-		currType.Tp = mtp
-
+		tps = append(tps, w.Result.GetType())
 	}
 	if x.ElseClause != nil {
-		t := x.ElseClause.GetType()
-		if currType.Tp == mysql.TypeUnspecified {
-			currType = *t
-		} else {
-			mtp := types.MergeFieldType(currType.Tp, t.Tp)
-			if mtp == t.Tp && mtp != currType.Tp {
-				currType.Charset = t.Charset
-				currType.Collate = t.Collate
-			}
-			currType.Tp = mtp
-		}
+		tps = append(tps, x.ElseClause.GetType())
+	}
+	x.SetType(types.AggregateFieldType(false, tps...))
+}
+
+func argTypes(args []ast.ExprNode) []*types.FieldType {
+	tps := make([]*types.FieldType, len(args))
+	for i, arg := range args {
+		tps[i] = arg.GetType()
 	}
-	x.SetType(&currType)
-	// TODO: We need a better way to set charset/collation
-	x.Type.Charset, x.Type.Collate = types.DefaultCharsetForType(x.Type.Tp)
+	return tps
 }
 
 // like expression expects the target expression and pattern to be a string, if it's not, we add a cast function.
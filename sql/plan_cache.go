@@ -0,0 +1,141 @@
+package sql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/privilege"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+)
+
+// EnablePlanCache keeps the plans of the last maxEntries SELECTs, so that
+// running the same SELECT again, e.g. for a dashboard, skips resolving and
+// optimizing it. The SELECTs are the same if their texts only differ by
+// whitespace, and they run in the same database, as the same user, with
+// the same variables. The plans are invalidated when tables are registered
+// or dropped, and when privileges change. The SELECTs on temporary tables,
+// and those with subqueries, variables or nondeterministic functions, which
+// are evaluated while planning, are not cached.
+// Zero, the default, disables the cache.
+func EnablePlanCache(maxEntries int) {
+	plans.Lock()
+	defer plans.Unlock()
+	plans.maxEntries = maxEntries
+	plans.entries = make(map[string]*list.Element)
+	plans.lru = list.New()
+}
+
+var plans planCache
+
+type planCache struct {
+	sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List // of *planCacheEntry, the most recently used first
+}
+
+type planCacheEntry struct {
+	key  string
+	plan plan.Plan
+}
+
+func (c *planCache) enabled() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.maxEntries > 0
+}
+
+func (c *planCache) get(key string) (plan.Plan, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*planCacheEntry).plan, true
+}
+
+func (c *planCache) put(key string, p plan.Plan) {
+	c.Lock()
+	defer c.Unlock()
+	if c.maxEntries <= 0 {
+		return
+	}
+	if e, found := c.entries[key]; found {
+		e.Value.(*planCacheEntry).plan = p
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&planCacheEntry{key, p})
+	for c.lru.Len() > c.maxEntries {
+		e := c.lru.Back()
+		delete(c.entries, e.Value.(*planCacheEntry).key)
+		c.lru.Remove(e)
+	}
+}
+
+// planCacheKey returns false if the plan of the statement can not be cached.
+// The key is made of the normalized statement, the variables changing the
+// plan, the user and the versions of the tables, privileges and UDFs.
+func planCacheKey(stmt *parsedStatement, vars *variable.SessionVars, user string, tempTables map[string]*executor.TableSource) (string, bool) {
+	if len(tempTables) > 0 {
+		return "", false
+	}
+	switch stmt.tree.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+	default:
+		return "", false
+	}
+	checker := &determinismChecker{deterministic: true, withSubqueries: true}
+	stmt.tree.Accept(checker)
+	if !checker.deterministic {
+		return "", false
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%v %v %d\x00%d\x00%d\x00%d",
+		normalizeSQL(stmt.sql), vars.CurrentDB, user, vars.StrictSQLMode, vars.TimeZone, vars.SelectLimit,
+		executor.SchemaVersion(), privilege.Version(), expression.UDFVersion())
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// normalizeSQL replaces the whitespace outside the quoted strings and names
+// by single spaces, and removes the trailing semicolon.
+func normalizeSQL(sql string) string {
+	var b strings.Builder
+	var quote byte
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && i+1 < len(sql) {
+				b.WriteByte(c)
+				i++
+				c = sql[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteByte(c)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(b.String(), ";"))
+}
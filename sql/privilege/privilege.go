@@ -0,0 +1,236 @@
+// Package privilege keeps the users and roles of the SQL endpoint, and the
+// privileges granted to them on databases and tables. The statements of a
+// session with a user are checked against them before they are executed.
+//
+// Roles are accounts that can not log in. The privileges granted to a role
+// apply to the users and roles it is granted to. Host names of accounts, as in
+// 'user'@'host', are ignored.
+package privilege
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/terror"
+	"github.com/lovelly/gleam/sql/util"
+)
+
+// Error instances.
+var (
+	// ErrTableAccessDenied returns for a statement on a table without the privilege.
+	ErrTableAccessDenied = terror.ClassPrivilege.New(codeTableAccessDenied, mysql.MySQLErrName[mysql.ErrTableaccessDenied])
+	// ErrSpecificAccessDenied returns for a statement without the global privilege.
+	ErrSpecificAccessDenied = terror.ClassPrivilege.New(codeSpecificAccessDenied, mysql.MySQLErrName[mysql.ErrSpecificAccessDenied])
+	// ErrCannotUser returns for creating an existing account, or changing a missing one.
+	ErrCannotUser = terror.ClassPrivilege.New(codeCannotUser, mysql.MySQLErrName[mysql.ErrCannotUser])
+)
+
+// Error codes.
+const (
+	codeTableAccessDenied    terror.ErrCode = 1142
+	codeSpecificAccessDenied terror.ErrCode = 1227
+	codeCannotUser           terror.ErrCode = 1396
+)
+
+func init() {
+	privilegeMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeTableAccessDenied:    mysql.ErrTableaccessDenied,
+		codeSpecificAccessDenied: mysql.ErrSpecificAccessDenied,
+		codeCannotUser:           mysql.ErrCannotUser,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassPrivilege] = privilegeMySQLErrCodes
+}
+
+// account is a user or a role.
+type account struct {
+	password []byte // SHA1(password), empty without password
+	isRole   bool
+	roles    map[string]bool
+	// privileges are keyed by "db.table", "db.*" and "*.*", in lower case
+	privileges map[string]mysql.PrivilegeType
+}
+
+var (
+	mu       sync.RWMutex
+	accounts = make(map[string]*account)
+	// version counts the changes of the accounts and their privileges.
+	version int64
+)
+
+// Version changes whenever accounts, roles or privileges change, e.g. to
+// invalidate what was checked with the previous privileges.
+func Version() int64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	return version
+}
+
+// AccountName removes the host from 'user'@'host'.
+func AccountName(user string) string {
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		return user[:i]
+	}
+	return user
+}
+
+// CreateUser adds a user with the password, which can be empty.
+func CreateUser(user, password string, ifNotExists bool) error {
+	var sha1Password []byte
+	if password != "" {
+		sha1Password = util.Sha1Hash([]byte(password))
+	}
+	return createAccount("CREATE USER", user, &account{password: sha1Password}, ifNotExists)
+}
+
+// CreateRole adds a role.
+func CreateRole(role string) error {
+	return createAccount("CREATE ROLE", role, &account{isRole: true}, false)
+}
+
+func createAccount(op, name string, a *account, ifNotExists bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	name = AccountName(name)
+	if accounts[name] != nil {
+		if ifNotExists {
+			return nil
+		}
+		return ErrCannotUser.GenByArgs(op, name)
+	}
+	a.roles = make(map[string]bool)
+	a.privileges = make(map[string]mysql.PrivilegeType)
+	accounts[name] = a
+	version++
+	return nil
+}
+
+// DropUser removes the user or role, and revokes the role from all accounts.
+func DropUser(name string, ifExists bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	name = AccountName(name)
+	if accounts[name] == nil {
+		if ifExists {
+			return nil
+		}
+		return ErrCannotUser.GenByArgs("DROP USER", name)
+	}
+	delete(accounts, name)
+	for _, a := range accounts {
+		delete(a.roles, name)
+	}
+	version++
+	return nil
+}
+
+// Grant gives the privileges on the table to the user or role. The table can
+// be "*" for all tables of the database, and the database can be "*" too.
+func Grant(name string, privs mysql.PrivilegeType, dbName, tableName string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	a := accounts[AccountName(name)]
+	if a == nil {
+		return ErrCannotUser.GenByArgs("GRANT", AccountName(name))
+	}
+	a.privileges[objectKey(dbName, tableName)] |= privs
+	version++
+	return nil
+}
+
+// Revoke takes back the privileges on the table, as granted by Grant().
+func Revoke(name string, privs mysql.PrivilegeType, dbName, tableName string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	a := accounts[AccountName(name)]
+	if a == nil {
+		return ErrCannotUser.GenByArgs("REVOKE", AccountName(name))
+	}
+	key := objectKey(dbName, tableName)
+	if a.privileges[key] &^= privs; a.privileges[key] == 0 {
+		delete(a.privileges, key)
+	}
+	version++
+	return nil
+}
+
+// GrantRole gives the privileges of the role to the user or role.
+func GrantRole(role, name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	role, name = AccountName(role), AccountName(name)
+	r, a := accounts[role], accounts[name]
+	if r == nil || !r.isRole {
+		return ErrCannotUser.GenByArgs("GRANT ROLE", role)
+	}
+	if a == nil {
+		return ErrCannotUser.GenByArgs("GRANT ROLE", name)
+	}
+	a.roles[role] = true
+	version++
+	return nil
+}
+
+// RevokeRole takes back the role granted by GrantRole().
+func RevokeRole(role, name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	a := accounts[AccountName(name)]
+	if a == nil {
+		return ErrCannotUser.GenByArgs("REVOKE ROLE", AccountName(name))
+	}
+	delete(a.roles, AccountName(role))
+	version++
+	return nil
+}
+
+// Authenticate checks the response of a MySQL client to the scramble of the
+// server with the mysql_native_password method. Roles can not log in.
+func Authenticate(user string, scramble, auth []byte) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	a := accounts[AccountName(user)]
+	if a == nil || a.isRole {
+		return false
+	}
+	if len(a.password) == 0 {
+		return len(auth) == 0
+	}
+	return bytes.Equal(util.CalcPassword(scramble, a.password), auth)
+}
+
+// Check returns whether the user, or one of its roles, has the privilege on the
+// table. An empty table name checks the global privilege.
+func Check(user string, priv mysql.PrivilegeType, dbName, tableName string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return check(AccountName(user), priv, dbName, tableName, make(map[string]bool))
+}
+
+func check(name string, priv mysql.PrivilegeType, dbName, tableName string, checked map[string]bool) bool {
+	a := accounts[name]
+	if a == nil || checked[name] {
+		return false
+	}
+	checked[name] = true
+	keys := []string{objectKey("*", "*")}
+	if tableName != "" {
+		keys = append(keys, objectKey(dbName, "*"), objectKey(dbName, tableName))
+	}
+	for _, key := range keys {
+		if privs := a.privileges[key]; privs&(priv|mysql.AllPriv) != 0 {
+			return true
+		}
+	}
+	for role := range a.roles {
+		if check(role, priv, dbName, tableName, checked) {
+			return true
+		}
+	}
+	return false
+}
+
+func objectKey(dbName, tableName string) string {
+	return strings.ToLower(dbName) + "." + strings.ToLower(tableName)
+}
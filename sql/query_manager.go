@@ -0,0 +1,249 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+)
+
+// QueryManager runs the statements of many sessions concurrently, e.g. for a
+// sql server, with the same flow options, usually one distributed.Option(),
+// so the queries share the driver's connections to the master and agents.
+// Each session has its own variables, changed by its SET and USE statements.
+//
+// Each statement reads the tables of files, registered by RegisterFileTable(),
+// the streams of CREATE STREAM, and the union tables, on its own flow, so each statement on a
+// stream reads its next messages. A flow runs only once, so the tables of RegisterTable()
+// are read by one statement, which also runs the other steps of their flow.
+//
+// The sessions can also create temporary tables, with
+// "CREATE TEMPORARY TABLE name AS SELECT ...".
+type QueryManager struct {
+	options       []flow.FlowOption
+	maxPerSession int
+	tempCount     int64
+
+	sync.Mutex
+	sessions map[string]*managedSession
+	flows    map[*flow.Flow]*flowLock
+}
+
+type managedSession struct {
+	sync.Mutex // guards vars and the temporary tables while the statements are planned
+	vars       *variable.SessionVars
+	slots      chan bool
+	tempTables map[string]*executor.TableSource // by executor.TableKey()
+	dirty      *executor.DirtyDB                // the changes of the transaction, read by its statements
+}
+
+type flowLock struct {
+	sync.Mutex
+	order int  // locks are taken in this order, not to deadlock
+	ran   bool // set once a statement has run the flow
+}
+
+// NewQueryManager runs at most maxQueriesPerSession statements of each
+// session at the same time. The options run the flows, running locally if empty.
+func NewQueryManager(maxQueriesPerSession int, options ...flow.FlowOption) *QueryManager {
+	if maxQueriesPerSession <= 0 {
+		maxQueriesPerSession = 1
+	}
+	return &QueryManager{
+		options:       options,
+		maxPerSession: maxQueriesPerSession,
+		sessions:      make(map[string]*managedSession),
+		flows:         make(map[*flow.Flow]*flowLock),
+	}
+}
+
+// Run runs the SQL in the session, created on first use, as the user like
+// QueryAs(), or with all privileges if the user is empty. It waits while the
+// session runs maxQueriesPerSession statements, and returns the rows
+// collected by Dataset.Collect(). SET, USE, and the CREATE and DROP of
+// temporary tables, streams and functions return no rows.
+// Between BEGIN and COMMIT, the rows inserted into the tables of sinks, and
+// changed by UPDATE, are kept by the session and read by its statements,
+// until COMMIT writes them to the sinks, or ROLLBACK drops them. The changes
+// of a failed statement are dropped. Outside a transaction, INSERT writes
+// the rows to the sinks.
+func (m *QueryManager) Run(ctx context.Context, sessionID, user, sql string) ([][]interface{}, error) {
+	s, err := m.session(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case s.slots <- true:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if create := createTemporaryTable.FindStringSubmatch(sql); create != nil {
+		return nil, m.createTemporaryTable(ctx, s, user, create[2], create[3], create[1] != "")
+	}
+	if drop := dropTemporaryTable.FindStringSubmatch(sql); drop != nil {
+		return nil, s.dropTemporaryTable(drop[2], drop[1] != "")
+	}
+	if handled, err := execStreamStatement(user, sql); handled {
+		return nil, err
+	}
+	if handled, err := execFunctionStatement(user, sql); handled {
+		return nil, err
+	}
+
+	stmt, err := parseStatement(sql)
+	if err != nil {
+		return nil, err
+	}
+	dirty := s.dirty.Statement()
+	ds, _, unlock, err := m.exec(s, user, stmt, dirty)
+	defer unlock()
+	var rows [][]interface{}
+	if err == nil && ds != nil {
+		rows, err = ds.Collect(ctx, m.options...)
+	}
+	dirty.Done(err)
+	return rows, err
+}
+
+// exec builds the dataset of the statement on a new flow, keeping its changes
+// in dirty. The flows of its registered tables stay locked until unlock() is
+// called.
+func (m *QueryManager) exec(s *managedSession, user string, stmt *parsedStatement, dirty *executor.DirtyStatement) (ds *flow.Dataset, p plan.Plan, unlock func(), err error) {
+	s.Lock()
+	locks := m.flowLocks(stmt.tree, s.vars.CurrentDB, s.tempTables)
+	s.Unlock()
+	for _, l := range locks {
+		l.Lock()
+	}
+	unlock = func() {
+		for _, l := range locks {
+			l.Unlock()
+		}
+	}
+	for _, l := range locks {
+		if l.ran {
+			unlock()
+			return nil, nil, func() {}, fmt.Errorf("Failed to run %s: its tables were read by a previous statement, register them with RegisterFileTable() to read them again", stmt.sql)
+		}
+	}
+
+	s.Lock()
+	s.vars.User = user
+	ds, p, err = execStatement(s.vars, user, stmt, flow.New("query"), s.tempTables, dirty)
+	s.Unlock()
+	if err != nil || ds == nil {
+		unlock()
+		return ds, p, func() {}, err
+	}
+	for _, l := range locks {
+		l.ran = true
+	}
+	return ds, p, unlock, nil
+}
+
+// CloseSession forgets the variables of the session, removes its
+// temporary tables, and rolls back its transaction.
+func (m *QueryManager) CloseSession(sessionID string) {
+	m.Lock()
+	s := m.sessions[sessionID]
+	delete(m.sessions, sessionID)
+	m.Unlock()
+	if s == nil {
+		return
+	}
+
+	s.dirty.Rollback()
+	s.Lock()
+	defer s.Unlock()
+	for key := range s.tempTables {
+		s.removeTemporaryTable(key)
+	}
+}
+
+func (m *QueryManager) session(sessionID string) (*managedSession, error) {
+	m.Lock()
+	defer m.Unlock()
+	if s, found := m.sessions[sessionID]; found {
+		return s, nil
+	}
+	vars, err := newSessionVars()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create session %s: %v", sessionID, err)
+	}
+	s := &managedSession{
+		vars:       vars,
+		slots:      make(chan bool, m.maxPerSession),
+		tempTables: make(map[string]*executor.TableSource),
+		dirty:      executor.NewDirtyDB(),
+	}
+	m.sessions[sessionID] = s
+	return s, nil
+}
+
+// flowLocks returns the locks of the flows of the tables in the statement,
+// except the tables of files, streams and unions, and the temporary tables, in the
+// order to take them.
+func (m *QueryManager) flowLocks(tree ast.StmtNode, currentDB string, tempTables map[string]*executor.TableSource) (locks []*flowLock) {
+	v := &tableNameCollector{}
+	tree.Accept(v)
+
+	m.Lock()
+	defer m.Unlock()
+	seen := make(map[*flowLock]bool)
+	for _, t := range v.tables {
+		dbName := t.Schema.O
+		if dbName == "" {
+			dbName = currentDB
+		}
+		key := executor.TableKey(dbName, t.Name.O)
+		if _, found := tempTables[key]; found {
+			continue
+		}
+		ts, found := executor.GetTable(key)
+		if !found || ts.Location != nil || ts.Stream != nil || len(ts.Parts) > 0 || ts.Dataset == nil || ts.Dataset.Flow == nil {
+			continue
+		}
+		l, found := m.flows[ts.Dataset.Flow]
+		if !found {
+			l = &flowLock{order: len(m.flows)}
+			m.flows[ts.Dataset.Flow] = l
+		}
+		if !seen[l] {
+			seen[l] = true
+			locks = append(locks, l)
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].order < locks[j].order })
+	return locks
+}
+
+// tableNameCollector collects the names of the tables a statement reads.
+type tableNameCollector struct {
+	tables []*ast.TableName
+}
+
+func (v *tableNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *ast.TableName:
+		v.tables = append(v.tables, x)
+	case *ast.InsertStmt:
+		// the rows are written to the sink of the table, not read
+		if x.Select != nil {
+			x.Select.Accept(v)
+		}
+		return n, true
+	}
+	return n, false
+}
+
+func (v *tableNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
@@ -0,0 +1,286 @@
+package sql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/expression"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/util"
+)
+
+// EnableResultCache keeps the results of the last maxEntries deterministic
+// SELECTs on tables of local files, registered by RegisterFileTable().
+// Running such a query again returns its cached rows without reading the
+// files, as long as its plan is the same and the files have the same sizes
+// and modification times as when they were read. The results are cached
+// when the flow of the dataset returned by Query() runs.
+// Zero, the default, disables the cache.
+func EnableResultCache(maxEntries int) {
+	results.Lock()
+	defer results.Unlock()
+	results.maxEntries = maxEntries
+	results.entries = make(map[string]*list.Element)
+	results.lru = list.New()
+}
+
+var results resultCache
+
+type resultCache struct {
+	sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List // of *resultCacheEntry, the most recently used first
+}
+
+type resultCacheEntry struct {
+	key  string
+	rows [][]interface{}
+}
+
+func (c *resultCache) enabled() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.maxEntries > 0
+}
+
+func (c *resultCache) get(key string) ([][]interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*resultCacheEntry).rows, true
+}
+
+func (c *resultCache) put(key string, rows [][]interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if c.maxEntries <= 0 {
+		return
+	}
+	if e, found := c.entries[key]; found {
+		e.Value.(*resultCacheEntry).rows = rows
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&resultCacheEntry{key, rows})
+	for c.lru.Len() > c.maxEntries {
+		e := c.lru.Back()
+		delete(c.entries, e.Value.(*resultCacheEntry).key)
+		c.lru.Remove(e)
+	}
+}
+
+// cachedQuery is a query whose results can be cached.
+type cachedQuery struct {
+	key    string
+	flow   *flow.Flow // of the tables
+	tables []*executor.TableSource
+}
+
+// newCachedQuery returns nil if the results of the query can not be cached.
+// The key is made of the query text, the plan, which resolves the tables in
+// the current database, the variables changing the results, and the versions
+// of the table files.
+func newCachedQuery(tree ast.StmtNode, p plan.Plan, vars *variable.SessionVars) *cachedQuery {
+	switch tree.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+	default:
+		return nil
+	}
+	checker := &determinismChecker{deterministic: true}
+	tree.Accept(checker)
+	if !checker.deterministic {
+		return nil
+	}
+
+	q := &cachedQuery{}
+	if !q.addTables(p) || len(q.tables) == 0 {
+		return nil
+	}
+	versions, ok := q.versions()
+	if !ok {
+		return nil
+	}
+	digest, err := planDigest(p)
+	if err != nil {
+		return nil
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%v %v %d\x00%s",
+		tree.Text(), digest, vars.CurrentDB, vars.StrictSQLMode, vars.TimeZone, vars.SelectLimit, versions)
+	q.key = hex.EncodeToString(h.Sum(nil))
+	return q
+}
+
+// addTables adds the tables scanned by the plan, which must all be read from
+// files on the same flow.
+func (q *cachedQuery) addTables(p plan.Plan) bool {
+	if scan, ok := p.(*plan.PhysicalTableScan); ok {
+		ts, _ := executor.GetTable(executor.TableKey(scan.DBName.L, scan.Table.Name.L))
+		if ts == nil || ts.Location == nil || ts.Dataset == nil {
+			return false
+		}
+		if q.flow != nil && q.flow != ts.Dataset.Flow {
+			return false
+		}
+		q.flow = ts.Dataset.Flow
+		q.tables = append(q.tables, ts)
+	}
+	for _, child := range p.GetChildren() {
+		if !q.addTables(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// versions lists the sizes and modification times of the table files.
+// Only local files have versions.
+func (q *cachedQuery) versions() (string, bool) {
+	var versions []string
+	for _, ts := range q.tables {
+		path := q.flow.Expand(ts.Location.Path)
+		if strings.Contains(path, "://") {
+			return "", false
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, "*")
+		}
+		fileNames, err := filepath.Glob(path)
+		if err != nil {
+			return "", false
+		}
+		for _, fileName := range fileNames {
+			info, err := os.Stat(fileName)
+			if err != nil {
+				return "", false
+			}
+			if info.IsDir() {
+				continue
+			}
+			versions = append(versions, fmt.Sprintf("%s %d %d", fileName, info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, "\n"), true
+}
+
+// planDigest describes the plan with the types and the JSON of its nodes.
+func planDigest(p plan.Plan) (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	digest := fmt.Sprintf("%T%s(", p, data)
+	for _, child := range p.GetChildren() {
+		childDigest, err := planDigest(child)
+		if err != nil {
+			return "", err
+		}
+		digest += childDigest + ","
+	}
+	return digest + ")", nil
+}
+
+// cachedDataset returns the cached rows on the flow of the tables.
+func (q *cachedQuery) cachedDataset(rows [][]interface{}) *flow.Dataset {
+	return q.flow.Slices(rows)
+}
+
+// collect caches the rows of the dataset when its flow runs, unless the
+// table files change before all rows are read.
+func (q *cachedQuery) collect(ds *flow.Dataset) {
+	versions, _ := q.versions()
+	var mu sync.Mutex
+	var rows [][]interface{}
+	remaining := len(ds.Shards)
+	ds.Output(func(reader io.Reader) error {
+		var shardRows [][]interface{}
+		err := util.TakeMessage(reader, -1, func(encodedBytes []byte) error {
+			row, err := util.DecodeRow(encodedBytes)
+			if err != nil {
+				return err
+			}
+			shardRows = append(shardRows, append(append([]interface{}{}, row.K...), row.V...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		rows = append(rows, shardRows...)
+		if remaining--; remaining == 0 {
+			if current, ok := q.versions(); ok && current == versions {
+				results.put(q.key, rows)
+			}
+		}
+		return nil
+	})
+}
+
+// nondeterministicFunctions return different results for the same arguments.
+var nondeterministicFunctions = map[string]bool{
+	ast.Rand:             true,
+	ast.Curdate:          true,
+	ast.CurrentDate:      true,
+	ast.CurrentTime:      true,
+	ast.CurrentTimestamp: true,
+	ast.Curtime:          true,
+	ast.Now:              true,
+	ast.Sysdate:          true,
+	ast.UTCDate:          true,
+	ast.UnixTimestamp:    true,
+	ast.ConnectionID:     true,
+	ast.LastInsertId:     true,
+	ast.RowCount:         true,
+	ast.FoundRows:        true,
+	ast.Sleep:            true,
+	ast.GetLock:          true,
+	ast.ReleaseLock:      true,
+}
+
+// determinismChecker finds the functions and variables whose values can
+// change between runs of the same query, and with withSubqueries, the
+// subqueries, whose results can change with the tables.
+type determinismChecker struct {
+	deterministic  bool
+	withSubqueries bool
+}
+
+func (c *determinismChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.FuncCallExpr:
+		if nondeterministicFunctions[x.FnName.L] || expression.GetUDF(x.FnName.L) != nil {
+			c.deterministic = false
+		}
+	case *ast.VariableExpr:
+		c.deterministic = false
+	case *ast.SubqueryExpr:
+		if c.withSubqueries {
+			c.deterministic = false
+		}
+	}
+	return in, !c.deterministic
+}
+
+func (c *determinismChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, c.deterministic
+}
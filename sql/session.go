@@ -179,14 +179,22 @@ func CreateSession(info infoschema.InfoSchema) (Session, error) {
 }
 
 func createSession(info infoschema.InfoSchema) (*session, error) {
+	vars, err := newSessionVars()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return createSessionWithVars(info, vars), nil
+}
+
+func createSessionWithVars(info infoschema.InfoSchema, vars *variable.SessionVars) *session {
 	s := &session{
 		values:      make(map[fmt.Stringer]interface{}),
 		parser:      parser.New(),
-		sessionVars: variable.NewSessionVars(),
+		sessionVars: vars,
 	}
 	s.sessionVars.TxnCtx.InfoSchema = info
 
-	return s, nil
+	return s
 }
 
 // Compile is safe for concurrent use by multiple goroutines.
@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/sessionctx/varsutil"
+)
+
+// globalVars keeps the global system variables changed by SET GLOBAL.
+type globalVars struct {
+	sync.RWMutex
+	values map[string]string
+}
+
+var globals = &globalVars{values: make(map[string]string)}
+
+// GetGlobalSysVar implements the variable.GlobalVarAccessor interface.
+func (g *globalVars) GetGlobalSysVar(name string) (string, error) {
+	name = strings.ToLower(name)
+	g.RLock()
+	value, found := g.values[name]
+	g.RUnlock()
+	if found {
+		return value, nil
+	}
+	sysVar := variable.SysVars[name]
+	if sysVar == nil {
+		return "", variable.UnknownSystemVar.GenByArgs(name)
+	}
+	return sysVar.Value, nil
+}
+
+// SetGlobalSysVar implements the variable.GlobalVarAccessor interface.
+func (g *globalVars) SetGlobalSysVar(name string, value string) error {
+	g.Lock()
+	g.values[strings.ToLower(name)] = value
+	g.Unlock()
+	return nil
+}
+
+// querySessionVars are the session variables used by Query(), and by
+// QueryAs() for each user, by user name, so the users do not share their
+// variables. They are kept between queries, so SET statements apply to the
+// following queries.
+var querySessionVars = make(map[string]*variable.SessionVars)
+
+// lastConnectionID is the connection id of the last created session.
+var lastConnectionID uint64
+
+func newSessionVars() (*variable.SessionVars, error) {
+	vars := variable.NewSessionVars()
+	vars.ConnectionID = atomic.AddUint64(&lastConnectionID, 1)
+	vars.CurrentDB = executor.DefaultDB
+	vars.GlobalVarsAccessor = globals
+	if err := varsutil.LoadGlobalVars(vars); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return vars, nil
+}
+
+func getQuerySessionVars(user string) (*variable.SessionVars, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if vars := querySessionVars[user]; vars != nil {
+		return vars, nil
+	}
+	vars, err := newSessionVars()
+	if err != nil {
+		return nil, err
+	}
+	vars.User = user
+	querySessionVars[user] = vars
+	return vars, nil
+}
+
+// resetStmtCtx starts a new statement context for the statement, after keeping
+// the rows affected by the previous statement for ROW_COUNT(). SHOW WARNINGS
+// keeps the context of the previous statement to list its warnings.
+func resetStmtCtx(vars *variable.SessionVars, stmt ast.StmtNode) {
+	if show, ok := stmt.(*ast.ShowStmt); ok && show.Tp == ast.ShowWarnings {
+		return
+	}
+	if vars.StmtCtx.InSelectStmt {
+		vars.PrevAffectedRows = -1
+	} else {
+		vars.PrevAffectedRows = int64(vars.StmtCtx.AffectedRows())
+	}
+	sc := &variable.StatementContext{TimeZone: vars.TimeZone}
+	switch stmt.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt, *ast.ShowStmt:
+		sc.InSelectStmt = true
+	}
+	vars.StmtCtx = sc
+}
+
+// Warnings returns the warnings of the last statement run by Query(),
+// e.g. values converted in non-strict sql_mode.
+func Warnings() []error {
+	vars, err := getQuerySessionVars("")
+	if err != nil {
+		return nil
+	}
+	return vars.StmtCtx.GetWarnings()
+}
+
+// ConnectionID returns the id of the session of Query(), as returned by CONNECTION_ID().
+func ConnectionID() uint64 {
+	vars, err := getQuerySessionVars("")
+	if err != nil {
+		return 0
+	}
+	return vars.ConnectionID
+}
+
+// AffectedRows returns the rows written by the last INSERT or LOAD DATA run by
+// Query(), once the flow of its dataset has run.
+func AffectedRows() uint64 {
+	vars, err := getQuerySessionVars("")
+	if err != nil {
+		return 0
+	}
+	return vars.StmtCtx.AffectedRows()
+}
+
+// ExecutionContext returns a context for running the dataset of a query,
+// which times out after max_execution_time if it is set.
+//
+//	ctx, cancel := sql.ExecutionContext(context.Background())
+//	defer cancel()
+//	out.RunContext(ctx)
+func ExecutionContext(parent context.Context) (context.Context, context.CancelFunc) {
+	vars, err := getQuerySessionVars("")
+	if err != nil || vars.MaxExecutionTime <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, vars.MaxExecutionTime)
+}
@@ -14,6 +14,7 @@
 package variable
 
 import (
+	"math"
 	"sync"
 	"time"
 
@@ -98,6 +99,16 @@ type SessionVars struct {
 	// Current user
 	User string
 
+	// ConnectionID identifies the session, returned by CONNECTION_ID().
+	ConnectionID uint64
+
+	// LastInsertID is returned by LAST_INSERT_ID(), and set by LAST_INSERT_ID(expr).
+	LastInsertID uint64
+
+	// PrevAffectedRows is returned by ROW_COUNT(): the rows affected by the
+	// previous statement, or -1 if it returned rows.
+	PrevAffectedRows int64
+
 	// Current DB
 	CurrentDB string
 
@@ -120,6 +131,21 @@ type SessionVars struct {
 	// Per-connection time zones. Each client that connects has its own time zone setting, given by the session time_zone variable.
 	// See https://dev.mysql.com/doc/refman/5.7/en/time-zone-support.html
 	TimeZone *time.Location
+
+	// SelectLimit is the most rows returned by a SELECT without LIMIT, set by sql_select_limit.
+	SelectLimit uint64
+
+	// MaxExecutionTime is the timeout of running a SELECT, set by max_execution_time. 0 means no timeout.
+	MaxExecutionTime time.Duration
+
+	// GroupConcatMaxLen is the most bytes of a GROUP_CONCAT() result, set by group_concat_max_len.
+	GroupConcatMaxLen uint64
+
+	// MaxMemoryMB is the most memory requested for each task of a query, set by gleam_max_memory_mb. 0 means no limit.
+	MaxMemoryMB uint64
+
+	// MaxParallelism is the most shards read from each table by a query, set by gleam_max_parallelism. 0 means no limit.
+	MaxParallelism uint64
 }
 
 // NewSessionVars creates a session vars object.
@@ -131,6 +157,9 @@ func NewSessionVars() *SessionVars {
 		StrictSQLMode: true,
 		Status:        mysql.ServerStatusAutocommit,
 		StmtCtx:       new(StatementContext),
+		SelectLimit:   math.MaxUint64,
+
+		GroupConcatMaxLen: 1024,
 	}
 }
 
@@ -187,6 +216,11 @@ const (
 	CharacterSetResults = "character_set_results"
 	MaxAllowedPacket    = "max_allowed_packet"
 	TimeZone            = "time_zone"
+	SQLSelectLimit      = "sql_select_limit"
+	MaxExecutionTime    = "max_execution_time"
+	GroupConcatMaxLen   = "group_concat_max_len"
+	GleamMaxMemoryMB    = "gleam_max_memory_mb"
+	GleamMaxParallelism = "gleam_max_parallelism"
 )
 
 // StatementContext contains variables for a statement.
@@ -196,6 +230,11 @@ type StatementContext struct {
 	InUpdateOrDeleteStmt bool
 	IgnoreTruncate       bool
 	TruncateAsWarning    bool
+	// InSelectStmt is set for the statements returning rows, e.g. SELECT and SHOW.
+	InSelectStmt bool
+	// TimeZone is the session time zone, in which TIMESTAMP values stored in UTC are shown.
+	// nil means the server local time zone.
+	TimeZone *time.Location
 
 	/* Variables that changes during execution. */
 	mu struct {
@@ -77,6 +77,9 @@ func init() {
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeUnknownSystemVar: mysql.ErrUnknownSystemVariable,
 		CodeIncorrectScope:   mysql.ErrIncorrectGlobalLocalVar,
+		CodeWrongValueForVar: mysql.ErrWrongValueForVar,
+		CodeWrongTypeForVar:  mysql.ErrWrongTypeForVar,
+		CodeUnknownTimeZone:  mysql.ErrUnknownTimeZone,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassVariable] = mySQLErrCodes
 
@@ -102,7 +105,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeSession, "rand_seed2", ""},
 	{ScopeGlobal, "validate_password_number_count", ""},
 	{ScopeSession, "gtid_next", ""},
-	{ScopeGlobal | ScopeSession, "sql_select_limit", "18446744073709551615"},
+	{ScopeGlobal | ScopeSession, SQLSelectLimit, "18446744073709551615"},
 	{ScopeGlobal, "ndb_show_foreign_key_mock_tables", ""},
 	{ScopeNone, "multi_range_count", "256"},
 	{ScopeGlobal | ScopeSession, "default_week_format", "0"},
@@ -535,7 +538,10 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "back_log", "80"},
 	{ScopeNone, "lower_case_file_system", "ON"},
 	{ScopeGlobal, "rpl_semi_sync_master_wait_no_slave", ""},
-	{ScopeGlobal | ScopeSession, "group_concat_max_len", "1024"},
+	{ScopeGlobal | ScopeSession, GroupConcatMaxLen, "1024"},
+	{ScopeGlobal | ScopeSession, MaxExecutionTime, "0"},
+	{ScopeGlobal | ScopeSession, GleamMaxMemoryMB, "0"},
+	{ScopeGlobal | ScopeSession, GleamMaxParallelism, "0"},
 	{ScopeSession, "pseudo_thread_id", ""},
 	{ScopeNone, "socket", "/tmp/myssock"},
 	{ScopeNone, "have_dynamic_loading", "YES"},
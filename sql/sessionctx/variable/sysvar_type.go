@@ -0,0 +1,159 @@
+package variable
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lovelly/gleam/sql/terror"
+)
+
+// VarType is the type of the values of a system variable.
+type VarType int
+
+const (
+	// TypeStr accepts any string.
+	TypeStr VarType = iota
+	// TypeBool accepts ON/OFF, TRUE/FALSE or 1/0, normalized to ON or OFF.
+	TypeBool
+	// TypeUnsigned accepts an integer within [Min, Max].
+	TypeUnsigned
+	// TypeEnum accepts one of Values, case insensitively.
+	TypeEnum
+	// TypeSet accepts a comma separated list of Values, case insensitively.
+	TypeSet
+)
+
+// SysVarType describes the valid values of a system variable.
+type SysVarType struct {
+	Type     VarType
+	Min, Max uint64
+	Values   []string
+}
+
+// Variable value error codes.
+const (
+	CodeWrongValueForVar terror.ErrCode = 1231
+	CodeWrongTypeForVar  terror.ErrCode = 1232
+	CodeUnknownTimeZone  terror.ErrCode = 1298
+)
+
+// Variable value errors.
+var (
+	ErrWrongValueForVar = terror.ClassVariable.New(CodeWrongValueForVar, "Variable '%s' can't be set to the value of '%s'")
+	ErrWrongTypeForVar  = terror.ClassVariable.New(CodeWrongTypeForVar, "Incorrect argument type to variable '%s'")
+	ErrUnknownTimeZone  = terror.ClassVariable.New(CodeUnknownTimeZone, "Unknown or incorrect time zone: '%s'")
+)
+
+// SQL modes accepted by sql_mode.
+var sqlModes = []string{
+	"REAL_AS_FLOAT", "PIPES_AS_CONCAT", "ANSI_QUOTES", "IGNORE_SPACE", "ONLY_FULL_GROUP_BY",
+	"NO_UNSIGNED_SUBTRACTION", "NO_DIR_IN_CREATE", "POSTGRESQL", "ORACLE", "MSSQL", "DB2",
+	"MAXDB", "NO_KEY_OPTIONS", "NO_TABLE_OPTIONS", "NO_FIELD_OPTIONS", "MYSQL323", "MYSQL40",
+	"ANSI", "NO_AUTO_VALUE_ON_ZERO", "NO_BACKSLASH_ESCAPES", "STRICT_TRANS_TABLES",
+	"STRICT_ALL_TABLES", "NO_ZERO_IN_DATE", "NO_ZERO_DATE", "ALLOW_INVALID_DATES",
+	"ERROR_FOR_DIVISION_BY_ZERO", "TRADITIONAL", "NO_AUTO_CREATE_USER", "HIGH_NOT_PRECEDENCE",
+	"NO_ENGINE_SUBSTITUTION", "PAD_CHAR_TO_FULL_LENGTH",
+}
+
+var (
+	boolType     = &SysVarType{Type: TypeBool}
+	unsignedType = &SysVarType{Type: TypeUnsigned, Max: math.MaxUint64}
+)
+
+// sysVarTypes are the types of the system variables that are validated when set.
+// The other variables accept any string.
+var sysVarTypes = map[string]*SysVarType{
+	AutocommitVar:             boolType,
+	SQLModeVar:                {Type: TypeSet, Values: sqlModes},
+	SQLSelectLimit:            unsignedType,
+	MaxExecutionTime:          {Type: TypeUnsigned, Max: math.MaxUint32},
+	GroupConcatMaxLen:         {Type: TypeUnsigned, Min: 4, Max: math.MaxUint64},
+	MaxAllowedPacket:          {Type: TypeUnsigned, Min: 1024, Max: 1 << 30},
+	GleamMaxMemoryMB:          {Type: TypeUnsigned, Max: math.MaxInt32},
+	GleamMaxParallelism:       {Type: TypeUnsigned, Max: math.MaxInt32},
+	"big_tables":              boolType,
+	"foreign_key_checks":      boolType,
+	"unique_checks":           boolType,
+	"sql_auto_is_null":        boolType,
+	"sql_big_selects":         boolType,
+	"sql_log_bin":             boolType,
+	"sql_notes":               boolType,
+	"sql_safe_updates":        boolType,
+	"sql_warnings":            boolType,
+	"div_precision_increment": {Type: TypeUnsigned, Max: 30},
+	"max_join_size":           {Type: TypeUnsigned, Min: 1, Max: math.MaxUint64},
+	"max_sort_length":         {Type: TypeUnsigned, Min: 4, Max: 8388608},
+	"interactive_timeout":     {Type: TypeUnsigned, Min: 1, Max: 31536000},
+	"wait_timeout":            {Type: TypeUnsigned, Min: 1, Max: 31536000},
+	"net_read_timeout":        {Type: TypeUnsigned, Min: 1, Max: 31536000},
+	"net_write_timeout":       {Type: TypeUnsigned, Min: 1, Max: 31536000},
+	"tx_isolation":            {Type: TypeEnum, Values: []string{"READ-UNCOMMITTED", "READ-COMMITTED", "REPEATABLE-READ", "SERIALIZABLE"}},
+}
+
+// GetSysVarType returns the type of a system variable, or nil if it accepts any string.
+func GetSysVarType(name string) *SysVarType {
+	return sysVarTypes[strings.ToLower(name)]
+}
+
+// ValidateSysVar checks the value of a system variable against its type,
+// and returns the value in its normalized form.
+func ValidateSysVar(name, value string) (string, error) {
+	t := GetSysVarType(name)
+	if t == nil {
+		return value, nil
+	}
+	switch t.Type {
+	case TypeBool:
+		switch strings.ToUpper(value) {
+		case "ON", "TRUE", "1":
+			return "ON", nil
+		case "OFF", "FALSE", "0":
+			return "OFF", nil
+		}
+	case TypeUnsigned:
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", ErrWrongTypeForVar.GenByArgs(name)
+		}
+		// out of range values are truncated, like MySQL does
+		if v < t.Min {
+			v = t.Min
+		}
+		if v > t.Max {
+			v = t.Max
+		}
+		return strconv.FormatUint(v, 10), nil
+	case TypeEnum:
+		for _, allowed := range t.Values {
+			if strings.EqualFold(value, allowed) {
+				return allowed, nil
+			}
+		}
+	case TypeSet:
+		if value == "" {
+			return "", nil
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			item = strings.ToUpper(strings.TrimSpace(item))
+			if !containsString(t.Values, item) {
+				return "", ErrWrongValueForVar.GenByArgs(name, item)
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return value, nil
+	}
+	return "", ErrWrongValueForVar.GenByArgs(name, value)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
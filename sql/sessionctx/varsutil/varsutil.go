@@ -14,6 +14,7 @@
 package varsutil
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -63,6 +64,54 @@ func GetGlobalSystemVar(s *variable.SessionVars, key string) (string, error) {
 	return s.GlobalVarsAccessor.GetGlobalSysVar(key)
 }
 
+// SetGlobalSystemVar validates and sets a global system variable.
+// It does not change the variables of the existing sessions.
+func SetGlobalSystemVar(vars *variable.SessionVars, name string, value string) error {
+	name = strings.ToLower(name)
+	sysVar := variable.SysVars[name]
+	if sysVar == nil {
+		return variable.UnknownSystemVar.GenByArgs(name)
+	}
+	if sysVar.Scope&variable.ScopeGlobal == 0 {
+		return variable.ErrIncorrectScope
+	}
+	value, err := variable.ValidateSysVar(name, value)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if name == variable.TimeZone && parseTimeZone(value) == nil {
+		return variable.ErrUnknownTimeZone.GenByArgs(value)
+	}
+	return vars.GlobalVarsAccessor.SetGlobalSysVar(name, value)
+}
+
+// sessionGlobalVars are the global variables copied to new sessions,
+// since they are read from the session fields instead of through GetSessionSystemVar.
+var sessionGlobalVars = []string{
+	variable.SQLModeVar,
+	variable.TimeZone,
+	variable.SQLSelectLimit,
+	variable.MaxExecutionTime,
+	variable.GroupConcatMaxLen,
+	variable.GleamMaxMemoryMB,
+	variable.GleamMaxParallelism,
+}
+
+// LoadGlobalVars initializes the session variables from their global values.
+func LoadGlobalVars(vars *variable.SessionVars) error {
+	for _, name := range sessionGlobalVars {
+		value, err := vars.GlobalVarsAccessor.GetGlobalSysVar(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = SetSessionSystemVar(vars, name, types.NewStringDatum(value)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	vars.CommonGlobalLoaded = true
+	return nil
+}
+
 // epochShiftBits is used to reserve logical part of the timestamp.
 const epochShiftBits = 18
 
@@ -84,9 +133,29 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 	if err != nil {
 		return errors.Trace(err)
 	}
+	sVal, err = variable.ValidateSysVar(name, sVal)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	switch name {
 	case variable.TimeZone:
-		vars.TimeZone = parseTimeZone(sVal)
+		loc := parseTimeZone(sVal)
+		if loc == nil {
+			return variable.ErrUnknownTimeZone.GenByArgs(sVal)
+		}
+		vars.TimeZone = loc
+		vars.StmtCtx.TimeZone = loc
+	case variable.SQLSelectLimit:
+		vars.SelectLimit, _ = strconv.ParseUint(sVal, 10, 64)
+	case variable.MaxExecutionTime:
+		ms, _ := strconv.ParseUint(sVal, 10, 64)
+		vars.MaxExecutionTime = time.Duration(ms) * time.Millisecond
+	case variable.GroupConcatMaxLen:
+		vars.GroupConcatMaxLen, _ = strconv.ParseUint(sVal, 10, 64)
+	case variable.GleamMaxMemoryMB:
+		vars.MaxMemoryMB, _ = strconv.ParseUint(sVal, 10, 64)
+	case variable.GleamMaxParallelism:
+		vars.MaxParallelism, _ = strconv.ParseUint(sVal, 10, 64)
 	case variable.SQLModeVar:
 		sVal = strings.ToUpper(sVal)
 		if strings.Contains(sVal, "STRICT_TRANS_TABLES") || strings.Contains(sVal, "STRICT_ALL_TABLES") {
@@ -0,0 +1,140 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/parser"
+)
+
+// The parser does not support streams, so their statements are matched first:
+//
+//	CREATE STREAM [IF NOT EXISTS] name (column definitions) WITH (option = 'value', ...)
+//	DROP STREAM [IF EXISTS] name
+var (
+	createStream = regexp.MustCompile(`(?is)^\s*CREATE\s+STREAM\s+(IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s*(\(.*\))\s*WITH\s*\((.*)\)\s*;?\s*$`)
+	dropStream   = regexp.MustCompile(`(?is)^\s*DROP\s+STREAM\s+(IF\s+EXISTS\s+)?([\w.]+)\s*;?\s*$`)
+	streamOption = regexp.MustCompile(`(?s)^\s*(\w+)\s*=\s*'((?:[^']|'')*)'\s*(,|$)`)
+)
+
+// execStreamStatement runs CREATE STREAM or DROP STREAM, and tells whether
+// the SQL is one of them. Only the queries with all privileges can run them.
+func execStreamStatement(user, sql string) (bool, error) {
+	create := createStream.FindStringSubmatch(sql)
+	drop := dropStream.FindStringSubmatch(sql)
+	if create == nil && drop == nil {
+		return false, nil
+	}
+	if user != "" {
+		return true, fmt.Errorf("User %s can not create or drop streams", user)
+	}
+	if create != nil {
+		return true, createStreamTable(create[2], create[3], create[4], create[1] != "")
+	}
+	return true, dropStreamTable(drop[2], drop[1] != "")
+}
+
+// createStreamTable registers the stream as a table. The option "type" is
+// the stream source, kafka by default, and the other options are passed to
+// the source.
+func createStreamTable(tableName, columnDefs, withClause string, ifNotExists bool) error {
+	options, err := parseStreamOptions(withClause)
+	if err != nil {
+		return fmt.Errorf("Failed to parse stream %s options: %v", tableName, err)
+	}
+	streamType := strings.ToLower(options["type"])
+	if streamType == "" {
+		streamType = "kafka"
+	}
+	delete(options, "type")
+	open := executor.StreamSources[streamType]
+	if open == nil {
+		return fmt.Errorf("Unknown stream type %s, import github.com/lovelly/gleam/sql/streamsource", streamType)
+	}
+
+	columns, columnElems, err := parseColumnDefs(columnDefs)
+	if err != nil {
+		return fmt.Errorf("Failed to parse stream %s columns: %v", tableName, err)
+	}
+	dbName, name := splitTableName(tableName)
+	key := executor.TableKey(dbName, name)
+	if _, found := executor.GetTable(key); found {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("Table %s.%s already exists", dbName, name)
+	}
+
+	stream := &executor.Stream{Type: streamType, Options: options}
+	for _, c := range columns {
+		stream.Fields = append(stream.Fields, c.ColumnName)
+	}
+	dataset, err := open(flow.New(name), name, stream)
+	if err != nil {
+		return fmt.Errorf("Failed to open stream %s: %v", tableName, err)
+	}
+	executor.AddDatabase(dbName)
+	if !executor.AddTable(key, &executor.TableSource{
+		DBName:      dbName,
+		Dataset:     dataset,
+		TableInfo:   newTableInfo(name, columns, columnElems),
+		Stream:      stream,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	}) && !ifNotExists {
+		// created by a concurrent statement
+		return fmt.Errorf("Table %s.%s already exists", dbName, name)
+	}
+	return nil
+}
+
+func dropStreamTable(tableName string, ifExists bool) error {
+	dbName, name := splitTableName(tableName)
+	key := executor.TableKey(dbName, name)
+	isStream := func(ts *executor.TableSource) bool { return ts.Stream != nil }
+	if !executor.DeleteTable(key, isStream) && !ifExists {
+		return fmt.Errorf("Unknown stream %s.%s", dbName, name)
+	}
+	return nil
+}
+
+// parseStreamOptions parses the "name = 'value'" options, separated by commas.
+// The names are in lower case.
+func parseStreamOptions(withClause string) (map[string]string, error) {
+	options := make(map[string]string)
+	for rest := withClause; strings.TrimSpace(rest) != ""; {
+		m := streamOption.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("expecting name = 'value' at %s", strings.TrimSpace(rest))
+		}
+		options[strings.ToLower(m[1])] = strings.Replace(m[2], "''", "'", -1)
+		rest = rest[len(m[0]):]
+	}
+	return options, nil
+}
+
+// parseColumnDefs parses the column definitions as those of CREATE TABLE,
+// and the members of the ENUM and SET columns.
+func parseColumnDefs(columnDefs string) (columns []executor.TableColumn, columnElems map[string][]string, err error) {
+	tree, err := parser.New().ParseOneStmt("CREATE TABLE stream "+columnDefs, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, col := range tree.(*ast.CreateTableStmt).Cols {
+		columns = append(columns, executor.TableColumn{
+			ColumnName: col.Name.Name.O,
+			ColumnType: col.Tp.Tp,
+		})
+		if len(col.Tp.Elems) > 0 {
+			if columnElems == nil {
+				columnElems = make(map[string][]string)
+			}
+			columnElems[col.Name.Name.O] = col.Tp.Elems
+		}
+	}
+	return columns, columnElems, nil
+}
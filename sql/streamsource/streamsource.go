@@ -0,0 +1,135 @@
+// Package streamsource reads the kafka topics of the tables created by
+// CREATE STREAM. Import it for its side effect:
+//
+//	import _ "github.com/lovelly/gleam/sql/streamsource"
+//
+// The options of the WITH clause are:
+//
+//	brokers          the comma separated kafka brokers, required
+//	topic            the topic, required
+//	group            the consumer group, "gleam-<table>" by default
+//	format           "raw", the default, for one column of the raw messages,
+//	                 "json", "csv", or "avro" with schema_registry
+//	schema_registry  the url of the schema registry of the avro messages
+//	metadata         the comma separated metadata of the last columns, from
+//	                 "key", "partition", "offset", "timestamp" and "window"
+//	window_seconds   the size of the tumbling windows of "window"
+//	batch_seconds    how long each query may read its batch, 10 by default
+//	shards           the number of shards reading the partitions
+//	timeout_seconds  the kafka timeout
+//
+// Each query of the table reads the next batch of messages, up to the last
+// ones when the query starts, so running the query repeatedly aggregates the
+// stream in micro batches. The offsets of the batch are committed after the
+// rows are collected, or written to the sink of INSERT INTO, so a failed
+// query reads the batch again, e.g.
+//
+//	CREATE STREAM clicks (page varchar(255), user varchar(255), window bigint)
+//	WITH (brokers = 'localhost:9092', topic = 'clicks', format = 'json',
+//	      metadata = 'window', window_seconds = '60')
+//
+//	SELECT window, page, count(user) FROM clicks GROUP BY window, page
+package streamsource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/plugins/kafka"
+	_ "github.com/lovelly/gleam/plugins/kafka/avro"
+	"github.com/lovelly/gleam/sql/executor"
+)
+
+func init() {
+	executor.StreamSources["kafka"] = openKafka
+}
+
+func openKafka(f *flow.Flow, name string, stream *executor.Stream) (*flow.Dataset, error) {
+	options := stream.Options
+	brokers, topic := splitList(options["brokers"]), options["topic"]
+	if len(brokers) == 0 || topic == "" {
+		return nil, fmt.Errorf("kafka stream %s needs the brokers and topic options", name)
+	}
+	group := options["group"]
+	if group == "" {
+		group = "gleam-" + name
+	}
+
+	metadata := splitList(options["metadata"])
+	if len(metadata) > len(stream.Fields) {
+		return nil, fmt.Errorf("kafka stream %s has %d metadata for %d columns", name, len(metadata), len(stream.Fields))
+	}
+	fields := stream.Fields[:len(stream.Fields)-len(metadata)]
+
+	windowSeconds, err := intOption(options, "window_seconds", 0)
+	if err != nil {
+		return nil, err
+	}
+	batchSeconds, err := intOption(options, "batch_seconds", 10)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := intOption(options, "shards", 0)
+	if err != nil {
+		return nil, err
+	}
+	timeoutSeconds, err := intOption(options, "timeout_seconds", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	src := kafka.New(brokers, topic, group).
+		Shards(shards).
+		Metadata(metadata...).
+		Window(time.Duration(windowSeconds) * time.Second).
+		Batch(time.Duration(batchSeconds) * time.Second)
+	if timeoutSeconds > 0 {
+		src.Timeout(timeoutSeconds)
+	}
+
+	switch format := strings.ToLower(options["format"]); format {
+	case "", "raw":
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("kafka stream %s of raw messages needs one message column, not %d", name, len(fields))
+		}
+	case "avro":
+		if options["schema_registry"] == "" {
+			return nil, fmt.Errorf("kafka stream %s of avro messages needs the schema_registry option", name)
+		}
+		src.SchemaRegistry(options["schema_registry"]).AvroFields(fields...)
+	case "json", "csv":
+		src.Format(format, fields...)
+	default:
+		return nil, fmt.Errorf("kafka stream %s has unknown format %s", name, format)
+	}
+
+	ds := f.Read(src)
+	if ds == nil {
+		return nil, fmt.Errorf("Failed to read kafka topic %s", topic)
+	}
+	return ds, nil
+}
+
+func splitList(list string) (items []string) {
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func intOption(options map[string]string, name string, defaultValue int) (int, error) {
+	value, found := options[name]
+	if !found {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to parse option %s: %v", name, err)
+	}
+	return n, nil
+}
@@ -18,7 +18,6 @@
 package table
 
 import (
-	"log"
 	"strings"
 
 	"github.com/lovelly/gleam/sql/context"
@@ -97,11 +96,10 @@ func CastValues(ctx context.Context, rec []types.Datum, cols []*Column, ignoreEr
 		var converted types.Datum
 		converted, err = CastValue(ctx, rec[c.Offset], c.ToInfo())
 		if err != nil {
-			if ignoreErr {
-				log.Printf("cast values failed:%v", err)
-			} else {
+			if !ignoreErr {
 				return errors.Trace(err)
 			}
+			ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 		}
 		rec[c.Offset] = converted
 	}
@@ -115,8 +113,7 @@ func CastValue(ctx context.Context, val types.Datum, col *model.ColumnInfo) (cas
 		if ctx.GetSessionVars().StrictSQLMode {
 			return casted, errors.Trace(err)
 		}
-		// TODO: add warnings.
-		log.Printf("cast value error %v", err)
+		ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 	}
 	return casted, nil
 }
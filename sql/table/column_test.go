@@ -0,0 +1,38 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/sql/model"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+)
+
+type testContext struct {
+	vars *variable.SessionVars
+}
+
+func (c *testContext) SetValue(key fmt.Stringer, value interface{}) {}
+func (c *testContext) Value(key fmt.Stringer) interface{}           { return nil }
+func (c *testContext) ClearValue(key fmt.Stringer)                  {}
+func (c *testContext) GetSessionVars() *variable.SessionVars        { return c.vars }
+
+func TestCastValueWarnings(t *testing.T) {
+	ctx := &testContext{vars: variable.NewSessionVars()}
+	col := &model.ColumnInfo{FieldType: *types.NewFieldType(mysql.TypeLonglong)}
+
+	ctx.vars.StrictSQLMode = true
+	if _, err := CastValue(ctx, types.NewStringDatum("abc"), col); err == nil {
+		t.Errorf("expected an error in strict mode")
+	}
+
+	ctx.vars.StrictSQLMode = false
+	if _, err := CastValue(ctx, types.NewStringDatum("abc"), col); err != nil {
+		t.Errorf("expected a warning in non-strict mode, got error %v", err)
+	}
+	if warns := ctx.vars.StmtCtx.GetWarnings(); len(warns) != 1 {
+		t.Errorf("expected 1 warning, got %v", warns)
+	}
+}
@@ -0,0 +1,184 @@
+package sql
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lovelly/gleam/filesystem"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/util"
+)
+
+// The parser reads the table functions as the names of tables read only by
+// the statement, see ast.TableFunction:
+//
+//	FROM FILES('path or pattern' [, 'format' [, 'column definitions']])
+//	FROM RANGE([start,] stop [, step])
+//
+// FILES reads the files like RegisterFileTable(), each file by its own
+// shard. The format is csv, tsv, txt, jsonl, orc or parquet, by default the
+// file extension, ignoring the compression extension, e.g.
+// "logs/*.jsonl.gz". The column definitions are those of CREATE TABLE, e.g.
+// 'name varchar(255), age int', and are the json fields of jsonl files.
+// Without them, txt and jsonl files are read as a "line" column.
+//
+// RANGE generates the integers from start, 0 by default, to stop, excluded,
+// as a "n" column.
+
+// compressionExtensions are ignored to find the format of the files.
+var compressionExtensions = map[string]bool{".gz": true, ".bz2": true, ".zst": true, ".lz4": true, ".snappy": true}
+
+// tableFunctionCollector creates the tables of the table functions of the
+// statement, by table name.
+type tableFunctionCollector struct {
+	tables map[string]*executor.TableSource
+	err    error
+}
+
+func (v *tableFunctionCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok && t.Function != nil && v.err == nil {
+		ts, err := newTableFunction(t.Name.O, t.Function.Name, t.Function.Args)
+		if err != nil {
+			v.err = err
+			return n, true
+		}
+		if v.tables == nil {
+			v.tables = make(map[string]*executor.TableSource)
+		}
+		v.tables[t.Name.O] = ts
+	}
+	return n, false
+}
+
+func (v *tableFunctionCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func newTableFunction(name, function string, args []string) (*executor.TableSource, error) {
+	if function == "RANGE" {
+		return newRangeTable(name, args)
+	}
+	return newFilesTable(name, args)
+}
+
+func newFilesTable(name string, args []string) (*executor.TableSource, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, fmt.Errorf("FILES() needs the path, and optionally the format and the column definitions")
+	}
+	location := &executor.TableLocation{Path: args[0], PartitionCount: countFiles(args[0])}
+	if len(args) > 1 {
+		location.FileType = strings.ToLower(args[1])
+	} else {
+		fileName := args[0]
+		if ext := path.Ext(fileName); compressionExtensions[ext] {
+			fileName = strings.TrimSuffix(fileName, ext)
+		}
+		location.FileType = strings.TrimPrefix(path.Ext(fileName), ".")
+	}
+
+	columns := []executor.TableColumn{{ColumnName: "line", ColumnType: mysql.TypeVarchar}}
+	var columnElems map[string][]string
+	if len(args) > 2 {
+		var err error
+		if columns, columnElems, err = parseColumnDefs("(" + args[2] + ")"); err != nil {
+			return nil, fmt.Errorf("Failed to parse FILES() columns %s: %v", args[2], err)
+		}
+		if location.FileType == "jsonl" {
+			for _, c := range columns {
+				location.Fields = append(location.Fields, c.ColumnName)
+			}
+		}
+	} else if location.FileType != "txt" && location.FileType != "jsonl" {
+		return nil, fmt.Errorf("FILES() of %s files needs the column definitions", args[0])
+	}
+	if executor.OpenTableLocation == nil {
+		return nil, fmt.Errorf("Failed to read %s: no file source, import github.com/lovelly/gleam/sql/filesource", args[0])
+	}
+
+	return &executor.TableSource{
+		TableInfo:   newTableInfo(name, columns, columnElems),
+		Location:    location,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	}, nil
+}
+
+// countFiles counts the files of the path or pattern, at least 1.
+func countFiles(fileOrPattern string) int {
+	folder, pattern := path.Split(fileOrPattern)
+	if folder == "" || !strings.ContainsAny(pattern, "*?[") || strings.ContainsAny(folder, "*?[") {
+		return 1
+	}
+	files, err := filesystem.List(strings.TrimSuffix(folder, "/"))
+	if err != nil {
+		return 1
+	}
+	count := 0
+	for _, file := range files {
+		if matched, _ := path.Match(pattern, path.Base(file.Location)); matched {
+			count++
+		}
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+func newRangeTable(name string, args []string) (*executor.TableSource, error) {
+	var numbers []int64
+	for _, arg := range args {
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("RANGE() needs integers, not %s", arg)
+		}
+		numbers = append(numbers, n)
+	}
+	r := &rangeSource{step: 1}
+	switch len(numbers) {
+	case 1:
+		r.stop = numbers[0]
+	case 3:
+		r.step = numbers[2]
+		fallthrough
+	case 2:
+		r.start, r.stop = numbers[0], numbers[1]
+	default:
+		return nil, fmt.Errorf("RANGE() needs the stop, or the start, stop and step")
+	}
+	if r.step == 0 {
+		return nil, fmt.Errorf("RANGE() step can not be 0")
+	}
+
+	columns := []executor.TableColumn{{ColumnName: "n", ColumnType: mysql.TypeLonglong}}
+	return &executor.TableSource{
+		TableInfo: newTableInfo(name, columns, nil),
+		Source:    r,
+		Columns:   columns,
+	}, nil
+}
+
+// rangeSource generates the integers of RANGE().
+type rangeSource struct {
+	start, stop, step int64
+}
+
+func (r *rangeSource) Generate(f *flow.Flow) *flow.Dataset {
+	return f.Source("range", func(writer io.Writer, stats *pb.InstructionStat) error {
+		stats.InputCounter++
+		for n := r.start; (r.step > 0 && n < r.stop) || (r.step < 0 && n > r.stop); n += r.step {
+			stats.OutputCounter++
+			if err := util.NewRow(util.Now(), n).WriteTo(writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql/ast"
+	"github.com/lovelly/gleam/sql/executor"
+)
+
+// The parser does not support temporary tables, so QueryManager matches
+// their statements first:
+//
+//	CREATE TEMPORARY TABLE [IF NOT EXISTS] name [AS] SELECT ...
+//	DROP TEMPORARY TABLE [IF EXISTS] name
+var (
+	createTemporaryTable = regexp.MustCompile(`(?is)^\s*CREATE\s+TEMPORARY\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s+(?:AS\s+)?(\(?\s*SELECT\b.*)$`)
+	dropTemporaryTable   = regexp.MustCompile(`(?is)^\s*DROP\s+TEMPORARY\s+TABLE\s+(IF\s+EXISTS\s+)?([\w.]+)\s*;?\s*$`)
+)
+
+// createTemporaryTable runs the query, and keeps its rows as the table of the
+// session, until it is dropped or the session is closed. The rows of each
+// partition are kept as a cached shard, see flow.Dataset.CacheTo(), which the
+// following statements of the session read. The table is only added if all
+// the shards are kept, and hides a registered table of the same name.
+func (m *QueryManager) createTemporaryTable(ctx context.Context, s *managedSession, user, tableName, selectSQL string, ifNotExists bool) error {
+	s.Lock()
+	dbName, name := s.tableName(tableName)
+	key := executor.TableKey(dbName, name)
+	_, exists := s.tempTables[key]
+	s.Unlock()
+	if exists {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("Temporary table %s.%s already exists", dbName, name)
+	}
+	if !executor.HasDatabase(dbName) {
+		return fmt.Errorf("Unknown database %s", dbName)
+	}
+
+	stmt, err := parseStatement(selectSQL)
+	if err != nil {
+		return err
+	}
+	switch stmt.tree.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+	default:
+		return fmt.Errorf("Temporary table %s needs a SELECT: %s", tableName, selectSQL)
+	}
+	if stmt.outfile != nil {
+		return fmt.Errorf("Temporary table %s can not be written INTO OUTFILE", tableName)
+	}
+
+	ds, p, unlock, err := m.exec(s, user, stmt, s.dirty.Statement())
+	defer unlock()
+	if err != nil {
+		return err
+	}
+	var columns []executor.TableColumn
+	columnElems := make(map[string][]string)
+	for _, col := range p.GetSchema().Columns {
+		columns = append(columns, executor.TableColumn{
+			ColumnName: col.ColName.O,
+			ColumnType: col.RetType.Tp,
+		})
+		if len(col.RetType.Elems) > 0 {
+			columnElems[col.ColName.O] = col.RetType.Elems
+		}
+	}
+
+	cache := &executor.TableCache{
+		Name:       fmt.Sprintf("sql-temporary-%d-%d", s.vars.ConnectionID, atomic.AddInt64(&m.tempCount, 1)),
+		ShardCount: len(ds.Shards),
+	}
+	if _, err := ds.CacheTo(ctx, cache.Name, m.options...); err != nil {
+		flow.Uncache(cache.Name)
+		return fmt.Errorf("Failed to create temporary table %s.%s: %v", dbName, name, err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if _, exists := s.tempTables[key]; exists {
+		flow.Uncache(cache.Name)
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("Temporary table %s.%s already exists", dbName, name)
+	}
+	s.tempTables[key] = &executor.TableSource{
+		DBName:      strings.ToLower(dbName),
+		TableInfo:   newTableInfo(name, columns, columnElems),
+		Cache:       cache,
+		Columns:     columns,
+		ColumnElems: columnElems,
+	}
+	return nil
+}
+
+func (s *managedSession) dropTemporaryTable(tableName string, ifExists bool) error {
+	s.Lock()
+	defer s.Unlock()
+	dbName, name := s.tableName(tableName)
+	key := executor.TableKey(dbName, name)
+	if _, found := s.tempTables[key]; !found {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("Unknown temporary table %s.%s", dbName, name)
+	}
+	s.removeTemporaryTable(key)
+	return nil
+}
+
+// removeTemporaryTable forgets the table and drops its shards kept locally.
+// The agents drop theirs when unused, see flow.Dataset.Cache().
+// The session is locked.
+func (s *managedSession) removeTemporaryTable(key string) {
+	if ts := s.tempTables[key]; ts != nil && ts.Cache != nil {
+		flow.Uncache(ts.Cache.Name)
+	}
+	delete(s.tempTables, key)
+}
+
+// tableName splits the table name, in the current database if not qualified.
+// The session is locked.
+func (s *managedSession) tableName(tableName string) (dbName, name string) {
+	if i := strings.LastIndex(tableName, "."); i >= 0 {
+		return tableName[:i], tableName[i+1:]
+	}
+	return s.vars.CurrentDB, tableName
+}
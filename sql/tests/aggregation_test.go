@@ -0,0 +1,134 @@
+package sql
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestAggregation(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	for _, test := range []struct {
+		query    string
+		format   string
+		expected string
+	}{
+		{
+			"select word, count(*), sum(line), min(line), max(line), avg(line) from words group by word",
+			"%v %v %v %v %v %v\n",
+			"a 1 3 3 3 3\nis 2 2 2 2 2\nthis 3 9 1 5 3",
+		},
+		{
+			"select count(*), count(line), sum(line) from words",
+			"%v %v %v\n",
+			"6 5 14",
+		},
+	} {
+		f := flow.New("testAggregation")
+		words := f.Slices([][]interface{}{
+			{"this", 1},
+			{"is", 2},
+			{"a", 3},
+			{"this", 3},
+			{"this", 5},
+			{"is", nil},
+		}).RoundRobin("rr", 3)
+
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(words, "words", []executor.TableColumn{
+			{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+			{ColumnName: "line", ColumnType: mysql.TypeLong},
+		})
+
+		out, _, err := sql.Query(test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, test.format)
+		f.Run()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		sort.Strings(lines)
+		if actual := strings.Join(lines, "\n"); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.query, test.expected, actual)
+		}
+	}
+}
+
+func TestAggregationOfDecimals(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testAggregationOfDecimals")
+	prices := f.Slices([][]interface{}{
+		{"a", "0.1"},
+		{"a", "0.1"},
+		{"a", "0.1"},
+		{"b", "0.2"},
+		{"b", "0.1"},
+		{"b", nil},
+	}).RoundRobin("rr", 3)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(prices, "prices", []executor.TableColumn{
+		{ColumnName: "item", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "price", ColumnType: mysql.TypeNewDecimal},
+	})
+
+	query := "select item, sum(price), avg(price), min(price), max(price) from prices group by item"
+	out, _, err := sql.Query(query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	// the groups are aggregated by the shards they are partitioned to
+	if len(out.Shards) != 3 {
+		t.Errorf("%s: expected 3 shards, got %d", query, len(out.Shards))
+	}
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v %v %v %v\n")
+	f.Run()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	sort.Strings(lines)
+	// the sums are exact, unlike the float64 0.30000000000000004
+	expected := "a 0.3 0.10000 0.1 0.1\nb 0.3 0.15000 0.1 0.2"
+	if actual := strings.Join(lines, "\n"); actual != expected {
+		t.Errorf("%s: expected %q, got %q", query, expected, actual)
+	}
+}
+
+func TestAggregationOfNoRows(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testAggregationOfNoRows")
+	words := f.Slices([][]interface{}{}).RoundRobin("rr", 2)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+
+	query := "select count(*), sum(line) from words"
+	out, _, err := sql.Query(query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v\n")
+	f.Run()
+
+	if actual, expected := strings.TrimSpace(buf.String()), "0 <nil>"; actual != expected {
+		t.Errorf("%s: expected %q, got %q", query, expected, actual)
+	}
+}
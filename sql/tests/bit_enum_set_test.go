@@ -0,0 +1,83 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+	"github.com/lovelly/gleam/sql/util/types"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestBitEnumSetColumns(t *testing.T) {
+	gio.Init()
+
+	states := []string{"open", "done"}
+	tags := []string{"a", "b", "c"}
+	row := func(name string, state string, tag string, flags uint64) []interface{} {
+		e, _ := types.ParseEnumName(states, state)
+		s, _ := types.ParseSetName(tags, tag)
+		return executor.EncodeRowValues([]types.Datum{
+			types.NewStringDatum(name),
+			types.NewDatum(e),
+			types.NewDatum(s),
+			types.NewDatum(types.Bit{Value: flags, Width: types.MaxBitWidth}),
+		})
+	}
+
+	f := flow.New("testBitEnumSet")
+	tasks := f.Slices([][]interface{}{
+		row("write", "done", "a,c", 5),
+		row("review", "open", "b", 0xffffffffffffffff),
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(tasks, "tasks", []executor.TableColumn{
+		{ColumnName: "name", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "state", ColumnType: mysql.TypeEnum},
+		{ColumnName: "tags", ColumnType: mysql.TypeSet},
+		{ColumnName: "flags", ColumnType: mysql.TypeBit},
+	}, sql.ColumnElems("state", states...), sql.ColumnElems("tags", tags...))
+
+	out, p, err := sql.Query("select name, state, tags, flags from tasks")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	sc := &variable.StatementContext{}
+	var got []string
+	out.OutputRow(func(r *util.Row) error {
+		datums, err := executor.DecodeRowValues(sc, p.GetSchema(), append(r.K, r.V...))
+		if err != nil {
+			return err
+		}
+		line := ""
+		for _, d := range datums {
+			s, err := d.ToString()
+			if err != nil {
+				return err
+			}
+			line += s + "|"
+		}
+		got = append(got, line)
+		return nil
+	})
+	f.Run()
+
+	expected := map[string]bool{
+		"write|done|a,c|\x00\x00\x00\x00\x00\x00\x00\x05|": true,
+		"review|open|b|\xff\xff\xff\xff\xff\xff\xff\xff|":  true,
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d rows, got %q", len(expected), got)
+	}
+	for _, line := range got {
+		if !expected[line] {
+			t.Errorf("unexpected row %q", line)
+		}
+	}
+}
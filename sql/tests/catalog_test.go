@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestSaveAndLoadCatalog(t *testing.T) {
+	gio.Init()
+
+	defer executor.SetDatabases(executor.DatabaseNames())
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{location.Path, int64(location.PartitionCount)}}), nil
+	}
+
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "catalog.json")
+
+	columns := []executor.TableColumn{
+		{ColumnName: "path", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "partitions", ColumnType: mysql.TypeLonglong},
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	if err := sql.RegisterFileTable(flow.New("testCatalog"), "saved.orders", columns, executor.TableLocation{
+		FileType:       "csv",
+		Path:           "/data/orders.csv",
+		PartitionCount: 3,
+		HasHeader:      true,
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	sql.RegisterTable(flow.New("testCatalog").Slices(nil), "unsaved", columns)
+	if err := sql.SaveCatalog(fileName); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	executor.SetTables(make(map[string]*executor.TableSource))
+	executor.SetDatabases([]string{executor.DefaultDB})
+	f := flow.New("testCatalog")
+	if err := sql.LoadCatalog(f, fileName); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !executor.HasDatabase("saved") {
+		t.Errorf("expected the saved database to be loaded")
+	}
+	if len(executor.AllTables()) != 1 {
+		t.Errorf("expected only the file table to be saved, got %d tables", len(executor.AllTables()))
+	}
+
+	out, _, err := sql.Query("select path, partitions from saved.orders")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v\n")
+	f.Run()
+
+	if got := strings.TrimSpace(buf.String()); got != "/data/orders.csv 3" {
+		t.Errorf("unexpected rows %q", got)
+	}
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+)
+
+func TestCommonSubexpressionElimination(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testCommonSubexpressions")
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+		{"is", 2},
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+
+	_, p, err := sql.Query("select (line + 1) * 2, (line + 1) * 3, word from words")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	proj, ok := p.(*plan.Projection)
+	if !ok {
+		t.Fatalf("expected a projection, got %s", plan.ToString(p))
+	}
+	computed, ok := proj.GetChildByIndex(0).(*plan.Projection)
+	if !ok {
+		t.Fatalf("expected a projection computing line + 1, got %s", plan.ToString(p))
+	}
+	if last := computed.Exprs[len(computed.Exprs)-1]; last.String() != "plus(gleam.words.line, 1)" {
+		t.Errorf("expected line + 1 to be computed once, got %v", computed.Exprs)
+	}
+
+	// the branches of CASE are not evaluated for every row
+	_, p, err = sql.Query("select case when line > 1 then 10 / line end, case when line > 2 then 10 / line end from words")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if _, ok := p.GetChildByIndex(0).(*plan.Projection); ok {
+		t.Errorf("expected no extracted subexpressions, got %s", plan.ToString(p))
+	}
+}
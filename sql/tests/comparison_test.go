@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+)
+
+func TestComparisonsWithRoundedConstants(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	for _, test := range []struct {
+		condition string
+		expected  string
+		selection string
+	}{
+		{"n > 1.9", "2 3", "ge(gleam.nums.n, 2)"},
+		{"n >= 1.1", "2 3", "gt(gleam.nums.n, 1)"},
+		{"n < 2.1", "1 2", "le(gleam.nums.n, 2)"},
+		{"1.9 < n", "2 3", "ge(gleam.nums.n, 2)"},
+		{"n <= 1e30", "1 2 3", "le(gleam.nums.n, 9223372036854775807)"},
+		{"n > 2", "3", "gt(gleam.nums.n, 2)"},
+	} {
+		f := flow.New("testComparisons")
+		nums := f.Slices([][]interface{}{{1}, {2}, {3}})
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(nums, "nums", []executor.TableColumn{
+			{ColumnName: "n", ColumnType: mysql.TypeLonglong},
+		})
+
+		out, p, err := sql.Query("select n from nums where " + test.condition)
+		if err != nil {
+			t.Fatalf("%s: %v", test.condition, err)
+		}
+		if s := scanCondition(p); s != test.selection {
+			t.Errorf("%s: expected the condition %s, got %s", test.condition, test.selection, s)
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, "%v\n")
+		f.Run()
+		if got := strings.Join(strings.Fields(buf.String()), " "); got != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.condition, got, test.expected)
+		}
+	}
+}
+
+// scanCondition returns the condition evaluated on the rows of the table.
+func scanCondition(p plan.Plan) string {
+	if scan, ok := p.(*plan.PhysicalUnionScan); ok {
+		return scan.Condition.String()
+	}
+	for _, child := range p.GetChildren() {
+		if s := scanCondition(child); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func TestMergedColumnRanges(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	defer func(maxRanges int) { plan.MaxRanges = maxRanges }(plan.MaxRanges)
+
+	for _, test := range []struct {
+		condition string
+		maxRanges int
+		expected  string
+		selection string
+	}{
+		{"n in (1, 2) or n between 1 and 3", 1024, "1 2 3", "_and(ge(gleam.nums.n, 1), le(gleam.nums.n, 3))"},
+		{"n = 4 or n > 2 or n = 1", 1024, "1 3 4", "_or(gt(gleam.nums.n, 2), eq(gleam.nums.n, 1))"},
+		{"n = 1 or n = 3", 1024, "1 3", "_or(eq(gleam.nums.n, 1), eq(gleam.nums.n, 3))"},
+		// beyond MaxRanges, the full range is kept with the residual filter
+		{"n in (1, 3, 4)", 2, "1 3 4", "_in(gleam.nums.n, 1, 3, 4)"},
+		{"n = 1 or n = 3 or n = 4", 2, "1 3 4", "_or(eq(gleam.nums.n, 1), _or(eq(gleam.nums.n, 3), eq(gleam.nums.n, 4)))"},
+	} {
+		plan.MaxRanges = test.maxRanges
+		f := flow.New("testRanges")
+		nums := f.Slices([][]interface{}{{1}, {2}, {3}, {4}, {nil}})
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(nums, "nums", []executor.TableColumn{
+			{ColumnName: "n", ColumnType: mysql.TypeLonglong},
+		})
+
+		out, p, err := sql.Query("select n from nums where " + test.condition)
+		if err != nil {
+			t.Fatalf("%s: %v", test.condition, err)
+		}
+		if s := scanCondition(p); s != test.selection {
+			t.Errorf("%s: expected the condition %s, got %s", test.condition, test.selection, s)
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, "%v\n")
+		f.Run()
+		if got := strings.Join(strings.Fields(buf.String()), " "); got != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.condition, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestDatabases(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+	}
+	defer executor.SetDatabases(executor.DatabaseNames())
+	defer sql.Query("use " + executor.DefaultDB)
+
+	query := func(text string) string {
+		f := flow.New("testDatabases")
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(f.Slices([][]interface{}{{"default"}}), "words", columns)
+		sql.RegisterTable(f.Slices([][]interface{}{{"other"}}), "Other.words", columns)
+
+		out, _, err := sql.Query(text)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, "%v\n")
+		f.Run()
+		return strings.TrimSpace(buf.String())
+	}
+
+	if got := query("select word from words"); got != "default" {
+		t.Errorf("expected the table of the default database, got %q", got)
+	}
+	if got := query("select word from other.words"); got != "other" {
+		t.Errorf("expected the table of the other database, got %q", got)
+	}
+	if _, _, err := sql.Query("use missing"); err == nil {
+		t.Errorf("expected an unknown database error")
+	}
+	if _, _, err := sql.Query("use other"); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if got := query("select word from words"); got != "other" {
+		t.Errorf("expected the table of the current database, got %q", got)
+	}
+	if got := query("select word from gleam.words"); got != "default" {
+		t.Errorf("expected the table of the default database, got %q", got)
+	}
+
+	if _, _, err := sql.Query("create database other"); err == nil {
+		t.Errorf("expected a database exists error")
+	}
+	for _, stmt := range []string{
+		"create database if not exists other",
+		"create database if not exists empty",
+		"use empty",
+	} {
+		if _, _, err := sql.Query(stmt); err != nil {
+			t.Errorf("%s: %v", stmt, err)
+		}
+	}
+	if _, _, err := sql.Query("select word from words"); err == nil {
+		t.Errorf("expected a table not exists error in the empty database")
+	}
+}
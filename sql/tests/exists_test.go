@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestExists(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	for _, test := range []struct {
+		query    string
+		format   string
+		expected string
+	}{
+		{
+			"select word from words where exists (select 1 from docs where num = line)",
+			"%v\n",
+			"a\nthis",
+		},
+		{
+			"select word from words where exists (select 1 from docs where num = line limit 1)",
+			"%v\n",
+			"a\nthis",
+		},
+		{
+			"select word, exists (select 1 from docs where num = line) from words",
+			"%v %v\n",
+			"a 1\nis 0\nnull 0\nthis 1",
+		},
+		{
+			// the NULL num makes NOT IN NULL for the lines it has no match for
+			"select word from words where line not in (select num from docs)",
+			"%v\n",
+			"",
+		},
+		{
+			"select word, line not in (select num from docs) from words",
+			"%v %v\n",
+			"a 0\nis <nil>\nnull <nil>\nthis 0",
+		},
+		{
+			"select word from words where line not in (select num from docs where num is not null)",
+			"%v\n",
+			"is",
+		},
+		{
+			// NOT IN of no rows is true, also for a NULL line
+			"select word from words where line not in (select num from docs where num > 10)",
+			"%v\n",
+			"a\nis\nnull\nthis",
+		},
+	} {
+		f := flow.New("testExists")
+		words := f.Slices([][]interface{}{
+			{"this", 1},
+			{"is", 2},
+			{"a", 3},
+			{"null", nil},
+		}).RoundRobin("rr", 2)
+		docs := f.Slices([][]interface{}{
+			{1, "first"},
+			{3, "third"},
+			{3, "again"},
+			{nil, "none"},
+		})
+
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(words, "words", []executor.TableColumn{
+			{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+			{ColumnName: "line", ColumnType: mysql.TypeLong},
+		})
+		sql.RegisterTable(docs, "docs", []executor.TableColumn{
+			{ColumnName: "num", ColumnType: mysql.TypeLong},
+			{ColumnName: "title", ColumnType: mysql.TypeVarchar},
+		})
+
+		out, _, err := sql.Query(test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, test.format)
+		f.Run()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		sort.Strings(lines)
+		if actual := strings.Join(lines, "\n"); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.query, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestPipeFunction(t *testing.T) {
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLonglong},
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	m := sql.NewQueryManager(1)
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"create function shout as pipe 'tr a-z A-Z'",
+		"create function twice as pipe 'awk ''{ print $1 * 2 }''' returns bigint",
+		"create function if not exists shout as pipe 'cat'",
+	} {
+		if _, err := m.Run(ctx, "a", "", stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	defer m.Run(ctx, "a", "", "drop function if exists shout")
+	defer m.Run(ctx, "a", "", "drop function if exists twice")
+
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{"select shout(word) from words", "[[THIS] [IS] [A]]"},
+		{"select word, twice(line) + 1 from words where line > 1", "[[is 5] [a 7]]"},
+		{"select shout(concat(word, '!')), line from words where line = 3", "[[A! 3]]"},
+	} {
+		words := flow.New("testPipeFunction").Slices([][]interface{}{{"this", 1}, {"is", 2}, {"a", 3}})
+		sql.RegisterTable(words, "words", columns)
+		rows, err := m.Run(ctx, "a", "", test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		if actual := fmt.Sprint(rows); actual != test.expected {
+			t.Errorf("%s: %s, expecting %s", test.query, actual, test.expected)
+		}
+	}
+
+	// NULL is piped as \N, and the answers \N are NULL
+	words := flow.New("testPipeFunction").Slices([][]interface{}{{nil, 1}, {"this", 2}})
+	sql.RegisterTable(words, "words", columns)
+	rows, err := m.Run(ctx, "a", "", "select shout(word), line from words")
+	if actual := fmt.Sprint(rows); err != nil || actual != "[[<nil> 1] [THIS 2]]" {
+		t.Errorf("shout NULL: %s, %v", actual, err)
+	}
+
+	// a table named like a function is not called
+	var inserted []string
+	sql.RegisterSink("shout", columns, func(row []interface{}) error {
+		inserted = append(inserted, fmt.Sprint(row))
+		return nil
+	})
+	words = flow.New("testPipeFunction").Slices([][]interface{}{{"this", 2}})
+	sql.RegisterTable(words, "words", columns)
+	if _, err := m.Run(ctx, "a", "", "insert into shout (word, line) select shout(word), line from words where line = 2"); err != nil {
+		t.Errorf("insert into shout: %v", err)
+	}
+	if actual := fmt.Sprint(inserted); actual != "[[THIS 2]]" {
+		t.Errorf("inserted %s", actual)
+	}
+
+	for _, stmt := range []string{
+		"create function concat as pipe 'cat'",
+		"create function left as pipe 'cat'",
+	} {
+		if _, err := m.Run(ctx, "a", "", stmt); err == nil {
+			t.Errorf("%s: expected an error naming a function after a builtin function or a keyword", stmt)
+		}
+	}
+
+	words = flow.New("testPipeFunction").Slices([][]interface{}{{"this", 1}})
+	sql.RegisterTable(words, "words", columns)
+	if _, err := m.Run(ctx, "a", "", "select word from words where shout(word) = 'A'"); err == nil {
+		t.Errorf("expected an error calling a function run by a pipe in the filters")
+	}
+	if _, err := m.Run(ctx, "a", "user", "drop function shout"); err == nil {
+		t.Errorf("expected an error dropping a function without all privileges")
+	}
+	if _, err := m.Run(ctx, "a", "", "drop function shout"); err != nil {
+		t.Errorf("drop function: %v", err)
+	}
+	if _, err := m.Run(ctx, "a", "", "select shout(word) from words"); err == nil {
+		t.Errorf("expected an error calling a dropped function")
+	}
+}
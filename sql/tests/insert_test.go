@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestInsertSelect(t *testing.T) {
+	gio.Init()
+
+	if _, _, err := sql.Query("set sql_mode = ''"); err != nil {
+		t.Fatalf("set sql_mode: %v", err)
+	}
+	defer sql.Query("set sql_mode = default")
+
+	f := flow.New("testInsert")
+	words := f.Slices([][]interface{}{
+		{"this", "1"},
+		{"is", "2x"},
+	})
+
+	var mu sync.Mutex
+	written := make(map[string]int64)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeVarchar},
+	})
+	sql.RegisterSink("numbered", []executor.TableColumn{
+		{ColumnName: "num", ColumnType: mysql.TypeLonglong},
+		{ColumnName: "label", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "note", ColumnType: mysql.TypeVarchar},
+	}, func(row []interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if row[2] != nil {
+			t.Errorf("expected the note to default to NULL, got %v", row[2])
+		}
+		written[row[1].(string)] = row[0].(int64)
+		return nil
+	})
+
+	if _, _, err := sql.Query("insert into numbered select word from words"); err == nil {
+		t.Errorf("expected a column count error")
+	}
+	if _, _, err := sql.Query("select num from numbered"); err == nil {
+		t.Errorf("expected an error reading a sink")
+	}
+
+	out, _, err := sql.Query("insert into numbered (label, num) select word, line from words")
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	out.Run()
+
+	if len(written) != 2 || written["this"] != 1 || written["is"] != 2 {
+		t.Errorf("unexpected rows written: %v", written)
+	}
+	if rows := sql.AffectedRows(); rows != 2 {
+		t.Errorf("expected 2 affected rows, got %d", rows)
+	}
+	if warns := sql.Warnings(); len(warns) != 1 {
+		t.Errorf("expected 1 conversion warning, got %v", warns)
+	}
+}
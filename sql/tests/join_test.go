@@ -0,0 +1,279 @@
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/util"
+)
+
+func TestHashJoinStrategies(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	for _, limit := range []uint64{plan.BroadcastJoinRowLimit, 0} {
+		saved := plan.BroadcastJoinRowLimit
+		plan.BroadcastJoinRowLimit = limit
+
+		f := flow.New("testJoin")
+
+		words := f.Slices([][]interface{}{
+			{"this", 1},
+			{"is", 2},
+			{"a", 3},
+			{"table", 3},
+		}).RoundRobin("rr", 2).Hint(flow.TotalSize(64))
+
+		docs := f.Slices([][]interface{}{
+			{1, "first"},
+			{3, "third"},
+		}).Hint(flow.TotalSize(1))
+
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(words, "words", []executor.TableColumn{
+			{"word", mysql.TypeVarchar},
+			{"line", mysql.TypeLong},
+		})
+		sql.RegisterTable(docs, "docs", []executor.TableColumn{
+			{"num", mysql.TypeLong},
+			{"name", mysql.TypeVarchar},
+		})
+
+		out, p, err := sql.Query("select word, line, num, name from words, docs where line = num")
+		plan.BroadcastJoinRowLimit = saved
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+
+		expected := plan.BroadcastHashJoin
+		if limit == 0 {
+			expected = plan.PartitionedHashJoin
+		}
+		if strategy := findJoinStrategy(p); strategy != expected {
+			t.Errorf("limit %d: join strategy %v, expected %v", limit, strategy, expected)
+		}
+
+		var buf bytes.Buffer
+		out.Fprintf(&buf, "%v %v %v %v\n")
+		f.Run()
+
+		lines2 := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines2) != 3 {
+			t.Errorf("limit %d: expected 3 joined rows, got %q", limit, buf.String())
+		}
+		for _, line := range lines2 {
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[1] != fields[2] {
+				t.Errorf("limit %d: unexpected joined row %q", limit, line)
+			}
+		}
+	}
+}
+
+func findJoinStrategy(p plan.Plan) plan.JoinStrategy {
+	if join, ok := p.(*plan.PhysicalHashJoin); ok {
+		return join.Strategy
+	}
+	for _, c := range p.GetChildren() {
+		if s := findJoinStrategy(c); s != plan.SortMergeJoin {
+			return s
+		}
+	}
+	return plan.SortMergeJoin
+}
+
+func TestApplyBatches(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testApply")
+
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+		{"is", 2},
+		{"a", 3},
+		{"table", 3},
+	}).Hint(flow.TotalSize(64))
+
+	docs := f.Slices([][]interface{}{
+		{1, "first"},
+		{3, "third"},
+	}).Hint(flow.TotalSize(1))
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{"word", mysql.TypeVarchar},
+		{"line", mysql.TypeLong},
+	})
+	sql.RegisterTable(docs, "docs", []executor.TableColumn{
+		{"num", mysql.TypeLong},
+		{"name", mysql.TypeVarchar},
+	})
+
+	out, p, err := sql.Query("select word, line, (select num from docs where num = line) from words")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if !strings.Contains(plan.ToString(p), "Apply") {
+		t.Errorf("expected an apply plan: %s", plan.ToString(p))
+	}
+
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v %v\n")
+	f.Run()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Errorf("expected 4 rows, got %q", buf.String())
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			t.Errorf("unexpected row %q", line)
+			continue
+		}
+		// the line without a doc keeps a NULL subquery value
+		if fields[0] == "is" && fields[2] != "<nil>" || fields[0] != "is" && fields[1] != fields[2] {
+			t.Errorf("unexpected row %q", line)
+		}
+	}
+}
+
+func TestJoinKeepsPartitions(t *testing.T) {
+	gio.Init()
+
+	saved := plan.BroadcastJoinRowLimit
+	plan.BroadcastJoinRowLimit = 0
+	defer func() { plan.BroadcastJoinRowLimit = saved }()
+
+	f := flow.New("testJoinPartitions")
+
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+		{"is", 2},
+		{"a", 3},
+	}).RoundRobin("rr", 2)
+	docs := f.Slices([][]interface{}{
+		{1, "first"},
+		{3, "third"},
+	})
+	pages := f.Slices([][]interface{}{
+		{1, 10},
+		{2, 20},
+		{3, 30},
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+	sql.RegisterTable(docs, "docs", []executor.TableColumn{
+		{ColumnName: "num", ColumnType: mysql.TypeLong},
+		{ColumnName: "name", ColumnType: mysql.TypeVarchar},
+	})
+	sql.RegisterTable(pages, "pages", []executor.TableColumn{
+		{ColumnName: "doc", ColumnType: mysql.TypeLong},
+		{ColumnName: "page", ColumnType: mysql.TypeLong},
+	})
+
+	out, p, err := sql.Query("select word, name, page from words join docs on line = num join pages on line = doc")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v %v\n")
+	f.Run()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Errorf("expected 2 joined rows, got %q", buf.String())
+	}
+
+	// the rows of the first join are partitioned by line already, so only
+	// the three tables are shuffled
+	scatters := 0
+	for _, step := range f.Steps {
+		if strings.HasSuffix(step.Name, ".ScatterPartitions") {
+			scatters++
+		}
+	}
+	if scatters != 3 {
+		t.Errorf("expected 3 shuffles, got %d: %s", scatters, plan.ToString(p))
+	}
+}
+
+func TestJoinOfMixedKeyTypes(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	saved := plan.BroadcastJoinRowLimit
+	defer func() { plan.BroadcastJoinRowLimit = saved }()
+
+	for _, limit := range []uint64{plan.BroadcastJoinRowLimit, 0} {
+		plan.BroadcastJoinRowLimit = limit
+
+		for _, test := range []struct {
+			query    string
+			expected string
+		}{
+			{"select id, name, weight from items, weights where id = weight", "1 one 1\n3 three 3"},
+			{"select id, name, price from items, prices where id = price", "1 one 1.00\n2 two 2.0"},
+			{"select id from items where id in (select price from prices)", "1\n2"},
+		} {
+			f := flow.New("testMixedJoin")
+			items := f.Slices([][]interface{}{
+				{1, "one"},
+				{2, "two"},
+				{3, "three"},
+			}).RoundRobin("rr", 2)
+			weights := f.Slices([][]interface{}{
+				{float64(1)},
+				{2.5},
+				{float64(3)},
+			})
+			prices := f.Slices([][]interface{}{
+				{"1.00"},
+				{"2.0"},
+				{"4.5"},
+			})
+
+			executor.SetTables(make(map[string]*executor.TableSource))
+			sql.RegisterTable(items, "items", []executor.TableColumn{
+				{ColumnName: "id", ColumnType: mysql.TypeLong},
+				{ColumnName: "name", ColumnType: mysql.TypeVarchar},
+			})
+			sql.RegisterTable(weights, "weights", []executor.TableColumn{
+				{ColumnName: "weight", ColumnType: mysql.TypeDouble},
+			})
+			sql.RegisterTable(prices, "prices", []executor.TableColumn{
+				{ColumnName: "price", ColumnType: mysql.TypeNewDecimal},
+			})
+
+			out, _, err := sql.Query(test.query)
+			if err != nil {
+				t.Fatalf("limit %d: %s: %v", limit, test.query, err)
+			}
+			var buf bytes.Buffer
+			out.OutputRow(func(row *util.Row) error {
+				fmt.Fprintln(&buf, append(row.K, row.V...)...)
+				return nil
+			})
+			f.Run()
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			sort.Strings(lines)
+			if got := strings.Join(lines, "\n"); got != test.expected {
+				t.Errorf("limit %d: %s: got %q, expected %q", limit, test.query, got, test.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/distributed/plan"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestLimitReadsLeadingRows(t *testing.T) {
+	f := flow.New("testLimitReadsLeadingRows")
+	ds := f.Slices([][]interface{}{
+		{"this", 1}, {"is", 2}, {"a", 3}, {"table", 4}, {"of", 5}, {"words", 6},
+	}).RoundRobin("rr", 2)
+	sql.RegisterTable(ds, "pushed_words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+
+	if _, _, err := sql.Query("select word, line from pushed_words limit 2 offset 1"); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	// the first LocalLimit() reads the shards of the table from the agents,
+	// which send only the leading rows, and skip the blocks after them
+	_, taskGroups := plan.GroupTasks(f)
+	var limited int
+	for _, tg := range taskGroups {
+		shardRange := tg.InputShardRange()
+		if shardRange == nil {
+			continue
+		}
+		limited++
+		if shardRange.GetStopRow() != 3 || shardRange.GetStartRow() != 0 {
+			t.Errorf("%s reads the range %v, expected the 3 leading rows", tg, shardRange)
+		}
+		if input := tg.Tasks[0].InputShards[0].Dataset; input != ds {
+			t.Errorf("%s reads the range of %s, expected the table", tg, input.Step.Name)
+		}
+	}
+	if limited != len(ds.Shards) {
+		t.Errorf("%d task groups read the leading rows, expected %d", limited, len(ds.Shards))
+	}
+}
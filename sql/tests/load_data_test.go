@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestLoadData(t *testing.T) {
+	gio.Init()
+
+	dir, err := ioutil.TempDir("", "load_data")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	written := make(map[string]int64)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterSink("numbered", []executor.TableColumn{
+		{ColumnName: "num", ColumnType: mysql.TypeLonglong},
+		{ColumnName: "label", ColumnType: mysql.TypeVarchar},
+	}, func(row []interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written[fmt.Sprint(row[1])] = row[0].(int64)
+		return nil
+	})
+
+	load := func(stmt, path, content string) {
+		mu.Lock()
+		written = make(map[string]int64)
+		mu.Unlock()
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		out, _, err := sql.Query(fmt.Sprintf(stmt, path))
+		if err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+		out.Run()
+	}
+
+	local := filepath.Join(dir, "local.txt")
+	if _, _, err := sql.Query("load data infile '" + local + "' into table numbered lines starting by 'x'"); err == nil {
+		t.Errorf("expected an unsupported option error")
+	}
+	if _, _, err := sql.Query("load data local infile '" + local + "' into table numbered"); err == nil {
+		t.Errorf("expected an error loading a missing local file")
+	}
+
+	// the default fields are tab separated, with \ escaping
+	load("load data local infile '%s' into table numbered", local,
+		"1\tthis\\tone\n2\t\\N\n3\tline\\\nbreak\n")
+	if len(written) != 3 || written["this\tone"] != 1 || written["<nil>"] != 2 || written["line\nbreak"] != 3 {
+		t.Errorf("unexpected rows written: %v", written)
+	}
+
+	load(`load data infile '%s' into table numbered fields terminated by ',' enclosed by '"' escaped by '|'`,
+		filepath.Join(dir, "server.csv"), "4,\"a,b\"\n5,\"say |\"hi|\"\"\n")
+	if len(written) != 2 || written["a,b"] != 4 || written[`say "hi"`] != 5 {
+		t.Errorf("unexpected rows written: %v", written)
+	}
+}
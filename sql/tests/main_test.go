@@ -0,0 +1,15 @@
+package sql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lovelly/gleam/gio"
+)
+
+// TestMain runs the mappers of the filters and projections, when the test
+// binary is started again to run them, instead of the tests.
+func TestMain(m *testing.M) {
+	gio.Init()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,73 @@
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestSelectIntoOutfile(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	dir, err := ioutil.TempDir("", "outfile")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		format   string
+		expected string
+	}{
+		{"format csv", "\"a,b\",\\N\nthis,1"},
+		{"", "a,b\t\\N\nthis\t1"},
+	} {
+		f := flow.New("testOutfile")
+		words := f.Slices([][]interface{}{
+			{"this", 1},
+			{"a,b", nil},
+		}).RoundRobin("rr", 2)
+
+		executor.SetTables(make(map[string]*executor.TableSource))
+		sql.RegisterTable(words, "words", []executor.TableColumn{
+			{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+			{ColumnName: "line", ColumnType: mysql.TypeLong},
+		})
+
+		fileName := filepath.Join(dir, "words.out")
+		out, _, err := sql.Query("select word, line from words into outfile '" + fileName + "' " + test.format)
+		if err != nil {
+			t.Fatalf("%s: query: %v", test.format, err)
+		}
+		out.Run()
+
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			t.Fatalf("%s: read: %v", test.format, err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		sort.Strings(lines)
+		if strings.Join(lines, "\n") != test.expected {
+			t.Errorf("%s: unexpected file content %q", test.format, data)
+		}
+	}
+
+	for _, stmt := range []string{
+		"select word from words into outfile '/tmp/words.xml' format xml",
+		"set @a = 1 into outfile '/tmp/words.csv'",
+	} {
+		if _, _, err := sql.Query(stmt); err == nil {
+			t.Errorf("%s: expected an error", stmt)
+		}
+	}
+}
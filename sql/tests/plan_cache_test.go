@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+	"github.com/lovelly/gleam/sql/privilege"
+)
+
+func TestPlanCache(t *testing.T) {
+	gio.Init()
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{"a"}, {"b"}, {"c"}}), nil
+	}
+	register := func() {
+		if err := sql.RegisterFileTable(flow.New("testPlanCache"), "words", []executor.TableColumn{
+			{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		}, executor.TableLocation{FileType: "txt", Path: "words.txt"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	register()
+
+	sql.EnablePlanCache(10)
+	defer sql.EnablePlanCache(0)
+
+	planOf := func(user, q string) plan.Plan {
+		_, p, err := sql.QueryAs(user, q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		return p
+	}
+
+	q := "select count(word) from words"
+	p := planOf("", q)
+	if planOf("", "select  count(word)\n from words;") != p {
+		t.Errorf("expected the plan of the same query to be cached")
+	}
+	if planOf("", "select max(word) from words") == p {
+		t.Errorf("expected another plan for another query")
+	}
+	random := "select word, rand() from words"
+	if planOf("", random) == planOf("", random) {
+		t.Errorf("expected the plans of nondeterministic functions not to be cached")
+	}
+
+	m := sql.NewQueryManager(1)
+	for i := 0; i < 2; i++ {
+		rows, err := m.Run(context.Background(), "a", "", q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if fmt.Sprint(rows) != "[[3]]" {
+			t.Errorf("run %d: %s returned %v", i, q, rows)
+		}
+	}
+
+	register()
+	if planOf("", q) == p {
+		t.Errorf("expected the plan to be invalidated by registering the table")
+	}
+	p = planOf("", q)
+	defer executor.SetDatabases(executor.DatabaseNames())
+	if _, err := m.Run(context.Background(), "a", "", "create database planned"); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	if planOf("", q) == p {
+		t.Errorf("expected the plan to be invalidated by creating a database")
+	}
+
+	// the cached plan of the user is checked again when privileges change
+	if err := privilege.CreateUser("planner", "", false); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	defer privilege.DropUser("planner", true)
+	if err := privilege.Grant("planner", mysql.SelectPriv, executor.DefaultDB, "words"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	planOf("planner", q)
+	if err := privilege.Revoke("planner", mysql.SelectPriv, executor.DefaultDB, "words"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, _, err := sql.QueryAs("planner", q); err == nil {
+		t.Errorf("expected the revoked privilege to deny the cached query")
+	}
+}
+
+// BenchmarkPlanning plans small queries, as the MySQL protocol frontend does,
+// with and without the plan cache.
+func BenchmarkPlanning(b *testing.B) {
+	gio.Init()
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{"a", 1}}), nil
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	if err := sql.RegisterFileTable(flow.New("benchmarkPlanning"), "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	}, executor.TableLocation{FileType: "csv", Path: "words.csv"}); err != nil {
+		b.Fatalf("register: %v", err)
+	}
+
+	queries := []string{
+		"select word from words where line = 3",
+		"select x.word from (select word, line from words) x where x.line > 1",
+		"select word, count(line) from words group by word",
+	}
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			if cached {
+				sql.EnablePlanCache(10)
+				defer sql.EnablePlanCache(0)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := sql.Query(queries[i%len(queries)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+)
+
+func TestPlanToPB(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testPlanToPB")
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+	})
+	docs := f.Slices([][]interface{}{
+		{1, "first"},
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+	sql.RegisterTable(docs, "docs", []executor.TableColumn{
+		{ColumnName: "num", ColumnType: mysql.TypeLong},
+		{ColumnName: "name", ColumnType: mysql.TypeVarchar},
+	})
+
+	_, p, err := sql.Query("select word, name from words, docs where line = num")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	sp, err := plan.ToPB(p.(plan.PhysicalPlan))
+	if err != nil {
+		t.Fatalf("to pb: %v", err)
+	}
+	data, err := proto.Marshal(sp)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	sp = &pb.SqlPlan{}
+	if err = proto.Unmarshal(data, sp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if sp.Type != "Projection" || len(sp.Exprs) != 2 || len(sp.Schema) != 2 {
+		t.Fatalf("unexpected root %v", sp)
+	}
+	join := sp.Children[0]
+	if join.Type != "HashJoin" || len(join.EqualConditions) != 1 || len(join.Children) != 2 {
+		t.Fatalf("unexpected join %v", join)
+	}
+	if eq := join.EqualConditions[0]; eq.FuncName != "eq" || len(eq.Args) != 2 {
+		t.Errorf("unexpected join condition %v", eq)
+	}
+	if join.Strategy != int32(findJoinStrategy(p)) {
+		t.Errorf("join strategy %d, expected %v", join.Strategy, findJoinStrategy(p))
+	}
+
+	for _, query := range []string{
+		"select word, name from words, docs where line = num",
+		"select word, name from words left join docs on line = num and name != 'x'",
+		"select line, count(*), sum(line) from words group by line limit 2",
+		"select name from docs where exists (select * from words where line = num)",
+		"select word from words where line > 0 limit 1, 2",
+		"select word, line, (select num from docs where num = line) from words",
+	} {
+		_, p, err := sql.Query(query)
+		if err != nil {
+			t.Fatalf("query %q: %v", query, err)
+		}
+		expected, err := plan.ToPB(p.(plan.PhysicalPlan))
+		if err != nil {
+			t.Fatalf("%q to pb: %v", query, err)
+		}
+		data, err := proto.Marshal(expected)
+		if err != nil {
+			t.Fatalf("%q marshal: %v", query, err)
+		}
+		sp := &pb.SqlPlan{}
+		if err = proto.Unmarshal(data, sp); err != nil {
+			t.Fatalf("%q unmarshal: %v", query, err)
+		}
+		is := sql.InfoSchema()
+		ctx, err := sql.CreateSession(is)
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		decoded, err := plan.PBToPlan(sp, ctx, is)
+		if err != nil {
+			t.Fatalf("%q pb to plan: %v", query, err)
+		}
+		if findJoinStrategy(decoded) != findJoinStrategy(p) {
+			t.Errorf("%q: join strategy %v, expected %v", query, findJoinStrategy(decoded), findJoinStrategy(p))
+		}
+		if mode, expected := findAggMode(decoded), findAggMode(p); mode != expected {
+			t.Errorf("%q: aggregation %v, expected %v", query, mode, expected)
+		}
+		actual, err := plan.ToPB(decoded)
+		if err != nil {
+			t.Fatalf("%q decoded to pb: %v", query, err)
+		}
+		if !proto.Equal(actual, expected) {
+			t.Errorf("%q: round trip changed the plan\n%v\nexpected\n%v", query, actual, expected)
+		}
+	}
+}
+
+func findAggMode(p plan.Plan) string {
+	if agg, ok := p.(*plan.PhysicalAggregation); ok {
+		mode := fmt.Sprintf("%d", agg.AggType)
+		for _, f := range agg.AggFuncs {
+			mode += fmt.Sprintf(" %s:%d", f.GetName(), f.GetMode())
+		}
+		return mode
+	}
+	for _, c := range p.GetChildren() {
+		if mode := findAggMode(c); mode != "" {
+			return mode
+		}
+	}
+	return ""
+}
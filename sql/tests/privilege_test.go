@@ -0,0 +1,116 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/privilege"
+	"github.com/lovelly/gleam/sql/util"
+)
+
+func TestPrivileges(t *testing.T) {
+	gio.Init()
+
+	for _, q := range []string{
+		"create user 'ann'@'%' identified by 'secret'",
+		"create user 'bob'@'%'",
+	} {
+		if _, _, err := sql.Query(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	if err := privilege.CreateRole("reader"); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	defer func() {
+		for _, name := range []string{"ann", "bob", "reader"} {
+			privilege.DropUser(name, true)
+		}
+	}()
+	if _, _, err := sql.Query("create user 'ann'@'%'"); err == nil {
+		t.Errorf("expected an error creating an existing user")
+	}
+	if _, _, err := sql.Query("grant select on words to 'reader'@'%'"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if err := privilege.GrantRole("reader", "ann"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+
+	scramble := []byte("01234567890123456789")
+	if !privilege.Authenticate("ann", scramble, util.CalcPassword(scramble, util.Sha1Hash([]byte("secret")))) {
+		t.Errorf("expected ann to log in")
+	}
+	if privilege.Authenticate("ann", scramble, util.CalcPassword(scramble, util.Sha1Hash([]byte("wrong")))) {
+		t.Errorf("expected a wrong password to fail")
+	}
+	if !privilege.Authenticate("bob", scramble, nil) || privilege.Authenticate("reader", scramble, nil) {
+		t.Errorf("expected bob to log in without password, and roles not to log in")
+	}
+
+	f := flow.New("testPrivileges")
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(f.Slices([][]interface{}{{"a"}, {"b"}}), "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+	})
+	sql.RegisterSink("copies", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+	}, func(row []interface{}) error { return nil })
+
+	for _, test := range []struct {
+		user    string
+		query   string
+		allowed bool
+	}{
+		{"ann", "select word from words", true},
+		{"bob", "select word from words", false},
+		{"ann", "insert into copies select word from words", false},
+		{"ann", "create user 'eve'@'%'", false},
+		{"ann", "grant select on words to 'bob'@'%'", false},
+		{"", "insert into copies select word from words", true},
+		{"ann", "select word from words into outfile '/tmp/words.csv'", false},
+		{"ann", "load data infile '/tmp/words.csv' into table copies", false},
+		{"ann", "set global max_execution_time = 1000", false},
+		{"ann", "set max_execution_time = 1000", true},
+	} {
+		_, _, err := sql.QueryAs(test.user, test.query)
+		if test.allowed && err != nil {
+			t.Errorf("%s %s: %v", test.user, test.query, err)
+		}
+		if !test.allowed && (err == nil || !strings.Contains(err.Error(), "denied")) {
+			t.Errorf("%s %s: expected access denied, got %v", test.user, test.query, err)
+		}
+	}
+
+	// the session variables of ann are not the ones of Query()
+	ctx, cancel := sql.ExecutionContext(context.Background())
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("SET by QueryAs changed the session of Query()")
+	}
+	cancel()
+
+	out, _, err := sql.QueryAs("ann", "select word from words")
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v\n")
+	f.Run()
+	if got := buf.String(); got != "a\nb\n" {
+		t.Errorf("unexpected rows %q", got)
+	}
+
+	if err := privilege.RevokeRole("reader", "ann"); err != nil {
+		t.Fatalf("revoke role: %v", err)
+	}
+	if _, _, err := sql.QueryAs("ann", "select word from words"); err == nil {
+		t.Errorf("expected access denied after revoking the role")
+	}
+}
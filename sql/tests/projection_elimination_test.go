@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/plan"
+)
+
+func TestProjectionElimination(t *testing.T) {
+	gio.Init()
+
+	f := flow.New("testProjectionElimination")
+	words := f.Slices([][]interface{}{
+		{"this", 1, "a"},
+		{"is", 2, "b"},
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+		{ColumnName: "tag", ColumnType: mysql.TypeVarchar},
+	})
+
+	for _, query := range []string{
+		"select x.word from (select word, line from words) x",
+		"select x.tag, count(x.line) from (select tag, line from words) x group by x.tag",
+	} {
+		_, p, err := sql.Query(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		for c := p.GetChildByIndex(0); c != nil; {
+			if _, ok := c.(*plan.Projection); ok {
+				t.Errorf("%s: expected the projection of the subquery to be merged, got %s", query, plan.ToString(p))
+			}
+			if len(c.GetChildren()) == 0 {
+				break
+			}
+			c = c.GetChildByIndex(0)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestPushDown(t *testing.T) {
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLonglong},
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	m := sql.NewQueryManager(1)
+
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{"select word from words where line > 1", "[[is] [a]]"},
+		{"select concat(word, '!'), line * 10 from words where word != 'is'", "[[this! 10] [a! 30]]"},
+		{"select upper(word) from words where line between 2 and 3 and word like 'i%'", "[[IS]]"},
+		{"select word, database() from words where line = 1", "[[this gleam]]"},
+	} {
+		words := flow.New("testPushDown").Slices([][]interface{}{{"this", 1}, {"is", 2}, {"a", 3}})
+		sql.RegisterTable(words, "words", columns)
+		rows, err := m.Run(context.Background(), "a", "", test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		if actual := fmt.Sprint(rows); actual != test.expected {
+			t.Errorf("%s: %s, expecting %s", test.query, actual, test.expected)
+		}
+	}
+
+	if _, _, err := sql.Query("select word from words where @x := line"); err == nil {
+		t.Errorf("expected an error setting a variable in the mappers")
+	}
+}
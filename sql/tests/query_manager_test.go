@@ -0,0 +1,114 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestQueryManagerConcurrentSessions(t *testing.T) {
+	gio.Init()
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{"this", 1}, {"is", 2}, {"a", 3}}), nil
+	}
+
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	if err := sql.RegisterFileTable(flow.New("testQueryManager"), "words", columns, executor.TableLocation{FileType: "csv", Path: "words.csv"}); err != nil {
+		t.Fatalf("register file table: %v", err)
+	}
+	notes := flow.New("testQueryManagerNotes").Slices([][]interface{}{{"x", 1}})
+	sql.RegisterTable(notes, "notes", columns)
+
+	m := sql.NewQueryManager(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(session string) {
+			defer wg.Done()
+			rows, err := m.Run(context.Background(), session, "", "select count(line) from words")
+			if err != nil {
+				t.Errorf("session %s: %v", session, err)
+				return
+			}
+			if len(rows) != 1 || gio.ToInt64(rows[0][0]) != 3 {
+				t.Errorf("session %s: rows %v, expecting 3", session, rows)
+			}
+		}([]string{"a", "b", "c"}[i%3])
+
+		// registering tables while the sessions query
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sql.RegisterTable(flow.New("testQueryManagerOther").Slices([][]interface{}{{"y", i}}), fmt.Sprintf("other%d", i), columns)
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := m.Run(context.Background(), "a", "", "select word from notes"); err != nil {
+		t.Errorf("first read of notes: %v", err)
+	}
+	if _, err := m.Run(context.Background(), "b", "", "select word from notes"); err == nil {
+		t.Errorf("expecting an error reading the ran flow of notes again")
+	}
+}
+
+func TestQueryManagerSessionFunctions(t *testing.T) {
+	gio.Init()
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{"this", 1}, {"is", 2}}), nil
+	}
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	}
+	if err := sql.RegisterFileTable(flow.New("testSessionFunctions"), "words", columns, executor.TableLocation{FileType: "csv", Path: "words.csv"}); err != nil {
+		t.Fatalf("register file table: %v", err)
+	}
+
+	m := sql.NewQueryManager(1)
+	ctx := context.Background()
+	// the functions without grammar rules of their own are also identifiers
+	query := "select connection_id(), lpad(word, 6, '.') as format, instr(word, 'i') as quote from words where line = 1"
+	run := func(session string) []interface{} {
+		rows, err := m.Run(ctx, session, "", query)
+		if err != nil {
+			t.Fatalf("session %s: %v", session, err)
+		}
+		if len(rows) != 1 || len(rows[0]) != 3 {
+			t.Fatalf("session %s: rows %v, expecting one row of 3 fields", session, rows)
+		}
+		return rows[0]
+	}
+
+	a, b, again := run("a"), run("b"), run("a")
+	if gio.ToString(a[1]) != "..this" || gio.ToInt64(a[2]) != 3 {
+		t.Errorf("session a: unexpected row %v", a)
+	}
+	if gio.ToInt64(a[0]) == gio.ToInt64(b[0]) {
+		t.Errorf("sessions a and b have the same CONNECTION_ID() %v", a[0])
+	}
+	if gio.ToInt64(a[0]) != gio.ToInt64(again[0]) {
+		t.Errorf("session a changed its CONNECTION_ID() from %v to %v", a[0], again[0])
+	}
+}
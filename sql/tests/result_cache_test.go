@@ -0,0 +1,98 @@
+package sql
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestResultCache(t *testing.T) {
+	gio.Init()
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	// the table reads the words of the file, or of words if it is set
+	var words []string
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		tableWords := words
+		if tableWords == nil {
+			data, err := ioutil.ReadFile(location.Path)
+			if err != nil {
+				return nil, err
+			}
+			tableWords = strings.Fields(string(data))
+		}
+		var rows [][]interface{}
+		for _, word := range tableWords {
+			rows = append(rows, []interface{}{word})
+		}
+		return f.Slices(rows), nil
+	}
+
+	dir, err := ioutil.TempDir("", "resultcache")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "words.txt")
+
+	sql.EnableResultCache(10)
+	defer sql.EnableResultCache(0)
+
+	query := func(q string) string {
+		f := flow.New("testResultCache")
+		executor.SetTables(make(map[string]*executor.TableSource))
+		if err := sql.RegisterFileTable(f, "words", []executor.TableColumn{
+			{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		}, executor.TableLocation{FileType: "txt", Path: fileName}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		out, _, err := sql.Query(q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		var buf bytes.Buffer
+		out.Fprintf(&buf, "%v\n")
+		f.Run()
+		lines := strings.Fields(buf.String())
+		sort.Strings(lines)
+		return strings.Join(lines, " ")
+	}
+
+	if err := ioutil.WriteFile(fileName, []byte("a b"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := query("select word from words"); got != "a b" {
+		t.Errorf("expected the words of the file, got %q", got)
+	}
+
+	// the unchanged file is not read again
+	words = []string{"stale"}
+	if got := query("select word from words"); got != "a b" {
+		t.Errorf("expected the cached words, got %q", got)
+	}
+	if got := query("select word from words limit 1"); got != "stale" {
+		t.Errorf("expected another query to read the table, got %q", got)
+	}
+
+	// the changed file is read again
+	words = nil
+	if err := ioutil.WriteFile(fileName, []byte("a b c"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := query("select word from words"); got != "a b c" {
+		t.Errorf("expected the words of the changed file, got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestSetVariables(t *testing.T) {
+	gio.Init()
+
+	for _, stmt := range []string{
+		"set autocommit = 'maybe'",
+		"set sql_mode = 'NOT_A_MODE'",
+		"set max_execution_time = 'soon'",
+		"set time_zone = 'Nowhere/City'",
+		"set no_such_variable = 1",
+		"set global pseudo_thread_id = 1",
+		"set session max_connections = 1",
+	} {
+		if _, _, err := sql.Query(stmt); err == nil {
+			t.Errorf("%s: expected an error", stmt)
+		}
+	}
+	// the variables are kept by the session of Query(), and by the globals
+	defer func() {
+		for _, stmt := range []string{
+			"set global group_concat_max_len = default",
+			"set autocommit = default, sql_mode = default, time_zone = default, @tenant = null",
+			"set character_set_client = default, character_set_connection = default, " +
+				"character_set_results = default, collation_connection = default",
+		} {
+			if _, _, err := sql.Query(stmt); err != nil {
+				t.Errorf("restore %s: %v", stmt, err)
+			}
+		}
+	}()
+	for _, stmt := range []string{
+		"set autocommit = 0, sql_mode = 'strict_all_tables,ansi_quotes'",
+		"set time_zone = '+08:00'",
+		"set global group_concat_max_len = 2048",
+		"set @tenant = 'abc'",
+		"set names utf8 collate utf8_bin",
+	} {
+		if _, _, err := sql.Query(stmt); err != nil {
+			t.Errorf("%s: %v", stmt, err)
+		}
+	}
+
+	if _, _, err := sql.Query("set sql_select_limit = 2"); err != nil {
+		t.Fatalf("set sql_select_limit: %v", err)
+	}
+	defer sql.Query("set sql_select_limit = default")
+
+	f := flow.New("testSet")
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+		{"is", 2},
+		{"a", 3},
+		{"table", 4},
+	}).RoundRobin("rr", 2)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{"word", mysql.TypeVarchar},
+		{"line", mysql.TypeLong},
+	})
+
+	out, _, err := sql.Query("select word, line from words")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("query returned no dataset")
+	}
+
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v\n")
+	f.Run()
+
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Errorf("expected 2 rows limited by sql_select_limit, got %q", buf.String())
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	gio.Init()
+
+	if _, _, err := sql.Query("set gleam_max_parallelism = 'many'"); err == nil {
+		t.Errorf("expected an error setting gleam_max_parallelism to a string")
+	}
+	if _, _, err := sql.Query("set gleam_max_parallelism = 2, gleam_max_memory_mb = 64"); err != nil {
+		t.Fatalf("set resource limits: %v", err)
+	}
+	defer sql.Query("set gleam_max_parallelism = default, gleam_max_memory_mb = default")
+
+	f := flow.New("testResourceLimits")
+	words := f.Slices([][]interface{}{
+		{"this", 1},
+		{"is", 2},
+		{"a", 3},
+		{"table", 4},
+	}).RoundRobin("rr", 4)
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+	})
+
+	out, _, err := sql.Query("select word, line from words where line > 1")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(out.Shards) > 2 {
+		t.Errorf("expected at most 2 shards limited by gleam_max_parallelism, got %d", len(out.Shards))
+	}
+	for d := out; d != words; d = d.Step.InputDatasets[0] {
+		if d.Step.MaxMemoryMB != 64 {
+			t.Errorf("step %s: expected memory limited to 64MB by gleam_max_memory_mb, got %d", d.Step.Name, d.Step.MaxMemoryMB)
+		}
+	}
+	// the steps of the table are shared by the next queries
+	for d := words; ; d = d.Step.InputDatasets[0] {
+		if d.Step.MaxMemoryMB != 0 {
+			t.Errorf("table step %s: expected no memory limit, got %d", d.Step.Name, d.Step.MaxMemoryMB)
+		}
+		if len(d.Step.InputDatasets) == 0 {
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v %v\n")
+	f.Run()
+
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 {
+		t.Errorf("expected 3 rows, got %q", buf.String())
+	}
+}
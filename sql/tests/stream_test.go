@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+)
+
+func TestStreams(t *testing.T) {
+	gio.Init()
+
+	// creating the stream, and then each query, reads the next batch
+	batches := [][][]interface{}{
+		nil,
+		{{"home", "alice", 0}, {"home", "bob", 0}, {"about", "bob", 60000}},
+		{{"home", "carol", 120000}},
+	}
+	executor.StreamSources["fake"] = func(f *flow.Flow, name string, stream *executor.Stream) (*flow.Dataset, error) {
+		if stream.Options["topic"] != "clicks" || len(stream.Fields) != 3 {
+			t.Errorf("stream %s options %v fields %v", name, stream.Options, stream.Fields)
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return f.Slices(batch), nil
+	}
+	defer delete(executor.StreamSources, "fake")
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	create := `create stream clicks (page varchar(255), user varchar(255), window bigint)
+		with (type = 'fake', topic = 'clicks')`
+	if _, _, err := sql.Query(create); err != nil {
+		t.Fatalf("create stream: %v", err)
+	}
+	if _, _, err := sql.Query(create); err == nil {
+		t.Errorf("expecting an error creating the stream again")
+	}
+	if _, _, err := sql.Query("create stream if not exists clicks (page varchar(255)) with (type = 'fake')"); err != nil {
+		t.Errorf("create stream if not exists: %v", err)
+	}
+
+	m := sql.NewQueryManager(1)
+	for i, expected := range []int{2, 1} {
+		rows, err := m.Run(context.Background(), "a", "", "select window, count(user) from clicks group by window")
+		if err != nil {
+			t.Fatalf("batch %d: %v", i, err)
+		}
+		if len(rows) != expected {
+			t.Errorf("batch %d: rows %v, expecting %d windows", i, rows, expected)
+		}
+	}
+
+	if _, _, err := sql.QueryAs("someone", "drop stream clicks"); err == nil {
+		t.Errorf("expecting an error dropping the stream without privileges")
+	}
+	if _, _, err := sql.Query("drop stream clicks"); err != nil {
+		t.Errorf("drop stream: %v", err)
+	}
+	if _, _, err := sql.Query("drop stream clicks"); err == nil {
+		t.Errorf("expecting an error dropping the dropped stream")
+	}
+}
@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+)
+
+func TestTableFunctions(t *testing.T) {
+	gio.Init()
+
+	var opened *executor.TableLocation
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		opened = location
+		return f.Slices([][]interface{}{{"GET", 200}, {"GET", 404}, {"POST", 200}}), nil
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+
+	m := sql.NewQueryManager(1)
+	rows, err := m.Run(context.Background(), "a", "", "select count(n), sum(n) from range(1, 11)")
+	if err != nil {
+		t.Fatalf("select from range: %v", err)
+	}
+	if len(rows) != 1 || gio.ToInt64(rows[0][0]) != 10 || gio.ToInt64(rows[0][1]) != 55 {
+		t.Errorf("rows %v, expecting 10 and 55", rows)
+	}
+
+	rows, err = m.Run(context.Background(), "a", "", "select method, count(status) from files('hdfs:///logs/2024-*/*.jsonl.gz', 'jsonl', 'method varchar(10), status int') group by method")
+	if err != nil {
+		t.Fatalf("select from files: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("rows %v, expecting 2 methods", rows)
+	}
+	if opened == nil || opened.Path != "hdfs:///logs/2024-*/*.jsonl.gz" || opened.FileType != "jsonl" || len(opened.Fields) != 2 {
+		t.Errorf("opened location %+v", opened)
+	}
+
+	if _, err := m.Run(context.Background(), "a", "", "select line from files('logs/*.txt.gz')"); err != nil {
+		t.Errorf("select from txt files: %v", err)
+	} else if opened.FileType != "txt" {
+		t.Errorf("file type %s, expecting txt from the extension", opened.FileType)
+	}
+	// the arguments are scanned as literals, after comments and with escapes
+	if _, err := m.Run(context.Background(), "a", "", "select line /* from range(3) */ from -- files('x')\n files('logs/it\\'s.txt') where line != 'from range(2)'"); err != nil {
+		t.Errorf("select from files with comments and escapes: %v", err)
+	} else if opened.Path != "logs/it's.txt" {
+		t.Errorf("opened %s, expecting logs/it's.txt", opened.Path)
+	}
+
+	// each file is read by its own shard
+	dir, err := ioutil.TempDir("", "table_function_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.csv"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Run(context.Background(), "a", "", "select line from files('"+dir+"/*.txt')"); err != nil {
+		t.Errorf("select from txt files: %v", err)
+	} else if opened.PartitionCount != 3 {
+		t.Errorf("partition count %d, expecting 3 files", opened.PartitionCount)
+	}
+
+	if _, err := m.Run(context.Background(), "a", "", "select x from files('logs/*.csv')"); err == nil {
+		t.Errorf("expecting an error reading csv files without the column definitions")
+	}
+	if _, err := m.Run(context.Background(), "a", "", "select n from range(1, 5, 0)"); err == nil {
+		t.Errorf("expecting an error for the step 0")
+	}
+	if _, err := m.Run(context.Background(), "a", "", "select n from range(1, x)"); err == nil {
+		t.Errorf("expecting an error for a column argument")
+	}
+}
@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/pb"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestTemporaryTables(t *testing.T) {
+	gio.Init()
+
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		if location.Path == "broken.csv" {
+			return f.Source("broken", func(w io.Writer, stats *pb.InstructionStat) error {
+				return fmt.Errorf("broken file")
+			}), nil
+		}
+		return f.Slices([][]interface{}{{"this", 1, 1.5}, {"is", nil, 2.5}, {"a", 3, nil}}), nil
+	}
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	columns := []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeLong},
+		{ColumnName: "score", ColumnType: mysql.TypeDouble},
+	}
+	for name, path := range map[string]string{"words": "words.csv", "broken": "broken.csv"} {
+		if err := sql.RegisterFileTable(flow.New("testTemporaryTables"), name, columns,
+			executor.TableLocation{FileType: "csv", Path: path}); err != nil {
+			t.Fatalf("register file table %s: %v", name, err)
+		}
+	}
+
+	m := sql.NewQueryManager(1)
+	ctx := context.Background()
+
+	if _, err := m.Run(ctx, "a", "", "create temporary table short as select word, line, score from words where word != 'this'"); err != nil {
+		t.Fatalf("create temporary table: %v", err)
+	}
+	if _, err := m.Run(ctx, "a", "", "create temporary table short as select word from words"); err == nil {
+		t.Errorf("expecting an error creating the temporary table again")
+	}
+	rows, err := m.Run(ctx, "a", "", "select word, line, score from short")
+	if err != nil {
+		t.Fatalf("select from temporary table: %v", err)
+	}
+	// the NULLs and the column types are kept
+	expected := [][]interface{}{{"is", nil, 2.5}, {"a", int64(3), nil}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("rows %v, expecting %v", rows, expected)
+	}
+	if _, err := m.Run(ctx, "b", "", "select count(line) from short"); err == nil {
+		t.Errorf("expecting the temporary table to be hidden from other sessions")
+	}
+
+	if _, err := m.Run(ctx, "a", "", "create temporary table failed as select word from broken"); err == nil {
+		t.Errorf("expecting an error creating the temporary table from a failing query")
+	}
+	if _, err := m.Run(ctx, "a", "", "select word from failed"); err == nil {
+		t.Errorf("expecting the failed temporary table not to be added")
+	}
+
+	m.CloseSession("a")
+	if _, err := m.Run(ctx, "a", "", "drop temporary table short"); err == nil {
+		t.Errorf("expecting an error dropping the removed temporary table")
+	}
+	if _, err := m.Run(ctx, "a", "", "drop temporary table if exists short"); err != nil {
+		t.Errorf("drop temporary table if exists: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestReadOwnWrites(t *testing.T) {
+	gio.Init()
+
+	columns := []executor.TableColumn{
+		{ColumnName: "id", ColumnType: mysql.TypeLonglong},
+		{ColumnName: "note", ColumnType: mysql.TypeVarchar},
+	}
+	var written []string
+	sink := func(row []interface{}) error {
+		written = append(written, fmt.Sprintf("%v %v", row...))
+		return nil
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterSink("events", columns, sink)
+
+	m := sql.NewQueryManager(1)
+	ctx := context.Background()
+	run := func(session, query string) [][]interface{} {
+		rows, err := m.Run(ctx, session, "", query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return rows
+	}
+
+	// outside a transaction, the rows go to the sink, and are not kept
+	run("a", "insert into events (id) select n from range(2)")
+	if fmt.Sprint(written) != "[0 <nil> 1 <nil>]" {
+		t.Errorf("unexpected rows written to the sink %v", written)
+	}
+	if _, err := m.Run(ctx, "a", "", "select id from events"); err == nil {
+		t.Errorf("expected the committed rows not to be read")
+	}
+
+	written = nil
+	run("a", "begin")
+	run("a", "insert into events (id) select n from range(4)")
+	if len(written) != 0 {
+		t.Errorf("expected no rows written before the commit, got %v", written)
+	}
+	if rows := run("a", "select id from events where id > 1"); fmt.Sprint(rows) != "[[2] [3]]" {
+		t.Errorf("unexpected inserted rows %v", rows)
+	}
+	run("a", "update events set note = 'big', id = id * 10 where id > 1")
+	if rows := run("a", "select id, note from events where note is not null"); fmt.Sprint(rows) != "[[20 big] [30 big]]" {
+		t.Errorf("unexpected updated rows %v", rows)
+	}
+	if _, err := m.Run(ctx, "b", "", "select id from events"); err == nil {
+		t.Errorf("expected another session not to read the inserted rows")
+	}
+
+	// a failed statement leaves none of its rows
+	if _, err := m.Run(ctx, "a", "", "insert into events (id) select concat('x', n) from range(1)"); err == nil {
+		t.Errorf("expected the insert of a string id to fail")
+	}
+	run("a", "commit")
+	if fmt.Sprint(written) != "[0 <nil> 1 <nil> 20 big 30 big]" {
+		t.Errorf("unexpected rows committed to the sink %v", written)
+	}
+	if _, err := m.Run(ctx, "a", "", "select id from events"); err == nil {
+		t.Errorf("expected the committed rows not to be read")
+	}
+
+	written = nil
+	run("a", "begin")
+	run("a", "insert into events (id) select n from range(3)")
+	run("a", "rollback")
+	run("a", "commit")
+	if len(written) != 0 {
+		t.Errorf("expected the rolled back rows not to be written, got %v", written)
+	}
+}
+
+func TestReadOwnWritesInSortOrder(t *testing.T) {
+	gio.Init()
+
+	columns := []executor.TableColumn{
+		{ColumnName: "note", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "id", ColumnType: mysql.TypeLonglong},
+	}
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	f := flow.New("testReadOwnWritesInSortOrder")
+	notes := f.Slices([][]interface{}{
+		{"a", int64(1)}, {"d", int64(4)}, {"f", int64(6)}, {"h", int64(8)},
+	}).LocalSort("sort", flow.Field(2))
+	sql.RegisterTable(notes, "notes", columns)
+	ts, _ := executor.GetTable(executor.TableKey(executor.DefaultDB, "notes"))
+	ts.Sink = func(row []interface{}) error { return nil }
+
+	m := sql.NewQueryManager(1)
+	ctx := context.Background()
+	for _, query := range []string{
+		"begin",
+		"insert into notes select 'e', n + 5 from range(1)",
+		"insert into notes (id, note) select n * 7, 'x' from range(2)",
+	} {
+		if _, err := m.Run(ctx, "a", "", query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	rows, err := m.Run(ctx, "a", "", "select note, id from notes where id < 8")
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if fmt.Sprint(rows) != "[[x 0] [a 1] [d 4] [e 5] [f 6] [x 7]]" {
+		t.Errorf("expected the inserted rows merged by id, got %v", rows)
+	}
+}
@@ -0,0 +1,118 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestUnionTables(t *testing.T) {
+	gio.Init()
+
+	// the csv files have strings, and the stream has numbers in another order
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{"GET", "200"}, {"GET", "404"}}), nil
+	}
+	executor.StreamSources["fake"] = func(f *flow.Flow, name string, stream *executor.Stream) (*flow.Dataset, error) {
+		return f.Slices([][]interface{}{{int64(200), "POST", "b"}}), nil
+	}
+	defer delete(executor.StreamSources, "fake")
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	if err := sql.RegisterFileTable(flow.New("testUnionTablesHistory"), "history", []executor.TableColumn{
+		{ColumnName: "method", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "status", ColumnType: mysql.TypeVarchar},
+	}, executor.TableLocation{FileType: "csv", Path: "history/*.csv"}); err != nil {
+		t.Fatalf("register file table: %v", err)
+	}
+	if _, _, err := sql.Query("create stream live (status bigint, method varchar(10), host varchar(10)) with (type = 'fake')"); err != nil {
+		t.Fatalf("create stream: %v", err)
+	}
+	columns := []executor.TableColumn{
+		{ColumnName: "method", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "status", ColumnType: mysql.TypeLong},
+		{ColumnName: "host", ColumnType: mysql.TypeVarchar},
+	}
+	if err := sql.RegisterUnionTable(flow.New("testUnionTables"), "logs", columns, "history", "live"); err != nil {
+		t.Fatalf("register union table: %v", err)
+	}
+
+	m := sql.NewQueryManager(1)
+	rows, err := m.Run(context.Background(), "a", "", "select status, count(method) from logs group by status")
+	if err != nil {
+		t.Fatalf("select from union table: %v", err)
+	}
+	counts := make(map[int64]int64)
+	for _, row := range rows {
+		counts[gio.ToInt64(row[0])] = gio.ToInt64(row[1])
+	}
+	if len(rows) != 2 || counts[200] != 2 || counts[404] != 1 {
+		t.Errorf("rows %v, expecting 2 of status 200 and 1 of 404", rows)
+	}
+	rows, err = m.Run(context.Background(), "a", "", "select count(host) from logs")
+	if err != nil {
+		t.Fatalf("count hosts: %v", err)
+	}
+	if len(rows) != 1 || gio.ToInt64(rows[0][0]) != 1 {
+		t.Errorf("rows %v, expecting the missing hosts of the files to be NULL", rows)
+	}
+
+	sql.RegisterTable(flow.New("testUnionTablesNotes").Slices([][]interface{}{{"x"}}), "notes", columns[:1])
+	if err := sql.RegisterUnionTable(flow.New("testUnionTablesNotes"), "all", columns, "logs", "notes"); err == nil {
+		t.Errorf("expecting an error for a part read only once")
+	}
+}
+
+func TestUnionTableConversions(t *testing.T) {
+	gio.Init()
+
+	rows := [][]interface{}{{"12345678901234567.89", "2024-03-01"}}
+	defer func(open func(*flow.Flow, *executor.TableLocation) (*flow.Dataset, error)) {
+		executor.OpenTableLocation = open
+	}(executor.OpenTableLocation)
+	executor.OpenTableLocation = func(f *flow.Flow, location *executor.TableLocation) (*flow.Dataset, error) {
+		return f.Slices(rows), nil
+	}
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	strings := []executor.TableColumn{
+		{ColumnName: "amount", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "day", ColumnType: mysql.TypeVarchar},
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := sql.RegisterFileTable(flow.New("testUnionTableConversions"), name, strings,
+			executor.TableLocation{FileType: "csv", Path: name + ".csv"}); err != nil {
+			t.Fatalf("register file table: %v", err)
+		}
+	}
+	if err := sql.RegisterUnionTable(flow.New("testUnionTableConversions"), "payments", []executor.TableColumn{
+		{ColumnName: "amount", ColumnType: mysql.TypeNewDecimal},
+		{ColumnName: "day", ColumnType: mysql.TypeDate},
+	}, "a", "b"); err != nil {
+		t.Fatalf("register union table: %v", err)
+	}
+
+	m := sql.NewQueryManager(1)
+	result, err := m.Run(context.Background(), "a", "", "select sum(amount), count(day) from payments")
+	if err != nil {
+		t.Fatalf("select from union table: %v", err)
+	}
+	// the decimals are exact, unlike the floats
+	if len(result) != 1 || fmt.Sprint(result[0][0]) != "24691357802469135.78" || gio.ToInt64(result[0][1]) != 2 {
+		t.Errorf("rows %v, expecting the exact sum 24691357802469135.78 of 2 rows", result)
+	}
+
+	rows = [][]interface{}{{"abc", "2024-03-01"}}
+	if _, err := m.Run(context.Background(), "a", "", "select count(amount) from payments"); err == nil {
+		t.Errorf("expecting an error converting abc to a decimal")
+	}
+}
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lovelly/gleam/flow"
+	"github.com/lovelly/gleam/gio"
+	"github.com/lovelly/gleam/sql"
+	"github.com/lovelly/gleam/sql/executor"
+	"github.com/lovelly/gleam/sql/mysql"
+)
+
+func TestShowWarnings(t *testing.T) {
+	gio.Init()
+
+	if _, _, err := sql.Query("set sql_mode = ''"); err != nil {
+		t.Fatalf("set sql_mode: %v", err)
+	}
+	defer sql.Query("set sql_mode = default")
+
+	f := flow.New("testWarnings")
+	words := f.Slices([][]interface{}{
+		{"this", "1"},
+		{"is", "2x"},
+	})
+
+	defer executor.SetTables(executor.SetTables(make(map[string]*executor.TableSource)))
+	sql.RegisterTable(words, "words", []executor.TableColumn{
+		{ColumnName: "word", ColumnType: mysql.TypeVarchar},
+		{ColumnName: "line", ColumnType: mysql.TypeVarchar},
+	})
+	sql.RegisterSink("numbered", []executor.TableColumn{
+		{ColumnName: "num", ColumnType: mysql.TypeLonglong},
+		{ColumnName: "label", ColumnType: mysql.TypeVarchar},
+	}, func(row []interface{}) error {
+		return nil
+	})
+
+	if lines := showWarnings(t); len(lines) != 0 {
+		t.Errorf("expected no warnings, got %q", lines)
+	}
+
+	out, _, err := sql.Query("insert into numbered (label, num) select word, line from words")
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	out.Run()
+
+	lines := showWarnings(t)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 warning, got %q", lines)
+	}
+	// "2x" is truncated to 2
+	if expected := fmt.Sprintf("Warning|%d|Data Truncated", mysql.WarnDataTruncated); lines[0] != expected {
+		t.Errorf("expected warning %q, got %q", expected, lines[0])
+	}
+
+	// SHOW WARNINGS keeps the warnings of the statement before it
+	if warns := sql.Warnings(); len(warns) != 1 {
+		t.Errorf("expected the warning kept after SHOW WARNINGS, got %v", warns)
+	}
+}
+
+// showWarnings runs SHOW WARNINGS, and returns its rows as level|code|message.
+func showWarnings(t *testing.T) []string {
+	out, _, err := sql.Query("show warnings")
+	if err != nil {
+		t.Fatalf("show warnings: %v", err)
+	}
+	var buf bytes.Buffer
+	out.Fprintf(&buf, "%v|%v|%v\n")
+	out.Run()
+
+	if buf.Len() == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(buf.String()), "\n")
+}
@@ -750,7 +750,12 @@ func (d *Datum) convertToString(sc *variable.StatementContext, target *FieldType
 	case KindString, KindBytes:
 		s = d.GetString()
 	case KindMysqlTime:
-		s = d.GetMysqlTime().String()
+		t := d.GetMysqlTime()
+		if t.Type == mysql.TypeTimestamp {
+			// shown in the session time zone
+			t.ConvertTimeZone(time.UTC, sessionTimeZone(sc))
+		}
+		s = t.String()
 	case KindMysqlDuration:
 		s = d.GetMysqlDuration().String()
 	case KindMysqlDecimal:
@@ -873,21 +878,27 @@ func (d *Datum) convertToMysqlTime(sc *variable.StatementContext, target *FieldT
 	if target.Decimal != UnspecifiedLength {
 		fsp = target.Decimal
 	}
-	var ret Datum
+	// TIMESTAMP values are stored in UTC, while the other values are in the session time zone.
+	parseTp := tp
+	if tp == mysql.TypeTimestamp {
+		parseTp = mysql.TypeDatetime
+	}
+	var (
+		t   Time
+		err error
+		ret Datum
+	)
 	switch d.k {
 	case KindMysqlTime:
-		t, err := d.GetMysqlTime().Convert(tp)
-		if err != nil {
-			ret.SetValue(t)
-			return ret, errors.Trace(err)
+		t = d.GetMysqlTime()
+		if t.Type == mysql.TypeTimestamp && tp != mysql.TypeTimestamp {
+			err = t.ConvertTimeZone(time.UTC, sessionTimeZone(sc))
+		} else if t.Type != mysql.TypeTimestamp && tp == mysql.TypeTimestamp {
+			err = t.ConvertTimeZone(sessionTimeZone(sc), time.UTC)
 		}
-		t, err = t.roundFrac(fsp)
-		ret.SetValue(t)
-		if err != nil {
-			return ret, errors.Trace(err)
+		if err == nil {
+			t, err = t.Convert(tp)
 		}
-	case KindMysqlDuration:
-		t, err := d.GetMysqlDuration().ConvertToTime(tp)
 		if err != nil {
 			ret.SetValue(t)
 			return ret, errors.Trace(err)
@@ -897,24 +908,39 @@ func (d *Datum) convertToMysqlTime(sc *variable.StatementContext, target *FieldT
 		if err != nil {
 			return ret, errors.Trace(err)
 		}
-	case KindString, KindBytes:
-		t, err := ParseTime(d.GetString(), tp, fsp)
-		ret.SetValue(t)
-		if err != nil {
-			return ret, errors.Trace(err)
+		return ret, nil
+	case KindMysqlDuration:
+		t, err = d.GetMysqlDuration().ConvertToTime(parseTp)
+		if err == nil {
+			t, err = t.roundFrac(fsp)
 		}
+	case KindString, KindBytes:
+		t, err = ParseTime(d.GetString(), parseTp, fsp)
 	case KindInt64:
-		t, err := ParseTimeFromNum(d.GetInt64(), tp, fsp)
-		ret.SetValue(t)
-		if err != nil {
-			return ret, errors.Trace(err)
-		}
+		t, err = ParseTimeFromNum(d.GetInt64(), parseTp, fsp)
 	default:
 		return invalidConv(d, tp)
 	}
+	if err == nil && tp == mysql.TypeTimestamp {
+		if err = t.ConvertTimeZone(sessionTimeZone(sc), time.UTC); err == nil {
+			t, err = t.Convert(tp)
+		}
+	}
+	ret.SetValue(t)
+	if err != nil {
+		return ret, errors.Trace(err)
+	}
 	return ret, nil
 }
 
+// sessionTimeZone returns the time zone of the statement, or the local time zone.
+func sessionTimeZone(sc *variable.StatementContext) *time.Location {
+	if sc == nil || sc.TimeZone == nil {
+		return time.Local
+	}
+	return sc.TimeZone
+}
+
 func (d *Datum) convertToMysqlDuration(sc *variable.StatementContext, target *FieldType) (Datum, error) {
 	tp := target.Tp
 	fsp := DefaultFsp
@@ -128,7 +128,7 @@ func InvOp2(x, y interface{}, o opcode.Op) (interface{}, error) {
 	return nil, errors.Errorf("Invalid operation: %v %v %v (mismatched types %T and %T)", x, o, y, x, y)
 }
 
-// Overflow returns an overflowed error.
+// Overflow returns an overflowed error, which is an ErrOverflow.
 func overflow(v interface{}, tp byte) error {
-	return errors.Errorf("constant %v overflows %s", v, TypeStr(tp))
+	return errors.Trace(ErrOverflow.Gen("constant %v overflows %s", v, TypeStr(tp)))
 }
@@ -174,6 +174,9 @@ func DefaultTypeForValue(value interface{}, tp *FieldType) {
 	}
 	tp.Flen = UnspecifiedLength
 	tp.Decimal = UnspecifiedLength
+	if d, ok := value.(*MyDecimal); ok {
+		tp.Flen, tp.Decimal = d.PrecisionAndFrac()
+	}
 }
 
 // DefaultCharsetForType returns the default charset/collation for mysql type.
@@ -197,6 +200,148 @@ func MergeFieldType(a byte, b byte) byte {
 	return fieldTypeMergeRules[ia][ib]
 }
 
+// AggregateFieldType returns the type of the results of the control functions
+// returning one of the arguments, e.g. COALESCE, IFNULL, IF and CASE, like
+// MySQL aggregates the types of the arguments:
+//   - the type is the merged type of the arguments, ignoring the NULL ones,
+//     or unspecified if the type of an argument is;
+//   - an integer is unsigned if all the integers are unsigned, and is widened
+//     to the next signed type, or a decimal, if only some are and the signed
+//     type does not hold their values;
+//   - a string is binary if one of the string arguments is binary, and other
+//     types have the binary charset;
+//   - the length and decimals fit all the arguments;
+//   - it is NOT NULL if notNull is true and one of the arguments is NOT NULL,
+//     e.g. for COALESCE, but not for IF and CASE, whose arguments are not
+//     all possible results.
+func AggregateFieldType(notNull bool, tps ...*FieldType) *FieldType {
+	var args []*FieldType
+	for _, tp := range tps {
+		if tp == nil || tp.Tp == mysql.TypeUnspecified {
+			return NewFieldType(mysql.TypeUnspecified)
+		}
+		if tp.Tp != mysql.TypeNull {
+			args = append(args, tp)
+		}
+	}
+	if len(args) == 0 {
+		tp := NewFieldType(mysql.TypeNull)
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+		return tp
+	}
+
+	ret := NewFieldType(args[0].Tp)
+	for _, arg := range args[1:] {
+		ret.Tp = MergeFieldType(ret.Tp, arg.Tp)
+	}
+	if ret.Tp == mysql.TypeEnum || ret.Tp == mysql.TypeSet {
+		if len(args) > 1 {
+			ret.Tp = mysql.TypeVarchar
+		} else {
+			ret.Elems = args[0].Elems
+		}
+	}
+
+	if isIntegerType(ret.Tp) {
+		signed, unsigned, widest := false, false, false
+		for _, arg := range args {
+			if mysql.HasUnsignedFlag(arg.Flag) {
+				unsigned = true
+				widest = widest || arg.Tp == ret.Tp
+			} else {
+				signed = true
+			}
+		}
+		if unsigned && !signed {
+			ret.Flag |= mysql.UnsignedFlag
+		} else if widest {
+			// the signed type does not hold the largest unsigned values
+			ret.Tp = widenedIntegerType[ret.Tp]
+		}
+	}
+
+	intDigits, decimals := 0, 0
+	for _, arg := range args {
+		if arg.Flen == UnspecifiedLength {
+			intDigits = UnspecifiedLength
+			break
+		}
+		frac := arg.Decimal
+		if frac == UnspecifiedLength || !isNumericType(arg.Tp) {
+			frac = 0
+		}
+		if frac > decimals {
+			decimals = frac
+		}
+		if n := arg.Flen - frac; n > intDigits {
+			intDigits = n
+		}
+	}
+	if intDigits != UnspecifiedLength {
+		ret.Flen = intDigits + decimals
+		if ret.Tp == mysql.TypeNewDecimal || ret.Tp == mysql.TypeFloat || ret.Tp == mysql.TypeDouble {
+			ret.Decimal = decimals
+		}
+	}
+
+	if IsTypeChar(ret.Tp) || IsTypeBlob(ret.Tp) || ret.Tp == mysql.TypeVarString {
+		ret.Charset, ret.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
+		for _, arg := range args {
+			if IsTypeChar(arg.Tp) || IsTypeBlob(arg.Tp) || arg.Tp == mysql.TypeVarString {
+				if arg.Charset == charset.CharsetBin || mysql.HasBinaryFlag(arg.Flag) {
+					ret.Charset, ret.Collate = charset.CharsetBin, charset.CollationBin
+					ret.Flag |= mysql.BinaryFlag
+					break
+				}
+				if arg.Charset != "" && ret.Charset == mysql.DefaultCharset {
+					ret.Charset, ret.Collate = arg.Charset, arg.Collate
+				}
+			}
+		}
+	} else {
+		ret.Charset, ret.Collate = charset.CharsetBin, charset.CollationBin
+		ret.Flag |= mysql.BinaryFlag
+	}
+
+	if notNull {
+		for _, tp := range tps {
+			if tp != nil && mysql.HasNotNullFlag(tp.Flag) {
+				ret.Flag |= mysql.NotNullFlag
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// widenedIntegerType holds the signed types of all the values of the signed
+// and unsigned integer types.
+var widenedIntegerType = map[byte]byte{
+	mysql.TypeTiny:     mysql.TypeShort,
+	mysql.TypeShort:    mysql.TypeInt24,
+	mysql.TypeInt24:    mysql.TypeLong,
+	mysql.TypeLong:     mysql.TypeLonglong,
+	mysql.TypeLonglong: mysql.TypeNewDecimal,
+	mysql.TypeYear:     mysql.TypeShort,
+	mysql.TypeBit:      mysql.TypeNewDecimal,
+}
+
+func isIntegerType(tp byte) bool {
+	switch tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		return true
+	}
+	return false
+}
+
+func isNumericType(tp byte) bool {
+	switch tp {
+	case mysql.TypeFloat, mysql.TypeDouble, mysql.TypeDecimal, mysql.TypeNewDecimal:
+		return true
+	}
+	return isIntegerType(tp)
+}
+
 func getFieldTypeIndex(tp byte) int {
 	itp := int(tp)
 	if itp < fieldTypeTearFrom {
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/util/charset"
+)
+
+func TestAggregateFieldType(t *testing.T) {
+	fieldType := func(tp byte, flag uint, flen, decimal int, chs string) *FieldType {
+		return &FieldType{Tp: tp, Flag: flag, Flen: flen, Decimal: decimal, Charset: chs}
+	}
+	null := NewFieldType(mysql.TypeNull)
+	unsignedInt := fieldType(mysql.TypeLong, mysql.UnsignedFlag|mysql.NotNullFlag, 10, 0, charset.CharsetBin)
+	signedBigint := fieldType(mysql.TypeLonglong, 0, 20, 0, charset.CharsetBin)
+	unsignedBigint := fieldType(mysql.TypeLonglong, mysql.UnsignedFlag, 20, 0, charset.CharsetBin)
+	decimal := fieldType(mysql.TypeNewDecimal, 0, 10, 4, charset.CharsetBin)
+	varchar := fieldType(mysql.TypeVarchar, 0, 30, 0, mysql.DefaultCharset)
+	varbinary := fieldType(mysql.TypeVarchar, mysql.BinaryFlag, 10, 0, charset.CharsetBin)
+
+	tests := []struct {
+		notNull bool
+		args    []*FieldType
+		tp      byte
+		flag    uint
+		flen    int
+		decimal int
+		charset string
+	}{
+		{true, []*FieldType{null, unsignedInt}, mysql.TypeLong, mysql.UnsignedFlag | mysql.BinaryFlag | mysql.NotNullFlag, 10, UnspecifiedLength, charset.CharsetBin},
+		{false, []*FieldType{null, unsignedInt}, mysql.TypeLong, mysql.UnsignedFlag | mysql.BinaryFlag, 10, UnspecifiedLength, charset.CharsetBin},
+		{true, []*FieldType{unsignedInt, signedBigint}, mysql.TypeLonglong, mysql.BinaryFlag | mysql.NotNullFlag, 20, UnspecifiedLength, charset.CharsetBin},
+		{true, []*FieldType{unsignedBigint, signedBigint}, mysql.TypeNewDecimal, mysql.BinaryFlag, 20, 0, charset.CharsetBin},
+		{true, []*FieldType{signedBigint, decimal}, mysql.TypeNewDecimal, mysql.BinaryFlag, 24, 4, charset.CharsetBin},
+		{true, []*FieldType{signedBigint, varchar}, mysql.TypeVarchar, 0, 30, UnspecifiedLength, mysql.DefaultCharset},
+		{true, []*FieldType{varchar, varbinary}, mysql.TypeVarchar, mysql.BinaryFlag, 30, UnspecifiedLength, charset.CharsetBin},
+		{true, []*FieldType{null, null}, mysql.TypeNull, 0, UnspecifiedLength, UnspecifiedLength, charset.CharsetBin},
+	}
+	for i, test := range tests {
+		tp := AggregateFieldType(test.notNull, test.args...)
+		if tp.Tp != test.tp || tp.Flag != test.flag || tp.Flen != test.flen || tp.Decimal != test.decimal || tp.Charset != test.charset {
+			t.Errorf("%d: type %+v, expecting tp %d flag %d flen %d decimal %d charset %s",
+				i, tp, test.tp, test.flag, test.flen, test.decimal, test.charset)
+		}
+	}
+}
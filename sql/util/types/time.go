@@ -444,14 +444,28 @@ func (t *Time) check() error {
 	return nil
 }
 
+// ConvertTimeZone converts the time value from one time zone to another.
+// The zero value is kept as is.
+func (t *Time) ConvertTimeZone(from, to *gotime.Location) error {
+	if t.IsZero() {
+		return nil
+	}
+	raw, err := t.Time.GoTime(from)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	t.Time = FromGoTime(raw.In(to))
+	return nil
+}
+
 // Sub subtracts t1 from t, returns a duration value.
 // Note that sub should not be done on different time types.
 func (t *Time) Sub(t1 *Time) Duration {
 	var duration gotime.Duration
 	if t.Type == mysql.TypeTimestamp && t1.Type == mysql.TypeTimestamp {
-		// TODO: Consider time_zone variable.
-		a, _ := t.Time.GoTime(gotime.Local)
-		b, _ := t1.Time.GoTime(gotime.Local)
+		// timestamps are stored in UTC
+		a, _ := t.Time.GoTime(gotime.UTC)
+		b, _ := t1.Time.GoTime(gotime.UTC)
 		duration = a.Sub(b)
 	} else {
 		seconds, microseconds, neg := calcTimeDiff(t.Time, t1.Time, 1)
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lovelly/gleam/sql/mysql"
+	"github.com/lovelly/gleam/sql/sessionctx/variable"
+)
+
+func TestTimestampTimeZone(t *testing.T) {
+	east := &variable.StatementContext{TimeZone: time.FixedZone("UTC+8", 8*3600)}
+	utc := &variable.StatementContext{TimeZone: time.UTC}
+
+	d := NewStringDatum("2017-01-01 08:00:00")
+	ts, err := d.ConvertTo(east, NewFieldType(mysql.TypeTimestamp))
+	if err != nil {
+		t.Fatalf("convert to timestamp: %v", err)
+	}
+	if s := ts.GetMysqlTime().String(); s != "2017-01-01 00:00:00" {
+		t.Errorf("timestamp is stored as %s, expected UTC 2017-01-01 00:00:00", s)
+	}
+
+	for _, c := range []struct {
+		sc       *variable.StatementContext
+		expected string
+	}{
+		{east, "2017-01-01 08:00:00"},
+		{utc, "2017-01-01 00:00:00"},
+	} {
+		s, err := ts.ConvertTo(c.sc, NewFieldType(mysql.TypeVarchar))
+		if err != nil {
+			t.Fatalf("convert to string: %v", err)
+		}
+		if s.GetString() != c.expected {
+			t.Errorf("timestamp shown as %s in %v, expected %s", s.GetString(), c.sc.TimeZone, c.expected)
+		}
+		dt, err := ts.ConvertTo(c.sc, NewFieldType(mysql.TypeDatetime))
+		if err != nil {
+			t.Fatalf("convert to datetime: %v", err)
+		}
+		if s := dt.GetMysqlTime().String(); s != c.expected {
+			t.Errorf("timestamp converted to datetime %s in %v, expected %s", s, c.sc.TimeZone, c.expected)
+		}
+	}
+}
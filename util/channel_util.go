@@ -114,6 +114,10 @@ func ReaderToChannel(wg *sync.WaitGroup, name string, readCloser io.ReadCloser,
 	}
 	var counter int64
 	err := copyBuffer(writer, reader, &counter)
+	if err == ErrReaderStopped {
+		// the reader does not need the rest of the input
+		return nil
+	}
 	if err != nil {
 		fmt.Fprintf(errorOutput, "%s>Read %d bytes from input to channel: %v\n", name, counter, err)
 		return err
@@ -153,12 +157,7 @@ func LineReaderToChannel(wg *sync.WaitGroup, stat *pb.InstructionStat, name stri
 	for scanner.Scan() {
 		stat.InputCounter++
 		// fmt.Printf("%s>line input: %s\n", name, scanner.Text())
-		parts := bytes.Split(scanner.Bytes(), []byte{'\t'})
-		var slice []interface{}
-		for _, m := range parts {
-			slice = append(slice, m)
-		}
-		NewRow(Now(), slice...).WriteTo(w)
+		tsvRow(scanner.Bytes()).WriteTo(w)
 		stat.OutputCounter++
 	}
 	if err := scanner.Err(); err != nil {
@@ -167,6 +166,17 @@ func LineReaderToChannel(wg *sync.WaitGroup, stat *pb.InstructionStat, name stri
 	}
 }
 
+// tsvRow splits the tab separated line into the []byte fields of a row,
+// which share the bytes of the line.
+func tsvRow(line []byte) *Row {
+	parts := bytes.Split(line, []byte{'\t'})
+	slice := make([]interface{}, len(parts))
+	for i, m := range parts {
+		slice[i] = m
+	}
+	return NewRow(Now(), slice...)
+}
+
 func ConvertLineReaderToRowReader(lineReader io.Reader, name string, errorOutput io.Writer) (rowReader io.Reader) {
 	piper := NewPiper()
 	go func() {
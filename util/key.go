@@ -87,7 +87,7 @@ func Compare(a interface{}, b interface{}) (ret int) {
 		aIsFloat := isFloat(a)
 		bIsFloat := isFloat(b)
 		if !aIsFloat && !bIsFloat {
-			return int(ToInt64(a) - ToInt64(b))
+			return compareInts(a, b)
 		}
 
 		t := ToFloat64(a)
@@ -105,6 +105,42 @@ func Compare(a interface{}, b interface{}) (ret int) {
 	return ret
 }
 
+// compareInts compares integers without overflowing, so large unsigned
+// values such as BIT(64) columns keep their order.
+func compareInts(a interface{}, b interface{}) int {
+	x, xIsUint := a.(uint64)
+	y, yIsUint := b.(uint64)
+	if !xIsUint && !yIsUint {
+		x, y := ToInt64(a), ToInt64(b)
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+	}
+	if !xIsUint {
+		i := ToInt64(a)
+		if i < 0 {
+			return -1
+		}
+		x = uint64(i)
+	}
+	if !yIsUint {
+		i := ToInt64(b)
+		if i < 0 {
+			return 1
+		}
+		y = uint64(i)
+	}
+	if x < y {
+		return -1
+	} else if x > y {
+		return 1
+	}
+	return 0
+}
+
 func isFloat(a interface{}) bool {
 	if _, ok := a.(float64); ok {
 		return true
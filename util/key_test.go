@@ -20,3 +20,22 @@ func TestHashPositive(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareIntegers(t *testing.T) {
+	cases := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{int64(1), int64(2), -1},
+		{int64(-9223372036854775808), int64(1), -1},
+		{uint64(0xffffffffffffffff), uint64(1), 1},
+		{uint64(0xffffffffffffffff), int64(1), 1},
+		{int64(-1), uint64(1), -1},
+		{uint64(3), int32(3), 0},
+	}
+	for _, c := range cases {
+		if x := Compare(c.a, c.b); x != c.expected {
+			t.Errorf("Compare(%v, %v) = %d, expected %d", c.a, c.b, x, c.expected)
+		}
+	}
+}
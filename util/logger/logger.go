@@ -0,0 +1,262 @@
+// Package logger writes leveled log lines with the flow, step and task ids
+// as fields, in text or JSON, so the logs of the master, the agents and the
+// executors can be collected and filtered together.
+//
+//	logger.ForTask(flowId, stepId, taskId).Infof("reading %s", name)
+//
+// writes, in the text format,
+//
+//	2017/06/01 12:00:00 INFO reading f1-d2-s3 flow=12345 step=2 task=3
+//
+// and, in the JSON format,
+//
+//	{"time":"2017-06-01T12:00:00Z","level":"info","msg":"reading f1-d2-s3","flow":12345,"step":2,"task":3}
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int32
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+	Fatal
+)
+
+var levelNames = []string{"debug", "info", "warn", "error", "fatal"}
+
+func (l Level) String() string {
+	if l < Debug || l > Fatal {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown log level %q, expecting debug, info, warn or error", name)
+}
+
+const (
+	FormatText = "text"
+	FormatJson = "json"
+)
+
+var (
+	lock   sync.Mutex
+	level            = Info
+	format           = FormatText
+	output io.Writer = os.Stderr
+)
+
+// Configure sets the level and the format, e.g. from the command line flags.
+func Configure(levelName, formatName string) error {
+	l, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	if formatName != FormatText && formatName != FormatJson {
+		return fmt.Errorf("unknown log format %q, expecting text or json", formatName)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	level, format = l, formatName
+	return nil
+}
+
+// Args are the command line flags to configure a child process, e.g. an
+// executor started by the agent, like this one.
+func Args() []string {
+	lock.Lock()
+	defer lock.Unlock()
+	return []string{"--log.level", level.String(), "--log.format", format}
+}
+
+func SetOutput(w io.Writer) {
+	lock.Lock()
+	defer lock.Unlock()
+	output = w
+}
+
+// IsEnabled tells whether the lines of the level are written.
+func IsEnabled(l Level) bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return l >= level
+}
+
+// RedirectStdLog writes the lines of the standard log package, e.g. of the
+// libraries, at the info level, or at the fatal and error levels for the
+// log.Fatal and log.Panic lines.
+func RedirectStdLog() {
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(stdLogWriter{})
+}
+
+type stdLogWriter struct{}
+
+func (stdLogWriter) Write(p []byte) (int, error) {
+	root.write(stdLogLevel(), strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// stdLogLevel finds the level of a standard log line by its caller.
+func stdLogLevel() Level {
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		switch name := strings.TrimPrefix(frame.Function, "log.(*Logger)"); {
+		case strings.HasPrefix(name, "log.Fatal"), strings.HasPrefix(name, ".Fatal"):
+			return Fatal
+		case strings.HasPrefix(name, "log.Panic"), strings.HasPrefix(name, ".Panic"):
+			return Error
+		}
+		if !more {
+			return Info
+		}
+	}
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes the lines with its fields.
+type Logger struct {
+	fields []field
+}
+
+var root = &Logger{}
+
+// With returns a logger adding the field to the lines.
+func With(key string, value interface{}) *Logger {
+	return root.With(key, value)
+}
+
+func (l *Logger) With(key string, value interface{}) *Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	return &Logger{fields: append(fields, field{key, value})}
+}
+
+// ForFlow returns a logger adding the flow id, the hash code of the flow.
+func ForFlow(flowId uint32) *Logger {
+	return root.With("flow", flowId)
+}
+
+// ForTask returns a logger adding the flow, step and task ids.
+func ForTask(flowId uint32, stepId, taskId int32) *Logger {
+	return ForFlow(flowId).With("step", stepId).With("task", taskId)
+}
+
+func (l *Logger) Debugf(f string, args ...interface{}) { l.logf(Debug, f, args...) }
+func (l *Logger) Infof(f string, args ...interface{})  { l.logf(Info, f, args...) }
+func (l *Logger) Warnf(f string, args ...interface{})  { l.logf(Warn, f, args...) }
+func (l *Logger) Errorf(f string, args ...interface{}) { l.logf(Error, f, args...) }
+
+// Fatalf writes the line and exits.
+func (l *Logger) Fatalf(f string, args ...interface{}) {
+	l.logf(Fatal, f, args...)
+	os.Exit(1)
+}
+
+func Debugf(f string, args ...interface{}) { root.logf(Debug, f, args...) }
+func Infof(f string, args ...interface{})  { root.logf(Info, f, args...) }
+func Warnf(f string, args ...interface{})  { root.logf(Warn, f, args...) }
+func Errorf(f string, args ...interface{}) { root.logf(Error, f, args...) }
+
+// Fatalf writes the line and exits.
+func Fatalf(f string, args ...interface{}) {
+	root.logf(Fatal, f, args...)
+	os.Exit(1)
+}
+
+func (l *Logger) logf(lineLevel Level, f string, args ...interface{}) {
+	if !IsEnabled(lineLevel) {
+		return
+	}
+	l.write(lineLevel, fmt.Sprintf(f, args...))
+}
+
+func (l *Logger) write(lineLevel Level, message string) {
+	lock.Lock()
+	defer lock.Unlock()
+	if lineLevel < level {
+		return
+	}
+	var line []byte
+	if format == FormatJson {
+		line = l.jsonLine(time.Now(), lineLevel, message)
+	} else {
+		line = l.textLine(time.Now(), lineLevel, message)
+	}
+	output.Write(line)
+}
+
+func (l *Logger) textLine(t time.Time, lineLevel Level, message string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(t.Format("2006/01/02 15:04:05 "))
+	buf.WriteString(strings.ToUpper(lineLevel.String()))
+	buf.WriteByte(' ')
+	buf.WriteString(message)
+	for _, f := range l.fields {
+		value := fmt.Sprint(f.value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&buf, " %s=%s", f.key, value)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func (l *Logger) jsonLine(t time.Time, lineLevel Level, message string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJsonValue(&buf, t.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJsonValue(&buf, lineLevel.String())
+	buf.WriteString(`,"msg":`)
+	writeJsonValue(&buf, message)
+	for _, f := range l.fields {
+		buf.WriteByte(',')
+		writeJsonValue(&buf, f.key)
+		buf.WriteByte(':')
+		writeJsonValue(&buf, f.value)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func writeJsonValue(buf *bytes.Buffer, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer func() {
+		SetOutput(os.Stderr)
+		Configure("info", FormatText)
+	}()
+
+	if err := Configure("warn", FormatText); err != nil {
+		t.Fatal(err)
+	}
+	ForTask(12345, 2, 3).Infof("not written")
+	ForTask(12345, 2, 3).With("name", "f1 d2").Warnf("reading %s", "shard")
+	line := buf.String()
+	if strings.Contains(line, "not written") {
+		t.Errorf("info line written at warn level: %s", line)
+	}
+	if !strings.HasSuffix(line, ` WARN reading shard flow=12345 step=2 task=3 name="f1 d2"`+"\n") {
+		t.Errorf("unexpected text line: %s", line)
+	}
+
+	buf.Reset()
+	if err := Configure("debug", FormatJson); err != nil {
+		t.Fatal(err)
+	}
+	ForFlow(12345).Debugf("a %q", "quoted")
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json line %s: %v", buf.String(), err)
+	}
+	if decoded["level"] != "debug" || decoded["msg"] != `a "quoted"` || decoded["flow"] != float64(12345) {
+		t.Errorf("unexpected json line: %s", buf.String())
+	}
+
+	if err := Configure("verbose", FormatText); err == nil {
+		t.Errorf("expected an error for an unknown level")
+	}
+	if err := Configure("info", "xml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	RedirectStdLog()
+	defer func() {
+		SetOutput(os.Stderr)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	log.Printf("from a library")
+	if line := buf.String(); !strings.HasSuffix(line, " INFO from a library\n") {
+		t.Errorf("unexpected std log line: %s", line)
+	}
+
+	buf.Reset()
+	func() {
+		defer func() { recover() }()
+		log.Panicf("broken %d", 1)
+	}()
+	if line := buf.String(); !strings.HasSuffix(line, " ERROR broken 1\n") {
+		t.Errorf("unexpected std log panic line: %s", line)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,15 +14,22 @@ import (
 type MessageControl int32
 
 const (
-	MessageControlEOF = MessageControl(math.MinInt32)
+	MessageControlEOF   = MessageControl(math.MinInt32)
+	MessageControlAbort = MessageControl(math.MinInt32 + 1)
 )
 
+// ErrAborted is returned when reading a partition whose writer failed, so
+// its rows are incomplete.
+var ErrAborted = errors.New("the partition is aborted by its writer")
+
 // message contains 3 kinds of data with the formats:
 //   the first 4 bytes is int32 flag
 //   if flag > 0
 //     actual data row bytes with length = flag
 //   else if flag == math.MinInt32
 //     end of partition: EOF(math.MinInt32)
+//   else if flag == math.MinInt32 + 1
+//     the writer failed, and the partition is incomplete
 //   else
 //     meta data bytes with length = - flag
 
@@ -84,8 +92,46 @@ func ProcessMessage(reader io.Reader, f func([]byte) error) (err error) {
 	return TakeMessage(reader, -1, f)
 }
 
-// ReadMessage reads out the []byte for one message
+// ReadMessage reads out the []byte for one message. It returns io.EOF at
+// the end of the partition, and ErrAborted if the partition is aborted.
 func ReadMessage(reader io.Reader) (m []byte, err error) {
+	m, err = readMessage(reader)
+	if err == errEndOfPartition {
+		return nil, io.EOF
+	}
+	return
+}
+
+// ReadStreamMessage is ReadMessage for the streams written to the agents,
+// which end with the EOF message. It returns io.ErrUnexpectedEOF if the
+// stream ends without the EOF message, e.g. the writer is killed.
+func ReadStreamMessage(reader io.Reader) (m []byte, err error) {
+	m, err = readMessage(reader)
+	switch err {
+	case errEndOfPartition:
+		return nil, io.EOF
+	case io.EOF:
+		return nil, io.ErrUnexpectedEOF
+	}
+	return
+}
+
+// HasBufferedMessage tells whether the next message is read from the buffer,
+// without waiting for more data, e.g. to flush the answers to the messages
+// read before waiting.
+func HasBufferedMessage(reader *bufio.Reader) bool {
+	if reader.Buffered() < 4 {
+		return false
+	}
+	header, _ := reader.Peek(4)
+	length := int32(binary.LittleEndian.Uint32(header))
+	// the control messages have no content
+	return length < 0 || reader.Buffered() >= 4+int(length)
+}
+
+var errEndOfPartition = errors.New("end of partition")
+
+func readMessage(reader io.Reader) (m []byte, err error) {
 	var length int32
 	err = binary.Read(reader, binary.LittleEndian, &length)
 	if err == io.EOF {
@@ -95,7 +141,10 @@ func ReadMessage(reader io.Reader) (m []byte, err error) {
 		return nil, fmt.Errorf("Failed to read message length: %v", err)
 	}
 	if length == int32(MessageControlEOF) {
-		return nil, io.EOF
+		return nil, errEndOfPartition
+	}
+	if length == int32(MessageControlAbort) {
+		return nil, ErrAborted
 	}
 	if length == 0 {
 		return
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReadStreamMessage(t *testing.T) {
+
+	var complete, aborted, truncated bytes.Buffer
+	for _, buf := range []*bytes.Buffer{&complete, &aborted, &truncated} {
+		WriteMessage(buf, []byte("row"))
+	}
+	WriteEOFMessage(&complete)
+	WriteAbortMessage(&aborted)
+
+	if m, err := ReadStreamMessage(&complete); err != nil || string(m) != "row" {
+		t.Errorf("read %q: %v", m, err)
+	}
+	if _, err := ReadStreamMessage(&complete); err != io.EOF {
+		t.Errorf("complete stream ends with %v", err)
+	}
+
+	ReadStreamMessage(&aborted)
+	if _, err := ReadStreamMessage(&aborted); err != ErrAborted {
+		t.Errorf("aborted stream ends with %v", err)
+	}
+
+	// a stream closed by the writer without the EOF message is incomplete
+	ReadStreamMessage(&truncated)
+	if _, err := ReadStreamMessage(&truncated); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated stream ends with %v", err)
+	}
+
+	// the partitions piped between instructions end without the EOF message
+	WriteMessage(&truncated, []byte("row"))
+	ReadMessage(&truncated)
+	if _, err := ReadMessage(&truncated); err != io.EOF {
+		t.Errorf("partition ends with %v", err)
+	}
+}
+
+func TestHasBufferedMessage(t *testing.T) {
+	var data bytes.Buffer
+	WriteMessage(&data, []byte("row"))
+	WriteEOFMessage(&data)
+
+	// the reader gets the bytes one by one, as from a slow network
+	reader := bufio.NewReader(&slowReader{data.Bytes()})
+	expected := []bool{false, false, false, false, false, false, false, true}
+	for i, isBuffered := range expected {
+		if i > 0 {
+			reader.Peek(i)
+		}
+		if got := HasBufferedMessage(reader); got != isBuffered {
+			t.Errorf("with %d bytes buffered: %v", i, got)
+		}
+	}
+	if m, err := ReadMessage(reader); err != nil || string(m) != "row" {
+		t.Fatalf("read %q: %v", m, err)
+	}
+	reader.Peek(4)
+	if !HasBufferedMessage(reader) {
+		t.Errorf("the EOF message is not buffered")
+	}
+}
+
+// slowReader reads a byte at a time.
+type slowReader struct {
+	data []byte
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0], r.data = r.data[0], r.data[1:]
+	return 1, nil
+}
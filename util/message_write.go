@@ -13,6 +13,15 @@ func WriteEOFMessage(writer io.Writer) (err error) {
 	return
 }
 
+// WriteAbortMessage ends a partition as incomplete, so its readers fail
+// instead of reading only part of it.
+func WriteAbortMessage(writer io.Writer) (err error) {
+	if err = binary.Write(writer, binary.LittleEndian, int32(MessageControlAbort)); err != nil {
+		return fmt.Errorf("Failed to write message length: %v", err)
+	}
+	return
+}
+
 func WriteMessage(writer io.Writer, m []byte) (err error) {
 	if err = binary.Write(writer, binary.LittleEndian, int32(len(m))); err != nil {
 		return fmt.Errorf("Failed to write message length: %v", err)
@@ -44,7 +53,9 @@ func (b *BufferedMessageWriter) WriteMessage(m []byte) (err error) {
 	nextSize := 4 + len(m)
 	if nextSize > b.Available() {
 		if b.Buffered() > 0 {
-			b.flush()
+			if err := b.flush(); err != nil {
+				return err
+			}
 		}
 		if nextSize > b.Available() {
 			// Large write, empty buffer.
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// PeekWrites wraps the writers to copy the first count rows written to any of
+// them into stat.PeekedRows, for developers to inspect the output of a step.
+// The rows are length prefixed messages, or lines if isLines, e.g. the output
+// of Pipe() commands, which are encoded as rows of their tab separated fields.
+// The rows are appended under statLock, which the readers of the stat hold
+// while the step runs. It may be nil if the stat is read after the writes.
+func PeekWrites(writers []io.Writer, count int, isLines bool, stat *pb.InstructionStat, statLock sync.Locker) (ret []io.Writer) {
+	if statLock == nil {
+		statLock = &sync.Mutex{}
+	}
+	p := &rowPeeker{Locker: statLock, count: count, isLines: isLines, stat: stat}
+	for _, w := range writers {
+		ret = append(ret, &peekWriter{w: w, peeker: p})
+	}
+	return ret
+}
+
+type rowPeeker struct {
+	sync.Locker
+	count   int
+	isLines bool
+	stat    *pb.InstructionStat
+}
+
+func (p *rowPeeker) isFull() bool {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.stat.PeekedRows) >= p.count
+}
+
+func (p *rowPeeker) add(row []byte) {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.stat.PeekedRows) < p.count {
+		p.stat.PeekedRows = append(p.stat.PeekedRows, append([]byte{}, row...))
+	}
+}
+
+// peekWriter splits the bytes written to one writer into rows.
+type peekWriter struct {
+	w      io.Writer
+	peeker *rowPeeker
+	buf    []byte
+	done   bool
+}
+
+func (pw *peekWriter) Write(data []byte) (n int, err error) {
+	n, err = pw.w.Write(data)
+	if pw.done {
+		return
+	}
+	if pw.peeker.isFull() {
+		pw.done, pw.buf = true, nil
+		return
+	}
+	pw.buf = append(pw.buf, data[:n]...)
+	if pw.peeker.isLines {
+		pw.splitLines()
+	} else {
+		pw.splitMessages()
+	}
+	return
+}
+
+// Close closes the wrapped writer, so that the end of the rows can be signaled
+// to the readers through the wrapper.
+func (pw *peekWriter) Close() error {
+	if c, ok := pw.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (pw *peekWriter) splitLines() {
+	for {
+		i := bytes.IndexByte(pw.buf, '\n')
+		if i < 0 {
+			return
+		}
+		var fields []interface{}
+		for _, field := range bytes.Split(pw.buf[:i], []byte{'\t'}) {
+			fields = append(fields, append([]byte{}, field...))
+		}
+		if encoded, err := encodeRow(*NewRow(Now(), fields...)); err == nil {
+			pw.peeker.add(encoded)
+		}
+		pw.buf = pw.buf[i+1:]
+	}
+}
+
+func (pw *peekWriter) splitMessages() {
+	for len(pw.buf) >= 4 {
+		length := int32(binary.LittleEndian.Uint32(pw.buf))
+		if length < 0 {
+			// the EOF control message
+			pw.done, pw.buf = true, nil
+			return
+		}
+		if len(pw.buf) < 4+int(length) {
+			return
+		}
+		pw.peeker.add(pw.buf[4 : 4+length])
+		pw.buf = pw.buf[4+length:]
+	}
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestPeekWrites(t *testing.T) {
+
+	var output bytes.Buffer
+	var messages bytes.Buffer
+	for _, m := range []string{"first", "second", "third"} {
+		WriteMessage(&messages, []byte(m))
+	}
+	WriteEOFMessage(&messages)
+
+	stat := &pb.InstructionStat{}
+	writer := PeekWrites([]io.Writer{&output}, 2, false, stat, nil)[0]
+
+	// write in small chunks to split the messages
+	data := messages.Bytes()
+	for len(data) > 0 {
+		n := 3
+		if n > len(data) {
+			n = len(data)
+		}
+		writer.Write(data[:n])
+		data = data[n:]
+	}
+
+	if !bytes.Equal(output.Bytes(), messages.Bytes()) {
+		t.Errorf("written %q, expected %q", output.Bytes(), messages.Bytes())
+	}
+	if len(stat.PeekedRows) != 2 || string(stat.PeekedRows[0]) != "first" || string(stat.PeekedRows[1]) != "second" {
+		t.Errorf("peeked %q", stat.PeekedRows)
+	}
+}
+
+func TestPeekWritesLines(t *testing.T) {
+
+	var output bytes.Buffer
+	stat := &pb.InstructionStat{}
+	writer := PeekWrites([]io.Writer{&output}, 5, true, stat, nil)[0]
+
+	writer.Write([]byte("a\t1\nb"))
+	writer.Write([]byte("\t2\n"))
+
+	if len(stat.PeekedRows) != 2 {
+		t.Fatalf("peeked %d rows", len(stat.PeekedRows))
+	}
+	row, err := DecodeRow(stat.PeekedRows[1])
+	if err != nil {
+		t.Fatalf("DecodeRow: %v", err)
+	}
+	if string(row.K[0].([]byte)) != "b" || string(row.V[0].([]byte)) != "2" {
+		t.Errorf("peeked row %+v", row)
+	}
+}
+
+func TestPeekWritesUnderStatLock(t *testing.T) {
+
+	var lock sync.Mutex
+	stat := &pb.InstructionStat{}
+	writer := PeekWrites([]io.Writer{ioutil.Discard}, 100, true, stat, &lock)[0]
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			writer.Write([]byte("a\t1\n"))
+		}
+	}()
+	// the reporter copies the rows while they are written
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		lock.Lock()
+		peeked := append([][]byte(nil), stat.PeekedRows...)
+		lock.Unlock()
+		if len(peeked) > 100 {
+			t.Fatalf("peeked %d rows", len(peeked))
+		}
+	}
+	if len(stat.PeekedRows) != 100 {
+		t.Errorf("peeked %d rows", len(stat.PeekedRows))
+	}
+}
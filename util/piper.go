@@ -1,7 +1,9 @@
 package util
 
 import (
+	"errors"
 	"io"
+	"io/ioutil"
 )
 
 type Piper struct {
@@ -18,3 +20,23 @@ func NewPiper() *Piper {
 		Writer: pw,
 	}
 }
+
+// ErrReaderStopped is returned to the writer of a StoppableReader that is stopped.
+var ErrReaderStopped = errors.New("reader stopped")
+
+// StoppableReader is a pipe whose writer can stop sending, e.g. by closing
+// its network channel, when the reader does not need the rest of the data.
+type StoppableReader struct {
+	*io.PipeReader
+}
+
+// StopReading skips the rest of the reader. A StoppableReader is closed,
+// so its writer gets ErrReaderStopped, and other readers are drained,
+// so their writers are not blocked.
+func StopReading(reader io.Reader) {
+	if r, ok := reader.(*StoppableReader); ok {
+		r.CloseWithError(ErrReaderStopped)
+		return
+	}
+	io.Copy(ioutil.Discard, reader)
+}
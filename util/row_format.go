@@ -0,0 +1,291 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+// The formats of rows in byte streams, e.g. the console of "gleam write"
+// and "gleam read".
+const (
+	// FormatTsv is tab separated lines, of []byte fields.
+	FormatTsv = "tsv"
+	// FormatCsv is comma separated lines, of string fields.
+	FormatCsv = "csv"
+	// FormatJsonl is one JSON array per line, of typed fields.
+	FormatJsonl = "jsonl"
+	// FormatMsgpack is the msgpack encoded rows of the channels.
+	FormatMsgpack = "msgpack"
+)
+
+// FormatReaderToChannel reads the rows in the format from the reader, uses
+// the 1-based keyFields as the keys if set, and writes them to the channel.
+func FormatReaderToChannel(wg *sync.WaitGroup, stat *pb.InstructionStat, name, format string, keyFields []int, reader io.Reader, ch io.WriteCloser, errorOutput io.Writer) {
+	if (format == FormatTsv || format == "") && keyFields == nil {
+		LineReaderToChannel(wg, stat, name, reader, ch, true, errorOutput)
+		return
+	}
+
+	defer wg.Done()
+	defer ch.Close()
+
+	w := bufio.NewWriterSize(ch, BUFFER_SIZE)
+	defer w.Flush()
+
+	err := ReadFormattedRows(bufio.NewReaderSize(reader, BUFFER_SIZE), format, func(row *Row) error {
+		stat.InputCounter++
+		if keyFields != nil {
+			if err := checkFields(keyFields, len(row.K)+len(row.V)); err != nil {
+				return err
+			}
+			row.UseKeys(keyFields)
+		}
+		stat.OutputCounter++
+		return row.WriteTo(w)
+	})
+	if err != nil {
+		fmt.Fprintf(errorOutput, "%s>Failed to read %s rows to channel: %v\n", name, format, err)
+	}
+}
+
+// ChannelToFormatWriter writes the rows of the channel in the format, with
+// only the 1-based fields if set.
+func ChannelToFormatWriter(wg *sync.WaitGroup, stat *pb.InstructionStat, name, format string, fields []int, reader io.Reader, writer io.WriteCloser, errorOutput io.Writer) {
+	defer wg.Done()
+	defer writer.Close()
+
+	w := bufio.NewWriterSize(writer, BUFFER_SIZE)
+	defer w.Flush()
+
+	write, err := formattedRowWriter(w, format)
+	if err != nil {
+		fmt.Fprintf(errorOutput, "%s>%v\n", name, err)
+		return
+	}
+
+	err = ProcessMessage(bufio.NewReaderSize(reader, BUFFER_SIZE), func(encodedBytes []byte) error {
+		row, err := DecodeRow(encodedBytes)
+		if err != nil {
+			return fmt.Errorf("Failed to decode byte: %v", err)
+		}
+		stat.InputCounter++
+		values := append(append([]interface{}{}, row.K...), row.V...)
+		if fields != nil {
+			if err := checkFields(fields, len(values)); err != nil {
+				return err
+			}
+			var selected []interface{}
+			for _, field := range fields {
+				selected = append(selected, values[field-1])
+			}
+			values = selected
+		}
+		stat.OutputCounter++
+		return write(row.T, values)
+	})
+	if err != nil {
+		fmt.Fprintf(errorOutput, "%s>Failed to write %s rows from channel: %v\n", name, format, err)
+	}
+}
+
+func checkFields(fields []int, fieldCount int) error {
+	for _, field := range fields {
+		if field < 1 || field > fieldCount {
+			return fmt.Errorf("field %d is out of the %d fields", field, fieldCount)
+		}
+	}
+	return nil
+}
+
+// ReadFormattedRows reads the rows in the format, and calls f on each row.
+// The []byte fields of tsv rows are only valid until f returns.
+func ReadFormattedRows(reader io.Reader, format string, f func(*Row) error) error {
+	switch format {
+	case FormatTsv, "":
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if err := f(tsvRow(scanner.Bytes())); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	case FormatCsv:
+		r := csv.NewReader(reader)
+		r.FieldsPerRecord = -1
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			var values []interface{}
+			for _, field := range record {
+				values = append(values, field)
+			}
+			if err := f(NewRow(Now(), values...)); err != nil {
+				return err
+			}
+		}
+	case FormatJsonl:
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.UseNumber()
+			var values []interface{}
+			if err := decoder.Decode(&values); err != nil {
+				return fmt.Errorf("Failed to parse %q as a JSON array: %v", line, err)
+			}
+			for i, value := range values {
+				values[i] = fromJson(value)
+			}
+			if err := f(NewRow(Now(), values...)); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	case FormatMsgpack:
+		return ProcessMessage(reader, func(encodedBytes []byte) error {
+			row, err := DecodeRow(encodedBytes)
+			if err != nil {
+				return fmt.Errorf("Failed to decode byte: %v", err)
+			}
+			return f(row)
+		})
+	}
+	return fmt.Errorf("Unknown row format %s", format)
+}
+
+// formattedRowWriter returns the function writing the values of a row.
+func formattedRowWriter(w io.Writer, format string) (func(ts int64, values []interface{}) error, error) {
+	switch format {
+	case FormatTsv, "":
+		return func(ts int64, values []interface{}) error {
+			if _, err := fprintRow(w, 0, "\t", values...); err != nil {
+				return err
+			}
+			_, err := w.Write([]byte("\n"))
+			return err
+		}, nil
+	case FormatCsv:
+		cw := csv.NewWriter(w)
+		return func(ts int64, values []interface{}) error {
+			record := make([]string, len(values))
+			for i, value := range values {
+				switch v := value.(type) {
+				case []byte:
+					record[i] = string(v)
+				case nil:
+				default:
+					record[i] = fmt.Sprint(v)
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+			cw.Flush()
+			return cw.Error()
+		}, nil
+	case FormatJsonl:
+		encoder := json.NewEncoder(w)
+		return func(ts int64, values []interface{}) error {
+			for i, value := range values {
+				values[i] = toJson(value)
+			}
+			return encoder.Encode(values)
+		}, nil
+	case FormatMsgpack:
+		return func(ts int64, values []interface{}) error {
+			return NewRow(ts, values...).WriteTo(w)
+		}, nil
+	}
+	return nil, fmt.Errorf("Unknown row format %s", format)
+}
+
+// JsonRow encodes the values as a JSON array, as a line of the jsonl format.
+func JsonRow(values []interface{}) ([]byte, error) {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		converted[i] = toJson(value)
+	}
+	return json.Marshal(converted)
+}
+
+// JsonValue converts a value decoded with json.Decoder.UseNumber() to a
+// field value: the numbers to int64 or float64, and the nested objects and
+// arrays to their JSON text.
+func JsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return fromJsonNumber(v)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return value
+}
+
+// fromJson converts the JSON numbers to int64 or float64.
+func fromJson(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return fromJsonNumber(v)
+	case []interface{}:
+		for i, x := range v {
+			v[i] = fromJson(x)
+		}
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = fromJson(x)
+		}
+	}
+	return value
+}
+
+func fromJsonNumber(v json.Number) interface{} {
+	if i, err := v.Int64(); err == nil {
+		return i
+	}
+	f, _ := v.Float64()
+	return f
+}
+
+// toJson converts the []byte to strings, which JSON would encode in base64.
+func toJson(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, x := range v {
+			list[i] = toJson(x)
+		}
+		return list
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprint(toJson(k))] = toJson(x)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[k] = toJson(x)
+		}
+		return m
+	}
+	return value
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/lovelly/gleam/pb"
+)
+
+func TestFormattedRows(t *testing.T) {
+	for format, input := range map[string]string{
+		FormatTsv:   "a\t1\nb\t2\n",
+		FormatCsv:   "a,1\n\"b,c\",2\n",
+		FormatJsonl: "[\"a\",1,1.5]\n[\"b\",2,[3]]\n",
+	} {
+		var wg sync.WaitGroup
+		channel := NewPiper()
+		wg.Add(1)
+		go FormatReaderToChannel(&wg, &pb.InstructionStat{}, "in", format, []int{2}, bytes.NewBufferString(input), channel.Writer, &bytes.Buffer{})
+
+		var output, errors bytes.Buffer
+		wg.Add(1)
+		ChannelToFormatWriter(&wg, &pb.InstructionStat{}, "out", format, nil, channel.Reader, nopWriteCloser{&output}, &errors)
+		wg.Wait()
+
+		// the second field is the key
+		expected := map[string]string{
+			FormatTsv:   "1\ta\n2\tb\n",
+			FormatCsv:   "1,a\n2,\"b,c\"\n",
+			FormatJsonl: "[1,\"a\",1.5]\n[2,\"b\",[3]]\n",
+		}[format]
+		if output.String() != expected || errors.Len() > 0 {
+			t.Errorf("%s: %q, expected %q, errors %q", format, output.String(), expected, errors.String())
+		}
+	}
+}
+
+func TestTsvRowsWithoutKeys(t *testing.T) {
+	var wg sync.WaitGroup
+	channel := NewPiper()
+	wg.Add(1)
+	go FormatReaderToChannel(&wg, &pb.InstructionStat{}, "in", FormatTsv, nil, bytes.NewBufferString("a\t1\nb\t\n"), channel.Writer, &bytes.Buffer{})
+
+	var output, errors bytes.Buffer
+	wg.Add(1)
+	ChannelToFormatWriter(&wg, &pb.InstructionStat{}, "out", FormatTsv, []int{2, 1}, channel.Reader, nopWriteCloser{&output}, &errors)
+	wg.Wait()
+
+	if expected := "1\ta\n\tb\n"; output.String() != expected || errors.Len() > 0 {
+		t.Errorf("%q, expected %q, errors %q", output.String(), expected, errors.String())
+	}
+}
+
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error { return nil }
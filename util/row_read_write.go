@@ -46,9 +46,27 @@ func EncodeKeys(anyObject ...interface{}) ([]byte, error) {
 			return nil, fmt.Errorf("Failed to encode key: %v", err)
 		}
 	}
+	if err := en.Flush(); err != nil {
+		return nil, fmt.Errorf("Failed to encode key: %v", err)
+	}
 	return buf.Bytes(), nil
 }
 
+// DecodeKeys decodes keys encoded by EncodeKeys
+func DecodeKeys(encodedBytes []byte) (keys []interface{}, err error) {
+	dc := msgp.NewReader(bytes.NewReader(encodedBytes))
+	for {
+		key, err := dc.ReadIntf()
+		if err == io.EOF {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+}
+
 // DecodeRow decodes one row of data from a blob
 func DecodeRow(encodedBytes []byte) (*Row, error) {
 	row := &Row{}